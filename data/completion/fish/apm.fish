@@ -3,7 +3,7 @@
 function __fish_apm_perform_completion
     set -l args (commandline -opc)
     set -l current (commandline -ct)
-    set -l results ($args[1] $args[2..-1] $current --generate-shell-completion 2>/dev/null)
+    set -l results ($args[1] $args[2..-1] "$current" --generate-shell-completion 2>/dev/null)
 
     for line in $results[-1..1]
         if test (string trim -- $line) = ""
@@ -8,12 +8,17 @@ import (
 
 // DBusError создаёт типизированную DBus ошибку на основе APMError.
 // Если ошибка не является APMError, возвращается стандартная dbus.Error.
+// Подсказка по устранению, если есть, передаётся вторым и третьим элементами тела (действие и текст).
 func DBusError(err error) *dbus.Error {
 	var apmErr APMError
 	if errors.As(err, &apmErr) {
+		body := []any{err.Error()}
+		if r := RemediationOf(err); r != nil {
+			body = append(body, r.Action, r.Hint)
+		}
 		return &dbus.Error{
 			Name: apmErr.DBusErrorName(),
-			Body: []any{err.Error()},
+			Body: body,
 		}
 	}
 	return dbus.MakeFailedError(err)
@@ -0,0 +1,28 @@
+package apmerr
+
+import "errors"
+
+// Действия для устранения ошибок, машиночитаемые идентификаторы для клиентов API
+const (
+	RemediationUpdateIndexes   = "update_indexes"
+	RemediationFreeDiskSpace   = "free_disk_space"
+	RemediationClosePkgManager = "close_package_managers"
+	RemediationFixBroken       = "fix_broken"
+	RemediationRunAsRoot       = "run_as_root"
+	RemediationSelectProvider  = "select_provider"
+)
+
+// Remediation подсказка по устранению ошибки: машиночитаемое действие и текст для пользователя.
+type Remediation struct {
+	Action string `json:"action"`
+	Hint   string `json:"hint"`
+}
+
+// RemediationOf возвращает подсказку из цепочки ошибок, если она есть.
+func RemediationOf(err error) *Remediation {
+	var r interface{ GetRemediation() *Remediation }
+	if errors.As(err, &r) {
+		return r.GetRemediation()
+	}
+	return nil
+}
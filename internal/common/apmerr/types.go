@@ -20,6 +20,8 @@ const (
 	ErrorTypeContainer   = "CONTAINER"
 	ErrorTypeNoOperation = "NO_OPERATION"
 	ErrorTypeNotFound    = "NOT_FOUND"
+	ErrorTypeBusy        = "BUSY"
+	ErrorTypeHook        = "HOOK"
 )
 
 type APMError struct {
@@ -66,7 +68,7 @@ func (e APMError) HTTPStatus() int {
 		return http.StatusForbidden
 	case ErrorTypeNotFound:
 		return http.StatusNotFound
-	case ErrorTypeCanceled, ErrorTypeNoOperation:
+	case ErrorTypeCanceled, ErrorTypeNoOperation, ErrorTypeBusy:
 		return http.StatusConflict
 	default:
 		return http.StatusInternalServerError
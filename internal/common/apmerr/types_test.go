@@ -112,3 +112,34 @@ type notFoundError struct {
 
 func (e *notFoundError) Error() string    { return e.msg }
 func (e *notFoundError) IsNotFound() bool { return true }
+
+type remediableError struct {
+	msg string
+}
+
+func (e *remediableError) Error() string { return e.msg }
+func (e *remediableError) GetRemediation() *Remediation {
+	return &Remediation{Action: RemediationFreeDiskSpace, Hint: "free space"}
+}
+
+func TestRemediationOf(t *testing.T) {
+	if r := RemediationOf(fmt.Errorf("plain")); r != nil {
+		t.Errorf("plain error should have no remediation, got %+v", r)
+	}
+
+	wrapped := fmt.Errorf("install: %w", New(ErrorTypeApt, &remediableError{msg: "no space"}))
+	r := RemediationOf(wrapped)
+	if r == nil || r.Action != RemediationFreeDiskSpace {
+		t.Errorf("expected free_disk_space remediation, got %+v", r)
+	}
+}
+
+func TestDBusError_Remediation(t *testing.T) {
+	dbusErr := DBusError(New(ErrorTypeApt, &remediableError{msg: "no space"}))
+	if len(dbusErr.Body) != 3 {
+		t.Fatalf("expected 3 body elements, got %d", len(dbusErr.Body))
+	}
+	if dbusErr.Body[1] != RemediationFreeDiskSpace {
+		t.Errorf("expected action in body, got %v", dbusErr.Body[1])
+	}
+}
@@ -17,6 +17,7 @@
 package app
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 )
@@ -26,6 +27,11 @@ var (
 	Log LoggerImpl
 	T_  func(string) string
 	TN_ func(string, string, int) string
+	// TL_ и TLN_ переводят строку на язык клиента из ctx (WithLocale), без него на язык процесса
+	TL_  func(context.Context, string) string
+	TLN_ func(context.Context, string, string, int) string
+	// SetLanguage переключает язык сообщений процесса (--lang)
+	SetLanguage func(string)
 )
 
 // Инициализируем функции переводов и логирования автоматически при импорте модуля для тестов
@@ -41,6 +47,17 @@ func init() {
 			return plural
 		}
 	}
+	if TL_ == nil {
+		TL_ = func(_ context.Context, s string) string { return T_(s) }
+	}
+	if TLN_ == nil {
+		TLN_ = func(_ context.Context, single string, plural string, count int) string {
+			return TN_(single, plural, count)
+		}
+	}
+	if SetLanguage == nil {
+		SetLanguage = func(string) {}
+	}
 	if Log == nil {
 		Log = &testLogger{}
 	}
@@ -49,15 +66,16 @@ func init() {
 // testLogger простая реализация LoggerImpl для тестов
 type testLogger struct{}
 
-func (l *testLogger) Debug(...interface{})          {}
-func (l *testLogger) Debugf(string, ...interface{}) {}
-func (l *testLogger) Info(...interface{})           {}
-func (l *testLogger) Warn(...interface{})           {}
-func (l *testLogger) Error(...interface{})          {}
-func (l *testLogger) Errorf(string, ...interface{}) {}
-func (l *testLogger) Fatal(...interface{})          {}
-func (l *testLogger) Warning(...interface{})        {}
-func (l *testLogger) EnableStdoutLogging()          {}
+func (l *testLogger) Debug(...interface{})                         {}
+func (l *testLogger) Debugf(string, ...interface{})                {}
+func (l *testLogger) Info(...interface{})                          {}
+func (l *testLogger) Warn(...interface{})                          {}
+func (l *testLogger) Error(...interface{})                         {}
+func (l *testLogger) Errorf(string, ...interface{})                {}
+func (l *testLogger) Fatal(...interface{})                         {}
+func (l *testLogger) Warning(...interface{})                       {}
+func (l *testLogger) EnableStdoutLogging()                         {}
+func (l *testLogger) InfoFields(map[string]string, ...interface{}) {}
 
 // LoggerImpl интерфейс для логирования
 type LoggerImpl interface {
@@ -69,6 +87,7 @@ type LoggerImpl interface {
 	Errorf(format string, args ...interface{})
 	Fatal(args ...interface{})
 	Warning(args ...interface{})
+	InfoFields(fields map[string]string, args ...interface{})
 	EnableStdoutLogging()
 }
 
@@ -83,6 +102,9 @@ type DatabaseManager interface {
 type Translator interface {
 	T_(messageID string) string
 	TN_(messageID string, pluralMessageID string, count int) string
+	TL_(ctx context.Context, messageID string) string
+	TLN_(ctx context.Context, messageID string, pluralMessageID string, count int) string
+	SetLanguage(lang string)
 }
 
 // Config централизованный конфиг приложение
@@ -122,6 +144,9 @@ func InitializeApp(buildInfo BuildInfo) (*Config, error) {
 	translator := NewTranslator(config.PathLocales)
 	T_ = translator.T_
 	TN_ = translator.TN_
+	TL_ = translator.TL_
+	TLN_ = translator.TLN_
+	SetLanguage = translator.SetLanguage
 
 	dbManager := NewDatabaseManager(
 		config.PathDBSQLSystem,
@@ -129,6 +154,9 @@ func InitializeApp(buildInfo BuildInfo) (*Config, error) {
 	)
 
 	dbusManager := NewDBusManager()
+	if dbSync, ok := dbManager.(DatabaseSync); ok {
+		dbSync.SetChangeNotifier(DBusChangeNotifier(dbusManager))
+	}
 	appConfig := NewAppConfig(dbManager, configManager, dbusManager)
 
 	return appConfig, nil
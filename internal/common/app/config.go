@@ -40,6 +40,7 @@ type Manager interface {
 	SetFormatType(formatType string)
 	SetFields(fields []string)
 	EnableVerbose()
+	EnableAccessible()
 	GetTemporaryImageFile() string
 	GetPathImageContainerFile() string
 	GetPathImageFile() string
@@ -76,6 +77,86 @@ type Colors struct {
 	ProgressFilled string `yaml:"progressFilled"`
 }
 
+// KernelPolicy политика выбора flavour при обновлении ядра
+type KernelPolicy struct {
+	// PreferredFlavour flavour, на который нужно переходить при обновлении
+	PreferredFlavour string `yaml:"preferredFlavour"`
+	// AutoSwitch разрешает автоматический переход на другой flavour
+	AutoSwitch bool `yaml:"autoSwitch"`
+}
+
+// Политики автоматического обновления системы
+const (
+	AutoUpgradeSecurity = "security"
+	AutoUpgradeAll      = "all"
+	AutoUpgradeNone     = "none"
+)
+
+// AutoUpgradePolicy настройки автоматического обновления (apm system auto-upgrade)
+type AutoUpgradePolicy struct {
+	// Policy какие обновления применять: security, all или none (только проверка и уведомление)
+	Policy string `yaml:"policy"`
+	// OnCalendar расписание таймера systemd в формате OnCalendar
+	OnCalendar string `yaml:"onCalendar"`
+	// Notify уведомлять пользователей в графических сеансах о необходимости перезагрузки
+	Notify bool `yaml:"notify"`
+}
+
+// HTTPRole роль HTTP API: право доступа и субъекты, которым оно выдаётся
+type HTTPRole struct {
+	// Name имя роли, возвращается в /api/v1/auth/whoami
+	Name string `yaml:"name"`
+	// Permission право роли: read или manage
+	Permission string `yaml:"permission"`
+	// Tokens статические токены, передаваемые в заголовке Authorization
+	Tokens []string `yaml:"tokens"`
+	// Users имена или UID пользователей, подключающихся через unix-сокет
+	Users []string `yaml:"users"`
+	// Groups имена или GID групп, подключающихся через unix-сокет
+	Groups []string `yaml:"groups"`
+}
+
+// HTTPSocket unix-сокет HTTP API системного сервиса (apm http-server)
+type HTTPSocket struct {
+	// Path путь сокета; пустой — сокет не создаётся
+	Path string `yaml:"path"`
+	// Mode права доступа к файлу сокета в восьмеричной записи, по умолчанию 0666
+	Mode string `yaml:"mode"`
+	// Group группа-владелец файла сокета
+	Group string `yaml:"group"`
+	// Permission право подключившихся пользователей без роли: read, manage или пустое (доступ только по ролям)
+	Permission string `yaml:"permission"`
+	// SocketOnly слушать только сокет, не открывая TCP-порт
+	SocketOnly bool `yaml:"socketOnly"`
+}
+
+// NetworkSettings сетевые настройки: ограничение скорости загрузки и прокси (apm config set network.*)
+type NetworkSettings struct {
+	// Limit ограничение скорости загрузки в байтах в секунду с суффиксом K, M или G, например 2M
+	Limit string `yaml:"limit" json:"limit"`
+	// HTTPProxy прокси для http-запросов
+	HTTPProxy string `yaml:"httpProxy" json:"httpProxy"`
+	// HTTPSProxy прокси для https-запросов
+	HTTPSProxy string `yaml:"httpsProxy" json:"httpsProxy"`
+	// SocksProxy SOCKS5-прокси для схем без отдельного прокси; в APT применяется только к https-источникам
+	SocksProxy string `yaml:"socksProxy" json:"socksProxy"`
+}
+
+// Режимы определения атомарной системы
+const (
+	AtomicModeAuto = "auto"
+	AtomicModeOn   = "on"
+	AtomicModeOff  = "off"
+)
+
+// ModuleToggles включает и отключает модули apm, даже если их инструменты установлены
+type ModuleToggles struct {
+	// Distrobox команды и сервисы distrobox
+	Distrobox bool `yaml:"distrobox"`
+	// Stplr пакеты из репозитория STPLR
+	Stplr bool `yaml:"stplr"`
+}
+
 const defaultConfigPath = "/etc/apm/config.yml"
 
 // Константы форматов вывода
@@ -98,13 +179,54 @@ type Configuration struct {
 	Environment     string `yaml:"environment"`
 	PathDBSQLSystem string `yaml:"pathDBSQLSystem"`
 	PathDBSQLUser   string `yaml:"pathDBSQLUser"`
+	PathStateDir    string `yaml:"pathStateDir"`
+	PathCacheDir    string `yaml:"pathCacheDir"`
 	PathLocales     string `yaml:"pathLocales"`
 	Colors          Colors `yaml:"colors"`
 	FormatType      string `yaml:"formatType"`
 
+	// AtomicMode определение атомарной системы: auto (по наличию bootc), on или off
+	AtomicMode string `yaml:"atomicMode"`
+
+	// Accessible заменяет анимацию спиннеров периодическими текстовыми строками состояния
+	Accessible         bool `yaml:"accessible"`
+	AccessibleInterval int  `yaml:"accessibleInterval"`
+
+	SelfUpdateChannel       string `yaml:"selfUpdateChannel"`
+	SelfUpdateTestingSource string `yaml:"selfUpdateTestingSource"`
+	PolkitFallback          bool   `yaml:"polkitFallback"`
+	VerifyDownloads         bool   `yaml:"verifyDownloads"`
+	// NonInteractiveAnswer ответ на диалоги с флагом --non-interactive: yes или no
+	NonInteractiveAnswer string `yaml:"nonInteractiveAnswer"`
+	// WatchInterval интервал обновления базы пакетов в apm system watch, в минутах
+	WatchInterval int `yaml:"watchInterval"`
+
+	Kernel            KernelPolicy      `yaml:"kernel"`
+	AutoUpgrade       AutoUpgradePolicy `yaml:"autoUpgrade"`
+	RestartBlacklist  []string          `yaml:"restartBlacklist"`
+	ProtectedPackages []string          `yaml:"protectedPackages"`
+
+	// HTTPRoles роли доступа к HTTP API
+	HTTPRoles []HTTPRole `yaml:"httpRoles"`
+	// HTTPSocket unix-сокет HTTP API
+	HTTPSocket HTTPSocket `yaml:"httpSocket"`
+	// HTTPListen адрес TCP системного HTTP API (apm http-server) по умолчанию
+	HTTPListen string `yaml:"httpListen"`
+
+	// Modules переключатели модулей
+	Modules ModuleToggles `yaml:"modules"`
+
+	// Network ограничение скорости и прокси для загрузок apm и APT
+	Network NetworkSettings `yaml:"network"`
+
+	// ContainerStorage каталог хранилища контейнеров distrobox по умолчанию для новых пользователей
+	ContainerStorage string `yaml:"containerStorage"`
+
 	PathContainerFile string `yaml:"-"`
 	PathImageFile     string `yaml:"pathImageFile"`
 	PathResourcesDir  string `yaml:"pathResourcesDir"`
+	PathBuildLogs     string `yaml:"pathBuildLogs"`
+	PathHooksDir      string `yaml:"pathHooksDir"`
 	Version           string `yaml:"-"`
 
 	ParsedVersion *version.Version `yaml:"-"`
@@ -123,14 +245,34 @@ type Configuration struct {
 type configManagerImpl struct {
 	config     *Configuration
 	configPath string
+	defaults   defaultLocations
+}
+
+// DefaultConfiguration возвращает конфигурацию со значениями по умолчанию
+func DefaultConfiguration() *Configuration {
+	return &Configuration{
+		Colors:                  GetDefaultColors(),
+		FormatType:              FormatTypeTree,
+		AccessibleInterval:      5,
+		AtomicMode:              AtomicModeAuto,
+		SelfUpdateChannel:       "stable",
+		SelfUpdateTestingSource: "sisyphus",
+		PolkitFallback:          true,
+		NonInteractiveAnswer:    "no",
+		VerifyDownloads:         true,
+		WatchInterval:           60,
+		Kernel:                  KernelPolicy{AutoSwitch: true},
+		AutoUpgrade:             AutoUpgradePolicy{Policy: AutoUpgradeSecurity, OnCalendar: "daily", Notify: true},
+		RestartBlacklist:        GetDefaultRestartBlacklist(),
+		ProtectedPackages:       GetDefaultProtectedPackages(),
+		Modules:                 ModuleToggles{Distrobox: true, Stplr: true},
+		PathHooksDir:            "/etc/apm/hooks.d",
+	}
 }
 
 // NewConfigManager создает новый менеджер конфигурации
 func NewConfigManager(buildInfo BuildInfo) (Manager, error) {
-	cfg := &Configuration{
-		Colors:     GetDefaultColors(),
-		FormatType: FormatTypeTree,
-	}
+	cfg := DefaultConfiguration()
 
 	cm := &configManagerImpl{
 		config: cfg,
@@ -147,8 +289,6 @@ func NewConfigManager(buildInfo BuildInfo) (Manager, error) {
 func (cm *configManagerImpl) loadConfiguration(buildInfo BuildInfo) error {
 	cm.applyBuildInfo(buildInfo)
 
-	cm.config.PathDBSQLUser = "~/.cache/apm/apm.db"
-
 	// Устанавливаем дефолт для системной БД если не задан через build, тесты будут использовать этот путь
 	if cm.config.PathDBSQLSystem == "" {
 		cm.config.PathDBSQLSystem = filepath.Join(os.TempDir(), "apm-system.db")
@@ -156,6 +296,14 @@ func (cm *configManagerImpl) loadConfiguration(buildInfo BuildInfo) error {
 	if cm.config.PathResourcesDir == "" {
 		cm.config.PathResourcesDir = filepath.Join(os.TempDir(), "apm-resources")
 	}
+	if cm.config.PathBuildLogs == "" {
+		cm.config.PathBuildLogs = defaultBuildLogsDir
+	}
+	defaults := defaultLocations{
+		systemDB:  cm.config.PathDBSQLSystem,
+		buildLogs: cm.config.PathBuildLogs,
+		userDB:    expandUser(legacyUserDBPath),
+	}
 
 	if err := cm.loadConfigFile(); err != nil {
 		return err
@@ -164,7 +312,10 @@ func (cm *configManagerImpl) loadConfiguration(buildInfo BuildInfo) error {
 	// Определяем режим разработки
 	cm.config.DevMode = cm.config.Environment != "prod"
 
+	cm.defaults = defaults
+	cm.resolveLocations(defaults)
 	cm.expandPaths()
+	cm.relocateData()
 
 	if err := cm.ensureDirectories(); err != nil {
 		return err
@@ -228,6 +379,9 @@ func (cm *configManagerImpl) loadConfigFile() error {
 func (cm *configManagerImpl) expandPaths() {
 	cm.config.PathDBSQLUser = filepath.Clean(expandUser(cm.config.PathDBSQLUser))
 	cm.config.PathDBSQLSystem = filepath.Clean(expandUser(cm.config.PathDBSQLSystem))
+	if cm.config.ContainerStorage != "" {
+		cm.config.ContainerStorage = filepath.Clean(expandUser(cm.config.ContainerStorage))
+	}
 }
 
 // ensureDirectories создает необходимые директории
@@ -250,9 +404,16 @@ func (cm *configManagerImpl) ensureDirectories() error {
 
 // detectSystemCapabilities определяет доступные системные утилиты
 func (cm *configManagerImpl) detectSystemCapabilities() {
-	cm.config.IsAtomic = fileExists("/usr/bin/bootc")
-	cm.config.ExistStplr = fileExists("/usr/bin/stplr")
-	cm.config.ExistDistrobox = fileExists("/usr/bin/distrobox")
+	switch cm.config.AtomicMode {
+	case AtomicModeOn:
+		cm.config.IsAtomic = true
+	case AtomicModeOff:
+		cm.config.IsAtomic = false
+	default:
+		cm.config.IsAtomic = fileExists("/usr/bin/bootc")
+	}
+	cm.config.ExistStplr = cm.config.Modules.Stplr && fileExists("/usr/bin/stplr")
+	cm.config.ExistDistrobox = cm.config.Modules.Distrobox && fileExists("/usr/bin/distrobox")
 }
 
 // parseVersion парсит версию из конфигурации, при ошибке использует "unknown"
@@ -324,12 +485,16 @@ func (cm *configManagerImpl) SaveConfig(config *Configuration) error {
 	cm.config.Colors = config.Colors
 	cm.config.CommandPrefix = config.CommandPrefix
 	cm.config.FormatType = config.FormatType
+	cm.config.Network = config.Network
 	cm.configPath = configPath
 	return nil
 }
 
-// GetConfigPath возвращает путь к файлу конфигурации
+// GetConfigPath возвращает путь к файлу конфигурации, в который сохраняются изменения
 func (cm *configManagerImpl) GetConfigPath() string {
+	if cm.configPath == "" {
+		return defaultConfigPath
+	}
 	return cm.configPath
 }
 
@@ -381,6 +546,51 @@ func (cm *configManagerImpl) EnableVerbose() {
 	Log.EnableStdoutLogging()
 }
 
+// EnableAccessible включает текстовый вывод прогресса без анимации
+func (cm *configManagerImpl) EnableAccessible() {
+	cm.config.Accessible = true
+}
+
+// GetDefaultRestartBlacklist возвращает службы, которые не перезапускаются автоматически после обновления.
+// Их перезапуск завершает пользовательские сеансы или текущую операцию apm.
+func GetDefaultRestartBlacklist() []string {
+	return []string{
+		"apm.service",
+		"dbus.service",
+		"dbus-broker.service",
+		"systemd-logind.service",
+		"display-manager.service",
+		"gdm.service",
+		"sddm.service",
+		"lightdm.service",
+		"user@*.service",
+		"getty@*.service",
+		"serial-getty@*.service",
+	}
+}
+
+// GetDefaultProtectedPackages возвращает пакеты, без которых система не загрузится или
+// не сможет восстановить себя через apm.
+func GetDefaultProtectedPackages() []string {
+	return []string{
+		"apm",
+		"apt",
+		"rpm",
+		"bash",
+		"coreutils",
+		"filesystem",
+		"setup",
+		"glibc",
+		"glibc-core",
+		"glibc-pthread",
+		"systemd",
+		"util-linux",
+		"shadow-utils",
+		"pam",
+		"sudo",
+	}
+}
+
 // GetDefaultColors возвращает цветовую схему по умолчанию
 func GetDefaultColors() Colors {
 	return Colors{
@@ -63,3 +63,27 @@ func TestEnsurePath(t *testing.T) {
 		t.Errorf("second call should not fail: %v", err)
 	}
 }
+
+func TestDetectSystemCapabilities_Overrides(t *testing.T) {
+	cm := &configManagerImpl{config: &Configuration{AtomicMode: AtomicModeOn}}
+	cm.detectSystemCapabilities()
+	if !cm.config.IsAtomic {
+		t.Error("atomicMode on must force atomic mode")
+	}
+	if cm.config.ExistDistrobox || cm.config.ExistStplr {
+		t.Error("disabled modules must not be detected")
+	}
+
+	cm.config.AtomicMode = AtomicModeOff
+	cm.detectSystemCapabilities()
+	if cm.config.IsAtomic {
+		t.Error("atomicMode off must disable atomic mode")
+	}
+}
+
+func TestGetConfigPath_Default(t *testing.T) {
+	cm := &configManagerImpl{config: DefaultConfiguration()}
+	if got := cm.GetConfigPath(); got != defaultConfigPath {
+		t.Errorf("GetConfigPath() = %q, want %q", got, defaultConfigPath)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sync"
 	"syscall"
 
@@ -38,6 +39,8 @@ type databaseManagerImpl struct {
 
 	systemOnce sync.Once
 	userOnce   sync.Once
+
+	notify func(name string)
 }
 
 // NewDatabaseManager создает новый менеджер баз данных
@@ -82,10 +85,13 @@ func (dm *databaseManagerImpl) initSystemDB() error {
 		Log.Warning("System database file not found. It will be created automatically.")
 	}
 
-	db, err := sql.Open("sqlite3", dm.systemPath)
+	// Системную базу изменяет только root, остальные процессы открывают её только для чтения
+	readOnly := syscall.Geteuid() != 0
+	db, err := sql.Open(sqliteDriver, databaseDSN(dm.systemPath, readOnly))
 	if err != nil {
 		return fmt.Errorf(T_("error opening system database: %w"), err)
 	}
+	db.SetMaxIdleConns(maxIdleConns)
 
 	if err = db.Ping(); err != nil {
 		db.Close()
@@ -95,6 +101,14 @@ func (dm *databaseManagerImpl) initSystemDB() error {
 		return fmt.Errorf(T_("error connecting to system database: %w"), err)
 	}
 
+	// Пользователь без прав не может менять системную базу, миграции выполнит root
+	if !readOnly {
+		if err = migrateOnOpen(db, dm.systemPath, systemMigrations); err != nil {
+			db.Close()
+			return err
+		}
+	}
+
 	dm.systemDB = db
 	return nil
 }
@@ -105,16 +119,22 @@ func (dm *databaseManagerImpl) initUserDB() error {
 		Log.Warning("User database file not found. It will be created automatically.")
 	}
 
-	db, err := sql.Open("sqlite3", dm.userPath)
+	db, err := sql.Open(sqliteDriver, databaseDSN(dm.userPath, false))
 	if err != nil {
 		return fmt.Errorf(T_("error opening user database: %w"), err)
 	}
+	db.SetMaxIdleConns(maxIdleConns)
 
 	if err = db.Ping(); err != nil {
 		db.Close()
 		return fmt.Errorf(T_("error connecting to user database: %w"), err)
 	}
 
+	if err = migrateOnOpen(db, dm.userPath, userMigrations); err != nil {
+		db.Close()
+		return err
+	}
+
 	dm.userDB = db
 	return nil
 }
@@ -140,3 +160,181 @@ func (dm *databaseManagerImpl) Close() error {
 
 	return nil
 }
+
+// migrateOnOpen применяет миграции при открытии базы. При ошибке транзакция откатывается,
+// а резервная копия остаётся рядом с файлом базы.
+func migrateOnOpen(db *sql.DB, path string, migrations []Migration) error {
+	backup, err := applyMigrations(db, path, migrations)
+	if err != nil {
+		if backup != "" {
+			return fmt.Errorf(T_("database migration error for %s: %w. Backup saved to %s, run 'apm db migrate' to retry"), path, err, backup)
+		}
+		return fmt.Errorf(T_("database migration error for %s: %w. Run 'apm db migrate' to retry"), path, err)
+	}
+	return nil
+}
+
+// databaseTarget файл базы данных вместе с его миграциями
+type databaseTarget struct {
+	name       string
+	path       string
+	migrations []Migration
+}
+
+// targets возвращает обслуживаемые базы данных
+func (dm *databaseManagerImpl) targets() []databaseTarget {
+	return []databaseTarget{
+		{name: DatabaseSystem, path: dm.systemPath, migrations: systemMigrations},
+		{name: DatabaseUser, path: dm.userPath, migrations: userMigrations},
+	}
+}
+
+// writableTargets возвращает базы, которые текущий пользователь может изменять:
+// системная база доступна только root
+func (dm *databaseManagerImpl) writableTargets() []databaseTarget {
+	targets := dm.targets()
+	if syscall.Geteuid() != 0 {
+		return targets[1:]
+	}
+	return targets
+}
+
+// DatabaseStatus возвращает версию схемы и состояние каждой базы данных.
+func (dm *databaseManagerImpl) DatabaseStatus() ([]DatabaseStatus, error) {
+	var statuses []DatabaseStatus
+	for _, target := range dm.targets() {
+		status, err := readStatus(target)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// MigrateDatabases применяет недостающие миграции ко всем базам данных.
+func (dm *databaseManagerImpl) MigrateDatabases() ([]DatabaseStatus, error) {
+	var statuses []DatabaseStatus
+	for _, target := range dm.writableTargets() {
+		db, err := openTarget(target)
+		if err != nil {
+			return nil, err
+		}
+		backup, err := applyMigrations(db, target.path, target.migrations)
+		db.Close()
+		if err != nil {
+			return nil, fmt.Errorf(T_("database migration error for %s: %w"), target.path, err)
+		}
+
+		status, err := readStatus(target)
+		if err != nil {
+			return nil, err
+		}
+		status.Backup = backup
+		statuses = append(statuses, status)
+		if backup != "" {
+			dm.NotifyChanged(target.name)
+		}
+	}
+	return statuses, nil
+}
+
+// VacuumDatabases сжимает базы данных. Повреждённая база переименовывается в <path>.corrupt
+// и создаётся заново, данные о пакетах восстановит следующее обновление. Другие процессы
+// получают уведомление и переоткрывают базу.
+func (dm *databaseManagerImpl) VacuumDatabases() ([]VacuumResult, error) {
+	var results []VacuumResult
+	for _, target := range dm.writableTargets() {
+		info, err := os.Stat(target.path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		result := VacuumResult{Name: target.name, Path: target.path, SizeBefore: info.Size()}
+
+		db, err := openTarget(target)
+		if err != nil {
+			return nil, err
+		}
+		if checkIntegrity(db) {
+			_, err = db.Exec(`VACUUM`)
+			db.Close()
+			if err != nil {
+				return nil, fmt.Errorf(T_("failed to vacuum database %s: %w"), target.path, err)
+			}
+		} else {
+			db.Close()
+			result.Backup = target.path + ".corrupt"
+			if err = os.Rename(target.path, result.Backup); err != nil {
+				return nil, fmt.Errorf(T_("failed to move corrupted database %s: %w"), target.path, err)
+			}
+			if db, err = openTarget(target); err != nil {
+				return nil, err
+			}
+			_, err = applyMigrations(db, target.path, target.migrations)
+			db.Close()
+			if err != nil {
+				return nil, fmt.Errorf(T_("database migration error for %s: %w"), target.path, err)
+			}
+			result.Recovered = true
+		}
+
+		if info, err = os.Stat(target.path); err == nil {
+			result.SizeAfter = info.Size()
+		}
+		results = append(results, result)
+
+		// Файл базы мог быть пересоздан: текущий и другие процессы должны открыть его заново
+		dm.Invalidate(target.name)
+		dm.NotifyChanged(target.name)
+	}
+	return results, nil
+}
+
+// openTarget открывает файл базы данных отдельным подключением для обслуживания
+func openTarget(target databaseTarget) (*sql.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(target.path), 0755); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open(sqliteDriver, databaseDSN(target.path, false))
+	if err != nil {
+		return nil, fmt.Errorf(T_("error opening database %s: %w"), target.path, err)
+	}
+	return db, nil
+}
+
+// readStatus читает состояние базы данных, не создавая файл
+func readStatus(target databaseTarget) (DatabaseStatus, error) {
+	status := DatabaseStatus{
+		Name:   target.name,
+		Path:   target.path,
+		Latest: latestVersion(target.migrations),
+	}
+
+	info, err := os.Stat(target.path)
+	if os.IsNotExist(err) {
+		status.Pending = len(target.migrations)
+		return status, nil
+	}
+	if err != nil {
+		return status, err
+	}
+	status.Exists = true
+	status.Size = info.Size()
+
+	db, err := sql.Open(sqliteDriver, databaseDSN(target.path, true))
+	if err != nil {
+		return status, fmt.Errorf(T_("error opening database %s: %w"), target.path, err)
+	}
+	defer db.Close()
+
+	status.Healthy = checkIntegrity(db)
+	if status.Version, err = schemaVersion(db); err != nil {
+		return status, nil
+	}
+	status.Pending = len(pendingMigrations(target.migrations, status.Version))
+	return status, nil
+}
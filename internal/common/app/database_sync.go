@@ -0,0 +1,248 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/mattn/go-sqlite3"
+)
+
+const (
+	// DatabaseSystem системная база: пишет только root (системный сервис и команды с правами root)
+	DatabaseSystem = "system"
+	// DatabaseUser пользовательская база: общая для сессионного сервиса и команд пользователя
+	DatabaseUser = "user"
+
+	// DatabaseChangedSignal сигнал DBus, которым процесс сообщает остальным об изменении базы
+	DatabaseChangedSignal = "org.altlinux.APM.DatabaseChanged"
+
+	// busyTimeout время ожидания блокировки базы другим процессом внутри SQLite
+	busyTimeout = 5 * time.Second
+	// busyRetries количество повторов транзакции, если база осталась заблокированной
+	busyRetries = 5
+	// maxIdleConns количество простаивающих подключений в пуле
+	maxIdleConns = 2
+
+	// sqliteDriver драйвер SQLite, сохраняющий файлы WAL после закрытия базы: без них процессы
+	// без прав на запись в каталог базы не смогут открыть её только для чтения
+	sqliteDriver = "sqlite3_apm"
+)
+
+var databaseObjectPath = dbus.ObjectPath("/org/altlinux/APM")
+
+// sqlFunctions функции Go, доступные в SQL-запросах каждого подключения к базам
+var sqlFunctions = map[string]any{}
+
+func init() {
+	sql.Register(sqliteDriver, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			// Для баз в памяти и только для чтения параметр не применяется, это не ошибка
+			_ = conn.SetFileControlInt("main", sqlite3.SQLITE_FCNTL_PERSIST_WAL, 1)
+			for name, impl := range sqlFunctions {
+				if err := conn.RegisterFunc(name, impl, true); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
+
+// RegisterSQLFunction делает чистую функцию Go доступной в SQL-запросах под именем name.
+// Вызывается из init пакетов до открытия баз.
+func RegisterSQLFunction(name string, impl any) {
+	sqlFunctions[name] = impl
+}
+
+// DatabaseSync согласование баз данных между процессами: сброс подключений и уведомления об изменениях
+type DatabaseSync interface {
+	// Invalidate закрывает простаивающие подключения к базе, следующие запросы откроют файл заново
+	Invalidate(name string)
+	// NotifyChanged сообщает другим процессам, что база была изменена
+	NotifyChanged(name string)
+	// SetChangeNotifier задаёт способ доставки уведомлений об изменениях
+	SetChangeNotifier(fn func(name string))
+}
+
+// databaseDSN формирует строку подключения SQLite. Записывающие подключения работают в режиме WAL
+// и сразу берут блокировку на запись, чтобы ожидание занятой базы учитывало busy timeout.
+// Подключения только для чтения не меняют файл базы.
+func databaseDSN(path string, readOnly bool) string {
+	params := url.Values{}
+	params.Set("_busy_timeout", fmt.Sprint(busyTimeout.Milliseconds()))
+	if readOnly {
+		params.Set("mode", "ro")
+		params.Set("_query_only", "1")
+	} else {
+		params.Set("_journal_mode", "WAL")
+		params.Set("_txlock", "immediate")
+	}
+	return "file:" + path + "?" + params.Encode()
+}
+
+// IsReadOnly сообщает, что подключение открыто только для чтения. Такие подключения не меняют
+// схему: таблицы создают миграции процесса с правами на запись.
+func IsReadOnly(db *sql.DB) bool {
+	var queryOnly int
+	if err := db.QueryRow("PRAGMA query_only").Scan(&queryOnly); err != nil {
+		return false
+	}
+	return queryOnly == 1
+}
+
+// IsBusy сообщает, что операция не выполнена из-за блокировки базы другим процессом.
+func IsBusy(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+	}
+	return false
+}
+
+// RetryBusy выполняет запись в базу и повторяет её с нарастающей паузой, пока база занята другим процессом.
+func RetryBusy(ctx context.Context, fn func() error) error {
+	delay := 100 * time.Millisecond
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !IsBusy(err) || attempt >= busyRetries {
+			return err
+		}
+
+		Log.Debugf("database is busy, retrying in %s (attempt %d/%d)", delay, attempt, busyRetries)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// NotifyDatabaseChanged сообщает другим процессам об изменении базы, если менеджер это поддерживает.
+func NotifyDatabaseChanged(dm DatabaseManager, name string) {
+	if dbSync, ok := dm.(DatabaseSync); ok {
+		dbSync.NotifyChanged(name)
+	}
+}
+
+// Invalidate закрывает простаивающие подключения к базе name.
+func (dm *databaseManagerImpl) Invalidate(name string) {
+	dm.mutex.Lock()
+	defer dm.mutex.Unlock()
+
+	db := dm.userDB
+	if name == DatabaseSystem {
+		db = dm.systemDB
+	}
+	if db == nil {
+		return
+	}
+
+	db.SetMaxIdleConns(0)
+	db.SetMaxIdleConns(maxIdleConns)
+	Log.Debugf("%s database connections invalidated", name)
+}
+
+// NotifyChanged отправляет уведомление об изменении базы name.
+func (dm *databaseManagerImpl) NotifyChanged(name string) {
+	dm.mutex.Lock()
+	notify := dm.notify
+	dm.mutex.Unlock()
+
+	if notify != nil {
+		notify(name)
+	}
+}
+
+// SetChangeNotifier задаёт способ доставки уведомлений об изменениях.
+func (dm *databaseManagerImpl) SetChangeNotifier(fn func(name string)) {
+	dm.mutex.Lock()
+	defer dm.mutex.Unlock()
+	dm.notify = fn
+}
+
+// DBusChangeNotifier возвращает уведомитель, отправляющий DatabaseChangedSignal. Сервис отправляет сигнал
+// через своё подключение, команды подключаются к шине на время отправки: системная база — системная шина,
+// пользовательская — сессионная. Недоступность шины не считается ошибкой.
+func DBusChangeNotifier(dbusManager DBusManager) func(name string) {
+	return func(name string) {
+		emitSignal(dbusManager, name == DatabaseSystem, DatabaseChangedSignal, name, uint32(os.Getpid()))
+	}
+}
+
+// WatchDatabaseChanges принимает уведомления других процессов об изменении баз и сбрасывает
+// подключения к изменённой базе. Работает до отмены контекста.
+func WatchDatabaseChanges(ctx context.Context, conn *dbus.Conn, dm DatabaseManager) {
+	dbSync, ok := dm.(DatabaseSync)
+	if !ok || conn == nil {
+		return
+	}
+
+	matchOptions := []dbus.MatchOption{
+		dbus.WithMatchObjectPath(databaseObjectPath),
+		dbus.WithMatchInterface("org.altlinux.APM"),
+		dbus.WithMatchMember("DatabaseChanged"),
+	}
+	if err := conn.AddMatchSignalContext(ctx, matchOptions...); err != nil {
+		Log.Debugf("failed to subscribe to database notifications: %v", err)
+		return
+	}
+	defer func() { _ = conn.RemoveMatchSignal(matchOptions...) }()
+
+	signals := make(chan *dbus.Signal, 16)
+	conn.Signal(signals)
+	defer conn.RemoveSignal(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig, ok := <-signals:
+			if !ok {
+				return
+			}
+			if name, changed := decodeDatabaseChanged(sig); changed {
+				dbSync.Invalidate(name)
+			}
+		}
+	}
+}
+
+// decodeDatabaseChanged разбирает сигнал об изменении базы. Собственные уведомления процесса пропускаются.
+func decodeDatabaseChanged(sig *dbus.Signal) (string, bool) {
+	if sig == nil || sig.Name != DatabaseChangedSignal || len(sig.Body) < 2 {
+		return "", false
+	}
+
+	name, ok := sig.Body[0].(string)
+	if !ok || (name != DatabaseSystem && name != DatabaseUser) {
+		return "", false
+	}
+	if pid, isPid := sig.Body[1].(uint32); isPid && int(pid) == os.Getpid() {
+		return "", false
+	}
+
+	return name, true
+}
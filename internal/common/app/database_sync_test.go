@@ -0,0 +1,128 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/mattn/go-sqlite3"
+)
+
+func TestDatabaseDSNModes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "apm.db")
+
+	db, err := sql.Open(sqliteDriver, databaseDSN(path, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var mode string
+	if err = db.QueryRow(`PRAGMA journal_mode`).Scan(&mode); err != nil {
+		t.Fatal(err)
+	}
+	if mode != "wal" {
+		t.Errorf("expected WAL journal mode, got %q", mode)
+	}
+	if _, err = db.Exec(`CREATE TABLE packages (name TEXT)`); err != nil {
+		t.Fatal(err)
+	}
+
+	ro, err := sql.Open(sqliteDriver, databaseDSN(path, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ro.Close()
+
+	var count int
+	if err = ro.QueryRow(`SELECT COUNT(*) FROM packages`).Scan(&count); err != nil {
+		t.Fatalf("expected read-only connection to read, got %v", err)
+	}
+	if _, err = ro.Exec(`INSERT INTO packages (name) VALUES ('vim')`); err == nil {
+		t.Error("expected read-only connection to reject writes")
+	}
+	if !IsReadOnly(ro) || IsReadOnly(db) {
+		t.Error("expected only the read-only connection to be reported as read-only")
+	}
+}
+
+func TestRetryBusy(t *testing.T) {
+	busy := sqlite3.Error{Code: sqlite3.ErrBusy}
+
+	calls := 0
+	err := RetryBusy(context.Background(), func() error {
+		calls++
+		if calls < 2 {
+			return busy
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Errorf("expected success on second attempt, got %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	other := errors.New("constraint failed")
+	if err = RetryBusy(context.Background(), func() error {
+		calls++
+		return other
+	}); !errors.Is(err, other) || calls != 1 {
+		t.Errorf("expected non-busy error without retries, got %v after %d calls", err, calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls = 0
+	if err = RetryBusy(ctx, func() error {
+		calls++
+		return busy
+	}); !IsBusy(err) || calls != 1 {
+		t.Errorf("expected canceled context to stop retries, got %v after %d calls", err, calls)
+	}
+}
+
+func TestDecodeDatabaseChanged(t *testing.T) {
+	other := uint32(os.Getpid() + 1)
+
+	name, ok := decodeDatabaseChanged(&dbus.Signal{Name: DatabaseChangedSignal, Body: []interface{}{DatabaseSystem, other}})
+	if !ok || name != DatabaseSystem {
+		t.Errorf("expected system database change, got %q %v", name, ok)
+	}
+
+	if _, ok = decodeDatabaseChanged(&dbus.Signal{Name: DatabaseChangedSignal, Body: []interface{}{DatabaseUser, uint32(os.Getpid())}}); ok {
+		t.Error("expected own notification to be ignored")
+	}
+	if _, ok = decodeDatabaseChanged(&dbus.Signal{Name: DatabaseChangedSignal, Body: []interface{}{"other", other}}); ok {
+		t.Error("expected unknown database to be ignored")
+	}
+	if _, ok = decodeDatabaseChanged(&dbus.Signal{Name: "org.altlinux.APM.Notification", Body: []interface{}{DatabaseUser, other}}); ok {
+		t.Error("expected other signals to be ignored")
+	}
+}
+
+func TestNotifyAndInvalidate(t *testing.T) {
+	dir := t.TempDir()
+	dm := NewDatabaseManager(filepath.Join(dir, "system.db"), filepath.Join(dir, "user.db"))
+
+	var notified []string
+	dm.(DatabaseSync).SetChangeNotifier(func(name string) { notified = append(notified, name) })
+	NotifyDatabaseChanged(dm, DatabaseUser)
+	if len(notified) != 1 || notified[0] != DatabaseUser {
+		t.Errorf("unexpected notifications: %v", notified)
+	}
+
+	db, err := dm.GetUserDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dm.Close()
+
+	dm.(DatabaseSync).Invalidate(DatabaseUser)
+	if err = db.Ping(); err != nil {
+		t.Errorf("expected database to reopen after invalidation, got %v", err)
+	}
+}
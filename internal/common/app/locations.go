@@ -0,0 +1,268 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+const (
+	// DefaultStateDir каталог состояния apm по умолчанию
+	DefaultStateDir = "/var/lib/apm"
+	// defaultBuildLogsDir каталог журналов сборки образа по умолчанию
+	defaultBuildLogsDir = DefaultStateDir + "/logs"
+	// legacyUserDBPath расположение пользовательской БД до поддержки XDG
+	legacyUserDBPath = "~/.cache/apm/apm.db"
+	// userDBName имя файла пользовательской БД в каталоге кэша
+	userDBName = "apm.db"
+)
+
+// dbSidecarSuffixes служебные файлы SQLite, переносимые вместе с базой
+var dbSidecarSuffixes = []string{"-wal", "-shm", "-journal"}
+
+// defaultLocations расположения данных до применения файла конфигурации
+type defaultLocations struct {
+	systemDB  string
+	buildLogs string
+	userDB    string
+}
+
+// resolveLocations вычисляет пути к БД и журналам из каталогов pathStateDir и pathCacheDir.
+// Явно заданные pathDBSQLSystem, pathDBSQLUser и pathBuildLogs имеют приоритет.
+func (cm *configManagerImpl) resolveLocations(defaults defaultLocations) {
+	cfg := cm.config
+
+	if cfg.PathCacheDir == "" {
+		cfg.PathCacheDir = filepath.Join(xdgDir("XDG_CACHE_HOME", "~/.cache"), "apm")
+	}
+	cfg.PathCacheDir = filepath.Clean(expandUser(cfg.PathCacheDir))
+	if cfg.PathDBSQLUser == "" {
+		cfg.PathDBSQLUser = filepath.Join(cfg.PathCacheDir, userDBName)
+	}
+
+	if cfg.PathStateDir != "" {
+		cfg.PathStateDir = filepath.Clean(expandUser(cfg.PathStateDir))
+		if cfg.PathDBSQLSystem == "" || cfg.PathDBSQLSystem == defaults.systemDB {
+			cfg.PathDBSQLSystem = filepath.Join(cfg.PathStateDir, filepath.Base(defaults.systemDB))
+		}
+		if cfg.PathBuildLogs == "" || cfg.PathBuildLogs == defaults.buildLogs {
+			cfg.PathBuildLogs = filepath.Join(cfg.PathStateDir, filepath.Base(defaults.buildLogs))
+		}
+	}
+	if cfg.PathDBSQLSystem == "" {
+		cfg.PathDBSQLSystem = defaults.systemDB
+	}
+	if cfg.PathBuildLogs == "" {
+		cfg.PathBuildLogs = defaults.buildLogs
+	}
+	cfg.PathBuildLogs = filepath.Clean(expandUser(cfg.PathBuildLogs))
+}
+
+// DataRelocation перенос данных из расположения по умолчанию в расположение из конфигурации
+type DataRelocation struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// DataRelocator переносит данные по явной команде: root переносит системные данные,
+// обычный пользователь - свою БД
+type DataRelocator interface {
+	RelocateData() ([]DataRelocation, error)
+}
+
+// StateDir возвращает каталог состояния системных служб apm с учётом pathStateDir
+func StateDir(cfg *Configuration) string {
+	if cfg.PathStateDir != "" {
+		return cfg.PathStateDir
+	}
+	return DefaultStateDir
+}
+
+// relocateData предупреждает о данных, оставшихся в расположении по умолчанию. Базы открыты
+// службами apm (системная - системными службами, пользовательская - сеансовой), поэтому
+// при загрузке конфигурации данные не переносятся: это делает RelocateData.
+func (cm *configManagerImpl) relocateData() {
+	for _, r := range cm.pendingRelocations() {
+		Log.Warning(fmt.Sprintf("Data is still located in %s. Stop apm services and run 'apm db relocate' to move it to %s", r.From, r.To))
+	}
+}
+
+// pendingRelocations возвращает данные текущего пользователя, оставшиеся в расположении по умолчанию:
+// для root - системную БД и журналы сборки, для остальных - пользовательскую БД
+func (cm *configManagerImpl) pendingRelocations() []DataRelocation {
+	var pending []DataRelocation
+	if syscall.Geteuid() != 0 {
+		if needsRelocation(cm.defaults.userDB, cm.config.PathDBSQLUser) {
+			pending = append(pending, DataRelocation{From: cm.defaults.userDB, To: cm.config.PathDBSQLUser})
+		}
+		return pending
+	}
+
+	if needsRelocation(cm.defaults.systemDB, cm.config.PathDBSQLSystem) {
+		pending = append(pending, DataRelocation{From: cm.defaults.systemDB, To: cm.config.PathDBSQLSystem})
+	}
+	if needsRelocation(cm.defaults.buildLogs, cm.config.PathBuildLogs) {
+		pending = append(pending, DataRelocation{From: cm.defaults.buildLogs, To: cm.config.PathBuildLogs})
+	}
+	return pending
+}
+
+// RelocateData переносит данные из pendingRelocations в расположение из конфигурации.
+// Службы apm, использующие эти данные, должны быть остановлены.
+func (cm *configManagerImpl) RelocateData() ([]DataRelocation, error) {
+	pending := cm.pendingRelocations()
+	for i, r := range pending {
+		var err error
+		if r.From == cm.defaults.buildLogs {
+			err = relocateDir(r.From, r.To)
+		} else {
+			err = relocateDatabase(r.From, r.To)
+		}
+		if err != nil {
+			return pending[:i], err
+		}
+	}
+	return pending, nil
+}
+
+// xdgDir возвращает каталог из переменной окружения XDG или запасной путь
+func xdgDir(env, fallback string) string {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return dir
+	}
+	return fallback
+}
+
+// relocateDatabase переносит файл БД вместе со служебными файлами SQLite.
+// Существующая непустая база по новому пути не перезаписывается.
+func relocateDatabase(from, to string) error {
+	if !needsRelocation(from, to) {
+		return nil
+	}
+	if info, err := os.Stat(to); err == nil && info.Size() > 0 {
+		return fmt.Errorf(T_("database %s already exists, %s is left in place"), to, from)
+	}
+
+	if err := EnsureDir(filepath.Dir(to)); err != nil {
+		return err
+	}
+	if err := moveFile(from, to); err != nil {
+		return err
+	}
+	for _, suffix := range dbSidecarSuffixes {
+		if !fileExists(from + suffix) {
+			continue
+		}
+		if err := moveFile(from+suffix, to+suffix); err != nil {
+			return err
+		}
+	}
+
+	Log.Info(fmt.Sprintf("Database moved from %s to %s", from, to))
+	return nil
+}
+
+// relocateDir переносит содержимое каталога, если новый каталог отсутствует или пуст
+func relocateDir(from, to string) error {
+	if !needsRelocation(from, to) {
+		return nil
+	}
+	if entries, err := os.ReadDir(to); err == nil && len(entries) > 0 {
+		return fmt.Errorf(T_("directory %s is not empty, %s is left in place"), to, from)
+	}
+
+	if err := moveDir(from, to); err != nil {
+		return err
+	}
+
+	Log.Info(fmt.Sprintf("Directory moved from %s to %s", from, to))
+	return nil
+}
+
+// moveDir переносит каталог со всеми подкаталогами и удаляет опустевший исходный каталог
+func moveDir(from, to string) error {
+	entries, err := os.ReadDir(from)
+	if err != nil {
+		return err
+	}
+	if err = EnsureDir(to); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		src, dst := filepath.Join(from, entry.Name()), filepath.Join(to, entry.Name())
+		if entry.IsDir() {
+			err = moveDir(src, dst)
+		} else {
+			err = moveFile(src, dst)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return os.Remove(from)
+}
+
+// needsRelocation сообщает, что путь изменился и по старому пути есть данные
+func needsRelocation(from, to string) bool {
+	if from == "" || to == "" || filepath.Clean(from) == filepath.Clean(to) {
+		return false
+	}
+	info, err := os.Stat(from)
+	if err != nil {
+		return false
+	}
+	return info.IsDir() || info.Size() > 0
+}
+
+// moveFile переносит файл, копируя его при переносе между файловыми системами
+func moveFile(from, to string) error {
+	err := os.Rename(from, to)
+	if err == nil || !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	dst, err := os.OpenFile(to, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(dst, src); err != nil {
+		_ = dst.Close()
+		_ = os.Remove(to)
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		_ = os.Remove(to)
+		return err
+	}
+
+	return os.Remove(from)
+}
@@ -0,0 +1,180 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolveLocations(t *testing.T) {
+	cache := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cache)
+
+	defaults := defaultLocations{systemDB: "/var/lib/apm/apm.db", buildLogs: defaultBuildLogsDir}
+	cm := &configManagerImpl{config: &Configuration{
+		PathStateDir:    "/srv/apm",
+		PathDBSQLSystem: defaults.systemDB,
+	}}
+	cm.resolveLocations(defaults)
+
+	cfg := cm.config
+	if cfg.PathDBSQLUser != filepath.Join(cache, "apm", "apm.db") {
+		t.Errorf("expected user database in XDG cache, got %s", cfg.PathDBSQLUser)
+	}
+	if cfg.PathDBSQLSystem != "/srv/apm/apm.db" {
+		t.Errorf("expected system database in state dir, got %s", cfg.PathDBSQLSystem)
+	}
+	if cfg.PathBuildLogs != "/srv/apm/logs" {
+		t.Errorf("expected build logs in state dir, got %s", cfg.PathBuildLogs)
+	}
+
+	cm = &configManagerImpl{config: &Configuration{
+		PathStateDir:    "/srv/apm",
+		PathDBSQLSystem: "/data/custom.db",
+		PathCacheDir:    "/data/cache",
+	}}
+	cm.resolveLocations(defaults)
+	if cm.config.PathDBSQLSystem != "/data/custom.db" {
+		t.Errorf("expected explicit database path to win, got %s", cm.config.PathDBSQLSystem)
+	}
+	if cm.config.PathDBSQLUser != "/data/cache/apm.db" {
+		t.Errorf("expected user database in cache dir, got %s", cm.config.PathDBSQLUser)
+	}
+}
+
+func TestRelocateDatabase(t *testing.T) {
+	dir := t.TempDir()
+	from := filepath.Join(dir, "old", "apm.db")
+	to := filepath.Join(dir, "new", "apm.db")
+	writeTestFile(t, from, "data")
+	writeTestFile(t, from+"-wal", "wal")
+
+	if err := relocateDatabase(from, to); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content, err := os.ReadFile(to); err != nil || string(content) != "data" {
+		t.Errorf("expected database moved, got %q: %v", content, err)
+	}
+	if !fileExists(to+"-wal") || fileExists(from) || fileExists(from+"-wal") {
+		t.Errorf("expected database and WAL moved from old location")
+	}
+
+	writeTestFile(t, from, "old")
+	if err := relocateDatabase(from, to); err == nil {
+		t.Fatal("expected error for existing database")
+	}
+	if content, _ := os.ReadFile(to); string(content) != "data" {
+		t.Errorf("expected existing database to be kept, got %q", content)
+	}
+	if !fileExists(from) {
+		t.Errorf("expected old database left in place")
+	}
+}
+
+func TestRelocateDir(t *testing.T) {
+	dir := t.TempDir()
+	from := filepath.Join(dir, "logs")
+	to := filepath.Join(dir, "state", "logs")
+	writeTestFile(t, filepath.Join(from, "build-1.log.gz"), "log")
+	writeTestFile(t, filepath.Join(from, "image", "build-2.log.gz"), "nested")
+
+	if err := relocateDir(from, to); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !fileExists(filepath.Join(to, "build-1.log.gz")) {
+		t.Errorf("expected log moved to new directory")
+	}
+	if content, err := os.ReadFile(filepath.Join(to, "image", "build-2.log.gz")); err != nil || string(content) != "nested" {
+		t.Errorf("expected subdirectory moved, got %q: %v", content, err)
+	}
+	if fileExists(from) {
+		t.Errorf("expected empty old directory removed")
+	}
+}
+
+func TestRelocateDataOnLoadKeepsUserDatabase(t *testing.T) {
+	dir := t.TempDir()
+	cm := &configManagerImpl{
+		defaults: defaultLocations{userDB: filepath.Join(dir, "old", "apm.db")},
+		config:   &Configuration{PathDBSQLUser: filepath.Join(dir, "new", "apm.db")},
+	}
+	writeTestFile(t, cm.defaults.userDB, "data")
+
+	cm.relocateData()
+	if !fileExists(cm.defaults.userDB) || fileExists(cm.config.PathDBSQLUser) {
+		t.Errorf("expected the user database to stay in place until apm db relocate")
+	}
+}
+
+func TestRelocateUserData(t *testing.T) {
+	if syscall.Geteuid() == 0 {
+		t.Skip("root relocates system data")
+	}
+
+	dir := t.TempDir()
+	cm := &configManagerImpl{
+		defaults: defaultLocations{userDB: filepath.Join(dir, "old", "apm.db")},
+		config:   &Configuration{PathDBSQLUser: filepath.Join(dir, "new", "apm.db")},
+	}
+	writeTestFile(t, cm.defaults.userDB, "data")
+
+	moved, err := cm.RelocateData()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(moved) != 1 || moved[0].To != cm.config.PathDBSQLUser {
+		t.Errorf("unexpected relocations: %v", moved)
+	}
+	if !fileExists(cm.config.PathDBSQLUser) || fileExists(cm.defaults.userDB) {
+		t.Errorf("expected user database moved")
+	}
+}
+
+func TestRelocateSystemData(t *testing.T) {
+	if syscall.Geteuid() != 0 {
+		t.Skip("relocation of system data requires root")
+	}
+
+	dir := t.TempDir()
+	cm := &configManagerImpl{
+		defaults: defaultLocations{
+			systemDB:  filepath.Join(dir, "lib", "apm.db"),
+			buildLogs: filepath.Join(dir, "lib", "logs"),
+		},
+		config: &Configuration{
+			PathDBSQLSystem: filepath.Join(dir, "srv", "apm.db"),
+			PathBuildLogs:   filepath.Join(dir, "srv", "logs"),
+		},
+	}
+	writeTestFile(t, cm.defaults.systemDB, "data")
+	writeTestFile(t, filepath.Join(cm.defaults.buildLogs, "build-1.log.gz"), "log")
+
+	if pending := cm.pendingRelocations(); len(pending) != 2 {
+		t.Fatalf("expected database and logs pending, got %v", pending)
+	}
+
+	moved, err := cm.RelocateData()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(moved) != 2 || moved[0].To != cm.config.PathDBSQLSystem || moved[1].To != cm.config.PathBuildLogs {
+		t.Errorf("unexpected relocations: %v", moved)
+	}
+	if !fileExists(cm.config.PathDBSQLSystem) || fileExists(cm.defaults.systemDB) {
+		t.Errorf("expected database moved")
+	}
+	if pending := cm.pendingRelocations(); len(pending) != 0 {
+		t.Errorf("expected nothing pending, got %v", pending)
+	}
+}
@@ -19,11 +19,22 @@ package app
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/coreos/go-systemd/journal"
 	"github.com/sirupsen/logrus"
 )
 
+// Поля структурированного лога. В systemd journal они записываются с префиксом APM_,
+// например APM_MODULE и APM_TRANSACTION, и используются командой apm log для фильтрации.
+const (
+	LogFieldModule      = "module"
+	LogFieldTransaction = "transaction"
+)
+
+// LogIdentifier идентификатор apm в systemd journal
+const LogIdentifier = "apm"
+
 // loggerImpl реализация Logger интерфейса
 type loggerImpl struct {
 	*logrus.Logger
@@ -79,6 +90,17 @@ func (l *loggerImpl) Errorf(format string, args ...interface{}) {
 	l.Logger.Errorf(format, args...)
 }
 
+// InfoFields информационное сообщение с полями структурированного лога. Пустые поля пропускаются.
+func (l *loggerImpl) InfoFields(fields map[string]string, args ...interface{}) {
+	data := make(logrus.Fields, len(fields))
+	for k, v := range fields {
+		if v != "" {
+			data[k] = v
+		}
+	}
+	l.WithFields(data).Info(args...)
+}
+
 // EnableStdoutLogging включает вывод всех логов в stdout
 func (l *loggerImpl) EnableStdoutLogging() {
 	l.stdoutHook.enableAll = true
@@ -110,7 +132,10 @@ func (hook *JournalHook) Fire(entry *logrus.Entry) error {
 	vars := map[string]string{
 		"MESSAGE":           entry.Message,
 		"PRIORITY":          fmt.Sprintf("%d", priority),
-		"SYSLOG_IDENTIFIER": "apm",
+		"SYSLOG_IDENTIFIER": LogIdentifier,
+	}
+	for k, v := range entry.Data {
+		vars["APM_"+strings.ToUpper(k)] = fmt.Sprint(v)
 	}
 
 	return journal.Send(entry.Message, priority, vars)
@@ -0,0 +1,194 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// Migration версионированное изменение схемы базы данных
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(tx *sql.Tx) error
+}
+
+// DatabaseStatus состояние файла базы данных и его схемы
+type DatabaseStatus struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Exists  bool   `json:"exists"`
+	Size    int64  `json:"size"`
+	Version int    `json:"version"`
+	Latest  int    `json:"latest"`
+	Pending int    `json:"pending"`
+	Healthy bool   `json:"healthy"`
+	Backup  string `json:"backup,omitempty"`
+}
+
+// VacuumResult результат обслуживания файла базы данных
+type VacuumResult struct {
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	SizeBefore int64  `json:"sizeBefore"`
+	SizeAfter  int64  `json:"sizeAfter"`
+	Recovered  bool   `json:"recovered"`
+	Backup     string `json:"backup,omitempty"`
+}
+
+// DatabaseMaintenance обслуживание файлов баз данных: версии схемы, миграции и сжатие
+type DatabaseMaintenance interface {
+	DatabaseStatus() ([]DatabaseStatus, error)
+	MigrateDatabases() ([]DatabaseStatus, error)
+	VacuumDatabases() ([]VacuumResult, error)
+}
+
+const createMigrationsTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version INTEGER PRIMARY KEY,
+	description TEXT NOT NULL,
+	applied_at TEXT NOT NULL
+)`
+
+// systemMigrations миграции системной базы. Новые миграции добавляются в конец с возрастающей версией.
+var systemMigrations = []Migration{
+	{Version: 1, Description: "baseline", Up: func(*sql.Tx) error { return nil }},
+	{Version: 2, Description: "transaction journal", Up: migrateTransactionJournal},
+	{Version: 3, Description: "host packages", Up: migrateHostPackages},
+	{Version: 4, Description: "packages search index", Up: migratePackagesSearchIndex},
+	{Version: 5, Description: "appstream components", Up: migrateHostAppStream},
+	{Version: 6, Description: "image build history", Up: migrateHostImageHistory},
+}
+
+// userMigrations миграции пользовательской базы
+var userMigrations = []Migration{
+	{Version: 1, Description: "baseline", Up: func(*sql.Tx) error { return nil }},
+	{Version: 2, Description: "distrobox containers", Up: migrateDistrobox},
+	{Version: 3, Description: "icons", Up: migrateIcons},
+}
+
+// latestVersion возвращает последнюю известную версию схемы
+func latestVersion(migrations []Migration) int {
+	latest := 0
+	for _, m := range migrations {
+		if m.Version > latest {
+			latest = m.Version
+		}
+	}
+	return latest
+}
+
+// schemaVersion возвращает текущую версию схемы. База без таблицы миграций имеет версию 0.
+func schemaVersion(db *sql.DB) (int, error) {
+	var name string
+	err := db.QueryRow(`SELECT name FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'`).Scan(&name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var version sql.NullInt64
+	if err = db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&version); err != nil {
+		return 0, err
+	}
+	return int(version.Int64), nil
+}
+
+// pendingMigrations возвращает непримененные миграции по возрастанию версии
+func pendingMigrations(migrations []Migration, version int) []Migration {
+	var pending []Migration
+	for _, m := range migrations {
+		if m.Version > version {
+			pending = append(pending, m)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Version < pending[j].Version })
+	return pending
+}
+
+// backupDatabase сохраняет согласованную копию базы рядом с файлом: <path>.v<version>.bak
+func backupDatabase(db *sql.DB, path string, version int) (string, error) {
+	backup := fmt.Sprintf("%s.v%d.bak", path, version)
+	if err := os.Remove(backup); err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	if _, err := db.Exec(`VACUUM INTO ?`, backup); err != nil {
+		return "", err
+	}
+	return backup, nil
+}
+
+// applyMigrations применяет недостающие миграции в одной транзакции. Если файл базы уже
+// содержит данные, перед этим создаётся резервная копия. Возвращает путь к копии.
+func applyMigrations(db *sql.DB, path string, migrations []Migration) (string, error) {
+	version, err := schemaVersion(db)
+	if err != nil {
+		return "", fmt.Errorf(T_("failed to read schema version: %w"), err)
+	}
+
+	pending := pendingMigrations(migrations, version)
+	if len(pending) == 0 {
+		return "", nil
+	}
+
+	var backup string
+	if info, errStat := os.Stat(path); errStat == nil && info.Size() > 0 {
+		if backup, err = backupDatabase(db, path, version); err != nil {
+			return "", fmt.Errorf(T_("failed to back up database before migration: %w"), err)
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return backup, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err = tx.Exec(createMigrationsTable); err != nil {
+		return backup, fmt.Errorf(T_("failed to create migrations table: %w"), err)
+	}
+	for _, m := range pending {
+		if err = m.Up(tx); err != nil {
+			return backup, fmt.Errorf(T_("migration %d (%s) failed: %w"), m.Version, m.Description, err)
+		}
+		if _, err = tx.Exec(`INSERT INTO schema_migrations (version, description, applied_at) VALUES (?, ?, ?)`,
+			m.Version, m.Description, time.Now().Format(time.RFC3339)); err != nil {
+			return backup, fmt.Errorf(T_("migration %d (%s) failed: %w"), m.Version, m.Description, err)
+		}
+		Log.Debugf("applied database migration %d (%s) to %s", m.Version, m.Description, path)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return backup, err
+	}
+	return backup, nil
+}
+
+// checkIntegrity выполняет PRAGMA quick_check
+func checkIntegrity(db *sql.DB) bool {
+	var result string
+	if err := db.QueryRow(`PRAGMA quick_check`).Scan(&result); err != nil {
+		return false
+	}
+	return result == "ok"
+}
@@ -0,0 +1,165 @@
+package app
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func openTestDB(t *testing.T, path string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func TestApplyMigrations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "apm.db")
+	db := openTestDB(t, path)
+
+	migrations := []Migration{
+		{Version: 2, Description: "add index", Up: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`CREATE INDEX idx_items_name ON items(name)`)
+			return err
+		}},
+		{Version: 1, Description: "create items", Up: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`CREATE TABLE items (name TEXT)`)
+			return err
+		}},
+	}
+
+	if _, err := applyMigrations(db, path, migrations); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	version, err := schemaVersion(db)
+	if err != nil || version != 2 {
+		t.Fatalf("expected version 2, got %d (%v)", version, err)
+	}
+
+	backup, err := applyMigrations(db, path, migrations)
+	if err != nil {
+		t.Fatalf("unexpected error on second run: %v", err)
+	}
+	if backup != "" {
+		t.Errorf("expected no backup without pending migrations, got %s", backup)
+	}
+}
+
+func TestApplyMigrationsRollback(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "apm.db")
+	db := openTestDB(t, path)
+
+	base := []Migration{{Version: 1, Description: "create items", Up: func(tx *sql.Tx) error {
+		_, err := tx.Exec(`CREATE TABLE items (name TEXT)`)
+		return err
+	}}}
+	if _, err := applyMigrations(db, path, base); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	broken := append(base,
+		Migration{Version: 2, Description: "create other", Up: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`CREATE TABLE other (id INTEGER)`)
+			return err
+		}},
+		Migration{Version: 3, Description: "fail", Up: func(*sql.Tx) error {
+			return errors.New("boom")
+		}},
+	)
+
+	backup, err := applyMigrations(db, path, broken)
+	if err == nil {
+		t.Fatal("expected migration error")
+	}
+	if backup != path+".v1.bak" {
+		t.Errorf("unexpected backup path %q", backup)
+	}
+	if _, errStat := os.Stat(backup); errStat != nil {
+		t.Errorf("expected backup file: %v", errStat)
+	}
+
+	version, _ := schemaVersion(db)
+	if version != 1 {
+		t.Errorf("expected version to stay 1 after rollback, got %d", version)
+	}
+	var name string
+	if errQuery := db.QueryRow(`SELECT name FROM sqlite_master WHERE name = 'other'`).Scan(&name); !errors.Is(errQuery, sql.ErrNoRows) {
+		t.Errorf("expected table from failed batch to be rolled back")
+	}
+}
+
+func TestSchemaMigrations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "apm.db")
+	db := openTestDB(t, path)
+
+	// База, созданная AutoMigrate прежних версий, без колонок, появившихся позже
+	if _, err := db.Exec("CREATE TABLE `host_image_packages` (`name` text,`version` text,`installed` numeric,PRIMARY KEY (`name`,`version`))"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("INSERT INTO host_image_packages (name, version, installed) VALUES ('vim', '9.1', 1)"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := applyMigrations(db, path, systemMigrations); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, table := range []string{"transaction_journal", "host_held_packages", "host_appstream_components", "host_image_history"} {
+		var name string
+		if err := db.QueryRow(`SELECT name FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&name); err != nil {
+			t.Errorf("expected table %s to be created: %v", table, err)
+		}
+	}
+
+	var held sql.NullBool
+	var versionInstalledRaw sql.NullString
+	err := db.QueryRow(`SELECT held, versionInstalledRaw FROM host_image_packages WHERE name = 'vim'`).Scan(&held, &versionInstalledRaw)
+	if err != nil {
+		t.Errorf("expected missing columns to be added to the existing table: %v", err)
+	}
+}
+
+func TestVacuumRecoversCorruptedDatabase(t *testing.T) {
+	dir := t.TempDir()
+	userPath := filepath.Join(dir, "user.db")
+	if err := os.WriteFile(userPath, []byte("definitely not a sqlite database, just garbage bytes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dm := NewDatabaseManager(filepath.Join(dir, "system.db"), userPath).(*databaseManagerImpl)
+
+	statuses, err := dm.DatabaseStatus()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if statuses[1].Healthy {
+		t.Errorf("expected corrupted user database to be reported unhealthy")
+	}
+
+	results, err := dm.VacuumDatabases()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var recovered bool
+	for _, r := range results {
+		if r.Name == "user" {
+			recovered = r.Recovered
+		}
+	}
+	if !recovered {
+		t.Fatalf("expected user database to be recovered, got %+v", results)
+	}
+
+	statuses, err = dm.DatabaseStatus()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !statuses[1].Healthy || statuses[1].Pending != 0 {
+		t.Errorf("expected healthy migrated user database, got %+v", statuses[1])
+	}
+}
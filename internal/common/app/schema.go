@@ -0,0 +1,165 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Схема таблиц, которые раньше создавались только через AutoMigrate при первом обращении сервиса.
+// Таблицы создаются миграциями до открытия базы сервисами, поэтому процессы без прав на запись
+// находят их уже готовыми. Колонки, добавленные после первой версии таблицы, досоздаются в старых базах.
+
+const schemaTransactionJournal = "CREATE TABLE IF NOT EXISTS `transaction_journal` (`id` integer PRIMARY KEY AUTOINCREMENT,`date` datetime,`module` text,`action` text,`targets` text,`installed` text,`upgraded` text,`removed` text,`previous` text,`status` text,`error` text,`user` text,`transaction_id` text)"
+
+const schemaHostImagePackages = "CREATE TABLE IF NOT EXISTS `host_image_packages` (`name` text,`architecture` text,`section` text,`installedSize` integer,`maintainer` text,`version` text,`versionRaw` text,`versionInstalled` text,`versionInstalledRaw` text,`depends` text,`aliases` text,`provides` text,`size` integer,`filename` text,`summary` text,`description` text,`idAppStream` integer,`changelog` text,`installed` numeric,`held` numeric,`typePackage` integer,`files` text,PRIMARY KEY (`name`,`version`))"
+
+const schemaHostHeldPackages = "CREATE TABLE IF NOT EXISTS `host_held_packages` (`name` text,`created` datetime,PRIMARY KEY (`name`))"
+
+const schemaPackagesSearchIndex = "CREATE VIRTUAL TABLE IF NOT EXISTS host_image_packages_fts USING fts5(name, summary, description, keywords, version UNINDEXED, tokenize = 'unicode61 remove_diacritics 2')"
+
+const schemaHostAppStream = "CREATE TABLE IF NOT EXISTS `host_appstream_components` (`id` integer PRIMARY KEY AUTOINCREMENT,`pkgname` text NOT NULL,`components` TEXT)"
+
+const schemaHostImageHistory = "CREATE TABLE IF NOT EXISTS `host_image_history` (`imagename` text,`imagedate` datetime,`config` text,`logpath` text,`packages` text,PRIMARY KEY (`imagename`,`imagedate`))"
+
+const schemaDistroboxPackages = "CREATE TABLE IF NOT EXISTS `distrobox_packages` (`container` text,`name` text,`version` text,`description` text,`installed` numeric,`exporting` numeric,`manager` text,`export_paths` text,PRIMARY KEY (`container`,`name`))"
+
+const schemaDistroboxOsCache = "CREATE TABLE IF NOT EXISTS `distrobox_os_cache` (`id` text,`os` text,`active` numeric,`updated_at` integer,PRIMARY KEY (`id`))"
+
+const schemaDistroboxContainers = "CREATE TABLE IF NOT EXISTS `distrobox_containers` (`container` text,`os` text,`count` integer,`synced_at` integer,PRIMARY KEY (`container`))"
+
+const schemaDistroboxSnapshots = "CREATE TABLE IF NOT EXISTS `distrobox_snapshots` (`id` integer PRIMARY KEY AUTOINCREMENT,`container` text,`name` text,`image` text,`os` text,`created_at` integer)"
+
+const schemaIcons = "CREATE TABLE IF NOT EXISTS `icons` (`package` text,`container` text DEFAULT \"\",`icon` blob NOT NULL,`hash` text NOT NULL DEFAULT \"\",PRIMARY KEY (`package`,`container`))"
+
+// migrateTransactionJournal создаёт журнал транзакций
+func migrateTransactionJournal(tx *sql.Tx) error {
+	return execAll(tx,
+		schemaTransactionJournal,
+		"CREATE INDEX IF NOT EXISTS `idx_transaction_journal_status` ON `transaction_journal`(`status`)",
+		"CREATE INDEX IF NOT EXISTS `idx_transaction_journal_module` ON `transaction_journal`(`module`)",
+		"CREATE INDEX IF NOT EXISTS `idx_transaction_journal_date` ON `transaction_journal`(`date`)",
+	)
+}
+
+// migrateHostPackages создаёт таблицы пакетов и удерживаемых пакетов
+func migrateHostPackages(tx *sql.Tx) error {
+	if err := execAll(tx, schemaHostImagePackages, schemaHostHeldPackages); err != nil {
+		return err
+	}
+	return addMissingColumns(tx, "host_image_packages", "`held` numeric", "`versionInstalledRaw` text")
+}
+
+// migratePackagesSearchIndex создаёт полнотекстовый индекс пакетов. Если SQLite собран без FTS5,
+// индекс не создаётся и поиск по описанию выполняется через LIKE.
+func migratePackagesSearchIndex(tx *sql.Tx) error {
+	if _, err := tx.Exec(schemaPackagesSearchIndex); err != nil {
+		if strings.Contains(err.Error(), "no such module") {
+			Log.Debugf("full-text search is unavailable, skipping the search index: %v", err)
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// migrateHostAppStream создаёт таблицу компонентов AppStream
+func migrateHostAppStream(tx *sql.Tx) error {
+	return execAll(tx,
+		schemaHostAppStream,
+		"CREATE INDEX IF NOT EXISTS `idx_host_appstream_components_pkg_name` ON `host_appstream_components`(`pkgname`)",
+	)
+}
+
+// migrateHostImageHistory создаёт историю сборок образа
+func migrateHostImageHistory(tx *sql.Tx) error {
+	if err := execAll(tx, schemaHostImageHistory); err != nil {
+		return err
+	}
+	return addMissingColumns(tx, "host_image_history", "`logpath` text", "`packages` text")
+}
+
+// migrateDistrobox создаёт таблицы пакетов, кэша и снимков контейнеров distrobox
+func migrateDistrobox(tx *sql.Tx) error {
+	err := execAll(tx,
+		schemaDistroboxPackages,
+		schemaDistroboxOsCache,
+		schemaDistroboxContainers,
+		schemaDistroboxSnapshots,
+		"CREATE UNIQUE INDEX IF NOT EXISTS `idx_distrobox_snapshot` ON `distrobox_snapshots`(`container`,`name`)",
+	)
+	if err != nil {
+		return err
+	}
+	return addMissingColumns(tx, "distrobox_packages", "`export_paths` text")
+}
+
+// migrateIcons создаёт таблицу иконок
+func migrateIcons(tx *sql.Tx) error {
+	if err := execAll(tx, schemaIcons); err != nil {
+		return err
+	}
+	return addMissingColumns(tx, "icons", "`hash` text NOT NULL DEFAULT \"\"")
+}
+
+// execAll выполняет запросы по порядку
+func execAll(tx *sql.Tx, statements ...string) error {
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addMissingColumns добавляет в таблицу колонки, которых в ней ещё нет. Колонка задаётся
+// определением вида "`name` type".
+func addMissingColumns(tx *sql.Tx, table string, columns ...string) error {
+	rows, err := tx.Query(fmt.Sprintf("PRAGMA table_info(`%s`)", table))
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var (
+			cid, notNull, pk int
+			name, colType    string
+			defaultValue     sql.NullString
+		)
+		if err = rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		existing[name] = true
+	}
+	if err = rows.Close(); err != nil {
+		return err
+	}
+
+	for _, column := range columns {
+		name := strings.Trim(strings.Fields(column)[0], "`")
+		if existing[name] {
+			continue
+		}
+		if _, err = tx.Exec(fmt.Sprintf("ALTER TABLE `%s` ADD COLUMN %s", table, column)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
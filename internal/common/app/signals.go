@@ -0,0 +1,74 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import "github.com/godbus/dbus/v5"
+
+const (
+	// PackagesChangedSignal сигнал DBus об установке, обновлении или удалении пакетов системы
+	PackagesChangedSignal = "org.altlinux.APM.PackagesChanged"
+	// ImagePendingSignal сигнал DBus о новом развёртывании атомарного образа, которое загрузится после перезагрузки
+	ImagePendingSignal = "org.altlinux.APM.ImagePending"
+	// KernelInstalledSignal сигнал DBus об установке ядра
+	KernelInstalledSignal = "org.altlinux.APM.KernelInstalled"
+	// RepoChangedSignal сигнал DBus об изменении списка репозиториев
+	RepoChangedSignal = "org.altlinux.APM.RepoChanged"
+)
+
+// StateNotifier отправляет сигнал об изменении состояния системы. Пустой уведомитель ничего не отправляет.
+type StateNotifier func(signal string, args ...interface{})
+
+// Emit отправляет сигнал signal с аргументами args.
+func (n StateNotifier) Emit(signal string, args ...interface{}) {
+	if n != nil {
+		n(signal, args...)
+	}
+}
+
+// DBusStateNotifier возвращает уведомитель, отправляющий сигналы об изменении состояния системы
+// на системную шину, чтобы графические апплеты обновлялись без периодических запросов.
+func DBusStateNotifier(dbusManager DBusManager) StateNotifier {
+	return func(signal string, args ...interface{}) {
+		emitSignal(dbusManager, true, signal, args...)
+	}
+}
+
+// emitSignal отправляет сигнал на объект /org/altlinux/APM. Сервис отправляет его через своё подключение,
+// команды подключаются к шине на время отправки. Недоступность шины не считается ошибкой.
+func emitSignal(dbusManager DBusManager, systemBus bool, signal string, args ...interface{}) {
+	var conn *dbus.Conn
+	if dbusManager != nil {
+		conn = dbusManager.GetConnection()
+	}
+	if conn == nil {
+		var err error
+		if systemBus {
+			conn, err = dbus.ConnectSystemBus()
+		} else {
+			conn, err = dbus.ConnectSessionBus()
+		}
+		if err != nil {
+			Log.Debugf("failed to connect to DBus to send %s: %v", signal, err)
+			return
+		}
+		defer func() { _ = conn.Close() }()
+	}
+
+	if err := conn.Emit(databaseObjectPath, signal, args...); err != nil {
+		Log.Debugf("failed to send %s: %v", signal, err)
+	}
+}
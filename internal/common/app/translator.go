@@ -17,8 +17,10 @@
 package app
 
 import (
+	"context"
 	"os"
 	"strings"
+	"sync"
 
 	"golang.org/x/text/language"
 
@@ -35,6 +37,33 @@ var (
 type translatorImpl struct {
 	localesPath string
 	initialized bool
+	// language язык, на котором сейчас формируются сообщения
+	language string
+
+	mu       sync.Mutex
+	catalogs map[string]gotext.Translator
+}
+
+// localeKey ключ контекста с языком клиента
+type localeKey struct{}
+
+// WithLocale кладёт в ctx язык, на котором формируются сообщения для клиента, например ru или en_US.UTF-8.
+// Неизвестный или пустой язык не меняет ctx.
+func WithLocale(ctx context.Context, lang string) context.Context {
+	tag, ok := ParseLanguage(lang)
+	if lang == "" || !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, localeKey{}, tag.String())
+}
+
+// LocaleFromContext возвращает язык клиента из ctx или пустую строку
+func LocaleFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	lang, _ := ctx.Value(localeKey{}).(string)
+	return lang
 }
 
 // NewTranslator создает новый переводчик
@@ -54,10 +83,49 @@ func (t *translatorImpl) initLocales() {
 		Log.Warning("Translations folder not found at path: " + t.localesPath)
 	}
 
-	gotext.Configure(t.localesPath, GetSystemLocale().String(), "apm")
+	t.language = GetSystemLocale().String()
+	gotext.Configure(t.localesPath, t.language, "apm")
 	t.initialized = true
 }
 
+// SetLanguage переключает язык сообщений текущего процесса
+func (t *translatorImpl) SetLanguage(lang string) {
+	tag, ok := ParseLanguage(lang)
+	if !ok {
+		return
+	}
+	t.language = tag.String()
+	gotext.Configure(t.localesPath, t.language, "apm")
+	t.initialized = true
+}
+
+// catalog возвращает каталог языка клиента из ctx. ok равно false для языка процесса: его сообщения
+// переводит глобальный каталог gotext. Для языка без каталога (например, английского) возвращается nil.
+func (t *translatorImpl) catalog(ctx context.Context) (gotext.Translator, bool) {
+	lang := LocaleFromContext(ctx)
+	if lang == "" {
+		return nil, false
+	}
+	t.initLocales()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if lang == t.language {
+		return nil, false
+	}
+	catalog, ok := t.catalogs[lang]
+	if !ok {
+		if t.catalogs == nil {
+			t.catalogs = make(map[string]gotext.Translator)
+		}
+		locale := gotext.NewLocale(t.localesPath, lang)
+		locale.AddDomain("apm")
+		catalog = locale.Domains["apm"]
+		t.catalogs[lang] = catalog
+	}
+	return catalog, true
+}
+
 // T_ возвращает переведенную строку
 func (t *translatorImpl) T_(messageID string) string {
 	t.initLocales()
@@ -70,26 +138,75 @@ func (t *translatorImpl) TN_(messageID string, pluralMessageID string, count int
 	return gotextGetN(messageID, pluralMessageID, count)
 }
 
+// TL_ возвращает строку, переведенную на язык клиента из ctx
+func (t *translatorImpl) TL_(ctx context.Context, messageID string) string {
+	catalog, ok := t.catalog(ctx)
+	switch {
+	case !ok:
+		return t.T_(messageID)
+	case catalog == nil:
+		return messageID
+	default:
+		return catalog.Get(messageID)
+	}
+}
+
+// TLN_ возвращает строку на языке клиента из ctx с поддержкой множественного числа
+func (t *translatorImpl) TLN_(ctx context.Context, messageID string, pluralMessageID string, count int) string {
+	catalog, ok := t.catalog(ctx)
+	switch {
+	case !ok:
+		return t.TN_(messageID, pluralMessageID, count)
+	case catalog != nil:
+		return catalog.GetN(messageID, pluralMessageID, count)
+	case count == 1:
+		return messageID
+	default:
+		return pluralMessageID
+	}
+}
+
 // GetSystemLocale возвращает базовый язык системы в виде language.Tag.
+// APM_LANG имеет приоритет над переменными локали.
 func GetSystemLocale() language.Tag {
 	var localeStr string
-	if v := os.Getenv("LC_ALL"); v != "" {
-		localeStr = stripAfterDot(v)
+	if v := os.Getenv("APM_LANG"); v != "" {
+		localeStr = v
+	} else if v := os.Getenv("LC_ALL"); v != "" {
+		localeStr = v
 	} else if v := os.Getenv("LC_MESSAGES"); v != "" {
-		localeStr = stripAfterDot(v)
+		localeStr = v
 	} else {
-		localeStr = stripAfterDot(os.Getenv("LANG"))
+		localeStr = os.Getenv("LANG")
+	}
+
+	tag, ok := ParseLanguage(localeStr)
+	if !ok {
+		return language.English
+	}
+	return tag
+}
+
+// ParseLanguage приводит локаль в формате POSIX (ru_RU.UTF-8) или BCP 47 (ru-RU)
+// к базовому языку. Локали C и POSIX соответствуют английскому.
+func ParseLanguage(localeStr string) (language.Tag, bool) {
+	localeStr = stripAfterDot(localeStr)
+	if idx := strings.Index(localeStr, "@"); idx != -1 {
+		localeStr = localeStr[:idx]
+	}
+	if localeStr == "C" || localeStr == "POSIX" {
+		return language.English, true
 	}
 
 	// Приводим строку к формату BCP 47 (заменяем "_" на "-").
 	localeStr = strings.Replace(localeStr, "_", "-", 1)
 	tag, err := language.Parse(localeStr)
 	if err != nil {
-		return language.English
+		return language.Und, false
 	}
 
 	base, _ := tag.Base()
-	return language.Make(base.String())
+	return language.Make(base.String()), true
 }
 
 func stripAfterDot(localeStr string) string {
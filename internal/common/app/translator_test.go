@@ -0,0 +1,91 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testPO = `msgid ""
+msgstr ""
+"Content-Type: text/plain; charset=UTF-8\n"
+"Plural-Forms: nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n"
+
+msgid "Package %s not found"
+msgstr "Пакет %s не найден"
+
+msgid "%d record found"
+msgid_plural "%d records found"
+msgstr[0] "Найдена %d запись"
+msgstr[1] "Найдено %d записи"
+msgstr[2] "Найдено %d записей"
+`
+
+func newTestTranslator(t *testing.T, lang string) *translatorImpl {
+	t.Helper()
+	dir := t.TempDir()
+	msgDir := filepath.Join(dir, "ru", "LC_MESSAGES")
+	if err := os.MkdirAll(msgDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(msgDir, "apm.po"), []byte(testPO), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tr := NewTranslator(dir).(*translatorImpl)
+	tr.SetLanguage(lang)
+	return tr
+}
+
+func TestParseLanguage(t *testing.T) {
+	tests := map[string]string{
+		"ru_RU.UTF-8": "ru",
+		"ru-RU":       "ru",
+		"en_US@euro":  "en",
+		"C":           "en",
+		"POSIX":       "en",
+		"de":          "de",
+	}
+	for in, want := range tests {
+		tag, ok := ParseLanguage(in)
+		if !ok || tag.String() != want {
+			t.Errorf("ParseLanguage(%q) = %v, %v; want %s", in, tag, ok, want)
+		}
+	}
+	if _, ok := ParseLanguage("not a locale!"); ok {
+		t.Error("expected invalid locale to be rejected")
+	}
+}
+
+func TestTranslateWithContextLocale(t *testing.T) {
+	tr := newTestTranslator(t, "en")
+	ru := WithLocale(context.Background(), "ru_RU.UTF-8")
+
+	if got := tr.TL_(ru, "Package %s not found"); got != "Пакет %s не найден" {
+		t.Errorf("TL_ with ru locale = %q", got)
+	}
+	if got := tr.TLN_(ru, "%d record found", "%d records found", 3); got != "Найдено %d записи" {
+		t.Errorf("TLN_ with ru locale = %q", got)
+	}
+	if got := tr.TL_(context.Background(), "Package %s not found"); got != "Package %s not found" {
+		t.Errorf("TL_ without locale must use the process language, got %q", got)
+	}
+	if LocaleFromContext(WithLocale(context.Background(), "not a locale!")) != "" {
+		t.Error("unknown locale must not be stored in the context")
+	}
+}
+
+func TestTranslateToEnglishFromOtherProcessLanguage(t *testing.T) {
+	tr := newTestTranslator(t, "ru")
+	en := WithLocale(context.Background(), "en_US.UTF-8")
+
+	if got := tr.TL_(context.Background(), "Package %s not found"); got != "Пакет %s не найден" {
+		t.Errorf("TL_ without locale = %q", got)
+	}
+	if got := tr.TL_(en, "Package %s not found"); got != "Package %s not found" {
+		t.Errorf("TL_ with en locale = %q", got)
+	}
+	if got := tr.TLN_(en, "%d record found", "%d records found", 5); got != "%d records found" {
+		t.Errorf("TLN_ with en locale = %q", got)
+	}
+}
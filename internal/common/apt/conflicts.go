@@ -0,0 +1,109 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package apt
+
+import (
+	"apm/internal/common/app"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ConflictPolicy определяет способ разрешения файловых конфликтов RPM.
+type ConflictPolicy string
+
+const (
+	// ConflictPolicyNone конфликт не разрешается автоматически
+	ConflictPolicyNone ConflictPolicy = ""
+	// ConflictPolicyReplace перезаписать конфликтующие файлы (rpm --replacefiles)
+	ConflictPolicyReplace ConflictPolicy = "replace"
+	// ConflictPolicySkip исключить конфликтующие пакеты из транзакции
+	ConflictPolicySkip ConflictPolicy = "skip"
+	// ConflictPolicyRemove удалить установленные пакеты, владеющие конфликтующими файлами
+	ConflictPolicyRemove ConflictPolicy = "remove"
+)
+
+// ConflictPolicies список допустимых политик разрешения конфликтов
+var ConflictPolicies = []ConflictPolicy{ConflictPolicyReplace, ConflictPolicySkip, ConflictPolicyRemove}
+
+// ParseConflictPolicy проверяет и возвращает политику разрешения конфликтов.
+func ParseConflictPolicy(value string) (ConflictPolicy, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return ConflictPolicyNone, nil
+	}
+	for _, p := range ConflictPolicies {
+		if string(p) == value {
+			return p, nil
+		}
+	}
+	return ConflictPolicyNone, fmt.Errorf(app.T_("Unknown conflict policy: %s. Allowed values: replace, skip, remove"), value)
+}
+
+// FileConflict описывает конфликт двух пакетов по одному файлу.
+type FileConflict struct {
+	Path          string `json:"path"`
+	Package       string `json:"package"`
+	ConflictsWith string `json:"conflictsWith"`
+	// Installed true, если ConflictsWith уже установлен в системе
+	Installed bool `json:"installed"`
+}
+
+var (
+	// file /usr/bin/foo from install of foo-1.0-alt1.x86_64 conflicts with file from package bar-2.0-alt1.x86_64
+	reFileConflictInstalled = regexp.MustCompile(`^file (\S+) from install of (\S+) conflicts with file from package (\S+)$`)
+	// file /usr/bin/foo conflicts between attempted installs of foo-1.0-alt1.x86_64 and bar-2.0-alt1.x86_64
+	reFileConflictAttempted = regexp.MustCompile(`^file (\S+) conflicts between attempted installs of (\S+) and (\S+)$`)
+)
+
+// ParseFileConflicts извлекает файловые конфликты из вывода RPM транзакции.
+func ParseFileConflicts(text string) []FileConflict {
+	var conflicts []FileConflict
+	seen := make(map[FileConflict]bool)
+	for _, line := range strings.Split(text, "\n") {
+		line = cleanErrorPrefix(strings.TrimSpace(line))
+
+		var conflict FileConflict
+		if m := reFileConflictInstalled.FindStringSubmatch(line); m != nil {
+			conflict = FileConflict{Path: m[1], Package: m[2], ConflictsWith: m[3], Installed: true}
+		} else if m = reFileConflictAttempted.FindStringSubmatch(line); m != nil {
+			conflict = FileConflict{Path: m[1], Package: m[2], ConflictsWith: m[3]}
+		} else {
+			continue
+		}
+
+		if !seen[conflict] {
+			seen[conflict] = true
+			conflicts = append(conflicts, conflict)
+		}
+	}
+	return conflicts
+}
+
+// PackageNameFromNEVRA возвращает имя пакета из строки вида name-version-release.arch
+func PackageNameFromNEVRA(nevra string) string {
+	nevra = strings.TrimSpace(nevra)
+	idx := strings.LastIndex(nevra, "-")
+	if idx <= 0 {
+		return nevra
+	}
+	idx = strings.LastIndex(nevra[:idx], "-")
+	if idx <= 0 {
+		return nevra
+	}
+	return nevra[:idx]
+}
@@ -0,0 +1,89 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package apt
+
+import (
+	"testing"
+)
+
+func TestParseFileConflicts(t *testing.T) {
+	details := "file /usr/bin/foo from install of foo-1.0-alt1.x86_64 conflicts with file from package bar-utils-2.0-alt1.x86_64\n" +
+		"error: file /usr/share/doc/x conflicts between attempted installs of foo-1.0-alt1.x86_64 and baz-3-alt2.noarch\n" +
+		"file /usr/bin/foo from install of foo-1.0-alt1.x86_64 conflicts with file from package bar-utils-2.0-alt1.x86_64\n" +
+		"libfoo is needed by bar-1.0-alt1.x86_64"
+
+	conflicts := ParseFileConflicts(details)
+	if len(conflicts) != 2 {
+		t.Fatalf("expected 2 conflicts, got %d: %+v", len(conflicts), conflicts)
+	}
+
+	first := conflicts[0]
+	if first.Path != "/usr/bin/foo" || first.Package != "foo-1.0-alt1.x86_64" ||
+		first.ConflictsWith != "bar-utils-2.0-alt1.x86_64" || !first.Installed {
+		t.Errorf("unexpected first conflict: %+v", first)
+	}
+
+	second := conflicts[1]
+	if second.Path != "/usr/share/doc/x" || second.ConflictsWith != "baz-3-alt2.noarch" || second.Installed {
+		t.Errorf("unexpected second conflict: %+v", second)
+	}
+}
+
+func TestParseFileConflictsEmpty(t *testing.T) {
+	if conflicts := ParseFileConflicts("Error while running transaction"); len(conflicts) != 0 {
+		t.Errorf("expected no conflicts, got %+v", conflicts)
+	}
+}
+
+func TestParseConflictPolicy(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected ConflictPolicy
+		wantErr  bool
+	}{
+		{"", ConflictPolicyNone, false},
+		{"replace", ConflictPolicyReplace, false},
+		{" Skip ", ConflictPolicySkip, false},
+		{"REMOVE", ConflictPolicyRemove, false},
+		{"force", ConflictPolicyNone, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseConflictPolicy(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseConflictPolicy(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+		if got != tt.expected {
+			t.Errorf("ParseConflictPolicy(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestPackageNameFromNEVRA(t *testing.T) {
+	tests := map[string]string{
+		"foo-1.0-alt1.x86_64":           "foo",
+		"bar-utils-2.0-alt1.x86_64":     "bar-utils",
+		"lib-foo-devel-3.1-alt2.noarch": "lib-foo-devel",
+		"plain":                         "plain",
+	}
+
+	for input, expected := range tests {
+		if got := PackageNameFromNEVRA(input); got != expected {
+			t.Errorf("PackageNameFromNEVRA(%q) = %q, want %q", input, got, expected)
+		}
+	}
+}
@@ -17,6 +17,7 @@
 package apt
 
 import (
+	"apm/internal/common/apmerr"
 	"apm/internal/common/app"
 	"fmt"
 	"regexp"
@@ -158,9 +159,10 @@ const (
 
 // MatchedError представляет найденную ошибку с извлечёнными параметрами.
 type MatchedError struct {
-	Entry   ErrorEntry
-	Params  []string
-	Details string
+	Entry       ErrorEntry
+	Params      []string
+	Details     string
+	Remediation *apmerr.Remediation
 }
 
 // ErrorEntry описывает шаблон ошибки.
@@ -508,8 +510,9 @@ func CheckError(requestError string) *MatchedError {
 				params = matches[1:]
 			}
 			return &MatchedError{
-				Entry:  entry,
-				Params: params,
+				Entry:       entry,
+				Params:      params,
+				Remediation: remediationFor(entry.Code),
 			}
 		}
 	}
@@ -533,6 +536,11 @@ func (e *MatchedError) Error() string {
 	return msg
 }
 
+// GetRemediation возвращает подсказку по устранению ошибки, если она известна.
+func (e *MatchedError) GetRemediation() *apmerr.Remediation {
+	return e.Remediation
+}
+
 func (e *MatchedError) IsCritical() bool {
 	switch e.Entry.Code {
 	case ErrPackageNotInstalled:
@@ -554,6 +562,39 @@ func (e *MatchedError) IsNotFound() bool {
 	}
 }
 
+// IsDependencyError сообщает, что операция не выполнена из-за неразрешимых зависимостей
+func (e *MatchedError) IsDependencyError() bool {
+	switch e.Entry.Code {
+	case ErrBrokenPackages, ErrInternalBrokenPackages, ErrUnmetDependencies, ErrSomeBrokenDependencies,
+		ErrDependencyUnsatisfied, ErrDependencyUnsatisfied2, ErrFailedDependency, ErrFailedDependencyTooNew,
+		ErrCannotInstallWithBrokenDeps, ErrPackagesCouldNotBeInstalled, ErrConflictsViolated, ErrResolverBroken:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsLockError сообщает, что база пакетов заблокирована другим процессом
+func (e *MatchedError) IsLockError() bool {
+	switch e.Entry.Code {
+	case ErrAptLockFailed, ErrLockDownloadDir, ErrRpmDatabaseLock:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsNetworkError сообщает, что не удалось скачать индексы или пакеты
+func (e *MatchedError) IsNetworkError() bool {
+	switch e.Entry.Code {
+	case ErrDownloadFailed, ErrFetchArchivesFailed, ErrFailedToFetchArchives, ErrFailedToFetch,
+		ErrFailedToFetchSomeIndex, ErrDownloadPackageListsFailed, ErrDownloadPackagesFailed, ErrDownloadPackagesForDist:
+		return true
+	default:
+		return false
+	}
+}
+
 func (e *MatchedError) NeedUpdate() bool {
 	switch e.Entry.Code {
 	case ErrFailedToFetchArchives:
@@ -567,6 +608,47 @@ func (e *MatchedError) NeedUpdate() bool {
 	}
 }
 
+// remediationFor подбирает подсказку по коду ошибки для самых частых случаев.
+func remediationFor(code int) *apmerr.Remediation {
+	switch code {
+	case ErrFailedToFetchArchives, ErrFailedToFetch, ErrFailedToFetchSomeIndex,
+		ErrPackageFileOutOfSync, ErrDownloadPackagesFailed, ErrFetchArchivesFailed,
+		ErrNoInstallationCandidate:
+		return &apmerr.Remediation{
+			Action: apmerr.RemediationUpdateIndexes,
+			Hint:   app.T_("Update the package lists with 'apm system update' and try again"),
+		}
+	case ErrNotEnoughSpace:
+		return &apmerr.Remediation{
+			Action: apmerr.RemediationFreeDiskSpace,
+			Hint:   app.T_("Free disk space on /var (for example, clean the package cache) and try again"),
+		}
+	case ErrAptLockFailed, ErrLockDownloadDir:
+		return &apmerr.Remediation{
+			Action: apmerr.RemediationClosePkgManager,
+			Hint:   app.T_("Close other package managers or wait for running operations to finish"),
+		}
+	case ErrUnmetDependencies, ErrBrokenPackages, ErrSomeBrokenDependencies,
+		ErrCannotInstallWithBrokenDeps, ErrPackagesCouldNotBeInstalled:
+		return &apmerr.Remediation{
+			Action: apmerr.RemediationFixBroken,
+			Hint:   app.T_("Fix broken dependencies with 'apm system upgrade' or remove the conflicting packages"),
+		}
+	case ErrPermissionDenied:
+		return &apmerr.Remediation{
+			Action: apmerr.RemediationRunAsRoot,
+			Hint:   app.T_("Run the command with elevated rights (sudo or pkexec)"),
+		}
+	case ErrVirtualMultipleProviders, ErrVirtualMultipleProvidersNeedSelect, ErrMultiInstallProvidersSelect:
+		return &apmerr.Remediation{
+			Action: apmerr.RemediationSelectProvider,
+			Hint:   app.T_("Specify one of the providing packages explicitly"),
+		}
+	default:
+		return nil
+	}
+}
+
 func patternToRegex(pattern string) string {
 	parts := strings.Split(pattern, "%s")
 	for i, part := range parts {
@@ -21,6 +21,7 @@ import (
 	aptParser "apm/internal/common/apt"
 	aptBinding "apm/internal/common/binding/apt"
 	aptLib "apm/internal/common/binding/apt/lib"
+	"apm/internal/common/filter"
 	"apm/internal/common/helper"
 	"apm/internal/common/reply"
 	"context"
@@ -36,6 +37,7 @@ type Actions struct {
 	reporter           *reply.Reporter
 	serviceAptDatabase *PackageDBService
 	serviceAptBinding  *aptBinding.Actions
+	preferencesFile    string
 }
 
 func NewActions(serviceAptDatabase *PackageDBService, appConfig *app.Config, reporter *reply.Reporter) *Actions {
@@ -44,6 +46,7 @@ func NewActions(serviceAptDatabase *PackageDBService, appConfig *app.Config, rep
 		reporter:           reporter,
 		serviceAptDatabase: serviceAptDatabase,
 		serviceAptBinding:  aptBinding.NewActions(),
+		preferencesFile:    DefaultPreferencesFile,
 	}
 }
 
@@ -102,14 +105,14 @@ func (a *Actions) FindPackage(ctx context.Context, installed []string, removed [
 	a.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventSystemCheck))
 	defer a.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventSystemCheck))
 
-	expandedInstall, expandedRemove, rpmFiles, packagesInfo, seenInfo, err := a.expandPackageLists(ctx, installed, removed)
+	expandedInstall, expandedRemove, rpmFiles, packagesInfo, seenInfo, err := a.expandPackageLists(ctx, installed, removed, reinstall)
 	if err != nil {
 		return nil, nil, nil, nil, err
 	}
 
 	if len(expandedInstall) == 0 && len(expandedRemove) == 0 {
 		if len(installed) > 0 || len(removed) > 0 {
-			return nil, nil, nil, nil, errors.New(app.T_("No packages found matching the specified patterns"))
+			return nil, nil, nil, nil, errors.New(app.TL_(ctx, "No packages found matching the specified patterns"))
 		}
 	}
 
@@ -127,7 +130,8 @@ func (a *Actions) FindPackage(ctx context.Context, installed []string, removed [
 }
 
 // expandPackageLists обрабатывает wildcard-пакеты и RPM-файлы, возвращая расширенные списки.
-func (a *Actions) expandPackageLists(ctx context.Context, installed, removed []string) (
+// При переустановке шаблоны сопоставляются только с установленными пакетами.
+func (a *Actions) expandPackageLists(ctx context.Context, installed, removed []string, reinstall bool) (
 	expandedInstall, expandedRemove, rpmFiles []string, packagesInfo []Package, seenInfo map[string]bool, err error,
 ) {
 	seenInfo = make(map[string]bool)
@@ -164,8 +168,8 @@ func (a *Actions) expandPackageLists(ctx context.Context, installed, removed []s
 		return nil
 	}
 
-	// Обрабатываем пакеты на установку (ищем среди всех доступных)
-	if err = processPackageList(installed, &expandedInstall, false); err != nil {
+	// Обрабатываем пакеты на установку (ищем среди всех доступных, при переустановке - среди установленных)
+	if err = processPackageList(installed, &expandedInstall, reinstall); err != nil {
 		return
 	}
 
@@ -243,6 +247,15 @@ func (a *Actions) Install(ctx context.Context, packages []string, downloadOnly b
 	a.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventSystemWorking))
 	defer a.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventSystemWorking))
 
+	if !downloadOnly {
+		err := a.downloadAndVerify(ctx, a.getHandler(ctx, len(packages)), func(handler aptLib.ProgressHandler) error {
+			return a.serviceAptBinding.InstallPackages(packages, handler, true)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
 	err := a.serviceAptBinding.InstallPackages(packages, a.getHandler(ctx, len(packages)), downloadOnly)
 	if err != nil {
 		return err
@@ -256,6 +269,22 @@ func (a *Actions) CombineInstallRemovePackages(ctx context.Context, packagesInst
 	a.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventSystemWorking))
 	defer a.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventSystemWorking))
 
+	if !downloadOnly && len(packagesInstall) > 0 {
+		err := a.downloadAndVerify(ctx, a.getHandler(ctx, len(packagesInstall)+len(packagesRemove)), func(handler aptLib.ProgressHandler) error {
+			return a.serviceAptBinding.CombineInstallRemovePackages(
+				packagesInstall,
+				packagesRemove,
+				handler,
+				purge,
+				depends,
+				true,
+			)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
 	err := a.serviceAptBinding.CombineInstallRemovePackages(
 		packagesInstall,
 		packagesRemove,
@@ -287,6 +316,19 @@ func (a *Actions) Upgrade(ctx context.Context, downloadOnly bool) error {
 	a.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventSystemUpgrade))
 	defer a.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventSystemUpgrade))
 
+	if err := a.loadHeldPackages(ctx); err != nil {
+		return err
+	}
+
+	if !downloadOnly {
+		err := a.downloadAndVerify(ctx, a.getHandler(ctx), func(handler aptLib.ProgressHandler) error {
+			return a.serviceAptBinding.DistUpgrade(handler, true)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
 	err := a.serviceAptBinding.DistUpgrade(a.getHandler(ctx), downloadOnly)
 	if err != nil {
 		return err
@@ -295,6 +337,23 @@ func (a *Actions) Upgrade(ctx context.Context, downloadOnly bool) error {
 	return nil
 }
 
+// FixBroken исправляет нарушенные зависимости установленных пакетов, как apt-get -f install
+func (a *Actions) FixBroken(ctx context.Context) error {
+	a.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventSystemRepair))
+	defer a.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventSystemRepair))
+
+	return a.serviceAptBinding.FixBroken(a.getHandler(ctx))
+}
+
+// CheckFixBroken симулирует исправление нарушенных зависимостей
+func (a *Actions) CheckFixBroken(ctx context.Context) (packageChanges *aptLib.PackageChanges, err error) {
+	a.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventSystemCheck))
+	defer a.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventSystemCheck))
+
+	packageChanges, err = a.serviceAptBinding.SimulateFixBroken()
+	return
+}
+
 func (a *Actions) CheckInstall(ctx context.Context, packageName []string) (packageChanges *aptLib.PackageChanges, err error) {
 	a.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventSystemCheck))
 	defer a.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventSystemCheck))
@@ -351,6 +410,10 @@ func (a *Actions) CheckUpgrade(ctx context.Context) (packageChanges *aptLib.Pack
 	a.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventSystemCheck))
 	defer a.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventSystemCheck))
 
+	if err = a.loadHeldPackages(ctx); err != nil {
+		return nil, err
+	}
+
 	packageChanges, err = a.serviceAptBinding.SimulateDistUpgrade()
 	return
 }
@@ -384,10 +447,19 @@ func (a *Actions) Update(ctx context.Context, noLock ...bool) ([]Package, error)
 	}
 	wg.Wait()
 
+	// Пакеты STPLR не приходят из APT: переносим их из базы, иначе перезапись таблицы их удалит
+	stplrPackages, errStplr := a.serviceAptDatabase.QueryHostImagePackages(ctx, []filter.Filter{
+		{Field: "typePackage", Op: filter.OpEq, Value: fmt.Sprintf("%d", int(PackageTypeStplr))},
+	}, "", "", 0, 0)
+	if errStplr != nil {
+		app.Log.Debugf("failed to keep STPLR packages: %v", errStplr)
+	}
+	packages = append(packages, stplrPackages...)
+
 	// @TODO Обновляем информацию о том, установлены ли пакеты локально, на самом деле об этом можно узнать из биндингов
 	packages, err = a.updateInstalledInfo(ctx, packages, noLock...)
 	if err != nil {
-		return nil, fmt.Errorf(app.T_("Error updating information about installed packages: %w"), err)
+		return nil, fmt.Errorf(app.TL_(ctx, "Error updating information about installed packages: %w"), err)
 	}
 
 	err = a.serviceAptDatabase.SavePackagesToDB(ctx, packages)
@@ -407,7 +479,7 @@ func (a *Actions) UpdateDBOnly(ctx context.Context, noLock ...bool) ([]Package,
 
 	packages, err = a.updateInstalledInfo(ctx, packages, noLock...)
 	if err != nil {
-		return nil, fmt.Errorf(app.T_("Error updating information about installed packages: %w"), err)
+		return nil, fmt.Errorf(app.TL_(ctx, "Error updating information about installed packages: %w"), err)
 	}
 
 	err = a.serviceAptDatabase.SavePackagesToDB(ctx, packages)
@@ -420,15 +492,17 @@ func (a *Actions) UpdateDBOnly(ctx context.Context, noLock ...bool) ([]Package,
 
 // updateInstalledInfo обновляет срез пакетов, устанавливая поля Installed и InstalledVersion, если пакет найден в системе.
 func (a *Actions) updateInstalledInfo(ctx context.Context, packages []Package, noLock ...bool) ([]Package, error) {
-	installed, err := a.GetInstalledPackages(ctx, noLock...)
+	installed, err := a.GetInstalledEVRs(ctx, noLock...)
 	if err != nil {
 		return nil, err
 	}
 
 	for i, pkg := range packages {
-		if version, found := installed[pkg.Name]; found {
+		if evr, found := installed[pkg.Name]; found {
+			_, version, _ := helper.SplitEVR(evr)
 			packages[i].Installed = true
 			packages[i].VersionInstalled = version
+			packages[i].VersionInstalledRaw = evr
 		}
 	}
 
@@ -441,6 +515,23 @@ func (a *Actions) GetInstalledPackages(ctx context.Context, noLock ...bool) (map
 	return a.serviceAptBinding.RpmGetInstalledPackages(ctx, commandPrefix, noLock...)
 }
 
+// GetInstalledEVRs возвращает карту, где ключ – имя пакета, а значение – его установленная версия
+// с эпохой и релизом.
+func (a *Actions) GetInstalledEVRs(ctx context.Context, noLock ...bool) (map[string]string, error) {
+	commandPrefix := a.appConfig.ConfigManager.GetConfig().CommandPrefix
+	return a.serviceAptBinding.RpmGetInstalledEVRs(ctx, commandPrefix, noLock...)
+}
+
+// GetInstalledFiles возвращает манифест файлов установленного пакета.
+func (a *Actions) GetInstalledFiles(ctx context.Context, packageName string) ([]aptBinding.RpmFileInfo, bool, error) {
+	return a.serviceAptBinding.RpmQueryFiles(ctx, packageName)
+}
+
+// GetFileOwners возвращает установленные пакеты, которым принадлежит файл или которые предоставляют возможность.
+func (a *Actions) GetFileOwners(ctx context.Context, target string) ([]aptBinding.InstalledPackageInfo, error) {
+	return a.serviceAptBinding.RpmQueryOwners(ctx, target)
+}
+
 func (a *Actions) AptUpdate(ctx context.Context, noLock ...bool) error {
 	a.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventSystemAptUpdate))
 	defer a.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventSystemAptUpdate))
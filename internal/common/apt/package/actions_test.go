@@ -0,0 +1,47 @@
+package _package
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestExpandPackageListsWildcard(t *testing.T) {
+	a := &Actions{serviceAptDatabase: newTestDBService(t,
+		Package{Name: "python3-module-foo", Version: "1.0", Installed: true},
+		Package{Name: "python3-module-bar", Version: "2.0"},
+		Package{Name: "vim", Version: "9.0", Installed: true},
+	)}
+	ctx := context.Background()
+
+	t.Run("reinstall matches only installed packages", func(t *testing.T) {
+		install, remove, _, _, _, err := a.expandPackageLists(ctx, []string{"python3-module-*"}, nil, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(install, []string{"python3-module-foo"}) || len(remove) != 0 {
+			t.Errorf("unexpected expansion: install %v, remove %v", install, remove)
+		}
+	})
+
+	t.Run("install matches all available packages", func(t *testing.T) {
+		install, _, _, _, _, err := a.expandPackageLists(ctx, []string{"python3-module-*"}, nil, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		slices.Sort(install)
+		if !slices.Equal(install, []string{"python3-module-bar", "python3-module-foo"}) {
+			t.Errorf("unexpected expansion: %v", install)
+		}
+	})
+
+	t.Run("remove matches only installed packages", func(t *testing.T) {
+		_, remove, _, _, _, err := a.expandPackageLists(ctx, nil, []string{"python3-module-*"}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(remove, []string{"python3-module-foo"}) {
+			t.Errorf("unexpected expansion: %v", remove)
+		}
+	})
+}
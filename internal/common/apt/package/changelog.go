@@ -0,0 +1,86 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package _package
+
+import (
+	"strings"
+)
+
+// ChangelogEntry запись changelog пакета
+type ChangelogEntry struct {
+	Date    string   `json:"date"`
+	Author  string   `json:"author"`
+	Version string   `json:"version"`
+	Changes []string `json:"changes"`
+}
+
+// ParseChangelog разбирает changelog RPM на записи в порядке следования (от новых к старым).
+func ParseChangelog(changelog string) []ChangelogEntry {
+	var entries []ChangelogEntry
+	for _, line := range strings.Split(changelog, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+
+		if isChangelogHeader(trimmed) {
+			entries = append(entries, parseChangelogHeader(trimmed))
+			continue
+		}
+		if len(entries) > 0 {
+			last := &entries[len(entries)-1]
+			last.Changes = append(last.Changes, trimmed)
+		}
+	}
+	return entries
+}
+
+// parseChangelogHeader разбирает заголовок вида "* Mon Jan 15 2024 Имя <email> 1.0-alt1".
+func parseChangelogHeader(line string) ChangelogEntry {
+	fields := strings.Fields(line)
+	entry := ChangelogEntry{Date: strings.Join(fields[1:5], " ")}
+
+	rest := fields[5:]
+	if n := len(rest); n > 0 && !strings.ContainsAny(rest[n-1], "@<>") {
+		entry.Version = rest[n-1]
+		rest = rest[:n-1]
+	}
+	entry.Author = strings.Join(rest, " ")
+	return entry
+}
+
+// ChangelogSince возвращает записи новее версии since. Версия сравнивается без эпохи, версия без
+// релиза совпадает с любым её релизом. Если запись с версией since не найдена, возвращаются все записи
+// и found == false.
+func ChangelogSince(entries []ChangelogEntry, since string) (result []ChangelogEntry, found bool) {
+	since = stripEpoch(since)
+	for i, entry := range entries {
+		version := stripEpoch(entry.Version)
+		if version != "" && (version == since || strings.HasPrefix(version, since+"-")) {
+			return entries[:i], true
+		}
+	}
+	return entries, false
+}
+
+// stripEpoch отбрасывает эпоху из строки версии.
+func stripEpoch(version string) string {
+	if idx := strings.Index(version, ":"); idx >= 0 {
+		return version[idx+1:]
+	}
+	return version
+}
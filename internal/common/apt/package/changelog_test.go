@@ -0,0 +1,55 @@
+package _package
+
+import "testing"
+
+const testChangelog = `* Tue Oct 01 2024 Ivan Ivanov <ivan@altlinux.org> 1:9.1-alt1
+- Updated to 9.1.
+- Fixed CVE-2024-0001.
+
+* Mon Jun 03 2024 Ivan Ivanov <ivan@altlinux.org> 1:9.0-alt2
+- Rebuilt with new toolchain.
+
+* Fri Jan 12 2024 Petr Petrov <petr@altlinux.org> 9.0-alt1
+- Initial build.`
+
+func TestParseChangelog(t *testing.T) {
+	entries := ParseChangelog(testChangelog)
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+
+	first := entries[0]
+	if first.Date != "Tue Oct 01 2024" || first.Author != "Ivan Ivanov <ivan@altlinux.org>" || first.Version != "1:9.1-alt1" {
+		t.Errorf("unexpected header: %+v", first)
+	}
+	if len(first.Changes) != 2 || first.Changes[1] != "- Fixed CVE-2024-0001." {
+		t.Errorf("unexpected changes: %v", first.Changes)
+	}
+
+	noVersion := ParseChangelog("* Mon Jan 15 2024 maintainer@alt\n- change")
+	if len(noVersion) != 1 || noVersion[0].Version != "" || noVersion[0].Author != "maintainer@alt" {
+		t.Errorf("unexpected entry without version: %+v", noVersion)
+	}
+}
+
+func TestChangelogSince(t *testing.T) {
+	entries := ParseChangelog(testChangelog)
+
+	tests := []struct {
+		since string
+		count int
+		found bool
+	}{
+		{"9.0-alt2", 1, true},
+		{"1:9.0-alt2", 1, true},
+		{"9.0", 1, true},
+		{"9.1", 0, true},
+		{"8.2", 3, false},
+	}
+	for _, tt := range tests {
+		got, found := ChangelogSince(entries, tt.since)
+		if len(got) != tt.count || found != tt.found {
+			t.Errorf("ChangelogSince(%q) = %d entries, found %v; want %d, %v", tt.since, len(got), found, tt.count, tt.found)
+		}
+	}
+}
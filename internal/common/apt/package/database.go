@@ -26,6 +26,8 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path"
+	"slices"
 	"strings"
 	"sync"
 
@@ -39,9 +41,10 @@ import (
 
 // PackageDBService предоставляет сервис для операций с базой данных пакетов.
 type PackageDBService struct {
-	dbManager app.DatabaseManager
-	reporter  *reply.Reporter
-	realDb    *gorm.DB
+	dbManager   app.DatabaseManager
+	reporter    *reply.Reporter
+	realDb      *gorm.DB
+	searchIndex bool
 }
 
 var initDBMutex sync.Mutex
@@ -76,10 +79,14 @@ func (s *PackageDBService) db() (*gorm.DB, error) {
 			return nil, fmt.Errorf("ошибка подключения к SQLite через GORM: %w", err)
 		}
 
-		// Автоматическая миграция
-		if err = s.realDb.AutoMigrate(&DBPackage{}); err != nil {
-			return nil, fmt.Errorf("ошибка миграции структуры таблицы: %w", err)
+		// Автоматическая миграция, подключение только для чтения использует схему из миграций root
+		readOnly := app.IsReadOnly(conn)
+		if !readOnly {
+			if err = s.realDb.AutoMigrate(&DBPackage{}, &DBHeldPackage{}); err != nil {
+				return nil, fmt.Errorf("ошибка миграции структуры таблицы: %w", err)
+			}
 		}
+		s.initSearchIndex(s.realDb, readOnly)
 	}
 
 	return s.realDb, nil
@@ -95,26 +102,28 @@ func NewPackageDBService(dbManager app.DatabaseManager, reporter *reply.Reporter
 
 // DBPackage описывает модель пакета для GORM.
 type DBPackage struct {
-	Name             string      `gorm:"column:name;primaryKey"`
-	Architecture     string      `gorm:"column:architecture"`
-	Section          string      `gorm:"column:section"`
-	InstalledSize    int         `gorm:"column:installedSize"`
-	Maintainer       string      `gorm:"column:maintainer"`
-	Version          string      `gorm:"column:version;primaryKey"`
-	VersionRaw       string      `gorm:"column:versionRaw"`
-	VersionInstalled string      `gorm:"column:versionInstalled"`
-	Depends          string      `gorm:"column:depends"`
-	Aliases          string      `gorm:"column:aliases"`
-	Provides         string      `gorm:"column:provides"`
-	Size             int         `gorm:"column:size"`
-	Filename         string      `gorm:"column:filename"`
-	Summary          string      `gorm:"column:summary"`
-	Description      string      `gorm:"column:description"`
-	IDAppStream      *uint       `gorm:"column:idAppStream"`
-	Changelog        string      `gorm:"column:changelog"`
-	Installed        bool        `gorm:"column:installed"`
-	TypePackage      PackageType `gorm:"column:typePackage"`
-	Files            string      `gorm:"column:files"`
+	Name                string      `gorm:"column:name;primaryKey"`
+	Architecture        string      `gorm:"column:architecture"`
+	Section             string      `gorm:"column:section"`
+	InstalledSize       int         `gorm:"column:installedSize"`
+	Maintainer          string      `gorm:"column:maintainer"`
+	Version             string      `gorm:"column:version;primaryKey"`
+	VersionRaw          string      `gorm:"column:versionRaw"`
+	VersionInstalled    string      `gorm:"column:versionInstalled"`
+	VersionInstalledRaw string      `gorm:"column:versionInstalledRaw"`
+	Depends             string      `gorm:"column:depends"`
+	Aliases             string      `gorm:"column:aliases"`
+	Provides            string      `gorm:"column:provides"`
+	Size                int         `gorm:"column:size"`
+	Filename            string      `gorm:"column:filename"`
+	Summary             string      `gorm:"column:summary"`
+	Description         string      `gorm:"column:description"`
+	IDAppStream         *uint       `gorm:"column:idAppStream"`
+	Changelog           string      `gorm:"column:changelog"`
+	Installed           bool        `gorm:"column:installed"`
+	Held                bool        `gorm:"column:held"`
+	TypePackage         PackageType `gorm:"column:typePackage"`
+	Files               string      `gorm:"column:files"`
 }
 
 // TableName задаёт имя таблицы.
@@ -125,22 +134,24 @@ func (DBPackage) TableName() string {
 // fromDBModel преобразует модель базы данных в бизнес-структуру.
 func (dbp DBPackage) fromDBModel() Package {
 	p := Package{
-		Name:             dbp.Name,
-		Architecture:     dbp.Architecture,
-		Section:          dbp.Section,
-		InstalledSize:    dbp.InstalledSize,
-		Maintainer:       dbp.Maintainer,
-		Version:          dbp.Version,
-		VersionRaw:       dbp.VersionRaw,
-		VersionInstalled: dbp.VersionInstalled,
-		Size:             dbp.Size,
-		Filename:         dbp.Filename,
-		Summary:          dbp.Summary,
-		Description:      dbp.Description,
-		Changelog:        dbp.Changelog,
-		Installed:        dbp.Installed,
-		TypePackage:      int(dbp.TypePackage),
-		HasAppStream:     dbp.IDAppStream != nil,
+		Name:                dbp.Name,
+		Architecture:        dbp.Architecture,
+		Section:             dbp.Section,
+		InstalledSize:       dbp.InstalledSize,
+		Maintainer:          dbp.Maintainer,
+		Version:             dbp.Version,
+		VersionRaw:          dbp.VersionRaw,
+		VersionInstalled:    dbp.VersionInstalled,
+		VersionInstalledRaw: dbp.VersionInstalledRaw,
+		Size:                dbp.Size,
+		Filename:            dbp.Filename,
+		Summary:             dbp.Summary,
+		Description:         dbp.Description,
+		Changelog:           dbp.Changelog,
+		Installed:           dbp.Installed,
+		Held:                dbp.Held,
+		TypePackage:         int(dbp.TypePackage),
+		HasAppStream:        dbp.IDAppStream != nil,
 	}
 	if strings.TrimSpace(dbp.Aliases) != "" {
 		p.Aliases = strings.Split(dbp.Aliases, ",")
@@ -160,21 +171,23 @@ func (dbp DBPackage) fromDBModel() Package {
 // toDBModel преобразует бизнес-структуру в модель базы данных.
 func (p Package) toDBModel() DBPackage {
 	dbp := DBPackage{
-		Name:             p.Name,
-		Architecture:     p.Architecture,
-		Section:          p.Section,
-		InstalledSize:    p.InstalledSize,
-		Maintainer:       p.Maintainer,
-		Version:          p.Version,
-		VersionRaw:       p.VersionRaw,
-		VersionInstalled: p.VersionInstalled,
-		Size:             p.Size,
-		Filename:         p.Filename,
-		Summary:          p.Summary,
-		Description:      p.Description,
-		Changelog:        p.Changelog,
-		Installed:        p.Installed,
-		TypePackage:      PackageType(p.TypePackage),
+		Name:                p.Name,
+		Architecture:        p.Architecture,
+		Section:             p.Section,
+		InstalledSize:       p.InstalledSize,
+		Maintainer:          p.Maintainer,
+		Version:             p.Version,
+		VersionRaw:          p.VersionRaw,
+		VersionInstalled:    p.VersionInstalled,
+		VersionInstalledRaw: p.VersionInstalledRaw,
+		Size:                p.Size,
+		Filename:            p.Filename,
+		Summary:             p.Summary,
+		Description:         p.Description,
+		Changelog:           p.Changelog,
+		Installed:           p.Installed,
+		Held:                p.Held,
+		TypePackage:         PackageType(p.TypePackage),
 	}
 	if len(p.Aliases) > 0 {
 		dbp.Aliases = strings.Join(p.Aliases, ",")
@@ -204,35 +217,82 @@ func (s *PackageDBService) SavePackagesToDB(ctx context.Context, packages []Pack
 		return err
 	}
 
-	err = db.Transaction(func(tx *gorm.DB) error {
-		// Очищаем таблицу
-		if errDel := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&DBPackage{}).Error; errDel != nil {
-			return fmt.Errorf(app.T_("Table cleanup error: %w"), errDel)
-		}
-
-		batchSize := 1000
-		n := len(packages)
-		for i := 0; i < n; i += batchSize {
-			end := i + batchSize
-			if end > n {
-				end = n
+	err = app.RetryBusy(ctx, func() error {
+		return db.Transaction(func(tx *gorm.DB) error {
+			// Очищаем таблицу
+			if errDel := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&DBPackage{}).Error; errDel != nil {
+				return fmt.Errorf(app.TL_(ctx, "Table cleanup error: %w"), errDel)
 			}
-			batch := packages[i:end]
 
-			// Конвертация в список DBPackage
-			var dbPackages []DBPackage
-			for _, pkg := range batch {
-				dbPackages = append(dbPackages, pkg.toDBModel())
+			batchSize := 1000
+			n := len(packages)
+			for i := 0; i < n; i += batchSize {
+				end := i + batchSize
+				if end > n {
+					end = n
+				}
+				batch := packages[i:end]
+
+				// Конвертация в список DBPackage
+				var dbPackages []DBPackage
+				for _, pkg := range batch {
+					dbPackages = append(dbPackages, pkg.toDBModel())
+				}
+
+				if errCreate := tx.Create(&dbPackages).Error; errCreate != nil {
+					return fmt.Errorf(app.TL_(ctx, "Batch insert error: %w"), errCreate)
+				}
 			}
+			if errHeld := markHeldPackages(tx); errHeld != nil {
+				return errHeld
+			}
+			return s.rebuildSearchIndex(tx)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	app.NotifyDatabaseChanged(s.dbManager, app.DatabaseSystem)
+	return nil
+}
+
+// SaveStplrPackages заменяет пакеты STPLR в базе, не затрагивая системные.
+func (s *PackageDBService) SaveStplrPackages(ctx context.Context, packages []Package) error {
+	syncDBMutex.Lock()
+	defer syncDBMutex.Unlock()
+
+	db, err := s.db()
+	if err != nil {
+		return err
+	}
 
-			if errCreate := tx.Create(&dbPackages).Error; errCreate != nil {
-				return fmt.Errorf(app.T_("Batch insert error: %w"), errCreate)
+	dbPackages := make([]DBPackage, 0, len(packages))
+	for _, pkg := range packages {
+		pkg.TypePackage = int(PackageTypeStplr)
+		dbPackages = append(dbPackages, pkg.toDBModel())
+	}
+
+	err = app.RetryBusy(ctx, func() error {
+		return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if errDel := tx.Where("typePackage = ?", PackageTypeStplr).Delete(&DBPackage{}).Error; errDel != nil {
+				return fmt.Errorf(app.TL_(ctx, "Table cleanup error: %w"), errDel)
 			}
-		}
-		return nil
+			if len(dbPackages) == 0 {
+				return s.rebuildSearchIndex(tx)
+			}
+			if errCreate := tx.CreateInBatches(&dbPackages, 1000).Error; errCreate != nil {
+				return fmt.Errorf(app.TL_(ctx, "Batch insert error: %w"), errCreate)
+			}
+			return s.rebuildSearchIndex(tx)
+		})
 	})
+	if err != nil {
+		return err
+	}
 
-	return err
+	app.NotifyDatabaseChanged(s.dbManager, app.DatabaseSystem)
+	return nil
 }
 
 // GetPackageByName возвращает запись пакета по имени.
@@ -248,7 +308,7 @@ func (s *PackageDBService) GetPackageByName(ctx context.Context, packageName str
 			packageName, "%,"+packageName+",%", "%,"+packageName+",%").
 		First(&dbPkg).Error
 	if err != nil {
-		return Package{}, fmt.Errorf(app.T_("Failed to get information about the package %s"), packageName)
+		return Package{}, fmt.Errorf(app.TL_(ctx, "Failed to get information about the package %s"), packageName)
 	}
 
 	return dbPkg.fromDBModel(), nil
@@ -321,29 +381,30 @@ func (s *PackageDBService) SyncPackageInstallationInfo(ctx context.Context, inst
 		return err
 	}
 
-	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		if err = tx.Exec("DROP TABLE IF EXISTS tmp_installed").Error; err != nil {
-			return fmt.Errorf(app.T_("Temporary table drop error: %w"), err)
-		}
+	err = app.RetryBusy(ctx, func() error {
+		return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err = tx.Exec("DROP TABLE IF EXISTS tmp_installed").Error; err != nil {
+				return fmt.Errorf(app.TL_(ctx, "Temporary table drop error: %w"), err)
+			}
 
-		if err = tx.Exec("CREATE TEMPORARY TABLE tmp_installed (name TEXT PRIMARY KEY, version TEXT)").Error; err != nil {
-			return fmt.Errorf(app.T_("Temporary table creation error: %w"), err)
-		}
+			if err = tx.Exec("CREATE TEMPORARY TABLE tmp_installed (name TEXT PRIMARY KEY, version TEXT)").Error; err != nil {
+				return fmt.Errorf(app.TL_(ctx, "Temporary table creation error: %w"), err)
+			}
 
-		var rows []map[string]interface{}
-		for name, version := range installedPackages {
-			rows = append(rows, map[string]interface{}{
-				"name":    name,
-				"version": version,
-			})
-		}
-		if len(rows) > 0 {
-			if err = tx.Table("tmp_installed").Create(rows).Error; err != nil {
-				return fmt.Errorf(app.T_("Batch insert into temporary table error: %w"), err)
+			var rows []map[string]interface{}
+			for name, version := range installedPackages {
+				rows = append(rows, map[string]interface{}{
+					"name":    name,
+					"version": version,
+				})
+			}
+			if len(rows) > 0 {
+				if err = tx.Table("tmp_installed").Create(rows).Error; err != nil {
+					return fmt.Errorf(app.TL_(ctx, "Batch insert into temporary table error: %w"), err)
+				}
 			}
-		}
 
-		updateSQL := `
+			updateSQL := `
 			UPDATE host_image_packages
 			SET
 				installed = CASE
@@ -357,13 +418,19 @@ func (s *PackageDBService) SyncPackageInstallationInfo(ctx context.Context, inst
 					''
 				)
 		`
-		if err = tx.Exec(updateSQL).Error; err != nil {
-			return fmt.Errorf(app.T_("Batch update error: %w"), err)
-		}
+			if err = tx.Exec(updateSQL).Error; err != nil {
+				return fmt.Errorf(app.TL_(ctx, "Batch update error: %w"), err)
+			}
 
-		return nil
+			return nil
+		})
 	})
-	return err
+	if err != nil {
+		return err
+	}
+
+	app.NotifyDatabaseChanged(s.dbManager, app.DatabaseSystem)
+	return nil
 }
 
 // SearchPackagesByNameLike ищет пакеты по произвольному шаблону LIKE
@@ -373,8 +440,9 @@ func (s *PackageDBService) SearchPackagesByNameLike(ctx context.Context, likePat
 		return nil, err
 	}
 
+	nameCond, nameArgs := likeAny("name", likePattern)
 	query := db.WithContext(ctx).Model(&DBPackage{}).
-		Where("name LIKE ?", likePattern)
+		Where(nameCond, nameArgs...)
 
 	if installed {
 		query = query.Where("installed = ?", true)
@@ -382,18 +450,19 @@ func (s *PackageDBService) SearchPackagesByNameLike(ctx context.Context, likePat
 
 	var dbPkgs []DBPackage
 	if err = query.Find(&dbPkgs).Error; err != nil {
-		return nil, fmt.Errorf(app.T_("Query execution error: %w"), err)
+		return nil, fmt.Errorf(app.TL_(ctx, "Query execution error: %w"), err)
 	}
 
 	// Fallback: поиск по файлам если по имени ничего не нашли
 	if len(dbPkgs) == 0 {
+		filesCond, filesArgs := likeAny("files", likePattern)
 		query = db.WithContext(ctx).Model(&DBPackage{}).
-			Where("files LIKE ?", likePattern)
+			Where(filesCond, filesArgs...)
 		if installed {
 			query = query.Where("installed = ?", true)
 		}
 		if err = query.Find(&dbPkgs).Error; err != nil {
-			return nil, fmt.Errorf(app.T_("Query execution error: %w"), err)
+			return nil, fmt.Errorf(app.TL_(ctx, "Query execution error: %w"), err)
 		}
 	}
 
@@ -404,6 +473,187 @@ func (s *PackageDBService) SearchPackagesByNameLike(ctx context.Context, likePat
 	return result, nil
 }
 
+// likeAny строит условие LIKE по всем нормализованным вариантам шаблона (регистр, транслитерация)
+func likeAny(column string, likePattern string) (string, []interface{}) {
+	variants := helper.SearchVariants(likePattern)
+	conds := make([]string, 0, len(variants))
+	args := make([]interface{}, 0, len(variants))
+	for _, v := range variants {
+		conds = append(conds, column+" LIKE ?")
+		args = append(args, v)
+	}
+	return "(" + strings.Join(conds, " OR ") + ")", args
+}
+
+// reverseDependsChunk сколько capability проверяется в одном запросе
+const reverseDependsChunk = 100
+
+// GetReverseDependencies возвращает пакеты, в зависимостях которых есть хотя бы одна из capability.
+func (s *PackageDBService) GetReverseDependencies(ctx context.Context, capabilities []string, installed bool) ([]Package, error) {
+	if len(capabilities) == 0 {
+		return nil, nil
+	}
+
+	db, err := s.db()
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(capabilities))
+	for _, c := range capabilities {
+		wanted[c] = true
+	}
+
+	seen := make(map[string]bool)
+	var result []Package
+	for start := 0; start < len(capabilities); start += reverseDependsChunk {
+		chunk := capabilities[start:min(start+reverseDependsChunk, len(capabilities))]
+
+		conditions := make([]string, 0, len(chunk))
+		args := make([]interface{}, 0, len(chunk))
+		for _, c := range chunk {
+			conditions = append(conditions, "(',' || depends || ',') LIKE ?")
+			args = append(args, "%,"+c+",%")
+		}
+
+		query := db.WithContext(ctx).Model(&DBPackage{}).
+			Where(strings.Join(conditions, " OR "), args...)
+		if installed {
+			query = query.Where("installed = ?", true)
+		}
+
+		var dbPkgs []DBPackage
+		if err = query.Find(&dbPkgs).Error; err != nil {
+			return nil, fmt.Errorf(app.TL_(ctx, "Query execution error: %w"), err)
+		}
+
+		// LIKE трактует _ и % как шаблон, поэтому совпадение проверяется точно
+		for _, dbp := range dbPkgs {
+			pkg := dbp.fromDBModel()
+			if seen[pkg.Name] {
+				continue
+			}
+			for _, dep := range pkg.Depends {
+				if wanted[dep] {
+					seen[pkg.Name] = true
+					result = append(result, pkg)
+					break
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// GetProviders возвращает пакеты, предоставляющие (Provides) любую из capability
+func (s *PackageDBService) GetProviders(ctx context.Context, capabilities []string) ([]Package, error) {
+	if len(capabilities) == 0 {
+		return nil, nil
+	}
+
+	db, err := s.db()
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(capabilities))
+	for _, c := range capabilities {
+		wanted[c] = true
+	}
+
+	seen := make(map[string]bool)
+	var result []Package
+	for start := 0; start < len(capabilities); start += reverseDependsChunk {
+		chunk := capabilities[start:min(start+reverseDependsChunk, len(capabilities))]
+
+		conditions := make([]string, 0, len(chunk))
+		args := make([]interface{}, 0, len(chunk))
+		for _, c := range chunk {
+			conditions = append(conditions, "(',' || provides || ',') LIKE ?")
+			args = append(args, "%,"+c+",%")
+		}
+
+		var dbPkgs []DBPackage
+		if err = db.WithContext(ctx).Model(&DBPackage{}).
+			Where(strings.Join(conditions, " OR "), args...).
+			Find(&dbPkgs).Error; err != nil {
+			return nil, fmt.Errorf(app.TL_(ctx, "Query execution error: %w"), err)
+		}
+
+		// LIKE трактует _ и % как шаблон, поэтому совпадение проверяется точно
+		for _, dbp := range dbPkgs {
+			pkg := dbp.fromDBModel()
+			if seen[pkg.Name] {
+				continue
+			}
+			for _, prov := range pkg.Provides {
+				if wanted[prov] {
+					seen[pkg.Name] = true
+					result = append(result, pkg)
+					break
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// FindPackagesProviding ищет в индексе репозиториев пакеты, содержащие файл по абсолютному пути
+// или предоставляющие библиотеку либо возможность с указанным именем.
+func (s *PackageDBService) FindPackagesProviding(ctx context.Context, target string) ([]Package, error) {
+	db, err := s.db()
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.WithContext(ctx).Model(&DBPackage{})
+	if strings.HasPrefix(target, "/") {
+		query = query.Where("(',' || files || ',') LIKE ?", "%,"+target+",%")
+	} else {
+		query = query.Where("(',' || provides || ',') LIKE ? OR (',' || files || ',') LIKE ?",
+			"%,"+target+"%", "%/"+target+",%")
+	}
+
+	var dbPkgs []DBPackage
+	if err = query.Order("name").Find(&dbPkgs).Error; err != nil {
+		return nil, fmt.Errorf(app.TL_(ctx, "Query execution error: %w"), err)
+	}
+
+	// LIKE трактует _ и % как шаблон, поэтому совпадение проверяется точно
+	var result []Package
+	for _, dbp := range dbPkgs {
+		if pkg := dbp.fromDBModel(); pkg.provides(target) {
+			result = append(result, pkg)
+		}
+	}
+	return result, nil
+}
+
+// provides проверяет, содержит ли пакет файл target или предоставляет ли возможность target.
+// Имя библиотеки совпадает и с версионированными вариантами: libbar.so находит libbar.so.1()(64bit).
+func (p Package) provides(target string) bool {
+	if strings.HasPrefix(target, "/") {
+		return slices.Contains(p.Files, target)
+	}
+
+	for _, prov := range p.Provides {
+		if prov == target {
+			return true
+		}
+		if rest, ok := strings.CutPrefix(prov, target); ok && (rest[0] == '.' || rest[0] == '(') {
+			return true
+		}
+	}
+	for _, file := range p.Files {
+		if path.Base(file) == target {
+			return true
+		}
+	}
+	return false
+}
+
 // SearchPackagesMultiLimit ищет пакеты по произвольному шаблону LIKE для автодополнения
 func (s *PackageDBService) SearchPackagesMultiLimit(ctx context.Context, likePattern string, limit int, installed bool) ([]Package, error) {
 	if limit <= 0 {
@@ -415,9 +665,10 @@ func (s *PackageDBService) SearchPackagesMultiLimit(ctx context.Context, likePat
 		return nil, err
 	}
 
+	nameCond, nameArgs := likeAny("name", likePattern)
 	query := db.WithContext(ctx).
 		Model(&DBPackage{}).
-		Where("name LIKE ?", likePattern).
+		Where(nameCond, nameArgs...).
 		Limit(limit)
 
 	if installed {
@@ -426,19 +677,20 @@ func (s *PackageDBService) SearchPackagesMultiLimit(ctx context.Context, likePat
 
 	var dbPkgs []DBPackage
 	if err = query.Find(&dbPkgs).Error; err != nil {
-		return nil, fmt.Errorf(app.T_("Query execution error: %w"), err)
+		return nil, fmt.Errorf(app.TL_(ctx, "Query execution error: %w"), err)
 	}
 
 	// Fallback: поиск по файлам если по имени ничего не нашли
 	if len(dbPkgs) == 0 {
+		filesCond, filesArgs := likeAny("files", likePattern)
 		query = db.WithContext(ctx).Model(&DBPackage{}).
-			Where("files LIKE ?", likePattern).
+			Where(filesCond, filesArgs...).
 			Limit(limit)
 		if installed {
 			query = query.Where("installed = ?", true)
 		}
 		if err = query.Find(&dbPkgs).Error; err != nil {
-			return nil, fmt.Errorf(app.T_("Query execution error: %w"), err)
+			return nil, fmt.Errorf(app.TL_(ctx, "Query execution error: %w"), err)
 		}
 	}
 
@@ -486,7 +738,7 @@ func (s *PackageDBService) QueryHostImagePackages(
 
 	var dbPkgs []DBPackage
 	if err = query.Find(&dbPkgs).Error; err != nil {
-		return nil, fmt.Errorf(app.T_("Query execution error: %w"), err)
+		return nil, fmt.Errorf(app.TL_(ctx, "Query execution error: %w"), err)
 	}
 
 	// Преобразование к бизнес-структурам
@@ -510,7 +762,7 @@ func (s *PackageDBService) CountHostImagePackages(ctx context.Context, filters [
 
 	var totalCount int64
 	if err = query.Count(&totalCount).Error; err != nil {
-		return 0, fmt.Errorf(app.T_("Package count error: %w"), err)
+		return 0, fmt.Errorf(app.TL_(ctx, "Package count error: %w"), err)
 	}
 
 	return totalCount, nil
@@ -608,6 +860,47 @@ func installedApplier(query *gorm.DB, f filter.Filter) (*gorm.DB, bool) {
 	return query.Where(clause.Eq{Column: col, Value: boolVal}), true
 }
 
+// heldApplier обрабатывает фильтр held с ParseBool.
+func heldApplier(query *gorm.DB, f filter.Filter) (*gorm.DB, bool) {
+	boolVal, ok := helper.ParseBool(f.Value)
+	if !ok {
+		return query, true
+	}
+	col := clause.Column{Name: "held"}
+	if f.Op == filter.OpNe {
+		return query.Where(clause.Neq{Column: col, Value: boolVal}), true
+	}
+	return query.Where(clause.Eq{Column: col, Value: boolVal}), true
+}
+
+// upgradableCondition отбирает установленные пакеты, версия кандидата которых новее установленной
+const upgradableCondition = "installed = 1 AND versionInstalledRaw != '' AND apm_candidate_newer(versionRaw, versionInstalledRaw)"
+
+func init() {
+	app.RegisterSQLFunction("apm_candidate_newer", candidateNewer)
+}
+
+// upgradableApplier обрабатывает виртуальный фильтр upgradable с ParseBool.
+func upgradableApplier(query *gorm.DB, f filter.Filter) (*gorm.DB, bool) {
+	boolVal, ok := helper.ParseBool(f.Value)
+	if !ok {
+		return query, true
+	}
+	if f.Op == filter.OpNe {
+		boolVal = !boolVal
+	}
+	if boolVal {
+		return query.Where(upgradableCondition), true
+	}
+	return query.Where("NOT (" + upgradableCondition + ")"), true
+}
+
+// candidateNewer сообщает, что версия кандидата из кэша новее установленной. Обе версии
+// сравниваются полностью, с эпохой и релизом.
+func candidateNewer(candidateRaw, installedRaw string) bool {
+	return helper.CompareVersions(candidateRaw, installedRaw) > 0
+}
+
 // SaveSinglePackage сохраняет один пакет в базу данных без очистки таблицы
 func (s *PackageDBService) SaveSinglePackage(ctx context.Context, pkg Package) error {
 	dbPkg := pkg.toDBModel()
@@ -653,7 +946,7 @@ func (s *PackageDBService) PackageDatabaseExist(ctx context.Context) error {
 	}
 
 	if count == 0 {
-		return fmt.Errorf(app.T_("Table %s exists but contains no records"), DBPackage{}.TableName())
+		return fmt.Errorf(app.TL_(ctx, "Table %s exists but contains no records"), DBPackage{}.TableName())
 	}
 
 	return nil
@@ -666,7 +959,7 @@ func (s *PackageDBService) UpdateAppStreamLinks(ctx context.Context) error {
 		return err
 	}
 
-	return db.WithContext(ctx).Exec(`
+	err = db.WithContext(ctx).Exec(`
 		UPDATE host_image_packages
 		SET idAppStream = (
 			SELECT id FROM host_appstream_components
@@ -678,6 +971,12 @@ func (s *PackageDBService) UpdateAppStreamLinks(ctx context.Context) error {
 			WHERE host_appstream_components.pkgname = host_image_packages.name
 		)
 	`).Error
+	if err != nil {
+		return err
+	}
+
+	// Ключевые слова AppStream входят в полнотекстовый индекс
+	return s.RebuildSearchIndex(ctx)
 }
 
 // GetSections возвращает список уникальных секций пакетов.
@@ -719,11 +1018,14 @@ var SystemFilterConfig = &filter.Config{
 			"summary":          {DefaultOp: filter.OpLike, Sortable: true, AllowedOps: []filter.Op{filter.OpEq, filter.OpNe, filter.OpLike}, Extra: map[string]any{"type": "STRING", "description": "Short package summary"}},
 			"changelog":        {DefaultOp: filter.OpLike, Sortable: true, AllowedOps: []filter.Op{filter.OpEq, filter.OpNe, filter.OpLike}, Extra: map[string]any{"type": "STRING", "description": "Last changelog entry"}},
 			"installed":        {DefaultOp: filter.OpEq, Sortable: true, AllowedOps: []filter.Op{filter.OpEq, filter.OpNe}, Extra: map[string]any{"type": "BOOL", "description": "Installation status"}},
+			"held":             {DefaultOp: filter.OpEq, Sortable: true, AllowedOps: []filter.Op{filter.OpEq, filter.OpNe}, Extra: map[string]any{"type": "BOOL", "description": "Held back from upgrades"}},
+			"upgradable":       {DefaultOp: filter.OpEq, AllowedOps: []filter.Op{filter.OpEq, filter.OpNe}, Extra: map[string]any{"type": "BOOL", "description": "Installed with a newer candidate version"}},
 			"typePackage": {DefaultOp: filter.OpEq, Sortable: true, AllowedOps: []filter.Op{filter.OpEq, filter.OpNe}, Extra: map[string]any{
 				"type":        "ENUM",
 				"description": app.T_("Package type"),
 				"info": map[PackageType]string{
 					PackageTypeSystem: "System package",
+					PackageTypeStplr:  "STPLR package",
 				},
 			}},
 			"files": {DefaultOp: filter.OpContains, AllowedOps: []filter.Op{filter.OpEq, filter.OpNe, filter.OpLike, filter.OpContains}, Extra: map[string]any{"type": "STRING", "description": "Package file list"}},
@@ -741,6 +1043,8 @@ var SystemFilterApplier = &filter.GormApplier{
 		appliers := swcat.PrefixedAppliers(swcat.AppStreamPrefix, appStreamApplier)
 		appliers["isApp"] = isAppApplier
 		appliers["installed"] = installedApplier
+		appliers["held"] = heldApplier
+		appliers["upgradable"] = upgradableApplier
 		return appliers
 	}(),
 }
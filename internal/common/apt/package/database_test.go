@@ -1,6 +1,7 @@
 package _package
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -165,3 +166,47 @@ func assertSliceEqual(t *testing.T, name string, got, want []string) {
 		}
 	}
 }
+
+func TestLikeAny(t *testing.T) {
+	cond, args := likeAny("name", "%vim%")
+	if cond != "(name LIKE ?)" || len(args) != 1 || args[0] != "%vim%" {
+		t.Errorf("unexpected condition for latin pattern: %s %v", cond, args)
+	}
+
+	cond, args = likeAny("name", "%Хром%")
+	if len(args) < 3 || cond != "("+strings.TrimSuffix(strings.Repeat("name LIKE ? OR ", len(args)), " OR ")+")" {
+		t.Fatalf("unexpected condition for cyrillic pattern: %s %v", cond, args)
+	}
+	found := false
+	for _, a := range args {
+		if a == "%chrom%" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected transliterated variant %%chrom%%, got %v", args)
+	}
+}
+
+func TestPackageProvides(t *testing.T) {
+	pkg := Package{
+		Name:     "libbar",
+		Provides: []string{"libbar.so.1()(64bit)", "bar-api"},
+		Files:    []string{"/usr/bin/bar", "/usr/lib64/libbar.so.1"},
+	}
+
+	for target, want := range map[string]bool{
+		"/usr/bin/bar":   true,
+		"/usr/bin/ba":    false,
+		"libbar.so":      true,
+		"libbar.so.1":    true,
+		"libba":          false,
+		"bar-api":        true,
+		"bar":            true,
+		"/usr/lib64/bar": false,
+	} {
+		if got := pkg.provides(target); got != want {
+			t.Errorf("provides(%q) = %v, want %v", target, got, want)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package _package
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/helper"
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// searchIndexTable полнотекстовый индекс FTS5 по названию, описанию и ключевым словам AppStream.
+const searchIndexTable = "host_image_packages_fts"
+
+// Веса колонок индекса для ранжирования bm25: совпадение в названии важнее совпадения в описании.
+// Версия хранится для связи с таблицей пакетов и в поиске не участвует.
+const searchIndexWeights = "10.0, 5.0, 1.0, 3.0, 0.0"
+
+// initSearchIndex создаёт индекс, если SQLite собран с FTS5 (тег сборки sqlite_fts5), и заполняет
+// его для уже существующей базы. Без FTS5 поиск по описанию выполняется через LIKE. Подключение
+// только для чтения использует индекс, созданный миграциями.
+func (s *PackageDBService) initSearchIndex(db *gorm.DB, readOnly bool) {
+	var exists int64
+	db.Raw("SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = ?", searchIndexTable).Scan(&exists)
+	if readOnly {
+		s.searchIndex = exists > 0
+		return
+	}
+
+	err := db.Exec(fmt.Sprintf(
+		"CREATE VIRTUAL TABLE IF NOT EXISTS %s USING fts5(name, summary, description, keywords, version UNINDEXED, tokenize = 'unicode61 remove_diacritics 2')",
+		searchIndexTable,
+	)).Error
+	if err != nil {
+		app.Log.Debugf("full-text search is unavailable, falling back to LIKE: %v", err)
+		return
+	}
+	s.searchIndex = true
+
+	if exists == 0 {
+		if err = s.rebuildSearchIndex(db); err != nil {
+			app.Log.Debugf("failed to build the search index: %v", err)
+		}
+	}
+}
+
+// rebuildSearchIndex перезаполняет индекс из таблицы пакетов и компонентов AppStream.
+func (s *PackageDBService) rebuildSearchIndex(tx *gorm.DB) error {
+	if !s.searchIndex {
+		return nil
+	}
+
+	keywords := "''"
+	var appstream int64
+	tx.Raw("SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'host_appstream_components'").Scan(&appstream)
+	if appstream > 0 {
+		keywords = `COALESCE((
+			SELECT group_concat(kv.value, ' ')
+			FROM host_appstream_components ac,
+			json_each(ac.components) AS comp,
+			json_each(json_extract(comp.value, '$.keywords')) AS kv
+			WHERE ac.pkgname = p.name
+		), '')`
+	}
+
+	if err := tx.Exec("DELETE FROM " + searchIndexTable).Error; err != nil {
+		return fmt.Errorf(app.T_("Failed to update the search index: %w"), err)
+	}
+	err := tx.Exec(fmt.Sprintf(
+		"INSERT INTO %s (name, summary, description, keywords, version) SELECT p.name, p.summary, p.description, %s, p.version FROM host_image_packages p",
+		searchIndexTable, keywords,
+	)).Error
+	if err != nil {
+		return fmt.Errorf(app.T_("Failed to update the search index: %w"), err)
+	}
+	return nil
+}
+
+// RebuildSearchIndex перестраивает полнотекстовый индекс, например после обновления данных AppStream.
+func (s *PackageDBService) RebuildSearchIndex(ctx context.Context) error {
+	db, err := s.db()
+	if err != nil {
+		return err
+	}
+	return app.RetryBusy(ctx, func() error {
+		return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return s.rebuildSearchIndex(tx)
+		})
+	})
+}
+
+// SearchPackagesFullText ищет пакеты по названию, описанию и ключевым словам AppStream. Все слова
+// запроса должны встретиться в пакете, результаты упорядочены по релевантности.
+func (s *PackageDBService) SearchPackagesFullText(ctx context.Context, text string, installed bool) ([]Package, error) {
+	db, err := s.db()
+	if err != nil {
+		return nil, err
+	}
+
+	var dbPkgs []DBPackage
+	if s.searchIndex {
+		match := ftsQuery(text)
+		if match == "" {
+			return []Package{}, nil
+		}
+		query := db.WithContext(ctx).Table(searchIndexTable).
+			Select("p.*").
+			Joins(fmt.Sprintf("JOIN host_image_packages p ON p.name = %[1]s.name AND p.version = %[1]s.version", searchIndexTable)).
+			Where(searchIndexTable+" MATCH ?", match).
+			Order(fmt.Sprintf("bm25(%s, %s), p.name", searchIndexTable, searchIndexWeights))
+		if installed {
+			query = query.Where("p.installed = ?", true)
+		}
+		err = query.Find(&dbPkgs).Error
+	} else {
+		query := db.WithContext(ctx).Model(&DBPackage{})
+		words := ftsWords(text)
+		if len(words) == 0 {
+			return []Package{}, nil
+		}
+		for _, word := range words {
+			pattern := "%" + word + "%"
+			nameCond, nameArgs := likeAny("name", pattern)
+			summaryCond, summaryArgs := likeAny("summary", pattern)
+			descCond, descArgs := likeAny("description", pattern)
+			query = query.Where(
+				fmt.Sprintf("(%s) OR (%s) OR (%s)", nameCond, summaryCond, descCond),
+				append(append(nameArgs, summaryArgs...), descArgs...)...,
+			)
+		}
+		if installed {
+			query = query.Where("installed = ?", true)
+		}
+		// Совпадения в названии выше остальных
+		nameCond, nameArgs := likeAny("name", "%"+words[0]+"%")
+		err = query.Select(fmt.Sprintf("*, CASE WHEN %s THEN 0 ELSE 1 END AS name_rank", nameCond), nameArgs...).
+			Order("name_rank, name").
+			Find(&dbPkgs).Error
+	}
+	if err != nil {
+		return nil, fmt.Errorf(app.TL_(ctx, "Query execution error: %w"), err)
+	}
+
+	result := make([]Package, 0, len(dbPkgs))
+	for _, dbp := range dbPkgs {
+		result = append(result, dbp.fromDBModel())
+	}
+	return result, nil
+}
+
+// ftsWords разбивает запрос на слова по разделителям.
+func ftsWords(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// ftsQuery строит выражение MATCH: каждое слово ищется как префикс, все слова обязательны.
+// Для запросов на кириллице добавляются варианты транслитерации через OR.
+func ftsQuery(text string) string {
+	var groups []string
+	for _, variant := range helper.SearchVariants(text) {
+		words := ftsWords(variant)
+		if len(words) == 0 {
+			continue
+		}
+		terms := make([]string, 0, len(words))
+		for _, w := range words {
+			terms = append(terms, `"`+w+`"*`)
+		}
+		group := strings.Join(terms, " ")
+		if len(words) > 1 {
+			group = "(" + group + ")"
+		}
+		groups = append(groups, group)
+	}
+	return strings.Join(groups, " OR ")
+}
@@ -0,0 +1,162 @@
+package _package
+
+import (
+	"apm/internal/common/filter"
+	"context"
+	"database/sql"
+	"slices"
+	"strings"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type memoryDBManager struct {
+	db *sql.DB
+}
+
+func (m *memoryDBManager) GetSystemDB() (*sql.DB, error) { return m.db, nil }
+func (m *memoryDBManager) GetUserDB() (*sql.DB, error)   { return m.db, nil }
+func (m *memoryDBManager) Close() error                  { return m.db.Close() }
+
+func newTestDBService(t *testing.T, packages ...Package) *PackageDBService {
+	t.Helper()
+	// Драйвер apm регистрирует функции, которые используют фильтры
+	conn, err := sql.Open("sqlite3_apm", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	s := NewPackageDBService(&memoryDBManager{db: conn}, nil)
+	db, err := s.db()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pkg := range packages {
+		dbp := pkg.toDBModel()
+		if err = db.Create(&dbp).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = s.rebuildSearchIndex(db); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func names(packages []Package) []string {
+	result := make([]string, 0, len(packages))
+	for _, pkg := range packages {
+		result = append(result, pkg.Name)
+	}
+	return result
+}
+
+func TestSearchPackagesFullText(t *testing.T) {
+	s := newTestDBService(t,
+		Package{Name: "vim", Version: "9.0", Summary: "Text editor", Description: "Vi improved", Installed: true},
+		Package{Name: "editor-tools", Version: "1.0", Summary: "Tools", Description: "Helpers for a text editor"},
+		Package{Name: "htop", Version: "3.0", Summary: "Process viewer", Description: "Interactive process viewer"},
+	)
+	ctx := context.Background()
+
+	found, err := s.SearchPackagesFullText(ctx, "editor", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(names(found), []string{"editor-tools", "vim"}) {
+		t.Errorf("name matches must rank first, got %v", names(found))
+	}
+
+	found, _ = s.SearchPackagesFullText(ctx, "improved", false)
+	if !slices.Equal(names(found), []string{"vim"}) {
+		t.Errorf("description must be searched, got %v", names(found))
+	}
+
+	found, _ = s.SearchPackagesFullText(ctx, "text editor", true)
+	if !slices.Equal(names(found), []string{"vim"}) {
+		t.Errorf("installed filter must apply, got %v", names(found))
+	}
+
+	found, _ = s.SearchPackagesFullText(ctx, "process editor", false)
+	if len(found) != 0 {
+		t.Errorf("all words must match, got %v", names(found))
+	}
+}
+
+func TestSearchIndexKeywords(t *testing.T) {
+	s := newTestDBService(t)
+	if !s.searchIndex {
+		t.Skip("SQLite is built without FTS5")
+	}
+	db, _ := s.db()
+	for _, stmt := range []string{
+		"CREATE TABLE host_appstream_components (id INTEGER PRIMARY KEY, pkgname TEXT, components TEXT)",
+		`INSERT INTO host_appstream_components (pkgname, components) VALUES ('krita', '[{"keywords":["painting","sketch"]}]')`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	dbp := Package{Name: "krita", Version: "5.2", Summary: "Digital art"}.toDBModel()
+	if err := db.Create(&dbp).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RebuildSearchIndex(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := s.SearchPackagesFullText(context.Background(), "paint", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(names(found), []string{"krita"}) {
+		t.Errorf("AppStream keywords must be indexed, got %v", names(found))
+	}
+}
+
+func TestFtsQuery(t *testing.T) {
+	tests := map[string]string{
+		"vim":              `"vim"*`,
+		"Text  editor":     `("text"* "editor"*)`,
+		`"quoted" OR -not`: `("quoted"* "or"* "not"*)`,
+		"  ":               "",
+	}
+	for in, want := range tests {
+		if got := ftsQuery(in); got != want {
+			t.Errorf("ftsQuery(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := ftsQuery("редактор"); !strings.HasPrefix(got, `"редактор"* OR `) {
+		t.Errorf("cyrillic query must include transliteration variants, got %q", got)
+	}
+}
+
+func TestUpgradableFilter(t *testing.T) {
+	s := newTestDBService(t,
+		Package{Name: "vim", Version: "9.1", VersionRaw: "2:9.1-alt1", VersionInstalled: "9.0", VersionInstalledRaw: "2:9.0-alt3", Installed: true},
+		Package{Name: "htop", Version: "3.0", VersionRaw: "3.0-alt2", VersionInstalled: "3.0", VersionInstalledRaw: "3.0-alt1", Installed: true},
+		Package{Name: "zip", Version: "3.1", VersionRaw: "3.1-alt1"},
+		Package{Name: "mc", Version: "4.8.30", VersionRaw: "4.8.30-alt1", VersionInstalled: "4.8.9", VersionInstalledRaw: "4.8.9-alt1", Installed: true},
+		Package{Name: "less", Version: "643", VersionRaw: "643-alt1", VersionInstalled: "643", VersionInstalledRaw: "643-alt1", Installed: true},
+	)
+
+	filters := []filter.Filter{{Field: "upgradable", Op: filter.OpEq, Value: "true"}}
+	packages, err := s.QueryHostImagePackages(context.Background(), filters, "name", "ASC", 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(packages); !slices.Equal(got, []string{"htop", "mc", "vim"}) {
+		t.Errorf("expected htop, mc and vim to be upgradable, got %v", got)
+	}
+
+	count, err := s.CountHostImagePackages(context.Background(), filters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Errorf("expected 3 upgradable packages, got %d", count)
+	}
+}
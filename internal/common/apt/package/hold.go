@@ -0,0 +1,242 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package _package
+
+import (
+	"apm/internal/common/app"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultPreferencesFile файл настроек APT, в котором закрепляются удерживаемые пакеты.
+const DefaultPreferencesFile = "/etc/apt/preferences"
+
+const (
+	holdBlockBegin = "# BEGIN apm hold"
+	holdBlockEnd   = "# END apm hold"
+	holdPriority   = 1001
+)
+
+// DBHeldPackage описывает пакет, удерживаемый от обновления.
+type DBHeldPackage struct {
+	Name    string    `gorm:"column:name;primaryKey"`
+	Created time.Time `gorm:"column:created"`
+}
+
+// TableName задаёт имя таблицы.
+func (DBHeldPackage) TableName() string {
+	return "host_held_packages"
+}
+
+// markHeldPackages переносит признак удержания в таблицу пакетов.
+func markHeldPackages(tx *gorm.DB) error {
+	return tx.Exec(`
+		UPDATE host_image_packages
+		SET held = EXISTS (
+			SELECT 1 FROM host_held_packages h WHERE h.name = host_image_packages.name
+		)
+	`).Error
+}
+
+// HoldPackages помечает пакеты как удерживаемые от обновления.
+func (s *PackageDBService) HoldPackages(ctx context.Context, names []string) error {
+	return s.changeHeld(ctx, func(tx *gorm.DB) error {
+		now := time.Now()
+		rows := make([]DBHeldPackage, 0, len(names))
+		for _, name := range names {
+			rows = append(rows, DBHeldPackage{Name: name, Created: now})
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error
+	})
+}
+
+// UnholdPackages снимает удержание с пакетов.
+func (s *PackageDBService) UnholdPackages(ctx context.Context, names []string) error {
+	return s.changeHeld(ctx, func(tx *gorm.DB) error {
+		return tx.Where("name IN ?", names).Delete(&DBHeldPackage{}).Error
+	})
+}
+
+// changeHeld изменяет список удерживаемых пакетов и синхронизирует таблицу пакетов.
+func (s *PackageDBService) changeHeld(ctx context.Context, change func(tx *gorm.DB) error) error {
+	syncDBMutex.Lock()
+	defer syncDBMutex.Unlock()
+
+	db, err := s.db()
+	if err != nil {
+		return err
+	}
+
+	err = app.RetryBusy(ctx, func() error {
+		return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if errChange := change(tx); errChange != nil {
+				return errChange
+			}
+			return markHeldPackages(tx)
+		})
+	})
+	if err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Failed to update held packages: %w"), err)
+	}
+
+	app.NotifyDatabaseChanged(s.dbManager, app.DatabaseSystem)
+	return nil
+}
+
+// GetHeldPackages возвращает имена удерживаемых пакетов.
+func (s *PackageDBService) GetHeldPackages(ctx context.Context) ([]string, error) {
+	db, err := s.db()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	if err = db.WithContext(ctx).Model(&DBHeldPackage{}).Order("name").Pluck("name", &names).Error; err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// loadHeldPackages передаёт список удерживаемых пакетов в APT перед обновлением.
+func (a *Actions) loadHeldPackages(ctx context.Context) error {
+	held, err := a.serviceAptDatabase.GetHeldPackages(ctx)
+	if err != nil {
+		return err
+	}
+	a.serviceAptBinding.SetHeldPackages(held)
+	return nil
+}
+
+// SyncHoldPreferences закрепляет установленные версии удерживаемых пакетов в настройках APT.
+// Закрепляется точная версия с эпохой и релизом.
+func (a *Actions) SyncHoldPreferences(ctx context.Context) error {
+	held, err := a.serviceAptDatabase.GetHeldPackages(ctx)
+	if err != nil {
+		return err
+	}
+
+	pins := make(map[string]string, len(held))
+	if len(held) > 0 {
+		packages, errFind := a.serviceAptDatabase.GetPackagesByNames(ctx, held)
+		if errFind != nil {
+			return errFind
+		}
+		for _, pkg := range packages {
+			if pkg.Installed && pkg.VersionInstalledRaw != "" && slices.Contains(held, pkg.Name) {
+				pins[pkg.Name] = pkg.VersionInstalledRaw
+			}
+		}
+	}
+
+	existing, err := os.ReadFile(a.preferencesFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	content := renderHoldPreferences(string(existing), pins)
+	if content == "" && errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return os.WriteFile(a.preferencesFile, []byte(content), 0644)
+}
+
+// renderHoldPreferences заменяет управляемый блок закреплений в содержимом файла preferences.
+func renderHoldPreferences(existing string, pins map[string]string) string {
+	var kept []string
+	inBlock := false
+	for _, line := range strings.Split(existing, "\n") {
+		switch {
+		case strings.TrimSpace(line) == holdBlockBegin:
+			inBlock = true
+		case strings.TrimSpace(line) == holdBlockEnd:
+			inBlock = false
+		case !inBlock:
+			kept = append(kept, line)
+		}
+	}
+
+	content := strings.TrimRight(strings.Join(kept, "\n"), "\n")
+	if len(pins) == 0 {
+		if content == "" {
+			return ""
+		}
+		return content + "\n"
+	}
+
+	var b strings.Builder
+	if content != "" {
+		b.WriteString(content)
+		b.WriteString("\n\n")
+	}
+	b.WriteString(holdBlockBegin + "\n")
+	names := make([]string, 0, len(pins))
+	for name := range pins {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for i, name := range names {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "Package: %s\nPin: version %s\nPin-Priority: %d\n", name, pins[name], holdPriority)
+	}
+	b.WriteString(holdBlockEnd + "\n")
+	return b.String()
+}
+
+// DeleteOrphanedHeld удаляет записи об удержании пакетов, которых нет ни среди доступных в базе пакетов,
+// ни среди установленных (installed), и возвращает число удалённых записей.
+// Пока база пакетов пуста, ничего не удаляется.
+func (s *PackageDBService) DeleteOrphanedHeld(ctx context.Context, installed map[string]string) (int64, error) {
+	var deleted int64
+	err := s.changeHeld(ctx, func(tx *gorm.DB) error {
+		var available int64
+		if err := tx.Model(&DBPackage{}).Count(&available).Error; err != nil {
+			return err
+		}
+		if available == 0 {
+			return nil
+		}
+
+		var orphaned []string
+		if err := tx.Model(&DBHeldPackage{}).
+			Where("name NOT IN (?)", tx.Model(&DBPackage{}).Select("name")).
+			Pluck("name", &orphaned).Error; err != nil {
+			return err
+		}
+		orphaned = slices.DeleteFunc(orphaned, func(name string) bool {
+			_, ok := installed[name]
+			return ok
+		})
+		if len(orphaned) == 0 {
+			return nil
+		}
+
+		result := tx.Where("name IN ?", orphaned).Delete(&DBHeldPackage{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return deleted, err
+}
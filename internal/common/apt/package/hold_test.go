@@ -0,0 +1,33 @@
+package _package
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderHoldPreferences(t *testing.T) {
+	existing := "Package: foo\nPin: release a=p11\nPin-Priority: 900\n"
+
+	got := renderHoldPreferences(existing, map[string]string{"vim": "2:9.0-alt1", "bash": "5.2-alt3"})
+	if !strings.HasPrefix(got, existing+"\n"+holdBlockBegin+"\n") {
+		t.Fatalf("expected user pins to be kept before the managed block, got %q", got)
+	}
+	if strings.Index(got, "Package: bash") > strings.Index(got, "Package: vim") {
+		t.Errorf("expected pins sorted by name, got %q", got)
+	}
+	if !strings.Contains(got, "Package: vim\nPin: version 2:9.0-alt1\nPin-Priority: 1001\n") {
+		t.Errorf("expected vim pinned to installed version, got %q", got)
+	}
+
+	again := renderHoldPreferences(got, map[string]string{"vim": "2:9.0-alt1"})
+	if strings.Count(again, holdBlockBegin) != 1 || strings.Contains(again, "Package: bash") {
+		t.Errorf("expected managed block to be replaced, got %q", again)
+	}
+
+	if cleared := renderHoldPreferences(got, nil); cleared != existing {
+		t.Errorf("expected managed block to be removed, got %q", cleared)
+	}
+	if empty := renderHoldPreferences("", nil); empty != "" {
+		t.Errorf("expected empty file without pins, got %q", empty)
+	}
+}
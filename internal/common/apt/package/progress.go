@@ -84,7 +84,7 @@ func (a *Actions) getHandler(ctx context.Context, packageCount ...int) func(pkg
 			if percent < 100 && downloadThrottle.ShouldUpdate(percent) {
 				downloadThrottle.RecordUpdate(percent)
 
-				viewText := app.T_("Downloading packages")
+				viewText := app.TL_(ctx, "Downloading packages")
 				if speedStr := helper.FormatSpeed(speed); speedStr != "" {
 					viewText += "  " + speedStr
 				}
@@ -100,9 +100,9 @@ func (a *Actions) getHandler(ctx context.Context, packageCount ...int) func(pkg
 			if !downloadStarted {
 				return
 			}
-			doneText := app.T_("All packages downloaded")
+			doneText := app.TL_(ctx, "All packages downloaded")
 			if pkgCount == 1 {
-				doneText = app.T_("Package downloaded")
+				doneText = app.TL_(ctx, "Package downloaded")
 			}
 			a.reporter.CreateEventNotification(ctx, reply.StateAfter,
 				reply.WithEventName(reply.EventSystemDownloadProgress),
@@ -166,15 +166,15 @@ func (a *Actions) getHandler(ctx context.Context, packageCount ...int) func(pkg
 						reply.WithEventName(ev),
 						reply.WithProgress(true),
 						reply.WithProgressPercent(float64(percent)),
-						reply.WithEventView(fmt.Sprintf(app.T_("Installing progress: %s"), pkg)),
+						reply.WithEventView(fmt.Sprintf(app.TL_(ctx, "Installing progress: %s"), pkg)),
 					)
 				} else {
 					a.reporter.CreateEventNotification(ctx, reply.StateAfter,
 						reply.WithEventName(ev),
 						reply.WithProgress(true),
 						reply.WithProgressPercent(100),
-						reply.WithEventView(fmt.Sprintf(app.T_("Installing %s"), pkg)),
-						reply.WithProgressDoneText(fmt.Sprintf(app.T_("Installing %s"), pkg)),
+						reply.WithEventView(fmt.Sprintf(app.TL_(ctx, "Installing %s"), pkg)),
+						reply.WithProgressDoneText(fmt.Sprintf(app.TL_(ctx, "Installing %s"), pkg)),
 					)
 
 					// Удаляем из отслеживания
@@ -192,6 +192,8 @@ func (a *Actions) getUpdateHandler(ctx context.Context) aptLib.ProgressHandler {
 	}
 	items := make(map[string]*itemState)
 	done := make(map[string]bool)
+	overall := newProgressThrottler()
+	overallStarted := false
 	var mu sync.Mutex
 	nextID := 1
 
@@ -224,22 +226,54 @@ func (a *Actions) getUpdateHandler(ctx context.Context) aptLib.ProgressHandler {
 				id := state.id
 				mu.Unlock()
 
-				viewText := pkg
-				if speedStr := helper.FormatSpeed(speed); speedStr != "" {
-					viewText += "  " + speedStr
-				}
-
 				ev := fmt.Sprintf("%s-%d", reply.EventSystemAptUpdate, id)
 				a.reporter.CreateEventNotification(ctx, reply.StateBefore,
 					reply.WithEventName(ev),
 					reply.WithProgress(true),
 					reply.WithProgressPercent(float64(percent)),
-					reply.WithEventView(viewText),
+					reply.WithEventView(downloadView(pkg, cur, total, speed)),
 				)
 			} else {
 				mu.Unlock()
 			}
 
+		case aptLib.CallbackDownloadProgress:
+			if total == 0 {
+				return
+			}
+
+			mu.Lock()
+			overallStarted = true
+			percent := int((cur * 100) / total)
+			if percent >= 100 || !overall.ShouldUpdate(percent) {
+				mu.Unlock()
+				return
+			}
+			overall.RecordUpdate(percent)
+			mu.Unlock()
+
+			a.reporter.CreateEventNotification(ctx, reply.StateBefore,
+				reply.WithEventName(reply.EventSystemAptUpdateProgress),
+				reply.WithProgress(true),
+				reply.WithProgressPercent(float64(percent)),
+				reply.WithEventView(downloadView(app.TL_(ctx, "Downloading package lists"), cur, total, speed)),
+			)
+
+		case aptLib.CallbackDownloadComplete:
+			mu.Lock()
+			started := overallStarted
+			overallStarted = false
+			mu.Unlock()
+
+			if started {
+				a.reporter.CreateEventNotification(ctx, reply.StateAfter,
+					reply.WithEventName(reply.EventSystemAptUpdateProgress),
+					reply.WithProgress(true),
+					reply.WithProgressPercent(100),
+					reply.WithProgressDoneText(app.TL_(ctx, "Package lists downloaded")),
+				)
+			}
+
 		case aptLib.CallbackDownloadStop:
 			mu.Lock()
 			state, tracked := items[pkg]
@@ -266,3 +300,15 @@ func (a *Actions) getUpdateHandler(ctx context.Context) aptLib.ProgressHandler {
 		}
 	}
 }
+
+// downloadView формирует текст прогресса загрузки: загружено/всего, скорость и оставшееся время.
+func downloadView(title string, cur, total, speed uint64) string {
+	view := fmt.Sprintf("%s  %s / %s", title, helper.FormatBytes(cur), helper.FormatBytes(total))
+	if speedStr := helper.FormatSpeed(speed); speedStr != "" {
+		view += "  " + speedStr
+	}
+	if eta := helper.FormatETA(cur, total, speed); eta != "" {
+		view += "  " + fmt.Sprintf(app.T_("ETA %s"), eta)
+	}
+	return view
+}
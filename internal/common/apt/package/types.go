@@ -24,27 +24,31 @@ import (
 
 // Package описывает структуру для хранения информации о пакете.
 type Package struct {
-	Name             string            `json:"name"`
-	Architecture     string            `json:"architecture"`
-	Section          string            `json:"section"`
-	InstalledSize    int               `json:"installedSize"`
-	Maintainer       string            `json:"maintainer"`
-	Version          string            `json:"version"`
-	VersionRaw       string            `json:"versionRaw"`
-	VersionInstalled string            `json:"versionInstalled"`
-	Depends          []string          `json:"depends"`
-	Aliases          []string          `json:"aliases"`
-	Provides         []string          `json:"provides"`
-	Size             int               `json:"size"`
-	Filename         string            `json:"filename"`
-	Summary          string            `json:"summary"`
-	Description      string            `json:"description"`
-	AppStream        []swcat.Component `json:"appStream,omitempty"`
-	HasAppStream     bool              `json:"-"`
-	Changelog        string            `json:"lastChangelog"`
-	Installed        bool              `json:"installed"`
-	TypePackage      int               `json:"typePackage"`
-	Files            []string          `json:"files"`
+	Name                string            `json:"name"`
+	Architecture        string            `json:"architecture"`
+	Section             string            `json:"section"`
+	InstalledSize       int               `json:"installedSize"`
+	Maintainer          string            `json:"maintainer"`
+	Version             string            `json:"version"`
+	VersionRaw          string            `json:"versionRaw"`
+	VersionInstalled    string            `json:"versionInstalled"`
+	VersionInstalledRaw string            `json:"versionInstalledRaw"`
+	Depends             []string          `json:"depends"`
+	Aliases             []string          `json:"aliases"`
+	Provides            []string          `json:"provides"`
+	Size                int               `json:"size"`
+	Filename            string            `json:"filename"`
+	LocalFile           string            `json:"localFile,omitempty"`
+	Group               string            `json:"group,omitempty"`
+	Summary             string            `json:"summary"`
+	Description         string            `json:"description"`
+	AppStream           []swcat.Component `json:"appStream,omitempty"`
+	HasAppStream        bool              `json:"-"`
+	Changelog           string            `json:"lastChangelog"`
+	Installed           bool              `json:"installed"`
+	Held                bool              `json:"held"`
+	TypePackage         int               `json:"typePackage"`
+	Files               []string          `json:"files"`
 }
 
 type PackageType uint8
@@ -0,0 +1,281 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package _package
+
+import (
+	"apm/internal/common/app"
+	aptBinding "apm/internal/common/binding/apt"
+	aptLib "apm/internal/common/binding/apt/lib"
+	"apm/internal/common/reply"
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	// defaultArchivesDir каталог скачанных пакетов APT по умолчанию
+	defaultArchivesDir = "/var/cache/apt/archives"
+	// archivesConfigKey ключ конфигурации APT с каталогом скачанных пакетов
+	archivesConfigKey = "Dir::Cache::Archives"
+	// verifyDownloadRetries сколько раз повторно скачивать архивы, не прошедшие проверку
+	verifyDownloadRetries = 1
+	// defaultSourcesList и defaultSourceParts расположение источников APT по умолчанию
+	defaultSourcesList = "/etc/apt/sources.list"
+	defaultSourceParts = "/etc/apt/sources.list.d"
+	// defaultEtcDir каталог, относительно которого APT ищет sources.list, если путь не абсолютный
+	defaultEtcDir = "/etc/apt"
+)
+
+// ChecksumError ошибка проверки контрольных сумм или подписей скачанных пакетов.
+type ChecksumError struct {
+	Failed []aptBinding.RpmCheckResult
+	// locale язык клиента, на котором формируется сообщение
+	locale string
+}
+
+func (e *ChecksumError) Error() string {
+	lines := make([]string, 0, len(e.Failed)+1)
+	lines = append(lines, app.TL_(app.WithLocale(context.Background(), e.locale), "Downloaded packages failed checksum or signature verification:"))
+	for _, f := range e.Failed {
+		lines = append(lines, fmt.Sprintf("%s: %s", filepath.Base(f.Path), f.Output))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// downloadAndVerify скачивает пакеты функцией download (в режиме «только скачать») и до запуска установки
+// проверяет через rpm -K все архивы транзакции, в том числе уже лежавшие в кеше. Повреждённые архивы удаляются
+// и скачиваются заново с другого источника: порядок активных источников сдвигается (см. useAlternateSources),
+// и APT берёт архив из следующего зеркала, в котором есть та же версия пакета. Если источник один или версия
+// есть только в одном из них, архив скачивается оттуда же. Если архив не прошёл проверку и после повторной
+// загрузки, возвращается ChecksumError со списком пакетов.
+func (a *Actions) downloadAndVerify(ctx context.Context, handler aptLib.ProgressHandler, download func(aptLib.ProgressHandler) error) error {
+	if !a.appConfig.ConfigManager.GetConfig().VerifyDownloads {
+		return nil
+	}
+
+	restore := func() {}
+	defer func() { restore() }()
+
+	for attempt := 0; ; attempt++ {
+		collector := &archiveCollector{handler: handler}
+		if err := download(collector.progress); err != nil {
+			return err
+		}
+
+		archives := collector.archives()
+		if len(archives) == 0 {
+			return nil
+		}
+
+		failed, err := a.verifyArchives(ctx, archives)
+		if err != nil {
+			return err
+		}
+		if len(failed) == 0 {
+			return nil
+		}
+
+		for _, f := range failed {
+			if errRemove := os.Remove(f.Path); errRemove != nil && !os.IsNotExist(errRemove) {
+				app.Log.Warning(fmt.Sprintf("failed to remove corrupted archive %s: %v", f.Path, errRemove))
+			}
+		}
+
+		if attempt >= verifyDownloadRetries {
+			return &ChecksumError{Failed: failed, locale: app.LocaleFromContext(ctx)}
+		}
+
+		app.Log.Warning(fmt.Sprintf("%d archives failed verification and will be downloaded again", len(failed)))
+
+		restore()
+		if restore, err = a.useAlternateSources(attempt + 1); err != nil {
+			restore = func() {}
+			return err
+		}
+	}
+}
+
+// useAlternateSources применяет временную конфигурацию APT, в которой активные источники сдвинуты на shift позиций.
+// Порядок источников определяет порядок файлов версии в кеше APT, а значит и зеркало, с которого скачивается
+// архив. Списки пакетов остаются прежними, во временном каталоге пересобирается только кеш.
+// Возвращает функцию, восстанавливающую прежние переопределения.
+func (a *Actions) useAlternateSources(shift int) (func(), error) {
+	previous := a.GetAptConfigOverrides()
+
+	sources, err := activeSources(previous)
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) < 2 {
+		app.Log.Warning("only one package source is configured, corrupted archives are downloaded from the same source")
+		return func() {}, nil
+	}
+
+	dir, err := os.MkdirTemp("", "apm-sources-")
+	if err != nil {
+		return nil, err
+	}
+
+	sourcesList := filepath.Join(dir, "sources.list")
+	sourceParts := filepath.Join(dir, "sources.list.d")
+	if err = os.Mkdir(sourceParts, 0755); err == nil {
+		err = os.WriteFile(sourcesList, []byte(strings.Join(rotateSources(sources, shift), "\n")+"\n"), 0644)
+	}
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+
+	overrides := make(map[string]string, len(previous)+4)
+	maps.Copy(overrides, previous)
+	overrides["Dir::Etc::sourcelist"] = sourcesList
+	overrides["Dir::Etc::sourceparts"] = sourceParts
+	overrides["Dir::Cache::pkgcache"] = filepath.Join(dir, "pkgcache.bin")
+	overrides["Dir::Cache::srcpkgcache"] = filepath.Join(dir, "srcpkgcache.bin")
+	a.SetAptConfigOverrides(overrides)
+
+	return func() {
+		a.SetAptConfigOverrides(previous)
+		_ = os.RemoveAll(dir)
+	}, nil
+}
+
+// activeSources возвращает активные бинарные источники (строки rpm) из sources.list и sources.list.d
+// с учётом переопределений конфигурации APT.
+func activeSources(overrides map[string]string) ([]string, error) {
+	files := []string{etcPath(configValue(overrides, "Dir::Etc::sourcelist"), defaultSourcesList)}
+	parts, err := filepath.Glob(filepath.Join(etcPath(configValue(overrides, "Dir::Etc::sourceparts"), defaultSourceParts), "*.list"))
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, parts...)
+
+	var sources []string
+	for _, file := range files {
+		data, errRead := os.ReadFile(file)
+		if errRead != nil {
+			if os.IsNotExist(errRead) {
+				continue
+			}
+			return nil, errRead
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if fields := strings.Fields(line); len(fields) > 0 && fields[0] == "rpm" {
+				sources = append(sources, line)
+			}
+		}
+	}
+	return sources, nil
+}
+
+// rotateSources возвращает источники, циклически сдвинутые на shift позиций.
+func rotateSources(sources []string, shift int) []string {
+	if len(sources) == 0 {
+		return nil
+	}
+	shift %= len(sources)
+	return append(slices.Clone(sources[shift:]), sources[:shift]...)
+}
+
+// etcPath возвращает путь из конфигурации APT; относительные пути отсчитываются от /etc/apt.
+func etcPath(value, fallback string) string {
+	switch {
+	case value == "":
+		return fallback
+	case filepath.IsAbs(value):
+		return value
+	default:
+		return filepath.Join(defaultEtcDir, value)
+	}
+}
+
+// archiveCollector передаёт события прогресса в handler и собирает пути архивов транзакции
+type archiveCollector struct {
+	mu      sync.Mutex
+	handler aptLib.ProgressHandler
+	paths   []string
+}
+
+func (c *archiveCollector) progress(pkg string, event aptLib.ProgressType, cur, total, speed uint64) {
+	if event == aptLib.CallbackDownloadArchive {
+		c.mu.Lock()
+		c.paths = append(c.paths, pkg)
+		c.mu.Unlock()
+		return
+	}
+	if c.handler != nil {
+		c.handler(pkg, event, cur, total, speed)
+	}
+}
+
+// archives возвращает отсортированные пути архивов без повторов
+func (c *archiveCollector) archives() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	paths := slices.Clone(c.paths)
+	slices.Sort(paths)
+	return slices.Compact(paths)
+}
+
+// verifyArchives проверяет архивы и возвращает не прошедшие проверку.
+func (a *Actions) verifyArchives(ctx context.Context, paths []string) ([]aptBinding.RpmCheckResult, error) {
+	a.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventSystemVerifyPackages))
+	defer a.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventSystemVerifyPackages))
+
+	results, err := a.serviceAptBinding.RpmCheckSig(ctx, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	var failed []aptBinding.RpmCheckResult
+	for _, r := range results {
+		if !r.OK {
+			failed = append(failed, r)
+		}
+	}
+	return failed, nil
+}
+
+// ArchivesDir возвращает каталог, в который APT скачивает пакеты.
+func (a *Actions) ArchivesDir() string {
+	return archivesDir(a.GetAptConfigOverrides())
+}
+
+// archivesDir возвращает каталог скачанных пакетов с учётом переопределений конфигурации APT.
+func archivesDir(overrides map[string]string) string {
+	if value := configValue(overrides, archivesConfigKey); value != "" {
+		return value
+	}
+	return defaultArchivesDir
+}
+
+// configValue возвращает значение переопределения конфигурации APT; ключи APT не зависят от регистра.
+func configValue(overrides map[string]string, key string) string {
+	for k, value := range overrides {
+		if strings.EqualFold(k, key) {
+			return value
+		}
+	}
+	return ""
+}
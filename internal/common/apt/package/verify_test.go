@@ -0,0 +1,136 @@
+package _package
+
+import (
+	aptBinding "apm/internal/common/binding/apt"
+	aptLib "apm/internal/common/binding/apt/lib"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestArchivesDir(t *testing.T) {
+	if got := archivesDir(nil); got != defaultArchivesDir {
+		t.Errorf("expected default dir, got %s", got)
+	}
+	if got := archivesDir(map[string]string{"dir::cache::archives": "/tmp/archives/"}); got != "/tmp/archives/" {
+		t.Errorf("expected override dir, got %s", got)
+	}
+}
+
+func TestArchiveCollector(t *testing.T) {
+	var forwarded []aptLib.ProgressType
+	collector := &archiveCollector{handler: func(_ string, event aptLib.ProgressType, _, _, _ uint64) {
+		forwarded = append(forwarded, event)
+	}}
+
+	collector.progress("", aptLib.CallbackDownloadProgress, 50, 100, 0)
+	collector.progress("/var/cache/apt/archives/vim-9.0-alt1.x86_64.rpm", aptLib.CallbackDownloadArchive, 0, 0, 0)
+	collector.progress("/var/cache/apt/archives/bash-5.2-alt1.x86_64.rpm", aptLib.CallbackDownloadArchive, 0, 0, 0)
+	collector.progress("/var/cache/apt/archives/vim-9.0-alt1.x86_64.rpm", aptLib.CallbackDownloadArchive, 0, 0, 0)
+
+	if !slices.Equal(forwarded, []aptLib.ProgressType{aptLib.CallbackDownloadProgress}) {
+		t.Errorf("expected only progress events forwarded, got %v", forwarded)
+	}
+	want := []string{"/var/cache/apt/archives/bash-5.2-alt1.x86_64.rpm", "/var/cache/apt/archives/vim-9.0-alt1.x86_64.rpm"}
+	if got := collector.archives(); !slices.Equal(got, want) {
+		t.Errorf("expected all transaction archives, got %v", got)
+	}
+}
+
+func TestChecksumError(t *testing.T) {
+	err := &ChecksumError{Failed: []aptBinding.RpmCheckResult{
+		{Path: "/var/cache/apt/archives/vim-9.0-alt1.x86_64.rpm", Output: "DIGESTS NOT OK"},
+	}}
+	if !strings.Contains(err.Error(), "vim-9.0-alt1.x86_64.rpm: DIGESTS NOT OK") {
+		t.Errorf("unexpected error text: %s", err.Error())
+	}
+}
+
+func TestUseAlternateSources(t *testing.T) {
+	dir := t.TempDir()
+	sourcesList := filepath.Join(dir, "sources.list")
+	sourceParts := filepath.Join(dir, "sources.list.d")
+	if err := os.Mkdir(sourceParts, 0755); err != nil {
+		t.Fatal(err)
+	}
+	main := "rpm [alt] http://ftp.altlinux.org/pub/distributions/ALTLinux Sisyphus/x86_64 classic"
+	mirror := "rpm [alt] http://mirror.yandex.ru/altlinux Sisyphus/x86_64 classic"
+	if err := os.WriteFile(sourcesList, []byte("# comment\n"+main+"\nrpm-src [alt] http://ftp.altlinux.org/pub x86_64 classic\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceParts, "mirror.list"), []byte("#"+main+"\n"+mirror+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	previous := map[string]string{"Dir::Etc::sourcelist": sourcesList, "Dir::Etc::sourceparts": sourceParts}
+	a := &Actions{serviceAptBinding: aptBinding.NewActions()}
+	a.SetAptConfigOverrides(previous)
+
+	restore, err := a.useAlternateSources(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	overrides := a.GetAptConfigOverrides()
+	data, err := os.ReadFile(overrides["Dir::Etc::sourcelist"])
+	if err != nil {
+		t.Fatalf("rotated sources.list not written: %v", err)
+	}
+	if got := strings.Split(strings.TrimSpace(string(data)), "\n"); !slices.Equal(got, []string{mirror, main}) {
+		t.Errorf("expected the mirror first, got %v", got)
+	}
+	if overrides["Dir::Etc::sourceparts"] == sourceParts || overrides["Dir::Cache::pkgcache"] == "" {
+		t.Errorf("expected a separate sources.list.d and pkgcache, got %v", overrides)
+	}
+
+	restore()
+	if got := a.GetAptConfigOverrides(); got["Dir::Etc::sourcelist"] != sourcesList || len(got) != len(previous) {
+		t.Errorf("expected previous overrides restored, got %v", got)
+	}
+	if _, err = os.Stat(filepath.Dir(overrides["Dir::Etc::sourcelist"])); !os.IsNotExist(err) {
+		t.Errorf("expected temporary sources removed, got %v", err)
+	}
+}
+
+func TestUseAlternateSourcesSingleSource(t *testing.T) {
+	sourcesList := filepath.Join(t.TempDir(), "sources.list")
+	if err := os.WriteFile(sourcesList, []byte("rpm [alt] http://ftp.altlinux.org/pub/distributions/ALTLinux Sisyphus/x86_64 classic\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	previous := map[string]string{"Dir::Etc::sourcelist": sourcesList, "Dir::Etc::sourceparts": t.TempDir()}
+	a := &Actions{serviceAptBinding: aptBinding.NewActions()}
+	a.SetAptConfigOverrides(previous)
+
+	restore, err := a.useAlternateSources(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer restore()
+
+	if got := a.GetAptConfigOverrides(); got["Dir::Etc::sourcelist"] != sourcesList {
+		t.Errorf("expected overrides unchanged with a single source, got %v", got)
+	}
+}
+
+func TestRotateSources(t *testing.T) {
+	sources := []string{"a", "b", "c"}
+	tests := []struct {
+		shift int
+		want  []string
+	}{
+		{0, []string{"a", "b", "c"}},
+		{1, []string{"b", "c", "a"}},
+		{4, []string{"b", "c", "a"}},
+	}
+	for _, tt := range tests {
+		if got := rotateSources(sources, tt.shift); !slices.Equal(got, tt.want) {
+			t.Errorf("rotateSources(%d) = %v, want %v", tt.shift, got, tt.want)
+		}
+	}
+	if !slices.Equal(sources, []string{"a", "b", "c"}) {
+		t.Errorf("input modified: %v", sources)
+	}
+}
@@ -129,6 +129,15 @@ AptResult execute_transaction(AptCache *cache,
         }
 
         if (download_only) {
+            // Report every archive of the transaction, including ones already in the cache
+            if (global_callback != nullptr) {
+                for (auto I = acquire.ItemsBegin(); I != acquire.ItemsEnd(); ++I) {
+                    if ((*I)->Status == pkgAcquire::Item::StatDone && !(*I)->DestFile.empty()) {
+                        global_callback((*I)->DestFile.c_str(), APT_CALLBACK_DOWNLOAD_ARCHIVE, 0, 0, 0,
+                                        global_user_data);
+                    }
+                }
+            }
             return make_result(APT_SUCCESS, nullptr);
         }
 
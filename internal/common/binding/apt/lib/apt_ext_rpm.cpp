@@ -2,6 +2,10 @@
 #include "error.h"
 #include "ext_rpm.h"
 
+#include <rpm/rpmdb.h>
+#include <rpm/rpmlib.h>
+#include <rpm/rpmts.h>
+
 #include <set>
 #include <sys/stat.h>
 
@@ -56,3 +60,41 @@ AptResult apt_preprocess_install_arguments(const char **install_names, const siz
 void apt_clear_install_arguments() {
     _config->Clear("APT::Arguments");
 }
+
+// Erases headers selected by rpmdb instance, so copies with identical NEVRA can be told apart.
+AptResult apt_rpmdb_remove_headers(const unsigned int *instances, const size_t count) {
+    if (!instances || count == 0) {
+        return make_result(APT_SUCCESS, nullptr);
+    }
+
+    if (rpmReadConfigFiles(nullptr, nullptr) != 0) {
+        return make_result(APT_ERROR_INIT_FAILED, "Failed to read rpm configuration");
+    }
+
+    rpmts ts = rpmtsCreate();
+    rpmtsSetRootDir(ts, "/");
+
+    for (size_t i = 0; i < count; i++) {
+        unsigned int instance = instances[i];
+        rpmdbMatchIterator mi = rpmtsInitIterator(ts, RPMDBI_PACKAGES, &instance, sizeof(instance));
+        Header h = mi ? rpmdbNextIterator(mi) : nullptr;
+        if (!h) {
+            rpmdbFreeIterator(mi);
+            rpmtsFree(ts);
+            return make_result(APT_ERROR_PACKAGE_NOT_FOUND,
+                               ("rpmdb header instance " + std::to_string(instance) + " not found").c_str());
+        }
+        rpmtsAddEraseElement(ts, h, -1);
+        rpmdbFreeIterator(mi);
+    }
+
+    // Files and scriptlets belong to the remaining copy with the same NEVRA, only the record goes away.
+    rpmtsSetFlags(ts, static_cast<rpmtransFlags>(RPMTRANS_FLAG_JUSTDB | RPMTRANS_FLAG_NOSCRIPTS | RPMTRANS_FLAG_NOTRIGGERS));
+    const int rc = rpmtsRun(ts, nullptr, RPMPROB_FILTER_NONE);
+    rpmtsFree(ts);
+
+    if (rc != 0) {
+        return make_result(APT_ERROR_OPERATION_FAILED, "Failed to remove rpmdb headers");
+    }
+    return make_result(APT_SUCCESS, nullptr);
+}
@@ -19,10 +19,12 @@ struct AptTransaction {
     std::vector<std::string> install_names;
     std::vector<std::string> remove_names;
     std::vector<std::string> reinstall_names;
+    std::vector<std::string> hold_names;
     bool purge = false;
     bool remove_depends = false;
     bool is_dist_upgrade = false;
     bool is_autoremove = false;
+    bool is_fix_broken = false;
 };
 
 // Allocates a new transaction bound to the given cache.
@@ -94,6 +96,19 @@ AptResult apt_transaction_dist_upgrade(AptTransaction *tx) {
     return make_result(APT_SUCCESS, nullptr);
 }
 
+// Appends package names that must stay at their installed versions.
+AptResult apt_transaction_hold(AptTransaction *tx, const char **names, const size_t count) {
+    if (!tx) return make_result(APT_ERROR_INVALID_PARAMETERS, APT_MSG_NULL_TRANSACTION);
+    if (!names || count == 0) return make_result(APT_ERROR_INVALID_PARAMETERS, APT_MSG_NO_PACKAGE_NAMES);
+
+    for (size_t i = 0; i < count; i++) {
+        if (names[i]) {
+            tx->hold_names.emplace_back(names[i]);
+        }
+    }
+    return make_result(APT_SUCCESS, nullptr);
+}
+
 // Marks the transaction as an auto remove operation.
 AptResult apt_transaction_autoremove(AptTransaction *tx) {
     if (!tx) return make_result(APT_ERROR_INVALID_PARAMETERS, APT_MSG_NULL_TRANSACTION);
@@ -101,6 +116,13 @@ AptResult apt_transaction_autoremove(AptTransaction *tx) {
     return make_result(APT_SUCCESS, nullptr);
 }
 
+// Marks the transaction as a repair of broken dependencies.
+AptResult apt_transaction_fix_broken(AptTransaction *tx) {
+    if (!tx) return make_result(APT_ERROR_INVALID_PARAMETERS, APT_MSG_NULL_TRANSACTION);
+    tx->is_fix_broken = true;
+    return make_result(APT_SUCCESS, nullptr);
+}
+
 // Converts a vector of strings to a vector of C string pointers.
 static std::vector<const char *> to_cstr_array(const std::vector<std::string> &v) {
     std::vector<const char *> result(v.size());
@@ -110,8 +132,30 @@ static std::vector<const char *> to_cstr_array(const std::vector<std::string> &v
     return result;
 }
 
+// Reverts held packages to their installed versions and re-resolves dependencies.
+static void apply_holds(const AptCache *cache, const std::vector<std::string> &hold_names) {
+    if (hold_names.empty()) {
+        return;
+    }
+
+    pkgProblemResolver Fix(cache->dep_cache);
+    for (const auto &name : hold_names) {
+        pkgCache::PkgIterator pkg = cache->dep_cache->FindPkg(name);
+        if (pkg.end() || pkg->CurrentState != pkgCache::State::Installed) {
+            continue;
+        }
+        cache->dep_cache->MarkKeep(pkg);
+        Fix.Protect(pkg);
+    }
+
+    if (cache->dep_cache->BrokenCount() > 0) {
+        (void) Fix.Resolve(true);
+    }
+}
+
 // Simulates a distribution upgrade and collects the resulting changes.
-static AptResult plan_dist_upgrade(const AptCache *cache, AptPackageChanges *changes) {
+static AptResult plan_dist_upgrade(const AptCache *cache, const std::vector<std::string> &hold_names,
+                                   AptPackageChanges *changes) {
     if (!cache->cache_file) {
         return make_result(APT_ERROR_CACHE_OPEN_FAILED, APT_MSG_CACHE_FILE_NOT_AVAILABLE);
     }
@@ -122,6 +166,7 @@ static AptResult plan_dist_upgrade(const AptCache *cache, AptPackageChanges *cha
         CacheStateGuard stateGuard(cache->dep_cache);
 
         pkgDistUpgrade(*cache->dep_cache);
+        apply_holds(cache, hold_names);
 
         if (cache->dep_cache->BrokenCount() > 0) {
             pkgProblemResolver Fix2(cache->dep_cache);
@@ -237,18 +282,65 @@ static AptResult plan_autoremove(const AptCache *cache, AptPackageChanges *chang
     }
 }
 
+// Simulates fixing broken dependencies and collects the resulting changes.
+static AptResult plan_fix_broken(const AptCache *cache, AptPackageChanges *changes) {
+    if (!cache->cache_file) {
+        return make_result(APT_ERROR_CACHE_OPEN_FAILED, APT_MSG_CACHE_FILE_NOT_AVAILABLE);
+    }
+
+    try {
+        memset(changes, 0, sizeof(AptPackageChanges));
+
+        CacheStateGuard stateGuard(cache->dep_cache);
+
+        if (!pkgFixBroken(*cache->dep_cache) || cache->dep_cache->BrokenCount() > 0) {
+            std::string err = collect_pending_errors();
+            if (err.empty()) err = APT_MSG_BROKEN_DEPS;
+            return make_result(APT_ERROR_DEPENDENCY_BROKEN, err.c_str());
+        }
+
+        if (_error->PendingError()) {
+            return make_result(APT_ERROR_DEPENDENCY_BROKEN);
+        }
+
+        const std::set<std::string> empty_set;
+        std::vector<std::string> extra_installed, upgraded, new_installed, removed, kept_back;
+        uint64_t download_size = 0;
+        int64_t install_size = 0;
+
+        collect_package_changes(cache, empty_set,
+                                extra_installed, upgraded,
+                                new_installed, removed, kept_back, download_size, install_size);
+        extra_installed.clear();
+
+        std::vector<std::pair<std::string, std::string>> essential_list;
+        collect_essential_packages(cache, essential_list);
+
+        populate_changes_structure(changes, extra_installed, upgraded, new_installed, removed,
+                                   kept_back, kept_back.size(), essential_list, download_size, install_size);
+
+        return make_result(APT_SUCCESS, nullptr);
+    } catch (const std::exception &e) {
+        return make_result(APT_ERROR_UNKNOWN, (std::string("Fix broken simulation failed: ") + e.what()).c_str());
+    }
+}
+
 // Dispatches to the appropriate planner based on transaction type.
 AptResult apt_transaction_plan(const AptTransaction *tx, AptPackageChanges *changes) {
     if (!tx || !changes) return make_result(APT_ERROR_INVALID_PARAMETERS, APT_MSG_INVALID_PARAMS);
 
     if (tx->is_dist_upgrade) {
-        return plan_dist_upgrade(tx->cache, changes);
+        return plan_dist_upgrade(tx->cache, tx->hold_names, changes);
     }
 
     if (tx->is_autoremove) {
         return plan_autoremove(tx->cache, changes);
     }
 
+    if (tx->is_fix_broken) {
+        return plan_fix_broken(tx->cache, changes);
+    }
+
     auto inst = to_cstr_array(tx->install_names);
     auto rem = to_cstr_array(tx->remove_names);
     auto reinst = to_cstr_array(tx->reinstall_names);
@@ -275,6 +367,23 @@ AptResult apt_transaction_execute(const AptTransaction *tx,
             if (err.empty()) err = "Distribution upgrade failed";
             return make_result(APT_ERROR_CACHE_OPEN_FAILED, err.c_str());
         }
+        apply_holds(tx->cache, tx->hold_names);
+
+        if (tx->cache->dep_cache->DelCount() == 0 &&
+            tx->cache->dep_cache->InstCount() == 0 &&
+            tx->cache->dep_cache->BadCount() == 0) {
+            return make_result(APT_SUCCESS, nullptr);
+        }
+
+        return execute_transaction(tx->cache, nullptr, callback, user_data, download_only, false);
+    }
+
+    if (tx->is_fix_broken) {
+        if (!pkgFixBroken(*tx->cache->dep_cache) || tx->cache->dep_cache->BrokenCount() > 0) {
+            std::string err = collect_pending_errors();
+            if (err.empty()) err = APT_MSG_BROKEN_DEPS;
+            return make_result(APT_ERROR_DEPENDENCY_BROKEN, err.c_str());
+        }
 
         if (tx->cache->dep_cache->DelCount() == 0 &&
             tx->cache->dep_cache->InstCount() == 0 &&
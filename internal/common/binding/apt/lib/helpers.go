@@ -128,6 +128,21 @@ func ClearInstallArguments() {
 	C.apt_clear_install_arguments()
 }
 
+// RemoveRpmHeaders удаляет записи из базы rpm по номерам экземпляров заголовков, не трогая файлы и скриптлеты
+func RemoveRpmHeaders(instances []uint) error {
+	if len(instances) == 0 {
+		return nil
+	}
+	cInstances := make([]C.uint, len(instances))
+	for i, instance := range instances {
+		cInstances[i] = C.uint(instance)
+	}
+	if res := C.apt_rpmdb_remove_headers(&cInstances[0], C.size_t(len(cInstances))); res.code != C.APT_SUCCESS {
+		return ErrorFromResult(res)
+	}
+	return nil
+}
+
 // withMutex выполняет функцию под защитой глобального мьютекса APT
 func withMutex(fn func() error) error {
 	AptMutex.Lock()
@@ -146,4 +161,3 @@ func openCacheUnsafe(system *System, readOnly bool) (*Cache, error) {
 	runtime.SetFinalizer(c, (*Cache).Close)
 	return c, nil
 }
-
@@ -20,7 +20,8 @@ typedef enum {
     APT_CALLBACK_DOWNLOAD_PROGRESS = 21,
     APT_CALLBACK_DOWNLOAD_STOP = 22,
     APT_CALLBACK_DOWNLOAD_COMPLETE = 23,
-    APT_CALLBACK_DOWNLOAD_ITEM_PROGRESS = 24
+    APT_CALLBACK_DOWNLOAD_ITEM_PROGRESS = 24,
+    APT_CALLBACK_DOWNLOAD_ARCHIVE = 25
 } AptCallbackType;
 
 // Reports progress during package download/install.
@@ -13,6 +13,9 @@ AptResult apt_preprocess_install_arguments(const char **install_names, size_t in
 // Clears RPM file arguments previously added by apt_preprocess_install_arguments().
 void apt_clear_install_arguments(void);
 
+// Removes rpmdb header records by instance number without running scriptlets or touching files.
+AptResult apt_rpmdb_remove_headers(const unsigned int *instances, size_t count);
+
 #ifdef __cplusplus
 }
 #endif
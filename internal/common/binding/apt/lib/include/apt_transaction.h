@@ -26,9 +26,15 @@ AptResult apt_transaction_reinstall(AptTransaction *tx, const char **names, size
 // Marks all upgradable packages for a distribution upgrade.
 AptResult apt_transaction_dist_upgrade(AptTransaction *tx);
 
+// Keeps `names` at their installed versions during a distribution upgrade.
+AptResult apt_transaction_hold(AptTransaction *tx, const char **names, size_t count);
+
 // Marks automatically installed packages with no dependents for removal.
 AptResult apt_transaction_autoremove(AptTransaction *tx);
 
+// Marks the changes needed to fix broken dependencies of installed packages (apt-get -f install).
+AptResult apt_transaction_fix_broken(AptTransaction *tx);
+
 // Simulates the transaction and fills `changes` with the planned result.
 AptResult apt_transaction_plan(const AptTransaction *tx, AptPackageChanges *changes);
 
@@ -35,6 +35,8 @@ const (
 	CallbackDownloadStop         ProgressType = 22
 	CallbackDownloadComplete     ProgressType = 23
 	CallbackDownloadItemProgress ProgressType = 24
+	// CallbackDownloadArchive передаёт в packageName путь архива транзакции после скачивания
+	CallbackDownloadArchive ProgressType = 25
 )
 
 type ProgressHandler func(packageName string, eventType ProgressType, current, total, speed uint64)
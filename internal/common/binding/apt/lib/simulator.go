@@ -57,9 +57,16 @@ func (c *Cache) planWithTransaction(setup func(tx *C.AptTransaction) C.AptResult
 	return changes, err
 }
 
-// SimulateDistUpgrade симулирует обновление системы
-func (c *Cache) SimulateDistUpgrade() (*PackageChanges, error) {
+// SimulateDistUpgrade симулирует обновление системы, оставляя held пакеты на установленных версиях
+func (c *Cache) SimulateDistUpgrade(held []string) (*PackageChanges, error) {
 	return c.planWithTransaction(func(tx *C.AptTransaction) C.AptResult {
+		if len(held) > 0 {
+			cNames := makeCStringArray(held)
+			defer freeCStringArray(cNames)
+			if res := C.apt_transaction_hold(tx, (**C.char)(unsafe.Pointer(&cNames[0])), C.size_t(len(held))); res.code != C.APT_SUCCESS {
+				return res
+			}
+		}
 		return C.apt_transaction_dist_upgrade(tx)
 	}, nil)
 }
@@ -71,6 +78,13 @@ func (c *Cache) SimulateAutoRemove() (*PackageChanges, error) {
 	}, nil)
 }
 
+// SimulateFixBroken симулирует исправление нарушенных зависимостей
+func (c *Cache) SimulateFixBroken() (*PackageChanges, error) {
+	return c.planWithTransaction(func(tx *C.AptTransaction) C.AptResult {
+		return C.apt_transaction_fix_broken(tx)
+	}, nil)
+}
+
 // SimulateInstall симулирует установку пакетов
 func (c *Cache) SimulateInstall(packageNames []string) (*PackageChanges, error) {
 	if len(packageNames) == 0 {
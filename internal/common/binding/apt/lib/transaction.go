@@ -117,6 +117,22 @@ func (tx *Transaction) DistUpgrade() error {
 	})
 }
 
+// Hold оставляет пакеты на установленных версиях при обновлении системы
+func (tx *Transaction) Hold(names []string) error {
+	if len(names) == 0 {
+		return CustomError(AptErrorInvalidParameters, "No package names")
+	}
+	return withMutex(func() error {
+		cNames := makeCStringArray(names)
+		defer freeCStringArray(cNames)
+		res := C.apt_transaction_hold(tx.ptr, (**C.char)(unsafe.Pointer(&cNames[0])), C.size_t(len(names)))
+		if res.code != C.APT_SUCCESS {
+			return ErrorFromResult(res)
+		}
+		return nil
+	})
+}
+
 // AutoRemove помечает транзакцию как автоматическое удаление неиспользуемых пакетов
 func (tx *Transaction) AutoRemove() error {
 	return withMutex(func() error {
@@ -128,6 +144,17 @@ func (tx *Transaction) AutoRemove() error {
 	})
 }
 
+// FixBroken помечает транзакцию как исправление нарушенных зависимостей установленных пакетов
+func (tx *Transaction) FixBroken() error {
+	return withMutex(func() error {
+		res := C.apt_transaction_fix_broken(tx.ptr)
+		if res.code != C.APT_SUCCESS {
+			return ErrorFromResult(res)
+		}
+		return nil
+	})
+}
+
 // Plan выполняет симуляцию транзакции
 func (tx *Transaction) Plan() (*PackageChanges, error) {
 	var changes *PackageChanges
@@ -20,6 +20,7 @@ import (
 	"apm/internal/common/app"
 	"apm/internal/common/apt"
 	"apm/internal/common/binding/apt/lib"
+	"apm/internal/common/network"
 	"strings"
 	"sync"
 )
@@ -34,6 +35,7 @@ var (
 
 type Actions struct {
 	configOverrides map[string]string
+	heldPackages    []string
 }
 
 func NewActions() *Actions {
@@ -50,6 +52,29 @@ func (a *Actions) GetConfigOverrides() map[string]string {
 	return a.configOverrides
 }
 
+// effectiveOverrides объединяет сетевые настройки apm (прокси, ограничение скорости)
+// с переопределениями конфигурации APT; явные переопределения имеют приоритет.
+func (a *Actions) effectiveOverrides() map[string]string {
+	overrides := network.AptOptions(network.Current())
+	if len(overrides) == 0 {
+		return a.configOverrides
+	}
+	for key, value := range a.configOverrides {
+		overrides[key] = value
+	}
+	return overrides
+}
+
+// SetHeldPackages устанавливает пакеты, которые не обновляются при обновлении системы
+func (a *Actions) SetHeldPackages(packages []string) {
+	a.heldPackages = packages
+}
+
+// GetHeldPackages возвращает пакеты, удерживаемые от обновления
+func (a *Actions) GetHeldPackages() []string {
+	return a.heldPackages
+}
+
 func getSystem() (*lib.System, error) {
 	aptSystemOnce.Do(func() {
 		aptSystem, aptSystemErr = lib.NewSystem()
@@ -110,7 +135,7 @@ func (a *Actions) runOperation(opts OperationOptions, fn func(system *lib.System
 	}
 
 	if err == nil {
-		err = lib.WithConfigOverrides(a.configOverrides, func() error {
+		err = lib.WithConfigOverrides(a.effectiveOverrides(), func() error {
 			return fn(system)
 		})
 	}
@@ -219,11 +244,33 @@ func (a *Actions) DistUpgrade(handler lib.ProgressHandler, downloadOnly bool) er
 			if err := tx.DistUpgrade(); err != nil {
 				return err
 			}
+			if len(a.heldPackages) > 0 {
+				if err := tx.Hold(a.heldPackages); err != nil {
+					return err
+				}
+			}
 			return tx.Execute(handler, downloadOnly)
 		})
 	})
 }
 
+// FixBroken исправление нарушенных зависимостей установленных пакетов
+func (a *Actions) FixBroken(handler lib.ProgressHandler) error {
+	return a.runOperation(OperationOptions{}, func(system *lib.System) error {
+		return withCache(system, false, func(cache *lib.Cache) error {
+			tx, err := cache.NewTransaction()
+			if err != nil {
+				return err
+			}
+			defer tx.Close()
+			if err := tx.FixBroken(); err != nil {
+				return err
+			}
+			return tx.Execute(handler, false)
+		})
+	})
+}
+
 // Update обновление локальной базы пакетов
 func (a *Actions) Update(handler lib.ProgressHandler, noLock ...bool) error {
 	skipLock := len(noLock) > 0 && noLock[0]
@@ -289,7 +336,18 @@ func (a *Actions) SimulateRemove(packageNames []string, purge bool, depends bool
 func (a *Actions) SimulateDistUpgrade() (packageChanges *lib.PackageChanges, err error) {
 	err = a.runOperation(OperationOptions{}, func(system *lib.System) error {
 		return withCache(system, false, func(cache *lib.Cache) error {
-			packageChanges, err = cache.SimulateDistUpgrade()
+			packageChanges, err = cache.SimulateDistUpgrade(a.heldPackages)
+			return err
+		})
+	})
+	return
+}
+
+// SimulateFixBroken симуляция исправления нарушенных зависимостей
+func (a *Actions) SimulateFixBroken() (packageChanges *lib.PackageChanges, err error) {
+	err = a.runOperation(OperationOptions{}, func(system *lib.System) error {
+		return withCache(system, false, func(cache *lib.Cache) error {
+			packageChanges, err = cache.SimulateFixBroken()
 			return err
 		})
 	})
@@ -22,6 +22,7 @@ import (
 	"apm/internal/common/helper"
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"os/exec"
 	"strings"
@@ -42,24 +43,55 @@ type KernelRPMInfo struct {
 	BuildTime string
 }
 
+// RpmFileInfo файл из манифеста установленного пакета
+type RpmFileInfo struct {
+	Path  string
+	Flags string
+	Mode  string
+}
+
+// RpmCheckResult результат проверки контрольных сумм и подписи RPM-файла
+type RpmCheckResult struct {
+	Path   string
+	OK     bool
+	Output string
+}
+
 // RpmGetInstalledPackages возвращает карту установленных пакетов (имя -> версия)
 func (a *Actions) RpmGetInstalledPackages(ctx context.Context, commandPrefix string, noLock ...bool) (map[string]string, error) {
 	var result map[string]string
 	skipLock := len(noLock) > 0 && noLock[0]
 
 	err := a.runOperation(OperationOptions{SkipLock: skipLock}, func(_ *lib.System) error {
-		command := fmt.Sprintf("%s rpm -qia", commandPrefix)
-		cmd := exec.CommandContext(ctx, "sh", "-c", command)
-		cmd.Env = []string{"LC_ALL=C"}
+		index, indexErr := installedIndex(ctx, commandPrefix)
+		if indexErr != nil {
+			return indexErr
+		}
 
-		output, cmdErr := cmd.CombinedOutput()
-		if cmdErr != nil {
-			return fmt.Errorf(app.T_("Error executing the rpm -qia command: %w"), cmdErr)
+		result = installedVersions(index)
+		return nil
+	})
+
+	return result, err
+}
+
+// RpmGetInstalledEVRs возвращает карту установленных пакетов (имя -> [эпоха:]версия-релиз)
+func (a *Actions) RpmGetInstalledEVRs(ctx context.Context, commandPrefix string, noLock ...bool) (map[string]string, error) {
+	var result map[string]string
+	skipLock := len(noLock) > 0 && noLock[0]
+
+	err := a.runOperation(OperationOptions{SkipLock: skipLock}, func(_ *lib.System) error {
+		index, indexErr := installedIndex(ctx, commandPrefix)
+		if indexErr != nil {
+			return indexErr
 		}
 
-		var parseErr error
-		result, parseErr = parseRpmQiaOutput(string(output))
-		return parseErr
+		newest := newestInstalled(index)
+		result = make(map[string]string, len(newest))
+		for name, header := range newest {
+			result[name] = header.evr()
+		}
+		return nil
 	})
 
 	return result, err
@@ -70,18 +102,23 @@ func (a *Actions) RpmQueryKernelPackages(ctx context.Context) ([]KernelRPMInfo,
 	var result []KernelRPMInfo
 
 	err := a.runOperation(OperationOptions{}, func(_ *lib.System) error {
-		cmd := exec.CommandContext(ctx, "rpm", "-qa", "--queryformat",
-			"%{NAME}\t%{VERSION}\t%{RELEASE}\t%{BUILDTIME}\n", "kernel-image-*")
-		cmd.Env = []string{"LC_ALL=C"}
-
-		output, cmdErr := cmd.Output()
-		if cmdErr != nil {
-			return fmt.Errorf(app.T_("failed to query installed kernels: %s"), cmdErr.Error())
+		index, indexErr := installedIndex(ctx, "")
+		if indexErr != nil {
+			return fmt.Errorf(app.TL_(ctx, "failed to query installed kernels: %s"), indexErr.Error())
 		}
 
-		var parseErr error
-		result, parseErr = parseKernelRpmOutput(string(output))
-		return parseErr
+		for _, header := range index.withPrefix("kernel-image-") {
+			if strings.Contains(header.Name, "debuginfo") {
+				continue
+			}
+			result = append(result, KernelRPMInfo{
+				Name:      header.Name,
+				Version:   header.Version,
+				Release:   header.Release,
+				BuildTime: header.BuildTime,
+			})
+		}
+		return nil
 	})
 
 	return result, err
@@ -89,26 +126,22 @@ func (a *Actions) RpmQueryKernelPackages(ctx context.Context) ([]KernelRPMInfo,
 
 // RpmIsPackageInstalled проверяет установлен ли пакет
 func (a *Actions) RpmIsPackageInstalled(packageName string) (bool, error) {
-	var installed bool
-
-	err := a.runOperation(OperationOptions{}, func(_ *lib.System) error {
-		cmd := exec.Command("rpm", "-q", packageName)
-		cmdErr := cmd.Run()
-		installed = cmdErr == nil
-		return nil // Не возвращаем ошибку - отсутствие пакета это не ошибка
-	})
-
-	return installed, err
+	return a.RpmIsAnyPackageInstalled([]string{packageName})
 }
 
-// RpmIsAnyPackageInstalled проверяет установлен ли хотя бы один из пакетов
+// RpmIsAnyPackageInstalled проверяет установлен ли хотя бы один из пакетов. Как и в rpm -q,
+// пакет можно указать именем или именем с версией (имя-версия[-релиз[.архитектура]]).
 func (a *Actions) RpmIsAnyPackageInstalled(packageNames []string) (bool, error) {
 	var installed bool
 
 	err := a.runOperation(OperationOptions{}, func(_ *lib.System) error {
+		index, indexErr := installedIndex(context.Background(), "")
+		if indexErr != nil {
+			return indexErr
+		}
+
 		for _, pkgName := range packageNames {
-			cmd := exec.Command("rpm", "-q", pkgName)
-			if cmd.Run() == nil {
+			if index.isInstalled(pkgName) {
 				installed = true
 				return nil
 			}
@@ -119,110 +152,171 @@ func (a *Actions) RpmIsAnyPackageInstalled(packageNames []string) (bool, error)
 	return installed, err
 }
 
-// parseRpmQiaOutput парсит вывод rpm -qia и возвращает карту имя -> версия
-func parseRpmQiaOutput(output string) (map[string]string, error) {
-	installed := make(map[string]string)
-	scanner := bufio.NewScanner(strings.NewReader(output))
-	var currentName, currentVersion, currentArch string
-
-	flushCurrent := func() {
-		if currentName == "" {
-			return
-		}
-		name := currentName
-		if strings.HasPrefix(name, "i586-") && (currentArch == "i586" || currentArch == "i386") {
-			name = strings.TrimPrefix(name, "i586-")
-		}
+// RpmQueryFiles возвращает манифест файлов установленного пакета (аналог rpm -ql) с флагами и правами.
+// Если пакет не установлен, возвращает installed == false без ошибки.
+func (a *Actions) RpmQueryFiles(ctx context.Context, packageName string) (files []RpmFileInfo, installed bool, err error) {
+	err = a.runOperation(OperationOptions{}, func(_ *lib.System) error {
+		cmd := exec.CommandContext(ctx, "rpm", "-q", "--queryformat",
+			"[%{FILENAMES}\t%{FILEFLAGS:fflags}\t%{FILEMODES:perms}\n]", "--", packageName)
+		cmd.Env = []string{"LC_ALL=C"}
 
-		// Если пакет уже есть, выбираем более новую версию
-		if existingVersion, exists := installed[name]; exists {
-			if helper.CompareVersions(currentVersion, existingVersion) > 0 {
-				installed[name] = currentVersion
+		output, cmdErr := cmd.Output()
+		if cmdErr != nil {
+			var exitErr *exec.ExitError
+			if errors.As(cmdErr, &exitErr) {
+				return nil
 			}
-		} else {
-			installed[name] = currentVersion
+			return fmt.Errorf(app.TL_(ctx, "Error executing the rpm -ql command: %w"), cmdErr)
 		}
 
-		currentName, currentVersion, currentArch = "", "", ""
-	}
+		installed = true
+		files = parseRpmFilesOutput(string(output))
+		return nil
+	})
 
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
+	return files, installed, err
+}
 
-		if strings.HasPrefix(line, "Name") {
-			flushCurrent()
-		}
-		if line == "" {
-			flushCurrent()
-			continue
+// RpmQueryOwners возвращает установленные пакеты, которым принадлежит файл (rpm -qf) или которые
+// предоставляют возможность (rpm -q --whatprovides). Если владельцев нет, возвращает пустой список.
+func (a *Actions) RpmQueryOwners(ctx context.Context, target string) (owners []InstalledPackageInfo, err error) {
+	err = a.runOperation(OperationOptions{}, func(_ *lib.System) error {
+		args := []string{"-q", "--queryformat", "%{NAME}\t%{VERSION}-%{RELEASE}\t%{ARCH}\n"}
+		if strings.HasPrefix(target, "/") {
+			args = append(args, "-f")
+		} else {
+			args = append(args, "--whatprovides")
 		}
+		cmd := exec.CommandContext(ctx, "rpm", append(args, "--", target)...)
+		cmd.Env = []string{"LC_ALL=C"}
 
-		if strings.HasPrefix(line, "Name") {
-			parts := strings.SplitN(line, ":", 2)
-			if len(parts) == 2 {
-				currentName = strings.TrimSpace(parts[1])
+		output, cmdErr := cmd.Output()
+		if cmdErr != nil {
+			var exitErr *exec.ExitError
+			if errors.As(cmdErr, &exitErr) {
+				return nil
 			}
-			continue
+			return fmt.Errorf(app.TL_(ctx, "Error executing the rpm -qf command: %w"), cmdErr)
 		}
 
-		if strings.HasPrefix(line, "Version") {
-			parts := strings.SplitN(line, ":", 2)
-			if len(parts) == 2 {
-				currentVersion = strings.TrimSpace(parts[1])
-			}
-			continue
-		}
+		owners = parseRpmOwnersOutput(string(output))
+		return nil
+	})
+
+	return owners, err
+}
+
+// RpmCheckSig проверяет контрольные суммы и подписи RPM-файлов через rpm -K.
+// Каждый файл проверяется отдельно, чтобы повреждённый архив не скрывал результаты остальных.
+func (a *Actions) RpmCheckSig(ctx context.Context, paths []string) ([]RpmCheckResult, error) {
+	results := make([]RpmCheckResult, 0, len(paths))
 
-		if strings.HasPrefix(line, "Architecture") {
-			parts := strings.SplitN(line, ":", 2)
-			if len(parts) == 2 {
-				currentArch = strings.TrimSpace(parts[1])
+	err := a.runOperation(OperationOptions{}, func(_ *lib.System) error {
+		for _, path := range paths {
+			cmd := exec.CommandContext(ctx, "rpm", "-K", "--", path)
+			cmd.Env = []string{"LC_ALL=C"}
+
+			output, cmdErr := cmd.CombinedOutput()
+			if cmdErr != nil {
+				var exitErr *exec.ExitError
+				if !errors.As(cmdErr, &exitErr) {
+					return fmt.Errorf(app.TL_(ctx, "Error executing the rpm -K command: %w"), cmdErr)
+				}
 			}
-			continue
+
+			results = append(results, RpmCheckResult{
+				Path:   path,
+				OK:     cmdErr == nil,
+				Output: strings.TrimSpace(string(output)),
+			})
 		}
+		return nil
+	})
+
+	return results, err
+}
+
+// RpmRemoveHeaders удаляет из базы rpm только записи с указанными номерами экземпляров (%{DBINSTANCE}).
+// Нужен для копий пакета с одинаковым NEVRA, которые rpm -e не различает.
+func (a *Actions) RpmRemoveHeaders(_ context.Context, instances []uint) error {
+	if len(instances) == 0 {
+		return nil
 	}
 
-	flushCurrent()
+	return a.runOperation(OperationOptions{}, func(_ *lib.System) error {
+		return lib.RemoveRpmHeaders(instances)
+	})
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf(app.T_("error scanning RPM output: %w"), err)
+// installedVersions строит карту имя -> версия, для нескольких версий пакета выбирается более новая
+func installedVersions(index *rpmIndex) map[string]string {
+	newest := newestInstalled(index)
+	installed := make(map[string]string, len(newest))
+	for name, header := range newest {
+		installed[name] = header.Version
 	}
+	return installed
+}
+
+// newestInstalled выбирает для каждого имени запись с наибольшей версией. Префикс i586- у
+// 32-битных пакетов отбрасывается.
+func newestInstalled(index *rpmIndex) map[string]rpmHeader {
+	installed := make(map[string]rpmHeader, len(index.byName))
+
+	for _, headers := range index.byName {
+		for _, header := range headers {
+			name := header.Name
+			if strings.HasPrefix(name, "i586-") && (header.Arch == "i586" || header.Arch == "i386") {
+				name = strings.TrimPrefix(name, "i586-")
+			}
 
-	return installed, nil
+			if existing, exists := installed[name]; !exists || helper.CompareVersions(header.evr(), existing.evr()) > 0 {
+				installed[name] = header
+			}
+		}
+	}
+
+	return installed
 }
 
-// parseKernelRpmOutput парсит вывод rpm -qa для ядер
-func parseKernelRpmOutput(output string) ([]KernelRPMInfo, error) {
-	var kernels []KernelRPMInfo
+// parseRpmFilesOutput парсит вывод rpm -q --queryformat с путём, флагами и правами файлов
+func parseRpmFilesOutput(output string) []RpmFileInfo {
+	var files []RpmFileInfo
 	scanner := bufio.NewScanner(strings.NewReader(output))
 
 	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" || line == "(contains no files)" {
 			continue
 		}
 
-		parts := strings.Split(line, "\t")
-		if len(parts) != 4 {
-			continue
+		parts := strings.SplitN(line, "\t", 3)
+		info := RpmFileInfo{Path: parts[0]}
+		if len(parts) > 1 {
+			info.Flags = parts[1]
 		}
-
-		name := strings.TrimSpace(parts[0])
-		if strings.Contains(name, "debuginfo") {
-			continue
+		if len(parts) > 2 {
+			info.Mode = parts[2]
 		}
-
-		kernels = append(kernels, KernelRPMInfo{
-			Name:      name,
-			Version:   strings.TrimSpace(parts[1]),
-			Release:   strings.TrimSpace(parts[2]),
-			BuildTime: strings.TrimSpace(parts[3]),
-		})
+		files = append(files, info)
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf(app.T_("error scanning RPM output: %s"), err.Error())
+	return files
+}
+
+// parseRpmOwnersOutput парсит вывод rpm -q --queryformat с именем, версией и архитектурой пакета
+func parseRpmOwnersOutput(output string) []InstalledPackageInfo {
+	var owners []InstalledPackageInfo
+	seen := make(map[string]bool)
+
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Split(strings.TrimSpace(line), "\t")
+		if len(parts) != 3 || seen[parts[0]] {
+			continue
+		}
+		seen[parts[0]] = true
+		owners = append(owners, InstalledPackageInfo{Name: parts[0], Version: parts[1], Arch: parts[2]})
 	}
 
-	return kernels, nil
+	return owners
 }
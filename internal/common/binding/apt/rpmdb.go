@@ -0,0 +1,212 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package apt
+
+import (
+	"apm/internal/common/app"
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rpmDBDir каталог базы данных rpm, по времени изменения файлов в нём определяется актуальность кэша
+const rpmDBDir = "/var/lib/rpm"
+
+// rpmIndexQueryFormat формат строки rpm -qa для индекса установленных пакетов
+const rpmIndexQueryFormat = "%{NAME}\\t%{EPOCH}\\t%{VERSION}\\t%{RELEASE}\\t%{ARCH}\\t%{BUILDTIME}\\n"
+
+// rpmHeader запись об установленном пакете
+type rpmHeader struct {
+	Name      string
+	Epoch     string
+	Version   string
+	Release   string
+	Arch      string
+	BuildTime string
+}
+
+// rpmIndex снимок rpmdb, полученный одним вызовом rpm -qa
+type rpmIndex struct {
+	stamp  time.Time
+	byName map[string][]rpmHeader
+}
+
+var (
+	rpmIndexMutex sync.Mutex
+	// rpmIndexCache индекс rpmdb хоста
+	rpmIndexCache *rpmIndex
+)
+
+// installedIndex возвращает индекс установленных пакетов для commandPrefix.
+// Повторный запуск rpm происходит только после изменения rpmdb. С commandPrefix rpm запускается
+// в другом окружении (например, в контейнере), чья rpmdb не совпадает с /var/lib/rpm хоста,
+// поэтому такой индекс не кэшируется.
+func installedIndex(ctx context.Context, commandPrefix string) (*rpmIndex, error) {
+	rpmIndexMutex.Lock()
+	defer rpmIndexMutex.Unlock()
+
+	cacheable := commandPrefix == ""
+	var stamp time.Time
+	if cacheable {
+		stamp = rpmDBStamp()
+		if rpmIndexCache != nil && !stamp.IsZero() && rpmIndexCache.stamp.Equal(stamp) {
+			return rpmIndexCache, nil
+		}
+	}
+
+	command := fmt.Sprintf("%s rpm -qa --queryformat '%s'", commandPrefix, rpmIndexQueryFormat)
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = []string{"LC_ALL=C"}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf(app.TL_(ctx, "Error executing the rpm -qa command: %w"), err)
+	}
+
+	index, err := parseRpmIndexOutput(string(output))
+	if err != nil {
+		return nil, err
+	}
+
+	if cacheable {
+		index.stamp = stamp
+		rpmIndexCache = index
+	}
+	return index, nil
+}
+
+// rpmDBStamp возвращает наибольшее время изменения файлов rpmdb, нулевое время если каталог недоступен
+func rpmDBStamp() time.Time {
+	var stamp time.Time
+	entries, err := os.ReadDir(rpmDBDir)
+	if err != nil {
+		return stamp
+	}
+
+	if info, statErr := os.Stat(rpmDBDir); statErr == nil {
+		stamp = info.ModTime()
+	}
+	for _, entry := range entries {
+		info, infoErr := os.Stat(filepath.Join(rpmDBDir, entry.Name()))
+		if infoErr != nil {
+			continue
+		}
+		if info.ModTime().After(stamp) {
+			stamp = info.ModTime()
+		}
+	}
+
+	return stamp
+}
+
+// isInstalled проверяет наличие пакета так же, как rpm -q: label может быть именем пакета
+// или именем с версией в виде имя-версия[-релиз[.архитектура]]
+func (idx *rpmIndex) isInstalled(label string) bool {
+	if len(idx.byName[label]) > 0 {
+		return true
+	}
+
+	for i := strings.IndexByte(label, '-'); i > 0; i = nextDash(label, i) {
+		for _, header := range idx.byName[label[:i]] {
+			rest := label[i+1:]
+			if rest == header.Version ||
+				rest == header.Version+"-"+header.Release ||
+				rest == header.Version+"-"+header.Release+"."+header.Arch {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nextDash возвращает позицию следующего дефиса после from или -1
+func nextDash(s string, from int) int {
+	if i := strings.IndexByte(s[from+1:], '-'); i >= 0 {
+		return from + 1 + i
+	}
+	return -1
+}
+
+// withPrefix возвращает записи пакетов, имя которых начинается с prefix
+func (idx *rpmIndex) withPrefix(prefix string) []rpmHeader {
+	var result []rpmHeader
+	for name, headers := range idx.byName {
+		if strings.HasPrefix(name, prefix) {
+			result = append(result, headers...)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].Version+"-"+result[i].Release < result[j].Version+"-"+result[j].Release
+	})
+	return result
+}
+
+// evr возвращает полную версию пакета в виде [эпоха:]версия-релиз
+func (h rpmHeader) evr() string {
+	version := h.Version + "-" + h.Release
+	if h.Epoch != "" {
+		version = h.Epoch + ":" + version
+	}
+	return version
+}
+
+// parseRpmIndexOutput парсит вывод rpm -qa с форматом rpmIndexQueryFormat
+func parseRpmIndexOutput(output string) (*rpmIndex, error) {
+	index := &rpmIndex{byName: make(map[string][]rpmHeader)}
+	scanner := bufio.NewScanner(strings.NewReader(output))
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		parts := strings.Split(line, "\t")
+		if len(parts) != 6 {
+			continue
+		}
+
+		header := rpmHeader{
+			Name:      strings.TrimSpace(parts[0]),
+			Epoch:     strings.TrimSpace(parts[1]),
+			Version:   strings.TrimSpace(parts[2]),
+			Release:   strings.TrimSpace(parts[3]),
+			Arch:      strings.TrimSpace(parts[4]),
+			BuildTime: strings.TrimSpace(parts[5]),
+		}
+		if header.Epoch == "(none)" {
+			header.Epoch = ""
+		}
+		index.byName[header.Name] = append(index.byName[header.Name], header)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf(app.T_("error scanning RPM output: %w"), err)
+	}
+
+	return index, nil
+}
@@ -0,0 +1,119 @@
+package apt
+
+import (
+	"testing"
+)
+
+const testRpmIndexOutput = "bash\t(none)\t5.2.15\talt1\tx86_64\t1700000000\n" +
+	"\n" +
+	"broken line\n" +
+	"kernel-image-6.12\t(none)\t6.12.10\talt1\tx86_64\t1700000001\n" +
+	"kernel-image-6.12\t(none)\t6.12.9\talt1\tx86_64\t1700000002\n" +
+	"kernel-image-6.12\t(none)\t6.12.11\talt1\tx86_64\t1700000003\n" +
+	"vim-console\t4\t9.1.0\talt1\tx86_64\t1700000004\n" +
+	"i586-glibc-core\t6\t2.38\talt0\ti586\t1700000005\n" +
+	"glibc-core\t6\t2.38\talt1\tx86_64\t1700000006\n" +
+	"i586-wine\t(none)\t9.0\talt1\ti586\t1700000007\n" +
+	"i586-tool\t(none)\t1.0\talt1\tx86_64\t1700000008\n"
+
+func TestParseRpmIndexOutput(t *testing.T) {
+	index, err := parseRpmIndexOutput(testRpmIndexOutput)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		count int
+		want  rpmHeader
+	}{
+		{"bash", 1, rpmHeader{Name: "bash", Version: "5.2.15", Release: "alt1", Arch: "x86_64", BuildTime: "1700000000"}},
+		{"kernel-image-6.12", 3, rpmHeader{Name: "kernel-image-6.12", Version: "6.12.10", Release: "alt1", Arch: "x86_64", BuildTime: "1700000001"}},
+		{"vim-console", 1, rpmHeader{Name: "vim-console", Epoch: "4", Version: "9.1.0", Release: "alt1", Arch: "x86_64", BuildTime: "1700000004"}},
+		{"broken line", 0, rpmHeader{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := index.byName[tt.name]
+			if len(headers) != tt.count {
+				t.Fatalf("expected %d headers, got %+v", tt.count, headers)
+			}
+			if tt.count > 0 && headers[0] != tt.want {
+				t.Errorf("got %+v, want %+v", headers[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestNewestInstalled(t *testing.T) {
+	index, err := parseRpmIndexOutput(testRpmIndexOutput)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	newest := newestInstalled(index)
+
+	tests := []struct {
+		name string
+		evr  string
+		arch string
+	}{
+		{"bash", "5.2.15-alt1", "x86_64"},
+		{"kernel-image-6.12", "6.12.11-alt1", "x86_64"},
+		{"vim-console", "4:9.1.0-alt1", "x86_64"},
+		// i586-пакет попадает под общее имя, выбирается более новая версия
+		{"glibc-core", "6:2.38-alt1", "x86_64"},
+		{"wine", "9.0-alt1", "i586"},
+		// префикс отбрасывается только у 32-битных пакетов
+		{"i586-tool", "1.0-alt1", "x86_64"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, ok := newest[tt.name]
+			if !ok {
+				t.Fatalf("package %s not found in %v", tt.name, newest)
+			}
+			if header.evr() != tt.evr || header.Arch != tt.arch {
+				t.Errorf("got %s.%s, want %s.%s", header.evr(), header.Arch, tt.evr, tt.arch)
+			}
+		})
+	}
+
+	for _, name := range []string{"i586-glibc-core", "i586-wine", "tool"} {
+		if _, ok := newest[name]; ok {
+			t.Errorf("unexpected entry %s", name)
+		}
+	}
+}
+
+func TestIsInstalled(t *testing.T) {
+	index, err := parseRpmIndexOutput(testRpmIndexOutput)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		label string
+		want  bool
+	}{
+		{"bash", true},
+		{"bash-5.2.15", true},
+		{"bash-5.2.15-alt1", true},
+		{"bash-5.2.15-alt1.x86_64", true},
+		{"bash-5.2.14", false},
+		{"bash-5.2.15-alt2", false},
+		{"kernel-image-6.12", true},
+		{"kernel-image-6.12-6.12.9-alt1", true},
+		{"kernel-image-6.12-6.12.12", false},
+		{"vim-console-9.1.0", true},
+		{"vim", false},
+		{"kernel-image", false},
+		{"wine", false},
+		{"", false},
+		{"-", false},
+	}
+	for _, tt := range tests {
+		if got := index.isInstalled(tt.label); got != tt.want {
+			t.Errorf("isInstalled(%q) = %v, want %v", tt.label, got, tt.want)
+		}
+	}
+}
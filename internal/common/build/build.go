@@ -77,7 +77,7 @@ func (cfgService *ConfigService) SetAptConfigOverrides(overrides map[string]stri
 
 func (cfgService *ConfigService) Build(ctx context.Context) error {
 	if cfgService.serviceHostConfig.GetConfig() == nil {
-		return errors.New(app.T_("Configuration not loaded. Load config first"))
+		return errors.New(app.TL_(ctx, "Configuration not loaded. Load config first"))
 	}
 
 	_, err := cfgService.executeModules(ctx, cfgService.serviceHostConfig.GetConfig().Modules)
@@ -166,7 +166,7 @@ func (cfgService *ConfigService) ExecuteModule(ctx context.Context, module core.
 		return nil, fmt.Errorf("module '%s': %w", module.GetLabel(), err)
 	} else {
 		if out == nil && len(module.Output) > 0 {
-			app.Log.Warn(fmt.Sprintf(app.T_("'%s' type doesn't support output"), module.Type))
+			app.Log.Warn(fmt.Sprintf(app.TL_(ctx, "'%s' type doesn't support output"), module.Type))
 		} else if out != nil {
 			output, err = core.ResolveExprMap(module.Output, out)
 			if err != nil {
@@ -231,7 +231,7 @@ func (cfgService *ConfigService) GetPackageByName(ctx context.Context, packageNa
 		}
 
 		if len(alternativePackages) == 0 {
-			errorFindPackage := fmt.Sprintf(app.T_("Failed to retrieve information about the package %s"), packageName)
+			errorFindPackage := fmt.Sprintf(app.TL_(ctx, "Failed to retrieve information about the package %s"), packageName)
 			return nil, errors.New(errorFindPackage)
 		} else if len(alternativePackages) == 1 {
 			return &alternativePackages[0], nil
@@ -242,7 +242,7 @@ func (cfgService *ConfigService) GetPackageByName(ctx context.Context, packageNa
 			altNames = append(altNames, altPkg.Name)
 		}
 
-		message := err.Error() + app.T_(". Maybe you were looking for: ")
+		message := err.Error() + app.TL_(ctx, ". Maybe you were looking for: ")
 
 		errPackageNotFound := fmt.Errorf(message+"%s", strings.Join(altNames, " "))
 
@@ -433,7 +433,7 @@ func (cfgService *ConfigService) executeModules(ctx context.Context, modules []c
 						newLabelText = fmt.Sprintf(" with %s", newLabel)
 					}
 
-					app.Log.Warn(fmt.Sprintf(app.T_("module with id='%s'%s will be overriding%s"), module.Id, oldLabelText, newLabelText))
+					app.Log.Warn(fmt.Sprintf(app.TL_(ctx, "module with id='%s'%s will be overriding%s"), module.Id, oldLabelText, newLabelText))
 				}
 
 				modulesMap[module.Id] = output
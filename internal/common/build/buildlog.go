@@ -0,0 +1,160 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package build
+
+import (
+	"apm/internal/common/app"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// buildLogPrefix и buildLogSuffix задают имя файла журнала сборки: build-<время>.log.gz
+	buildLogPrefix = "build-"
+	buildLogSuffix = ".log.gz"
+	// buildLogTimeLayout формат времени в имени файла, сортируется лексикографически
+	buildLogTimeLayout = "20060102T150405"
+	// maxBuildLogs количество хранимых журналов, более старые удаляются
+	maxBuildLogs = 20
+)
+
+// BuildLog журнал одной сборки образа (поколения)
+type BuildLog struct {
+	Generation int    `json:"generation"`
+	Path       string `json:"path"`
+	Date       string `json:"date"`
+	Size       int64  `json:"size"`
+}
+
+// BuildLogStore хранит сжатые журналы сборок образа
+type BuildLogStore struct {
+	dir string
+}
+
+// NewBuildLogStore создаёт хранилище журналов сборки в каталоге dir.
+func NewBuildLogStore(dir string) *BuildLogStore {
+	return &BuildLogStore{dir: dir}
+}
+
+// Save сжимает и сохраняет вывод сборки, после чего удаляет журналы сверх лимита.
+// Возвращает путь к сохранённому файлу.
+func (s *BuildLogStore) Save(output string, date time.Time) (string, error) {
+	if err := os.MkdirAll(s.dir, 0750); err != nil {
+		return "", fmt.Errorf(app.T_("Failed to create directory %s: %v"), s.dir, err)
+	}
+
+	path := filepath.Join(s.dir, buildLogPrefix+date.Format(buildLogTimeLayout)+buildLogSuffix)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0640)
+	if err != nil {
+		return "", fmt.Errorf(app.T_("Failed to write build log %s: %v"), path, err)
+	}
+
+	zw := gzip.NewWriter(file)
+	zw.ModTime = date
+	_, err = io.WriteString(zw, removeANSI(output))
+	if errClose := zw.Close(); err == nil {
+		err = errClose
+	}
+	if errClose := file.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return "", fmt.Errorf(app.T_("Failed to write build log %s: %v"), path, err)
+	}
+
+	s.prune()
+	return path, nil
+}
+
+// List возвращает журналы сборок от старых к новым. Поколения нумеруются с единицы.
+func (s *BuildLogStore) List() ([]BuildLog, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []BuildLog{}, nil
+		}
+		return nil, fmt.Errorf(app.T_("Failed to read %s: %v"), s.dir, err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasPrefix(name, buildLogPrefix) && strings.HasSuffix(name, buildLogSuffix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	logs := make([]BuildLog, 0, len(names))
+	for i, name := range names {
+		log := BuildLog{Generation: i + 1, Path: filepath.Join(s.dir, name)}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, buildLogPrefix), buildLogSuffix)
+		if date, errParse := time.ParseInLocation(buildLogTimeLayout, stamp, time.Local); errParse == nil {
+			log.Date = date.Format(time.RFC3339)
+		}
+		if info, errInfo := os.Stat(log.Path); errInfo == nil {
+			log.Size = info.Size()
+		}
+		logs = append(logs, log)
+	}
+
+	return logs, nil
+}
+
+// ReadBuildLog распаковывает журнал сборки.
+func ReadBuildLog(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf(app.T_("Failed to read build log %s: %v"), path, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		return "", fmt.Errorf(app.T_("Failed to read build log %s: %v"), path, err)
+	}
+	defer func() { _ = zr.Close() }()
+
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return "", fmt.Errorf(app.T_("Failed to read build log %s: %v"), path, err)
+	}
+
+	return string(data), nil
+}
+
+// prune удаляет самые старые журналы сверх maxBuildLogs
+func (s *BuildLogStore) prune() {
+	logs, err := s.List()
+	if err != nil || len(logs) <= maxBuildLogs {
+		return
+	}
+
+	for _, log := range logs[:len(logs)-maxBuildLogs] {
+		if errRemove := os.Remove(log.Path); errRemove != nil {
+			app.Log.Debugf("failed to remove old build log %s: %v", log.Path, errRemove)
+		}
+	}
+}
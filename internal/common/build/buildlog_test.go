@@ -0,0 +1,78 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBuildLogStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	store := NewBuildLogStore(dir)
+
+	logs, err := store.List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(logs) != 0 {
+		t.Fatalf("expected no logs in missing directory, got %d", len(logs))
+	}
+
+	first := time.Date(2025, 1, 1, 10, 0, 0, 0, time.Local)
+	firstPath, err := store.Save("\x1b[32mSTEP 1/2\x1b[0m: FROM alt:p11\n", first)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	secondPath, err := store.Save("STEP 2/2: RUN apm system image build\n", first.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	logs, err = store.List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(logs) != 2 {
+		t.Fatalf("expected 2 logs, got %d", len(logs))
+	}
+	if logs[0].Generation != 1 || logs[0].Path != firstPath || logs[1].Path != secondPath {
+		t.Errorf("unexpected order: %+v", logs)
+	}
+	if logs[0].Date != first.Format(time.RFC3339) {
+		t.Errorf("expected date %s, got %s", first.Format(time.RFC3339), logs[0].Date)
+	}
+
+	content, err := ReadBuildLog(firstPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content != "STEP 1/2: FROM alt:p11\n" {
+		t.Errorf("expected ANSI sequences stripped, got %q", content)
+	}
+}
+
+func TestBuildLogStorePrune(t *testing.T) {
+	dir := t.TempDir()
+	store := NewBuildLogStore(dir)
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local)
+	for i := 0; i < maxBuildLogs+3; i++ {
+		if _, err := store.Save("build", start.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	logs, err := store.List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(logs) != maxBuildLogs {
+		t.Fatalf("expected %d logs, got %d", maxBuildLogs, len(logs))
+	}
+
+	oldest := filepath.Join(dir, buildLogPrefix+start.Format(buildLogTimeLayout)+buildLogSuffix)
+	if _, err = os.Stat(oldest); !os.IsNotExist(err) {
+		t.Errorf("expected oldest log to be removed")
+	}
+}
@@ -19,6 +19,7 @@ package build
 import (
 	"apm/internal/common/app"
 	"apm/internal/common/build/core"
+	"apm/internal/common/build/models"
 	"context"
 	"errors"
 	"os"
@@ -153,6 +154,8 @@ func (s *HostConfigService) SaveConfigToDB(ctx context.Context) error {
 		ImageName: s.config.Image,
 		Config:    s.config,
 		ImageDate: time.Now().Format(time.RFC3339),
+		LogPath:   s.hostImageService.LastBuildLog(),
+		Packages:  s.hostImageService.LastBuildPackages(),
 	}
 	return s.serviceHostDatabase.SaveImageToDB(ctx, history)
 }
@@ -179,6 +182,12 @@ func (s *HostConfigService) AddRemovePackage(pkg string) error {
 	return s.SaveConfig()
 }
 
+// SetKernel задаёт параметры ядра образа и сохраняет изменения в файл.
+func (s *HostConfigService) SetKernel(info models.KernelInfo) error {
+	s.config.SetKernel(info)
+	return s.SaveConfig()
+}
+
 // GetConfig возвращает текущую конфигурацию.
 func (s *HostConfigService) GetConfig() *Config {
 	return s.config
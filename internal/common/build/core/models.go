@@ -60,7 +60,8 @@ var modelMap = map[string]func() models.Body{
 }
 
 var (
-	imageApplyModuleName = "image-apply-results"
+	imageApplyModuleName  = "image-apply-results"
+	imageKernelModuleName = "image-apply-kernel"
 )
 
 type Envs struct {
@@ -167,6 +168,53 @@ func (cfg *Config) AddRemovePackage(pkg string) {
 	}
 }
 
+// findKernelBody возвращает тело последнего безусловного модуля ядра конфигурации.
+func (cfg *Config) findKernelBody() *models.KernelBody {
+	for i := len(cfg.Modules) - 1; i >= 0; i-- {
+		module := cfg.Modules[i]
+		if module.Type != TypeKernel || module.If != "" {
+			continue
+		}
+		if body, ok := module.Body.(*models.KernelBody); ok {
+			return body
+		}
+	}
+
+	return nil
+}
+
+// GetKernel возвращает параметры ядра образа или nil, если модуль ядра не задан.
+func (cfg *Config) GetKernel() *models.KernelInfo {
+	body := cfg.findKernelBody()
+	if body == nil {
+		return nil
+	}
+
+	info := body.KernelInfo
+	info.Modules = slices.Clone(body.KernelInfo.Modules)
+	return &info
+}
+
+// SetKernel задаёт параметры ядра образа. Если модуля ядра нет, он добавляется
+// перед модулем пакетов image-apply, чтобы пакеты ставились уже поверх нового ядра.
+func (cfg *Config) SetKernel(info models.KernelInfo) {
+	if body := cfg.findKernelBody(); body != nil {
+		body.KernelInfo = info
+		return
+	}
+
+	idx := len(cfg.Modules)
+	if idx > 0 && cfg.Modules[idx-1].Name == imageApplyModuleName {
+		idx--
+	}
+
+	cfg.Modules = slices.Insert(cfg.Modules, idx, Module{
+		Name: imageKernelModuleName,
+		Type: TypeKernel,
+		Body: &models.KernelBody{KernelInfo: info},
+	})
+}
+
 func (cfg *Config) IsInstalled(pkg string) bool {
 	return slices.Contains(cfg.getTotalInstall(), pkg)
 }
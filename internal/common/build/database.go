@@ -37,17 +37,27 @@ import (
 )
 
 type ImageHistory struct {
+	ID        uint    `json:"id"`
 	ImageName string  `json:"image"`
 	Config    *Config `json:"config"`
 	ImageDate string  `json:"date"`
+	LogPath   string  `json:"logPath,omitempty"`
+	// Packages снимок пакетов собранного образа: имя -> версия
+	Packages map[string]string `json:"-"`
 }
 
 type DBHistory struct {
-	ImageName  string    `gorm:"column:imagename;primaryKey"`
-	ImageDate  time.Time `gorm:"column:imagedate;primaryKey"`
-	ConfigJSON string    `gorm:"column:config"`
+	ID           uint      `gorm:"column:id;->;-:migration"`
+	ImageName    string    `gorm:"column:imagename;primaryKey"`
+	ImageDate    time.Time `gorm:"column:imagedate;primaryKey"`
+	ConfigJSON   string    `gorm:"column:config"`
+	LogPath      string    `gorm:"column:logpath"`
+	PackagesJSON string    `gorm:"column:packages"`
 }
 
+// historyColumns выбирает rowid таблицы в качестве идентификатора записи
+const historyColumns = "rowid AS id, *"
+
 type HostDBService struct {
 	dbManager app.DatabaseManager
 	reporter  *reply.Reporter
@@ -83,9 +93,11 @@ func (h *HostDBService) db() (*gorm.DB, error) {
 			return nil, fmt.Errorf(app.T_("SQLite connection error via GORM: %w"), err)
 		}
 
-		// Автоматическая миграция
-		if err = h.realDb.AutoMigrate(&DBHistory{}); err != nil {
-			return nil, fmt.Errorf(app.T_("Table structure migration error: %w"), err)
+		// Автоматическая миграция, подключение только для чтения использует схему из миграций root
+		if !app.IsReadOnly(conn) {
+			if err = h.realDb.AutoMigrate(&DBHistory{}); err != nil {
+				return nil, fmt.Errorf(app.T_("Table structure migration error: %w"), err)
+			}
 		}
 	}
 
@@ -113,10 +125,20 @@ func (dbh DBHistory) fromDBModel() (ImageHistory, error) {
 		return ImageHistory{}, fmt.Errorf(app.T_("Config conversion error: %v"), err)
 	}
 
+	var packages map[string]string
+	if dbh.PackagesJSON != "" {
+		if err = json.Unmarshal([]byte(dbh.PackagesJSON), &packages); err != nil {
+			return ImageHistory{}, fmt.Errorf(app.T_("Package snapshot conversion error: %v"), err)
+		}
+	}
+
 	return ImageHistory{
+		ID:        dbh.ID,
 		ImageName: dbh.ImageName,
 		Config:    &cfg,
 		ImageDate: dbh.ImageDate.Format(time.RFC3339),
+		LogPath:   dbh.LogPath,
+		Packages:  packages,
 	}, nil
 }
 
@@ -132,10 +154,21 @@ func (ih ImageHistory) toDBModel() (DBHistory, error) {
 		return DBHistory{}, fmt.Errorf(app.T_("Error serializing config: %v"), err)
 	}
 
+	var packagesJSON string
+	if ih.Packages != nil {
+		packagesBytes, errPackages := json.Marshal(ih.Packages)
+		if errPackages != nil {
+			return DBHistory{}, fmt.Errorf(app.T_("Error serializing package snapshot: %v"), errPackages)
+		}
+		packagesJSON = string(packagesBytes)
+	}
+
 	return DBHistory{
-		ImageName:  ih.ImageName,
-		ConfigJSON: string(cfgBytes),
-		ImageDate:  parsedDate,
+		ImageName:    ih.ImageName,
+		ConfigJSON:   string(cfgBytes),
+		ImageDate:    parsedDate,
+		LogPath:      ih.LogPath,
+		PackagesJSON: packagesJSON,
 	}, nil
 }
 
@@ -157,7 +190,7 @@ func (h *HostDBService) SaveImageToDB(ctx context.Context, imageHistory ImageHis
 
 	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		if errCreate := tx.Create(&dbHist).Error; errCreate != nil {
-			return fmt.Errorf(app.T_("Error inserting data: %v"), errCreate)
+			return fmt.Errorf(app.TL_(ctx, "Error inserting data: %v"), errCreate)
 		}
 		return nil
 	})
@@ -172,7 +205,7 @@ func (h *HostDBService) GetImageHistoriesFiltered(ctx context.Context, imageName
 		return nil, err
 	}
 
-	query := db.WithContext(ctx).Model(&DBHistory{})
+	query := db.WithContext(ctx).Model(&DBHistory{}).Select(historyColumns)
 
 	if imageNameFilter != "" {
 		query = query.Where("imagename LIKE ?", "%"+imageNameFilter+"%")
@@ -185,9 +218,9 @@ func (h *HostDBService) GetImageHistoriesFiltered(ctx context.Context, imageName
 	var dbHistories []DBHistory
 	if err = query.Find(&dbHistories).Error; err != nil {
 		if strings.Contains(err.Error(), "no such table") {
-			return nil, errors.New(app.T_("History not found"))
+			return nil, errors.New(app.TL_(ctx, "History not found"))
 		}
-		return nil, fmt.Errorf(app.T_("Query execution error: %v"), err)
+		return nil, fmt.Errorf(app.TL_(ctx, "Query execution error: %v"), err)
 	}
 
 	var histories []ImageHistory
@@ -202,6 +235,28 @@ func (h *HostDBService) GetImageHistoriesFiltered(ctx context.Context, imageName
 	return histories, nil
 }
 
+// GetImageHistoryByID возвращает запись истории по идентификатору
+func (h *HostDBService) GetImageHistoryByID(ctx context.Context, id uint) (ImageHistory, error) {
+	db, err := h.db()
+	if err != nil {
+		return ImageHistory{}, err
+	}
+
+	var dbHist DBHistory
+	err = db.WithContext(ctx).Model(&DBHistory{}).
+		Select(historyColumns).
+		Where("rowid = ?", id).
+		Take(&dbHist).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ImageHistory{}, fmt.Errorf(app.TL_(ctx, "Image history record %d not found"), id)
+		}
+		return ImageHistory{}, fmt.Errorf(app.TL_(ctx, "Query execution error: %v"), err)
+	}
+
+	return dbHist.fromDBModel()
+}
+
 // CountImageHistoriesFiltered возвращает количество записей с учётом фильтров.
 func (h *HostDBService) CountImageHistoriesFiltered(ctx context.Context, imageNameFilter string) (int, error) {
 	db, err := h.db()
@@ -218,9 +273,9 @@ func (h *HostDBService) CountImageHistoriesFiltered(ctx context.Context, imageNa
 	var count int64
 	if err = query.Count(&count).Error; err != nil {
 		if strings.Contains(err.Error(), "no such table") {
-			return 0, errors.New(app.T_("History not found"))
+			return 0, errors.New(app.TL_(ctx, "History not found"))
 		}
-		return 0, fmt.Errorf(app.T_("Query execution error: %v"), err)
+		return 0, fmt.Errorf(app.TL_(ctx, "Query execution error: %v"), err)
 	}
 
 	return int(count), nil
@@ -245,12 +300,12 @@ func (h *HostDBService) IsLatestConfigSame(ctx context.Context, newConfig Config
 		if strings.Contains(err.Error(), "record not found") {
 			return false, nil
 		}
-		return false, fmt.Errorf(app.T_("Query execution error: %v"), err)
+		return false, fmt.Errorf(app.TL_(ctx, "Query execution error: %v"), err)
 	}
 
 	var latestConfig Config
 	if latestConfig, err = ParseJsonConfigData([]byte(dbHist.ConfigJSON)); err != nil {
-		return false, fmt.Errorf(app.T_("History config conversion error: %v"), err)
+		return false, fmt.Errorf(app.TL_(ctx, "History config conversion error: %v"), err)
 	}
 
 	return reflect.DeepEqual(newConfig, latestConfig), nil
@@ -27,7 +27,11 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
 )
 
 type HostImage struct {
@@ -35,8 +39,10 @@ type HostImage struct {
 		Image ImageInfo `json:"image"`
 	} `json:"spec"`
 	Status struct {
-		Staged *ImageStatus `json:"staged"`
-		Booted ImageStatus  `json:"booted"`
+		Staged         *ImageStatus `json:"staged"`
+		Booted         ImageStatus  `json:"booted"`
+		Rollback       *ImageStatus `json:"rollback"`
+		RollbackQueued bool         `json:"rollbackQueued"`
 	} `json:"status"`
 }
 
@@ -65,6 +71,9 @@ type HostImageService struct {
 	containerPath string
 	runner        command.Runner
 	podman        *PodmanService
+	buildLogs     *BuildLogStore
+	lastBuildLog  string
+	lastPackages  map[string]string
 }
 
 // NewHostImageService создаёт новый сервис для работы с образами хоста.
@@ -75,9 +84,70 @@ func NewHostImageService(appConfig *app.Configuration, containerPath string, run
 		containerPath: containerPath,
 		runner:        runner,
 		podman:        NewPodmanService(runner, reporter),
+		buildLogs:     NewBuildLogStore(appConfig.PathBuildLogs),
 	}
 }
 
+// BuildLogs возвращает сохранённые журналы сборок образа.
+func (h *HostImageService) BuildLogs() ([]BuildLog, error) {
+	return h.buildLogs.List()
+}
+
+// ReadBuildLog возвращает распакованное содержимое журнала сборки.
+func (h *HostImageService) ReadBuildLog(path string) (string, error) {
+	return ReadBuildLog(path)
+}
+
+// LastBuildLog возвращает путь к журналу последней сборки в текущем процессе.
+func (h *HostImageService) LastBuildLog() string {
+	return h.lastBuildLog
+}
+
+// LastBuildPackages возвращает снимок пакетов последнего собранного в текущем процессе образа.
+func (h *HostImageService) LastBuildPackages() map[string]string {
+	return h.lastPackages
+}
+
+// imagePackages возвращает пакеты, установленные в образе podmanImageID: имя -> версия.
+// Несколько установленных версий одного пакета (например, ядра) перечисляются через запятую.
+func (h *HostImageService) imagePackages(ctx context.Context, podmanImageID string) (map[string]string, error) {
+	stdout, stderr, err := h.runner.Run(ctx, []string{"podman", "run", "--rm", "--entrypoint", "rpm", podmanImageID,
+		"-qa", "--qf", `%{NAME}\t%|EPOCH?{%{EPOCH}:}:{}|%{VERSION}-%{RELEASE}\n`}, command.WithQuiet())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", strings.TrimSpace(stderr), err)
+	}
+
+	return parseRpmPackages(stdout), nil
+}
+
+// parseRpmPackages разбирает вывод rpm -qa в формате "имя\tверсия"
+func parseRpmPackages(output string) map[string]string {
+	packages := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		name, version, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if !ok || name == "" {
+			continue
+		}
+		if previous, exists := packages[name]; exists {
+			versions := append(strings.Split(previous, ", "), version)
+			slices.Sort(versions)
+			version = strings.Join(versions, ", ")
+		}
+		packages[name] = version
+	}
+	return packages
+}
+
+// saveBuildLog сохраняет вывод сборки. Ошибка записи журнала не прерывает сборку.
+func (h *HostImageService) saveBuildLog(output string) {
+	path, err := h.buildLogs.Save(output, time.Now())
+	if err != nil {
+		app.Log.Debugf("failed to save build log: %v", err)
+		return
+	}
+	h.lastBuildLog = path
+}
+
 func (h *HostImageService) GetHostImage() (HostImage, error) {
 	var host HostImage
 
@@ -136,8 +206,77 @@ func (h *HostImageService) EnableOverlay() error {
 	return nil
 }
 
+// hostImageName имя локального образа хоста в podman
+const hostImageName = "os"
+
+// tagPattern допустимые символы тега варианта образа
+var tagPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]{0,127}$`)
+
+// TaggedImageName возвращает имя локального образа для варианта с тегом.
+func TaggedImageName(tag string) string {
+	return hostImageName + ":" + tag
+}
+
+// ValidateImageTag проверяет тег варианта образа.
+func ValidateImageTag(tag string) error {
+	if !tagPattern.MatchString(tag) || tag == "latest" {
+		return fmt.Errorf(app.T_("Invalid image tag %q: use letters, digits, '.', '_' or '-'"), tag)
+	}
+	return nil
+}
+
 // BuildImage сборка образа
 func (h *HostImageService) BuildImage(ctx context.Context, pullImage bool) (string, error) {
+	return h.buildImage(ctx, pullImage, hostImageName, h.containerPath, "/etc/apm", nil)
+}
+
+// BuildTaggedImage собирает вариант образа из произвольного файла конфигурации
+// и сохраняет его под отдельным тегом, не переключая систему.
+func (h *HostImageService) BuildTaggedImage(ctx context.Context, config Config, tag string, pullImage bool, hostCache bool) (string, error) {
+	if err := ValidateImageTag(tag); err != nil {
+		return "", err
+	}
+
+	containerFile, err := os.CreateTemp("/var/tmp", "apm-containerfile-*")
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = os.Remove(containerFile.Name()) }()
+
+	content := containerfileContent(config.Image, filepath.Base(h.appConfig.PathImageFile), "from=resources", hostCache)
+	if _, err = containerFile.WriteString(content); err != nil {
+		_ = containerFile.Close()
+		return "", err
+	}
+	if err = containerFile.Close(); err != nil {
+		return "", err
+	}
+
+	extraArgs := []string{"--build-context", "resources=" + h.appConfig.PathResourcesDir}
+	return h.buildImage(ctx, pullImage, TaggedImageName(tag), containerFile.Name(), filepath.Dir(h.appConfig.PathImageFile), extraArgs)
+}
+
+// TaggedImageID возвращает идентификатор собранного варианта образа по тегу.
+func (h *HostImageService) TaggedImageID(ctx context.Context, tag string) (string, error) {
+	if err := ValidateImageTag(tag); err != nil {
+		return "", err
+	}
+
+	stdout, _, err := h.runner.Run(ctx, []string{"podman", "images", "-q", TaggedImageName(tag)}, command.WithQuiet())
+	if err != nil {
+		return "", fmt.Errorf(app.TL_(ctx, "Error podman image: %v"), err)
+	}
+
+	imageID := strings.TrimSpace(stdout)
+	if imageID == "" {
+		return "", fmt.Errorf(app.TL_(ctx, "No valid images with tag '%s'. Please build the image first."), TaggedImageName(tag))
+	}
+
+	return imageID, nil
+}
+
+// buildImage запускает podman build и возвращает идентификатор собранного образа imageName
+func (h *HostImageService) buildImage(ctx context.Context, pullImage bool, imageName, containerPath, contextDir string, extraArgs []string) (string, error) {
 	h.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventSystemBuildImage))
 	defer h.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventSystemBuildImage))
 
@@ -145,39 +284,57 @@ func (h *HostImageService) BuildImage(ctx context.Context, pullImage bool) (stri
 	if pullImage {
 		buildArgs = append(buildArgs, "--pull=always")
 	}
-	buildArgs = append(buildArgs, "--squash", "-t", "os", "-f", h.containerPath, "/etc/apm")
+	buildArgs = append(buildArgs, extraArgs...)
+	buildArgs = append(buildArgs, "--squash", "-t", imageName, "-f", containerPath, contextDir)
 
+	h.lastBuildLog = ""
 	if h.appConfig.Verbose {
-		_, _, err := h.runner.Run(ctx, buildArgs, command.WithEnv("TMPDIR=/var/tmp", "LC_ALL=C"))
+		stdout, stderr, err := h.runner.Run(ctx, buildArgs, command.WithEnv("TMPDIR=/var/tmp", "LC_ALL=C"))
+		h.saveBuildLog(stdout + stderr)
 		if err != nil {
-			return "", fmt.Errorf(app.T_("Failed to build image. Please fix the configuration: %s"), h.appConfig.PathImageFile)
+			return "", fmt.Errorf(app.TL_(ctx, "Failed to build image. Please fix the configuration: %s"), h.appConfig.PathImageFile)
 		}
 	} else {
 		stdout, err := h.podman.Pull(ctx, buildArgs)
+		h.saveBuildLog(stdout)
 		if err != nil {
 			if apmLogs := extractAPMLogs(stdout); apmLogs != "" {
 				return "", fmt.Errorf("%s\n%s\n%s",
-					fmt.Sprintf(app.T_("Failed to build image. Please fix the configuration: %s"), h.appConfig.PathImageFile),
-					app.T_("Build log:"),
+					fmt.Sprintf(app.TL_(ctx, "Failed to build image. Please fix the configuration: %s"), h.appConfig.PathImageFile),
+					app.TL_(ctx, "Build log:"),
 					apmLogs)
 			}
 			return "", fmt.Errorf("%s\n%v", stdout, err)
 		}
 	}
 
-	imgStdout, _, err := h.runner.Run(ctx, []string{"podman", "images", "-q", "os"}, command.WithQuiet())
+	imgStdout, _, err := h.runner.Run(ctx, []string{"podman", "images", "-q", imageName}, command.WithQuiet())
 	if err != nil {
-		return "", fmt.Errorf(app.T_("Error podman image: %v"), err)
+		return "", fmt.Errorf(app.TL_(ctx, "Error podman image: %v"), err)
 	}
 
 	podmanImageID := strings.TrimSpace(imgStdout)
 	if podmanImageID == "" {
-		return "", errors.New(app.T_("No valid images with tag 'os'. Please build the image first."))
+		return "", fmt.Errorf(app.TL_(ctx, "No valid images with tag '%s'. Please build the image first."), imageName)
 	}
 
 	return podmanImageID, nil
 }
 
+// RollbackImage ставит предыдущее развёртывание первым в порядке загрузки. Подготовленное, но не
+// загруженное развёртывание при этом отбрасывается
+func (h *HostImageService) RollbackImage(ctx context.Context) error {
+	h.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventSystemRollbackImage))
+	defer h.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventSystemRollbackImage))
+
+	stdout, stderr, err := h.runner.Run(ctx, []string{"bootc", "rollback"})
+	if err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Error rolling back to the previous image: %s"), stdout+stderr)
+	}
+
+	return nil
+}
+
 // SwitchImage переключение образа
 func (h *HostImageService) SwitchImage(ctx context.Context, podmanImageID string, isLocal bool) error {
 	h.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventSystemSwitchImage))
@@ -191,7 +348,7 @@ func (h *HostImageService) SwitchImage(ctx context.Context, podmanImageID string
 	}
 	stdout, stderr, err := h.runner.Run(ctx, args)
 	if err != nil {
-		return fmt.Errorf(app.T_("Error switching to the new image: %s"), stdout+stderr)
+		return fmt.Errorf(app.TL_(ctx, "Error switching to the new image: %s"), stdout+stderr)
 	}
 
 	return nil
@@ -203,7 +360,7 @@ func (h *HostImageService) CheckAndUpdateBaseImage(ctx context.Context, pullImag
 	defer h.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventSystemCheckUpdateBaseImage))
 	image, err := h.GetHostImage()
 	if err != nil {
-		return fmt.Errorf(app.T_("Error retrieving information: %v"), err)
+		return fmt.Errorf(app.TL_(ctx, "Error retrieving information: %v"), err)
 	}
 
 	if image.Status.Booted.Image.Image.Transport != "containers-storage" {
@@ -246,7 +403,7 @@ func (h *HostImageService) CheckAndUpdateBaseImage(ctx context.Context, pullImag
 	// Генерируем Containerfile если его нет
 	if _, statErr := os.Stat(h.containerPath); statErr != nil {
 		if err = h.GenerateDockerfile(config, hostCache); err != nil {
-			return fmt.Errorf(app.T_("Failed to generate Containerfile: %w"), err)
+			return fmt.Errorf(app.TL_(ctx, "Failed to generate Containerfile: %w"), err)
 		}
 	}
 
@@ -273,12 +430,12 @@ func (h *HostImageService) getRemoteImageInfo(ctx context.Context, imageName str
 		if errMsg == "" {
 			errMsg = fmt.Sprintf("%v", err)
 		}
-		return "", fmt.Errorf(app.T_("Skopeo inspect error: %s"), errMsg)
+		return "", fmt.Errorf(app.TL_(ctx, "Skopeo inspect error: %s"), errMsg)
 	}
 
 	var info SkopeoInspectInfo
 	if err = json.Unmarshal([]byte(stdout), &info); err != nil {
-		return "", fmt.Errorf(app.T_("Failed to parse skopeo inspect: %w"), err)
+		return "", fmt.Errorf(app.TL_(ctx, "Failed to parse skopeo inspect: %w"), err)
 	}
 
 	return strings.Join(info.Layers, ","), nil
@@ -289,7 +446,7 @@ func (h *HostImageService) bootcUpgrade(ctx context.Context) error {
 	defer h.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventSystemBootcUpgrade))
 
 	if _, err := h.podman.BootcUpgrade(ctx, []string{"bootc", "upgrade"}); err != nil {
-		return fmt.Errorf(app.T_("Bootc upgrade failed: %v"), err)
+		return fmt.Errorf(app.TL_(ctx, "Bootc upgrade failed: %v"), err)
 	}
 
 	return nil
@@ -321,11 +478,18 @@ func (h *HostImageService) GenerateDefaultConfig() (Config, error) {
 
 // GenerateDockerfile генерирует содержимое Dockerfile, формируя apm команды с модификаторами для пакетов.
 func (h *HostImageService) GenerateDockerfile(config Config, hostCache bool) error {
+	dockerStr := containerfileContent(config.Image, "image.yml", "source=resources", hostCache)
+	return os.WriteFile(h.containerPath, []byte(dockerStr), 0644)
+}
+
+// containerfileContent формирует Containerfile: configName - имя файла конфигурации в контексте сборки,
+// resourcesSource - откуда монтировать ресурсы (каталог контекста или именованный build-context)
+func containerfileContent(image, configName, resourcesSource string, hostCache bool) string {
 	var dockerfileLines []string
-	dockerfileLines = append(dockerfileLines, fmt.Sprintf("FROM \"%s\"", config.Image))
+	dockerfileLines = append(dockerfileLines, fmt.Sprintf("FROM \"%s\"", image))
 
-	runCmd := "RUN --mount=type=bind,ro,source=image.yml,target=/etc/apm/image.yml" +
-		" --mount=type=bind,ro,source=resources,target=/etc/apm/resources"
+	runCmd := "RUN --mount=type=bind,ro,source=" + configName + ",target=/etc/apm/image.yml" +
+		" --mount=type=bind,ro," + resourcesSource + ",target=/etc/apm/resources"
 	if hostCache {
 		runCmd += " --mount=type=cache,target=/var/cache/apt/archives,id=apt-cache" +
 			" mkdir -p /var/cache/apt/archives/partial &&"
@@ -333,8 +497,7 @@ func (h *HostImageService) GenerateDockerfile(config Config, hostCache bool) err
 	runCmd += " apm system image build"
 	dockerfileLines = append(dockerfileLines, runCmd)
 
-	dockerStr := strings.Join(dockerfileLines, "\n") + "\n"
-	return os.WriteFile(h.containerPath, []byte(dockerStr), 0644)
+	return strings.Join(dockerfileLines, "\n") + "\n"
 }
 
 // BuildAndSwitch перестраивает и переключает систему на новый образ. checkSame - включена ли проверка на изменение конфигурации
@@ -344,7 +507,7 @@ func (h *HostImageService) BuildAndSwitch(ctx context.Context, pullImage bool, c
 		return err
 	}
 	if !statusSame && checkSame {
-		return errors.New(app.T_("The image has not changed, build paused"))
+		return errors.New(app.TL_(ctx, "The image has not changed, build paused"))
 	}
 
 	idImage, err := h.BuildImage(ctx, pullImage)
@@ -352,6 +515,12 @@ func (h *HostImageService) BuildAndSwitch(ctx context.Context, pullImage bool, c
 		return err
 	}
 
+	// Снимок пакетов сохраняется в истории для сравнения сборок, без него история остаётся рабочей
+	h.lastPackages, err = h.imagePackages(ctx, idImage)
+	if err != nil {
+		app.Log.Warning(fmt.Sprintf(app.TL_(ctx, "Failed to read the package list of the built image: %v"), err))
+	}
+
 	err = h.SwitchImage(ctx, idImage, true)
 	if err != nil {
 		return err
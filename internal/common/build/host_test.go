@@ -144,8 +144,10 @@ func TestHostImage_Structure(t *testing.T) {
 			},
 		},
 		Status: struct {
-			Staged *ImageStatus `json:"staged"`
-			Booted ImageStatus  `json:"booted"`
+			Staged         *ImageStatus `json:"staged"`
+			Booted         ImageStatus  `json:"booted"`
+			Rollback       *ImageStatus `json:"rollback"`
+			RollbackQueued bool         `json:"rollbackQueued"`
 		}{
 			Booted: ImageStatus{
 				Image: Image{
@@ -179,3 +181,37 @@ func TestHostImage_Structure(t *testing.T) {
 func stringPtr(s string) *string {
 	return &s
 }
+
+func TestContainerfileContent(t *testing.T) {
+	content := containerfileContent("alt:sisyphus", "image.yml", "source=resources", false)
+	expected := "FROM \"alt:sisyphus\"\n" +
+		"RUN --mount=type=bind,ro,source=image.yml,target=/etc/apm/image.yml" +
+		" --mount=type=bind,ro,source=resources,target=/etc/apm/resources apm system image build\n"
+	if content != expected {
+		t.Errorf("unexpected Containerfile:\n%s", content)
+	}
+
+	tagged := containerfileContent("alt:sisyphus", "work.yml", "from=resources", true)
+	if !strings.Contains(tagged, "source=work.yml,target=/etc/apm/image.yml") {
+		t.Errorf("tagged Containerfile should mount the custom config: %s", tagged)
+	}
+	if !strings.Contains(tagged, "from=resources,target=/etc/apm/resources") {
+		t.Errorf("tagged Containerfile should mount resources from build context: %s", tagged)
+	}
+	if !strings.Contains(tagged, "id=apt-cache") {
+		t.Errorf("host cache mount missing: %s", tagged)
+	}
+}
+
+func TestValidateImageTag(t *testing.T) {
+	for _, tag := range []string{"work", "home-2", "v1.0_test"} {
+		if err := ValidateImageTag(tag); err != nil {
+			t.Errorf("tag %q should be valid: %v", tag, err)
+		}
+	}
+	for _, tag := range []string{"", "latest", "-work", "a/b", "a:b", strings.Repeat("a", 129)} {
+		if err := ValidateImageTag(tag); err == nil {
+			t.Errorf("tag %q should be invalid", tag)
+		}
+	}
+}
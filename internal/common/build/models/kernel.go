@@ -146,9 +146,15 @@ func (b *KernelBody) Execute(ctx context.Context, svc Service) (any, error) {
 		}
 
 		app.Log.Info(fmt.Sprintf("Installing kernel %s with modules: %s", toInstall.Flavour, strings.Join(modules, ", ")))
-		if err = mgr.InstallKernel(ctx, toInstall, modules, b.KernelInfo.IncludeHeaders, false); err != nil {
+		rebuilds, err := mgr.InstallKernel(ctx, toInstall, modules, b.KernelInfo.IncludeHeaders, false)
+		if err != nil {
 			return nil, err
 		}
+		for _, rebuild := range rebuilds {
+			if !rebuild.Success {
+				app.Log.Warning(fmt.Sprintf("Failed to rebuild module %s for kernel %s: %s", rebuild.Name, toInstall.Flavour, rebuild.Error))
+			}
+		}
 
 		// TODO: Заменить на более точечное обновление, как в kernel service
 		app.Log.Info("Updating packages DB for kernel")
@@ -346,7 +352,7 @@ func currentKernelInfo(ctx context.Context, svc Service) (*service.Info, error)
 
 	kernelInfo := svc.KernelManager().ParseKernelPackageFromDB(packages[0])
 	if kernelInfo == nil {
-		return nil, errors.New(app.T_("failed to parse kernel package from database"))
+		return nil, errors.New(app.TL_(ctx, "failed to parse kernel package from database"))
 	}
 
 	kernelInfo.IsRunning = true
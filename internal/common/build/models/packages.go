@@ -5,6 +5,7 @@ import (
 	"apm/internal/common/osutils"
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
 )
 
@@ -64,6 +65,10 @@ func (b *PackagesBody) Execute(ctx context.Context, svc Service) (any, error) {
 
 	var ops []string
 	for _, p := range b.Install {
+		// Локальные пакеты указываются относительно каталога ресурсов образа
+		if strings.HasSuffix(p, ".rpm") && !filepath.IsAbs(p) {
+			p = filepath.Join(svc.ResourcesDir(), p)
+		}
 		ops = append(ops, p+"+")
 	}
 	for _, p := range b.Remove {
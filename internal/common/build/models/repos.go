@@ -59,7 +59,7 @@ func (b *ReposBody) Execute(ctx context.Context, svc Service) (any, error) {
 	var repoSvc = svc.RepoService()
 
 	if b.Branch != "" && !slices.Contains(repoSvc.GetBranches(), b.Branch) {
-		return nil, fmt.Errorf(app.T_("unknown branch %s"), b.Branch)
+		return nil, fmt.Errorf(app.TL_(ctx, "unknown branch %s"), b.Branch)
 	}
 
 	if b.Clean {
@@ -112,7 +112,7 @@ func (p *PodmanService) Pull(ctx context.Context, args []string) (string, error)
 		}),
 	)
 	if err != nil {
-		return output, fmt.Errorf(app.T_("Command failed with error: %v"), err)
+		return output, fmt.Errorf(app.TL_(ctx, "Command failed with error: %v"), err)
 	}
 
 	p.reporter.CreateEventNotification(ctx, reply.StateAfter,
@@ -197,12 +197,12 @@ func (p *PodmanService) PruneOldImages(ctx context.Context) error {
 	defer p.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventSystemPruneOldImages))
 
 	if stdout, stderr, err := p.runner.Run(ctx, []string{"podman", "image", "prune", "-f"}, command.WithQuiet()); err != nil {
-		return fmt.Errorf(app.T_("Error deleting old images: %v, output: %s"), err, stdout+stderr)
+		return fmt.Errorf(app.TL_(ctx, "Error deleting old images: %v, output: %s"), err, stdout+stderr)
 	}
 
 	stdout, _, err := p.runner.Run(ctx, []string{"podman", "images", "--noheading"}, command.WithQuiet())
 	if err != nil {
-		return fmt.Errorf(app.T_("Error retrieving podman image: %v"), err)
+		return fmt.Errorf(app.TL_(ctx, "Error retrieving podman image: %v"), err)
 	}
 
 	scanner := bufio.NewScanner(strings.NewReader(stdout))
@@ -216,7 +216,7 @@ func (p *PodmanService) PruneOldImages(ctx context.Context) error {
 		}
 		imageID := fields[2]
 		if rmStdout, rmStderr, rmErr := p.runner.Run(ctx, []string{"podman", "rmi", "-f", imageID}, command.WithQuiet()); rmErr != nil {
-			return fmt.Errorf(app.T_("Error deleting image %s: %v, output: %s\n"), imageID, rmErr, rmStdout+rmStderr)
+			return fmt.Errorf(app.TL_(ctx, "Error deleting image %s: %v, output: %s\n"), imageID, rmErr, rmStdout+rmStderr)
 		}
 	}
 
@@ -257,7 +257,7 @@ func (p *PodmanService) BootcUpgrade(ctx context.Context, args []string) (string
 		}),
 	)
 	if err != nil {
-		return output, fmt.Errorf(app.T_("Command failed with error: %v"), err)
+		return output, fmt.Errorf(app.TL_(ctx, "Command failed with error: %v"), err)
 	}
 
 	p.reporter.CreateEventNotification(ctx, reply.StateAfter,
@@ -286,7 +286,7 @@ func (p *PodmanService) parseBootcProgressLine(ctx context.Context, rawLine stri
 			total, err2 := strconv.ParseFloat(matches[2], 64)
 			if err1 == nil && err2 == nil && total > 0 {
 				percent := (current / total) * 100
-				viewText := fmt.Sprintf(app.T_("Fetching layers %d/%d"), int(current), int(total))
+				viewText := fmt.Sprintf(app.TL_(ctx, "Fetching layers %d/%d"), int(current), int(total))
 				p.reporter.CreateEventNotification(ctx, reply.StateBefore,
 					reply.WithEventName(reply.EventBootcLayers),
 					reply.WithEventView(viewText),
@@ -0,0 +1,84 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+)
+
+// Capability возможность окружения, без которой команда не может работать
+type Capability string
+
+const (
+	// CapDBus шина DBus и запущенные на ней сервисы
+	CapDBus Capability = "dbus"
+	// CapSystemd запущенный systemd и управляемые им службы
+	CapSystemd Capability = "systemd"
+	// CapHost загруженная хост-система: bootc, история образа, контейнеры distrobox
+	CapHost Capability = "host"
+)
+
+// capabilitiesKey ключ Metadata команды со списком требуемых возможностей
+const capabilitiesKey = "capabilities"
+
+// Requires возвращает Metadata команды с требуемыми возможностями окружения.
+// Команды без требований считаются допустимыми внутри сборки образа.
+func Requires(caps ...Capability) map[string]any {
+	return map[string]any{capabilitiesKey: caps}
+}
+
+// RequiredCapabilities возвращает возможности, требуемые командой и её родителями.
+func RequiredCapabilities(cmd *cli.Command) []Capability {
+	var result []Capability
+	seen := make(map[Capability]bool)
+	for _, c := range cmd.Lineage() {
+		caps, _ := c.Metadata[capabilitiesKey].([]Capability)
+		for _, capability := range caps {
+			if !seen[capability] {
+				seen[capability] = true
+				result = append(result, capability)
+			}
+		}
+	}
+	return result
+}
+
+// checkCapabilities запрещает команды, которым нужны DBus, systemd или хост-система,
+// когда apm запущен внутри контейнера сборки образа.
+func checkCapabilities(cmd *cli.Command, buildEnv bool) error {
+	if !buildEnv {
+		return nil
+	}
+
+	required := RequiredCapabilities(cmd)
+	if len(required) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(required))
+	for _, capability := range required {
+		names = append(names, string(capability))
+	}
+	return apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(
+		app.T_("Command '%s' is not available inside an image build, it requires: %s"),
+		cmd.FullName(), strings.Join(names, ", ")))
+}
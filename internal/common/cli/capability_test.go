@@ -0,0 +1,67 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+)
+
+func TestCheckCapabilities(t *testing.T) {
+	var invoked *cli.Command
+	capture := func(_ context.Context, cmd *cli.Command) error {
+		invoked = cmd
+		return nil
+	}
+
+	root := &cli.Command{
+		Name: "apm",
+		Commands: []*cli.Command{
+			{
+				Name:     "distrobox",
+				Metadata: Requires(CapHost),
+				Commands: []*cli.Command{
+					{Name: "list", Metadata: Requires(CapDBus, CapHost), Action: capture},
+				},
+			},
+			{Name: "install", Action: capture},
+		},
+	}
+
+	if err := root.Run(context.Background(), []string{"apm", "distrobox", "list"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := RequiredCapabilities(invoked); !slices.Equal(got, []Capability{CapDBus, CapHost}) {
+		t.Errorf("unexpected capabilities %v", got)
+	}
+	if err := checkCapabilities(invoked, true); err == nil {
+		t.Error("expected command requiring host to be rejected inside image build")
+	}
+	if err := checkCapabilities(invoked, false); err != nil {
+		t.Errorf("unexpected error outside image build: %v", err)
+	}
+
+	if err := root.Run(context.Background(), []string{"apm", "install"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkCapabilities(invoked, true); err != nil {
+		t.Errorf("expected command without requirements to be allowed: %v", err)
+	}
+}
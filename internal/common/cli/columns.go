@@ -0,0 +1,102 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+)
+
+// ColumnsFlag возвращает флаг выбора колонок для команд, выводящих списки.
+func ColumnsFlag() cli.Flag {
+	return &cli.StringSliceFlag{
+		Name:  "columns",
+		Usage: app.T_("Output only specified columns of list items, for example: name,version,size"),
+	}
+}
+
+// AvailableColumns возвращает имена колонок элемента списка по json-тегам его структуры.
+func AvailableColumns(item any) []string {
+	t := reflect.TypeOf(item)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var columns []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		// Поля встроенных структур json поднимает на уровень выше
+		if field.Anonymous && name == "" {
+			columns = append(columns, AvailableColumns(reflect.Zero(field.Type).Interface())...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		columns = append(columns, name)
+	}
+
+	return columns
+}
+
+// ApplyColumns проверяет выбранные колонки по структуре элемента списка и ограничивает ими вывод.
+// Возвращает true, если колонки заданы.
+func ApplyColumns(appConfig *app.Config, columns []string, item any) (bool, error) {
+	if len(columns) == 0 {
+		return false, nil
+	}
+
+	available := AvailableColumns(item)
+	known := make(map[string]bool, len(available))
+	for _, name := range available {
+		known[name] = true
+	}
+
+	var selected []string
+	for _, column := range columns {
+		column = strings.TrimSpace(column)
+		if column == "" {
+			continue
+		}
+		if !known[column] {
+			return false, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(
+				app.T_("Unknown column %q. Available columns: %s"), column, strings.Join(available, ", ")))
+		}
+		selected = append(selected, column)
+	}
+	if len(selected) == 0 {
+		return false, nil
+	}
+
+	appConfig.ConfigManager.SetFields(selected)
+	return true, nil
+}
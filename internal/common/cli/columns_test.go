@@ -0,0 +1,69 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/testutil"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type columnsBase struct {
+	Name string `json:"name"`
+}
+
+type columnsItem struct {
+	columnsBase
+	Version  string `json:"version"`
+	Size     int    `json:"size,omitempty"`
+	Internal bool   `json:"-"`
+}
+
+func TestAvailableColumns(t *testing.T) {
+	got := AvailableColumns(&columnsItem{})
+	want := []string{"name", "version", "size"}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestApplyColumns(t *testing.T) {
+	appConfig := &app.Config{ConfigManager: &testutil.MockConfigManager{Config: &app.Configuration{}}}
+
+	applied, err := ApplyColumns(appConfig, nil, columnsItem{})
+	if err != nil || applied {
+		t.Fatalf("expected no columns applied, got %v, %v", applied, err)
+	}
+
+	applied, err = ApplyColumns(appConfig, []string{"name", " size"}, columnsItem{})
+	if err != nil || !applied {
+		t.Fatalf("expected columns applied, got %v, %v", applied, err)
+	}
+
+	_, err = ApplyColumns(appConfig, []string{"name", "repo"}, columnsItem{})
+	var apmErr apmerr.APMError
+	if !errors.As(err, &apmErr) || apmErr.Type != apmerr.ErrorTypeValidation {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "repo") || !strings.Contains(err.Error(), "name, version, size") {
+		t.Errorf("expected error to list available columns, got %q", err.Error())
+	}
+}
@@ -18,6 +18,7 @@ package cli
 
 import (
 	"apm/internal/common/app"
+	"context"
 
 	"github.com/urfave/cli/v3"
 )
@@ -30,9 +31,33 @@ func HelpCommand() *cli.Command {
 		Usage:     app.T_("Show the list of commands or help for each command"),
 		ArgsUsage: app.T_("[command]"),
 		HideHelp:  true,
+		Action:    helpAction,
 	}
 }
 
+// helpAction показывает справку по корню, по вложенной команде (apm help repo list)
+// или по теме exit-codes.
+func helpAction(ctx context.Context, cmd *cli.Command) error {
+	args := cmd.Args().Slice()
+	root := cmd.Root()
+	if len(args) == 0 {
+		return cli.ShowRootCommandHelp(root)
+	}
+	if len(args) == 1 && args[0] == ExitCodeTopic {
+		return PrintExitCodes(root.Writer)
+	}
+
+	parent := root
+	for _, name := range args[:len(args)-1] {
+		sub := parent.Command(name)
+		if sub == nil {
+			return cli.ShowCommandHelp(ctx, parent, name)
+		}
+		parent = sub
+	}
+	return cli.ShowCommandHelp(ctx, parent, args[len(args)-1])
+}
+
 // VersionCommand возвращает команду version с заданным action.
 func VersionCommand(action cli.ActionFunc) *cli.Command {
 	return &cli.Command{
@@ -49,6 +74,7 @@ func NewDBusCommand(name, usage string, action cli.ActionFunc) *cli.Command {
 		Name:     name,
 		Usage:    usage,
 		Category: app.T_("Services"),
+		Metadata: Requires(CapDBus),
 		Action:   action,
 	}
 }
@@ -59,6 +85,7 @@ func NewHTTPCommand(name, usage, defaultListen string, action cli.ActionFunc) *c
 		Name:     name,
 		Usage:    usage,
 		Category: app.T_("Services"),
+		Metadata: Requires(CapSystemd),
 		Action:   action,
 		Flags: []cli.Flag{
 			&cli.StringFlag{
@@ -72,6 +99,14 @@ func NewHTTPCommand(name, usage, defaultListen string, action cli.ActionFunc) *c
 				Usage:   app.T_("API token in format <read|manage>:<token> (prefer APM_API_TOKEN env)"),
 				Sources: cli.EnvVars("APM_API_TOKEN"),
 			},
+			&cli.StringFlag{
+				Name:  "socket",
+				Usage: app.T_("Unix socket path to listen on (overrides httpSocket.path from the configuration)"),
+			},
+			&cli.BoolFlag{
+				Name:  "socket-only",
+				Usage: app.T_("Listen only on the unix socket without opening a TCP port"),
+			},
 		},
 	}
 }
@@ -0,0 +1,124 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+)
+
+// completionFlag служебный флаг, который скрипты автодополнения добавляют к командной строке.
+const completionFlag = "--generate-shell-completion"
+
+// CompletionLimit ограничивает число вариантов, выводимых за одно автодополнение.
+const CompletionLimit = 200
+
+// CompletionToken возвращает дополняемое слово и слово перед ним.
+// Скрипты автодополнения всегда передают текущее слово, даже пустое. Разбор идёт по os.Args,
+// потому что urfave/cli забирает дополняемое слово в значение флага и его нет в cmd.Args().
+func CompletionToken() (current, previous string) {
+	return completionToken(os.Args[1:])
+}
+
+func completionToken(args []string) (current, previous string) {
+	if n := len(args); n > 0 && args[n-1] == completionFlag {
+		args = args[:n-1]
+	}
+	if n := len(args); n > 0 {
+		current = strings.TrimSpace(args[n-1])
+		if n > 1 {
+			previous = args[n-2]
+		}
+	}
+	return current, previous
+}
+
+// CompletingFlag сообщает, дополняется ли значение флага с одним из указанных имён.
+func CompletingFlag(names ...string) bool {
+	_, previous := CompletionToken()
+	return isFlagName(previous, names)
+}
+
+// CompletingFlagValue сообщает, дополняется ли значение любого небулевого флага команды.
+// Позиционные варианты в этом случае выводить не нужно.
+func CompletingFlagValue(cmd *cli.Command) bool {
+	_, previous := CompletionToken()
+	for _, flag := range cmd.Flags {
+		if _, ok := flag.(*cli.BoolFlag); ok {
+			continue
+		}
+		if isFlagName(previous, flag.Names()) {
+			return true
+		}
+	}
+	return false
+}
+
+func isFlagName(arg string, names []string) bool {
+	name, ok := strings.CutPrefix(arg, "-")
+	if !ok {
+		return false
+	}
+	name = strings.TrimPrefix(name, "-")
+	return name != "" && slices.Contains(names, name)
+}
+
+// CompleteFlags выводит флаги команды, если дополняемое слово начинается с дефиса.
+// Возвращает true, когда дальнейшее дополнение значений не требуется.
+func CompleteFlags(ctx context.Context, cmd *cli.Command) bool {
+	current, _ := CompletionToken()
+	if !strings.HasPrefix(current, "-") {
+		return false
+	}
+	cli.DefaultCompleteWithFlags(ctx, cmd)
+	return true
+}
+
+// PrintCompletions выводит варианты, начинающиеся с prefix, без повторов и без значений из exclude.
+func PrintCompletions(prefix string, values []string, exclude ...string) {
+	for _, value := range filterCompletions(prefix, values, exclude) {
+		fmt.Println(value)
+	}
+}
+
+func filterCompletions(prefix string, values []string, exclude []string) []string {
+	seen := make(map[string]struct{}, len(values)+len(exclude))
+	for _, e := range exclude {
+		seen[strings.TrimRight(strings.TrimSpace(e), "+-")] = struct{}{}
+	}
+
+	var result []string
+	for _, value := range values {
+		if value == "" || !strings.HasPrefix(value, prefix) {
+			continue
+		}
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+		result = append(result, value)
+		if len(result) >= CompletionLimit {
+			break
+		}
+	}
+	return result
+}
@@ -0,0 +1,100 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+)
+
+func TestCompletionToken(t *testing.T) {
+	cases := []struct {
+		args              []string
+		current, previous string
+	}{
+		{[]string{"s", "install", "fi", completionFlag}, "fi", "install"},
+		{[]string{"s", "install", "", completionFlag}, "", "install"},
+		{[]string{"d", "install", "-c", "", completionFlag}, "", "-c"},
+		{[]string{completionFlag}, "", ""},
+		{nil, "", ""},
+	}
+	for _, c := range cases {
+		current, previous := completionToken(c.args)
+		if current != c.current || previous != c.previous {
+			t.Errorf("completionToken(%q) = %q, %q; want %q, %q", c.args, current, previous, c.current, c.previous)
+		}
+	}
+}
+
+func TestIsFlagName(t *testing.T) {
+	names := []string{"container", "c"}
+	for _, arg := range []string{"-c", "--container", "--c"} {
+		if !isFlagName(arg, names) {
+			t.Errorf("isFlagName(%q) = false", arg)
+		}
+	}
+	for _, arg := range []string{"c", "-", "--", "--name", "container"} {
+		if isFlagName(arg, names) {
+			t.Errorf("isFlagName(%q) = true", arg)
+		}
+	}
+}
+
+func TestFilterCompletions(t *testing.T) {
+	got := filterCompletions("fi", []string{"firefox", "file", "vim", "firefox", "", "fish"}, []string{"fish+"})
+	want := []string{"firefox", "file"}
+	if !slices.Equal(got, want) {
+		t.Errorf("filterCompletions = %q, want %q", got, want)
+	}
+
+	values := make([]string, CompletionLimit+10)
+	for i := range values {
+		values[i] = "pkg" + string(rune('a'+i%26)) + string(rune('a'+i/26))
+	}
+	if got = filterCompletions("", values, nil); len(got) != CompletionLimit {
+		t.Errorf("filterCompletions returned %d values, want %d", len(got), CompletionLimit)
+	}
+}
+
+func TestCompletingFlagValue(t *testing.T) {
+	cmd := &cli.Command{
+		Name: "install",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "container", Aliases: []string{"c"}},
+			&cli.BoolFlag{Name: "simulate", Aliases: []string{"s"}},
+		},
+	}
+
+	saved := os.Args
+	t.Cleanup(func() { os.Args = saved })
+
+	cases := map[string]bool{
+		"-c":          true,
+		"--container": true,
+		"-s":          false,
+		"vim":         false,
+	}
+	for previous, want := range cases {
+		os.Args = []string{"apm", "d", "install", previous, "", completionFlag}
+		if got := CompletingFlagValue(cmd); got != want {
+			t.Errorf("CompletingFlagValue after %q = %v, want %v", previous, got, want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"fmt"
+	"slices"
+
+	"github.com/urfave/cli/v3"
+)
+
+// simulateFlag локальный флаг команды, включающий имитацию выполнения
+const simulateFlag = "simulate"
+
+// supportsSimulate сообщает, умеет ли команда имитировать выполнение
+func supportsSimulate(cmd *cli.Command) bool {
+	return slices.ContainsFunc(cmd.Flags, func(f cli.Flag) bool {
+		return slices.Contains(f.Names(), simulateFlag)
+	})
+}
+
+// applyDryRun включает имитацию по глобальному флагу --dry-run. Команды без флага simulate
+// не умеют показывать изменения заранее, поэтому отклоняют --dry-run и ничего не выполняют.
+func applyDryRun(cmd *cli.Command) error {
+	if !cmd.Bool("dry-run") {
+		return nil
+	}
+	if !supportsSimulate(cmd) {
+		return apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(
+			app.T_("Command '%s' does not support --dry-run"), cmd.FullName()))
+	}
+	return cmd.Set(simulateFlag, "true")
+}
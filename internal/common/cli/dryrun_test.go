@@ -0,0 +1,81 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"apm/internal/common/apmerr"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+)
+
+// runDryRun запускает подкоманду с глобальным --dry-run и возвращает значение simulate в её действии
+func runDryRun(t *testing.T, withSimulate bool) (bool, error) {
+	t.Helper()
+
+	var simulated, called bool
+	sub := &cli.Command{
+		Name: "install",
+		Action: func(_ context.Context, cmd *cli.Command) error {
+			called = true
+			simulated = withSimulate && cmd.Bool(simulateFlag)
+			return nil
+		},
+	}
+	if withSimulate {
+		sub.Flags = []cli.Flag{&cli.BoolFlag{Name: simulateFlag, Aliases: []string{"s"}}}
+	}
+	root := &cli.Command{
+		Name:     "apm",
+		Flags:    []cli.Flag{&cli.BoolFlag{Name: "dry-run"}},
+		Commands: []*cli.Command{sub},
+	}
+
+	var unavailable error
+	ApplyCommandSettings(root, CommandHooks{
+		OnUnavailable: func(_ context.Context, _ *cli.Command, err error) error {
+			unavailable = err
+			return err
+		},
+	})
+
+	err := root.Run(context.Background(), []string{"apm", "--dry-run", "install"})
+	if called && unavailable != nil {
+		t.Fatal("action must not run when --dry-run is rejected")
+	}
+	return simulated, err
+}
+
+func TestDryRunEnablesSimulate(t *testing.T) {
+	simulated, err := runDryRun(t, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !simulated {
+		t.Error("--dry-run must enable the simulate flag of the command")
+	}
+}
+
+func TestDryRunRejectedWithoutSimulate(t *testing.T) {
+	_, err := runDryRun(t, false)
+	var apmErr apmerr.APMError
+	if !errors.As(err, &apmErr) || apmErr.Type != apmerr.ErrorTypeValidation {
+		t.Fatalf("expected VALIDATION error, got %v", err)
+	}
+}
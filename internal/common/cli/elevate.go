@@ -0,0 +1,130 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"apm/internal/common/app"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// pkexecPath путь к pkexec, через который выполняется повышение прав
+const pkexecPath = "pkexec"
+
+// Коды завершения pkexec, означающие отказ в авторизации.
+const (
+	pkexecDismissed     = 126
+	pkexecNotAuthorized = 127
+)
+
+// pkexecErrorPrefix начало сообщения, которое pkexec печатает при собственной ошибке. Коды 126 и 127
+// может вернуть и сама команда, поэтому отказ в авторизации определяется только вместе с этим сообщением.
+const pkexecErrorPrefix = "Error executing command as another user"
+
+// stderrTailSize сколько последних байт stderr сохраняется для поиска сообщения pkexec
+const stderrTailSize = 4096
+
+// tailWriter хранит последние байты записанного потока
+type tailWriter struct {
+	buf []byte
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	if len(w.buf) > stderrTailSize {
+		w.buf = w.buf[len(w.buf)-stderrTailSize:]
+	}
+	return len(p), nil
+}
+
+// ErrElevatedFailed возвращается, когда команда, запущенная через polkit, завершилась с ошибкой.
+// Вывод ошибки уже напечатан дочерним процессом, поэтому текст пустой.
+var ErrElevatedFailed = errors.New("")
+
+// elevatedExitError сохраняет код завершения дочернего процесса, чтобы apm вернул его вызывающему
+type elevatedExitError struct {
+	status int
+}
+
+func (e *elevatedExitError) Error() string { return "" }
+
+func (e *elevatedExitError) Is(target error) bool { return target == ErrElevatedFailed }
+
+// ExitStatus возвращает код завершения команды, выполненной через polkit
+func (e *elevatedExitError) ExitStatus() int { return e.status }
+
+// shouldElevate определяет, нужно ли выполнить команду через polkit вместо отказа по правам.
+// Повышение прав доступно только на неатомарных системах, если оно не отключено в конфигурации.
+func shouldElevate(isRoot bool, mode RootCheckMode, cfg *app.Configuration) bool {
+	return mode == RequireRoot && !isRoot && !cfg.IsAtomic && cfg.PolkitFallback
+}
+
+// elevatedArgs формирует аргументы pkexec для повторного запуска текущей команды.
+func elevatedArgs(executable string, args []string) []string {
+	result := make([]string, 0, len(args)+1)
+	result = append(result, executable)
+	if len(args) > 1 {
+		result = append(result, args[1:]...)
+	}
+	return result
+}
+
+// runElevated повторно запускает текущую команду от root через pkexec.
+// Процесс живёт только до завершения одной транзакции, ввод и вывод передаются напрямую,
+// поэтому диалоги подтверждения и прогресс работают как при запуске через sudo.
+func runElevated() error {
+	pkexec, err := exec.LookPath(pkexecPath)
+	if err != nil {
+		return errors.New(app.T_("Elevated rights are required to perform this action. Please use sudo or su"))
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf(app.T_("Failed to determine the path to apm: %w"), err)
+	}
+
+	cmd := exec.Command(pkexec, elevatedArgs(executable, os.Args)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	stderr := &tailWriter{}
+	cmd.Stderr = io.MultiWriter(os.Stderr, stderr)
+
+	err = cmd.Run()
+	if err == nil {
+		return nil
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return fmt.Errorf(app.T_("Failed to run pkexec: %w"), err)
+	}
+
+	return elevatedError(exitErr.ExitCode(), string(stderr.buf))
+}
+
+// elevatedError превращает код завершения pkexec в ошибку. Коды 126 и 127 считаются отказом
+// в авторизации, только если pkexec сообщил о собственной ошибке, иначе это код самой команды.
+func elevatedError(status int, stderr string) error {
+	if (status == pkexecDismissed || status == pkexecNotAuthorized) && strings.Contains(stderr, pkexecErrorPrefix) {
+		return errors.New(app.T_("Authorization was not granted. Please use sudo or su"))
+	}
+	return &elevatedExitError{status: status}
+}
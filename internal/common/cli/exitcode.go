@@ -0,0 +1,156 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/reply"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"text/tabwriter"
+)
+
+// Коды завершения apm. Скрипты могут ветвиться по ним вместо разбора вывода.
+const (
+	ExitSuccess     = 0
+	ExitFailure     = 1
+	ExitUsage       = 2
+	ExitPermission  = 3
+	ExitLocked      = 4
+	ExitNetwork     = 5
+	ExitDependency  = 6
+	ExitNotFound    = 7
+	ExitCanceled    = 8
+	ExitNoOperation = 9
+)
+
+// ExitCodeTopic тема справки со списком кодов завершения: apm help exit-codes
+const ExitCodeTopic = "exit-codes"
+
+// exitCodeEntry описывает код завершения и типы ошибок, которые к нему приводят
+type exitCodeEntry struct {
+	Code        int
+	Name        string
+	ErrorTypes  []string
+	Description func() string
+}
+
+// exitCodes таблица кодов завершения, из неё строится и классификация ошибок, и справка
+var exitCodes = []exitCodeEntry{
+	{ExitSuccess, "success", nil, func() string { return app.T_("The command completed successfully") }},
+	{ExitFailure, "failure", []string{apmerr.ErrorTypeDatabase, apmerr.ErrorTypeRepository, apmerr.ErrorTypeApt,
+		apmerr.ErrorTypeImage, apmerr.ErrorTypeKernel, apmerr.ErrorTypeContainer, apmerr.ErrorTypeHook},
+		func() string { return app.T_("General error not covered by other codes") }},
+	{ExitUsage, "usage", []string{apmerr.ErrorTypeValidation},
+		func() string { return app.T_("Invalid arguments, flags or unknown command") }},
+	{ExitPermission, "permission", []string{apmerr.ErrorTypePermission},
+		func() string { return app.T_("Permission denied: root rights or authorization are required") }},
+	{ExitLocked, "locked", []string{apmerr.ErrorTypeBusy},
+		func() string { return app.T_("The package database is locked by another process") }},
+	{ExitNetwork, "network", nil,
+		func() string { return app.T_("Failed to download indexes or packages") }},
+	{ExitDependency, "dependency", nil,
+		func() string { return app.T_("Dependencies cannot be resolved or packages conflict") }},
+	{ExitNotFound, "not-found", []string{apmerr.ErrorTypeNotFound},
+		func() string { return app.T_("Package, image or other object not found") }},
+	{ExitCanceled, "canceled", []string{apmerr.ErrorTypeCanceled},
+		func() string { return app.T_("The operation was cancelled in a dialog or by a signal") }},
+	{ExitNoOperation, "nothing-to-do", []string{apmerr.ErrorTypeNoOperation},
+		func() string { return app.T_("Nothing to do: the system is already in the requested state") }},
+}
+
+// ExitCode возвращает код завершения процесса для ошибки, которой завершилась команда
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitSuccess
+	}
+
+	// Команда, перезапущенная через polkit, уже выбрала код завершения
+	var status interface{ ExitStatus() int }
+	if errors.As(err, &status) {
+		return status.ExitStatus()
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return ExitCanceled
+	}
+
+	var lock interface{ IsLockError() bool }
+	if errors.As(err, &lock) && lock.IsLockError() {
+		return ExitLocked
+	}
+
+	var network interface{ IsNetworkError() bool }
+	if errors.As(err, &network) && network.IsNetworkError() {
+		return ExitNetwork
+	}
+	var opErr *net.OpError
+	var urlErr *url.Error
+	if errors.As(err, &opErr) || errors.As(err, &urlErr) {
+		return ExitNetwork
+	}
+
+	var dependency interface{ IsDependencyError() bool }
+	if errors.As(err, &dependency) && dependency.IsDependencyError() {
+		return ExitDependency
+	}
+
+	return exitCodeForType(errorType(err))
+}
+
+// errorType возвращает тип ошибки apmerr из цепочки. Если исходная ошибка потеряна,
+// используется код, сохранённый в ответе CLI.
+func errorType(err error) string {
+	var apmErr apmerr.APMError
+	if errors.As(err, &apmErr) {
+		return apmErr.Type
+	}
+	var cliErr *reply.CliError
+	if errors.As(err, &cliErr) {
+		return cliErr.ErrorCode
+	}
+	return ""
+}
+
+// exitCodeForType ищет код завершения по типу ошибки в таблице
+func exitCodeForType(errorType string) int {
+	for _, entry := range exitCodes {
+		for _, t := range entry.ErrorTypes {
+			if t == errorType {
+				return entry.Code
+			}
+		}
+	}
+	return ExitFailure
+}
+
+// PrintExitCodes выводит таблицу кодов завершения для apm help exit-codes
+func PrintExitCodes(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, app.T_("Exit codes:")); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, entry := range exitCodes {
+		_, _ = fmt.Fprintf(tw, "   %d\t%s\t%s\n", entry.Code, entry.Name, entry.Description())
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,99 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/apt"
+	"apm/internal/common/reply"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, ExitSuccess},
+		{"plain error", errors.New("boom"), ExitFailure},
+		{"validation", apmerr.New(apmerr.ErrorTypeValidation, errors.New("bad")), ExitUsage},
+		{"permission", apmerr.New(apmerr.ErrorTypePermission, errors.New("denied")), ExitPermission},
+		{"busy", apmerr.New(apmerr.ErrorTypeBusy, errors.New("busy")), ExitLocked},
+		{"not found", apmerr.New(apmerr.ErrorTypeNotFound, errors.New("missing")), ExitNotFound},
+		{"canceled", apmerr.New(apmerr.ErrorTypeCanceled, errors.New("cancel")), ExitCanceled},
+		{"context canceled", fmt.Errorf("wrap: %w", context.Canceled), ExitCanceled},
+		{"no operation", apmerr.New(apmerr.ErrorTypeNoOperation, errors.New("nothing")), ExitNoOperation},
+		{"apt lock", apmerr.New(apmerr.ErrorTypeApt, &apt.MatchedError{Entry: apt.ErrorEntry{Code: apt.ErrAptLockFailed}}), ExitLocked},
+		{"apt fetch", apmerr.New(apmerr.ErrorTypeApt, &apt.MatchedError{Entry: apt.ErrorEntry{Code: apt.ErrFailedToFetch}}), ExitNetwork},
+		{"apt broken", apmerr.New(apmerr.ErrorTypeApt, &apt.MatchedError{Entry: apt.ErrorEntry{Code: apt.ErrBrokenPackages}}), ExitDependency},
+		{"apt other", apmerr.New(apmerr.ErrorTypeApt, &apt.MatchedError{Entry: apt.ErrorEntry{Code: apt.ErrOperationCancelled}}), ExitFailure},
+		{"http", &url.Error{Op: "Get", URL: "http://example", Err: errors.New("refused")}, ExitNetwork},
+		{"elevated child", &elevatedExitError{status: ExitDependency}, ExitDependency},
+		{"cli response with cause", &reply.CliError{ErrorCode: apmerr.ErrorTypeApt,
+			Err: apmerr.New(apmerr.ErrorTypeNotFound, errors.New("missing"))}, ExitNotFound},
+		{"cli response without cause", &reply.CliError{ErrorCode: apmerr.ErrorTypePermission}, ExitPermission},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ExitCode(tc.err); got != tc.want {
+				t.Errorf("ExitCode(%v) = %d, want %d", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestElevatedExitErrorIsElevatedFailed(t *testing.T) {
+	if !errors.Is(&elevatedExitError{status: 4}, ErrElevatedFailed) {
+		t.Error("elevated child error must match ErrElevatedFailed")
+	}
+}
+
+func TestExitCodesTableUnique(t *testing.T) {
+	codes := map[int]bool{}
+	types := map[string]bool{}
+	for _, entry := range exitCodes {
+		if codes[entry.Code] {
+			t.Errorf("duplicate exit code %d", entry.Code)
+		}
+		codes[entry.Code] = true
+		for _, errorType := range entry.ErrorTypes {
+			if types[errorType] {
+				t.Errorf("error type %s mapped to several exit codes", errorType)
+			}
+			types[errorType] = true
+		}
+	}
+}
+
+func TestPrintExitCodes(t *testing.T) {
+	var buf bytes.Buffer
+	if err := PrintExitCodes(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range exitCodes {
+		if !strings.Contains(buf.String(), entry.Name) {
+			t.Errorf("output misses %q:\n%s", entry.Name, buf.String())
+		}
+	}
+}
@@ -18,6 +18,9 @@ package cli
 
 import (
 	"apm/internal/common/app"
+	"apm/internal/common/helper"
+	"context"
+	"strings"
 
 	"github.com/urfave/cli/v3"
 )
@@ -51,5 +54,84 @@ func RootFlags() []cli.Flag {
 			Aliases: []string{"v"},
 			Usage:   app.T_("Enable verbose logging to stdout"),
 		},
+		&cli.BoolFlag{
+			Name:  "wait",
+			Usage: app.T_("Wait for another running apm operation to finish instead of failing"),
+		},
+		&cli.BoolFlag{
+			Name:  "accessible",
+			Usage: app.T_("Show progress as plain text status lines instead of animation, for screen readers"),
+		},
+		&cli.BoolFlag{
+			Name:  "yes",
+			Usage: app.T_("Answer yes to all confirmation dialogs"),
+		},
+		&cli.BoolFlag{
+			Name:  "assume-no",
+			Usage: app.T_("Answer no to all confirmation dialogs"),
+		},
+		&cli.BoolFlag{
+			Name:  "non-interactive",
+			Usage: app.T_("Do not show dialogs, use the answer from nonInteractiveAnswer in the configuration"),
+		},
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: app.T_("Only show the changes the command would make, for commands that support simulation"),
+		},
+		&cli.StringFlag{
+			Name:    "lang",
+			Usage:   app.T_("Language of messages, e.g. ru or en_US (overrides the system locale)"),
+			Sources: cli.EnvVars("APM_LANG"),
+		},
+	}
+}
+
+// WithAnswer кладёт в ctx заранее заданный ответ на диалоги по флагам --yes, --assume-no и --non-interactive.
+// Отказ имеет приоритет: при одновременном --yes и --assume-no ничего не выполняется без подтверждения.
+func WithAnswer(ctx context.Context, cmd *cli.Command, cfg *app.Configuration) context.Context {
+	if answer := answerFromFlags(globalBool(cmd, "yes"), globalBool(cmd, "assume-no"), globalBool(cmd, "non-interactive"), cfg.NonInteractiveAnswer); answer != "" {
+		return context.WithValue(ctx, helper.AnswerKey, answer)
+	}
+	return ctx
+}
+
+// globalBool возвращает значение глобального флага. Локальный флаг команды с тем же именем
+// (например, --yes у system install) скрывает глобальный при обычном поиске, поэтому корневая
+// команда проверяется отдельно.
+func globalBool(cmd *cli.Command, name string) bool {
+	return cmd.Bool(name) || cmd.Root().Bool(name)
+}
+
+// answerFromFlags выбирает ответ на диалоги. Для --non-interactive берётся ответ из конфигурации,
+// любое значение кроме yes считается отказом.
+func answerFromFlags(yes, assumeNo, nonInteractive bool, configured string) string {
+	switch {
+	case assumeNo:
+		return helper.AnswerNo
+	case yes:
+		return helper.AnswerYes
+	case nonInteractive && configured == helper.AnswerYes:
+		return helper.AnswerYes
+	case nonInteractive:
+		return helper.AnswerNo
+	default:
+		return ""
+	}
+}
+
+// LanguageFromArgs находит значение --lang в аргументах до разбора команд:
+// язык нужно переключить раньше, чем будут переведены описания команд и флагов.
+func LanguageFromArgs(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if value, ok := strings.CutPrefix(arg, "--lang="); ok {
+			return value
+		}
+		if arg == "--lang" && i+1 < len(args) {
+			return args[i+1]
+		}
 	}
+	return ""
 }
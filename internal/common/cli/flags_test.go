@@ -0,0 +1,95 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/helper"
+	"context"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+)
+
+func TestLanguageFromArgs(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"system", "install", "vim"}, ""},
+		{[]string{"--lang", "ru", "system", "list"}, "ru"},
+		{[]string{"system", "list", "--lang=en_US.UTF-8"}, "en_US.UTF-8"},
+		{[]string{"system", "install", "--", "--lang", "ru"}, ""},
+		{[]string{"--lang"}, ""},
+	}
+	for _, tt := range tests {
+		if got := LanguageFromArgs(tt.args); got != tt.want {
+			t.Errorf("LanguageFromArgs(%v) = %q; want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestAnswerFromFlags(t *testing.T) {
+	tests := []struct {
+		name                          string
+		yes, assumeNo, nonInteractive bool
+		configured                    string
+		want                          string
+	}{
+		{"no flags", false, false, false, "yes", ""},
+		{"yes", true, false, false, "no", "yes"},
+		{"assume-no", false, true, false, "yes", "no"},
+		{"assume-no wins over yes", true, true, false, "", "no"},
+		{"non-interactive configured yes", false, false, true, "yes", "yes"},
+		{"non-interactive configured no", false, false, true, "no", "no"},
+		{"non-interactive unknown value", false, false, true, "maybe", "no"},
+		{"yes wins over non-interactive", true, false, true, "no", "yes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := answerFromFlags(tt.yes, tt.assumeNo, tt.nonInteractive, tt.configured); got != tt.want {
+				t.Errorf("answerFromFlags() = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithAnswerReadsGlobalYes(t *testing.T) {
+	var answer string
+	root := &cli.Command{
+		Name:  "apm",
+		Flags: []cli.Flag{&cli.BoolFlag{Name: "yes"}, &cli.BoolFlag{Name: "assume-no"}, &cli.BoolFlag{Name: "non-interactive"}},
+		Commands: []*cli.Command{{
+			Name:  "install",
+			Flags: []cli.Flag{&cli.BoolFlag{Name: "yes", Aliases: []string{"y"}}},
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				answer, _ = WithAnswer(ctx, cmd, &app.Configuration{}).Value(helper.AnswerKey).(string)
+				return nil
+			},
+		}},
+	}
+
+	for _, args := range [][]string{{"apm", "--yes", "install"}, {"apm", "install", "-y"}} {
+		answer = ""
+		if err := root.Run(context.Background(), args); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if answer != helper.AnswerYes {
+			t.Errorf("%v: answer = %q; want %q", args, answer, helper.AnswerYes)
+		}
+	}
+}
@@ -18,6 +18,7 @@ package cli
 
 import (
 	"apm/internal/common/app"
+	"apm/internal/common/helper"
 	"context"
 	"fmt"
 	"strings"
@@ -28,8 +29,9 @@ import (
 )
 
 type CommandHooks struct {
-	OnNotFound   func(ctx context.Context, cmd *cli.Command, name string)
-	OnUsageError func(err error) error
+	OnNotFound    func(ctx context.Context, cmd *cli.Command, name string)
+	OnUsageError  func(err error) error
+	OnUnavailable func(ctx context.Context, cmd *cli.Command, err error) error
 }
 
 // ApplyCommandSettings обходит дерево команд рекурсивно и применяет общие настройки.
@@ -45,6 +47,18 @@ func ApplyCommandSettings(cmd *cli.Command, hooks CommandHooks) {
 			return hooks.OnUsageError(err)
 		}
 	}
+	if hooks.OnUnavailable != nil && cmd.Action != nil {
+		action := cmd.Action
+		cmd.Action = func(ctx context.Context, c *cli.Command) error {
+			if err := checkCapabilities(c, helper.IsBuildEnvironment()); err != nil {
+				return hooks.OnUnavailable(ctx, c, err)
+			}
+			if err := applyDryRun(c); err != nil {
+				return hooks.OnUnavailable(ctx, c, err)
+			}
+			return action(ctx, c)
+		}
+	}
 	for _, sub := range cmd.Commands {
 		ApplyCommandSettings(sub, hooks)
 	}
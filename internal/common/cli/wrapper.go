@@ -80,10 +80,27 @@ func WithOptions[T any](
 				appConfig.ConfigManager.SetFields(fields)
 			}
 			ctx = context.WithValue(ctx, helper.TransactionKey, cmd.String("transaction"))
+			if cmd.Bool("wait") {
+				ctx = context.WithValue(ctx, helper.WaitLockKey, true)
+			}
+			ctx = WithAnswer(ctx, cmd, appConfig.ConfigManager.GetConfig())
 
 			if cmd.Bool("verbose") {
 				appConfig.ConfigManager.EnableVerbose()
 			}
+			if cmd.Bool("accessible") {
+				appConfig.ConfigManager.EnableAccessible()
+			}
+
+			// Внутри сборки образа polkit недоступен, команды выполняются напрямую
+			if shouldElevate(syscall.Geteuid() == 0, rootCheck, appConfig.ConfigManager.GetConfig()) && !helper.IsBuildEnvironment() {
+				err := runElevated()
+				if err == nil || errors.Is(err, ErrElevatedFailed) {
+					return err
+				}
+				return reporter.CliResponse(ctx, errorResponse(
+					apmerr.New(apmerr.ErrorTypePermission, err)))
+			}
 
 			if err := CheckRoot(rootCheck); err != nil {
 				return reporter.CliResponse(ctx, errorResponse(
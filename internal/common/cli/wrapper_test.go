@@ -16,7 +16,13 @@
 
 package cli
 
-import "testing"
+import (
+	"apm/internal/common/app"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
 
 func TestEvaluateRootCheck(t *testing.T) {
 	cases := []struct {
@@ -43,3 +49,71 @@ func TestEvaluateRootCheck(t *testing.T) {
 		})
 	}
 }
+
+func TestShouldElevate(t *testing.T) {
+	cases := []struct {
+		name   string
+		isRoot bool
+		mode   RootCheckMode
+		cfg    app.Configuration
+		want   bool
+	}{
+		{"user requires root", false, RequireRoot, app.Configuration{PolkitFallback: true}, true},
+		{"already root", true, RequireRoot, app.Configuration{PolkitFallback: true}, false},
+		{"no root check", false, NoRootCheck, app.Configuration{PolkitFallback: true}, false},
+		{"atomic system", false, RequireRoot, app.Configuration{PolkitFallback: true, IsAtomic: true}, false},
+		{"disabled in config", false, RequireRoot, app.Configuration{}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := shouldElevate(tc.isRoot, tc.mode, &tc.cfg); got != tc.want {
+				t.Fatalf("shouldElevate() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestElevatedArgs(t *testing.T) {
+	got := elevatedArgs("/usr/bin/apm", []string{"apm", "s", "install", "vim", "-f", "json"})
+	want := []string{"/usr/bin/apm", "s", "install", "vim", "-f", "json"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("elevatedArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestElevatedError(t *testing.T) {
+	cases := []struct {
+		name   string
+		status int
+		stderr string
+		denied bool
+	}{
+		{"dismissed", 126, "Error executing command as another user: Request dismissed\n", true},
+		{"not authorized", 127, "Error executing command as another user: Not authorized\n\nThis incident has been reported.\n", true},
+		{"command exit 127", 127, "sh: foo: command not found\n", false},
+		{"command exit 126", 126, "", false},
+		{"command failure", ExitDependency, "Error executing command as another user: Not authorized\n", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := elevatedError(tc.status, tc.stderr)
+			var status interface{ ExitStatus() int }
+			isExit := errors.As(err, &status)
+			if isExit == tc.denied {
+				t.Fatalf("elevatedError() = %v, authorization denied expected: %v", err, tc.denied)
+			}
+			if isExit && status.ExitStatus() != tc.status {
+				t.Errorf("exit status = %d, want %d", status.ExitStatus(), tc.status)
+			}
+		})
+	}
+}
+
+func TestTailWriter(t *testing.T) {
+	w := &tailWriter{}
+	_, _ = w.Write([]byte(strings.Repeat("x", stderrTailSize)))
+	_, _ = w.Write([]byte(pkexecErrorPrefix))
+	if len(w.buf) != stderrTailSize || !strings.HasSuffix(string(w.buf), pkexecErrorPrefix) {
+		t.Errorf("unexpected tail of %d bytes", len(w.buf))
+	}
+}
@@ -32,6 +32,25 @@ func GenerateIntrospectXML(interfaces map[string]any) string {
     <signal name="Notification">
       <arg type="s" name="message" direction="out"/>
     </signal>
+    <signal name="PackagesChanged">
+      <arg type="s" name="module" direction="out"/>
+      <arg type="s" name="action" direction="out"/>
+      <arg type="as" name="installed" direction="out"/>
+      <arg type="as" name="upgraded" direction="out"/>
+      <arg type="as" name="removed" direction="out"/>
+    </signal>
+    <signal name="ImagePending">
+      <arg type="s" name="action" direction="out"/>
+      <arg type="as" name="targets" direction="out"/>
+    </signal>
+    <signal name="KernelInstalled">
+      <arg type="s" name="version" direction="out"/>
+      <arg type="b" name="nextBoot" direction="out"/>
+    </signal>
+    <signal name="RepoChanged">
+      <arg type="s" name="action" direction="out"/>
+      <arg type="as" name="targets" direction="out"/>
+    </signal>
   </interface>
 `)
 
@@ -405,6 +405,11 @@ func (g *Generator) generateJSONExample(responseType string) string {
 	return string(jsonBytes)
 }
 
+// ExampleValue создаёт пример значения типа с заполненными полями
+func ExampleValue(typ reflect.Type) interface{} {
+	return (&Generator{}).createExampleStruct(typ)
+}
+
 // createExampleStruct создаёт пример структуры с заполненными значениями
 func (g *Generator) createExampleStruct(typ reflect.Type) interface{} {
 	if typ.Kind() == reflect.Slice {
@@ -448,8 +453,11 @@ func (g *Generator) createExampleStruct(typ reflect.Type) interface{} {
 			slice := reflect.MakeSlice(field.Type, 1, 1)
 			elem := slice.Index(0)
 			if elem.CanSet() {
-				exampleElem := g.createExampleStruct(field.Type.Elem())
-				elem.Set(reflect.ValueOf(exampleElem))
+				exampleElem := reflect.ValueOf(g.createExampleStruct(field.Type.Elem()))
+				// Для срезов интерфейсов пример элемента не строится
+				if exampleElem.IsValid() {
+					elem.Set(exampleElem)
+				}
 			}
 			fieldValue.Set(slice)
 		case reflect.Struct:
@@ -18,6 +18,7 @@ package helper
 
 import (
 	"apm/internal/common/app"
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
@@ -31,6 +32,35 @@ type contextKey string
 
 const TransactionKey contextKey = "transaction"
 
+// QuietKey отключает отправку промежуточных событий задачи, клиент получает только итоговый результат
+const QuietKey contextKey = "quiet"
+
+// WaitLockKey включает ожидание освобождения блокировки операций apm вместо немедленной ошибки
+const WaitLockKey contextKey = "wait-lock"
+
+// WaitLockTimeoutKey ограничивает ожидание блокировки с WaitLockKey (time.Duration), по истечении
+// возвращается ошибка BUSY. Нулевое значение или его отсутствие означает ожидание без ограничения
+const WaitLockTimeoutKey contextKey = "wait-lock-timeout"
+
+// ServiceLockWait ожидание блокировки синхронным запросом сервиса: меньше стандартного
+// таймаута ответа D-Bus (25 секунд), чтобы операция не выполнилась после ухода клиента
+const ServiceLockWait = 20 * time.Second
+
+// AnswerKey заранее заданный ответ на диалоги: AnswerYes или AnswerNo. Диалоги при этом не показываются
+const AnswerKey contextKey = "answer"
+
+// Заранее заданные ответы на диалоги
+const (
+	AnswerYes = "yes"
+	AnswerNo  = "no"
+)
+
+// AnswerFromContext возвращает заранее заданный ответ на диалоги или пустую строку, если нужно спросить пользователя
+func AnswerFromContext(ctx context.Context) string {
+	answer, _ := ctx.Value(AnswerKey).(string)
+	return answer
+}
+
 // GenerateTransactionID генерирует уникальный ID транзакции
 func GenerateTransactionID() string {
 	b := make([]byte, 8)
@@ -76,6 +106,29 @@ func IsRunningInContainer() bool {
 	return false
 }
 
+// BuildEnvVar переопределяет автоопределение среды сборки образа: 1 - включить, 0 - выключить
+const BuildEnvVar = "APM_BUILD_ENV"
+
+// systemdRuntimeDir существует только при запущенном systemd
+var systemdRuntimeDir = "/run/systemd/system"
+
+// IsBuildEnvironment проверка, запущен ли apm внутри контейнера сборки образа: контейнер без systemd,
+// в котором нет DBus и служб, поэтому доступны только прямые операции с пакетами.
+func IsBuildEnvironment() bool {
+	switch os.Getenv(BuildEnvVar) {
+	case "1", "true":
+		return true
+	case "0", "false":
+		return false
+	}
+
+	if !IsRunningInContainer() {
+		return false
+	}
+	_, err := os.Stat(systemdRuntimeDir)
+	return err != nil
+}
+
 // FilterDescription генерирует общее описание фильтра для команд.
 func FilterDescription(examples string, notes ...string) string {
 	result := app.T_("Filtering for the list method:") + "\n\n" +
@@ -133,3 +133,35 @@ func TestIsRunningInContainer_MockFileSystem(t *testing.T) {
 		t.Errorf("IsRunningInContainer() should return a boolean value, got %v", result)
 	}
 }
+
+func TestIsBuildEnvironment(t *testing.T) {
+	originalDir := systemdRuntimeDir
+	defer func() { systemdRuntimeDir = originalDir }()
+
+	t.Run("forced by env", func(t *testing.T) {
+		t.Setenv(BuildEnvVar, "1")
+		if !IsBuildEnvironment() {
+			t.Error("expected build environment when forced")
+		}
+		t.Setenv(BuildEnvVar, "0")
+		t.Setenv("container", "podman")
+		if IsBuildEnvironment() {
+			t.Error("expected no build environment when disabled")
+		}
+	})
+
+	t.Run("container without systemd", func(t *testing.T) {
+		t.Setenv(BuildEnvVar, "")
+		t.Setenv("container", "podman")
+
+		systemdRuntimeDir = filepath.Join(t.TempDir(), "missing")
+		if !IsBuildEnvironment() {
+			t.Error("expected build environment in container without systemd")
+		}
+
+		systemdRuntimeDir = t.TempDir()
+		if IsBuildEnvironment() {
+			t.Error("expected no build environment when systemd is running")
+		}
+	})
+}
@@ -28,6 +28,47 @@ func TestFormatSpeed(t *testing.T) {
 	}
 }
 
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		input uint64
+		want  string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1536, "2 KB"},
+		{1572864, "1.5 MB"},
+		{3221225472, "3.0 GB"},
+	}
+
+	for _, tt := range tests {
+		got := FormatBytes(tt.input)
+		if got != tt.want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestFormatETA(t *testing.T) {
+	tests := []struct {
+		cur, total, speed uint64
+		want              string
+	}{
+		{0, 100, 0, ""},
+		{100, 100, 10, ""},
+		{0, 100, 10, "0:10"},
+		{0, 101, 10, "0:11"},
+		{0, 6500, 1, "1:48:20"},
+		{1000, 91000, 1000, "1:30"},
+	}
+
+	for _, tt := range tests {
+		got := FormatETA(tt.cur, tt.total, tt.speed)
+		if got != tt.want {
+			t.Errorf("FormatETA(%d, %d, %d) = %q, want %q", tt.cur, tt.total, tt.speed, got, tt.want)
+		}
+	}
+}
+
 func TestCompareVersions(t *testing.T) {
 	tests := []struct {
 		a, b string
@@ -47,6 +88,17 @@ func TestCompareVersions(t *testing.T) {
 		{"", "", 0},
 		{"1", "2", -1},
 		{"1.0", "1.0.0.0.0", 0},
+		{"1.10", "1.9", 1},
+		{"1.0a", "1.0", 1},
+		{"001.2", "1.2", 0},
+		{"20240101", "20231231", 1},
+		{"9.0-alt2", "9.0-alt1", 1},
+		{"9.0-alt1", "9.0-alt1.1", -1},
+		{"9.0.1-alt1", "9.0-alt2", 1},
+		{"9.0-alt1", "9.0", 0},
+		{"1:1.0-alt1", "2.0-alt1", 1},
+		{"0:1.0-alt1", "1.0-alt1", 0},
+		{"1.0-alt2:p11+1234.100.1.1@1700000000", "1.0-alt1", 1},
 	}
 
 	for _, tt := range tests {
@@ -64,6 +64,15 @@ func getStartTime(pid uint32) (uint64, error) {
 	return startTime, err
 }
 
+// Действия Polkit системного сервиса. manage подразумевает все остальные действия.
+const (
+	PolkitActionManage     = "org.altlinux.APM.manage"
+	PolkitActionInstall    = "org.altlinux.APM.install"
+	PolkitActionRemove     = "org.altlinux.APM.remove"
+	PolkitActionUpgrade    = "org.altlinux.APM.upgrade"
+	PolkitActionRepoManage = "org.altlinux.APM.repo-manage"
+)
+
 // PolkitCheck выполняет универсальную проверку доступа через Polkit.
 func PolkitCheck(conn *dbus.Conn, sender dbus.Sender, actionID string) error {
 	pid, err := callerPID(conn, sender)
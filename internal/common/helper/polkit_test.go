@@ -0,0 +1,38 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package helper
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPolkitActionsDeclared(t *testing.T) {
+	data, err := os.ReadFile("../../../data/org.altlinux.APM.policy.in")
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy := string(data)
+
+	for _, action := range []string{PolkitActionManage, PolkitActionInstall, PolkitActionRemove, PolkitActionUpgrade, PolkitActionRepoManage} {
+		id := strings.Replace(action, "org.altlinux.APM", "@SERVICE_ID@", 1)
+		if !strings.Contains(policy, `<action id="`+id+`">`) {
+			t.Errorf("action %s is not declared in the polkit policy", action)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package helper
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxSearchVariants ограничивает число вариантов одного поискового запроса
+const maxSearchVariants = 16
+
+// cyrillicToLatin варианты транслитерации кириллицы. Первый вариант основной, остальные
+// покрывают распространённые написания в названиях пакетов (хром → chrom, кальк → calc).
+var cyrillicToLatin = map[rune][]string{
+	'а': {"a"}, 'б': {"b"}, 'в': {"v", "w"}, 'г': {"g"}, 'д': {"d"},
+	'е': {"e"}, 'ё': {"e", "yo"}, 'ж': {"zh", "j"}, 'з': {"z"}, 'и': {"i"},
+	'й': {"y", "i", "j"}, 'к': {"k", "c"}, 'л': {"l"}, 'м': {"m"}, 'н': {"n"},
+	'о': {"o"}, 'п': {"p"}, 'р': {"r"}, 'с': {"s", "c"}, 'т': {"t"},
+	'у': {"u"}, 'ф': {"f", "ph"}, 'х': {"h", "ch", "kh", "x"}, 'ц': {"ts", "c"}, 'ч': {"ch"},
+	'ш': {"sh"}, 'щ': {"sch", "shch"}, 'ъ': {""}, 'ы': {"y"}, 'ь': {""},
+	'э': {"e"}, 'ю': {"yu", "u"}, 'я': {"ya", "ia"},
+}
+
+// SearchVariants нормализует поисковый запрос: приводит к нижнему регистру и для запросов
+// на кириллице добавляет вариант с заглавной первой буквой и варианты транслитерации.
+// Спецсимволы LIKE (%, _) сохраняются, поэтому функция применима и к готовым шаблонам.
+func SearchVariants(query string) []string {
+	folded := strings.ToLower(query)
+	variants := []string{folded}
+	if !hasCyrillic(folded) {
+		return variants
+	}
+
+	add := func(v string) {
+		if len(variants) >= maxSearchVariants {
+			return
+		}
+		for _, existing := range variants {
+			if existing == v {
+				return
+			}
+		}
+		variants = append(variants, v)
+	}
+
+	add(upperFirstLetter(folded))
+	for _, v := range transliterate(folded, maxSearchVariants) {
+		add(v)
+	}
+	return variants
+}
+
+// hasCyrillic проверяет наличие кириллицы в строке
+func hasCyrillic(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Cyrillic, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// upperFirstLetter переводит в верхний регистр первую букву строки, пропуская спецсимволы LIKE
+func upperFirstLetter(s string) string {
+	for i, r := range s {
+		if unicode.IsLetter(r) {
+			return s[:i] + string(unicode.ToUpper(r)) + s[i+utf8.RuneLen(r):]
+		}
+	}
+	return s
+}
+
+// transliterate возвращает не более limit вариантов транслитерации строки в нижнем регистре
+func transliterate(s string, limit int) []string {
+	results := []string{""}
+	for _, r := range s {
+		alternatives, ok := cyrillicToLatin[r]
+		if !ok {
+			for i := range results {
+				results[i] += string(r)
+			}
+			continue
+		}
+
+		next := make([]string, 0, limit)
+		for _, prefix := range results {
+			for _, alt := range alternatives {
+				if len(next) < limit {
+					next = append(next, prefix+alt)
+				}
+			}
+		}
+		results = next
+	}
+	return results
+}
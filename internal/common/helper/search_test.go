@@ -0,0 +1,74 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package helper
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSearchVariants(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		contains []string
+		exact    []string
+	}{
+		{
+			name:  "Latin query is case folded",
+			input: "FireFox",
+			exact: []string{"firefox"},
+		},
+		{
+			name:  "LIKE pattern is kept",
+			input: "%Vim%",
+			exact: []string{"%vim%"},
+		},
+		{
+			name:     "Cyrillic query is transliterated",
+			input:    "хром",
+			contains: []string{"хром", "Хром", "chrom", "hrom"},
+		},
+		{
+			name:     "Mixed case Cyrillic pattern",
+			input:    "%КАЛЬК%",
+			contains: []string{"%кальк%", "%Кальк%", "%calc%", "%kalk%"},
+		},
+		{
+			name:     "Mixed scripts",
+			input:    "gimp-плагин",
+			contains: []string{"gimp-плагин", "gimp-plagin"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SearchVariants(tt.input)
+			if tt.exact != nil && !slices.Equal(got, tt.exact) {
+				t.Errorf("SearchVariants(%q) = %v, want %v", tt.input, got, tt.exact)
+			}
+			for _, want := range tt.contains {
+				if !slices.Contains(got, want) {
+					t.Errorf("SearchVariants(%q) = %v, missing %q", tt.input, got, want)
+				}
+			}
+			if len(got) > maxSearchVariants {
+				t.Errorf("SearchVariants(%q) returned %d variants, limit is %d", tt.input, len(got), maxSearchVariants)
+			}
+		})
+	}
+}
@@ -118,36 +118,168 @@ func FormatSpeed(bytesPerSec uint64) string {
 	}
 }
 
-// CompareVersions сравнивает две версии пакетов
+// FormatBytes форматирует количество байт в человекочитаемый вид.
+func FormatBytes(bytes uint64) string {
+	const (
+		kb = 1024
+		mb = 1024 * 1024
+		gb = 1024 * 1024 * 1024
+	)
+	switch {
+	case bytes >= gb:
+		return fmt.Sprintf("%.1f GB", float64(bytes)/float64(gb))
+	case bytes >= mb:
+		return fmt.Sprintf("%.1f MB", float64(bytes)/float64(mb))
+	case bytes >= kb:
+		return fmt.Sprintf("%.0f KB", float64(bytes)/float64(kb))
+	default:
+		return fmt.Sprintf("%d B", bytes)
+	}
+}
+
+// FormatETA возвращает оставшееся время загрузки в виде m:ss или h:mm:ss.
+// Пустая строка, если скорость неизвестна или загрузка завершена.
+func FormatETA(cur, total, bytesPerSec uint64) string {
+	if bytesPerSec == 0 || cur >= total {
+		return ""
+	}
+	seconds := (total - cur + bytesPerSec - 1) / bytesPerSec
+	h, m, s := seconds/3600, seconds%3600/60, seconds%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
+
+// CompareVersions сравнивает две версии пакетов вида [эпоха:]версия[-релиз]. Релиз учитывается,
+// только если он указан у обеих версий.
 // Возвращает: 1 если a > b, -1 если a < b, 0 если равны
 func CompareVersions(a, b string) int {
-	aParts := strings.Split(a, ".")
-	bParts := strings.Split(b, ".")
+	aEpoch, aVersion, aRelease := SplitEVR(a)
+	bEpoch, bVersion, bRelease := SplitEVR(b)
+
+	if cmp := compareNumeric(aEpoch, bEpoch); cmp != 0 {
+		return cmp
+	}
+	if cmp := compareDotted(aVersion, bVersion); cmp != 0 {
+		return cmp
+	}
+	if aRelease == "" || bRelease == "" {
+		return 0
+	}
+	return compareDotted(aRelease, bRelease)
+}
 
-	maxLen := len(aParts)
-	if len(bParts) > maxLen {
-		maxLen = len(bParts)
+// SplitEVR разбирает версию вида [эпоха:]версия[-релиз] на части. Метка дистрибутива
+// и время сборки после релиза (":p11+..." и "@..." в версиях APT) отбрасываются.
+func SplitEVR(evr string) (epoch, version, release string) {
+	version = evr
+	if idx := strings.Index(version, ":"); idx >= 0 && epochRegex.MatchString(version[:idx]) {
+		epoch, version = version[:idx], version[idx+1:]
+	}
+	if idx := strings.IndexAny(version, ":@"); idx >= 0 {
+		version = version[:idx]
 	}
+	if idx := strings.LastIndex(version, "-"); idx >= 0 {
+		version, release = version[:idx], version[idx+1:]
+	}
+	return epoch, version, release
+}
 
-	for i := 0; i < maxLen; i++ {
-		aVal := 0
-		bVal := 0
+// compareDotted сравнивает версии по частям, разделённым точкой. Недостающие части считаются нулём.
+func compareDotted(a, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
 
+	for i := 0; i < max(len(aParts), len(bParts)); i++ {
+		var aPart, bPart string
 		if i < len(aParts) {
-			aVal, _ = strconv.Atoi(aParts[i])
+			aPart = aParts[i]
 		}
-
 		if i < len(bParts) {
-			bVal, _ = strconv.Atoi(bParts[i])
+			bPart = bParts[i]
 		}
+		if cmp := comparePart(aPart, bPart); cmp != 0 {
+			return cmp
+		}
+	}
 
-		if aVal > bVal {
-			return 1
+	return 0
+}
+
+// comparePart сравнивает часть версии по числовым и буквенным сегментам: числа сравниваются
+// по значению, числовой сегмент новее буквенного
+func comparePart(a, b string) int {
+	for a != "" || b != "" {
+		var segA, segB string
+		segA, a = versionSegment(a)
+		segB, b = versionSegment(b)
+
+		aNumeric := segA == "" || isASCIIDigit(rune(segA[0]))
+		bNumeric := segB == "" || isASCIIDigit(rune(segB[0]))
+
+		var cmp int
+		switch {
+		case aNumeric && bNumeric:
+			cmp = compareNumeric(segA, segB)
+		case segA == "":
+			cmp = -1
+		case segB == "":
+			cmp = 1
+		case aNumeric:
+			cmp = 1
+		case bNumeric:
+			cmp = -1
+		default:
+			cmp = strings.Compare(segA, segB)
 		}
-		if aVal < bVal {
-			return -1
+		if cmp != 0 {
+			return cmp
 		}
 	}
 
 	return 0
 }
+
+// compareNumeric сравнивает числа произвольной длины, пустая строка считается нулём
+func compareNumeric(a, b string) int {
+	a = strings.TrimLeft(a, "0")
+	b = strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		if len(a) > len(b) {
+			return 1
+		}
+		return -1
+	}
+	return strings.Compare(a, b)
+}
+
+// versionSegment отделяет от начала строки числовой или буквенный сегмент версии,
+// пропуская разделители перед ним
+func versionSegment(s string) (string, string) {
+	s = strings.TrimLeftFunc(s, func(r rune) bool {
+		return !isASCIIDigit(r) && !isASCIILetter(r)
+	})
+	if s == "" {
+		return "", ""
+	}
+
+	numeric := isASCIIDigit(rune(s[0]))
+	end := 0
+	for end < len(s) {
+		r := rune(s[end])
+		if (numeric && !isASCIIDigit(r)) || (!numeric && !isASCIILetter(r)) {
+			break
+		}
+		end++
+	}
+	return s[:end], s[end:]
+}
+
+func isASCIIDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isASCIILetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
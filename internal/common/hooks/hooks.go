@@ -0,0 +1,370 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package hooks
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/command"
+	"apm/internal/common/helper"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// События транзакций, на которые подписываются хуки
+const (
+	EventInstall       = "install"
+	EventRemove        = "remove"
+	EventUpgrade       = "upgrade"
+	EventImageApply    = "image-apply"
+	EventKernelInstall = "kernel-install"
+)
+
+// Events все события транзакций в порядке вывода
+var Events = []string{EventInstall, EventRemove, EventUpgrade, EventImageApply, EventKernelInstall}
+
+// Фазы выполнения хука относительно транзакции
+const (
+	PhasePre  = "pre"
+	PhasePost = "post"
+)
+
+// Phases все фазы выполнения хуков
+var Phases = []string{PhasePre, PhasePost}
+
+// Политики обработки ошибки хука
+const (
+	// FailAbort в фазе pre отменяет транзакцию, в фазе post завершает команду ошибкой
+	FailAbort = "abort"
+	// FailWarn пишет предупреждение в лог и продолжает операцию
+	FailWarn = "warn"
+	// FailIgnore продолжает операцию без предупреждения
+	FailIgnore = "ignore"
+)
+
+// Типы хуков
+const (
+	TypeExecutable  = "executable"
+	TypeDeclarative = "declarative"
+)
+
+// Статусы транзакции, передаваемые хукам фазы post
+const (
+	StatusDone   = "done"
+	StatusFailed = "failed"
+)
+
+// DefaultTimeout время выполнения хука, если в описании не задано другое
+const DefaultTimeout = 60 * time.Second
+
+// Hook описывает хук транзакции. Исполняемый файл вызывается для всех событий и обеих фаз с политикой warn,
+// YAML файл задаёт команду, события, фазы, таймаут и политику явно. Имя хука совпадает с именем файла.
+type Hook struct {
+	Name      string   `yaml:"-" json:"name"`
+	Type      string   `yaml:"-" json:"type"`
+	Command   string   `yaml:"command" json:"command"`
+	Events    []string `yaml:"events" json:"events"`
+	Phases    []string `yaml:"phases" json:"phases"`
+	Timeout   string   `yaml:"timeout" json:"timeout"`
+	OnFailure string   `yaml:"onFailure" json:"onFailure"`
+	File      string   `yaml:"-" json:"file"`
+
+	timeout time.Duration
+}
+
+// Matches сообщает, подписан ли хук на событие в указанной фазе.
+func (h Hook) Matches(phase, event string) bool {
+	return slices.Contains(h.Phases, phase) && slices.Contains(h.Events, event)
+}
+
+// normalize подставляет значения по умолчанию и проверяет поля описания хука.
+func (h *Hook) normalize(ctx context.Context) error {
+	if len(h.Events) == 0 {
+		h.Events = slices.Clone(Events)
+	}
+	for _, event := range h.Events {
+		if !slices.Contains(Events, event) {
+			return fmt.Errorf(app.TL_(ctx, "Unknown hook event %s, available: %s"), event, strings.Join(Events, ", "))
+		}
+	}
+
+	if len(h.Phases) == 0 {
+		h.Phases = slices.Clone(Phases)
+	}
+	for _, phase := range h.Phases {
+		if !slices.Contains(Phases, phase) {
+			return fmt.Errorf(app.TL_(ctx, "Unknown hook phase %s, available: %s"), phase, strings.Join(Phases, ", "))
+		}
+	}
+
+	switch h.OnFailure {
+	case "":
+		h.OnFailure = FailWarn
+	case FailAbort, FailWarn, FailIgnore:
+	default:
+		return fmt.Errorf(app.TL_(ctx, "Unknown hook failure policy %s, available: abort, warn, ignore"), h.OnFailure)
+	}
+
+	h.timeout = DefaultTimeout
+	if h.Timeout != "" {
+		timeout, err := time.ParseDuration(h.Timeout)
+		if err != nil || timeout <= 0 {
+			return fmt.Errorf(app.TL_(ctx, "Invalid hook timeout %s"), h.Timeout)
+		}
+		h.timeout = timeout
+	}
+	h.Timeout = h.timeout.String()
+
+	if h.Type == TypeDeclarative && strings.TrimSpace(h.Command) == "" {
+		return errors.New(app.TL_(ctx, "Hook command is not specified"))
+	}
+	return nil
+}
+
+// Transaction описание транзакции, которое хук получает в stdin в формате JSON.
+// Для хуков фазы pre списки пакетов содержат планируемые изменения, для фазы post - выполненные.
+type Transaction struct {
+	Phase       string   `json:"phase"`
+	Event       string   `json:"event"`
+	Transaction string   `json:"transaction,omitempty"`
+	Targets     []string `json:"targets"`
+	Installed   []string `json:"installed"`
+	Upgraded    []string `json:"upgraded"`
+	Removed     []string `json:"removed"`
+	Status      string   `json:"status,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// NewTransaction создаёт описание транзакции события event с идентификатором транзакции из контекста.
+func NewTransaction(ctx context.Context, event string, targets []string) Transaction {
+	id, _ := ctx.Value(helper.TransactionKey).(string)
+	return Transaction{Event: event, Transaction: id, Targets: targets}
+}
+
+// Result результат выполнения хука
+type Result struct {
+	Name     string `json:"name"`
+	Phase    string `json:"phase"`
+	Event    string `json:"event"`
+	Success  bool   `json:"success"`
+	Duration string `json:"duration"`
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Service читает хуки из каталога и выполняет их вокруг транзакций.
+type Service struct {
+	runner command.Runner
+	dir    string
+}
+
+// NewService создаёт сервис хуков для указанного каталога.
+func NewService(runner command.Runner, dir string) *Service {
+	return &Service{runner: runner, dir: dir}
+}
+
+// List возвращает хуки каталога в порядке выполнения - по имени файла.
+// Файлы без права на исполнение, кроме YAML описаний, и скрытые файлы пропускаются.
+func (s *Service) List(ctx context.Context) ([]Hook, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Hook{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to read hooks directory %s: %v"), s.dir, err)
+	}
+
+	hooks := make([]Hook, 0, len(entries))
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") || !entry.Type().IsRegular() {
+			continue
+		}
+
+		path := filepath.Join(s.dir, entry.Name())
+		ext := filepath.Ext(entry.Name())
+		if ext == ".yaml" || ext == ".yml" {
+			hook, err := readHook(ctx, path)
+			if err != nil {
+				return nil, err
+			}
+			hooks = append(hooks, hook)
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil || info.Mode().Perm()&0o111 == 0 {
+			continue
+		}
+		hook := Hook{Name: entry.Name(), Type: TypeExecutable, Command: path, File: path}
+		if err = hook.normalize(ctx); err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, hook)
+	}
+
+	return hooks, nil
+}
+
+// Get возвращает хук по имени.
+func (s *Service) Get(ctx context.Context, name string) (Hook, error) {
+	hooks, err := s.List(ctx)
+	if err != nil {
+		return Hook{}, err
+	}
+	idx := slices.IndexFunc(hooks, func(h Hook) bool { return h.Name == name })
+	if idx == -1 {
+		return Hook{}, fmt.Errorf(app.TL_(ctx, "Hook %s not found"), name)
+	}
+	return hooks[idx], nil
+}
+
+// Run выполняет хуки, подписанные на событие и фазу транзакции. Ошибка возвращается только
+// для хука с политикой abort, остальные хуки после него не запускаются.
+func (s *Service) Run(ctx context.Context, tx Transaction) error {
+	hooks, err := s.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range hooks {
+		if !hook.Matches(tx.Phase, tx.Event) {
+			continue
+		}
+
+		result := s.Exec(ctx, hook, tx)
+		if result.Success {
+			continue
+		}
+
+		switch hook.OnFailure {
+		case FailAbort:
+			return fmt.Errorf(app.TL_(ctx, "Hook %s failed: %s"), hook.Name, result.Error)
+		case FailWarn:
+			app.Log.Warning(fmt.Sprintf("hook %s failed on %s-%s: %s", hook.Name, tx.Phase, tx.Event, result.Error))
+		default:
+			app.Log.Debug(fmt.Sprintf("hook %s failed on %s-%s: %s", hook.Name, tx.Phase, tx.Event, result.Error))
+		}
+	}
+	return nil
+}
+
+// Before выполняет хуки фазы pre. Хук с политикой abort отменяет транзакцию.
+func (s *Service) Before(ctx context.Context, tx Transaction) error {
+	tx.Phase = PhasePre
+	if err := s.Run(ctx, tx); err != nil {
+		return apmerr.New(apmerr.ErrorTypeHook, fmt.Errorf(app.TL_(ctx, "The transaction was cancelled by a hook: %w"), err))
+	}
+	return nil
+}
+
+// After выполняет хуки фазы post с результатом транзакции opErr. Хуки запускаются и после отмены
+// команды, а ошибка хука с политикой abort возвращается, только если сама транзакция прошла успешно.
+func (s *Service) After(ctx context.Context, tx Transaction, opErr error) error {
+	tx.Phase = PhasePost
+	tx.Status = StatusDone
+	if opErr != nil {
+		tx.Status = StatusFailed
+		tx.Error = opErr.Error()
+	}
+
+	err := s.Run(context.WithoutCancel(ctx), tx)
+	if err == nil {
+		return nil
+	}
+	if opErr != nil {
+		app.Log.Warning(err.Error())
+		return nil
+	}
+	return apmerr.New(apmerr.ErrorTypeHook, fmt.Errorf(app.TL_(ctx, "The transaction completed, but a hook failed: %w"), err))
+}
+
+// Exec запускает хук с описанием транзакции в stdin и ограничением по времени.
+func (s *Service) Exec(ctx context.Context, hook Hook, tx Transaction) Result {
+	result := Result{Name: hook.Name, Phase: tx.Phase, Event: tx.Event}
+
+	for _, list := range []*[]string{&tx.Targets, &tx.Installed, &tx.Upgraded, &tx.Removed} {
+		if *list == nil {
+			*list = []string{}
+		}
+	}
+	payload, err := json.Marshal(tx)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	hookCtx, cancel := context.WithTimeout(ctx, hook.timeout)
+	defer cancel()
+
+	opts := []command.Option{
+		command.WithStdin(bytes.NewReader(payload)),
+		command.WithEnv("APM_HOOK_PHASE="+tx.Phase, "APM_HOOK_EVENT="+tx.Event, "APM_HOOK_NAME="+hook.Name),
+		command.WithQuiet(),
+	}
+	if hook.Type == TypeDeclarative {
+		opts = append(opts, command.WithShell())
+	}
+
+	start := time.Now()
+	stdout, stderr, err := s.runner.Run(hookCtx, []string{hook.Command}, opts...)
+	result.Duration = time.Since(start).Round(time.Millisecond).String()
+	result.Stdout = stdout
+	result.Stderr = stderr
+
+	switch {
+	case errors.Is(hookCtx.Err(), context.DeadlineExceeded):
+		result.Error = fmt.Sprintf(app.TL_(ctx, "timed out after %s"), hook.timeout)
+	case err != nil && strings.TrimSpace(stderr) != "":
+		result.Error = fmt.Sprintf("%v: %s", err, strings.TrimSpace(stderr))
+	case err != nil:
+		result.Error = err.Error()
+	default:
+		result.Success = true
+	}
+	return result
+}
+
+// readHook читает и проверяет YAML описание хука.
+func readHook(ctx context.Context, path string) (Hook, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Hook{}, fmt.Errorf(app.TL_(ctx, "Failed to read hook %s: %v"), path, err)
+	}
+
+	var hook Hook
+	if err = yaml.Unmarshal(data, &hook); err != nil {
+		return Hook{}, fmt.Errorf(app.TL_(ctx, "Failed to parse hook %s: %v"), path, err)
+	}
+	hook.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	hook.Type = TypeDeclarative
+	hook.File = path
+	if err = hook.normalize(ctx); err != nil {
+		return Hook{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return hook, nil
+}
@@ -0,0 +1,160 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package hooks
+
+import (
+	"apm/internal/common/command"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func writeHook(t *testing.T, dir, name, content string, mode os.FileMode) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServiceList(t *testing.T) {
+	dir := t.TempDir()
+	writeHook(t, dir, "20-snapshot", "#!/bin/sh\nexit 0\n", 0o755)
+	writeHook(t, dir, "10-notify.yaml", "command: echo hi\nevents: [install]\nphases: [post]\nonFailure: ignore\ntimeout: 5s\n", 0o644)
+	writeHook(t, dir, "README", "not a hook", 0o644)
+	writeHook(t, dir, ".hidden", "#!/bin/sh\n", 0o755)
+
+	hooks, err := NewService(command.NewRunner("", false), dir).List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hooks) != 2 {
+		t.Fatalf("expected 2 hooks, got %+v", hooks)
+	}
+
+	notify, snapshot := hooks[0], hooks[1]
+	if notify.Name != "10-notify" || notify.Type != TypeDeclarative || notify.OnFailure != FailIgnore || notify.Timeout != "5s" {
+		t.Errorf("unexpected declarative hook: %+v", notify)
+	}
+	if !notify.Matches(PhasePost, EventInstall) || notify.Matches(PhasePre, EventInstall) || notify.Matches(PhasePost, EventRemove) {
+		t.Errorf("declarative hook matches wrong events: %+v", notify)
+	}
+	if snapshot.Name != "20-snapshot" || snapshot.Type != TypeExecutable || snapshot.OnFailure != FailWarn {
+		t.Errorf("unexpected executable hook: %+v", snapshot)
+	}
+	if !slices.Equal(snapshot.Events, Events) || !slices.Equal(snapshot.Phases, Phases) {
+		t.Errorf("executable hook must match all events and phases: %+v", snapshot)
+	}
+}
+
+func TestServiceListMissingDir(t *testing.T) {
+	hooks, err := NewService(command.NewRunner("", false), filepath.Join(t.TempDir(), "missing")).List(context.Background())
+	if err != nil || len(hooks) != 0 {
+		t.Fatalf("expected no hooks, got %v, %v", hooks, err)
+	}
+}
+
+func TestServiceListInvalid(t *testing.T) {
+	cases := map[string]string{
+		"event":   "command: true\nevents: [reboot]\n",
+		"phase":   "command: true\nphases: [during]\n",
+		"policy":  "command: true\nonFailure: retry\n",
+		"timeout": "command: true\ntimeout: soon\n",
+		"command": "events: [install]\n",
+	}
+	for name, content := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeHook(t, dir, "bad.yaml", content, 0o644)
+			if _, err := NewService(command.NewRunner("", false), dir).List(context.Background()); err == nil {
+				t.Fatal("expected validation error")
+			}
+		})
+	}
+}
+
+func TestServiceRunPassesTransaction(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(t.TempDir(), "out")
+	writeHook(t, dir, "record", "#!/bin/sh\necho \"$APM_HOOK_PHASE $APM_HOOK_EVENT\" > "+out+"\ncat >> "+out+"\n", 0o755)
+
+	svc := NewService(command.NewRunner("", false), dir)
+	err := svc.Run(context.Background(), Transaction{Phase: PhasePre, Event: EventRemove, Removed: []string{"foo"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "pre remove\n") || !strings.Contains(string(data), `"removed":["foo"]`) {
+		t.Errorf("unexpected hook input: %s", data)
+	}
+}
+
+func TestServiceRunFailurePolicies(t *testing.T) {
+	dir := t.TempDir()
+	writeHook(t, dir, "10-warn.yaml", "command: exit 1\nonFailure: warn\n", 0o644)
+	writeHook(t, dir, "20-abort.yaml", "command: echo denied >&2; exit 1\nevents: [install]\nonFailure: abort\n", 0o644)
+
+	svc := NewService(command.NewRunner("", false), dir)
+	if err := svc.Run(context.Background(), Transaction{Phase: PhasePre, Event: EventRemove}); err != nil {
+		t.Fatalf("warn policy must not fail the transaction: %v", err)
+	}
+
+	err := svc.Run(context.Background(), Transaction{Phase: PhasePre, Event: EventInstall})
+	if err == nil || !strings.Contains(err.Error(), "20-abort") || !strings.Contains(err.Error(), "denied") {
+		t.Fatalf("expected abort error with stderr, got %v", err)
+	}
+}
+
+func TestServiceExecTimeout(t *testing.T) {
+	dir := t.TempDir()
+	writeHook(t, dir, "slow.yaml", "command: sleep 5\ntimeout: 100ms\n", 0o644)
+
+	svc := NewService(command.NewRunner("", false), dir)
+	hook, err := svc.Get(context.Background(), "slow")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := svc.Exec(context.Background(), hook, Transaction{Phase: PhasePost, Event: EventUpgrade})
+	if result.Success || !strings.Contains(result.Error, "100ms") {
+		t.Fatalf("expected timeout, got %+v", result)
+	}
+}
+
+func TestServiceAfterReportsResult(t *testing.T) {
+	dir := t.TempDir()
+	writeHook(t, dir, "check.yaml", "command: grep -q '\"status\":\"done\"'\nphases: [post]\nonFailure: abort\n", 0o644)
+
+	svc := NewService(command.NewRunner("", false), dir)
+	tx := Transaction{Event: EventUpgrade}
+	if err := svc.After(context.Background(), tx, nil); err != nil {
+		t.Fatalf("hook must see a successful transaction: %v", err)
+	}
+	if err := svc.After(context.Background(), tx, errors.New("apt failed")); err != nil {
+		t.Fatalf("hook failure after a failed transaction must not replace its error: %v", err)
+	}
+	if err := svc.Before(context.Background(), tx); err != nil {
+		t.Fatalf("post hook must not run in pre phase: %v", err)
+	}
+}
@@ -0,0 +1,282 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package http_server
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/reply"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/user"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Способы аутентификации запроса
+const (
+	AuthNone  = "none"
+	AuthToken = "token"
+	AuthPeer  = "peer"
+)
+
+// Identity субъект запроса к HTTP API
+type Identity struct {
+	// Name имя токена или пользователя
+	Name string `json:"name"`
+	// Role роль из конфигурации, пустая для владельца сервиса и при отключённой аутентификации
+	Role string `json:"role,omitempty"`
+	// Permission итоговое право: read или manage
+	Permission string `json:"permission"`
+	// Method способ аутентификации: none, token или peer
+	Method string `json:"method"`
+	// UID пользователь, подключившийся через unix-сокет
+	UID *int `json:"uid,omitempty"`
+}
+
+// peerCred учётные данные процесса на другой стороне unix-сокета
+type peerCred struct {
+	uid int
+	gid int
+}
+
+type identityKey struct{}
+type peerCredKey struct{}
+
+// IdentityFromContext возвращает субъект запроса, установленный withAuth
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(Identity)
+	return id, ok
+}
+
+// staticToken токен из --api-token или роли конфигурации
+type staticToken struct {
+	token    string
+	identity Identity
+}
+
+// authenticator сопоставляет запросы с ролями
+type authenticator struct {
+	tokens []staticToken
+	roles  []app.HTTPRole
+	// ownerUID пользователь, от имени которого запущен сервер; ему и root через сокет доступно всё
+	ownerUID int
+	// socketPermission право пользователей сокета без роли: доступ к сокету ограничен правами файла
+	socketPermission string
+}
+
+// newAuthenticator собирает токены из --api-token и ролей конфигурации
+func newAuthenticator(apiToken string, roles []app.HTTPRole, socketPermission string) (*authenticator, error) {
+	if socketPermission != "" && socketPermission != PermRead && socketPermission != PermManage {
+		return nil, fmt.Errorf(app.T_("Unknown unix socket permission '%s': must be '%s' or '%s'"), socketPermission, PermRead, PermManage)
+	}
+	a := &authenticator{ownerUID: os.Getuid(), socketPermission: socketPermission}
+	if apiToken != "" {
+		parsed, err := parseToken(apiToken)
+		if err != nil {
+			return nil, err
+		}
+		a.tokens = append(a.tokens, staticToken{
+			token:    parsed.token,
+			identity: Identity{Name: "api-token", Permission: parsed.permission, Method: AuthToken},
+		})
+	}
+
+	names := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		if role.Name == "" {
+			return nil, errors.New(app.T_("HTTP role without a name in the configuration"))
+		}
+		if names[role.Name] {
+			return nil, fmt.Errorf(app.T_("Duplicate HTTP role %q in the configuration"), role.Name)
+		}
+		names[role.Name] = true
+		if role.Permission != PermRead && role.Permission != PermManage {
+			return nil, fmt.Errorf(app.T_("HTTP role %q has unknown permission '%s': must be '%s' or '%s'"), role.Name, role.Permission, PermRead, PermManage)
+		}
+		for i, token := range role.Tokens {
+			if len(token) < minTokenLength {
+				return nil, fmt.Errorf(app.T_("Token %d of HTTP role %q is too short: minimum %d characters required"), i+1, role.Name, minTokenLength)
+			}
+			a.tokens = append(a.tokens, staticToken{
+				token:    token,
+				identity: Identity{Name: role.Name, Role: role.Name, Permission: role.Permission, Method: AuthToken},
+			})
+		}
+		if len(role.Users) > 0 || len(role.Groups) > 0 {
+			a.roles = append(a.roles, role)
+		}
+	}
+	return a, nil
+}
+
+// enabled сообщает, требуется ли аутентификация для TCP-запросов
+func (a *authenticator) enabled() bool {
+	return len(a.tokens) > 0 || len(a.roles) > 0
+}
+
+// requestToken извлекает токен из заголовка Authorization или параметра token
+func requestToken(r *http.Request) string {
+	if authHeader := r.Header.Get("Authorization"); authHeader != "" {
+		return strings.TrimPrefix(authHeader, "Bearer ")
+	}
+	return r.URL.Query().Get("token")
+}
+
+// lookupToken ищет токен среди статических. Клиенты передают токен как
+// есть или в формате <permission>:<token>, поэтому проверяются оба варианта.
+func (a *authenticator) lookupToken(tokenStr string) (Identity, bool) {
+	candidates := []string{tokenStr}
+	if perm, token, ok := strings.Cut(tokenStr, ":"); ok && (perm == PermRead || perm == PermManage) {
+		candidates = append(candidates, token)
+	}
+
+	var found *Identity
+	for _, candidate := range candidates {
+		for i := range a.tokens {
+			// Сравниваем со всеми токенами, чтобы время ответа не зависело от позиции совпадения
+			if subtle.ConstantTimeCompare([]byte(candidate), []byte(a.tokens[i].token)) == 1 && found == nil {
+				found = &a.tokens[i].identity
+			}
+		}
+	}
+	if found == nil {
+		return Identity{}, false
+	}
+	return *found, true
+}
+
+// lookupPeer определяет роль пользователя, подключившегося через unix-сокет
+func (a *authenticator) lookupPeer(cred peerCred) (Identity, bool) {
+	uid := cred.uid
+	name := strconv.Itoa(uid)
+	var groups []string
+	if u, err := user.LookupId(name); err == nil {
+		name = u.Username
+		groups, _ = u.GroupIds()
+	}
+
+	if uid == 0 || uid == a.ownerUID {
+		return Identity{Name: name, Permission: PermManage, Method: AuthPeer, UID: &uid}, true
+	}
+
+	groupIDs := append([]string{strconv.Itoa(cred.gid)}, groups...)
+	groupNames := make([]string, 0, len(groupIDs))
+	for _, gid := range groupIDs {
+		if g, err := user.LookupGroupId(gid); err == nil {
+			groupNames = append(groupNames, g.Name)
+		}
+	}
+
+	// При совпадении нескольких ролей выбирается дающая больше прав
+	var result Identity
+	matched := false
+	for _, role := range a.roles {
+		member := slices.Contains(role.Users, name) || slices.Contains(role.Users, strconv.Itoa(uid))
+		for _, group := range role.Groups {
+			if slices.Contains(groupIDs, group) || slices.Contains(groupNames, group) {
+				member = true
+			}
+		}
+		if !member || (matched && result.Permission == PermManage) {
+			continue
+		}
+		result = Identity{Name: name, Role: role.Name, Permission: role.Permission, Method: AuthPeer, UID: &uid}
+		matched = true
+	}
+	if !matched && a.socketPermission != "" {
+		return Identity{Name: name, Permission: a.socketPermission, Method: AuthPeer, UID: &uid}, true
+	}
+	return result, matched
+}
+
+// authenticate определяет субъект запроса. Токен имеет приоритет над учётными
+// данными сокета; без настроенной аутентификации TCP-запросы получают полный доступ.
+// При отказе возвращает HTTP-статус и сообщение.
+func (a *authenticator) authenticate(r *http.Request) (Identity, int, string) {
+	if tokenStr := requestToken(r); tokenStr != "" {
+		if id, ok := a.lookupToken(tokenStr); ok {
+			return id, http.StatusOK, ""
+		}
+		return Identity{}, http.StatusUnauthorized, app.T_("Invalid API token")
+	}
+
+	if cred, ok := r.Context().Value(peerCredKey{}).(peerCred); ok {
+		if id, found := a.lookupPeer(cred); found {
+			return id, http.StatusOK, ""
+		}
+		return Identity{}, http.StatusForbidden, fmt.Sprintf(app.T_("No HTTP role is assigned to user with UID %d"), cred.uid)
+	}
+
+	if !a.enabled() {
+		return Identity{Name: "anonymous", Permission: PermManage, Method: AuthNone}, http.StatusOK, ""
+	}
+	return Identity{}, http.StatusUnauthorized, app.T_("Authorization header or token query parameter is required")
+}
+
+// withPeerCred сохраняет в контексте соединения учётные данные процесса, подключившегося через unix-сокет
+func withPeerCred(ctx context.Context, conn net.Conn) context.Context {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return ctx
+	}
+	raw, err := unixConn.SyscallConn()
+	if err != nil {
+		return ctx
+	}
+
+	var ucred *syscall.Ucred
+	var credErr error
+	if err = raw.Control(func(fd uintptr) {
+		ucred, credErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	}); err != nil || credErr != nil {
+		app.Log.Warning(fmt.Sprintf("HTTP: failed to read unix socket peer credentials: %v", errors.Join(err, credErr)))
+		return ctx
+	}
+	return context.WithValue(ctx, peerCredKey{}, peerCred{uid: int(ucred.Uid), gid: int(ucred.Gid)})
+}
+
+// RegisterWhoAmI регистрирует эндпоинт /api/v1/auth/whoami
+func (s *Server) RegisterWhoAmI() {
+	s.RegisterEndpoints([]Endpoint{whoAmIEndpoint()})
+}
+
+// whoAmIEndpoint возвращает эндпоинт с описанием субъекта текущего запроса
+func whoAmIEndpoint() Endpoint {
+	return Endpoint{
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			id, _ := IdentityFromContext(r.Context())
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			_ = json.NewEncoder(w).Encode(reply.OK(id))
+		},
+		HTTPMethod:   http.MethodGet,
+		HTTPPath:     "/api/v1/auth/whoami",
+		ResponseType: reflect.TypeOf(Identity{}),
+		Permission:   PermRead,
+		Summary:      "Текущий субъект запроса",
+		Description:  "Возвращает роль и право токена или пользователя unix-сокета, выполняющего запрос",
+		Tags:         []string{"auth"},
+	}
+}
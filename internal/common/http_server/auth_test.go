@@ -0,0 +1,216 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package http_server
+
+import (
+	"apm/internal/common/app"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func newTestServer(t *testing.T, apiToken string, roles []app.HTTPRole) *Server {
+	t.Helper()
+	s, err := NewServer(Config{APIToken: apiToken, Roles: roles}, nil)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	s.RegisterWhoAmI()
+	s.RegisterEndpoints([]Endpoint{{
+		Handler:    func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) },
+		HTTPMethod: http.MethodPost,
+		HTTPPath:   "/api/v1/manage",
+		Permission: PermManage,
+	}})
+	return s
+}
+
+func whoAmI(t *testing.T, s *Server, r *http.Request) (int, Identity) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.mux.ServeHTTP(rec, r)
+	var resp struct {
+		Data Identity `json:"data"`
+	}
+	if rec.Code == http.StatusOK {
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+	}
+	return rec.Code, resp.Data
+}
+
+func TestNewAuthenticatorValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		roles []app.HTTPRole
+	}{
+		{"no name", []app.HTTPRole{{Permission: PermRead}}},
+		{"unknown permission", []app.HTTPRole{{Name: "ops", Permission: "admin"}}},
+		{"short token", []app.HTTPRole{{Name: "ops", Permission: PermRead, Tokens: []string{"abc"}}}},
+		{"duplicate", []app.HTTPRole{{Name: "ops", Permission: PermRead}, {Name: "ops", Permission: PermManage}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newAuthenticator("", tt.roles, ""); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestAuthDisabled(t *testing.T) {
+	s := newTestServer(t, "", nil)
+
+	code, id := whoAmI(t, s, httptest.NewRequest(http.MethodGet, "/api/v1/auth/whoami", nil))
+	if code != http.StatusOK || id.Method != AuthNone || id.Permission != PermManage {
+		t.Fatalf("got %d %+v", code, id)
+	}
+}
+
+func TestAuthTokens(t *testing.T) {
+	s := newTestServer(t, "manage:cli-secret", []app.HTTPRole{
+		{Name: "monitoring", Permission: PermRead, Tokens: []string{"read-secret"}},
+	})
+
+	tests := []struct {
+		name   string
+		token  string
+		code   int
+		role   string
+		perm   string
+		manage int
+	}{
+		{"missing", "", http.StatusUnauthorized, "", "", http.StatusUnauthorized},
+		{"invalid", "wrong-secret", http.StatusUnauthorized, "", "", http.StatusUnauthorized},
+		{"api token", "cli-secret", http.StatusOK, "", PermManage, http.StatusNoContent},
+		{"api token with permission prefix", "manage:cli-secret", http.StatusOK, "", PermManage, http.StatusNoContent},
+		{"role token", "read-secret", http.StatusOK, "monitoring", PermRead, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/auth/whoami", nil)
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			code, id := whoAmI(t, s, r)
+			if code != tt.code || id.Role != tt.role || id.Permission != tt.perm {
+				t.Fatalf("whoami: got %d %+v", code, id)
+			}
+
+			r = httptest.NewRequest(http.MethodPost, "/api/v1/manage", nil)
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			s.mux.ServeHTTP(rec, r)
+			if rec.Code != tt.manage {
+				t.Fatalf("manage: got %d, want %d", rec.Code, tt.manage)
+			}
+		})
+	}
+}
+
+func TestAuthPeer(t *testing.T) {
+	const otherUID = 4242
+	s := newTestServer(t, "", []app.HTTPRole{
+		{Name: "viewers", Permission: PermRead, Users: []string{strconv.Itoa(otherUID)}},
+	})
+
+	request := func(uid int) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/auth/whoami", nil)
+		return r.WithContext(context.WithValue(r.Context(), peerCredKey{}, peerCred{uid: uid, gid: uid}))
+	}
+
+	code, id := whoAmI(t, s, request(os.Getuid()))
+	if code != http.StatusOK || id.Method != AuthPeer || id.Permission != PermManage || id.UID == nil || *id.UID != os.Getuid() {
+		t.Fatalf("owner: got %d %+v", code, id)
+	}
+
+	code, id = whoAmI(t, s, request(otherUID))
+	if code != http.StatusOK || id.Role != "viewers" || id.Permission != PermRead {
+		t.Fatalf("role: got %d %+v", code, id)
+	}
+
+	if code, _ = whoAmI(t, s, request(otherUID+1)); code != http.StatusForbidden {
+		t.Fatalf("unknown user: got %d", code)
+	}
+
+	// Без токена TCP-запросы отклоняются, если настроены роли
+	if code, _ = whoAmI(t, s, httptest.NewRequest(http.MethodGet, "/api/v1/auth/whoami", nil)); code != http.StatusUnauthorized {
+		t.Fatalf("tcp: got %d", code)
+	}
+}
+
+func TestAuthSocketPermission(t *testing.T) {
+	s, err := NewServer(Config{SocketPermission: PermRead}, nil)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	s.RegisterWhoAmI()
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/auth/whoami", nil)
+	r = r.WithContext(context.WithValue(r.Context(), peerCredKey{}, peerCred{uid: 4242, gid: 4242}))
+	code, id := whoAmI(t, s, r)
+	if code != http.StatusOK || id.Method != AuthPeer || id.Permission != PermRead || id.Role != "" {
+		t.Fatalf("got %d %+v", code, id)
+	}
+
+	if _, err = NewServer(Config{SocketPermission: "admin"}, nil); err == nil {
+		t.Fatal("expected error for unknown socket permission")
+	}
+}
+
+func TestUnixSocketPeerCred(t *testing.T) {
+	s := newTestServer(t, "manage:cli-secret", nil)
+	path := filepath.Join(t.TempDir(), "run", "apm.sock")
+
+	l, err := listenUnix(path, 0, "")
+	if err != nil {
+		t.Fatalf("listenUnix: %v", err)
+	}
+	srv := &http.Server{Handler: s.mux, ConnContext: withPeerCred}
+	go func() { _ = srv.Serve(l) }()
+	defer func() { _ = srv.Close() }()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+	resp, err := client.Get("http://apm/api/v1/auth/whoami")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var body struct {
+		Data Identity `json:"data"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || body.Data.Method != AuthPeer || body.Data.Permission != PermManage {
+		t.Fatalf("got %d %+v", resp.StatusCode, body.Data)
+	}
+}
@@ -46,7 +46,7 @@ func (b *BaseHTTPWrapper) CtxWithTransaction(r *http.Request) context.Context {
 	if tx == "" {
 		tx = r.URL.Query().Get("transaction")
 	}
-	return context.WithValue(b.Ctx, helper.TransactionKey, tx)
+	return withAnswer(context.WithValue(b.Ctx, helper.TransactionKey, tx), r)
 }
 
 // CtxWithTransactionOrGenerate создает контекст с transaction, генерируя его если не передан
@@ -58,7 +58,31 @@ func (b *BaseHTTPWrapper) CtxWithTransactionOrGenerate(r *http.Request) (context
 	if tx == "" {
 		tx = helper.GenerateTransactionID()
 	}
-	return context.WithValue(b.Ctx, helper.TransactionKey, tx), tx
+	return withAnswer(context.WithValue(b.Ctx, helper.TransactionKey, tx), r), tx
+}
+
+// withAnswer переопределяет ответ на диалоги параметром запроса answer=yes|no
+// или заголовком X-APM-Answer. Другие значения игнорируются.
+func withAnswer(ctx context.Context, r *http.Request) context.Context {
+	answer := r.Header.Get("X-APM-Answer")
+	if answer == "" {
+		answer = r.URL.Query().Get("answer")
+	}
+	if answer == helper.AnswerYes || answer == helper.AnswerNo {
+		return context.WithValue(ctx, helper.AnswerKey, answer)
+	}
+	return ctx
+}
+
+// CtxWithRequestCancel создает контекст с transaction, который отменяется при отключении HTTP клиента.
+// Вызывающий обязан вызвать возвращённую функцию отмены.
+func (b *BaseHTTPWrapper) CtxWithRequestCancel(r *http.Request) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(b.CtxWithTransaction(r))
+	stop := context.AfterFunc(r.Context(), cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
 }
 
 // WriteJSON отправляет JSON ответ
@@ -86,6 +110,10 @@ func (b *BaseHTTPWrapper) RunBackground(rw http.ResponseWriter, r *http.Request,
 	}
 
 	ctx, txID := b.CtxWithTransactionOrGenerate(r)
+	if r.URL.Query().Get("quiet") == "true" {
+		ctx = context.WithValue(ctx, helper.QuietKey, true)
+	}
+	ctx = b.Reporter.StartTask(ctx, event)
 	go func() {
 		resp, err := fn(ctx)
 		b.Reporter.SendTaskResult(ctx, event, resp, err)
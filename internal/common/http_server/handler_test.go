@@ -0,0 +1,51 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package http_server
+
+import (
+	"apm/internal/common/helper"
+	"context"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithAnswer(t *testing.T) {
+	base := context.WithValue(context.Background(), helper.AnswerKey, helper.AnswerNo)
+
+	tests := []struct {
+		name   string
+		target string
+		header string
+		want   string
+	}{
+		{"service default", "/api/v1/system/install", "", helper.AnswerNo},
+		{"query", "/api/v1/system/install?answer=yes", "", helper.AnswerYes},
+		{"header wins over query", "/api/v1/system/install?answer=no", "yes", helper.AnswerYes},
+		{"unknown value ignored", "/api/v1/system/install?answer=maybe", "", helper.AnswerNo},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", tt.target, nil)
+			if tt.header != "" {
+				r.Header.Set("X-APM-Answer", tt.header)
+			}
+			if got := helper.AnswerFromContext(withAnswer(base, r)); got != tt.want {
+				t.Errorf("answer = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -269,6 +269,16 @@ func (g *OpenAPIGenerator) createOperation(ep Endpoint) *Operation {
 			Description: qp.Description,
 			Schema:      &Schema{Type: g.mapType(qp.Type)},
 		})
+
+		// Фоновые задачи поддерживают тихий режим, см. BaseHTTPWrapper.RunBackground
+		if qp.Name == "background" {
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:        "quiet",
+				In:          "query",
+				Description: "Не отправлять промежуточные события фоновой задачи, только итоговый результат",
+				Schema:      &Schema{Type: "boolean"},
+			})
+		}
 	}
 
 	// Transaction header
@@ -279,6 +289,16 @@ func (g *OpenAPIGenerator) createOperation(ep Endpoint) *Operation {
 		Schema:      &Schema{Type: "string"},
 	})
 
+	// Изменяющие операции принимают заранее заданный ответ на диалоги, см. withAnswer
+	if ep.HTTPMethod != "GET" {
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:        "answer",
+			In:          "query",
+			Description: "Ответ на диалоги подтверждения без участия пользователя: yes или no",
+			Schema:      &Schema{Type: "string"},
+		})
+	}
+
 	// Request body для POST/PUT/DELETE
 	if ep.HTTPMethod == "POST" || ep.HTTPMethod == "PUT" || ep.HTTPMethod == "DELETE" {
 		// Если есть RequestType - используем его
@@ -22,21 +22,31 @@ import (
 	"apm/internal/common/reply"
 	"bufio"
 	"context"
-	"crypto/subtle"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"net/url"
+	"os"
 	"strings"
 	"time"
 )
 
 // Config конфигурация HTTP сервера
 type Config struct {
-	ListenAddr   string
-	APIToken     string
+	ListenAddr string
+	// SocketPath путь unix-сокета; при пустом ListenAddr сервер слушает только его
+	SocketPath string
+	// SocketMode права доступа к файлу сокета, по умолчанию 0666
+	SocketMode os.FileMode
+	// SocketGroup группа-владелец файла сокета
+	SocketGroup string
+	// SocketPermission право пользователей сокета без роли; пустое — доступ только по ролям
+	SocketPermission string
+	APIToken         string
+	// Roles роли доступа из конфигурации apm
+	Roles        []app.HTTPRole
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 }
@@ -52,13 +62,14 @@ func DefaultConfig() Config {
 
 // Server HTTP сервер APM
 type Server struct {
-	config      Config
-	appConfig   *app.Config
-	mux         *http.ServeMux
-	server      *http.Server
-	listener    net.Listener
-	registry    *Registry
-	parsedToken tokenInfo
+	config     Config
+	appConfig  *app.Config
+	mux        *http.ServeMux
+	server     *http.Server
+	listener   net.Listener
+	registry   *Registry
+	auth       *authenticator
+	unixSocket net.Listener
 }
 
 // tokenInfo информация о токене
@@ -74,13 +85,11 @@ func NewServer(config Config, appConfig *app.Config) (*Server, error) {
 		appConfig: appConfig,
 		mux:       http.NewServeMux(),
 	}
-	if config.APIToken != "" {
-		parsed, err := parseToken(config.APIToken)
-		if err != nil {
-			return nil, err
-		}
-		s.parsedToken = parsed
+	auth, err := newAuthenticator(config.APIToken, config.Roles, config.SocketPermission)
+	if err != nil {
+		return nil, err
 	}
+	s.auth = auth
 	return s, nil
 }
 
@@ -118,37 +127,22 @@ func checkPermission(tokenPerm string, requiredPerm string) bool {
 // withAuth оборачивает handler в per-handler аутентификацию и проверку прав
 func (s *Server) withAuth(perm string, handler http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if s.parsedToken.token == "" {
-			handler(w, r)
-			return
-		}
-
-		tokenStr := ""
-		if authHeader := r.Header.Get("Authorization"); authHeader != "" {
-			tokenStr = authHeader
-			if strings.HasPrefix(authHeader, "Bearer ") {
-				tokenStr = strings.TrimPrefix(authHeader, "Bearer ")
-			}
-		} else if t := r.URL.Query().Get("token"); t != "" {
-			tokenStr = t
-		}
-
-		if tokenStr == "" {
-			writeUnauthorized(w, app.T_("Authorization header or token query parameter is required"))
+		id, status, message := s.auth.authenticate(r)
+		switch status {
+		case http.StatusUnauthorized:
+			writeUnauthorized(w, message)
 			return
-		}
-
-		if subtle.ConstantTimeCompare([]byte(tokenStr), []byte(s.parsedToken.token)) != 1 {
-			writeUnauthorized(w, app.T_("Invalid API token"))
+		case http.StatusForbidden:
+			writeForbidden(w, message)
 			return
 		}
 
-		if !checkPermission(s.parsedToken.permission, perm) {
-			writeForbidden(w, fmt.Sprintf(app.T_("Insufficient permissions. Required: %s, provided: %s"), perm, s.parsedToken.permission))
+		if !checkPermission(id.Permission, perm) {
+			writeForbidden(w, fmt.Sprintf(app.T_("Insufficient permissions. Required: %s, provided: %s"), perm, id.Permission))
 			return
 		}
 
-		handler(w, r)
+		handler(w, r.WithContext(context.WithValue(r.Context(), identityKey{}, id)))
 	}
 }
 
@@ -211,7 +205,7 @@ func (s *Server) corsMiddleware(next http.Handler) http.Handler {
 		if isAllowedOrigin(origin) {
 			w.Header().Set("Access-Control-Allow-Origin", origin)
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Transaction-ID")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Transaction-ID, X-APM-Answer")
 
 			if r.Method == http.MethodOptions {
 				w.WriteHeader(http.StatusOK)
@@ -264,22 +258,56 @@ func (s *Server) Start(ctx context.Context) error {
 		Handler:      handler,
 		ReadTimeout:  s.config.ReadTimeout,
 		WriteTimeout: s.config.WriteTimeout,
+		ConnContext:  withPeerCred,
 	}
 
-	var err error
-
-	s.listener, err = net.Listen("tcp", s.config.ListenAddr)
+	activated, err := activatedListeners()
 	if err != nil {
-		return fmt.Errorf("failed to listen on %s: %w", s.config.ListenAddr, err)
+		return err
 	}
 
-	app.Log.Info("HTTP server listening on http://" + s.config.ListenAddr)
+	if len(activated) == 0 && s.config.ListenAddr == "" && s.config.SocketPath == "" {
+		return errors.New("no TCP address or unix socket to listen on")
+	}
 
-	go func() {
-		if err = s.server.Serve(s.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			app.Log.Errorf("HTTP server error: %v", err)
+	// При активации через сокет-юнит адреса задаёт systemd, собственные сокеты не открываются
+	for _, l := range activated {
+		if l.Addr().Network() == "unix" {
+			s.unixSocket = l
+		} else {
+			s.listener = l
 		}
-	}()
+		app.Log.Info("HTTP server listening on systemd socket " + l.Addr().String())
+	}
+
+	if len(activated) == 0 && s.config.ListenAddr != "" {
+		s.listener, err = net.Listen("tcp", s.config.ListenAddr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", s.config.ListenAddr, err)
+		}
+		app.Log.Info("HTTP server listening on http://" + s.config.ListenAddr)
+	}
+
+	if len(activated) == 0 && s.config.SocketPath != "" {
+		if s.unixSocket, err = listenUnix(s.config.SocketPath, s.config.SocketMode, s.config.SocketGroup); err != nil {
+			if s.listener != nil {
+				_ = s.listener.Close()
+			}
+			return err
+		}
+		app.Log.Info("HTTP server listening on unix:" + s.config.SocketPath)
+	}
+
+	for _, l := range []net.Listener{s.listener, s.unixSocket} {
+		if l == nil {
+			continue
+		}
+		go func(l net.Listener) {
+			if serveErr := s.server.Serve(l); serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+				app.Log.Errorf("HTTP server error: %v", serveErr)
+			}
+		}(l)
+	}
 
 	<-ctx.Done()
 
@@ -333,15 +361,12 @@ func (s *Server) RegisterHealthCheck() {
 // RegisterWebSocket регистрирует WebSocket эндпоинт для событий
 func (s *Server) RegisterWebSocket() {
 	hub := GetWebSocketHub()
-	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	s.mux.HandleFunc("GET /api/v1/events", s.withAuth(PermRead, func(w http.ResponseWriter, r *http.Request) {
 		hub.HandleWebSocket(w, r)
-	})
-	if s.parsedToken.token != "" {
-		s.mux.HandleFunc("GET /api/v1/events", s.withAuth(PermRead, handler))
-	} else {
-		s.mux.HandleFunc("GET /api/v1/events", handler)
+	}))
+	if s.config.ListenAddr != "" {
+		app.Log.Info("WebSocket events endpoint: ws://" + s.config.ListenAddr + "/api/v1/events")
 	}
-	app.Log.Info("WebSocket events endpoint: ws://" + s.config.ListenAddr + "/api/v1/events")
 }
 
 // RegisterAPIInfo регистрирует эндпоинт информации об API
@@ -0,0 +1,117 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package http_server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+)
+
+// listenFDsStart номер первого дескриптора, переданного systemd при активации через сокет
+const listenFDsStart = 3
+
+// defaultSocketMode права файла сокета по умолчанию: подключиться может любой
+// локальный пользователь, а права определяются ролями
+const defaultSocketMode os.FileMode = 0o666
+
+// listenUnix создаёт unix-сокет HTTP API. Доступ к сокету ограничивается
+// правами файла и группой-владельцем.
+func listenUnix(path string, mode os.FileMode, group string) (net.Listener, error) {
+	if mode == 0 {
+		mode = defaultSocketMode
+	}
+	gid := -1
+	if group != "" {
+		g, err := user.LookupGroup(group)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find socket group %s: %w", group, err)
+		}
+		if gid, err = strconv.Atoi(g.Gid); err != nil {
+			return nil, fmt.Errorf("invalid gid %s of group %s", g.Gid, group)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("failed to listen on %s: file exists and is not a socket", path)
+		}
+		_ = os.Remove(path)
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	if gid >= 0 {
+		if err = os.Chown(path, -1, gid); err != nil {
+			_ = l.Close()
+			return nil, fmt.Errorf("failed to set socket group: %w", err)
+		}
+	}
+	if err = os.Chmod(path, mode); err != nil {
+		_ = l.Close()
+		return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+	}
+	return l, nil
+}
+
+// listenFDs возвращает число сокетов, переданных systemd процессу pid, по переменным LISTEN_PID и LISTEN_FDS
+func listenFDs(listenPID, listenFDsEnv string, pid int) int {
+	if listenPID != strconv.Itoa(pid) {
+		return 0
+	}
+	n, err := strconv.Atoi(listenFDsEnv)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// activatedListeners возвращает сокеты, открытые systemd при активации через apm-http.socket.
+// Если сервер запущен не через сокет-юнит, список пуст.
+func activatedListeners() ([]net.Listener, error) {
+	n := listenFDs(os.Getenv("LISTEN_PID"), os.Getenv("LISTEN_FDS"), os.Getpid())
+	if n == 0 {
+		return nil, nil
+	}
+	// Дочерние процессы не должны считать сокеты своими
+	_ = os.Unsetenv("LISTEN_PID")
+	_ = os.Unsetenv("LISTEN_FDS")
+	_ = os.Unsetenv("LISTEN_FDNAMES")
+
+	listeners := make([]net.Listener, 0, n)
+	for fd := listenFDsStart; fd < listenFDsStart+n; fd++ {
+		f := os.NewFile(uintptr(fd), "systemd-socket")
+		l, err := net.FileListener(f)
+		_ = f.Close()
+		if err != nil {
+			for _, opened := range listeners {
+				_ = opened.Close()
+			}
+			return nil, fmt.Errorf("failed to use socket passed by systemd: %w", err)
+		}
+		listeners = append(listeners, l)
+	}
+	return listeners, nil
+}
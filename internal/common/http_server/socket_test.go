@@ -0,0 +1,98 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package http_server
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestListenUnixMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "apm.sock")
+
+	l, err := listenUnix(path, 0o660, "")
+	if err != nil {
+		t.Fatalf("listenUnix: %v", err)
+	}
+	defer func() { _ = l.Close() }()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if info.Mode()&os.ModeSocket == 0 || info.Mode().Perm() != 0o660 {
+		t.Fatalf("unexpected mode %v", info.Mode())
+	}
+}
+
+func TestListenUnixReplacesStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "apm.sock")
+
+	// Сокет, оставшийся после аварийного завершения, не удаляется при закрытии
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	stale.SetUnlinkOnClose(false)
+	_ = stale.Close()
+
+	l, err := listenUnix(path, 0, "")
+	if err != nil {
+		t.Fatalf("listenUnix: %v", err)
+	}
+	defer func() { _ = l.Close() }()
+
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != defaultSocketMode {
+		t.Fatalf("unexpected socket: %v %v", info, err)
+	}
+}
+
+func TestListenUnixRefusesRegularFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "apm.sock")
+	if err := os.WriteFile(path, []byte("data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := listenUnix(path, 0, ""); err == nil {
+		t.Fatal("expected error for a regular file")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "data" {
+		t.Fatal("regular file must not be removed")
+	}
+}
+
+func TestListenFDs(t *testing.T) {
+	tests := []struct {
+		pid, fds string
+		want     int
+	}{
+		{"42", "2", 2},
+		{"42", "", 0},
+		{"42", "-1", 0},
+		{"41", "2", 0},
+		{"", "1", 0},
+	}
+
+	for _, tt := range tests {
+		if got := listenFDs(tt.pid, tt.fds, 42); got != tt.want {
+			t.Errorf("listenFDs(%q, %q) = %d, want %d", tt.pid, tt.fds, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package icon
+
+import (
+	"apm/internal/common/app"
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// catalogCheckInterval как часто проверяется изменение каталогов SWCatalog
+	catalogCheckInterval = 30 * time.Second
+	// maxCachedIcons предел числа масштабированных иконок в памяти
+	maxCachedIcons = 1024
+)
+
+// iconCache кэш масштабированных иконок. Сбрасывается после синхронизации иконок
+// и при изменении каталогов SWCatalog, например после обновления пакетов или данных AppStream.
+type iconCache struct {
+	mu        sync.Mutex
+	items     map[string][]byte
+	stamp     time.Time
+	checkedAt time.Time
+	reloading atomic.Bool
+}
+
+// GetSizedIcon возвращает иконку пакета, масштабированную до size пикселей. Нулевой size
+// возвращает иконку в исходном размере. Если каталоги SWCatalog изменились, кэш сбрасывается
+// и иконки синхронизируются заново в фоне.
+func (s *Service) GetSizedIcon(ctx context.Context, pkgName, container string, size int) ([]byte, error) {
+	s.checkCatalog(ctx)
+
+	key := fmt.Sprintf("%s/%s@%d", container, pkgName, size)
+	s.cache.mu.Lock()
+	data, ok := s.cache.items[key]
+	s.cache.mu.Unlock()
+	if ok {
+		return data, nil
+	}
+
+	data, err := s.GetIcon(pkgName, container)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = scalePNG(data, size); err != nil {
+		return nil, fmt.Errorf(app.TL_(ctx, "Error scaling icon %s: %v"), pkgName, err)
+	}
+
+	s.cache.mu.Lock()
+	if s.cache.items == nil || len(s.cache.items) >= maxCachedIcons {
+		s.cache.items = make(map[string][]byte)
+	}
+	s.cache.items[key] = data
+	s.cache.mu.Unlock()
+
+	return data, nil
+}
+
+// Invalidate сбрасывает кэш масштабированных иконок
+func (s *Service) Invalidate() {
+	s.cache.mu.Lock()
+	s.cache.items = nil
+	s.cache.mu.Unlock()
+}
+
+// checkCatalog сравнивает время изменения каталогов SWCatalog с запомненным. При изменении
+// кэш сбрасывается и запускается фоновая синхронизация иконок.
+func (s *Service) checkCatalog(ctx context.Context) {
+	s.cache.mu.Lock()
+	if time.Since(s.cache.checkedAt) < catalogCheckInterval {
+		s.cache.mu.Unlock()
+		return
+	}
+	s.cache.checkedAt = time.Now()
+	stamp := catalogStamp(s.catalogDir)
+	changed := !s.cache.stamp.IsZero() && !stamp.Equal(s.cache.stamp)
+	s.cache.stamp = stamp
+	s.cache.mu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	s.Invalidate()
+	if !s.cache.reloading.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer s.cache.reloading.Store(false)
+		if _, err := s.ReloadIcons(context.WithoutCancel(ctx)); err != nil {
+			app.Log.Error(err.Error())
+		}
+	}()
+}
+
+// catalogStamp возвращает время последнего изменения каталога и файлов в нём
+func catalogStamp(dir string) time.Time {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return time.Time{}
+	}
+
+	latest := info.ModTime()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return latest
+	}
+	for _, entry := range entries {
+		if fi, errInfo := entry.Info(); errInfo == nil && fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+		}
+	}
+	return latest
+}
@@ -0,0 +1,78 @@
+package icon
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.NRGBA{R: 255, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestScalePNG(t *testing.T) {
+	data, err := scalePNG(testPNG(t, 128, 64), 32)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("scaled icon is not PNG: %v", err)
+	}
+	if img.Bounds().Dx() != 32 || img.Bounds().Dy() != 16 {
+		t.Errorf("expected 32x16, got %v", img.Bounds())
+	}
+	if c := color.NRGBAModel.Convert(img.At(5, 5)).(color.NRGBA); c.R != 255 || c.A != 255 {
+		t.Errorf("unexpected pixel color %v", c)
+	}
+
+	svg := []byte("<svg xmlns=\"http://www.w3.org/2000/svg\"/>")
+	if data, err = scalePNG(svg, 32); err != nil || !bytes.Equal(data, svg) {
+		t.Errorf("expected SVG to be returned unchanged, got %q (%v)", data, err)
+	}
+}
+
+func TestGetSizedIconCache(t *testing.T) {
+	db := newTestDBService(t)
+	compressed, err := compressIcon(testPNG(t, 64, 64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.SaveIconsBatch([]DBIcon{{Package: "firefox", Icon: compressed, Hash: "h1"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Service{dbService: db, catalogDir: t.TempDir()}
+	data, err := s.GetSizedIcon(t.Context(), "firefox", "", 16)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if img, errDecode := png.Decode(bytes.NewReader(data)); errDecode != nil || img.Bounds().Dx() != 16 {
+		t.Fatalf("expected 16px icon, got %v (%v)", img, errDecode)
+	}
+
+	if err = db.DeleteIcons("", []string{"firefox"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.GetSizedIcon(t.Context(), "firefox", "", 16); err != nil {
+		t.Errorf("expected cached icon, got %v", err)
+	}
+
+	s.Invalidate()
+	if _, err = s.GetSizedIcon(t.Context(), "firefox", "", 16); err == nil {
+		t.Error("expected error after cache invalidation")
+	}
+}
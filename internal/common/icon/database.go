@@ -18,6 +18,7 @@ package icon
 
 import (
 	"apm/internal/common/app"
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -34,6 +35,7 @@ type DBIcon struct {
 	Package   string `gorm:"column:package;primaryKey"`
 	Container string `gorm:"column:container;primaryKey;default:''"`
 	Icon      []byte `gorm:"column:icon;not null"`
+	Hash      string `gorm:"column:hash;not null;default:''"`
 }
 
 // TableName задаёт имя таблицы.
@@ -138,26 +140,26 @@ func (s *DBService) IconExists(pkgName, container string) (bool, error) {
 	return count > 0, nil
 }
 
-// GetExistingPackages возвращает множество имён пакетов, уже сохранённых для данного контейнера
-func (s *DBService) GetExistingPackages(container string) (map[string]bool, error) {
+// GetExistingHashes возвращает хэши содержимого иконок, сохранённых для данного контейнера
+func (s *DBService) GetExistingHashes(container string) (map[string]string, error) {
 	db, err := s.db()
 	if err != nil {
 		return nil, err
 	}
 
-	var packages []string
-	err = db.Model(&DBIcon{}).Where("container = ?", container).Pluck("package", &packages).Error
+	var records []DBIcon
+	err = db.Model(&DBIcon{}).Select("package", "hash").Where("container = ?", container).Find(&records).Error
 	if err != nil {
 		return nil, err
 	}
-	result := make(map[string]bool, len(packages))
-	for _, p := range packages {
-		result[p] = true
+	result := make(map[string]string, len(records))
+	for _, r := range records {
+		result[r.Package] = r.Hash
 	}
 	return result, nil
 }
 
-// SaveIconsBatch сохраняет иконки игнорируя конфликты
+// SaveIconsBatch сохраняет иконки, перезаписывая изменившиеся
 func (s *DBService) SaveIconsBatch(icons []DBIcon) error {
 	if len(icons) == 0 {
 		return nil
@@ -166,21 +168,53 @@ func (s *DBService) SaveIconsBatch(icons []DBIcon) error {
 	if err != nil {
 		return err
 	}
-	return db.Transaction(func(tx *gorm.DB) error {
-		batchSize := 500
-		for i := 0; i < len(icons); i += batchSize {
-			end := i + batchSize
-			if end > len(icons) {
-				end = len(icons)
+	return app.RetryBusy(context.Background(), func() error {
+		return db.Transaction(func(tx *gorm.DB) error {
+			batchSize := 500
+			for i := 0; i < len(icons); i += batchSize {
+				end := i + batchSize
+				if end > len(icons) {
+					end = len(icons)
+				}
+				if err := tx.Clauses(clause.OnConflict{
+					Columns:   []clause.Column{{Name: "package"}, {Name: "container"}},
+					DoUpdates: clause.AssignmentColumns([]string{"icon", "hash"}),
+				}).Create(icons[i:end]).Error; err != nil {
+					return err
+				}
 			}
-			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(icons[i:end]).Error; err != nil {
-				return err
-			}
-		}
-		return nil
+			return nil
+		})
 	})
 }
 
+// DeleteIcons удаляет иконки указанных пакетов контейнера
+func (s *DBService) DeleteIcons(container string, packages []string) error {
+	if len(packages) == 0 {
+		return nil
+	}
+	db, err := s.db()
+	if err != nil {
+		return err
+	}
+	return db.Where("container = ? AND package IN ?", container, packages).Delete(&DBIcon{}).Error
+}
+
+// DeleteMissingContainers удаляет иконки контейнеров, которых нет в списке. Системные иконки не затрагиваются.
+func (s *DBService) DeleteMissingContainers(containers []string) (int, error) {
+	db, err := s.db()
+	if err != nil {
+		return 0, err
+	}
+
+	query := db.Where("container <> ''")
+	if len(containers) > 0 {
+		query = query.Where("container NOT IN ?", containers)
+	}
+	result := query.Delete(&DBIcon{})
+	return int(result.RowsAffected), result.Error
+}
+
 // GetStats возвращает количество иконок и общий размер данных
 func (s *DBService) GetStats() (int, int, error) {
 	db, err := s.db()
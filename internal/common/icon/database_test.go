@@ -0,0 +1,99 @@
+package icon
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type memoryDBManager struct {
+	db *sql.DB
+}
+
+func (m *memoryDBManager) GetSystemDB() (*sql.DB, error) { return m.db, nil }
+func (m *memoryDBManager) GetUserDB() (*sql.DB, error)   { return m.db, nil }
+func (m *memoryDBManager) Close() error                  { return m.db.Close() }
+
+func newTestDBService(t *testing.T) *DBService {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+	return NewIconDBService(&memoryDBManager{db: db})
+}
+
+func TestSaveIconsBatchUpdatesChanged(t *testing.T) {
+	s := newTestDBService(t)
+
+	if err := s.SaveIconsBatch([]DBIcon{
+		{Package: "firefox", Container: "", Icon: []byte("v1"), Hash: "h1"},
+		{Package: "gimp", Container: "arch", Icon: []byte("v1"), Hash: "h1"},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.SaveIconsBatch([]DBIcon{{Package: "firefox", Container: "", Icon: []byte("v2"), Hash: "h2"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	hashes, err := s.GetExistingHashes("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(hashes, map[string]string{"firefox": "h2"}) {
+		t.Errorf("unexpected hashes %v", hashes)
+	}
+	data, err := s.GetIcon("firefox", "")
+	if err != nil || string(data) != "v2" {
+		t.Errorf("expected updated icon, got %q (%v)", data, err)
+	}
+}
+
+func TestDeleteOrphanIcons(t *testing.T) {
+	s := newTestDBService(t)
+
+	if err := s.SaveIconsBatch([]DBIcon{
+		{Package: "firefox", Container: "", Icon: []byte("x")},
+		{Package: "gimp", Container: "", Icon: []byte("x")},
+		{Package: "gimp", Container: "arch", Icon: []byte("x")},
+		{Package: "vlc", Container: "gone", Icon: []byte("x")},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := s.DeleteIcons("", []string{"gimp"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	removed, err := s.DeleteMissingContainers([]string{"arch"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("expected 1 icon of removed container, got %d", removed)
+	}
+
+	count, _, err := s.GetStats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 icons left, got %d", count)
+	}
+	if _, err = s.GetIcon("gimp", "arch"); err != nil {
+		t.Errorf("expected container icon to survive: %v", err)
+	}
+}
+
+func TestOrphanPackages(t *testing.T) {
+	existing := map[string]string{"firefox": "a", "gimp": "b", "vlc": "c"}
+	seen := map[string]bool{"firefox": true, "inkscape": true}
+
+	got := orphanPackages(existing, seen)
+	if !reflect.DeepEqual(got, []string{"gimp", "vlc"}) {
+		t.Errorf("unexpected orphans %v", got)
+	}
+}
@@ -0,0 +1,88 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package icon
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// scalePNG масштабирует PNG-иконку так, чтобы большая сторона была равна size, сохраняя пропорции.
+// Данные в другом формате (например, SVG из stock-иконок) возвращаются без изменений.
+func scalePNG(data []byte, size int) ([]byte, error) {
+	if size <= 0 || !bytes.HasPrefix(data, []byte("\x89PNG")) {
+		return data, nil
+	}
+
+	src, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if max(width, height) == size || width == 0 || height == 0 {
+		return data, nil
+	}
+
+	dstWidth, dstHeight := size, size
+	if width > height {
+		dstHeight = max(1, height*size/width)
+	} else if height > width {
+		dstWidth = max(1, width*size/height)
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < dstHeight; y++ {
+		for x := 0; x < dstWidth; x++ {
+			dst.Set(x, y, sample(src, bounds, float64(x)*float64(width)/float64(dstWidth), float64(y)*float64(height)/float64(dstHeight),
+				float64(width)/float64(dstWidth), float64(height)/float64(dstHeight)))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err = png.Encode(&buf, dst); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// sample усредняет пиксели исходного изображения в области stepX×stepY, начиная с точки (fx, fy).
+// При увеличении область меньше пикселя и берётся ближайший пиксель.
+func sample(src image.Image, bounds image.Rectangle, fx, fy, stepX, stepY float64) color.NRGBA {
+	x0, y0 := int(fx), int(fy)
+	x1, y1 := max(x0+1, int(fx+stepX)), max(y0+1, int(fy+stepY))
+	x1, y1 = min(x1, bounds.Dx()), min(y1, bounds.Dy())
+
+	var r, g, b, a, n uint64
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			c := color.NRGBAModel.Convert(src.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+			r += uint64(c.R)
+			g += uint64(c.G)
+			b += uint64(c.B)
+			a += uint64(c.A)
+			n++
+		}
+	}
+	if n == 0 {
+		return color.NRGBA{}
+	}
+	return color.NRGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: uint8(a / n)}
+}
@@ -24,17 +24,25 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"runtime"
+	"sort"
 	"sync"
 )
 
+// catalogDir каталог XML-файлов SWCatalog
+const catalogDir = "/usr/share/swcatalog/xml"
+
 // Service предоставляет сервис для работы с иконками.
 type Service struct {
 	serviceDistroAPI *sandbox.DistroAPIService
 	dbService        *DBService
 	runner           command.Runner
+	catalogDir       string
+	cache            iconCache
 }
 
 // NewIconService создаёт новый сервис для работы с иконками.
@@ -46,6 +54,7 @@ func NewIconService(dbManager app.DatabaseManager, runner command.Runner, report
 		serviceDistroAPI: distroAPISvc,
 		dbService:        iconDB,
 		runner:           runner,
+		catalogDir:       catalogDir,
 	}
 }
 
@@ -55,6 +64,7 @@ type PackageIcon struct {
 	Name      string `json:"name"`
 	Icon      []byte `json:"icon"`
 	Container string `json:"container"`
+	Hash      string `json:"-"`
 }
 
 // GetIcon возвращает распакованную иконку для указанного пакета из базы данных.
@@ -71,11 +81,31 @@ func (s *Service) GetIcon(pkgName, container string) ([]byte, error) {
 	return decompressed, nil
 }
 
-// ReloadIcons загружает и сохраняет иконки из SWCatalog в базу данных.
-func (s *Service) ReloadIcons(ctx context.Context) error {
-	containerList, err := s.serviceDistroAPI.GetContainerList(ctx, true)
-	if err != nil {
-		app.Log.Error(err.Error())
+// SyncSummary итог синхронизации иконок
+type SyncSummary struct {
+	Added     int `json:"added"`
+	Updated   int `json:"updated"`
+	Removed   int `json:"removed"`
+	Unchanged int `json:"unchanged"`
+}
+
+// syncResult изменения иконок одного контейнера относительно базы данных
+type syncResult struct {
+	changed   []PackageIcon
+	added     int
+	updated   int
+	unchanged int
+	orphans   []string
+}
+
+// ReloadIcons синхронизирует иконки из SWCatalog с базой данных: перезаписываются только
+// изменившиеся иконки, иконки исчезнувших пакетов и контейнеров удаляются.
+func (s *Service) ReloadIcons(ctx context.Context) (SyncSummary, error) {
+	var summary SyncSummary
+
+	containerList, errList := s.serviceDistroAPI.GetContainerList(ctx, true)
+	if errList != nil {
+		app.Log.Error(errList.Error())
 		containerList = nil
 	}
 
@@ -83,46 +113,85 @@ func (s *Service) ReloadIcons(ctx context.Context) error {
 	defer runtime.GC()
 
 	// Обработка системных пакетов
-	systemPackages, err := s.getPackages(ctx, "")
-	if err != nil {
-		return err
-	}
-	if err = s.saveNewIcons(systemPackages); err != nil {
-		app.Log.Error(fmt.Sprintf(app.T_("Error saving icon batch: %v"), err))
+	if err := s.syncContainer(ctx, "", &summary); err != nil {
+		return summary, err
 	}
 
 	// Обработка иконок для каждого контейнера
+	containers := make([]string, 0, len(containerList))
 	for _, distroContainer := range containerList {
+		containers = append(containers, distroContainer.ContainerName)
 		if distroContainer.OS == "Arch" {
-			distroPackages, err := s.getPackages(ctx, distroContainer.ContainerName)
-			if err != nil {
+			if err := s.syncContainer(ctx, distroContainer.ContainerName, &summary); err != nil {
 				app.Log.Error(err)
-				continue
-			}
-			if err = s.saveNewIcons(distroPackages); err != nil {
-				app.Log.Error(fmt.Sprintf(app.T_("Error saving icon batch: %v"), err))
 			}
 		}
 	}
 
+	// Удаляем иконки удалённых контейнеров, только если список контейнеров получен
+	if errList == nil {
+		removed, err := s.dbService.DeleteMissingContainers(containers)
+		if err != nil {
+			app.Log.Error(fmt.Sprintf(app.TL_(ctx, "Error removing icons of deleted containers: %v"), err))
+		}
+		summary.Removed += removed
+	}
+
 	// Вывод статистики из БД
 	count, totalSize, err := s.dbService.GetStats()
 	if err != nil {
-		app.Log.Error(app.T_("Error calculating icon statistics: "), err)
+		app.Log.Error(app.TL_(ctx, "Error calculating icon statistics: "), err)
 	} else {
-		app.Log.Debugf(app.T_("Total number of icons in the database: %d, total size: %d bytes"), count, totalSize)
+		app.Log.Debugf(app.TL_(ctx, "Total number of icons in the database: %d, total size: %d bytes"), count, totalSize)
 	}
+	app.Log.Debugf("icon sync: added %d, updated %d, removed %d, unchanged %d",
+		summary.Added, summary.Updated, summary.Removed, summary.Unchanged)
+	s.Invalidate()
+	return summary, nil
+}
+
+// ReloadContainerIcons синхронизирует иконки одного контейнера, например после обновления его пакетов
+func (s *Service) ReloadContainerIcons(ctx context.Context, container string) (SyncSummary, error) {
+	var summary SyncSummary
+	if err := s.syncContainer(ctx, container, &summary); err != nil {
+		return summary, err
+	}
+	s.Invalidate()
+	return summary, nil
+}
+
+// syncContainer применяет изменения иконок одного контейнера и добавляет их в итог
+func (s *Service) syncContainer(ctx context.Context, container string, summary *SyncSummary) error {
+	result, err := s.getPackages(ctx, container)
+	if err != nil {
+		return err
+	}
+
+	if err = s.saveIcons(result.changed); err != nil {
+		app.Log.Error(fmt.Sprintf(app.TL_(ctx, "Error saving icon batch: %v"), err))
+	} else {
+		summary.Added += result.added
+		summary.Updated += result.updated
+	}
+	if err = s.dbService.DeleteIcons(container, result.orphans); err != nil {
+		app.Log.Error(fmt.Sprintf(app.TL_(ctx, "Error removing outdated icons: %v"), err))
+	} else {
+		summary.Removed += len(result.orphans)
+	}
+	summary.Unchanged += result.unchanged
+
 	return nil
 }
 
-// getPackages получает иконки из SWCatalog для указанного контейнера.
-func (s *Service) getPackages(ctx context.Context, container string) ([]PackageIcon, error) {
-	var packageIcons []PackageIcon
-	systemSwCatService := NewSwCatIconService("/usr/share/swcatalog/xml", container, s.runner)
+// getPackages получает иконки из SWCatalog для указанного контейнера и сравнивает их
+// с сохранёнными по хэшу содержимого.
+func (s *Service) getPackages(ctx context.Context, container string) (syncResult, error) {
+	var result syncResult
+	systemSwCatService := NewSwCatIconService(s.catalogDir, container, s.runner)
 
 	packageSwCatIcons, err := systemSwCatService.LoadSWCatalogs(ctx)
 	if err != nil {
-		return nil, err
+		return result, err
 	}
 
 	var cachedBase, stockBase string
@@ -130,7 +199,7 @@ func (s *Service) getPackages(ctx context.Context, container string) ([]PackageI
 	if container != "" {
 		cachedBase, stockBase, cleanup, err = systemSwCatService.prepareTempIconDirs(ctx, "/usr/share/swcatalog/icons", "")
 		if err != nil {
-			return nil, fmt.Errorf(app.T_("Error preparing temporary directories: %v"), err)
+			return result, fmt.Errorf(app.TL_(ctx, "Error preparing temporary directories: %v"), err)
 		}
 		defer cleanup()
 	} else {
@@ -138,10 +207,10 @@ func (s *Service) getPackages(ctx context.Context, container string) ([]PackageI
 		stockBase = "/usr/share/icons/hicolor/128x128/apps"
 	}
 
-	// Загружаем все существующие иконки для контейнера одним запросом
-	existingIcons, err := s.dbService.GetExistingPackages(container)
+	// Загружаем хэши всех существующих иконок контейнера одним запросом
+	existingHashes, err := s.dbService.GetExistingHashes(container)
 	if err != nil {
-		return nil, fmt.Errorf(app.T_("Error checking the existence of the icon in the database: %v"), err)
+		return result, fmt.Errorf(app.TL_(ctx, "Error checking the existence of the icon in the database: %v"), err)
 	}
 
 	var (
@@ -150,45 +219,76 @@ func (s *Service) getPackages(ctx context.Context, container string) ([]PackageI
 		mu  sync.Mutex
 	)
 
+	seen := make(map[string]bool, len(packageSwCatIcons))
 	for _, pkgSwIcon := range packageSwCatIcons {
-		// Если иконка уже сохранена в БД, пропускаем её
-		if existingIcons[pkgSwIcon.PkgName] {
-			continue
-		}
+		seen[pkgSwIcon.PkgName] = true
 
 		wg.Add(1)
 		sem <- struct{}{}
 		go func(pkgSwIcon PackageIconsSwCat) {
 			defer wg.Done()
+			defer func() { <-sem }()
 			rawIcon, errFind := systemSwCatService.getIconFromPackage(pkgSwIcon, cachedBase, stockBase)
 			if errFind != nil {
-				app.Log.Debugf(app.T_("Error retrieving icon: %s"), errFind.Error())
-				<-sem
+				app.Log.Debugf(app.TL_(ctx, "Error retrieving icon: %s"), errFind.Error())
 				return
 			}
+
+			hash := iconHash(rawIcon)
+			oldHash, exists := existingHashes[pkgSwIcon.PkgName]
+			if exists && oldHash == hash {
+				mu.Lock()
+				result.unchanged++
+				mu.Unlock()
+				return
+			}
+
 			compressedIcon, err := compressIcon(rawIcon)
 			if err != nil {
-				app.Log.Error(app.T_("Error compressing the icon: "), err)
-				<-sem
+				app.Log.Error(app.TL_(ctx, "Error compressing the icon: "), err)
 				return
 			}
 			mu.Lock()
-			packageIcons = append(packageIcons, PackageIcon{
+			if exists {
+				result.updated++
+			} else {
+				result.added++
+			}
+			result.changed = append(result.changed, PackageIcon{
 				Name:      pkgSwIcon.PkgName,
 				Icon:      compressedIcon,
 				Container: container,
+				Hash:      hash,
 			})
 			mu.Unlock()
-			<-sem
 		}(pkgSwIcon)
 	}
 	wg.Wait()
 
-	return packageIcons, nil
+	result.orphans = orphanPackages(existingHashes, seen)
+	return result, nil
+}
+
+// orphanPackages возвращает сохранённые пакеты, которых больше нет в каталоге
+func orphanPackages(existing map[string]string, seen map[string]bool) []string {
+	var orphans []string
+	for pkg := range existing {
+		if !seen[pkg] {
+			orphans = append(orphans, pkg)
+		}
+	}
+	sort.Strings(orphans)
+	return orphans
+}
+
+// iconHash возвращает хэш исходного содержимого иконки
+func iconHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
 }
 
-// saveNewIcons сохраняет иконки пакетов "батчем"
-func (s *Service) saveNewIcons(icons []PackageIcon) error {
+// saveIcons сохраняет новые и изменившиеся иконки пакетов "батчем"
+func (s *Service) saveIcons(icons []PackageIcon) error {
 	if len(icons) == 0 {
 		return nil
 	}
@@ -198,6 +298,7 @@ func (s *Service) saveNewIcons(icons []PackageIcon) error {
 			Package:   ic.Name,
 			Container: ic.Container,
 			Icon:      ic.Icon,
+			Hash:      ic.Hash,
 		})
 	}
 	return s.dbService.SaveIconsBatch(dbIcons)
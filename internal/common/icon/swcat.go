@@ -83,7 +83,7 @@ func (s *SwCatIconService) copyDirFromContainer(ctx context.Context, src, dst st
 	// Команда копирования из контейнера.
 	_, stderr, err := s.runner.Run(ctx, []string{"distrobox", "enter", s.containerName, "--", "cp", "-r", src + "/.", dst}, command.WithQuiet())
 	if err != nil {
-		return fmt.Errorf(app.T_("Error copying from container: %v, stderr: %s"), err, stderr)
+		return fmt.Errorf(app.TL_(ctx, "Error copying from container: %v, stderr: %s"), err, stderr)
 	}
 	return nil
 }
@@ -260,7 +260,7 @@ func (s *SwCatIconService) LoadSWCatalogs(ctx context.Context) ([]PackageIconsSw
 	if s.containerName == "" {
 		entries, err := os.ReadDir(s.path)
 		if err != nil {
-			return nil, fmt.Errorf(app.T_("Failed to read directory %s: %w"), s.path, err)
+			return nil, fmt.Errorf(app.TL_(ctx, "Failed to read directory %s: %w"), s.path, err)
 		}
 		for _, entry := range entries {
 			if !entry.IsDir() {
@@ -270,7 +270,7 @@ func (s *SwCatIconService) LoadSWCatalogs(ctx context.Context) ([]PackageIconsSw
 	} else {
 		stdout, stderr, err := s.runner.Run(ctx, []string{"distrobox", "enter", s.containerName, "--", "find", s.path, "-maxdepth", "1", "-type", "f"}, command.WithQuiet())
 		if err != nil {
-			return nil, fmt.Errorf(app.T_("Error retrieving files in %s (container %s): %v, stderr: %s"), s.path, s.containerName, err, stderr)
+			return nil, fmt.Errorf(app.TL_(ctx, "Error retrieving files in %s (container %s): %v, stderr: %s"), s.path, s.containerName, err, stderr)
 		}
 		lines := strings.Split(strings.TrimSpace(stdout), "\n")
 		for _, line := range lines {
@@ -291,25 +291,25 @@ func (s *SwCatIconService) LoadSWCatalogs(ctx context.Context) ([]PackageIconsSw
 		if s.containerName == "" {
 			data, err = os.ReadFile(fullPath)
 			if err != nil {
-				return nil, fmt.Errorf(app.T_("Failed to read file %s: %w"), fullPath, err)
+				return nil, fmt.Errorf(app.TL_(ctx, "Failed to read file %s: %w"), fullPath, err)
 			}
 		} else {
 			stdout, stderr, err := s.runner.Run(ctx, []string{"distrobox", "enter", s.containerName, "--", "cat", fullPath}, command.WithQuiet())
 			if err != nil {
-				return nil, fmt.Errorf(app.T_("Error executing command for file %s: %v, stderr: %s"), fullPath, err, stderr)
+				return nil, fmt.Errorf(app.TL_(ctx, "Error executing command for file %s: %v, stderr: %s"), fullPath, err, stderr)
 			}
 			data = []byte(stdout)
 		}
 		if strings.HasSuffix(fileName, ".gz") {
 			data, err = s.decompressGzip(data)
 			if err != nil {
-				return nil, fmt.Errorf(app.T_("Failed to unpack file %s: %w"), fullPath, err)
+				return nil, fmt.Errorf(app.TL_(ctx, "Failed to unpack file %s: %w"), fullPath, err)
 			}
 		}
 		var catalog SWCatalog
 		err = xml.Unmarshal(data, &catalog)
 		if err != nil {
-			return nil, fmt.Errorf(app.T_("Error parsing XML file %s: %w"), fullPath, err)
+			return nil, fmt.Errorf(app.TL_(ctx, "Error parsing XML file %s: %w"), fullPath, err)
 		}
 		allComponents = append(allComponents, catalog.Components...)
 	}
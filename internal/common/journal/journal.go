@@ -0,0 +1,505 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package journal
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/helper"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	// ActionInstall установка пакетов
+	ActionInstall = "install"
+	// ActionRemove удаление пакетов
+	ActionRemove = "remove"
+	// ActionReinstall переустановка пакетов
+	ActionReinstall = "reinstall"
+	// ActionUpgrade обновление системы
+	ActionUpgrade = "upgrade"
+	// ActionAdd добавление репозиториев
+	ActionAdd = "add"
+	// ActionSet установка ветки репозиториев
+	ActionSet = "set"
+	// ActionRestore восстановление источников из снимка
+	ActionRestore = "restore"
+	// ActionMigrate перевод источников apt-repo под управление apm
+	ActionMigrate = "migrate"
+	// ActionApply применение конфигурации образа
+	ActionApply = "apply"
+	// ActionUpdate обновление базового образа
+	ActionUpdate = "update"
+	// ActionVacuum сжатие и восстановление баз данных apm
+	ActionVacuum = "vacuum"
+	// ActionRelocate перенос данных apm в новое расположение
+	ActionRelocate = "relocate"
+)
+
+const (
+	// ModuleSystem пакетные операции apm system
+	ModuleSystem = "system"
+	// ModuleKernel операции с ядрами и модулями ядра
+	ModuleKernel = "kernel"
+	// ModuleRepository операции с репозиториями
+	ModuleRepository = "repository"
+	// ModuleImage операции с атомарным образом
+	ModuleImage = "image"
+)
+
+const (
+	// StatusDone транзакция завершена
+	StatusDone = "done"
+	// StatusInProgress транзакция начата, но не завершена. Остаётся в журнале, если процесс был прерван
+	StatusInProgress = "in_progress"
+	// StatusRepaired прерванная транзакция восстановлена командой repair
+	StatusRepaired = "repaired"
+	// StatusFailed операция завершилась ошибкой
+	StatusFailed = "failed"
+)
+
+// maxHistoryLimit наибольшее количество записей в одной выборке истории
+const maxHistoryLimit = 1000
+
+// Entry запись журнала транзакций. Previous хранит версии обновлённых и удалённых пакетов
+// до транзакции в формате имя=[эпоха:]версия-релиз.
+type Entry struct {
+	ID          uint      `json:"id"`
+	Date        time.Time `json:"date"`
+	Module      string    `json:"module"`
+	Action      string    `json:"action"`
+	Targets     []string  `json:"targets,omitempty"`
+	Installed   []string  `json:"installed"`
+	Upgraded    []string  `json:"upgraded"`
+	Removed     []string  `json:"removed"`
+	Previous    []string  `json:"previous,omitempty"`
+	Status      string    `json:"status"`
+	Error       string    `json:"error,omitempty"`
+	User        string    `json:"user,omitempty"`
+	Transaction string    `json:"transaction,omitempty"`
+}
+
+// DBEntry описывает модель записи журнала для GORM.
+type DBEntry struct {
+	ID          uint      `gorm:"column:id;primaryKey;autoIncrement"`
+	Date        time.Time `gorm:"column:date;index"`
+	Module      string    `gorm:"column:module;index"`
+	Action      string    `gorm:"column:action"`
+	Targets     string    `gorm:"column:targets"`
+	Installed   string    `gorm:"column:installed"`
+	Upgraded    string    `gorm:"column:upgraded"`
+	Removed     string    `gorm:"column:removed"`
+	Previous    string    `gorm:"column:previous"`
+	Status      string    `gorm:"column:status;index"`
+	Error       string    `gorm:"column:error"`
+	User        string    `gorm:"column:user"`
+	Transaction string    `gorm:"column:transaction_id"`
+}
+
+// HistoryFilter параметры выборки истории операций.
+type HistoryFilter struct {
+	// Module модуль операций, пустое значение — все модули
+	Module string
+	Limit  int
+	Offset int
+}
+
+// TableName задаёт имя таблицы.
+func (DBEntry) TableName() string {
+	return "transaction_journal"
+}
+
+// Service сервис журнала транзакций.
+type Service struct {
+	dbManager app.DatabaseManager
+	realDb    *gorm.DB
+	mu        sync.Mutex
+}
+
+// NewService создаёт новый сервис журнала транзакций.
+func NewService(dbManager app.DatabaseManager) *Service {
+	return &Service{
+		dbManager: dbManager,
+	}
+}
+
+func (s *Service) db() (*gorm.DB, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.realDb == nil {
+		gormLogger := logger.New(
+			log.New(os.Stdout, "\r\n", log.LstdFlags),
+			logger.Config{
+				LogLevel: logger.Silent,
+			},
+		)
+
+		conn, err := s.dbManager.GetSystemDB()
+		if err != nil {
+			return nil, fmt.Errorf(app.T_("failed to get system DB: %w"), err)
+		}
+		s.realDb, err = gorm.Open(sqlite.Dialector{
+			Conn:       conn,
+			DriverName: "sqlite3",
+		}, &gorm.Config{
+			Logger: gormLogger,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if !app.IsReadOnly(conn) {
+			if err = s.realDb.AutoMigrate(&DBEntry{}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return s.realDb, nil
+}
+
+// create сохраняет запись, повторяя попытку, пока база занята другим процессом
+func create(ctx context.Context, db *gorm.DB, model *DBEntry) error {
+	return app.RetryBusy(ctx, func() error {
+		return db.WithContext(ctx).Create(model).Error
+	})
+}
+
+// Record сохраняет запись в журнал. Пустые транзакции не записываются.
+func (s *Service) Record(ctx context.Context, entry Entry) error {
+	if entry.empty() {
+		return nil
+	}
+
+	db, err := s.db()
+	if err != nil {
+		return err
+	}
+
+	entry.fill(ctx)
+	entry.Status = StatusDone
+	model := entry.toDBModel()
+	return create(ctx, db, &model)
+}
+
+// RecordResult сохраняет результат операции: при ошибке запись получает статус failed и текст ошибки
+// и сохраняется даже без изменений пакетов.
+func (s *Service) RecordResult(ctx context.Context, entry Entry, opErr error) error {
+	if opErr == nil {
+		return s.Record(ctx, entry)
+	}
+
+	db, err := s.db()
+	if err != nil {
+		return err
+	}
+
+	entry.fill(ctx)
+	entry.Status = StatusFailed
+	entry.Error = opErr.Error()
+	model := entry.toDBModel()
+	return create(ctx, db, &model)
+}
+
+// Begin отмечает начало транзакции и возвращает идентификатор отметки.
+// Отметка снимается через Discard; если процесс прервали, она остаётся и находится через Interrupted.
+func (s *Service) Begin(ctx context.Context, entry Entry) (uint, error) {
+	db, err := s.db()
+	if err != nil {
+		return 0, err
+	}
+
+	entry.fill(ctx)
+	entry.Status = StatusInProgress
+	model := entry.toDBModel()
+	if err = create(ctx, db, &model); err != nil {
+		return 0, err
+	}
+	return model.ID, nil
+}
+
+// Discard снимает отметку начатой транзакции.
+func (s *Service) Discard(ctx context.Context, id uint) error {
+	db, err := s.db()
+	if err != nil {
+		return err
+	}
+
+	return db.WithContext(ctx).Where("id = ? AND status = ?", id, StatusInProgress).Delete(&DBEntry{}).Error
+}
+
+// Complete переводит начатую транзакцию в выполненную с итоговыми изменениями.
+func (s *Service) Complete(ctx context.Context, id uint, entry Entry) error {
+	db, err := s.db()
+	if err != nil {
+		return err
+	}
+
+	model := entry.toDBModel()
+	return db.WithContext(ctx).Model(&DBEntry{}).Where("id = ? AND status = ?", id, StatusInProgress).Updates(map[string]interface{}{
+		"installed": model.Installed,
+		"upgraded":  model.Upgraded,
+		"removed":   model.Removed,
+		"status":    StatusDone,
+	}).Error
+}
+
+// Fail отмечает начатую транзакцию как завершившуюся ошибкой. Выполненные транзакции не меняются.
+func (s *Service) Fail(ctx context.Context, id uint) error {
+	db, err := s.db()
+	if err != nil {
+		return err
+	}
+
+	return db.WithContext(ctx).Model(&DBEntry{}).Where("id = ? AND status = ?", id, StatusInProgress).Update("status", StatusFailed).Error
+}
+
+// Interrupted возвращает транзакции, которые были начаты, но не завершены, от новых к старым.
+func (s *Service) Interrupted(ctx context.Context) ([]Entry, error) {
+	db, err := s.db()
+	if err != nil {
+		return nil, err
+	}
+
+	var models []DBEntry
+	if err = db.WithContext(ctx).Where("status = ?", StatusInProgress).Order("date DESC, id DESC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(models))
+	for _, m := range models {
+		entries = append(entries, m.fromDBModel())
+	}
+	return entries, nil
+}
+
+// MarkRepaired отмечает все прерванные транзакции как восстановленные.
+func (s *Service) MarkRepaired(ctx context.Context) error {
+	db, err := s.db()
+	if err != nil {
+		return err
+	}
+
+	return db.WithContext(ctx).Model(&DBEntry{}).Where("status = ?", StatusInProgress).Update("status", StatusRepaired).Error
+}
+
+// Since возвращает завершённые записи журнала начиная с указанного момента, от новых к старым.
+func (s *Service) Since(ctx context.Context, since time.Time) ([]Entry, error) {
+	db, err := s.db()
+	if err != nil {
+		return nil, err
+	}
+
+	var models []DBEntry
+	if err = db.WithContext(ctx).Where("date >= ? AND (status IS NULL OR status IN ?)", since, []string{"", StatusDone}).Order("date DESC, id DESC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(models))
+	for _, m := range models {
+		entries = append(entries, m.fromDBModel())
+	}
+	return entries, nil
+}
+
+// Last возвращает последнюю завершённую запись с указанным действием или nil, если таких записей нет.
+func (s *Service) Last(ctx context.Context, action string) (*Entry, error) {
+	db, err := s.db()
+	if err != nil {
+		return nil, err
+	}
+
+	var models []DBEntry
+	if err = db.WithContext(ctx).Where("action = ? AND (status IS NULL OR status IN ?)", action, []string{"", StatusDone}).Order("date DESC, id DESC").Limit(1).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	if len(models) == 0 {
+		return nil, nil
+	}
+
+	entry := models[0].fromDBModel()
+	return &entry, nil
+}
+
+// History возвращает записи журнала всех статусов от новых к старым и общее количество записей по фильтру.
+func (s *Service) History(ctx context.Context, filter HistoryFilter) ([]Entry, int, error) {
+	if filter.Limit <= 0 || filter.Limit > maxHistoryLimit {
+		filter.Limit = maxHistoryLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	db, err := s.db()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := db.WithContext(ctx).Model(&DBEntry{})
+	if filter.Module != "" {
+		query = query.Where("module = ?", filter.Module)
+	}
+
+	var total int64
+	if err = query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var models []DBEntry
+	if err = query.Order("date DESC, id DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&models).Error; err != nil {
+		return nil, 0, err
+	}
+
+	entries := make([]Entry, 0, len(models))
+	for _, m := range models {
+		entries = append(entries, m.fromDBModel())
+	}
+	return entries, int(total), nil
+}
+
+// Get возвращает запись журнала по идентификатору или nil, если записи нет.
+func (s *Service) Get(ctx context.Context, id uint) (*Entry, error) {
+	db, err := s.db()
+	if err != nil {
+		return nil, err
+	}
+
+	var model DBEntry
+	if err = db.WithContext(ctx).First(&model, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	entry := model.fromDBModel()
+	return &entry, nil
+}
+
+// FindByTransaction возвращает последнюю запись журнала с указанным идентификатором транзакции
+// или nil, если такой записи нет.
+func (s *Service) FindByTransaction(ctx context.Context, transaction string) (*Entry, error) {
+	db, err := s.db()
+	if err != nil {
+		return nil, err
+	}
+
+	var models []DBEntry
+	if err = db.WithContext(ctx).Where("transaction_id = ?", transaction).Order("date DESC, id DESC").Limit(1).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	if len(models) == 0 {
+		return nil, nil
+	}
+
+	entry := models[0].fromDBModel()
+	return &entry, nil
+}
+
+// empty сообщает, что запись не содержит изменений
+func (e Entry) empty() bool {
+	return len(e.Installed) == 0 && len(e.Upgraded) == 0 && len(e.Removed) == 0 && len(e.Targets) == 0
+}
+
+// fill заполняет дату, пользователя и транзакцию, если они не заданы
+func (e *Entry) fill(ctx context.Context) {
+	if e.Date.IsZero() {
+		e.Date = time.Now()
+	}
+	if e.User == "" {
+		e.User = currentUser()
+	}
+	if e.Transaction == "" {
+		if tx, ok := ctx.Value(helper.TransactionKey).(string); ok {
+			e.Transaction = tx
+		}
+	}
+}
+
+// currentUser возвращает пользователя, запустившего операцию. Для команд через sudo и pkexec
+// это исходный пользователь, а не root.
+func currentUser() string {
+	if name := os.Getenv("SUDO_USER"); name != "" {
+		return name
+	}
+	if uid := os.Getenv("PKEXEC_UID"); uid != "" {
+		if u, err := user.LookupId(uid); err == nil {
+			return u.Username
+		}
+		return uid
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return strconv.Itoa(os.Getuid())
+}
+
+func (e Entry) toDBModel() DBEntry {
+	return DBEntry{
+		ID:          e.ID,
+		Date:        e.Date,
+		Module:      e.Module,
+		Action:      e.Action,
+		Targets:     strings.Join(e.Targets, ","),
+		Installed:   strings.Join(e.Installed, ","),
+		Upgraded:    strings.Join(e.Upgraded, ","),
+		Removed:     strings.Join(e.Removed, ","),
+		Previous:    strings.Join(e.Previous, ","),
+		Status:      e.Status,
+		Error:       e.Error,
+		User:        e.User,
+		Transaction: e.Transaction,
+	}
+}
+
+func (m DBEntry) fromDBModel() Entry {
+	return Entry{
+		ID:          m.ID,
+		Date:        m.Date,
+		Module:      m.Module,
+		Action:      m.Action,
+		Targets:     splitList(m.Targets),
+		Installed:   splitList(m.Installed),
+		Upgraded:    splitList(m.Upgraded),
+		Removed:     splitList(m.Removed),
+		Previous:    splitList(m.Previous),
+		Status:      m.Status,
+		Error:       m.Error,
+		User:        m.User,
+		Transaction: m.Transaction,
+	}
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, ",")
+}
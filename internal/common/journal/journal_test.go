@@ -0,0 +1,313 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package journal
+
+import (
+	"apm/internal/common/helper"
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type memoryDBManager struct {
+	db *sql.DB
+}
+
+func (m *memoryDBManager) GetSystemDB() (*sql.DB, error) { return m.db, nil }
+func (m *memoryDBManager) GetUserDB() (*sql.DB, error)   { return m.db, nil }
+func (m *memoryDBManager) Close() error                  { return m.db.Close() }
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+	return NewService(&memoryDBManager{db: db})
+}
+
+func TestRecordAndSince(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	entries := []Entry{
+		{Date: now.Add(-10 * 24 * time.Hour), Module: "system", Action: ActionInstall, Installed: []string{"old"}},
+		{Date: now.Add(-2 * time.Hour), Module: "system", Action: ActionInstall, Installed: []string{"vim", "mc"}},
+		{Date: now.Add(-1 * time.Hour), Module: "system", Action: ActionRemove, Removed: []string{"nano"}},
+		{Module: "system", Action: ActionInstall},
+	}
+	for _, e := range entries {
+		if err := s.Record(ctx, e); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	got, err := s.Since(ctx, now.Add(-7*24*time.Hour))
+	if err != nil {
+		t.Fatalf("Since: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 entries, got %d: %+v", len(got), got)
+	}
+	if got[0].Action != ActionRemove || len(got[0].Removed) != 1 || got[0].Removed[0] != "nano" {
+		t.Errorf("unexpected newest entry: %+v", got[0])
+	}
+	if len(got[1].Installed) != 2 || len(got[1].Upgraded) != 0 {
+		t.Errorf("unexpected second entry: %+v", got[1])
+	}
+}
+
+func TestBeginDiscardInterrupted(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+
+	doneID, err := s.Begin(ctx, Entry{Module: "system", Action: ActionInstall, Installed: []string{"vim"}})
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	if err = s.Discard(ctx, doneID); err != nil {
+		t.Fatalf("Discard: %v", err)
+	}
+
+	if _, err = s.Begin(ctx, Entry{Module: "system", Action: ActionUpgrade, Upgraded: []string{"glibc"}}); err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	if err = s.Record(ctx, Entry{Module: "system", Action: ActionRemove, Removed: []string{"nano"}}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	interrupted, err := s.Interrupted(ctx)
+	if err != nil {
+		t.Fatalf("Interrupted: %v", err)
+	}
+	if len(interrupted) != 1 || interrupted[0].Action != ActionUpgrade || interrupted[0].Status != StatusInProgress {
+		t.Fatalf("unexpected interrupted entries: %+v", interrupted)
+	}
+
+	recent, err := s.Since(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("Since: %v", err)
+	}
+	if len(recent) != 1 || recent[0].Action != ActionRemove || recent[0].Status != StatusDone {
+		t.Fatalf("Since should return only completed entries, got %+v", recent)
+	}
+
+	if err = s.MarkRepaired(ctx); err != nil {
+		t.Fatalf("MarkRepaired: %v", err)
+	}
+	if interrupted, _ = s.Interrupted(ctx); len(interrupted) != 0 {
+		t.Errorf("expected no interrupted entries after repair, got %+v", interrupted)
+	}
+}
+
+func TestLast(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	last, err := s.Last(ctx, ActionUpgrade)
+	if err != nil {
+		t.Fatalf("Last: %v", err)
+	}
+	if last != nil {
+		t.Fatalf("expected no entry, got %+v", last)
+	}
+
+	entries := []Entry{
+		{Date: now.Add(-3 * time.Hour), Module: "system", Action: ActionUpgrade, Upgraded: []string{"glibc"}},
+		{Date: now.Add(-2 * time.Hour), Module: "system", Action: ActionUpgrade, Upgraded: []string{"kernel"}},
+		{Date: now.Add(-1 * time.Hour), Module: "system", Action: ActionInstall, Installed: []string{"vim"}},
+	}
+	for _, e := range entries {
+		if err = s.Record(ctx, e); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+	if _, err = s.Begin(ctx, Entry{Module: "system", Action: ActionUpgrade, Upgraded: []string{"mesa"}}); err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+
+	last, err = s.Last(ctx, ActionUpgrade)
+	if err != nil {
+		t.Fatalf("Last: %v", err)
+	}
+	if last == nil || len(last.Upgraded) != 1 || last.Upgraded[0] != "kernel" {
+		t.Fatalf("unexpected last upgrade: %+v", last)
+	}
+}
+
+func TestCompleteAndFail(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+
+	doneID, err := s.Begin(ctx, Entry{Module: ModuleSystem, Action: ActionInstall, Installed: []string{"vim"}})
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	if err = s.Complete(ctx, doneID, Entry{Installed: []string{"vim", "vim-common"}}); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if err = s.Fail(ctx, doneID); err != nil {
+		t.Fatalf("Fail: %v", err)
+	}
+
+	failedID, err := s.Begin(ctx, Entry{Module: ModuleSystem, Action: ActionRemove, Removed: []string{"nano"}})
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	if err = s.Fail(ctx, failedID); err != nil {
+		t.Fatalf("Fail: %v", err)
+	}
+
+	done, err := s.Get(ctx, doneID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if done == nil || done.Status != StatusDone || len(done.Installed) != 2 {
+		t.Errorf("expected completed transaction to stay done, got %+v", done)
+	}
+
+	failed, err := s.Get(ctx, failedID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if failed == nil || failed.Status != StatusFailed {
+		t.Errorf("expected failed transaction, got %+v", failed)
+	}
+
+	if interrupted, _ := s.Interrupted(ctx); len(interrupted) != 0 {
+		t.Errorf("failed transactions must not be reported as interrupted, got %+v", interrupted)
+	}
+
+	missing, err := s.Get(ctx, 100)
+	if err != nil || missing != nil {
+		t.Errorf("expected no entry for unknown id, got %+v, %v", missing, err)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.WithValue(context.Background(), helper.TransactionKey, "tx-1")
+	now := time.Now()
+
+	entries := []Entry{
+		{Date: now.Add(-3 * time.Hour), Module: ModuleSystem, Action: ActionInstall, Installed: []string{"vim"}},
+		{Date: now.Add(-2 * time.Hour), Module: ModuleRepository, Action: ActionAdd, Targets: []string{"rpm http://example.org x86_64 classic"}},
+		{Date: now.Add(-1 * time.Hour), Module: ModuleKernel, Action: ActionInstall, Targets: []string{"6.12.1-un-def-alt1"}, Installed: []string{"kernel-image-un-def"}},
+	}
+	for _, e := range entries {
+		if err := s.Record(ctx, e); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+	if err := s.RecordResult(ctx, Entry{Module: ModuleRepository, Action: ActionSet, Targets: []string{"p11"}}, errors.New("network is unreachable")); err != nil {
+		t.Fatalf("RecordResult: %v", err)
+	}
+	if err := s.RecordResult(ctx, Entry{Module: ModuleRepository, Action: ActionSet}, nil); err != nil {
+		t.Fatalf("RecordResult: %v", err)
+	}
+
+	all, total, err := s.History(ctx, HistoryFilter{})
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if total != 4 || len(all) != 4 {
+		t.Fatalf("expected 4 entries, got %d (total %d): %+v", len(all), total, all)
+	}
+	if all[0].Status != StatusFailed || all[0].Error != "network is unreachable" {
+		t.Errorf("expected failed operation first, got %+v", all[0])
+	}
+	if all[1].Transaction != "tx-1" || all[1].User == "" {
+		t.Errorf("expected transaction and user to be filled, got %+v", all[1])
+	}
+
+	repos, total, err := s.History(ctx, HistoryFilter{Module: ModuleRepository, Limit: 1, Offset: 1})
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if total != 2 || len(repos) != 1 || repos[0].Action != ActionAdd || repos[0].Targets[0] != "rpm http://example.org x86_64 classic" {
+		t.Errorf("unexpected repository page: %+v (total %d)", repos, total)
+	}
+}
+
+func TestPreviousAndFindByTransaction(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.WithValue(context.Background(), helper.TransactionKey, "tx-7")
+
+	id, err := s.Begin(ctx, Entry{Module: ModuleSystem, Action: ActionUpgrade, Upgraded: []string{"bash"}, Previous: []string{"bash=5.1"}})
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	if err = s.Complete(ctx, id, Entry{Upgraded: []string{"bash"}}); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+
+	entry, err := s.FindByTransaction(ctx, "tx-7")
+	if err != nil {
+		t.Fatalf("FindByTransaction: %v", err)
+	}
+	if entry == nil || entry.ID != id || len(entry.Previous) != 1 || entry.Previous[0] != "bash=5.1" {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+
+	missing, err := s.FindByTransaction(ctx, "tx-8")
+	if err != nil {
+		t.Fatalf("FindByTransaction: %v", err)
+	}
+	if missing != nil {
+		t.Errorf("expected no entry, got %+v", missing)
+	}
+}
+
+func TestReadOnlyDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "apm.db")
+	rw, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = rw.Close() })
+	// Схема старой версии без колонки transaction_id: AutoMigrate попытался бы её добавить
+	_, err = rw.Exec("CREATE TABLE `transaction_journal` (`id` integer PRIMARY KEY AUTOINCREMENT,`date` datetime,`module` text,`action` text,`targets` text,`installed` text,`upgraded` text,`removed` text,`previous` text,`status` text,`error` text,`user` text)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = rw.Exec("INSERT INTO transaction_journal (module, action, installed, status) VALUES ('system', 'install', 'vim', 'done')"); err != nil {
+		t.Fatal(err)
+	}
+
+	ro, err := sql.Open("sqlite3", "file:"+path+"?mode=ro&_query_only=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ro.Close() })
+
+	entries, total, err := NewService(&memoryDBManager{db: ro}).History(context.Background(), HistoryFilter{})
+	if err != nil {
+		t.Fatalf("History on read-only database: %v", err)
+	}
+	if total != 1 || len(entries) != 1 {
+		t.Errorf("expected 1 entry, got %d (%d)", len(entries), total)
+	}
+}
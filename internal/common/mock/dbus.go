@@ -0,0 +1,119 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package mock
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/helper"
+	"apm/internal/common/reply"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+var dbusErrorType = reflect.TypeOf((*dbus.Error)(nil))
+
+// MethodTable строит таблицу методов для conn.ExportMethodTable с теми же сигнатурами,
+// что и у DBus-обёртки object. Тип ответа берётся из одноимённого метода поля actions обёртки.
+func MethodTable(ctx context.Context, iface string, object interface{}, responder *Responder) map[string]interface{} {
+	module := iface[strings.LastIndexByte(iface, '.')+1:]
+	actionsType := actionsTypeOf(object)
+
+	value := reflect.ValueOf(object)
+	methods := make(map[string]interface{})
+	for i := 0; i < value.NumMethod(); i++ {
+		name := value.Type().Method(i).Name
+		fnType := value.Method(i).Type()
+		if fnType.NumOut() == 0 || fnType.Out(fnType.NumOut()-1) != dbusErrorType {
+			continue
+		}
+
+		var respType reflect.Type
+		if actionsType != nil {
+			if m, ok := actionsType.MethodByName(name); ok && m.Type.NumOut() >= 2 {
+				respType = m.Type.Out(0)
+			}
+		}
+
+		event := module + "." + name
+		methods[name] = reflect.MakeFunc(fnType, func(args []reflect.Value) []reflect.Value {
+			callCtx := context.WithValue(ctx, helper.TransactionKey, transactionArg(args))
+			resp, err := responder.Run(callCtx, event, respType)
+			return dbusResults(fnType, resp, err)
+		}).Interface()
+	}
+	return methods
+}
+
+// actionsTypeOf возвращает тип поля actions DBus-обёртки
+func actionsTypeOf(object interface{}) reflect.Type {
+	t := reflect.TypeOf(object)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	if field, ok := t.FieldByName("actions"); ok {
+		return field.Type
+	}
+	return nil
+}
+
+// transactionArg находит transaction среди аргументов: это строка перед флагом background,
+// а при его отсутствии последний строковый аргумент
+func transactionArg(args []reflect.Value) string {
+	last := ""
+	for i, arg := range args {
+		if arg.Kind() != reflect.String || arg.Type() == reflect.TypeOf(dbus.Sender("")) {
+			continue
+		}
+		if i+1 < len(args) && args[i+1].Kind() == reflect.Bool {
+			return arg.String()
+		}
+		last = arg.String()
+	}
+	return last
+}
+
+// dbusResults формирует возвращаемые значения метода: JSON ответа в строке и *dbus.Error
+func dbusResults(fnType reflect.Type, resp interface{}, err error) []reflect.Value {
+	results := make([]reflect.Value, fnType.NumOut())
+	for i := range results {
+		results[i] = reflect.Zero(fnType.Out(i))
+	}
+
+	if err != nil {
+		results[len(results)-1] = reflect.ValueOf(apmerr.DBusError(err))
+		return results
+	}
+	for i := 0; i < len(results)-1; i++ {
+		if fnType.Out(i).Kind() != reflect.String {
+			continue
+		}
+		data, jerr := json.Marshal(reply.OK(resp))
+		if jerr != nil {
+			results[len(results)-1] = reflect.ValueOf(dbus.MakeFailedError(jerr))
+			return results
+		}
+		results[i] = reflect.ValueOf(string(data)).Convert(fnType.Out(i))
+	}
+	return results
+}
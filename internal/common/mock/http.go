@@ -0,0 +1,105 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package mock
+
+import (
+	"apm/internal/common/helper"
+	"apm/internal/common/http_server"
+	"apm/internal/common/reply"
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// modulePrefixes приводит имена пакетов модулей к префиксам событий
+var modulePrefixes = map[string]string{
+	"repository": "repo",
+}
+
+// backgroundResponse ответ при запуске имитации в фоне
+type backgroundResponse struct {
+	Message     string `json:"message"`
+	Transaction string `json:"transaction"`
+}
+
+// Endpoints заменяет обработчики endpoints имитацией, сохраняя пути и документацию.
+func Endpoints(ctx context.Context, endpoints []http_server.Endpoint, responder *Responder) []http_server.Endpoint {
+	result := make([]http_server.Endpoint, len(endpoints))
+	for i, ep := range endpoints {
+		ep.Handler = handler(ctx, ep, eventName(ep.Handler), responder)
+		result[i] = ep
+	}
+	return result
+}
+
+// handler имитирует endpoint: синхронно или в фоне при ?background=true
+func handler(ctx context.Context, ep http_server.Endpoint, event string, responder *Responder) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		tx := r.Header.Get("X-Transaction-ID")
+		if tx == "" {
+			tx = r.URL.Query().Get("transaction")
+		}
+
+		if r.URL.Query().Get("background") == "true" {
+			if tx == "" {
+				tx = helper.GenerateTransactionID()
+			}
+			taskCtx := context.WithValue(ctx, helper.TransactionKey, tx)
+			go func() {
+				resp, err := responder.Run(taskCtx, event, ep.ResponseType)
+				responder.reporter.SendTaskResult(taskCtx, event, resp, err)
+			}()
+
+			rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+			rw.WriteHeader(http.StatusAccepted)
+			_ = json.NewEncoder(rw).Encode(reply.OK(backgroundResponse{
+				Message:     "Task started in background",
+				Transaction: tx,
+			}))
+			return
+		}
+
+		resp, err := responder.Run(context.WithValue(r.Context(), helper.TransactionKey, tx), event, ep.ResponseType)
+		if err != nil {
+			reply.WriteHTTPError(rw, err)
+			return
+		}
+		if ep.ContentType != "" {
+			rw.Header().Set("Content-Type", ep.ContentType)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_ = json.NewEncoder(rw).Encode(reply.OK(resp))
+	}
+}
+
+// eventName выводит имя события из обработчика: (*HTTPWrapper).Install пакета system → system.Install
+func eventName(fn http.HandlerFunc) string {
+	name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+	name = strings.TrimSuffix(name, "-fm")
+
+	pkg := name[strings.LastIndexByte(name, '/')+1:]
+	pkg, _, _ = strings.Cut(pkg, ".")
+	if prefix, ok := modulePrefixes[pkg]; ok {
+		pkg = prefix
+	}
+
+	return pkg + "." + name[strings.LastIndexByte(name, '.')+1:]
+}
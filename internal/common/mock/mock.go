@@ -0,0 +1,153 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package mock
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/dbus_doc"
+	"apm/internal/common/reply"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	// EnvMock включает режим разработчика: dbus и http сервисы отвечают заготовленными данными
+	EnvMock = "APM_MOCK"
+	// EnvMockError задаёт методы, возвращающие ошибку: "Install=NOT_FOUND,repo.Add,*"
+	EnvMockError = "APM_MOCK_ERROR"
+	// EnvMockDelay задаёт паузу между шагами прогресса, например "500ms"
+	EnvMockDelay = "APM_MOCK_DELAY"
+
+	defaultDelay     = 200 * time.Millisecond
+	defaultErrorType = apmerr.ErrorTypeApt
+	progressSteps    = 4
+)
+
+// representativeErrors типичные тексты ошибок по их типам
+var representativeErrors = map[string]string{
+	apmerr.ErrorTypeDatabase:    "database is locked",
+	apmerr.ErrorTypeRepository:  "failed to fetch repository index",
+	apmerr.ErrorTypeApt:         "unmet dependencies: example-package depends on libexample",
+	apmerr.ErrorTypeValidation:  "invalid argument",
+	apmerr.ErrorTypePermission:  "elevated rights are required",
+	apmerr.ErrorTypeCanceled:    "operation cancelled",
+	apmerr.ErrorTypeImage:       "failed to pull image",
+	apmerr.ErrorTypeKernel:      "kernel flavour not found",
+	apmerr.ErrorTypeContainer:   "container not found",
+	apmerr.ErrorTypeNoOperation: "nothing to do",
+	apmerr.ErrorTypeNotFound:    "package example-package not found",
+	apmerr.ErrorTypeBusy:        "another operation (install) is in progress by PID 1234",
+}
+
+// Enabled сообщает, включён ли режим имитации бэкенда.
+func Enabled() bool {
+	return os.Getenv(EnvMock) == "1"
+}
+
+// Responder имитирует выполнение методов: рассылает события и возвращает примеры ответов.
+type Responder struct {
+	reporter *reply.Reporter
+	delay    time.Duration
+	failures map[string]string
+}
+
+// NewResponder создаёт имитатор с настройками из переменных окружения.
+func NewResponder(reporter *reply.Reporter) *Responder {
+	r := &Responder{
+		reporter: reporter,
+		delay:    defaultDelay,
+		failures: parseFailures(os.Getenv(EnvMockError)),
+	}
+	if value := os.Getenv(EnvMockDelay); value != "" {
+		if delay, err := time.ParseDuration(value); err == nil && delay >= 0 {
+			r.delay = delay
+		} else {
+			app.Log.Warn(fmt.Sprintf("invalid %s value %q, using %s", EnvMockDelay, value, defaultDelay))
+		}
+	}
+	return r
+}
+
+// Run имитирует метод event ("system.Install"): отправляет события начала, прогресса и завершения,
+// затем возвращает пример ответа типа respType или ошибку, заданную в APM_MOCK_ERROR.
+func (r *Responder) Run(ctx context.Context, event string, respType reflect.Type) (interface{}, error) {
+	r.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(event))
+	for step := 1; step <= progressSteps; step++ {
+		select {
+		case <-ctx.Done():
+			return nil, apmerr.New(apmerr.ErrorTypeCanceled, ctx.Err())
+		case <-time.After(r.delay):
+		}
+		r.reporter.CreateEventNotification(ctx, reply.StateBefore,
+			reply.WithEventName(event),
+			reply.WithProgress(true),
+			reply.WithProgressPercent(float64(step*100/progressSteps)))
+	}
+	r.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(event))
+
+	if err := r.failure(event); err != nil {
+		return nil, err
+	}
+	return example(respType), nil
+}
+
+// failure возвращает внедрённую ошибку для метода event
+func (r *Responder) failure(event string) error {
+	method := event[strings.LastIndexByte(event, '.')+1:]
+	for _, key := range []string{event, method, "*"} {
+		if errType, ok := r.failures[strings.ToLower(key)]; ok {
+			return apmerr.New(errType, errors.New(representativeErrors[errType]))
+		}
+	}
+	return nil
+}
+
+// parseFailures разбирает значение APM_MOCK_ERROR в соответствие метод → тип ошибки
+func parseFailures(value string) map[string]string {
+	failures := make(map[string]string)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		name, errType, found := strings.Cut(item, "=")
+		errType = strings.ToUpper(strings.TrimSpace(errType))
+		if _, known := representativeErrors[errType]; !found || !known {
+			errType = defaultErrorType
+		}
+		failures[strings.ToLower(strings.TrimSpace(name))] = errType
+	}
+	return failures
+}
+
+// example строит пример ответа по типу; без типа возвращается только сообщение
+func example(respType reflect.Type) interface{} {
+	for respType != nil && respType.Kind() == reflect.Ptr {
+		respType = respType.Elem()
+	}
+	if respType == nil || respType.Kind() != reflect.Struct {
+		return map[string]interface{}{"message": "Mock response"}
+	}
+	return dbus_doc.ExampleValue(respType)
+}
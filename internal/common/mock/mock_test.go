@@ -0,0 +1,183 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package mock
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/helper"
+	"apm/internal/common/http_server"
+	"apm/internal/common/reply"
+	"apm/internal/common/testutil"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+type fakeResponse struct {
+	Message  string   `json:"message"`
+	Packages []string `json:"packages"`
+	Count    int      `json:"count"`
+}
+
+type fakeActions struct{}
+
+func (a *fakeActions) Install(_ context.Context, _ []string) (*fakeResponse, error) {
+	return nil, nil
+}
+
+type fakeWrapper struct {
+	actions *fakeActions
+}
+
+func (w *fakeWrapper) Install(_ dbus.Sender, _ []string, _ string, _ bool) (string, *dbus.Error) {
+	return "", nil
+}
+
+func (w *fakeWrapper) Icon(_ string) ([]byte, *dbus.Error) {
+	return nil, nil
+}
+
+func (w *fakeWrapper) Status(rw http.ResponseWriter, _ *http.Request) {
+	rw.WriteHeader(http.StatusOK)
+}
+
+func newTestResponder(t *testing.T, failures string) *Responder {
+	t.Setenv(EnvMockDelay, "0s")
+	t.Setenv(EnvMockError, failures)
+	appConfig := &app.Config{
+		ConfigManager: &testutil.MockConfigManager{Config: &app.Configuration{Format: app.FormatHTTP}},
+		DBusManager:   app.NewDBusManager(),
+	}
+	return NewResponder(reply.NewReporter(appConfig))
+}
+
+func TestParseFailures(t *testing.T) {
+	failures := parseFailures("Install=not_found, repo.Add ,Remove=UNKNOWN,")
+	expected := map[string]string{
+		"install":  apmerr.ErrorTypeNotFound,
+		"repo.add": defaultErrorType,
+		"remove":   defaultErrorType,
+	}
+	if !reflect.DeepEqual(failures, expected) {
+		t.Errorf("expected %v, got %v", expected, failures)
+	}
+}
+
+func TestResponderRun(t *testing.T) {
+	responder := newTestResponder(t, "system.Remove=PERMISSION")
+	ctx := context.WithValue(context.Background(), helper.TransactionKey, "tx-1")
+
+	resp, err := responder.Run(ctx, "system.Install", reflect.TypeOf(&fakeResponse{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	example, ok := resp.(fakeResponse)
+	if !ok || example.Message == "" || len(example.Packages) != 1 {
+		t.Errorf("expected filled example response, got %#v", resp)
+	}
+
+	_, err = responder.Run(ctx, "system.Remove", nil)
+	var apmErr apmerr.APMError
+	if !errors.As(err, &apmErr) || apmErr.Type != apmerr.ErrorTypePermission {
+		t.Errorf("expected injected permission error, got %v", err)
+	}
+
+	_, err = responder.Run(ctx, "repo.Remove", nil)
+	if err != nil {
+		t.Errorf("expected failure to match only system.Remove, got %v", err)
+	}
+}
+
+func TestMethodTable(t *testing.T) {
+	responder := newTestResponder(t, "Icon")
+	methods := MethodTable(context.Background(), "org.altlinux.APM.system", &fakeWrapper{}, responder)
+
+	if _, ok := methods["Status"]; ok {
+		t.Errorf("expected method without *dbus.Error result to be skipped")
+	}
+
+	install, ok := methods["Install"].(func(dbus.Sender, []string, string, bool) (string, *dbus.Error))
+	if !ok {
+		t.Fatalf("expected Install with wrapper signature, got %T", methods["Install"])
+	}
+	data, dbusErr := install(":1.1", []string{"zip"}, "tx-2", false)
+	if dbusErr != nil {
+		t.Fatalf("unexpected error: %v", dbusErr)
+	}
+	var resp struct {
+		Data fakeResponse `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(data), &resp); err != nil || resp.Data.Count != 42 {
+		t.Errorf("expected example JSON response, got %q: %v", data, err)
+	}
+
+	icon := methods["Icon"].(func(string) ([]byte, *dbus.Error))
+	if _, dbusErr = icon("zip"); dbusErr == nil {
+		t.Errorf("expected injected error for Icon")
+	}
+}
+
+func TestTransactionArg(t *testing.T) {
+	args := func(values ...interface{}) []reflect.Value {
+		result := make([]reflect.Value, len(values))
+		for i, v := range values {
+			result[i] = reflect.ValueOf(v)
+		}
+		return result
+	}
+
+	if tx := transactionArg(args(dbus.Sender(":1.1"), "tx", true, false, "config")); tx != "tx" {
+		t.Errorf("expected transaction before background flag, got %q", tx)
+	}
+	if tx := transactionArg(args("branch", "tx")); tx != "tx" {
+		t.Errorf("expected last string argument, got %q", tx)
+	}
+}
+
+func TestEndpointsHandler(t *testing.T) {
+	responder := newTestResponder(t, "")
+	wrapper := &fakeWrapper{}
+	endpoints := Endpoints(context.Background(), []http_server.Endpoint{{
+		Handler:      wrapper.Status,
+		HTTPMethod:   http.MethodGet,
+		HTTPPath:     "/api/v1/status",
+		ResponseType: reflect.TypeOf(fakeResponse{}),
+	}}, responder)
+
+	rec := httptest.NewRecorder()
+	endpoints[0].Handler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/status", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var resp struct {
+		Data fakeResponse `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Data.Message == "" {
+		t.Errorf("expected example JSON response, got %q: %v", rec.Body.String(), err)
+	}
+
+	if name := eventName(wrapper.Status); name != "mock.Status" {
+		t.Errorf("expected event name mock.Status, got %q", name)
+	}
+}
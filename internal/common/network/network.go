@@ -0,0 +1,211 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package network
+
+import (
+	"apm/internal/common/app"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	current   app.NetworkSettings
+	currentMu sync.RWMutex
+)
+
+// Configure задаёт сетевые настройки, которые применяются HTTP-клиентами apm и биндингом APT.
+func Configure(settings app.NetworkSettings) {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	current = settings
+}
+
+// Current возвращает текущие сетевые настройки.
+func Current() app.NetworkSettings {
+	currentMu.RLock()
+	defer currentMu.RUnlock()
+	return current
+}
+
+// ParseLimit разбирает ограничение скорости вида 512K, 2M, 1G или число байт.
+// Пустая строка и 0 означают отсутствие ограничения.
+func ParseLimit(value string) (uint64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	multiplier := uint64(1)
+	number := value
+	switch strings.ToUpper(value[len(value)-1:]) {
+	case "K":
+		multiplier = 1024
+	case "M":
+		multiplier = 1024 * 1024
+	case "G":
+		multiplier = 1024 * 1024 * 1024
+	}
+	if multiplier > 1 {
+		number = value[:len(value)-1]
+	}
+
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n < 0 || math.IsInf(n, 0) || math.IsNaN(n) {
+		return 0, fmt.Errorf(app.T_("Invalid bandwidth limit %q, expected a number with an optional K, M or G suffix"), value)
+	}
+	return uint64(n * float64(multiplier)), nil
+}
+
+// ValidateProxy проверяет адрес прокси; допустимые схемы: http, https, socks5 и socks5h.
+func ValidateProxy(value string) error {
+	if value == "" {
+		return nil
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf(app.T_("Invalid proxy address %q"), value)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+		return nil
+	default:
+		return fmt.Errorf(app.T_("Unsupported proxy scheme %q, expected http, https, socks5 or socks5h"), u.Scheme)
+	}
+}
+
+// ValidateHTTPProxy проверяет прокси для http-запросов: метод http в APT не поддерживает SOCKS,
+// поэтому допустимы только схемы http и https.
+func ValidateHTTPProxy(value string) error {
+	if err := ValidateProxy(value); err != nil {
+		return err
+	}
+	if isSocks(value) {
+		return fmt.Errorf(app.T_("SOCKS proxy %q is not supported for http sources, use an http or https proxy"), value)
+	}
+	return nil
+}
+
+// isSocks сообщает, задаёт ли адрес SOCKS-прокси.
+func isSocks(proxy string) bool {
+	u, err := url.Parse(proxy)
+	return err == nil && (u.Scheme == "socks5" || u.Scheme == "socks5h")
+}
+
+// proxyFor возвращает прокси для схемы запроса: отдельный для http/https, затем SOCKS.
+func proxyFor(s app.NetworkSettings, scheme string) string {
+	proxy := s.HTTPProxy
+	if scheme == "https" {
+		proxy = s.HTTPSProxy
+	}
+	if proxy == "" {
+		proxy = s.SocksProxy
+	}
+	return proxy
+}
+
+// AptOptions возвращает параметры конфигурации APT для прокси и ограничения скорости.
+// SOCKS-прокси поддерживает только метод https (libcurl), поэтому для http он не применяется:
+// http-источникам нужен отдельный httpProxy.
+func AptOptions(s app.NetworkSettings) map[string]string {
+	options := make(map[string]string)
+	if s.HTTPProxy != "" && !isSocks(s.HTTPProxy) {
+		options["Acquire::http::Proxy"] = s.HTTPProxy
+	}
+	if proxy := proxyFor(s, "https"); proxy != "" {
+		options["Acquire::https::Proxy"] = proxy
+	}
+
+	// Dl-Limit задаётся в килобайтах в секунду
+	if limit, err := ParseLimit(s.Limit); err == nil && limit > 0 {
+		kb := strconv.FormatUint(max((limit+1023)/1024, 1), 10)
+		options["Acquire::http::Dl-Limit"] = kb
+		options["Acquire::https::Dl-Limit"] = kb
+	}
+	return options
+}
+
+// NewHTTPClient создаёт HTTP-клиент с прокси и ограничением скорости из текущих настроек.
+// Без настроенного прокси используются переменные окружения http_proxy и https_proxy.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	settings := Current()
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		if proxy := proxyFor(settings, req.URL.Scheme); proxy != "" {
+			return url.Parse(proxy)
+		}
+		return http.ProxyFromEnvironment(req)
+	}
+
+	var rt http.RoundTripper = transport
+	if limit, err := ParseLimit(settings.Limit); err == nil && limit > 0 {
+		rt = &limitedTransport{base: transport, limit: limit}
+	}
+
+	return &http.Client{Timeout: timeout, Transport: rt}
+}
+
+// limitedTransport ограничивает скорость чтения тела ответа.
+type limitedTransport struct {
+	base  http.RoundTripper
+	limit uint64
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = newLimitedReader(resp.Body, t.limit)
+	return resp, nil
+}
+
+// limitedReader замедляет чтение так, чтобы средняя скорость не превышала limit байт в секунду.
+type limitedReader struct {
+	io.ReadCloser
+	limit uint64
+	read  uint64
+	start time.Time
+	sleep func(time.Duration)
+}
+
+func newLimitedReader(r io.ReadCloser, limit uint64) *limitedReader {
+	return &limitedReader{ReadCloser: r, limit: limit, start: time.Now(), sleep: time.Sleep}
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	// Читаем порциями не больше десятой части секундного лимита, чтобы паузы были короткими
+	if chunk := max(r.limit/10, 1); uint64(len(p)) > chunk {
+		p = p[:chunk]
+	}
+
+	n, err := r.ReadCloser.Read(p)
+	r.read += uint64(n)
+
+	expected := time.Duration(float64(r.read) / float64(r.limit) * float64(time.Second))
+	if wait := expected - time.Since(r.start); wait > 0 {
+		r.sleep(wait)
+	}
+	return n, err
+}
@@ -0,0 +1,244 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package oplock
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/helper"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// DefaultPath файл блокировки операций apm, общий для CLI, D-Bus и HTTP сервисов
+const DefaultPath = "/run/apm.lock"
+
+// defaultPollInterval интервал повторных попыток захвата файла при ожидании
+const defaultPollInterval = 500 * time.Millisecond
+
+// Holder сведения о процессе, выполняющем операцию
+type Holder struct {
+	PID         int       `json:"pid"`
+	Operation   string    `json:"operation"`
+	Transaction string    `json:"transaction,omitempty"`
+	Started     time.Time `json:"started"`
+}
+
+// ErrBusy возвращается, если другая операция apm уже выполняется
+type ErrBusy struct {
+	Holder Holder
+	// locale язык клиента, запросившего блокировку, на нём формируются сообщения
+	locale string
+}
+
+// newErrBusy возвращает ErrBusy с языком клиента из ctx
+func newErrBusy(ctx context.Context, holder Holder) error {
+	return apmerr.New(apmerr.ErrorTypeBusy, &ErrBusy{Holder: holder, locale: app.LocaleFromContext(ctx)})
+}
+
+func (e *ErrBusy) Error() string {
+	ctx := app.WithLocale(context.Background(), e.locale)
+	h := e.Holder
+	switch {
+	case h.PID > 0 && h.Transaction != "":
+		return fmt.Sprintf(app.TL_(ctx, "Another operation (%s) is in progress by PID %d, transaction %s"), h.Operation, h.PID, h.Transaction)
+	case h.PID > 0:
+		return fmt.Sprintf(app.TL_(ctx, "Another operation (%s) is in progress by PID %d"), h.Operation, h.PID)
+	default:
+		return app.TL_(ctx, "Another apm operation is in progress")
+	}
+}
+
+// GetRemediation предлагает дождаться завершения текущей операции
+func (e *ErrBusy) GetRemediation() *apmerr.Remediation {
+	return &apmerr.Remediation{
+		Action: apmerr.RemediationClosePkgManager,
+		Hint:   app.TL_(app.WithLocale(context.Background(), e.locale), "Wait for the running operation to finish or repeat the command with --wait"),
+	}
+}
+
+// Lock рекомендательная блокировка операций: flock на файле между процессами
+// и очередь из одного слота внутри процесса, в которой ждут запросы сервисов.
+type Lock struct {
+	path         string
+	pollInterval time.Duration
+	slot         chan struct{}
+
+	mu     sync.Mutex
+	holder Holder
+}
+
+// New создаёт блокировку на файле path
+func New(path string) *Lock {
+	return &Lock{
+		path:         path,
+		pollInterval: defaultPollInterval,
+		slot:         make(chan struct{}, 1),
+	}
+}
+
+var (
+	sharedOnce sync.Once
+	shared     *Lock
+)
+
+// Shared возвращает общую для процесса блокировку на DefaultPath
+func Shared() *Lock {
+	sharedOnce.Do(func() {
+		shared = New(DefaultPath)
+	})
+	return shared
+}
+
+type heldKey struct{}
+
+// errWaitTimeout причина отмены ожидания по helper.WaitLockTimeoutKey
+var errWaitTimeout = errors.New("lock wait timeout")
+
+// Acquire захватывает блокировку для операции. С helper.WaitLockKey в ctx ожидает освобождения
+// (не дольше helper.WaitLockTimeoutKey, если он задан), иначе сразу возвращает ErrBusy. Вложенные вызовы с контекстом, полученным от Acquire, не блокируются.
+// Для nil блокировки возвращает ctx без изменений.
+func (l *Lock) Acquire(ctx context.Context, operation string) (context.Context, func(), error) {
+	if l == nil || ctx.Value(heldKey{}) == l {
+		return ctx, func() {}, nil
+	}
+
+	wait, _ := ctx.Value(helper.WaitLockKey).(bool)
+	tx, _ := ctx.Value(helper.TransactionKey).(string)
+	holder := Holder{PID: os.Getpid(), Operation: operation, Transaction: tx, Started: time.Now()}
+
+	waitCtx := ctx
+	if timeout, _ := ctx.Value(helper.WaitLockTimeoutKey).(time.Duration); wait && timeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeoutCause(ctx, timeout, errWaitTimeout)
+		defer cancel()
+	}
+
+	select {
+	case l.slot <- struct{}{}:
+	default:
+		if !wait {
+			return ctx, nil, newErrBusy(ctx, l.current())
+		}
+		select {
+		case l.slot <- struct{}{}:
+		case <-waitCtx.Done():
+			return ctx, nil, waitError(waitCtx, l.current())
+		}
+	}
+
+	file, err := l.lockFile(waitCtx, wait)
+	if err != nil {
+		<-l.slot
+		return ctx, nil, err
+	}
+	writeHolder(file, holder)
+
+	l.mu.Lock()
+	l.holder = holder
+	l.mu.Unlock()
+
+	release := sync.OnceFunc(func() {
+		_ = file.Truncate(0)
+		_ = syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		_ = file.Close()
+
+		l.mu.Lock()
+		l.holder = Holder{}
+		l.mu.Unlock()
+		<-l.slot
+	})
+
+	return context.WithValue(ctx, heldKey{}, l), release, nil
+}
+
+// current возвращает владельца блокировки внутри процесса
+func (l *Lock) current() Holder {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.holder
+}
+
+// lockFile захватывает flock на файле блокировки, при wait повторяя попытки до отмены ctx.
+func (l *Lock) lockFile(ctx context.Context, wait bool) (*os.File, error) {
+	file, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypePermission, fmt.Errorf(app.TL_(ctx, "failed to open lock file %s: %w"), l.path, err))
+	}
+
+	for {
+		err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil {
+			return file, nil
+		}
+		if !errors.Is(err, syscall.EWOULDBLOCK) {
+			_ = file.Close()
+			return nil, apmerr.New(apmerr.ErrorTypeBusy, fmt.Errorf(app.TL_(ctx, "failed to lock %s: %w"), l.path, err))
+		}
+		if !wait {
+			holder := readHolder(file)
+			_ = file.Close()
+			return nil, newErrBusy(ctx, holder)
+		}
+
+		select {
+		case <-ctx.Done():
+			holder := readHolder(file)
+			_ = file.Close()
+			return nil, waitError(ctx, holder)
+		case <-time.After(l.pollInterval):
+		}
+	}
+}
+
+// waitError возвращает ErrBusy, если истёк срок ожидания блокировки, и ошибку отмены, если отменён сам запрос
+func waitError(ctx context.Context, holder Holder) error {
+	if errors.Is(context.Cause(ctx), errWaitTimeout) {
+		return newErrBusy(ctx, holder)
+	}
+	return apmerr.New(apmerr.ErrorTypeCanceled, ctx.Err())
+}
+
+// writeHolder записывает сведения о владельце в файл блокировки для сообщений другим процессам
+func writeHolder(file *os.File, holder Holder) {
+	data, err := json.Marshal(holder)
+	if err != nil {
+		return
+	}
+	if err = file.Truncate(0); err != nil {
+		return
+	}
+	_, _ = file.WriteAt(data, 0)
+}
+
+// readHolder читает сведения о владельце блокировки, записанные другим процессом
+func readHolder(file *os.File) Holder {
+	var holder Holder
+	data, err := io.ReadAll(io.NewSectionReader(file, 0, 4096))
+	if err != nil {
+		return holder
+	}
+	_ = json.Unmarshal(data, &holder)
+	return holder
+}
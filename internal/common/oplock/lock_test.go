@@ -0,0 +1,163 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package oplock
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/helper"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLock(t *testing.T) *Lock {
+	l := New(filepath.Join(t.TempDir(), "apm.lock"))
+	l.pollInterval = 10 * time.Millisecond
+	return l
+}
+
+func TestAcquireBusy(t *testing.T) {
+	l := newTestLock(t)
+	ctx := context.WithValue(context.Background(), helper.TransactionKey, "tx-1")
+
+	held, release, err := l.Acquire(ctx, "install")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, nestedRelease, err := l.Acquire(held, "remove"); err != nil {
+		t.Errorf("expected nested acquire to pass, got %v", err)
+	} else {
+		nestedRelease()
+	}
+
+	_, _, err = l.Acquire(context.Background(), "remove")
+	var apmErr apmerr.APMError
+	if !errors.As(err, &apmErr) || apmErr.Type != apmerr.ErrorTypeBusy {
+		t.Fatalf("expected BUSY error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "tx-1") {
+		t.Errorf("expected holder transaction in error, got %q", err.Error())
+	}
+
+	// Другой процесс видит занятый файл и сведения о владельце
+	other := New(l.path)
+	_, _, err = other.Acquire(app.WithLocale(context.Background(), "ru"), "upgrade")
+	var busy *ErrBusy
+	if !errors.As(err, &busy) || busy.Holder.Operation != "install" || busy.Holder.Transaction != "tx-1" {
+		t.Fatalf("expected file lock held by install tx-1, got %v", err)
+	}
+	if busy.locale != "ru" {
+		t.Errorf("expected the client locale kept in the error, got %q", busy.locale)
+	}
+
+	release()
+	release()
+
+	_, otherRelease, err := other.Acquire(context.Background(), "upgrade")
+	if err != nil {
+		t.Fatalf("expected lock to be free after release, got %v", err)
+	}
+	otherRelease()
+}
+
+func TestAcquireWait(t *testing.T) {
+	l := newTestLock(t)
+	waitCtx := context.WithValue(context.Background(), helper.WaitLockKey, true)
+
+	_, release, err := l.Acquire(context.Background(), "install")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acquired := make(chan error, 1)
+	go func() {
+		_, waitRelease, err := l.Acquire(waitCtx, "remove")
+		if err == nil {
+			waitRelease()
+		}
+		acquired <- err
+	}()
+
+	select {
+	case err = <-acquired:
+		t.Fatalf("expected waiting acquire to block, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	release()
+	if err = <-acquired; err != nil {
+		t.Errorf("expected queued acquire to succeed, got %v", err)
+	}
+
+	_, release, _ = New(l.path).Acquire(context.Background(), "install")
+	defer release()
+
+	canceled, cancel := context.WithTimeout(waitCtx, 50*time.Millisecond)
+	defer cancel()
+	_, _, err = l.Acquire(canceled, "remove")
+	var apmErr apmerr.APMError
+	if !errors.As(err, &apmErr) || apmErr.Type != apmerr.ErrorTypeCanceled {
+		t.Errorf("expected CANCELED error while waiting for another process, got %v", err)
+	}
+}
+
+func TestAcquireWaitTimeout(t *testing.T) {
+	l := newTestLock(t)
+	ctx := context.WithValue(context.Background(), helper.WaitLockKey, true)
+	ctx = context.WithValue(ctx, helper.WaitLockTimeoutKey, 50*time.Millisecond)
+
+	_, release, err := l.Acquire(context.WithValue(context.Background(), helper.TransactionKey, "tx-1"), "install")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var busy *ErrBusy
+	_, _, err = l.Acquire(ctx, "remove")
+	if !errors.As(err, &busy) || busy.Holder.Transaction != "tx-1" {
+		t.Errorf("expected BUSY after waiting in the process queue, got %v", err)
+	}
+
+	other := New(l.path)
+	other.pollInterval = 10 * time.Millisecond
+	_, _, err = other.Acquire(ctx, "remove")
+	var apmErr apmerr.APMError
+	if !errors.As(err, &apmErr) || apmErr.Type != apmerr.ErrorTypeBusy || !errors.As(err, &busy) || busy.Holder.Operation != "install" {
+		t.Errorf("expected BUSY after waiting for another process, got %v", err)
+	}
+
+	release()
+	_, otherRelease, err := other.Acquire(ctx, "remove")
+	if err != nil {
+		t.Fatalf("expected free lock to be acquired, got %v", err)
+	}
+	otherRelease()
+}
+
+func TestAcquireNil(t *testing.T) {
+	var l *Lock
+	ctx := context.Background()
+	got, release, err := l.Acquire(ctx, "install")
+	if err != nil || got != ctx {
+		t.Fatalf("expected nil lock to be a no-op, got %v", err)
+	}
+	release()
+}
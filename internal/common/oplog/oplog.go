@@ -0,0 +1,248 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package oplog
+
+import (
+	"apm/internal/common/app"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// LevelError ошибка
+	LevelError = "error"
+	// LevelWarning предупреждение
+	LevelWarning = "warning"
+	// LevelInfo информационное сообщение
+	LevelInfo = "info"
+	// LevelDebug отладочное сообщение
+	LevelDebug = "debug"
+)
+
+// journalctlPath утилита чтения systemd journal
+const journalctlPath = "journalctl"
+
+// Entry запись лога apm.
+type Entry struct {
+	Time        time.Time `json:"time"`
+	Level       string    `json:"level"`
+	Module      string    `json:"module,omitempty"`
+	Transaction string    `json:"transaction,omitempty"`
+	PID         int       `json:"pid,omitempty"`
+	Message     string    `json:"message"`
+}
+
+// Filter параметры выборки записей лога.
+type Filter struct {
+	// Grep подстрока для поиска в сообщении и модуле без учёта регистра
+	Grep string
+	// Transaction идентификатор транзакции
+	Transaction string
+	// Lines максимальное количество последних записей, 0 — без ограничения
+	Lines int
+}
+
+// Match проверяет, подходит ли запись под фильтр.
+func (f Filter) Match(e Entry) bool {
+	if f.Transaction != "" && e.Transaction != f.Transaction {
+		return false
+	}
+	if f.Grep == "" {
+		return true
+	}
+
+	grep := strings.ToLower(f.Grep)
+	return strings.Contains(strings.ToLower(e.Message), grep) || strings.Contains(strings.ToLower(e.Module), grep)
+}
+
+// Reader читает записи apm из systemd journal, включая записи системного D-Bus и HTTP сервиса.
+type Reader struct{}
+
+// NewReader создаёт новый Reader.
+func NewReader() *Reader {
+	return &Reader{}
+}
+
+// Read возвращает последние записи, подходящие под фильтр, от старых к новым.
+func (r *Reader) Read(ctx context.Context, filter Filter) ([]Entry, error) {
+	var entries []Entry
+	err := r.stream(ctx, journalArgs(filter, false), func(e Entry) {
+		if !filter.Match(e) {
+			return
+		}
+		entries = append(entries, e)
+		if filter.Lines > 0 && len(entries) > filter.Lines {
+			entries = entries[1:]
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if entries == nil {
+		entries = []Entry{}
+	}
+	return entries, nil
+}
+
+// Follow выводит последние записи и затем новые по мере появления, пока не отменён ctx.
+func (r *Reader) Follow(ctx context.Context, filter Filter, fn func(Entry)) error {
+	err := r.stream(ctx, journalArgs(filter, true), func(e Entry) {
+		if filter.Match(e) {
+			fn(e)
+		}
+	})
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// stream запускает journalctl и передаёт каждую разобранную запись в fn.
+func (r *Reader) stream(ctx context.Context, args []string, fn func(Entry)) error {
+	journalctl, err := exec.LookPath(journalctlPath)
+	if err != nil {
+		return errors.New(app.TL_(ctx, "journalctl is not available, viewing logs requires systemd journal"))
+	}
+
+	cmd := exec.CommandContext(ctx, journalctl, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err = cmd.Start(); err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Failed to run journalctl: %w"), err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		entry, errParse := ParseEntry(scanner.Bytes())
+		if errParse != nil {
+			app.Log.Debug(fmt.Sprintf("skip journal entry: %v", errParse))
+			continue
+		}
+		fn(entry)
+	}
+
+	if err = cmd.Wait(); err != nil && ctx.Err() == nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf(app.TL_(ctx, "Failed to read journal: %s"), msg)
+		}
+		return fmt.Errorf(app.TL_(ctx, "Failed to read journal: %w"), err)
+	}
+
+	return scanner.Err()
+}
+
+// journalArgs формирует аргументы journalctl для фильтра.
+// Фильтр по транзакции передаётся в journalctl, поиск по подстроке выполняется на стороне apm.
+func journalArgs(filter Filter, follow bool) []string {
+	args := []string{"--output=json", "--no-pager", "SYSLOG_IDENTIFIER=" + app.LogIdentifier}
+	if filter.Transaction != "" {
+		args = append(args, "APM_"+strings.ToUpper(app.LogFieldTransaction)+"="+filter.Transaction)
+	}
+	if follow {
+		args = append(args, "--follow")
+	}
+	// При поиске по подстроке последние записи отбираются уже после фильтрации
+	if filter.Lines > 0 && (follow || filter.Grep == "") {
+		args = append(args, "--lines="+strconv.Itoa(filter.Lines))
+	}
+	return args
+}
+
+// ParseEntry разбирает строку вывода journalctl --output=json.
+func ParseEntry(line []byte) (Entry, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(line, &raw); err != nil {
+		return Entry{}, err
+	}
+
+	entry := Entry{
+		Message:     fieldString(raw["MESSAGE"]),
+		Module:      fieldString(raw["APM_"+strings.ToUpper(app.LogFieldModule)]),
+		Transaction: fieldString(raw["APM_"+strings.ToUpper(app.LogFieldTransaction)]),
+		Level:       LevelInfo,
+	}
+
+	if usec, err := strconv.ParseInt(fieldString(raw["__REALTIME_TIMESTAMP"]), 10, 64); err == nil {
+		entry.Time = time.UnixMicro(usec)
+	}
+	if pid, err := strconv.Atoi(fieldString(raw["_PID"])); err == nil {
+		entry.PID = pid
+	}
+	if priority, err := strconv.Atoi(fieldString(raw["PRIORITY"])); err == nil {
+		entry.Level = priorityLevel(priority)
+	}
+
+	return entry, nil
+}
+
+// priorityLevel переводит syslog-приоритет в уровень записи.
+func priorityLevel(priority int) string {
+	switch {
+	case priority <= 3:
+		return LevelError
+	case priority == 4:
+		return LevelWarning
+	case priority == 7:
+		return LevelDebug
+	default:
+		return LevelInfo
+	}
+}
+
+// fieldString возвращает значение поля journal. Бинарные значения journalctl
+// выводит массивом байт, повторяющиеся поля — массивом значений, из которых берётся первое.
+func fieldString(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+
+	var ints []int
+	if err := json.Unmarshal(raw, &ints); err == nil {
+		b := make([]byte, len(ints))
+		for i, v := range ints {
+			b[i] = byte(v)
+		}
+		return string(b)
+	}
+
+	var list []json.RawMessage
+	if err := json.Unmarshal(raw, &list); err == nil && len(list) > 0 {
+		return fieldString(list[0])
+	}
+
+	return ""
+}
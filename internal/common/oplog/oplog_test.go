@@ -0,0 +1,95 @@
+package oplog
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseEntry(t *testing.T) {
+	line := `{"__REALTIME_TIMESTAMP":"1760000000000000","PRIORITY":"4","_PID":"42","MESSAGE":"[RUN] Installing packages","APM_MODULE":"system","APM_TRANSACTION":"tx-1","SYSLOG_IDENTIFIER":"apm"}`
+
+	e, err := ParseEntry([]byte(line))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e.Level != LevelWarning {
+		t.Errorf("expected level warning, got %s", e.Level)
+	}
+	if e.Module != "system" || e.Transaction != "tx-1" || e.PID != 42 {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+	if !e.Time.Equal(time.UnixMicro(1760000000000000)) {
+		t.Errorf("unexpected time: %v", e.Time)
+	}
+}
+
+func TestParseEntryBinaryMessage(t *testing.T) {
+	e, err := ParseEntry([]byte(`{"MESSAGE":[104,105],"PRIORITY":"3"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e.Message != "hi" || e.Level != LevelError {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+}
+
+func TestParseEntryInvalid(t *testing.T) {
+	if _, err := ParseEntry([]byte("not json")); err == nil {
+		t.Error("expected error for invalid line")
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	e := Entry{Module: "repo", Transaction: "tx-1", Message: "Repository added"}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"empty filter", Filter{}, true},
+		{"grep message ignores case", Filter{Grep: "REPOSITORY"}, true},
+		{"grep module", Filter{Grep: "repo"}, true},
+		{"grep miss", Filter{Grep: "kernel"}, false},
+		{"transaction match", Filter{Transaction: "tx-1"}, true},
+		{"transaction miss", Filter{Transaction: "tx-2"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(e); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJournalArgs(t *testing.T) {
+	args := journalArgs(Filter{Transaction: "tx-1", Lines: 20}, false)
+	for _, want := range []string{"SYSLOG_IDENTIFIER=apm", "APM_TRANSACTION=tx-1", "--lines=20"} {
+		if !slices.Contains(args, want) {
+			t.Errorf("expected %s in %v", want, args)
+		}
+	}
+
+	args = journalArgs(Filter{Grep: "error", Lines: 20}, false)
+	if slices.Contains(args, "--lines=20") {
+		t.Errorf("lines must be applied after grep, got %v", args)
+	}
+
+	args = journalArgs(Filter{Grep: "error", Lines: 20}, true)
+	if !slices.Contains(args, "--follow") || !slices.Contains(args, "--lines=20") {
+		t.Errorf("unexpected follow args: %v", args)
+	}
+}
+
+func TestRender(t *testing.T) {
+	r := &Renderer{}
+	line := r.Render(Entry{Level: LevelInfo, Module: "system", Transaction: "tx-1", Message: "done"})
+	for _, want := range []string{"INFO", "[system]", "done", "(tx-1)"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("expected %q in %q", want, line)
+		}
+	}
+}
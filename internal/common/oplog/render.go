@@ -0,0 +1,65 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package oplog
+
+import (
+	"apm/internal/common/app"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Renderer форматирует записи лога для вывода в терминал.
+type Renderer struct {
+	timeStyle   lipgloss.Style
+	moduleStyle lipgloss.Style
+	txStyle     lipgloss.Style
+	levelStyles map[string]lipgloss.Style
+}
+
+// NewRenderer создаёт Renderer с цветами из конфигурации.
+func NewRenderer(colors app.Colors) *Renderer {
+	return &Renderer{
+		timeStyle:   lipgloss.NewStyle().Faint(true),
+		moduleStyle: lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colors.Accent)),
+		txStyle:     lipgloss.NewStyle().Foreground(lipgloss.Color(colors.TreeBranch)),
+		levelStyles: map[string]lipgloss.Style{
+			LevelError:   lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colors.ResultError)),
+			LevelWarning: lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("3")),
+			LevelInfo:    lipgloss.NewStyle().Foreground(lipgloss.Color("2")),
+			LevelDebug:   lipgloss.NewStyle().Faint(true),
+		},
+	}
+}
+
+// Render возвращает строку вида «время УРОВЕНЬ [модуль] сообщение (транзакция)».
+func (r *Renderer) Render(e Entry) string {
+	parts := []string{
+		r.timeStyle.Render(e.Time.Format("2006-01-02 15:04:05")),
+		r.levelStyles[e.Level].Render(fmt.Sprintf("%-7s", strings.ToUpper(e.Level))),
+	}
+	if e.Module != "" {
+		parts = append(parts, r.moduleStyle.Render("["+e.Module+"]"))
+	}
+	parts = append(parts, e.Message)
+	if e.Transaction != "" {
+		parts = append(parts, r.txStyle.Render("("+e.Transaction+")"))
+	}
+
+	return strings.Join(parts, " ")
+}
@@ -18,8 +18,10 @@ package reply
 
 import (
 	"apm/internal/common/app"
+	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/godbus/dbus/v5"
@@ -48,6 +50,12 @@ type EventData struct {
 	Transaction     string  `json:"transaction"`
 }
 
+const (
+	dbusObjectPath = dbus.ObjectPath("/org/altlinux/APM")
+	dbusInterface  = "org.altlinux.APM"
+	dbusSignalName = dbusInterface + ".Notification"
+)
+
 const (
 	EventTypeNotification = "NOTIFICATION"
 	EventTypeProgress     = "PROGRESS"
@@ -63,6 +71,11 @@ const (
 const (
 	EventDistroUpdate       = "distrobox.Update"
 	EventDistroContainerAdd = "distrobox.ContainerAdd"
+	EventDistroIconSync     = "distrobox.IconSync"
+	EventDistroStorageSet   = "distrobox.StorageSet"
+	EventDistroClone        = "distrobox.ContainerClone"
+	EventDistroSnapshot     = "distrobox.ContainerSnapshot"
+	EventDistroRestore      = "distrobox.ContainerRestore"
 
 	EventDistroSavePackagesToDB = "distro.SavePackagesToDB"
 	EventDistroGetContainerList = "distro.GetContainerList"
@@ -70,6 +83,8 @@ const (
 	EventDistroGetContainerInfo = "distro.GetContainerOsInfo"
 	EventDistroCreateContainer  = "distro.CreateContainer"
 	EventDistroRemoveContainer  = "distro.RemoveContainer"
+	EventDistroStartContainer   = "distro.StartContainer"
+	EventDistroStopContainer    = "distro.StopContainer"
 	EventDistroInstallPackage   = "distro.InstallPackage"
 	EventDistroRemovePackage    = "distro.RemovePackage"
 	EventDistroGetPackages      = "distro.GetPackages"
@@ -78,6 +93,11 @@ const (
 	EventDistroGetInfoPackage   = "distro.GetInfoPackage"
 	EventDistroUpdatePackages   = "distro.UpdatePackages"
 	EventDistroGetPackagesQuery = "distro.GetPackagesQuery"
+	EventDistroProvision        = "distro.Provision"
+	EventDistroRelocateStorage  = "distro.RelocateStorage"
+	EventDistroCloneContainer   = "distro.CloneContainer"
+	EventDistroCommitContainer  = "distro.CommitContainer"
+	EventDistroRestoreContainer = "distro.RestoreContainer"
 
 	EventSystemWorking              = "system.Working"
 	EventSystemUpgrade              = "system.Upgrade"
@@ -87,7 +107,11 @@ const (
 	EventSystemRemove               = "system.Remove"
 	EventSystemCheckInstall         = "system.CheckInstall"
 	EventSystemCheckRemove          = "system.CheckRemove"
+	EventSystemAutoRemove           = "system.AutoRemove"
+	EventSystemCheckAutoRemove      = "system.CheckAutoRemove"
 	EventSystemCheckUpgrade         = "system.CheckUpgrade"
+	EventSystemReinstall            = "system.Reinstall"
+	EventSystemCheckReinstall       = "system.CheckReinstall"
 	EventSystemImageUpdate          = "system.ImageUpdate"
 	EventSystemImageApply           = "system.ImageApply"
 	EventSystemUpdateKernel         = "system.UpdateKernel"
@@ -97,6 +121,7 @@ const (
 	EventSystemSaveImageToDB        = "system.SaveImageToDB"
 	EventSystemBuildImage           = "system.BuildImage"
 	EventSystemSwitchImage          = "system.SwitchImage"
+	EventSystemRollbackImage        = "system.RollbackImage"
 	EventSystemCheckUpdateBaseImage = "system.CheckAndUpdateBaseImage"
 	EventSystemBootcUpgrade         = "system.bootcUpgrade"
 	EventSystemPruneOldImages       = "system.pruneOldImages"
@@ -104,10 +129,27 @@ const (
 	EventSystemUpdateApplications   = "system.UpdateApplications"
 	EventSystemDownloadProgress     = "system.downloadProgress"
 	EventSystemInstallProgress      = "system.installProgress"
+	EventSystemAptUpdateProgress    = "system.aptUpdateProgress"
 	EventSystemPullImage            = "system.pullImage"
 	EventSystemLintTmpfiles         = "system.LintTmpfiles"
 	EventSystemLintSysusers         = "system.LintSysusers"
 	EventSystemLintRunTmp           = "system.LintRunTmp"
+	EventSystemVerifyPackages       = "system.VerifyPackages"
+	EventSystemRepair               = "system.Repair"
+	EventSystemRestartServices      = "system.RestartServices"
+	EventSystemRollback             = "system.Rollback"
+	EventSystemBatch                = "system.Batch"
+	EventSystemWatch                = "system.Watch"
+
+	EventRepoAdd        = "repo.Add"
+	EventRepoRemove     = "repo.Remove"
+	EventRepoSet        = "repo.Set"
+	EventRepoClean      = "repo.Clean"
+	EventRepoCheckURL   = "repo.CheckURL"
+	EventRepoVerifyTask = "repo.VerifyTask"
+	EventRepoKeyAdd     = "repo.KeyAdd"
+	EventRepoKeyRemove  = "repo.KeyRemove"
+	EventRepoRestore    = "repo.Restore"
 
 	EventApplicationUpdate   = "application.Update"
 	EventApplicationSaveToDB = "application.SaveToDB"
@@ -130,6 +172,7 @@ const (
 	EventKernelCheckRemoveMods  = "kernel.CheckRemoveKernelModules"
 	EventKernelRemove           = "kernel.RemovePackage"
 	EventKernelCheckRemove      = "kernel.CheckRemovePackage"
+	EventKernelRebuildModules   = "kernel.RebuildModules"
 )
 
 // TaskResultEvent содержит результат фоновой задачи
@@ -195,10 +238,7 @@ func sendNotificationResponse(eventData *EventData, dbusConn *dbus.Conn) {
 		return
 	}
 
-	objPath := dbus.ObjectPath("/org/altlinux/APM")
-	signalName := "org.altlinux.APM.Notification"
-
-	err = dbusConn.Emit(objPath, signalName, string(message))
+	err = dbusConn.Emit(dbusObjectPath, dbusSignalName, string(message))
 	if err != nil {
 		app.Log.Error(app.T_("Error sending notification: %v"), err)
 	}
@@ -235,10 +275,7 @@ func sendTaskResultDBus(event *TaskResultEvent, dbusConn *dbus.Conn) {
 		return
 	}
 
-	objPath := dbus.ObjectPath("/org/altlinux/APM")
-	signalName := "org.altlinux.APM.Notification"
-
-	err = dbusConn.Emit(objPath, signalName, string(message))
+	err = dbusConn.Emit(dbusObjectPath, dbusSignalName, string(message))
 	if err != nil {
 		app.Log.Error(app.T_("Error sending task result: %v"), err)
 	}
@@ -249,17 +286,38 @@ var (
 	verboseProgressLast = make(map[string]int)
 )
 
-// logVerboseEvent логирует событие как простой текст вместо спиннера
-func logVerboseEvent(eventData *EventData) {
+// logEvent записывает начало и завершение задачи в лог с модулем и транзакцией,
+// чтобы их можно было найти через apm log. В режиме verbose записи выводятся вместо спиннера.
+func logEvent(eventData *EventData) {
 	if eventData.Type == EventTypeProgress {
-		logVerboseProgress(eventData)
 		return
 	}
 
+	fields := map[string]string{
+		app.LogFieldModule:      eventModule(eventData.Name),
+		app.LogFieldTransaction: eventData.Transaction,
+	}
+
 	if eventData.State == StateBefore {
-		app.Log.Info("[RUN] ", eventData.View)
+		app.Log.InfoFields(fields, "[RUN] ", eventData.View)
 	} else if eventData.State == StateAfter {
-		app.Log.Info("[OK] ", eventData.View)
+		app.Log.InfoFields(fields, "[OK] ", eventData.View)
+	}
+}
+
+// eventModule возвращает модуль события по префиксу имени, например system для system.Install.
+func eventModule(name string) string {
+	module, _, found := strings.Cut(name, ".")
+	if !found {
+		return ""
+	}
+	return module
+}
+
+// logVerboseEvent логирует прогресс как простой текст вместо спиннера
+func logVerboseEvent(eventData *EventData) {
+	if eventData.Type == EventTypeProgress {
+		logVerboseProgress(eventData)
 	}
 }
 
@@ -290,116 +348,144 @@ func logVerboseProgress(eventData *EventData) {
 	}
 }
 
-func getTaskText(task string) string {
+func getTaskText(ctx context.Context, task string) string {
 	switch task {
 	case EventDistroSavePackagesToDB:
-		return app.T_("Saving packages to the database")
+		return app.TL_(ctx, "Saving packages to the database")
 	case EventDistroGetContainerList:
-		return app.T_("Requesting list of containers")
+		return app.TL_(ctx, "Requesting list of containers")
 	case EventDistroExportingApp:
-		return app.T_("Exporting package")
+		return app.TL_(ctx, "Exporting package")
 	case EventDistroGetContainerInfo:
-		return app.T_("Requesting container information")
+		return app.TL_(ctx, "Requesting container information")
 	case EventDistroCreateContainer:
-		return app.T_("Creating container")
+		return app.TL_(ctx, "Creating container")
 	case EventDistroRemoveContainer:
-		return app.T_("Deleting container")
+		return app.TL_(ctx, "Deleting container")
+	case EventDistroStartContainer:
+		return app.TL_(ctx, "Starting container")
+	case EventDistroStopContainer:
+		return app.TL_(ctx, "Stopping container")
 	case EventDistroInstallPackage:
-		return app.T_("Installing package")
+		return app.TL_(ctx, "Installing package")
 	case EventDistroRemovePackage:
-		return app.T_("Removing package")
+		return app.TL_(ctx, "Removing package")
 	case EventDistroGetPackages:
-		return app.T_("Retrieving list of packages")
+		return app.TL_(ctx, "Retrieving list of packages")
 	case EventDistroGetPackageOwner:
-		return app.T_("Determining file owner")
+		return app.TL_(ctx, "Determining file owner")
 	case EventDistroGetPathByPkg:
-		return app.T_("Searching package paths")
+		return app.TL_(ctx, "Searching package paths")
 	case EventDistroGetInfoPackage:
-		return app.T_("Retrieving package information")
+		return app.TL_(ctx, "Retrieving package information")
 	case EventDistroUpdatePackages:
-		return app.T_("Updating packages")
+		return app.TL_(ctx, "Updating packages")
+	case EventDistroProvision:
+		return app.TL_(ctx, "Installing locale and fonts")
 	case EventDistroGetPackagesQuery:
-		return app.T_("Filtering packages")
+		return app.TL_(ctx, "Filtering packages")
+	case EventDistroRelocateStorage:
+		return app.TL_(ctx, "Moving container storage")
+	case EventDistroCloneContainer:
+		return app.TL_(ctx, "Cloning container")
+	case EventDistroCommitContainer:
+		return app.TL_(ctx, "Creating container snapshot")
+	case EventDistroRestoreContainer:
+		return app.TL_(ctx, "Restoring container from snapshot")
 	case EventSystemWorking:
-		return app.T_("Working with packages")
+		return app.TL_(ctx, "Working with packages")
 	case EventSystemUpgrade:
-		return app.T_("System update")
+		return app.TL_(ctx, "System update")
 	case EventSystemCheck:
-		return app.T_("Analyzing packages")
+		return app.TL_(ctx, "Analyzing packages")
 	case EventSystemUpdate:
-		return app.T_("General update process")
+		return app.TL_(ctx, "General update process")
 	case EventSystemUpdateKernel:
-		return app.T_("General update kernel")
+		return app.TL_(ctx, "General update kernel")
 	case EventSystemUpdateSTPLR:
-		return app.T_("Loading package list from STPLR repository")
+		return app.TL_(ctx, "Loading package list from STPLR repository")
 	case EventSystemAptUpdate:
-		return app.T_("Loading package list from repository")
+		return app.TL_(ctx, "Loading package list from repository")
+	case EventRepoCheckURL:
+		return app.TL_(ctx, "Checking repository availability")
 	case EventSystemSavePackagesToDB:
-		return app.T_("Saving packages to the database")
+		return app.TL_(ctx, "Saving packages to the database")
 	case EventSystemSaveImageToDB:
-		return app.T_("Saving image history to the database")
+		return app.TL_(ctx, "Saving image history to the database")
 	case EventSystemBuildImage:
-		return app.T_("Building local image")
+		return app.TL_(ctx, "Building local image")
 	case EventSystemSwitchImage:
-		return app.T_("Switching to local image")
+		return app.TL_(ctx, "Switching to local image")
+	case EventSystemRollbackImage:
+		return app.TL_(ctx, "Rolling back to the previous image")
 	case EventSystemCheckUpdateBaseImage:
-		return app.T_("General Image Update Process")
+		return app.TL_(ctx, "General Image Update Process")
 	case EventSystemBootcUpgrade:
-		return app.T_("Downloading base image update")
+		return app.TL_(ctx, "Downloading base image update")
 	case EventSystemPruneOldImages:
-		return app.T_("Cleaning up old images")
+		return app.TL_(ctx, "Cleaning up old images")
 	case EventSystemUpdateAllPackagesDB:
-		return app.T_("Synchronizing database")
+		return app.TL_(ctx, "Synchronizing database")
 	case EventSystemUpdateApplications:
-		return app.T_("Loading application data from catalogs")
+		return app.TL_(ctx, "Loading application data from catalogs")
 	case EventSystemDownloadProgress:
-		return app.T_("Downloading packages")
+		return app.TL_(ctx, "Downloading packages")
+	case EventSystemAptUpdateProgress:
+		return app.TL_(ctx, "Downloading package lists")
 	case EventSystemPullImage:
-		return app.T_("Downloading image")
+		return app.TL_(ctx, "Downloading image")
 	case EventSystemLintTmpfiles:
-		return app.T_("Checking tmpfiles.d")
+		return app.TL_(ctx, "Checking tmpfiles.d")
 	case EventSystemLintSysusers:
-		return app.T_("Checking sysusers.d")
+		return app.TL_(ctx, "Checking sysusers.d")
 	case EventSystemLintRunTmp:
-		return app.T_("Checking /run and /tmp")
+		return app.TL_(ctx, "Checking /run and /tmp")
+	case EventSystemVerifyPackages:
+		return app.TL_(ctx, "Verifying downloaded packages")
+	case EventSystemRepair:
+		return app.TL_(ctx, "Repairing interrupted transaction")
+	case EventSystemRestartServices:
+		return app.TL_(ctx, "Restarting services")
 	case EventApplicationUpdate:
-		return app.T_("Updating application data")
+		return app.TL_(ctx, "Updating application data")
 	case EventApplicationSaveToDB:
-		return app.T_("Saving application data")
+		return app.TL_(ctx, "Saving application data")
 	case EventBootcLayers:
-		return app.T_("Fetching layers")
+		return app.TL_(ctx, "Fetching layers")
 	case EventBootcDownload:
-		return app.T_("Downloading update")
+		return app.TL_(ctx, "Downloading update")
 	case EventKernelCurrent:
-		return app.T_("Get current kernel")
+		return app.TL_(ctx, "Get current kernel")
 	case EventKernelList:
-		return app.T_("Get list kernels")
+		return app.TL_(ctx, "Get list kernels")
 	case EventKernelListModules:
-		return app.T_("Get kernel modules")
+		return app.TL_(ctx, "Get kernel modules")
 	case EventKernelInstall:
-		return app.T_("Install kernel")
+		return app.TL_(ctx, "Install kernel")
 	case EventKernelCheckInstall:
-		return app.T_("Simulate install kernel")
+		return app.TL_(ctx, "Simulate install kernel")
 	case EventKernelUpdate:
-		return app.T_("Update kernel")
+		return app.TL_(ctx, "Update kernel")
 	case EventKernelCheckUpdate:
-		return app.T_("Simulate update kernel")
+		return app.TL_(ctx, "Simulate update kernel")
 	case EventKernelClean:
-		return app.T_("Clean old kernels")
+		return app.TL_(ctx, "Clean old kernels")
 	case EventKernelCheckClean:
-		return app.T_("Simulate clean old kernels")
+		return app.TL_(ctx, "Simulate clean old kernels")
 	case EventKernelInstallMods:
-		return app.T_("Install kernel modules")
+		return app.TL_(ctx, "Install kernel modules")
 	case EventKernelCheckInstallMods:
-		return app.T_("Simulate install kernel modules")
+		return app.TL_(ctx, "Simulate install kernel modules")
 	case EventKernelRemoveMods:
-		return app.T_("Remove kernel modules")
+		return app.TL_(ctx, "Remove kernel modules")
 	case EventKernelCheckRemoveMods:
-		return app.T_("Simulate remove kernel modules")
+		return app.TL_(ctx, "Simulate remove kernel modules")
 	case EventKernelRemove:
-		return app.T_("Remove packages")
+		return app.TL_(ctx, "Remove packages")
 	case EventKernelCheckRemove:
-		return app.T_("Simulate Remove packages")
+		return app.TL_(ctx, "Simulate Remove packages")
+	case EventKernelRebuildModules:
+		return app.TL_(ctx, "Rebuild external kernel modules")
 	default:
 		return task
 	}
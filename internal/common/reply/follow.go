@@ -0,0 +1,105 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reply
+
+import (
+	"apm/internal/common/app"
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// FollowDaemonEvents подписывается на сигналы сервиса apm и показывает события транзакции
+// тем же спиннером и прогрессом, что и при локальном выполнении. Возвращает результат задачи,
+// когда сервис присылает TASK_RESULT этой транзакции, или ошибку контекста при отмене.
+func FollowDaemonEvents(ctx context.Context, appConfig *app.Config, conn *dbus.Conn, transaction string) (*TaskResultEvent, error) {
+	matchOptions := []dbus.MatchOption{
+		dbus.WithMatchObjectPath(dbusObjectPath),
+		dbus.WithMatchInterface(dbusInterface),
+		dbus.WithMatchMember("Notification"),
+	}
+	if err := conn.AddMatchSignalContext(ctx, matchOptions...); err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.RemoveMatchSignal(matchOptions...) }()
+
+	signals := make(chan *dbus.Signal, 64)
+	conn.Signal(signals)
+	defer conn.RemoveSignal(signals)
+
+	verbose := appConfig.ConfigManager.GetConfig().Verbose
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case sig, ok := <-signals:
+			if !ok {
+				return nil, errors.New(app.TL_(ctx, "D-Bus connection closed"))
+			}
+
+			event, result := decodeDaemonSignal(sig, transaction)
+			if result != nil {
+				return result, nil
+			}
+			if event == nil {
+				continue
+			}
+
+			if verbose {
+				logVerboseEvent(event)
+			} else {
+				updateTask(appConfig, event.Type, event.Name, event.View, event.State, event.ProgressPercent, event.ProgressDone)
+			}
+		}
+	}
+}
+
+// decodeDaemonSignal разбирает сигнал сервиса. Сигналы других транзакций пропускаются.
+func decodeDaemonSignal(sig *dbus.Signal, transaction string) (*EventData, *TaskResultEvent) {
+	if sig == nil || sig.Name != dbusSignalName || len(sig.Body) == 0 {
+		return nil, nil
+	}
+
+	payload, ok := sig.Body[0].(string)
+	if !ok {
+		return nil, nil
+	}
+
+	var header struct {
+		Type        string `json:"type"`
+		Transaction string `json:"transaction"`
+	}
+	if err := json.Unmarshal([]byte(payload), &header); err != nil || header.Transaction != transaction {
+		return nil, nil
+	}
+
+	if header.Type == EventTypeTaskResult {
+		var result TaskResultEvent
+		if err := json.Unmarshal([]byte(payload), &result); err != nil {
+			return nil, nil
+		}
+		return nil, &result
+	}
+
+	var event EventData
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		return nil, nil
+	}
+	return &event, nil
+}
@@ -0,0 +1,70 @@
+package reply
+
+import (
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func newDaemonSignal(body ...interface{}) *dbus.Signal {
+	return &dbus.Signal{Path: dbusObjectPath, Name: dbusSignalName, Body: body}
+}
+
+func TestDecodeDaemonSignal_Event(t *testing.T) {
+	sig := newDaemonSignal(`{"name":"system.Upgrade","message":"Upgrade","state":"BEFORE","type":"PROGRESS","progress":42.5,"transaction":"tx-1"}`)
+
+	event, result := decodeDaemonSignal(sig, "tx-1")
+	if result != nil {
+		t.Fatalf("expected no result, got %+v", result)
+	}
+	if event == nil {
+		t.Fatal("expected event")
+	}
+	if event.Name != "system.Upgrade" || event.View != "Upgrade" || event.State != StateBefore {
+		t.Errorf("unexpected event: %+v", event)
+	}
+	if event.Type != EventTypeProgress || event.ProgressPercent != 42.5 {
+		t.Errorf("unexpected progress: %+v", event)
+	}
+}
+
+func TestDecodeDaemonSignal_OtherTransaction(t *testing.T) {
+	sig := newDaemonSignal(`{"name":"system.Upgrade","state":"BEFORE","type":"NOTIFICATION","transaction":"tx-2"}`)
+
+	event, result := decodeDaemonSignal(sig, "tx-1")
+	if event != nil || result != nil {
+		t.Errorf("expected signal of another transaction to be skipped, got %+v %+v", event, result)
+	}
+}
+
+func TestDecodeDaemonSignal_TaskResult(t *testing.T) {
+	sig := newDaemonSignal(`{"type":"TASK_RESULT","name":"system.ImageApply","transaction":"tx-1","data":{"message":"done"},"error":null}`)
+
+	event, result := decodeDaemonSignal(sig, "tx-1")
+	if event != nil {
+		t.Errorf("expected no event, got %+v", event)
+	}
+	if result == nil || result.Name != "system.ImageApply" || result.Error != nil {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	data, ok := result.Data.(map[string]interface{})
+	if !ok || data["message"] != "done" {
+		t.Errorf("unexpected data: %+v", result.Data)
+	}
+}
+
+func TestDecodeDaemonSignal_Malformed(t *testing.T) {
+	cases := map[string]*dbus.Signal{
+		"empty body":   newDaemonSignal(),
+		"not a string": newDaemonSignal(42),
+		"invalid json": newDaemonSignal("{"),
+		"other signal": {Path: dbusObjectPath, Name: "org.altlinux.APM.Other", Body: []interface{}{`{"transaction":"tx-1"}`}},
+	}
+
+	for name, sig := range cases {
+		event, result := decodeDaemonSignal(sig, "tx-1")
+		if event != nil || result != nil {
+			t.Errorf("%s: expected signal to be skipped", name)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reply
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+var (
+	dbusErrorType  = reflect.TypeOf((*dbus.Error)(nil))
+	dbusSenderType = reflect.TypeOf(dbus.Sender(""))
+)
+
+// Localizable объект D-Bus, методы которого формируют сообщения на языке из своего контекста
+type Localizable interface {
+	// WithLocale возвращает копию объекта, контекст которой содержит язык клиента lang
+	WithLocale(lang string) any
+}
+
+// Locales языки ответов, выбранные клиентами D-Bus
+type Locales struct {
+	mu      sync.RWMutex
+	senders map[string]string
+}
+
+// NewLocales создаёт пустой реестр языков клиентов
+func NewLocales() *Locales {
+	return &Locales{senders: make(map[string]string)}
+}
+
+// Set задаёт язык ответов клиента; пустой язык возвращает язык сервиса
+func (l *Locales) Set(sender, lang string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if lang == "" {
+		delete(l.senders, sender)
+		return
+	}
+	l.senders[sender] = lang
+}
+
+// Get возвращает язык ответов клиента
+func (l *Locales) Get(sender string) string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.senders[sender]
+}
+
+// LocalizedMethodTable строит таблицу методов D-Bus объекта, вызывающую методы с языком клиента:
+// если клиент выбрал язык, а объект реализует Localizable, метод вызывается у копии объекта
+// с этим языком в контексте. Сообщения ответа, событий и результата фоновой задачи переводятся
+// при формировании. Методам без аргумента dbus.Sender он добавляется: godbus подставляет его сам
+// и не учитывает в сигнатуре метода на шине.
+func LocalizedMethodTable(object interface{}, locales *Locales) map[string]interface{} {
+	value := reflect.ValueOf(object)
+	localizable, _ := object.(Localizable)
+	methods := make(map[string]interface{})
+	for i := 0; i < value.NumMethod(); i++ {
+		name := value.Type().Method(i).Name
+		fnType := value.Method(i).Type()
+		if fnType.NumOut() == 0 || fnType.Out(fnType.NumOut()-1) != dbusErrorType {
+			continue
+		}
+
+		senderIndex := -1
+		for j := 0; j < fnType.NumIn(); j++ {
+			if fnType.In(j) == dbusSenderType {
+				senderIndex = j
+				break
+			}
+		}
+
+		wrappedType := fnType
+		if senderIndex < 0 {
+			in := []reflect.Type{dbusSenderType}
+			for j := 0; j < fnType.NumIn(); j++ {
+				in = append(in, fnType.In(j))
+			}
+			out := make([]reflect.Type, fnType.NumOut())
+			for j := range out {
+				out[j] = fnType.Out(j)
+			}
+			wrappedType = reflect.FuncOf(in, out, fnType.IsVariadic())
+		}
+
+		methods[name] = reflect.MakeFunc(wrappedType, func(args []reflect.Value) []reflect.Value {
+			var sender string
+			if senderIndex < 0 {
+				sender = args[0].String()
+				args = args[1:]
+			} else {
+				sender = args[senderIndex].String()
+			}
+
+			method := value.Method(i)
+			if lang := locales.Get(sender); lang != "" && localizable != nil {
+				method = reflect.ValueOf(localizable.WithLocale(lang)).MethodByName(name)
+			}
+			if fnType.IsVariadic() {
+				return method.CallSlice(args)
+			}
+			return method.Call(args)
+		}).Interface()
+	}
+	return methods
+}
@@ -0,0 +1,69 @@
+package reply
+
+import (
+	"apm/internal/common/app"
+	"context"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+type localeTestObject struct {
+	ctx context.Context
+}
+
+func (o *localeTestObject) WithLocale(lang string) any {
+	return &localeTestObject{ctx: app.WithLocale(o.ctx, lang)}
+}
+
+func (o *localeTestObject) List(transaction string) (string, *dbus.Error) {
+	return "list:" + app.LocaleFromContext(o.ctx), nil
+}
+
+func (o *localeTestObject) Remove(sender dbus.Sender, name string) (string, *dbus.Error) {
+	return "", &dbus.Error{Name: "org.altlinux.APM.Error.NotFound", Body: []interface{}{"remove:" + app.LocaleFromContext(o.ctx)}}
+}
+
+func (o *localeTestObject) Helper() string { return "" }
+
+func TestLocalizedMethodTable(t *testing.T) {
+	locales := NewLocales()
+	methods := LocalizedMethodTable(&localeTestObject{ctx: context.Background()}, locales)
+
+	if _, ok := methods["Helper"]; ok {
+		t.Fatal("methods without *dbus.Error result must not be exported")
+	}
+	if _, ok := methods["WithLocale"]; ok {
+		t.Fatal("WithLocale must not be exported")
+	}
+
+	// Методу без dbus.Sender аргумент добавляется первым
+	list, ok := methods["List"].(func(dbus.Sender, string) (string, *dbus.Error))
+	if !ok {
+		t.Fatalf("unexpected List signature %T", methods["List"])
+	}
+	remove, ok := methods["Remove"].(func(dbus.Sender, string) (string, *dbus.Error))
+	if !ok {
+		t.Fatalf("unexpected Remove signature %T", methods["Remove"])
+	}
+
+	if resp, _ := list(":1.10", "tx"); resp != "list:" {
+		t.Fatalf("client without locale must be served in the service language, got %s", resp)
+	}
+
+	locales.Set(":1.10", "ru_RU.UTF-8")
+	if resp, _ := list(":1.10", "tx"); resp != "list:ru" {
+		t.Fatalf("expected the client locale in the call context, got %s", resp)
+	}
+	if _, dbusErr := remove(":1.10", "vim"); dbusErr == nil || dbusErr.Body[0] != "remove:ru" {
+		t.Fatalf("got %+v", dbusErr)
+	}
+	if _, dbusErr := remove(":1.11", "vim"); dbusErr.Body[0] != "remove:" {
+		t.Fatalf("other clients must not be affected, got %+v", dbusErr)
+	}
+
+	locales.Set(":1.10", "")
+	if locales.Get(":1.10") != "" {
+		t.Fatal("empty locale must reset the client language")
+	}
+}
@@ -32,6 +32,9 @@ const clearLine = "\r\033[K"
 
 var spinnerFrames = []string{"|", "/", "-", "\\"}
 
+// defaultAccessibleInterval интервал строк состояния в режиме доступности, если он не задан в конфигурации
+const defaultAccessibleInterval = 5 * time.Second
+
 var (
 	mu           sync.Mutex
 	activeSp     *simpleSpinner
@@ -58,6 +61,11 @@ type simpleSpinner struct {
 	doneCh      chan struct{}
 	filledStyle lipgloss.Style
 	emptyStyle  lipgloss.Style
+
+	// accessible печатает строки состояния раз в interval вместо анимации
+	accessible bool
+	interval   time.Duration
+	announced  map[string]string
 }
 
 func disableEcho() {
@@ -94,9 +102,6 @@ func CreateSpinner(appConfig *app.Config) {
 		return
 	}
 
-	disableEcho()
-	fmt.Print("\033[?25l") // скрыть курсор
-
 	colors := appConfig.ConfigManager.GetColors()
 	sp := &simpleSpinner{
 		colors:      colors,
@@ -104,6 +109,19 @@ func CreateSpinner(appConfig *app.Config) {
 		doneCh:      make(chan struct{}),
 		filledStyle: lipgloss.NewStyle().Foreground(lipgloss.Color(colors.ProgressFilled)),
 		emptyStyle:  lipgloss.NewStyle().Foreground(lipgloss.Color(colors.ProgressEmpty)),
+		interval:    120 * time.Millisecond,
+	}
+
+	if cfg := appConfig.ConfigManager.GetConfig(); cfg.Accessible {
+		sp.accessible = true
+		sp.announced = make(map[string]string)
+		sp.interval = time.Duration(cfg.AccessibleInterval) * time.Second
+		if sp.interval <= 0 {
+			sp.interval = defaultAccessibleInterval
+		}
+	} else {
+		disableEcho()
+		fmt.Print("\033[?25l") // скрыть курсор
 	}
 	activeSp = sp
 
@@ -125,6 +143,12 @@ func StopSpinner(appConfig *app.Config) {
 	close(activeSp.stopCh)
 	<-activeSp.doneCh
 
+	if activeSp.accessible {
+		activeSp.renderPlain()
+		activeSp = nil
+		return
+	}
+
 	activeSp.mu.Lock()
 	al := activeSp.activeLines
 
@@ -202,7 +226,7 @@ func updateTask(appConfig *app.Config, eventType string, taskName string, viewNa
 
 func (sp *simpleSpinner) run() {
 	defer close(sp.doneCh)
-	ticker := time.NewTicker(120 * time.Millisecond)
+	ticker := time.NewTicker(sp.interval)
 	defer ticker.Stop()
 
 	for {
@@ -210,16 +234,18 @@ func (sp *simpleSpinner) run() {
 		case <-sp.stopCh:
 			return
 		case <-ticker.C:
-			sp.render()
+			if sp.accessible {
+				sp.renderPlain()
+			} else {
+				sp.render()
+			}
 		}
 	}
 }
 
-func (sp *simpleSpinner) render() {
-	sp.mu.Lock()
-
-	prevActiveLines := sp.activeLines
-
+// takeTasks забирает завершённые задачи для печати и возвращает их строки вместе с активными задачами.
+// Вызывается под sp.mu.
+func (sp *simpleSpinner) takeTasks() ([]string, []task) {
 	var completedLines []string
 	for i := range sp.tasks {
 		t := &sp.tasks[i]
@@ -249,6 +275,45 @@ func (sp *simpleSpinner) render() {
 		}
 	}
 
+	return completedLines, actives
+}
+
+// renderPlain печатает строки состояния без управляющих последовательностей. Активная задача
+// объявляется повторно только при изменении её состояния, чтобы экранный чтец не зачитывал одно и то же.
+func (sp *simpleSpinner) renderPlain() {
+	sp.mu.Lock()
+	completedLines, actives := sp.takeTasks()
+
+	var buf strings.Builder
+	for _, line := range completedLines {
+		buf.WriteString(fmt.Sprintf(app.T_("Done: %s"), line))
+		buf.WriteByte('\n')
+	}
+
+	current := make(map[string]string, len(actives))
+	for _, t := range actives {
+		status := t.viewName + "…"
+		if t.eventType == EventTypeProgress {
+			status = fmt.Sprintf("%s %.0f%%…", t.viewName, clampPercent(t.progressPercent))
+		}
+		current[t.name] = status
+		if sp.announced[t.name] != status {
+			buf.WriteString(status)
+			buf.WriteByte('\n')
+		}
+	}
+	sp.announced = current
+	sp.mu.Unlock()
+
+	os.Stdout.WriteString(buf.String())
+}
+
+func (sp *simpleSpinner) render() {
+	sp.mu.Lock()
+
+	prevActiveLines := sp.activeLines
+	completedLines, actives := sp.takeTasks()
+
 	frame := spinnerFrames[sp.frame%len(spinnerFrames)]
 	sp.frame++
 	sp.activeLines = len(actives)
@@ -305,14 +370,19 @@ func (sp *simpleSpinner) render() {
 
 func renderProgressBar(t task, filledStyle, emptyStyle lipgloss.Style) string {
 	const width = 30
-	pct := t.progressPercent
-	if pct < 0 {
-		pct = 0
-	} else if pct > 100 {
-		pct = 100
-	}
+	pct := clampPercent(t.progressPercent)
 
 	filled := int(pct / 100 * float64(width))
 	bar := filledStyle.Render(strings.Repeat("█", filled)) + emptyStyle.Render(strings.Repeat("░", width-filled))
 	return fmt.Sprintf("[%s] %.0f%% %s", bar, pct, t.viewName)
 }
+
+// clampPercent ограничивает процент прогресса диапазоном 0..100
+func clampPercent(pct float64) float64 {
+	if pct < 0 {
+		return 0
+	} else if pct > 100 {
+		return 100
+	}
+	return pct
+}
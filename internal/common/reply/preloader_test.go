@@ -0,0 +1,33 @@
+package reply
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderPlainAnnouncesChanges(t *testing.T) {
+	sp := &simpleSpinner{accessible: true, announced: map[string]string{}}
+	sp.tasks = []task{
+		{eventType: EventTypeProgress, name: "download", viewName: "Downloading", state: StateBefore, progressPercent: 45},
+		{name: "lock", viewName: "Locking database", state: StateAfter},
+	}
+
+	out := captureStdout(t, sp.renderPlain)
+	if strings.Contains(out, "\033") || strings.Contains(out, "\r") {
+		t.Errorf("expected no control sequences, got %q", out)
+	}
+	if !strings.Contains(out, "Locking database") || !strings.Contains(out, "Downloading 45%…") {
+		t.Errorf("unexpected output: %q", out)
+	}
+
+	out = captureStdout(t, sp.renderPlain)
+	if out != "" {
+		t.Errorf("expected unchanged status to be skipped, got %q", out)
+	}
+
+	sp.tasks[0].progressPercent = 80
+	out = captureStdout(t, sp.renderPlain)
+	if out != "Downloading 80%…\n" {
+		t.Errorf("unexpected output: %q", out)
+	}
+}
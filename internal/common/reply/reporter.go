@@ -20,14 +20,17 @@ import (
 	"apm/internal/common/apmerr"
 	"apm/internal/common/app"
 	"apm/internal/common/helper"
+	"apm/internal/common/taskmanager"
 	"context"
 	"errors"
+	"time"
 )
 
 // Reporter инкапсулирует доставку ответов и событий приложения.
 type Reporter struct {
 	appConfig *app.Config
 	renderer  *responseRenderer
+	tasks     *taskmanager.Manager
 }
 
 // NewReporter создаёт Reporter поверх appConfig.
@@ -35,9 +38,23 @@ func NewReporter(appConfig *app.Config) *Reporter {
 	return &Reporter{
 		appConfig: appConfig,
 		renderer:  newResponseRenderer(appConfig),
+		tasks:     taskmanager.NewManager(),
 	}
 }
 
+// Tasks возвращает менеджер фоновых задач
+func (r *Reporter) Tasks() *taskmanager.Manager {
+	return r.tasks
+}
+
+// StartTask регистрирует фоновую задачу с transaction из ctx и возвращает её отменяемый контекст.
+// Результат задачи сохраняется при вызове SendTaskResult.
+func (r *Reporter) StartTask(ctx context.Context, taskName string) context.Context {
+	// Результат фоновой задачи доставляется событием, поэтому она может ждать блокировку операций сколько угодно
+	ctx = context.WithValue(ctx, helper.WaitLockTimeoutKey, time.Duration(0))
+	return r.tasks.Start(ctx, taskName)
+}
+
 // CliResponse рендерит APIResponse в выбранном формате (text/json/dbus/http).
 func (r *Reporter) CliResponse(ctx context.Context, resp APIResponse) error {
 	return r.renderer.CliResponse(ctx, resp)
@@ -58,12 +75,13 @@ func (r *Reporter) CreateEventNotification(ctx context.Context, state string, op
 		ed.Name = "unknown"
 	}
 	if ed.View == "" {
-		ed.View = getTaskText(ed.Name)
+		ed.View = getTaskText(ctx, ed.Name)
 	}
 	r.dispatchEvent(ctx, &ed)
 }
 
 // dispatchEvent отправляет уведомление выбранным транспортом (DBus, WebSocket, лог).
+// В машинном режиме (dbus, http) события только логируются и отправляются транспортом, без вывода в терминал.
 func (r *Reporter) dispatchEvent(ctx context.Context, eventData *EventData) {
 	if txStr, ok := ctx.Value(helper.TransactionKey).(string); ok {
 		eventData.Transaction = txStr
@@ -71,12 +89,17 @@ func (r *Reporter) dispatchEvent(ctx context.Context, eventData *EventData) {
 
 	config := r.appConfig.ConfigManager.GetConfig()
 
-	if config.Verbose {
+	logEvent(eventData)
+	if config.Verbose || IsMachineMode(r.appConfig) {
 		logVerboseEvent(eventData)
 	} else {
 		updateTask(r.appConfig, eventData.Type, eventData.Name, eventData.View, eventData.State, eventData.ProgressPercent, eventData.ProgressDone)
 	}
 
+	if quiet, _ := ctx.Value(helper.QuietKey).(bool); quiet {
+		return
+	}
+
 	switch config.Format {
 	case app.FormatDBus:
 		sendNotificationResponse(eventData, r.appConfig.DBusManager.GetConnection())
@@ -87,6 +110,8 @@ func (r *Reporter) dispatchEvent(ctx context.Context, eventData *EventData) {
 
 // SendTaskResult отправляет результат фоновой задачи через DBus или WebSocket.
 func (r *Reporter) SendTaskResult(ctx context.Context, taskName string, data interface{}, taskErr error) {
+	r.tasks.Finish(ctx, data, taskErr)
+
 	txStr, _ := ctx.Value(helper.TransactionKey).(string)
 
 	event := TaskResultEvent{
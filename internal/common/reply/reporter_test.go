@@ -0,0 +1,152 @@
+package reply
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/helper"
+	"apm/internal/common/testutil"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// captureStdout возвращает всё, что fn напечатала в stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = orig }()
+
+	fn()
+
+	_ = w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+type mockHub struct {
+	mu     sync.Mutex
+	events []interface{}
+}
+
+func (h *mockHub) BroadcastEvent(event interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, event)
+}
+
+func machineAppConfig(format string) *app.Config {
+	return &app.Config{
+		ConfigManager: &testutil.MockConfigManager{Config: &app.Configuration{Format: format}},
+		DBusManager:   app.NewDBusManager(),
+	}
+}
+
+func TestMachineModeNoStrayOutput(t *testing.T) {
+	for _, format := range []string{app.FormatDBus, app.FormatHTTP} {
+		t.Run(format, func(t *testing.T) {
+			appConfig := machineAppConfig(format)
+			reporter := NewReporter(appConfig)
+			ctx := context.WithValue(context.Background(), helper.TransactionKey, "tx-1")
+
+			out := captureStdout(t, func() {
+				CreateSpinner(appConfig)
+				reporter.CreateEventNotification(ctx, StateBefore, WithEventName(EventSystemInstall))
+				reporter.CreateEventNotification(ctx, StateBefore, WithEventName(EventSystemInstall),
+					WithProgress(true), WithProgressPercent(42))
+				reporter.CreateEventNotification(ctx, StateAfter, WithEventName(EventSystemInstall))
+				StopSpinner(appConfig)
+			})
+			if out != "" {
+				t.Errorf("expected no stdout output in %s mode, got %q", format, out)
+			}
+		})
+	}
+}
+
+func TestMachineModeCliResponseIsJSON(t *testing.T) {
+	for _, format := range []string{app.FormatDBus, app.FormatHTTP} {
+		t.Run(format, func(t *testing.T) {
+			reporter := NewReporter(machineAppConfig(format))
+			ctx := context.WithValue(context.Background(), helper.TransactionKey, "tx-2")
+
+			out := captureStdout(t, func() {
+				_ = reporter.CliResponse(ctx, OK(map[string]interface{}{"message": "done"}))
+			})
+
+			lines := strings.Split(strings.TrimSpace(out), "\n")
+			if len(lines) != 1 {
+				t.Fatalf("expected a single JSON line, got %q", out)
+			}
+			var resp APIResponse
+			if err := json.Unmarshal([]byte(lines[0]), &resp); err != nil {
+				t.Fatalf("expected JSON output, got %q: %v", out, err)
+			}
+			if resp.Transaction != "tx-2" {
+				t.Errorf("expected transaction tx-2, got %q", resp.Transaction)
+			}
+		})
+	}
+}
+
+func TestCliResponseErrorKeepsCause(t *testing.T) {
+	reporter := NewReporter(machineAppConfig(app.FormatJSON))
+	cause := apmerr.New(apmerr.ErrorTypeBusy, errors.New("locked"))
+
+	var err error
+	captureStdout(t, func() {
+		err = reporter.CliResponse(context.Background(), ErrorResponseFromError(cause))
+	})
+
+	var cliErr *CliError
+	if !errors.As(err, &cliErr) {
+		t.Fatalf("expected *CliError, got %T", err)
+	}
+	if cliErr.Error() != "" {
+		t.Errorf("error text is already printed, got %q", cliErr.Error())
+	}
+	if cliErr.ErrorCode != apmerr.ErrorTypeBusy {
+		t.Errorf("expected error code BUSY, got %q", cliErr.ErrorCode)
+	}
+	var apmErr apmerr.APMError
+	if !errors.As(err, &apmErr) || apmErr.Type != apmerr.ErrorTypeBusy {
+		t.Errorf("expected the original APMError in the chain, got %v", err)
+	}
+}
+
+func TestQuietSuppressesEvents(t *testing.T) {
+	hub := &mockHub{}
+	SetWebSocketHub(hub)
+	defer SetWebSocketHub(nil)
+
+	reporter := NewReporter(machineAppConfig(app.FormatHTTP))
+
+	quiet := context.WithValue(context.Background(), helper.QuietKey, true)
+	reporter.CreateEventNotification(quiet, StateBefore, WithEventName(EventSystemInstall))
+	if len(hub.events) != 0 {
+		t.Fatalf("expected quiet context to suppress events, got %d", len(hub.events))
+	}
+
+	reporter.SendTaskResult(quiet, EventSystemInstall, nil, nil)
+	if len(hub.events) != 1 {
+		t.Errorf("expected task result to be delivered in quiet mode, got %d events", len(hub.events))
+	}
+
+	reporter.CreateEventNotification(context.Background(), StateBefore, WithEventName(EventSystemInstall))
+	if len(hub.events) != 2 {
+		t.Errorf("expected event without quiet flag, got %d events", len(hub.events))
+	}
+}
@@ -35,10 +35,25 @@ import (
 )
 
 type APIError struct {
-	ErrorCode string `json:"errorCode"`
-	Message   string `json:"message"`
+	ErrorCode   string              `json:"errorCode"`
+	Message     string              `json:"message"`
+	Remediation *apmerr.Remediation `json:"remediation,omitempty"`
+
+	// cause исходная ошибка, по ней CLI выбирает код завершения
+	cause error
+}
+
+// CliError возвращается из CliResponse после вывода ошибки: текст уже показан пользователю,
+// поэтому Error() пуст, а исходная ошибка доступна через errors.As/errors.Is
+type CliError struct {
+	ErrorCode string
+	Err       error
 }
 
+func (e *CliError) Error() string { return "" }
+
+func (e *CliError) Unwrap() error { return e.Err }
+
 type APIResponse struct {
 	Data        interface{} `json:"data"`
 	Error       *APIError   `json:"error"`
@@ -50,11 +65,12 @@ func OK(data interface{}) APIResponse {
 }
 
 func ErrorResponseFromError(err error) APIResponse {
+	apiErr := &APIError{Message: err.Error(), Remediation: apmerr.RemediationOf(err), cause: err}
 	var apmErr apmerr.APMError
 	if errors.As(err, &apmErr) {
-		return APIResponse{Error: &APIError{ErrorCode: apmErr.Type, Message: err.Error()}}
+		apiErr.ErrorCode = apmErr.Type
 	}
-	return APIResponse{Error: &APIError{Message: err.Error()}}
+	return APIResponse{Error: apiErr}
 }
 
 type responseRenderer struct {
@@ -64,6 +80,7 @@ type responseRenderer struct {
 	accentStyle     lipgloss.Style
 	messageStyle    lipgloss.Style
 	errorMsgStyle   lipgloss.Style
+	hintStyle       lipgloss.Style
 }
 
 func newResponseRenderer(appConfig *app.Config) *responseRenderer {
@@ -79,6 +96,7 @@ func newRendererFromColors(colors app.Colors) *responseRenderer {
 		accentStyle:     lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colors.Accent)),
 		messageStyle:    lipgloss.NewStyle().Bold(true).MarginBottom(1),
 		errorMsgStyle:   lipgloss.NewStyle().Foreground(lipgloss.Color(colors.ResultError)),
+		hintStyle:       lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colors.Accent)),
 	}
 }
 
@@ -91,6 +109,12 @@ func IsInteractive(appConfig *app.Config) bool {
 	return appConfig.ConfigManager.GetConfig().Format == app.FormatText && IsTTY()
 }
 
+// IsMachineMode возвращает true для форматов dbus и http: вывод только структурированный, без обращения к TTY
+func IsMachineMode(appConfig *app.Config) bool {
+	format := appConfig.ConfigManager.GetConfig().Format
+	return format == app.FormatDBus || format == app.FormatHTTP
+}
+
 func (r *responseRenderer) formatField(key string, value interface{}) string {
 	valStr := fmt.Sprintf("%v", value)
 	if key == "name" || key == "packageName" || key == "url" {
@@ -256,6 +280,13 @@ func (r *responseRenderer) CliResponse(ctx context.Context, resp APIResponse) er
 		}
 		fmt.Println(string(b))
 
+	case app.FormatDBus, app.FormatHTTP:
+		b, err := json.Marshal(resp)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+
 	default:
 		var output string
 		if isError {
@@ -269,6 +300,9 @@ func (r *responseRenderer) CliResponse(ctx context.Context, resp APIResponse) er
 			}
 			dataMap := map[string]interface{}{"message": msg}
 			output = r.renderText(dataMap, true)
+			if resp.Error.Remediation != nil {
+				output += "\n" + r.renderHint(resp.Error.Remediation.Hint)
+			}
 		} else {
 			dataMap := toDataMap(resp.Data)
 			if dataMap != nil {
@@ -288,11 +322,16 @@ func (r *responseRenderer) CliResponse(ctx context.Context, resp APIResponse) er
 	}
 
 	if isError {
-		return errors.New("")
+		return &CliError{ErrorCode: resp.Error.ErrorCode, Err: resp.Error.cause}
 	}
 	return nil
 }
 
+// renderHint выделяет подсказку по устранению ошибки
+func (r *responseRenderer) renderHint(hint string) string {
+	return r.hintStyle.Render(app.T_("Hint") + ": " + hint)
+}
+
 func (r *responseRenderer) renderText(dataMap map[string]interface{}, isError bool) string {
 	dataMap = normalizeDataMap(dataMap)
 	if r.appConfig != nil {
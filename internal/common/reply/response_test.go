@@ -3,6 +3,7 @@ package reply
 import (
 	"apm/internal/common/apmerr"
 	"apm/internal/common/app"
+	"apm/internal/common/apt"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -399,6 +400,26 @@ func TestErrorResponseFromError_WrappedAPMError(t *testing.T) {
 	}
 }
 
+func TestErrorResponseFromError_Remediation(t *testing.T) {
+	matched := apt.CheckError("Unable to acquire APT system lock - another process may be using APT")
+	if matched == nil {
+		t.Fatal("lock error should match a known pattern")
+	}
+	resp := ErrorResponseFromError(apmerr.New(apmerr.ErrorTypeApt, matched))
+
+	if resp.Error.Remediation == nil {
+		t.Fatal("lock error should carry remediation")
+	}
+	if resp.Error.Remediation.Action != apmerr.RemediationClosePkgManager {
+		t.Errorf("expected close_package_managers, got %q", resp.Error.Remediation.Action)
+	}
+
+	b, _ := json.Marshal(ErrorResponseFromError(fmt.Errorf("plain")))
+	if strings.Contains(string(b), "remediation") {
+		t.Error("remediation should be omitted when absent")
+	}
+}
+
 func TestRenderText_TreeFormat(t *testing.T) {
 	r := newRendererFromColors(app.GetDefaultColors())
 	data := map[string]interface{}{
@@ -0,0 +1,183 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sandbox
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/command"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// AlpineProvider реализует интерфейс PackageProvider для Alpine Linux (apk)
+type AlpineProvider struct {
+	servicePackage *PackageService
+	runner         command.Runner
+}
+
+// NewAlpineProvider возвращает новый экземпляр AlpineProvider.
+func NewAlpineProvider(servicePackage *PackageService, runner command.Runner) *AlpineProvider {
+	return &AlpineProvider{
+		servicePackage: servicePackage,
+		runner:         runner,
+	}
+}
+
+// GetPackages обновляет индекс репозиториев и получает список пакетов через apk search.
+func (p *AlpineProvider) GetPackages(ctx context.Context, containerInfo ContainerInfo) ([]PackageInfo, error) {
+	if _, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "sudo", "apk", "update"}, command.WithQuiet()); err != nil {
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to update package database: %v, stderr: %s"), err, stderr)
+	}
+
+	stdout, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "apk", "search", "-v", "-d"}, command.WithEnv("LC_ALL=C"), command.WithQuiet())
+	if err != nil {
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to search packages (apk search): %v, stderr: %s"), err, stderr)
+	}
+
+	exportingPackages, err := p.servicePackage.GetAllApplicationsByContainer(ctx, containerInfo)
+	if err != nil {
+		app.Log.Error(app.TL_(ctx, "Error retrieving exporting packages: "), err)
+		exportingPackages = []string{}
+	}
+
+	installed := make(map[string]bool)
+	infoStdout, _, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "apk", "info"}, command.WithEnv("LC_ALL=C"), command.WithQuiet())
+	if err != nil {
+		app.Log.Error(app.TL_(ctx, "Error retrieving installed packages: "), err)
+	}
+	for _, line := range strings.Split(infoStdout, "\n") {
+		if name := strings.TrimSpace(line); name != "" {
+			installed[name] = true
+		}
+	}
+
+	packages := parseApkSearch(stdout)
+	markPackages(packages, containerInfo, "apk", installed, exportingPackages)
+	return packages, nil
+}
+
+// parseApkSearch разбирает строки вида "name-1.2.3-r0 - description".
+func parseApkSearch(output string) []PackageInfo {
+	var packages []PackageInfo
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		nameVersion, description, _ := strings.Cut(strings.TrimSpace(line), " - ")
+		name, version := splitApkNameVersion(nameVersion)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		packages = append(packages, PackageInfo{
+			Name:        name,
+			Version:     version,
+			Description: strings.TrimSpace(description),
+		})
+	}
+	return packages
+}
+
+// splitApkNameVersion делит "py3-foo-1.2.3-r0" на имя "py3-foo" и версию "1.2.3-r0".
+func splitApkNameVersion(s string) (string, string) {
+	release := strings.LastIndex(s, "-")
+	if release <= 0 {
+		return "", ""
+	}
+	version := strings.LastIndex(s[:release], "-")
+	if version <= 0 {
+		return "", ""
+	}
+	return s[:version], s[version+1:]
+}
+
+// RemovePackage удаляет указанный пакет через apk del.
+func (p *AlpineProvider) RemovePackage(ctx context.Context, containerInfo ContainerInfo, packageName string) error {
+	if err := validatePackageName(packageName); err != nil {
+		return err
+	}
+	_, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "sudo", "apk", "del", packageName})
+	if err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Failed to remove package %s: %v, stderr: %s"), packageName, err, stderr)
+	}
+	return nil
+}
+
+// InstallPackage устанавливает указанный пакет через apk add.
+func (p *AlpineProvider) InstallPackage(ctx context.Context, containerInfo ContainerInfo, packageName string) error {
+	if err := validatePackageName(packageName); err != nil {
+		return err
+	}
+	_, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "sudo", "apk", "add", packageName})
+	if err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Failed to install package %s: %v, stderr: %s"), packageName, err, stderr)
+	}
+	return nil
+}
+
+// GetPackageOwner определяет пакет-владельца файла через apk info --who-owns.
+func (p *AlpineProvider) GetPackageOwner(ctx context.Context, containerInfo ContainerInfo, filePath string) (string, error) {
+	stdout, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "apk", "info", "--who-owns", filePath}, command.WithEnv("LC_ALL=C"), command.WithQuiet())
+	if err != nil {
+		app.Log.Debugf(app.TL_(ctx, "Command execution error: %s %s"), stderr, err.Error())
+		return "", err
+	}
+
+	// Ожидаемый вывод: "/usr/bin/htop is owned by htop-3.3.0-r0"
+	const marker = " is owned by "
+	idx := strings.Index(stdout, marker)
+	if idx == -1 {
+		return "", fmt.Errorf(app.TL_(ctx, "Failed to recognize the owner for file '%s'"), filePath)
+	}
+	name, _ := splitApkNameVersion(strings.TrimSpace(stdout[idx+len(marker):]))
+	return name, nil
+}
+
+// GetPathByPackageName возвращает список путей для файла пакета, найденных через apk info -L.
+// apk выводит пути без ведущего слеша, поэтому он добавляется.
+func (p *AlpineProvider) GetPathByPackageName(ctx context.Context, containerInfo ContainerInfo, packageName, filePath string) ([]string, error) {
+	stdout, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "apk", "info", "-L", packageName}, command.WithEnv("LC_ALL=C"), command.WithQuiet())
+	if err != nil {
+		app.Log.Debugf(app.TL_(ctx, "Command execution error: %s %s"), stderr, err.Error())
+	}
+
+	var files []string
+	for _, line := range strings.Split(stdout, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasSuffix(line, " contains:") {
+			continue
+		}
+		files = append(files, "/"+strings.TrimPrefix(line, "/"))
+	}
+	return filterPackagePaths(strings.Join(files, "\n"), filePath), nil
+}
+
+// ProvisionPackages возвращает пакеты локалей и шрифтов. Alpine использует musl, локали поставляются в musl-locales.
+func (p *AlpineProvider) ProvisionPackages(_ ContainerInfo, locale string, fonts bool) []string {
+	var packages []string
+	if locale != "" {
+		packages = append(packages, "musl-locales", "musl-locales-lang")
+	}
+	if fonts {
+		packages = append(packages, "font-dejavu", "font-liberation", "font-noto")
+	}
+	return packages
+}
+
+// GenerateLocale ничего не делает: musl не требует генерации локалей.
+func (p *AlpineProvider) GenerateLocale(_ context.Context, _ ContainerInfo, _ string) error {
+	return nil
+}
@@ -43,18 +43,18 @@ func NewAltProvider(servicePackage *PackageService, runner command.Runner) *AltP
 // GetPackages обновляет базу пакетов, выполняет поиск и отмечает установленные пакеты.
 func (p *AltProvider) GetPackages(ctx context.Context, containerInfo ContainerInfo) ([]PackageInfo, error) {
 	if _, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "sudo", "apt-get", "update"}, command.WithQuiet()); err != nil {
-		return nil, fmt.Errorf(app.T_("Failed to update package database: %v, stderr: %s"), err, stderr)
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to update package database: %v, stderr: %s"), err, stderr)
 	}
 
 	stdout, _, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "apt-cache", "dumpavail"}, command.WithEnv("LC_ALL=C"), command.WithQuiet())
 	if err != nil {
-		return nil, fmt.Errorf(app.T_("Error executing command: %w"), err)
+		return nil, fmt.Errorf(app.TL_(ctx, "Error executing command: %w"), err)
 	}
 
 	// Получаем список экспортированных пакетов.
 	exportingPackages, err := p.servicePackage.GetAllApplicationsByContainer(ctx, containerInfo)
 	if err != nil {
-		app.Log.Error(app.T_("Error retrieving installed packages: "), err)
+		app.Log.Error(app.TL_(ctx, "Error retrieving installed packages: "), err)
 		exportingPackages = []string{}
 	}
 
@@ -143,7 +143,7 @@ func (p *AltProvider) RemovePackage(ctx context.Context, containerInfo Container
 	}
 	_, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "sudo", "apt-get", "remove", "-y", packageName})
 	if err != nil {
-		return fmt.Errorf(app.T_("Failed to remove package %s: %v, stderr: %s"), packageName, err, stderr)
+		return fmt.Errorf(app.TL_(ctx, "Failed to remove package %s: %v, stderr: %s"), packageName, err, stderr)
 	}
 	return nil
 }
@@ -155,11 +155,28 @@ func (p *AltProvider) InstallPackage(ctx context.Context, containerInfo Containe
 	}
 	_, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "sudo", "apt-get", "install", "-y", packageName})
 	if err != nil {
-		return fmt.Errorf(app.T_("Failed to install package %s: %v, stderr: %s"), packageName, err, stderr)
+		return fmt.Errorf(app.TL_(ctx, "Failed to install package %s: %v, stderr: %s"), packageName, err, stderr)
 	}
 	return nil
 }
 
+// ProvisionPackages возвращает пакеты локалей и шрифтов. В ALT локали поставляются уже собранными в glibc-locales.
+func (p *AltProvider) ProvisionPackages(_ ContainerInfo, locale string, fonts bool) []string {
+	var packages []string
+	if locale != "" {
+		packages = append(packages, "glibc-locales")
+	}
+	if fonts {
+		packages = append(packages, "fonts-ttf-dejavu", "fonts-ttf-liberation")
+	}
+	return packages
+}
+
+// GenerateLocale ничего не делает: glibc-locales содержит все локали в собранном виде.
+func (p *AltProvider) GenerateLocale(_ context.Context, _ ContainerInfo, _ string) error {
+	return nil
+}
+
 // GetPathByPackageName возвращает список путей для файла пакета, найденных через rpm -ql.
 func (p *AltProvider) GetPathByPackageName(ctx context.Context, containerInfo ContainerInfo, packageName, filePath string) ([]string, error) {
 	parseOutput := func(output string) []string {
@@ -176,7 +193,7 @@ func (p *AltProvider) GetPathByPackageName(ctx context.Context, containerInfo Co
 
 	stdout, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "rpm", "-ql", packageName}, command.WithEnv("LC_ALL=C"), command.WithQuiet())
 	if err != nil {
-		app.Log.Debugf(app.T_("Command execution error: %s %s"), stderr, err.Error())
+		app.Log.Debugf(app.TL_(ctx, "Command execution error: %s %s"), stderr, err.Error())
 	}
 
 	filtered := helper.FilterLines(stdout, filePath)
@@ -184,7 +201,7 @@ func (p *AltProvider) GetPathByPackageName(ctx context.Context, containerInfo Co
 	if len(paths) == 0 {
 		qaStdout, qaStderr, qaErr := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "rpm", "-qa"}, command.WithEnv("LC_ALL=C"), command.WithQuiet())
 		if qaErr != nil {
-			app.Log.Debugf(app.T_("Fallback command execution error: %s %s"), qaStderr, qaErr.Error())
+			app.Log.Debugf(app.TL_(ctx, "Fallback command execution error: %s %s"), qaStderr, qaErr.Error())
 			return []string{}, nil
 		}
 
@@ -209,7 +226,7 @@ func (p *AltProvider) GetPathByPackageName(ctx context.Context, containerInfo Co
 		}
 		sort.Strings(allPaths)
 		if len(allPaths) > 0 {
-			app.Log.Debugf(app.T_("Fallback search found %d files"), len(allPaths))
+			app.Log.Debugf(app.TL_(ctx, "Fallback search found %d files"), len(allPaths))
 			return allPaths, nil
 		}
 	}
@@ -221,7 +238,7 @@ func (p *AltProvider) GetPathByPackageName(ctx context.Context, containerInfo Co
 func (p *AltProvider) GetPackageOwner(ctx context.Context, containerInfo ContainerInfo, filePath string) (string, error) {
 	stdout, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "rpm", "-qf", "--queryformat", "%{NAME}", filePath}, command.WithEnv("LC_ALL=C"), command.WithQuiet())
 	if err != nil {
-		app.Log.Debugf(app.T_("Command execution error: %s %s"), stderr, err.Error())
+		app.Log.Debugf(app.TL_(ctx, "Command execution error: %s %s"), stderr, err.Error())
 		return "", err
 	}
 	return strings.TrimSpace(stdout), nil
@@ -231,7 +248,7 @@ func (p *AltProvider) GetPackageOwner(ctx context.Context, containerInfo Contain
 func (p *AltProvider) getInstalledPackages(ctx context.Context, containerInfo ContainerInfo) ([]string, error) {
 	stdout, _, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "rpm", "-qia"}, command.WithEnv("LC_ALL=C"), command.WithQuiet())
 	if err != nil {
-		return nil, fmt.Errorf(app.T_("Error executing command rpm -qia: %w"), err)
+		return nil, fmt.Errorf(app.TL_(ctx, "Error executing command rpm -qia: %w"), err)
 	}
 
 	var packages []string
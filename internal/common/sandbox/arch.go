@@ -45,24 +45,24 @@ func NewArchProvider(servicePackage *PackageService, runner command.Runner) *Arc
 // GetPackages обновляет базу пакетов и выполняет поиск:
 func (p *ArchProvider) GetPackages(ctx context.Context, containerInfo ContainerInfo) ([]PackageInfo, error) {
 	if _, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "sudo", "pacman", "-Sy", "--noconfirm"}, command.WithQuiet()); err != nil {
-		return nil, fmt.Errorf(app.T_("Failed to update package database: %v, stderr: %s"), err, stderr)
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to update package database: %v, stderr: %s"), err, stderr)
 	}
 
 	// Получаем пакеты из официальных репозиториев
 	stdoutSs, stderrSs, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "pacman", "-Ss"}, command.WithEnv("LC_ALL=C"), command.WithQuiet())
 	if err != nil {
-		return nil, fmt.Errorf(app.T_("Failed to search packages (pacman -Ss): %v, stderr: %s"), err, stderrSs)
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to search packages (pacman -Ss): %v, stderr: %s"), err, stderrSs)
 	}
 
 	exportingPackages, err := p.servicePackage.GetAllApplicationsByContainer(ctx, containerInfo)
 	if err != nil {
-		app.Log.Error(app.T_("Error retrieving exporting packages: "), err)
+		app.Log.Error(app.TL_(ctx, "Error retrieving exporting packages: "), err)
 		exportingPackages = []string{}
 	}
 
 	installedPackages, err := p.getInstalledPackages(ctx, containerInfo)
 	if err != nil {
-		app.Log.Error(app.T_("Error retrieving installed packages: "), err)
+		app.Log.Error(app.TL_(ctx, "Error retrieving installed packages: "), err)
 		installedPackages = []string{}
 	}
 	installedMap := make(map[string]bool, len(installedPackages))
@@ -72,7 +72,7 @@ func (p *ArchProvider) GetPackages(ctx context.Context, containerInfo ContainerI
 
 	packagesOfficial, err := p.parseOutput(stdoutSs, exportingPackages, installedMap)
 	if err != nil {
-		app.Log.Errorf(app.T_("Error parsing official packages: %v"), err)
+		app.Log.Errorf(app.TL_(ctx, "Error parsing official packages: %v"), err)
 		return nil, err
 	}
 
@@ -87,7 +87,7 @@ func (p *ArchProvider) GetPackages(ctx context.Context, containerInfo ContainerI
 func (p *ArchProvider) getInstalledPackages(ctx context.Context, containerInfo ContainerInfo) ([]string, error) {
 	stdout, _, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "pacman", "-Qq"}, command.WithEnv("LC_ALL=C"), command.WithQuiet())
 	if err != nil {
-		return nil, fmt.Errorf(app.T_("Error executing command pacman -Qq: %w"), err)
+		return nil, fmt.Errorf(app.TL_(ctx, "Error executing command pacman -Qq: %w"), err)
 	}
 
 	var packages []string
@@ -107,7 +107,7 @@ func (p *ArchProvider) RemovePackage(ctx context.Context, containerInfo Containe
 	}
 	_, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "sudo", "pacman", "-Rs", "--noconfirm", packageName})
 	if err != nil {
-		return fmt.Errorf(app.T_("Failed to remove package %s: %v, stderr: %s"), packageName, err, stderr)
+		return fmt.Errorf(app.TL_(ctx, "Failed to remove package %s: %v, stderr: %s"), packageName, err, stderr)
 	}
 	return nil
 }
@@ -119,7 +119,27 @@ func (p *ArchProvider) InstallPackage(ctx context.Context, containerInfo Contain
 	}
 	_, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "sudo", "pacman", "-S", "--noconfirm", packageName})
 	if err != nil {
-		return fmt.Errorf(app.T_("Failed to install package %s: %v, stderr: %s"), packageName, err, stderr)
+		return fmt.Errorf(app.TL_(ctx, "Failed to install package %s: %v, stderr: %s"), packageName, err, stderr)
+	}
+	return nil
+}
+
+// ProvisionPackages возвращает пакеты шрифтов. Локали входят в glibc и генерируются через locale-gen.
+func (p *ArchProvider) ProvisionPackages(_ ContainerInfo, _ string, fonts bool) []string {
+	if !fonts {
+		return nil
+	}
+	return []string{"noto-fonts", "ttf-dejavu", "ttf-liberation"}
+}
+
+// GenerateLocale включает локаль в /etc/locale.gen и запускает locale-gen.
+func (p *ArchProvider) GenerateLocale(ctx context.Context, containerInfo ContainerInfo, locale string) error {
+	if err := enableLocaleGen(ctx, p.runner, containerInfo, locale); err != nil {
+		return err
+	}
+	_, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "sudo", "locale-gen"})
+	if err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Failed to generate locale %s: %v, stderr: %s"), locale, err, stderr)
 	}
 	return nil
 }
@@ -141,13 +161,13 @@ func (p *ArchProvider) GetPackageOwner(ctx context.Context, containerInfo Contai
 				return fields[0], nil
 			}
 		}
-		return "", fmt.Errorf(app.T_("Failed to recognize the owner for file '%s'"), fileName)
+		return "", fmt.Errorf(app.TL_(ctx, "Failed to recognize the owner for file '%s'"), fileName)
 	}
 
 	// Если не найдено, пробуем через pacman -F.
 	stdout, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "pacman", "-F", fileName}, command.WithEnv("LC_ALL=C"), command.WithQuiet())
 	if err != nil {
-		return "", fmt.Errorf(app.T_("Failed to find a package for file '%s': %v, stderr: %s"), fileName, err, stderr)
+		return "", fmt.Errorf(app.TL_(ctx, "Failed to find a package for file '%s': %v, stderr: %s"), fileName, err, stderr)
 	}
 	lines := strings.Split(stdout, "\n")
 	for _, line := range lines {
@@ -169,7 +189,7 @@ func (p *ArchProvider) GetPackageOwner(ctx context.Context, containerInfo Contai
 			return pkgName, nil
 		}
 	}
-	return "", fmt.Errorf(app.T_("Failed to determine the package for file '%s'"), fileName)
+	return "", fmt.Errorf(app.TL_(ctx, "Failed to determine the package for file '%s'"), fileName)
 }
 
 // GetPathByPackageName возвращает список путей для файла, принадлежащего указанному пакету,
@@ -192,7 +212,7 @@ func (p *ArchProvider) GetPathByPackageName(ctx context.Context, containerInfo C
 
 	stdout, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "pacman", "-Ql", packageName}, command.WithQuiet())
 	if err != nil {
-		app.Log.Debugf(app.T_("Command execution error: %s %s"), stderr, err.Error())
+		app.Log.Debugf(app.TL_(ctx, "Command execution error: %s %s"), stderr, err.Error())
 	}
 
 	filtered := helper.FilterLines(stdout, filePath)
@@ -200,7 +220,7 @@ func (p *ArchProvider) GetPathByPackageName(ctx context.Context, containerInfo C
 	if len(paths) == 0 {
 		qqStdout, qqStderr, qqErr := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "pacman", "-Qq"}, command.WithQuiet())
 		if qqErr != nil {
-			app.Log.Debugf(app.T_("Fallback command execution error: %s %s"), qqStderr, qqErr.Error())
+			app.Log.Debugf(app.TL_(ctx, "Fallback command execution error: %s %s"), qqStderr, qqErr.Error())
 			return paths, nil
 		}
 
@@ -228,7 +248,7 @@ func (p *ArchProvider) GetPathByPackageName(ctx context.Context, containerInfo C
 		}
 		sort.Strings(allPaths)
 		if len(allPaths) > 0 {
-			app.Log.Debugf(app.T_("Fallback search found %d files"), len(allPaths))
+			app.Log.Debugf(app.TL_(ctx, "Fallback search found %d files"), len(allPaths))
 			return allPaths, nil
 		}
 	}
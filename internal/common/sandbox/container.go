@@ -0,0 +1,277 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sandbox
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/command"
+	"apm/internal/common/reply"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	// ContainerStateRunning контейнер запущен
+	ContainerStateRunning = "running"
+	// ContainerStateExited контейнер остановлен
+	ContainerStateExited = "exited"
+	// ContainerStateCreated контейнер создан, но ни разу не запускался
+	ContainerStateCreated = "created"
+)
+
+// ContainerResources потребление ресурсов запущенным контейнером по данным podman stats.
+type ContainerResources struct {
+	CPUPercent    string `json:"cpuPercent"`
+	MemoryUsage   string `json:"memoryUsage"`
+	MemoryPercent string `json:"memoryPercent"`
+	PIDs          string `json:"pids"`
+}
+
+// podmanStats строка вывода podman stats --format json
+type podmanStats struct {
+	Name       string `json:"name"`
+	CPUPercent string `json:"cpu_percent"`
+	MemUsage   string `json:"mem_usage"`
+	MemPercent string `json:"mem_percent"`
+	PIDs       string `json:"pids"`
+}
+
+// podmanInspect часть вывода podman container inspect, нужная для пересоздания контейнера
+type podmanInspect struct {
+	ImageName string `json:"ImageName"`
+	Config    struct {
+		CreateCommand []string `json:"CreateCommand"`
+	} `json:"Config"`
+}
+
+// inspectCreateCommand возвращает команду podman create, которой distrobox создал контейнер, и образ
+// контейнера. Команда пустая, если podman её не сохранил.
+func inspectCreateCommand(ctx context.Context, runner command.Runner, containerName string, opts ...command.Option) ([]string, string, error) {
+	opts = append([]command.Option{command.WithQuiet()}, opts...)
+	stdout, stderr, err := runner.Run(ctx, []string{"podman", "container", "inspect", "--format", "json", containerName}, opts...)
+	if err != nil {
+		return nil, "", fmt.Errorf(app.TL_(ctx, "Failed to inspect container %s: %s"), containerName, strings.TrimSpace(stderr))
+	}
+
+	var rows []podmanInspect
+	if err = json.Unmarshal([]byte(stdout), &rows); err != nil || len(rows) == 0 {
+		return nil, "", fmt.Errorf(app.TL_(ctx, "Failed to inspect container %s: %s"), containerName, strings.TrimSpace(stdout))
+	}
+	return rows[0].Config.CreateCommand, rows[0].ImageName, nil
+}
+
+// recreateCommand подставляет в команду создания контейнера образ image и имя containerName, сохраняя
+// остальные параметры distrobox create: домашний каталог, тома, хуки инициализации. Аргументы
+// точки входа после образа не меняются. Без исходной команды возвращает distrobox create
+// с параметрами по умолчанию.
+func recreateCommand(createCommand []string, sourceImage, image, containerName string) []string {
+	imageIndex := slices.Index(createCommand, sourceImage)
+	if len(createCommand) < 2 || sourceImage == "" || imageIndex < 0 {
+		return []string{"distrobox", "create", "-i", image, "-n", containerName, "--yes"}
+	}
+
+	args := slices.Clone(createCommand)
+	args[imageIndex] = image
+	for i := 1; i < imageIndex; i++ {
+		switch {
+		case args[i] == "--name" && i+1 < imageIndex:
+			args[i+1] = containerName
+		case strings.HasPrefix(args[i], "--name="):
+			args[i] = "--name=" + containerName
+		}
+	}
+	return args
+}
+
+// imageNameComponent возвращает имя контейнера в виде, допустимом в имени образа. Имена образов
+// пишутся в нижнем регистре, поэтому имена, различающиеся только регистром, получают разные суффиксы.
+func imageNameComponent(containerName string) string {
+	lower := strings.ToLower(containerName)
+	if lower == containerName {
+		return lower
+	}
+	sum := sha256.Sum256([]byte(containerName))
+	return lower + "-" + hex.EncodeToString(sum[:4])
+}
+
+// parseContainerListLine разбирает строку вывода distrobox ls вида «ID | NAME | STATUS | IMAGE».
+func parseContainerListLine(line string) (ContainerInfo, bool) {
+	parts := strings.Split(line, "|")
+	if len(parts) < 2 {
+		return ContainerInfo{}, false
+	}
+
+	info := ContainerInfo{ID: strings.TrimSpace(parts[0]), ContainerName: strings.TrimSpace(parts[1])}
+	if info.ContainerName == "" {
+		return ContainerInfo{}, false
+	}
+	if len(parts) > 2 {
+		info.State = parseContainerState(parts[2])
+		info.Running = info.State == ContainerStateRunning
+	}
+	if len(parts) > 3 {
+		info.Image = strings.TrimSpace(parts[3])
+	}
+
+	return info, true
+}
+
+// parseContainerState приводит колонку STATUS (например, «Up 2 hours» или «Exited (0) 3 days ago») к состоянию контейнера.
+func parseContainerState(status string) string {
+	status = strings.ToLower(strings.TrimSpace(status))
+	switch {
+	case status == "":
+		return ""
+	case strings.HasPrefix(status, "up"), strings.HasPrefix(status, "running"):
+		return ContainerStateRunning
+	case strings.HasPrefix(status, "exited"), strings.HasPrefix(status, "stopped"):
+		return ContainerStateExited
+	case strings.HasPrefix(status, "created"):
+		return ContainerStateCreated
+	}
+
+	return strings.Fields(status)[0]
+}
+
+// parseContainerStats разбирает JSON вывод podman stats и возвращает ресурсы по имени контейнера.
+func parseContainerStats(output string) (map[string]ContainerResources, error) {
+	var stats []podmanStats
+	if err := json.Unmarshal([]byte(output), &stats); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]ContainerResources, len(stats))
+	for _, s := range stats {
+		result[s.Name] = ContainerResources{
+			CPUPercent:    s.CPUPercent,
+			MemoryUsage:   s.MemUsage,
+			MemoryPercent: s.MemPercent,
+			PIDs:          s.PIDs,
+		}
+	}
+
+	return result, nil
+}
+
+// fillResources заполняет потребление ресурсов для запущенных контейнеров одним вызовом podman stats.
+func (d *DistroAPIService) fillResources(ctx context.Context, containers []ContainerInfo) {
+	var names []string
+	for _, c := range containers {
+		if c.Running {
+			names = append(names, c.ContainerName)
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+
+	args := append([]string{"podman", "stats", "--no-stream", "--format", "json"}, names...)
+	stdout, stderr, err := d.runner.Run(ctx, args, command.WithQuiet())
+	if err != nil {
+		app.Log.Errorf("podman stats failed: %v, stderr: %s", err, stderr)
+		return
+	}
+
+	resources, err := parseContainerStats(stdout)
+	if err != nil {
+		app.Log.Errorf("failed to parse podman stats: %v", err)
+		return
+	}
+
+	for i := range containers {
+		if r, ok := resources[containers[i].ContainerName]; ok {
+			containers[i].Resources = &r
+		}
+	}
+}
+
+// findContainer возвращает запись контейнера из distrobox ls.
+func (d *DistroAPIService) findContainer(ctx context.Context, containerName string) (ContainerInfo, error) {
+	if err := validateContainerName(containerName); err != nil {
+		return ContainerInfo{}, err
+	}
+
+	containers, err := d.GetContainerList(ctx, false)
+	if err != nil {
+		return ContainerInfo{}, fmt.Errorf(app.TL_(ctx, "Failed to get the list of containers: %v"), err)
+	}
+
+	for _, c := range containers {
+		if c.ContainerName == containerName {
+			return c, nil
+		}
+	}
+
+	return ContainerInfo{}, fmt.Errorf(app.TL_(ctx, "Container %s not found"), containerName)
+}
+
+// GetContainerStatus возвращает состояние контейнера и потребление ресурсов, не запуская его.
+func (d *DistroAPIService) GetContainerStatus(ctx context.Context, containerName string) (ContainerInfo, error) {
+	d.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventDistroGetContainerInfo))
+	defer d.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventDistroGetContainerInfo))
+
+	info, err := d.findContainer(ctx, containerName)
+	if err != nil {
+		return ContainerInfo{}, err
+	}
+
+	if osName, ok := normalizeOsName(info.Image); ok {
+		info.OS, info.Active = osName, true
+	}
+
+	containers := []ContainerInfo{info}
+	d.fillResources(ctx, containers)
+
+	return containers[0], nil
+}
+
+// StartContainer запускает контейнер через podman.
+func (d *DistroAPIService) StartContainer(ctx context.Context, containerName string) (ContainerInfo, error) {
+	d.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventDistroStartContainer))
+	defer d.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventDistroStartContainer))
+
+	if _, err := d.findContainer(ctx, containerName); err != nil {
+		return ContainerInfo{}, err
+	}
+
+	if _, stderr, err := d.runner.Run(ctx, []string{"podman", "start", containerName}, command.WithQuiet()); err != nil {
+		return ContainerInfo{}, fmt.Errorf(app.TL_(ctx, "Failed to start container %s: %v, stderr: %s"), containerName, err, strings.TrimSpace(stderr))
+	}
+
+	return d.GetContainerStatus(ctx, containerName)
+}
+
+// StopContainer останавливает контейнер через podman.
+func (d *DistroAPIService) StopContainer(ctx context.Context, containerName string) (ContainerInfo, error) {
+	d.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventDistroStopContainer))
+	defer d.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventDistroStopContainer))
+
+	if _, err := d.findContainer(ctx, containerName); err != nil {
+		return ContainerInfo{}, err
+	}
+
+	if _, stderr, err := d.runner.Run(ctx, []string{"podman", "stop", containerName}, command.WithQuiet()); err != nil {
+		return ContainerInfo{}, fmt.Errorf(app.TL_(ctx, "Failed to stop container %s: %v, stderr: %s"), containerName, err, strings.TrimSpace(stderr))
+	}
+
+	return d.GetContainerStatus(ctx, containerName)
+}
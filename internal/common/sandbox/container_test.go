@@ -0,0 +1,99 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sandbox
+
+import (
+	"testing"
+)
+
+func TestParseContainerListLine(t *testing.T) {
+	tests := []struct {
+		line    string
+		ok      bool
+		name    string
+		state   string
+		running bool
+		image   string
+	}{
+		{"4f2c1a | atomic-alt | Up 2 hours | registry.altlinux.org/sisyphus/base:latest", true, "atomic-alt", ContainerStateRunning, true, "registry.altlinux.org/sisyphus/base:latest"},
+		{"9ab3d0 | arch | Exited (0) 3 days ago | archlinux:latest", true, "arch", ContainerStateExited, false, "archlinux:latest"},
+		{"c0ffee | fresh | Created | ubuntu:latest", true, "fresh", ContainerStateCreated, false, "ubuntu:latest"},
+		{"c0ffee | legacy", true, "legacy", "", false, ""},
+		{"garbage", false, "", "", false, ""},
+		{"id |   | Up", false, "", "", false, ""},
+	}
+
+	for _, tt := range tests {
+		info, ok := parseContainerListLine(tt.line)
+		if ok != tt.ok {
+			t.Errorf("parseContainerListLine(%q) ok = %v, want %v", tt.line, ok, tt.ok)
+			continue
+		}
+		if ok && info.ID == "" {
+			t.Errorf("parseContainerListLine(%q) did not parse container ID", tt.line)
+		}
+		if info.ContainerName != tt.name || info.State != tt.state || info.Running != tt.running || info.Image != tt.image {
+			t.Errorf("parseContainerListLine(%q) = %+v", tt.line, info)
+		}
+	}
+}
+
+func TestParseContainerStats(t *testing.T) {
+	output := `[{"id":"4f2c1a","name":"atomic-alt","cpu_percent":"1.25%","mem_usage":"52.4MB / 16.6GB","mem_percent":"0.32%","pids":"7"}]`
+
+	stats, err := parseContainerStats(output)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	r, ok := stats["atomic-alt"]
+	if !ok {
+		t.Fatalf("expected stats for atomic-alt, got %+v", stats)
+	}
+	if r.CPUPercent != "1.25%" || r.MemoryUsage != "52.4MB / 16.6GB" || r.MemoryPercent != "0.32%" || r.PIDs != "7" {
+		t.Errorf("unexpected resources: %+v", r)
+	}
+
+	if _, err = parseContainerStats("not json"); err == nil {
+		t.Error("expected error for invalid output")
+	}
+}
+
+func TestNormalizeOsName(t *testing.T) {
+	tests := []struct {
+		input  string
+		name   string
+		active bool
+	}{
+		{"altlinux", "ALT Linux", true},
+		{"registry.altlinux.org/sisyphus/base:latest", "ALT Linux", true},
+		{"archlinux:latest", "Arch", true},
+		{"Ubuntu", "Ubuntu", true},
+		{"fedora", "Fedora", true},
+		{"opensuse-tumbleweed", "openSUSE", true},
+		{"docker.io/library/alpine:latest", "Alpine", true},
+		{"void", "Void", true},
+		{"gentoo", "gentoo", false},
+	}
+
+	for _, tt := range tests {
+		name, active := normalizeOsName(tt.input)
+		if name != tt.name || active != tt.active {
+			t.Errorf("normalizeOsName(%q) = %q, %v; want %q, %v", tt.input, name, active, tt.name, tt.active)
+		}
+	}
+}
@@ -44,6 +44,7 @@ type DBDistroPackage struct {
 	Installed   bool   `gorm:"column:installed"`
 	Exporting   bool   `gorm:"column:exporting"`
 	Manager     string `gorm:"column:manager"`
+	ExportPaths string `gorm:"column:export_paths"`
 }
 
 type DistroDBService struct {
@@ -81,7 +82,7 @@ func (s *DistroDBService) db() (*gorm.DB, error) {
 			return nil, fmt.Errorf("error opening GORM with existing db: %w", err)
 		}
 
-		if err = s.realDb.AutoMigrate(&DBDistroPackage{}); err != nil {
+		if err = s.realDb.AutoMigrate(&DBDistroPackage{}, &DBContainerOsInfo{}, &DBContainerSync{}, &DBContainerSnapshot{}); err != nil {
 			return nil, fmt.Errorf("autoMigrate failed: %w", err)
 		}
 	}
@@ -135,7 +136,7 @@ func (s *DistroDBService) SavePackagesToDB(ctx context.Context, containerName st
 	defer s.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventDistroSavePackagesToDB))
 
 	if len(containerName) == 0 {
-		return errors.New(app.T_("The 'container' field cannot be empty when saving packages to the database"))
+		return errors.New(app.TL_(ctx, "The 'container' field cannot be empty when saving packages to the database"))
 	}
 
 	db, err := s.db()
@@ -143,32 +144,40 @@ func (s *DistroDBService) SavePackagesToDB(ctx context.Context, containerName st
 		return err
 	}
 
-	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		if err = tx.Where("container = ?", containerName).Delete(&DBDistroPackage{}).Error; err != nil {
-			return err
-		}
-
-		batchSize := 1000
-		for i := 0; i < len(packages); i += batchSize {
-			end := i + batchSize
-			if end > len(packages) {
-				end = len(packages)
+	err = app.RetryBusy(ctx, func() error {
+		return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err = tx.Where("container = ?", containerName).Delete(&DBDistroPackage{}).Error; err != nil {
+				return err
 			}
-			batch := packages[i:end]
 
-			var dbEntries []DBDistroPackage
-			for _, p := range batch {
-				p.Container = containerName
-				dbEntries = append(dbEntries, p.toDBModel())
+			batchSize := 1000
+			for i := 0; i < len(packages); i += batchSize {
+				end := i + batchSize
+				if end > len(packages) {
+					end = len(packages)
+				}
+				batch := packages[i:end]
+
+				var dbEntries []DBDistroPackage
+				for _, p := range batch {
+					p.Container = containerName
+					dbEntries = append(dbEntries, p.toDBModel())
+				}
+
+				if err := tx.Create(&dbEntries).Error; err != nil {
+					return err
+				}
 			}
 
-			if err := tx.Create(&dbEntries).Error; err != nil {
-				return err
-			}
-		}
-
-		return nil
+			return nil
+		})
 	})
+	if err != nil {
+		return err
+	}
+
+	app.NotifyDatabaseChanged(s.dbManager, app.DatabaseUser)
+	return nil
 }
 
 // DatabaseExist проверяет, есть ли вообще записи в таблице (не пустая ли).
@@ -181,13 +190,13 @@ func (s *DistroDBService) DatabaseExist(ctx context.Context) error {
 	var count int64
 	if err = db.WithContext(ctx).Model(&DBDistroPackage{}).Count(&count).Error; err != nil {
 		if strings.Contains(err.Error(), "no such table") {
-			return errors.New(app.T_("The database does not have any records, it is necessary to create or update any container"))
+			return errors.New(app.TL_(ctx, "The database does not have any records, it is necessary to create or update any container"))
 		}
 		return err
 	}
 
 	if count == 0 {
-		return errors.New(app.T_("The database contains no records, you need to create or update any container"))
+		return errors.New(app.TL_(ctx, "The database contains no records, you need to create or update any container"))
 	}
 	return nil
 }
@@ -208,7 +217,7 @@ func (s *DistroDBService) ContainerDatabaseExist(ctx context.Context, containerN
 	}
 
 	if count == 0 {
-		return fmt.Errorf(app.T_("No records found for container %s"), containerName)
+		return fmt.Errorf(app.TL_(ctx, "No records found for container %s"), containerName)
 	}
 	return nil
 }
@@ -315,13 +324,17 @@ func (s *DistroDBService) UpdatePackageField(ctx context.Context, containerName,
 	}
 
 	if !allowedFields[fieldName] {
-		app.Log.Errorf(app.T_("The field %s cannot be updated."), fieldName)
+		app.Log.Errorf(app.TL_(ctx, "The field %s cannot be updated."), fieldName)
 		return
 	}
 
 	updateMap := map[string]interface{}{
 		fieldName: value,
 	}
+	// Набор файлов пакета меняется при установке и удалении, кеш путей экспорта сбрасывается
+	if fieldName == "installed" {
+		updateMap["export_paths"] = ""
+	}
 
 	db, err := s.db()
 	if err != nil {
@@ -360,11 +373,19 @@ func (s *DistroDBService) DeletePackagesFromContainer(ctx context.Context, conta
 		return err
 	}
 
-	if err = db.WithContext(ctx).
-		Where("container = ?", containerName).
-		Delete(&DBDistroPackage{}).Error; err != nil {
-		return fmt.Errorf(app.T_("Error deleting container records %s: %v"), containerName, err)
+	err = app.RetryBusy(ctx, func() error {
+		return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("container = ?", containerName).Delete(&DBDistroPackage{}).Error; err != nil {
+				return err
+			}
+			return tx.Where("container = ?", containerName).Delete(&DBContainerSync{}).Error
+		})
+	})
+	if err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Error deleting container records %s: %v"), containerName, err)
 	}
+
+	app.NotifyDatabaseChanged(s.dbManager, app.DatabaseUser)
 	return nil
 }
 
@@ -390,7 +411,7 @@ var DistroFilterConfig = &filter.Config{
 		"container":   {DefaultOp: filter.OpEq, Sortable: true, AllowedOps: []filter.Op{filter.OpEq, filter.OpNe, filter.OpLike}, Extra: map[string]any{"type": "STRING", "description": "Container name"}},
 		"installed":   {DefaultOp: filter.OpEq, Sortable: true, AllowedOps: []filter.Op{filter.OpEq, filter.OpNe}, Extra: map[string]any{"type": "BOOL", "description": "Installation status"}},
 		"exporting":   {DefaultOp: filter.OpEq, Sortable: true, AllowedOps: []filter.Op{filter.OpEq, filter.OpNe}, Extra: map[string]any{"type": "BOOL", "description": "Export status"}},
-		"manager":     {DefaultOp: filter.OpEq, Sortable: true, AllowedOps: []filter.Op{filter.OpEq, filter.OpNe}, Extra: map[string]any{"type": "STRING", "description": "Package manager", "choice": []string{"apt-get", "apt", "pacman"}}},
+		"manager":     {DefaultOp: filter.OpEq, Sortable: true, AllowedOps: []filter.Op{filter.OpEq, filter.OpNe}, Extra: map[string]any{"type": "STRING", "description": "Package manager", "choice": []string{"apt-get", "apt", "pacman", "dnf5", "zypper", "apk", "xbps"}}},
 	},
 }
 
@@ -23,6 +23,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"regexp"
 	"slices"
 	"strings"
@@ -33,6 +34,7 @@ import (
 type DistroAPIService struct {
 	runner   command.Runner
 	reporter *reply.Reporter
+	osCache  osInfoCache
 }
 
 // NewDistroAPIService возвращает новый экземпляр DistroAPIService.
@@ -44,9 +46,14 @@ func NewDistroAPIService(runner command.Runner, reporter *reply.Reporter) *Distr
 }
 
 type ContainerInfo struct {
-	OS            string `json:"os"`
-	ContainerName string `json:"name"`
-	Active        bool   `json:"active"`
+	ID            string              `json:"id,omitempty"`
+	OS            string              `json:"os"`
+	ContainerName string              `json:"name"`
+	Active        bool                `json:"active"`
+	Image         string              `json:"image,omitempty"`
+	State         string              `json:"state,omitempty"`
+	Running       bool                `json:"running"`
+	Resources     *ContainerResources `json:"resources,omitempty"`
 }
 
 // GetContainerList получает список контейнеров, а если требуется полная информация (getFullInfo),
@@ -57,7 +64,7 @@ func (d *DistroAPIService) GetContainerList(ctx context.Context, getFullInfo boo
 
 	stdout, stderr, err := d.runner.Run(ctx, []string{"distrobox", "ls"}, command.WithQuiet())
 	if err != nil {
-		return nil, errors.New(app.T_("Failed to retrieve the list of containers: ") + stderr)
+		return nil, errors.New(app.TL_(ctx, "Failed to retrieve the list of containers: ") + stderr)
 	}
 
 	output := strings.TrimSpace(stdout)
@@ -72,44 +79,54 @@ func (d *DistroAPIService) GetContainerList(ctx context.Context, getFullInfo boo
 
 	var containers []ContainerInfo
 
-	var containerNames []string
+	var entries []ContainerInfo
 	for _, line := range lines[1:] {
-		parts := strings.Split(line, "|")
-		if len(parts) < 2 {
-			continue
-		}
-		name := strings.TrimSpace(parts[1])
-		if name != "" {
-			containerNames = append(containerNames, name)
+		if entry, ok := parseContainerListLine(line); ok {
+			entries = append(entries, entry)
 		}
 	}
 
 	if getFullInfo {
+		cached := d.loadOsCache(ctx)
+
 		var wg sync.WaitGroup
 		mu := &sync.Mutex{}
-		for _, name := range containerNames {
+		var fetched []ContainerInfo
+		for _, entry := range entries {
+			if c, ok := cached[entry.ID]; ok {
+				entry.OS, entry.Active = c.OS, c.Active
+				containers = append(containers, entry)
+				continue
+			}
+
 			wg.Add(1)
-			go func(n string) {
+			go func(e ContainerInfo) {
 				defer wg.Done()
-				info, err := d.fetchOsInfo(ctx, n)
-				if err != nil {
-					app.Log.Error(err)
-					info = ContainerInfo{ContainerName: n, OS: "", Active: false}
+				info := e
+				// Остановленный контейнер не запускаем ради os-release, ОС определяется по образу
+				if e.Running {
+					osInfo, err := d.fetchOsInfo(ctx, e.ContainerName)
+					if err != nil {
+						app.Log.Error(err)
+					}
+					info.OS, info.Active = osInfo.OS, osInfo.Active
+				} else if osName, ok := normalizeOsName(e.Image); ok {
+					info.OS, info.Active = osName, true
 				}
 				mu.Lock()
 				containers = append(containers, info)
+				if e.Running && info.OS != "" {
+					fetched = append(fetched, info)
+				}
 				mu.Unlock()
-			}(name)
+			}(entry)
 		}
 		wg.Wait()
+
+		d.saveOsCache(ctx, fetched, entries)
+		d.fillResources(ctx, containers)
 	} else {
-		for _, name := range containerNames {
-			containers = append(containers, ContainerInfo{
-				ContainerName: name,
-				OS:            "",
-				Active:        false,
-			})
-		}
+		containers = entries
 	}
 
 	slices.SortFunc(containers, func(a, b ContainerInfo) int {
@@ -121,7 +138,8 @@ func (d *DistroAPIService) GetContainerList(ctx context.Context, getFullInfo boo
 
 // ExportingApp экспортирует пакет в хост-систему.
 // Принимает отдельные списки для desktop и консольных приложений и обрабатывает каждый тип соответственно.
-func (d *DistroAPIService) ExportingApp(ctx context.Context, containerInfo ContainerInfo, _ string, desktopPaths, consolePaths []string, deleteApp bool) error {
+// Возвращает предупреждения о конфликтах идентификаторов .desktop файлов.
+func (d *DistroAPIService) ExportingApp(ctx context.Context, containerInfo ContainerInfo, _ string, desktopPaths, consolePaths []string, deleteApp bool, opts ExportOptions) ([]string, error) {
 	d.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventDistroExportingApp))
 	defer d.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventDistroExportingApp))
 	// Определяем суффикс: "-d", если deleteApp == true, иначе пустая строка.
@@ -135,11 +153,21 @@ func (d *DistroAPIService) ExportingApp(ctx context.Context, containerInfo Conta
 	}
 	var commands []cmdEntry
 
+	appsDir, errDir := userApplicationsDir()
+	if deleteApp && errDir == nil {
+		// Переименованные без префикса файлы возвращаем к имени, которое ожидает distrobox-export -d
+		for _, path := range desktopPaths {
+			restoreDesktopPrefix(appsDir, containerInfo.ContainerName, filepath.Base(path))
+		}
+	}
+
 	// Обрабатываем desktop приложения
 	for _, path := range desktopPaths {
 		args := []string{"distrobox", "enter", containerInfo.ContainerName, "--", "distrobox-export", "--app", path}
 		if suffix != "" {
 			args = append(args, suffix)
+		} else if opts.Label != "" {
+			args = append(args, "--export-label", opts.Label)
 		}
 		commands = append(commands, cmdEntry{args: args})
 	}
@@ -166,9 +194,9 @@ func (d *DistroAPIService) ExportingApp(ctx context.Context, containerInfo Conta
 				stderrStr := strings.TrimSpace(stderr)
 				cmdStr := strings.Join(args, " ")
 				if stderrStr != "" {
-					errChan <- fmt.Errorf(app.T_("Error executing command %q: %s"), cmdStr, stderrStr)
+					errChan <- fmt.Errorf(app.TL_(ctx, "Error executing command %q: %s"), cmdStr, stderrStr)
 				} else {
-					errChan <- fmt.Errorf(app.T_("Error executing command %q: %v"), cmdStr, err)
+					errChan <- fmt.Errorf(app.TL_(ctx, "Error executing command %q: %v"), cmdStr, err)
 				}
 			}
 		}(cmd.args)
@@ -177,10 +205,20 @@ func (d *DistroAPIService) ExportingApp(ctx context.Context, containerInfo Conta
 	wg.Wait()
 	close(errChan)
 	for err := range errChan {
-		return err
+		return nil, err
 	}
 
-	return nil
+	var warnings []string
+	if !deleteApp && opts.StripPrefix && errDir == nil {
+		for _, path := range desktopPaths {
+			if warning := stripDesktopPrefix(appsDir, hostApplicationsDir, containerInfo.ContainerName, filepath.Base(path)); warning != "" {
+				app.Log.Warning(warning)
+				warnings = append(warnings, warning)
+			}
+		}
+	}
+
+	return warnings, nil
 }
 
 // fetchOsInfo выполняет команду для получения информации об ОС контейнера
@@ -189,9 +227,9 @@ func (d *DistroAPIService) fetchOsInfo(ctx context.Context, containerName string
 	stdout, stderr, err := d.runner.Run(ctx, []string{"distrobox", "enter", containerName, "--", "cat", "/etc/os-release"}, command.WithQuiet())
 	if err != nil {
 		stderrStr := strings.TrimSpace(stderr)
-		errMsg := fmt.Errorf(app.T_("Error getting OS information for container %s: %v"), containerName, err)
+		errMsg := fmt.Errorf(app.TL_(ctx, "Error getting OS information for container %s: %v"), containerName, err)
 		if stderrStr != "" {
-			errMsg = fmt.Errorf(app.T_("Error getting OS information for container %s: %s"), containerName, stderrStr)
+			errMsg = fmt.Errorf(app.TL_(ctx, "Error getting OS information for container %s: %s"), containerName, stderrStr)
 		}
 		return ContainerInfo{ContainerName: containerName, OS: "", Active: false}, errMsg
 	}
@@ -216,21 +254,33 @@ func (d *DistroAPIService) fetchOsInfo(ctx context.Context, containerName string
 	}
 
 	// Приводим имя ОС к нужному формату и определяем активность контейнера
+	osName, active := normalizeOsName(osName)
+
+	return ContainerInfo{ContainerName: containerName, OS: osName, Active: active}, nil
+}
+
+// normalizeOsName приводит имя ОС (или ссылку на образ) к поддерживаемому виду.
+// Второе значение сообщает, поддерживается ли ОС контейнера.
+func normalizeOsName(osName string) (string, bool) {
 	lowerOsName := strings.ToLower(osName)
-	active := false
 	switch {
 	case strings.Contains(lowerOsName, "arch"):
-		osName = "Arch"
-		active = true
+		return "Arch", true
 	case strings.Contains(lowerOsName, "alt"):
-		osName = "ALT Linux"
-		active = true
+		return "ALT Linux", true
 	case strings.Contains(lowerOsName, "ubuntu"):
-		osName = "Ubuntu"
-		active = true
-	}
-
-	return ContainerInfo{ContainerName: containerName, OS: osName, Active: active}, nil
+		return "Ubuntu", true
+	case strings.Contains(lowerOsName, "fedora"):
+		return "Fedora", true
+	case strings.Contains(lowerOsName, "suse"):
+		return "openSUSE", true
+	case strings.Contains(lowerOsName, "alpine"):
+		return "Alpine", true
+	case strings.Contains(lowerOsName, "void"):
+		return "Void", true
+	}
+
+	return osName, false
 }
 
 // GetContainerOsInfo запрос информации о контейнере.
@@ -241,7 +291,7 @@ func (d *DistroAPIService) GetContainerOsInfo(ctx context.Context, containerName
 	// Получаем список контейнеров
 	containers, err := d.GetContainerList(ctx, false)
 	if err != nil {
-		return ContainerInfo{}, fmt.Errorf(app.T_("Failed to get the list of containers: %v"), err)
+		return ContainerInfo{}, fmt.Errorf(app.TL_(ctx, "Failed to get the list of containers: %v"), err)
 	}
 
 	var found bool
@@ -253,14 +303,15 @@ func (d *DistroAPIService) GetContainerOsInfo(ctx context.Context, containerName
 	}
 
 	if !found {
-		return ContainerInfo{}, fmt.Errorf(app.T_("Container %s not found"), containerName)
+		return ContainerInfo{}, fmt.Errorf(app.TL_(ctx, "Container %s not found"), containerName)
 	}
 
 	return d.fetchOsInfo(ctx, containerName)
 }
 
 // CreateContainer создает контейнер, выполняя команду создания, и затем возвращает информацию о контейнере.
-func (d *DistroAPIService) CreateContainer(ctx context.Context, image, containerName string, addPkg string, hook string) (ContainerInfo, error) {
+// Непустой home задаёт отдельный домашний каталог контейнера.
+func (d *DistroAPIService) CreateContainer(ctx context.Context, image, containerName string, addPkg string, hook string, home string) (ContainerInfo, error) {
 	d.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventDistroCreateContainer))
 	defer d.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventDistroCreateContainer))
 
@@ -286,7 +337,7 @@ func (d *DistroAPIService) CreateContainer(ctx context.Context, image, container
 	for _, c := range containers {
 		if c.ContainerName == containerName {
 			return ContainerInfo{ContainerName: containerName, OS: "", Active: false},
-				fmt.Errorf(app.T_("Container already exists: %s"), containerName)
+				fmt.Errorf(app.TL_(ctx, "Container already exists: %s"), containerName)
 		}
 	}
 
@@ -303,15 +354,20 @@ func (d *DistroAPIService) CreateContainer(ctx context.Context, image, container
 		args = append(args, "--init-hooks", hook)
 	}
 
+	// Отдельный домашний каталог изолирует контейнер от файлов пользователя на хосте
+	if home != "" {
+		args = append(args, "--home", home)
+	}
+
 	_, stderr, err := d.runner.Run(ctx, args)
 	if err != nil {
-		app.Log.Errorf(app.T_("Failed to create container %s: %v, stderr: %s"), containerName, err, stderr)
-		return ContainerInfo{}, fmt.Errorf(app.T_("Failed to create container %s: %v"), containerName, stderr)
+		app.Log.Errorf(app.TL_(ctx, "Failed to create container %s: %v, stderr: %s"), containerName, err, stderr)
+		return ContainerInfo{}, fmt.Errorf(app.TL_(ctx, "Failed to create container %s: %v"), containerName, stderr)
 	}
 
 	// Первый enter инициализирует контейнер (установка пакетов, настройка)
 	if _, stderr, err = d.runner.Run(ctx, []string{"distrobox", "enter", containerName, "--", "true"}); err != nil {
-		app.Log.Errorf(app.T_("Failed to initialize container %s: %v, stderr: %s"), containerName, err, stderr)
+		app.Log.Errorf(app.TL_(ctx, "Failed to initialize container %s: %v, stderr: %s"), containerName, err, stderr)
 	}
 
 	return d.GetContainerOsInfo(ctx, containerName)
@@ -328,19 +384,19 @@ func (d *DistroAPIService) RemoveContainer(ctx context.Context, containerName st
 
 	containers, err := d.GetContainerList(ctx, false)
 	if err != nil {
-		return ContainerInfo{}, fmt.Errorf(app.T_("Failed to get the list of containers: %v"), err)
+		return ContainerInfo{}, fmt.Errorf(app.TL_(ctx, "Failed to get the list of containers: %v"), err)
 	}
 
 	found := slices.ContainsFunc(containers, func(c ContainerInfo) bool {
 		return c.ContainerName == containerName
 	})
 	if !found {
-		return ContainerInfo{}, fmt.Errorf(app.T_("Container %s not found"), containerName)
+		return ContainerInfo{}, fmt.Errorf(app.TL_(ctx, "Container %s not found"), containerName)
 	}
 
 	_, stderr, errRm := d.runner.Run(ctx, []string{"distrobox", "rm", "--yes", "--force", containerName})
 	if errRm != nil {
-		return ContainerInfo{}, fmt.Errorf(app.T_("Failed to delete container %s: %v, stderr: %s"), containerName, errRm, stderr)
+		return ContainerInfo{}, fmt.Errorf(app.TL_(ctx, "Failed to delete container %s: %v, stderr: %s"), containerName, errRm, stderr)
 	}
 
 	return ContainerInfo{ContainerName: containerName}, nil
@@ -0,0 +1,170 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sandbox
+
+import (
+	"apm/internal/common/app"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// hostApplicationsDir каталог .desktop файлов хост-системы
+const hostApplicationsDir = "/usr/share/applications"
+
+// ExportOptions настройки экспорта приложений на хост
+type ExportOptions struct {
+	// Label суффикс имени приложения в меню вместо стандартного «(on контейнер)»
+	Label string
+	// StripPrefix убирает префикс «контейнер-» из имени .desktop файла, чтобы идентификатор
+	// совпадал с исходным и окружение рабочего стола находило иконку приложения
+	StripPrefix bool
+}
+
+var (
+	// exportDesktopDirs каталоги .desktop файлов, экспортируемых на хост
+	exportDesktopDirs = []string{"/usr/share/applications", "/usr/local/share/applications"}
+	// exportBinaryDirs каталоги исполняемых файлов, экспортируемых на хост
+	exportBinaryDirs = []string{"/usr/bin", "/usr/sbin", "/usr/games", "/usr/local/bin"}
+)
+
+// splitExportPaths отбирает из списка файлов пакета .desktop файлы и исполняемые файлы для экспорта
+func splitExportPaths(files []string) (desktopPaths, consolePaths []string) {
+	desktopPaths, consolePaths = []string{}, []string{}
+	for _, file := range files {
+		dir := path.Dir(file)
+		switch {
+		case strings.HasSuffix(file, ".desktop") && hasDirPrefix(file, exportDesktopDirs):
+			desktopPaths = append(desktopPaths, file)
+		case slices.Contains(exportBinaryDirs, dir):
+			consolePaths = append(consolePaths, file)
+		}
+	}
+	return desktopPaths, consolePaths
+}
+
+// hasDirPrefix проверяет, что файл лежит в одном из каталогов или их подкаталогах
+func hasDirPrefix(file string, dirs []string) bool {
+	for _, dir := range dirs {
+		if strings.HasPrefix(file, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// MainExportPaths оставляет только основной файл пакета: .desktop файл, имя которого совпадает
+// с именем пакета (или первый найденный), а для консольных пакетов — одноимённый исполняемый файл.
+func MainExportPaths(packageName string, desktopPaths, consolePaths []string) ([]string, []string) {
+	if len(desktopPaths) > 0 {
+		return []string{pickMain(packageName, desktopPaths, ".desktop")}, []string{}
+	}
+	if len(consolePaths) > 0 {
+		return []string{}, []string{pickMain(packageName, consolePaths, "")}
+	}
+	return desktopPaths, consolePaths
+}
+
+// pickMain выбирает путь, базовое имя которого совпадает с пакетом. Поддерживаются
+// имена в обратной DNS-нотации вроде org.gnome.Calculator.desktop.
+func pickMain(packageName string, paths []string, suffix string) string {
+	name := strings.ToLower(packageName)
+	for _, p := range paths {
+		base := strings.ToLower(strings.TrimSuffix(path.Base(p), suffix))
+		if base == name || strings.HasSuffix(base, "."+name) {
+			return p
+		}
+	}
+	return paths[0]
+}
+
+// userApplicationsDir возвращает каталог пользовательских .desktop файлов
+func userApplicationsDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".local", "share", "applications"), nil
+}
+
+// stripDesktopPrefix переименовывает экспортированный «контейнер-имя.desktop» в «имя.desktop».
+// Если такой идентификатор уже занят приложением хоста или другого контейнера, файл остаётся
+// с префиксом и возвращается предупреждение.
+func stripDesktopPrefix(appsDir, hostDir, containerName, desktopName string) string {
+	exported := filepath.Join(appsDir, containerName+"-"+desktopName)
+	if _, err := os.Stat(exported); err != nil {
+		return ""
+	}
+
+	target := filepath.Join(appsDir, desktopName)
+	if _, err := os.Stat(filepath.Join(hostDir, desktopName)); err == nil {
+		return fmt.Sprintf(app.T_("Desktop ID %s is already used by a host application, keeping %s"),
+			desktopName, filepath.Base(exported))
+	}
+	if _, err := os.Stat(target); err == nil && !isDesktopFileForContainer(target, containerName) {
+		owner := desktopFileContainer(target)
+		if owner == "" {
+			return fmt.Sprintf(app.T_("Desktop ID %s is already used by a user application, keeping %s"),
+				desktopName, filepath.Base(exported))
+		}
+		return fmt.Sprintf(app.T_("Desktop ID %s is already exported from container %s, keeping %s"),
+			desktopName, owner, filepath.Base(exported))
+	}
+
+	if err := os.Rename(exported, target); err != nil {
+		return fmt.Sprintf(app.T_("Failed to rename %s: %v"), exported, err)
+	}
+	return ""
+}
+
+// restoreDesktopPrefix возвращает файлу «имя.desktop» контейнера исходное имя с префиксом
+func restoreDesktopPrefix(appsDir, containerName, desktopName string) {
+	stripped := filepath.Join(appsDir, desktopName)
+	exported := filepath.Join(appsDir, containerName+"-"+desktopName)
+	if _, err := os.Stat(exported); !errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if _, err := os.Stat(stripped); err != nil {
+		return
+	}
+	if !isDesktopFileForContainer(stripped, containerName) {
+		return
+	}
+	if err := os.Rename(stripped, exported); err != nil {
+		app.Log.Debugf("failed to restore desktop file %s: %v", stripped, err)
+	}
+}
+
+// desktopFileContainer возвращает имя контейнера, из которого экспортирован .desktop файл
+func desktopFileContainer(filePath string) string {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return ""
+	}
+
+	fields := strings.Fields(string(data))
+	for i := 0; i+2 < len(fields); i++ {
+		if strings.HasSuffix(fields[i], "distrobox-enter") && fields[i+1] == "-n" {
+			return fields[i+2]
+		}
+	}
+	return ""
+}
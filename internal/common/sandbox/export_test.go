@@ -0,0 +1,133 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sandbox
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestSplitExportPaths(t *testing.T) {
+	files := []string{
+		"/usr/bin/gimp",
+		"/usr/bin/gimp-console",
+		"/usr/sbin/gimpd",
+		"/usr/bin/helpers/tool",
+		"/usr/lib/gimp/2.0/plug-ins/script-fu",
+		"/usr/share/applications/gimp.desktop",
+		"/usr/share/applications/kde/gimp-extra.desktop",
+		"/usr/share/applications/mimeinfo.cache",
+		"/usr/share/gimp/usr/bin/not-a-binary",
+	}
+
+	desktop, console := splitExportPaths(files)
+	if !slices.Equal(desktop, []string{"/usr/share/applications/gimp.desktop", "/usr/share/applications/kde/gimp-extra.desktop"}) {
+		t.Errorf("unexpected desktop paths: %v", desktop)
+	}
+	if !slices.Equal(console, []string{"/usr/bin/gimp", "/usr/bin/gimp-console", "/usr/sbin/gimpd"}) {
+		t.Errorf("unexpected console paths: %v", console)
+	}
+
+	desktop, console = splitExportPaths(nil)
+	if desktop == nil || console == nil {
+		t.Error("expected empty slices for package without files")
+	}
+}
+
+func TestMainExportPaths(t *testing.T) {
+	desktop, console := MainExportPaths("calculator",
+		[]string{"/usr/share/applications/org.gnome.Extra.desktop", "/usr/share/applications/org.gnome.Calculator.desktop"},
+		[]string{"/usr/bin/gnome-calculator"})
+	if !slices.Equal(desktop, []string{"/usr/share/applications/org.gnome.Calculator.desktop"}) || len(console) != 0 {
+		t.Errorf("expected main desktop file only, got %v %v", desktop, console)
+	}
+
+	desktop, console = MainExportPaths("ripgrep", []string{}, []string{"/usr/bin/rg", "/usr/bin/ripgrep"})
+	if len(desktop) != 0 || !slices.Equal(console, []string{"/usr/bin/ripgrep"}) {
+		t.Errorf("expected matching binary, got %v %v", desktop, console)
+	}
+
+	desktop, console = MainExportPaths("fd-find", []string{}, []string{"/usr/bin/fd"})
+	if !slices.Equal(console, []string{"/usr/bin/fd"}) || len(desktop) != 0 {
+		t.Errorf("expected first binary as fallback, got %v %v", desktop, console)
+	}
+}
+
+func writeDesktop(t *testing.T, path, container string) {
+	t.Helper()
+	content := "[Desktop Entry]\nName=Gimp\nExec=/usr/bin/distrobox-enter -n " + container + " -- gimp\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStripDesktopPrefix(t *testing.T) {
+	appsDir := t.TempDir()
+	hostDir := t.TempDir()
+
+	writeDesktop(t, filepath.Join(appsDir, "atomic-alt-gimp.desktop"), "atomic-alt")
+	if warning := stripDesktopPrefix(appsDir, hostDir, "atomic-alt", "gimp.desktop"); warning != "" {
+		t.Fatalf("unexpected warning: %s", warning)
+	}
+	if _, err := os.Stat(filepath.Join(appsDir, "gimp.desktop")); err != nil {
+		t.Fatalf("expected stripped desktop file: %v", err)
+	}
+
+	restoreDesktopPrefix(appsDir, "atomic-alt", "gimp.desktop")
+	if _, err := os.Stat(filepath.Join(appsDir, "atomic-alt-gimp.desktop")); err != nil {
+		t.Fatalf("expected prefix to be restored: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(appsDir, "gimp.desktop")); !os.IsNotExist(err) {
+		t.Errorf("expected stripped desktop file to be removed")
+	}
+}
+
+func TestStripDesktopPrefixConflicts(t *testing.T) {
+	t.Run("other container", func(t *testing.T) {
+		appsDir := t.TempDir()
+		writeDesktop(t, filepath.Join(appsDir, "gimp.desktop"), "fedora")
+		writeDesktop(t, filepath.Join(appsDir, "atomic-alt-gimp.desktop"), "atomic-alt")
+
+		if warning := stripDesktopPrefix(appsDir, t.TempDir(), "atomic-alt", "gimp.desktop"); warning == "" {
+			t.Fatal("expected conflict warning")
+		}
+		if desktopFileContainer(filepath.Join(appsDir, "gimp.desktop")) != "fedora" {
+			t.Errorf("expected desktop file of other container to stay untouched")
+		}
+		if _, err := os.Stat(filepath.Join(appsDir, "atomic-alt-gimp.desktop")); err != nil {
+			t.Errorf("expected prefixed desktop file to be kept: %v", err)
+		}
+	})
+
+	t.Run("host application", func(t *testing.T) {
+		appsDir := t.TempDir()
+		hostDir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(hostDir, "gimp.desktop"), []byte("[Desktop Entry]\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		writeDesktop(t, filepath.Join(appsDir, "atomic-alt-gimp.desktop"), "atomic-alt")
+
+		if warning := stripDesktopPrefix(appsDir, hostDir, "atomic-alt", "gimp.desktop"); warning == "" {
+			t.Fatal("expected conflict warning")
+		}
+		if _, err := os.Stat(filepath.Join(appsDir, "gimp.desktop")); !os.IsNotExist(err) {
+			t.Errorf("expected desktop file not to be renamed")
+		}
+	})
+}
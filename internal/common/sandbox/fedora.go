@@ -0,0 +1,139 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sandbox
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/command"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// FedoraProvider реализует интерфейс PackageProvider для Fedora (dnf5)
+type FedoraProvider struct {
+	servicePackage *PackageService
+	runner         command.Runner
+}
+
+// NewFedoraProvider возвращает новый экземпляр FedoraProvider.
+func NewFedoraProvider(servicePackage *PackageService, runner command.Runner) *FedoraProvider {
+	return &FedoraProvider{
+		servicePackage: servicePackage,
+		runner:         runner,
+	}
+}
+
+// GetPackages обновляет метаданные репозиториев и получает список пакетов через dnf5 repoquery.
+func (p *FedoraProvider) GetPackages(ctx context.Context, containerInfo ContainerInfo) ([]PackageInfo, error) {
+	if _, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "sudo", "dnf5", "makecache"}, command.WithQuiet()); err != nil {
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to update package database: %v, stderr: %s"), err, stderr)
+	}
+
+	stdout, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--",
+		"dnf5", "repoquery", "--available", "--latest-limit=1", "--queryformat", "%{name}|%{evr}|%{summary}\\n"},
+		command.WithEnv("LC_ALL=C"), command.WithQuiet())
+	if err != nil {
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to search packages (dnf5 repoquery): %v, stderr: %s"), err, stderr)
+	}
+
+	exportingPackages, err := p.servicePackage.GetAllApplicationsByContainer(ctx, containerInfo)
+	if err != nil {
+		app.Log.Error(app.TL_(ctx, "Error retrieving exporting packages: "), err)
+		exportingPackages = []string{}
+	}
+
+	installed, err := rpmInstalledPackages(ctx, p.runner, containerInfo)
+	if err != nil {
+		app.Log.Error(app.TL_(ctx, "Error retrieving installed packages: "), err)
+		installed = map[string]bool{}
+	}
+
+	packages := parseDnfRepoquery(stdout)
+	markPackages(packages, containerInfo, "dnf5", installed, exportingPackages)
+	return packages, nil
+}
+
+// parseDnfRepoquery разбирает строки вида "name|evr|summary". Пакеты разных архитектур схлопываются по имени.
+func parseDnfRepoquery(output string) []PackageInfo {
+	var packages []PackageInfo
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), "|", 3)
+		if len(parts) != 3 || parts[0] == "" || seen[parts[0]] {
+			continue
+		}
+		seen[parts[0]] = true
+		packages = append(packages, PackageInfo{
+			Name:        parts[0],
+			Version:     parts[1],
+			Description: strings.TrimSpace(parts[2]),
+		})
+	}
+	return packages
+}
+
+// RemovePackage удаляет указанный пакет через dnf5 remove.
+func (p *FedoraProvider) RemovePackage(ctx context.Context, containerInfo ContainerInfo, packageName string) error {
+	if err := validatePackageName(packageName); err != nil {
+		return err
+	}
+	_, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "sudo", "dnf5", "remove", "-y", packageName})
+	if err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Failed to remove package %s: %v, stderr: %s"), packageName, err, stderr)
+	}
+	return nil
+}
+
+// InstallPackage устанавливает указанный пакет через dnf5 install.
+func (p *FedoraProvider) InstallPackage(ctx context.Context, containerInfo ContainerInfo, packageName string) error {
+	if err := validatePackageName(packageName); err != nil {
+		return err
+	}
+	_, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "sudo", "dnf5", "install", "-y", packageName})
+	if err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Failed to install package %s: %v, stderr: %s"), packageName, err, stderr)
+	}
+	return nil
+}
+
+// GetPackageOwner определяет пакет-владельца файла через rpm -qf.
+func (p *FedoraProvider) GetPackageOwner(ctx context.Context, containerInfo ContainerInfo, filePath string) (string, error) {
+	return rpmPackageOwner(ctx, p.runner, containerInfo, filePath)
+}
+
+// GetPathByPackageName возвращает список путей для файла пакета, найденных через rpm -ql.
+func (p *FedoraProvider) GetPathByPackageName(ctx context.Context, containerInfo ContainerInfo, packageName, filePath string) ([]string, error) {
+	return rpmPackagePaths(ctx, p.runner, containerInfo, packageName, filePath)
+}
+
+// ProvisionPackages возвращает пакеты локалей и шрифтов. В Fedora локали поставляются собранными в glibc-langpack.
+func (p *FedoraProvider) ProvisionPackages(_ ContainerInfo, locale string, fonts bool) []string {
+	var packages []string
+	if locale != "" {
+		packages = append(packages, "glibc-langpack-"+localeLanguage(locale))
+	}
+	if fonts {
+		packages = append(packages, "dejavu-sans-fonts", "liberation-fonts", "google-noto-sans-fonts")
+	}
+	return packages
+}
+
+// GenerateLocale ничего не делает: glibc-langpack содержит локали языка в собранном виде.
+func (p *FedoraProvider) GenerateLocale(_ context.Context, _ ContainerInfo, _ string) error {
+	return nil
+}
@@ -0,0 +1,86 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sandbox
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/command"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var localeRegex = regexp.MustCompile(`^[a-z]{2,3}_[A-Z]{2}(\.[A-Za-z0-9-]+)?(@[a-z]+)?$`)
+
+// ProvisionResult описывает, что было сделано при подготовке контейнера.
+type ProvisionResult struct {
+	Locale   string   `json:"locale,omitempty"`
+	Fonts    bool     `json:"fonts"`
+	Packages []string `json:"packages"`
+}
+
+// NormalizeLocale проверяет имя локали и дополняет его кодировкой UTF-8, если она не указана: ru_RU -> ru_RU.UTF-8
+func NormalizeLocale(locale string) (string, error) {
+	locale = strings.TrimSpace(locale)
+	if !localeRegex.MatchString(locale) {
+		return "", fmt.Errorf(app.T_("Invalid locale: %q, expected format like ru_RU.UTF-8"), locale)
+	}
+	if !strings.Contains(locale, ".") {
+		base, modifier, found := strings.Cut(locale, "@")
+		locale = base + ".UTF-8"
+		if found {
+			locale += "@" + modifier
+		}
+	}
+	return locale, nil
+}
+
+// localeLanguage возвращает код языка локали: ru_RU.UTF-8 -> ru
+func localeLanguage(locale string) string {
+	lang, _, _ := strings.Cut(locale, "_")
+	return lang
+}
+
+// localeGenLine возвращает строку для /etc/locale.gen: ru_RU.UTF-8 -> "ru_RU.UTF-8 UTF-8"
+func localeGenLine(locale string) string {
+	charset := "UTF-8"
+	if _, rest, ok := strings.Cut(locale, "."); ok {
+		charset, _, _ = strings.Cut(rest, "@")
+	}
+	return locale + " " + charset
+}
+
+// enableLocaleGen включает локаль в /etc/locale.gen, раскомментировав строку или дописав её в конец.
+func enableLocaleGen(ctx context.Context, runner command.Runner, containerInfo ContainerInfo, locale string) error {
+	return enableLocaleIn(ctx, runner, containerInfo, locale, "/etc/locale.gen")
+}
+
+// enableLocaleIn включает локаль в файле со списком локалей в формате locale.gen.
+func enableLocaleIn(ctx context.Context, runner command.Runner, containerInfo ContainerInfo, locale, file string) error {
+	line := localeGenLine(locale)
+	pattern := regexp.QuoteMeta(line)
+	script := fmt.Sprintf(
+		"sed -i 's/^#[[:space:]]*%s/%s/' %s && (grep -q '^%s' %s || echo '%s' >> %s)",
+		pattern, line, file, pattern, file, line, file)
+
+	_, stderr, err := runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "sudo", "sh", "-c", script})
+	if err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Failed to enable locale %s: %v, stderr: %s"), locale, err, stderr)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sandbox
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalizeLocale(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "ru_RU.UTF-8", want: "ru_RU.UTF-8"},
+		{input: " ru_RU ", want: "ru_RU.UTF-8"},
+		{input: "be_BY@latin", want: "be_BY.UTF-8@latin"},
+		{input: "ru_RU.KOI8-R", want: "ru_RU.KOI8-R"},
+		{input: "russian", wantErr: true},
+		{input: "ru_RU.UTF-8' >> /etc/passwd", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeLocale(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("NormalizeLocale(%q) expected error", tt.input)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("NormalizeLocale(%q) = %q, %v; want %q", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func TestLocaleGenLine(t *testing.T) {
+	if got := localeGenLine("ru_RU.UTF-8"); got != "ru_RU.UTF-8 UTF-8" {
+		t.Errorf("unexpected line %q", got)
+	}
+	if got := localeGenLine("be_BY.UTF-8@latin"); got != "be_BY.UTF-8@latin UTF-8" {
+		t.Errorf("unexpected line %q", got)
+	}
+}
+
+func TestProvisionPackages(t *testing.T) {
+	ubuntu := NewUbuntuProvider(nil, nil)
+	if pkgs := ubuntu.ProvisionPackages(ContainerInfo{OS: "Ubuntu 24.04"}, "ru_RU.UTF-8", false); !slices.Contains(pkgs, "language-pack-ru") {
+		t.Errorf("expected language pack on Ubuntu, got %v", pkgs)
+	}
+	if pkgs := ubuntu.ProvisionPackages(ContainerInfo{OS: "Debian GNU/Linux"}, "ru_RU.UTF-8", false); slices.Contains(pkgs, "language-pack-ru") {
+		t.Errorf("unexpected language pack on Debian: %v", pkgs)
+	}
+
+	arch := NewArchProvider(nil, nil)
+	if pkgs := arch.ProvisionPackages(ContainerInfo{OS: "Arch Linux"}, "ru_RU.UTF-8", false); len(pkgs) != 0 {
+		t.Errorf("expected no packages for locale on Arch, got %v", pkgs)
+	}
+	if pkgs := arch.ProvisionPackages(ContainerInfo{OS: "Arch Linux"}, "", true); len(pkgs) == 0 {
+		t.Error("expected font packages on Arch")
+	}
+
+	alt := NewAltProvider(nil, nil)
+	if pkgs := alt.ProvisionPackages(ContainerInfo{OS: "ALT"}, "ru_RU.UTF-8", true); !slices.Contains(pkgs, "glibc-locales") {
+		t.Errorf("expected glibc-locales on ALT, got %v", pkgs)
+	}
+}
@@ -0,0 +1,155 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sandbox
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/command"
+	"context"
+	"encoding/xml"
+	"fmt"
+)
+
+// OpenSUSEProvider реализует интерфейс PackageProvider для openSUSE (zypper)
+type OpenSUSEProvider struct {
+	servicePackage *PackageService
+	runner         command.Runner
+}
+
+// NewOpenSUSEProvider возвращает новый экземпляр OpenSUSEProvider.
+func NewOpenSUSEProvider(servicePackage *PackageService, runner command.Runner) *OpenSUSEProvider {
+	return &OpenSUSEProvider{
+		servicePackage: servicePackage,
+		runner:         runner,
+	}
+}
+
+// zypperSearchResult XML-вывод zypper search
+type zypperSearchResult struct {
+	Solvables []struct {
+		Name    string `xml:"name,attr"`
+		Status  string `xml:"status,attr"`
+		Summary string `xml:"summary,attr"`
+		Edition string `xml:"edition,attr"`
+	} `xml:"search-result>solvable-list>solvable"`
+}
+
+// GetPackages обновляет репозитории и получает список пакетов через zypper search в формате XML.
+func (p *OpenSUSEProvider) GetPackages(ctx context.Context, containerInfo ContainerInfo) ([]PackageInfo, error) {
+	if _, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "sudo", "zypper", "--non-interactive", "refresh"}, command.WithQuiet()); err != nil {
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to update package database: %v, stderr: %s"), err, stderr)
+	}
+
+	stdout, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--",
+		"zypper", "--non-interactive", "--no-refresh", "--xmlout", "search", "--details", "--type", "package"},
+		command.WithEnv("LC_ALL=C"), command.WithQuiet())
+	if err != nil {
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to search packages (zypper search): %v, stderr: %s"), err, stderr)
+	}
+
+	exportingPackages, err := p.servicePackage.GetAllApplicationsByContainer(ctx, containerInfo)
+	if err != nil {
+		app.Log.Error(app.TL_(ctx, "Error retrieving exporting packages: "), err)
+		exportingPackages = []string{}
+	}
+
+	packages, installed, err := parseZypperSearch(stdout)
+	if err != nil {
+		return nil, err
+	}
+	markPackages(packages, containerInfo, "zypper", installed, exportingPackages)
+	return packages, nil
+}
+
+// parseZypperSearch разбирает XML-вывод zypper search --details. Для пакета берётся первая версия,
+// статус installed любой из версий отмечает пакет установленным.
+func parseZypperSearch(output string) ([]PackageInfo, map[string]bool, error) {
+	var result zypperSearchResult
+	if err := xml.Unmarshal([]byte(output), &result); err != nil {
+		return nil, nil, fmt.Errorf(app.T_("Failed to parse zypper output: %v"), err)
+	}
+
+	var packages []PackageInfo
+	installed := make(map[string]bool)
+	seen := make(map[string]bool)
+	for _, s := range result.Solvables {
+		if s.Status == "installed" {
+			installed[s.Name] = true
+		}
+		if s.Name == "" || seen[s.Name] {
+			continue
+		}
+		seen[s.Name] = true
+		packages = append(packages, PackageInfo{
+			Name:        s.Name,
+			Version:     s.Edition,
+			Description: s.Summary,
+		})
+	}
+	return packages, installed, nil
+}
+
+// RemovePackage удаляет указанный пакет через zypper remove.
+func (p *OpenSUSEProvider) RemovePackage(ctx context.Context, containerInfo ContainerInfo, packageName string) error {
+	if err := validatePackageName(packageName); err != nil {
+		return err
+	}
+	_, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "sudo", "zypper", "--non-interactive", "remove", packageName})
+	if err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Failed to remove package %s: %v, stderr: %s"), packageName, err, stderr)
+	}
+	return nil
+}
+
+// InstallPackage устанавливает указанный пакет через zypper install.
+func (p *OpenSUSEProvider) InstallPackage(ctx context.Context, containerInfo ContainerInfo, packageName string) error {
+	if err := validatePackageName(packageName); err != nil {
+		return err
+	}
+	_, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "sudo", "zypper", "--non-interactive", "install", packageName})
+	if err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Failed to install package %s: %v, stderr: %s"), packageName, err, stderr)
+	}
+	return nil
+}
+
+// GetPackageOwner определяет пакет-владельца файла через rpm -qf.
+func (p *OpenSUSEProvider) GetPackageOwner(ctx context.Context, containerInfo ContainerInfo, filePath string) (string, error) {
+	return rpmPackageOwner(ctx, p.runner, containerInfo, filePath)
+}
+
+// GetPathByPackageName возвращает список путей для файла пакета, найденных через rpm -ql.
+func (p *OpenSUSEProvider) GetPathByPackageName(ctx context.Context, containerInfo ContainerInfo, packageName, filePath string) ([]string, error) {
+	return rpmPackagePaths(ctx, p.runner, containerInfo, packageName, filePath)
+}
+
+// ProvisionPackages возвращает пакеты локалей и шрифтов. В openSUSE локали собраны в glibc-locale.
+func (p *OpenSUSEProvider) ProvisionPackages(_ ContainerInfo, locale string, fonts bool) []string {
+	var packages []string
+	if locale != "" {
+		packages = append(packages, "glibc-locale")
+	}
+	if fonts {
+		packages = append(packages, "dejavu-fonts", "liberation-fonts", "google-noto-sans-fonts")
+	}
+	return packages
+}
+
+// GenerateLocale ничего не делает: glibc-locale содержит все локали в собранном виде.
+func (p *OpenSUSEProvider) GenerateLocale(_ context.Context, _ ContainerInfo, _ string) error {
+	return nil
+}
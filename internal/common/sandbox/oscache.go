@@ -0,0 +1,162 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sandbox
+
+import (
+	"apm/internal/common/app"
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// containerOsCacheTTL время жизни сведений об ОС контейнера в кеше.
+// Кеш привязан к ID контейнера, поэтому пересозданный контейнер опрашивается заново.
+const containerOsCacheTTL = 24 * time.Hour
+
+// osInfoCache хранилище сведений об ОС контейнеров
+type osInfoCache interface {
+	GetContainerOsCache(ctx context.Context, ttl time.Duration) (map[string]ContainerInfo, error)
+	SaveContainerOsCache(ctx context.Context, containers []ContainerInfo, existing []string) error
+}
+
+// DBContainerOsInfo запись кеша ОС контейнера
+type DBContainerOsInfo struct {
+	ID        string `gorm:"column:id;primaryKey"`
+	OS        string `gorm:"column:os"`
+	Active    bool   `gorm:"column:active"`
+	UpdatedAt int64  `gorm:"column:updated_at"`
+}
+
+// TableName задаёт имя таблицы.
+func (DBContainerOsInfo) TableName() string {
+	return "distrobox_os_cache"
+}
+
+// SetOsCache подключает кеш сведений об ОС, чтобы GetContainerList не входил в каждый контейнер.
+func (d *DistroAPIService) SetOsCache(cache osInfoCache) {
+	d.osCache = cache
+}
+
+// loadOsCache возвращает актуальные записи кеша по ID контейнера. Ошибка кеша не прерывает получение списка.
+func (d *DistroAPIService) loadOsCache(ctx context.Context) map[string]ContainerInfo {
+	if d.osCache == nil {
+		return nil
+	}
+
+	cached, err := d.osCache.GetContainerOsCache(ctx, containerOsCacheTTL)
+	if err != nil {
+		app.Log.Debugf("failed to read container OS cache: %v", err)
+		return nil
+	}
+	return cached
+}
+
+// saveOsCache сохраняет полученные сведения и удаляет записи исчезнувших контейнеров
+func (d *DistroAPIService) saveOsCache(ctx context.Context, fetched []ContainerInfo, entries []ContainerInfo) {
+	if d.osCache == nil {
+		return
+	}
+
+	existing := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.ID != "" {
+			existing = append(existing, e.ID)
+		}
+	}
+
+	if err := d.osCache.SaveContainerOsCache(ctx, fetched, existing); err != nil {
+		app.Log.Debugf("failed to save container OS cache: %v", err)
+	}
+}
+
+// GetContainerOsCache возвращает записи кеша не старше ttl, ключ - ID контейнера.
+func (s *DistroDBService) GetContainerOsCache(ctx context.Context, ttl time.Duration) (map[string]ContainerInfo, error) {
+	db, err := s.db()
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []DBContainerOsInfo
+	err = db.WithContext(ctx).
+		Where("updated_at >= ?", time.Now().Add(-ttl).Unix()).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]ContainerInfo, len(rows))
+	for _, row := range rows {
+		result[row.ID] = ContainerInfo{ID: row.ID, OS: row.OS, Active: row.Active}
+	}
+	return result, nil
+}
+
+// SaveContainerOsCache сохраняет сведения об ОС контейнеров и удаляет записи контейнеров,
+// ID которых нет в existing.
+func (s *DistroDBService) SaveContainerOsCache(ctx context.Context, containers []ContainerInfo, existing []string) error {
+	db, err := s.db()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+	rows := make([]DBContainerOsInfo, 0, len(containers))
+	for _, c := range containers {
+		if c.ID == "" {
+			continue
+		}
+		rows = append(rows, DBContainerOsInfo{ID: c.ID, OS: c.OS, Active: c.Active, UpdatedAt: now})
+	}
+
+	return app.RetryBusy(ctx, func() error {
+		return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			stale := tx.Model(&DBContainerOsInfo{})
+			if len(existing) > 0 {
+				stale = stale.Where("id NOT IN ?", existing)
+			} else {
+				stale = stale.Where("1 = 1")
+			}
+			if err := stale.Delete(&DBContainerOsInfo{}).Error; err != nil {
+				return err
+			}
+
+			if len(rows) == 0 {
+				return nil
+			}
+			return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&rows).Error
+		})
+	})
+}
+
+// ClearContainerOsCache очищает кеш сведений об ОС контейнеров.
+func (s *DistroDBService) ClearContainerOsCache(ctx context.Context) error {
+	db, err := s.db()
+	if err != nil {
+		return err
+	}
+
+	err = app.RetryBusy(ctx, func() error {
+		return db.WithContext(ctx).Where("1 = 1").Delete(&DBContainerOsInfo{}).Error
+	})
+	if err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Failed to clear container cache: %v"), err)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sandbox
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/command"
+	"apm/internal/common/reply"
+	"apm/internal/common/testutil"
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type mockListRunner struct {
+	mu    sync.Mutex
+	calls [][]string
+}
+
+func (m *mockListRunner) Run(_ context.Context, args []string, _ ...command.Option) (string, string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, args)
+
+	switch {
+	case slices.Equal(args, []string{"distrobox", "ls"}):
+		return "ID | NAME | STATUS | IMAGE\n" +
+			"aaa | cached | Up 2 hours | docker.io/library/archlinux:latest\n" +
+			"bbb | fresh | Up 1 hour | registry.altlinux.org/sisyphus/base:latest\n", "", nil
+	case len(args) > 2 && args[1] == "enter":
+		return "NAME=\"ALT Sisyphus\"\nID=altlinux\n", "", nil
+	}
+	return "[]", "", nil
+}
+
+func (m *mockListRunner) entered() []string {
+	var names []string
+	for _, call := range m.calls {
+		if len(call) > 2 && call[1] == "enter" {
+			names = append(names, call[2])
+		}
+	}
+	return names
+}
+
+type mockOsCache struct {
+	entries  map[string]ContainerInfo
+	saved    []ContainerInfo
+	existing []string
+}
+
+func (m *mockOsCache) GetContainerOsCache(_ context.Context, _ time.Duration) (map[string]ContainerInfo, error) {
+	return m.entries, nil
+}
+
+func (m *mockOsCache) SaveContainerOsCache(_ context.Context, containers []ContainerInfo, existing []string) error {
+	m.saved = containers
+	m.existing = existing
+	return nil
+}
+
+// testReporter возвращает Reporter, который не выводит события в терминал
+func testReporter() *reply.Reporter {
+	return reply.NewReporter(&app.Config{
+		ConfigManager: &testutil.MockConfigManager{Config: &app.Configuration{Format: app.FormatDBus}},
+		DBusManager:   app.NewDBusManager(),
+	})
+}
+
+func TestGetContainerListUsesOsCache(t *testing.T) {
+	runner := &mockListRunner{}
+	cache := &mockOsCache{entries: map[string]ContainerInfo{
+		"aaa": {ID: "aaa", OS: "Arch", Active: true},
+	}}
+
+	svc := NewDistroAPIService(runner, testReporter())
+	svc.SetOsCache(cache)
+
+	containers, err := svc.GetContainerList(context.Background(), true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(containers) != 2 {
+		t.Fatalf("expected 2 containers, got %d", len(containers))
+	}
+	if containers[0].ContainerName != "cached" || containers[0].OS != "Arch" || !containers[0].Active {
+		t.Errorf("expected cached OS info, got %+v", containers[0])
+	}
+	if containers[1].OS != "ALT Linux" {
+		t.Errorf("expected fetched OS info, got %+v", containers[1])
+	}
+
+	if entered := runner.entered(); !slices.Equal(entered, []string{"fresh"}) {
+		t.Errorf("expected only uncached container to be entered, got %v", entered)
+	}
+	if len(cache.saved) != 1 || cache.saved[0].ID != "bbb" {
+		t.Errorf("expected fetched container to be cached, got %+v", cache.saved)
+	}
+	if strings.Join(cache.existing, ",") != "aaa,bbb" {
+		t.Errorf("expected existing IDs aaa,bbb, got %v", cache.existing)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"apm/internal/common/app"
 	"apm/internal/common/command"
 	"apm/internal/common/filter"
+	"apm/internal/common/helper"
 	"apm/internal/common/reply"
 	"context"
 	"errors"
@@ -86,6 +87,8 @@ type PackageProvider interface {
 	InstallPackage(ctx context.Context, containerInfo ContainerInfo, packageName string) error
 	GetPackageOwner(ctx context.Context, containerInfo ContainerInfo, fileName string) (string, error)
 	GetPathByPackageName(ctx context.Context, containerInfo ContainerInfo, packageName, filePath string) ([]string, error)
+	ProvisionPackages(containerInfo ContainerInfo, locale string, fonts bool) []string
+	GenerateLocale(ctx context.Context, containerInfo ContainerInfo, locale string) error
 }
 
 // getProvider возвращает подходящий провайдер в зависимости от имени ОС контейнера.
@@ -97,6 +100,14 @@ func (p *PackageService) getProvider(osName string) (PackageProvider, error) {
 		return NewArchProvider(p, p.runner), nil
 	} else if strings.Contains(lowerName, "alt") {
 		return NewAltProvider(p, p.runner), nil
+	} else if strings.Contains(lowerName, "fedora") {
+		return NewFedoraProvider(p, p.runner), nil
+	} else if strings.Contains(lowerName, "suse") {
+		return NewOpenSUSEProvider(p, p.runner), nil
+	} else if strings.Contains(lowerName, "alpine") {
+		return NewAlpineProvider(p, p.runner), nil
+	} else if strings.Contains(lowerName, "void") {
+		return NewVoidProvider(p, p.runner), nil
 	}
 
 	return nil, errors.New(app.T_("This container is not supported: ") + osName)
@@ -114,6 +125,35 @@ func (p *PackageService) InstallPackage(ctx context.Context, containerInfo Conta
 	return provider.InstallPackage(ctx, containerInfo, packageName)
 }
 
+// Provision устанавливает пакеты локали и шрифтов и генерирует локаль в контейнере
+func (p *PackageService) Provision(ctx context.Context, containerInfo ContainerInfo, locale string, fonts bool) (ProvisionResult, error) {
+	p.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventDistroProvision))
+	defer p.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventDistroProvision))
+	provider, err := p.getProvider(containerInfo.OS)
+	if err != nil {
+		return ProvisionResult{}, err
+	}
+
+	result := ProvisionResult{
+		Locale:   locale,
+		Fonts:    fonts,
+		Packages: provider.ProvisionPackages(containerInfo, locale, fonts),
+	}
+	for _, pkg := range result.Packages {
+		if err = provider.InstallPackage(ctx, containerInfo, pkg); err != nil {
+			return ProvisionResult{}, err
+		}
+	}
+
+	if locale != "" {
+		if err = provider.GenerateLocale(ctx, containerInfo, locale); err != nil {
+			return ProvisionResult{}, err
+		}
+	}
+
+	return result, nil
+}
+
 // RemovePackage удаление пакета
 func (p *PackageService) RemovePackage(ctx context.Context, containerInfo ContainerInfo, packageName string) error {
 	p.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventDistroRemovePackage))
@@ -140,7 +180,7 @@ func (p *PackageService) GetPackages(ctx context.Context, containerInfo Containe
 
 // GetPackageOwner получает название пакета, которому принадлежит указанный файл, из контейнера.
 func (p *PackageService) GetPackageOwner(ctx context.Context, containerInfo ContainerInfo, fileName string) (string, error) {
-	viewName := fmt.Sprintf("%s: %s", app.T_("Determining file owner"), filepath.Base(fileName))
+	viewName := fmt.Sprintf("%s: %s", app.TL_(ctx, "Determining file owner"), filepath.Base(fileName))
 	p.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventDistroGetPackageOwner), reply.WithEventView(viewName))
 	defer p.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventDistroGetPackageOwner), reply.WithEventView(viewName))
 	provider, err := p.getProvider(containerInfo.OS)
@@ -170,21 +210,24 @@ func (p *PackageService) GetInfoPackage(ctx context.Context, containerInfo Conta
 	// Получаем информацию о пакете из базы данных
 	info, err := p.serviceDistroDatabase.GetPackageInfoByName(containerInfo.ContainerName, packageName)
 	if err != nil {
-		return InfoPackageAnswer{}, fmt.Errorf(app.T_("Failed to retrieve package information: %s"), packageName)
+		return InfoPackageAnswer{}, fmt.Errorf(app.TL_(ctx, "Failed to retrieve package information: %s"), packageName)
 	}
 
-	// Получаем пути для GUI-приложений
-	desktopPaths, err := p.GetPathByPackageName(ctx, containerInfo, packageName, "/usr/share/applications/")
-	if err != nil {
-		app.Log.Debugf(fmt.Sprintf(app.T_("Error retrieving desktop file path: %v"), err))
-		desktopPaths = []string{}
-	}
-
-	// Получаем пути для консольных приложений
-	consolePaths, err := p.GetPathByPackageName(ctx, containerInfo, packageName, "/usr/bin/")
-	if err != nil {
-		app.Log.Debugf(fmt.Sprintf(app.T_("Error retrieving console path: %v"), err))
-		consolePaths = []string{}
+	// Файлы есть только у установленного пакета, пути берутся из кеша или из контейнера одним вызовом
+	desktopPaths, consolePaths := []string{}, []string{}
+	if info.Installed {
+		var cached bool
+		desktopPaths, consolePaths, cached = p.serviceDistroDatabase.GetExportPaths(ctx, containerInfo.ContainerName, packageName)
+		if !cached {
+			files, err := p.GetPathByPackageName(ctx, containerInfo, packageName, "/usr/")
+			if err != nil {
+				app.Log.Debugf(fmt.Sprintf(app.TL_(ctx, "Error retrieving package file list: %v"), err))
+			}
+			desktopPaths, consolePaths = splitExportPaths(files)
+			if err = p.serviceDistroDatabase.SaveExportPaths(ctx, containerInfo.ContainerName, packageName, desktopPaths, consolePaths); err != nil {
+				app.Log.Debugf("failed to cache export paths: %v", err)
+			}
+		}
 	}
 
 	// Определяем, является ли пакет консольным (имеет только консольные пути)
@@ -214,6 +257,11 @@ func (p *PackageService) UpdatePackages(ctx context.Context, containerInfo Conta
 		return []PackageInfo{}, errorSave
 	}
 
+	if err = p.serviceDistroDatabase.SaveContainerSync(ctx, containerInfo, len(packages)); err != nil {
+		app.Log.Error(err)
+		return []PackageInfo{}, err
+	}
+
 	return packages, nil
 }
 
@@ -223,7 +271,7 @@ func (p *PackageService) GetPackagesQuery(ctx context.Context, containerInfo Con
 	defer p.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventDistroGetPackagesQuery))
 	if builder.ForceUpdate {
 		if len(containerInfo.ContainerName) == 0 {
-			return PackageQueryResult{}, errors.New(app.T_("A container must be specified for the forced update operation"))
+			return PackageQueryResult{}, errors.New(app.TL_(ctx, "A container must be specified for the forced update operation"))
 		}
 		_, err := p.UpdatePackages(ctx, containerInfo)
 		if err != nil {
@@ -280,10 +328,10 @@ func (p *PackageService) GetAllApplicationsByContainer(ctx context.Context, cont
 	wg.Wait()
 
 	if errDesktop != nil {
-		app.Log.Error(fmt.Sprintf(app.T_("Error retrieving desktop applications for container %s: %v"), containerInfo.ContainerName, errDesktop))
+		app.Log.Error(fmt.Sprintf(app.TL_(ctx, "Error retrieving desktop applications for container %s: %v"), containerInfo.ContainerName, errDesktop))
 	}
 	if errConsole != nil {
-		app.Log.Error(fmt.Sprintf(app.T_("Error retrieving console applications for container %s: %v"), containerInfo.ContainerName, errConsole))
+		app.Log.Error(fmt.Sprintf(app.TL_(ctx, "Error retrieving console applications for container %s: %v"), containerInfo.ContainerName, errConsole))
 	}
 
 	// Объединяем оба массива и удаляем дубли
@@ -317,13 +365,13 @@ func isDesktopFileForContainer(filePath, containerName string) bool {
 func (p *PackageService) GetDesktopApplicationsByContainer(ctx context.Context, containerInfo ContainerInfo) ([]string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return nil, fmt.Errorf(app.T_("Failed to retrieve home directory: %v"), err)
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to retrieve home directory: %v"), err)
 	}
 
 	localShareApps := filepath.Join(homeDir, ".local", "share", "applications")
 	entries, err := os.ReadDir(localShareApps)
 	if err != nil {
-		return nil, fmt.Errorf(app.T_("Error reading directory %s: %v"), localShareApps, err)
+		return nil, fmt.Errorf(app.TL_(ctx, "Error reading directory %s: %v"), localShareApps, err)
 	}
 
 	prefix := containerInfo.ContainerName + "-"
@@ -352,7 +400,7 @@ func (p *PackageService) GetDesktopApplicationsByContainer(ctx context.Context,
 		packagePath := filepath.Join("/usr/share/applications", originalName)
 		ownerPackage, err := p.GetPackageOwner(ctx, containerInfo, packagePath)
 		if err != nil {
-			app.Log.Error(fmt.Sprintf(app.T_("Error retrieving owner for file %s: %v"), fileName, err))
+			app.Log.Error(fmt.Sprintf(app.TL_(ctx, "Error retrieving owner for file %s: %v"), fileName, err))
 			continue
 		}
 		if ownerPackage != "" {
@@ -373,13 +421,13 @@ func (p *PackageService) GetDesktopApplicationsByContainer(ctx context.Context,
 func (p *PackageService) GetConsoleApplicationsByContainer(ctx context.Context, containerInfo ContainerInfo) ([]string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return nil, fmt.Errorf(app.T_("Failed to retrieve home directory: %v"), err)
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to retrieve home directory: %v"), err)
 	}
 
 	localBinApps := filepath.Join(homeDir, ".local", "bin")
 	entries, err := os.ReadDir(localBinApps)
 	if err != nil {
-		return nil, fmt.Errorf(app.T_("Error reading directory %s: %v"), localBinApps, err)
+		return nil, fmt.Errorf(app.TL_(ctx, "Error reading directory %s: %v"), localBinApps, err)
 	}
 
 	packageNamesSet := make(map[string]struct{})
@@ -391,14 +439,14 @@ func (p *PackageService) GetConsoleApplicationsByContainer(ctx context.Context,
 			fullPath := filepath.Join(localBinApps, fileName)
 			contentBytes, err := os.ReadFile(fullPath)
 			if err != nil {
-				app.Log.Error(fmt.Sprintf(app.T_("Error processing file %s: %v"), fileName, err))
+				app.Log.Error(fmt.Sprintf(app.TL_(ctx, "Error processing file %s: %v"), fileName, err))
 				continue
 			}
 			content := string(contentBytes)
 			if strings.Contains(content, marker) {
 				ownerPackage, err := p.GetPackageOwner(ctx, containerInfo, filepath.Join("/usr/bin", fileName))
 				if err != nil {
-					app.Log.Error(fmt.Sprintf(app.T_("Error retrieving owner for file %s: %v"), fileName, err))
+					app.Log.Error(fmt.Sprintf(app.TL_(ctx, "Error retrieving owner for file %s: %v"), fileName, err))
 					continue
 				}
 				if ownerPackage != "" {
@@ -414,3 +462,30 @@ func (p *PackageService) GetConsoleApplicationsByContainer(ctx context.Context,
 	}
 	return packageNames, nil
 }
+
+// filterPackagePaths оставляет из списка файлов пакета пути, содержащие filePath, без каталогов.
+func filterPackagePaths(output, filePath string) []string {
+	var paths []string
+	for _, line := range strings.Split(helper.FilterLines(output, filePath), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && !strings.HasSuffix(trimmed, "/") {
+			paths = append(paths, trimmed)
+		}
+	}
+	return paths
+}
+
+// markPackages проставляет пакетам признаки установки и экспорта, менеджер и контейнер.
+func markPackages(packages []PackageInfo, containerInfo ContainerInfo, manager string, installed map[string]bool, exporting []string) {
+	exportingMap := make(map[string]bool, len(exporting))
+	for _, name := range exporting {
+		exportingMap[name] = true
+	}
+
+	for i := range packages {
+		packages[i].Installed = installed[packages[i].Name]
+		packages[i].Exporting = exportingMap[packages[i].Name]
+		packages[i].Manager = manager
+		packages[i].Container = containerInfo.ContainerName
+	}
+}
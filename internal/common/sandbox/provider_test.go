@@ -99,6 +99,34 @@ func TestGetProvider(t *testing.T) {
 			expectError:  false,
 			description:  "Should detect ALT even with distro variant",
 		},
+		{
+			name:         "Fedora detection",
+			osName:       "Fedora",
+			expectedType: "*service.FedoraProvider",
+			expectError:  false,
+			description:  "Should detect Fedora and return Fedora provider",
+		},
+		{
+			name:         "openSUSE detection",
+			osName:       "openSUSE",
+			expectedType: "*service.OpenSUSEProvider",
+			expectError:  false,
+			description:  "Should detect openSUSE and return openSUSE provider",
+		},
+		{
+			name:         "Alpine detection",
+			osName:       "Alpine",
+			expectedType: "*service.AlpineProvider",
+			expectError:  false,
+			description:  "Should detect Alpine and return Alpine provider",
+		},
+		{
+			name:         "Void detection",
+			osName:       "Void",
+			expectedType: "*service.VoidProvider",
+			expectError:  false,
+			description:  "Should detect Void and return Void provider",
+		},
 		{
 			name:         "Unsupported OS",
 			osName:       "CentOS",
@@ -167,6 +195,14 @@ func getProviderTypeName(provider PackageProvider) string {
 		return "*service.ArchProvider"
 	case *AltProvider:
 		return "*service.AltProvider"
+	case *FedoraProvider:
+		return "*service.FedoraProvider"
+	case *OpenSUSEProvider:
+		return "*service.OpenSUSEProvider"
+	case *AlpineProvider:
+		return "*service.AlpineProvider"
+	case *VoidProvider:
+		return "*service.VoidProvider"
 	default:
 		return "unknown"
 	}
@@ -244,6 +280,12 @@ func TestGetProviderSubstringMatching(t *testing.T) {
 			expectedType: "*service.AltProvider",
 			description:  "Should match ALT in longer string",
 		},
+		{
+			name:         "openSUSE in description",
+			osName:       "openSUSE Tumbleweed",
+			expectedType: "*service.OpenSUSEProvider",
+			description:  "Should match SUSE in longer string",
+		},
 		{
 			name:         "Multiple matches - first wins",
 			osName:       "ubuntu-arch-alt-test",
@@ -275,9 +317,7 @@ func TestGetProviderErrorMessages(t *testing.T) {
 	unsupportedOSes := []string{
 		"CentOS",
 		"RedHat",
-		"SUSE",
-		"Fedora",
-		"OpenSUSE",
+		"Gentoo",
 		"FreeBSD",
 		"Windows",
 		"macOS",
@@ -302,3 +342,71 @@ func TestGetProviderErrorMessages(t *testing.T) {
 		})
 	}
 }
+
+func TestParseDnfRepoquery(t *testing.T) {
+	output := "htop|3.3.0-3.fc41|Interactive process viewer\n" +
+		"htop|3.3.0-3.fc41|Interactive process viewer\n" +
+		"vim-enhanced|2:9.1.0-1.fc41|A version of the VIM editor which includes recent enhancements\n" +
+		"broken line\n"
+
+	packages := parseDnfRepoquery(output)
+	if len(packages) != 2 {
+		t.Fatalf("expected 2 packages, got %+v", packages)
+	}
+	if packages[1].Name != "vim-enhanced" || packages[1].Version != "2:9.1.0-1.fc41" {
+		t.Errorf("unexpected package: %+v", packages[1])
+	}
+}
+
+func TestParseZypperSearch(t *testing.T) {
+	output := `<?xml version='1.0'?>
+<stream>
+<search-result version="0.0">
+<solvable-list>
+<solvable status="installed" name="htop" summary="An interactive process viewer" kind="package" edition="3.3.0-1.2" arch="x86_64" repository="(System Packages)"/>
+<solvable status="other-version" name="htop" summary="An interactive process viewer" kind="package" edition="3.3.0-1.3" arch="x86_64" repository="repo-oss"/>
+<solvable status="not-installed" name="mc" summary="Midnight Commander" kind="package" edition="4.8.31-1.1" arch="x86_64" repository="repo-oss"/>
+</solvable-list>
+</search-result>
+</stream>`
+
+	packages, installed, err := parseZypperSearch(output)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(packages) != 2 || packages[0].Version != "3.3.0-1.2" || packages[1].Description != "Midnight Commander" {
+		t.Errorf("unexpected packages: %+v", packages)
+	}
+	if !installed["htop"] || installed["mc"] {
+		t.Errorf("unexpected installed set: %v", installed)
+	}
+}
+
+func TestParseApkSearch(t *testing.T) {
+	output := "htop-3.3.0-r0 - Interactive process viewer\n" +
+		"py3-requests-2.31.0-r1 - HTTP request library for Python\n"
+
+	packages := parseApkSearch(output)
+	if len(packages) != 2 {
+		t.Fatalf("expected 2 packages, got %+v", packages)
+	}
+	if packages[1].Name != "py3-requests" || packages[1].Version != "2.31.0-r1" || packages[1].Description != "HTTP request library for Python" {
+		t.Errorf("unexpected package: %+v", packages[1])
+	}
+}
+
+func TestParseXbpsSearch(t *testing.T) {
+	output := "[*] bash-5.2.21_1                 GNU Bourne Again Shell\n" +
+		"[-] python3-requests-2.31.0_2      Python HTTP library\n"
+
+	packages, installed := parseXbpsSearch(output)
+	if len(packages) != 2 {
+		t.Fatalf("expected 2 packages, got %+v", packages)
+	}
+	if packages[1].Name != "python3-requests" || packages[1].Version != "2.31.0_2" || packages[1].Description != "Python HTTP library" {
+		t.Errorf("unexpected package: %+v", packages[1])
+	}
+	if !installed["bash"] || installed["python3-requests"] {
+		t.Errorf("unexpected installed set: %v", installed)
+	}
+}
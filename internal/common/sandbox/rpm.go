@@ -0,0 +1,60 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sandbox
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/command"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// rpmInstalledPackages возвращает множество установленных пакетов rpm-дистрибутива.
+func rpmInstalledPackages(ctx context.Context, runner command.Runner, containerInfo ContainerInfo) (map[string]bool, error) {
+	stdout, _, err := runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "rpm", "-qa", "--queryformat", "%{NAME}\\n"}, command.WithEnv("LC_ALL=C"), command.WithQuiet())
+	if err != nil {
+		return nil, fmt.Errorf(app.TL_(ctx, "Error executing command rpm -qa: %w"), err)
+	}
+
+	installed := make(map[string]bool)
+	for _, line := range strings.Split(stdout, "\n") {
+		if name := strings.TrimSpace(line); name != "" {
+			installed[name] = true
+		}
+	}
+	return installed, nil
+}
+
+// rpmPackagePaths возвращает файлы установленного пакета через rpm -ql.
+func rpmPackagePaths(ctx context.Context, runner command.Runner, containerInfo ContainerInfo, packageName, filePath string) ([]string, error) {
+	stdout, stderr, err := runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "rpm", "-ql", packageName}, command.WithEnv("LC_ALL=C"), command.WithQuiet())
+	if err != nil {
+		app.Log.Debugf(app.TL_(ctx, "Command execution error: %s %s"), stderr, err.Error())
+	}
+	return filterPackagePaths(stdout, filePath), nil
+}
+
+// rpmPackageOwner определяет пакет-владельца файла через rpm -qf.
+func rpmPackageOwner(ctx context.Context, runner command.Runner, containerInfo ContainerInfo, filePath string) (string, error) {
+	stdout, stderr, err := runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "rpm", "-qf", "--queryformat", "%{NAME}", filePath}, command.WithEnv("LC_ALL=C"), command.WithQuiet())
+	if err != nil {
+		app.Log.Debugf(app.TL_(ctx, "Command execution error: %s %s"), stderr, err.Error())
+		return "", err
+	}
+	return strings.TrimSpace(stdout), nil
+}
@@ -0,0 +1,232 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sandbox
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/command"
+	"apm/internal/common/reply"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// snapshotImagePrefix префикс локальных образов, в которые сохраняются снимки контейнеров
+const snapshotImagePrefix = "localhost/apm-snapshot/"
+
+// snapshotNameRegex допустимое имя снимка, оно же тег образа
+var snapshotNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127}$`)
+
+// DBContainerSnapshot запись о снимке контейнера
+type DBContainerSnapshot struct {
+	ID        uint   `gorm:"column:id;primaryKey;autoIncrement"`
+	Container string `gorm:"column:container;uniqueIndex:idx_distrobox_snapshot"`
+	Name      string `gorm:"column:name;uniqueIndex:idx_distrobox_snapshot"`
+	Image     string `gorm:"column:image"`
+	OS        string `gorm:"column:os"`
+	CreatedAt int64  `gorm:"column:created_at"`
+}
+
+// TableName задаёт имя таблицы.
+func (DBContainerSnapshot) TableName() string {
+	return "distrobox_snapshots"
+}
+
+// Snapshot снимок контейнера, сохранённый в локальный образ.
+type Snapshot struct {
+	Container string    `json:"container"`
+	Name      string    `json:"name"`
+	Image     string    `json:"image"`
+	OS        string    `json:"os"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// NewSnapshot возвращает снимок контейнера с именем name. Пустое name заменяется временем создания.
+func NewSnapshot(containerInfo ContainerInfo, name string) (Snapshot, error) {
+	now := time.Now()
+	if name == "" {
+		name = now.Format("20060102-150405")
+	}
+	if !snapshotNameRegex.MatchString(name) {
+		return Snapshot{}, fmt.Errorf(app.T_("Invalid snapshot name: %q"), name)
+	}
+
+	return Snapshot{
+		Container: containerInfo.ContainerName,
+		Name:      name,
+		Image:     snapshotImagePrefix + strings.ToLower(containerInfo.ContainerName) + ":" + name,
+		OS:        containerInfo.OS,
+		CreatedAt: now,
+	}, nil
+}
+
+// SaveSnapshot записывает снимок контейнера в базу.
+func (s *DistroDBService) SaveSnapshot(ctx context.Context, snapshot Snapshot) error {
+	db, err := s.db()
+	if err != nil {
+		return err
+	}
+
+	row := DBContainerSnapshot{
+		Container: snapshot.Container,
+		Name:      snapshot.Name,
+		Image:     snapshot.Image,
+		OS:        snapshot.OS,
+		CreatedAt: snapshot.CreatedAt.Unix(),
+	}
+	return app.RetryBusy(ctx, func() error {
+		return db.WithContext(ctx).Create(&row).Error
+	})
+}
+
+// GetSnapshots возвращает снимки контейнера, начиная с последнего.
+func (s *DistroDBService) GetSnapshots(ctx context.Context, containerName string) ([]Snapshot, error) {
+	db, err := s.db()
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []DBContainerSnapshot
+	if err = db.WithContext(ctx).
+		Where("container = ?", containerName).
+		Order("created_at DESC, id DESC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]Snapshot, 0, len(rows))
+	for _, row := range rows {
+		snapshots = append(snapshots, Snapshot{
+			Container: row.Container,
+			Name:      row.Name,
+			Image:     row.Image,
+			OS:        row.OS,
+			CreatedAt: time.Unix(row.CreatedAt, 0),
+		})
+	}
+	return snapshots, nil
+}
+
+// DeleteSnapshot удаляет запись о снимке контейнера.
+func (s *DistroDBService) DeleteSnapshot(ctx context.Context, containerName, name string) error {
+	db, err := s.db()
+	if err != nil {
+		return err
+	}
+
+	return app.RetryBusy(ctx, func() error {
+		return db.WithContext(ctx).
+			Where("container = ? AND name = ?", containerName, name).
+			Delete(&DBContainerSnapshot{}).Error
+	})
+}
+
+// CommitContainer сохраняет файловую систему контейнера в локальный образ image.
+// Запущенный контейнер останавливается, чтобы снимок был согласованным.
+func (d *DistroAPIService) CommitContainer(ctx context.Context, containerName, image string) error {
+	d.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventDistroCommitContainer))
+	defer d.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventDistroCommitContainer))
+
+	info, err := d.findContainer(ctx, containerName)
+	if err != nil {
+		return err
+	}
+
+	if info.Running {
+		if _, stderr, errStop := d.runner.Run(ctx, []string{"podman", "stop", containerName}, command.WithQuiet()); errStop != nil {
+			return fmt.Errorf(app.TL_(ctx, "Failed to stop container %s: %v, stderr: %s"), containerName, errStop, strings.TrimSpace(stderr))
+		}
+	}
+
+	if _, stderr, errCommit := d.runner.Run(ctx, []string{"podman", "container", "commit", containerName, image}, command.WithQuiet()); errCommit != nil {
+		return fmt.Errorf(app.TL_(ctx, "Failed to create snapshot of container %s: %s"), containerName, strings.TrimSpace(stderr))
+	}
+
+	return nil
+}
+
+// RemoveImage удаляет локальный образ снимка.
+func (d *DistroAPIService) RemoveImage(ctx context.Context, image string) error {
+	if _, stderr, err := d.runner.Run(ctx, []string{"podman", "rmi", image}, command.WithQuiet()); err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Failed to remove image %s: %s"), image, strings.TrimSpace(stderr))
+	}
+	return nil
+}
+
+// CloneContainer создаёт контейнер containerName копией контейнера source. Исходный контейнер
+// останавливается, так как distrobox не клонирует запущенные контейнеры.
+func (d *DistroAPIService) CloneContainer(ctx context.Context, source, containerName string) (ContainerInfo, error) {
+	d.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventDistroCloneContainer))
+	defer d.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventDistroCloneContainer))
+
+	if err := validateContainerName(containerName); err != nil {
+		return ContainerInfo{}, err
+	}
+
+	info, err := d.findContainer(ctx, source)
+	if err != nil {
+		return ContainerInfo{}, err
+	}
+	if _, err = d.findContainer(ctx, containerName); err == nil {
+		return ContainerInfo{}, fmt.Errorf(app.TL_(ctx, "Container already exists: %s"), containerName)
+	}
+
+	if info.Running {
+		if _, stderr, errStop := d.runner.Run(ctx, []string{"podman", "stop", source}, command.WithQuiet()); errStop != nil {
+			return ContainerInfo{}, fmt.Errorf(app.TL_(ctx, "Failed to stop container %s: %v, stderr: %s"), source, errStop, strings.TrimSpace(stderr))
+		}
+	}
+
+	if _, stderr, errClone := d.runner.Run(ctx, []string{"distrobox", "create", "--clone", source, "-n", containerName, "--yes"}); errClone != nil {
+		return ContainerInfo{}, fmt.Errorf(app.TL_(ctx, "Failed to clone container %s: %s"), source, strings.TrimSpace(stderr))
+	}
+
+	return d.GetContainerOsInfo(ctx, containerName)
+}
+
+// RestoreContainer пересоздаёт контейнер containerName из образа снимка. Существующий контейнер
+// с этим именем удаляется, поэтому восстановить можно и уже удалённый контейнер.
+func (d *DistroAPIService) RestoreContainer(ctx context.Context, containerName, image string) (ContainerInfo, error) {
+	d.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventDistroRestoreContainer))
+	defer d.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventDistroRestoreContainer))
+
+	if err := validateContainerName(containerName); err != nil {
+		return ContainerInfo{}, err
+	}
+	if err := validateImageRef(image); err != nil {
+		return ContainerInfo{}, err
+	}
+
+	if _, err := d.findContainer(ctx, containerName); err == nil {
+		if _, stderr, errRm := d.runner.Run(ctx, []string{"distrobox", "rm", "--force", containerName}, command.WithQuiet()); errRm != nil {
+			return ContainerInfo{}, fmt.Errorf(app.TL_(ctx, "Failed to remove container %s: %s"), containerName, strings.TrimSpace(stderr))
+		}
+	}
+
+	if _, stderr, err := d.runner.Run(ctx, []string{"distrobox", "create", "-i", image, "-n", containerName, "--yes"}); err != nil {
+		return ContainerInfo{}, fmt.Errorf(app.TL_(ctx, "Failed to restore container %s: %s"), containerName, strings.TrimSpace(stderr))
+	}
+
+	// Первый enter заново настраивает пользователя и монтирования distrobox
+	if _, stderr, err := d.runner.Run(ctx, []string{"distrobox", "enter", containerName, "--", "true"}); err != nil {
+		app.Log.Errorf(app.TL_(ctx, "Failed to initialize container %s: %v, stderr: %s"), containerName, err, stderr)
+	}
+
+	return d.GetContainerOsInfo(ctx, containerName)
+}
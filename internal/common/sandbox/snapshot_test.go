@@ -0,0 +1,51 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sandbox
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewSnapshot(t *testing.T) {
+	info := ContainerInfo{ContainerName: "Dev-Box", OS: "ALT Linux"}
+
+	snapshot, err := NewSnapshot(info, "before-upgrade")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if snapshot.Image != "localhost/apm-snapshot/dev-box:before-upgrade" {
+		t.Errorf("unexpected image: %s", snapshot.Image)
+	}
+	if snapshot.Container != "Dev-Box" || snapshot.OS != "ALT Linux" {
+		t.Errorf("unexpected snapshot: %+v", snapshot)
+	}
+
+	snapshot, err = NewSnapshot(info, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasSuffix(snapshot.Image, ":"+snapshot.Name) || len(snapshot.Name) != len("20060102-150405") {
+		t.Errorf("expected name from creation time, got %q", snapshot.Name)
+	}
+
+	for _, name := range []string{"-bad", "a/b", "with space", strings.Repeat("x", 129)} {
+		if _, err = NewSnapshot(info, name); err == nil {
+			t.Errorf("expected error for snapshot name %q", name)
+		}
+	}
+}
@@ -0,0 +1,377 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sandbox
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/command"
+	"apm/internal/common/reply"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const (
+	// storageConfEnv переменная окружения podman с путём к storage.conf
+	storageConfEnv = "CONTAINERS_STORAGE_CONF"
+	// storageConfName файл настроек хранилища контейнеров podman
+	storageConfName = "storage.conf"
+	// migrateImagePrefix префикс временных образов при переносе контейнеров
+	migrateImagePrefix = "localhost/apm-migrate-"
+)
+
+// ErrStorageUnchanged хранилище уже расположено по указанному пути
+var ErrStorageUnchanged = errors.New("storage location is unchanged")
+
+// StorageInfo сведения о хранилище контейнеров
+type StorageInfo struct {
+	Path   string `json:"path"`
+	Custom bool   `json:"custom"`
+	Total  uint64 `json:"total"`
+	Free   uint64 `json:"free"`
+}
+
+// StorageService управляет расположением хранилища podman для контейнеров distrobox.
+// Каталог graphroot записывается в storage.conf пользователя, поэтому podman и distrobox
+// видят перенесённые контейнеры и при запуске без apm. Остальные настройки файла сохраняются.
+type StorageService struct {
+	runner   command.Runner
+	reporter *reply.Reporter
+	confPath string
+}
+
+// NewStorageService создаёт сервис хранилища контейнеров.
+func NewStorageService(runner command.Runner, reporter *reply.Reporter) *StorageService {
+	return &StorageService{
+		runner:   runner,
+		reporter: reporter,
+		confPath: storageConfPath(),
+	}
+}
+
+// storageConfPath возвращает путь к storage.conf podman в каталоге настроек пользователя
+func storageConfPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "containers", storageConfName)
+}
+
+// renderStorageConf формирует storage.conf с каталогом graphroot
+func renderStorageConf(path string) string {
+	return fmt.Sprintf("[storage]\ndriver = \"overlay\"\ngraphroot = %q\n", path)
+}
+
+// setGraphRoot задаёт graphroot в секции [storage] содержимого storage.conf, сохраняя остальные настройки
+func setGraphRoot(existing, path string) string {
+	if strings.TrimSpace(existing) == "" {
+		return renderStorageConf(path)
+	}
+
+	line := fmt.Sprintf("graphroot = %q", path)
+	lines := strings.Split(strings.TrimRight(existing, "\n"), "\n")
+	section, header := "", -1
+	for i, l := range lines {
+		trimmed := strings.TrimSpace(l)
+		if strings.HasPrefix(trimmed, "[") {
+			section = trimmed
+			if section == "[storage]" {
+				header = i
+			}
+			continue
+		}
+		if section == "[storage]" && confKey(trimmed) == "graphroot" {
+			lines[i] = line
+			return strings.Join(lines, "\n") + "\n"
+		}
+	}
+
+	if header >= 0 {
+		lines = append(lines[:header+1], append([]string{line}, lines[header+1:]...)...)
+		return strings.Join(lines, "\n") + "\n"
+	}
+	return strings.Join(lines, "\n") + "\n\n" + renderStorageConf(path)
+}
+
+// graphRoot возвращает graphroot из секции [storage] содержимого storage.conf
+func graphRoot(content string) string {
+	section := ""
+	for _, l := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(l)
+		if strings.HasPrefix(trimmed, "[") {
+			section = trimmed
+			continue
+		}
+		if section != "[storage]" || confKey(trimmed) != "graphroot" {
+			continue
+		}
+		_, value, _ := strings.Cut(trimmed, "=")
+		value = strings.TrimSpace(value)
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			return unquoted
+		}
+		return strings.Trim(value, `"'`)
+	}
+	return ""
+}
+
+// confKey возвращает имя ключа строки storage.conf вида key = value
+func confKey(line string) string {
+	key, _, ok := strings.Cut(line, "=")
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(key)
+}
+
+// ApplyDefault задаёт хранилище из конфигурации, если пользователь ещё не создавал контейнеры
+// и не переносил хранилище. Существующие контейнеры так не окажутся скрыты.
+func (s *StorageService) ApplyDefault(path string) {
+	if path == "" {
+		return
+	}
+	existing := s.readConf()
+	if graphRoot(existing) != "" {
+		return
+	}
+
+	dataDir := os.Getenv("XDG_DATA_HOME")
+	if !filepath.IsAbs(dataDir) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return
+		}
+		dataDir = filepath.Join(home, ".local", "share")
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "containers", "storage")); err == nil {
+		return
+	}
+
+	if err := writeConf(s.confPath, setGraphRoot(existing, path)); err != nil {
+		app.Log.Debugf("failed to apply default container storage: %v", err)
+	}
+}
+
+// Info возвращает текущее расположение хранилища и свободное место на его разделе.
+func (s *StorageService) Info(ctx context.Context) (StorageInfo, error) {
+	stdout, stderr, err := s.runner.Run(ctx, []string{"podman", "info", "--format", "{{.Store.GraphRoot}}"}, command.WithQuiet())
+	if err != nil {
+		return StorageInfo{}, fmt.Errorf(app.TL_(ctx, "Failed to get container storage information: %s"), strings.TrimSpace(stderr))
+	}
+
+	info := StorageInfo{
+		Path:   strings.TrimSpace(stdout),
+		Custom: graphRoot(s.readConf()) != "",
+	}
+
+	var stat syscall.Statfs_t
+	if err = syscall.Statfs(info.Path, &stat); err == nil {
+		info.Total = stat.Blocks * uint64(stat.Bsize)
+		info.Free = stat.Bavail * uint64(stat.Bsize)
+	}
+
+	return info, nil
+}
+
+// Relocate переносит хранилище контейнеров в path. Каждый контейнер сохраняется в образ,
+// загружается в новое хранилище и создаётся заново той же командой podman create, что и исходный.
+// Новый graphroot записывается в storage.conf пользователя и проверяется до удаления старых
+// контейнеров, поэтому при любой ошибке контейнеры остаются в прежнем хранилище.
+// Возвращает имена перенесённых контейнеров.
+func (s *StorageService) Relocate(ctx context.Context, path string, containers []ContainerInfo) ([]string, error) {
+	s.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventDistroRelocateStorage))
+	defer s.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventDistroRelocateStorage))
+
+	current, err := s.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if filepath.Clean(current.Path) == filepath.Clean(path) {
+		return nil, ErrStorageUnchanged
+	}
+
+	if err = os.MkdirAll(path, 0700); err != nil {
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to create directory %s: %v"), path, err)
+	}
+
+	// Архивы контейнеров готовятся рядом с новым хранилищем: во временном каталоге (часто tmpfs) может не хватить места
+	stageDir, err := os.MkdirTemp(path, ".apm-migrate-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(stageDir) }()
+
+	previous, hadConf := s.readConfFile()
+	content := setGraphRoot(previous, path)
+	newConf := filepath.Join(stageDir, "new-"+storageConfName)
+	oldConf := filepath.Join(stageDir, "old-"+storageConfName)
+	if err = writeConf(newConf, content); err != nil {
+		return nil, err
+	}
+	if err = writeConf(oldConf, setGraphRoot(previous, current.Path)); err != nil {
+		return nil, err
+	}
+	newEnv := command.WithEnv(storageConfEnv + "=" + newConf)
+	oldEnv := command.WithEnv(storageConfEnv + "=" + oldConf)
+
+	migrated := make([]string, 0, len(containers))
+	for _, c := range containers {
+		if err = s.migrateContainer(ctx, c.ContainerName, stageDir, oldEnv, newEnv); err != nil {
+			return nil, err
+		}
+		migrated = append(migrated, c.ContainerName)
+	}
+
+	if err = s.switchConf(ctx, path, content, previous, hadConf); err != nil {
+		return nil, err
+	}
+
+	for _, name := range migrated {
+		if _, stderr, errRm := s.runner.Run(ctx, []string{"podman", "rm", "--force", name}, command.WithQuiet(), oldEnv); errRm != nil {
+			app.Log.Warning(fmt.Sprintf("failed to remove container %s from old storage: %s", name, strings.TrimSpace(stderr)))
+		}
+	}
+
+	return migrated, nil
+}
+
+// switchConf записывает storage.conf с новым graphroot и проверяет, что podman его использует.
+// Если podman видит другое хранилище, прежний файл восстанавливается.
+func (s *StorageService) switchConf(ctx context.Context, path, content, previous string, hadConf bool) error {
+	err := writeConf(s.confPath, content)
+	if err == nil {
+		var info StorageInfo
+		if info, err = s.Info(ctx); err == nil && filepath.Clean(info.Path) != filepath.Clean(path) {
+			err = fmt.Errorf(app.TL_(ctx, "podman uses storage %s instead of %s"), info.Path, path)
+		}
+	}
+	if err == nil {
+		return nil
+	}
+
+	if hadConf {
+		_ = writeConf(s.confPath, previous)
+	} else {
+		_ = os.Remove(s.confPath)
+	}
+	return fmt.Errorf(app.TL_(ctx, "Failed to switch container storage to %s: %v"), path, err)
+}
+
+// migrateContainer переносит один контейнер из хранилища oldEnv в хранилище newEnv
+func (s *StorageService) migrateContainer(ctx context.Context, name, stageDir string, oldEnv, newEnv command.Option) error {
+	image := migrateImagePrefix + imageNameComponent(name)
+	archive := filepath.Join(stageDir, name+".tar")
+
+	createCommand, sourceImage, err := inspectCreateCommand(ctx, s.runner, name, oldEnv)
+	if err != nil {
+		return err
+	}
+
+	steps := []struct {
+		args []string
+		opts []command.Option
+	}{
+		{args: []string{"podman", "container", "commit", name, image}, opts: []command.Option{oldEnv}},
+		{args: []string{"podman", "save", "-o", archive, image}, opts: []command.Option{oldEnv}},
+		{args: []string{"podman", "load", "-i", archive}, opts: []command.Option{newEnv}},
+		{args: recreateCommand(createCommand, sourceImage, image, name), opts: []command.Option{newEnv}},
+	}
+
+	for _, step := range steps {
+		opts := append([]command.Option{command.WithQuiet()}, step.opts...)
+		if _, stderr, errRun := s.runner.Run(ctx, step.args, opts...); errRun != nil {
+			return fmt.Errorf(app.TL_(ctx, "Failed to migrate container %s: %s"), name, strings.TrimSpace(stderr))
+		}
+	}
+
+	_ = os.Remove(archive)
+	if _, stderr, errRmi := s.runner.Run(ctx, []string{"podman", "rmi", image}, command.WithQuiet(), oldEnv); errRmi != nil {
+		app.Log.Debugf("failed to remove temporary image %s: %s", image, stderr)
+	}
+
+	return nil
+}
+
+// readConf возвращает содержимое storage.conf пользователя или пустую строку, если файла нет
+func (s *StorageService) readConf() string {
+	content, _ := s.readConfFile()
+	return content
+}
+
+// readConfFile возвращает содержимое storage.conf пользователя и признак существования файла
+func (s *StorageService) readConfFile() (string, bool) {
+	data, err := os.ReadFile(s.confPath)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// writeConf записывает содержимое storage.conf
+func writeConf(confPath, content string) error {
+	if err := os.MkdirAll(filepath.Dir(confPath), 0755); err != nil {
+		return fmt.Errorf(app.T_("Failed to create directory %s: %v"), filepath.Dir(confPath), err)
+	}
+	if err := os.WriteFile(confPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf(app.T_("Failed to write %s: %v"), confPath, err)
+	}
+	return nil
+}
+
+// podmanSize строка вывода podman ps --size --format json
+type podmanSize struct {
+	Names []string `json:"Names"`
+	Size  *struct {
+		RwSize int64 `json:"rwSize"`
+	} `json:"Size"`
+}
+
+// parseContainerSizes разбирает вывод podman ps --size и возвращает занимаемое место по имени контейнера
+func parseContainerSizes(output string) (map[string]int64, error) {
+	var rows []podmanSize
+	if err := json.Unmarshal([]byte(output), &rows); err != nil {
+		return nil, err
+	}
+
+	sizes := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		if row.Size == nil {
+			continue
+		}
+		for _, name := range row.Names {
+			sizes[name] = row.Size.RwSize
+		}
+	}
+	return sizes, nil
+}
+
+// DiskUsage возвращает место на диске, занимаемое каждым контейнером. podman ps --size
+// считает размер слоёв долго, поэтому вызывается только по запросу сведений о хранилище.
+func (s *StorageService) DiskUsage(ctx context.Context) (map[string]int64, error) {
+	stdout, stderr, err := s.runner.Run(ctx, []string{"podman", "ps", "--all", "--size", "--format", "json"}, command.WithQuiet())
+	if err != nil {
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to get container disk usage: %s"), strings.TrimSpace(stderr))
+	}
+	return parseContainerSizes(stdout)
+}
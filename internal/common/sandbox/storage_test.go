@@ -0,0 +1,185 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sandbox
+
+import (
+	"apm/internal/common/command"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type mockStorageRunner struct {
+	graphRoot  string
+	confPath   string
+	ignoreConf bool
+	calls      []string
+}
+
+func (m *mockStorageRunner) Run(_ context.Context, args []string, _ ...command.Option) (string, string, error) {
+	line := strings.Join(args, " ")
+	m.calls = append(m.calls, line)
+	switch {
+	case strings.HasPrefix(line, "podman info"):
+		// podman читает graphroot из storage.conf пользователя
+		if data, err := os.ReadFile(m.confPath); err == nil && !m.ignoreConf && graphRoot(string(data)) != "" {
+			return graphRoot(string(data)) + "\n", "", nil
+		}
+		return m.graphRoot + "\n", "", nil
+	case strings.HasPrefix(line, "podman container inspect"):
+		return `[{"ImageName":"quay.io/toolbx/arch-toolbox:latest","Config":{"CreateCommand":` +
+			`["podman","create","--hostname","arch.host","--name","arch","--volume","/data:/data",` +
+			`"quay.io/toolbx/arch-toolbox:latest","--name","user","--home","/home/user/arch"]}}]`, "", nil
+	}
+	return "", "", nil
+}
+
+func TestParseContainerSizes(t *testing.T) {
+	output := `[{"Names":["atomic-alt"],"Size":{"rootFsSize":900,"rwSize":120}},{"Names":["no-size"]}]`
+
+	sizes, err := parseContainerSizes(output)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sizes["atomic-alt"] != 120 {
+		t.Errorf("expected rw size 120, got %d", sizes["atomic-alt"])
+	}
+	if _, ok := sizes["no-size"]; ok {
+		t.Error("expected container without size to be skipped")
+	}
+}
+
+func TestSetGraphRoot(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		want     string
+	}{
+		{"empty", "", renderStorageConf("/data")},
+		{"replace", "[storage]\ndriver = \"overlay\"\ngraphroot = \"/old\"\n", "[storage]\ndriver = \"overlay\"\ngraphroot = \"/data\"\n"},
+		{"add to section", "[storage]\nrunroot = \"/run/user/1000\"\n", "[storage]\ngraphroot = \"/data\"\nrunroot = \"/run/user/1000\"\n"},
+		{"add section", "[storage.options]\nmount_program = \"/usr/bin/fuse-overlayfs\"\n",
+			"[storage.options]\nmount_program = \"/usr/bin/fuse-overlayfs\"\n\n" + renderStorageConf("/data")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := setGraphRoot(tt.existing, "/data")
+			if got != tt.want {
+				t.Errorf("setGraphRoot() = %q, want %q", got, tt.want)
+			}
+			if root := graphRoot(got); root != "/data" {
+				t.Errorf("graphRoot() = %q, want /data", root)
+			}
+		})
+	}
+}
+
+func TestStorageRelocate(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "storage")
+	confPath := filepath.Join(dir, "containers", storageConfName)
+	runner := &mockStorageRunner{graphRoot: "/var/home/user/.local/share/containers/storage", confPath: confPath}
+	svc := &StorageService{runner: runner, reporter: testReporter(), confPath: confPath}
+	userConf := "[storage]\ndriver = \"overlay\"\nrunroot = \"/run/user/1000/containers\"\n"
+	if err := writeConf(svc.confPath, userConf); err != nil {
+		t.Fatal(err)
+	}
+
+	migrated, err := svc.Relocate(context.Background(), target, []ContainerInfo{{ContainerName: "arch"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(migrated) != 1 || migrated[0] != "arch" {
+		t.Errorf("expected arch to be migrated, got %v", migrated)
+	}
+
+	calls := strings.Join(runner.calls, "\n")
+	for _, want := range []string{
+		"podman container commit arch " + migrateImagePrefix + "arch",
+		"podman save -o " + target + "/.apm-migrate-",
+		"podman create --hostname arch.host --name arch --volume /data:/data " + migrateImagePrefix + "arch --name user --home /home/user/arch",
+		"podman rm --force arch",
+	} {
+		if !strings.Contains(calls, want) {
+			t.Errorf("expected call %q, got:\n%s", want, calls)
+		}
+	}
+	if strings.Index(calls, "podman rm --force arch") < strings.LastIndex(calls, "podman info") {
+		t.Errorf("old container must be removed only after the new storage is verified:\n%s", calls)
+	}
+
+	conf, err := os.ReadFile(svc.confPath)
+	if err != nil {
+		t.Fatalf("expected storage.conf to be written: %v", err)
+	}
+	if graphRoot(string(conf)) != target || !strings.Contains(string(conf), "runroot = ") {
+		t.Errorf("expected user storage.conf to point to %s and keep other settings, got %q", target, conf)
+	}
+	if staged, _ := filepath.Glob(filepath.Join(target, ".apm-migrate-*")); len(staged) != 0 {
+		t.Errorf("expected staging directory to be removed, got %v", staged)
+	}
+
+	if _, err = svc.Relocate(context.Background(), target, nil); err != ErrStorageUnchanged {
+		t.Errorf("expected ErrStorageUnchanged, got %v", err)
+	}
+}
+
+func TestStorageRelocateKeepsContainersWhenSwitchFails(t *testing.T) {
+	dir := t.TempDir()
+	confPath := filepath.Join(dir, "containers", storageConfName)
+	runner := &mockStorageRunner{graphRoot: "/var/home/user/.local/share/containers/storage", confPath: confPath, ignoreConf: true}
+	svc := &StorageService{runner: runner, reporter: testReporter(), confPath: confPath}
+
+	if _, err := svc.Relocate(context.Background(), filepath.Join(dir, "storage"), []ContainerInfo{{ContainerName: "arch"}}); err == nil {
+		t.Fatal("expected error when podman does not pick up the new storage")
+	}
+	if strings.Contains(strings.Join(runner.calls, "\n"), "podman rm --force") {
+		t.Errorf("old containers must be kept, got calls:\n%s", strings.Join(runner.calls, "\n"))
+	}
+	if _, err := os.Stat(confPath); !os.IsNotExist(err) {
+		t.Errorf("expected storage.conf to be restored to its absent state, stat error: %v", err)
+	}
+}
+
+func TestRecreateCommand(t *testing.T) {
+	create := []string{"podman", "create", "--name=dev", "--env", "HOME=/home/user", "docker.io/library/alpine:latest", "--name", "user"}
+
+	got := recreateCommand(create, "docker.io/library/alpine:latest", "localhost/snap:1", "dev-restore")
+	want := []string{"podman", "create", "--name=dev-restore", "--env", "HOME=/home/user", "localhost/snap:1", "--name", "user"}
+	if !slices.Equal(got, want) {
+		t.Errorf("recreateCommand() = %v, want %v", got, want)
+	}
+
+	got = recreateCommand(nil, "", "localhost/snap:1", "dev")
+	if want = []string{"distrobox", "create", "-i", "localhost/snap:1", "-n", "dev", "--yes"}; !slices.Equal(got, want) {
+		t.Errorf("recreateCommand() without create command = %v, want %v", got, want)
+	}
+}
+
+func TestImageNameComponent(t *testing.T) {
+	if got := imageNameComponent("dev"); got != "dev" {
+		t.Errorf("imageNameComponent(dev) = %q", got)
+	}
+	upper := imageNameComponent("Dev")
+	if upper == "dev" || upper != strings.ToLower(upper) {
+		t.Errorf("imageNameComponent(Dev) = %q, want a distinct lowercase name", upper)
+	}
+}
@@ -0,0 +1,138 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sandbox
+
+import (
+	"apm/internal/common/app"
+	"context"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// DBContainerSync сведения о последней синхронизации пакетов контейнера с локальной базой
+type DBContainerSync struct {
+	Container string `gorm:"column:container;primaryKey"`
+	OS        string `gorm:"column:os"`
+	Count     int    `gorm:"column:count"`
+	SyncedAt  int64  `gorm:"column:synced_at"`
+}
+
+// TableName задаёт имя таблицы.
+func (DBContainerSync) TableName() string {
+	return "distrobox_containers"
+}
+
+// SyncInfo описывает локальную базу пакетов контейнера.
+type SyncInfo struct {
+	Container string    `json:"container"`
+	OS        string    `json:"os"`
+	Count     int       `json:"count"`
+	SyncedAt  time.Time `json:"syncedAt"`
+}
+
+// ContainerInfo возвращает сведения о контейнере, достаточные для запросов к локальной базе.
+func (s SyncInfo) ContainerInfo() ContainerInfo {
+	return ContainerInfo{ContainerName: s.Container, OS: s.OS}
+}
+
+// exportPaths кешированные пути установленного пакета для экспорта
+type exportPaths struct {
+	Desktop []string `json:"desktop"`
+	Console []string `json:"console"`
+}
+
+// SaveContainerSync запоминает время синхронизации и количество пакетов контейнера.
+func (s *DistroDBService) SaveContainerSync(ctx context.Context, containerInfo ContainerInfo, count int) error {
+	db, err := s.db()
+	if err != nil {
+		return err
+	}
+
+	row := DBContainerSync{
+		Container: containerInfo.ContainerName,
+		OS:        containerInfo.OS,
+		Count:     count,
+		SyncedAt:  time.Now().Unix(),
+	}
+	return app.RetryBusy(ctx, func() error {
+		return db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&row).Error
+	})
+}
+
+// GetContainerSync возвращает сведения о синхронизации контейнера. Ошибка означает, что контейнер ещё не синхронизирован.
+func (s *DistroDBService) GetContainerSync(ctx context.Context, containerName string) (SyncInfo, error) {
+	db, err := s.db()
+	if err != nil {
+		return SyncInfo{}, err
+	}
+
+	var row DBContainerSync
+	if err = db.WithContext(ctx).Where("container = ?", containerName).First(&row).Error; err != nil {
+		return SyncInfo{}, err
+	}
+
+	return SyncInfo{
+		Container: row.Container,
+		OS:        row.OS,
+		Count:     row.Count,
+		SyncedAt:  time.Unix(row.SyncedAt, 0),
+	}, nil
+}
+
+// GetExportPaths возвращает кешированные пути пакета для экспорта, ok равен false, если кеша нет.
+func (s *DistroDBService) GetExportPaths(ctx context.Context, containerName, name string) (desktopPaths, consolePaths []string, ok bool) {
+	db, err := s.db()
+	if err != nil {
+		return nil, nil, false
+	}
+
+	var dbp DBDistroPackage
+	if err = db.WithContext(ctx).
+		Select("export_paths").
+		Where("container = ? AND name = ?", containerName, name).
+		First(&dbp).Error; err != nil || dbp.ExportPaths == "" {
+		return nil, nil, false
+	}
+
+	var paths exportPaths
+	if err = json.Unmarshal([]byte(dbp.ExportPaths), &paths); err != nil {
+		return nil, nil, false
+	}
+	return paths.Desktop, paths.Console, true
+}
+
+// SaveExportPaths кеширует пути пакета для экспорта, чтобы не входить в контейнер повторно.
+func (s *DistroDBService) SaveExportPaths(ctx context.Context, containerName, name string, desktopPaths, consolePaths []string) error {
+	db, err := s.db()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(exportPaths{Desktop: desktopPaths, Console: consolePaths})
+	if err != nil {
+		return err
+	}
+
+	return app.RetryBusy(ctx, func() error {
+		return db.WithContext(ctx).
+			Model(&DBDistroPackage{}).
+			Where("container = ? AND name = ?", containerName, name).
+			Update("export_paths", string(data)).Error
+	})
+}
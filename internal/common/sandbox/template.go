@@ -0,0 +1,166 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sandbox
+
+import (
+	"apm/internal/common/app"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// DefaultTemplateDirs директории шаблонов контейнеров. Шаблон из /etc переопределяет одноимённый шаблон дистрибутива.
+var DefaultTemplateDirs = []string{
+	"/usr/share/apm/distrobox-templates.d",
+	"/etc/apm/distrobox-templates.d",
+}
+
+// Template описывает шаблон контейнера. Имя шаблона совпадает с именем файла без расширения.
+type Template struct {
+	Name        string   `yaml:"-" json:"name"`
+	Description string   `yaml:"description" json:"description"`
+	Image       string   `yaml:"image" json:"image"`
+	Packages    []string `yaml:"packages" json:"packages"`
+	InitHooks   []string `yaml:"initHooks" json:"initHooks"`
+	Home        string   `yaml:"home" json:"home"`
+	Export      []string `yaml:"export" json:"export"`
+	File        string   `yaml:"-" json:"file"`
+}
+
+// AdditionalPackages возвращает пакеты шаблона в формате --additional-packages.
+func (t Template) AdditionalPackages() string {
+	return strings.Join(t.Packages, " ")
+}
+
+// InitHook объединяет команды инициализации шаблона в одну команду --init-hooks.
+func (t Template) InitHook() string {
+	return strings.Join(t.InitHooks, " && ")
+}
+
+// HomePath возвращает отдельный домашний каталог контейнера. Пустая строка означает общий с хостом каталог.
+// Поддерживаются ~/ в начале пути и подстановка {name} - имени контейнера.
+func (t Template) HomePath(containerName string) (string, error) {
+	home := strings.ReplaceAll(strings.TrimSpace(t.Home), "{name}", containerName)
+	if rest, ok := strings.CutPrefix(home, "~/"); ok {
+		userHome, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		home = filepath.Join(userHome, rest)
+	}
+	if home != "" && !filepath.IsAbs(home) {
+		return "", fmt.Errorf(app.T_("Template home must be an absolute path: %s"), t.Home)
+	}
+	return home, nil
+}
+
+// validate проверяет обязательные поля и имена пакетов шаблона.
+func (t Template) validate() error {
+	if err := validateImageRef(t.Image); err != nil {
+		return err
+	}
+	for _, pkg := range slices.Concat(t.Packages, t.Export) {
+		if err := validatePackageName(pkg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TemplateService читает шаблоны контейнеров из YAML файлов.
+type TemplateService struct {
+	dirs []string
+}
+
+// NewTemplateService создаёт сервис шаблонов для указанных директорий.
+func NewTemplateService(dirs []string) *TemplateService {
+	return &TemplateService{dirs: dirs}
+}
+
+// List возвращает шаблоны, отсортированные по имени. Директории обходятся по порядку,
+// поэтому шаблон из более поздней директории заменяет одноимённый.
+func (s *TemplateService) List() ([]Template, error) {
+	byName := make(map[string]Template)
+	for _, dir := range s.dirs {
+		entries, err := os.ReadDir(dir)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf(app.T_("Failed to read templates directory %s: %v"), dir, err)
+		}
+
+		for _, entry := range entries {
+			ext := filepath.Ext(entry.Name())
+			if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+				continue
+			}
+			tmpl, err := readTemplate(filepath.Join(dir, entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+			byName[tmpl.Name] = tmpl
+		}
+	}
+
+	templates := make([]Template, 0, len(byName))
+	for _, tmpl := range byName {
+		templates = append(templates, tmpl)
+	}
+	slices.SortFunc(templates, func(a, b Template) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return templates, nil
+}
+
+// Get возвращает шаблон по имени.
+func (s *TemplateService) Get(name string) (Template, error) {
+	templates, err := s.List()
+	if err != nil {
+		return Template{}, err
+	}
+	idx := slices.IndexFunc(templates, func(t Template) bool { return t.Name == name })
+	if idx == -1 {
+		return Template{}, fmt.Errorf(app.T_("Template %s not found"), name)
+	}
+	return templates[idx], nil
+}
+
+// readTemplate читает и проверяет файл шаблона.
+func readTemplate(path string) (Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Template{}, fmt.Errorf(app.T_("Failed to read template %s: %v"), path, err)
+	}
+
+	var tmpl Template
+	if err = yaml.Unmarshal(data, &tmpl); err != nil {
+		return Template{}, fmt.Errorf(app.T_("Failed to parse template %s: %v"), path, err)
+	}
+	tmpl.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	tmpl.File = path
+
+	if err = tmpl.validate(); err != nil {
+		return Template{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return tmpl, nil
+}
@@ -0,0 +1,92 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sandbox
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func writeTemplate(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTemplateServiceList(t *testing.T) {
+	root := t.TempDir()
+	vendorDir := filepath.Join(root, "usr")
+	etcDir := filepath.Join(root, "etc")
+
+	writeTemplate(t, vendorDir, "go-dev.yml", "image: registry.altlinux.org/sisyphus/base:latest\npackages: [golang]\n")
+	writeTemplate(t, vendorDir, "web.yaml", "description: Web\nimage: docker.io/library/alpine:latest\n")
+	writeTemplate(t, vendorDir, "README", "not a template")
+	writeTemplate(t, etcDir, "go-dev.yml", `description: Go development
+image: registry.altlinux.org/sisyphus/base:latest
+packages: [golang, git]
+initHooks:
+  - echo one
+  - echo two
+home: /srv/homes/{name}
+export: [code]
+`)
+
+	svc := NewTemplateService([]string{vendorDir, etcDir, filepath.Join(root, "missing")})
+	templates, err := svc.List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(templates) != 2 || templates[0].Name != "go-dev" || templates[1].Name != "web" {
+		t.Fatalf("unexpected templates: %+v", templates)
+	}
+
+	goDev := templates[0]
+	if goDev.Description != "Go development" || !slices.Equal(goDev.Export, []string{"code"}) {
+		t.Errorf("expected template from /etc to override vendor one, got %+v", goDev)
+	}
+	if goDev.AdditionalPackages() != "golang git" || goDev.InitHook() != "echo one && echo two" {
+		t.Errorf("unexpected create arguments: %q, %q", goDev.AdditionalPackages(), goDev.InitHook())
+	}
+	if home, err := goDev.HomePath("box"); err != nil || home != "/srv/homes/box" {
+		t.Errorf("unexpected home: %q, %v", home, err)
+	}
+
+	if _, err = svc.Get("missing"); err == nil {
+		t.Error("expected error for unknown template")
+	}
+}
+
+func TestTemplateValidation(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "bad.yml", "image: alpine\npackages: [\"vim; rm -rf /\"]\n")
+
+	_, err := NewTemplateService([]string{dir}).List()
+	if err == nil || !strings.Contains(err.Error(), "bad.yml") {
+		t.Fatalf("expected validation error with file name, got %v", err)
+	}
+
+	if _, err = (Template{Home: "relative/home"}).HomePath("box"); err == nil {
+		t.Error("expected error for relative home")
+	}
+}
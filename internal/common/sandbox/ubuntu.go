@@ -48,17 +48,17 @@ func (p *UbuntuProvider) GetPackages(ctx context.Context, containerInfo Containe
 	// Обновляем базу пакетов.
 	_, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "sudo", "apt-get", "update"}, command.WithQuiet())
 	if err != nil {
-		return nil, fmt.Errorf(app.T_("Failed to update package database: %v, stderr: %s"), err, stderr)
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to update package database: %v, stderr: %s"), err, stderr)
 	}
 
 	stdout, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "apt", "search", "."}, command.WithEnv("LC_ALL=C"), command.WithQuiet())
 	if err != nil {
-		return nil, fmt.Errorf(app.T_("Failed to execute apt search: %v, stderr: %s"), err, stderr)
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to execute apt search: %v, stderr: %s"), err, stderr)
 	}
 
 	exportingPackages, err := p.servicePackage.GetAllApplicationsByContainer(ctx, containerInfo)
 	if err != nil {
-		app.Log.Error(app.T_("Failed to retrieve installed packages: "), err)
+		app.Log.Error(app.TL_(ctx, "Failed to retrieve installed packages: "), err)
 		exportingPackages = []string{}
 	}
 
@@ -86,7 +86,7 @@ func (p *UbuntuProvider) GetPathByPackageName(ctx context.Context, containerInfo
 
 	stdout, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "dpkg", "-L", packageName}, command.WithEnv("LC_ALL=C"), command.WithQuiet())
 	if err != nil {
-		app.Log.Debugf(app.T_("Command execution error: %s %s"), stderr, err.Error())
+		app.Log.Debugf(app.TL_(ctx, "Command execution error: %s %s"), stderr, err.Error())
 	}
 
 	filtered := helper.FilterLines(stdout, filePath)
@@ -94,7 +94,7 @@ func (p *UbuntuProvider) GetPathByPackageName(ctx context.Context, containerInfo
 	if len(paths) == 0 {
 		dlStdout, dlStderr, dlErr := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "dpkg", "-l"}, command.WithEnv("LC_ALL=C"), command.WithQuiet())
 		if dlErr != nil {
-			app.Log.Debugf(app.T_("Fallback command execution error: %s %s"), dlStderr, dlErr.Error())
+			app.Log.Debugf(app.TL_(ctx, "Fallback command execution error: %s %s"), dlStderr, dlErr.Error())
 			return paths, nil
 		}
 
@@ -124,7 +124,7 @@ func (p *UbuntuProvider) GetPathByPackageName(ctx context.Context, containerInfo
 		}
 		sort.Strings(allPaths)
 		if len(allPaths) > 0 {
-			app.Log.Debugf(app.T_("Fallback search found %d files"), len(allPaths))
+			app.Log.Debugf(app.TL_(ctx, "Fallback search found %d files"), len(allPaths))
 			return allPaths, nil
 		}
 	}
@@ -154,12 +154,49 @@ func (p *UbuntuProvider) InstallPackage(ctx context.Context, containerInfo Conta
 	}
 	_, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "sudo", "apt-get", "install", "-y", packageName})
 	if err != nil {
-		return fmt.Errorf(app.T_("Failed to install package %s: %v, stderr: %s"), packageName, err, stderr)
+		return fmt.Errorf(app.TL_(ctx, "Failed to install package %s: %v, stderr: %s"), packageName, err, stderr)
 	}
 
 	return nil
 }
 
+// ProvisionPackages возвращает пакеты локалей и шрифтов. Для Ubuntu добавляется языковой пакет.
+func (p *UbuntuProvider) ProvisionPackages(containerInfo ContainerInfo, locale string, fonts bool) []string {
+	var packages []string
+	if locale != "" {
+		packages = append(packages, "locales")
+		if isUbuntu(containerInfo.OS) {
+			packages = append(packages, "language-pack-"+localeLanguage(locale))
+		}
+	}
+	if fonts {
+		packages = append(packages, "fonts-dejavu-core", "fonts-liberation", "fonts-noto-core")
+	}
+	return packages
+}
+
+// GenerateLocale генерирует локаль. Ubuntu принимает имя локали в locale-gen,
+// в Debian локаль нужно сначала включить в /etc/locale.gen.
+func (p *UbuntuProvider) GenerateLocale(ctx context.Context, containerInfo ContainerInfo, locale string) error {
+	cmd := []string{"distrobox", "enter", containerInfo.ContainerName, "--", "sudo", "locale-gen"}
+	if isUbuntu(containerInfo.OS) {
+		cmd = append(cmd, locale)
+	} else if err := enableLocaleGen(ctx, p.runner, containerInfo, locale); err != nil {
+		return err
+	}
+
+	_, stderr, err := p.runner.Run(ctx, cmd)
+	if err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Failed to generate locale %s: %v, stderr: %s"), locale, err, stderr)
+	}
+	return nil
+}
+
+// isUbuntu отличает Ubuntu от Debian, которые обслуживает один провайдер
+func isUbuntu(osName string) bool {
+	return strings.Contains(strings.ToLower(osName), "ubuntu")
+}
+
 // RemovePackage удаляет указанный пакет внутри контейнера через apt-get remove.
 func (p *UbuntuProvider) RemovePackage(ctx context.Context, containerInfo ContainerInfo, packageName string) error {
 	if err := validatePackageName(packageName); err != nil {
@@ -167,7 +204,7 @@ func (p *UbuntuProvider) RemovePackage(ctx context.Context, containerInfo Contai
 	}
 	_, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "sudo", "apt-get", "remove", "-y", packageName})
 	if err != nil {
-		return fmt.Errorf(app.T_("Failed to remove package %s: %v, stderr: %s"), packageName, err, stderr)
+		return fmt.Errorf(app.TL_(ctx, "Failed to remove package %s: %v, stderr: %s"), packageName, err, stderr)
 	}
 
 	return nil
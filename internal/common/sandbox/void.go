@@ -0,0 +1,182 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sandbox
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/command"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// VoidProvider реализует интерфейс PackageProvider для Void Linux (xbps)
+type VoidProvider struct {
+	servicePackage *PackageService
+	runner         command.Runner
+}
+
+// NewVoidProvider возвращает новый экземпляр VoidProvider.
+func NewVoidProvider(servicePackage *PackageService, runner command.Runner) *VoidProvider {
+	return &VoidProvider{
+		servicePackage: servicePackage,
+		runner:         runner,
+	}
+}
+
+// GetPackages синхронизирует индекс репозиториев и получает список пакетов через xbps-query.
+// Установленные пакеты отмечены в выводе маркером [*].
+func (p *VoidProvider) GetPackages(ctx context.Context, containerInfo ContainerInfo) ([]PackageInfo, error) {
+	if _, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "sudo", "xbps-install", "-S"}, command.WithQuiet()); err != nil {
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to update package database: %v, stderr: %s"), err, stderr)
+	}
+
+	stdout, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "xbps-query", "--regex", "-Rs", "."}, command.WithEnv("LC_ALL=C"), command.WithQuiet())
+	if err != nil {
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to search packages (xbps-query): %v, stderr: %s"), err, stderr)
+	}
+
+	exportingPackages, err := p.servicePackage.GetAllApplicationsByContainer(ctx, containerInfo)
+	if err != nil {
+		app.Log.Error(app.TL_(ctx, "Error retrieving exporting packages: "), err)
+		exportingPackages = []string{}
+	}
+
+	packages, installed := parseXbpsSearch(stdout)
+	markPackages(packages, containerInfo, "xbps", installed, exportingPackages)
+	return packages, nil
+}
+
+// parseXbpsSearch разбирает строки вида "[*] bash-5.2.21_1   GNU Bourne Again Shell".
+func parseXbpsSearch(output string) ([]PackageInfo, map[string]bool) {
+	var packages []PackageInfo
+	installed := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) < 4 || line[0] != '[' {
+			continue
+		}
+		fields := strings.Fields(line[3:])
+		if len(fields) == 0 {
+			continue
+		}
+		name, version := splitXbpsNameVersion(fields[0])
+		if name == "" {
+			continue
+		}
+		if line[1] == '*' {
+			installed[name] = true
+		}
+		packages = append(packages, PackageInfo{
+			Name:        name,
+			Version:     version,
+			Description: strings.Join(fields[1:], " "),
+		})
+	}
+	return packages, installed
+}
+
+// splitXbpsNameVersion делит "python3-foo-1.2_1" на имя "python3-foo" и версию "1.2_1".
+func splitXbpsNameVersion(s string) (string, string) {
+	idx := strings.LastIndex(s, "-")
+	if idx <= 0 {
+		return "", ""
+	}
+	return s[:idx], s[idx+1:]
+}
+
+// RemovePackage удаляет указанный пакет через xbps-remove.
+func (p *VoidProvider) RemovePackage(ctx context.Context, containerInfo ContainerInfo, packageName string) error {
+	if err := validatePackageName(packageName); err != nil {
+		return err
+	}
+	_, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "sudo", "xbps-remove", "-y", packageName})
+	if err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Failed to remove package %s: %v, stderr: %s"), packageName, err, stderr)
+	}
+	return nil
+}
+
+// InstallPackage устанавливает указанный пакет через xbps-install.
+func (p *VoidProvider) InstallPackage(ctx context.Context, containerInfo ContainerInfo, packageName string) error {
+	if err := validatePackageName(packageName); err != nil {
+		return err
+	}
+	_, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "sudo", "xbps-install", "-y", packageName})
+	if err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Failed to install package %s: %v, stderr: %s"), packageName, err, stderr)
+	}
+	return nil
+}
+
+// GetPackageOwner определяет пакет-владельца файла через xbps-query -o.
+func (p *VoidProvider) GetPackageOwner(ctx context.Context, containerInfo ContainerInfo, filePath string) (string, error) {
+	stdout, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "xbps-query", "-o", filePath}, command.WithEnv("LC_ALL=C"), command.WithQuiet())
+	if err != nil {
+		app.Log.Debugf(app.TL_(ctx, "Command execution error: %s %s"), stderr, err.Error())
+		return "", err
+	}
+
+	// Ожидаемый вывод: "bash-5.2.21_1: /usr/bin/bash (regular file)"
+	pkgVersion, _, found := strings.Cut(strings.TrimSpace(stdout), ":")
+	if !found {
+		return "", fmt.Errorf(app.TL_(ctx, "Failed to recognize the owner for file '%s'"), filePath)
+	}
+	name, _ := splitXbpsNameVersion(pkgVersion)
+	return name, nil
+}
+
+// GetPathByPackageName возвращает список путей для файла пакета, найденных через xbps-query -f.
+// Для символических ссылок берётся сама ссылка без цели.
+func (p *VoidProvider) GetPathByPackageName(ctx context.Context, containerInfo ContainerInfo, packageName, filePath string) ([]string, error) {
+	stdout, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "xbps-query", "-f", packageName}, command.WithEnv("LC_ALL=C"), command.WithQuiet())
+	if err != nil {
+		app.Log.Debugf(app.TL_(ctx, "Command execution error: %s %s"), stderr, err.Error())
+	}
+
+	var files []string
+	for _, line := range strings.Split(stdout, "\n") {
+		file, _, _ := strings.Cut(line, " -> ")
+		files = append(files, file)
+	}
+	return filterPackagePaths(strings.Join(files, "\n"), filePath), nil
+}
+
+// ProvisionPackages возвращает пакеты локалей и шрифтов.
+func (p *VoidProvider) ProvisionPackages(_ ContainerInfo, locale string, fonts bool) []string {
+	var packages []string
+	if locale != "" {
+		packages = append(packages, "glibc-locales")
+	}
+	if fonts {
+		packages = append(packages, "dejavu-fonts-ttf", "liberation-fonts-ttf", "noto-fonts-ttf")
+	}
+	return packages
+}
+
+// GenerateLocale включает локаль в /etc/default/libc-locales и пересобирает локали glibc-locales.
+func (p *VoidProvider) GenerateLocale(ctx context.Context, containerInfo ContainerInfo, locale string) error {
+	if err := enableLocaleIn(ctx, p.runner, containerInfo, locale, "/etc/default/libc-locales"); err != nil {
+		return err
+	}
+
+	_, stderr, err := p.runner.Run(ctx, []string{"distrobox", "enter", containerInfo.ContainerName, "--", "sudo", "xbps-reconfigure", "-f", "glibc-locales"})
+	if err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Failed to generate locale %s: %v, stderr: %s"), locale, err, stderr)
+	}
+	return nil
+}
@@ -20,6 +20,9 @@ import (
 	"apm/internal/common/app"
 	apmcli "apm/internal/common/cli"
 	"apm/internal/common/dbus_doc"
+	"apm/internal/common/helper"
+	"apm/internal/common/mock"
+	"apm/internal/common/reply"
 	"context"
 	"fmt"
 	"sync"
@@ -54,30 +57,62 @@ type DBusRunConfig struct {
 	Modules []DBusModule
 }
 
-func RunDBus(ctx context.Context, _ *cli.Command, appConfig *app.Config, cfg DBusRunConfig) error {
+func RunDBus(ctx context.Context, cmd *cli.Command, appConfig *app.Config, cfg DBusRunConfig) error {
 	appConfig.ConfigManager.SetFormat(app.FormatDBus)
-	appConfig.ConfigManager.EnableVerbose()
+	// Машинный режим: логи пишутся в stdout, вывод команд не транслируется в терминал
+	app.Log.EnableStdoutLogging()
 	if err := apmcli.CheckRoot(cfg.Mode); err != nil {
 		return err
 	}
+	// Запросы клиентов ждут своей очереди на блокировку операций, а не завершаются ошибкой.
+	// Синхронный запрос ждёт не дольше ServiceLockWait, фоновые задачи ждут без ограничения
+	ctx = context.WithValue(ctx, helper.WaitLockKey, true)
+	ctx = context.WithValue(ctx, helper.WaitLockTimeoutKey, helper.ServiceLockWait)
+	// Ответ на диалоги для всех клиентов задаётся флагами запуска сервиса
+	ctx = apmcli.WithAnswer(ctx, cmd, appConfig.ConfigManager.GetConfig())
 
 	if err := connectBus(appConfig, cfg.Bus); err != nil {
 		return fmt.Errorf("connect dbus: %w", err)
 	}
 	conn := appConfig.DBusManager.GetConnection()
 
-	interfaces := make(map[string]any, len(cfg.Modules))
+	// Другие процессы сообщают об изменении баз данных, после чего подключения к ним открываются заново
+	go app.WatchDatabaseChanges(ctx, conn, appConfig.DatabaseManager)
+
+	// Режим разработчика: методы отвечают заготовленными данными, фоновые задачи модулей не запускаются
+	var responder *mock.Responder
+	if mock.Enabled() {
+		app.Log.Warn("APM_MOCK=1: DBus methods return mock data")
+		responder = mock.NewResponder(reply.NewReporter(appConfig))
+	}
+
+	interfaces := make(map[string]any, len(cfg.Modules)+1)
 	var postHooks []func(context.Context)
+
+	// Клиенты выбирают язык ответов через SetLocale, ответы модулей переводятся при возврате
+	locales := reply.NewLocales()
+	localeWrapper := &LocaleWrapper{locales: locales}
+	if err := conn.Export(localeWrapper, DBusObjectPath, DBusLocaleInterface); err != nil {
+		return fmt.Errorf("export %s: %w", DBusLocaleInterface, err)
+	}
+	interfaces[DBusLocaleInterface] = localeWrapper
+	go forgetDisconnected(ctx, conn, locales)
+
 	for _, mod := range cfg.Modules {
 		exp, err := mod.Build(ctx, conn)
 		if err != nil {
 			return fmt.Errorf("build %s: %w", mod.Interface, err)
 		}
-		if err = conn.Export(exp.Object, DBusObjectPath, mod.Interface); err != nil {
+		if responder != nil {
+			err = conn.ExportMethodTable(mock.MethodTable(ctx, mod.Interface, exp.Object, responder), DBusObjectPath, mod.Interface)
+		} else {
+			err = conn.ExportMethodTable(reply.LocalizedMethodTable(exp.Object, locales), DBusObjectPath, mod.Interface)
+		}
+		if err != nil {
 			return fmt.Errorf("export %s: %w", mod.Interface, err)
 		}
 		interfaces[mod.Interface] = exp.Object
-		if exp.PostExport != nil {
+		if exp.PostExport != nil && responder == nil {
 			postHooks = append(postHooks, exp.PostExport)
 		}
 	}
@@ -19,10 +19,15 @@ package service
 import (
 	"apm/internal/common/app"
 	apmcli "apm/internal/common/cli"
+	"apm/internal/common/helper"
 	"apm/internal/common/http_server"
+	"apm/internal/common/mock"
 	"apm/internal/common/reply"
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"strconv"
 	"sync"
 
 	"github.com/urfave/cli/v3"
@@ -43,6 +48,8 @@ type HTTPRunConfig struct {
 	Mode    apmcli.RootCheckMode
 	APIInfo APIInfo
 	Modules []HTTPModule
+	// Socket unix-сокет из конфигурации; флаги --socket и --socket-only переопределяют его
+	Socket app.HTTPSocket
 }
 
 func RunHTTP(
@@ -52,7 +59,8 @@ func RunHTTP(
 	cfg HTTPRunConfig,
 ) error {
 	appConfig.ConfigManager.SetFormat(app.FormatHTTP)
-	appConfig.ConfigManager.EnableVerbose()
+	// Машинный режим: логи пишутся в stdout, вывод команд не транслируется в терминал
+	app.Log.EnableStdoutLogging()
 
 	if err := apmcli.CheckRoot(cfg.Mode); err != nil {
 		return err
@@ -65,6 +73,18 @@ func RunHTTP(
 	if token := cmd.String("api-token"); token != "" {
 		httpCfg.APIToken = token
 	}
+	httpCfg.Roles = appConfig.ConfigManager.GetConfig().HTTPRoles
+
+	socket := cfg.Socket
+	if path := cmd.String("socket"); path != "" {
+		socket.Path = path
+	}
+	if cmd.Bool("socket-only") {
+		socket.SocketOnly = true
+	}
+	if err := applySocketConfig(&httpCfg, socket); err != nil {
+		return err
+	}
 
 	server, err := http_server.NewServer(httpCfg, appConfig)
 	if err != nil {
@@ -76,14 +96,32 @@ func RunHTTP(
 	server.RegisterHealthCheck()
 	server.RegisterWebSocket()
 	server.RegisterAPIInfo(cfg.APIInfo.IsAtomic, cfg.APIInfo.HasDistrobox, cfg.APIInfo.HasKernel)
-
+	server.RegisterWhoAmI()
+
+	// Запросы клиентов ждут своей очереди на блокировку операций, а не завершаются ошибкой.
+	// Синхронный запрос ждёт не дольше ServiceLockWait, фоновые задачи ждут без ограничения
+	ctx = context.WithValue(ctx, helper.WaitLockKey, true)
+	ctx = context.WithValue(ctx, helper.WaitLockTimeoutKey, helper.ServiceLockWait)
+	// Ответ на диалоги по умолчанию задаётся флагами запуска сервиса, запрос может переопределить его параметром answer
+	ctx = apmcli.WithAnswer(ctx, cmd, appConfig.ConfigManager.GetConfig())
 	runCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
+	// Режим разработчика: endpoints отвечают заготовленными данными, фоновые задачи модулей не запускаются
+	var responder *mock.Responder
+	if mock.Enabled() {
+		app.Log.Warn("APM_MOCK=1: HTTP endpoints return mock data")
+		responder = mock.NewResponder(reply.NewReporter(appConfig))
+	}
+
 	var wg sync.WaitGroup
 	for _, mod := range cfg.Modules {
-		server.RegisterEndpoints(mod.Endpoints(runCtx))
-		if mod.PostInit != nil {
+		endpoints := mod.Endpoints(runCtx)
+		if responder != nil {
+			endpoints = mock.Endpoints(runCtx, endpoints, responder)
+		}
+		server.RegisterEndpoints(endpoints)
+		if mod.PostInit != nil && responder == nil {
 			wg.Add(1)
 			go func(h func(context.Context)) {
 				defer wg.Done()
@@ -106,3 +144,29 @@ func RunHTTP(
 	}
 	return nil
 }
+
+// applySocketConfig переносит настройки unix-сокета в конфигурацию HTTP сервера
+func applySocketConfig(httpCfg *http_server.Config, socket app.HTTPSocket) error {
+	if socket.Path == "" {
+		if socket.SocketOnly {
+			return errors.New(app.T_("Unix socket path is required to listen only on a socket"))
+		}
+		return nil
+	}
+
+	if socket.Mode != "" {
+		mode, err := strconv.ParseUint(socket.Mode, 8, 32)
+		if err != nil || mode > 0o777 {
+			return fmt.Errorf(app.T_("Invalid unix socket mode %q: expected an octal value such as 0660"), socket.Mode)
+		}
+		httpCfg.SocketMode = os.FileMode(mode)
+	}
+
+	httpCfg.SocketPath = socket.Path
+	httpCfg.SocketGroup = socket.Group
+	httpCfg.SocketPermission = socket.Permission
+	if socket.SocketOnly {
+		httpCfg.ListenAddr = ""
+	}
+	return nil
+}
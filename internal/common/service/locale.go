@@ -0,0 +1,93 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/reply"
+	"context"
+	"fmt"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// DBusLocaleInterface интерфейс выбора языка ответов D-Bus сервиса
+const DBusLocaleInterface = "org.altlinux.APM.locale"
+
+// LocaleWrapper позволяет клиенту D-Bus выбрать язык сообщений в ответах
+type LocaleWrapper struct {
+	locales *reply.Locales
+}
+
+// SetLocale задаёт язык ответов для вызывающего клиента, например ru или en_US.UTF-8.
+// Пустая строка возвращает язык сервиса.
+func (w *LocaleWrapper) SetLocale(sender dbus.Sender, locale string) *dbus.Error {
+	if locale == "" {
+		w.locales.Set(string(sender), "")
+		return nil
+	}
+	tag, ok := app.ParseLanguage(locale)
+	if !ok {
+		return apmerr.DBusError(apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.TL_(app.WithLocale(context.Background(), w.locales.Get(string(sender))), "Unknown locale %q"), locale)))
+	}
+	w.locales.Set(string(sender), tag.String())
+	return nil
+}
+
+// GetLocale возвращает язык ответов вызывающего клиента или пустую строку, если он не задан
+func (w *LocaleWrapper) GetLocale(sender dbus.Sender) (string, *dbus.Error) {
+	return w.locales.Get(string(sender)), nil
+}
+
+// forgetDisconnected удаляет языки клиентов, отключившихся от шины
+func forgetDisconnected(ctx context.Context, conn *dbus.Conn, locales *reply.Locales) {
+	matchOptions := []dbus.MatchOption{
+		dbus.WithMatchSender("org.freedesktop.DBus"),
+		dbus.WithMatchInterface("org.freedesktop.DBus"),
+		dbus.WithMatchMember("NameOwnerChanged"),
+	}
+	if err := conn.AddMatchSignalContext(ctx, matchOptions...); err != nil {
+		app.Log.Debugf("failed to subscribe to NameOwnerChanged: %v", err)
+		return
+	}
+	defer func() { _ = conn.RemoveMatchSignal(matchOptions...) }()
+
+	signals := make(chan *dbus.Signal, 16)
+	conn.Signal(signals)
+	defer conn.RemoveSignal(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig, ok := <-signals:
+			if !ok {
+				return
+			}
+			if sig.Name != "org.freedesktop.DBus.NameOwnerChanged" || len(sig.Body) != 3 {
+				continue
+			}
+			// Клиент отключился: у уникального имени больше нет владельца
+			name, _ := sig.Body[0].(string)
+			newOwner, _ := sig.Body[2].(string)
+			if newOwner == "" && name != "" {
+				locales.Set(name, "")
+			}
+		}
+	}
+}
@@ -19,6 +19,7 @@ package swcat
 import (
 	"apm/internal/common/app"
 	"apm/internal/common/filter"
+	"apm/internal/common/helper"
 	"apm/internal/common/reply"
 	"context"
 	"fmt"
@@ -78,8 +79,10 @@ func (s *DBService) db() (*gorm.DB, error) {
 			return nil, fmt.Errorf("failed to connect GORM to SQLite: %w", err)
 		}
 
-		if err = s.realDb.AutoMigrate(&DBAppStream{}); err != nil {
-			return nil, fmt.Errorf("failed to migrate host_appstream_components: %w", err)
+		if !app.IsReadOnly(conn) {
+			if err = s.realDb.AutoMigrate(&DBAppStream{}); err != nil {
+				return nil, fmt.Errorf("failed to migrate host_appstream_components: %w", err)
+			}
 		}
 	}
 
@@ -96,31 +99,39 @@ func (s *DBService) SaveComponentsToDB(ctx context.Context, pkgMap map[string][]
 		return err
 	}
 
-	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		if err = tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&DBAppStream{}).Error; err != nil {
-			return fmt.Errorf(app.T_("Table cleanup error: %w"), err)
-		}
-
-		rows := make([]DBAppStream, 0, len(pkgMap))
-		for pkgName, comps := range pkgMap {
-			rows = append(rows, DBAppStream{
-				PkgName:    pkgName,
-				Components: comps,
-			})
-		}
+	err = app.RetryBusy(ctx, func() error {
+		return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err = tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&DBAppStream{}).Error; err != nil {
+				return fmt.Errorf(app.T_("Table cleanup error: %w"), err)
+			}
 
-		batchSize := 1000
-		for i := 0; i < len(rows); i += batchSize {
-			end := i + batchSize
-			if end > len(rows) {
-				end = len(rows)
+			rows := make([]DBAppStream, 0, len(pkgMap))
+			for pkgName, comps := range pkgMap {
+				rows = append(rows, DBAppStream{
+					PkgName:    pkgName,
+					Components: comps,
+				})
 			}
-			if err = tx.Create(rows[i:end]).Error; err != nil {
-				return fmt.Errorf(app.T_("Batch insert error: %w"), err)
+
+			batchSize := 1000
+			for i := 0; i < len(rows); i += batchSize {
+				end := i + batchSize
+				if end > len(rows) {
+					end = len(rows)
+				}
+				if err = tx.Create(rows[i:end]).Error; err != nil {
+					return fmt.Errorf(app.T_("Batch insert error: %w"), err)
+				}
 			}
-		}
-		return nil
+			return nil
+		})
 	})
+	if err != nil {
+		return err
+	}
+
+	app.NotifyDatabaseChanged(s.dbManager, app.DatabaseSystem)
+	return nil
 }
 
 // GetByPkgName возвращает компоненты AppStream для одного пакета.
@@ -271,18 +282,18 @@ func appStreamComponentApplier(query *gorm.DB, f filter.Filter) (*gorm.DB, bool)
 	}
 
 	if subField == "keywords" || subField == "categories" {
-		colExpr, sqlOp, sqlValue := filter.ColOpToSQL("kv.value", f.Op, f.Value)
+		cond, args := searchCondition("kv.value", f.Op, f.Value)
 		return query.Where(
-			fmt.Sprintf(`EXISTS (SELECT 1 FROM json_each(components) AS comp, json_each(json_extract(comp.value, '$.%s')) AS kv WHERE %s %s)`, subField, colExpr, sqlOp),
-			sqlValue,
+			fmt.Sprintf(`EXISTS (SELECT 1 FROM json_each(components) AS comp, json_each(json_extract(comp.value, '$.%s')) AS kv WHERE %s)`, subField, cond),
+			args...,
 		), true
 	}
 
 	if subField == "name" || subField == "summary" || subField == "description" {
-		colExpr, sqlOp, sqlValue := filter.ColOpToSQL("lv.value", f.Op, f.Value)
+		cond, args := searchCondition("lv.value", f.Op, f.Value)
 		return query.Where(
-			fmt.Sprintf(`EXISTS (SELECT 1 FROM json_each(components) AS comp, json_each(json_extract(comp.value, '$.%s')) AS lv WHERE %s %s)`, subField, colExpr, sqlOp),
-			sqlValue,
+			fmt.Sprintf(`EXISTS (SELECT 1 FROM json_each(components) AS comp, json_each(json_extract(comp.value, '$.%s')) AS lv WHERE %s)`, subField, cond),
+			args...,
 		), true
 	}
 
@@ -295,6 +306,24 @@ func appStreamComponentApplier(query *gorm.DB, f filter.Filter) (*gorm.DB, bool)
 	), true
 }
 
+// searchCondition строит условие фильтра по текстовому полю. Для LIKE и CONTAINS значение
+// нормализуется (регистр, транслитерация) и совпадение ищется по любому из вариантов.
+func searchCondition(col string, op filter.Op, value string) (string, []interface{}) {
+	variants := []string{value}
+	if op == filter.OpLike || op == filter.OpContains {
+		variants = helper.SearchVariants(value)
+	}
+
+	conds := make([]string, 0, len(variants))
+	args := make([]interface{}, 0, len(variants))
+	for _, v := range variants {
+		colExpr, sqlOp, sqlValue := filter.ColOpToSQL(col, op, v)
+		conds = append(conds, colExpr+" "+sqlOp)
+		args = append(args, sqlValue)
+	}
+	return "(" + strings.Join(conds, " OR ") + ")", args
+}
+
 // ComponentFields определения полей фильтрации для Component (без префикса).
 var ComponentFields = map[string]filter.FieldConfig{
 	"type":            {DefaultOp: filter.OpEq, AllowedOps: []filter.Op{filter.OpEq, filter.OpNe}, Extra: map[string]any{"type": "STRING", "description": app.T_("Component type")}},
@@ -17,6 +17,7 @@
 package swcat
 
 import (
+	"apm/internal/common/filter"
 	"encoding/json"
 	"encoding/xml"
 	"reflect"
@@ -1015,3 +1016,26 @@ func TestComponentSliceMultipleDesktopApps(t *testing.T) {
 		}
 	}
 }
+
+func TestSearchCondition(t *testing.T) {
+	cond, args := searchCondition("lv.value", filter.OpEq, "Браузер")
+	if cond != "(lv.value = ?)" || !reflect.DeepEqual(args, []interface{}{"Браузер"}) {
+		t.Errorf("equality filter must not be normalized: %s %v", cond, args)
+	}
+
+	cond, args = searchCondition("lv.value", filter.OpLike, "Браузер")
+	if len(args) < 2 {
+		t.Fatalf("expected normalized variants, got %s %v", cond, args)
+	}
+	want := map[interface{}]bool{"%браузер%": false, "%Браузер%": false, "%brauzer%": false}
+	for _, a := range args {
+		if _, ok := want[a]; ok {
+			want[a] = true
+		}
+	}
+	for v, found := range want {
+		if !found {
+			t.Errorf("expected variant %v in %v", v, args)
+		}
+	}
+}
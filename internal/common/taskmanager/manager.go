@@ -0,0 +1,213 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package taskmanager
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/helper"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+// State состояние фоновой задачи
+type State string
+
+const (
+	StateRunning   State = "running"
+	StateCompleted State = "completed"
+	StateFailed    State = "failed"
+	StateCanceled  State = "canceled"
+)
+
+// DefaultHistoryLimit число завершённых задач, результаты которых хранятся для последующего запроса
+const DefaultHistoryLimit = 100
+
+var (
+	ErrNotFound   = errors.New("task not found")
+	ErrNotRunning = errors.New("task is not running")
+)
+
+// Task фоновая задача. Идентификатором служит transaction, с которым задача была запущена.
+type Task struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	State      State      `json:"state"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Result     any        `json:"result,omitempty"`
+	ErrorCode  string     `json:"errorCode,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+type entry struct {
+	task     Task
+	cancel   context.CancelFunc
+	canceled bool
+}
+
+// Manager учитывает фоновые задачи, позволяет отменять их и хранит результаты завершённых.
+type Manager struct {
+	mu      sync.Mutex
+	tasks   map[string]*entry
+	order   []string
+	limit   int
+	nowFunc func() time.Time
+}
+
+// NewManager создаёт менеджер задач
+func NewManager() *Manager {
+	return &Manager{
+		tasks:   make(map[string]*entry),
+		limit:   DefaultHistoryLimit,
+		nowFunc: time.Now,
+	}
+}
+
+// Start регистрирует задачу с transaction из ctx и возвращает контекст, отменяемый через Cancel.
+// Без transaction задача не учитывается и контекст возвращается без изменений.
+func (m *Manager) Start(ctx context.Context, name string) context.Context {
+	id, _ := ctx.Value(helper.TransactionKey).(string)
+	if id == "" {
+		return ctx
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if prev, ok := m.tasks[id]; ok {
+		if prev.task.State == StateRunning {
+			prev.cancel()
+		}
+		m.order = slices.DeleteFunc(m.order, func(s string) bool { return s == id })
+	}
+	m.tasks[id] = &entry{
+		task: Task{
+			ID:        id,
+			Name:      name,
+			State:     StateRunning,
+			StartedAt: m.nowFunc(),
+		},
+		cancel: cancel,
+	}
+	m.order = append(m.order, id)
+	m.prune()
+
+	return ctx
+}
+
+// Finish сохраняет результат задачи с transaction из ctx. Результаты, не относящиеся
+// к запущенной задаче (например, события наблюдения), игнорируются.
+func (m *Manager) Finish(ctx context.Context, result any, err error) {
+	id, _ := ctx.Value(helper.TransactionKey).(string)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.tasks[id]
+	if !ok || e.task.State != StateRunning {
+		return
+	}
+
+	now := m.nowFunc()
+	e.task.FinishedAt = &now
+	e.cancel()
+
+	switch {
+	case e.canceled:
+		e.task.State = StateCanceled
+	case err != nil:
+		e.task.State = StateFailed
+		e.task.Error = err.Error()
+		var apmErr apmerr.APMError
+		if errors.As(err, &apmErr) {
+			e.task.ErrorCode = apmErr.Type
+		}
+	default:
+		e.task.State = StateCompleted
+		e.task.Result = result
+	}
+	m.prune()
+}
+
+// List возвращает задачи в порядке запуска
+func (m *Manager) List() []Task {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tasks := make([]Task, 0, len(m.order))
+	for _, id := range m.order {
+		tasks = append(tasks, m.tasks[id].task)
+	}
+	return tasks
+}
+
+// Get возвращает задачу по идентификатору
+func (m *Manager) Get(id string) (Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.tasks[id]
+	if !ok {
+		return Task{}, ErrNotFound
+	}
+	return e.task, nil
+}
+
+// Cancel отменяет контекст выполняющейся задачи. Задача получает состояние canceled,
+// когда операция завершится; прерывание выполняется на ближайшей проверке контекста.
+func (m *Manager) Cancel(id string) (Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.tasks[id]
+	if !ok {
+		return Task{}, ErrNotFound
+	}
+	if e.task.State != StateRunning {
+		return e.task, ErrNotRunning
+	}
+
+	e.canceled = true
+	e.cancel()
+	return e.task, nil
+}
+
+// prune удаляет самые старые завершённые задачи сверх лимита. Выполняющиеся задачи не удаляются.
+func (m *Manager) prune() {
+	finished := 0
+	for _, id := range m.order {
+		if m.tasks[id].task.State != StateRunning {
+			finished++
+		}
+	}
+
+	for i := 0; finished > m.limit && i < len(m.order); {
+		id := m.order[i]
+		if m.tasks[id].task.State == StateRunning {
+			i++
+			continue
+		}
+		delete(m.tasks, id)
+		m.order = slices.Delete(m.order, i, i+1)
+		finished--
+	}
+}
@@ -0,0 +1,121 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package taskmanager
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/helper"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func txContext(id string) context.Context {
+	return context.WithValue(context.Background(), helper.TransactionKey, id)
+}
+
+func TestManagerLifecycle(t *testing.T) {
+	m := NewManager()
+
+	ctx := m.Start(txContext("tx-1"), "system.Install")
+	tasks := m.List()
+	if len(tasks) != 1 || tasks[0].State != StateRunning || tasks[0].Name != "system.Install" {
+		t.Fatalf("expected one running task, got %+v", tasks)
+	}
+
+	m.Finish(ctx, "done", nil)
+	task, err := m.Get("tx-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.State != StateCompleted || task.Result != "done" || task.FinishedAt == nil {
+		t.Errorf("expected completed task with result, got %+v", task)
+	}
+	if ctx.Err() == nil {
+		t.Error("expected task context to be released after finish")
+	}
+
+	ctx = m.Start(txContext("tx-2"), "system.Remove")
+	m.Finish(ctx, nil, apmerr.New(apmerr.ErrorTypeApt, errors.New("broken")))
+	task, _ = m.Get("tx-2")
+	if task.State != StateFailed || task.ErrorCode != apmerr.ErrorTypeApt || task.Error != "broken" {
+		t.Errorf("expected failed task with error code, got %+v", task)
+	}
+
+	if _, err = m.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestManagerCancel(t *testing.T) {
+	m := NewManager()
+
+	ctx := m.Start(txContext("tx-1"), "system.Upgrade")
+	if _, err := m.Cancel("tx-1"); err != nil {
+		t.Fatal(err)
+	}
+	if ctx.Err() == nil {
+		t.Fatal("expected task context to be canceled")
+	}
+
+	m.Finish(ctx, nil, ctx.Err())
+	task, _ := m.Get("tx-1")
+	if task.State != StateCanceled {
+		t.Errorf("expected canceled state, got %s", task.State)
+	}
+
+	if _, err := m.Cancel("tx-1"); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("expected ErrNotRunning, got %v", err)
+	}
+	if _, err := m.Cancel("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestManagerIgnoresUntracked(t *testing.T) {
+	m := NewManager()
+
+	ctx := context.Background()
+	if m.Start(ctx, "system.Install") != ctx {
+		t.Error("expected context without transaction to be returned unchanged")
+	}
+	m.Finish(txContext("watch"), "change", nil)
+	if len(m.List()) != 0 {
+		t.Errorf("expected no tasks, got %+v", m.List())
+	}
+}
+
+func TestManagerPrune(t *testing.T) {
+	m := NewManager()
+	m.limit = 2
+
+	running := m.Start(txContext("running"), "system.Upgrade")
+	for i := range 4 {
+		ctx := m.Start(txContext(fmt.Sprintf("tx-%d", i)), "system.Install")
+		m.Finish(ctx, nil, nil)
+	}
+
+	tasks := m.List()
+	if len(tasks) != 3 {
+		t.Fatalf("expected running task and 2 finished, got %+v", tasks)
+	}
+	if tasks[0].ID != "running" || tasks[1].ID != "tx-2" || tasks[2].ID != "tx-3" {
+		t.Errorf("expected oldest finished tasks to be pruned, got %+v", tasks)
+	}
+	m.Finish(running, nil, nil)
+}
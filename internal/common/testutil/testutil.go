@@ -10,19 +10,21 @@ import (
 
 // MockConfigManager реализует app.Manager для тестов.
 type MockConfigManager struct {
-	Config *app.Configuration
+	Config  *app.Configuration
+	Version *version.Version
 }
 
 func (m *MockConfigManager) GetConfig() *app.Configuration         { return m.Config }
 func (m *MockConfigManager) GetColors() app.Colors                 { return app.Colors{} }
 func (m *MockConfigManager) SaveConfig(_ *app.Configuration) error { return nil }
 func (m *MockConfigManager) GetConfigPath() string                 { return "" }
-func (m *MockConfigManager) GetParsedVersion() *version.Version    { return nil }
+func (m *MockConfigManager) GetParsedVersion() *version.Version    { return m.Version }
 func (m *MockConfigManager) IsDevMode() bool                       { return false }
 func (m *MockConfigManager) SetFormat(_ string)                    {}
 func (m *MockConfigManager) SetFormatType(_ string)                {}
 func (m *MockConfigManager) SetFields(_ []string)                  {}
 func (m *MockConfigManager) EnableVerbose()                        {}
+func (m *MockConfigManager) EnableAccessible()                     {}
 func (m *MockConfigManager) GetTemporaryImageFile() string         { return "" }
 func (m *MockConfigManager) GetPathImageContainerFile() string     { return "" }
 func (m *MockConfigManager) GetPathImageFile() string              { return "" }
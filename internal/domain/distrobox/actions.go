@@ -21,6 +21,7 @@ import (
 	"apm/internal/common/app"
 	"apm/internal/common/command"
 	"apm/internal/common/filter"
+	"apm/internal/common/helper"
 	"apm/internal/common/icon"
 	"apm/internal/common/reply"
 	"apm/internal/common/sandbox"
@@ -37,6 +38,8 @@ type Actions struct {
 	servicePackage        packageService
 	serviceDistroDatabase distroDBService
 	serviceDistroAPI      distroAPIService
+	serviceStorage        storageService
+	serviceTemplate       templateService
 	iconService           IconServiceProvider
 }
 
@@ -46,7 +49,10 @@ func NewActions(appConfig *app.Config, reporter *reply.Reporter) *Actions {
 	cfg := appConfig.ConfigManager.GetConfig()
 	runner := command.NewRunner(cfg.CommandPrefix, cfg.Verbose)
 	distroPackageSvc := sandbox.NewPackageService(distroDBSvc, runner, reporter)
+	storageSvc := sandbox.NewStorageService(runner, reporter)
+	storageSvc.ApplyDefault(cfg.ContainerStorage)
 	distroAPISvc := sandbox.NewDistroAPIService(runner, reporter)
+	distroAPISvc.SetOsCache(distroDBSvc)
 	iconSvc := icon.NewIconService(appConfig.DatabaseManager, runner, reporter)
 
 	return &Actions{
@@ -55,6 +61,8 @@ func NewActions(appConfig *app.Config, reporter *reply.Reporter) *Actions {
 		servicePackage:        distroPackageSvc,
 		serviceDistroDatabase: distroDBSvc,
 		serviceDistroAPI:      distroAPISvc,
+		serviceStorage:        storageSvc,
+		serviceTemplate:       sandbox.NewTemplateService(sandbox.DefaultTemplateDirs),
 		iconService:           iconSvc,
 	}
 }
@@ -73,6 +81,19 @@ func (a *Actions) GetIconByPackage(_ context.Context, packageName, container str
 	return data, nil
 }
 
+// GetSizedIcon возвращает PNG-иконку пакета, масштабированную до size пикселей. Нулевой size
+// возвращает исходный размер, container можно передать пустым для пакетов хоста.
+func (a *Actions) GetSizedIcon(ctx context.Context, packageName, container string, size int) ([]byte, error) {
+	if size < 0 || size > 1024 {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.TL_(ctx, "Icon size must be between %d and %d"), 0, 1024))
+	}
+	data, err := a.iconService.GetSizedIcon(ctx, packageName, container, size)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, err)
+	}
+	return data, nil
+}
+
 // Update обновляет и синхронизирует список пакетов в контейнере.
 func (a *Actions) Update(ctx context.Context, container string) (*UpdateResponse, error) {
 	osInfo, err := a.validateContainer(ctx, container, false)
@@ -85,8 +106,15 @@ func (a *Actions) Update(ctx context.Context, container string) (*UpdateResponse
 		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
 	}
 
+	// Иконки берутся из SWCatalog только в контейнерах Arch, см. icon.Service.ReloadIcons
+	if osInfo.OS == "Arch" {
+		if _, err = a.iconService.ReloadContainerIcons(ctx, osInfo.ContainerName); err != nil {
+			app.Log.Error(err.Error())
+		}
+	}
+
 	return &UpdateResponse{
-		Message:   app.T_("Package list successfully updated"),
+		Message:   app.TL_(ctx, "Package list successfully updated"),
 		Container: osInfo,
 		Count:     len(packages),
 	}, nil
@@ -94,13 +122,13 @@ func (a *Actions) Update(ctx context.Context, container string) (*UpdateResponse
 
 // Info возвращает информацию о пакете.
 func (a *Actions) Info(ctx context.Context, container string, packageName string) (*InfoResponse, error) {
-	osInfo, err := a.validateContainer(ctx, container)
+	osInfo, err := a.offlineContainer(ctx, container)
 	if err != nil {
 		return nil, err
 	}
 	packageName = strings.TrimSpace(packageName)
 	if packageName == "" {
-		return nil, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.T_("You must specify the package name, for example `%s package`"), "info"))
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.TL_(ctx, "You must specify the package name, for example `%s package`"), "info"))
 	}
 	packageInfo, err := a.servicePackage.GetInfoPackage(ctx, osInfo, packageName)
 	if err != nil {
@@ -108,7 +136,7 @@ func (a *Actions) Info(ctx context.Context, container string, packageName string
 	}
 
 	return &InfoResponse{
-		Message:     app.T_("Package found"),
+		Message:     app.TL_(ctx, "Package found"),
 		PackageInfo: packageInfo,
 	}, nil
 }
@@ -118,7 +146,7 @@ func (a *Actions) Search(ctx context.Context, container string, packageName stri
 	var osInfo sandbox.ContainerInfo
 	var err error
 	if len(container) > 0 {
-		osInfo, err = a.validateContainer(ctx, container)
+		osInfo, err = a.offlineContainer(ctx, container)
 		if err != nil {
 			return nil, err
 		}
@@ -131,7 +159,7 @@ func (a *Actions) Search(ctx context.Context, container string, packageName stri
 
 	packageName = strings.TrimSpace(packageName)
 	if packageName == "" {
-		return nil, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.T_("You must specify the package name, for example `%s package`"), "search"))
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.TL_(ctx, "You must specify the package name, for example `%s package`"), "search"))
 	}
 
 	queryResult, err := a.servicePackage.GetPackageByName(ctx, osInfo, packageName)
@@ -140,8 +168,9 @@ func (a *Actions) Search(ctx context.Context, container string, packageName stri
 	}
 
 	return &SearchResponse{
-		Message:  fmt.Sprintf(app.TN_("%d record found", "%d records found", len(queryResult.Packages)), len(queryResult.Packages)),
+		Message:  fmt.Sprintf(app.TLN_(ctx, "%d record found", "%d records found", len(queryResult.Packages)), len(queryResult.Packages)),
 		Packages: queryResult.Packages,
+		Synced:   a.syncInfo(ctx, osInfo.ContainerName),
 	}, nil
 }
 
@@ -162,7 +191,12 @@ func (a *Actions) List(ctx context.Context, params ListParams) (*ListResponse, e
 	var err error
 
 	if len(params.Container) > 0 {
-		osInfo, err = a.validateContainer(ctx, params.Container)
+		// Принудительное обновление требует живого контейнера, иначе достаточно локальной базы
+		if params.ForceUpdate {
+			osInfo, err = a.validateContainer(ctx, params.Container)
+		} else {
+			osInfo, err = a.offlineContainer(ctx, params.Container)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -188,21 +222,34 @@ func (a *Actions) List(ctx context.Context, params ListParams) (*ListResponse, e
 	}
 
 	return &ListResponse{
-		Message:    fmt.Sprintf(app.TN_("%d record found", "%d records found", len(queryResult.Packages)), len(queryResult.Packages)),
+		Message:    fmt.Sprintf(app.TLN_(ctx, "%d record found", "%d records found", len(queryResult.Packages)), len(queryResult.Packages)),
 		Packages:   queryResult.Packages,
 		TotalCount: queryResult.TotalCount,
+		Synced:     a.syncInfo(ctx, osInfo.ContainerName),
 	}, nil
 }
 
-// Install устанавливает указанный пакет и опционально экспортирует его.
-func (a *Actions) Install(ctx context.Context, container string, packageName string, export bool) (*InstallResponse, error) {
+// InstallOptions задаёт параметры экспорта при установке пакета в контейнер.
+type InstallOptions struct {
+	// Export экспортировать приложение на хост после установки
+	Export bool
+	// ExportMain экспортировать только основной .desktop и исполняемый файл вместо всех файлов пакета
+	ExportMain bool
+	// Label подпись приложения в меню
+	Label string
+	// StripPrefix убрать имя контейнера из идентификатора .desktop файла
+	StripPrefix bool
+}
+
+// Install устанавливает указанный пакет и опционально экспортирует его согласно opts.
+func (a *Actions) Install(ctx context.Context, container string, packageName string, opts InstallOptions) (*InstallResponse, error) {
 	osInfo, err := a.validateContainer(ctx, container)
 	if err != nil {
 		return nil, err
 	}
 	packageName = strings.TrimSpace(packageName)
 	if packageName == "" {
-		return nil, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.T_("You must specify the package name, for example `%s package`"), "install"))
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.TL_(ctx, "You must specify the package name, for example `%s package`"), "install"))
 	}
 
 	packageInfo, err := a.servicePackage.GetInfoPackage(ctx, osInfo, packageName)
@@ -218,20 +265,28 @@ func (a *Actions) Install(ctx context.Context, container string, packageName str
 		a.serviceDistroDatabase.UpdatePackageField(ctx, osInfo.ContainerName, packageName, "installed", true)
 		packageInfo, _ = a.servicePackage.GetInfoPackage(ctx, osInfo, packageName)
 	}
-	if export && !packageInfo.Package.Exporting {
-		errExport := a.serviceDistroAPI.ExportingApp(ctx, osInfo, packageName, packageInfo.DesktopPaths, packageInfo.ConsolePaths, false)
+	var warnings []string
+	if opts.Export && !packageInfo.Package.Exporting {
+		desktopPaths, consolePaths := packageInfo.DesktopPaths, packageInfo.ConsolePaths
+		if opts.ExportMain {
+			desktopPaths, consolePaths = sandbox.MainExportPaths(packageName, desktopPaths, consolePaths)
+		}
+		exportOpts := sandbox.ExportOptions{Label: strings.TrimSpace(opts.Label), StripPrefix: opts.StripPrefix}
+		var errExport error
+		warnings, errExport = a.serviceDistroAPI.ExportingApp(ctx, osInfo, packageName, desktopPaths, consolePaths, false, exportOpts)
 		if errExport != nil {
 			return nil, apmerr.New(apmerr.ErrorTypeContainer, errExport)
 		}
-		if len(packageInfo.DesktopPaths) > 0 || len(packageInfo.ConsolePaths) > 0 {
+		if len(desktopPaths) > 0 || len(consolePaths) > 0 {
 			packageInfo.Package.Exporting = true
 			a.serviceDistroDatabase.UpdatePackageField(ctx, osInfo.ContainerName, packageName, "exporting", true)
 		}
 	}
 
 	return &InstallResponse{
-		Message:     fmt.Sprintf(app.T_("Package %s installed"), packageName),
+		Message:     fmt.Sprintf(app.TL_(ctx, "Package %s installed"), packageName),
 		PackageInfo: packageInfo,
+		Warnings:    warnings,
 	}, nil
 }
 
@@ -244,7 +299,7 @@ func (a *Actions) Remove(ctx context.Context, container string, packageName stri
 
 	packageName = strings.TrimSpace(packageName)
 	if packageName == "" {
-		return nil, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.T_("You must specify the package name, for example `%s package`"), "remove"))
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.TL_(ctx, "You must specify the package name, for example `%s package`"), "remove"))
 	}
 
 	packageInfo, err := a.servicePackage.GetInfoPackage(ctx, osInfo, packageName)
@@ -253,7 +308,7 @@ func (a *Actions) Remove(ctx context.Context, container string, packageName stri
 	}
 
 	if packageInfo.Package.Exporting {
-		_ = a.serviceDistroAPI.ExportingApp(ctx, osInfo, packageName, packageInfo.DesktopPaths, packageInfo.ConsolePaths, true)
+		_, _ = a.serviceDistroAPI.ExportingApp(ctx, osInfo, packageName, packageInfo.DesktopPaths, packageInfo.ConsolePaths, true, sandbox.ExportOptions{})
 		packageInfo.Package.Exporting = false
 		a.serviceDistroDatabase.UpdatePackageField(ctx, osInfo.ContainerName, packageName, "exporting", false)
 	}
@@ -268,20 +323,59 @@ func (a *Actions) Remove(ctx context.Context, container string, packageName stri
 	}
 
 	return &RemoveResponse{
-		Message:     fmt.Sprintf(app.T_("Package %s removed"), packageName),
+		Message:     fmt.Sprintf(app.TL_(ctx, "Package %s removed"), packageName),
 		PackageInfo: packageInfo,
 	}, nil
 }
 
-// ContainerList возвращает список контейнеров.
-func (a *Actions) ContainerList(ctx context.Context) (*ContainerListResponse, error) {
+// Provision устанавливает в контейнер пакеты локали и шрифтов, чтобы экспортированные
+// приложения корректно отображали национальные символы.
+func (a *Actions) Provision(ctx context.Context, container string, locale string, fonts bool) (*ProvisionResponse, error) {
+	locale = strings.TrimSpace(locale)
+	if locale == "" && !fonts {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify --locale and/or --fonts")))
+	}
+	if locale != "" {
+		normalized, err := sandbox.NormalizeLocale(locale)
+		if err != nil {
+			return nil, apmerr.New(apmerr.ErrorTypeValidation, err)
+		}
+		locale = normalized
+	}
+
+	osInfo, err := a.validateContainer(ctx, container, false)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := a.servicePackage.Provision(ctx, osInfo, locale, fonts)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeContainer, err)
+	}
+
+	return &ProvisionResponse{
+		Message:   fmt.Sprintf(app.TL_(ctx, "Container %s provisioned"), osInfo.ContainerName),
+		Container: osInfo,
+		Provision: result,
+	}, nil
+}
+
+// ContainerList возвращает список контейнеров. Сведения об ОС берутся из кеша,
+// refresh сбрасывает кеш и заново опрашивает контейнеры.
+func (a *Actions) ContainerList(ctx context.Context, refresh bool) (*ContainerListResponse, error) {
+	if refresh {
+		if err := a.serviceDistroDatabase.ClearContainerOsCache(ctx); err != nil {
+			return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+		}
+	}
+
 	containers, err := a.serviceDistroAPI.GetContainerList(ctx, true)
 	if err != nil {
 		return nil, apmerr.New(apmerr.ErrorTypeContainer, err)
 	}
 
 	if len(containers) == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeNotFound, errors.New(app.T_("No containers found")))
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, errors.New(app.TL_(ctx, "No containers found")))
 	}
 
 	return &ContainerListResponse{
@@ -294,14 +388,14 @@ func (a *Actions) ContainerAdd(ctx context.Context, image string, name string, a
 	image = strings.TrimSpace(image)
 	name = strings.TrimSpace(name)
 	if image == "" {
-		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.T_("You must specify the image link (--image)")))
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify the image link (--image)")))
 	}
 
 	if name == "" {
-		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.T_("You must specify the container name (--name)")))
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify the container name (--name)")))
 	}
 
-	osInfo, err := a.serviceDistroAPI.CreateContainer(ctx, image, name, additionalPackages, initHooks)
+	osInfo, err := a.serviceDistroAPI.CreateContainer(ctx, image, name, additionalPackages, initHooks, "")
 	if err != nil {
 		return nil, apmerr.New(apmerr.ErrorTypeContainer, err)
 	}
@@ -312,16 +406,100 @@ func (a *Actions) ContainerAdd(ctx context.Context, image string, name string, a
 	}
 
 	return &ContainerAddResponse{
-		Message:       fmt.Sprintf(app.T_("Container %s successfully created"), name),
+		Message:       fmt.Sprintf(app.TL_(ctx, "Container %s successfully created"), name),
 		ContainerInfo: osInfo,
 	}, nil
 }
 
+// ContainerAddTemplate создаёт контейнер по шаблону: образ, пакеты, хуки и домашний каталог берутся из шаблона,
+// после создания устанавливаются и экспортируются пакеты из списка export. Пустое name - имя шаблона.
+func (a *Actions) ContainerAddTemplate(ctx context.Context, templateName string, name string) (*ContainerAddResponse, error) {
+	templateName = strings.TrimSpace(templateName)
+	if templateName == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify the template name (--template)")))
+	}
+
+	tmpl, err := a.serviceTemplate.Get(templateName)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, err)
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = tmpl.Name
+	}
+
+	home, err := tmpl.HomePath(name)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, err)
+	}
+
+	osInfo, err := a.serviceDistroAPI.CreateContainer(ctx, tmpl.Image, name, tmpl.AdditionalPackages(), tmpl.InitHook(), home)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeContainer, err)
+	}
+
+	if _, err = a.servicePackage.UpdatePackages(ctx, osInfo); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+
+	// Ошибка экспорта не отменяет созданный контейнер, а попадает в предупреждения
+	var exported, warnings []string
+	for _, pkg := range tmpl.Export {
+		resp, errInstall := a.Install(ctx, name, pkg, InstallOptions{Export: true})
+		if errInstall != nil {
+			warnings = append(warnings, fmt.Sprintf(app.TL_(ctx, "Failed to export %s: %v"), pkg, errInstall))
+			continue
+		}
+		exported = append(exported, pkg)
+		warnings = append(warnings, resp.Warnings...)
+	}
+
+	return &ContainerAddResponse{
+		Message:       fmt.Sprintf(app.TL_(ctx, "Container %s successfully created"), name),
+		ContainerInfo: osInfo,
+		Template:      tmpl.Name,
+		Exported:      exported,
+		Warnings:      warnings,
+	}, nil
+}
+
+// TemplateList возвращает список шаблонов контейнеров.
+func (a *Actions) TemplateList(_ context.Context) (*TemplateListResponse, error) {
+	templates, err := a.serviceTemplate.List()
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, err)
+	}
+
+	return &TemplateListResponse{
+		Message:   fmt.Sprintf(app.TN_("%d template found", "%d templates found", len(templates)), len(templates)),
+		Templates: templates,
+	}, nil
+}
+
+// TemplateShow возвращает шаблон контейнера по имени.
+func (a *Actions) TemplateShow(_ context.Context, name string) (*TemplateResponse, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.T_("You must specify the template name")))
+	}
+
+	tmpl, err := a.serviceTemplate.Get(name)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, err)
+	}
+
+	return &TemplateResponse{
+		Message:  fmt.Sprintf(app.T_("Template %s"), tmpl.Name),
+		Template: tmpl,
+	}, nil
+}
+
 // ContainerRemove удаляет контейнер по имени.
 func (a *Actions) ContainerRemove(ctx context.Context, name string) (*ContainerRemoveResponse, error) {
 	name = strings.TrimSpace(name)
 	if name == "" {
-		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.T_("You must specify the container name (--name)")))
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify the container name (--name)")))
 	}
 
 	result, err := a.serviceDistroAPI.RemoveContainer(ctx, name)
@@ -331,15 +509,88 @@ func (a *Actions) ContainerRemove(ctx context.Context, name string) (*ContainerR
 
 	err = a.serviceDistroDatabase.DeletePackagesFromContainer(ctx, name)
 	if err != nil {
-		return nil, apmerr.New(apmerr.ErrorTypeDatabase, fmt.Errorf(app.T_("Error deleting container: %v"), err))
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, fmt.Errorf(app.TL_(ctx, "Error deleting container: %v"), err))
 	}
 
 	return &ContainerRemoveResponse{
-		Message:       fmt.Sprintf(app.T_("Container %s successfully deleted"), name),
+		Message:       fmt.Sprintf(app.TL_(ctx, "Container %s successfully deleted"), name),
 		ContainerInfo: result,
 	}, nil
 }
 
+// ContainerStart запускает остановленный контейнер.
+func (a *Actions) ContainerStart(ctx context.Context, name string) (*ContainerStartResponse, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify the container name")))
+	}
+
+	info, err := a.serviceDistroAPI.StartContainer(ctx, name)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeContainer, err)
+	}
+
+	return &ContainerStartResponse{
+		Message:       fmt.Sprintf(app.TL_(ctx, "Container %s started"), name),
+		ContainerInfo: info,
+	}, nil
+}
+
+// ContainerStop останавливает запущенный контейнер.
+func (a *Actions) ContainerStop(ctx context.Context, name string) (*ContainerStopResponse, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify the container name")))
+	}
+
+	info, err := a.serviceDistroAPI.StopContainer(ctx, name)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeContainer, err)
+	}
+
+	return &ContainerStopResponse{
+		Message:       fmt.Sprintf(app.TL_(ctx, "Container %s stopped"), name),
+		ContainerInfo: info,
+	}, nil
+}
+
+// ContainerStatus возвращает состояние контейнера и потребление ресурсов.
+func (a *Actions) ContainerStatus(ctx context.Context, name string) (*ContainerStatusResponse, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify the container name")))
+	}
+
+	info, err := a.serviceDistroAPI.GetContainerStatus(ctx, name)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, err)
+	}
+
+	message := fmt.Sprintf(app.TL_(ctx, "Container %s is stopped"), name)
+	if info.Running {
+		message = fmt.Sprintf(app.TL_(ctx, "Container %s is running"), name)
+	}
+
+	return &ContainerStatusResponse{
+		Message:       message,
+		ContainerInfo: info,
+	}, nil
+}
+
+// SyncIcons синхронизирует иконки пакетов хоста и контейнеров с базой данных.
+func (a *Actions) SyncIcons(ctx context.Context) (*IconSyncResponse, error) {
+	summary, err := a.iconService.ReloadIcons(ctx)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+
+	return &IconSyncResponse{
+		Message: fmt.Sprintf(app.TL_(ctx, "Icons synchronized: %d added, %d updated, %d removed"),
+			summary.Added, summary.Updated, summary.Removed),
+		Summary: summary,
+	}, nil
+}
+
 // GetFilterFields возвращает список свойств для фильтрации. Метод для DBUS
 func (a *Actions) GetFilterFields(_ context.Context) (GetFilterFieldsResponse, error) {
 	return sandbox.DistroFilterConfig.FieldsInfo(), nil
@@ -356,8 +607,8 @@ func (a *Actions) validateDatabase(ctx context.Context) error {
 
 // selectContainerInteractive показывает интерактивный селектор контейнера в TTY-режиме.
 func (a *Actions) selectContainerInteractive(ctx context.Context) (string, error) {
-	if !reply.IsInteractive(a.appConfig) {
-		return "", apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.T_("Required flag %s not set"), "container"))
+	if !reply.IsInteractive(a.appConfig) || helper.AnswerFromContext(ctx) != "" {
+		return "", apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.TL_(ctx, "Required flag %s not set"), "container"))
 	}
 
 	containers, err := a.serviceDistroAPI.GetContainerList(ctx, true)
@@ -394,7 +645,7 @@ func (a *Actions) validateContainer(ctx context.Context, container string, autoU
 	if errInfo != nil {
 		if err := a.serviceDistroDatabase.ContainerDatabaseExist(ctx, container); err == nil {
 			if err = a.serviceDistroDatabase.DeletePackagesFromContainer(ctx, container); err != nil {
-				return sandbox.ContainerInfo{}, apmerr.New(apmerr.ErrorTypeDatabase, fmt.Errorf(app.T_("Failed to delete container records: %w"), err))
+				return sandbox.ContainerInfo{}, apmerr.New(apmerr.ErrorTypeDatabase, fmt.Errorf(app.TL_(ctx, "Failed to delete container records: %w"), err))
 			}
 		}
 
@@ -413,6 +664,27 @@ func (a *Actions) validateContainer(ctx context.Context, container string, autoU
 	return osInfo, nil
 }
 
+// offlineContainer возвращает сведения о контейнере из локальной базы пакетов без обращения к distrobox.
+// Несинхронизированный контейнер проверяется через validateContainer.
+func (a *Actions) offlineContainer(ctx context.Context, container string) (sandbox.ContainerInfo, error) {
+	if synced := a.syncInfo(ctx, strings.TrimSpace(container)); synced != nil {
+		return synced.ContainerInfo(), nil
+	}
+	return a.validateContainer(ctx, container)
+}
+
+// syncInfo возвращает сведения о синхронизации контейнера или nil, если их нет.
+func (a *Actions) syncInfo(ctx context.Context, container string) *sandbox.SyncInfo {
+	if container == "" {
+		return nil
+	}
+	synced, err := a.serviceDistroDatabase.GetContainerSync(ctx, container)
+	if err != nil {
+		return nil
+	}
+	return &synced
+}
+
 // GenerateOnlineDoc запускает веб-сервер с HTML документацией для DBus API
 func (a *Actions) GenerateOnlineDoc(ctx context.Context) error {
 	return startDocServer(ctx)
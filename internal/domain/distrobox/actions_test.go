@@ -2,11 +2,14 @@ package distrobox
 
 import (
 	"apm/internal/common/apmerr"
+	"apm/internal/common/icon"
 	"apm/internal/common/sandbox"
 	"apm/internal/common/testutil"
 	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 )
 
 type mockPackageService struct {
@@ -17,6 +20,13 @@ type mockPackageService struct {
 	removeErr     error
 	installCalled bool
 	removeCalled  bool
+
+	provisionErr    error
+	provisionCalled bool
+	provisionLocale string
+	provisionFonts  bool
+
+	queriedOsInfo sandbox.ContainerInfo
 }
 
 func (m *mockPackageService) UpdatePackages(_ context.Context, _ sandbox.ContainerInfo) ([]sandbox.PackageInfo, error) {
@@ -31,7 +41,8 @@ func (m *mockPackageService) GetPackageByName(_ context.Context, _ sandbox.Conta
 	return m.searchResult, nil
 }
 
-func (m *mockPackageService) GetPackagesQuery(_ context.Context, _ sandbox.ContainerInfo, _ sandbox.PackageQueryBuilder) (sandbox.PackageQueryResult, error) {
+func (m *mockPackageService) GetPackagesQuery(_ context.Context, osInfo sandbox.ContainerInfo, _ sandbox.PackageQueryBuilder) (sandbox.PackageQueryResult, error) {
+	m.queriedOsInfo = osInfo
 	return sandbox.PackageQueryResult{}, nil
 }
 
@@ -45,11 +56,21 @@ func (m *mockPackageService) RemovePackage(_ context.Context, _ sandbox.Containe
 	return m.removeErr
 }
 
+func (m *mockPackageService) Provision(_ context.Context, _ sandbox.ContainerInfo, locale string, fonts bool) (sandbox.ProvisionResult, error) {
+	m.provisionCalled = true
+	m.provisionLocale = locale
+	m.provisionFonts = fonts
+	return sandbox.ProvisionResult{Locale: locale, Fonts: fonts}, m.provisionErr
+}
+
 type mockDistroDBService struct {
 	containerExistErr error
 	deleteErr         error
 	updatedFields     []updatedField
 	deleteCalled      bool
+	cacheCleared      bool
+	synced            map[string]sandbox.SyncInfo
+	snapshots         []sandbox.Snapshot
 }
 
 type updatedField struct {
@@ -68,52 +89,152 @@ func (m *mockDistroDBService) DeletePackagesFromContainer(_ context.Context, _ s
 	return m.deleteErr
 }
 
+func (m *mockDistroDBService) FindPackagesByName(_, _ string) ([]sandbox.PackageInfo, error) {
+	return nil, nil
+}
+
+func (m *mockDistroDBService) ClearContainerOsCache(_ context.Context) error {
+	m.cacheCleared = true
+	return nil
+}
+
 func (m *mockDistroDBService) UpdatePackageField(_ context.Context, containerName, name, fieldName string, value bool) {
 	m.updatedFields = append(m.updatedFields, updatedField{containerName, name, fieldName, value})
 }
 
+func (m *mockDistroDBService) GetContainerSync(_ context.Context, containerName string) (sandbox.SyncInfo, error) {
+	synced, ok := m.synced[containerName]
+	if !ok {
+		return sandbox.SyncInfo{}, errors.New("container is not synced")
+	}
+	return synced, nil
+}
+
+func (m *mockDistroDBService) SaveSnapshot(_ context.Context, snapshot sandbox.Snapshot) error {
+	m.snapshots = append([]sandbox.Snapshot{snapshot}, m.snapshots...)
+	return nil
+}
+
+func (m *mockDistroDBService) GetSnapshots(_ context.Context, containerName string) ([]sandbox.Snapshot, error) {
+	var result []sandbox.Snapshot
+	for _, s := range m.snapshots {
+		if s.Container == containerName {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockDistroDBService) DeleteSnapshot(_ context.Context, containerName, name string) error {
+	for i, s := range m.snapshots {
+		if s.Container == containerName && s.Name == name {
+			m.snapshots = append(m.snapshots[:i], m.snapshots[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
 type mockDistroAPIService struct {
-	osInfo       sandbox.ContainerInfo
-	osInfoErr    error
-	removeResult sandbox.ContainerInfo
-	removeErr    error
-	exportCalled bool
-	exportDelete bool
+	osInfo        sandbox.ContainerInfo
+	osInfoErr     error
+	osInfoCalls   int
+	removeResult  sandbox.ContainerInfo
+	removeErr     error
+	exportCalled  bool
+	exportDelete  bool
+	exportedPaths []string
+	statusResult  sandbox.ContainerInfo
+	stateErr      error
+	startCalled   bool
+	stopCalled    bool
+	containers    []sandbox.ContainerInfo
+	created       [][]string
+	committed     []string
+	restored      string
+	removedImages []string
 }
 
 func (m *mockDistroAPIService) GetContainerList(_ context.Context, _ bool) ([]sandbox.ContainerInfo, error) {
-	return nil, nil
+	return m.containers, nil
 }
 
 func (m *mockDistroAPIService) GetContainerOsInfo(_ context.Context, _ string) (sandbox.ContainerInfo, error) {
+	m.osInfoCalls++
 	return m.osInfo, m.osInfoErr
 }
 
-func (m *mockDistroAPIService) CreateContainer(_ context.Context, _, _, _ string, _ string) (sandbox.ContainerInfo, error) {
-	return sandbox.ContainerInfo{}, nil
+func (m *mockDistroAPIService) CreateContainer(_ context.Context, image, name, addPkg string, hook string, home string) (sandbox.ContainerInfo, error) {
+	m.created = append(m.created, []string{image, name, addPkg, hook, home})
+	return sandbox.ContainerInfo{ContainerName: name, OS: "alt"}, nil
 }
 
 func (m *mockDistroAPIService) RemoveContainer(_ context.Context, _ string) (sandbox.ContainerInfo, error) {
 	return m.removeResult, m.removeErr
 }
 
-func (m *mockDistroAPIService) ExportingApp(_ context.Context, _ sandbox.ContainerInfo, _ string, _, _ []string, deleteApp bool) error {
+func (m *mockDistroAPIService) ExportingApp(_ context.Context, _ sandbox.ContainerInfo, _ string, desktopPaths, consolePaths []string, deleteApp bool, _ sandbox.ExportOptions) ([]string, error) {
 	m.exportCalled = true
+	m.exportedPaths = append(append([]string{}, desktopPaths...), consolePaths...)
 	m.exportDelete = deleteApp
+	return nil, nil
+}
+
+func (m *mockDistroAPIService) StartContainer(_ context.Context, _ string) (sandbox.ContainerInfo, error) {
+	m.startCalled = true
+	return m.statusResult, m.stateErr
+}
+
+func (m *mockDistroAPIService) StopContainer(_ context.Context, _ string) (sandbox.ContainerInfo, error) {
+	m.stopCalled = true
+	return m.statusResult, m.stateErr
+}
+
+func (m *mockDistroAPIService) GetContainerStatus(_ context.Context, _ string) (sandbox.ContainerInfo, error) {
+	return m.statusResult, m.stateErr
+}
+
+func (m *mockDistroAPIService) CloneContainer(_ context.Context, source, name string) (sandbox.ContainerInfo, error) {
+	m.created = append(m.created, []string{source, name})
+	return sandbox.ContainerInfo{ContainerName: name, OS: "alt"}, nil
+}
+
+func (m *mockDistroAPIService) CommitContainer(_ context.Context, _ string, image string) error {
+	m.committed = append(m.committed, image)
+	return nil
+}
+
+func (m *mockDistroAPIService) RestoreContainer(_ context.Context, name, image string) (sandbox.ContainerInfo, error) {
+	m.restored = image
+	return sandbox.ContainerInfo{ContainerName: name, OS: "alt"}, nil
+}
+
+func (m *mockDistroAPIService) RemoveImage(_ context.Context, image string) error {
+	m.removedImages = append(m.removedImages, image)
 	return nil
 }
 
 type mockIconService struct {
-	iconData []byte
-	iconErr  error
+	iconData    []byte
+	iconErr     error
+	syncSummary icon.SyncSummary
+	syncErr     error
 }
 
 func (m *mockIconService) GetIcon(_, _ string) ([]byte, error) {
 	return m.iconData, m.iconErr
 }
 
-func (m *mockIconService) ReloadIcons(_ context.Context) error {
-	return nil
+func (m *mockIconService) GetSizedIcon(_ context.Context, _, _ string, _ int) ([]byte, error) {
+	return m.iconData, m.iconErr
+}
+
+func (m *mockIconService) ReloadIcons(_ context.Context) (icon.SyncSummary, error) {
+	return m.syncSummary, m.syncErr
+}
+
+func (m *mockIconService) ReloadContainerIcons(_ context.Context, _ string) (icon.SyncSummary, error) {
+	return m.syncSummary, m.syncErr
 }
 
 func newTestActions(pkg *mockPackageService, db *mockDistroDBService, api *mockDistroAPIService, ico *mockIconService) *Actions {
@@ -237,7 +358,7 @@ func TestInstall(t *testing.T) {
 			api := defaultAPI()
 			actions := newTestActions(tt.pkg, db, api, nil)
 
-			_, err := actions.Install(context.Background(), "test-container", tt.packageName, tt.export)
+			_, err := actions.Install(context.Background(), "test-container", tt.packageName, InstallOptions{Export: tt.export})
 
 			if tt.wantErr {
 				testutil.AssertAPMError(t, err, tt.wantErrType)
@@ -261,6 +382,76 @@ func TestInstall(t *testing.T) {
 	}
 }
 
+func TestProvision(t *testing.T) {
+	tests := []struct {
+		name        string
+		pkg         *mockPackageService
+		locale      string
+		fonts       bool
+		wantErrType string
+		wantLocale  string
+	}{
+		{
+			name:       "locale without charset defaults to UTF-8",
+			pkg:        &mockPackageService{},
+			locale:     "ru_RU",
+			fonts:      true,
+			wantLocale: "ru_RU.UTF-8",
+		},
+		{
+			name:       "fonts only",
+			pkg:        &mockPackageService{},
+			fonts:      true,
+			wantLocale: "",
+		},
+		{
+			name:        "nothing requested returns validation error",
+			pkg:         &mockPackageService{},
+			wantErrType: apmerr.ErrorTypeValidation,
+		},
+		{
+			name:        "invalid locale returns validation error",
+			pkg:         &mockPackageService{},
+			locale:      "ru_RU.UTF-8; rm -rf /",
+			wantErrType: apmerr.ErrorTypeValidation,
+		},
+		{
+			name:        "provision error returns container error",
+			pkg:         &mockPackageService{provisionErr: errors.New("pacman failed")},
+			locale:      "ru_RU.UTF-8",
+			wantErrType: apmerr.ErrorTypeContainer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actions := newTestActions(tt.pkg, defaultDB(), defaultAPI(), nil)
+
+			resp, err := actions.Provision(context.Background(), "test-container", tt.locale, tt.fonts)
+
+			if tt.wantErrType != "" {
+				testutil.AssertAPMError(t, err, tt.wantErrType)
+				if tt.wantErrType == apmerr.ErrorTypeValidation && tt.pkg.provisionCalled {
+					t.Error("provision must not run on validation error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.pkg.provisionLocale != tt.wantLocale {
+				t.Errorf("locale = %q, want %q", tt.pkg.provisionLocale, tt.wantLocale)
+			}
+			if tt.pkg.provisionFonts != tt.fonts {
+				t.Errorf("fonts = %v, want %v", tt.pkg.provisionFonts, tt.fonts)
+			}
+			if resp.Container.ContainerName != "test-container" {
+				t.Errorf("unexpected container %q", resp.Container.ContainerName)
+			}
+		})
+	}
+}
+
 func TestRemove(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -396,12 +587,38 @@ func TestRemove_ExportingPackage_DBUpdateOrder(t *testing.T) {
 	}
 }
 
+func TestInstall_ExportAll(t *testing.T) {
+	info := sandbox.InfoPackageAnswer{
+		Package:      sandbox.PackageInfo{Name: "libreoffice", Installed: true},
+		DesktopPaths: []string{"/usr/share/applications/libreoffice-writer.desktop", "/usr/share/applications/libreoffice.desktop"},
+		ConsolePaths: []string{"/usr/bin/libreoffice", "/usr/bin/soffice"},
+	}
+
+	api := defaultAPI()
+	actions := newTestActions(&mockPackageService{infoResult: info}, defaultDB(), api, nil)
+	if _, err := actions.Install(context.Background(), "test-container", "libreoffice", InstallOptions{Export: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(api.exportedPaths) != 4 {
+		t.Errorf("expected all desktop files and binaries to be exported, got %v", api.exportedPaths)
+	}
+
+	api = defaultAPI()
+	actions = newTestActions(&mockPackageService{infoResult: info}, defaultDB(), api, nil)
+	if _, err := actions.Install(context.Background(), "test-container", "libreoffice", InstallOptions{Export: true, ExportMain: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(api.exportedPaths) != 1 || api.exportedPaths[0] != "/usr/share/applications/libreoffice.desktop" {
+		t.Errorf("expected only the main desktop file to be exported, got %v", api.exportedPaths)
+	}
+}
+
 func TestInstall_ContainerNotFound_CleansDBAndReturnsError(t *testing.T) {
 	api := &mockDistroAPIService{osInfoErr: errors.New("container not found")}
 	db := &mockDistroDBService{containerExistErr: nil}
 	actions := newTestActions(&mockPackageService{}, db, api, nil)
 
-	_, err := actions.Install(context.Background(), "gone-container", "vim", false)
+	_, err := actions.Install(context.Background(), "gone-container", "vim", InstallOptions{})
 	testutil.AssertAPMError(t, err, apmerr.ErrorTypeNotFound)
 	if !db.deleteCalled {
 		t.Error("should clean DB records for container that no longer exists in distrobox")
@@ -482,3 +699,394 @@ func TestContainerRemove(t *testing.T) {
 		})
 	}
 }
+
+func TestContainerStartStop(t *testing.T) {
+	running := sandbox.ContainerInfo{ContainerName: "mybox", State: sandbox.ContainerStateRunning, Running: true}
+
+	t.Run("start calls API", func(t *testing.T) {
+		api := &mockDistroAPIService{statusResult: running}
+		resp, err := newTestActions(nil, defaultDB(), api, nil).ContainerStart(context.Background(), "mybox")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !api.startCalled || !resp.ContainerInfo.Running {
+			t.Errorf("expected started container, got %+v", resp.ContainerInfo)
+		}
+	})
+
+	t.Run("stop calls API", func(t *testing.T) {
+		api := &mockDistroAPIService{statusResult: sandbox.ContainerInfo{ContainerName: "mybox", State: sandbox.ContainerStateExited}}
+		resp, err := newTestActions(nil, defaultDB(), api, nil).ContainerStop(context.Background(), "mybox")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !api.stopCalled || resp.ContainerInfo.Running {
+			t.Errorf("expected stopped container, got %+v", resp.ContainerInfo)
+		}
+	})
+
+	t.Run("empty name returns validation error", func(t *testing.T) {
+		_, err := newTestActions(nil, defaultDB(), defaultAPI(), nil).ContainerStart(context.Background(), " ")
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+	})
+
+	t.Run("API error returns container error", func(t *testing.T) {
+		api := &mockDistroAPIService{stateErr: errors.New("failed")}
+		_, err := newTestActions(nil, defaultDB(), api, nil).ContainerStop(context.Background(), "mybox")
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeContainer)
+	})
+
+	t.Run("status of missing container returns not found", func(t *testing.T) {
+		api := &mockDistroAPIService{stateErr: errors.New("not found")}
+		_, err := newTestActions(nil, defaultDB(), api, nil).ContainerStatus(context.Background(), "mybox")
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNotFound)
+	})
+}
+
+func TestContainerListRefresh(t *testing.T) {
+	api := &mockDistroAPIService{containers: []sandbox.ContainerInfo{{ContainerName: "mybox"}}}
+
+	db := defaultDB()
+	if _, err := newTestActions(nil, db, api, nil).ContainerList(context.Background(), false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if db.cacheCleared {
+		t.Error("expected cache to be kept without refresh")
+	}
+
+	db = defaultDB()
+	if _, err := newTestActions(nil, db, api, nil).ContainerList(context.Background(), true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !db.cacheCleared {
+		t.Error("expected cache to be cleared on refresh")
+	}
+}
+
+func TestListOfflineSyncedContainer(t *testing.T) {
+	syncedAt := time.Unix(1700000000, 0)
+	db := &mockDistroDBService{synced: map[string]sandbox.SyncInfo{
+		"mybox": {Container: "mybox", OS: "Arch", Count: 42, SyncedAt: syncedAt},
+	}}
+
+	tests := []struct {
+		name        string
+		params      ListParams
+		wantAPICall bool
+		wantOS      string
+	}{
+		{"synced container is served offline", ListParams{Container: "mybox"}, false, "Arch"},
+		{"force update checks container", ListParams{Container: "mybox", ForceUpdate: true}, true, "alt"},
+		{"unsynced container checks container", ListParams{Container: "other"}, true, "alt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkg := &mockPackageService{}
+			api := defaultAPI()
+			resp, err := newTestActions(pkg, db, api, nil).List(context.Background(), tt.params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (api.osInfoCalls > 0) != tt.wantAPICall {
+				t.Errorf("expected distrobox call: %v, got %d calls", tt.wantAPICall, api.osInfoCalls)
+			}
+			if pkg.queriedOsInfo.OS != tt.wantOS {
+				t.Errorf("expected OS %q, got %q", tt.wantOS, pkg.queriedOsInfo.OS)
+			}
+			if !tt.wantAPICall && (resp.Synced == nil || !resp.Synced.SyncedAt.Equal(syncedAt)) {
+				t.Errorf("expected sync info in response, got %+v", resp.Synced)
+			}
+		})
+	}
+}
+
+func TestSearchOfflineSyncedContainer(t *testing.T) {
+	db := &mockDistroDBService{synced: map[string]sandbox.SyncInfo{"mybox": {Container: "mybox", OS: "Arch"}}}
+	api := defaultAPI()
+
+	resp, err := newTestActions(&mockPackageService{}, db, api, nil).Search(context.Background(), "mybox", "vim")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if api.osInfoCalls != 0 {
+		t.Errorf("expected search without entering distrobox, got %d calls", api.osInfoCalls)
+	}
+	if resp.Synced == nil || resp.Synced.Container != "mybox" {
+		t.Errorf("expected sync info in response, got %+v", resp.Synced)
+	}
+}
+
+type mockTemplateService struct {
+	templates []sandbox.Template
+}
+
+func (m *mockTemplateService) List() ([]sandbox.Template, error) {
+	return m.templates, nil
+}
+
+func (m *mockTemplateService) Get(name string) (sandbox.Template, error) {
+	for _, tmpl := range m.templates {
+		if tmpl.Name == name {
+			return tmpl, nil
+		}
+	}
+	return sandbox.Template{}, errors.New("template not found")
+}
+
+func TestContainerAddTemplate(t *testing.T) {
+	templates := &mockTemplateService{templates: []sandbox.Template{{
+		Name:      "go-dev",
+		Image:     "registry.altlinux.org/sisyphus/base:latest",
+		Packages:  []string{"golang", "git"},
+		InitHooks: []string{"echo ok"},
+		Home:      "/srv/homes/{name}",
+		Export:    []string{"code"},
+	}}}
+
+	api := defaultAPI()
+	pkg := &mockPackageService{infoResult: sandbox.InfoPackageAnswer{
+		Package:      sandbox.PackageInfo{Name: "code", Installed: true},
+		DesktopPaths: []string{"/usr/share/applications/code.desktop"},
+	}}
+	actions := newTestActions(pkg, defaultDB(), api, nil)
+	actions.serviceTemplate = templates
+
+	resp, err := actions.ContainerAddTemplate(context.Background(), "go-dev", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"registry.altlinux.org/sisyphus/base:latest", "go-dev", "golang git", "echo ok", "/srv/homes/go-dev"}
+	if len(api.created) != 1 || strings.Join(api.created[0], "|") != strings.Join(want, "|") {
+		t.Errorf("unexpected create arguments: %v", api.created)
+	}
+	if resp.Template != "go-dev" || len(resp.Exported) != 1 || !api.exportCalled {
+		t.Errorf("expected template packages to be exported, got %+v", resp)
+	}
+
+	_, err = actions.ContainerAddTemplate(context.Background(), "missing", "")
+	testutil.AssertAPMError(t, err, apmerr.ErrorTypeNotFound)
+}
+
+func TestContainerClone(t *testing.T) {
+	api := defaultAPI()
+	actions := newTestActions(&mockPackageService{}, defaultDB(), api, nil)
+
+	resp, err := actions.ContainerClone(context.Background(), "dev", "dev-copy")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Source != "dev" || resp.ContainerInfo.ContainerName != "dev-copy" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+
+	_, err = actions.ContainerClone(context.Background(), "dev", "")
+	testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+}
+
+func TestContainerSnapshotRestore(t *testing.T) {
+	api := defaultAPI()
+	db := defaultDB()
+	actions := newTestActions(&mockPackageService{}, db, api, nil)
+	ctx := context.Background()
+
+	_, err := actions.ContainerRestore(ctx, "test-container", "")
+	testutil.AssertAPMError(t, err, apmerr.ErrorTypeNotFound)
+
+	if _, err = actions.ContainerSnapshot(ctx, "test-container", "before"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = actions.ContainerSnapshot(ctx, "test-container", "before")
+	testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+
+	latest, err := actions.ContainerSnapshot(ctx, "test-container", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(api.committed) != 2 || api.committed[1] != latest.Snapshot.Image {
+		t.Errorf("unexpected committed images: %v", api.committed)
+	}
+
+	list, err := actions.ContainerSnapshots(ctx, "test-container")
+	if err != nil || len(list.Snapshots) != 2 {
+		t.Fatalf("expected 2 snapshots, got %+v (%v)", list, err)
+	}
+
+	resp, err := actions.ContainerRestore(ctx, "test-container", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Snapshot.Name != latest.Snapshot.Name || api.restored != latest.Snapshot.Image || !db.deleteCalled {
+		t.Errorf("expected restore from the latest snapshot, got %+v", resp)
+	}
+
+	if _, err = actions.SnapshotRemove(ctx, "test-container", "before"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(db.snapshots) != 1 || len(api.removedImages) != 1 {
+		t.Errorf("expected snapshot and image to be removed, got %v %v", db.snapshots, api.removedImages)
+	}
+}
+
+type mockStorageService struct {
+	info        sandbox.StorageInfo
+	usage       map[string]int64
+	relocateErr error
+	relocated   []sandbox.ContainerInfo
+}
+
+func (m *mockStorageService) Info(_ context.Context) (sandbox.StorageInfo, error) {
+	return m.info, nil
+}
+
+func (m *mockStorageService) DiskUsage(_ context.Context) (map[string]int64, error) {
+	return m.usage, nil
+}
+
+func (m *mockStorageService) Relocate(_ context.Context, path string, containers []sandbox.ContainerInfo) ([]string, error) {
+	if m.relocateErr != nil {
+		return nil, m.relocateErr
+	}
+	m.info.Path = path
+	m.relocated = containers
+	return []string{"mybox"}, nil
+}
+
+func TestStorageSet(t *testing.T) {
+	api := &mockDistroAPIService{containers: []sandbox.ContainerInfo{{ContainerName: "mybox"}}}
+
+	t.Run("relative path returns validation error", func(t *testing.T) {
+		actions := newTestActions(nil, defaultDB(), api, nil)
+		actions.serviceStorage = &mockStorageService{}
+		_, err := actions.StorageSet(context.Background(), "containers")
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+	})
+
+	t.Run("migrates containers", func(t *testing.T) {
+		storage := &mockStorageService{}
+		actions := newTestActions(nil, defaultDB(), api, nil)
+		actions.serviceStorage = storage
+		resp, err := actions.StorageSet(context.Background(), "/data/containers/")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Storage.Path != "/data/containers" || len(storage.relocated) != 1 {
+			t.Errorf("unexpected response: %+v", resp)
+		}
+	})
+
+	t.Run("same location returns no operation", func(t *testing.T) {
+		actions := newTestActions(nil, defaultDB(), api, nil)
+		actions.serviceStorage = &mockStorageService{relocateErr: sandbox.ErrStorageUnchanged}
+		_, err := actions.StorageSet(context.Background(), "/data/containers")
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNoOperation)
+	})
+}
+
+func TestSyncIcons(t *testing.T) {
+	t.Run("returns summary", func(t *testing.T) {
+		ico := &mockIconService{syncSummary: icon.SyncSummary{Added: 2, Updated: 1, Removed: 3}}
+		actions := newTestActions(&mockPackageService{}, defaultDB(), defaultAPI(), ico)
+
+		resp, err := actions.SyncIcons(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Summary != ico.syncSummary {
+			t.Errorf("unexpected summary %+v", resp.Summary)
+		}
+	})
+
+	t.Run("sync error returns database error", func(t *testing.T) {
+		ico := &mockIconService{syncErr: errors.New("swcatalog missing")}
+		actions := newTestActions(&mockPackageService{}, defaultDB(), defaultAPI(), ico)
+
+		_, err := actions.SyncIcons(context.Background())
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeDatabase)
+	})
+}
+
+func TestSimulate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("install lists planned steps without installing", func(t *testing.T) {
+		pkg := &mockPackageService{infoResult: sandbox.InfoPackageAnswer{Package: sandbox.PackageInfo{Name: "htop"}}}
+		db := defaultDB()
+		api := defaultAPI()
+		resp, err := newTestActions(pkg, db, api, nil).CheckInstall(ctx, "test-container", "htop", InstallOptions{Export: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resp.Actions) != 2 {
+			t.Errorf("expected install and export steps, got %v", resp.Actions)
+		}
+		if pkg.installCalled || api.exportCalled || len(db.updatedFields) != 0 {
+			t.Error("simulation must not change the container")
+		}
+	})
+
+	t.Run("installed and exported package returns no operation", func(t *testing.T) {
+		pkg := &mockPackageService{infoResult: sandbox.InfoPackageAnswer{
+			Package: sandbox.PackageInfo{Name: "htop", Installed: true, Exporting: true},
+		}}
+		_, err := newTestActions(pkg, defaultDB(), defaultAPI(), nil).CheckInstall(ctx, "test-container", "htop", InstallOptions{Export: true})
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNoOperation)
+	})
+
+	t.Run("remove of unsynced container does not update packages", func(t *testing.T) {
+		pkg := &mockPackageService{}
+		db := &mockDistroDBService{containerExistErr: errors.New("no records")}
+		_, err := newTestActions(pkg, db, defaultAPI(), nil).CheckRemove(ctx, "test-container", "htop", false)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNoOperation)
+		if pkg.removeCalled || db.deleteCalled {
+			t.Error("simulation must not change the container")
+		}
+	})
+
+	t.Run("start of running container returns no operation", func(t *testing.T) {
+		api := &mockDistroAPIService{statusResult: sandbox.ContainerInfo{ContainerName: "mybox", Running: true}}
+		_, err := newTestActions(nil, defaultDB(), api, nil).CheckContainerStart(ctx, "mybox")
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNoOperation)
+		if api.startCalled {
+			t.Error("simulation must not start the container")
+		}
+	})
+
+	t.Run("create with taken name returns validation error", func(t *testing.T) {
+		api := &mockDistroAPIService{containers: []sandbox.ContainerInfo{{ContainerName: "mybox"}}}
+		_, err := newTestActions(nil, defaultDB(), api, nil).CheckContainerAdd(ctx, "alt:latest", "mybox")
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+		if len(api.created) != 0 {
+			t.Error("simulation must not create containers")
+		}
+	})
+
+	t.Run("snapshot remove keeps the snapshot", func(t *testing.T) {
+		db := &mockDistroDBService{snapshots: []sandbox.Snapshot{{Container: "mybox", Name: "before", Image: "localhost/mybox:before"}}}
+		api := defaultAPI()
+		if _, err := newTestActions(nil, db, api, nil).CheckSnapshotRemove(ctx, "mybox", "before"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(db.snapshots) != 1 || len(api.removedImages) != 0 {
+			t.Error("simulation must not remove snapshots")
+		}
+	})
+
+	t.Run("storage set lists migrated containers", func(t *testing.T) {
+		api := &mockDistroAPIService{containers: []sandbox.ContainerInfo{{ContainerName: "mybox"}}}
+		storage := &mockStorageService{info: sandbox.StorageInfo{Path: "/home/user/.local/share/containers/storage"}}
+		actions := newTestActions(nil, defaultDB(), api, nil)
+		actions.serviceStorage = storage
+
+		resp, err := actions.CheckStorageSet(ctx, "/data/containers")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resp.Actions) != 2 || storage.relocated != nil {
+			t.Errorf("unexpected simulation: %v", resp.Actions)
+		}
+
+		_, err = actions.CheckStorageSet(ctx, storage.info.Path)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNoOperation)
+	})
+}
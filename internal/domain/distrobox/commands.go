@@ -35,14 +35,83 @@ func newErrorResponseFromError(err error) reply.APIResponse {
 	return reply.ErrorResponseFromError(err)
 }
 
+// completeContainers дополняет имена контейнеров для позиционных аргументов, не более maxArgs.
+func completeContainers(appConfig *app.Config, reporter *reply.Reporter, maxArgs int) func(ctx context.Context, cmd *cli.Command) {
+	return func(ctx context.Context, cmd *cli.Command) {
+		if apmcli.CompleteFlags(ctx, cmd) || apmcli.CompletingFlagValue(cmd) || cmd.NArg() > maxArgs {
+			return
+		}
+		printContainers(ctx, NewActions(appConfig, reporter))
+	}
+}
+
+// completePackages дополняет имена пакетов из кеша контейнера, указанного в --container,
+// а при вводе значения --container - имена контейнеров.
+func completePackages(appConfig *app.Config, reporter *reply.Reporter, installed bool) func(ctx context.Context, cmd *cli.Command) {
+	return func(ctx context.Context, cmd *cli.Command) {
+		if apmcli.CompleteFlags(ctx, cmd) {
+			return
+		}
+		actions := NewActions(appConfig, reporter)
+		if apmcli.CompletingFlag("container", "c") {
+			printContainers(ctx, actions)
+			return
+		}
+
+		current, _ := apmcli.CompletionToken()
+		if apmcli.CompletingFlagValue(cmd) || cmd.NArg() > 1 || (current == "" && !installed) {
+			return
+		}
+
+		packages, err := actions.serviceDistroDatabase.FindPackagesByName(cmd.String("container"), current)
+		if err != nil {
+			return
+		}
+		names := make([]string, 0, len(packages))
+		for _, p := range packages {
+			if installed && !p.Installed {
+				continue
+			}
+			names = append(names, p.Name)
+		}
+		apmcli.PrintCompletions(current, names)
+	}
+}
+
+// completeContainerFlag дополняет имена контейнеров в значении флага с одним из имён flagNames.
+func completeContainerFlag(appConfig *app.Config, reporter *reply.Reporter, flagNames ...string) func(ctx context.Context, cmd *cli.Command) {
+	return func(ctx context.Context, cmd *cli.Command) {
+		if !apmcli.CompletingFlag(flagNames...) {
+			cli.DefaultCompleteWithFlags(ctx, cmd)
+			return
+		}
+		printContainers(ctx, NewActions(appConfig, reporter))
+	}
+}
+
+// printContainers выводит имена контейнеров, начинающиеся с дополняемого слова.
+func printContainers(ctx context.Context, actions *Actions) {
+	containers, err := actions.serviceDistroAPI.GetContainerList(ctx, false)
+	if err != nil {
+		return
+	}
+	names := make([]string, 0, len(containers))
+	for _, c := range containers {
+		names = append(names, c.ContainerName)
+	}
+	current, _ := apmcli.CompletionToken()
+	apmcli.PrintCompletions(current, names)
+}
+
 func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 	withGlobalWrapper := apmcli.WithOptions(appConfig, reporter, apmcli.ForbidRoot, NewActions, newErrorResponseFromError)
 
 	return &cli.Command{
-		Name:    "distrobox",
-		Aliases: []string{"d"},
-		Hidden:  !appConfig.ConfigManager.GetConfig().ExistDistrobox,
-		Usage:   app.T_("Managing packages and containers in distrobox"),
+		Name:     "distrobox",
+		Aliases:  []string{"d"},
+		Hidden:   !appConfig.ConfigManager.GetConfig().ExistDistrobox,
+		Usage:    app.T_("Managing packages and containers in distrobox"),
+		Metadata: apmcli.Requires(apmcli.CapHost),
 		Commands: []*cli.Command{
 			{
 				Name:  "update",
@@ -53,8 +122,14 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 						Usage:   app.T_("Container name. Required"),
 						Aliases: []string{"c"},
 					},
+					simulateFlag(),
 				},
 				Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+					if cmd.Bool("simulate") {
+						resp, err := actions.CheckUpdate(ctx, cmd.String("container"))
+						return simulateResponse(ctx, reporter, resp, err)
+					}
+
 					resp, err := actions.Update(ctx, cmd.String("container"))
 					if err != nil {
 						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
@@ -62,6 +137,7 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 
 					return reporter.CliResponse(ctx, reply.OK(resp))
 				}),
+				ShellComplete: completeContainerFlag(appConfig, reporter, "container", "c"),
 			},
 			{
 				Name:      "info",
@@ -83,6 +159,7 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 
 					return reporter.CliResponse(ctx, reply.OK(resp))
 				}),
+				ShellComplete: completePackages(appConfig, reporter, false),
 			},
 			{
 				Name:      "search",
@@ -94,8 +171,13 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 						Usage:   app.T_("Container name. Optional flag"),
 						Aliases: []string{"c"},
 					},
+					apmcli.ColumnsFlag(),
 				},
 				Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+					if _, err := apmcli.ApplyColumns(appConfig, cmd.StringSlice("columns"), sandbox.PackageInfo{}); err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+
 					resp, err := actions.Search(ctx, cmd.String("container"), cmd.Args().First())
 					if err != nil {
 						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
@@ -103,6 +185,7 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 
 					return reporter.CliResponse(ctx, reply.OK(resp))
 				}),
+				ShellComplete: completePackages(appConfig, reporter, false),
 			},
 			{
 				Name:        "list",
@@ -142,6 +225,7 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 						Usage: app.T_("Force update all packages before the request"),
 						Value: false,
 					},
+					apmcli.ColumnsFlag(),
 				},
 				Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
 					filters, err := sandbox.DistroFilterConfig.Parse(cmd.StringSlice("filter"))
@@ -149,6 +233,10 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
 					}
 
+					if _, err = apmcli.ApplyColumns(appConfig, cmd.StringSlice("columns"), sandbox.PackageInfo{}); err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+
 					params := ListParams{
 						Container:   cmd.String("container"),
 						Sort:        cmd.String("sort"),
@@ -166,6 +254,7 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 
 					return reporter.CliResponse(ctx, reply.OK(resp))
 				}),
+				ShellComplete: completeContainerFlag(appConfig, reporter, "container", "c"),
 			},
 			{
 				Name:      "install",
@@ -181,15 +270,41 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 						Name:  "no-export",
 						Usage: app.T_("Do not export package to host"),
 					},
+					&cli.BoolFlag{
+						Name:  "export-main",
+						Usage: app.T_("Export only the main desktop file or binary instead of all package files"),
+					},
+					&cli.StringFlag{
+						Name:    "export-label",
+						Usage:   app.T_("Label appended to the application name in the menu, \"none\" to disable"),
+						Aliases: []string{"postfix"},
+					},
+					&cli.BoolFlag{
+						Name:  "strip-prefix",
+						Usage: app.T_("Remove the container name prefix from exported desktop file IDs"),
+					},
+					simulateFlag(),
 				},
 				Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
-					resp, err := actions.Install(ctx, cmd.String("container"), cmd.Args().First(), !cmd.Bool("no-export"))
+					opts := InstallOptions{
+						Export:      !cmd.Bool("no-export"),
+						ExportMain:  cmd.Bool("export-main"),
+						Label:       cmd.String("export-label"),
+						StripPrefix: cmd.Bool("strip-prefix"),
+					}
+					if cmd.Bool("simulate") {
+						resp, err := actions.CheckInstall(ctx, cmd.String("container"), cmd.Args().First(), opts)
+						return simulateResponse(ctx, reporter, resp, err)
+					}
+
+					resp, err := actions.Install(ctx, cmd.String("container"), cmd.Args().First(), opts)
 					if err != nil {
 						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
 					}
 
 					return reporter.CliResponse(ctx, reply.OK(resp))
 				}),
+				ShellComplete: completePackages(appConfig, reporter, false),
 			},
 			{
 				Name:      "remove",
@@ -205,8 +320,14 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 						Name:  "only-host",
 						Usage: app.T_("Remove only from host, leave package in container"),
 					},
+					simulateFlag(),
 				},
 				Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+					if cmd.Bool("simulate") {
+						resp, err := actions.CheckRemove(ctx, cmd.String("container"), cmd.Args().First(), cmd.Bool("only-host"))
+						return simulateResponse(ctx, reporter, resp, err)
+					}
+
 					resp, err := actions.Remove(ctx, cmd.String("container"), cmd.Args().First(), cmd.Bool("only-host"))
 					if err != nil {
 						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
@@ -214,6 +335,37 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 
 					return reporter.CliResponse(ctx, reply.OK(resp))
 				}),
+				ShellComplete: completePackages(appConfig, reporter, true),
+			},
+			{
+				Name:      "provision",
+				Usage:     app.T_("Install locale and fonts into the container for exported applications"),
+				ArgsUsage: "container",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "locale",
+						Usage: app.T_("Locale to install and generate, for example ru_RU.UTF-8"),
+					},
+					&cli.BoolFlag{
+						Name:  "fonts",
+						Usage: app.T_("Install fonts with Cyrillic support"),
+					},
+					simulateFlag(),
+				},
+				Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+					if cmd.Bool("simulate") {
+						resp, err := actions.CheckProvision(ctx, cmd.Args().First(), cmd.String("locale"), cmd.Bool("fonts"))
+						return simulateResponse(ctx, reporter, resp, err)
+					}
+
+					resp, err := actions.Provision(ctx, cmd.Args().First(), cmd.String("locale"), cmd.Bool("fonts"))
+					if err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+
+					return reporter.CliResponse(ctx, reply.OK(resp))
+				}),
+				ShellComplete: completeContainers(appConfig, reporter, 1),
 			},
 			{
 				Name:     "dbus-doc",
@@ -233,8 +385,15 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 					{
 						Name:  "list",
 						Usage: app.T_("List of containers"),
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "refresh",
+								Usage: app.T_("Re-read OS information from containers instead of the cache"),
+								Value: false,
+							},
+						},
 						Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
-							resp, err := actions.ContainerList(ctx)
+							resp, err := actions.ContainerList(ctx, cmd.Bool("refresh"))
 							if err != nil {
 								return reporter.CliResponse(ctx, newErrorResponseFromError(err))
 							}
@@ -243,23 +402,42 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 						}),
 					},
 					{
-						Name:  "create",
-						Usage: app.T_("Add container"),
+						Name:    "create",
+						Aliases: []string{"add"},
+						Usage:   app.T_("Add container"),
 						Flags: []cli.Flag{
 							&cli.StringFlag{
-								Name:     "image",
-								Usage:    app.T_("Container. Must be specified, options: alt, ubuntu, arch"),
-								Required: true,
+								Name:  "image",
+								Usage: app.T_("Container. Must be specified, options: alt, ubuntu, arch, fedora, opensuse, alpine, void"),
+							},
+							&cli.StringFlag{
+								Name:  "template",
+								Usage: app.T_("Create the container from a template instead of an image"),
 							},
 							&cli.StringFlag{
 								Name:     "name",
 								Usage:    app.T_("Container name"),
 								Required: false,
 							},
+							simulateFlag(),
 						},
 						Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+							if templateVal := cmd.String("template"); templateVal != "" {
+								if cmd.Bool("simulate") {
+									resp, err := actions.CheckContainerAddTemplate(ctx, templateVal, cmd.String("name"))
+									return simulateResponse(ctx, reporter, resp, err)
+								}
+
+								resp, err := actions.ContainerAddTemplate(ctx, templateVal, cmd.String("name"))
+								if err != nil {
+									return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+								}
+
+								return reporter.CliResponse(ctx, reply.OK(resp))
+							}
+
 							imageVal := cmd.String("image")
-							allowedImages := []string{"alt", "ubuntu", "arch"}
+							allowedImages := []string{"alt", "ubuntu", "arch", "fedora", "opensuse", "alpine", "void"}
 							valid := false
 							for _, img := range allowedImages {
 								if imageVal == img {
@@ -269,7 +447,7 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 							}
 							if !valid {
 								return reporter.CliResponse(ctx,
-									newErrorResponseFromError(apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.T_("The value for image must be one of: alt, ubuntu, arch")))))
+									newErrorResponseFromError(apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "The value for image must be one of: alt, ubuntu, arch, fedora, opensuse, alpine, void")))))
 							}
 
 							var imageLink string
@@ -280,6 +458,14 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 								imageLink = "ubuntu:latest"
 							case "alt":
 								imageLink = "registry.altlinux.org/sisyphus/base:latest"
+							case "fedora":
+								imageLink = "registry.fedoraproject.org/fedora-toolbox:latest"
+							case "opensuse":
+								imageLink = "registry.opensuse.org/opensuse/tumbleweed:latest"
+							case "alpine":
+								imageLink = "docker.io/library/alpine:latest"
+							case "void":
+								imageLink = "ghcr.io/void-linux/void-glibc-full:latest"
 							}
 
 							name := "atomic-" + imageVal
@@ -287,6 +473,11 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 								name = cmd.String("name")
 							}
 
+							if cmd.Bool("simulate") {
+								resp, err := actions.CheckContainerAdd(ctx, imageLink, name)
+								return simulateResponse(ctx, reporter, resp, err)
+							}
+
 							resp, err := actions.ContainerAdd(ctx, imageLink, name, "zsh mc nano", "")
 							if err != nil {
 								return reporter.CliResponse(ctx, newErrorResponseFromError(err))
@@ -318,6 +509,7 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 								Name:  "init-hooks",
 								Usage: app.T_("Calling hook to execute commands"),
 							},
+							simulateFlag(),
 						},
 						Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
 							imageVal := cmd.String("image")
@@ -325,6 +517,11 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 							addPkgVal := cmd.String("additional-packages")
 							hookVal := cmd.String("init-hooks")
 
+							if cmd.Bool("simulate") {
+								resp, err := actions.CheckContainerAdd(ctx, imageVal, nameVal)
+								return simulateResponse(ctx, reporter, resp, err)
+							}
+
 							resp, err := actions.ContainerAdd(ctx, imageVal, nameVal, addPkgVal, hookVal)
 							if err != nil {
 								return reporter.CliResponse(ctx, newErrorResponseFromError(err))
@@ -343,13 +540,250 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 								Usage:    app.T_("Container name. Required"),
 								Required: true,
 							},
+							simulateFlag(),
 						},
 						Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+							if cmd.Bool("simulate") {
+								resp, err := actions.CheckContainerRemove(ctx, cmd.String("name"))
+								return simulateResponse(ctx, reporter, resp, err)
+							}
+
 							resp, err := actions.ContainerRemove(ctx, cmd.String("name"))
 							if err != nil {
 								return reporter.CliResponse(ctx, newErrorResponseFromError(err))
 							}
 
+							return reporter.CliResponse(ctx, reply.OK(resp))
+						}),
+						ShellComplete: completeContainerFlag(appConfig, reporter, "name"),
+					},
+					{
+						Name:      "start",
+						Usage:     app.T_("Start container"),
+						ArgsUsage: "name",
+						Flags:     []cli.Flag{simulateFlag()},
+						Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+							if cmd.Bool("simulate") {
+								resp, err := actions.CheckContainerStart(ctx, cmd.Args().First())
+								return simulateResponse(ctx, reporter, resp, err)
+							}
+
+							resp, err := actions.ContainerStart(ctx, cmd.Args().First())
+							if err != nil {
+								return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+							}
+
+							return reporter.CliResponse(ctx, reply.OK(resp))
+						}),
+						ShellComplete: completeContainers(appConfig, reporter, 1),
+					},
+					{
+						Name:      "stop",
+						Usage:     app.T_("Stop container"),
+						ArgsUsage: "name",
+						Flags:     []cli.Flag{simulateFlag()},
+						Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+							if cmd.Bool("simulate") {
+								resp, err := actions.CheckContainerStop(ctx, cmd.Args().First())
+								return simulateResponse(ctx, reporter, resp, err)
+							}
+
+							resp, err := actions.ContainerStop(ctx, cmd.Args().First())
+							if err != nil {
+								return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+							}
+
+							return reporter.CliResponse(ctx, reply.OK(resp))
+						}),
+						ShellComplete: completeContainers(appConfig, reporter, 1),
+					},
+					{
+						Name:      "status",
+						Usage:     app.T_("Container state and resource usage"),
+						ArgsUsage: "name",
+						Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+							resp, err := actions.ContainerStatus(ctx, cmd.Args().First())
+							if err != nil {
+								return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+							}
+
+							return reporter.CliResponse(ctx, reply.OK(resp))
+						}),
+						ShellComplete: completeContainers(appConfig, reporter, 1),
+					},
+					{
+						Name:      "clone",
+						Usage:     app.T_("Clone container together with installed packages"),
+						ArgsUsage: "source name",
+						Flags:     []cli.Flag{simulateFlag()},
+						Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+							if cmd.Bool("simulate") {
+								resp, err := actions.CheckContainerClone(ctx, cmd.Args().Get(0), cmd.Args().Get(1))
+								return simulateResponse(ctx, reporter, resp, err)
+							}
+
+							resp, err := actions.ContainerClone(ctx, cmd.Args().Get(0), cmd.Args().Get(1))
+							if err != nil {
+								return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+							}
+
+							return reporter.CliResponse(ctx, reply.OK(resp))
+						}),
+						ShellComplete: completeContainers(appConfig, reporter, 1),
+					},
+					{
+						Name:      "snapshot",
+						Usage:     app.T_("Save container to a local snapshot image"),
+						ArgsUsage: "container",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "name",
+								Usage: app.T_("Snapshot name, defaults to the creation time"),
+							},
+							simulateFlag(),
+						},
+						Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+							if cmd.Bool("simulate") {
+								resp, err := actions.CheckContainerSnapshot(ctx, cmd.Args().First(), cmd.String("name"))
+								return simulateResponse(ctx, reporter, resp, err)
+							}
+
+							resp, err := actions.ContainerSnapshot(ctx, cmd.Args().First(), cmd.String("name"))
+							if err != nil {
+								return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+							}
+
+							return reporter.CliResponse(ctx, reply.OK(resp))
+						}),
+						ShellComplete: completeContainers(appConfig, reporter, 1),
+					},
+					{
+						Name:      "snapshots",
+						Usage:     app.T_("List container snapshots"),
+						ArgsUsage: "container",
+						Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+							resp, err := actions.ContainerSnapshots(ctx, cmd.Args().First())
+							if err != nil {
+								return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+							}
+
+							return reporter.CliResponse(ctx, reply.OK(resp))
+						}),
+						ShellComplete: completeContainers(appConfig, reporter, 1),
+					},
+					{
+						Name:      "restore",
+						Usage:     app.T_("Recreate container from a snapshot"),
+						ArgsUsage: "container",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "snapshot",
+								Usage: app.T_("Snapshot name, defaults to the latest snapshot"),
+							},
+							simulateFlag(),
+						},
+						Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+							if cmd.Bool("simulate") {
+								resp, err := actions.CheckContainerRestore(ctx, cmd.Args().First(), cmd.String("snapshot"))
+								return simulateResponse(ctx, reporter, resp, err)
+							}
+
+							resp, err := actions.ContainerRestore(ctx, cmd.Args().First(), cmd.String("snapshot"))
+							if err != nil {
+								return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+							}
+
+							return reporter.CliResponse(ctx, reply.OK(resp))
+						}),
+						ShellComplete: completeContainers(appConfig, reporter, 1),
+					},
+					{
+						Name:      "snapshot-remove",
+						Usage:     app.T_("Remove container snapshot"),
+						ArgsUsage: "container name",
+						Flags:     []cli.Flag{simulateFlag()},
+						Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+							if cmd.Bool("simulate") {
+								resp, err := actions.CheckSnapshotRemove(ctx, cmd.Args().Get(0), cmd.Args().Get(1))
+								return simulateResponse(ctx, reporter, resp, err)
+							}
+
+							resp, err := actions.SnapshotRemove(ctx, cmd.Args().Get(0), cmd.Args().Get(1))
+							if err != nil {
+								return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+							}
+
+							return reporter.CliResponse(ctx, reply.OK(resp))
+						}),
+						ShellComplete: completeContainers(appConfig, reporter, 1),
+					},
+				},
+			},
+			{
+				Name:     "template",
+				Usage:    app.T_("Container templates"),
+				Category: app.T_("Container"),
+				Commands: []*cli.Command{
+					{
+						Name:  "list",
+						Usage: app.T_("List container templates"),
+						Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+							resp, err := actions.TemplateList(ctx)
+							if err != nil {
+								return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+							}
+
+							return reporter.CliResponse(ctx, reply.OK(resp))
+						}),
+					},
+					{
+						Name:      "show",
+						Usage:     app.T_("Show container template"),
+						ArgsUsage: "name",
+						Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+							resp, err := actions.TemplateShow(ctx, cmd.Args().First())
+							if err != nil {
+								return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+							}
+
+							return reporter.CliResponse(ctx, reply.OK(resp))
+						}),
+					},
+				},
+			},
+			{
+				Name:     "storage",
+				Usage:    app.T_("Container storage location"),
+				Category: app.T_("Container"),
+				Commands: []*cli.Command{
+					{
+						Name:  "info",
+						Usage: app.T_("Show container storage location and free space"),
+						Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+							resp, err := actions.StorageInfo(ctx)
+							if err != nil {
+								return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+							}
+
+							return reporter.CliResponse(ctx, reply.OK(resp))
+						}),
+					},
+					{
+						Name:      "set",
+						Usage:     app.T_("Move container storage to another directory and migrate existing containers"),
+						ArgsUsage: "path",
+						Flags:     []cli.Flag{simulateFlag()},
+						Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+							if cmd.Bool("simulate") {
+								resp, err := actions.CheckStorageSet(ctx, cmd.Args().First())
+								return simulateResponse(ctx, reporter, resp, err)
+							}
+
+							resp, err := actions.StorageSet(ctx, cmd.Args().First())
+							if err != nil {
+								return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+							}
+
 							return reporter.CliResponse(ctx, reply.OK(resp))
 						}),
 					},
@@ -358,3 +792,21 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 		},
 	}
 }
+
+// simulateFlag флаг, при котором команда только показывает планируемые изменения
+func simulateFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:    "simulate",
+		Usage:   app.T_("Show the changes without applying them"),
+		Aliases: []string{"s"},
+	}
+}
+
+// simulateResponse выводит результат имитации команды
+func simulateResponse(ctx context.Context, reporter *reply.Reporter, resp *SimulateResponse, err error) error {
+	if err != nil {
+		return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+	}
+
+	return reporter.CliResponse(ctx, reply.OK(resp))
+}
@@ -40,9 +40,11 @@ func DBusFactory(appConfig *app.Config, reporter *reply.Reporter) service.DBusMo
 			return service.DBusExport{
 				Object: NewDBusWrapper(actions, ctx),
 				PostExport: func(ctx context.Context) {
-					if err := actions.GetIconService().ReloadIcons(ctx); err != nil {
+					resp, err := actions.SyncIcons(ctx)
+					if err != nil {
 						app.Log.Error(err.Error())
 					}
+					reporter.SendTaskResult(ctx, reply.EventDistroIconSync, resp, err)
 				},
 			}, nil
 		},
@@ -60,6 +62,13 @@ func NewDBusWrapper(a *Actions, ctx context.Context) *DBusWrapper {
 	return &DBusWrapper{actions: a, ctx: ctx}
 }
 
+// WithLocale возвращает копию обёртки, формирующую сообщения на языке клиента lang
+func (w *DBusWrapper) WithLocale(lang string) any {
+	localized := *w
+	localized.ctx = app.WithLocale(w.ctx, lang)
+	return &localized
+}
+
 // GetIconByPackage возвращает иконку приложения. Параметр container можно передать пустым.
 func (w *DBusWrapper) GetIconByPackage(packageName string, container string) ([]byte, *dbus.Error) {
 	ctx := context.WithValue(w.ctx, helper.TransactionKey, "")
@@ -71,6 +80,17 @@ func (w *DBusWrapper) GetIconByPackage(packageName string, container string) ([]
 	return data, nil
 }
 
+// GetIcon возвращает PNG-иконку пакета хоста, масштабированную до size пикселей. При size = 0 возвращается исходный размер.
+func (w *DBusWrapper) GetIcon(name string, size int) ([]byte, *dbus.Error) {
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, "")
+	data, err := w.actions.GetSizedIcon(ctx, name, "", size)
+	if err != nil {
+		return nil, apmerr.DBusError(err)
+	}
+
+	return data, nil
+}
+
 // GetFilterFields возвращает список полей фильтрации для динамического построения фильтров в интерфейсе.
 func (w *DBusWrapper) GetFilterFields(transaction string) (string, *dbus.Error) {
 	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
@@ -94,14 +114,14 @@ func (w *DBusWrapper) Update(container string, transaction string, background bo
 	}
 
 	if background {
-		ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventDistroUpdate)
 		go func() {
 			resp, err := w.actions.Update(ctx, container)
 			w.actions.reporter.SendTaskResult(ctx, reply.EventDistroUpdate, resp, err)
 		}()
 
 		bgResp := BackgroundTaskResponse{
-			Message:     app.T_("Task started in background"),
+			Message:     app.TL_(w.ctx, "Task started in background"),
 			Transaction: transaction,
 		}
 		data, jerr := json.Marshal(reply.OK(bgResp))
@@ -195,7 +215,7 @@ func (w *DBusWrapper) List(container string, sort string, order string, limit in
 // Install устанавливает пакет.
 func (w *DBusWrapper) Install(container string, packageName string, export bool, transaction string) (string, *dbus.Error) {
 	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
-	resp, err := w.actions.Install(ctx, container, packageName, export)
+	resp, err := w.actions.Install(ctx, container, packageName, InstallOptions{Export: export})
 	if err != nil {
 		return "", apmerr.DBusError(err)
 	}
@@ -220,10 +240,38 @@ func (w *DBusWrapper) Remove(container string, packageName string, onlyExport bo
 	return string(data), nil
 }
 
+// Provision устанавливает в контейнер локаль и шрифты.
+func (w *DBusWrapper) Provision(container string, locale string, fonts bool, transaction string) (string, *dbus.Error) {
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.Provision(ctx, container, locale, fonts)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// SyncIcons синхронизирует иконки пакетов и возвращает итог изменений.
+func (w *DBusWrapper) SyncIcons(transaction string) (string, *dbus.Error) {
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.SyncIcons(ctx)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
 // ContainerList возвращает список контейнеров.
 func (w *DBusWrapper) ContainerList(transaction string) (string, *dbus.Error) {
 	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
-	resp, err := w.actions.ContainerList(ctx)
+	resp, err := w.actions.ContainerList(ctx, false)
 	if err != nil {
 		return "", apmerr.DBusError(err)
 	}
@@ -241,14 +289,14 @@ func (w *DBusWrapper) ContainerAdd(image, name, additionalPackages, initHooks st
 	}
 
 	if background {
-		ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventDistroContainerAdd)
 		go func() {
 			resp, err := w.actions.ContainerAdd(ctx, image, name, additionalPackages, initHooks)
 			w.actions.reporter.SendTaskResult(ctx, reply.EventDistroContainerAdd, resp, err)
 		}()
 
 		bgResp := BackgroundTaskResponse{
-			Message:     app.T_("Task started in background"),
+			Message:     app.TL_(w.ctx, "Task started in background"),
 			Transaction: transaction,
 		}
 		data, jerr := json.Marshal(reply.OK(bgResp))
@@ -271,6 +319,70 @@ func (w *DBusWrapper) ContainerAdd(image, name, additionalPackages, initHooks st
 	return string(data), nil
 }
 
+// ContainerAddTemplate создаёт контейнер по шаблону.
+func (w *DBusWrapper) ContainerAddTemplate(template, name string, transaction string, background bool) (string, *dbus.Error) {
+	if transaction == "" {
+		transaction = helper.GenerateTransactionID()
+	}
+
+	if background {
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventDistroContainerAdd)
+		go func() {
+			resp, err := w.actions.ContainerAddTemplate(ctx, template, name)
+			w.actions.reporter.SendTaskResult(ctx, reply.EventDistroContainerAdd, resp, err)
+		}()
+
+		bgResp := BackgroundTaskResponse{
+			Message:     app.TL_(w.ctx, "Task started in background"),
+			Transaction: transaction,
+		}
+		data, jerr := json.Marshal(reply.OK(bgResp))
+		if jerr != nil {
+			return "", dbus.MakeFailedError(jerr)
+		}
+		return string(data), nil
+	}
+
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.ContainerAddTemplate(ctx, template, name)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// TemplateList возвращает список шаблонов контейнеров.
+func (w *DBusWrapper) TemplateList(transaction string) (string, *dbus.Error) {
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.TemplateList(ctx)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// TemplateShow возвращает шаблон контейнера.
+func (w *DBusWrapper) TemplateShow(name string, transaction string) (string, *dbus.Error) {
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.TemplateShow(ctx, name)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
 // ContainerRemove удаляет контейнер.
 func (w *DBusWrapper) ContainerRemove(name string, transaction string) (string, *dbus.Error) {
 	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
@@ -284,3 +396,231 @@ func (w *DBusWrapper) ContainerRemove(name string, transaction string) (string,
 	}
 	return string(data), nil
 }
+
+// ContainerStart запускает контейнер.
+func (w *DBusWrapper) ContainerStart(name string, transaction string) (string, *dbus.Error) {
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.ContainerStart(ctx, name)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// ContainerStop останавливает контейнер.
+func (w *DBusWrapper) ContainerStop(name string, transaction string) (string, *dbus.Error) {
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.ContainerStop(ctx, name)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// ContainerStatus возвращает состояние контейнера.
+func (w *DBusWrapper) ContainerStatus(name string, transaction string) (string, *dbus.Error) {
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.ContainerStatus(ctx, name)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// ContainerClone создаёт копию контейнера.
+func (w *DBusWrapper) ContainerClone(source, name string, transaction string, background bool) (string, *dbus.Error) {
+	if transaction == "" {
+		transaction = helper.GenerateTransactionID()
+	}
+
+	if background {
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventDistroClone)
+		go func() {
+			resp, err := w.actions.ContainerClone(ctx, source, name)
+			w.actions.reporter.SendTaskResult(ctx, reply.EventDistroClone, resp, err)
+		}()
+
+		bgResp := BackgroundTaskResponse{
+			Message:     app.TL_(w.ctx, "Task started in background"),
+			Transaction: transaction,
+		}
+		data, jerr := json.Marshal(reply.OK(bgResp))
+		if jerr != nil {
+			return "", dbus.MakeFailedError(jerr)
+		}
+		return string(data), nil
+	}
+
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.ContainerClone(ctx, source, name)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// ContainerSnapshot сохраняет снимок контейнера.
+func (w *DBusWrapper) ContainerSnapshot(container, name string, transaction string, background bool) (string, *dbus.Error) {
+	if transaction == "" {
+		transaction = helper.GenerateTransactionID()
+	}
+
+	if background {
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventDistroSnapshot)
+		go func() {
+			resp, err := w.actions.ContainerSnapshot(ctx, container, name)
+			w.actions.reporter.SendTaskResult(ctx, reply.EventDistroSnapshot, resp, err)
+		}()
+
+		bgResp := BackgroundTaskResponse{
+			Message:     app.TL_(w.ctx, "Task started in background"),
+			Transaction: transaction,
+		}
+		data, jerr := json.Marshal(reply.OK(bgResp))
+		if jerr != nil {
+			return "", dbus.MakeFailedError(jerr)
+		}
+		return string(data), nil
+	}
+
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.ContainerSnapshot(ctx, container, name)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// ContainerSnapshots возвращает снимки контейнера.
+func (w *DBusWrapper) ContainerSnapshots(container string, transaction string) (string, *dbus.Error) {
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.ContainerSnapshots(ctx, container)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// ContainerRestore пересоздаёт контейнер из снимка.
+func (w *DBusWrapper) ContainerRestore(container, name string, transaction string, background bool) (string, *dbus.Error) {
+	if transaction == "" {
+		transaction = helper.GenerateTransactionID()
+	}
+
+	if background {
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventDistroRestore)
+		go func() {
+			resp, err := w.actions.ContainerRestore(ctx, container, name)
+			w.actions.reporter.SendTaskResult(ctx, reply.EventDistroRestore, resp, err)
+		}()
+
+		bgResp := BackgroundTaskResponse{
+			Message:     app.TL_(w.ctx, "Task started in background"),
+			Transaction: transaction,
+		}
+		data, jerr := json.Marshal(reply.OK(bgResp))
+		if jerr != nil {
+			return "", dbus.MakeFailedError(jerr)
+		}
+		return string(data), nil
+	}
+
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.ContainerRestore(ctx, container, name)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// SnapshotRemove удаляет снимок контейнера.
+func (w *DBusWrapper) SnapshotRemove(container, name string, transaction string) (string, *dbus.Error) {
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.SnapshotRemove(ctx, container, name)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// StorageInfo возвращает расположение хранилища контейнеров.
+func (w *DBusWrapper) StorageInfo(transaction string) (string, *dbus.Error) {
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.StorageInfo(ctx)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// StorageSet переносит хранилище контейнеров в каталог path.
+func (w *DBusWrapper) StorageSet(path string, transaction string, background bool) (string, *dbus.Error) {
+	if transaction == "" {
+		transaction = helper.GenerateTransactionID()
+	}
+
+	if background {
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventDistroStorageSet)
+		go func() {
+			resp, err := w.actions.StorageSet(ctx, path)
+			w.actions.reporter.SendTaskResult(ctx, reply.EventDistroStorageSet, resp, err)
+		}()
+
+		bgResp := BackgroundTaskResponse{
+			Message:     app.TL_(w.ctx, "Task started in background"),
+			Transaction: transaction,
+		}
+		data, jerr := json.Marshal(reply.OK(bgResp))
+		if jerr != nil {
+			return "", dbus.MakeFailedError(jerr)
+		}
+		return string(data), nil
+	}
+
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.StorageSet(ctx, path)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
@@ -39,7 +39,7 @@ func HTTPFactory(appConfig *app.Config, reporter *reply.Reporter) service.HTTPMo
 			return NewHTTPWrapper(actions, appConfig, reporter, ctx).GetEndpoints()
 		},
 		PostInit: func(ctx context.Context) {
-			if err := actions.GetIconService().ReloadIcons(ctx); err != nil {
+			if _, err := actions.SyncIcons(ctx); err != nil {
 				app.Log.Error(err.Error())
 			}
 		},
@@ -184,7 +184,7 @@ func (w *HTTPWrapper) Install(rw http.ResponseWriter, r *http.Request) {
 	}
 
 	var container, packageName string
-	var export bool
+	var opts InstallOptions
 
 	for _, f := range []struct {
 		key    string
@@ -192,7 +192,10 @@ func (w *HTTPWrapper) Install(rw http.ResponseWriter, r *http.Request) {
 	}{
 		{"container", &container},
 		{"package", &packageName},
-		{"export", &export},
+		{"export", &opts.Export},
+		{"exportMain", &opts.ExportMain},
+		{"exportLabel", &opts.Label},
+		{"stripPrefix", &opts.StripPrefix},
 	} {
 		if err = reply.UnmarshalField(body, f.key, f.target); err != nil {
 			reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
@@ -210,7 +213,7 @@ func (w *HTTPWrapper) Install(rw http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := w.CtxWithTransaction(r)
-	resp, err := w.actions.Install(ctx, container, packageName, export)
+	resp, err := w.actions.Install(ctx, container, packageName, opts)
 	if err != nil {
 		reply.WriteHTTPError(rw, err)
 		return
@@ -261,6 +264,45 @@ func (w *HTTPWrapper) Remove(rw http.ResponseWriter, r *http.Request) {
 	w.WriteJSON(rw, reply.OK(resp))
 }
 
+// Provision устанавливает в контейнер локаль и шрифты.
+func (w *HTTPWrapper) Provision(rw http.ResponseWriter, r *http.Request) {
+	body, err := w.ParseBodyParams(r)
+	if err != nil {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
+		return
+	}
+
+	var container, locale string
+	var fonts bool
+
+	for _, f := range []struct {
+		key    string
+		target interface{}
+	}{
+		{"container", &container},
+		{"locale", &locale},
+		{"fonts", &fonts},
+	} {
+		if err = reply.UnmarshalField(body, f.key, f.target); err != nil {
+			reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
+			return
+		}
+	}
+
+	if container == "" {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, errors.New("container is required")))
+		return
+	}
+
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.Provision(ctx, container, locale, fonts)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
 // GetFilterFields возвращает доступные поля фильтрации.
 func (w *HTTPWrapper) GetFilterFields(rw http.ResponseWriter, r *http.Request) {
 	ctx := w.CtxWithTransaction(r)
@@ -288,10 +330,35 @@ func (w *HTTPWrapper) GetIcon(rw http.ResponseWriter, r *http.Request) {
 	_, _ = rw.Write(data)
 }
 
+// GetSizedIcon возвращает PNG-иконку пакета заданного размера.
+func (w *HTTPWrapper) GetSizedIcon(rw http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	size := 0
+	if v := query.Get("size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
+			return
+		}
+		size = n
+	}
+
+	ctx := w.CtxWithTransaction(r)
+	data, err := w.actions.GetSizedIcon(ctx, r.PathValue("pkg"), query.Get("container"), size)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+
+	rw.Header().Set("Content-Type", http.DetectContentType(data))
+	rw.Header().Set("Cache-Control", "max-age=3600")
+	_, _ = rw.Write(data)
+}
+
 // ContainerList возвращает список контейнеров.
 func (w *HTTPWrapper) ContainerList(rw http.ResponseWriter, r *http.Request) {
 	ctx := w.CtxWithTransaction(r)
-	resp, err := w.actions.ContainerList(ctx)
+	resp, err := w.actions.ContainerList(ctx, r.URL.Query().Get("refresh") == "true")
 	if err != nil {
 		reply.WriteHTTPError(rw, err)
 		return
@@ -348,6 +415,65 @@ func (w *HTTPWrapper) ContainerAdd(rw http.ResponseWriter, r *http.Request) {
 	w.WriteJSON(rw, reply.OK(resp))
 }
 
+// ContainerAddTemplate создаёт контейнер по шаблону.
+func (w *HTTPWrapper) ContainerAddTemplate(rw http.ResponseWriter, r *http.Request) {
+	body, err := w.ParseBodyParams(r)
+	if err != nil {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
+		return
+	}
+
+	var template, name string
+	if err = reply.UnmarshalField(body, "template", &template); err != nil {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
+		return
+	}
+	if err = reply.UnmarshalField(body, "name", &name); err != nil {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
+		return
+	}
+	if template == "" {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, errors.New("template is required")))
+		return
+	}
+
+	if w.RunBackground(rw, r, reply.EventDistroContainerAdd, func(ctx context.Context) (interface{}, error) {
+		return w.actions.ContainerAddTemplate(ctx, template, name)
+	}) {
+		return
+	}
+
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.ContainerAddTemplate(ctx, template, name)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// TemplateList возвращает список шаблонов контейнеров.
+func (w *HTTPWrapper) TemplateList(rw http.ResponseWriter, r *http.Request) {
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.TemplateList(ctx)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// TemplateShow возвращает шаблон контейнера.
+func (w *HTTPWrapper) TemplateShow(rw http.ResponseWriter, r *http.Request) {
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.TemplateShow(ctx, r.PathValue("name"))
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
 // ContainerRemove удаляет контейнер.
 func (w *HTTPWrapper) ContainerRemove(rw http.ResponseWriter, r *http.Request) {
 	name := r.PathValue("name")
@@ -365,6 +491,232 @@ func (w *HTTPWrapper) ContainerRemove(rw http.ResponseWriter, r *http.Request) {
 	w.WriteJSON(rw, reply.OK(resp))
 }
 
+// ContainerClone создаёт копию контейнера.
+func (w *HTTPWrapper) ContainerClone(rw http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, errors.New("container name is required")))
+		return
+	}
+
+	body, err := w.ParseBodyParams(r)
+	if err != nil {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
+		return
+	}
+
+	var target string
+	if err = reply.UnmarshalField(body, "target", &target); err != nil {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
+		return
+	}
+	if target == "" {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, errors.New("target is required")))
+		return
+	}
+
+	if w.RunBackground(rw, r, reply.EventDistroClone, func(ctx context.Context) (interface{}, error) {
+		return w.actions.ContainerClone(ctx, name, target)
+	}) {
+		return
+	}
+
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.ContainerClone(ctx, name, target)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// ContainerSnapshot сохраняет снимок контейнера.
+func (w *HTTPWrapper) ContainerSnapshot(rw http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, errors.New("container name is required")))
+		return
+	}
+
+	body, err := w.ParseBodyParams(r)
+	if err != nil {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
+		return
+	}
+
+	var snapshot string
+	if err = reply.UnmarshalField(body, "snapshot", &snapshot); err != nil {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
+		return
+	}
+
+	if w.RunBackground(rw, r, reply.EventDistroSnapshot, func(ctx context.Context) (interface{}, error) {
+		return w.actions.ContainerSnapshot(ctx, name, snapshot)
+	}) {
+		return
+	}
+
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.ContainerSnapshot(ctx, name, snapshot)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// ContainerSnapshots возвращает снимки контейнера.
+func (w *HTTPWrapper) ContainerSnapshots(rw http.ResponseWriter, r *http.Request) {
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.ContainerSnapshots(ctx, r.PathValue("name"))
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// ContainerRestore пересоздаёт контейнер из снимка.
+func (w *HTTPWrapper) ContainerRestore(rw http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, errors.New("container name is required")))
+		return
+	}
+
+	body, err := w.ParseBodyParams(r)
+	if err != nil {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
+		return
+	}
+
+	var snapshot string
+	if err = reply.UnmarshalField(body, "snapshot", &snapshot); err != nil {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
+		return
+	}
+
+	if w.RunBackground(rw, r, reply.EventDistroRestore, func(ctx context.Context) (interface{}, error) {
+		return w.actions.ContainerRestore(ctx, name, snapshot)
+	}) {
+		return
+	}
+
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.ContainerRestore(ctx, name, snapshot)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// SnapshotRemove удаляет снимок контейнера.
+func (w *HTTPWrapper) SnapshotRemove(rw http.ResponseWriter, r *http.Request) {
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.SnapshotRemove(ctx, r.PathValue("name"), r.PathValue("snapshot"))
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// StorageInfo возвращает расположение хранилища контейнеров.
+func (w *HTTPWrapper) StorageInfo(rw http.ResponseWriter, r *http.Request) {
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.StorageInfo(ctx)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// StorageSet переносит хранилище контейнеров.
+func (w *HTTPWrapper) StorageSet(rw http.ResponseWriter, r *http.Request) {
+	body, err := w.ParseBodyParams(r)
+	if err != nil {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
+		return
+	}
+
+	var path string
+	if err = reply.UnmarshalField(body, "path", &path); err != nil {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
+		return
+	}
+	if path == "" {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, errors.New("path is required")))
+		return
+	}
+
+	if w.RunBackground(rw, r, reply.EventDistroStorageSet, func(ctx context.Context) (interface{}, error) {
+		return w.actions.StorageSet(ctx, path)
+	}) {
+		return
+	}
+
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.StorageSet(ctx, path)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// ContainerStart запускает контейнер.
+func (w *HTTPWrapper) ContainerStart(rw http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, errors.New("container name is required")))
+		return
+	}
+
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.ContainerStart(ctx, name)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// ContainerStop останавливает контейнер.
+func (w *HTTPWrapper) ContainerStop(rw http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, errors.New("container name is required")))
+		return
+	}
+
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.ContainerStop(ctx, name)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// ContainerStatus возвращает состояние контейнера.
+func (w *HTTPWrapper) ContainerStatus(rw http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, errors.New("container name is required")))
+		return
+	}
+
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.ContainerStatus(ctx, name)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
 // GetEndpoints возвращает описания endpoints с handler
 func (w *HTTPWrapper) GetEndpoints() []http_server.Endpoint {
 	return []http_server.Endpoint{
@@ -420,6 +772,20 @@ func (w *HTTPWrapper) GetEndpoints() []http_server.Endpoint {
 				{Name: "container", Type: "string", Required: false, Description: "Имя контейнера"},
 			},
 		},
+		{
+			Handler:     w.GetSizedIcon,
+			HTTPMethod:  "GET",
+			HTTPPath:    "/api/v1/icons/{pkg}",
+			ContentType: "image/png",
+			Permission:  http_server.PermRead,
+			Summary:     "Получить иконку пакета заданного размера",
+			Tags:        []string{"icons"},
+			PathParams:  []string{"pkg"},
+			QueryParams: []http_server.QueryParam{
+				{Name: "size", Type: "integer", Required: false, Description: "Размер в пикселях, 0 - исходный"},
+				{Name: "container", Type: "string", Required: false, Description: "Имя контейнера"},
+			},
+		},
 		{
 			Handler:      w.Search,
 			HTTPMethod:   "GET",
@@ -469,6 +835,9 @@ func (w *HTTPWrapper) GetEndpoints() []http_server.Endpoint {
 				{Name: "container", Source: "body", Type: "string", ArgIndex: 1},
 				{Name: "package", Source: "body", Type: "string", ArgIndex: 2},
 				{Name: "export", Source: "body", Type: "bool", Default: "false", ArgIndex: 3},
+				{Name: "exportMain", Source: "body", Type: "bool", Default: "false", ArgIndex: 4},
+				{Name: "exportLabel", Source: "body", Type: "string", Default: "", ArgIndex: 5},
+				{Name: "stripPrefix", Source: "body", Type: "bool", Default: "false", ArgIndex: 6},
 			},
 		},
 		{
@@ -485,6 +854,20 @@ func (w *HTTPWrapper) GetEndpoints() []http_server.Endpoint {
 				{Name: "onlyExport", Source: "body", Type: "bool", Default: "false", ArgIndex: 3},
 			},
 		},
+		{
+			Handler:      w.Provision,
+			HTTPMethod:   "POST",
+			HTTPPath:     "/api/v1/distrobox/provision",
+			ResponseType: reflect.TypeOf(ProvisionResponse{}),
+			Permission:   http_server.PermManage,
+			Summary:      "Установить локаль и шрифты в контейнер",
+			Tags:         []string{"distrobox"},
+			ParamMappings: []http_server.ParamMapping{
+				{Name: "container", Source: "body", Type: "string", ArgIndex: 1},
+				{Name: "locale", Source: "body", Type: "string", ArgIndex: 2},
+				{Name: "fonts", Source: "body", Type: "bool", Default: "false", ArgIndex: 3},
+			},
+		},
 
 		// Контейнеры
 		{
@@ -495,6 +878,9 @@ func (w *HTTPWrapper) GetEndpoints() []http_server.Endpoint {
 			Permission:   http_server.PermRead,
 			Summary:      "Получить список контейнеров",
 			Tags:         []string{"distrobox"},
+			QueryParams: []http_server.QueryParam{
+				{Name: "refresh", Type: "boolean", Required: false, Description: "Заново опросить контейнеры вместо кеша"},
+			},
 		},
 		{
 			Handler:      w.ContainerAdd,
@@ -514,6 +900,41 @@ func (w *HTTPWrapper) GetEndpoints() []http_server.Endpoint {
 				{Name: "background", Type: "boolean", Required: false, Description: "Выполнить в фоне (результат придёт через WebSocket)"},
 			},
 		},
+		{
+			Handler:      w.ContainerAddTemplate,
+			HTTPMethod:   "POST",
+			HTTPPath:     "/api/v1/distrobox/containers/template",
+			ResponseType: reflect.TypeOf(ContainerAddResponse{}),
+			Permission:   http_server.PermManage,
+			Summary:      "Создать контейнер по шаблону",
+			Tags:         []string{"distrobox"},
+			ParamMappings: []http_server.ParamMapping{
+				{Name: "template", Source: "body", Type: "string", ArgIndex: 1},
+				{Name: "name", Source: "body", Type: "string", Default: "", ArgIndex: 2},
+			},
+			QueryParams: []http_server.QueryParam{
+				{Name: "background", Type: "boolean", Required: false, Description: "Выполнить в фоне (результат придёт через WebSocket)"},
+			},
+		},
+		{
+			Handler:      w.TemplateList,
+			HTTPMethod:   "GET",
+			HTTPPath:     "/api/v1/distrobox/templates",
+			ResponseType: reflect.TypeOf(TemplateListResponse{}),
+			Permission:   http_server.PermRead,
+			Summary:      "Получить список шаблонов контейнеров",
+			Tags:         []string{"distrobox"},
+		},
+		{
+			Handler:      w.TemplateShow,
+			HTTPMethod:   "GET",
+			HTTPPath:     "/api/v1/distrobox/templates/{name}",
+			ResponseType: reflect.TypeOf(TemplateResponse{}),
+			Permission:   http_server.PermRead,
+			Summary:      "Получить шаблон контейнера",
+			Tags:         []string{"distrobox"},
+			PathParams:   []string{"name"},
+		},
 		{
 			Handler:      w.ContainerRemove,
 			HTTPMethod:   "DELETE",
@@ -524,5 +945,127 @@ func (w *HTTPWrapper) GetEndpoints() []http_server.Endpoint {
 			Tags:         []string{"distrobox"},
 			PathParams:   []string{"name"},
 		},
+		{
+			Handler:      w.ContainerStatus,
+			HTTPMethod:   "GET",
+			HTTPPath:     "/api/v1/distrobox/containers/{name}",
+			ResponseType: reflect.TypeOf(ContainerStatusResponse{}),
+			Permission:   http_server.PermRead,
+			Summary:      "Получить состояние контейнера",
+			Tags:         []string{"distrobox"},
+			PathParams:   []string{"name"},
+		},
+		{
+			Handler:      w.ContainerStart,
+			HTTPMethod:   "POST",
+			HTTPPath:     "/api/v1/distrobox/containers/{name}/start",
+			ResponseType: reflect.TypeOf(ContainerStartResponse{}),
+			Permission:   http_server.PermManage,
+			Summary:      "Запустить контейнер",
+			Tags:         []string{"distrobox"},
+			PathParams:   []string{"name"},
+		},
+		{
+			Handler:      w.ContainerStop,
+			HTTPMethod:   "POST",
+			HTTPPath:     "/api/v1/distrobox/containers/{name}/stop",
+			ResponseType: reflect.TypeOf(ContainerStopResponse{}),
+			Permission:   http_server.PermManage,
+			Summary:      "Остановить контейнер",
+			Tags:         []string{"distrobox"},
+			PathParams:   []string{"name"},
+		},
+		{
+			Handler:      w.ContainerClone,
+			HTTPMethod:   "POST",
+			HTTPPath:     "/api/v1/distrobox/containers/{name}/clone",
+			ResponseType: reflect.TypeOf(ContainerCloneResponse{}),
+			Permission:   http_server.PermManage,
+			Summary:      "Создать копию контейнера",
+			Tags:         []string{"distrobox"},
+			PathParams:   []string{"name"},
+			ParamMappings: []http_server.ParamMapping{
+				{Name: "target", Source: "body", Type: "string", ArgIndex: 2},
+			},
+			QueryParams: []http_server.QueryParam{
+				{Name: "background", Type: "boolean", Required: false, Description: "Выполнить в фоне (результат придёт через WebSocket)"},
+			},
+		},
+		{
+			Handler:      w.ContainerSnapshots,
+			HTTPMethod:   "GET",
+			HTTPPath:     "/api/v1/distrobox/containers/{name}/snapshots",
+			ResponseType: reflect.TypeOf(SnapshotListResponse{}),
+			Permission:   http_server.PermRead,
+			Summary:      "Получить список снимков контейнера",
+			Tags:         []string{"distrobox"},
+			PathParams:   []string{"name"},
+		},
+		{
+			Handler:      w.ContainerSnapshot,
+			HTTPMethod:   "POST",
+			HTTPPath:     "/api/v1/distrobox/containers/{name}/snapshots",
+			ResponseType: reflect.TypeOf(SnapshotResponse{}),
+			Permission:   http_server.PermManage,
+			Summary:      "Сохранить снимок контейнера",
+			Tags:         []string{"distrobox"},
+			PathParams:   []string{"name"},
+			ParamMappings: []http_server.ParamMapping{
+				{Name: "snapshot", Source: "body", Type: "string", Default: "", ArgIndex: 2},
+			},
+			QueryParams: []http_server.QueryParam{
+				{Name: "background", Type: "boolean", Required: false, Description: "Выполнить в фоне (результат придёт через WebSocket)"},
+			},
+		},
+		{
+			Handler:      w.SnapshotRemove,
+			HTTPMethod:   "DELETE",
+			HTTPPath:     "/api/v1/distrobox/containers/{name}/snapshots/{snapshot}",
+			ResponseType: reflect.TypeOf(SnapshotResponse{}),
+			Permission:   http_server.PermManage,
+			Summary:      "Удалить снимок контейнера",
+			Tags:         []string{"distrobox"},
+			PathParams:   []string{"name", "snapshot"},
+		},
+		{
+			Handler:      w.ContainerRestore,
+			HTTPMethod:   "POST",
+			HTTPPath:     "/api/v1/distrobox/containers/{name}/restore",
+			ResponseType: reflect.TypeOf(ContainerRestoreResponse{}),
+			Permission:   http_server.PermManage,
+			Summary:      "Пересоздать контейнер из снимка",
+			Tags:         []string{"distrobox"},
+			PathParams:   []string{"name"},
+			ParamMappings: []http_server.ParamMapping{
+				{Name: "snapshot", Source: "body", Type: "string", Default: "", ArgIndex: 2},
+			},
+			QueryParams: []http_server.QueryParam{
+				{Name: "background", Type: "boolean", Required: false, Description: "Выполнить в фоне (результат придёт через WebSocket)"},
+			},
+		},
+		{
+			Handler:      w.StorageInfo,
+			HTTPMethod:   "GET",
+			HTTPPath:     "/api/v1/distrobox/storage",
+			ResponseType: reflect.TypeOf(StorageResponse{}),
+			Permission:   http_server.PermRead,
+			Summary:      "Получить расположение хранилища контейнеров",
+			Tags:         []string{"distrobox"},
+		},
+		{
+			Handler:      w.StorageSet,
+			HTTPMethod:   "PUT",
+			HTTPPath:     "/api/v1/distrobox/storage",
+			ResponseType: reflect.TypeOf(StorageSetResponse{}),
+			Permission:   http_server.PermManage,
+			Summary:      "Перенести хранилище контейнеров в другой каталог",
+			Tags:         []string{"distrobox"},
+			ParamMappings: []http_server.ParamMapping{
+				{Name: "path", Source: "body", Type: "string", ArgIndex: 1},
+			},
+			QueryParams: []http_server.QueryParam{
+				{Name: "background", Type: "boolean", Required: false, Description: "Выполнить в фоне (результат придёт через WebSocket)"},
+			},
+		},
 	}
 }
@@ -17,6 +17,7 @@
 package distrobox
 
 import (
+	"apm/internal/common/icon"
 	"apm/internal/common/sandbox"
 	"context"
 )
@@ -29,6 +30,7 @@ type packageService interface {
 	GetPackagesQuery(ctx context.Context, osInfo sandbox.ContainerInfo, builder sandbox.PackageQueryBuilder) (sandbox.PackageQueryResult, error)
 	InstallPackage(ctx context.Context, osInfo sandbox.ContainerInfo, packageName string) error
 	RemovePackage(ctx context.Context, osInfo sandbox.ContainerInfo, packageName string) error
+	Provision(ctx context.Context, osInfo sandbox.ContainerInfo, locale string, fonts bool) (sandbox.ProvisionResult, error)
 }
 
 // distroDBService определяет методы для работы с базой данных контейнеров.
@@ -36,20 +38,48 @@ type distroDBService interface {
 	DatabaseExist(ctx context.Context) error
 	ContainerDatabaseExist(ctx context.Context, containerName string) error
 	DeletePackagesFromContainer(ctx context.Context, containerName string) error
+	FindPackagesByName(containerName, partialName string) ([]sandbox.PackageInfo, error)
 	UpdatePackageField(ctx context.Context, containerName, name, fieldName string, value bool)
+	ClearContainerOsCache(ctx context.Context) error
+	GetContainerSync(ctx context.Context, containerName string) (sandbox.SyncInfo, error)
+	SaveSnapshot(ctx context.Context, snapshot sandbox.Snapshot) error
+	GetSnapshots(ctx context.Context, containerName string) ([]sandbox.Snapshot, error)
+	DeleteSnapshot(ctx context.Context, containerName, name string) error
 }
 
 // distroAPIService определяет методы для взаимодействия с distrobox CLI.
 type distroAPIService interface {
 	GetContainerList(ctx context.Context, getFullInfo bool) ([]sandbox.ContainerInfo, error)
 	GetContainerOsInfo(ctx context.Context, containerName string) (sandbox.ContainerInfo, error)
-	CreateContainer(ctx context.Context, image, containerName string, addPkg string, hook string) (sandbox.ContainerInfo, error)
+	CreateContainer(ctx context.Context, image, containerName string, addPkg string, hook string, home string) (sandbox.ContainerInfo, error)
 	RemoveContainer(ctx context.Context, containerName string) (sandbox.ContainerInfo, error)
-	ExportingApp(ctx context.Context, containerInfo sandbox.ContainerInfo, packageName string, desktopPaths, consolePaths []string, deleteApp bool) error
+	StartContainer(ctx context.Context, containerName string) (sandbox.ContainerInfo, error)
+	StopContainer(ctx context.Context, containerName string) (sandbox.ContainerInfo, error)
+	GetContainerStatus(ctx context.Context, containerName string) (sandbox.ContainerInfo, error)
+	ExportingApp(ctx context.Context, containerInfo sandbox.ContainerInfo, packageName string, desktopPaths, consolePaths []string, deleteApp bool, opts sandbox.ExportOptions) ([]string, error)
+	CloneContainer(ctx context.Context, source, containerName string) (sandbox.ContainerInfo, error)
+	CommitContainer(ctx context.Context, containerName, image string) error
+	RestoreContainer(ctx context.Context, containerName, image string) (sandbox.ContainerInfo, error)
+	RemoveImage(ctx context.Context, image string) error
+}
+
+// storageService определяет методы для управления хранилищем контейнеров.
+type storageService interface {
+	Info(ctx context.Context) (sandbox.StorageInfo, error)
+	DiskUsage(ctx context.Context) (map[string]int64, error)
+	Relocate(ctx context.Context, path string, containers []sandbox.ContainerInfo) ([]string, error)
+}
+
+// templateService определяет методы для чтения шаблонов контейнеров.
+type templateService interface {
+	List() ([]sandbox.Template, error)
+	Get(name string) (sandbox.Template, error)
 }
 
 // IconServiceProvider определяет методы для работы с иконками пакетов.
 type IconServiceProvider interface {
 	GetIcon(pkgName, container string) ([]byte, error)
-	ReloadIcons(ctx context.Context) error
+	GetSizedIcon(ctx context.Context, pkgName, container string, size int) ([]byte, error)
+	ReloadIcons(ctx context.Context) (icon.SyncSummary, error)
+	ReloadContainerIcons(ctx context.Context, container string) (icon.SyncSummary, error)
 }
@@ -18,6 +18,7 @@ package distrobox
 
 import (
 	"apm/internal/common/filter"
+	"apm/internal/common/icon"
 	"apm/internal/common/sandbox"
 )
 
@@ -38,6 +39,7 @@ type InfoResponse struct {
 type SearchResponse struct {
 	Message  string                `json:"message"`
 	Packages []sandbox.PackageInfo `json:"packages"`
+	Synced   *sandbox.SyncInfo     `json:"synced,omitempty"`
 }
 
 // ListFiltersBody тело запроса для List — только фильтры.
@@ -50,12 +52,14 @@ type ListResponse struct {
 	Message    string                `json:"message"`
 	Packages   []sandbox.PackageInfo `json:"packages"`
 	TotalCount int                   `json:"totalCount"`
+	Synced     *sandbox.SyncInfo     `json:"synced,omitempty"`
 }
 
 // InstallResponse структура ответа для Install метода
 type InstallResponse struct {
 	Message     string                    `json:"message"`
 	PackageInfo sandbox.InfoPackageAnswer `json:"packageInfo"`
+	Warnings    []string                  `json:"warnings,omitempty"`
 }
 
 // RemoveResponse структура ответа для Remove метода
@@ -64,6 +68,19 @@ type RemoveResponse struct {
 	PackageInfo sandbox.InfoPackageAnswer `json:"packageInfo"`
 }
 
+// ProvisionResponse структура ответа для Provision метода
+type ProvisionResponse struct {
+	Message   string                  `json:"message"`
+	Container sandbox.ContainerInfo   `json:"container"`
+	Provision sandbox.ProvisionResult `json:"provision"`
+}
+
+// IconSyncResponse структура ответа для SyncIcons метода
+type IconSyncResponse struct {
+	Message string           `json:"message"`
+	Summary icon.SyncSummary `json:"summary"`
+}
+
 // ContainerListResponse структура ответа для ContainerList метода
 type ContainerListResponse struct {
 	Containers []sandbox.ContainerInfo `json:"containers"`
@@ -73,6 +90,21 @@ type ContainerListResponse struct {
 type ContainerAddResponse struct {
 	Message       string                `json:"message"`
 	ContainerInfo sandbox.ContainerInfo `json:"containerInfo"`
+	Template      string                `json:"template,omitempty"`
+	Exported      []string              `json:"exported,omitempty"`
+	Warnings      []string              `json:"warnings,omitempty"`
+}
+
+// TemplateListResponse структура ответа для TemplateList метода
+type TemplateListResponse struct {
+	Message   string             `json:"message"`
+	Templates []sandbox.Template `json:"templates"`
+}
+
+// TemplateResponse структура ответа для TemplateShow метода
+type TemplateResponse struct {
+	Message  string           `json:"message"`
+	Template sandbox.Template `json:"template"`
 }
 
 // ContainerRemoveResponse структура ответа для ContainerRemove метода
@@ -81,6 +113,64 @@ type ContainerRemoveResponse struct {
 	ContainerInfo sandbox.ContainerInfo `json:"containerInfo"`
 }
 
+// ContainerCloneResponse структура ответа для ContainerClone метода
+type ContainerCloneResponse struct {
+	Message       string                `json:"message"`
+	Source        string                `json:"source"`
+	ContainerInfo sandbox.ContainerInfo `json:"containerInfo"`
+}
+
+// SnapshotResponse структура ответа для ContainerSnapshot и SnapshotRemove методов
+type SnapshotResponse struct {
+	Message  string           `json:"message"`
+	Snapshot sandbox.Snapshot `json:"snapshot"`
+}
+
+// SnapshotListResponse структура ответа для ContainerSnapshots метода
+type SnapshotListResponse struct {
+	Message   string             `json:"message"`
+	Snapshots []sandbox.Snapshot `json:"snapshots"`
+}
+
+// ContainerRestoreResponse структура ответа для ContainerRestore метода
+type ContainerRestoreResponse struct {
+	Message       string                `json:"message"`
+	Snapshot      sandbox.Snapshot      `json:"snapshot"`
+	ContainerInfo sandbox.ContainerInfo `json:"containerInfo"`
+}
+
+// ContainerStartResponse структура ответа для ContainerStart метода
+type ContainerStartResponse struct {
+	Message       string                `json:"message"`
+	ContainerInfo sandbox.ContainerInfo `json:"containerInfo"`
+}
+
+// ContainerStopResponse структура ответа для ContainerStop метода
+type ContainerStopResponse struct {
+	Message       string                `json:"message"`
+	ContainerInfo sandbox.ContainerInfo `json:"containerInfo"`
+}
+
+// ContainerStatusResponse структура ответа для ContainerStatus метода
+type ContainerStatusResponse struct {
+	Message       string                `json:"message"`
+	ContainerInfo sandbox.ContainerInfo `json:"containerInfo"`
+}
+
+// StorageResponse структура ответа для StorageInfo метода
+type StorageResponse struct {
+	Message   string              `json:"message"`
+	Storage   sandbox.StorageInfo `json:"storage"`
+	DiskUsage map[string]int64    `json:"diskUsage,omitempty"`
+}
+
+// StorageSetResponse структура ответа для StorageSet метода
+type StorageSetResponse struct {
+	Message  string              `json:"message"`
+	Storage  sandbox.StorageInfo `json:"storage"`
+	Migrated []string            `json:"migrated"`
+}
+
 // GetFilterFieldsResponse структура ответа для GetFilterFields метода
 type GetFilterFieldsResponse []filter.FieldInfo
 
@@ -89,3 +179,9 @@ type BackgroundTaskResponse struct {
 	Message     string `json:"message"`
 	Transaction string `json:"transaction"`
 }
+
+// SimulateResponse структура ответа для имитации команд distrobox
+type SimulateResponse struct {
+	Message string   `json:"message"`
+	Actions []string `json:"actions"`
+}
@@ -0,0 +1,406 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package distrobox
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/sandbox"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Методы Check* проверяют аргументы так же, как соответствующие команды, и описывают
+// действия, которые команда выполнит. Ни контейнеры, ни база пакетов при этом не меняются.
+
+// CheckUpdate имитирует обновление списка пакетов контейнера.
+func (a *Actions) CheckUpdate(ctx context.Context, container string) (*SimulateResponse, error) {
+	osInfo, err := a.checkContainer(ctx, container)
+	if err != nil {
+		return nil, err
+	}
+
+	return simulated(fmt.Sprintf(app.TL_(ctx, "Update the package list of container %s"), osInfo.ContainerName))
+}
+
+// CheckInstall имитирует установку и экспорт пакета.
+func (a *Actions) CheckInstall(ctx context.Context, container string, packageName string, opts InstallOptions) (*SimulateResponse, error) {
+	osInfo, err := a.checkContainer(ctx, container)
+	if err != nil {
+		return nil, err
+	}
+	packageName = strings.TrimSpace(packageName)
+	if packageName == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.TL_(ctx, "You must specify the package name, for example `%s package`"), "install"))
+	}
+
+	packageInfo, err := a.checkPackage(ctx, osInfo, packageName)
+	if err != nil {
+		return nil, err
+	}
+
+	var actions []string
+	if !packageInfo.Package.Installed {
+		actions = append(actions, fmt.Sprintf(app.TL_(ctx, "Install package %s into container %s"), packageName, osInfo.ContainerName))
+	}
+	if opts.Export && !packageInfo.Package.Exporting {
+		desktopPaths, consolePaths := packageInfo.DesktopPaths, packageInfo.ConsolePaths
+		if opts.ExportMain {
+			desktopPaths, consolePaths = sandbox.MainExportPaths(packageName, desktopPaths, consolePaths)
+		}
+		if !packageInfo.Package.Installed || len(desktopPaths) > 0 || len(consolePaths) > 0 {
+			actions = append(actions, fmt.Sprintf(app.TL_(ctx, "Export package %s to the host"), packageName))
+		}
+	}
+	if len(actions) == 0 {
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, fmt.Errorf(app.TL_(ctx, "Package %s is already installed"), packageName))
+	}
+
+	return simulated(actions...)
+}
+
+// CheckRemove имитирует удаление пакета и его экспорта.
+func (a *Actions) CheckRemove(ctx context.Context, container string, packageName string, onlyExport bool) (*SimulateResponse, error) {
+	osInfo, err := a.checkContainer(ctx, container)
+	if err != nil {
+		return nil, err
+	}
+	packageName = strings.TrimSpace(packageName)
+	if packageName == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.TL_(ctx, "You must specify the package name, for example `%s package`"), "remove"))
+	}
+
+	packageInfo, err := a.checkPackage(ctx, osInfo, packageName)
+	if err != nil {
+		return nil, err
+	}
+
+	var actions []string
+	if packageInfo.Package.Exporting {
+		actions = append(actions, fmt.Sprintf(app.TL_(ctx, "Remove the exported files of package %s from the host"), packageName))
+	}
+	if !onlyExport && packageInfo.Package.Installed {
+		actions = append(actions, fmt.Sprintf(app.TL_(ctx, "Remove package %s from container %s"), packageName, osInfo.ContainerName))
+	}
+	if len(actions) == 0 {
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, fmt.Errorf(app.TL_(ctx, "Package %s is not installed"), packageName))
+	}
+
+	return simulated(actions...)
+}
+
+// CheckProvision имитирует установку локали и шрифтов в контейнер.
+func (a *Actions) CheckProvision(ctx context.Context, container string, locale string, fonts bool) (*SimulateResponse, error) {
+	locale = strings.TrimSpace(locale)
+	if locale == "" && !fonts {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify --locale and/or --fonts")))
+	}
+	if locale != "" {
+		normalized, err := sandbox.NormalizeLocale(locale)
+		if err != nil {
+			return nil, apmerr.New(apmerr.ErrorTypeValidation, err)
+		}
+		locale = normalized
+	}
+
+	osInfo, err := a.checkContainer(ctx, container)
+	if err != nil {
+		return nil, err
+	}
+
+	var actions []string
+	if locale != "" {
+		actions = append(actions, fmt.Sprintf(app.TL_(ctx, "Install and generate locale %s in container %s"), locale, osInfo.ContainerName))
+	}
+	if fonts {
+		actions = append(actions, fmt.Sprintf(app.TL_(ctx, "Install fonts with Cyrillic support in container %s"), osInfo.ContainerName))
+	}
+
+	return simulated(actions...)
+}
+
+// CheckContainerAdd имитирует создание контейнера из образа.
+func (a *Actions) CheckContainerAdd(ctx context.Context, image string, name string) (*SimulateResponse, error) {
+	image = strings.TrimSpace(image)
+	name = strings.TrimSpace(name)
+	if image == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify the image link (--image)")))
+	}
+	if name == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify the container name (--name)")))
+	}
+	if err := a.checkContainerFree(ctx, name); err != nil {
+		return nil, err
+	}
+
+	return simulated(fmt.Sprintf(app.TL_(ctx, "Create container %s from image %s"), name, image))
+}
+
+// CheckContainerAddTemplate имитирует создание контейнера по шаблону.
+func (a *Actions) CheckContainerAddTemplate(ctx context.Context, templateName string, name string) (*SimulateResponse, error) {
+	templateName = strings.TrimSpace(templateName)
+	if templateName == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify the template name (--template)")))
+	}
+
+	tmpl, err := a.serviceTemplate.Get(templateName)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, err)
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = tmpl.Name
+	}
+	if _, err = tmpl.HomePath(name); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, err)
+	}
+	if err = a.checkContainerFree(ctx, name); err != nil {
+		return nil, err
+	}
+
+	actions := []string{fmt.Sprintf(app.TL_(ctx, "Create container %s from image %s"), name, tmpl.Image)}
+	for _, pkg := range tmpl.Export {
+		actions = append(actions, fmt.Sprintf(app.TL_(ctx, "Install package %s into container %s"), pkg, name),
+			fmt.Sprintf(app.TL_(ctx, "Export package %s to the host"), pkg))
+	}
+
+	return simulated(actions...)
+}
+
+// CheckContainerRemove имитирует удаление контейнера.
+func (a *Actions) CheckContainerRemove(ctx context.Context, name string) (*SimulateResponse, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify the container name (--name)")))
+	}
+	if _, err := a.serviceDistroAPI.GetContainerStatus(ctx, name); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, err)
+	}
+
+	return simulated(fmt.Sprintf(app.TL_(ctx, "Remove container %s and its package records"), name))
+}
+
+// CheckContainerStart имитирует запуск контейнера.
+func (a *Actions) CheckContainerStart(ctx context.Context, name string) (*SimulateResponse, error) {
+	info, err := a.checkContainerStatus(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if info.Running {
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, fmt.Errorf(app.TL_(ctx, "Container %s is already running"), info.ContainerName))
+	}
+
+	return simulated(fmt.Sprintf(app.TL_(ctx, "Start container %s"), info.ContainerName))
+}
+
+// CheckContainerStop имитирует остановку контейнера.
+func (a *Actions) CheckContainerStop(ctx context.Context, name string) (*SimulateResponse, error) {
+	info, err := a.checkContainerStatus(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Running {
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, fmt.Errorf(app.TL_(ctx, "Container %s is already stopped"), info.ContainerName))
+	}
+
+	return simulated(fmt.Sprintf(app.TL_(ctx, "Stop container %s"), info.ContainerName))
+}
+
+// CheckContainerClone имитирует клонирование контейнера.
+func (a *Actions) CheckContainerClone(ctx context.Context, source string, name string) (*SimulateResponse, error) {
+	source = strings.TrimSpace(source)
+	name = strings.TrimSpace(name)
+	if source == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify the container name")))
+	}
+	if name == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify the name of the new container")))
+	}
+	if _, err := a.serviceDistroAPI.GetContainerStatus(ctx, source); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, err)
+	}
+	if err := a.checkContainerFree(ctx, name); err != nil {
+		return nil, err
+	}
+
+	return simulated(fmt.Sprintf(app.TL_(ctx, "Clone container %s to %s"), source, name))
+}
+
+// CheckContainerSnapshot имитирует сохранение контейнера в снимок.
+func (a *Actions) CheckContainerSnapshot(ctx context.Context, container string, name string) (*SimulateResponse, error) {
+	container = strings.TrimSpace(container)
+	if container == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify the container name")))
+	}
+
+	osInfo, err := a.serviceDistroAPI.GetContainerOsInfo(ctx, container)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, err)
+	}
+
+	snapshot, err := sandbox.NewSnapshot(osInfo, strings.TrimSpace(name))
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, err)
+	}
+	if _, err = a.findSnapshot(ctx, container, snapshot.Name); err == nil {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.TL_(ctx, "Snapshot %s of container %s already exists"), snapshot.Name, container))
+	}
+
+	return simulated(fmt.Sprintf(app.TL_(ctx, "Save container %s to snapshot %s"), container, snapshot.Name))
+}
+
+// CheckContainerRestore имитирует пересоздание контейнера из снимка.
+func (a *Actions) CheckContainerRestore(ctx context.Context, container string, name string) (*SimulateResponse, error) {
+	container = strings.TrimSpace(container)
+	if container == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify the container name")))
+	}
+
+	snapshot, err := a.findSnapshot(ctx, container, strings.TrimSpace(name))
+	if err != nil {
+		return nil, err
+	}
+
+	return simulated(fmt.Sprintf(app.TL_(ctx, "Recreate container %s from snapshot %s"), container, snapshot.Name))
+}
+
+// CheckSnapshotRemove имитирует удаление снимка контейнера.
+func (a *Actions) CheckSnapshotRemove(ctx context.Context, container string, name string) (*SimulateResponse, error) {
+	container = strings.TrimSpace(container)
+	name = strings.TrimSpace(name)
+	if container == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify the container name")))
+	}
+	if name == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify the snapshot name")))
+	}
+
+	snapshot, err := a.findSnapshot(ctx, container, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return simulated(fmt.Sprintf(app.TL_(ctx, "Remove snapshot %s of container %s"), snapshot.Name, container))
+}
+
+// CheckStorageSet имитирует перенос хранилища контейнеров.
+func (a *Actions) CheckStorageSet(ctx context.Context, path string) (*SimulateResponse, error) {
+	path = strings.TrimSpace(path)
+	if !filepath.IsAbs(path) {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Storage path must be absolute")))
+	}
+	path = filepath.Clean(path)
+
+	info, err := a.serviceStorage.Info(ctx)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeContainer, err)
+	}
+	if filepath.Clean(info.Path) == path {
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, fmt.Errorf(app.TL_(ctx, "Containers are already stored in %s"), path))
+	}
+
+	containers, err := a.serviceDistroAPI.GetContainerList(ctx, false)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeContainer, err)
+	}
+
+	actions := []string{fmt.Sprintf(app.TL_(ctx, "Move container storage from %s to %s"), info.Path, path)}
+	for _, c := range containers {
+		actions = append(actions, fmt.Sprintf(app.TL_(ctx, "Migrate container %s"), c.ContainerName))
+	}
+
+	return simulated(actions...)
+}
+
+// checkContainer находит контейнер как validateContainer, но не трогает базу пакетов.
+func (a *Actions) checkContainer(ctx context.Context, container string) (sandbox.ContainerInfo, error) {
+	container = strings.TrimSpace(container)
+	if container == "" {
+		selected, err := a.selectContainerInteractive(ctx)
+		if err != nil {
+			return sandbox.ContainerInfo{}, err
+		}
+		container = selected
+	}
+
+	osInfo, err := a.serviceDistroAPI.GetContainerOsInfo(ctx, container)
+	if err != nil {
+		return sandbox.ContainerInfo{}, apmerr.New(apmerr.ErrorTypeNotFound, err)
+	}
+
+	return osInfo, nil
+}
+
+// checkPackage возвращает сведения о пакете из базы контейнера. Для контейнера, список пакетов
+// которого ещё не загружен, состояние пакета неизвестно, и он считается неустановленным.
+func (a *Actions) checkPackage(ctx context.Context, osInfo sandbox.ContainerInfo, packageName string) (sandbox.InfoPackageAnswer, error) {
+	if err := a.serviceDistroDatabase.ContainerDatabaseExist(ctx, osInfo.ContainerName); err != nil {
+		return sandbox.InfoPackageAnswer{Package: sandbox.PackageInfo{Name: packageName}}, nil
+	}
+
+	packageInfo, err := a.servicePackage.GetInfoPackage(ctx, osInfo, packageName)
+	if err != nil {
+		return sandbox.InfoPackageAnswer{}, apmerr.New(apmerr.ErrorTypeNotFound, err)
+	}
+
+	return packageInfo, nil
+}
+
+// checkContainerStatus проверяет имя и возвращает состояние существующего контейнера.
+func (a *Actions) checkContainerStatus(ctx context.Context, name string) (sandbox.ContainerInfo, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return sandbox.ContainerInfo{}, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify the container name")))
+	}
+
+	info, err := a.serviceDistroAPI.GetContainerStatus(ctx, name)
+	if err != nil {
+		return sandbox.ContainerInfo{}, apmerr.New(apmerr.ErrorTypeNotFound, err)
+	}
+	if info.ContainerName == "" {
+		info.ContainerName = name
+	}
+
+	return info, nil
+}
+
+// checkContainerFree проверяет, что имя нового контейнера не занято.
+func (a *Actions) checkContainerFree(ctx context.Context, name string) error {
+	containers, err := a.serviceDistroAPI.GetContainerList(ctx, false)
+	if err != nil {
+		return apmerr.New(apmerr.ErrorTypeContainer, err)
+	}
+	for _, c := range containers {
+		if c.ContainerName == name {
+			return apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.TL_(ctx, "Container %s already exists"), name))
+		}
+	}
+
+	return nil
+}
+
+// simulated собирает ответ имитации из списка действий.
+func simulated(actions ...string) (*SimulateResponse, error) {
+	return &SimulateResponse{
+		Message: app.T_("Simulation results"),
+		Actions: actions,
+	}, nil
+}
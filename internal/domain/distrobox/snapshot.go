@@ -0,0 +1,198 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package distrobox
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/sandbox"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ContainerClone создаёт контейнер name копией контейнера source вместе с установленными пакетами.
+func (a *Actions) ContainerClone(ctx context.Context, source string, name string) (*ContainerCloneResponse, error) {
+	source = strings.TrimSpace(source)
+	name = strings.TrimSpace(name)
+	if source == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify the container name")))
+	}
+	if name == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify the name of the new container")))
+	}
+
+	osInfo, err := a.serviceDistroAPI.CloneContainer(ctx, source, name)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeContainer, err)
+	}
+
+	if _, err = a.servicePackage.UpdatePackages(ctx, osInfo); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+
+	return &ContainerCloneResponse{
+		Message:       fmt.Sprintf(app.TL_(ctx, "Container %s cloned to %s"), source, name),
+		Source:        source,
+		ContainerInfo: osInfo,
+	}, nil
+}
+
+// ContainerSnapshot сохраняет контейнер в локальный образ и записывает снимок в базу.
+// Пустое name заменяется временем создания снимка.
+func (a *Actions) ContainerSnapshot(ctx context.Context, container string, name string) (*SnapshotResponse, error) {
+	container = strings.TrimSpace(container)
+	if container == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify the container name")))
+	}
+
+	osInfo, err := a.serviceDistroAPI.GetContainerOsInfo(ctx, container)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, err)
+	}
+
+	snapshot, err := sandbox.NewSnapshot(osInfo, strings.TrimSpace(name))
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, err)
+	}
+
+	// Повторный commit с тем же тегом молча перезаписал бы существующий снимок
+	if _, err = a.findSnapshot(ctx, container, snapshot.Name); err == nil {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.TL_(ctx, "Snapshot %s of container %s already exists"), snapshot.Name, container))
+	}
+
+	if err = a.serviceDistroAPI.CommitContainer(ctx, container, snapshot.Image); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeContainer, err)
+	}
+
+	if err = a.serviceDistroDatabase.SaveSnapshot(ctx, snapshot); err != nil {
+		if errRm := a.serviceDistroAPI.RemoveImage(ctx, snapshot.Image); errRm != nil {
+			app.Log.Warning(errRm.Error())
+		}
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+
+	return &SnapshotResponse{
+		Message:  fmt.Sprintf(app.TL_(ctx, "Snapshot %s of container %s created"), snapshot.Name, container),
+		Snapshot: snapshot,
+	}, nil
+}
+
+// ContainerSnapshots возвращает снимки контейнера, начиная с последнего.
+func (a *Actions) ContainerSnapshots(ctx context.Context, container string) (*SnapshotListResponse, error) {
+	container = strings.TrimSpace(container)
+	if container == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify the container name")))
+	}
+
+	snapshots, err := a.serviceDistroDatabase.GetSnapshots(ctx, container)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+
+	return &SnapshotListResponse{
+		Message:   fmt.Sprintf(app.TLN_(ctx, "%d snapshot found", "%d snapshots found", len(snapshots)), len(snapshots)),
+		Snapshots: snapshots,
+	}, nil
+}
+
+// ContainerRestore пересоздаёт контейнер из снимка name и заново синхронизирует его пакеты.
+// Пустое name означает последний снимок.
+func (a *Actions) ContainerRestore(ctx context.Context, container string, name string) (*ContainerRestoreResponse, error) {
+	container = strings.TrimSpace(container)
+	if container == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify the container name")))
+	}
+
+	snapshot, err := a.findSnapshot(ctx, container, strings.TrimSpace(name))
+	if err != nil {
+		return nil, err
+	}
+
+	osInfo, err := a.serviceDistroAPI.RestoreContainer(ctx, container, snapshot.Image)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeContainer, err)
+	}
+
+	if err = a.serviceDistroDatabase.DeletePackagesFromContainer(ctx, container); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+	if _, err = a.servicePackage.UpdatePackages(ctx, osInfo); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+
+	return &ContainerRestoreResponse{
+		Message:       fmt.Sprintf(app.TL_(ctx, "Container %s restored from snapshot %s"), container, snapshot.Name),
+		Snapshot:      snapshot,
+		ContainerInfo: osInfo,
+	}, nil
+}
+
+// SnapshotRemove удаляет снимок контейнера вместе с его образом.
+func (a *Actions) SnapshotRemove(ctx context.Context, container string, name string) (*SnapshotResponse, error) {
+	container = strings.TrimSpace(container)
+	name = strings.TrimSpace(name)
+	if container == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify the container name")))
+	}
+	if name == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify the snapshot name")))
+	}
+
+	snapshot, err := a.findSnapshot(ctx, container, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Образ мог быть удалён вручную, запись о снимке всё равно удаляется
+	if err = a.serviceDistroAPI.RemoveImage(ctx, snapshot.Image); err != nil {
+		app.Log.Warning(err.Error())
+	}
+
+	if err = a.serviceDistroDatabase.DeleteSnapshot(ctx, container, name); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+
+	return &SnapshotResponse{
+		Message:  fmt.Sprintf(app.TL_(ctx, "Snapshot %s of container %s removed"), name, container),
+		Snapshot: snapshot,
+	}, nil
+}
+
+// findSnapshot возвращает снимок контейнера по имени, пустое name - последний снимок.
+func (a *Actions) findSnapshot(ctx context.Context, container, name string) (sandbox.Snapshot, error) {
+	snapshots, err := a.serviceDistroDatabase.GetSnapshots(ctx, container)
+	if err != nil {
+		return sandbox.Snapshot{}, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+
+	if name == "" {
+		if len(snapshots) == 0 {
+			return sandbox.Snapshot{}, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.TL_(ctx, "Container %s has no snapshots"), container))
+		}
+		return snapshots[0], nil
+	}
+
+	for _, snapshot := range snapshots {
+		if snapshot.Name == name {
+			return snapshot, nil
+		}
+	}
+
+	return sandbox.Snapshot{}, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.TL_(ctx, "Snapshot %s of container %s not found"), name, container))
+}
@@ -0,0 +1,80 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package distrobox
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/sandbox"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// StorageInfo возвращает расположение хранилища контейнеров и свободное место.
+func (a *Actions) StorageInfo(ctx context.Context) (*StorageResponse, error) {
+	info, err := a.serviceStorage.Info(ctx)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeContainer, err)
+	}
+
+	usage, err := a.serviceStorage.DiskUsage(ctx)
+	if err != nil {
+		app.Log.Warning(err.Error())
+	}
+
+	return &StorageResponse{
+		Message:   fmt.Sprintf(app.TL_(ctx, "Containers are stored in %s"), info.Path),
+		Storage:   info,
+		DiskUsage: usage,
+	}, nil
+}
+
+// StorageSet переносит хранилище контейнеров в каталог path вместе с существующими контейнерами.
+func (a *Actions) StorageSet(ctx context.Context, path string) (*StorageSetResponse, error) {
+	path = strings.TrimSpace(path)
+	if !filepath.IsAbs(path) {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Storage path must be absolute")))
+	}
+
+	containers, err := a.serviceDistroAPI.GetContainerList(ctx, false)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeContainer, err)
+	}
+
+	migrated, err := a.serviceStorage.Relocate(ctx, filepath.Clean(path), containers)
+	if errors.Is(err, sandbox.ErrStorageUnchanged) {
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, fmt.Errorf(app.TL_(ctx, "Containers are already stored in %s"), path))
+	}
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeContainer, err)
+	}
+
+	info, err := a.serviceStorage.Info(ctx)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeContainer, err)
+	}
+
+	return &StorageSetResponse{
+		Message: fmt.Sprintf(app.TLN_(ctx, "Storage moved to %s, %d container migrated",
+			"Storage moved to %s, %d containers migrated", len(migrated)), info.Path, len(migrated)),
+		Storage:  info,
+		Migrated: migrated,
+	}, nil
+}
@@ -0,0 +1,302 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package driver
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/reply"
+	"apm/internal/domain/kernel"
+	"apm/internal/domain/repository"
+	"apm/internal/domain/system"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// drivers поддерживаемые драйверы видеокарт
+var drivers = []Driver{
+	{
+		Name:        "nvidia",
+		Description: "NVIDIA proprietary driver",
+		Component:   "classic",
+		Modules:     []string{"nvidia"},
+		Packages:    []string{"nvidia_glx_common", "nvidia-settings"},
+		Cmdline:     []string{"nvidia-drm.modeset=1", "rd.driver.blacklist=nouveau", "modprobe.blacklist=nouveau"},
+	},
+	{
+		Name:        "amdgpu-pro",
+		Description: "AMD GPU PRO userspace driver",
+		Component:   "classic",
+		Packages:    []string{"amdgpu-pro-vulkan", "amdgpu-pro-opencl"},
+		Cmdline:     []string{"amdgpu.si_support=1", "amdgpu.cik_support=1", "radeon.si_support=0", "radeon.cik_support=0"},
+	},
+}
+
+// Actions объединяет методы для установки драйверов видеокарт
+type Actions struct {
+	appConfig         *app.Config
+	reporter          *reply.Reporter
+	serviceKernel     kernelService
+	serviceRepository repositoryService
+	servicePackages   packageService
+	serviceCmdline    cmdlineService
+}
+
+// NewActions создаёт новый экземпляр Actions.
+func NewActions(appConfig *app.Config, reporter *reply.Reporter) *Actions {
+	kernelActions := kernel.NewActions(appConfig, reporter)
+
+	return &Actions{
+		appConfig:         appConfig,
+		reporter:          reporter,
+		serviceKernel:     kernelActions,
+		serviceRepository: repository.NewActions(appConfig, reporter),
+		servicePackages:   system.NewActions(appConfig, reporter),
+		serviceCmdline:    kernelActions,
+	}
+}
+
+// List возвращает список поддерживаемых драйверов
+func (a *Actions) List(_ context.Context) (*ListResponse, error) {
+	return &ListResponse{
+		Message: fmt.Sprintf(app.TN_("%d driver available", "%d drivers available", len(drivers)), len(drivers)),
+		Drivers: drivers,
+	}, nil
+}
+
+// Install проводит полную установку драйвера: проверяет совместимость с ядром, подключает
+// компонент репозитория, ставит модули ядра и пакеты, добавляет параметры ядра.
+// При dryRun изменения только планируются.
+func (a *Actions) Install(ctx context.Context, name string, flavour string, confirm bool, dryRun bool) (*InstallResponse, error) {
+	spec, err := findDriver(name)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &InstallResponse{Driver: spec.Name}
+
+	modules, err := a.serviceKernel.ListKernelModules(ctx, flavour)
+	if err != nil {
+		return nil, err
+	}
+	resp.Flavour = modules.Kernel.Flavour
+	if missing := missingModules(spec, modules); len(missing) > 0 {
+		return nil, apmerr.New(apmerr.ErrorTypeKernel, fmt.Errorf(
+			app.TL_(ctx, "Kernel flavour %s is not compatible with driver %s: modules not available: %s"),
+			resp.Flavour, spec.Name, strings.Join(missing, ", ")))
+	}
+
+	step, err := a.ensureComponent(ctx, spec, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	resp.Steps = append(resp.Steps, step)
+
+	step, err = a.installModules(ctx, spec, resp.Flavour, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	resp.Steps = append(resp.Steps, step)
+
+	step, err = a.installPackages(ctx, spec, confirm, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	resp.Steps = append(resp.Steps, step)
+
+	step, err = a.setCmdline(ctx, spec, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	resp.Steps = append(resp.Steps, step)
+
+	resp.PostInstall = a.postInstall(spec)
+	if dryRun {
+		resp.Message = fmt.Sprintf(app.TL_(ctx, "Driver %s installation plan"), spec.Name)
+	} else {
+		resp.Message = fmt.Sprintf(app.TL_(ctx, "Driver %s installed successfully"), spec.Name)
+	}
+
+	return resp, nil
+}
+
+// findDriver возвращает описание драйвера по имени
+func findDriver(name string) (Driver, error) {
+	for _, d := range drivers {
+		if d.Name == name {
+			return d, nil
+		}
+	}
+
+	names := make([]string, 0, len(drivers))
+	for _, d := range drivers {
+		names = append(names, d.Name)
+	}
+	return Driver{}, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(
+		app.T_("Unknown driver %q, supported drivers: %s"), name, strings.Join(names, ", ")))
+}
+
+// missingModules возвращает модули драйвера, отсутствующие для выбранного ядра
+func missingModules(spec Driver, modules *kernel.ListKernelModulesResponse) []string {
+	var missing []string
+	for _, module := range spec.Modules {
+		found := false
+		for _, available := range modules.Modules {
+			if available.Name == module {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, module)
+		}
+	}
+	return missing
+}
+
+// ensureComponent подключает компонент репозитория, если он ещё не активен.
+// Адрес и архитектура берутся из первого активного репозитория ветки.
+func (a *Actions) ensureComponent(ctx context.Context, spec Driver, dryRun bool) (reply.Step, error) {
+	step := reply.Step{Name: "repository"}
+
+	repos, err := a.serviceRepository.List(ctx, false)
+	if err != nil {
+		return step, err
+	}
+
+	var args []string
+	for _, repo := range repos.Repositories {
+		if !repo.Active {
+			continue
+		}
+		if slices.Contains(repo.Components, spec.Component) {
+			step.Status = reply.StepSkipped
+			step.Message = fmt.Sprintf(app.TL_(ctx, "Component %s is already enabled"), spec.Component)
+			return step, nil
+		}
+		if args == nil && repo.Branch != "" {
+			args = []string{"rpm", repo.URL, repo.Arch, spec.Component}
+		}
+	}
+
+	if args == nil {
+		return step, apmerr.New(apmerr.ErrorTypeRepository, fmt.Errorf(
+			app.TL_(ctx, "No active branch repository found to enable component %s"), spec.Component))
+	}
+
+	step.Message = fmt.Sprintf(app.TL_(ctx, "Component %s enabled"), spec.Component)
+	if dryRun {
+		step.Status = reply.StepPlanned
+		return step, nil
+	}
+
+	if _, err = a.serviceRepository.Add(ctx, args, ""); err != nil {
+		return step, err
+	}
+	step.Status = reply.StepDone
+	return step, nil
+}
+
+// installModules устанавливает модули ядра драйвера
+func (a *Actions) installModules(ctx context.Context, spec Driver, flavour string, dryRun bool) (reply.Step, error) {
+	step := reply.Step{Name: "modules"}
+	if len(spec.Modules) == 0 {
+		step.Status = reply.StepSkipped
+		step.Message = app.TL_(ctx, "Driver does not require kernel modules")
+		return step, nil
+	}
+
+	_, err := a.serviceKernel.InstallKernelModules(ctx, flavour, spec.Modules, dryRun)
+	if isNoOperation(err) {
+		step.Status = reply.StepSkipped
+		step.Message = app.TL_(ctx, "Kernel modules are already installed")
+		return step, nil
+	}
+	if err != nil {
+		return step, err
+	}
+
+	step.Status = reply.StepStatus(dryRun)
+	step.Message = fmt.Sprintf(app.TL_(ctx, "Kernel modules: %s"), strings.Join(spec.Modules, ", "))
+	return step, nil
+}
+
+// installPackages устанавливает пользовательские пакеты драйвера
+func (a *Actions) installPackages(ctx context.Context, spec Driver, confirm bool, dryRun bool) (reply.Step, error) {
+	step := reply.Step{Name: "packages"}
+
+	var err error
+	if dryRun {
+		_, err = a.servicePackages.CheckInstall(ctx, spec.Packages)
+	} else {
+		_, err = a.servicePackages.Install(ctx, spec.Packages, confirm, false)
+	}
+	if isNoOperation(err) {
+		step.Status = reply.StepSkipped
+		step.Message = app.TL_(ctx, "Packages are already installed")
+		return step, nil
+	}
+	if err != nil {
+		return step, err
+	}
+
+	step.Status = reply.StepStatus(dryRun)
+	step.Message = fmt.Sprintf(app.TL_(ctx, "Packages: %s"), strings.Join(spec.Packages, ", "))
+	return step, nil
+}
+
+// setCmdline добавляет параметры ядра, необходимые драйверу, так же как apm kernel cmdline set
+func (a *Actions) setCmdline(ctx context.Context, spec Driver, dryRun bool) (reply.Step, error) {
+	step := reply.Step{Name: "cmdline"}
+
+	resp, err := a.serviceCmdline.SetKernelCmdline(ctx, "", spec.Cmdline, nil, dryRun)
+	if isNoOperation(err) {
+		step.Status = reply.StepSkipped
+		step.Message = app.TL_(ctx, "Kernel parameters are already set")
+		return step, nil
+	}
+	if err != nil {
+		return step, err
+	}
+
+	step.Status = reply.StepStatus(dryRun)
+	step.Message = fmt.Sprintf(app.TL_(ctx, "Kernel parameters: %s"), strings.Join(resp.Added, " "))
+	return step, nil
+}
+
+// postInstall возвращает действия, которые пользователь должен выполнить после установки
+func (a *Actions) postInstall(spec Driver) []string {
+	steps := []string{app.T_("Reboot the system to load the new driver")}
+
+	switch spec.Name {
+	case "nvidia":
+		steps = append(steps, app.T_("Run nvidia-smi to verify that the driver is loaded"))
+	case "amdgpu-pro":
+		steps = append(steps, app.T_("Run vulkaninfo --summary to verify that the driver is used"))
+	}
+
+	return steps
+}
+
+// isNoOperation проверяет, что операция не требует изменений
+func isNoOperation(err error) bool {
+	var apmErr apmerr.APMError
+	return errors.As(err, &apmErr) && apmErr.Type == apmerr.ErrorTypeNoOperation
+}
@@ -0,0 +1,207 @@
+package driver
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/reply"
+	"apm/internal/domain/kernel"
+	kernelSvc "apm/internal/domain/kernel/service"
+	"apm/internal/domain/repository"
+	repoSvc "apm/internal/domain/repository/service"
+	"apm/internal/domain/system"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+type mockKernel struct {
+	modules     []kernelSvc.ModuleInfo
+	installErr  error
+	installed   []string
+	installDone bool
+}
+
+func (m *mockKernel) ListKernelModules(_ context.Context, flavour string) (*kernel.ListKernelModulesResponse, error) {
+	if flavour == "" {
+		flavour = "6.12"
+	}
+	return &kernel.ListKernelModulesResponse{
+		Kernel:  kernelSvc.FullKernelInfo{Flavour: flavour},
+		Modules: m.modules,
+	}, nil
+}
+
+func (m *mockKernel) InstallKernelModules(_ context.Context, _ string, modules []string, _ bool) (*kernel.InstallKernelModulesResponse, error) {
+	m.installDone = true
+	m.installed = modules
+	return &kernel.InstallKernelModulesResponse{}, m.installErr
+}
+
+type mockRepository struct {
+	repos []repoSvc.Repository
+	added []string
+}
+
+func (m *mockRepository) List(_ context.Context, _ bool) (*repository.RepoListResponse, error) {
+	return &repository.RepoListResponse{Repositories: m.repos}, nil
+}
+
+func (m *mockRepository) Add(_ context.Context, args []string, _ string) (*repository.RepoAddRemoveResponse, error) {
+	m.added = args
+	return &repository.RepoAddRemoveResponse{}, nil
+}
+
+type mockPackages struct {
+	installErr error
+	installed  []string
+	checked    []string
+}
+
+func (m *mockPackages) CheckInstall(_ context.Context, packages []string) (*system.CheckResponse, error) {
+	m.checked = packages
+	return &system.CheckResponse{}, m.installErr
+}
+
+func (m *mockPackages) Install(_ context.Context, packages []string, _ bool, _ bool) (*system.InstallRemoveResponse, error) {
+	m.installed = packages
+	return &system.InstallRemoveResponse{}, m.installErr
+}
+
+type mockCmdline struct {
+	current []string
+	dryRun  bool
+}
+
+func (m *mockCmdline) SetKernelCmdline(_ context.Context, _ string, add []string, _ []string, dryRun bool) (*kernel.KernelCmdlineResponse, error) {
+	m.dryRun = dryRun
+	var added []string
+	for _, arg := range add {
+		if !slices.Contains(m.current, arg) {
+			added = append(added, arg)
+		}
+	}
+	if len(added) == 0 {
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New("kernel command line is already up to date"))
+	}
+	return &kernel.KernelCmdlineResponse{Added: added}, nil
+}
+
+func newTestActions(k *mockKernel, r *mockRepository, p *mockPackages, c *mockCmdline) *Actions {
+	return &Actions{
+		appConfig:         &app.Config{},
+		serviceKernel:     k,
+		serviceRepository: r,
+		servicePackages:   p,
+		serviceCmdline:    c,
+	}
+}
+
+func branchRepos() []repoSvc.Repository {
+	return []repoSvc.Repository{
+		{URL: "http://example.org/task", Arch: "x86_64", Components: []string{"task"}, Active: true},
+		{URL: "http://ftp.altlinux.org/pub/distributions/ALTLinux/Sisyphus", Arch: "x86_64",
+			Components: []string{"gostcrypto"}, Active: true, Branch: "sisyphus"},
+	}
+}
+
+func stepsByName(resp *InstallResponse) map[string]reply.Step {
+	steps := make(map[string]reply.Step)
+	for _, step := range resp.Steps {
+		steps[step.Name] = step
+	}
+	return steps
+}
+
+func TestInstallNvidia(t *testing.T) {
+	k := &mockKernel{modules: []kernelSvc.ModuleInfo{{Name: "nvidia"}}}
+	r := &mockRepository{repos: branchRepos()}
+	p := &mockPackages{}
+	c := &mockCmdline{current: []string{"nvidia-drm.modeset=1"}}
+	actions := newTestActions(k, r, p, c)
+
+	resp, err := actions.Install(context.Background(), "nvidia", "std-def", true, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Flavour != "std-def" {
+		t.Errorf("expected flavour std-def, got %s", resp.Flavour)
+	}
+
+	wantRepo := []string{"rpm", "http://ftp.altlinux.org/pub/distributions/ALTLinux/Sisyphus", "x86_64", "classic"}
+	if !slices.Equal(r.added, wantRepo) {
+		t.Errorf("expected repository %v to be added, got %v", wantRepo, r.added)
+	}
+	if !slices.Equal(k.installed, []string{"nvidia"}) {
+		t.Errorf("expected nvidia module to be installed, got %v", k.installed)
+	}
+	if !slices.Equal(p.installed, []string{"nvidia_glx_common", "nvidia-settings"}) {
+		t.Errorf("unexpected packages installed: %v", p.installed)
+	}
+
+	steps := stepsByName(resp)
+	for _, name := range []string{"repository", "modules", "packages", "cmdline"} {
+		if steps[name].Status != reply.StepDone {
+			t.Errorf("expected step %s to be done, got %q", name, steps[name].Status)
+		}
+	}
+	if len(resp.PostInstall) == 0 {
+		t.Error("expected post-install steps")
+	}
+}
+
+func TestInstallSimulateAndSkip(t *testing.T) {
+	repos := branchRepos()
+	repos[1].Components = append(repos[1].Components, "classic")
+	k := &mockKernel{}
+	r := &mockRepository{repos: repos}
+	p := &mockPackages{installErr: apmerr.New(apmerr.ErrorTypeNoOperation, errors.New("nothing to do"))}
+	amdgpu, _ := findDriver("amdgpu-pro")
+	c := &mockCmdline{current: amdgpu.Cmdline}
+	actions := newTestActions(k, r, p, c)
+
+	resp, err := actions.Install(context.Background(), "amdgpu-pro", "", false, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.added != nil {
+		t.Errorf("expected no repository to be added, got %v", r.added)
+	}
+	if k.installDone {
+		t.Error("expected no kernel modules install for amdgpu-pro")
+	}
+	if p.installed != nil || p.checked == nil {
+		t.Error("expected packages to be only checked in simulation")
+	}
+
+	steps := stepsByName(resp)
+	for _, name := range []string{"repository", "modules", "packages", "cmdline"} {
+		if steps[name].Status != reply.StepSkipped {
+			t.Errorf("expected step %s to be skipped, got %q", name, steps[name].Status)
+		}
+	}
+	if !c.dryRun {
+		t.Error("expected kernel parameters to be only checked in simulation")
+	}
+}
+
+func TestInstallErrors(t *testing.T) {
+	actions := newTestActions(&mockKernel{}, &mockRepository{repos: branchRepos()}, &mockPackages{}, &mockCmdline{})
+
+	var apmErr apmerr.APMError
+	_, err := actions.Install(context.Background(), "radeon", "", true, false)
+	if !errors.As(err, &apmErr) || apmErr.Type != apmerr.ErrorTypeValidation {
+		t.Errorf("expected validation error for unknown driver, got %v", err)
+	}
+
+	_, err = actions.Install(context.Background(), "nvidia", "un-def", true, false)
+	if !errors.As(err, &apmErr) || apmErr.Type != apmerr.ErrorTypeKernel {
+		t.Errorf("expected kernel error for incompatible flavour, got %v", err)
+	}
+
+	actions = newTestActions(&mockKernel{}, &mockRepository{}, &mockPackages{}, &mockCmdline{})
+	_, err = actions.Install(context.Background(), "amdgpu-pro", "", true, false)
+	if !errors.As(err, &apmErr) || apmErr.Type != apmerr.ErrorTypeRepository {
+		t.Errorf("expected repository error without branch repository, got %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package driver
+
+import (
+	"apm/internal/common/app"
+	apmcli "apm/internal/common/cli"
+	"apm/internal/common/reply"
+	"context"
+
+	"github.com/urfave/cli/v3"
+)
+
+// newErrorResponseFromError создаёт ответ с ошибкой, извлекая тип из apmerr.APMError.
+func newErrorResponseFromError(err error) reply.APIResponse {
+	app.Log.Error(err.Error())
+	return reply.ErrorResponseFromError(err)
+}
+
+func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
+	withRootCheckWrapper := apmcli.WithOptions(appConfig, reporter, apmcli.RequireRoot, NewActions, newErrorResponseFromError)
+
+	return &cli.Command{
+		Name:  "driver",
+		Usage: app.T_("GPU driver installation"),
+		Commands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: app.T_("List supported drivers"),
+				Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+					resp, err := actions.List(ctx)
+					if err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+					return reporter.CliResponse(ctx, reply.OK(resp))
+				}),
+			},
+			{
+				Name:      "install",
+				Usage:     app.T_("Install GPU driver with kernel modules, packages and kernel parameters"),
+				ArgsUsage: "nvidia|amdgpu-pro",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "flavour",
+						Usage: app.T_("Install for specific kernel flavour"),
+					},
+					&cli.BoolFlag{
+						Name:    "yes",
+						Usage:   app.T_("Automatic confirmation"),
+						Aliases: []string{"y"},
+						Value:   false,
+					},
+					&cli.BoolFlag{
+						Name:    "simulate",
+						Usage:   app.T_("Show the installation plan without making changes"),
+						Aliases: []string{"s"},
+						Value:   false,
+					},
+				},
+				Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+					resp, err := actions.Install(ctx, cmd.Args().First(), cmd.String("flavour"), cmd.Bool("yes"), cmd.Bool("simulate"))
+					if err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+					return reporter.CliResponse(ctx, reply.OK(resp))
+				}),
+			},
+		},
+	}
+}
@@ -0,0 +1,47 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package driver
+
+import (
+	"apm/internal/domain/kernel"
+	"apm/internal/domain/repository"
+	"apm/internal/domain/system"
+	"context"
+)
+
+// kernelService определяет методы модуля kernel для работы с модулями ядра.
+type kernelService interface {
+	ListKernelModules(ctx context.Context, flavour string) (*kernel.ListKernelModulesResponse, error)
+	InstallKernelModules(ctx context.Context, flavour string, modules []string, dryRun bool) (*kernel.InstallKernelModulesResponse, error)
+}
+
+// repositoryService определяет методы модуля repo для подключения репозиториев.
+type repositoryService interface {
+	List(ctx context.Context, all bool) (*repository.RepoListResponse, error)
+	Add(ctx context.Context, args []string, date string) (*repository.RepoAddRemoveResponse, error)
+}
+
+// packageService определяет методы модуля system для установки пакетов.
+type packageService interface {
+	CheckInstall(ctx context.Context, packages []string) (*system.CheckResponse, error)
+	Install(ctx context.Context, packages []string, confirm bool, downloadOnly bool) (*system.InstallRemoveResponse, error)
+}
+
+// cmdlineService определяет методы модуля kernel для изменения параметров командной строки ядра.
+type cmdlineService interface {
+	SetKernelCmdline(ctx context.Context, version string, add []string, remove []string, dryRun bool) (*kernel.KernelCmdlineResponse, error)
+}
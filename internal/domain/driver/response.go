@@ -0,0 +1,44 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package driver
+
+import "apm/internal/common/reply"
+
+// Driver описание драйвера видеокарты: что нужно установить и настроить
+type Driver struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Component   string   `json:"component"`
+	Modules     []string `json:"modules"`
+	Packages    []string `json:"packages"`
+	Cmdline     []string `json:"cmdline"`
+}
+
+// ListResponse структура ответа для List метода
+type ListResponse struct {
+	Message string   `json:"message"`
+	Drivers []Driver `json:"drivers"`
+}
+
+// InstallResponse структура ответа для Install метода
+type InstallResponse struct {
+	Message     string       `json:"message"`
+	Driver      string       `json:"driver"`
+	Flavour     string       `json:"flavour"`
+	Steps       []reply.Step `json:"steps"`
+	PostInstall []string     `json:"postInstall"`
+}
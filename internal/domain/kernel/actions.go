@@ -21,7 +21,13 @@ import (
 	"apm/internal/common/app"
 	_package "apm/internal/common/apt/package"
 	"apm/internal/common/binding/apt"
+	aptlib "apm/internal/common/binding/apt/lib"
+	"apm/internal/common/build"
+	"apm/internal/common/build/models"
 	"apm/internal/common/command"
+	"apm/internal/common/hooks"
+	"apm/internal/common/journal"
+	"apm/internal/common/oplock"
 	"apm/internal/common/reply"
 	"apm/internal/domain/kernel/service"
 	"context"
@@ -39,6 +45,12 @@ type Actions struct {
 	serviceAptActions  aptActionsService
 	serviceAptDatabase aptDatabaseService
 	kernelManager      kernelManagerService
+	serviceHostConfig  hostConfigService
+	serviceHostImage   hostImageService
+	serviceJournal     journalService
+	serviceHooks       hooksService
+	operationLock      *oplock.Lock
+	stateNotifier      app.StateNotifier
 }
 
 // NewActions создаёт новый экземпляр Actions.
@@ -50,6 +62,16 @@ func NewActions(appConfig *app.Config, reporter *reply.Reporter) *Actions {
 	aptActions := apt.NewActions()
 	aptPackageActions := _package.NewActions(hostPackageDBSvc, appConfig, reporter)
 	kernelManager := service.NewKernelManager(hostPackageDBSvc, aptActions, runner, reporter)
+	hostImageSvc := build.NewHostImageService(
+		cfg,
+		appConfig.ConfigManager.GetPathImageContainerFile(),
+		runner,
+		reporter,
+	)
+	hostConfigSvc := build.NewHostConfigService(
+		build.NewHostDBService(appConfig.DatabaseManager, reporter),
+		hostImageSvc,
+	)
 
 	return &Actions{
 		appConfig:          appConfig,
@@ -57,9 +79,71 @@ func NewActions(appConfig *app.Config, reporter *reply.Reporter) *Actions {
 		serviceAptDatabase: hostPackageDBSvc,
 		serviceAptActions:  aptPackageActions,
 		kernelManager:      kernelManager,
+		serviceHostConfig:  hostConfigSvc,
+		serviceHostImage:   hostImageSvc,
+		serviceJournal:     journal.NewService(appConfig.DatabaseManager),
+		serviceHooks:       hooks.NewService(runner, cfg.PathHooksDir),
+		operationLock:      oplock.Shared(),
+		stateNotifier:      app.DBusStateNotifier(appConfig.DBusManager),
 	}
 }
 
+// lockOperation захватывает блокировку операций apm. Предпросмотр изменений (dryRun) выполняется без неё.
+func (a *Actions) lockOperation(ctx context.Context, operation string, dryRun bool) (context.Context, func(), error) {
+	if dryRun {
+		return ctx, func() {}, nil
+	}
+	return a.operationLock.Acquire(ctx, operation)
+}
+
+// recordOperation записывает результат операции в историю. Ошибка записи не прерывает операцию.
+func (a *Actions) recordOperation(ctx context.Context, entry journal.Entry, opErr error) {
+	if a.serviceJournal == nil {
+		return
+	}
+
+	entry.Module = journal.ModuleKernel
+	if err := a.serviceJournal.RecordResult(context.WithoutCancel(ctx), entry, opErr); err != nil {
+		app.Log.Warning(fmt.Sprintf("failed to record transaction journal: %v", err))
+	}
+}
+
+// notifyPackagesChanged сообщает по DBus об изменении пакетов ядра
+func (a *Actions) notifyPackagesChanged(action string, changes *aptlib.PackageChanges) {
+	if changes == nil {
+		return
+	}
+	a.stateNotifier.Emit(app.PackagesChangedSignal, journal.ModuleKernel, action,
+		changes.NewInstalledPackages, changes.UpgradedPackages, changes.RemovedPackages)
+}
+
+// beforeHooks запускает хуки перед транзакцией. Ошибка означает отмену транзакции хуком.
+func (a *Actions) beforeHooks(ctx context.Context, tx hooks.Transaction) error {
+	if a.serviceHooks == nil {
+		return nil
+	}
+	return a.serviceHooks.Before(ctx, tx)
+}
+
+// afterHooks запускает хуки после транзакции с её результатом opErr.
+func (a *Actions) afterHooks(ctx context.Context, tx hooks.Transaction, opErr error) error {
+	if a.serviceHooks == nil {
+		return nil
+	}
+	return a.serviceHooks.After(ctx, tx, opErr)
+}
+
+// changesEntry формирует запись истории по изменениям пакетов
+func changesEntry(action string, targets []string, changes *aptlib.PackageChanges) journal.Entry {
+	entry := journal.Entry{Action: action, Targets: targets}
+	if changes != nil {
+		entry.Installed = changes.NewInstalledPackages
+		entry.Upgraded = changes.UpgradedPackages
+		entry.Removed = changes.RemovedPackages
+	}
+	return entry
+}
+
 // ListKernels возвращает список ядер
 func (a *Actions) ListKernels(ctx context.Context, flavour string, installedOnly bool) (*ListKernelsResponse, error) {
 	err := a.validateDB(ctx)
@@ -82,11 +166,11 @@ func (a *Actions) ListKernels(ctx context.Context, flavour string, installedOnly
 	}
 
 	if len(kernels) == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeNotFound, errors.New(app.T_("No kernels found")))
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, errors.New(app.TL_(ctx, "No kernels found")))
 	}
 
 	return &ListKernelsResponse{
-		Message: fmt.Sprintf(app.TN_("%d kernel found", "%d kernels found", len(kernels)), len(kernels)),
+		Message: fmt.Sprintf(app.TLN_(ctx, "%d kernel found", "%d kernels found", len(kernels)), len(kernels)),
 		Kernels: a.formatKernelOutput(ctx, kernels),
 	}, nil
 }
@@ -104,14 +188,20 @@ func (a *Actions) GetCurrentKernel(ctx context.Context) (*GetCurrentKernelRespon
 	}
 
 	return &GetCurrentKernelResponse{
-		Message: app.T_("Current kernel information"),
+		Message: app.TL_(ctx, "Current kernel information"),
 		Kernel:  a.kernelManager.BuildFullKernelInfo(kernel),
 	}, nil
 }
 
 // InstallKernel устанавливает ядро с указанным flavour
 func (a *Actions) InstallKernel(ctx context.Context, flavour string, modules []string, includeHeaders bool, dryRun bool) (*InstallUpdateKernelResponse, error) {
-	err := a.validateDB(ctx)
+	ctx, release, err := a.lockOperation(ctx, journal.ActionInstall, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	err = a.validateDB(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -122,7 +212,7 @@ func (a *Actions) InstallKernel(ctx context.Context, flavour string, modules []s
 	}
 
 	if strings.TrimSpace(flavour) == "" {
-		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.T_("Kernel flavour must be specified")))
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Kernel flavour must be specified")))
 	}
 	latest, err := a.kernelManager.FindLatestKernel(ctx, flavour)
 	if err != nil {
@@ -158,12 +248,12 @@ func (a *Actions) InstallKernel(ctx context.Context, flavour string, modules []s
 	}
 
 	if len(preview.MissingModules) > 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.T_("some modules are not available: %s"), strings.Join(preview.MissingModules, ", ")))
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.TL_(ctx, "some modules are not available: %s"), strings.Join(preview.MissingModules, ", ")))
 	}
 
 	if len(preview.Changes.NewInstalledPackages) == 0 && len(preview.Changes.UpgradedPackages) == 0 {
 		return &InstallUpdateKernelResponse{
-			Message: fmt.Sprintf(app.T_("Kernel %s is already installed"), latest.FullVersion),
+			Message: fmt.Sprintf(app.TL_(ctx, "Kernel %s is already installed"), latest.FullVersion),
 			Kernel:  a.kernelManager.BuildFullKernelInfo(latest),
 			Preview: nil,
 		}, nil
@@ -171,32 +261,92 @@ func (a *Actions) InstallKernel(ctx context.Context, flavour string, modules []s
 
 	if dryRun {
 		return &InstallUpdateKernelResponse{
-			Message: app.T_("Installation preview"),
+			Message: app.TL_(ctx, "Installation preview"),
 			Kernel:  a.kernelManager.BuildFullKernelInfo(latest),
 			Preview: preview,
 		}, nil
 	}
 
-	err = a.kernelManager.InstallKernel(ctx, latest, modules, includeHeaders, false)
+	hookTx := hooks.NewTransaction(ctx, hooks.EventKernelInstall, []string{latest.FullVersion})
+	if preview.Changes != nil {
+		hookTx.Installed = preview.Changes.NewInstalledPackages
+		hookTx.Upgraded = preview.Changes.UpgradedPackages
+		hookTx.Removed = preview.Changes.RemovedPackages
+	}
+	if err = a.beforeHooks(ctx, hookTx); err != nil {
+		return nil, err
+	}
+
+	if a.isAtomic() {
+		err = a.applyKernelToImage(ctx, models.KernelInfo{Flavour: latest.Flavour, Modules: modules, IncludeHeaders: includeHeaders})
+		errHooks := a.afterHooks(ctx, hookTx, err)
+		a.recordOperation(ctx, changesEntry(journal.ActionInstall, []string{latest.FullVersion}, preview.Changes), err)
+		if err != nil {
+			return nil, err
+		}
+		a.stateNotifier.Emit(app.KernelInstalledSignal, latest.FullVersion, true)
+		if errHooks != nil {
+			return nil, errHooks
+		}
+
+		return &InstallUpdateKernelResponse{
+			Message:  fmt.Sprintf(app.TL_(ctx, "Kernel %s was added to the image and will be used after reboot"), latest.FullVersion),
+			Kernel:   a.kernelManager.BuildFullKernelInfo(latest),
+			Preview:  preview,
+			NextBoot: true,
+		}, nil
+	}
+
+	rebuilds, err := a.kernelManager.InstallKernel(ctx, latest, modules, includeHeaders, false)
+	errHooks := a.afterHooks(ctx, hookTx, err)
+	a.recordOperation(ctx, changesEntry(journal.ActionInstall, []string{latest.FullVersion}, preview.Changes), err)
 	if err != nil {
-		return nil, apmerr.New(apmerr.ErrorTypeKernel, fmt.Errorf(app.T_("failed to install kernel: %s"), err.Error()))
+		return nil, apmerr.New(apmerr.ErrorTypeKernel, fmt.Errorf(app.TL_(ctx, "failed to install kernel: %s"), err.Error()))
 	}
 
 	err = a.updateAllPackagesDB(ctx)
+	a.notifyPackagesChanged(journal.ActionInstall, preview.Changes)
+	a.stateNotifier.Emit(app.KernelInstalledSignal, latest.FullVersion, false)
 	if err != nil {
 		return nil, err
 	}
+	if errHooks != nil {
+		return nil, errHooks
+	}
+
+	message := fmt.Sprintf(app.TL_(ctx, "Kernel %s installed successfully"), latest.FullVersion)
+	if failed := failedRebuilds(rebuilds); len(failed) > 0 {
+		message = fmt.Sprintf(app.TL_(ctx, "Kernel %s installed, but external modules failed to build: %s. Do not boot into it until they are fixed"),
+			latest.FullVersion, strings.Join(failed, ", "))
+	}
 
 	return &InstallUpdateKernelResponse{
-		Message: fmt.Sprintf(app.T_("Kernel %s installed successfully"), latest.FullVersion),
-		Kernel:  a.kernelManager.BuildFullKernelInfo(latest),
-		Preview: preview,
+		Message:  message,
+		Kernel:   a.kernelManager.BuildFullKernelInfo(latest),
+		Preview:  preview,
+		Rebuilds: rebuilds,
 	}, nil
 }
 
+// failedRebuilds возвращает имена внешних модулей, которые не удалось собрать
+func failedRebuilds(rebuilds []service.ModuleRebuild) []string {
+	var failed []string
+	for _, rebuild := range rebuilds {
+		if !rebuild.Success {
+			failed = append(failed, rebuild.Name)
+		}
+	}
+	return failed
+}
+
 // UpdateKernel обновляет ядро до последней версии
 func (a *Actions) UpdateKernel(ctx context.Context, flavour string, modules []string, includeHeaders bool, dryRun bool) (*InstallUpdateKernelResponse, error) {
-	var err error
+	ctx, release, err := a.lockOperation(ctx, journal.ActionUpgrade, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	err = a.validateDB(ctx)
 	if err != nil {
 		return nil, err
@@ -216,30 +366,23 @@ func (a *Actions) UpdateKernel(ctx context.Context, flavour string, modules []st
 
 	latest, err := a.kernelManager.FindLatestKernel(ctx, flavour)
 
-	// Если ядро не найдено и пользователь НЕ указал flavour явно - ищем ближайшую новую версию
-	if err != nil && !userSpecifiedFlavour {
-		current, currentErr := a.kernelManager.GetCurrentKernel(ctx)
-		if currentErr == nil {
-			nextFlavours, flavourErr := a.kernelManager.FindNextFlavours(current.Version)
-			if flavourErr == nil && len(nextFlavours) > 0 {
-				// Берем ПЕРВЫЙ (ближайший новый) flavour
-				newFlavour := nextFlavours[0]
-
-				newLatest, newErr := a.kernelManager.FindLatestKernel(ctx, newFlavour)
-				if newErr == nil {
-					latest = newLatest
-					flavour = newFlavour
-					err = nil
-				}
-			}
+	// Если пользователь НЕ указал flavour явно - переход на другой flavour определяется политикой из конфигурации
+	if !userSpecifiedFlavour {
+		if switched, switchedFlavour := a.selectFlavourByPolicy(ctx, flavour, err == nil); switched != nil {
+			latest = switched
+			flavour = switchedFlavour
+			err = nil
 		}
 	}
 
 	if err != nil {
 		if userSpecifiedFlavour {
-			return nil, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.T_("no kernels found for flavour: %s. Remove --flavour option to attempt an automatic upgrade"), flavour))
+			return nil, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.TL_(ctx, "no kernels found for flavour: %s. Remove --flavour option to attempt an automatic upgrade"), flavour))
+		}
+		if !a.kernelPolicy().AutoSwitch {
+			return nil, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.TL_(ctx, "no kernels found for flavour: %s. Automatic flavour switch is disabled by kernel.autoSwitch"), flavour))
 		}
-		return nil, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.T_("no kernels found for flavour: %s"), flavour))
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.TL_(ctx, "no kernels found for flavour: %s"), flavour))
 	}
 
 	current, err := a.kernelManager.GetCurrentKernel(ctx)
@@ -250,7 +393,7 @@ func (a *Actions) UpdateKernel(ctx context.Context, flavour string, modules []st
 	// Сравниваем установленную версию с доступной версией
 	if latest.Version == current.VersionInstalled && latest.Release == current.Release {
 		return &InstallUpdateKernelResponse{
-			Message: app.T_("Kernel is already up to date"),
+			Message: app.TL_(ctx, "Kernel is already up to date"),
 			Kernel:  a.kernelManager.BuildFullKernelInfo(latest),
 			Preview: nil,
 		}, nil
@@ -268,7 +411,17 @@ func (a *Actions) UpdateKernel(ctx context.Context, flavour string, modules []st
 
 // CleanOldKernels удаляет старые ядра
 func (a *Actions) CleanOldKernels(ctx context.Context, noBackup bool, dryRun bool) (*CleanOldKernelsResponse, error) {
-	err := a.validateDB(ctx)
+	if a.isAtomic() {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "On atomic systems the kernel is part of the image, old kernels are removed together with old images")))
+	}
+
+	ctx, release, err := a.lockOperation(ctx, journal.ActionRemove, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	err = a.validateDB(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -280,7 +433,7 @@ func (a *Actions) CleanOldKernels(ctx context.Context, noBackup bool, dryRun boo
 	}
 
 	if len(allKernels) == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeNotFound, errors.New(app.T_("no kernels found")))
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, errors.New(app.TL_(ctx, "no kernels found")))
 	}
 
 	// Определяем текущее ядро
@@ -319,19 +472,19 @@ func (a *Actions) CleanOldKernels(ctx context.Context, noBackup bool, dryRun boo
 
 			// 1. Сохраняем новейшее ядро только для текущего загруженного flavour'а
 			if kernel.FullVersion == newestKernel.FullVersion && currentKernel != nil && fl == currentKernel.Flavour {
-				reasons = append(reasons, fmt.Sprintf(app.T_("latest for %s"), fl))
+				reasons = append(reasons, fmt.Sprintf(app.TL_(ctx, "latest for %s"), fl))
 			}
 
 			// 2. Сохраняем текущее запущенное ядро
 			if currentKernel != nil && kernel.Version == currentKernel.Version &&
 				kernel.Release == currentKernel.Release && kernel.Flavour == currentKernel.Flavour {
-				reasons = append(reasons, app.T_("Currently booted"))
+				reasons = append(reasons, app.TL_(ctx, "Currently booted"))
 				kernel.IsRunning = true
 			}
 
 			// 3. Сохраняем backup ядро (с uptime >= 1 день)
 			if backupKernel != nil && kernel.FullVersion == backupKernel.FullVersion {
-				reasons = append(reasons, app.T_("backup kernel"))
+				reasons = append(reasons, app.TL_(ctx, "backup kernel"))
 			}
 
 			// Если есть причины для сохранения - добавляем в список сохраненных
@@ -348,7 +501,7 @@ func (a *Actions) CleanOldKernels(ctx context.Context, noBackup bool, dryRun boo
 	}
 
 	if len(toRemove) == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.T_("no old kernels to clean")))
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.TL_(ctx, "no old kernels to clean")))
 	}
 
 	if dryRun {
@@ -359,11 +512,11 @@ func (a *Actions) CleanOldKernels(ctx context.Context, noBackup bool, dryRun boo
 
 		combinedPreview, errRemove := a.kernelManager.RemovePackages(ctx, removePackages, true)
 		if errRemove != nil {
-			return nil, apmerr.New(apmerr.ErrorTypeKernel, fmt.Errorf(app.T_("failed to simulate kernels removal: %s"), errRemove.Error()))
+			return nil, apmerr.New(apmerr.ErrorTypeKernel, fmt.Errorf(app.TL_(ctx, "failed to simulate kernels removal: %s"), errRemove.Error()))
 		}
 
 		return &CleanOldKernelsResponse{
-			Message:       fmt.Sprintf(app.TN_("Would remove %d old kernel", "Would remove %d old kernels", len(toRemove)), len(toRemove)),
+			Message:       fmt.Sprintf(app.TLN_(ctx, "Would remove %d old kernel", "Would remove %d old kernels", len(toRemove)), len(toRemove)),
 			RemoveKernels: toRemove,
 			KeptKernels:   keptKernels,
 			Preview:       combinedPreview,
@@ -376,18 +529,176 @@ func (a *Actions) CleanOldKernels(ctx context.Context, noBackup bool, dryRun boo
 	}
 
 	combinedPreview, err := a.kernelManager.RemovePackages(ctx, removePackages, false)
+	a.recordOperation(ctx, changesEntry(journal.ActionRemove, removePackages, combinedPreview), err)
 	if err != nil {
-		return nil, apmerr.New(apmerr.ErrorTypeKernel, fmt.Errorf(app.T_("failed to remove kernels: %s"), err.Error()))
+		return nil, apmerr.New(apmerr.ErrorTypeKernel, fmt.Errorf(app.TL_(ctx, "failed to remove kernels: %s"), err.Error()))
 	}
+	a.notifyPackagesChanged(journal.ActionRemove, combinedPreview)
 
 	return &CleanOldKernelsResponse{
-		Message:       fmt.Sprintf(app.TN_("Successfully removed %d old kernel", "Successfully removed %d old kernels", len(toRemove)), len(toRemove)),
+		Message:       fmt.Sprintf(app.TLN_(ctx, "Successfully removed %d old kernel", "Successfully removed %d old kernels", len(toRemove)), len(toRemove)),
 		RemoveKernels: toRemove,
 		KeptKernels:   keptKernels,
 		Preview:       combinedPreview,
 	}, nil
 }
 
+// SetDefaultKernel делает установленное ядро загружаемым по умолчанию.
+// version принимает полную версию из списка ядер (kernel-image-un-def=6.12.10-alt1) или имя ядра в /boot (6.12.10-un-def-alt1)
+func (a *Actions) SetDefaultKernel(ctx context.Context, version string, dryRun bool) (*SetDefaultKernelResponse, error) {
+	if a.isAtomic() {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "On atomic systems the kernel is part of the image and is selected by the image")))
+	}
+
+	version = strings.TrimSpace(version)
+	if version == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Kernel version must be specified")))
+	}
+
+	ctx, release, err := a.lockOperation(ctx, journal.ActionSet, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	kernel, err := a.findInstalledKernel(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := a.kernelManager.PlanDefaultKernel(ctx, kernel)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeKernel, err)
+	}
+
+	if dryRun {
+		return &SetDefaultKernelResponse{
+			Message: fmt.Sprintf(app.TL_(ctx, "Kernel %s would be set as default"), kernel.BootRelease()),
+			Kernel:  a.kernelManager.BuildFullKernelInfo(kernel),
+			Boot:    *plan,
+		}, nil
+	}
+
+	err = a.kernelManager.ApplyDefaultKernel(ctx, plan)
+	a.recordOperation(ctx, journal.Entry{Action: journal.ActionSet, Targets: []string{kernel.FullVersion}}, err)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeKernel, err)
+	}
+
+	return &SetDefaultKernelResponse{
+		Message: fmt.Sprintf(app.TL_(ctx, "Kernel %s is set as default, it will be loaded at next boot"), kernel.BootRelease()),
+		Kernel:  a.kernelManager.BuildFullKernelInfo(kernel),
+		Boot:    *plan,
+	}, nil
+}
+
+// GetKernelCmdline возвращает параметры командной строки ядра. Пустая version — текущее ядро
+func (a *Actions) GetKernelCmdline(ctx context.Context, version string) (*KernelCmdlineResponse, error) {
+	kernel, err := a.cmdlineKernel(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+
+	cmdline, err := a.kernelManager.GetCmdline(ctx, kernel)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeKernel, err)
+	}
+
+	return &KernelCmdlineResponse{
+		Message: fmt.Sprintf(app.TL_(ctx, "Kernel %s command line"), kernel.BootRelease()),
+		Kernel:  a.kernelManager.BuildFullKernelInfo(kernel),
+		Cmdline: *cmdline,
+	}, nil
+}
+
+// SetKernelCmdline добавляет и удаляет параметры командной строки ядра. Пустая version — текущее ядро
+func (a *Actions) SetKernelCmdline(ctx context.Context, version string, add []string, remove []string, dryRun bool) (*KernelCmdlineResponse, error) {
+	if len(add) == 0 && len(remove) == 0 {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "At least one parameter to add or remove must be specified")))
+	}
+	for _, arg := range add {
+		if slices.Contains(remove, arg) {
+			return nil, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.TL_(ctx, "Parameter %s cannot be added and removed at the same time"), arg))
+		}
+	}
+
+	ctx, release, err := a.lockOperation(ctx, journal.ActionSet, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	kernel, err := a.cmdlineKernel(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+
+	change, err := a.kernelManager.SetCmdline(ctx, kernel, add, remove, dryRun)
+	if !dryRun && (err != nil || len(change.Added) > 0 || len(change.Removed) > 0) {
+		a.recordOperation(ctx, journal.Entry{Action: journal.ActionSet, Targets: []string{kernel.FullVersion}}, err)
+	}
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeKernel, err)
+	}
+	if len(change.Added) == 0 && len(change.Removed) == 0 {
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.TL_(ctx, "Kernel command line is already up to date")))
+	}
+
+	message := fmt.Sprintf(app.TL_(ctx, "Kernel %s command line updated, changes will take effect after reboot"), kernel.BootRelease())
+	if dryRun {
+		message = fmt.Sprintf(app.TL_(ctx, "Kernel %s command line would be updated"), kernel.BootRelease())
+	}
+
+	return &KernelCmdlineResponse{
+		Message: message,
+		Kernel:  a.kernelManager.BuildFullKernelInfo(kernel),
+		Cmdline: change.After,
+		Added:   change.Added,
+		Removed: change.Removed,
+		Diff:    change.Diff,
+	}, nil
+}
+
+// cmdlineKernel находит ядро для работы с командной строкой. В атомарной системе доступно только загруженное ядро
+func (a *Actions) cmdlineKernel(ctx context.Context, version string) (*service.Info, error) {
+	current, err := a.kernelManager.GetCurrentKernel(ctx)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeKernel, err)
+	}
+	if strings.TrimSpace(version) == "" {
+		return current, nil
+	}
+
+	kernel, err := a.findInstalledKernel(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+	if a.isAtomic() && kernel.FullVersion != current.FullVersion {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "On atomic systems only the command line of the booted kernel can be changed")))
+	}
+
+	return kernel, nil
+}
+
+// findInstalledKernel находит установленное ядро по полной версии (kernel-image-un-def=6.12.10-alt1)
+// или имени ядра в /boot (6.12.10-un-def-alt1)
+func (a *Actions) findInstalledKernel(ctx context.Context, version string) (*service.Info, error) {
+	version = strings.TrimSpace(version)
+
+	installed, err := a.kernelManager.ListInstalledKernelsFromRPM(ctx)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeKernel, err)
+	}
+
+	for _, kernel := range installed {
+		if kernel.FullVersion == version || kernel.BootRelease() == version {
+			return kernel, nil
+		}
+	}
+
+	return nil, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.TL_(ctx, "Installed kernel %s not found"), version))
+}
+
 // ListKernelModules возвращает список модулей для ядра
 func (a *Actions) ListKernelModules(ctx context.Context, flavour string) (*ListKernelModulesResponse, error) {
 	var err error
@@ -415,7 +726,7 @@ func (a *Actions) ListKernelModules(ctx context.Context, flavour string) (*ListK
 	}
 
 	return &ListKernelModulesResponse{
-		Message: fmt.Sprintf(app.TN_("%d module found", "%d modules found", len(modules)), len(modules)),
+		Message: fmt.Sprintf(app.TLN_(ctx, "%d module found", "%d modules found", len(modules)), len(modules)),
 		Kernel:  a.kernelManager.BuildFullKernelInfo(latest),
 		Modules: modules,
 	}, nil
@@ -424,13 +735,19 @@ func (a *Actions) ListKernelModules(ctx context.Context, flavour string) (*ListK
 // InstallKernelModules устанавливает модули ядра
 func (a *Actions) InstallKernelModules(ctx context.Context, flavour string,
 	modules []string, dryRun bool) (*InstallKernelModulesResponse, error) {
-	err := a.validateDB(ctx)
+	ctx, release, err := a.lockOperation(ctx, journal.ActionInstall, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	err = a.validateDB(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	if len(modules) == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.T_("At least one module must be specified")))
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "At least one module must be specified")))
 	}
 
 	if flavour == "" {
@@ -466,7 +783,7 @@ func (a *Actions) InstallKernelModules(ctx context.Context, flavour string,
 	}
 
 	if len(missingModules) > 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.T_("modules not available: %s"), strings.Join(missingModules, ", ")))
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.TL_(ctx, "modules not available: %s"), strings.Join(missingModules, ", ")))
 	}
 
 	// Проверяем уже установленные модули только для текущего ядра
@@ -482,7 +799,7 @@ func (a *Actions) InstallKernelModules(ctx context.Context, flavour string,
 			}
 		}
 		if len(alreadyInstalledModules) > 0 {
-			return nil, apmerr.New(apmerr.ErrorTypeNoOperation, fmt.Errorf(app.T_("modules already installed: %s"), strings.Join(alreadyInstalledModules, ", ")))
+			return nil, apmerr.New(apmerr.ErrorTypeNoOperation, fmt.Errorf(app.TL_(ctx, "modules already installed: %s"), strings.Join(alreadyInstalledModules, ", ")))
 		}
 	}
 
@@ -500,22 +817,38 @@ func (a *Actions) InstallKernelModules(ctx context.Context, flavour string,
 	if dryRun {
 		preview, err := a.kernelManager.InstallModules(ctx, installPackages, true)
 		if err != nil {
-			return nil, apmerr.New(apmerr.ErrorTypeKernel, fmt.Errorf(app.T_("failed to simulate modules installation: %s"), err.Error()))
+			return nil, apmerr.New(apmerr.ErrorTypeKernel, fmt.Errorf(app.TL_(ctx, "failed to simulate modules installation: %s"), err.Error()))
 		}
 
 		return &InstallKernelModulesResponse{
-			Message: app.T_("Modules installation preview"),
+			Message: app.TL_(ctx, "Modules installation preview"),
 			Kernel:  a.kernelManager.BuildFullKernelInfo(latest),
 			Preview: preview,
 		}, nil
 	}
 
-	_, err = a.kernelManager.InstallModules(ctx, installPackages, false)
+	if a.isAtomic() {
+		err = a.updateImageKernelModules(ctx, latest.Flavour, modules, nil)
+		a.recordOperation(ctx, changesEntry(journal.ActionInstall, modules, nil), err)
+		if err != nil {
+			return nil, err
+		}
+
+		return &InstallKernelModulesResponse{
+			Message:  fmt.Sprintf(app.TLN_(ctx, "%d module was added to the image and will be available after reboot", "%d modules were added to the image and will be available after reboot", len(modules)), len(modules)),
+			Kernel:   a.kernelManager.BuildFullKernelInfo(latest),
+			NextBoot: true,
+		}, nil
+	}
+
+	changes, err := a.kernelManager.InstallModules(ctx, installPackages, false)
+	a.recordOperation(ctx, changesEntry(journal.ActionInstall, modules, changes), err)
 	if err != nil {
-		return nil, apmerr.New(apmerr.ErrorTypeKernel, fmt.Errorf(app.T_("failed to install modules: %s"), err.Error()))
+		return nil, apmerr.New(apmerr.ErrorTypeKernel, fmt.Errorf(app.TL_(ctx, "failed to install modules: %s"), err.Error()))
 	}
 
 	err = a.updateAllPackagesDB(ctx)
+	a.notifyPackagesChanged(journal.ActionInstall, changes)
 	if err != nil {
 		return nil, err
 	}
@@ -526,7 +859,7 @@ func (a *Actions) InstallKernelModules(ctx context.Context, flavour string,
 	}
 
 	return &InstallKernelModulesResponse{
-		Message: fmt.Sprintf(app.TN_("%d module installed successfully for kernel %s", "%d modules installed successfully for kernel %s", len(modules)), len(modules), updatedKernel.FullVersion),
+		Message: fmt.Sprintf(app.TLN_(ctx, "%d module installed successfully for kernel %s", "%d modules installed successfully for kernel %s", len(modules)), len(modules), updatedKernel.FullVersion),
 		Kernel:  a.kernelManager.BuildFullKernelInfo(updatedKernel),
 		Preview: nil,
 	}, nil
@@ -535,13 +868,19 @@ func (a *Actions) InstallKernelModules(ctx context.Context, flavour string,
 // RemoveKernelModules удаляет модули ядра
 func (a *Actions) RemoveKernelModules(ctx context.Context, flavour string,
 	modules []string, dryRun bool) (*RemoveKernelModulesResponse, error) {
-	err := a.validateDB(ctx)
+	ctx, release, err := a.lockOperation(ctx, journal.ActionRemove, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	err = a.validateDB(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	if len(modules) == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.T_("At least one module must be specified")))
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "At least one module must be specified")))
 	}
 
 	if flavour == "" {
@@ -578,17 +917,17 @@ func (a *Actions) RemoveKernelModules(ctx context.Context, flavour string,
 			}
 		}
 		if !found {
-			return nil, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.T_("module not found: %s"), module))
+			return nil, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.TL_(ctx, "module not found: %s"), module))
 		}
 	}
 
 	if len(notInstalledModules) > 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, fmt.Errorf(app.T_("modules not installed: %s"), strings.Join(notInstalledModules, ", ")))
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, fmt.Errorf(app.TL_(ctx, "modules not installed: %s"), strings.Join(notInstalledModules, ", ")))
 	}
 
 	if len(modulesToRemove) == 0 {
 		return &RemoveKernelModulesResponse{
-			Message: app.T_("No modules to remove"),
+			Message: app.TL_(ctx, "No modules to remove"),
 			Kernel:  a.kernelManager.BuildFullKernelInfo(latest),
 			Preview: nil,
 		}, nil
@@ -608,22 +947,38 @@ func (a *Actions) RemoveKernelModules(ctx context.Context, flavour string,
 	if dryRun {
 		preview, err := a.kernelManager.RemovePackages(ctx, removePackages, true)
 		if err != nil {
-			return nil, apmerr.New(apmerr.ErrorTypeKernel, fmt.Errorf(app.T_("failed to simulate modules removal: %s"), err.Error()))
+			return nil, apmerr.New(apmerr.ErrorTypeKernel, fmt.Errorf(app.TL_(ctx, "failed to simulate modules removal: %s"), err.Error()))
 		}
 
 		return &RemoveKernelModulesResponse{
-			Message: app.T_("Modules removal preview"),
+			Message: app.TL_(ctx, "Modules removal preview"),
 			Kernel:  a.kernelManager.BuildFullKernelInfo(latest),
 			Preview: preview,
 		}, nil
 	}
 
-	_, err = a.kernelManager.RemovePackages(ctx, removePackages, false)
+	if a.isAtomic() {
+		err = a.updateImageKernelModules(ctx, latest.Flavour, nil, modulesToRemove)
+		a.recordOperation(ctx, changesEntry(journal.ActionRemove, modulesToRemove, nil), err)
+		if err != nil {
+			return nil, err
+		}
+
+		return &RemoveKernelModulesResponse{
+			Message:  fmt.Sprintf(app.TLN_(ctx, "%d module was removed from the image and will be unloaded after reboot", "%d modules were removed from the image and will be unloaded after reboot", len(modulesToRemove)), len(modulesToRemove)),
+			Kernel:   a.kernelManager.BuildFullKernelInfo(latest),
+			NextBoot: true,
+		}, nil
+	}
+
+	changes, err := a.kernelManager.RemovePackages(ctx, removePackages, false)
+	a.recordOperation(ctx, changesEntry(journal.ActionRemove, modulesToRemove, changes), err)
 	if err != nil {
-		return nil, apmerr.New(apmerr.ErrorTypeKernel, fmt.Errorf(app.T_("failed to remove modules: %s"), err.Error()))
+		return nil, apmerr.New(apmerr.ErrorTypeKernel, fmt.Errorf(app.TL_(ctx, "failed to remove modules: %s"), err.Error()))
 	}
 
 	err = a.updateAllPackagesDB(ctx)
+	a.notifyPackagesChanged(journal.ActionRemove, changes)
 	if err != nil {
 		return nil, err
 	}
@@ -635,7 +990,7 @@ func (a *Actions) RemoveKernelModules(ctx context.Context, flavour string,
 	}
 
 	return &RemoveKernelModulesResponse{
-		Message: fmt.Sprintf(app.TN_("%d module removed successfully from kernel %s", "%d modules removed successfully from kernel %s", len(modulesToRemove)), len(modulesToRemove), latest.FullVersion),
+		Message: fmt.Sprintf(app.TLN_(ctx, "%d module removed successfully from kernel %s", "%d modules removed successfully from kernel %s", len(modulesToRemove)), len(modulesToRemove), latest.FullVersion),
 		Kernel:  a.kernelManager.BuildFullKernelInfo(updatedKernel),
 		Preview: nil,
 	}, nil
@@ -661,6 +1016,51 @@ func (a *Actions) detectFlavourOrDefault(ctx context.Context, flavour string) (s
 	return a.kernelManager.DetectCurrentFlavour(ctx)
 }
 
+// kernelPolicy возвращает политику выбора flavour из конфигурации
+func (a *Actions) kernelPolicy() app.KernelPolicy {
+	return a.appConfig.ConfigManager.GetConfig().Kernel
+}
+
+// selectFlavourByPolicy выбирает ядро другого flavour согласно kernel.preferredFlavour и kernel.autoSwitch.
+// Предпочитаемый flavour имеет приоритет, если для него есть ядра. Если ядер текущего flavour нет,
+// а предпочитаемый не задан или недоступен, берётся ближайший новый flavour. Возвращает nil, если переход не нужен.
+func (a *Actions) selectFlavourByPolicy(ctx context.Context, flavour string, currentFound bool) (*service.Info, string) {
+	policy := a.kernelPolicy()
+	if !policy.AutoSwitch {
+		return nil, ""
+	}
+
+	preferred := strings.TrimSpace(policy.PreferredFlavour)
+	if preferred != "" && preferred != flavour {
+		latest, err := a.kernelManager.FindLatestKernel(ctx, preferred)
+		if err == nil {
+			return latest, preferred
+		}
+		app.Log.Debugf("preferred kernel flavour %s is not available: %v", preferred, err)
+	}
+
+	if currentFound {
+		return nil, ""
+	}
+
+	current, err := a.kernelManager.GetCurrentKernel(ctx)
+	if err != nil {
+		return nil, ""
+	}
+
+	nextFlavours, err := a.kernelManager.FindNextFlavours(current.Version)
+	if err != nil || len(nextFlavours) == 0 {
+		return nil, ""
+	}
+
+	// Берем ПЕРВЫЙ (ближайший новый) flavour
+	latest, err := a.kernelManager.FindLatestKernel(ctx, nextFlavours[0])
+	if err != nil {
+		return nil, ""
+	}
+	return latest, nextFlavours[0]
+}
+
 // findKernelByVersion находит ядро по версии из списка
 func (a *Actions) findKernelByVersion(version string, kernels []*service.Info) *service.Info {
 	for _, kernel := range kernels {
@@ -693,7 +1093,7 @@ func (a *Actions) updateAllPackagesDB(ctx context.Context) error {
 func (a *Actions) validateDB(ctx context.Context) error {
 	if err := a.serviceAptDatabase.PackageDatabaseExist(ctx); err != nil {
 		if syscall.Geteuid() != 0 {
-			return apmerr.New(apmerr.ErrorTypePermission, errors.New(app.T_("package database is empty. Run 'apm system update' with elevated rights to create it")))
+			return apmerr.New(apmerr.ErrorTypePermission, errors.New(app.TL_(ctx, "package database is empty. Run 'apm system update' with elevated rights to create it")))
 		}
 
 		_, err = a.serviceAptActions.Update(ctx)
@@ -2,13 +2,18 @@ package kernel
 
 import (
 	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
 	_package "apm/internal/common/apt/package"
 	aptlib "apm/internal/common/binding/apt/lib"
+	"apm/internal/common/build"
+	"apm/internal/common/build/models"
 	"apm/internal/common/reply"
 	"apm/internal/common/testutil"
 	"apm/internal/domain/kernel/service"
 	"context"
 	"errors"
+	"slices"
+	"strings"
 	"syscall"
 	"testing"
 )
@@ -48,6 +53,7 @@ type mockKernelManager struct {
 	currentKernelErr    error
 	findLatestResult    *service.Info
 	findLatestErr       error
+	latestByFlavour     map[string]*service.Info
 	inheritModules      []string
 	inheritModulesErr   error
 	autoSelectResult    []string
@@ -55,6 +61,7 @@ type mockKernelManager struct {
 	simulateResult      *service.UpgradePreview
 	simulateErr         error
 	installKernelErr    error
+	rebuilds            []service.ModuleRebuild
 	findNextFlavours    []string
 	findNextFlavoursErr error
 	rpmKernels          []*service.Info
@@ -72,6 +79,13 @@ type mockKernelManager struct {
 	installModResult    *aptlib.PackageChanges
 	installModErr       error
 	simplePkgName       string
+	bootPlan            *service.BootDefaultPlan
+	bootPlanErr         error
+	applyDefaultErr     error
+	defaultApplied      bool
+	cmdline             *service.Cmdline
+	cmdlineChange       *service.CmdlineChange
+	cmdlineErr          error
 }
 
 func (m *mockKernelManager) ListKernels(_ context.Context, _ string) ([]*service.Info, error) {
@@ -80,7 +94,13 @@ func (m *mockKernelManager) ListKernels(_ context.Context, _ string) ([]*service
 func (m *mockKernelManager) GetCurrentKernel(_ context.Context) (*service.Info, error) {
 	return m.currentKernel, m.currentKernelErr
 }
-func (m *mockKernelManager) FindLatestKernel(_ context.Context, _ string) (*service.Info, error) {
+func (m *mockKernelManager) FindLatestKernel(_ context.Context, flavour string) (*service.Info, error) {
+	if m.latestByFlavour != nil {
+		if latest, ok := m.latestByFlavour[flavour]; ok {
+			return latest, nil
+		}
+		return nil, errors.New("not found")
+	}
 	return m.findLatestResult, m.findLatestErr
 }
 func (m *mockKernelManager) InheritModulesFromKernel(_ *service.Info, _ *service.Info) ([]string, error) {
@@ -92,8 +112,8 @@ func (m *mockKernelManager) AutoSelectHeadersAndFirmware(_ context.Context, _ *s
 func (m *mockKernelManager) SimulateUpgrade(_ *service.Info, _ []string, _ bool) (*service.UpgradePreview, error) {
 	return m.simulateResult, m.simulateErr
 }
-func (m *mockKernelManager) InstallKernel(_ context.Context, _ *service.Info, _ []string, _ bool, _ bool) error {
-	return m.installKernelErr
+func (m *mockKernelManager) InstallKernel(_ context.Context, _ *service.Info, _ []string, _ bool, _ bool) ([]service.ModuleRebuild, error) {
+	return m.rebuilds, m.installKernelErr
 }
 func (m *mockKernelManager) FindNextFlavours(_ string) ([]string, error) {
 	return m.findNextFlavours, m.findNextFlavoursErr
@@ -142,6 +162,58 @@ func (m *mockKernelManager) BuildFullKernelInfo(info *service.Info) service.Full
 	}
 }
 
+func (m *mockKernelManager) PlanDefaultKernel(_ context.Context, _ *service.Info) (*service.BootDefaultPlan, error) {
+	return m.bootPlan, m.bootPlanErr
+}
+func (m *mockKernelManager) ApplyDefaultKernel(_ context.Context, _ *service.BootDefaultPlan) error {
+	m.defaultApplied = true
+	return m.applyDefaultErr
+}
+
+func (m *mockKernelManager) GetCmdline(_ context.Context, _ *service.Info) (*service.Cmdline, error) {
+	return m.cmdline, m.cmdlineErr
+}
+func (m *mockKernelManager) SetCmdline(_ context.Context, _ *service.Info, _ []string, _ []string, _ bool) (*service.CmdlineChange, error) {
+	return m.cmdlineChange, m.cmdlineErr
+}
+
+type mockHostConfig struct {
+	config  build.Config
+	kernel  *models.KernelInfo
+	loadErr error
+}
+
+func (m *mockHostConfig) LoadConfig() error               { return m.loadErr }
+func (m *mockHostConfig) GetConfig() *build.Config        { return &m.config }
+func (m *mockHostConfig) GenerateDockerfile(_ bool) error { return nil }
+func (m *mockHostConfig) ConfigIsChanged(_ context.Context) (bool, error) {
+	return true, nil
+}
+func (m *mockHostConfig) SaveConfigToDB(_ context.Context) error { return nil }
+func (m *mockHostConfig) SetKernel(info models.KernelInfo) error {
+	m.config.SetKernel(info)
+	m.kernel = &info
+	return nil
+}
+
+type mockHostImage struct {
+	built    bool
+	buildErr error
+}
+
+func (m *mockHostImage) BuildAndSwitch(_ context.Context, _ bool, _ bool, _ build.SwitchableConfig) error {
+	m.built = true
+	return m.buildErr
+}
+
+func newAtomicTestActions(km *mockKernelManager, hc *mockHostConfig, hi *mockHostImage) *Actions {
+	actions := newTestActions(km, &mockAptActions{}, nil)
+	actions.appConfig.ConfigManager.GetConfig().IsAtomic = true
+	actions.serviceHostConfig = hc
+	actions.serviceHostImage = hi
+	return actions
+}
+
 func newTestActions(km *mockKernelManager, apt *mockAptActions, db *mockAptDatabase) *Actions {
 	if km == nil {
 		km = &mockKernelManager{}
@@ -381,6 +453,32 @@ func TestInstallKernel(t *testing.T) {
 			t.Errorf("expected flavour 6.12, got %s", resp.Kernel.Flavour)
 		}
 	})
+
+	t.Run("failed module rebuild is reported", func(t *testing.T) {
+		km := &mockKernelManager{
+			findLatestResult: latest,
+			simulateResult: &service.UpgradePreview{
+				Changes: &aptlib.PackageChanges{NewInstalledPackages: []string{"kernel-image-6.12"}},
+			},
+			rebuilds: []service.ModuleRebuild{
+				{Name: "virtualbox", Tool: service.RebuildToolDKMS, Success: true},
+				{Name: "nvidia", Tool: service.RebuildToolDKMS, Error: "build failed"},
+			},
+		}
+		apt := &mockAptActions{installedPkgs: map[string]string{}}
+		actions := newTestActions(km, apt, nil)
+
+		resp, err := actions.InstallKernel(testContext(), "6.12", nil, false, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resp.Rebuilds) != 2 {
+			t.Errorf("expected 2 rebuild results, got %d", len(resp.Rebuilds))
+		}
+		if !strings.Contains(resp.Message, "nvidia") || strings.Contains(resp.Message, "virtualbox") {
+			t.Errorf("expected message to name failed module only, got %q", resp.Message)
+		}
+	})
 }
 
 func TestUpdateKernel(t *testing.T) {
@@ -469,6 +567,102 @@ func TestUpdateKernel(t *testing.T) {
 	})
 }
 
+func TestUpdateKernelFlavourPolicy(t *testing.T) {
+	current := testKernel("std-def", "6.6.50", "kernel-image-std-def#6.6.50-alt1")
+	current.VersionInstalled = "6.6.40"
+	stdDef := testKernel("std-def", "6.6.50", "kernel-image-std-def#6.6.50-alt1")
+	unDef := testKernel("un-def", "6.12.10", "kernel-image-un-def#6.12.10-alt1")
+	next := testKernel("6.12", "6.12.10", "kernel-image-6.12#6.12.10-alt1")
+
+	newPolicyActions := func(km *mockKernelManager, policy app.KernelPolicy) *Actions {
+		km.currentKernel = current
+		km.detectFlavour = "std-def"
+		km.simulateResult = &service.UpgradePreview{Changes: &aptlib.PackageChanges{}}
+		actions := newTestActions(km, &mockAptActions{installedPkgs: map[string]string{}}, nil)
+		actions.appConfig.ConfigManager.GetConfig().Kernel = policy
+		return actions
+	}
+
+	t.Run("preferred flavour is used when available", func(t *testing.T) {
+		km := &mockKernelManager{latestByFlavour: map[string]*service.Info{"std-def": stdDef, "un-def": unDef}}
+		actions := newPolicyActions(km, app.KernelPolicy{PreferredFlavour: "un-def", AutoSwitch: true})
+
+		resp, err := actions.UpdateKernel(testContext(), "", nil, false, true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Kernel.Flavour != "un-def" {
+			t.Errorf("expected flavour un-def, got %s", resp.Kernel.Flavour)
+		}
+	})
+
+	t.Run("preferred flavour is ignored without auto switch", func(t *testing.T) {
+		km := &mockKernelManager{latestByFlavour: map[string]*service.Info{"std-def": stdDef, "un-def": unDef}}
+		actions := newPolicyActions(km, app.KernelPolicy{PreferredFlavour: "un-def"})
+
+		resp, err := actions.UpdateKernel(testContext(), "", nil, false, true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Kernel.Flavour != "std-def" {
+			t.Errorf("expected flavour std-def, got %s", resp.Kernel.Flavour)
+		}
+	})
+
+	t.Run("unavailable preferred flavour keeps current", func(t *testing.T) {
+		km := &mockKernelManager{latestByFlavour: map[string]*service.Info{"std-def": stdDef}}
+		actions := newPolicyActions(km, app.KernelPolicy{PreferredFlavour: "un-def", AutoSwitch: true})
+
+		resp, err := actions.UpdateKernel(testContext(), "", nil, false, true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Kernel.Flavour != "std-def" {
+			t.Errorf("expected flavour std-def, got %s", resp.Kernel.Flavour)
+		}
+	})
+
+	t.Run("explicit flavour overrides policy", func(t *testing.T) {
+		km := &mockKernelManager{latestByFlavour: map[string]*service.Info{"std-def": stdDef, "un-def": unDef}}
+		actions := newPolicyActions(km, app.KernelPolicy{PreferredFlavour: "un-def", AutoSwitch: true})
+
+		resp, err := actions.UpdateKernel(testContext(), "std-def", nil, false, true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Kernel.Flavour != "std-def" {
+			t.Errorf("expected flavour std-def, got %s", resp.Kernel.Flavour)
+		}
+	})
+
+	t.Run("missing current flavour switches to next", func(t *testing.T) {
+		km := &mockKernelManager{
+			latestByFlavour:  map[string]*service.Info{"6.12": next},
+			findNextFlavours: []string{"6.12", "un-def"},
+		}
+		actions := newPolicyActions(km, app.KernelPolicy{AutoSwitch: true})
+
+		resp, err := actions.UpdateKernel(testContext(), "", nil, false, true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Kernel.Flavour != "6.12" {
+			t.Errorf("expected flavour 6.12, got %s", resp.Kernel.Flavour)
+		}
+	})
+
+	t.Run("missing current flavour without auto switch returns not found", func(t *testing.T) {
+		km := &mockKernelManager{
+			latestByFlavour:  map[string]*service.Info{"6.12": next},
+			findNextFlavours: []string{"6.12"},
+		}
+		actions := newPolicyActions(km, app.KernelPolicy{})
+
+		_, err := actions.UpdateKernel(testContext(), "", nil, false, true)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNotFound)
+	})
+}
+
 func TestCleanOldKernels(t *testing.T) {
 	current := &service.Info{
 		PackageName: "kernel-image-6.12",
@@ -632,7 +826,7 @@ func TestListKernelModules(t *testing.T) {
 
 	t.Run("find modules error propagates", func(t *testing.T) {
 		km := &mockKernelManager{
-			findLatestResult: latest,
+			findLatestResult:    latest,
 			availableModulesErr: errors.New("db error"),
 		}
 		actions := newTestActions(km, nil, nil)
@@ -839,3 +1033,212 @@ func TestRemoveKernelModules(t *testing.T) {
 		}
 	})
 }
+
+func TestSetDefaultKernel(t *testing.T) {
+	older := testKernel("6.12", "6.12.3", "kernel-image-6.12=6.12.3-alt1")
+	plan := &service.BootDefaultPlan{Bootloader: service.BootloaderSymlink, Commands: []string{"ln -sfn vmlinuz-6.12.3-6.12-alt1 /boot/vmlinuz"}}
+
+	t.Run("empty version returns validation error", func(t *testing.T) {
+		actions := newTestActions(nil, nil, nil)
+
+		_, err := actions.SetDefaultKernel(testContext(), " ", false)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+	})
+
+	t.Run("unknown kernel returns not found", func(t *testing.T) {
+		km := &mockKernelManager{rpmKernels: []*service.Info{older}}
+		actions := newTestActions(km, nil, nil)
+
+		_, err := actions.SetDefaultKernel(testContext(), "6.1.0-std-def-alt1", false)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNotFound)
+	})
+
+	t.Run("dry run does not change bootloader", func(t *testing.T) {
+		km := &mockKernelManager{rpmKernels: []*service.Info{older}, bootPlan: plan}
+		actions := newTestActions(km, nil, nil)
+
+		resp, err := actions.SetDefaultKernel(testContext(), "6.12.3-6.12-alt1", true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if km.defaultApplied {
+			t.Error("dry run must not apply the plan")
+		}
+		if resp.Boot.Bootloader != service.BootloaderSymlink || len(resp.Boot.Commands) != 1 {
+			t.Errorf("unexpected plan: %+v", resp.Boot)
+		}
+	})
+
+	t.Run("full version applies plan", func(t *testing.T) {
+		km := &mockKernelManager{rpmKernels: []*service.Info{older}, bootPlan: plan}
+		actions := newTestActions(km, nil, nil)
+
+		resp, err := actions.SetDefaultKernel(testContext(), older.FullVersion, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !km.defaultApplied {
+			t.Error("expected plan to be applied")
+		}
+		if resp.Kernel.FullVersion != older.FullVersion {
+			t.Errorf("unexpected kernel: %+v", resp.Kernel)
+		}
+	})
+
+	t.Run("bootloader error propagates", func(t *testing.T) {
+		km := &mockKernelManager{rpmKernels: []*service.Info{older}, bootPlanErr: errors.New("grub menu entry not found")}
+		actions := newTestActions(km, nil, nil)
+
+		_, err := actions.SetDefaultKernel(testContext(), older.FullVersion, false)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeKernel)
+	})
+}
+
+func TestKernelCmdline(t *testing.T) {
+	current := testKernel("6.12", "6.12.10", "kernel-image-6.12=6.12.10-alt1")
+	older := testKernel("6.12", "6.12.3", "kernel-image-6.12=6.12.3-alt1")
+	cmdline := &service.Cmdline{Source: service.CmdlineSourceGrubby, Scope: "6.12.10-6.12-alt1", Args: []string{"ro", "quiet"}}
+
+	t.Run("get uses current kernel by default", func(t *testing.T) {
+		km := &mockKernelManager{currentKernel: current, cmdline: cmdline}
+		actions := newTestActions(km, nil, nil)
+
+		resp, err := actions.GetKernelCmdline(testContext(), "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Kernel.FullVersion != current.FullVersion || !slices.Equal(resp.Cmdline.Args, cmdline.Args) {
+			t.Errorf("unexpected response: %+v", resp)
+		}
+	})
+
+	t.Run("set without parameters returns validation error", func(t *testing.T) {
+		actions := newTestActions(&mockKernelManager{currentKernel: current}, nil, nil)
+
+		_, err := actions.SetKernelCmdline(testContext(), "", nil, nil, false)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+	})
+
+	t.Run("set with conflicting parameters returns validation error", func(t *testing.T) {
+		actions := newTestActions(&mockKernelManager{currentKernel: current}, nil, nil)
+
+		_, err := actions.SetKernelCmdline(testContext(), "", []string{"quiet"}, []string{"quiet"}, false)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+	})
+
+	t.Run("set without changes returns no operation", func(t *testing.T) {
+		km := &mockKernelManager{currentKernel: current, cmdlineChange: &service.CmdlineChange{After: *cmdline}}
+		actions := newTestActions(km, nil, nil)
+
+		_, err := actions.SetKernelCmdline(testContext(), "", []string{"quiet"}, nil, false)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNoOperation)
+	})
+
+	t.Run("set returns diff", func(t *testing.T) {
+		km := &mockKernelManager{
+			currentKernel: current,
+			rpmKernels:    []*service.Info{current, older},
+			cmdlineChange: &service.CmdlineChange{
+				After: service.Cmdline{Args: []string{"ro", "quiet", "splash"}},
+				Added: []string{"splash"},
+				Diff:  "-ro quiet\n+ro quiet splash\n",
+			},
+		}
+		actions := newTestActions(km, nil, nil)
+
+		resp, err := actions.SetKernelCmdline(testContext(), older.FullVersion, []string{"splash"}, nil, true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Kernel.FullVersion != older.FullVersion || resp.Diff == "" || !slices.Equal(resp.Added, []string{"splash"}) {
+			t.Errorf("unexpected response: %+v", resp)
+		}
+	})
+}
+
+func TestAtomicKernel(t *testing.T) {
+	latest := testKernel("6.12", "6.12.10", "kernel-image-6.12#6.12.10-alt1")
+	changes := &aptlib.PackageChanges{
+		NewInstalledPackages: []string{"kernel-image-6.12"},
+		NewInstalledCount:    1,
+	}
+
+	t.Run("install writes kernel to image and rebuilds", func(t *testing.T) {
+		km := &mockKernelManager{
+			findLatestResult: latest,
+			simulateResult:   &service.UpgradePreview{Changes: changes},
+			installKernelErr: errors.New("live system must not be touched"),
+		}
+		hc := &mockHostConfig{config: build.Config{Image: "registry/image:latest"}}
+		hi := &mockHostImage{}
+		actions := newAtomicTestActions(km, hc, hi)
+
+		resp, err := actions.InstallKernel(testContext(), "6.12", []string{"nvidia"}, true, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !resp.NextBoot {
+			t.Error("expected NextBoot to be set")
+		}
+		if !hi.built {
+			t.Error("expected image rebuild")
+		}
+		if hc.kernel == nil || hc.kernel.Flavour != "6.12" || !hc.kernel.IncludeHeaders {
+			t.Errorf("unexpected kernel section: %+v", hc.kernel)
+		}
+	})
+
+	t.Run("image without base returns image error", func(t *testing.T) {
+		km := &mockKernelManager{
+			findLatestResult: latest,
+			simulateResult:   &service.UpgradePreview{Changes: changes},
+		}
+		actions := newAtomicTestActions(km, &mockHostConfig{}, &mockHostImage{})
+
+		_, err := actions.InstallKernel(testContext(), "6.12", nil, false, false)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeImage)
+	})
+
+	t.Run("build error propagates", func(t *testing.T) {
+		km := &mockKernelManager{
+			findLatestResult: latest,
+			simulateResult:   &service.UpgradePreview{Changes: changes},
+		}
+		hc := &mockHostConfig{config: build.Config{Image: "registry/image:latest"}}
+		actions := newAtomicTestActions(km, hc, &mockHostImage{buildErr: errors.New("build failed")})
+
+		_, err := actions.InstallKernel(testContext(), "6.12", nil, false, false)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeImage)
+	})
+
+	t.Run("clean old kernels is rejected", func(t *testing.T) {
+		actions := newAtomicTestActions(nil, &mockHostConfig{}, &mockHostImage{})
+
+		_, err := actions.CleanOldKernels(testContext(), false, false)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+	})
+
+	t.Run("set default kernel is rejected", func(t *testing.T) {
+		actions := newAtomicTestActions(nil, &mockHostConfig{}, &mockHostImage{})
+
+		_, err := actions.SetDefaultKernel(testContext(), "6.12.3-6.12-alt1", false)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+	})
+
+	t.Run("cmdline of non-booted kernel is rejected", func(t *testing.T) {
+		current := testKernel("6.12", "6.12.10", "kernel-image-6.12=6.12.10-alt1")
+		older := testKernel("6.12", "6.12.3", "kernel-image-6.12=6.12.3-alt1")
+		km := &mockKernelManager{currentKernel: current, rpmKernels: []*service.Info{current, older}}
+		actions := newAtomicTestActions(km, &mockHostConfig{}, &mockHostImage{})
+
+		_, err := actions.GetKernelCmdline(testContext(), older.FullVersion)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+	})
+}
+
+func TestMergeModules(t *testing.T) {
+	got := mergeModules([]string{"a", "b"}, []string{"c", "a"}, []string{"b"})
+	if !slices.Equal(got, []string{"a", "c"}) {
+		t.Errorf("unexpected modules: %v", got)
+	}
+}
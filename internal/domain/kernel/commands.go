@@ -21,6 +21,7 @@ import (
 	"apm/internal/common/app"
 	apmcli "apm/internal/common/cli"
 	"apm/internal/common/reply"
+	"apm/internal/domain/kernel/service"
 	"context"
 	"errors"
 
@@ -33,6 +34,28 @@ func newErrorResponseFromError(err error) reply.APIResponse {
 	return reply.ErrorResponseFromError(err)
 }
 
+// completeFlavours дополняет flavour ядра в значении --flavour, а при positional и первым аргументом.
+func completeFlavours(appConfig *app.Config, reporter *reply.Reporter, positional bool) func(ctx context.Context, cmd *cli.Command) {
+	return func(ctx context.Context, cmd *cli.Command) {
+		if !apmcli.CompletingFlag("flavour") {
+			if apmcli.CompleteFlags(ctx, cmd) || !positional || apmcli.CompletingFlagValue(cmd) || cmd.NArg() > 1 {
+				return
+			}
+		}
+
+		kernels, err := NewActions(appConfig, reporter).kernelManager.ListKernels(ctx, "")
+		if err != nil {
+			return
+		}
+		flavours := make([]string, 0, len(kernels))
+		for _, k := range kernels {
+			flavours = append(flavours, k.Flavour)
+		}
+		current, _ := apmcli.CompletionToken()
+		apmcli.PrintCompletions(current, flavours)
+	}
+}
+
 func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 	withRootCheckWrapper := apmcli.WithOptions(appConfig, reporter, apmcli.RequireRoot, NewActions, newErrorResponseFromError)
 
@@ -56,14 +79,20 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 						Aliases: []string{"i"},
 						Value:   false,
 					},
+					apmcli.ColumnsFlag(),
 				},
 				Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+					if _, err := apmcli.ApplyColumns(appConfig, cmd.StringSlice("columns"), service.FullKernelInfo{}); err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+
 					resp, err := actions.ListKernels(ctx, cmd.String("flavour"), cmd.Bool("installed"))
 					if err != nil {
 						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
 					}
 					return reporter.CliResponse(ctx, reply.OK(resp))
 				}),
+				ShellComplete: completeFlavours(appConfig, reporter, false),
 			},
 			{
 				Name:  "info",
@@ -100,7 +129,7 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 				Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
 					flavour := cmd.Args().First()
 					if flavour == "" {
-						return reporter.CliResponse(ctx, newErrorResponseFromError(apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.T_("Kernel flavour must be specified")))))
+						return reporter.CliResponse(ctx, newErrorResponseFromError(apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Kernel flavour must be specified")))))
 					}
 
 					resp, err := actions.InstallKernel(ctx, flavour, cmd.StringSlice("modules"), cmd.Bool("headers"), cmd.Bool("simulate"))
@@ -109,6 +138,7 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 					}
 					return reporter.CliResponse(ctx, reply.OK(resp))
 				}),
+				ShellComplete: completeFlavours(appConfig, reporter, true),
 			},
 			{
 				Name:  "update",
@@ -116,7 +146,7 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 				Flags: []cli.Flag{
 					&cli.StringFlag{
 						Name:  "flavour",
-						Usage: app.T_("Update to specific flavour (default: current flavour)"),
+						Usage: app.T_("Update to specific flavour (default: current flavour or kernel.preferredFlavour)"),
 					},
 					&cli.StringSliceFlag{
 						Name:  "modules",
@@ -141,6 +171,7 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 					}
 					return reporter.CliResponse(ctx, reply.OK(resp))
 				}),
+				ShellComplete: completeFlavours(appConfig, reporter, false),
 			},
 			{
 				Name:  "clean",
@@ -166,6 +197,77 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 					return reporter.CliResponse(ctx, reply.OK(resp))
 				}),
 			},
+			{
+				Name:      "set-default",
+				Usage:     app.T_("Set installed kernel to boot by default"),
+				ArgsUsage: "full-version",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:    "simulate",
+						Usage:   app.T_("Show bootloader changes without applying them"),
+						Value:   false,
+						Aliases: []string{"s"},
+					},
+				},
+				Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+					version := cmd.Args().First()
+					if version == "" {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Kernel version must be specified")))))
+					}
+
+					resp, err := actions.SetDefaultKernel(ctx, version, cmd.Bool("simulate"))
+					if err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+					return reporter.CliResponse(ctx, reply.OK(resp))
+				}),
+			},
+			{
+				Name:  "cmdline",
+				Usage: app.T_("Kernel command line management"),
+				Commands: []*cli.Command{
+					{
+						Name:      "get",
+						Usage:     app.T_("Show kernel command line parameters"),
+						ArgsUsage: "[full-version]",
+						Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+							resp, err := actions.GetKernelCmdline(ctx, cmd.Args().First())
+							if err != nil {
+								return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+							}
+							return reporter.CliResponse(ctx, reply.OK(resp))
+						}),
+					},
+					{
+						Name:      "set",
+						Usage:     app.T_("Add or remove kernel command line parameters"),
+						ArgsUsage: "[full-version]",
+						Flags: []cli.Flag{
+							&cli.StringSliceFlag{
+								Name:  "add",
+								Usage: app.T_("Parameters to add (e.g., quiet, console=ttyS0)"),
+							},
+							&cli.StringSliceFlag{
+								Name:  "remove",
+								Usage: app.T_("Parameters to remove, a name without value removes all its values"),
+							},
+							&cli.BoolFlag{
+								Name:    "simulate",
+								Usage:   app.T_("Show changes without applying them"),
+								Aliases: []string{"s"},
+								Value:   false,
+							},
+						},
+						Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+							resp, err := actions.SetKernelCmdline(ctx, cmd.Args().First(), cmd.StringSlice("add"), cmd.StringSlice("remove"), cmd.Bool("simulate"))
+							if err != nil {
+								return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+							}
+							return reporter.CliResponse(ctx, reply.OK(resp))
+						}),
+					},
+				},
+			},
 			{
 				Name:  "modules",
 				Usage: app.T_("Kernel modules management"),
@@ -190,6 +292,7 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 							}
 							return reporter.CliResponse(ctx, reply.OK(resp))
 						}),
+						ShellComplete: completeFlavours(appConfig, reporter, true),
 					},
 					{
 						Name:      "install",
@@ -210,7 +313,7 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 						Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
 							modules := cmd.Args().Slice()
 							if len(modules) == 0 {
-								return reporter.CliResponse(ctx, newErrorResponseFromError(apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.T_("At least one module must be specified")))))
+								return reporter.CliResponse(ctx, newErrorResponseFromError(apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "At least one module must be specified")))))
 							}
 
 							resp, err := actions.InstallKernelModules(ctx, cmd.String("flavour"), modules, cmd.Bool("simulate"))
@@ -219,6 +322,7 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 							}
 							return reporter.CliResponse(ctx, reply.OK(resp))
 						}),
+						ShellComplete: completeFlavours(appConfig, reporter, false),
 					},
 					{
 						Name:      "remove",
@@ -239,7 +343,7 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 						Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
 							modules := cmd.Args().Slice()
 							if len(modules) == 0 {
-								return reporter.CliResponse(ctx, newErrorResponseFromError(apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.T_("At least one module must be specified")))))
+								return reporter.CliResponse(ctx, newErrorResponseFromError(apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "At least one module must be specified")))))
 							}
 
 							resp, err := actions.RemoveKernelModules(ctx, cmd.String("flavour"), modules, cmd.Bool("simulate"))
@@ -248,6 +352,7 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 							}
 							return reporter.CliResponse(ctx, reply.OK(resp))
 						}),
+						ShellComplete: completeFlavours(appConfig, reporter, false),
 					},
 				},
 			},
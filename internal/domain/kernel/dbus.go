@@ -52,10 +52,17 @@ func NewDBusWrapper(a *Actions, c *dbus.Conn, ctx context.Context) *DBusWrapper
 	return &DBusWrapper{actions: a, conn: c, ctx: ctx}
 }
 
-// checkManagePermission проверяет права org.altlinux.APM.manage
-func (w *DBusWrapper) checkManagePermission(sender dbus.Sender) *dbus.Error {
-	if err := helper.PolkitCheck(w.conn, sender, "org.altlinux.APM.manage"); err != nil {
-		return dbus.MakeFailedError(err)
+// WithLocale возвращает копию обёртки, формирующую сообщения на языке клиента lang
+func (w *DBusWrapper) WithLocale(lang string) any {
+	localized := *w
+	localized.ctx = app.WithLocale(w.ctx, lang)
+	return &localized
+}
+
+// checkPermission проверяет права на действие Polkit action
+func (w *DBusWrapper) checkPermission(sender dbus.Sender, action string) *dbus.Error {
+	if err := helper.PolkitCheck(w.conn, sender, action); err != nil {
+		return apmerr.DBusError(apmerr.New(apmerr.ErrorTypePermission, err))
 	}
 	return nil
 }
@@ -90,7 +97,7 @@ func (w *DBusWrapper) GetCurrentKernel(transaction string) (string, *dbus.Error)
 
 // CheckInstallKernel проверяет возможность установки ядра.
 func (w *DBusWrapper) CheckInstallKernel(sender dbus.Sender, flavour string, modules []string, includeHeaders bool, transaction string, background bool) (string, *dbus.Error) {
-	if err := w.checkManagePermission(sender); err != nil {
+	if err := w.checkPermission(sender, helper.PolkitActionInstall); err != nil {
 		return "", err
 	}
 
@@ -99,14 +106,14 @@ func (w *DBusWrapper) CheckInstallKernel(sender dbus.Sender, flavour string, mod
 	}
 
 	if background {
-		ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventKernelCheckInstall)
 		go func() {
 			resp, err := w.actions.InstallKernel(ctx, flavour, modules, includeHeaders, true)
 			w.actions.reporter.SendTaskResult(ctx, reply.EventKernelCheckInstall, resp, err)
 		}()
 
 		bgResp := BackgroundTaskResponse{
-			Message:     app.T_("Task started in background"),
+			Message:     app.TL_(w.ctx, "Task started in background"),
 			Transaction: transaction,
 		}
 		data, jerr := json.Marshal(reply.OK(bgResp))
@@ -130,7 +137,7 @@ func (w *DBusWrapper) CheckInstallKernel(sender dbus.Sender, flavour string, mod
 
 // InstallKernel устанавливает ядро.
 func (w *DBusWrapper) InstallKernel(sender dbus.Sender, flavour string, modules []string, includeHeaders bool, transaction string, background bool) (string, *dbus.Error) {
-	if err := w.checkManagePermission(sender); err != nil {
+	if err := w.checkPermission(sender, helper.PolkitActionInstall); err != nil {
 		return "", err
 	}
 
@@ -139,14 +146,14 @@ func (w *DBusWrapper) InstallKernel(sender dbus.Sender, flavour string, modules
 	}
 
 	if background {
-		ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventKernelInstall)
 		go func() {
 			resp, err := w.actions.InstallKernel(ctx, flavour, modules, includeHeaders, false)
 			w.actions.reporter.SendTaskResult(ctx, reply.EventKernelInstall, resp, err)
 		}()
 
 		bgResp := BackgroundTaskResponse{
-			Message:     app.T_("Task started in background"),
+			Message:     app.TL_(w.ctx, "Task started in background"),
 			Transaction: transaction,
 		}
 		data, jerr := json.Marshal(reply.OK(bgResp))
@@ -170,7 +177,7 @@ func (w *DBusWrapper) InstallKernel(sender dbus.Sender, flavour string, modules
 
 // CheckUpdateKernel проверяет возможность обновления ядра.
 func (w *DBusWrapper) CheckUpdateKernel(sender dbus.Sender, flavour string, modules []string, includeHeaders bool, transaction string, background bool) (string, *dbus.Error) {
-	if err := w.checkManagePermission(sender); err != nil {
+	if err := w.checkPermission(sender, helper.PolkitActionUpgrade); err != nil {
 		return "", err
 	}
 
@@ -179,14 +186,14 @@ func (w *DBusWrapper) CheckUpdateKernel(sender dbus.Sender, flavour string, modu
 	}
 
 	if background {
-		ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventKernelCheckUpdate)
 		go func() {
 			resp, err := w.actions.UpdateKernel(ctx, flavour, modules, includeHeaders, true)
 			w.actions.reporter.SendTaskResult(ctx, reply.EventKernelCheckUpdate, resp, err)
 		}()
 
 		bgResp := BackgroundTaskResponse{
-			Message:     app.T_("Task started in background"),
+			Message:     app.TL_(w.ctx, "Task started in background"),
 			Transaction: transaction,
 		}
 		data, jerr := json.Marshal(reply.OK(bgResp))
@@ -210,7 +217,7 @@ func (w *DBusWrapper) CheckUpdateKernel(sender dbus.Sender, flavour string, modu
 
 // UpdateKernel обновляет ядро.
 func (w *DBusWrapper) UpdateKernel(sender dbus.Sender, flavour string, modules []string, includeHeaders bool, transaction string, background bool) (string, *dbus.Error) {
-	if err := w.checkManagePermission(sender); err != nil {
+	if err := w.checkPermission(sender, helper.PolkitActionUpgrade); err != nil {
 		return "", err
 	}
 
@@ -219,14 +226,14 @@ func (w *DBusWrapper) UpdateKernel(sender dbus.Sender, flavour string, modules [
 	}
 
 	if background {
-		ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventKernelUpdate)
 		go func() {
 			resp, err := w.actions.UpdateKernel(ctx, flavour, modules, includeHeaders, false)
 			w.actions.reporter.SendTaskResult(ctx, reply.EventKernelUpdate, resp, err)
 		}()
 
 		bgResp := BackgroundTaskResponse{
-			Message:     app.T_("Task started in background"),
+			Message:     app.TL_(w.ctx, "Task started in background"),
 			Transaction: transaction,
 		}
 		data, jerr := json.Marshal(reply.OK(bgResp))
@@ -250,7 +257,7 @@ func (w *DBusWrapper) UpdateKernel(sender dbus.Sender, flavour string, modules [
 
 // CheckCleanOldKernels проверяет возможность удаления старых ядер.
 func (w *DBusWrapper) CheckCleanOldKernels(sender dbus.Sender, noBackup bool, transaction string, background bool) (string, *dbus.Error) {
-	if err := w.checkManagePermission(sender); err != nil {
+	if err := w.checkPermission(sender, helper.PolkitActionRemove); err != nil {
 		return "", err
 	}
 
@@ -259,14 +266,14 @@ func (w *DBusWrapper) CheckCleanOldKernels(sender dbus.Sender, noBackup bool, tr
 	}
 
 	if background {
-		ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventKernelCheckClean)
 		go func() {
 			resp, err := w.actions.CleanOldKernels(ctx, noBackup, true)
 			w.actions.reporter.SendTaskResult(ctx, reply.EventKernelCheckClean, resp, err)
 		}()
 
 		bgResp := BackgroundTaskResponse{
-			Message:     app.T_("Task started in background"),
+			Message:     app.TL_(w.ctx, "Task started in background"),
 			Transaction: transaction,
 		}
 		data, jerr := json.Marshal(reply.OK(bgResp))
@@ -290,7 +297,7 @@ func (w *DBusWrapper) CheckCleanOldKernels(sender dbus.Sender, noBackup bool, tr
 
 // CleanOldKernels удаляет старые ядра.
 func (w *DBusWrapper) CleanOldKernels(sender dbus.Sender, noBackup bool, transaction string, background bool) (string, *dbus.Error) {
-	if err := w.checkManagePermission(sender); err != nil {
+	if err := w.checkPermission(sender, helper.PolkitActionRemove); err != nil {
 		return "", err
 	}
 
@@ -299,14 +306,14 @@ func (w *DBusWrapper) CleanOldKernels(sender dbus.Sender, noBackup bool, transac
 	}
 
 	if background {
-		ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventKernelClean)
 		go func() {
 			resp, err := w.actions.CleanOldKernels(ctx, noBackup, false)
 			w.actions.reporter.SendTaskResult(ctx, reply.EventKernelClean, resp, err)
 		}()
 
 		bgResp := BackgroundTaskResponse{
-			Message:     app.T_("Task started in background"),
+			Message:     app.TL_(w.ctx, "Task started in background"),
 			Transaction: transaction,
 		}
 		data, jerr := json.Marshal(reply.OK(bgResp))
@@ -328,6 +335,56 @@ func (w *DBusWrapper) CleanOldKernels(sender dbus.Sender, noBackup bool, transac
 	return string(data), nil
 }
 
+// SetDefaultKernel делает установленное ядро загружаемым по умолчанию.
+func (w *DBusWrapper) SetDefaultKernel(sender dbus.Sender, version string, dryRun bool, transaction string) (string, *dbus.Error) {
+	if err := w.checkPermission(sender, helper.PolkitActionUpgrade); err != nil {
+		return "", err
+	}
+
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.SetDefaultKernel(ctx, version, dryRun)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// GetKernelCmdline возвращает параметры командной строки ядра. Пустая version — текущее ядро.
+func (w *DBusWrapper) GetKernelCmdline(version string, transaction string) (string, *dbus.Error) {
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.GetKernelCmdline(ctx, version)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// SetKernelCmdline добавляет и удаляет параметры командной строки ядра.
+func (w *DBusWrapper) SetKernelCmdline(sender dbus.Sender, version string, add []string, remove []string, dryRun bool, transaction string) (string, *dbus.Error) {
+	if err := w.checkPermission(sender, helper.PolkitActionManage); err != nil {
+		return "", err
+	}
+
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.SetKernelCmdline(ctx, version, add, remove, dryRun)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
 // ListKernelModules возвращает список модулей ядра.
 func (w *DBusWrapper) ListKernelModules(flavour string, transaction string) (string, *dbus.Error) {
 	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
@@ -344,7 +401,7 @@ func (w *DBusWrapper) ListKernelModules(flavour string, transaction string) (str
 
 // CheckInstallKernelModules проверяет возможность установки модулей ядра.
 func (w *DBusWrapper) CheckInstallKernelModules(sender dbus.Sender, flavour string, modules []string, transaction string, background bool) (string, *dbus.Error) {
-	if err := w.checkManagePermission(sender); err != nil {
+	if err := w.checkPermission(sender, helper.PolkitActionInstall); err != nil {
 		return "", err
 	}
 
@@ -353,14 +410,14 @@ func (w *DBusWrapper) CheckInstallKernelModules(sender dbus.Sender, flavour stri
 	}
 
 	if background {
-		ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventKernelCheckInstallMods)
 		go func() {
 			resp, err := w.actions.InstallKernelModules(ctx, flavour, modules, true)
 			w.actions.reporter.SendTaskResult(ctx, reply.EventKernelCheckInstallMods, resp, err)
 		}()
 
 		bgResp := BackgroundTaskResponse{
-			Message:     app.T_("Task started in background"),
+			Message:     app.TL_(w.ctx, "Task started in background"),
 			Transaction: transaction,
 		}
 		data, jerr := json.Marshal(reply.OK(bgResp))
@@ -384,7 +441,7 @@ func (w *DBusWrapper) CheckInstallKernelModules(sender dbus.Sender, flavour stri
 
 // InstallKernelModules устанавливает модули ядра.
 func (w *DBusWrapper) InstallKernelModules(sender dbus.Sender, flavour string, modules []string, transaction string, background bool) (string, *dbus.Error) {
-	if err := w.checkManagePermission(sender); err != nil {
+	if err := w.checkPermission(sender, helper.PolkitActionInstall); err != nil {
 		return "", err
 	}
 
@@ -393,14 +450,14 @@ func (w *DBusWrapper) InstallKernelModules(sender dbus.Sender, flavour string, m
 	}
 
 	if background {
-		ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventKernelInstallMods)
 		go func() {
 			resp, err := w.actions.InstallKernelModules(ctx, flavour, modules, false)
 			w.actions.reporter.SendTaskResult(ctx, reply.EventKernelInstallMods, resp, err)
 		}()
 
 		bgResp := BackgroundTaskResponse{
-			Message:     app.T_("Task started in background"),
+			Message:     app.TL_(w.ctx, "Task started in background"),
 			Transaction: transaction,
 		}
 		data, jerr := json.Marshal(reply.OK(bgResp))
@@ -424,7 +481,7 @@ func (w *DBusWrapper) InstallKernelModules(sender dbus.Sender, flavour string, m
 
 // CheckRemoveKernelModules проверяет возможность удаления модулей ядра.
 func (w *DBusWrapper) CheckRemoveKernelModules(sender dbus.Sender, flavour string, modules []string, transaction string, background bool) (string, *dbus.Error) {
-	if err := w.checkManagePermission(sender); err != nil {
+	if err := w.checkPermission(sender, helper.PolkitActionRemove); err != nil {
 		return "", err
 	}
 
@@ -433,14 +490,14 @@ func (w *DBusWrapper) CheckRemoveKernelModules(sender dbus.Sender, flavour strin
 	}
 
 	if background {
-		ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventKernelCheckRemoveMods)
 		go func() {
 			resp, err := w.actions.RemoveKernelModules(ctx, flavour, modules, true)
 			w.actions.reporter.SendTaskResult(ctx, reply.EventKernelCheckRemoveMods, resp, err)
 		}()
 
 		bgResp := BackgroundTaskResponse{
-			Message:     app.T_("Task started in background"),
+			Message:     app.TL_(w.ctx, "Task started in background"),
 			Transaction: transaction,
 		}
 		data, jerr := json.Marshal(reply.OK(bgResp))
@@ -464,7 +521,7 @@ func (w *DBusWrapper) CheckRemoveKernelModules(sender dbus.Sender, flavour strin
 
 // RemoveKernelModules удаляет модули ядра.
 func (w *DBusWrapper) RemoveKernelModules(sender dbus.Sender, flavour string, modules []string, transaction string, background bool) (string, *dbus.Error) {
-	if err := w.checkManagePermission(sender); err != nil {
+	if err := w.checkPermission(sender, helper.PolkitActionRemove); err != nil {
 		return "", err
 	}
 
@@ -473,14 +530,14 @@ func (w *DBusWrapper) RemoveKernelModules(sender dbus.Sender, flavour string, mo
 	}
 
 	if background {
-		ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventKernelRemoveMods)
 		go func() {
 			resp, err := w.actions.RemoveKernelModules(ctx, flavour, modules, false)
 			w.actions.reporter.SendTaskResult(ctx, reply.EventKernelRemoveMods, resp, err)
 		}()
 
 		bgResp := BackgroundTaskResponse{
-			Message:     app.T_("Task started in background"),
+			Message:     app.TL_(w.ctx, "Task started in background"),
 			Transaction: transaction,
 		}
 		data, jerr := json.Marshal(reply.OK(bgResp))
@@ -0,0 +1,105 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package kernel
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/build/models"
+	"apm/internal/common/journal"
+	"context"
+	"slices"
+)
+
+// isAtomic сообщает, что ядро входит в образ системы и меняется только через его пересборку.
+func (a *Actions) isAtomic() bool {
+	return a.appConfig.ConfigManager.GetConfig().IsAtomic
+}
+
+// applyKernelToImage записывает параметры ядра в конфигурацию образа и пересобирает образ.
+// Живая система не изменяется, новое ядро загрузится при следующей загрузке.
+func (a *Actions) applyKernelToImage(ctx context.Context, info models.KernelInfo) error {
+	if err := a.loadImageConfig(); err != nil {
+		return err
+	}
+
+	return a.rebuildImageWithKernel(ctx, info)
+}
+
+// rebuildImageWithKernel сохраняет параметры ядра в загруженную конфигурацию образа и пересобирает его.
+func (a *Actions) rebuildImageWithKernel(ctx context.Context, info models.KernelInfo) error {
+	if err := a.serviceHostConfig.SetKernel(info); err != nil {
+		return apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+
+	if err := a.serviceHostConfig.GenerateDockerfile(true); err != nil {
+		return apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+
+	if err := a.serviceHostImage.BuildAndSwitch(ctx, false, true, a.serviceHostConfig); err != nil {
+		return apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+
+	a.stateNotifier.Emit(app.ImagePendingSignal, journal.ActionApply, []string{a.serviceHostConfig.GetConfig().Image})
+	return nil
+}
+
+// updateImageKernelModules добавляет и удаляет модули в секции ядра конфигурации образа,
+// после чего пересобирает образ. Если секция ядра ещё не задана, она создаётся для flavour.
+func (a *Actions) updateImageKernelModules(ctx context.Context, flavour string, add []string, remove []string) error {
+	if err := a.loadImageConfig(); err != nil {
+		return err
+	}
+
+	info := models.KernelInfo{Flavour: flavour}
+	if current := a.serviceHostConfig.GetConfig().GetKernel(); current != nil {
+		info = *current
+	}
+
+	info.Modules = mergeModules(info.Modules, add, remove)
+
+	return a.rebuildImageWithKernel(ctx, info)
+}
+
+// loadImageConfig загружает конфигурацию образа и проверяет, что базовый образ задан.
+func (a *Actions) loadImageConfig() error {
+	if err := a.serviceHostConfig.LoadConfig(); err != nil {
+		return apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+
+	if err := a.serviceHostConfig.GetConfig().CheckImage(); err != nil {
+		return apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+
+	return nil
+}
+
+// mergeModules возвращает список модулей с добавленными add и исключёнными remove.
+func mergeModules(modules []string, add []string, remove []string) []string {
+	result := make([]string, 0, len(modules)+len(add))
+	for _, m := range modules {
+		if !slices.Contains(remove, m) && !slices.Contains(result, m) {
+			result = append(result, m)
+		}
+	}
+	for _, m := range add {
+		if !slices.Contains(remove, m) && !slices.Contains(result, m) {
+			result = append(result, m)
+		}
+	}
+	return result
+}
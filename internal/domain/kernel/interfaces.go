@@ -19,6 +19,10 @@ package kernel
 import (
 	_package "apm/internal/common/apt/package"
 	aptlib "apm/internal/common/binding/apt/lib"
+	"apm/internal/common/build"
+	"apm/internal/common/build/models"
+	"apm/internal/common/hooks"
+	"apm/internal/common/journal"
 	"apm/internal/domain/kernel/service"
 	"context"
 )
@@ -44,7 +48,7 @@ type kernelManagerService interface {
 	InheritModulesFromKernel(targetKernel *service.Info, sourceKernel *service.Info) ([]string, error)
 	AutoSelectHeadersAndFirmware(ctx context.Context, kernel *service.Info, includeHeaders bool) ([]string, error)
 	SimulateUpgrade(kernel *service.Info, modules []string, includeHeaders bool) (*service.UpgradePreview, error)
-	InstallKernel(ctx context.Context, kernel *service.Info, modules []string, includeHeaders bool, dryRun bool) error
+	InstallKernel(ctx context.Context, kernel *service.Info, modules []string, includeHeaders bool, dryRun bool) ([]service.ModuleRebuild, error)
 	FindNextFlavours(minVersion string) ([]string, error)
 	ListInstalledKernelsFromRPM(ctx context.Context) ([]*service.Info, error)
 	GetBackupKernel(ctx context.Context) (*service.Info, error)
@@ -56,4 +60,34 @@ type kernelManagerService interface {
 	InstallModules(ctx context.Context, installPackages []string, dryRun bool) (*aptlib.PackageChanges, error)
 	GetSimplePackageNameForModule(packageName string) string
 	BuildFullKernelInfo(info *service.Info) service.FullKernelInfo
+	PlanDefaultKernel(ctx context.Context, kernel *service.Info) (*service.BootDefaultPlan, error)
+	ApplyDefaultKernel(ctx context.Context, plan *service.BootDefaultPlan) error
+	GetCmdline(ctx context.Context, kernel *service.Info) (*service.Cmdline, error)
+	SetCmdline(ctx context.Context, kernel *service.Info, add []string, remove []string, dryRun bool) (*service.CmdlineChange, error)
+}
+
+// hostConfigService определяет методы для работы с конфигурацией образа на атомарной системе.
+type hostConfigService interface {
+	LoadConfig() error
+	GetConfig() *build.Config
+	SetKernel(info models.KernelInfo) error
+	GenerateDockerfile(hostCache bool) error
+	ConfigIsChanged(ctx context.Context) (bool, error)
+	SaveConfigToDB(ctx context.Context) error
+}
+
+// hostImageService определяет методы для пересборки образа на атомарной системе.
+type hostImageService interface {
+	BuildAndSwitch(ctx context.Context, pullImage bool, checkSame bool, hostConfigService build.SwitchableConfig) error
+}
+
+// journalService определяет методы записи истории операций.
+type journalService interface {
+	RecordResult(ctx context.Context, entry journal.Entry, opErr error) error
+}
+
+// hooksService определяет методы для выполнения хуков транзакций.
+type hooksService interface {
+	Before(ctx context.Context, tx hooks.Transaction) error
+	After(ctx context.Context, tx hooks.Transaction, opErr error) error
 }
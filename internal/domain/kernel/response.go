@@ -35,9 +35,11 @@ type GetCurrentKernelResponse struct {
 
 // InstallUpdateKernelResponse структура ответа для UpdateKernel/InstallKernel методов
 type InstallUpdateKernelResponse struct {
-	Message string                  `json:"message"`
-	Kernel  service.FullKernelInfo  `json:"kernel"`
-	Preview *service.UpgradePreview `json:"preview,omitempty"`
+	Message  string                  `json:"message"`
+	Kernel   service.FullKernelInfo  `json:"kernel"`
+	Preview  *service.UpgradePreview `json:"preview,omitempty"`
+	NextBoot bool                    `json:"nextBoot,omitempty"`
+	Rebuilds []service.ModuleRebuild `json:"rebuilds,omitempty"`
 }
 
 // WithReasons ядро с причинами сохранения
@@ -63,16 +65,35 @@ type ListKernelModulesResponse struct {
 
 // InstallKernelModulesResponse структура ответа для InstallKernelModules метода
 type InstallKernelModulesResponse struct {
-	Message string                 `json:"message"`
-	Kernel  service.FullKernelInfo `json:"kernel"`
-	Preview *aptlib.PackageChanges `json:"preview,omitempty"`
+	Message  string                 `json:"message"`
+	Kernel   service.FullKernelInfo `json:"kernel"`
+	Preview  *aptlib.PackageChanges `json:"preview,omitempty"`
+	NextBoot bool                   `json:"nextBoot,omitempty"`
 }
 
 // RemoveKernelModulesResponse структура ответа для RemoveKernelModules метода
 type RemoveKernelModulesResponse struct {
+	Message  string                 `json:"message"`
+	Kernel   service.FullKernelInfo `json:"kernel"`
+	Preview  *aptlib.PackageChanges `json:"preview,omitempty"`
+	NextBoot bool                   `json:"nextBoot,omitempty"`
+}
+
+// SetDefaultKernelResponse структура ответа для SetDefaultKernel метода
+type SetDefaultKernelResponse struct {
+	Message string                  `json:"message"`
+	Kernel  service.FullKernelInfo  `json:"kernel"`
+	Boot    service.BootDefaultPlan `json:"boot"`
+}
+
+// KernelCmdlineResponse структура ответа для GetKernelCmdline/SetKernelCmdline методов
+type KernelCmdlineResponse struct {
 	Message string                 `json:"message"`
 	Kernel  service.FullKernelInfo `json:"kernel"`
-	Preview *aptlib.PackageChanges `json:"preview,omitempty"`
+	Cmdline service.Cmdline        `json:"cmdline"`
+	Added   []string               `json:"added,omitempty"`
+	Removed []string               `json:"removed,omitempty"`
+	Diff    string                 `json:"diff,omitempty"`
 }
 
 // BackgroundTaskResponse структура ответа при запуске фоновой задачи
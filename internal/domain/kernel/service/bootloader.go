@@ -0,0 +1,254 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/command"
+	"apm/internal/common/helper"
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Загрузчики, с которыми умеет работать выбор ядра по умолчанию
+const (
+	BootloaderGrub        = "grub"
+	BootloaderSystemdBoot = "systemd-boot"
+	BootloaderSymlink     = "symlink"
+)
+
+// bootDir каталог с образами ядер и ссылками на ядро по умолчанию
+const bootDir = "/boot"
+
+// BootDefaultPlan описывает изменения, которые делают ядро загружаемым по умолчанию
+type BootDefaultPlan struct {
+	Bootloader string   `json:"bootloader"`
+	Entry      string   `json:"entry,omitempty"`
+	Commands   []string `json:"commands"`
+
+	args [][]string
+}
+
+// BootRelease возвращает имя ядра в /boot, например "6.12.10-un-def-alt1"
+func (info *Info) BootRelease() string {
+	version := info.Version
+	if clean, err := helper.GetVersionFromAptCache(version); err == nil {
+		version = clean
+	}
+	return fmt.Sprintf("%s-%s-%s", version, info.Flavour, info.Release)
+}
+
+// addCommand добавляет команду в план
+func (p *BootDefaultPlan) addCommand(args ...string) {
+	p.args = append(p.args, args)
+	p.Commands = append(p.Commands, strings.Join(args, " "))
+}
+
+// DetectBootloader определяет загрузчик системы. Если ни grub, ни systemd-boot не найдены,
+// ядро по умолчанию задаётся только ссылкой /boot/vmlinuz
+func (km *Manager) DetectBootloader(ctx context.Context) string {
+	if _, _, err := km.runner.Run(ctx, []string{"bootctl", "is-installed"}, command.WithQuiet()); err == nil {
+		return BootloaderSystemdBoot
+	}
+	if grubConfigPath() != "" {
+		return BootloaderGrub
+	}
+	return BootloaderSymlink
+}
+
+// PlanDefaultKernel формирует план смены ядра по умолчанию без изменения системы
+func (km *Manager) PlanDefaultKernel(ctx context.Context, kernel *Info) (*BootDefaultPlan, error) {
+	release := kernel.BootRelease()
+	vmlinuz := "vmlinuz-" + release
+	if _, err := os.Stat(filepath.Join(bootDir, vmlinuz)); err != nil {
+		return nil, fmt.Errorf(app.TL_(ctx, "kernel image %s not found"), filepath.Join(bootDir, vmlinuz))
+	}
+
+	plan := &BootDefaultPlan{Bootloader: km.DetectBootloader(ctx)}
+	plan.addCommand("ln", "-sfn", vmlinuz, filepath.Join(bootDir, "vmlinuz"))
+	initrd := "initrd-" + release + ".img"
+	if _, err := os.Stat(filepath.Join(bootDir, initrd)); err == nil {
+		plan.addCommand("ln", "-sfn", initrd, filepath.Join(bootDir, "initrd.img"))
+	}
+
+	switch plan.Bootloader {
+	case BootloaderSystemdBoot:
+		entry, err := km.findSystemdBootEntry(ctx, release)
+		if err != nil {
+			return nil, err
+		}
+		plan.Entry = entry
+		plan.addCommand("bootctl", "set-default", entry)
+	case BootloaderGrub:
+		tool, err := grubSetDefaultTool()
+		if err != nil {
+			return nil, err
+		}
+		entry, err := findGrubEntry(grubConfigPath(), vmlinuz)
+		if err != nil {
+			return nil, err
+		}
+		plan.Entry = entry
+		plan.addCommand(tool, entry)
+	}
+
+	return plan, nil
+}
+
+// ApplyDefaultKernel выполняет команды плана
+func (km *Manager) ApplyDefaultKernel(ctx context.Context, plan *BootDefaultPlan) error {
+	for _, args := range plan.args {
+		if _, stderr, err := km.runner.Run(ctx, args, command.WithQuiet()); err != nil {
+			return fmt.Errorf(app.TL_(ctx, "failed to run %s: %s"), strings.Join(args, " "), strings.TrimSpace(stderr+" "+err.Error()))
+		}
+	}
+	return nil
+}
+
+// findSystemdBootEntry ищет запись systemd-boot для указанного ядра
+func (km *Manager) findSystemdBootEntry(ctx context.Context, release string) (string, error) {
+	stdout, _, err := km.runner.Run(ctx, []string{"bootctl", "list", "--json=short", "--no-pager"}, command.WithQuiet())
+	if err != nil {
+		return "", fmt.Errorf(app.TL_(ctx, "failed to list boot entries: %s"), err.Error())
+	}
+
+	var entries []struct {
+		ID      string `json:"id"`
+		Version string `json:"version"`
+		Linux   string `json:"linux"`
+	}
+	if err = json.Unmarshal([]byte(stdout), &entries); err != nil {
+		return "", fmt.Errorf(app.TL_(ctx, "failed to parse boot entries: %s"), err.Error())
+	}
+
+	for _, entry := range entries {
+		if entry.Version == release || strings.HasSuffix(entry.Linux, "vmlinuz-"+release) || strings.Contains(entry.ID, release) {
+			return entry.ID, nil
+		}
+	}
+
+	return "", fmt.Errorf(app.TL_(ctx, "boot entry for kernel %s not found"), release)
+}
+
+// grubConfigPath возвращает путь к конфигурации grub или пустую строку
+func grubConfigPath() string {
+	for _, path := range []string{"grub/grub.cfg", "grub2/grub.cfg"} {
+		if _, err := os.Stat(filepath.Join(bootDir, path)); err == nil {
+			return filepath.Join(bootDir, path)
+		}
+	}
+	return ""
+}
+
+// grubSetDefaultTool ищет утилиту смены сохранённой записи grub
+func grubSetDefaultTool() (string, error) {
+	for _, tool := range []string{"grub-set-default", "grub2-set-default"} {
+		if _, err := exec.LookPath(tool); err == nil {
+			return tool, nil
+		}
+	}
+	return "", errors.New(app.T_("grub-set-default not found"))
+}
+
+// findGrubEntry ищет в grub.cfg пункт меню, загружающий указанный образ ядра.
+// Для пунктов во вложенном меню возвращается путь вида "submenu>entry"
+func findGrubEntry(configPath string, vmlinuz string) (string, error) {
+	file, err := os.Open(configPath)
+	if err != nil {
+		return "", fmt.Errorf(app.T_("failed to read grub configuration: %s"), err.Error())
+	}
+	defer file.Close()
+
+	entry, err := parseGrubEntry(file, vmlinuz)
+	if err != nil {
+		return "", err
+	}
+	if entry == "" {
+		return "", fmt.Errorf(app.T_("grub menu entry for %s not found, regenerate grub configuration"), vmlinuz)
+	}
+	return entry, nil
+}
+
+// grubBlock пункт меню или подменю grub.cfg
+type grubBlock struct {
+	id    string
+	depth int
+}
+
+// parseGrubEntry разбирает grub.cfg и возвращает путь к первому пункту меню с образом vmlinuz
+func parseGrubEntry(r io.Reader, vmlinuz string) (string, error) {
+	var stack []grubBlock
+	depth := 0
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if strings.HasPrefix(line, "menuentry ") || strings.HasPrefix(line, "submenu ") {
+			stack = append(stack, grubBlock{id: grubEntryID(line), depth: depth})
+		}
+
+		if strings.HasPrefix(line, "linux") && len(stack) > 0 {
+			fields := strings.Fields(line)
+			if len(fields) > 1 && filepath.Base(fields[1]) == vmlinuz {
+				ids := make([]string, 0, len(stack))
+				for _, block := range stack {
+					ids = append(ids, block.id)
+				}
+				return strings.Join(ids, ">"), nil
+			}
+		}
+
+		depth += strings.Count(line, "{") - strings.Count(line, "}")
+		for len(stack) > 0 && depth <= stack[len(stack)-1].depth {
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	return "", scanner.Err()
+}
+
+// grubEntryID извлекает идентификатор пункта меню ($menuentry_id_option), а при его отсутствии — заголовок
+func grubEntryID(line string) string {
+	if idx := strings.Index(line, "$menuentry_id_option"); idx != -1 {
+		if id := firstQuoted(line[idx:]); id != "" {
+			return id
+		}
+	}
+	return firstQuoted(line)
+}
+
+// firstQuoted возвращает первую строку в одинарных или двойных кавычках
+func firstQuoted(s string) string {
+	start := strings.IndexAny(s, `'"`)
+	if start == -1 {
+		return ""
+	}
+	end := strings.IndexByte(s[start+1:], s[start])
+	if end == -1 {
+		return ""
+	}
+	return s[start+1 : start+1+end]
+}
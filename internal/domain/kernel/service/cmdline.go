@@ -0,0 +1,268 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/command"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Способы хранения параметров командной строки ядра
+const (
+	CmdlineSourceOstree = "ostree"
+	CmdlineSourceGrubby = "grubby"
+	CmdlineSourceGrub   = "grub"
+)
+
+// CmdlineScopeAll параметры применяются ко всем ядрам
+const CmdlineScopeAll = "all"
+
+const (
+	// ostreeBootedPath признак загрузки из ostree-развёртывания (bootc)
+	ostreeBootedPath = "/run/ostree-booted"
+	// procCmdlinePath параметры загруженного ядра
+	procCmdlinePath = "/proc/cmdline"
+	// grubDefaultsPath файл с настройками загрузчика GRUB
+	grubDefaultsPath = "/etc/sysconfig/grub2"
+	// grubCmdlineKey переменная с параметрами командной строки ядра
+	grubCmdlineKey = "GRUB_CMDLINE_LINUX_DEFAULT"
+)
+
+var grubCmdlineRe = regexp.MustCompile(`(?m)^` + grubCmdlineKey + `=(['"]?)(.*?)(['"]?)$`)
+
+// Cmdline параметры командной строки ядра
+type Cmdline struct {
+	Source string   `json:"source"`
+	Scope  string   `json:"scope"`
+	Path   string   `json:"path,omitempty"`
+	Args   []string `json:"args"`
+}
+
+// CmdlineChange изменение параметров командной строки ядра
+type CmdlineChange struct {
+	Before  Cmdline  `json:"before"`
+	After   Cmdline  `json:"after"`
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	Diff    string   `json:"diff"`
+}
+
+// GetCmdline возвращает параметры командной строки ядра.
+// На bootc-системе это параметры текущего развёртывания, при наличии grubby — параметры указанного ядра,
+// иначе общие параметры GRUB для всех ядер
+func (km *Manager) GetCmdline(ctx context.Context, kernel *Info) (*Cmdline, error) {
+	release := kernel.BootRelease()
+
+	switch cmdlineSource() {
+	case CmdlineSourceOstree:
+		data, err := os.ReadFile(procCmdlinePath)
+		if err != nil {
+			return nil, fmt.Errorf(app.TL_(ctx, "failed to read %s: %s"), procCmdlinePath, err.Error())
+		}
+		var args []string
+		for _, arg := range strings.Fields(string(data)) {
+			if !strings.HasPrefix(arg, "BOOT_IMAGE=") {
+				args = append(args, arg)
+			}
+		}
+		return &Cmdline{Source: CmdlineSourceOstree, Scope: release, Args: args}, nil
+	case CmdlineSourceGrubby:
+		path := bootDir + "/vmlinuz-" + release
+		stdout, stderr, err := km.runner.Run(ctx, []string{"grubby", "--info=" + path}, command.WithQuiet())
+		if err != nil {
+			return nil, fmt.Errorf(app.TL_(ctx, "failed to get boot entry for %s: %s"), path, strings.TrimSpace(stderr))
+		}
+		return &Cmdline{Source: CmdlineSourceGrubby, Scope: release, Path: path, Args: parseGrubbyArgs(stdout)}, nil
+	default:
+		data, err := os.ReadFile(grubDefaultsPath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf(app.TL_(ctx, "failed to read %s: %s"), grubDefaultsPath, err.Error())
+		}
+		var args []string
+		if match := grubCmdlineRe.FindStringSubmatch(string(data)); match != nil {
+			args = strings.Fields(match[2])
+		}
+		return &Cmdline{Source: CmdlineSourceGrub, Scope: CmdlineScopeAll, Path: grubDefaultsPath, Args: args}, nil
+	}
+}
+
+// SetCmdline добавляет и удаляет параметры командной строки ядра.
+// Параметр без значения в remove удаляет и все его варианты вида "name=value"
+func (km *Manager) SetCmdline(ctx context.Context, kernel *Info, add []string, remove []string, dryRun bool) (*CmdlineChange, error) {
+	before, err := km.GetCmdline(ctx, kernel)
+	if err != nil {
+		return nil, err
+	}
+
+	after := *before
+	var added, removed []string
+	after.Args, added, removed = applyCmdlineArgs(before.Args, add, remove)
+
+	change := &CmdlineChange{
+		Before:  *before,
+		After:   after,
+		Added:   added,
+		Removed: removed,
+		Diff:    cmdlineDiff(before, &after),
+	}
+	if dryRun || (len(added) == 0 && len(removed) == 0) {
+		return change, nil
+	}
+
+	switch before.Source {
+	case CmdlineSourceOstree:
+		args := []string{"ostree", "admin", "kargs", "edit-in-place"}
+		for _, arg := range added {
+			args = append(args, "--append-if-missing="+arg)
+		}
+		for _, arg := range removed {
+			args = append(args, "--delete-if-present="+arg)
+		}
+		if _, stderr, errRun := km.runner.Run(ctx, args, command.WithQuiet()); errRun != nil {
+			return nil, fmt.Errorf(app.TL_(ctx, "failed to update kernel arguments: %s"), strings.TrimSpace(stderr))
+		}
+	case CmdlineSourceGrubby:
+		args := []string{"grubby", "--update-kernel=" + before.Path}
+		if len(added) > 0 {
+			args = append(args, "--args="+strings.Join(added, " "))
+		}
+		if len(removed) > 0 {
+			args = append(args, "--remove-args="+strings.Join(removed, " "))
+		}
+		if _, stderr, errRun := km.runner.Run(ctx, args, command.WithQuiet()); errRun != nil {
+			return nil, fmt.Errorf(app.TL_(ctx, "failed to update kernel arguments: %s"), strings.TrimSpace(stderr))
+		}
+	default:
+		if err = writeGrubCmdline(before.Path, after.Args); err != nil {
+			return nil, err
+		}
+		if _, stderr, errRun := km.runner.Run(ctx, []string{"update-grub"}, command.WithQuiet()); errRun != nil {
+			return nil, fmt.Errorf(app.TL_(ctx, "failed to update bootloader configuration: %s"), strings.TrimSpace(stderr))
+		}
+	}
+
+	return change, nil
+}
+
+// cmdlineSource определяет, где хранятся параметры командной строки ядра
+func cmdlineSource() string {
+	if _, err := os.Stat(ostreeBootedPath); err == nil {
+		return CmdlineSourceOstree
+	}
+	if _, err := exec.LookPath("grubby"); err == nil {
+		return CmdlineSourceGrubby
+	}
+	return CmdlineSourceGrub
+}
+
+// parseGrubbyArgs извлекает параметры из вывода "grubby --info"
+func parseGrubbyArgs(output string) []string {
+	for _, line := range strings.Split(output, "\n") {
+		value, ok := strings.CutPrefix(strings.TrimSpace(line), "args=")
+		if !ok {
+			continue
+		}
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+		return strings.Fields(value)
+	}
+	return nil
+}
+
+// applyCmdlineArgs применяет добавление и удаление параметров.
+// Возвращает новый список и фактически добавленные и удалённые параметры
+func applyCmdlineArgs(current []string, add []string, remove []string) (result []string, added []string, removed []string) {
+	for _, arg := range current {
+		if cmdlineArgMatches(arg, remove) {
+			removed = append(removed, arg)
+			continue
+		}
+		result = append(result, arg)
+	}
+
+	for _, arg := range add {
+		if !slices.Contains(result, arg) {
+			result = append(result, arg)
+			added = append(added, arg)
+		}
+	}
+
+	return result, added, removed
+}
+
+// cmdlineArgMatches сообщает, попадает ли параметр под один из шаблонов удаления
+func cmdlineArgMatches(arg string, patterns []string) bool {
+	name, _, _ := strings.Cut(arg, "=")
+	for _, pattern := range patterns {
+		if arg == pattern || (!strings.Contains(pattern, "=") && name == pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// cmdlineDiff формирует разницу параметров в формате unified diff
+func cmdlineDiff(before *Cmdline, after *Cmdline) string {
+	label := before.Path
+	if label == "" {
+		label = before.Source
+	}
+	oldLine := strings.Join(before.Args, " ")
+	newLine := strings.Join(after.Args, " ")
+	if before.Source == CmdlineSourceGrub {
+		oldLine = fmt.Sprintf("%s='%s'", grubCmdlineKey, oldLine)
+		newLine = fmt.Sprintf("%s='%s'", grubCmdlineKey, newLine)
+	}
+	if oldLine == newLine {
+		return ""
+	}
+	return fmt.Sprintf("--- %s\n+++ %s\n-%s\n+%s\n", label, label, oldLine, newLine)
+}
+
+// writeGrubCmdline записывает параметры в GRUB_CMDLINE_LINUX_DEFAULT, сохраняя остальные настройки
+func writeGrubCmdline(path string, args []string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf(app.T_("failed to read %s: %s"), path, err.Error())
+	}
+
+	content := string(data)
+	line := fmt.Sprintf("%s='%s'", grubCmdlineKey, strings.Join(args, " "))
+	if grubCmdlineRe.MatchString(content) {
+		content = grubCmdlineRe.ReplaceAllLiteralString(content, line)
+	} else {
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		content += line + "\n"
+	}
+
+	if err = os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf(app.T_("failed to write %s: %s"), path, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestWriteGrubCmdline(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		args    []string
+		want    string
+	}{
+		{
+			name:    "replace existing",
+			content: "GRUB_TIMEOUT=5\nGRUB_CMDLINE_LINUX_DEFAULT='quiet splash'\n",
+			args:    []string{"quiet", "splash", "nvidia-drm.modeset=1"},
+			want:    "GRUB_TIMEOUT=5\nGRUB_CMDLINE_LINUX_DEFAULT='quiet splash nvidia-drm.modeset=1'\n",
+		},
+		{
+			name:    "double quotes",
+			content: "GRUB_CMDLINE_LINUX_DEFAULT=\"quiet\"\n",
+			args:    []string{"quiet", "amdgpu.si_support=1"},
+			want:    "GRUB_CMDLINE_LINUX_DEFAULT='quiet amdgpu.si_support=1'\n",
+		},
+		{
+			name:    "missing variable",
+			content: "GRUB_TIMEOUT=5",
+			args:    []string{"nvidia-drm.modeset=1"},
+			want:    "GRUB_TIMEOUT=5\nGRUB_CMDLINE_LINUX_DEFAULT='nvidia-drm.modeset=1'\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "grub2")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := writeGrubCmdline(path, tt.args); err != nil {
+				t.Fatal(err)
+			}
+			if got, _ := os.ReadFile(path); string(got) != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestApplyCmdlineArgs(t *testing.T) {
+	result, added, removed := applyCmdlineArgs(
+		[]string{"quiet", "splash", "nouveau.modeset=0", "nvidia-drm.modeset=1"},
+		[]string{"nvidia-drm.modeset=1", "rd.driver.blacklist=nouveau"},
+		[]string{"splash", "nouveau.modeset"},
+	)
+
+	if want := []string{"quiet", "nvidia-drm.modeset=1", "rd.driver.blacklist=nouveau"}; !slices.Equal(result, want) {
+		t.Errorf("expected %v, got %v", want, result)
+	}
+	if want := []string{"rd.driver.blacklist=nouveau"}; !slices.Equal(added, want) {
+		t.Errorf("expected added %v, got %v", want, added)
+	}
+	if want := []string{"splash", "nouveau.modeset=0"}; !slices.Equal(removed, want) {
+		t.Errorf("expected removed %v, got %v", want, removed)
+	}
+}
@@ -198,20 +198,20 @@ func (km *Manager) GetCurrentKernel(ctx context.Context) (*Info, error) {
 
 	stdout, _, err := km.runner.Run(ctx, []string{"uname", "-r"}, command.WithQuiet())
 	if err != nil {
-		return nil, fmt.Errorf(app.T_("failed to get current kernel: %s"), err.Error())
+		return nil, fmt.Errorf(app.TL_(ctx, "failed to get current kernel: %s"), err.Error())
 	}
 
 	release := strings.TrimSpace(stdout)
 
 	tempKernel := parseKernelRelease(release)
 	if tempKernel == nil {
-		return nil, fmt.Errorf(app.T_("failed to parse kernel release: %s"), release)
+		return nil, fmt.Errorf(app.TL_(ctx, "failed to parse kernel release: %s"), release)
 	}
 
 	// Получаем все установленные ядра через RPM
 	installedKernels, err := km.ListInstalledKernelsFromRPM(ctx)
 	if err != nil {
-		return nil, fmt.Errorf(app.T_("failed to get installed kernels: %s"), err.Error())
+		return nil, fmt.Errorf(app.TL_(ctx, "failed to get installed kernels: %s"), err.Error())
 	}
 
 	// Ищем ядро с точной версией из uname
@@ -228,7 +228,7 @@ func (km *Manager) GetCurrentKernel(ctx context.Context) (*Info, error) {
 		}
 	}
 
-	return nil, fmt.Errorf(app.T_("running kernel %s not found in installed packages"), release)
+	return nil, fmt.Errorf(app.TL_(ctx, "running kernel %s not found in installed packages"), release)
 }
 
 // GetDefaultKernel возвращает информацию о ядре по умолчанию (/boot/vmlinuz)
@@ -266,7 +266,7 @@ func (km *Manager) ListKernels(ctx context.Context, flavour string) (kernels []*
 	// Ищем в базе данных с сортировкой по версии
 	packages, err := km.dbService.QueryHostImagePackages(ctx, filters, "version", "DESC", 0, 0)
 	if err != nil {
-		return nil, fmt.Errorf(app.T_("failed to search kernel packages in database: %s"), err.Error())
+		return nil, fmt.Errorf(app.TL_(ctx, "failed to search kernel packages in database: %s"), err.Error())
 	}
 
 	currentKernel, _ := km.GetCurrentKernel(ctx)
@@ -338,7 +338,7 @@ func (km *Manager) FindLatestKernel(ctx context.Context, flavour string) (*Info,
 	}
 
 	if len(kernels) == 0 {
-		return nil, fmt.Errorf(app.T_("no kernels found for flavour: %s"), flavour)
+		return nil, fmt.Errorf(app.TL_(ctx, "no kernels found for flavour: %s"), flavour)
 	}
 
 	return kernels[0], nil
@@ -401,8 +401,9 @@ func (km *Manager) SimulateUpgrade(kernel *Info, modules []string, includeHeader
 	return preview, nil
 }
 
-// InstallKernel устанавливает ядро с модулями
-func (km *Manager) InstallKernel(ctx context.Context, kernel *Info, modules []string, includeHeaders bool, dryRun bool) error {
+// InstallKernel устанавливает ядро с модулями и пересобирает для него внешние модули (dkms, akmods).
+// Результаты пересборки возвращаются вместе с успешной установкой
+func (km *Manager) InstallKernel(ctx context.Context, kernel *Info, modules []string, includeHeaders bool, dryRun bool) ([]ModuleRebuild, error) {
 	km.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventKernelInstall))
 	defer km.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventKernelInstall))
 
@@ -410,10 +411,14 @@ func (km *Manager) InstallKernel(ctx context.Context, kernel *Info, modules []st
 
 	if dryRun {
 		_, err := km.aptActions.SimulateInstall(installPackages)
-		return err
+		return nil, err
+	}
+
+	if err := km.aptActions.InstallPackages(installPackages, nil, false); err != nil {
+		return nil, err
 	}
 
-	return km.aptActions.InstallPackages(installPackages, nil, false)
+	return km.RebuildExternalModules(ctx, kernel), nil
 }
 
 // InstallModules устанавливает или симулирует установку пакетов модулей
@@ -454,7 +459,7 @@ func (km *Manager) DetectCurrentFlavour(ctx context.Context) (string, error) {
 	}
 
 	if current == nil {
-		return "", errors.New(app.T_("cannot detect current kernel"))
+		return "", errors.New(app.TL_(ctx, "cannot detect current kernel"))
 	}
 
 	return current.Flavour, nil
@@ -848,7 +853,7 @@ func calculatePackageAgeAndTime(version string) (int, time.Time) {
 func (km *Manager) ListInstalledKernelsFromRPM(ctx context.Context) ([]*Info, error) {
 	rpmKernels, err := km.aptActions.RpmQueryKernelPackages(ctx)
 	if err != nil {
-		return nil, fmt.Errorf(app.T_("failed to query installed kernels: %s"), err.Error())
+		return nil, fmt.Errorf(app.TL_(ctx, "failed to query installed kernels: %s"), err.Error())
 	}
 
 	var kernels []*Info
@@ -950,7 +955,7 @@ func (km *Manager) GetSimplePackageNameForModule(packageName string) string {
 func (km *Manager) GetBackupKernel(ctx context.Context) (*Info, error) {
 	stdout, _, err := km.runner.Run(ctx, []string{"last", "-a", "reboot"}, command.WithQuiet(), command.WithEnv("LC_ALL=C"))
 	if err != nil {
-		return nil, fmt.Errorf(app.T_("failed to get reboot history: %s"), err.Error())
+		return nil, fmt.Errorf(app.TL_(ctx, "failed to get reboot history: %s"), err.Error())
 	}
 
 	lines := strings.Split(stdout, "\n")
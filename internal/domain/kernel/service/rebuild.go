@@ -0,0 +1,160 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/command"
+	"apm/internal/common/reply"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Инструменты сборки внешних модулей ядра
+const (
+	RebuildToolDKMS   = "dkms"
+	RebuildToolAkmods = "akmods"
+)
+
+// ModuleRebuild результат пересборки внешнего модуля для нового ядра
+type ModuleRebuild struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Tool    string `json:"tool"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// dkmsModule модуль, зарегистрированный в dkms
+type dkmsModule struct {
+	name      string
+	version   string
+	installed bool
+}
+
+// RebuildExternalModules пересобирает модули dkms и akmods для указанного ядра.
+// Ошибка сборки одного модуля не прерывает сборку остальных и возвращается в результате
+func (km *Manager) RebuildExternalModules(ctx context.Context, kernel *Info) []ModuleRebuild {
+	release := kernel.BootRelease()
+
+	var pending []dkmsModule
+	for _, module := range km.listDKMSModules(ctx, release) {
+		if !module.installed {
+			pending = append(pending, module)
+		}
+	}
+	_, errAkmods := exec.LookPath(RebuildToolAkmods)
+	if len(pending) == 0 && errAkmods != nil {
+		return nil
+	}
+
+	km.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventKernelRebuildModules))
+	defer km.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventKernelRebuildModules))
+
+	var results []ModuleRebuild
+	for _, module := range pending {
+		result := ModuleRebuild{Name: module.name, Version: module.version, Tool: RebuildToolDKMS, Success: true}
+		_, stderr, err := km.runner.Run(ctx, []string{"dkms", "install", "-m", module.name, "-v", module.version, "-k", release}, command.WithQuiet())
+		if err != nil {
+			result.Success = false
+			result.Error = rebuildError(stderr, err)
+		}
+		results = append(results, result)
+	}
+
+	if errAkmods == nil {
+		result := ModuleRebuild{Name: RebuildToolAkmods, Tool: RebuildToolAkmods, Success: true}
+		_, stderr, err := km.runner.Run(ctx, []string{"akmods", "--kernels", release}, command.WithQuiet())
+		if err != nil {
+			result.Success = false
+			result.Error = rebuildError(stderr, err)
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// listDKMSModules возвращает модули dkms и признак их установки для release.
+// Если dkms не установлен, возвращается пустой список
+func (km *Manager) listDKMSModules(ctx context.Context, release string) []dkmsModule {
+	stdout, _, err := km.runner.Run(ctx, []string{"dkms", "status"}, command.WithQuiet())
+	if err != nil {
+		return nil
+	}
+	return parseDKMSStatus(stdout, release)
+}
+
+// parseDKMSStatus разбирает вывод "dkms status" в форматах
+// "name/version, release, arch: state" и "name, version, release, arch: state"
+func parseDKMSStatus(output string, release string) []dkmsModule {
+	var modules []dkmsModule
+	index := make(map[string]int)
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		colon := strings.LastIndex(line, ":")
+		if colon == -1 {
+			continue
+		}
+		state := strings.TrimSpace(line[colon+1:])
+		fields := strings.Split(line[:colon], ",")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+
+		var name, version, moduleRelease string
+		if nameVersion := strings.SplitN(fields[0], "/", 2); len(nameVersion) == 2 {
+			name, version = nameVersion[0], nameVersion[1]
+			if len(fields) > 1 {
+				moduleRelease = fields[1]
+			}
+		} else if len(fields) > 1 {
+			name, version = fields[0], fields[1]
+			if len(fields) > 2 {
+				moduleRelease = fields[2]
+			}
+		}
+		if name == "" || version == "" {
+			continue
+		}
+
+		key := name + "/" + version
+		i, ok := index[key]
+		if !ok {
+			i = len(modules)
+			index[key] = i
+			modules = append(modules, dkmsModule{name: name, version: version})
+		}
+		if moduleRelease == release && strings.HasPrefix(state, "installed") {
+			modules[i].installed = true
+		}
+	}
+
+	return modules
+}
+
+// rebuildError формирует текст ошибки сборки модуля
+func rebuildError(stderr string, err error) string {
+	lines := strings.Split(strings.TrimSpace(stderr), "\n")
+	if last := strings.TrimSpace(lines[len(lines)-1]); last != "" {
+		return fmt.Sprintf(app.T_("build failed: %s"), last)
+	}
+	return fmt.Sprintf(app.T_("build failed: %s"), err.Error())
+}
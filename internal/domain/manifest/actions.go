@@ -0,0 +1,453 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package manifest
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/filter"
+	"apm/internal/common/reply"
+	"apm/internal/domain/distrobox"
+	"apm/internal/domain/kernel"
+	"apm/internal/domain/kernel/service"
+	"apm/internal/domain/repository"
+	"apm/internal/domain/system"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Actions объединяет методы для выгрузки и применения манифеста системы
+type Actions struct {
+	appConfig         *app.Config
+	reporter          *reply.Reporter
+	serviceRepository repositoryService
+	serviceKernel     kernelService
+	servicePackages   packageService
+	serviceDistrobox  distroboxService
+	isAtomic          bool
+	root              bool
+}
+
+// NewActions создаёт новый экземпляр Actions. Контейнеры учитываются, только если установлен distrobox.
+func NewActions(appConfig *app.Config, reporter *reply.Reporter) *Actions {
+	cfg := appConfig.ConfigManager.GetConfig()
+
+	a := &Actions{
+		appConfig:         appConfig,
+		reporter:          reporter,
+		serviceRepository: repository.NewActions(appConfig, reporter),
+		serviceKernel:     kernel.NewActions(appConfig, reporter),
+		servicePackages:   system.NewActions(appConfig, reporter),
+		isAtomic:          cfg.IsAtomic,
+		root:              syscall.Geteuid() == 0,
+	}
+	if cfg.ExistDistrobox {
+		a.serviceDistrobox = distrobox.NewActions(appConfig, reporter)
+	}
+
+	return a
+}
+
+// Export выгружает в файл path подключённые репозитории, ядро с модулями, явно установленные пакеты
+// и контейнеры distrobox с их пакетами. Контейнеры принадлежат пользователю, поэтому от root они не выгружаются.
+func (a *Actions) Export(ctx context.Context, path string) (*ExportResponse, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify the manifest file")))
+	}
+
+	m := Manifest{Version: Version, CreatedAt: time.Now().UTC().Truncate(time.Second)}
+	var warnings []string
+
+	repos, err := a.serviceRepository.List(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, repo := range repos.Repositories {
+		if repo.Active && repo.Entry != "" {
+			m.Repositories = append(m.Repositories, repo.Entry)
+		}
+	}
+
+	if !a.isAtomic {
+		current, errKernel := a.serviceKernel.GetCurrentKernel(ctx)
+		if errKernel != nil {
+			warnings = append(warnings, fmt.Sprintf(app.TL_(ctx, "Kernel is not exported: %v"), errKernel))
+		} else {
+			m.Kernel = &Kernel{Flavour: current.Kernel.Flavour}
+			for _, module := range current.Kernel.InstalledModules {
+				m.Kernel.Modules = append(m.Kernel.Modules, module.Name)
+			}
+		}
+	}
+
+	explicit, err := a.servicePackages.ExplicitPackages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m.Packages = explicit.Packages
+
+	containers, containerWarnings := a.exportContainers(ctx)
+	m.Containers = containers
+	warnings = append(warnings, containerWarnings...)
+
+	if err = m.Save(path); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, err)
+	}
+
+	return &ExportResponse{
+		Message: fmt.Sprintf(app.TLN_(ctx, "Manifest saved to %s: %d package, %d container",
+			"Manifest saved to %s: %d packages, %d containers", len(m.Packages)), path, len(m.Packages), len(m.Containers)),
+		Path:     path,
+		Manifest: m,
+		Warnings: warnings,
+	}, nil
+}
+
+// exportContainers возвращает контейнеры distrobox с установленными и экспортированными пакетами.
+// Контейнер, пакеты которого не удалось получить, пропускается с предупреждением.
+func (a *Actions) exportContainers(ctx context.Context) ([]Container, []string) {
+	if a.serviceDistrobox == nil {
+		return nil, nil
+	}
+	if a.root {
+		return nil, []string{app.TL_(ctx, "Distrobox containers are not exported when running as root")}
+	}
+
+	list, err := a.serviceDistrobox.ContainerList(ctx, false)
+	if isErrorType(err, apmerr.ErrorTypeNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, []string{fmt.Sprintf(app.TL_(ctx, "Containers are not exported: %v"), err)}
+	}
+
+	var containers []Container
+	var warnings []string
+	for _, info := range list.Containers {
+		packages, errList := a.serviceDistrobox.List(ctx, distrobox.ListParams{
+			Container: info.ContainerName,
+			Filters:   []filter.Filter{{Field: "installed", Op: filter.OpEq, Value: "true"}},
+		})
+		if errList != nil {
+			warnings = append(warnings, fmt.Sprintf(app.TL_(ctx, "Container %s is not exported: %v"), info.ContainerName, errList))
+			continue
+		}
+
+		c := Container{Name: info.ContainerName, Image: info.Image}
+		for _, pkg := range packages.Packages {
+			c.Packages = append(c.Packages, pkg.Name)
+			if pkg.Exporting {
+				c.Export = append(c.Export, pkg.Name)
+			}
+		}
+		sort.Strings(c.Packages)
+		sort.Strings(c.Export)
+		containers = append(containers, c)
+	}
+
+	return containers, warnings
+}
+
+// Apply приводит систему к манифесту из файла path: подключает репозитории, ставит ядро с модулями,
+// пакеты и создаёт контейнеры distrobox с их пакетами. Системные разделы применяются от root,
+// контейнеры - от имени пользователя, поэтому полное применение требует двух запусков.
+// При dryRun изменения только планируются.
+func (a *Actions) Apply(ctx context.Context, path string, confirm bool, dryRun bool) (*ApplyResponse, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify the manifest file")))
+	}
+
+	m, err := Load(path)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, err)
+	}
+
+	resp := &ApplyResponse{Path: path}
+
+	step, err := a.applyRepositories(ctx, m.Repositories, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	resp.Steps = append(resp.Steps, step)
+
+	step, err = a.applyKernel(ctx, m.Kernel, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	resp.Steps = append(resp.Steps, step)
+
+	step, err = a.applyPackages(ctx, m.Packages, confirm, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	resp.Steps = append(resp.Steps, step)
+
+	steps, warnings := a.applyContainers(ctx, m.Containers, dryRun)
+	resp.Steps = append(resp.Steps, steps...)
+	resp.Warnings = warnings
+
+	if dryRun {
+		resp.Message = fmt.Sprintf(app.TL_(ctx, "Manifest %s application plan"), path)
+	} else {
+		resp.Message = fmt.Sprintf(app.TL_(ctx, "Manifest %s applied"), path)
+	}
+
+	return resp, nil
+}
+
+// applyRepositories подключает репозитории манифеста, которых ещё нет в системе
+func (a *Actions) applyRepositories(ctx context.Context, entries []string, dryRun bool) (reply.Step, error) {
+	step := reply.Step{Name: "repositories"}
+	if len(entries) == 0 {
+		return skipped(step, app.TL_(ctx, "Manifest has no repositories")), nil
+	}
+	if !a.root {
+		return skipped(step, app.TL_(ctx, "Requires root rights, run apm apply as root")), nil
+	}
+
+	current, err := a.serviceRepository.List(ctx, false)
+	if err != nil {
+		return step, err
+	}
+	active := make(map[string]bool, len(current.Repositories))
+	for _, repo := range current.Repositories {
+		if repo.Active {
+			active[repo.Entry] = true
+		}
+	}
+
+	added := 0
+	for _, entry := range entries {
+		if active[entry] {
+			continue
+		}
+		if !dryRun {
+			_, err = a.serviceRepository.Add(ctx, []string{entry}, "")
+			if isErrorType(err, apmerr.ErrorTypeNoOperation) {
+				continue
+			}
+			if err != nil {
+				return step, err
+			}
+		}
+		added++
+	}
+
+	if added == 0 {
+		return skipped(step, app.TL_(ctx, "Repositories are already configured")), nil
+	}
+
+	step.Status = reply.StepStatus(dryRun)
+	step.Message = fmt.Sprintf(app.TLN_(ctx, "%d repository added", "%d repositories added", added), added)
+	return step, nil
+}
+
+// applyKernel ставит flavour ядра из манифеста или недостающие модули текущего ядра
+func (a *Actions) applyKernel(ctx context.Context, k *Kernel, dryRun bool) (reply.Step, error) {
+	step := reply.Step{Name: "kernel"}
+	if k == nil || k.Flavour == "" {
+		return skipped(step, app.TL_(ctx, "Manifest has no kernel")), nil
+	}
+	if a.isAtomic {
+		return skipped(step, app.TL_(ctx, "Kernel is provided by the image")), nil
+	}
+	if !a.root {
+		return skipped(step, app.TL_(ctx, "Requires root rights, run apm apply as root")), nil
+	}
+
+	current, err := a.serviceKernel.GetCurrentKernel(ctx)
+	if err != nil {
+		return step, err
+	}
+
+	if current.Kernel.Flavour != k.Flavour {
+		_, err = a.serviceKernel.InstallKernel(ctx, k.Flavour, k.Modules, false, dryRun)
+		if err != nil && !isErrorType(err, apmerr.ErrorTypeNoOperation) {
+			return step, err
+		}
+		step.Status = reply.StepStatus(dryRun)
+		step.Message = fmt.Sprintf(app.TL_(ctx, "Kernel %s installed"), k.Flavour)
+		return step, nil
+	}
+
+	var missing []string
+	for _, module := range k.Modules {
+		if !slices.ContainsFunc(current.Kernel.InstalledModules, func(m service.InstalledModuleInfo) bool { return m.Name == module }) {
+			missing = append(missing, module)
+		}
+	}
+	if len(missing) == 0 {
+		return skipped(step, app.TL_(ctx, "Kernel and modules are already installed")), nil
+	}
+
+	_, err = a.serviceKernel.InstallKernelModules(ctx, k.Flavour, missing, dryRun)
+	if isErrorType(err, apmerr.ErrorTypeNoOperation) {
+		return skipped(step, app.TL_(ctx, "Kernel and modules are already installed")), nil
+	}
+	if err != nil {
+		return step, err
+	}
+
+	step.Status = reply.StepStatus(dryRun)
+	step.Message = fmt.Sprintf(app.TL_(ctx, "Kernel modules: %s"), strings.Join(missing, ", "))
+	return step, nil
+}
+
+// applyPackages ставит пакеты манифеста, уже установленные пакеты apt пропускает сам
+func (a *Actions) applyPackages(ctx context.Context, packages []string, confirm bool, dryRun bool) (reply.Step, error) {
+	step := reply.Step{Name: "packages"}
+	if len(packages) == 0 {
+		return skipped(step, app.TL_(ctx, "Manifest has no packages")), nil
+	}
+	if !a.root {
+		return skipped(step, app.TL_(ctx, "Requires root rights, run apm apply as root")), nil
+	}
+
+	var err error
+	if dryRun {
+		_, err = a.servicePackages.CheckInstall(ctx, packages)
+	} else {
+		_, err = a.servicePackages.Install(ctx, packages, confirm, false)
+	}
+	if isErrorType(err, apmerr.ErrorTypeNoOperation) {
+		return skipped(step, app.TL_(ctx, "Packages are already installed")), nil
+	}
+	if err != nil {
+		return step, err
+	}
+
+	step.Status = reply.StepStatus(dryRun)
+	step.Message = fmt.Sprintf(app.TLN_(ctx, "%d package from the manifest", "%d packages from the manifest", len(packages)), len(packages))
+	return step, nil
+}
+
+// applyContainers создаёт недостающие контейнеры и ставит в них пакеты из манифеста.
+// Ошибка одного контейнера не прерывает остальные и попадает в предупреждения.
+func (a *Actions) applyContainers(ctx context.Context, containers []Container, dryRun bool) ([]reply.Step, []string) {
+	step := reply.Step{Name: "containers"}
+	switch {
+	case len(containers) == 0:
+		return []reply.Step{skipped(step, app.TL_(ctx, "Manifest has no containers"))}, nil
+	case a.serviceDistrobox == nil:
+		return []reply.Step{skipped(step, app.TL_(ctx, "Distrobox is not installed"))}, nil
+	case a.root:
+		return []reply.Step{skipped(step, app.TL_(ctx, "Containers belong to the user, run apm apply without root rights"))}, nil
+	}
+
+	existing := make(map[string]bool)
+	list, err := a.serviceDistrobox.ContainerList(ctx, false)
+	if err != nil && !isErrorType(err, apmerr.ErrorTypeNotFound) {
+		return []reply.Step{skipped(step, err.Error())}, []string{fmt.Sprintf(app.TL_(ctx, "Containers are not applied: %v"), err)}
+	}
+	if list != nil {
+		for _, info := range list.Containers {
+			existing[info.ContainerName] = true
+		}
+	}
+
+	var steps []reply.Step
+	var warnings []string
+	for _, c := range containers {
+		s, w := a.applyContainer(ctx, c, existing[c.Name], dryRun)
+		steps = append(steps, s)
+		warnings = append(warnings, w...)
+	}
+	return steps, warnings
+}
+
+// applyContainer создаёт контейнер, если его нет, и ставит недостающие пакеты
+func (a *Actions) applyContainer(ctx context.Context, c Container, exists bool, dryRun bool) (reply.Step, []string) {
+	step := reply.Step{Name: "container " + c.Name}
+
+	if !exists {
+		if dryRun {
+			step.Status = reply.StepPlanned
+			step.Message = fmt.Sprintf(app.TLN_(ctx, "Container will be created from %s with %d package",
+				"Container will be created from %s with %d packages", len(c.Packages)), c.Image, len(c.Packages))
+			return step, nil
+		}
+		if _, err := a.serviceDistrobox.ContainerAdd(ctx, c.Image, c.Name, "", ""); err != nil {
+			step.Status = reply.StepFailed
+			step.Message = err.Error()
+			return step, []string{fmt.Sprintf(app.TL_(ctx, "Failed to create container %s: %v"), c.Name, err)}
+		}
+	}
+
+	installed := make(map[string]bool)
+	resp, err := a.serviceDistrobox.List(ctx, distrobox.ListParams{
+		Container: c.Name,
+		Filters:   []filter.Filter{{Field: "installed", Op: filter.OpEq, Value: "true"}},
+	})
+	if err != nil {
+		step.Status = reply.StepFailed
+		step.Message = err.Error()
+		return step, []string{fmt.Sprintf(app.TL_(ctx, "Failed to get packages of container %s: %v"), c.Name, err)}
+	}
+	for _, pkg := range resp.Packages {
+		installed[pkg.Name] = true
+	}
+
+	var missing []string
+	for _, pkg := range c.Packages {
+		if !installed[pkg] {
+			missing = append(missing, pkg)
+		}
+	}
+	if len(missing) == 0 {
+		return skipped(step, app.TL_(ctx, "Container packages are already installed")), nil
+	}
+	if dryRun {
+		step.Status = reply.StepPlanned
+		step.Message = fmt.Sprintf(app.TLN_(ctx, "%d package will be installed", "%d packages will be installed", len(missing)), len(missing))
+		return step, nil
+	}
+
+	var warnings []string
+	done := 0
+	for _, pkg := range missing {
+		if _, err = a.serviceDistrobox.Install(ctx, c.Name, pkg, distrobox.InstallOptions{Export: slices.Contains(c.Export, pkg)}); err != nil {
+			warnings = append(warnings, fmt.Sprintf(app.TL_(ctx, "Failed to install %s in container %s: %v"), pkg, c.Name, err))
+			continue
+		}
+		done++
+	}
+
+	step.Status = reply.StepDone
+	step.Message = fmt.Sprintf(app.TLN_(ctx, "%d of %d package installed", "%d of %d packages installed", len(missing)), done, len(missing))
+	return step, warnings
+}
+
+// skipped помечает шаг пропущенным с пояснением
+func skipped(step reply.Step, message string) reply.Step {
+	step.Status = reply.StepSkipped
+	step.Message = message
+	return step
+}
+
+// isErrorType проверяет, что ошибка относится к типу errorType
+func isErrorType(err error, errorType string) bool {
+	var apmErr apmerr.APMError
+	return errors.As(err, &apmErr) && apmErr.Type == errorType
+}
@@ -0,0 +1,269 @@
+package manifest
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/reply"
+	"apm/internal/common/sandbox"
+	"apm/internal/domain/distrobox"
+	"apm/internal/domain/kernel"
+	kernelSvc "apm/internal/domain/kernel/service"
+	"apm/internal/domain/repository"
+	repoSvc "apm/internal/domain/repository/service"
+	"apm/internal/domain/system"
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+type mockRepository struct {
+	repos []repoSvc.Repository
+	added []string
+}
+
+func (m *mockRepository) List(_ context.Context, _ bool) (*repository.RepoListResponse, error) {
+	return &repository.RepoListResponse{Repositories: m.repos}, nil
+}
+
+func (m *mockRepository) Add(_ context.Context, args []string, _ string) (*repository.RepoAddRemoveResponse, error) {
+	m.added = append(m.added, args...)
+	return &repository.RepoAddRemoveResponse{}, nil
+}
+
+type mockKernel struct {
+	current          kernelSvc.FullKernelInfo
+	installedFlavour string
+	installedModules []string
+}
+
+func (m *mockKernel) GetCurrentKernel(_ context.Context) (*kernel.GetCurrentKernelResponse, error) {
+	return &kernel.GetCurrentKernelResponse{Kernel: m.current}, nil
+}
+
+func (m *mockKernel) InstallKernel(_ context.Context, flavour string, _ []string, _ bool, _ bool) (*kernel.InstallUpdateKernelResponse, error) {
+	m.installedFlavour = flavour
+	return &kernel.InstallUpdateKernelResponse{}, nil
+}
+
+func (m *mockKernel) InstallKernelModules(_ context.Context, _ string, modules []string, _ bool) (*kernel.InstallKernelModulesResponse, error) {
+	m.installedModules = modules
+	return &kernel.InstallKernelModulesResponse{}, nil
+}
+
+type mockPackages struct {
+	explicit   []string
+	installErr error
+	installed  []string
+	checked    []string
+}
+
+func (m *mockPackages) ExplicitPackages(_ context.Context) (*system.ExplicitPackagesResponse, error) {
+	return &system.ExplicitPackagesResponse{Packages: m.explicit, Count: len(m.explicit)}, nil
+}
+
+func (m *mockPackages) CheckInstall(_ context.Context, packages []string) (*system.CheckResponse, error) {
+	m.checked = packages
+	return &system.CheckResponse{}, m.installErr
+}
+
+func (m *mockPackages) Install(_ context.Context, packages []string, _ bool, _ bool) (*system.InstallRemoveResponse, error) {
+	m.installed = packages
+	return &system.InstallRemoveResponse{}, m.installErr
+}
+
+type mockDistrobox struct {
+	containers []sandbox.ContainerInfo
+	packages   map[string][]sandbox.PackageInfo
+	created    []string
+	installed  []string
+	exported   []string
+}
+
+func (m *mockDistrobox) ContainerList(_ context.Context, _ bool) (*distrobox.ContainerListResponse, error) {
+	if len(m.containers) == 0 {
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, errors.New("no containers"))
+	}
+	return &distrobox.ContainerListResponse{Containers: m.containers}, nil
+}
+
+func (m *mockDistrobox) List(_ context.Context, params distrobox.ListParams) (*distrobox.ListResponse, error) {
+	return &distrobox.ListResponse{Packages: m.packages[params.Container]}, nil
+}
+
+func (m *mockDistrobox) ContainerAdd(_ context.Context, image string, name string, _, _ string) (*distrobox.ContainerAddResponse, error) {
+	m.created = append(m.created, name+"@"+image)
+	return &distrobox.ContainerAddResponse{}, nil
+}
+
+func (m *mockDistrobox) Install(_ context.Context, container string, packageName string, opts distrobox.InstallOptions) (*distrobox.InstallResponse, error) {
+	m.installed = append(m.installed, container+"/"+packageName)
+	if opts.Export {
+		m.exported = append(m.exported, packageName)
+	}
+	return &distrobox.InstallResponse{}, nil
+}
+
+func stepsByName(resp *ApplyResponse) map[string]reply.Step {
+	steps := make(map[string]reply.Step)
+	for _, step := range resp.Steps {
+		steps[step.Name] = step
+	}
+	return steps
+}
+
+func TestExportAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.yaml")
+	actions := &Actions{
+		appConfig: &app.Config{},
+		serviceRepository: &mockRepository{repos: []repoSvc.Repository{
+			{Entry: "rpm [p11] http://ftp.altlinux.org/pub/distributions/ALTLinux p11/branch/x86_64 classic", Active: true},
+			{Entry: "rpm http://example.org/disabled x86_64 classic", Active: false},
+		}},
+		serviceKernel: &mockKernel{current: kernelSvc.FullKernelInfo{
+			Flavour:          "6.12",
+			InstalledModules: []kernelSvc.InstalledModuleInfo{{Name: "v4l2loopback"}},
+		}},
+		servicePackages: &mockPackages{explicit: []string{"mc", "vim"}},
+		serviceDistrobox: &mockDistrobox{
+			containers: []sandbox.ContainerInfo{{ContainerName: "arch", Image: "archlinux:latest"}},
+			packages: map[string][]sandbox.PackageInfo{"arch": {
+				{Name: "yay", Installed: true, Exporting: true},
+				{Name: "git", Installed: true},
+			}},
+		},
+	}
+
+	resp, err := actions.Export(context.Background(), path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", resp.Warnings)
+	}
+
+	m, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load exported manifest: %v", err)
+	}
+	if len(m.Repositories) != 1 || m.Kernel == nil || m.Kernel.Flavour != "6.12" {
+		t.Errorf("unexpected manifest: %+v", m)
+	}
+	if !slices.Equal(m.Packages, []string{"mc", "vim"}) {
+		t.Errorf("unexpected packages: %v", m.Packages)
+	}
+	if len(m.Containers) != 1 || !slices.Equal(m.Containers[0].Packages, []string{"git", "yay"}) ||
+		!slices.Equal(m.Containers[0].Export, []string{"yay"}) {
+		t.Errorf("unexpected containers: %+v", m.Containers)
+	}
+}
+
+func TestApplyAsRoot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.yaml")
+	m := Manifest{
+		Version:      Version,
+		Repositories: []string{"rpm http://example.org/present x86_64 classic", "rpm http://example.org/new x86_64 classic"},
+		Kernel:       &Kernel{Flavour: "6.12", Modules: []string{"v4l2loopback", "zfs"}},
+		Packages:     []string{"mc", "vim"},
+		Containers:   []Container{{Name: "arch", Image: "archlinux:latest", Packages: []string{"git"}}},
+	}
+	if err := m.Save(path); err != nil {
+		t.Fatalf("failed to save manifest: %v", err)
+	}
+
+	r := &mockRepository{repos: []repoSvc.Repository{{Entry: "rpm http://example.org/present x86_64 classic", Active: true}}}
+	k := &mockKernel{current: kernelSvc.FullKernelInfo{
+		Flavour:          "6.12",
+		InstalledModules: []kernelSvc.InstalledModuleInfo{{Name: "v4l2loopback"}},
+	}}
+	p := &mockPackages{}
+	d := &mockDistrobox{}
+	actions := &Actions{appConfig: &app.Config{}, serviceRepository: r, serviceKernel: k, servicePackages: p, serviceDistrobox: d, root: true}
+
+	resp, err := actions.Apply(context.Background(), path, true, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(r.added, []string{"rpm http://example.org/new x86_64 classic"}) {
+		t.Errorf("unexpected repositories added: %v", r.added)
+	}
+	if k.installedFlavour != "" || !slices.Equal(k.installedModules, []string{"zfs"}) {
+		t.Errorf("expected only zfs module to be installed, got flavour %q modules %v", k.installedFlavour, k.installedModules)
+	}
+	if !slices.Equal(p.installed, []string{"mc", "vim"}) {
+		t.Errorf("unexpected packages installed: %v", p.installed)
+	}
+	if len(d.created) != 0 {
+		t.Errorf("expected no containers to be created as root, got %v", d.created)
+	}
+
+	steps := stepsByName(resp)
+	for _, name := range []string{"repositories", "kernel", "packages"} {
+		if steps[name].Status != reply.StepDone {
+			t.Errorf("expected step %s to be done, got %q", name, steps[name].Status)
+		}
+	}
+	if steps["containers"].Status != reply.StepSkipped {
+		t.Errorf("expected containers to be skipped as root, got %q", steps["containers"].Status)
+	}
+}
+
+func TestApplyAsUser(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.yaml")
+	m := Manifest{
+		Version:  Version,
+		Packages: []string{"mc"},
+		Containers: []Container{
+			{Name: "arch", Image: "archlinux:latest", Packages: []string{"git", "yay"}, Export: []string{"yay"}},
+			{Name: "alt", Image: "alt:sisyphus", Packages: []string{"htop"}},
+		},
+	}
+	if err := m.Save(path); err != nil {
+		t.Fatalf("failed to save manifest: %v", err)
+	}
+
+	p := &mockPackages{}
+	d := &mockDistrobox{
+		containers: []sandbox.ContainerInfo{{ContainerName: "alt", Image: "alt:sisyphus"}},
+		packages:   map[string][]sandbox.PackageInfo{"alt": {{Name: "htop", Installed: true}}},
+	}
+	actions := &Actions{appConfig: &app.Config{}, serviceRepository: &mockRepository{}, serviceKernel: &mockKernel{},
+		servicePackages: p, serviceDistrobox: d}
+
+	resp, err := actions.Apply(context.Background(), path, false, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.installed != nil {
+		t.Errorf("expected system packages to be skipped without root, got %v", p.installed)
+	}
+	if !slices.Equal(d.created, []string{"arch@archlinux:latest"}) {
+		t.Errorf("unexpected containers created: %v", d.created)
+	}
+	if !slices.Equal(d.installed, []string{"arch/git", "arch/yay"}) || !slices.Equal(d.exported, []string{"yay"}) {
+		t.Errorf("unexpected container packages: installed %v, exported %v", d.installed, d.exported)
+	}
+
+	steps := stepsByName(resp)
+	if steps["packages"].Status != reply.StepSkipped {
+		t.Errorf("expected packages to be skipped without root, got %q", steps["packages"].Status)
+	}
+	if steps["container arch"].Status != reply.StepDone || steps["container alt"].Status != reply.StepSkipped {
+		t.Errorf("unexpected container steps: %+v", resp.Steps)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.yaml")
+	m := Manifest{Version: Version + 1}
+	if err := m.Save(path); err != nil {
+		t.Fatalf("failed to save manifest: %v", err)
+	}
+
+	_, err := (&Actions{appConfig: &app.Config{}}).Apply(context.Background(), path, false, true)
+	var apmErr apmerr.APMError
+	if !errors.As(err, &apmErr) || apmErr.Type != apmerr.ErrorTypeValidation {
+		t.Errorf("expected validation error for unsupported version, got %v", err)
+	}
+}
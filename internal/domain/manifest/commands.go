@@ -0,0 +1,82 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package manifest
+
+import (
+	"apm/internal/common/app"
+	apmcli "apm/internal/common/cli"
+	"apm/internal/common/reply"
+	"context"
+
+	"github.com/urfave/cli/v3"
+)
+
+// newErrorResponseFromError создаёт ответ с ошибкой, извлекая тип из apmerr.APMError.
+func newErrorResponseFromError(err error) reply.APIResponse {
+	app.Log.Error(err.Error())
+	return reply.ErrorResponseFromError(err)
+}
+
+// ExportCommand команда выгрузки манифеста системы
+func ExportCommand(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
+	withGlobalWrapper := apmcli.WithOptions(appConfig, reporter, apmcli.NoRootCheck, NewActions, newErrorResponseFromError)
+
+	return &cli.Command{
+		Name:      "export",
+		Usage:     app.T_("Export repositories, kernel, installed packages and containers to a manifest"),
+		ArgsUsage: "manifest.yaml",
+		Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+			resp, err := actions.Export(ctx, cmd.Args().First())
+			if err != nil {
+				return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+			}
+			return reporter.CliResponse(ctx, reply.OK(resp))
+		}),
+	}
+}
+
+// ApplyCommand команда применения манифеста системы
+func ApplyCommand(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
+	withGlobalWrapper := apmcli.WithOptions(appConfig, reporter, apmcli.NoRootCheck, NewActions, newErrorResponseFromError)
+
+	return &cli.Command{
+		Name:      "apply",
+		Usage:     app.T_("Apply a manifest: system sections as root, containers as a regular user"),
+		ArgsUsage: "manifest.yaml",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "yes",
+				Usage:   app.T_("Automatic confirmation"),
+				Aliases: []string{"y"},
+				Value:   false,
+			},
+			&cli.BoolFlag{
+				Name:    "simulate",
+				Usage:   app.T_("Show the plan without making changes"),
+				Aliases: []string{"s"},
+				Value:   false,
+			},
+		},
+		Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+			resp, err := actions.Apply(ctx, cmd.Args().First(), cmd.Bool("yes"), cmd.Bool("simulate"))
+			if err != nil {
+				return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+			}
+			return reporter.CliResponse(ctx, reply.OK(resp))
+		}),
+	}
+}
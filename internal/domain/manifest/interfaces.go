@@ -0,0 +1,53 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package manifest
+
+import (
+	"apm/internal/domain/distrobox"
+	"apm/internal/domain/kernel"
+	"apm/internal/domain/repository"
+	"apm/internal/domain/system"
+	"context"
+)
+
+// repositoryService определяет методы модуля repo для чтения и подключения репозиториев.
+type repositoryService interface {
+	List(ctx context.Context, all bool) (*repository.RepoListResponse, error)
+	Add(ctx context.Context, args []string, date string) (*repository.RepoAddRemoveResponse, error)
+}
+
+// kernelService определяет методы модуля kernel для работы с ядром и его модулями.
+type kernelService interface {
+	GetCurrentKernel(ctx context.Context) (*kernel.GetCurrentKernelResponse, error)
+	InstallKernel(ctx context.Context, flavour string, modules []string, includeHeaders bool, dryRun bool) (*kernel.InstallUpdateKernelResponse, error)
+	InstallKernelModules(ctx context.Context, flavour string, modules []string, dryRun bool) (*kernel.InstallKernelModulesResponse, error)
+}
+
+// packageService определяет методы модуля system для работы с пакетами.
+type packageService interface {
+	ExplicitPackages(ctx context.Context) (*system.ExplicitPackagesResponse, error)
+	CheckInstall(ctx context.Context, packages []string) (*system.CheckResponse, error)
+	Install(ctx context.Context, packages []string, confirm bool, downloadOnly bool) (*system.InstallRemoveResponse, error)
+}
+
+// distroboxService определяет методы модуля distrobox для работы с контейнерами.
+type distroboxService interface {
+	ContainerList(ctx context.Context, refresh bool) (*distrobox.ContainerListResponse, error)
+	List(ctx context.Context, params distrobox.ListParams) (*distrobox.ListResponse, error)
+	ContainerAdd(ctx context.Context, image string, name string, additionalPackages, initHooks string) (*distrobox.ContainerAddResponse, error)
+	Install(ctx context.Context, container string, packageName string, opts distrobox.InstallOptions) (*distrobox.InstallResponse, error)
+}
@@ -0,0 +1,90 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package manifest
+
+import (
+	"apm/internal/common/app"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// Version текущая версия формата манифеста
+const Version = 1
+
+// Manifest описывает установленную систему: репозитории, ядро, пакеты и контейнеры distrobox
+type Manifest struct {
+	Version      int         `yaml:"version" json:"version"`
+	CreatedAt    time.Time   `yaml:"createdAt" json:"createdAt"`
+	Repositories []string    `yaml:"repositories,omitempty" json:"repositories"`
+	Kernel       *Kernel     `yaml:"kernel,omitempty" json:"kernel,omitempty"`
+	Packages     []string    `yaml:"packages,omitempty" json:"packages"`
+	Containers   []Container `yaml:"containers,omitempty" json:"containers"`
+}
+
+// Kernel flavour ядра и установленные модули
+type Kernel struct {
+	Flavour string   `yaml:"flavour" json:"flavour"`
+	Modules []string `yaml:"modules,omitempty" json:"modules"`
+}
+
+// Container контейнер distrobox и установленные в нём пакеты. Export - пакеты, экспортированные на хост.
+type Container struct {
+	Name     string   `yaml:"name" json:"name"`
+	Image    string   `yaml:"image" json:"image"`
+	Packages []string `yaml:"packages,omitempty" json:"packages"`
+	Export   []string `yaml:"export,omitempty" json:"export"`
+}
+
+// Load читает манифест из файла path.
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(app.T_("Failed to read manifest %s: %v"), path, err)
+	}
+
+	var m Manifest
+	if err = yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf(app.T_("Failed to parse manifest %s: %v"), path, err)
+	}
+	if m.Version != Version {
+		return nil, fmt.Errorf(app.T_("Unsupported manifest version %d, expected %d"), m.Version, Version)
+	}
+
+	for _, c := range m.Containers {
+		if c.Name == "" || c.Image == "" {
+			return nil, errors.New(app.T_("Manifest container must have a name and an image"))
+		}
+	}
+
+	return &m, nil
+}
+
+// Save записывает манифест в файл path.
+func (m *Manifest) Save(path string) error {
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf(app.T_("Failed to write manifest %s: %v"), path, err)
+	}
+	return nil
+}
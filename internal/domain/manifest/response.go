@@ -0,0 +1,35 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package manifest
+
+import "apm/internal/common/reply"
+
+// ExportResponse структура ответа для Export метода
+type ExportResponse struct {
+	Message  string   `json:"message"`
+	Path     string   `json:"path"`
+	Manifest Manifest `json:"manifest"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ApplyResponse структура ответа для Apply метода
+type ApplyResponse struct {
+	Message  string       `json:"message"`
+	Path     string       `json:"path"`
+	Steps    []reply.Step `json:"steps"`
+	Warnings []string     `json:"warnings,omitempty"`
+}
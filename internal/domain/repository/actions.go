@@ -20,8 +20,11 @@ import (
 	"apm/internal/common/apmerr"
 	"apm/internal/common/app"
 	_package "apm/internal/common/apt/package"
+	aptLib "apm/internal/common/binding/apt/lib"
 	"apm/internal/common/build"
 	"apm/internal/common/command"
+	"apm/internal/common/journal"
+	"apm/internal/common/oplock"
 	"apm/internal/common/reply"
 	"apm/internal/domain/repository/service"
 	"context"
@@ -32,9 +35,10 @@ import (
 
 // ShortRepoResponse Сокращённое представление репозитория
 type ShortRepoResponse struct {
-	Branch string `json:"branch"`
-	URL    string `json:"url"`
-	Arch   string `json:"arch"`
+	Branch string            `json:"branch"`
+	URL    string            `json:"url"`
+	Arch   string            `json:"arch"`
+	Task   *service.TaskInfo `json:"task,omitempty"`
 }
 
 // FormatRepoOutput принимает данные (один репозиторий или срез) и флаг full.
@@ -49,6 +53,7 @@ func FormatRepoOutput(data interface{}, full bool) interface{} {
 			Branch: v.Branch,
 			URL:    v.URL,
 			Arch:   v.Arch,
+			Task:   v.Task,
 		}
 	case []service.Repository:
 		if full {
@@ -60,6 +65,7 @@ func FormatRepoOutput(data interface{}, full bool) interface{} {
 				Branch: repo.Branch,
 				URL:    repo.URL,
 				Arch:   repo.Arch,
+				Task:   repo.Task,
 			})
 		}
 		return shortList
@@ -68,13 +74,27 @@ func FormatRepoOutput(data interface{}, full bool) interface{} {
 	}
 }
 
+// withoutTaskInfo возвращает копию списка без метаданных задач.
+func withoutTaskInfo(repos []service.Repository) []service.Repository {
+	result := make([]service.Repository, len(repos))
+	for i, repo := range repos {
+		repo.Task = nil
+		result[i] = repo
+	}
+	return result
+}
+
 // Actions объединяет методы для работы с репозиториями.
 type Actions struct {
 	appConfig         *app.Config
 	reporter          *reply.Reporter
 	repoService       repoService
+	serviceKeys       keyService
 	serviceAptActions aptActionsService
 	serviceHostImage  overlayService
+	serviceJournal    journalService
+	stateNotifier     app.StateNotifier
+	operationLock     *oplock.Lock
 }
 
 // NewActions создаёт новый экземпляр Actions.
@@ -94,24 +114,60 @@ func NewActions(appConfig *app.Config, reporter *reply.Reporter) *Actions {
 	return &Actions{
 		appConfig:         appConfig,
 		reporter:          reporter,
-		repoService:       service.NewRepoService(packageDBSvc, runner),
+		repoService:       service.NewRepoService(packageDBSvc, runner, reporter),
+		serviceKeys:       service.NewKeyService(runner),
 		serviceAptActions: aptActions,
 		serviceHostImage:  hostImageSvc,
+		serviceJournal:    journal.NewService(appConfig.DatabaseManager),
+		stateNotifier:     app.DBusStateNotifier(appConfig.DBusManager),
+		operationLock:     oplock.Shared(),
+	}
+}
+
+// recordOperation записывает результат операции в историю. Ошибка записи не прерывает операцию.
+// Об успешном изменении репозиториев отправляется сигнал RepoChanged.
+func (a *Actions) recordOperation(ctx context.Context, action string, targets []string, opErr error) {
+	if opErr == nil {
+		a.stateNotifier.Emit(app.RepoChangedSignal, action, targets)
+	}
+	if a.serviceJournal == nil {
+		return
+	}
+
+	entry := journal.Entry{Module: journal.ModuleRepository, Action: action, Targets: targets}
+	if err := a.serviceJournal.RecordResult(context.WithoutCancel(ctx), entry, opErr); err != nil {
+		app.Log.Warning(fmt.Sprintf("failed to record transaction journal: %v", err))
+	}
+}
+
+// backupSources сохраняет снимок источников перед их изменением. Ошибка не прерывает операцию.
+func (a *Actions) backupSources() {
+	if _, err := a.repoService.BackupSources(); err != nil {
+		app.Log.Warning(fmt.Sprintf("failed to back up repository sources: %v", err))
+	}
+}
+
+// repoTargets возвращает строки источников для записи в историю
+func repoTargets(repos []service.Repository) []string {
+	targets := make([]string, 0, len(repos))
+	for _, repo := range repos {
+		targets = append(targets, repo.Entry)
 	}
+	return targets
 }
 
 // List возвращает список репозиториев
 func (a *Actions) List(ctx context.Context, all bool) (*RepoListResponse, error) {
 	repos, err := a.repoService.GetRepositories(ctx, all)
 	if err != nil {
-		return nil, apmerr.New(apmerr.ErrorTypeRepository, err)
+		return nil, newRepoError(err)
 	}
 
 	var message string
 	if all {
-		message = fmt.Sprintf(app.TN_("%d repository found (including inactive)", "%d repositories found (including inactive)", len(repos)), len(repos))
+		message = fmt.Sprintf(app.TLN_(ctx, "%d repository found (including inactive)", "%d repositories found (including inactive)", len(repos)), len(repos))
 	} else {
-		message = fmt.Sprintf(app.TN_("%d active repository found", "%d active repositories found", len(repos)), len(repos))
+		message = fmt.Sprintf(app.TLN_(ctx, "%d active repository found", "%d active repositories found", len(repos)), len(repos))
 	}
 
 	return &RepoListResponse{
@@ -128,21 +184,30 @@ func (a *Actions) Add(ctx context.Context, args []string, date string) (*RepoAdd
 		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
 	}
 
+	ctx, release, err := a.operationLock.Acquire(ctx, journal.ActionAdd)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	if len(args) == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.T_("Repository source must be specified")))
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Repository source must be specified")))
 	}
 	date = strings.TrimSpace(date)
 
+	a.backupSources()
 	added, err := a.repoService.AddRepository(ctx, args, date)
 	if err != nil {
-		return nil, apmerr.New(apmerr.ErrorTypeRepository, err)
+		a.recordOperation(ctx, journal.ActionAdd, []string{strings.Join(args, " ")}, err)
+		return nil, newRepoError(err)
 	}
+	a.recordOperation(ctx, journal.ActionAdd, repoTargets(added), nil)
 
 	if len(added) == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.T_("All repositories already exist")))
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.TL_(ctx, "All repositories already exist")))
 	}
 
-	message := fmt.Sprintf(app.TN_("%d repository added", "%d repositories added", len(added)), len(added))
+	message := fmt.Sprintf(app.TLN_(ctx, "%d repository added", "%d repositories added", len(added)), len(added))
 
 	return &RepoAddRemoveResponse{
 		Message: message,
@@ -153,21 +218,21 @@ func (a *Actions) Add(ctx context.Context, args []string, date string) (*RepoAdd
 // CheckAdd симулирует добавление репозитория
 func (a *Actions) CheckAdd(ctx context.Context, args []string, date string) (*RepoSimulateResponse, error) {
 	if len(args) == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.T_("Repository source must be specified")))
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Repository source must be specified")))
 	}
 	date = strings.TrimSpace(date)
 
 	willAdd, err := a.repoService.SimulateAdd(ctx, args, date, false)
 	if err != nil {
-		return nil, apmerr.New(apmerr.ErrorTypeRepository, err)
+		return nil, newRepoError(err)
 	}
 
 	if len(willAdd) == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.T_("All repositories already exist")))
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.TL_(ctx, "All repositories already exist")))
 	}
 
 	return &RepoSimulateResponse{
-		Message: app.T_("Simulation results"),
+		Message: app.TL_(ctx, "Simulation results"),
 		WillAdd: willAdd,
 	}, nil
 }
@@ -179,21 +244,30 @@ func (a *Actions) Remove(ctx context.Context, args []string, date string) (*Repo
 		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
 	}
 
+	ctx, release, err := a.operationLock.Acquire(ctx, journal.ActionRemove)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	if len(args) == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.T_("Repository source must be specified")))
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Repository source must be specified")))
 	}
 	date = strings.TrimSpace(date)
 
+	a.backupSources()
 	removed, err := a.repoService.RemoveRepository(ctx, args, date, false)
 	if err != nil {
-		return nil, apmerr.New(apmerr.ErrorTypeRepository, err)
+		a.recordOperation(ctx, journal.ActionRemove, []string{strings.Join(args, " ")}, err)
+		return nil, newRepoError(err)
 	}
+	a.recordOperation(ctx, journal.ActionRemove, repoTargets(removed), nil)
 
 	if len(removed) == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.T_("No repositories found to remove")))
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.TL_(ctx, "No repositories found to remove")))
 	}
 
-	message := fmt.Sprintf(app.TN_("%d repository removed", "%d repositories removed", len(removed)), len(removed))
+	message := fmt.Sprintf(app.TLN_(ctx, "%d repository removed", "%d repositories removed", len(removed)), len(removed))
 
 	return &RepoAddRemoveResponse{
 		Message: message,
@@ -204,21 +278,21 @@ func (a *Actions) Remove(ctx context.Context, args []string, date string) (*Repo
 // CheckRemove симулирует удаление репозитория
 func (a *Actions) CheckRemove(ctx context.Context, args []string, date string) (*RepoSimulateResponse, error) {
 	if len(args) == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.T_("Repository source must be specified")))
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Repository source must be specified")))
 	}
 	date = strings.TrimSpace(date)
 
 	willRemove, err := a.repoService.SimulateRemove(ctx, args, date, false)
 	if err != nil {
-		return nil, apmerr.New(apmerr.ErrorTypeRepository, err)
+		return nil, newRepoError(err)
 	}
 
 	if len(willRemove) == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.T_("No repositories to remove")))
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.TL_(ctx, "No repositories to remove")))
 	}
 
 	return &RepoSimulateResponse{
-		Message:    app.T_("Simulation results"),
+		Message:    app.TL_(ctx, "Simulation results"),
 		WillRemove: willRemove,
 	}, nil
 }
@@ -229,23 +303,31 @@ func (a *Actions) Set(ctx context.Context, branch, date string) (*RepoSetRespons
 		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
 	}
 
+	ctx, release, err := a.operationLock.Acquire(ctx, journal.ActionSet)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	branch = strings.TrimSpace(branch)
 	if branch == "" {
-		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.T_("Branch name must be specified")))
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Branch name must be specified")))
 	}
 	date = strings.TrimSpace(date)
 
-	added, removed, err := a.repoService.SetBranch(ctx, branch, date)
-	if err != nil {
-		return nil, apmerr.New(apmerr.ErrorTypeRepository, err)
-	}
-
 	// Формируем имя ветки для сообщения
 	branchDisplay := branch
 	if date != "" {
 		branchDisplay = branch + " " + date
 	}
-	message := fmt.Sprintf(app.T_("Branch %s set successfully"), branchDisplay)
+
+	a.backupSources()
+	added, removed, err := a.repoService.SetBranch(ctx, branch, date)
+	a.recordOperation(ctx, journal.ActionSet, []string{branchDisplay}, err)
+	if err != nil {
+		return nil, newRepoError(err)
+	}
+	message := fmt.Sprintf(app.TL_(ctx, "Branch %s set successfully"), branchDisplay)
 
 	return &RepoSetResponse{
 		Message: message,
@@ -259,21 +341,21 @@ func (a *Actions) Set(ctx context.Context, branch, date string) (*RepoSetRespons
 func (a *Actions) CheckSet(ctx context.Context, branch, date string) (*RepoSimulateResponse, error) {
 	branch = strings.TrimSpace(branch)
 	if branch == "" {
-		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.T_("Branch name must be specified")))
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Branch name must be specified")))
 	}
 	date = strings.TrimSpace(date)
 
 	willRemove, err := a.repoService.SimulateRemove(ctx, []string{"all"}, "", false)
 	if err != nil {
-		return nil, apmerr.New(apmerr.ErrorTypeRepository, err)
+		return nil, newRepoError(err)
 	}
 	willAdd, err := a.repoService.SimulateAdd(ctx, []string{branch}, date, true)
 	if err != nil {
-		return nil, apmerr.New(apmerr.ErrorTypeRepository, err)
+		return nil, newRepoError(err)
 	}
 
 	return &RepoSimulateResponse{
-		Message:    app.T_("Simulation results"),
+		Message:    app.TL_(ctx, "Simulation results"),
 		WillAdd:    willAdd,
 		WillRemove: willRemove,
 	}, nil
@@ -285,16 +367,25 @@ func (a *Actions) Clean(ctx context.Context) (*RepoAddRemoveResponse, error) {
 		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
 	}
 
+	ctx, release, err := a.operationLock.Acquire(ctx, journal.ActionRemove)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	a.backupSources()
 	removed, err := a.repoService.CleanTemporary(ctx)
 	if err != nil {
-		return nil, apmerr.New(apmerr.ErrorTypeRepository, err)
+		a.recordOperation(ctx, journal.ActionRemove, nil, err)
+		return nil, newRepoError(err)
 	}
+	a.recordOperation(ctx, journal.ActionRemove, repoTargets(removed), nil)
 
 	if len(removed) == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.T_("No cdrom or task repositories found")))
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.TL_(ctx, "No cdrom or task repositories found")))
 	}
 
-	message := fmt.Sprintf(app.TN_("%d temporary repository removed", "%d temporary repositories removed", len(removed)), len(removed))
+	message := fmt.Sprintf(app.TLN_(ctx, "%d temporary repository removed", "%d temporary repositories removed", len(removed)), len(removed))
 
 	return &RepoAddRemoveResponse{
 		Message: message,
@@ -306,7 +397,7 @@ func (a *Actions) Clean(ctx context.Context) (*RepoAddRemoveResponse, error) {
 func (a *Actions) CheckClean(ctx context.Context) (*RepoSimulateResponse, error) {
 	repos, err := a.repoService.GetRepositories(ctx, false)
 	if err != nil {
-		return nil, apmerr.New(apmerr.ErrorTypeRepository, err)
+		return nil, newRepoError(err)
 	}
 
 	var willRemove []service.Repository
@@ -325,11 +416,11 @@ func (a *Actions) CheckClean(ctx context.Context) (*RepoSimulateResponse, error)
 	}
 
 	if len(willRemove) == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.T_("No cdrom or task repositories to remove")))
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.TL_(ctx, "No cdrom or task repositories to remove")))
 	}
 
 	return &RepoSimulateResponse{
-		Message:    app.T_("Simulation results"),
+		Message:    app.TL_(ctx, "Simulation results"),
 		WillRemove: willRemove,
 	}, nil
 }
@@ -348,15 +439,15 @@ func (a *Actions) GetBranches(_ context.Context) (*BranchesResponse, error) {
 func (a *Actions) GetTaskPackages(ctx context.Context, taskNum string) (*TaskPackagesResponse, error) {
 	taskNum = strings.TrimSpace(taskNum)
 	if taskNum == "" {
-		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.T_("Task number must be specified")))
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Task number must be specified")))
 	}
 
 	packages, err := a.repoService.GetTaskPackages(ctx, taskNum)
 	if err != nil {
-		return nil, apmerr.New(apmerr.ErrorTypeRepository, err)
+		return nil, newRepoError(err)
 	}
 
-	message := fmt.Sprintf(app.TN_("%d package in task %s", "%d packages in task %s", len(packages)), len(packages), taskNum)
+	message := fmt.Sprintf(app.TLN_(ctx, "%d package in task %s", "%d packages in task %s", len(packages)), len(packages), taskNum)
 
 	return &TaskPackagesResponse{
 		Message:  message,
@@ -366,6 +457,455 @@ func (a *Actions) GetTaskPackages(ctx context.Context, taskNum string) (*TaskPac
 	}, nil
 }
 
+// ArepoStatus возвращает состояние arepo и установленные biarch-пакеты
+func (a *Actions) ArepoStatus(ctx context.Context) (*ArepoResponse, error) {
+	repos, err := a.repoService.GetArepoRepositories(ctx)
+	if err != nil {
+		return nil, newRepoError(err)
+	}
+
+	packages, err := a.repoService.GetBiarchPackages(ctx)
+	if err != nil {
+		app.Log.Debugf("failed to query biarch packages: %v", err)
+	}
+
+	enabled := a.repoService.ArepoEnabled()
+	message := app.TL_(ctx, "Arepo is disabled")
+	if enabled {
+		message = app.TL_(ctx, "Arepo is enabled")
+	}
+
+	return &ArepoResponse{
+		Message:        message,
+		Enabled:        enabled,
+		Repositories:   repos,
+		BiarchPackages: packages,
+	}, nil
+}
+
+// ArepoEnable включает arepo и добавляет x86_64-i586 источники подключённым веткам
+func (a *Actions) ArepoEnable(ctx context.Context) (*ArepoResponse, error) {
+	if err := a.checkOverlay(ctx); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+
+	ctx, release, err := a.operationLock.Acquire(ctx, journal.ActionAdd)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	a.backupSources()
+	added, _, err := a.repoService.SetArepo(ctx, true, false)
+	if err != nil {
+		a.recordOperation(ctx, journal.ActionAdd, []string{"arepo"}, err)
+		return nil, newRepoError(err)
+	}
+	a.recordOperation(ctx, journal.ActionAdd, repoTargets(added), nil)
+
+	return &ArepoResponse{
+		Message: fmt.Sprintf(app.TLN_(ctx, "Arepo enabled, %d source added", "Arepo enabled, %d sources added", len(added)), len(added)),
+		Enabled: true,
+		Added:   added,
+	}, nil
+}
+
+// CheckArepoEnable симулирует включение arepo
+func (a *Actions) CheckArepoEnable(ctx context.Context) (*ArepoResponse, error) {
+	added, _, err := a.repoService.SetArepo(ctx, true, true)
+	if err != nil {
+		return nil, newRepoError(err)
+	}
+	if len(added) == 0 && a.repoService.ArepoEnabled() {
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.TL_(ctx, "Arepo is already enabled")))
+	}
+
+	return &ArepoResponse{
+		Message: app.TL_(ctx, "Simulation results"),
+		Enabled: true,
+		Added:   added,
+	}, nil
+}
+
+// ArepoDisable выключает arepo. Если установлены biarch-пакеты, без force операция отклоняется.
+func (a *Actions) ArepoDisable(ctx context.Context, force bool) (*ArepoResponse, error) {
+	if err := a.checkOverlay(ctx); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+
+	ctx, release, err := a.operationLock.Acquire(ctx, journal.ActionRemove)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	packages, err := a.biarchPackages(ctx, force)
+	if err != nil {
+		return nil, err
+	}
+
+	a.backupSources()
+	_, removed, err := a.repoService.SetArepo(ctx, false, false)
+	if err != nil {
+		a.recordOperation(ctx, journal.ActionRemove, []string{"arepo"}, err)
+		return nil, newRepoError(err)
+	}
+	a.recordOperation(ctx, journal.ActionRemove, repoTargets(removed), nil)
+
+	return &ArepoResponse{
+		Message:        fmt.Sprintf(app.TLN_(ctx, "Arepo disabled, %d source removed", "Arepo disabled, %d sources removed", len(removed)), len(removed)),
+		Enabled:        false,
+		Removed:        removed,
+		BiarchPackages: packages,
+	}, nil
+}
+
+// CheckArepoDisable симулирует выключение arepo
+func (a *Actions) CheckArepoDisable(ctx context.Context, force bool) (*ArepoResponse, error) {
+	packages, err := a.biarchPackages(ctx, force)
+	if err != nil {
+		return nil, err
+	}
+
+	_, removed, err := a.repoService.SetArepo(ctx, false, true)
+	if err != nil {
+		return nil, newRepoError(err)
+	}
+	if len(removed) == 0 && !a.repoService.ArepoEnabled() {
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.TL_(ctx, "Arepo is already disabled")))
+	}
+
+	return &ArepoResponse{
+		Message:        app.TL_(ctx, "Simulation results"),
+		Enabled:        false,
+		Removed:        removed,
+		BiarchPackages: packages,
+	}, nil
+}
+
+// biarchPackages возвращает установленные biarch-пакеты. Без force их наличие запрещает выключение arepo.
+func (a *Actions) biarchPackages(ctx context.Context, force bool) ([]string, error) {
+	packages, err := a.repoService.GetBiarchPackages(ctx)
+	if err != nil {
+		return nil, newRepoError(err)
+	}
+
+	if len(packages) > 0 && !force {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(
+			app.TL_(ctx, "Installed packages depend on arepo: %s. Remove them first or use --force"),
+			strings.Join(packages, ", "),
+		))
+	}
+	return packages, nil
+}
+
+// Backups возвращает снимки источников от старых к новым
+func (a *Actions) Backups(_ context.Context) (*RepoBackupListResponse, error) {
+	backups, err := a.repoService.ListBackups()
+	if err != nil {
+		return nil, newRepoError(err)
+	}
+
+	return &RepoBackupListResponse{
+		Message: fmt.Sprintf(app.TN_("%d repository backup found", "%d repository backups found", len(backups)), len(backups)),
+		Backups: backups,
+		Count:   len(backups),
+	}, nil
+}
+
+// Restore восстанавливает источники из снимка id, по умолчанию из последнего
+func (a *Actions) Restore(ctx context.Context, id string) (*RepoRestoreResponse, error) {
+	if err := a.checkOverlay(ctx); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+
+	ctx, release, err := a.operationLock.Acquire(ctx, journal.ActionRestore)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	backup, err := a.findBackup(id)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := a.repoService.GetRepositories(ctx, false)
+	if err != nil {
+		return nil, newRepoError(err)
+	}
+
+	err = a.repoService.RestoreBackup(backup.ID)
+	a.recordOperation(ctx, journal.ActionRestore, []string{backup.ID}, err)
+	if err != nil {
+		return nil, newRepoError(err)
+	}
+
+	after, err := a.repoService.GetRepositories(ctx, false)
+	if err != nil {
+		return nil, newRepoError(err)
+	}
+	added, removed := diffRepositories(before, after)
+
+	return &RepoRestoreResponse{
+		Message: fmt.Sprintf(app.TL_(ctx, "Repositories restored from backup %s"), backup.ID),
+		Backup:  *backup,
+		Added:   added,
+		Removed: removed,
+	}, nil
+}
+
+// CheckRestore симулирует восстановление источников из снимка
+func (a *Actions) CheckRestore(ctx context.Context, id string) (*RepoRestoreResponse, error) {
+	backup, err := a.findBackup(id)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := a.repoService.GetRepositories(ctx, false)
+	if err != nil {
+		return nil, newRepoError(err)
+	}
+	after, err := a.repoService.BackupRepositories(backup.ID)
+	if err != nil {
+		return nil, newRepoError(err)
+	}
+	added, removed := diffRepositories(before, after)
+
+	if len(added) == 0 && len(removed) == 0 {
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, fmt.Errorf(app.TL_(ctx, "Repositories already match backup %s"), backup.ID))
+	}
+
+	return &RepoRestoreResponse{
+		Message: app.TL_(ctx, "Simulation results"),
+		Backup:  *backup,
+		Added:   added,
+		Removed: removed,
+	}, nil
+}
+
+// findBackup ищет снимок по идентификатору, пустой идентификатор означает последний снимок
+func (a *Actions) findBackup(id string) (*service.Backup, error) {
+	backups, err := a.repoService.ListBackups()
+	if err != nil {
+		return nil, newRepoError(err)
+	}
+
+	id = strings.TrimSpace(id)
+	var backup *service.Backup
+	for i := range backups {
+		if backups[i].ID == id || (id == "" && i == len(backups)-1) {
+			backup = &backups[i]
+		}
+	}
+	if backup == nil {
+		if id == "" {
+			return nil, apmerr.New(apmerr.ErrorTypeNotFound, errors.New(app.T_("No repository backups found")))
+		}
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.T_("Repository backup %s not found"), id))
+	}
+	return backup, nil
+}
+
+// diffRepositories возвращает активные источники, появившиеся и исчезнувшие после изменения
+func diffRepositories(before, after []service.Repository) (added []service.Repository, removed []service.Repository) {
+	had := make(map[string]bool, len(before))
+	for _, repo := range before {
+		had[repo.Entry] = true
+	}
+	has := make(map[string]bool, len(after))
+	for _, repo := range after {
+		has[repo.Entry] = true
+		if !had[repo.Entry] {
+			added = append(added, repo)
+		}
+	}
+	for _, repo := range before {
+		if !has[repo.Entry] {
+			removed = append(removed, repo)
+		}
+	}
+	return added, removed
+}
+
+// KeyList возвращает ключи, которыми APT проверяет подписи репозиториев
+func (a *Actions) KeyList(ctx context.Context) (*KeyListResponse, error) {
+	keys, err := a.serviceKeys.ListKeys(ctx)
+	if err != nil {
+		return nil, newRepoError(err)
+	}
+
+	return &KeyListResponse{
+		Message: fmt.Sprintf(app.TLN_(ctx, "%d key found", "%d keys found", len(keys)), len(keys)),
+		Keys:    keys,
+		Count:   len(keys),
+	}, nil
+}
+
+// KeyAdd импортирует ключ из файла, по URL или с сервера ключей и регистрирует его для источников [name]
+func (a *Actions) KeyAdd(ctx context.Context, source, keyserver, name string) (*KeyChangeResponse, error) {
+	source = strings.TrimSpace(source)
+	if source == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Key file, URL or key ID must be specified")))
+	}
+
+	if err := a.checkOverlay(ctx); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+
+	ctx, release, err := a.operationLock.Acquire(ctx, journal.ActionAdd)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	added, err := a.serviceKeys.AddKey(ctx, source, strings.TrimSpace(keyserver), strings.TrimSpace(name), false)
+	if err != nil {
+		a.recordOperation(ctx, journal.ActionAdd, []string{source}, err)
+		return nil, newRepoError(err)
+	}
+	if len(added) == 0 {
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.TL_(ctx, "Key is already in the keyring")))
+	}
+	a.recordOperation(ctx, journal.ActionAdd, keyTargets(added), nil)
+
+	return &KeyChangeResponse{
+		Message: fmt.Sprintf(app.TLN_(ctx, "%d key added", "%d keys added", len(added)), len(added)),
+		Added:   added,
+	}, nil
+}
+
+// CheckKeyAdd симулирует импорт ключа: ключ загружается во временную связку и не регистрируется
+func (a *Actions) CheckKeyAdd(ctx context.Context, source, keyserver, name string) (*KeyChangeResponse, error) {
+	source = strings.TrimSpace(source)
+	if source == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Key file, URL or key ID must be specified")))
+	}
+
+	added, err := a.serviceKeys.AddKey(ctx, source, strings.TrimSpace(keyserver), strings.TrimSpace(name), true)
+	if err != nil {
+		return nil, newRepoError(err)
+	}
+	if len(added) == 0 {
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.TL_(ctx, "Key is already in the keyring")))
+	}
+
+	return &KeyChangeResponse{
+		Message: app.TL_(ctx, "Simulation results"),
+		Added:   added,
+	}, nil
+}
+
+// KeyRemove удаляет ключ по отпечатку, идентификатору или имени поставщика
+func (a *Actions) KeyRemove(ctx context.Context, id string) (*KeyChangeResponse, error) {
+	keys, err := a.findKeys(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = a.checkOverlay(ctx); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+
+	ctx, release, err := a.operationLock.Acquire(ctx, journal.ActionRemove)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if err = a.serviceKeys.RemoveKeys(ctx, keys); err != nil {
+		a.recordOperation(ctx, journal.ActionRemove, keyTargets(keys), err)
+		return nil, newRepoError(err)
+	}
+	a.recordOperation(ctx, journal.ActionRemove, keyTargets(keys), nil)
+
+	return &KeyChangeResponse{
+		Message: fmt.Sprintf(app.TLN_(ctx, "%d key removed", "%d keys removed", len(keys)), len(keys)),
+		Removed: keys,
+	}, nil
+}
+
+// CheckKeyRemove симулирует удаление ключа
+func (a *Actions) CheckKeyRemove(ctx context.Context, id string) (*KeyChangeResponse, error) {
+	keys, err := a.findKeys(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeyChangeResponse{
+		Message: app.TL_(ctx, "Simulation results"),
+		Removed: keys,
+	}, nil
+}
+
+// findKeys ищет ключи для удаления по отпечатку, идентификатору или имени поставщика
+func (a *Actions) findKeys(ctx context.Context, id string) ([]service.Key, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Key fingerprint, ID or name must be specified")))
+	}
+
+	keys, err := a.serviceKeys.FindKeys(ctx, id)
+	if err != nil {
+		return nil, newRepoError(err)
+	}
+	if len(keys) == 0 {
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.TL_(ctx, "Key %s not found"), id))
+	}
+	if err = service.CheckRemovable(keys); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, err)
+	}
+	return keys, nil
+}
+
+// KeyVerify проверяет, что для всех активных источников есть ключи подписи
+func (a *Actions) KeyVerify(ctx context.Context) (*KeyVerifyResponse, error) {
+	repos, err := a.repoService.GetRepositories(ctx, false)
+	if err != nil {
+		return nil, newRepoError(err)
+	}
+
+	checks, err := a.serviceKeys.VerifyKeys(ctx, repos)
+	if err != nil {
+		return nil, newRepoError(err)
+	}
+
+	missing := 0
+	for _, check := range checks {
+		if check.Status == service.KeyStatusMissingVendor || check.Status == service.KeyStatusMissingKey {
+			missing++
+		}
+	}
+
+	message := app.TL_(ctx, "All repository keys are present")
+	if missing > 0 {
+		message = fmt.Sprintf(app.TLN_(ctx, "%d repository has no key in the keyring", "%d repositories have no key in the keyring", missing), missing)
+	}
+
+	return &KeyVerifyResponse{
+		Message:      message,
+		Checks:       checks,
+		MissingCount: missing,
+	}, nil
+}
+
+// keyTargets возвращает отпечатки ключей для записи в историю
+func keyTargets(keys []service.Key) []string {
+	targets := make([]string, 0, len(keys))
+	for _, key := range keys {
+		targets = append(targets, key.Fingerprint)
+	}
+	return targets
+}
+
+// newRepoError оборачивает ошибку сервиса репозиториев, выделяя отмену операции клиентом.
+func newRepoError(err error) error {
+	if errors.Is(err, context.Canceled) {
+		return apmerr.New(apmerr.ErrorTypeCanceled, errors.New(app.T_("Operation cancelled")))
+	}
+	return apmerr.New(apmerr.ErrorTypeRepository, err)
+}
+
 // checkOverlay проверяет, включен ли overlay
 func (a *Actions) checkOverlay(_ context.Context) error {
 	if a.appConfig.ConfigManager.GetConfig().IsAtomic {
@@ -389,40 +929,66 @@ func (a *Actions) TestTask(ctx context.Context, taskNum string) (*TestTaskRespon
 		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
 	}
 
+	ctx, release, err := a.operationLock.Acquire(ctx, journal.ActionInstall)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	taskNum = strings.TrimSpace(taskNum)
 	if taskNum == "" {
-		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.T_("Task number must be specified")))
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Task number must be specified")))
 	}
 
 	var packagesToInstall []string
-	var err error
-
 	packagesToInstall, err = a.repoService.GetTaskPackages(ctx, taskNum)
 	if err != nil {
-		return nil, apmerr.New(apmerr.ErrorTypeRepository, err)
+		return nil, newRepoError(err)
 	}
 
 	if len(packagesToInstall) == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeNotFound, errors.New(app.T_("No packages to install from task")))
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, errors.New(app.TL_(ctx, "No packages to install from task")))
 	}
 
 	_, err = a.repoService.AddRepository(ctx, []string{taskNum}, "")
 	if err != nil {
-		return nil, apmerr.New(apmerr.ErrorTypeRepository, fmt.Errorf("%s: %v", app.T_("Failed to add task repository"), err))
+		return nil, apmerr.New(apmerr.ErrorTypeRepository, fmt.Errorf("%s: %v", app.TL_(ctx, "Failed to add task repository"), err))
 	}
 
 	defer func() {
 		_, _ = a.repoService.RemoveRepository(ctx, []string{taskNum}, "", false)
 	}()
 
-	_, err = a.serviceAptActions.Update(ctx)
+	packageParse, err := a.installTaskPackages(ctx, packagesToInstall)
 	if err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf(
+		"%s %s %s (%s %s)",
+		fmt.Sprintf(app.TLN_(ctx, "%d package successfully installed", "%d packages successfully installed", packageParse.NewInstalledCount), packageParse.NewInstalledCount),
+		app.TL_(ctx, "and"),
+		fmt.Sprintf(app.TLN_(ctx, "%d updated", "%d updated", packageParse.UpgradedCount), packageParse.UpgradedCount),
+		app.TL_(ctx, "task"),
+		taskNum,
+	)
+
+	return &TestTaskResponse{
+		Message: message,
+		TaskNum: taskNum,
+		Info:    *packageParse,
+	}, nil
+}
+
+// installTaskPackages обновляет базу пакетов и устанавливает пакеты из подключённой задачи
+func (a *Actions) installTaskPackages(ctx context.Context, packages []string) (*aptLib.PackageChanges, error) {
+	if _, err := a.serviceAptActions.Update(ctx); err != nil {
 		return nil, apmerr.New(apmerr.ErrorTypeApt, err)
 	}
 
 	packagesInstall, packagesRemove, _, packageParse, errFind := a.serviceAptActions.FindPackage(
 		ctx,
-		packagesToInstall,
+		packages,
 		nil,
 		false,
 		false,
@@ -433,26 +999,94 @@ func (a *Actions) TestTask(ctx context.Context, taskNum string) (*TestTaskRespon
 	}
 
 	if packageParse.NewInstalledCount == 0 && packageParse.UpgradedCount == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.T_("The operation will not make any changes")))
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.TL_(ctx, "The operation will not make any changes")))
 	}
 
-	err = a.serviceAptActions.CombineInstallRemovePackages(ctx, packagesInstall, packagesRemove, false, false, false)
+	err := a.serviceAptActions.CombineInstallRemovePackages(ctx, packagesInstall, packagesRemove, false, false, false)
 	if err != nil {
 		return nil, apmerr.New(apmerr.ErrorTypeApt, err)
 	}
 
-	message := fmt.Sprintf(
-		"%s %s %s (%s %s)",
-		fmt.Sprintf(app.TN_("%d package successfully installed", "%d packages successfully installed", packageParse.NewInstalledCount), packageParse.NewInstalledCount),
-		app.T_("and"),
-		fmt.Sprintf(app.TN_("%d updated", "%d updated", packageParse.UpgradedCount), packageParse.UpgradedCount),
-		app.T_("task"),
-		taskNum,
-	)
+	return packageParse, nil
+}
 
-	return &TestTaskResponse{
-		Message: message,
-		TaskNum: taskNum,
-		Info:    *packageParse,
-	}, nil
+// VerifyTask проверяет пакеты задачи без изменения системы: репозиторий задачи подключается
+// во временной конфигурации APT, установка каждого пакета симулируется отдельно, после чего песочница удаляется.
+func (a *Actions) VerifyTask(ctx context.Context, taskNum string) (*VerifyTaskResponse, error) {
+	taskNum = strings.TrimSpace(taskNum)
+	if taskNum == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Task number must be specified")))
+	}
+
+	packages, err := a.repoService.GetTaskPackages(ctx, taskNum)
+	if err != nil {
+		return nil, newRepoError(err)
+	}
+	if len(packages) == 0 {
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, errors.New(app.TL_(ctx, "No packages to install from task")))
+	}
+
+	sandbox, err := a.repoService.CreateSandbox(ctx, []string{taskNum})
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeRepository, fmt.Errorf("%s: %v", app.TL_(ctx, "Failed to add task repository"), err))
+	}
+	defer func() {
+		if errClean := sandbox.Cleanup(); errClean != nil {
+			app.Log.Warning(fmt.Sprintf("failed to remove sandbox %s: %v", sandbox.Dir, errClean))
+		}
+	}()
+
+	previous := a.serviceAptActions.GetAptConfigOverrides()
+	overrides := make(map[string]string, len(previous)+len(sandbox.Overrides))
+	for k, v := range previous {
+		overrides[k] = v
+	}
+	for k, v := range sandbox.Overrides {
+		overrides[k] = v
+	}
+	a.serviceAptActions.SetAptConfigOverrides(overrides)
+	defer a.serviceAptActions.SetAptConfigOverrides(previous)
+
+	if err = a.serviceAptActions.AptUpdate(ctx); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeApt, err)
+	}
+
+	resp := &VerifyTaskResponse{
+		TaskNum:  taskNum,
+		Packages: make([]TaskPackageCheck, 0, len(packages)),
+	}
+
+	for _, name := range packages {
+		if err = ctx.Err(); err != nil {
+			return nil, newRepoError(err)
+		}
+
+		check := TaskPackageCheck{Name: name, OK: true}
+		changes, errCheck := a.serviceAptActions.CheckInstall(ctx, []string{name})
+		if errCheck != nil {
+			check.OK = false
+			check.Error = errCheck.Error()
+			resp.BrokenCount++
+		} else {
+			check.Info = changes
+		}
+		resp.Packages = append(resp.Packages, check)
+	}
+
+	if changes, errAll := a.serviceAptActions.CheckInstall(ctx, packages); errAll != nil {
+		resp.InstallError = errAll.Error()
+	} else {
+		resp.Info = changes
+	}
+
+	switch {
+	case resp.BrokenCount > 0:
+		resp.Message = fmt.Sprintf(app.TLN_(ctx, "%d package from task %s has broken dependencies", "%d packages from task %s have broken dependencies", resp.BrokenCount), resp.BrokenCount, taskNum)
+	case resp.InstallError != "":
+		resp.Message = fmt.Sprintf(app.TL_(ctx, "Packages from task %s cannot be installed together"), taskNum)
+	default:
+		resp.Message = fmt.Sprintf(app.TLN_(ctx, "All %d package from task %s can be installed", "All %d packages from task %s can be installed", len(packages)), len(packages), taskNum)
+	}
+
+	return resp, nil
 }
@@ -2,13 +2,21 @@ package repository
 
 import (
 	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
 	_package "apm/internal/common/apt/package"
 	aptLib "apm/internal/common/binding/apt/lib"
+	"apm/internal/common/journal"
+	"apm/internal/common/oplock"
 	"apm/internal/common/testutil"
 	"apm/internal/domain/repository/service"
 	"context"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 type mockRepoService struct {
@@ -30,6 +38,53 @@ type mockRepoService struct {
 	simulateAddErr     error
 	simulateRemResult  []service.Repository
 	simulateRemErr     error
+	sandbox            *service.Sandbox
+	sandboxErr         error
+	arepoEnabled       bool
+	arepoRepos         []service.Repository
+	arepoAdded         []service.Repository
+	arepoRemoved       []service.Repository
+	arepoSet           *bool
+	biarchPackages     []string
+	backups            []service.Backup
+	backupCalls        int
+	restored           string
+	restoredRepos      []service.Repository
+	healthIssues       []service.HealthIssue
+	namedRepos         []service.NamedRepository
+	taskInfos          []*service.TaskInfo
+	taskInfoCalls      int
+	migration          *service.Migration
+	migrateSimulate    *bool
+}
+
+func (m *mockRepoService) CheckHealth(_ context.Context, _ []service.Repository) []service.HealthIssue {
+	return m.healthIssues
+}
+
+func (m *mockRepoService) NamedRepositories() ([]service.NamedRepository, error) {
+	return m.namedRepos, nil
+}
+
+func (m *mockRepoService) MigrateAptRepo(_ context.Context, simulate bool) (*service.Migration, error) {
+	m.migrateSimulate = &simulate
+	if m.migration == nil {
+		return &service.Migration{}, nil
+	}
+	return m.migration, nil
+}
+
+// GetTaskInfo возвращает состояния задачи по очереди, повторяя последнее
+func (m *mockRepoService) GetTaskInfo(_ context.Context, taskNum string) (*service.TaskInfo, error) {
+	if len(m.taskInfos) == 0 {
+		return nil, errors.New("task " + taskNum + " not found")
+	}
+	info := m.taskInfos[min(m.taskInfoCalls, len(m.taskInfos)-1)]
+	m.taskInfoCalls++
+	if info == nil {
+		return nil, errors.New("temporary failure")
+	}
+	return info, nil
 }
 
 func (m *mockRepoService) GetRepositories(_ context.Context, _ bool) ([]service.Repository, error) {
@@ -58,6 +113,37 @@ func (m *mockRepoService) SimulateRemove(_ context.Context, _ []string, _ string
 	return m.simulateRemResult, m.simulateRemErr
 }
 
+func (m *mockRepoService) CreateSandbox(_ context.Context, _ []string) (*service.Sandbox, error) {
+	return m.sandbox, m.sandboxErr
+}
+func (m *mockRepoService) ArepoEnabled() bool { return m.arepoEnabled }
+func (m *mockRepoService) GetArepoRepositories(_ context.Context) ([]service.Repository, error) {
+	return m.arepoRepos, nil
+}
+func (m *mockRepoService) SetArepo(_ context.Context, enabled bool, simulate bool) ([]service.Repository, []service.Repository, error) {
+	if !simulate {
+		m.arepoSet = &enabled
+	}
+	return m.arepoAdded, m.arepoRemoved, nil
+}
+func (m *mockRepoService) GetBiarchPackages(_ context.Context) ([]string, error) {
+	return m.biarchPackages, nil
+}
+
+func (m *mockRepoService) BackupSources() (*service.Backup, error) {
+	m.backupCalls++
+	return &service.Backup{}, nil
+}
+func (m *mockRepoService) ListBackups() ([]service.Backup, error) { return m.backups, nil }
+func (m *mockRepoService) RestoreBackup(id string) error {
+	m.restored = id
+	m.getReposResult = m.restoredRepos
+	return nil
+}
+func (m *mockRepoService) BackupRepositories(_ string) ([]service.Repository, error) {
+	return m.restoredRepos, nil
+}
+
 type mockAptActions struct {
 	updateErr    error
 	findInstall  []string
@@ -65,6 +151,23 @@ type mockAptActions struct {
 	findChanges  *aptLib.PackageChanges
 	findErr      error
 	combineErr   error
+	overrides    map[string]string
+	aptUpdateErr error
+	checkInstall func(packages []string) (*aptLib.PackageChanges, error)
+}
+
+func (m *mockAptActions) SetAptConfigOverrides(overrides map[string]string) {
+	m.overrides = overrides
+}
+func (m *mockAptActions) GetAptConfigOverrides() map[string]string { return m.overrides }
+func (m *mockAptActions) AptUpdate(_ context.Context, _ ...bool) error {
+	return m.aptUpdateErr
+}
+func (m *mockAptActions) CheckInstall(_ context.Context, packages []string) (*aptLib.PackageChanges, error) {
+	if m.checkInstall != nil {
+		return m.checkInstall(packages)
+	}
+	return &aptLib.PackageChanges{}, nil
 }
 
 func (m *mockAptActions) Update(_ context.Context, _ ...bool) ([]_package.Package, error) {
@@ -77,6 +180,19 @@ func (m *mockAptActions) CombineInstallRemovePackages(_ context.Context, _ []str
 	return m.combineErr
 }
 
+type mockJournal struct {
+	entries []journal.Entry
+}
+
+func (m *mockJournal) RecordResult(_ context.Context, entry journal.Entry, opErr error) error {
+	if opErr != nil {
+		entry.Status = journal.StatusFailed
+		entry.Error = opErr.Error()
+	}
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
 type mockOverlay struct{}
 
 func (m *mockOverlay) EnableOverlay() error { return nil }
@@ -191,6 +307,49 @@ func TestAdd(t *testing.T) {
 		_, err := actions.Add(context.Background(), []string{"p11"}, "")
 		testutil.AssertAPMError(t, err, apmerr.ErrorTypeRepository)
 	})
+
+	t.Run("cancelled request returns canceled error", func(t *testing.T) {
+		repo := &mockRepoService{addErr: fmt.Errorf("check task: %w", context.Canceled)}
+		actions := newTestActions(repo, nil)
+
+		_, err := actions.Add(context.Background(), []string{"123456"}, "")
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeCanceled)
+	})
+}
+
+func TestRecordHistory(t *testing.T) {
+	jr := &mockJournal{}
+	repo := &mockRepoService{
+		addResult:    []service.Repository{{Entry: "rpm [p11] http://ftp.altlinux.org/pub/distributions/ALTLinux/p11/branch x86_64 classic"}},
+		setBranchErr: errors.New("unknown branch"),
+	}
+	actions := newTestActions(repo, nil)
+	actions.serviceJournal = jr
+	var signals []string
+	actions.stateNotifier = func(signal string, args ...interface{}) {
+		signals = append(signals, signal)
+	}
+
+	if _, err := actions.Add(context.Background(), []string{"p11"}, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, _ = actions.Set(context.Background(), "p12", "")
+
+	if len(signals) != 1 || signals[0] != app.RepoChangedSignal {
+		t.Errorf("expected one RepoChanged signal for the successful change, got %v", signals)
+	}
+
+	if len(jr.entries) != 2 {
+		t.Fatalf("expected 2 history entries, got %+v", jr.entries)
+	}
+	added := jr.entries[0]
+	if added.Module != journal.ModuleRepository || added.Action != journal.ActionAdd || len(added.Targets) != 1 ||
+		added.Targets[0] != repo.addResult[0].Entry || added.Status == journal.StatusFailed {
+		t.Errorf("unexpected add entry: %+v", added)
+	}
+	if set := jr.entries[1]; set.Action != journal.ActionSet || set.Status != journal.StatusFailed || set.Targets[0] != "p12" {
+		t.Errorf("unexpected set entry: %+v", set)
+	}
 }
 
 func TestCheckAdd(t *testing.T) {
@@ -653,3 +812,657 @@ func TestTestTask(t *testing.T) {
 		testutil.AssertAPMError(t, err, apmerr.ErrorTypeApt)
 	})
 }
+
+func TestVerifyTask(t *testing.T) {
+	t.Run("empty task number returns validation error", func(t *testing.T) {
+		actions := newTestActions(nil, nil)
+
+		_, err := actions.VerifyTask(context.Background(), " ")
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+	})
+
+	t.Run("sandbox error propagates", func(t *testing.T) {
+		repo := &mockRepoService{taskPackagesResult: []string{"vim"}, sandboxErr: errors.New("task not found")}
+		actions := newTestActions(repo, nil)
+
+		_, err := actions.VerifyTask(context.Background(), "123")
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeRepository)
+	})
+
+	t.Run("reports broken packages and discards sandbox", func(t *testing.T) {
+		dir := t.TempDir() + "/sandbox"
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		repo := &mockRepoService{
+			taskPackagesResult: []string{"vim", "vim-broken"},
+			sandbox:            &service.Sandbox{Dir: dir, Overrides: map[string]string{"Dir::State::lists": dir + "/lists/"}},
+		}
+		var overridesDuringCheck map[string]string
+		apt := &mockAptActions{overrides: map[string]string{"APT::Keep": "1"}}
+		apt.checkInstall = func(packages []string) (*aptLib.PackageChanges, error) {
+			overridesDuringCheck = apt.overrides
+			for _, p := range packages {
+				if p == "vim-broken" {
+					return nil, errors.New("vim-broken: Depends: libfoo but it is not installable")
+				}
+			}
+			return &aptLib.PackageChanges{NewInstalledPackages: packages}, nil
+		}
+		actions := newTestActions(repo, apt)
+
+		resp, err := actions.VerifyTask(context.Background(), "123")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.BrokenCount != 1 || len(resp.Packages) != 2 {
+			t.Fatalf("expected 1 broken of 2, got %+v", resp)
+		}
+		if !resp.Packages[0].OK || resp.Packages[1].OK || resp.Packages[1].Error == "" {
+			t.Errorf("unexpected per-package results: %+v", resp.Packages)
+		}
+		if resp.InstallError == "" {
+			t.Error("expected combined install error")
+		}
+		if overridesDuringCheck["Dir::State::lists"] == "" || overridesDuringCheck["APT::Keep"] != "1" {
+			t.Errorf("sandbox overrides were not applied: %v", overridesDuringCheck)
+		}
+		if len(apt.overrides) != 1 || apt.overrides["APT::Keep"] != "1" {
+			t.Errorf("overrides were not restored: %v", apt.overrides)
+		}
+		if _, err = os.Stat(dir); !os.IsNotExist(err) {
+			t.Errorf("sandbox must be removed, stat err: %v", err)
+		}
+	})
+}
+
+func TestArepoDisable(t *testing.T) {
+	t.Run("refuses with installed biarch packages", func(t *testing.T) {
+		repo := &mockRepoService{biarchPackages: []string{"i586-wine"}}
+		actions := newTestActions(repo, nil)
+
+		_, err := actions.ArepoDisable(context.Background(), false)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+		if repo.arepoSet != nil {
+			t.Error("configuration must not change")
+		}
+	})
+
+	t.Run("force disables and reports packages", func(t *testing.T) {
+		repo := &mockRepoService{
+			biarchPackages: []string{"i586-wine"},
+			arepoRemoved:   []service.Repository{{Arch: "x86_64-i586", Branch: "sisyphus"}},
+		}
+		actions := newTestActions(repo, nil)
+
+		resp, err := actions.ArepoDisable(context.Background(), true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if repo.arepoSet == nil || *repo.arepoSet {
+			t.Error("expected arepo to be disabled")
+		}
+		if len(resp.Removed) != 1 || len(resp.BiarchPackages) != 1 {
+			t.Errorf("unexpected response: %+v", resp)
+		}
+	})
+
+	t.Run("simulation keeps configuration", func(t *testing.T) {
+		repo := &mockRepoService{arepoRemoved: []service.Repository{{Arch: "x86_64-i586", Branch: "sisyphus"}}}
+		actions := newTestActions(repo, nil)
+
+		resp, err := actions.CheckArepoDisable(context.Background(), false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if repo.arepoSet != nil || repo.backupCalls != 0 {
+			t.Error("configuration must not change")
+		}
+		if len(resp.Removed) != 1 {
+			t.Errorf("unexpected response: %+v", resp)
+		}
+	})
+
+	t.Run("simulation refuses with installed biarch packages", func(t *testing.T) {
+		actions := newTestActions(&mockRepoService{biarchPackages: []string{"i586-wine"}}, nil)
+
+		_, err := actions.CheckArepoDisable(context.Background(), false)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+	})
+}
+
+type mockKeyService struct {
+	keys      []service.Key
+	added     []service.Key
+	addErr    error
+	removed   []service.Key
+	checks    []service.KeyCheck
+	verifyFor []service.Repository
+	vendors   []service.Vendor
+	addedAs   string
+}
+
+func (m *mockKeyService) ListKeys(_ context.Context) ([]service.Key, error) { return m.keys, nil }
+func (m *mockKeyService) FindKeys(_ context.Context, id string) ([]service.Key, error) {
+	var found []service.Key
+	for _, key := range m.keys {
+		if key.Fingerprint == id {
+			found = append(found, key)
+		}
+	}
+	return found, nil
+}
+func (m *mockKeyService) AddKey(_ context.Context, _, _, name string, simulate bool) ([]service.Key, error) {
+	if !simulate {
+		m.addedAs = name
+	}
+	return m.added, m.addErr
+}
+func (m *mockKeyService) GetVendors() ([]service.Vendor, error) { return m.vendors, nil }
+func (m *mockKeyService) RemoveKeys(_ context.Context, keys []service.Key) error {
+	m.removed = keys
+	return nil
+}
+func (m *mockKeyService) VerifyKeys(_ context.Context, repos []service.Repository) ([]service.KeyCheck, error) {
+	m.verifyFor = repos
+	return m.checks, nil
+}
+
+func TestKeyAdd(t *testing.T) {
+	key := service.Key{Fingerprint: "0123456789ABCDEF0123456789ABCDEF01234567", Vendors: []string{"vendor"}}
+
+	t.Run("records added key", func(t *testing.T) {
+		actions := newTestActions(nil, nil)
+		journalMock := &mockJournal{}
+		actions.serviceJournal = journalMock
+		actions.serviceKeys = &mockKeyService{added: []service.Key{key}}
+
+		resp, err := actions.KeyAdd(context.Background(), "/tmp/vendor.asc", "", "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resp.Added) != 1 {
+			t.Errorf("expected 1 added key, got %d", len(resp.Added))
+		}
+		if len(journalMock.entries) != 1 || journalMock.entries[0].Targets[0] != key.Fingerprint {
+			t.Errorf("expected key fingerprint in journal, got %+v", journalMock.entries)
+		}
+	})
+
+	t.Run("empty source is rejected", func(t *testing.T) {
+		actions := newTestActions(nil, nil)
+		actions.serviceKeys = &mockKeyService{}
+
+		_, err := actions.KeyAdd(context.Background(), " ", "", "")
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+	})
+
+	t.Run("known key is no operation", func(t *testing.T) {
+		actions := newTestActions(nil, nil)
+		actions.serviceKeys = &mockKeyService{}
+
+		_, err := actions.KeyAdd(context.Background(), "/tmp/vendor.asc", "", "")
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNoOperation)
+	})
+
+	t.Run("service error is repository error", func(t *testing.T) {
+		actions := newTestActions(nil, nil)
+		actions.serviceKeys = &mockKeyService{addErr: errors.New("no valid OpenPGP data found")}
+
+		_, err := actions.KeyAdd(context.Background(), "/tmp/vendor.asc", "", "")
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeRepository)
+	})
+
+	t.Run("simulation does not register key", func(t *testing.T) {
+		keys := &mockKeyService{added: []service.Key{key}}
+		actions := newTestActions(nil, nil)
+		journalMock := &mockJournal{}
+		actions.serviceJournal = journalMock
+		actions.serviceKeys = keys
+
+		resp, err := actions.CheckKeyAdd(context.Background(), "/tmp/vendor.asc", "", "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resp.Added) != 1 || keys.addedAs != "" || len(journalMock.entries) != 0 {
+			t.Errorf("expected simulated key only, got %+v", resp)
+		}
+	})
+}
+
+func TestKeyRemove(t *testing.T) {
+	key := service.Key{Fingerprint: "0123456789ABCDEF0123456789ABCDEF01234567"}
+
+	t.Run("removes found key", func(t *testing.T) {
+		keys := &mockKeyService{keys: []service.Key{key}}
+		actions := newTestActions(nil, nil)
+		actions.serviceKeys = keys
+
+		resp, err := actions.KeyRemove(context.Background(), key.Fingerprint)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resp.Removed) != 1 || len(keys.removed) != 1 {
+			t.Errorf("expected key removed, got %+v", resp)
+		}
+	})
+
+	t.Run("unknown key is not found", func(t *testing.T) {
+		actions := newTestActions(nil, nil)
+		actions.serviceKeys = &mockKeyService{keys: []service.Key{key}}
+
+		_, err := actions.KeyRemove(context.Background(), "missing")
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNotFound)
+	})
+
+	t.Run("simulation keeps key", func(t *testing.T) {
+		keys := &mockKeyService{keys: []service.Key{key}}
+		actions := newTestActions(nil, nil)
+		actions.serviceKeys = keys
+
+		resp, err := actions.CheckKeyRemove(context.Background(), key.Fingerprint)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resp.Removed) != 1 || keys.removed != nil {
+			t.Errorf("expected key kept, got %+v", keys.removed)
+		}
+	})
+}
+
+func TestKeyVerify(t *testing.T) {
+	repos := []service.Repository{{Entry: "rpm [p11] http://example.org x86_64 classic", Active: true}}
+	keys := &mockKeyService{checks: []service.KeyCheck{
+		{Entry: repos[0].Entry, Vendor: "p11", Status: service.KeyStatusMissingKey},
+		{Entry: "rpm http://example.org noarch classic", Status: service.KeyStatusUnsigned},
+	}}
+	actions := newTestActions(&mockRepoService{getReposResult: repos}, nil)
+	actions.serviceKeys = keys
+
+	resp, err := actions.KeyVerify(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.MissingCount != 1 {
+		t.Errorf("expected 1 missing key, got %d", resp.MissingCount)
+	}
+	if len(keys.verifyFor) != 1 {
+		t.Errorf("expected active repositories passed to verification, got %+v", keys.verifyFor)
+	}
+}
+
+func TestBackupBeforeChanges(t *testing.T) {
+	repo := &mockRepoService{addResult: []service.Repository{{Entry: "rpm [p11] http://example.org x86_64 classic"}}}
+	actions := newTestActions(repo, nil)
+
+	if _, err := actions.Add(context.Background(), []string{"p11"}, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.backupCalls != 1 {
+		t.Errorf("expected sources backed up once, got %d", repo.backupCalls)
+	}
+
+	if _, err := actions.CheckAdd(context.Background(), []string{"p11"}, ""); err == nil {
+		t.Fatal("expected no operation error for empty simulation")
+	}
+	if repo.backupCalls != 1 {
+		t.Errorf("expected no backup for simulation, got %d", repo.backupCalls)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	p10 := service.Repository{Entry: "rpm [p10] http://example.org/p10 x86_64 classic", Active: true}
+	p11 := service.Repository{Entry: "rpm [p11] http://example.org/p11 x86_64 classic", Active: true}
+	backups := []service.Backup{{ID: "20250101T100000"}, {ID: "20250102T100000"}}
+
+	t.Run("latest backup by default", func(t *testing.T) {
+		repo := &mockRepoService{
+			backups:        backups,
+			getReposResult: []service.Repository{p11},
+			restoredRepos:  []service.Repository{p10},
+		}
+		journalMock := &mockJournal{}
+		actions := newTestActions(repo, nil)
+		actions.serviceJournal = journalMock
+
+		resp, err := actions.Restore(context.Background(), "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if repo.restored != "20250102T100000" || resp.Backup.ID != repo.restored {
+			t.Errorf("expected latest backup restored, got %q", repo.restored)
+		}
+		if len(resp.Added) != 1 || resp.Added[0].Entry != p10.Entry || len(resp.Removed) != 1 || resp.Removed[0].Entry != p11.Entry {
+			t.Errorf("unexpected changes: added %+v, removed %+v", resp.Added, resp.Removed)
+		}
+		if len(journalMock.entries) != 1 || journalMock.entries[0].Action != journal.ActionRestore {
+			t.Errorf("expected restore recorded in journal, got %+v", journalMock.entries)
+		}
+	})
+
+	t.Run("selected backup", func(t *testing.T) {
+		repo := &mockRepoService{backups: backups}
+		actions := newTestActions(repo, nil)
+
+		if _, err := actions.Restore(context.Background(), "20250101T100000"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if repo.restored != "20250101T100000" {
+			t.Errorf("expected selected backup restored, got %q", repo.restored)
+		}
+	})
+
+	t.Run("unknown backup is not found", func(t *testing.T) {
+		actions := newTestActions(&mockRepoService{backups: backups}, nil)
+
+		_, err := actions.Restore(context.Background(), "20240101T100000")
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNotFound)
+	})
+
+	t.Run("no backups", func(t *testing.T) {
+		actions := newTestActions(&mockRepoService{}, nil)
+
+		_, err := actions.Restore(context.Background(), "")
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNotFound)
+	})
+
+	t.Run("simulation shows changes without restoring", func(t *testing.T) {
+		repo := &mockRepoService{
+			backups:        backups,
+			getReposResult: []service.Repository{p11},
+			restoredRepos:  []service.Repository{p10},
+		}
+		actions := newTestActions(repo, nil)
+
+		resp, err := actions.CheckRestore(context.Background(), "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if repo.restored != "" {
+			t.Errorf("backup must not be restored, got %q", repo.restored)
+		}
+		if resp.Backup.ID != "20250102T100000" || len(resp.Added) != 1 || len(resp.Removed) != 1 {
+			t.Errorf("unexpected response: %+v", resp)
+		}
+	})
+
+	t.Run("simulation of matching backup is no operation", func(t *testing.T) {
+		repo := &mockRepoService{
+			backups:        backups,
+			getReposResult: []service.Repository{p10},
+			restoredRepos:  []service.Repository{p10},
+		}
+		actions := newTestActions(repo, nil)
+
+		_, err := actions.CheckRestore(context.Background(), "")
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNoOperation)
+	})
+}
+
+func TestHealth(t *testing.T) {
+	repos := []service.Repository{
+		{Entry: "rpm [p11] http://example.org x86_64 classic", File: "sources.list", Active: true},
+		{Entry: "rpm http://git.altlinux.org/repo/1/ x86_64 task", Branch: "task", Active: true},
+	}
+	repo := &mockRepoService{getReposResult: repos, healthIssues: []service.HealthIssue{
+		{Severity: service.SeverityWarning, Check: service.HealthDuplicate},
+	}}
+	actions := newTestActions(repo, nil)
+	actions.serviceKeys = &mockKeyService{checks: []service.KeyCheck{
+		{Entry: repos[0].Entry, Vendor: "p11", Status: service.KeyStatusMissingKey},
+		{Entry: repos[1].Entry, Status: service.KeyStatusUnsigned},
+	}}
+
+	resp, err := actions.Health(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Checked != 2 || resp.Errors != 1 || resp.Warnings != 1 || len(resp.Issues) != 3 {
+		t.Fatalf("unexpected report: %+v", resp)
+	}
+	if first := resp.Issues[0]; first.Check != service.HealthKey || first.File != "sources.list" {
+		t.Errorf("expected the missing key first, got %+v", first)
+	}
+	if last := resp.Issues[2]; last.Severity != service.SeverityInfo {
+		t.Errorf("expected the unsigned task to be informational, got %+v", last)
+	}
+}
+
+func TestAvailable(t *testing.T) {
+	repo := &mockRepoService{namedRepos: []service.NamedRepository{
+		{Name: "autoimports", Sources: []string{"autoimports.$(BRANCH)"}},
+	}}
+	actions := newTestActions(repo, nil)
+
+	resp, err := actions.Available(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Count != 1 || !resp.Repositories[0].Enabled {
+		t.Errorf("expected enabled autoimports, got %+v", resp.Repositories)
+	}
+
+	repo.simulateAddResult = []service.Repository{{URL: "http://example.com/vendor"}}
+	resp, err = actions.Available(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Repositories[0].Enabled {
+		t.Error("repository with sources to add must not be enabled")
+	}
+}
+
+func TestAddNamed(t *testing.T) {
+	named := service.NamedRepository{
+		Name:    "vendor",
+		Sources: []string{"rpm [vendor-key] http://example.com/vendor x86_64 main"},
+		Key:     "https://example.com/vendor.asc",
+		KeyName: "vendor-key",
+	}
+	added := []service.Repository{{URL: "http://example.com/vendor", Arch: "x86_64"}}
+	key := service.Key{Fingerprint: "0123456789ABCDEF0123456789ABCDEF01234567"}
+
+	t.Run("imports key and adds sources", func(t *testing.T) {
+		repo := &mockRepoService{namedRepos: []service.NamedRepository{named}, addResult: added}
+		actions := newTestActions(repo, nil)
+		keys := &mockKeyService{added: []service.Key{key}}
+		actions.serviceKeys = keys
+
+		resp, err := actions.AddNamed(context.Background(), "vendor")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if keys.addedAs != "vendor-key" {
+			t.Errorf("key imported as %q, want vendor-key", keys.addedAs)
+		}
+		if len(resp.Added) != 1 || len(resp.Keys) != 1 {
+			t.Errorf("unexpected response: %+v", resp)
+		}
+		if repo.backupCalls != 1 {
+			t.Errorf("expected sources backup, got %d", repo.backupCalls)
+		}
+	})
+
+	t.Run("skips registered vendor", func(t *testing.T) {
+		repo := &mockRepoService{namedRepos: []service.NamedRepository{named}, addResult: added}
+		actions := newTestActions(repo, nil)
+		keys := &mockKeyService{vendors: []service.Vendor{{Name: "vendor-key"}}}
+		actions.serviceKeys = keys
+
+		resp, err := actions.AddNamed(context.Background(), "vendor")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if keys.addedAs != "" || len(resp.Keys) != 0 {
+			t.Error("existing vendor key must not be imported again")
+		}
+	})
+
+	t.Run("already enabled", func(t *testing.T) {
+		repo := &mockRepoService{namedRepos: []service.NamedRepository{named}}
+		actions := newTestActions(repo, nil)
+		actions.serviceKeys = &mockKeyService{vendors: []service.Vendor{{Name: "vendor-key"}}}
+
+		_, err := actions.AddNamed(context.Background(), "vendor")
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNoOperation)
+	})
+
+	t.Run("unknown name", func(t *testing.T) {
+		actions := newTestActions(&mockRepoService{namedRepos: []service.NamedRepository{named}}, nil)
+
+		_, err := actions.AddNamed(context.Background(), "missing")
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNotFound)
+	})
+}
+
+func TestTaskInfo(t *testing.T) {
+	info := &service.TaskInfo{ID: "370123", State: "BUILDING", Subtasks: []service.TaskSubtask{{ID: "100", Package: "vim"}}}
+
+	t.Run("packages unavailable while building", func(t *testing.T) {
+		repo := &mockRepoService{taskInfos: []*service.TaskInfo{info}, taskPackagesErr: errors.New("still building")}
+		actions := newTestActions(repo, nil)
+
+		resp, err := actions.TaskInfo(context.Background(), "370123")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Task.State != "BUILDING" || resp.Count != 0 || resp.Packages == nil {
+			t.Errorf("unexpected response: %+v", resp)
+		}
+	})
+
+	t.Run("unknown task", func(t *testing.T) {
+		actions := newTestActions(nil, nil)
+
+		_, err := actions.TaskInfo(context.Background(), "1")
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeRepository)
+	})
+}
+
+func TestTaskWatch(t *testing.T) {
+	t.Run("reports state changes until done", func(t *testing.T) {
+		repo := &mockRepoService{taskInfos: []*service.TaskInfo{
+			{ID: "370123", State: "BUILDING"},
+			nil,
+			{ID: "370123", State: "BUILDING"},
+			{ID: "370123", State: service.TaskStateDone},
+		}}
+		actions := newTestActions(repo, nil)
+
+		var states []string
+		info, err := actions.waitTask(context.Background(), "370123", time.Millisecond, func(change TaskWatchChange) {
+			states = append(states, change.State)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.State != service.TaskStateDone || strings.Join(states, ",") != "BUILDING,DONE" {
+			t.Errorf("unexpected states %v, final %s", states, info.State)
+		}
+	})
+
+	t.Run("failed task stops watching", func(t *testing.T) {
+		repo := &mockRepoService{taskInfos: []*service.TaskInfo{{ID: "370123", State: service.TaskStateFailed}}}
+		actions := newTestActions(repo, nil)
+
+		_, err := actions.TaskWatch(context.Background(), "370123", 0, false, false, func(TaskWatchChange) {})
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeRepository)
+	})
+
+	t.Run("install adds repository and installs packages", func(t *testing.T) {
+		repo := &mockRepoService{
+			taskInfos:          []*service.TaskInfo{{ID: "370123", State: service.TaskStateTested}},
+			taskPackagesResult: []string{"vim"},
+			addResult:          []service.Repository{{URL: "http://git.altlinux.org/repo/370123/", Arch: "x86_64", Components: []string{"task"}, Active: true}},
+		}
+		apt := &mockAptActions{
+			findInstall: []string{"vim"},
+			findChanges: &aptLib.PackageChanges{NewInstalledCount: 1},
+		}
+		actions := newTestActions(repo, apt)
+
+		resp, err := actions.TaskWatch(context.Background(), "370123", 0, false, true, func(TaskWatchChange) {})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Added) != 1 || resp.Installed == nil || resp.Installed.NewInstalledCount != 1 {
+			t.Errorf("unexpected response: %+v", resp)
+		}
+	})
+
+	t.Run("interval below minimum", func(t *testing.T) {
+		actions := newTestActions(nil, nil)
+
+		_, err := actions.TaskWatch(context.Background(), "370123", time.Second, false, false, func(TaskWatchChange) {})
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+	})
+}
+
+func TestMigrate(t *testing.T) {
+	t.Run("simulation does not back up sources", func(t *testing.T) {
+		repo := &mockRepoService{migration: &service.Migration{
+			Changes:        []service.MigrationChange{{File: "/etc/apt/sources.list", Old: "rpm [p11] http://ftp.altlinux.org/pub/distributions/ALTLinux p11/branch/x86_64 classic"}},
+			PriorityBranch: "p11",
+		}}
+		actions := newTestActions(repo, nil)
+
+		resp, err := actions.Migrate(context.Background(), true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !resp.Simulate || len(resp.Changes) != 1 || repo.backupCalls != 0 || !*repo.migrateSimulate {
+			t.Errorf("unexpected simulation: %+v, backups %d", resp, repo.backupCalls)
+		}
+	})
+
+	t.Run("nothing to migrate", func(t *testing.T) {
+		repo := &mockRepoService{}
+		actions := newTestActions(repo, nil)
+
+		_, err := actions.Migrate(context.Background(), false)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNoOperation)
+		if repo.backupCalls != 1 {
+			t.Errorf("expected sources backup before migration, got %d", repo.backupCalls)
+		}
+	})
+}
+
+func TestAptRepoList(t *testing.T) {
+	repos := []service.Repository{
+		{Entry: "rpm [p11] http://ftp.altlinux.org/pub/distributions/ALTLinux/p11/branch x86_64 classic", Active: true},
+		{Entry: "rpm [p11] http://ftp.altlinux.org/pub/distributions/ALTLinux/p11/branch x86_64-i586 classic"},
+	}
+
+	want := "rpm [p11] http://ftp.altlinux.org/pub/distributions/ALTLinux/p11/branch x86_64 classic\n" +
+		"#rpm [p11] http://ftp.altlinux.org/pub/distributions/ALTLinux/p11/branch x86_64-i586 classic\n"
+	if got := aptRepoList(repos); got != want {
+		t.Errorf("aptRepoList() = %q, want %q", got, want)
+	}
+}
+
+func TestMutatingActionsTakeOperationLock(t *testing.T) {
+	repo := &mockRepoService{addResult: []service.Repository{{Entry: "rpm http://example.org/repo x86_64 classic"}}}
+	actions := newTestActions(repo, nil)
+	lockPath := filepath.Join(t.TempDir(), "apm.lock")
+	actions.operationLock = oplock.New(lockPath)
+
+	// Блокировку держит другой процесс apm
+	_, release, err := oplock.New(lockPath).Acquire(context.Background(), journal.ActionInstall)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	calls := map[string]func() error{
+		"add":    func() error { _, err := actions.Add(context.Background(), []string{"sisyphus"}, ""); return err },
+		"remove": func() error { _, err := actions.Remove(context.Background(), []string{"sisyphus"}, ""); return err },
+		"set":    func() error { _, err := actions.Set(context.Background(), "sisyphus", ""); return err },
+		"clean":  func() error { _, err := actions.Clean(context.Background()); return err },
+		"key":    func() error { _, err := actions.KeyAdd(context.Background(), "/tmp/key.asc", "", ""); return err },
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			testutil.AssertAPMError(t, call(), apmerr.ErrorTypeBusy)
+		})
+	}
+}
@@ -20,8 +20,11 @@ import (
 	"apm/internal/common/app"
 	apmcli "apm/internal/common/cli"
 	"apm/internal/common/reply"
+	"apm/internal/domain/repository/service"
 	"context"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/urfave/cli/v3"
 )
@@ -32,15 +35,42 @@ func newErrorResponseFromError(err error) reply.APIResponse {
 	return reply.ErrorResponseFromError(err)
 }
 
-// completeBranches возвращает функцию автодополнения для веток.
+// completeBranches возвращает функцию автодополнения для веток. Ветка дополняется только
+// первым аргументом и не предлагается в качестве значения флагов.
 func completeBranches(appConfig *app.Config, reporter *reply.Reporter) func(ctx context.Context, cmd *cli.Command) {
-	return func(_ context.Context, _ *cli.Command) {
+	return func(ctx context.Context, cmd *cli.Command) {
+		if apmcli.CompleteFlags(ctx, cmd) || apmcli.CompletingFlagValue(cmd) || cmd.NArg() > 1 {
+			return
+		}
+		current, _ := apmcli.CompletionToken()
 		actions := NewActions(appConfig, reporter)
-		branches := actions.repoService.GetBranches()
-		for _, branch := range branches {
-			fmt.Println(branch)
+		if cmd.Bool("named") {
+			repos, _ := actions.repoService.NamedRepositories()
+			names := make([]string, 0, len(repos))
+			for _, repo := range repos {
+				names = append(names, repo.Name)
+			}
+			apmcli.PrintCompletions(current, names)
+			return
+		}
+		apmcli.PrintCompletions(current, actions.repoService.GetBranches())
+	}
+}
+
+// namedAddResponse подключает или симулирует подключение репозитория из реестра
+func namedAddResponse(ctx context.Context, cmd *cli.Command, actions *Actions, reporter *reply.Reporter) error {
+	if cmd.Bool("simulate") {
+		resp, err := actions.CheckAddNamed(ctx, cmd.Args().First())
+		if err != nil {
+			return reporter.CliResponse(ctx, newErrorResponseFromError(err))
 		}
+		return reporter.CliResponse(ctx, reply.OK(resp))
+	}
+	resp, err := actions.AddNamed(ctx, cmd.Args().First())
+	if err != nil {
+		return reporter.CliResponse(ctx, newErrorResponseFromError(err))
 	}
+	return reporter.CliResponse(ctx, reply.OK(resp))
 }
 
 // CommandList возвращает команду repo со всеми подкомандами.
@@ -56,8 +86,8 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 		Commands: []*cli.Command{
 			{
 				Name:      "list",
-				Usage:     app.T_("List repositories"),
-				ArgsUsage: "[-a]",
+				Usage:     app.T_("List repositories. With the global --verbose flag, task metadata is shown"),
+				ArgsUsage: "[-a] [--format apt-repo]",
 				Flags: []cli.Flag{
 					&cli.BoolFlag{
 						Name:    "all",
@@ -70,24 +100,39 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 						Usage: app.T_("Full information output"),
 						Value: false,
 					},
+					apmcli.ColumnsFlag(),
 				},
 				Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+					columns, err := apmcli.ApplyColumns(appConfig, cmd.StringSlice("columns"), service.Repository{})
+					if err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+
 					resp, err := actions.List(ctx, cmd.Bool("all"))
 					if err != nil {
 						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
 					}
-					full := cmd.Bool("full")
+					if appConfig.ConfigManager.GetConfig().Format == formatAptRepo {
+						reply.StopSpinner(appConfig)
+						fmt.Print(aptRepoList(resp.Repositories))
+						return nil
+					}
+					full := cmd.Bool("full") || columns
+					repos := resp.Repositories
+					if !cmd.Bool("verbose") {
+						repos = withoutTaskInfo(repos)
+					}
 					return reporter.CliResponse(ctx, reply.OK(map[string]interface{}{
 						"message":      reply.MessageWithHint(resp.Message, full),
 						"count":        resp.Count,
-						"repositories": FormatRepoOutput(resp.Repositories, full),
+						"repositories": FormatRepoOutput(repos, full),
 					}))
 				}),
 			},
 			{
 				Name:      "add",
-				Usage:     app.T_("Add repository (branch/task/URL)"),
-				ArgsUsage: "<source> [arch] [components...]",
+				Usage:     app.T_("Add repository (branch/task/URL). Variables $(ARCH), $(BRANCH) and $(DATE) are expanded"),
+				ArgsUsage: "<source|file.list|file.repo|name> [arch] [components...]",
 				Flags: []cli.Flag{
 					&cli.StringFlag{
 						Name:    "date",
@@ -99,9 +144,16 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 						Usage:   app.T_("Simulate adding without making changes"),
 						Aliases: []string{"s"},
 					},
+					&cli.BoolFlag{
+						Name:  "named",
+						Usage: app.T_("Enable a repository from the registry by name, including its key (see 'apm repo available')"),
+					},
 				},
 				Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
 					args := cmd.Args().Slice()
+					if cmd.Bool("named") {
+						return namedAddResponse(ctx, cmd, actions, reporter)
+					}
 					if cmd.Bool("simulate") {
 						resp, err := actions.CheckAdd(ctx, args, cmd.String("date"))
 						if err != nil {
@@ -209,6 +261,248 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 					return reporter.CliResponse(ctx, reply.OK(resp))
 				}),
 			},
+			{
+				Name:      "restore",
+				Usage:     app.T_("Restore repository sources from a backup taken before changes. Without arguments, the latest backup is used"),
+				ArgsUsage: "[backup]",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:    "list",
+						Usage:   app.T_("List available backups"),
+						Aliases: []string{"l"},
+					},
+					&cli.BoolFlag{
+						Name:    "simulate",
+						Usage:   app.T_("Simulate restoring without making changes"),
+						Aliases: []string{"s"},
+					},
+				},
+				Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+					if cmd.Bool("list") {
+						resp, err := actions.Backups(ctx)
+						if err != nil {
+							return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+						}
+						return reporter.CliResponse(ctx, reply.OK(resp))
+					}
+					if cmd.Bool("simulate") {
+						resp, err := actions.CheckRestore(ctx, cmd.Args().First())
+						if err != nil {
+							return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+						}
+						return reporter.CliResponse(ctx, reply.OK(resp))
+					}
+					resp, err := actions.Restore(ctx, cmd.Args().First())
+					if err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+					return reporter.CliResponse(ctx, reply.OK(resp))
+				}),
+			},
+			{
+				Name:            "arepo",
+				Usage:           app.T_("Manage the x86_64-i586 (biarch) repository"),
+				HideHelpCommand: true,
+				Commands: []*cli.Command{
+					{
+						Name:  "status",
+						Usage: app.T_("Show whether arepo is enabled and which biarch packages are installed"),
+						Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+							resp, err := actions.ArepoStatus(ctx)
+							if err != nil {
+								return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+							}
+							return reporter.CliResponse(ctx, reply.OK(resp))
+						}),
+					},
+					{
+						Name:  "enable",
+						Usage: app.T_("Enable arepo and add x86_64-i586 sources to connected branches"),
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:    "simulate",
+								Usage:   app.T_("Simulate enabling without making changes"),
+								Aliases: []string{"s"},
+							},
+						},
+						Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+							if cmd.Bool("simulate") {
+								resp, err := actions.CheckArepoEnable(ctx)
+								if err != nil {
+									return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+								}
+								return reporter.CliResponse(ctx, reply.OK(resp))
+							}
+							resp, err := actions.ArepoEnable(ctx)
+							if err != nil {
+								return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+							}
+							return reporter.CliResponse(ctx, reply.OK(resp))
+						}),
+					},
+					{
+						Name:  "disable",
+						Usage: app.T_("Disable arepo and remove x86_64-i586 sources of connected branches"),
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "force",
+								Usage: app.T_("Disable even if installed packages depend on arepo"),
+								Value: false,
+							},
+							&cli.BoolFlag{
+								Name:    "simulate",
+								Usage:   app.T_("Simulate disabling without making changes"),
+								Aliases: []string{"s"},
+							},
+						},
+						Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+							if cmd.Bool("simulate") {
+								resp, err := actions.CheckArepoDisable(ctx, cmd.Bool("force"))
+								if err != nil {
+									return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+								}
+								return reporter.CliResponse(ctx, reply.OK(resp))
+							}
+							resp, err := actions.ArepoDisable(ctx, cmd.Bool("force"))
+							if err != nil {
+								return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+							}
+							return reporter.CliResponse(ctx, reply.OK(resp))
+						}),
+					},
+				},
+			},
+			{
+				Name:            "key",
+				Aliases:         []string{"keys"},
+				Usage:           app.T_("Manage GPG keys used to verify repositories"),
+				HideHelpCommand: true,
+				Commands: []*cli.Command{
+					{
+						Name:  "list",
+						Usage: app.T_("List keys in the APT keyring"),
+						Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+							resp, err := actions.KeyList(ctx)
+							if err != nil {
+								return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+							}
+							return reporter.CliResponse(ctx, reply.OK(resp))
+						}),
+					},
+					{
+						Name:      "add",
+						Usage:     app.T_("Import key from file, https URL or keyserver. The name is used as [name] in repository sources"),
+						ArgsUsage: "<file|url|fingerprint>",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "keyserver",
+								Usage: app.T_("Keyserver to receive the key fingerprint from"),
+							},
+							&cli.StringFlag{
+								Name:  "name",
+								Usage: app.T_("Key name for repository sources, defaults to the file name or key ID"),
+							},
+							&cli.BoolFlag{
+								Name:    "simulate",
+								Usage:   app.T_("Simulate importing without making changes"),
+								Aliases: []string{"s"},
+							},
+						},
+						Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+							if cmd.Bool("simulate") {
+								resp, err := actions.CheckKeyAdd(ctx, cmd.Args().First(), cmd.String("keyserver"), cmd.String("name"))
+								if err != nil {
+									return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+								}
+								return reporter.CliResponse(ctx, reply.OK(resp))
+							}
+							resp, err := actions.KeyAdd(ctx, cmd.Args().First(), cmd.String("keyserver"), cmd.String("name"))
+							if err != nil {
+								return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+							}
+							return reporter.CliResponse(ctx, reply.OK(resp))
+						}),
+					},
+					{
+						Name:      "remove",
+						Aliases:   []string{"rm"},
+						Usage:     app.T_("Remove key from the APT keyring"),
+						ArgsUsage: "<fingerprint|key-id|name>",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:    "simulate",
+								Usage:   app.T_("Simulate removal without making changes"),
+								Aliases: []string{"s"},
+							},
+						},
+						Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+							if cmd.Bool("simulate") {
+								resp, err := actions.CheckKeyRemove(ctx, cmd.Args().First())
+								if err != nil {
+									return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+								}
+								return reporter.CliResponse(ctx, reply.OK(resp))
+							}
+							resp, err := actions.KeyRemove(ctx, cmd.Args().First())
+							if err != nil {
+								return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+							}
+							return reporter.CliResponse(ctx, reply.OK(resp))
+						}),
+					},
+					{
+						Name:  "verify",
+						Usage: app.T_("Check that keys of active repositories are present in the keyring"),
+						Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+							resp, err := actions.KeyVerify(ctx)
+							if err != nil {
+								return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+							}
+							return reporter.CliResponse(ctx, reply.OK(resp))
+						}),
+					},
+				},
+			},
+			{
+				Name:  "migrate",
+				Usage: app.T_("Convert sources written by apt-repo to the apm format, including priority macros"),
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:    "simulate",
+						Usage:   app.T_("Show the changes without applying them"),
+						Aliases: []string{"s"},
+					},
+				},
+				Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+					resp, err := actions.Migrate(ctx, cmd.Bool("simulate"))
+					if err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+					return reporter.CliResponse(ctx, reply.OK(resp))
+				}),
+			},
+			{
+				Name:  "available",
+				Usage: app.T_("List well-known third-party repositories that can be enabled with 'apm repo add --named'"),
+				Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+					resp, err := actions.Available(ctx)
+					if err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+					return reporter.CliResponse(ctx, reply.OK(resp))
+				}),
+			},
+			{
+				Name:  "check",
+				Usage: app.T_("Check configured repositories and report problems by severity"),
+				Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+					resp, err := actions.Health(ctx)
+					if err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+					return reporter.CliResponse(ctx, reply.OK(resp))
+				}),
+			},
 			{
 				Name:  "branches",
 				Usage: app.T_("List available branches"),
@@ -231,6 +525,78 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 					}
 					return reporter.CliResponse(ctx, reply.OK(resp))
 				}),
+				Commands: []*cli.Command{
+					{
+						Name:      "info",
+						Usage:     app.T_("Show task status, subtasks and built packages"),
+						ArgsUsage: "<task_number>",
+						Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+							resp, err := actions.TaskInfo(ctx, cmd.Args().First())
+							if err != nil {
+								return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+							}
+							return reporter.CliResponse(ctx, reply.OK(resp))
+						}),
+					},
+					{
+						Name:      "watch",
+						Usage:     app.T_("Wait until the task is DONE or TESTED, reporting status changes"),
+						ArgsUsage: "<task_number>",
+						Flags: []cli.Flag{
+							&cli.IntFlag{
+								Name:  "interval",
+								Usage: app.T_("Interval between checks in seconds"),
+								Value: 60,
+							},
+							&cli.BoolFlag{
+								Name:  "add",
+								Usage: app.T_("Add the task repository when the task is done"),
+							},
+							&cli.BoolFlag{
+								Name:  "install",
+								Usage: app.T_("Add the task repository and install its packages when the task is done"),
+							},
+						},
+						Action: func(ctx context.Context, cmd *cli.Command) error {
+							watch := func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+								isText := appConfig.ConfigManager.GetConfig().Format == app.FormatText
+								interval := time.Duration(cmd.Int("interval")) * time.Second
+
+								resp, err := actions.TaskWatch(ctx, cmd.Args().First(), interval, cmd.Bool("add"), cmd.Bool("install"), func(change TaskWatchChange) {
+									reply.StopSpinner(appConfig)
+									if !isText {
+										if data, errMarshal := json.Marshal(change); errMarshal == nil {
+											fmt.Println(string(data))
+										}
+										return
+									}
+									fmt.Printf("[%s] %s\n", change.Date, change.Message)
+								})
+								if err != nil {
+									return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+								}
+								return reporter.CliResponse(ctx, reply.OK(resp))
+							}
+							// Подключение репозитория и установка пакетов требуют прав root
+							if cmd.Bool("add") || cmd.Bool("install") {
+								return withRootCheckWrapper(watch)(ctx, cmd)
+							}
+							return withGlobalWrapper(watch)(ctx, cmd)
+						},
+					},
+					{
+						Name:      "test",
+						Usage:     app.T_("Check that packages from task can be installed without changing the system"),
+						ArgsUsage: "<task_number>",
+						Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+							resp, err := actions.VerifyTask(ctx, cmd.Args().First())
+							if err != nil {
+								return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+							}
+							return reporter.CliResponse(ctx, reply.OK(resp))
+						}),
+					},
+				},
 			},
 			{
 				Name:      "test",
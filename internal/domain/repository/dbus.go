@@ -52,10 +52,17 @@ func NewDBusWrapper(a *Actions, c *dbus.Conn, ctx context.Context) *DBusWrapper
 	return &DBusWrapper{actions: a, conn: c, ctx: ctx}
 }
 
-// checkManagePermission проверяет права org.altlinux.APM.manage
-func (w *DBusWrapper) checkManagePermission(sender dbus.Sender) *dbus.Error {
-	if err := helper.PolkitCheck(w.conn, sender, "org.altlinux.APM.manage"); err != nil {
-		return dbus.MakeFailedError(err)
+// WithLocale возвращает копию обёртки, формирующую сообщения на языке клиента lang
+func (w *DBusWrapper) WithLocale(lang string) any {
+	localized := *w
+	localized.ctx = app.WithLocale(w.ctx, lang)
+	return &localized
+}
+
+// checkPermission проверяет права на действие Polkit action
+func (w *DBusWrapper) checkPermission(sender dbus.Sender, action string) *dbus.Error {
+	if err := helper.PolkitCheck(w.conn, sender, action); err != nil {
+		return apmerr.DBusError(apmerr.New(apmerr.ErrorTypePermission, err))
 	}
 	return nil
 }
@@ -76,7 +83,7 @@ func (w *DBusWrapper) List(all bool, transaction string) (string, *dbus.Error) {
 
 // Add добавляет репозиторий.
 func (w *DBusWrapper) Add(sender dbus.Sender, source, date, transaction string) (string, *dbus.Error) {
-	if err := w.checkManagePermission(sender); err != nil {
+	if err := w.checkPermission(sender, helper.PolkitActionRepoManage); err != nil {
 		return "", err
 	}
 	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
@@ -92,9 +99,57 @@ func (w *DBusWrapper) Add(sender dbus.Sender, source, date, transaction string)
 	return string(data), nil
 }
 
+// AddNamed подключает репозиторий из реестра вместе с его ключом.
+func (w *DBusWrapper) AddNamed(sender dbus.Sender, name, transaction string) (string, *dbus.Error) {
+	if err := w.checkPermission(sender, helper.PolkitActionRepoManage); err != nil {
+		return "", err
+	}
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.AddNamed(ctx, name)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// Migrate переводит источники apt-repo под управление apm.
+func (w *DBusWrapper) Migrate(sender dbus.Sender, simulate bool, transaction string) (string, *dbus.Error) {
+	if err := w.checkPermission(sender, helper.PolkitActionRepoManage); err != nil {
+		return "", err
+	}
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.Migrate(ctx, simulate)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// Available возвращает реестр именованных репозиториев.
+func (w *DBusWrapper) Available(transaction string) (string, *dbus.Error) {
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.Available(ctx)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
 // Remove удаляет репозиторий.
 func (w *DBusWrapper) Remove(sender dbus.Sender, source, date, transaction string) (string, *dbus.Error) {
-	if err := w.checkManagePermission(sender); err != nil {
+	if err := w.checkPermission(sender, helper.PolkitActionRepoManage); err != nil {
 		return "", err
 	}
 	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
@@ -112,7 +167,7 @@ func (w *DBusWrapper) Remove(sender dbus.Sender, source, date, transaction strin
 
 // Set устанавливает ветку репозитория.
 func (w *DBusWrapper) Set(sender dbus.Sender, branch, date, transaction string) (string, *dbus.Error) {
-	if err := w.checkManagePermission(sender); err != nil {
+	if err := w.checkPermission(sender, helper.PolkitActionRepoManage); err != nil {
 		return "", err
 	}
 	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
@@ -129,7 +184,7 @@ func (w *DBusWrapper) Set(sender dbus.Sender, branch, date, transaction string)
 
 // Clean удаляет cdrom-источники из репозиториев.
 func (w *DBusWrapper) Clean(sender dbus.Sender, transaction string) (string, *dbus.Error) {
-	if err := w.checkManagePermission(sender); err != nil {
+	if err := w.checkPermission(sender, helper.PolkitActionRepoManage); err != nil {
 		return "", err
 	}
 	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
@@ -171,6 +226,20 @@ func (w *DBusWrapper) GetTaskPackages(taskNum string, transaction string) (strin
 	return string(data), nil
 }
 
+// TaskInfo возвращает состояние, подзадачи и пакеты задачи.
+func (w *DBusWrapper) TaskInfo(taskNum string, transaction string) (string, *dbus.Error) {
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.TaskInfo(ctx, taskNum)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
 // CheckAdd симулирует добавление репозитория.
 func (w *DBusWrapper) CheckAdd(source, date, transaction string) (string, *dbus.Error) {
 	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
@@ -228,3 +297,110 @@ func (w *DBusWrapper) CheckClean(transaction string) (string, *dbus.Error) {
 	}
 	return string(data), nil
 }
+
+// Backups возвращает снимки источников.
+func (w *DBusWrapper) Backups(transaction string) (string, *dbus.Error) {
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.Backups(ctx)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// Restore восстанавливает источники из снимка. Пустой id - последний снимок.
+func (w *DBusWrapper) Restore(sender dbus.Sender, id, transaction string) (string, *dbus.Error) {
+	if err := w.checkPermission(sender, helper.PolkitActionRepoManage); err != nil {
+		return "", err
+	}
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.Restore(ctx, id)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// KeyList возвращает ключи связки APT.
+func (w *DBusWrapper) KeyList(transaction string) (string, *dbus.Error) {
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.KeyList(ctx)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// KeyAdd импортирует ключ из файла, по URL или с сервера ключей.
+func (w *DBusWrapper) KeyAdd(sender dbus.Sender, source, keyserver, name, transaction string) (string, *dbus.Error) {
+	if err := w.checkPermission(sender, helper.PolkitActionRepoManage); err != nil {
+		return "", err
+	}
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.KeyAdd(ctx, source, keyserver, name)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// KeyRemove удаляет ключ по отпечатку, идентификатору или имени.
+func (w *DBusWrapper) KeyRemove(sender dbus.Sender, id, transaction string) (string, *dbus.Error) {
+	if err := w.checkPermission(sender, helper.PolkitActionRepoManage); err != nil {
+		return "", err
+	}
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.KeyRemove(ctx, id)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// KeyVerify проверяет наличие ключей активных репозиториев.
+func (w *DBusWrapper) KeyVerify(transaction string) (string, *dbus.Error) {
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.KeyVerify(ctx)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// Health проверяет активные репозитории и возвращает отчёт о проблемах.
+func (w *DBusWrapper) Health(transaction string) (string, *dbus.Error) {
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.Health(ctx)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,119 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package repository
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/domain/repository/service"
+	"context"
+	"fmt"
+	"sort"
+)
+
+// severityOrder порядок вывода проблем: сначала ошибки
+var severityOrder = map[string]int{
+	service.SeverityError:   0,
+	service.SeverityWarning: 1,
+	service.SeverityInfo:    2,
+}
+
+// Health проверяет все активные источники и возвращает отчёт с проблемами, отсортированными по серьёзности.
+func (a *Actions) Health(ctx context.Context) (*RepoHealthResponse, error) {
+	repos, err := a.repoService.GetRepositories(ctx, false)
+	if err != nil {
+		return nil, newRepoError(err)
+	}
+
+	issues := a.repoService.CheckHealth(ctx, repos)
+	if ctx.Err() != nil {
+		return nil, newRepoError(ctx.Err())
+	}
+
+	keyIssues, err := a.keyHealth(ctx, repos)
+	if err != nil {
+		return nil, newRepoError(err)
+	}
+	issues = append(issues, keyIssues...)
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return severityOrder[issues[i].Severity] < severityOrder[issues[j].Severity]
+	})
+
+	resp := &RepoHealthResponse{
+		Checked: len(repos),
+		Issues:  issues,
+	}
+	if resp.Issues == nil {
+		resp.Issues = []service.HealthIssue{}
+	}
+	for _, issue := range issues {
+		switch issue.Severity {
+		case service.SeverityError:
+			resp.Errors++
+		case service.SeverityWarning:
+			resp.Warnings++
+		}
+	}
+
+	switch {
+	case resp.Errors > 0:
+		resp.Message = fmt.Sprintf(app.TLN_(ctx, "%d problem found in repositories", "%d problems found in repositories", resp.Errors), resp.Errors)
+	case resp.Warnings > 0:
+		resp.Message = fmt.Sprintf(app.TLN_(ctx, "Repositories work, %d warning", "Repositories work, %d warnings", resp.Warnings), resp.Warnings)
+	default:
+		resp.Message = app.TL_(ctx, "All repositories are healthy")
+	}
+	return resp, nil
+}
+
+// keyHealth переводит результаты проверки ключей в проблемы отчёта. Неподписанные задачи
+// сборочницы считаются нормой.
+func (a *Actions) keyHealth(ctx context.Context, repos []service.Repository) ([]service.HealthIssue, error) {
+	checks, err := a.serviceKeys.VerifyKeys(ctx, repos)
+	if err != nil {
+		return nil, err
+	}
+
+	byEntry := make(map[string]service.Repository, len(repos))
+	for _, repo := range repos {
+		byEntry[repo.Entry] = repo
+	}
+
+	var issues []service.HealthIssue
+	for _, check := range checks {
+		repo := byEntry[check.Entry]
+		issue := service.HealthIssue{Check: service.HealthKey, Entry: check.Entry, File: repo.File}
+		switch check.Status {
+		case service.KeyStatusMissingVendor:
+			issue.Severity = service.SeverityError
+			issue.Message = fmt.Sprintf(app.TL_(ctx, "Signing key %s is not described in vendors.list"), check.Vendor)
+		case service.KeyStatusMissingKey:
+			issue.Severity = service.SeverityError
+			issue.Message = fmt.Sprintf(app.TL_(ctx, "Key %s of %s is not in the keyring"), check.Fingerprint, check.Vendor)
+		case service.KeyStatusUnsigned:
+			issue.Severity = service.SeverityWarning
+			if repo.Branch == "task" {
+				issue.Severity = service.SeverityInfo
+			}
+			issue.Message = app.TL_(ctx, "Repository is not signed")
+		default:
+			continue
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
@@ -92,7 +92,14 @@ func (w *HTTPWrapper) Add(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := w.CtxWithTransaction(r)
+	if w.RunBackground(rw, r, reply.EventRepoAdd, func(ctx context.Context) (interface{}, error) {
+		return w.actions.Add(ctx, []string{source}, date)
+	}) {
+		return
+	}
+
+	ctx, cancel := w.CtxWithRequestCancel(r)
+	defer cancel()
 	resp, err := w.actions.Add(ctx, []string{source}, date)
 	if err != nil {
 		reply.WriteHTTPError(rw, err)
@@ -101,6 +108,51 @@ func (w *HTTPWrapper) Add(rw http.ResponseWriter, r *http.Request) {
 	w.WriteJSON(rw, reply.OK(resp))
 }
 
+// AddNamed подключает репозиторий из реестра вместе с его ключом.
+func (w *HTTPWrapper) AddNamed(rw http.ResponseWriter, r *http.Request) {
+	body, err := w.ParseBodyParams(r)
+	if err != nil {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
+		return
+	}
+
+	var name string
+	if err = reply.UnmarshalField(body, "name", &name); err != nil {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
+		return
+	}
+	if name == "" {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, errors.New("name is required")))
+		return
+	}
+
+	if w.RunBackground(rw, r, reply.EventRepoAdd, func(ctx context.Context) (interface{}, error) {
+		return w.actions.AddNamed(ctx, name)
+	}) {
+		return
+	}
+
+	ctx, cancel := w.CtxWithRequestCancel(r)
+	defer cancel()
+	resp, err := w.actions.AddNamed(ctx, name)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// Available возвращает реестр именованных репозиториев.
+func (w *HTTPWrapper) Available(rw http.ResponseWriter, r *http.Request) {
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.Available(ctx)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
 // CheckAdd симулирует добавление репозитория.
 func (w *HTTPWrapper) CheckAdd(rw http.ResponseWriter, r *http.Request) {
 	body, err := w.ParseBodyParams(r)
@@ -129,7 +181,8 @@ func (w *HTTPWrapper) CheckAdd(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := w.CtxWithTransaction(r)
+	ctx, cancel := w.CtxWithRequestCancel(r)
+	defer cancel()
 	resp, err := w.actions.CheckAdd(ctx, []string{source}, date)
 	if err != nil {
 		reply.WriteHTTPError(rw, err)
@@ -166,7 +219,14 @@ func (w *HTTPWrapper) Remove(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := w.CtxWithTransaction(r)
+	if w.RunBackground(rw, r, reply.EventRepoRemove, func(ctx context.Context) (interface{}, error) {
+		return w.actions.Remove(ctx, []string{source}, date)
+	}) {
+		return
+	}
+
+	ctx, cancel := w.CtxWithRequestCancel(r)
+	defer cancel()
 	resp, err := w.actions.Remove(ctx, []string{source}, date)
 	if err != nil {
 		reply.WriteHTTPError(rw, err)
@@ -240,7 +300,14 @@ func (w *HTTPWrapper) Set(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := w.CtxWithTransaction(r)
+	if w.RunBackground(rw, r, reply.EventRepoSet, func(ctx context.Context) (interface{}, error) {
+		return w.actions.Set(ctx, branch, date)
+	}) {
+		return
+	}
+
+	ctx, cancel := w.CtxWithRequestCancel(r)
+	defer cancel()
 	resp, err := w.actions.Set(ctx, branch, date)
 	if err != nil {
 		reply.WriteHTTPError(rw, err)
@@ -277,7 +344,8 @@ func (w *HTTPWrapper) CheckSet(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := w.CtxWithTransaction(r)
+	ctx, cancel := w.CtxWithRequestCancel(r)
+	defer cancel()
 	resp, err := w.actions.CheckSet(ctx, branch, date)
 	if err != nil {
 		reply.WriteHTTPError(rw, err)
@@ -288,6 +356,12 @@ func (w *HTTPWrapper) CheckSet(rw http.ResponseWriter, r *http.Request) {
 
 // Clean удаляет временные cdrom-источники.
 func (w *HTTPWrapper) Clean(rw http.ResponseWriter, r *http.Request) {
+	if w.RunBackground(rw, r, reply.EventRepoClean, func(ctx context.Context) (interface{}, error) {
+		return w.actions.Clean(ctx)
+	}) {
+		return
+	}
+
 	ctx := w.CtxWithTransaction(r)
 	resp, err := w.actions.Clean(ctx)
 	if err != nil {
@@ -323,7 +397,8 @@ func (w *HTTPWrapper) GetBranches(rw http.ResponseWriter, r *http.Request) {
 func (w *HTTPWrapper) GetTaskPackages(rw http.ResponseWriter, r *http.Request) {
 	taskNum := r.PathValue("taskNum")
 
-	ctx := w.CtxWithTransaction(r)
+	ctx, cancel := w.CtxWithRequestCancel(r)
+	defer cancel()
 	resp, err := w.actions.GetTaskPackages(ctx, taskNum)
 	if err != nil {
 		reply.WriteHTTPError(rw, err)
@@ -332,6 +407,20 @@ func (w *HTTPWrapper) GetTaskPackages(rw http.ResponseWriter, r *http.Request) {
 	w.WriteJSON(rw, reply.OK(resp))
 }
 
+// TaskInfo возвращает состояние, подзадачи и пакеты задачи.
+func (w *HTTPWrapper) TaskInfo(rw http.ResponseWriter, r *http.Request) {
+	taskNum := r.PathValue("taskNum")
+
+	ctx, cancel := w.CtxWithRequestCancel(r)
+	defer cancel()
+	resp, err := w.actions.TaskInfo(ctx, taskNum)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
 // TestTask тестирует пакеты задачи.
 func (w *HTTPWrapper) TestTask(rw http.ResponseWriter, r *http.Request) {
 	taskNum := r.PathValue("taskNum")
@@ -345,6 +434,186 @@ func (w *HTTPWrapper) TestTask(rw http.ResponseWriter, r *http.Request) {
 	w.WriteJSON(rw, reply.OK(resp))
 }
 
+// VerifyTask проверяет возможность установки пакетов задачи без изменения системы.
+func (w *HTTPWrapper) VerifyTask(rw http.ResponseWriter, r *http.Request) {
+	taskNum := r.PathValue("taskNum")
+
+	if w.RunBackground(rw, r, reply.EventRepoVerifyTask, func(ctx context.Context) (interface{}, error) {
+		return w.actions.VerifyTask(ctx, taskNum)
+	}) {
+		return
+	}
+
+	ctx, cancel := w.CtxWithRequestCancel(r)
+	defer cancel()
+	resp, err := w.actions.VerifyTask(ctx, taskNum)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// Backups возвращает снимки источников.
+func (w *HTTPWrapper) Backups(rw http.ResponseWriter, r *http.Request) {
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.Backups(ctx)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// Restore восстанавливает источники из снимка.
+func (w *HTTPWrapper) Restore(rw http.ResponseWriter, r *http.Request) {
+	body, err := w.ParseBodyParams(r)
+	if err != nil {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
+		return
+	}
+
+	var id string
+	if err = reply.UnmarshalField(body, "id", &id); err != nil {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
+		return
+	}
+
+	if w.RunBackground(rw, r, reply.EventRepoRestore, func(ctx context.Context) (interface{}, error) {
+		return w.actions.Restore(ctx, id)
+	}) {
+		return
+	}
+
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.Restore(ctx, id)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// Migrate переводит источники apt-repo под управление apm.
+func (w *HTTPWrapper) Migrate(rw http.ResponseWriter, r *http.Request) {
+	body, err := w.ParseBodyParams(r)
+	if err != nil {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
+		return
+	}
+
+	var simulate bool
+	if err = reply.UnmarshalField(body, "simulate", &simulate); err != nil {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
+		return
+	}
+
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.Migrate(ctx, simulate)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// KeyList возвращает ключи связки APT.
+func (w *HTTPWrapper) KeyList(rw http.ResponseWriter, r *http.Request) {
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.KeyList(ctx)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// KeyAdd импортирует ключ из файла, по URL или с сервера ключей.
+func (w *HTTPWrapper) KeyAdd(rw http.ResponseWriter, r *http.Request) {
+	body, err := w.ParseBodyParams(r)
+	if err != nil {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
+		return
+	}
+
+	var source, keyserver, name string
+
+	for _, f := range []struct {
+		key    string
+		target interface{}
+	}{
+		{"source", &source},
+		{"keyserver", &keyserver},
+		{"name", &name},
+	} {
+		if err = reply.UnmarshalField(body, f.key, f.target); err != nil {
+			reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
+			return
+		}
+	}
+
+	if source == "" {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, errors.New("source is required")))
+		return
+	}
+
+	if w.RunBackground(rw, r, reply.EventRepoKeyAdd, func(ctx context.Context) (interface{}, error) {
+		return w.actions.KeyAdd(ctx, source, keyserver, name)
+	}) {
+		return
+	}
+
+	ctx, cancel := w.CtxWithRequestCancel(r)
+	defer cancel()
+	resp, err := w.actions.KeyAdd(ctx, source, keyserver, name)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// KeyRemove удаляет ключ по отпечатку, идентификатору или имени.
+func (w *HTTPWrapper) KeyRemove(rw http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if w.RunBackground(rw, r, reply.EventRepoKeyRemove, func(ctx context.Context) (interface{}, error) {
+		return w.actions.KeyRemove(ctx, id)
+	}) {
+		return
+	}
+
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.KeyRemove(ctx, id)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// KeyVerify проверяет наличие ключей активных репозиториев.
+func (w *HTTPWrapper) KeyVerify(rw http.ResponseWriter, r *http.Request) {
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.KeyVerify(ctx)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// Health проверяет активные репозитории и возвращает отчёт о проблемах.
+func (w *HTTPWrapper) Health(rw http.ResponseWriter, r *http.Request) {
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.Health(ctx)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
 // GetEndpoints возвращает описания endpoints с handler
 func (w *HTTPWrapper) GetEndpoints() []http_server.Endpoint {
 	return []http_server.Endpoint{
@@ -372,6 +641,9 @@ func (w *HTTPWrapper) GetEndpoints() []http_server.Endpoint {
 				{Name: "source", Source: "body", Type: "string", ArgIndex: 1},
 				{Name: "date", Source: "body", Type: "string", Default: "", ArgIndex: 2},
 			},
+			QueryParams: []http_server.QueryParam{
+				{Name: "background", Type: "boolean", Required: false, Description: "Выполнить в фоне (результат придёт через WebSocket)"},
+			},
 		},
 		{
 			Handler:      w.CheckAdd,
@@ -386,6 +658,30 @@ func (w *HTTPWrapper) GetEndpoints() []http_server.Endpoint {
 				{Name: "date", Source: "body", Type: "string", Default: "", ArgIndex: 2},
 			},
 		},
+		{
+			Handler:      w.AddNamed,
+			HTTPMethod:   "POST",
+			HTTPPath:     "/api/v1/repo/named",
+			ResponseType: reflect.TypeOf(RepoAddRemoveResponse{}),
+			Permission:   http_server.PermManage,
+			Summary:      "Подключить репозиторий из реестра вместе с ключом",
+			Tags:         []string{"repo"},
+			ParamMappings: []http_server.ParamMapping{
+				{Name: "name", Source: "body", Type: "string", ArgIndex: 1},
+			},
+			QueryParams: []http_server.QueryParam{
+				{Name: "background", Type: "boolean", Required: false, Description: "Выполнить в фоне (результат придёт через WebSocket)"},
+			},
+		},
+		{
+			Handler:      w.Available,
+			HTTPMethod:   "GET",
+			HTTPPath:     "/api/v1/repo/available",
+			ResponseType: reflect.TypeOf(RepoAvailableResponse{}),
+			Permission:   http_server.PermRead,
+			Summary:      "Получить реестр известных сторонних репозиториев",
+			Tags:         []string{"repo"},
+		},
 		{
 			Handler:      w.Remove,
 			HTTPMethod:   "DELETE",
@@ -398,6 +694,9 @@ func (w *HTTPWrapper) GetEndpoints() []http_server.Endpoint {
 				{Name: "source", Source: "body", Type: "string", ArgIndex: 1},
 				{Name: "date", Source: "body", Type: "string", Default: "", ArgIndex: 2},
 			},
+			QueryParams: []http_server.QueryParam{
+				{Name: "background", Type: "boolean", Required: false, Description: "Выполнить в фоне (результат придёт через WebSocket)"},
+			},
 		},
 		{
 			Handler:      w.CheckRemove,
@@ -424,6 +723,9 @@ func (w *HTTPWrapper) GetEndpoints() []http_server.Endpoint {
 				{Name: "branch", Source: "body", Type: "string", ArgIndex: 1},
 				{Name: "date", Source: "body", Type: "string", Default: "", ArgIndex: 2},
 			},
+			QueryParams: []http_server.QueryParam{
+				{Name: "background", Type: "boolean", Required: false, Description: "Выполнить в фоне (результат придёт через WebSocket)"},
+			},
 		},
 		{
 			Handler:      w.CheckSet,
@@ -446,6 +748,9 @@ func (w *HTTPWrapper) GetEndpoints() []http_server.Endpoint {
 			Permission:   http_server.PermManage,
 			Summary:      "Удалить временные репозитории (cdrom, task)",
 			Tags:         []string{"repo"},
+			QueryParams: []http_server.QueryParam{
+				{Name: "background", Type: "boolean", Required: false, Description: "Выполнить в фоне (результат придёт через WebSocket)"},
+			},
 		},
 		{
 			Handler:      w.CheckClean,
@@ -475,6 +780,16 @@ func (w *HTTPWrapper) GetEndpoints() []http_server.Endpoint {
 			Tags:         []string{"repo"},
 			PathParams:   []string{"taskNum"},
 		},
+		{
+			Handler:      w.TaskInfo,
+			HTTPMethod:   "GET",
+			HTTPPath:     "/api/v1/repo/task/{taskNum}/info",
+			ResponseType: reflect.TypeOf(TaskInfoResponse{}),
+			Permission:   http_server.PermRead,
+			Summary:      "Получить состояние, подзадачи и пакеты задачи",
+			Tags:         []string{"repo"},
+			PathParams:   []string{"taskNum"},
+		},
 		{
 			Handler:      w.TestTask,
 			HTTPMethod:   "POST",
@@ -485,5 +800,111 @@ func (w *HTTPWrapper) GetEndpoints() []http_server.Endpoint {
 			Tags:         []string{"repo"},
 			PathParams:   []string{"taskNum"},
 		},
+		{
+			Handler:      w.VerifyTask,
+			HTTPMethod:   "POST",
+			HTTPPath:     "/api/v1/repo/task/{taskNum}/verify",
+			ResponseType: reflect.TypeOf(VerifyTaskResponse{}),
+			Permission:   http_server.PermManage,
+			Summary:      "Проверить установку пакетов задачи без изменения системы",
+			Tags:         []string{"repo"},
+			PathParams:   []string{"taskNum"},
+			QueryParams: []http_server.QueryParam{
+				{Name: "background", Type: "boolean", Required: false, Description: "Выполнить в фоне (результат придёт через WebSocket)"},
+			},
+		},
+		{
+			Handler:      w.Backups,
+			HTTPMethod:   "GET",
+			HTTPPath:     "/api/v1/repo/backups",
+			ResponseType: reflect.TypeOf(RepoBackupListResponse{}),
+			Permission:   http_server.PermRead,
+			Summary:      "Получить список снимков источников",
+			Tags:         []string{"repo"},
+		},
+		{
+			Handler:      w.Restore,
+			HTTPMethod:   "POST",
+			HTTPPath:     "/api/v1/repo/restore",
+			ResponseType: reflect.TypeOf(RepoRestoreResponse{}),
+			Permission:   http_server.PermManage,
+			Summary:      "Восстановить источники из снимка (по умолчанию из последнего)",
+			Tags:         []string{"repo"},
+			ParamMappings: []http_server.ParamMapping{
+				{Name: "id", Source: "body", Type: "string", Default: "", ArgIndex: 1},
+			},
+			QueryParams: []http_server.QueryParam{
+				{Name: "background", Type: "boolean", Required: false, Description: "Выполнить в фоне (результат придёт через WebSocket)"},
+			},
+		},
+		{
+			Handler:      w.Migrate,
+			HTTPMethod:   "POST",
+			HTTPPath:     "/api/v1/repo/migrate",
+			ResponseType: reflect.TypeOf(RepoMigrateResponse{}),
+			Permission:   http_server.PermManage,
+			Summary:      "Перевести источники apt-repo под управление apm",
+			Tags:         []string{"repo"},
+			ParamMappings: []http_server.ParamMapping{
+				{Name: "simulate", Source: "body", Type: "bool", Default: "false", ArgIndex: 1},
+			},
+		},
+		{
+			Handler:      w.KeyList,
+			HTTPMethod:   "GET",
+			HTTPPath:     "/api/v1/repo/keys",
+			ResponseType: reflect.TypeOf(KeyListResponse{}),
+			Permission:   http_server.PermRead,
+			Summary:      "Получить список GPG ключей APT",
+			Tags:         []string{"repo"},
+		},
+		{
+			Handler:      w.KeyAdd,
+			HTTPMethod:   "POST",
+			HTTPPath:     "/api/v1/repo/keys",
+			ResponseType: reflect.TypeOf(KeyChangeResponse{}),
+			Permission:   http_server.PermManage,
+			Summary:      "Импортировать GPG ключ из файла, по URL или с сервера ключей",
+			Tags:         []string{"repo"},
+			ParamMappings: []http_server.ParamMapping{
+				{Name: "source", Source: "body", Type: "string", ArgIndex: 1},
+				{Name: "keyserver", Source: "body", Type: "string", Default: "", ArgIndex: 2},
+				{Name: "name", Source: "body", Type: "string", Default: "", ArgIndex: 3},
+			},
+			QueryParams: []http_server.QueryParam{
+				{Name: "background", Type: "boolean", Required: false, Description: "Выполнить в фоне (результат придёт через WebSocket)"},
+			},
+		},
+		{
+			Handler:      w.KeyRemove,
+			HTTPMethod:   "DELETE",
+			HTTPPath:     "/api/v1/repo/keys/{id}",
+			ResponseType: reflect.TypeOf(KeyChangeResponse{}),
+			Permission:   http_server.PermManage,
+			Summary:      "Удалить GPG ключ по отпечатку, идентификатору или имени",
+			Tags:         []string{"repo"},
+			PathParams:   []string{"id"},
+			QueryParams: []http_server.QueryParam{
+				{Name: "background", Type: "boolean", Required: false, Description: "Выполнить в фоне (результат придёт через WebSocket)"},
+			},
+		},
+		{
+			Handler:      w.KeyVerify,
+			HTTPMethod:   "GET",
+			HTTPPath:     "/api/v1/repo/keys/verify",
+			ResponseType: reflect.TypeOf(KeyVerifyResponse{}),
+			Permission:   http_server.PermRead,
+			Summary:      "Проверить наличие ключей активных репозиториев",
+			Tags:         []string{"repo"},
+		},
+		{
+			Handler:      w.Health,
+			HTTPMethod:   "GET",
+			HTTPPath:     "/api/v1/repo/check",
+			ResponseType: reflect.TypeOf(RepoHealthResponse{}),
+			Permission:   http_server.PermRead,
+			Summary:      "Проверить активные репозитории: доступность, ключи, архитектуру, повторы, даты архивов и смешение веток",
+			Tags:         []string{"repo"},
+		},
 	}
 }
@@ -19,6 +19,7 @@ package repository
 import (
 	_package "apm/internal/common/apt/package"
 	aptLib "apm/internal/common/binding/apt/lib"
+	"apm/internal/common/journal"
 	"apm/internal/domain/repository/service"
 	"context"
 )
@@ -32,8 +33,31 @@ type repoService interface {
 	CleanTemporary(ctx context.Context) ([]service.Repository, error)
 	GetBranches() []string
 	GetTaskPackages(ctx context.Context, taskNum string) ([]string, error)
+	GetTaskInfo(ctx context.Context, taskNum string) (*service.TaskInfo, error)
 	SimulateAdd(ctx context.Context, args []string, date string, force bool) ([]service.Repository, error)
 	SimulateRemove(ctx context.Context, args []string, date string, purge bool) ([]service.Repository, error)
+	CreateSandbox(ctx context.Context, args []string) (*service.Sandbox, error)
+	ArepoEnabled() bool
+	GetArepoRepositories(ctx context.Context) ([]service.Repository, error)
+	SetArepo(ctx context.Context, enabled bool, simulate bool) (added []service.Repository, removed []service.Repository, err error)
+	GetBiarchPackages(ctx context.Context) ([]string, error)
+	BackupSources() (*service.Backup, error)
+	ListBackups() ([]service.Backup, error)
+	RestoreBackup(id string) error
+	BackupRepositories(id string) ([]service.Repository, error)
+	CheckHealth(ctx context.Context, repos []service.Repository) []service.HealthIssue
+	NamedRepositories() ([]service.NamedRepository, error)
+	MigrateAptRepo(ctx context.Context, simulate bool) (*service.Migration, error)
+}
+
+// keyService определяет методы управления GPG ключами APT.
+type keyService interface {
+	ListKeys(ctx context.Context) ([]service.Key, error)
+	FindKeys(ctx context.Context, id string) ([]service.Key, error)
+	AddKey(ctx context.Context, source, keyserver, name string, simulate bool) ([]service.Key, error)
+	RemoveKeys(ctx context.Context, keys []service.Key) error
+	VerifyKeys(ctx context.Context, repos []service.Repository) ([]service.KeyCheck, error)
+	GetVendors() ([]service.Vendor, error)
 }
 
 // overlayService определяет методы для работы с usr-overlay в атомарных системах.
@@ -41,9 +65,18 @@ type overlayService interface {
 	EnableOverlay() error
 }
 
-// aptActionsService определяет методы APT операций, используемых в TestTask.
+// aptActionsService определяет методы APT операций, используемых в TestTask и VerifyTask.
 type aptActionsService interface {
+	SetAptConfigOverrides(overrides map[string]string)
+	GetAptConfigOverrides() map[string]string
 	Update(ctx context.Context, noLock ...bool) ([]_package.Package, error)
+	AptUpdate(ctx context.Context, noLock ...bool) error
+	CheckInstall(ctx context.Context, packageName []string) (*aptLib.PackageChanges, error)
 	FindPackage(ctx context.Context, installed []string, removed []string, purge bool, depends bool, reinstall bool) ([]string, []string, []_package.Package, *aptLib.PackageChanges, error)
 	CombineInstallRemovePackages(ctx context.Context, install []string, remove []string, purge bool, depends bool, downloadOnly bool) error
 }
+
+// journalService определяет методы записи истории операций.
+type journalService interface {
+	RecordResult(ctx context.Context, entry journal.Entry, opErr error) error
+}
@@ -0,0 +1,90 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package repository
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/journal"
+	"apm/internal/domain/repository/service"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// formatAptRepo формат вывода repo list, совпадающий с выводом apt-repo list
+const formatAptRepo = "apt-repo"
+
+// Migrate переводит источники, созданные apt-repo, под управление apm.
+// При simulate только сообщает, какие строки и макросы будут изменены.
+func (a *Actions) Migrate(ctx context.Context, simulate bool) (*RepoMigrateResponse, error) {
+	if !simulate {
+		if err := a.checkOverlay(ctx); err != nil {
+			return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+		}
+
+		var release func()
+		var err error
+		if ctx, release, err = a.operationLock.Acquire(ctx, journal.ActionMigrate); err != nil {
+			return nil, err
+		}
+		defer release()
+		a.backupSources()
+	}
+
+	migration, err := a.repoService.MigrateAptRepo(ctx, simulate)
+	if !simulate {
+		a.recordOperation(ctx, journal.ActionMigrate, []string{formatAptRepo}, err)
+	}
+	if err != nil {
+		return nil, newRepoError(err)
+	}
+
+	if !migration.Changed() {
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.TL_(ctx, "Repository sources are already in apm format")))
+	}
+
+	message := fmt.Sprintf(app.TLN_(ctx, "%d source line migrated", "%d source lines migrated", len(migration.Changes)), len(migration.Changes))
+	if simulate {
+		message = fmt.Sprintf(app.TLN_(ctx, "%d source line will be migrated", "%d source lines will be migrated", len(migration.Changes)), len(migration.Changes))
+	}
+
+	return &RepoMigrateResponse{
+		Message:        message,
+		Changes:        migration.Changes,
+		PriorityBranch: migration.PriorityBranch,
+		PriorityMacro:  migration.PriorityMacro,
+		LegacyMacro:    migration.LegacyMacro,
+		TaskMeta:       migration.TaskMeta,
+		Simulate:       simulate,
+	}, nil
+}
+
+// aptRepoList формирует вывод в формате apt-repo list: активные строки источников,
+// а с all также закомментированные
+func aptRepoList(repos []service.Repository) string {
+	var sb strings.Builder
+	for _, repo := range repos {
+		if !repo.Active {
+			sb.WriteString("#")
+		}
+		sb.WriteString(repo.Entry)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
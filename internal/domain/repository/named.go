@@ -0,0 +1,170 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package repository
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/journal"
+	"apm/internal/domain/repository/service"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Available возвращает реестр именованных репозиториев с отметкой о подключении
+func (a *Actions) Available(ctx context.Context) (*RepoAvailableResponse, error) {
+	repos, err := a.repoService.NamedRepositories()
+	if err != nil {
+		return nil, newRepoError(err)
+	}
+
+	for i := range repos {
+		repos[i].Enabled = a.namedEnabled(ctx, repos[i])
+	}
+
+	return &RepoAvailableResponse{
+		Message:      fmt.Sprintf(app.TLN_(ctx, "%d named repository available", "%d named repositories available", len(repos)), len(repos)),
+		Repositories: repos,
+		Count:        len(repos),
+	}, nil
+}
+
+// AddNamed подключает репозиторий из реестра вместе с его ключом
+func (a *Actions) AddNamed(ctx context.Context, name string) (*RepoAddRemoveResponse, error) {
+	if err := a.checkOverlay(ctx); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+
+	ctx, release, err := a.operationLock.Acquire(ctx, journal.ActionAdd)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	named, err := a.findNamed(name)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := a.addNamedKey(ctx, named)
+	if err != nil {
+		a.recordOperation(ctx, journal.ActionAdd, []string{named.Name}, err)
+		return nil, newRepoError(err)
+	}
+	if len(keys) > 0 {
+		a.recordOperation(ctx, journal.ActionAdd, keyTargets(keys), nil)
+	}
+
+	a.backupSources()
+	var added []service.Repository
+	for _, source := range named.Sources {
+		repos, errAdd := a.repoService.AddRepository(ctx, []string{source}, "")
+		added = append(added, repos...)
+		if errAdd != nil {
+			a.recordOperation(ctx, journal.ActionAdd, []string{source}, errAdd)
+			return nil, newRepoError(errAdd)
+		}
+	}
+	a.recordOperation(ctx, journal.ActionAdd, repoTargets(added), nil)
+
+	if len(added) == 0 && len(keys) == 0 {
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, fmt.Errorf(app.TL_(ctx, "Repository %s is already enabled"), named.Name))
+	}
+
+	return &RepoAddRemoveResponse{
+		Message: fmt.Sprintf(app.TLN_(ctx, "%d repository added", "%d repositories added", len(added)), len(added)),
+		Added:   added,
+		Keys:    keys,
+	}, nil
+}
+
+// CheckAddNamed симулирует подключение репозитория из реестра
+func (a *Actions) CheckAddNamed(ctx context.Context, name string) (*RepoSimulateResponse, error) {
+	named, err := a.findNamed(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var willAdd []service.Repository
+	for _, source := range named.Sources {
+		repos, errSim := a.repoService.SimulateAdd(ctx, []string{source}, "", false)
+		if errSim != nil {
+			return nil, newRepoError(errSim)
+		}
+		willAdd = append(willAdd, repos...)
+	}
+
+	if len(willAdd) == 0 {
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, fmt.Errorf(app.TL_(ctx, "Repository %s is already enabled"), named.Name))
+	}
+
+	return &RepoSimulateResponse{
+		Message: app.TL_(ctx, "Simulation results"),
+		WillAdd: willAdd,
+	}, nil
+}
+
+// findNamed ищет запись реестра по имени
+func (a *Actions) findNamed(name string) (service.NamedRepository, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return service.NamedRepository{}, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.T_("Repository name must be specified")))
+	}
+
+	repos, err := a.repoService.NamedRepositories()
+	if err != nil {
+		return service.NamedRepository{}, newRepoError(err)
+	}
+	for _, repo := range repos {
+		if repo.Name == name {
+			return repo, nil
+		}
+	}
+	return service.NamedRepository{}, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.T_("Named repository %s not found, see 'apm repo available'"), name))
+}
+
+// addNamedKey импортирует ключ репозитория, если поставщик с таким именем ещё не зарегистрирован
+func (a *Actions) addNamedKey(ctx context.Context, named service.NamedRepository) ([]service.Key, error) {
+	if named.Key == "" {
+		return nil, nil
+	}
+
+	vendors, err := a.serviceKeys.GetVendors()
+	if err != nil {
+		return nil, err
+	}
+	for _, vendor := range vendors {
+		if vendor.Name == named.VendorName() {
+			return nil, nil
+		}
+	}
+
+	return a.serviceKeys.AddKey(ctx, named.Key, named.Keyserver, named.VendorName(), false)
+}
+
+// namedEnabled сообщает, что все источники записи реестра уже подключены
+func (a *Actions) namedEnabled(ctx context.Context, named service.NamedRepository) bool {
+	for _, source := range named.Sources {
+		willAdd, err := a.repoService.SimulateAdd(ctx, []string{source}, "", false)
+		if err != nil || len(willAdd) > 0 {
+			return false
+		}
+	}
+	return true
+}
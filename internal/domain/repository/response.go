@@ -33,6 +33,7 @@ type RepoAddRemoveResponse struct {
 	Message string               `json:"message"`
 	Added   []service.Repository `json:"added,omitempty"`
 	Removed []service.Repository `json:"removed,omitempty"`
+	Keys    []service.Key        `json:"keys,omitempty"`
 }
 
 // RepoSetResponse структура ответа для Set метода
@@ -50,6 +51,16 @@ type RepoSimulateResponse struct {
 	WillRemove []service.Repository `json:"willRemove,omitempty"`
 }
 
+// ArepoResponse структура ответа для команд arepo
+type ArepoResponse struct {
+	Message        string               `json:"message"`
+	Enabled        bool                 `json:"enabled"`
+	Repositories   []service.Repository `json:"repositories,omitempty"`
+	Added          []service.Repository `json:"added,omitempty"`
+	Removed        []service.Repository `json:"removed,omitempty"`
+	BiarchPackages []string             `json:"biarchPackages,omitempty"`
+}
+
 // BranchesResponse структура ответа для GetBranches метода
 type BranchesResponse struct {
 	Message  string   `json:"message"`
@@ -64,9 +75,106 @@ type TaskPackagesResponse struct {
 	Count    int      `json:"count"`
 }
 
+// TaskInfoResponse структура ответа для TaskInfo метода
+type TaskInfoResponse struct {
+	Message  string            `json:"message"`
+	Task     *service.TaskInfo `json:"task"`
+	Packages []string          `json:"packages"`
+	Count    int               `json:"count"`
+}
+
+// TaskWatchResponse структура ответа для TaskWatch метода
+type TaskWatchResponse struct {
+	Message   string                 `json:"message"`
+	Task      *service.TaskInfo      `json:"task"`
+	Added     []service.Repository   `json:"added,omitempty"`
+	Installed *aptlib.PackageChanges `json:"installed,omitempty"`
+}
+
 // TestTaskResponse структура ответа для TestTask метода
 type TestTaskResponse struct {
 	Message string                `json:"message"`
 	TaskNum string                `json:"taskNum"`
 	Info    aptlib.PackageChanges `json:"info"`
 }
+
+// TaskPackageCheck результат проверки установки одного пакета задачи
+type TaskPackageCheck struct {
+	Name  string                 `json:"name"`
+	OK    bool                   `json:"ok"`
+	Error string                 `json:"error,omitempty"`
+	Info  *aptlib.PackageChanges `json:"info,omitempty"`
+}
+
+// VerifyTaskResponse структура ответа для VerifyTask метода
+type VerifyTaskResponse struct {
+	Message      string                 `json:"message"`
+	TaskNum      string                 `json:"taskNum"`
+	Packages     []TaskPackageCheck     `json:"packages"`
+	BrokenCount  int                    `json:"brokenCount"`
+	InstallError string                 `json:"installError,omitempty"`
+	Info         *aptlib.PackageChanges `json:"info,omitempty"`
+}
+
+// RepoBackupListResponse структура ответа для Backups метода
+type RepoBackupListResponse struct {
+	Message string           `json:"message"`
+	Backups []service.Backup `json:"backups"`
+	Count   int              `json:"count"`
+}
+
+// RepoRestoreResponse структура ответа для Restore метода
+type RepoRestoreResponse struct {
+	Message string               `json:"message"`
+	Backup  service.Backup       `json:"backup"`
+	Added   []service.Repository `json:"added,omitempty"`
+	Removed []service.Repository `json:"removed,omitempty"`
+}
+
+// KeyListResponse структура ответа для KeyList метода
+type KeyListResponse struct {
+	Message string        `json:"message"`
+	Keys    []service.Key `json:"keys"`
+	Count   int           `json:"count"`
+}
+
+// KeyChangeResponse структура ответа для KeyAdd/KeyRemove методов
+type KeyChangeResponse struct {
+	Message string        `json:"message"`
+	Added   []service.Key `json:"added,omitempty"`
+	Removed []service.Key `json:"removed,omitempty"`
+}
+
+// KeyVerifyResponse структура ответа для KeyVerify метода
+type KeyVerifyResponse struct {
+	Message      string             `json:"message"`
+	Checks       []service.KeyCheck `json:"checks"`
+	MissingCount int                `json:"missingCount"`
+}
+
+// RepoHealthResponse структура ответа для Health метода
+type RepoHealthResponse struct {
+	Message  string                `json:"message"`
+	Checked  int                   `json:"checked"`
+	Issues   []service.HealthIssue `json:"issues"`
+	Errors   int                   `json:"errors"`
+	Warnings int                   `json:"warnings"`
+}
+
+// RepoAvailableResponse структура ответа для Available метода
+type RepoAvailableResponse struct {
+	Message      string                    `json:"message"`
+	Repositories []service.NamedRepository `json:"repositories"`
+	Count        int                       `json:"count"`
+}
+
+// RepoMigrateResponse структура ответа для Migrate метода
+type RepoMigrateResponse struct {
+	Message        string                    `json:"message"`
+	Changes        []service.MigrationChange `json:"changes"`
+	PriorityBranch string                    `json:"priorityBranch,omitempty"`
+	PriorityMacro  bool                      `json:"priorityMacro"`
+	LegacyMacro    bool                      `json:"legacyMacro"`
+	TaskMeta       []string                  `json:"taskMeta,omitempty"`
+	Simulate       bool                      `json:"simulate"`
+}
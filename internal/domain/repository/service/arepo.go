@@ -0,0 +1,236 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/command"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// arepoArch компонент-архитектура biarch-репозитория
+const arepoArch = "x86_64-i586"
+
+// ArepoEnabled сообщает, включён ли arepo в конфигурации
+func (s *RepoService) ArepoEnabled() bool {
+	return s.useArepo
+}
+
+// GetArepoRepositories возвращает активные biarch-источники веток
+func (s *RepoService) GetArepoRepositories(ctx context.Context) ([]Repository, error) {
+	repos, err := s.GetRepositories(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Repository
+	for _, repo := range repos {
+		if repo.Arch == arepoArch {
+			result = append(result, repo)
+		}
+	}
+	return result, nil
+}
+
+// SetArepo включает или выключает arepo: записывает /etc/sysconfig/apt-repo и
+// добавляет или убирает x86_64-i586 источники у уже подключённых веток.
+// При simulate файлы не изменяются, возвращаются только планируемые изменения.
+func (s *RepoService) SetArepo(ctx context.Context, enabled bool, simulate bool) (added []Repository, removed []Repository, err error) {
+	s.ensureInitialized()
+	if s.arch != "x86_64" {
+		return nil, nil, fmt.Errorf(app.TL_(ctx, "Arepo is only available on x86_64, current architecture: %s"), s.arch)
+	}
+
+	if !simulate {
+		if err = s.writeArepoConfig(enabled); err != nil {
+			return nil, nil, err
+		}
+		s.useArepo = enabled
+	}
+
+	repos, err := s.GetRepositories(ctx, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !enabled {
+		for _, repo := range repos {
+			if repo.Arch != arepoArch || !isArepoBranch(repo) {
+				continue
+			}
+			if !simulate {
+				if err = s.removeOrCommentRepo(repo.Entry); err != nil {
+					return added, removed, err
+				}
+			}
+			repo.Active = false
+			removed = append(removed, repo)
+		}
+		return added, removed, nil
+	}
+
+	seen := make(map[string]bool)
+	for _, repo := range repos {
+		if repo.Arch != "x86_64" || !isArepoBranch(repo) || len(repo.Components) == 0 {
+			continue
+		}
+
+		line := arepoLine(repo)
+		canonical := canonicalizeRepoLine(line)
+		if seen[canonical] {
+			continue
+		}
+		seen[canonical] = true
+
+		exists, commented, errCheck := s.checkRepoExists(ctx, line)
+		if errCheck != nil {
+			return added, removed, errCheck
+		}
+		if exists {
+			continue
+		}
+
+		file := repo.File
+		switch {
+		case simulate:
+		case commented:
+			if file, err = s.uncommentRepo(line); err != nil {
+				return added, removed, err
+			}
+		default:
+			if err = insertAfterInFile(repo.File, canonicalizeRepoLine(repo.Entry), line); err != nil {
+				return added, removed, err
+			}
+		}
+
+		if parsed := s.parseLine(line, file, true); parsed != nil {
+			added = append(added, *parsed)
+		}
+	}
+
+	return added, removed, nil
+}
+
+// GetBiarchPackages возвращает установленные пакеты, которым нужен arepo
+func (s *RepoService) GetBiarchPackages(ctx context.Context) ([]string, error) {
+	stdout, stderr, err := s.runner.Run(ctx, []string{"rpm", "-qa", "--queryformat", "%{NAME}\n"}, command.WithQuiet())
+	if err != nil {
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to query installed packages: %s"), strings.TrimSpace(stderr))
+	}
+
+	var packages []string
+	for _, name := range strings.Split(stdout, "\n") {
+		name = strings.TrimSpace(name)
+		if strings.HasPrefix(name, "i586-") {
+			packages = append(packages, name)
+		}
+	}
+	sort.Strings(packages)
+	return packages, nil
+}
+
+// isArepoBranch проверяет, что источник относится к ветке, у которой есть arepo
+func isArepoBranch(repo Repository) bool {
+	if repo.Branch == "" || repo.Branch == "task" {
+		return false
+	}
+	return !strings.Contains(repo.URL, "altlinuxclub") && !strings.Contains(repo.URL, "autoimports")
+}
+
+// arepoLine формирует x86_64-i586 строку для источника ветки с основным компонентом
+func arepoLine(repo Repository) string {
+	fields := strings.Fields(canonicalizeRepoLine(repo.Entry))
+	idx := 1
+	if strings.HasPrefix(fields[idx], "[") {
+		idx++
+	}
+	parts := append([]string{}, fields[:idx+1]...)
+	return strings.Join(append(parts, arepoArch, repo.Components[0]), " ")
+}
+
+// writeArepoConfig записывает значение AREPO в конфигурацию apt-repo, сохраняя остальные строки
+func (s *RepoService) writeArepoConfig(enabled bool) error {
+	value := "AREPO=YES"
+	if !enabled {
+		value = "AREPO=NO"
+	}
+
+	content, err := os.ReadFile(s.arepoConfig)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf(app.T_("Failed to read %s: %v"), s.arepoConfig, err)
+	}
+
+	var lines []string
+	replaced := false
+	for _, line := range strings.Split(strings.TrimRight(string(content), "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "AREPO=") || strings.HasPrefix(trimmed, "AREPO ") {
+			if replaced {
+				continue
+			}
+			line = value
+			replaced = true
+		}
+		if line != "" || len(lines) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	if !replaced {
+		lines = append(lines, value)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(s.arepoConfig), 0755); err != nil {
+		return fmt.Errorf(app.T_("Failed to write %s: %v"), s.arepoConfig, err)
+	}
+	if err = writeFileAtomic(s.arepoConfig, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		return fmt.Errorf(app.T_("Failed to write %s: %v"), s.arepoConfig, err)
+	}
+	return nil
+}
+
+// insertAfterInFile вставляет строку сразу после строки-якоря, либо в конец файла
+func insertAfterInFile(filename string, canonicalAnchor string, newLine string) error {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf(app.T_("Failed to read %s: %v"), filename, err)
+	}
+
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	result := make([]string, 0, len(lines)+1)
+	inserted := false
+	for _, line := range lines {
+		result = append(result, line)
+		if !inserted && canonicalizeRepoLine(strings.TrimSpace(line)) == canonicalAnchor {
+			result = append(result, newLine)
+			inserted = true
+		}
+	}
+	if !inserted {
+		result = append(result, newLine)
+	}
+
+	if err = writeFileAtomic(filename, []byte(strings.Join(result, "\n")+"\n"), 0644); err != nil {
+		return fmt.Errorf(app.T_("Failed to write to %s: %v"), filename, err)
+	}
+	return nil
+}
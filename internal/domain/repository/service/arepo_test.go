@@ -0,0 +1,121 @@
+package service
+
+import (
+	"apm/internal/common/command"
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSetArepo(t *testing.T) {
+	const (
+		mainLine   = "rpm [alt] http://ftp.altlinux.org/pub/distributions/ALTLinux/Sisyphus x86_64 classic gostcrypto"
+		noarchLine = "rpm [alt] http://ftp.altlinux.org/pub/distributions/ALTLinux/Sisyphus noarch classic"
+		arepoEntry = "rpm [alt] http://ftp.altlinux.org/pub/distributions/ALTLinux/Sisyphus x86_64-i586 classic"
+	)
+	ctx := context.Background()
+
+	t.Run("enable inserts biarch entry after branch line", func(t *testing.T) {
+		s, _ := newTestService(t)
+		s.useArepo = false
+		writeSourcesList(t, s, mainLine+"\n"+noarchLine+"\n")
+
+		added, _, err := s.SetArepo(ctx, true, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(added) != 1 || added[0].Arch != arepoArch {
+			t.Fatalf("expected one biarch source, got %+v", added)
+		}
+
+		content, _ := os.ReadFile(s.confMain)
+		want := mainLine + "\n" + arepoEntry + "\n" + noarchLine + "\n"
+		if string(content) != want {
+			t.Errorf("unexpected sources.list:\n%s", content)
+		}
+		if !s.ArepoEnabled() || !checkArepoEnabled(s.arepoConfig) {
+			t.Error("arepo must be enabled in config")
+		}
+
+		added, _, err = s.SetArepo(ctx, true, false)
+		if err != nil || len(added) != 0 {
+			t.Errorf("second enable must be a no-op, got %+v, %v", added, err)
+		}
+	})
+
+	t.Run("disable removes biarch entries and keeps other settings", func(t *testing.T) {
+		s, _ := newTestService(t)
+		writeSourcesList(t, s, mainLine+"\n"+arepoEntry+"\n"+noarchLine+"\n")
+		if err := os.WriteFile(s.arepoConfig, []byte("# apt-repo settings\nAREPO=YES\nOTHER=1\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		_, removed, err := s.SetArepo(ctx, false, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(removed) != 1 {
+			t.Fatalf("expected one removed source, got %+v", removed)
+		}
+
+		content, _ := os.ReadFile(s.confMain)
+		if strings.Contains(string(content), arepoArch) {
+			t.Errorf("biarch entry must be removed:\n%s", content)
+		}
+		config, _ := os.ReadFile(s.arepoConfig)
+		if string(config) != "# apt-repo settings\nAREPO=NO\nOTHER=1\n" {
+			t.Errorf("unexpected config:\n%s", config)
+		}
+		if s.ArepoEnabled() || checkArepoEnabled(s.arepoConfig) {
+			t.Error("arepo must be disabled in config")
+		}
+	})
+
+	t.Run("simulate reports changes without writing files", func(t *testing.T) {
+		s, _ := newTestService(t)
+		s.useArepo = false
+		writeSourcesList(t, s, mainLine+"\n"+noarchLine+"\n")
+
+		added, _, err := s.SetArepo(ctx, true, true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(added) != 1 || added[0].Arch != arepoArch {
+			t.Fatalf("expected one biarch source, got %+v", added)
+		}
+
+		content, _ := os.ReadFile(s.confMain)
+		if string(content) != mainLine+"\n"+noarchLine+"\n" {
+			t.Errorf("sources.list must not change:\n%s", content)
+		}
+		if _, err = os.Stat(s.arepoConfig); !os.IsNotExist(err) || s.ArepoEnabled() {
+			t.Errorf("arepo config must not change: %v", err)
+		}
+	})
+
+	t.Run("rejects non x86_64", func(t *testing.T) {
+		s, _ := newTestService(t)
+		s.arch = "aarch64"
+
+		if _, _, err := s.SetArepo(ctx, true, false); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+func TestGetBiarchPackages(t *testing.T) {
+	s, _ := newTestService(t)
+	s.runner = &mockRunner{runFunc: func(_ context.Context, args []string, _ ...command.Option) (string, string, error) {
+		return "bash\ni586-wine\ni586-glibc-core\nwine\n", "", nil
+	}}
+
+	packages, err := s.GetBiarchPackages(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"i586-glibc-core", "i586-wine"}; !reflect.DeepEqual(packages, want) {
+		t.Errorf("got %v, want %v", packages, want)
+	}
+}
@@ -0,0 +1,349 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"apm/internal/common/app"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultBackupDir каталог снимков источников APT.
+	DefaultBackupDir = "/var/lib/apm/repo-backups"
+
+	// backupTimeLayout формат имени снимка, сортируется лексикографически
+	backupTimeLayout = "20060102T150405"
+	// maxRepoBackups количество хранимых снимков, более старые удаляются
+	maxRepoBackups = 20
+	// backupSourcesList имя основного файла источников в снимке
+	backupSourcesList = "sources.list"
+	// backupSourcesDir каталог дополнительных файлов источников в снимке
+	backupSourcesDir = "sources.list.d"
+)
+
+// Backup снимок файлов источников APT
+type Backup struct {
+	ID    string   `json:"id"`
+	Date  string   `json:"date"`
+	Files []string `json:"files"`
+}
+
+// writeFileAtomic записывает файл через временный файл в том же каталоге и переименование,
+// чтобы прерванная запись не оставила повреждённый файл. Права существующего файла сохраняются.
+func writeFileAtomic(filename string, data []byte, perm os.FileMode) error {
+	if info, err := os.Stat(filename); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if err == nil {
+		err = tmp.Chmod(perm)
+	}
+	if errClose := tmp.Close(); err == nil {
+		err = errClose
+	}
+	if err == nil {
+		err = os.Rename(tmpName, filename)
+	}
+	if err != nil {
+		_ = os.Remove(tmpName)
+		return err
+	}
+	return nil
+}
+
+// sourcesSnapshot читает текущие файлы источников: путь внутри снимка → содержимое
+func (s *RepoService) sourcesSnapshot() (map[string][]byte, error) {
+	s.ensureInitialized()
+
+	files := make(map[string][]byte)
+	if data, err := os.ReadFile(s.confMain); err == nil {
+		files[backupSourcesList] = data
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf(app.T_("Failed to read %s: %v"), s.confMain, err)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(s.confDir, "*.list"))
+	for _, path := range matches {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf(app.T_("Failed to read %s: %v"), path, err)
+		}
+		files[filepath.Join(backupSourcesDir, filepath.Base(path))] = data
+	}
+	return files, nil
+}
+
+// readBackup читает файлы снимка
+func (s *RepoService) readBackup(id string) (map[string][]byte, error) {
+	dir := filepath.Join(s.backupDir, id)
+	files := make(map[string][]byte)
+
+	if data, err := os.ReadFile(filepath.Join(dir, backupSourcesList)); err == nil {
+		files[backupSourcesList] = data
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf(app.T_("Failed to read %s: %v"), dir, err)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(dir, backupSourcesDir, "*.list"))
+	for _, path := range matches {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf(app.T_("Failed to read %s: %v"), path, err)
+		}
+		files[filepath.Join(backupSourcesDir, filepath.Base(path))] = data
+	}
+	return files, nil
+}
+
+// BackupSources сохраняет снимок источников APT. Если источники не изменились с последнего снимка,
+// новый снимок не создаётся и возвращается последний.
+func (s *RepoService) BackupSources() (*Backup, error) {
+	files, err := s.sourcesSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	backups, err := s.ListBackups()
+	if err != nil {
+		return nil, err
+	}
+	if len(backups) > 0 {
+		latest := backups[len(backups)-1]
+		if previous, errRead := s.readBackup(latest.ID); errRead == nil && sameFiles(previous, files) {
+			return &latest, nil
+		}
+	}
+
+	if err = os.MkdirAll(s.backupDir, 0700); err != nil {
+		return nil, fmt.Errorf(app.T_("Failed to create directory %s: %v"), s.backupDir, err)
+	}
+
+	now := time.Now()
+	id := now.Format(backupTimeLayout)
+	for i := 2; ; i++ {
+		if _, errStat := os.Stat(filepath.Join(s.backupDir, id)); os.IsNotExist(errStat) {
+			break
+		}
+		id = fmt.Sprintf("%s-%d", now.Format(backupTimeLayout), i)
+	}
+
+	// Снимок собирается во временном каталоге и появляется целиком после переименования
+	tmpDir, err := os.MkdirTemp(s.backupDir, ".tmp-")
+	if err != nil {
+		return nil, fmt.Errorf(app.T_("Failed to create directory %s: %v"), s.backupDir, err)
+	}
+	if err = os.MkdirAll(filepath.Join(tmpDir, backupSourcesDir), 0700); err == nil {
+		for name, data := range files {
+			if err = os.WriteFile(filepath.Join(tmpDir, name), data, 0644); err != nil {
+				break
+			}
+		}
+	}
+	if err == nil {
+		err = os.Rename(tmpDir, filepath.Join(s.backupDir, id))
+	}
+	if err != nil {
+		_ = os.RemoveAll(tmpDir)
+		return nil, fmt.Errorf(app.T_("Failed to save repository backup: %v"), err)
+	}
+
+	s.pruneBackups()
+	return &Backup{ID: id, Date: backupDate(id), Files: sortedNames(files)}, nil
+}
+
+// ListBackups возвращает снимки источников от старых к новым
+func (s *RepoService) ListBackups() ([]Backup, error) {
+	entries, err := os.ReadDir(s.backupDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []Backup{}, nil
+		}
+		return nil, fmt.Errorf(app.T_("Failed to read %s: %v"), s.backupDir, err)
+	}
+
+	backups := make([]Backup, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		files, errRead := s.readBackup(entry.Name())
+		if errRead != nil {
+			continue
+		}
+		backups = append(backups, Backup{ID: entry.Name(), Date: backupDate(entry.Name()), Files: sortedNames(files)})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].ID < backups[j].ID })
+
+	return backups, nil
+}
+
+// RestoreBackup восстанавливает источники из снимка id. Текущее состояние предварительно
+// сохраняется в новый снимок, поэтому восстановление можно отменить.
+func (s *RepoService) RestoreBackup(id string) error {
+	if err := s.checkBackup(id); err != nil {
+		return err
+	}
+
+	files, err := s.readBackup(id)
+	if err != nil {
+		return err
+	}
+	if _, err = s.BackupSources(); err != nil {
+		return err
+	}
+
+	main, ok := files[backupSourcesList]
+	if !ok {
+		main = []byte("\n")
+	}
+	if err = writeFileAtomic(s.confMain, main, 0644); err != nil {
+		return fmt.Errorf(app.T_("Failed to write to %s: %v"), s.confMain, err)
+	}
+
+	if err = os.MkdirAll(s.confDir, 0755); err != nil {
+		return fmt.Errorf(app.T_("Failed to create directory %s: %v"), s.confDir, err)
+	}
+	for name, data := range files {
+		if name == backupSourcesList {
+			continue
+		}
+		path := filepath.Join(s.confDir, filepath.Base(name))
+		if err = writeFileAtomic(path, data, 0644); err != nil {
+			return fmt.Errorf(app.T_("Failed to write to %s: %v"), path, err)
+		}
+	}
+
+	// Файлы, появившиеся после снимка, удаляются
+	current, _ := filepath.Glob(filepath.Join(s.confDir, "*.list"))
+	for _, path := range current {
+		if _, inBackup := files[filepath.Join(backupSourcesDir, filepath.Base(path))]; inBackup {
+			continue
+		}
+		if err = os.Remove(path); err != nil {
+			return fmt.Errorf(app.T_("Failed to remove %s: %v"), path, err)
+		}
+	}
+
+	return nil
+}
+
+// BackupRepositories возвращает активные источники снимка id без его восстановления
+func (s *RepoService) BackupRepositories(id string) ([]Repository, error) {
+	s.ensureInitialized()
+	if err := s.checkBackup(id); err != nil {
+		return nil, err
+	}
+
+	files, err := s.readBackup(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var repos []Repository
+	for _, name := range sortedNames(files) {
+		path := s.confMain
+		if name != backupSourcesList {
+			path = filepath.Join(s.confDir, filepath.Base(name))
+		}
+		for _, line := range strings.Split(string(files[name]), "\n") {
+			trimmed := strings.TrimSpace(line)
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			if repo := s.parseLine(trimmed, path, true); repo != nil {
+				repos = append(repos, *repo)
+			}
+		}
+	}
+	return repos, nil
+}
+
+// checkBackup проверяет, что снимок id существует
+func (s *RepoService) checkBackup(id string) error {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.HasPrefix(id, ".") {
+		return fmt.Errorf(app.T_("Repository backup %s not found"), id)
+	}
+	if info, err := os.Stat(filepath.Join(s.backupDir, id)); err != nil || !info.IsDir() {
+		return fmt.Errorf(app.T_("Repository backup %s not found"), id)
+	}
+	return nil
+}
+
+// pruneBackups удаляет самые старые снимки сверх maxRepoBackups
+func (s *RepoService) pruneBackups() {
+	backups, err := s.ListBackups()
+	if err != nil || len(backups) <= maxRepoBackups {
+		return
+	}
+
+	for _, backup := range backups[:len(backups)-maxRepoBackups] {
+		if errRemove := os.RemoveAll(filepath.Join(s.backupDir, backup.ID)); errRemove != nil {
+			app.Log.Debugf("failed to remove old repository backup %s: %v", backup.ID, errRemove)
+		}
+	}
+}
+
+// backupDate возвращает время создания снимка в формате RFC3339
+func backupDate(id string) string {
+	stamp, _, _ := strings.Cut(id, "-")
+	date, err := time.ParseInLocation(backupTimeLayout, stamp, time.Local)
+	if err != nil {
+		return ""
+	}
+	return date.Format(time.RFC3339)
+}
+
+// sameFiles сравнивает наборы файлов снимков
+func sameFiles(a, b map[string][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, data := range a {
+		other, ok := b[name]
+		if !ok || !bytes.Equal(data, other) {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedNames возвращает отсортированные имена файлов снимка
+func sortedNames(files map[string][]byte) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
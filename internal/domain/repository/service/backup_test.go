@@ -0,0 +1,161 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sources.list")
+	if err := os.WriteFile(path, []byte("old\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeFileAtomic(path, []byte("new\n"), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "new\n" {
+		t.Errorf("expected new content, got %q", data)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600 preserved, got %v", info.Mode().Perm())
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("expected no temporary files left, got %d entries", len(entries))
+	}
+}
+
+func TestBackupSources(t *testing.T) {
+	s, _ := newTestService(t)
+	writeSourcesList(t, s, "rpm [p11] http://example.org/p11 x86_64 classic\n")
+	writeExtraList(t, s, "extra.list", "rpm http://example.org/extra noarch classic\n")
+
+	first, err := s.BackupSources()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(first.Files) != 2 || first.Date == "" {
+		t.Errorf("unexpected backup: %+v", first)
+	}
+
+	second, err := s.BackupSources()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if second.ID != first.ID {
+		t.Errorf("expected unchanged sources to reuse backup %s, got %s", first.ID, second.ID)
+	}
+
+	writeSourcesList(t, s, "rpm [p10] http://example.org/p10 x86_64 classic\n")
+	third, err := s.BackupSources()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if third.ID == first.ID {
+		t.Error("expected new backup after sources changed")
+	}
+
+	backups, err := s.ListBackups()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(backups) != 2 || backups[0].ID != first.ID || backups[1].ID != third.ID {
+		t.Errorf("unexpected backups: %+v", backups)
+	}
+}
+
+func TestRestoreBackup(t *testing.T) {
+	s, _ := newTestService(t)
+	original := "rpm [p11] http://example.org/p11 x86_64 classic\n"
+	writeSourcesList(t, s, original)
+	writeExtraList(t, s, "extra.list", "rpm http://example.org/extra noarch classic\n")
+
+	backup, err := s.BackupSources()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	writeSourcesList(t, s, "rpm [p10] http://example.org/p10 x86_64 classic\n")
+	writeExtraList(t, s, "new.list", "rpm http://example.org/new noarch classic\n")
+	if err = os.Remove(filepath.Join(s.confDir, "extra.list")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = s.RestoreBackup(backup.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if content := readSourcesList(t, s); content != original {
+		t.Errorf("expected sources.list restored, got %q", content)
+	}
+	if _, err = os.Stat(filepath.Join(s.confDir, "extra.list")); err != nil {
+		t.Error("expected extra.list restored")
+	}
+	if _, err = os.Stat(filepath.Join(s.confDir, "new.list")); !os.IsNotExist(err) {
+		t.Error("expected new.list removed")
+	}
+
+	backups, _ := s.ListBackups()
+	if len(backups) != 2 {
+		t.Errorf("expected state before restore to be backed up, got %d backups", len(backups))
+	}
+
+	if err = s.RestoreBackup("../etc"); err == nil {
+		t.Error("expected error for invalid backup id")
+	}
+}
+
+func TestBackupRepositories(t *testing.T) {
+	s, _ := newTestService(t)
+	writeSourcesList(t, s, "# comment\nrpm [p11] http://example.org/p11 x86_64 classic\n")
+	writeExtraList(t, s, "extra.list", "rpm http://example.org/extra noarch classic\n")
+
+	backup, err := s.BackupSources()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	writeSourcesList(t, s, "rpm [p10] http://example.org/p10 x86_64 classic\n")
+
+	repos, err := s.BackupRepositories(backup.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repos) != 2 || repos[0].URL != "http://example.org/p11" || repos[1].File != filepath.Join(s.confDir, "extra.list") {
+		t.Errorf("unexpected repositories: %+v", repos)
+	}
+	if content := readSourcesList(t, s); content != "rpm [p10] http://example.org/p10 x86_64 classic\n" {
+		t.Errorf("sources.list must not change, got %q", content)
+	}
+
+	if _, err = s.BackupRepositories("missing"); err == nil {
+		t.Error("expected error for missing backup")
+	}
+}
+
+func TestPruneBackups(t *testing.T) {
+	s, _ := newTestService(t)
+	for i := 0; i < maxRepoBackups+2; i++ {
+		dir := filepath.Join(s.backupDir, fmt.Sprintf("20250101T1000%02d", i))
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s.pruneBackups()
+
+	backups, err := s.ListBackups()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(backups) != maxRepoBackups {
+		t.Fatalf("expected %d backups, got %d", maxRepoBackups, len(backups))
+	}
+	if backups[0].ID != "20250101T100002" {
+		t.Errorf("expected oldest backups removed, got first %s", backups[0].ID)
+	}
+}
@@ -0,0 +1,277 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"apm/internal/common/app"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Уровни серьёзности проблем источников
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// Виды проверок источников
+const (
+	HealthReachability = "reachability"
+	HealthKey          = "key"
+	HealthArch         = "arch"
+	HealthDuplicate    = "duplicate"
+	HealthArchiveDate  = "archiveDate"
+	HealthBranchMix    = "branchMix"
+)
+
+// archiveDatePattern выделяет дату архива из URL источника
+var archiveDatePattern = regexp.MustCompile(`/date/(\d{4}/\d{2}/\d{2})(/|$)`)
+
+// HealthIssue проблема, найденная при проверке источников
+type HealthIssue struct {
+	Severity string `json:"severity"`
+	Check    string `json:"check"`
+	Entry    string `json:"entry,omitempty"`
+	File     string `json:"file,omitempty"`
+	Message  string `json:"message"`
+}
+
+// CheckHealth проверяет активные источники: доступность индексов, архитектуру, повторы, даты архивов
+// и смешение веток. Проверка ключей выполняется отдельно сервисом ключей.
+func (s *RepoService) CheckHealth(ctx context.Context, repos []Repository) []HealthIssue {
+	s.ensureInitialized()
+
+	var active []Repository
+	for _, repo := range repos {
+		if repo.Active {
+			active = append(active, repo)
+		}
+	}
+
+	issues := s.checkReachability(ctx, active)
+	issues = append(issues, s.checkArch(active)...)
+	issues = append(issues, checkDuplicates(active)...)
+	issues = append(issues, checkArchiveDates(active, time.Now())...)
+	issues = append(issues, checkBranchMix(active)...)
+	return issues
+}
+
+// checkReachability параллельно запрашивает файл release каждого источника.
+func (s *RepoService) checkReachability(ctx context.Context, repos []Repository) []HealthIssue {
+	results := make([]*HealthIssue, len(repos))
+	var wg sync.WaitGroup
+	for i, repo := range repos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.checkRelease(ctx, repo); err != nil {
+				results[i] = &HealthIssue{
+					Severity: SeverityError,
+					Check:    HealthReachability,
+					Entry:    repo.Entry,
+					File:     repo.File,
+					Message:  err.Error(),
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	var issues []HealthIssue
+	for _, issue := range results {
+		if issue != nil {
+			issues = append(issues, *issue)
+		}
+	}
+	return issues
+}
+
+// checkRelease проверяет наличие индекса base/release источника. Источники с неподдерживаемой
+// схемой (cdrom, ftp) пропускаются.
+func (s *RepoService) checkRelease(ctx context.Context, repo Repository) error {
+	u, err := url.Parse(repo.URL)
+	if err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Invalid repository URL %s: %v"), repo.URL, err)
+	}
+	release := strings.TrimRight(repo.URL, "/") + "/" + repo.Arch + "/base/release"
+
+	switch u.Scheme {
+	case "file", "":
+		path := filepath.Join(u.Path, repo.Arch, "base", "release")
+		if _, err = os.Stat(path); err != nil {
+			return fmt.Errorf(app.TL_(ctx, "Repository index %s is not available: %v"), path, err)
+		}
+	case "http", "https":
+		resp, err := s.doRequest(ctx, http.MethodHead, release)
+		if err != nil {
+			return fmt.Errorf(app.TL_(ctx, "Repository %s is unreachable: %v"), repo.URL, err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode >= http.StatusBadRequest {
+			return fmt.Errorf(app.TL_(ctx, "Repository index %s is not available: %s"), release, resp.Status)
+		}
+	}
+	return nil
+}
+
+// checkArch находит источники для архитектуры, которую система не использует.
+func (s *RepoService) checkArch(repos []Repository) []HealthIssue {
+	allowed := []string{s.arch, "noarch"}
+	if s.arch == "x86_64" {
+		allowed = append(allowed, "x86_64-i586")
+	}
+
+	var issues []HealthIssue
+	for _, repo := range repos {
+		if !slices.Contains(allowed, repo.Arch) {
+			issues = append(issues, HealthIssue{
+				Severity: SeverityError,
+				Check:    HealthArch,
+				Entry:    repo.Entry,
+				File:     repo.File,
+				Message:  fmt.Sprintf(app.T_("Repository architecture %s does not match the system architecture %s"), repo.Arch, s.arch),
+			})
+		}
+	}
+	return issues
+}
+
+// checkDuplicates находит источники, подключённые несколько раз, в том числе с разной схемой URL.
+func checkDuplicates(repos []Repository) []HealthIssue {
+	seen := make(map[string]Repository, len(repos))
+	var issues []HealthIssue
+	for _, repo := range repos {
+		components := slices.Clone(repo.Components)
+		sort.Strings(components)
+		key := stripScheme(repo.URL) + " " + repo.Arch + " " + strings.Join(components, " ")
+
+		first, ok := seen[key]
+		if !ok {
+			seen[key] = repo
+			continue
+		}
+		issues = append(issues, HealthIssue{
+			Severity: SeverityWarning,
+			Check:    HealthDuplicate,
+			Entry:    repo.Entry,
+			File:     repo.File,
+			Message:  fmt.Sprintf(app.T_("Repository is already configured in %s"), first.File),
+		})
+	}
+	return issues
+}
+
+// checkArchiveDates проверяет даты архивных источников: дата должна существовать и не быть в будущем,
+// а источники одной ветки не должны ссылаться на разные даты.
+func checkArchiveDates(repos []Repository, now time.Time) []HealthIssue {
+	var issues []HealthIssue
+	dates := make(map[string]string)
+	for _, repo := range repos {
+		match := archiveDatePattern.FindStringSubmatch(repo.URL)
+		if match == nil {
+			continue
+		}
+
+		date, err := time.Parse("2006/01/02", match[1])
+		switch {
+		case err != nil:
+			issues = append(issues, HealthIssue{
+				Severity: SeverityError,
+				Check:    HealthArchiveDate,
+				Entry:    repo.Entry,
+				File:     repo.File,
+				Message:  fmt.Sprintf(app.T_("Archive date %s does not exist"), match[1]),
+			})
+			continue
+		case date.After(now):
+			issues = append(issues, HealthIssue{
+				Severity: SeverityError,
+				Check:    HealthArchiveDate,
+				Entry:    repo.Entry,
+				File:     repo.File,
+				Message:  fmt.Sprintf(app.T_("Archive date %s is in the future"), match[1]),
+			})
+			continue
+		}
+
+		if previous, ok := dates[repo.Branch]; ok && previous != match[1] {
+			issues = append(issues, HealthIssue{
+				Severity: SeverityWarning,
+				Check:    HealthArchiveDate,
+				Entry:    repo.Entry,
+				File:     repo.File,
+				Message:  fmt.Sprintf(app.T_("Archive of %s is pinned to %s and %s at the same time"), repo.Branch, previous, match[1]),
+			})
+			continue
+		}
+		dates[repo.Branch] = match[1]
+	}
+	return issues
+}
+
+// checkBranchMix находит одновременно подключённые разные ветки, например p10 и sisyphus.
+// Сертифицированные ветки и autoimports считаются частью своей базовой ветки, задачи не учитываются.
+func checkBranchMix(repos []Repository) []HealthIssue {
+	branches := make(map[string]bool)
+	for _, repo := range repos {
+		if base := baseBranch(repo.Branch); base != "" {
+			branches[base] = true
+		}
+	}
+	if len(branches) < 2 {
+		return nil
+	}
+
+	names := make([]string, 0, len(branches))
+	for name := range branches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return []HealthIssue{{
+		Severity: SeverityError,
+		Check:    HealthBranchMix,
+		Message:  fmt.Sprintf(app.T_("Repositories of different branches are mixed: %s"), strings.Join(names, ", ")),
+	}}
+}
+
+// baseBranch возвращает ветку, на которой основан источник.
+func baseBranch(branch string) string {
+	branch = strings.TrimPrefix(strings.ToLower(branch), "autoimports.")
+	switch branch {
+	case "", "task":
+		return ""
+	case "c8", "c8.1":
+		return "p8"
+	case "c9f2":
+		return "p9"
+	case "c10f1", "c10f2":
+		return "p10"
+	}
+	return branch
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckReachability(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/good/x86_64/base/release" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	local := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(local, "noarch", "base"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(local, "noarch", "base", "release"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, _ := newTestService(t)
+	repos := []Repository{
+		{URL: server.URL + "/good", Arch: "x86_64", Entry: "good"},
+		{URL: server.URL + "/bad", Arch: "x86_64", Entry: "bad"},
+		{URL: "file://" + local, Arch: "noarch", Entry: "local"},
+		{URL: "cdrom:[ALT]/", Arch: "x86_64", Entry: "cdrom"},
+	}
+
+	issues := s.checkReachability(context.Background(), repos)
+	if len(issues) != 1 || issues[0].Entry != "bad" || issues[0].Severity != SeverityError {
+		t.Fatalf("expected only the missing index to be reported, got %+v", issues)
+	}
+}
+
+func TestCheckArch(t *testing.T) {
+	s, _ := newTestService(t)
+	issues := s.checkArch([]Repository{
+		{Arch: "x86_64"}, {Arch: "noarch"}, {Arch: "x86_64-i586"}, {Arch: "aarch64", Entry: "foreign"},
+	})
+	if len(issues) != 1 || issues[0].Entry != "foreign" {
+		t.Errorf("expected only the foreign architecture, got %+v", issues)
+	}
+}
+
+func TestCheckDuplicates(t *testing.T) {
+	issues := checkDuplicates([]Repository{
+		{URL: "http://example.org/repo", Arch: "x86_64", Components: []string{"classic", "gostcrypto"}, File: "a.list"},
+		{URL: "https://example.org/repo/", Arch: "x86_64", Components: []string{"gostcrypto", "classic"}, File: "b.list", Entry: "dup"},
+		{URL: "http://example.org/repo", Arch: "noarch", Components: []string{"classic"}, File: "a.list"},
+	})
+	if len(issues) != 1 || issues[0].Entry != "dup" || !strings.Contains(issues[0].Message, "a.list") {
+		t.Errorf("expected one duplicate pointing to the first file, got %+v", issues)
+	}
+}
+
+func TestCheckArchiveDates(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	archive := "http://" + RepoArchiveURL + "/p10/date/"
+	issues := checkArchiveDates([]Repository{
+		{URL: archive + "2025/01/15", Branch: "p10", Entry: "ok"},
+		{URL: archive + "2025/01/16", Branch: "p10", Entry: "other"},
+		{URL: archive + "2025/02/30", Branch: "p10", Entry: "invalid"},
+		{URL: archive + "2026/01/01", Branch: "p10", Entry: "future"},
+		{URL: "http://" + RepoBaseURL + "/p10/branch", Branch: "p10"},
+	}, now)
+
+	got := make(map[string]string)
+	for _, issue := range issues {
+		got[issue.Entry] = issue.Severity
+	}
+	want := map[string]string{"other": SeverityWarning, "invalid": SeverityError, "future": SeverityError}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected issues: %+v", issues)
+	}
+	for entry, severity := range want {
+		if got[entry] != severity {
+			t.Errorf("%s: expected %s, got %q", entry, severity, got[entry])
+		}
+	}
+}
+
+func TestCheckBranchMix(t *testing.T) {
+	compatible := []Repository{{Branch: "p10"}, {Branch: "c10f2"}, {Branch: "autoimports.p10"}, {Branch: "task"}, {Branch: ""}}
+	if issues := checkBranchMix(compatible); len(issues) != 0 {
+		t.Errorf("expected derived branches to be compatible, got %+v", issues)
+	}
+
+	issues := checkBranchMix([]Repository{{Branch: "p10"}, {Branch: "sisyphus"}})
+	if len(issues) != 1 || !strings.Contains(issues[0].Message, "p10, sisyphus") {
+		t.Errorf("expected p10 and sisyphus mix, got %+v", issues)
+	}
+}
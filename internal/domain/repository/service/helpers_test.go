@@ -54,6 +54,10 @@ func newTestService(t *testing.T) (*RepoService, string) {
 		confDir:            confDir,
 		arch:               "x86_64",
 		useArepo:           true,
+		arepoConfig:        filepath.Join(tmpDir, "apt-repo"),
+		backupDir:          filepath.Join(tmpDir, "backups"),
+		priorityMacro:      filepath.Join(tmpDir, "macros.d", "priority_distbranch"),
+		legacyMacro:        filepath.Join(tmpDir, "macros.d", "p10"),
 		httpClient:         &http.Client{},
 		serviceAptDatabase: db,
 		runner:             runner,
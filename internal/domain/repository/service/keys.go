@@ -0,0 +1,646 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/command"
+	"apm/internal/common/network"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultKeyringDir каталог GPG (homedir) apm, через который APT проверяет подписи репозиториев.
+	// Связка дистрибутива подключается к нему через gpg.conf, новые ключи попадают в собственную связку apm.
+	DefaultKeyringDir = "/etc/apm/gpgkeys"
+	// DefaultDistroKeyringDir каталог GPG с ключами дистрибутива, apm их не изменяет.
+	DefaultDistroKeyringDir = "/usr/lib/alt-gpgkeys"
+	// DefaultAptKeyringConf файл настройки APT, указывающий связку apm.
+	DefaultAptKeyringConf = "/etc/apt/apt.conf.d/apm-gpgkeys.conf"
+	// DefaultVendorsList основной файл описания поставщиков (ключей) APT.
+	DefaultVendorsList = "/etc/apt/vendors.list"
+	// DefaultVendorsListDir каталог дополнительных файлов поставщиков APT.
+	DefaultVendorsListDir = "/etc/apt/vendors.list.d/"
+	// DefaultKeyserver сервер ключей по умолчанию.
+	DefaultKeyserver = "hkps://keys.openpgp.org"
+
+	// vendorFilePrefix префикс файлов поставщиков, созданных apm
+	vendorFilePrefix = "apm-"
+	// maxKeySize ограничение размера ключа, загружаемого по URL
+	maxKeySize = 1 << 20
+)
+
+// KeyStatus результат проверки ключа репозитория
+const (
+	KeyStatusOK            = "ok"
+	KeyStatusUnsigned      = "unsigned"
+	KeyStatusMissingVendor = "missingVendor"
+	KeyStatusMissingKey    = "missingKey"
+)
+
+var (
+	vendorBlockRe = regexp.MustCompile(`simple-key\s+"([^"]+)"\s*\{([^}]*)}`)
+	vendorFprRe   = regexp.MustCompile(`Fingerprint\s+"([^"]+)"`)
+	vendorNameRe  = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+	fingerprintRe = regexp.MustCompile(`^(0[xX])?[0-9A-Fa-f]{40}$`)
+	shortKeyIDRe  = regexp.MustCompile(`^(0[xX])?[0-9A-Fa-f]{8,16}$`)
+)
+
+// Key ключ из связки APT
+type Key struct {
+	KeyID       string   `json:"keyId"`
+	Fingerprint string   `json:"fingerprint"`
+	UserIDs     []string `json:"userIds"`
+	Created     string   `json:"created,omitempty"`
+	Expires     string   `json:"expires,omitempty"`
+	Vendors     []string `json:"vendors,omitempty"`
+	// Distribution ключ из связки дистрибутива
+	Distribution bool `json:"distribution,omitempty"`
+}
+
+// Vendor описание поставщика APT: имя в квадратных скобках источника и отпечаток ключа
+type Vendor struct {
+	Name        string `json:"name"`
+	Fingerprint string `json:"fingerprint"`
+	File        string `json:"file"`
+}
+
+// KeyCheck результат проверки ключа одного источника
+type KeyCheck struct {
+	Entry       string `json:"entry"`
+	Vendor      string `json:"vendor,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Status      string `json:"status"`
+}
+
+// KeyService сервис управления GPG ключами APT
+type KeyService struct {
+	keyringDir  string
+	distroDir   string
+	aptConf     string
+	vendorsMain string
+	vendorsDir  string
+	httpClient  *http.Client
+	runner      commandRunner
+}
+
+// NewKeyService создаёт сервис управления ключами
+func NewKeyService(runner commandRunner) *KeyService {
+	return &KeyService{
+		keyringDir:  DefaultKeyringDir,
+		distroDir:   DefaultDistroKeyringDir,
+		aptConf:     DefaultAptKeyringConf,
+		vendorsMain: DefaultVendorsList,
+		vendorsDir:  DefaultVendorsListDir,
+		httpClient:  network.NewHTTPClient(HTTPTimeout),
+		runner:      runner,
+	}
+}
+
+// ListKeys возвращает ключи связки вместе с именами поставщиков, которые на них ссылаются
+func (s *KeyService) ListKeys(ctx context.Context) ([]Key, error) {
+	distro, err := s.listDir(ctx, s.distroDir)
+	if err != nil {
+		return nil, err
+	}
+	keys := distro
+	if _, errStat := os.Stat(s.keyringDir); errStat == nil {
+		if keys, err = s.listDir(ctx, s.keyringDir); err != nil {
+			return nil, err
+		}
+	}
+
+	fromDistro := make(map[string]bool, len(distro))
+	for _, key := range distro {
+		fromDistro[key.Fingerprint] = true
+	}
+	for i := range keys {
+		keys[i].Distribution = fromDistro[keys[i].Fingerprint]
+	}
+
+	vendors, err := s.GetVendors()
+	if err != nil {
+		return nil, err
+	}
+	for i := range keys {
+		for _, vendor := range vendors {
+			if vendor.Fingerprint == keys[i].Fingerprint {
+				keys[i].Vendors = append(keys[i].Vendors, vendor.Name)
+			}
+		}
+	}
+
+	return keys, nil
+}
+
+// listDir возвращает ключи связки каталога dir
+func (s *KeyService) listDir(ctx context.Context, dir string) ([]Key, error) {
+	if s.distroDir != "" && dir == s.distroDir {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			return []Key{}, nil
+		}
+	}
+	stdout, stderr, err := s.gpgIn(ctx, dir, nil, "--with-colons", "--fingerprint", "--list-keys")
+	if err != nil {
+		if strings.Contains(stderr, "No public key") {
+			return []Key{}, nil
+		}
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to read keyring %s: %s"), dir, gpgMessage(stderr, err))
+	}
+	return parseGPGKeys(stdout), nil
+}
+
+// FindKeys ищет ключи по отпечатку, идентификатору ключа или имени поставщика
+func (s *KeyService) FindKeys(ctx context.Context, id string) ([]Key, error) {
+	keys, err := s.ListKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id = strings.TrimSpace(id)
+	normalized := normalizeFingerprint(id)
+	var found []Key
+	for _, key := range keys {
+		if matchKey(key, id, normalized) {
+			found = append(found, key)
+		}
+	}
+	return found, nil
+}
+
+// matchKey проверяет, соответствует ли ключ идентификатору
+func matchKey(key Key, id, normalized string) bool {
+	for _, vendor := range key.Vendors {
+		if vendor == id {
+			return true
+		}
+	}
+	if normalized == "" {
+		return false
+	}
+	if key.Fingerprint == normalized {
+		return true
+	}
+	return len(normalized) >= 8 && strings.HasSuffix(key.Fingerprint, normalized)
+}
+
+// AddKey импортирует ключ из файла, по https URL или с сервера ключей (если задан keyserver, source - полный
+// отпечаток ключа) и регистрирует для него поставщика name. Отпечаток без keyserver загружается с DefaultKeyserver.
+// Возвращает только новые ключи. При simulate ключ импортируется во временную связку, а связка APT
+// и поставщики не изменяются.
+func (s *KeyService) AddKey(ctx context.Context, source, keyserver, name string, simulate bool) ([]Key, error) {
+	source = strings.TrimSpace(source)
+	if keyserver == "" && (fingerprintRe.MatchString(source) || shortKeyIDRe.MatchString(source)) {
+		if _, err := os.Stat(source); err != nil {
+			keyserver = DefaultKeyserver
+		}
+	}
+	if keyserver != "" && !fingerprintRe.MatchString(source) {
+		return nil, fmt.Errorf(app.TL_(ctx, "Key %s must be specified by its full 40-character fingerprint to be received from a keyserver"), source)
+	}
+	if strings.HasPrefix(source, "http://") {
+		return nil, fmt.Errorf(app.TL_(ctx, "Key URL %s must use https"), source)
+	}
+	if name == "" {
+		name = vendorNameFromSource(source, keyserver != "")
+	}
+	if !vendorNameRe.MatchString(name) {
+		return nil, fmt.Errorf(app.TL_(ctx, "Invalid key name %q: only letters, digits, '.', '_' and '-' are allowed"), name)
+	}
+
+	vendors, err := s.GetVendors()
+	if err != nil {
+		return nil, err
+	}
+	for _, vendor := range vendors {
+		if vendor.Name == name {
+			return nil, fmt.Errorf(app.TL_(ctx, "Key name %s is already used in %s"), name, vendor.File)
+		}
+	}
+
+	before, err := s.ListKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	target := s
+	if simulate {
+		tmpDir, errTemp := os.MkdirTemp("", "apm-keyring-")
+		if errTemp != nil {
+			return nil, fmt.Errorf(app.TL_(ctx, "Failed to create directory %s: %v"), os.TempDir(), errTemp)
+		}
+		defer func() { _ = os.RemoveAll(tmpDir) }()
+
+		scratch := *s
+		scratch.keyringDir = tmpDir
+		target = &scratch
+	} else if err = s.ensureKeyring(); err != nil {
+		return nil, err
+	}
+
+	if err = target.importKey(ctx, source, keyserver); err != nil {
+		return nil, err
+	}
+
+	after, err := target.ListKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(before))
+	for _, key := range before {
+		known[key.Fingerprint] = true
+	}
+
+	var added []Key
+	for _, key := range after {
+		if known[key.Fingerprint] {
+			continue
+		}
+		vendorName := name
+		if len(added) > 0 {
+			vendorName = fmt.Sprintf("%s-%d", name, len(added)+1)
+		}
+		if !simulate {
+			if err = s.writeVendor(vendorName, key); err != nil {
+				return added, err
+			}
+		}
+		key.Vendors = append(key.Vendors, vendorName)
+		added = append(added, key)
+	}
+
+	return added, nil
+}
+
+// importKey импортирует ключ в связку с сервера ключей, по URL или из файла
+func (s *KeyService) importKey(ctx context.Context, source, keyserver string) error {
+	switch {
+	case keyserver != "":
+		return s.receiveKey(ctx, normalizeFingerprint(source), keyserver)
+	case strings.HasPrefix(source, "https://"):
+		data, err := s.download(ctx, source)
+		if err != nil {
+			return err
+		}
+		_, stderr, err := s.gpg(ctx, bytes.NewReader(data), "--import")
+		if err != nil {
+			return fmt.Errorf(app.TL_(ctx, "Failed to import key from %s: %s"), source, gpgMessage(stderr, err))
+		}
+	default:
+		if _, err := os.Stat(source); err != nil {
+			return fmt.Errorf(app.TL_(ctx, "Key file %s not found"), source)
+		}
+		_, stderr, err := s.gpg(ctx, nil, "--import", source)
+		if err != nil {
+			return fmt.Errorf(app.TL_(ctx, "Failed to import key from %s: %s"), source, gpgMessage(stderr, err))
+		}
+	}
+	return nil
+}
+
+// receiveKey получает ключ с сервера ключей во временную связку и переносит его в связку,
+// только если отпечаток полученного ключа совпадает с запрошенным fingerprint
+func (s *KeyService) receiveKey(ctx context.Context, fingerprint, keyserver string) error {
+	tmpDir, err := os.MkdirTemp("", "apm-keyring-")
+	if err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Failed to create directory %s: %v"), os.TempDir(), err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	_, stderr, err := s.gpgIn(ctx, tmpDir, nil, "--keyserver", keyserver, "--recv-keys", fingerprint)
+	if err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Failed to receive key %s from %s: %s"), fingerprint, keyserver, gpgMessage(stderr, err))
+	}
+
+	received, err := s.listDir(ctx, tmpDir)
+	if err != nil {
+		return err
+	}
+	for _, key := range received {
+		if key.Fingerprint != fingerprint {
+			return fmt.Errorf(app.TL_(ctx, "Key %s received from %s does not match the requested fingerprint %s"), key.Fingerprint, keyserver, fingerprint)
+		}
+	}
+	if len(received) == 0 {
+		return fmt.Errorf(app.TL_(ctx, "Key %s was not found on %s"), fingerprint, keyserver)
+	}
+
+	exported := filepath.Join(tmpDir, "key.asc")
+	if _, stderr, err = s.gpgIn(ctx, tmpDir, nil, "--armor", "--output", exported, "--export", fingerprint); err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Failed to receive key %s from %s: %s"), fingerprint, keyserver, gpgMessage(stderr, err))
+	}
+	if _, stderr, err = s.gpg(ctx, nil, "--import", exported); err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Failed to import key from %s: %s"), keyserver, gpgMessage(stderr, err))
+	}
+	return nil
+}
+
+// ensureKeyring создаёт связку apm, подключает к ней связку дистрибутива и указывает её APT
+func (s *KeyService) ensureKeyring() error {
+	if err := os.MkdirAll(s.keyringDir, 0700); err != nil {
+		return fmt.Errorf(app.T_("Failed to create directory %s: %v"), s.keyringDir, err)
+	}
+
+	var gpgConf strings.Builder
+	if s.distroDir != "" {
+		fmt.Fprintf(&gpgConf, "keyring %s\n", filepath.Join(s.distroDir, "pubring.gpg"))
+	}
+	fmt.Fprintf(&gpgConf, "primary-keyring %s\n", filepath.Join(s.keyringDir, "pubring.gpg"))
+	path := filepath.Join(s.keyringDir, "gpg.conf")
+	if err := writeFileAtomic(path, []byte(gpgConf.String()), 0644); err != nil {
+		return fmt.Errorf(app.T_("Failed to write file %s: %v"), path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.aptConf), 0755); err != nil {
+		return fmt.Errorf(app.T_("Failed to create directory %s: %v"), filepath.Dir(s.aptConf), err)
+	}
+	aptConf := fmt.Sprintf("APT::GPG::PubringPath \"%s\";\n", s.keyringDir)
+	if err := writeFileAtomic(s.aptConf, []byte(aptConf), 0644); err != nil {
+		return fmt.Errorf(app.T_("Failed to write file %s: %v"), s.aptConf, err)
+	}
+	return nil
+}
+
+// CheckRemovable запрещает удаление ключей дистрибутива
+func CheckRemovable(keys []Key) error {
+	for _, key := range keys {
+		if key.Distribution {
+			return fmt.Errorf(app.T_("Key %s belongs to the distribution keyring and cannot be removed"), key.Fingerprint)
+		}
+	}
+	return nil
+}
+
+// RemoveKeys удаляет ключи из связки apm вместе с поставщиками, созданными apm
+func (s *KeyService) RemoveKeys(ctx context.Context, keys []Key) error {
+	if err := CheckRemovable(keys); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		_, stderr, err := s.gpg(ctx, nil, "--yes", "--delete-keys", key.Fingerprint)
+		if err != nil {
+			return fmt.Errorf(app.TL_(ctx, "Failed to remove key %s: %s"), key.Fingerprint, gpgMessage(stderr, err))
+		}
+
+		for _, vendor := range key.Vendors {
+			path := filepath.Join(s.vendorsDir, vendorFilePrefix+vendor+".list")
+			if errRemove := os.Remove(path); errRemove != nil && !os.IsNotExist(errRemove) {
+				return fmt.Errorf(app.TL_(ctx, "Failed to remove file %s: %v"), path, errRemove)
+			}
+		}
+	}
+	return nil
+}
+
+// VerifyKeys проверяет, что для каждого подписанного источника описан поставщик и его ключ есть в связке
+func (s *KeyService) VerifyKeys(ctx context.Context, repos []Repository) ([]KeyCheck, error) {
+	keys, err := s.ListKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	vendors, err := s.GetVendors()
+	if err != nil {
+		return nil, err
+	}
+
+	inKeyring := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		inKeyring[key.Fingerprint] = true
+	}
+	byName := make(map[string]Vendor, len(vendors))
+	for _, vendor := range vendors {
+		byName[vendor.Name] = vendor
+	}
+
+	checks := make([]KeyCheck, 0, len(repos))
+	for _, repo := range repos {
+		check := KeyCheck{Entry: repo.Entry, Vendor: entryVendor(repo.Entry)}
+		vendor, ok := byName[check.Vendor]
+		switch {
+		case check.Vendor == "":
+			check.Status = KeyStatusUnsigned
+		case !ok:
+			check.Status = KeyStatusMissingVendor
+		case !inKeyring[vendor.Fingerprint]:
+			check.Fingerprint = vendor.Fingerprint
+			check.Status = KeyStatusMissingKey
+		default:
+			check.Fingerprint = vendor.Fingerprint
+			check.Status = KeyStatusOK
+		}
+		checks = append(checks, check)
+	}
+
+	return checks, nil
+}
+
+// GetVendors возвращает поставщиков из vendors.list и vendors.list.d
+func (s *KeyService) GetVendors() ([]Vendor, error) {
+	files := []string{s.vendorsMain}
+	if matches, err := filepath.Glob(filepath.Join(s.vendorsDir, "*.list")); err == nil {
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+
+	var vendors []Vendor
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf(app.T_("Failed to read %s: %v"), file, err)
+		}
+		vendors = append(vendors, parseVendors(string(data), file)...)
+	}
+	return vendors, nil
+}
+
+// writeVendor записывает описание поставщика для ключа в vendors.list.d
+func (s *KeyService) writeVendor(name string, key Key) error {
+	if err := os.MkdirAll(s.vendorsDir, 0755); err != nil {
+		return fmt.Errorf(app.T_("Failed to create directory %s: %v"), s.vendorsDir, err)
+	}
+
+	userID := ""
+	if len(key.UserIDs) > 0 {
+		userID = strings.ReplaceAll(key.UserIDs[0], `"`, "'")
+	}
+	content := fmt.Sprintf("simple-key \"%s\" {\n\tFingerprint \"%s\";\n\tName \"%s\";\n}\n", name, key.Fingerprint, userID)
+
+	path := filepath.Join(s.vendorsDir, vendorFilePrefix+name+".list")
+	if err := writeFileAtomic(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf(app.T_("Failed to write file %s: %v"), path, err)
+	}
+	return nil
+}
+
+// download загружает ключ по URL
+func (s *KeyService) download(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to download key from %s: %v"), url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to download key from %s: %s"), url, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxKeySize))
+	if err != nil {
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to download key from %s: %v"), url, err)
+	}
+	return data, nil
+}
+
+// gpg выполняет gpg со связкой ключей apm
+func (s *KeyService) gpg(ctx context.Context, stdin io.Reader, args ...string) (string, string, error) {
+	return s.gpgIn(ctx, s.keyringDir, stdin, args...)
+}
+
+// gpgIn выполняет gpg со связкой ключей каталога dir
+func (s *KeyService) gpgIn(ctx context.Context, dir string, stdin io.Reader, args ...string) (string, string, error) {
+	cmd := append([]string{"gpg", "--homedir", dir, "--batch", "--no-tty"}, args...)
+	opts := []command.Option{command.WithQuiet()}
+	if stdin != nil {
+		opts = append(opts, command.WithStdin(stdin))
+	}
+	return s.runner.Run(ctx, cmd, opts...)
+}
+
+// gpgMessage возвращает последнюю строку вывода gpg или текст ошибки
+func gpgMessage(stderr string, err error) string {
+	lines := strings.Split(strings.TrimSpace(stderr), "\n")
+	if last := strings.TrimSpace(lines[len(lines)-1]); last != "" {
+		return strings.TrimPrefix(last, "gpg: ")
+	}
+	return err.Error()
+}
+
+// parseGPGKeys разбирает вывод gpg --with-colons --fingerprint --list-keys
+func parseGPGKeys(output string) []Key {
+	keys := make([]Key, 0)
+	var current *Key
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, ":")
+		if len(fields) < 10 {
+			continue
+		}
+
+		switch fields[0] {
+		case "pub":
+			keys = append(keys, Key{
+				KeyID:   fields[4],
+				UserIDs: []string{},
+				Created: formatGPGTime(fields[5]),
+				Expires: formatGPGTime(fields[6]),
+			})
+			current = &keys[len(keys)-1]
+		case "fpr":
+			if current != nil && current.Fingerprint == "" {
+				current.Fingerprint = fields[9]
+			}
+		case "uid":
+			if current != nil {
+				current.UserIDs = append(current.UserIDs, fields[9])
+			}
+		case "sub":
+			// Отпечатки подключей не относятся к основному ключу
+			current = nil
+		}
+	}
+	return keys
+}
+
+// formatGPGTime переводит время gpg (unix-время или ISO 8601) в RFC3339
+func formatGPGTime(value string) string {
+	if value == "" {
+		return ""
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC().Format(time.RFC3339)
+	}
+	return value
+}
+
+// parseVendors разбирает блоки simple-key файла поставщиков
+func parseVendors(content, file string) []Vendor {
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+			lines = append(lines, line)
+		}
+	}
+
+	var vendors []Vendor
+	for _, match := range vendorBlockRe.FindAllStringSubmatch(strings.Join(lines, "\n"), -1) {
+		fpr := vendorFprRe.FindStringSubmatch(match[2])
+		if fpr == nil {
+			continue
+		}
+		vendors = append(vendors, Vendor{
+			Name:        match[1],
+			Fingerprint: normalizeFingerprint(fpr[1]),
+			File:        file,
+		})
+	}
+	return vendors
+}
+
+// normalizeFingerprint приводит отпечаток к виду без пробелов в верхнем регистре
+func normalizeFingerprint(value string) string {
+	value = strings.TrimPrefix(strings.TrimPrefix(value, "0x"), "0X")
+	return strings.ToUpper(strings.ReplaceAll(value, " ", ""))
+}
+
+// entryVendor возвращает имя поставщика из строки источника: rpm [p11] http://... → p11
+func entryVendor(entry string) string {
+	fields := strings.Fields(strings.TrimLeft(entry, "# "))
+	if len(fields) > 1 && strings.HasPrefix(fields[1], "[") && strings.HasSuffix(fields[1], "]") {
+		return strings.Trim(fields[1], "[]")
+	}
+	return ""
+}
+
+// vendorNameFromSource возвращает имя поставщика по умолчанию: имя файла без расширения или идентификатор ключа
+func vendorNameFromSource(source string, fromKeyserver bool) string {
+	if fromKeyserver {
+		return strings.ToLower(normalizeFingerprint(source))
+	}
+	name := filepath.Base(strings.TrimRight(source, "/"))
+	for _, ext := range []string{".gpg", ".asc", ".key", ".pub"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+	return name
+}
@@ -0,0 +1,333 @@
+package service
+
+import (
+	"apm/internal/common/command"
+	"context"
+	"errors"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const (
+	altFpr    = "A18B1EC7B4C7B5CC2E8CD2B4A4A3E1C7C3BC5C4D"
+	vendorFpr = "0123456789ABCDEF0123456789ABCDEF01234567"
+)
+
+// colonsKey возвращает описание ключа в формате gpg --with-colons
+func colonsKey(fpr, uid string) string {
+	return "pub:-:4096:1:" + fpr[24:] + ":1609459200:::-:::scSC::::::23::0:\n" +
+		"fpr:::::::::" + fpr + ":\n" +
+		"uid:-::::1609459200::HASH::" + uid + "::::::::::0:\n" +
+		"sub:-:4096:1:FFFFFFFFFFFFFFFF:1609459200::::::e::::::23:\n" +
+		"fpr:::::::::FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF:\n"
+}
+
+// fakeKeyring имитирует gpg со связкой ключей в памяти; связка дистрибутива хранится в distro,
+// связки других каталогов в scratch
+type fakeKeyring struct {
+	dir       string
+	distroDir string
+	keys      map[string]string
+	distro    map[string]string
+	scratch   map[string]string
+	imports   map[string]string
+	// served отпечаток ключа, который сервер ключей отдаёт на запрос
+	served map[string]string
+}
+
+func (f *fakeKeyring) run(_ context.Context, args []string, _ ...command.Option) (string, string, error) {
+	keys := f.keys
+	if idx := slices.Index(args, "--homedir"); idx >= 0 && args[idx+1] == f.distroDir {
+		keys = f.distro
+	} else if idx >= 0 && args[idx+1] != f.dir {
+		keys = f.scratch
+	}
+
+	switch {
+	case slices.Contains(args, "--list-keys"):
+		var out strings.Builder
+		for _, fpr := range slices.Sorted(maps.Keys(keys)) {
+			out.WriteString(colonsKey(fpr, keys[fpr]))
+		}
+		return out.String(), "", nil
+	case slices.Contains(args, "--recv-keys"):
+		fpr, ok := f.served[args[len(args)-1]]
+		if !ok {
+			return "", "gpg: keyserver receive failed: No data\n", errors.New("exit status 2")
+		}
+		keys[fpr] = "Vendor <vendor@example.org>"
+		return "", "", nil
+	case slices.Contains(args, "--export"):
+		if idx := slices.Index(args, "--output"); idx >= 0 {
+			f.imports[args[idx+1]] = args[len(args)-1]
+		}
+		return "", "", nil
+	case slices.Contains(args, "--import"):
+		fpr, ok := f.imports[args[len(args)-1]]
+		if !ok {
+			return "", "gpg: no valid OpenPGP data found.\n", errors.New("exit status 2")
+		}
+		keys[fpr] = "Vendor <vendor@example.org>"
+		return "", "", nil
+	case slices.Contains(args, "--delete-keys"):
+		delete(keys, args[len(args)-1])
+		return "", "", nil
+	}
+	return "", "", errors.New("unexpected command")
+}
+
+func newTestKeyService(t *testing.T) (*KeyService, *fakeKeyring) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	keyring := &fakeKeyring{
+		dir:       filepath.Join(tmpDir, "keyring"),
+		distroDir: filepath.Join(tmpDir, "alt-gpgkeys"),
+		keys:      map[string]string{altFpr: "ALT Linux Team <team@altlinux.org>"},
+		distro:    map[string]string{altFpr: "ALT Linux Team <team@altlinux.org>"},
+		scratch:   map[string]string{},
+		imports:   map[string]string{},
+		served:    map[string]string{},
+	}
+	s := &KeyService{
+		keyringDir:  filepath.Join(tmpDir, "keyring"),
+		distroDir:   filepath.Join(tmpDir, "alt-gpgkeys"),
+		aptConf:     filepath.Join(tmpDir, "apt.conf.d", "apm-gpgkeys.conf"),
+		vendorsMain: filepath.Join(tmpDir, "vendors.list"),
+		vendorsDir:  filepath.Join(tmpDir, "vendors.list.d"),
+		httpClient:  &http.Client{},
+		runner:      &mockRunner{runFunc: keyring.run},
+	}
+	vendors := "# system vendors\nsimple-key \"p11\" {\n\tFingerprint \"A18B 1EC7 B4C7 B5CC 2E8C  D2B4 A4A3 E1C7 C3BC 5C4D\";\n\tName \"ALT Linux Team\";\n}\n" +
+		"simple-key \"lost\" {\n\tFingerprint \"1111111111111111111111111111111111111111\";\n}\n"
+	if err := os.WriteFile(s.vendorsMain, []byte(vendors), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(s.distroDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	return s, keyring
+}
+
+func TestParseGPGKeys(t *testing.T) {
+	keys := parseGPGKeys(colonsKey(altFpr, "ALT Linux Team <team@altlinux.org>"))
+	if len(keys) != 1 {
+		t.Fatalf("expected 1 key, got %d", len(keys))
+	}
+	key := keys[0]
+	if key.Fingerprint != altFpr || key.KeyID != altFpr[24:] {
+		t.Errorf("expected primary key fingerprint, got %+v", key)
+	}
+	if len(key.UserIDs) != 1 || key.Created != "2021-01-01T00:00:00Z" || key.Expires != "" {
+		t.Errorf("unexpected key details: %+v", key)
+	}
+}
+
+func TestListKeysWithVendors(t *testing.T) {
+	s, _ := newTestKeyService(t)
+
+	keys, err := s.ListKeys(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 1 || !slices.Equal(keys[0].Vendors, []string{"p11"}) || !keys[0].Distribution {
+		t.Errorf("expected distribution key with vendor p11, got %+v", keys)
+	}
+
+	found, err := s.FindKeys(context.Background(), "c3bc5c4d")
+	if err != nil || len(found) != 1 {
+		t.Errorf("expected key found by short id, got %+v (%v)", found, err)
+	}
+	found, _ = s.FindKeys(context.Background(), "p11")
+	if len(found) != 1 {
+		t.Errorf("expected key found by vendor name, got %+v", found)
+	}
+}
+
+func TestAddAndRemoveKey(t *testing.T) {
+	s, keyring := newTestKeyService(t)
+	ctx := context.Background()
+	keyFile := filepath.Join(t.TempDir(), "vendor.asc")
+	if err := os.WriteFile(keyFile, []byte("key"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	keyring.imports[keyFile] = vendorFpr
+
+	t.Run("simulate imports into a temporary keyring", func(t *testing.T) {
+		added, err := s.AddKey(ctx, keyFile, "", "", true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(added) != 1 || added[0].Fingerprint != vendorFpr || !slices.Equal(added[0].Vendors, []string{"vendor"}) {
+			t.Fatalf("unexpected added keys: %+v", added)
+		}
+		if _, ok := keyring.keys[vendorFpr]; ok {
+			t.Error("key must not be imported into the APT keyring")
+		}
+		if _, err = os.Stat(filepath.Join(s.vendorsDir, "apm-vendor.list")); !os.IsNotExist(err) {
+			t.Errorf("vendor file must not be written: %v", err)
+		}
+	})
+
+	t.Run("name is taken from file name", func(t *testing.T) {
+		added, err := s.AddKey(ctx, keyFile, "", "", false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(added) != 1 || added[0].Fingerprint != vendorFpr || !slices.Equal(added[0].Vendors, []string{"vendor"}) {
+			t.Fatalf("unexpected added keys: %+v", added)
+		}
+
+		data, err := os.ReadFile(filepath.Join(s.vendorsDir, "apm-vendor.list"))
+		if err != nil {
+			t.Fatalf("expected vendor file: %v", err)
+		}
+		if !strings.Contains(string(data), `Fingerprint "`+vendorFpr+`"`) {
+			t.Errorf("unexpected vendor file: %s", data)
+		}
+
+		data, err = os.ReadFile(s.aptConf)
+		if err != nil || !strings.Contains(string(data), `APT::GPG::PubringPath "`+s.keyringDir+`"`) {
+			t.Errorf("expected APT to be pointed to the apm keyring, got %q (%v)", data, err)
+		}
+		data, err = os.ReadFile(filepath.Join(s.keyringDir, "gpg.conf"))
+		if err != nil || !strings.Contains(string(data), "keyring "+filepath.Join(s.distroDir, "pubring.gpg")) {
+			t.Errorf("expected distribution keyring in gpg.conf, got %q (%v)", data, err)
+		}
+	})
+
+	t.Run("existing key is not reported", func(t *testing.T) {
+		added, err := s.AddKey(ctx, keyFile, "", "vendor2", false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(added) != 0 {
+			t.Errorf("expected no new keys, got %+v", added)
+		}
+	})
+
+	t.Run("used name is rejected", func(t *testing.T) {
+		if _, err := s.AddKey(ctx, keyFile, "", "p11", false); err == nil {
+			t.Error("expected error for used vendor name")
+		}
+		if _, err := s.AddKey(ctx, keyFile, "", "bad name", false); err == nil {
+			t.Error("expected error for invalid vendor name")
+		}
+	})
+
+	t.Run("distribution key cannot be removed", func(t *testing.T) {
+		keys, err := s.FindKeys(ctx, "p11")
+		if err != nil || len(keys) != 1 {
+			t.Fatalf("expected distribution key, got %+v (%v)", keys, err)
+		}
+		if err = s.RemoveKeys(ctx, keys); err == nil {
+			t.Error("expected error when removing a distribution key")
+		}
+		if _, ok := keyring.keys[altFpr]; !ok {
+			t.Error("distribution key must be kept")
+		}
+	})
+
+	t.Run("remove deletes key and vendor file", func(t *testing.T) {
+		keys, err := s.FindKeys(ctx, "vendor")
+		if err != nil || len(keys) != 1 {
+			t.Fatalf("expected key by vendor name, got %+v (%v)", keys, err)
+		}
+		if err = s.RemoveKeys(ctx, keys); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := keyring.keys[vendorFpr]; ok {
+			t.Error("expected key removed from keyring")
+		}
+		if _, err = os.Stat(filepath.Join(s.vendorsDir, "apm-vendor.list")); !os.IsNotExist(err) {
+			t.Error("expected vendor file removed")
+		}
+	})
+}
+
+func TestVerifyKeys(t *testing.T) {
+	s, _ := newTestKeyService(t)
+
+	repos := []Repository{
+		{Entry: "rpm [p11] http://ftp.altlinux.org/pub/distributions/ALTLinux/p11/branch x86_64 classic"},
+		{Entry: "rpm [lost] http://example.org/repo x86_64 classic"},
+		{Entry: "rpm [other] http://example.org/other x86_64 classic"},
+		{Entry: "rpm http://example.org/unsigned x86_64 classic"},
+	}
+
+	checks, err := s.VerifyKeys(context.Background(), repos)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{KeyStatusOK, KeyStatusMissingKey, KeyStatusMissingVendor, KeyStatusUnsigned}
+	for i, check := range checks {
+		if check.Status != expected[i] {
+			t.Errorf("%s: expected %s, got %s", check.Entry, expected[i], check.Status)
+		}
+	}
+}
+
+func TestAddKeyFromKeyserver(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("full fingerprint is received and verified", func(t *testing.T) {
+		s, keyring := newTestKeyService(t)
+		keyring.served[vendorFpr] = vendorFpr
+
+		added, err := s.AddKey(ctx, "0x"+strings.ToLower(vendorFpr), "", "vendor", false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(added) != 1 || added[0].Fingerprint != vendorFpr {
+			t.Fatalf("unexpected added keys: %+v", added)
+		}
+		if _, ok := keyring.keys[vendorFpr]; !ok {
+			t.Error("expected key imported into the apm keyring")
+		}
+	})
+
+	t.Run("short key ID is rejected", func(t *testing.T) {
+		s, keyring := newTestKeyService(t)
+		keyring.served[vendorFpr[24:]] = vendorFpr
+
+		if _, err := s.AddKey(ctx, vendorFpr[24:], "", "vendor", false); err == nil {
+			t.Fatal("expected error for short key ID")
+		}
+		if _, err := s.AddKey(ctx, vendorFpr[32:], "hkps://keyserver.example.org", "vendor", false); err == nil {
+			t.Fatal("expected error for short key ID with keyserver")
+		}
+		if _, ok := keyring.keys[vendorFpr]; ok {
+			t.Error("key must not be imported")
+		}
+	})
+
+	t.Run("key with another fingerprint is rejected", func(t *testing.T) {
+		s, keyring := newTestKeyService(t)
+		spoofed := "FEDCBA9876543210FEDCBA9876543210FEDCBA98"
+		keyring.served[vendorFpr] = spoofed
+
+		if _, err := s.AddKey(ctx, vendorFpr, "", "vendor", false); err == nil {
+			t.Fatal("expected error for mismatching fingerprint")
+		}
+		if _, ok := keyring.keys[spoofed]; ok {
+			t.Error("spoofed key must not reach the apm keyring")
+		}
+		if _, err := os.Stat(filepath.Join(s.vendorsDir, "apm-vendor.list")); !os.IsNotExist(err) {
+			t.Errorf("vendor file must not be written: %v", err)
+		}
+	})
+}
+
+func TestAddKeyRejectsPlainHTTP(t *testing.T) {
+	s, _ := newTestKeyService(t)
+
+	_, err := s.AddKey(context.Background(), "http://example.org/vendor.asc", "", "", false)
+	if err == nil || !strings.Contains(err.Error(), "https") {
+		t.Errorf("expected https error, got %v", err)
+	}
+}
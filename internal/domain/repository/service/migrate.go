@@ -0,0 +1,178 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"apm/internal/common/app"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// MigrationChange изменение строки источника при переходе с apt-repo. Пустой New означает удалённый повтор.
+type MigrationChange struct {
+	File string `json:"file"`
+	Old  string `json:"old"`
+	New  string `json:"new,omitempty"`
+}
+
+// Migration результат перевода источников apt-repo под управление apm
+type Migration struct {
+	Changes        []MigrationChange `json:"changes"`
+	PriorityBranch string            `json:"priorityBranch,omitempty"`
+	PriorityMacro  bool              `json:"priorityMacro"`
+	LegacyMacro    bool              `json:"legacyMacro"`
+	TaskMeta       []string          `json:"taskMeta,omitempty"`
+}
+
+// MigrateAptRepo приводит источники, созданные apt-repo, к виду, который ведёт apm: строки формата
+// new_format переписываются в канонический вид, повторы активных источников удаляются, макрос
+// приоритета ветки пересоздаётся, а устаревший макрос p10 удаляется. При simulate файлы не меняются.
+func (s *RepoService) MigrateAptRepo(ctx context.Context, simulate bool) (*Migration, error) {
+	s.ensureInitialized()
+	migration := &Migration{Changes: []MigrationChange{}}
+
+	files, err := s.getSourceFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, file := range files {
+		changes, errFile := s.migrateSourceFile(file, seen, simulate)
+		if errFile != nil {
+			return migration, errFile
+		}
+		migration.Changes = append(migration.Changes, changes...)
+	}
+
+	repos, err := s.GetRepositories(ctx, false)
+	if err != nil {
+		return migration, err
+	}
+
+	if err = s.migratePriorityMacro(repos, migration, simulate); err != nil {
+		return migration, err
+	}
+
+	var tasks []Repository
+	for _, repo := range repos {
+		if isTaskRepo(repo) && repo.Task == nil {
+			tasks = append(tasks, repo)
+			migration.TaskMeta = append(migration.TaskMeta, repo.Entry)
+		}
+	}
+	if !simulate && len(tasks) > 0 {
+		s.storeTaskInfo(ctx, tasks)
+	}
+
+	return migration, nil
+}
+
+// Changed сообщает, что миграция меняет хотя бы один файл
+func (m *Migration) Changed() bool {
+	return len(m.Changes) > 0 || m.PriorityMacro || m.LegacyMacro || len(m.TaskMeta) > 0
+}
+
+// migrateSourceFile переписывает строки одного файла источников. seen накапливает
+// канонические строки активных источников из уже обработанных файлов.
+func (s *RepoService) migrateSourceFile(file string, seen map[string]bool, simulate bool) ([]MigrationChange, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf(app.T_("Failed to read %s: %v"), file, err)
+	}
+
+	var changes []MigrationChange
+	lines := strings.Split(string(content), "\n")
+	result := make([]string, 0, len(lines))
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		body, commented := strings.CutPrefix(trimmed, "#")
+		body = strings.TrimSpace(body)
+		if !strings.HasPrefix(body, "rpm") || parseTaskMeta(trimmed) != nil {
+			result = append(result, line)
+			continue
+		}
+
+		canonical := canonicalizeRepoLine(body)
+		if !commented {
+			if seen[canonical] {
+				changes = append(changes, MigrationChange{File: file, Old: trimmed})
+				continue
+			}
+			seen[canonical] = true
+		}
+
+		if canonical == body {
+			result = append(result, line)
+			continue
+		}
+
+		migrated := canonical
+		if commented {
+			migrated = "# " + canonical
+		}
+		changes = append(changes, MigrationChange{File: file, Old: trimmed, New: migrated})
+		result = append(result, migrated)
+	}
+
+	if simulate || len(changes) == 0 {
+		return changes, nil
+	}
+
+	if err = writeFileAtomic(file, []byte(strings.Join(result, "\n")), 0644); err != nil {
+		return changes, fmt.Errorf(app.T_("Failed to write to %s: %v"), file, err)
+	}
+	return changes, nil
+}
+
+// migratePriorityMacro пересоздаёт макрос приоритета по подключённой ветке и удаляет устаревший макрос p10
+func (s *RepoService) migratePriorityMacro(repos []Repository, migration *Migration, simulate bool) error {
+	for _, repo := range repos {
+		branch := strings.ToLower(repo.Branch)
+		if slices.Contains(priorityMacroBranches, branch) && !archiveDatePattern.MatchString(repo.URL) {
+			migration.PriorityBranch = branch
+			break
+		}
+	}
+
+	if _, err := os.Stat(s.legacyMacro); err == nil {
+		migration.LegacyMacro = true
+	}
+
+	if migration.PriorityBranch != "" {
+		want := fmt.Sprintf("%%_priority_distbranch %s\n", migration.PriorityBranch)
+		current, _ := os.ReadFile(s.priorityMacro)
+		migration.PriorityMacro = string(current) != want
+	}
+
+	if simulate {
+		return nil
+	}
+	if migration.LegacyMacro {
+		if err := os.Remove(s.legacyMacro); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf(app.T_("Failed to remove %s: %v"), s.legacyMacro, err)
+		}
+	}
+	if migration.PriorityMacro {
+		s.setPriorityMacro(migration.PriorityBranch, "")
+	}
+	return nil
+}
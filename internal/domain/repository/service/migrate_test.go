@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrateAptRepo(t *testing.T) {
+	ctx := context.Background()
+
+	const aptRepoSources = "# apt-repo sources\n" +
+		"rpm [p11] http://ftp.altlinux.org/pub/distributions/ALTLinux p11/branch/x86_64 classic\n" +
+		"rpm [p11] http://ftp.altlinux.org/pub/distributions/ALTLinux p11/branch/noarch classic\n" +
+		"#rpm [p11] http://ftp.altlinux.org/pub/distributions/ALTLinux p11/branch/x86_64-i586 classic\n" +
+		"rpm http://example.com/vendor x86_64 main\n"
+
+	t.Run("simulate reports changes without writing", func(t *testing.T) {
+		s, _ := newTestService(t)
+		writeSourcesList(t, s, aptRepoSources)
+		writeExtraList(t, s, "dup.list", "rpm [p11] http://ftp.altlinux.org/pub/distributions/ALTLinux/p11/branch x86_64 classic\n")
+
+		migration, err := s.MigrateAptRepo(ctx, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(migration.Changes) != 4 {
+			t.Fatalf("expected 4 changes, got %+v", migration.Changes)
+		}
+		if last := migration.Changes[3]; last.New != "" || last.File != filepath.Join(s.confDir, "dup.list") {
+			t.Errorf("expected duplicate removal from dup.list, got %+v", last)
+		}
+		if migration.PriorityBranch != "p11" || !migration.PriorityMacro {
+			t.Errorf("expected p11 priority macro, got %+v", migration)
+		}
+		if readSourcesList(t, s) != aptRepoSources {
+			t.Error("simulation must not change sources.list")
+		}
+		if _, err = os.Stat(s.priorityMacro); !os.IsNotExist(err) {
+			t.Error("simulation must not write the priority macro")
+		}
+	})
+
+	t.Run("rewrites sources and macros", func(t *testing.T) {
+		s, _ := newTestService(t)
+		writeSourcesList(t, s, aptRepoSources)
+		if err := os.MkdirAll(filepath.Dir(s.legacyMacro), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(s.legacyMacro, []byte("%_priority_distbranch p10\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		migration, err := s.MigrateAptRepo(ctx, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !migration.LegacyMacro {
+			t.Error("expected legacy macro to be reported")
+		}
+
+		content := readSourcesList(t, s)
+		for _, want := range []string{
+			"rpm [p11] http://ftp.altlinux.org/pub/distributions/ALTLinux/p11/branch x86_64 classic\n",
+			"# rpm [p11] http://ftp.altlinux.org/pub/distributions/ALTLinux/p11/branch x86_64-i586 classic\n",
+			"rpm http://example.com/vendor x86_64 main\n",
+		} {
+			if !strings.Contains(content, want) {
+				t.Errorf("sources.list missing %q:\n%s", want, content)
+			}
+		}
+
+		macro, err := os.ReadFile(s.priorityMacro)
+		if err != nil || string(macro) != "%_priority_distbranch p11\n" {
+			t.Errorf("unexpected priority macro %q: %v", macro, err)
+		}
+		if _, err = os.Stat(s.legacyMacro); !os.IsNotExist(err) {
+			t.Error("legacy p10 macro must be removed")
+		}
+
+		again, err := s.MigrateAptRepo(ctx, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if again.Changed() {
+			t.Errorf("second migration must be a no-op, got %+v", again)
+		}
+	})
+}
@@ -22,19 +22,29 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
 // AddRepository добавляет репозиторий
 func (s *RepoService) AddRepository(ctx context.Context, args []string, date string) ([]Repository, error) {
 	s.ensureInitialized()
+	args, date, err := s.expandSourceArgs(ctx, args, date)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args) == 1 && isSourceFile(strings.TrimSpace(args[0])) {
+		return s.addSourceFile(ctx, strings.TrimSpace(args[0]), false)
+	}
+
 	urls, err := s.parseSourceArgs(ctx, args, date)
 	if err != nil {
 		return nil, err
 	}
 
 	if len(urls) == 0 {
-		return nil, errors.New(app.T_("Failed to parse repository source"))
+		return nil, errors.New(app.TL_(ctx, "Failed to parse repository source"))
 	}
 
 	var added []Repository
@@ -72,6 +82,8 @@ func (s *RepoService) AddRepository(ctx context.Context, args []string, date str
 		s.setPriorityMacro(args[0], date)
 	}
 
+	s.storeTaskInfo(ctx, added)
+
 	return added, nil
 }
 
@@ -81,7 +93,7 @@ func (s *RepoService) RemoveRepository(ctx context.Context, args []string, date
 	var removed []Repository
 
 	if len(args) == 0 {
-		return nil, errors.New(app.T_("Repository source must be specified"))
+		return nil, errors.New(app.TL_(ctx, "Repository source must be specified"))
 	}
 
 	source := args[0]
@@ -157,7 +169,7 @@ func (s *RepoService) RemoveRepository(ctx context.Context, args []string, date
 func (s *RepoService) SetBranch(ctx context.Context, branch, date string) (added []Repository, removed []Repository, err error) {
 	s.ensureInitialized()
 	if _, ok := s.branches[branch]; !ok {
-		return nil, nil, fmt.Errorf(app.T_("Unknown branch: %s"), branch)
+		return nil, nil, fmt.Errorf(app.TL_(ctx, "Unknown branch: %s"), branch)
 	}
 
 	removed, err = s.RemoveRepository(ctx, []string{"all"}, "", false)
@@ -209,6 +221,10 @@ func (s *RepoService) CleanTemporary(ctx context.Context) ([]Repository, error)
 // SimulateAdd симулирует добавление репозитория
 func (s *RepoService) SimulateAdd(ctx context.Context, args []string, date string, force bool) ([]Repository, error) {
 	s.ensureInitialized()
+	if len(args) == 1 && isSourceFile(strings.TrimSpace(args[0])) {
+		return s.addSourceFile(ctx, strings.TrimSpace(args[0]), true)
+	}
+
 	urls, err := s.parseSourceArgs(ctx, args, date)
 	if err != nil {
 		return nil, err
@@ -233,7 +249,7 @@ func (s *RepoService) SimulateRemove(ctx context.Context, args []string, date st
 	var willRemove []Repository
 
 	if len(args) == 0 {
-		return nil, errors.New(app.T_("Repository source must be specified"))
+		return nil, errors.New(app.TL_(ctx, "Repository source must be specified"))
 	}
 
 	source := args[0]
@@ -272,23 +288,24 @@ func (s *RepoService) SimulateRemove(ctx context.Context, args []string, date st
 	return willRemove, nil
 }
 
+// priorityMacroBranches ветки, для которых apm ведёт макрос %_priority_distbranch
+var priorityMacroBranches = []string{"p10", "p11", "sisyphus"}
+
 // setPriorityMacro устанавливает макрос %_priority_distbranch
 func (s *RepoService) setPriorityMacro(source, date string) {
 	if date != "" {
 		return
 	}
 
-	priorityBranches := []string{"p10", "p11", "sisyphus"}
-
-	for _, pb := range priorityBranches {
+	for _, pb := range priorityMacroBranches {
 		if source == pb {
-			if err := os.MkdirAll(RPMMacrosDir, 0755); err != nil {
+			if err := os.MkdirAll(filepath.Dir(s.priorityMacro), 0755); err != nil {
 				app.Log.Debugf("failed to create macros dir: %v", err)
 				return
 			}
 
 			content := fmt.Sprintf("%%_priority_distbranch %s\n", source)
-			if err := os.WriteFile(PriorityDistbranchMacro, []byte(content), 0644); err != nil {
+			if err := writeFileAtomic(s.priorityMacro, []byte(content), 0644); err != nil {
 				app.Log.Debugf("failed to write priority macro: %v", err)
 			}
 			return
@@ -298,10 +315,10 @@ func (s *RepoService) setPriorityMacro(source, date string) {
 
 // removePriorityMacro удаляет макрос приоритета
 func (s *RepoService) removePriorityMacro() {
-	if err := os.Remove(PriorityDistbranchMacro); err != nil && !os.IsNotExist(err) {
+	if err := os.Remove(s.priorityMacro); err != nil && !os.IsNotExist(err) {
 		app.Log.Debugf("failed to remove priority macro: %v", err)
 	}
-	if err := os.Remove(LegacyP10Macro); err != nil && !os.IsNotExist(err) {
+	if err := os.Remove(s.legacyMacro); err != nil && !os.IsNotExist(err) {
 		app.Log.Debugf("failed to remove legacy macro: %v", err)
 	}
 }
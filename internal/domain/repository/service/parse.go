@@ -29,7 +29,12 @@ import (
 // parseSourceArgs парсит аргументы в URL
 func (s *RepoService) parseSourceArgs(ctx context.Context, args []string, date string) ([]string, error) {
 	if len(args) == 0 {
-		return nil, errors.New(app.T_("Repository source must be specified"))
+		return nil, errors.New(app.TL_(ctx, "Repository source must be specified"))
+	}
+
+	args, date, err := s.expandSourceArgs(ctx, args, date)
+	if err != nil {
+		return nil, err
 	}
 
 	date = strings.TrimSpace(date)
@@ -93,7 +98,7 @@ func (s *RepoService) parseSource(ctx context.Context, source, date string) ([]s
 		return []string{source}, nil
 	}
 
-	return nil, fmt.Errorf(app.T_("Unknown repository format: %s"), source)
+	return nil, fmt.Errorf(app.TL_(ctx, "Unknown repository format: %s"), source)
 }
 
 // buildRepoFromArgs собирает строку репозитория из аргументов [type, url, arch, components...]
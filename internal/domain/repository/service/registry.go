@@ -0,0 +1,154 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"apm/internal/common/app"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// DefaultRegistryDirs директории манифестов именованных репозиториев. Запись из /etc переопределяет одноимённую запись дистрибутива.
+var DefaultRegistryDirs = []string{
+	"/usr/share/apm/repos.d",
+	"/etc/apm/repos.d",
+}
+
+// NamedRepository известный сторонний репозиторий, подключаемый по имени.
+// Sources - источники в любом формате, понятном AddRepository: ветка, URL, строка rpm или файл .list.
+// Key - файл, https URL или полный отпечаток ключа, импортируемый под именем KeyName (по умолчанию Name).
+type NamedRepository struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Homepage    string   `json:"homepage,omitempty"`
+	Sources     []string `json:"sources"`
+	Key         string   `json:"key,omitempty"`
+	Keyserver   string   `json:"keyserver,omitempty"`
+	KeyName     string   `json:"keyName,omitempty"`
+	File        string   `json:"file,omitempty"`
+	Enabled     bool     `json:"enabled"`
+}
+
+// VendorName возвращает имя, под которым импортируется ключ репозитория
+func (r NamedRepository) VendorName() string {
+	if r.KeyName != "" {
+		return r.KeyName
+	}
+	return r.Name
+}
+
+// registryManifest содержимое JSON манифеста реестра
+type registryManifest struct {
+	Repositories []NamedRepository `json:"repositories"`
+}
+
+// builtinNamedRepositories возвращает репозитории, известные apm без манифестов
+func builtinNamedRepositories() []NamedRepository {
+	return []NamedRepository{
+		{
+			Name:        "autoimports",
+			Description: app.T_("Packages automatically imported from other distributions for the current branch"),
+			Homepage:    "https://www.altlinux.org/Autoimports",
+			Sources:     []string{"autoimports.$(BRANCH)"},
+		},
+	}
+}
+
+// NamedRepositories возвращает реестр именованных репозиториев, отсортированный по имени
+func (s *RepoService) NamedRepositories() ([]NamedRepository, error) {
+	byName := make(map[string]NamedRepository)
+	for _, repo := range builtinNamedRepositories() {
+		byName[repo.Name] = repo
+	}
+
+	for _, dir := range s.registryDirs {
+		files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		slices.Sort(files)
+
+		for _, file := range files {
+			repos, err := readRegistryManifest(file)
+			if err != nil {
+				return nil, err
+			}
+			for _, repo := range repos {
+				byName[repo.Name] = repo
+			}
+		}
+	}
+
+	repos := make([]NamedRepository, 0, len(byName))
+	for _, repo := range byName {
+		repos = append(repos, repo)
+	}
+	slices.SortFunc(repos, func(a, b NamedRepository) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return repos, nil
+}
+
+// readRegistryManifest читает и проверяет манифест реестра
+func readRegistryManifest(file string) ([]NamedRepository, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf(app.T_("Failed to read registry manifest %s: %v"), file, err)
+	}
+
+	var manifest registryManifest
+	if err = json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf(app.T_("Failed to parse registry manifest %s: %v"), file, err)
+	}
+
+	for i := range manifest.Repositories {
+		repo := &manifest.Repositories[i]
+		repo.File = file
+		repo.Enabled = false
+		if err = repo.validate(); err != nil {
+			return nil, fmt.Errorf(app.T_("Invalid registry manifest %s: %v"), file, err)
+		}
+	}
+	return manifest.Repositories, nil
+}
+
+// validate проверяет имя, источники и ключ записи реестра
+func (r NamedRepository) validate() error {
+	if !vendorNameRe.MatchString(r.Name) {
+		return fmt.Errorf(app.T_("invalid repository name %q"), r.Name)
+	}
+	if len(r.Sources) == 0 {
+		return fmt.Errorf(app.T_("repository %s has no sources"), r.Name)
+	}
+	for _, source := range r.Sources {
+		if strings.TrimSpace(source) == "" {
+			return fmt.Errorf(app.T_("repository %s has an empty source"), r.Name)
+		}
+	}
+	if r.Key == "" && (r.Keyserver != "" || r.KeyName != "") {
+		return errors.New(app.T_("keyserver and keyName require key"))
+	}
+	if r.KeyName != "" && !vendorNameRe.MatchString(r.KeyName) {
+		return fmt.Errorf(app.T_("invalid key name %q"), r.KeyName)
+	}
+	return nil
+}
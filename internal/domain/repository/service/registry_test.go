@@ -0,0 +1,74 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNamedRepositories(t *testing.T) {
+	s, tmpDir := newTestService(t)
+	vendorDir := filepath.Join(tmpDir, "share")
+	localDir := filepath.Join(tmpDir, "etc")
+	s.registryDirs = []string{vendorDir, filepath.Join(tmpDir, "missing"), localDir}
+
+	for _, dir := range []string{vendorDir, localDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeManifest := func(dir, name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeManifest(vendorDir, "vendor.json", `{"repositories": [
+		{"name": "vendor", "description": "Vendor", "sources": ["rpm [vendor] http://example.com/vendor x86_64 main"], "key": "https://example.com/vendor.asc"},
+		{"name": "extra", "description": "Extra", "sources": ["https://example.com/extra.list"]}
+	]}`)
+	writeManifest(localDir, "override.json", `{"repositories": [
+		{"name": "vendor", "description": "Local mirror", "sources": ["rpm [vendor] http://mirror.local/vendor x86_64 main"]}
+	]}`)
+	writeManifest(localDir, "ignored.txt", `not a manifest`)
+
+	repos, err := s.NamedRepositories()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := make([]string, 0, len(repos))
+	for _, repo := range repos {
+		names = append(names, repo.Name)
+	}
+	if len(names) != 3 || names[0] != "autoimports" || names[1] != "extra" || names[2] != "vendor" {
+		t.Fatalf("unexpected registry: %v", names)
+	}
+	if repos[2].Description != "Local mirror" || repos[2].File != filepath.Join(localDir, "override.json") {
+		t.Errorf("local manifest must override vendor entry: %+v", repos[2])
+	}
+	if repos[2].VendorName() != "vendor" {
+		t.Errorf("VendorName() = %q, want vendor", repos[2].VendorName())
+	}
+}
+
+func TestReadRegistryManifestInvalid(t *testing.T) {
+	tests := map[string]string{
+		"broken json":    `{"repositories": [`,
+		"bad name":       `{"repositories": [{"name": "bad name", "sources": ["p11"]}]}`,
+		"no sources":     `{"repositories": [{"name": "vendor"}]}`,
+		"keyName no key": `{"repositories": [{"name": "vendor", "sources": ["p11"], "keyName": "vendor"}]}`,
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "manifest.json")
+			if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := readRegistryManifest(file); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -17,9 +17,13 @@
 package service
 
 import (
+	"apm/internal/common/app"
 	"apm/internal/common/command"
+	"apm/internal/common/network"
+	"apm/internal/common/reply"
 	"bufio"
 	"context"
+	"fmt"
 	"net/http"
 	"os"
 	"regexp"
@@ -63,13 +67,14 @@ const (
 
 // Repository представляет информацию о репозитории
 type Repository struct {
-	URL        string   `json:"url"`
-	Arch       string   `json:"arch"`
-	Components []string `json:"components"`
-	Active     bool     `json:"active"`
-	File       string   `json:"file"`
-	Entry      string   `json:"entry"`
-	Branch     string   `json:"branch"`
+	URL        string    `json:"url"`
+	Arch       string    `json:"arch"`
+	Components []string  `json:"components"`
+	Active     bool      `json:"active"`
+	File       string    `json:"file"`
+	Entry      string    `json:"entry"`
+	Branch     string    `json:"branch"`
+	Task       *TaskInfo `json:"task,omitempty"`
 }
 
 // Branch представляет информацию о ветке ALT Linux
@@ -87,24 +92,34 @@ type RepoService struct {
 	arch               string
 	branches           map[string]Branch
 	useArepo           bool
+	arepoConfig        string
+	backupDir          string
+	registryDirs       []string
+	priorityMacro      string
+	legacyMacro        string
 	httpClient         *http.Client
 	serviceAptDatabase packageDBService
 	runner             commandRunner
+	reporter           *reply.Reporter
 	initOnce           sync.Once
 }
 
 // NewRepoService создает новый сервис для работы с репозиториями
-func NewRepoService(dbService packageDBService, runner commandRunner) *RepoService {
+func NewRepoService(dbService packageDBService, runner commandRunner, reporter *reply.Reporter) *RepoService {
 	return &RepoService{
-		confMain: DefaultSourcesList,
-		confDir:  DefaultSourcesListDir,
-		arch:     detectArch(runner),
-		useArepo: checkArepoEnabled(),
-		httpClient: &http.Client{
-			Timeout: HTTPTimeout,
-		},
+		confMain:           DefaultSourcesList,
+		confDir:            DefaultSourcesListDir,
+		arch:               detectArch(runner),
+		arepoConfig:        ArepoConfigFile,
+		backupDir:          DefaultBackupDir,
+		registryDirs:       DefaultRegistryDirs,
+		priorityMacro:      PriorityDistbranchMacro,
+		legacyMacro:        LegacyP10Macro,
+		useArepo:           checkArepoEnabled(ArepoConfigFile),
+		httpClient:         network.NewHTTPClient(HTTPTimeout),
 		serviceAptDatabase: dbService,
 		runner:             runner,
+		reporter:           reporter,
 	}
 }
 
@@ -149,8 +164,8 @@ func detectArch(runner commandRunner) string {
 }
 
 // checkArepoEnabled проверяет включен ли arepo
-func checkArepoEnabled() bool {
-	file, err := os.Open(ArepoConfigFile)
+func checkArepoEnabled(path string) bool {
+	file, err := os.Open(path)
 	if err != nil {
 		return true
 	}
@@ -175,6 +190,23 @@ func (s *RepoService) httpScheme(ctx context.Context) string {
 	return "http://"
 }
 
+// doRequest выполняет HTTP запрос к серверу репозиториев, сообщая о проверке каждого URL.
+// Запрос прерывается при отмене контекста (например, при отключении HTTP клиента).
+func (s *RepoService) doRequest(ctx context.Context, method, url string) (*http.Response, error) {
+	if s.reporter != nil {
+		view := reply.WithEventView(fmt.Sprintf(app.TL_(ctx, "Checking %s"), url))
+		s.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventRepoCheckURL), view)
+		defer s.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventRepoCheckURL), view)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.httpClient.Do(req)
+}
+
 // checkHTTPSEnabled проверяет установлен ли пакет apt-https
 func (s *RepoService) checkHTTPSEnabled(ctx context.Context) bool {
 	_, err := s.serviceAptDatabase.GetPackageByName(ctx, "apt-https")
@@ -0,0 +1,96 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"apm/internal/common/app"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Sandbox временная конфигурация APT, не затрагивающая системные sources.list и списки пакетов.
+type Sandbox struct {
+	Dir       string
+	Overrides map[string]string
+}
+
+// Cleanup удаляет временный каталог песочницы.
+func (s *Sandbox) Cleanup() error {
+	return os.RemoveAll(s.Dir)
+}
+
+// CreateSandbox создаёт временную конфигурацию APT из активных системных репозиториев
+// и дополнительных источников args (в том же формате, что и для AddRepository).
+// Для работы APT с песочницей нужно применить Overrides.
+func (s *RepoService) CreateSandbox(ctx context.Context, args []string) (*Sandbox, error) {
+	s.ensureInitialized()
+
+	urls, err := s.parseSourceArgs(ctx, args, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(urls) == 0 {
+		return nil, errors.New(app.TL_(ctx, "Failed to parse repository source"))
+	}
+
+	repos, err := s.GetRepositories(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "apm-sandbox-")
+	if err != nil {
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to create sandbox directory: %w"), err)
+	}
+	sandbox := &Sandbox{Dir: dir}
+
+	lines := make([]string, 0, len(repos)+len(urls))
+	for _, repo := range repos {
+		lines = append(lines, repo.Entry)
+	}
+	lines = append(lines, urls...)
+
+	sourcesList := filepath.Join(dir, "sources.list")
+	sourceParts := filepath.Join(dir, "sources.list.d")
+	listsDir := filepath.Join(dir, "lists")
+
+	for _, d := range []string{sourceParts, filepath.Join(listsDir, "partial")} {
+		if err = os.MkdirAll(d, 0755); err != nil {
+			_ = sandbox.Cleanup()
+			return nil, fmt.Errorf(app.TL_(ctx, "Failed to create sandbox directory: %w"), err)
+		}
+	}
+
+	if err = os.WriteFile(sourcesList, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		_ = sandbox.Cleanup()
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to write sandbox sources: %w"), err)
+	}
+
+	sandbox.Overrides = map[string]string{
+		"Dir::Etc::sourcelist":    sourcesList,
+		"Dir::Etc::sourceparts":   sourceParts,
+		"Dir::State::lists":       listsDir + "/",
+		"Dir::Cache::pkgcache":    filepath.Join(dir, "pkgcache.bin"),
+		"Dir::Cache::srcpkgcache": filepath.Join(dir, "srcpkgcache.bin"),
+	}
+
+	return sandbox, nil
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCreateSandbox(t *testing.T) {
+	s, _ := newTestService(t)
+	writeSourcesList(t, s, "rpm http://example.com/base x86_64 classic\n# rpm http://example.com/old x86_64 classic\n")
+	writeExtraList(t, s, "extra.list", "rpm http://example.com/extra noarch classic\n")
+
+	sandbox, err := s.CreateSandbox(context.Background(), []string{"rpm http://example.com/task x86_64 task"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = sandbox.Cleanup() }()
+
+	data, err := os.ReadFile(sandbox.Overrides["Dir::Etc::sourcelist"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	for _, want := range []string{"example.com/base", "example.com/extra", "example.com/task"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("sandbox sources missing %s: %s", want, content)
+		}
+	}
+	if strings.Contains(content, "example.com/old") {
+		t.Errorf("inactive repo must not be copied: %s", content)
+	}
+
+	if system := readSourcesList(t, s); strings.Contains(system, "example.com/task") {
+		t.Errorf("system sources.list must not be modified: %s", system)
+	}
+
+	if err = sandbox.Cleanup(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(sandbox.Dir); !os.IsNotExist(err) {
+		t.Errorf("sandbox directory must be removed, stat err: %v", err)
+	}
+}
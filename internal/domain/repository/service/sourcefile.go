@@ -0,0 +1,210 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"apm/internal/common/app"
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// maxSourceFileSize ограничивает размер загружаемого файла источников
+const maxSourceFileSize = 1 << 20
+
+var sourceFileNameUnsafe = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// isSourceFile проверяет, указывает ли аргумент на готовый файл источников .list/.repo
+func isSourceFile(source string) bool {
+	name := source
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "file://") {
+		u, err := url.Parse(source)
+		if err != nil {
+			return false
+		}
+		name = u.Path
+	} else if info, err := os.Stat(source); err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+
+	ext := strings.ToLower(path.Ext(name))
+	return ext == ".list" || ext == ".repo"
+}
+
+// readSourceFile читает файл источников с диска или по URL
+func (s *RepoService) readSourceFile(ctx context.Context, source string) ([]byte, error) {
+	if strings.HasPrefix(source, "file://") {
+		source = strings.TrimPrefix(source, "file://")
+	}
+
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf(app.TL_(ctx, "Failed to open %s: %v"), source, err)
+		}
+		if len(data) > maxSourceFileSize {
+			return nil, fmt.Errorf(app.TL_(ctx, "Source file %s is too large"), source)
+		}
+		return data, nil
+	}
+
+	resp, err := s.doRequest(ctx, http.MethodGet, source)
+	if err != nil {
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to download %s: %v"), source, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to download %s: %s"), source, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSourceFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to download %s: %v"), source, err)
+	}
+	if len(data) > maxSourceFileSize {
+		return nil, fmt.Errorf(app.TL_(ctx, "Source file %s is too large"), source)
+	}
+	return data, nil
+}
+
+// parseSourceFileLines разбирает содержимое файла источников, проверяя каждую строку rpm.
+// Комментарии и пустые строки пропускаются, повторы внутри файла отбрасываются
+func (s *RepoService) parseSourceFileLines(source string, data []byte) ([]string, error) {
+	var lines []string
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.Join(strings.Fields(strings.ReplaceAll(line, "_arch_", s.arch)), " ")
+		parts := strings.Fields(line)
+		if parts[0] != "rpm" && parts[0] != "rpm-src" {
+			return nil, fmt.Errorf(app.T_("Invalid repository line %d in %s: %s"), lineNum, source, line)
+		}
+		if repo := s.parseLine(line, "", true); repo == nil || repo.URL == "" || len(repo.Components) == 0 {
+			return nil, fmt.Errorf(app.T_("Invalid repository line %d in %s: %s"), lineNum, source, line)
+		}
+
+		canonical := canonicalizeRepoLine(line)
+		if seen[canonical] {
+			continue
+		}
+		seen[canonical] = true
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(lines) == 0 {
+		return nil, fmt.Errorf(app.T_("No repositories found in %s"), source)
+	}
+	return lines, nil
+}
+
+// sourceFileName возвращает нормализованное имя файла в sources.list.d
+func (s *RepoService) sourceFileName(source string) string {
+	name := source
+	if u, err := url.Parse(source); err == nil && u.Scheme != "" {
+		name = u.Path
+	}
+	name = strings.ToLower(path.Base(name))
+	name = strings.TrimSuffix(strings.TrimSuffix(name, ".list"), ".repo")
+	name = strings.Trim(sourceFileNameUnsafe.ReplaceAllString(name, "-"), "-.")
+	if name == "" {
+		name = "apm"
+	}
+	return filepath.Join(s.confDir, name+".list")
+}
+
+// addSourceFile устанавливает репозитории из файла .list/.repo в sources.list.d.
+// Уже подключённые источники пропускаются, закомментированные раскомментируются на месте
+func (s *RepoService) addSourceFile(ctx context.Context, source string, simulate bool) ([]Repository, error) {
+	data, err := s.readSourceFile(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := s.parseSourceFileLines(source, data)
+	if err != nil {
+		return nil, err
+	}
+
+	target := s.sourceFileName(source)
+	var added []Repository
+	var content []string
+
+	for _, line := range lines {
+		exists, commented, err := s.checkRepoExists(ctx, line)
+		if err != nil {
+			return added, err
+		}
+		if exists {
+			continue
+		}
+
+		file := target
+		if commented && !simulate {
+			if file, err = s.uncommentRepo(line); err != nil {
+				return added, err
+			}
+		} else if !commented {
+			content = append(content, line)
+		}
+
+		if repo := s.parseLine(line, file, true); repo != nil {
+			added = append(added, *repo)
+		}
+	}
+
+	if simulate || len(content) == 0 {
+		return added, nil
+	}
+
+	existing, err := os.ReadFile(target)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return added, fmt.Errorf(app.TL_(ctx, "Failed to open %s: %v"), target, err)
+	}
+	if len(existing) > 0 && !bytes.HasSuffix(existing, []byte("\n")) {
+		existing = append(existing, '\n')
+	}
+	if err = os.MkdirAll(s.confDir, 0755); err != nil {
+		return added, fmt.Errorf(app.TL_(ctx, "Failed to write to %s: %v"), target, err)
+	}
+	if err = writeFileAtomic(target, append(existing, strings.Join(content, "\n")+"\n"...), 0644); err != nil {
+		return added, fmt.Errorf(app.TL_(ctx, "Failed to write to %s: %v"), target, err)
+	}
+
+	return added, nil
+}
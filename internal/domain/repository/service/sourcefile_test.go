@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSourceFile = `# Example vendor repository
+rpm [vendor] http://example.com/vendor _arch_ main
+rpm [vendor] http://example.com/vendor noarch main
+
+rpm [vendor] http://example.com/vendor   noarch main
+`
+
+func TestIsSourceFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "vendor.repo")
+	if err := os.WriteFile(file, []byte(testSourceFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		source string
+		want   bool
+	}{
+		{file, true},
+		{filepath.Join(dir, "missing.list"), false},
+		{dir, false},
+		{"https://example.com/vendor.list?token=1", true},
+		{"https://example.com/repo", false},
+		{"p11", false},
+	}
+	for _, tt := range tests {
+		if got := isSourceFile(tt.source); got != tt.want {
+			t.Errorf("isSourceFile(%q) = %v, want %v", tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestParseSourceFileLines(t *testing.T) {
+	s, _ := newTestService(t)
+
+	lines, err := s.parseSourceFileLines("vendor.repo", []byte(testSourceFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"rpm [vendor] http://example.com/vendor x86_64 main",
+		"rpm [vendor] http://example.com/vendor noarch main",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("lines = %q, want %q", lines, want)
+	}
+
+	if _, err = s.parseSourceFileLines("bad.list", []byte("deb http://example.com/ stable main\n")); err == nil {
+		t.Error("expected error for non-rpm line")
+	}
+	if _, err = s.parseSourceFileLines("bad.list", []byte("rpm http://example.com/\n")); err == nil {
+		t.Error("expected error for incomplete line")
+	}
+	if _, err = s.parseSourceFileLines("empty.list", []byte("# nothing\n")); err == nil {
+		t.Error("expected error for file without repositories")
+	}
+}
+
+func TestSourceFileName(t *testing.T) {
+	s, _ := newTestService(t)
+
+	tests := map[string]string{
+		"/tmp/Vendor Repo.repo":                "vendor-repo.list",
+		"https://example.com/a/b/extra.list?x": "extra.list",
+		"/tmp/.repo":                           "apm.list",
+	}
+	for source, want := range tests {
+		if got := s.sourceFileName(source); got != filepath.Join(s.confDir, want) {
+			t.Errorf("sourceFileName(%q) = %q, want %q", source, got, want)
+		}
+	}
+}
+
+func TestAddRepositoryFromFile(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("local file", func(t *testing.T) {
+		s, tmpDir := newTestService(t)
+		writeSourcesList(t, s, "rpm [vendor] http://example.com/vendor noarch main\n")
+
+		file := filepath.Join(tmpDir, "Vendor.repo")
+		if err := os.WriteFile(file, []byte(testSourceFile), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		added, err := s.AddRepository(ctx, []string{file}, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(added) != 1 {
+			t.Fatalf("expected 1 added repo, got %d", len(added))
+		}
+
+		target := filepath.Join(s.confDir, "vendor.list")
+		if added[0].File != target {
+			t.Errorf("File = %q, want %q", added[0].File, target)
+		}
+		content, err := os.ReadFile(target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != "rpm [vendor] http://example.com/vendor x86_64 main\n" {
+			t.Errorf("unexpected content: %q", content)
+		}
+
+		added, err = s.AddRepository(ctx, []string{file}, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(added) != 0 {
+			t.Errorf("expected no additions on repeat, got %d", len(added))
+		}
+	})
+
+	t.Run("URL", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/extra.list" {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write([]byte(testSourceFile))
+		}))
+		defer server.Close()
+
+		s, _ := newTestService(t)
+
+		willAdd, err := s.SimulateAdd(ctx, []string{server.URL + "/extra.list"}, "", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(willAdd) != 2 {
+			t.Fatalf("expected 2 repos in simulation, got %d", len(willAdd))
+		}
+		if _, err = os.Stat(filepath.Join(s.confDir, "extra.list")); !os.IsNotExist(err) {
+			t.Error("simulation must not write the file")
+		}
+
+		added, err := s.AddRepository(ctx, []string{server.URL + "/extra.list"}, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(added) != 2 {
+			t.Fatalf("expected 2 added repos, got %d", len(added))
+		}
+
+		if _, err = s.AddRepository(ctx, []string{server.URL + "/missing.list"}, ""); err == nil {
+			t.Error("expected error for missing file")
+		}
+	})
+}
@@ -19,6 +19,7 @@ package service
 import (
 	"apm/internal/common/app"
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"net/url"
@@ -38,19 +39,21 @@ func (s *RepoService) GetRepositories(_ context.Context, all bool) ([]Repository
 		return nil, err
 	}
 
+	meta := make(map[string]*TaskInfo)
 	for _, filename := range files {
-		parsed, errParse := s.parseSourceFile(filename, all)
+		parsed, errParse := s.parseSourceFile(filename, all, meta)
 		if errParse != nil {
 			continue
 		}
 		repos = append(repos, parsed...)
 	}
+	attachTaskMeta(repos, meta)
 
 	return repos, nil
 }
 
-// parseSourceFile парсит один файл с репозиториями
-func (s *RepoService) parseSourceFile(filename string, all bool) ([]Repository, error) {
+// parseSourceFile парсит один файл с репозиториями, сохранённые метаданные задач собираются в meta
+func (s *RepoService) parseSourceFile(filename string, all bool, meta map[string]*TaskInfo) ([]Repository, error) {
 	file, err := os.Open(filename)
 	if err != nil {
 		return nil, err
@@ -72,6 +75,8 @@ func (s *RepoService) parseSourceFile(filename string, all bool) ([]Repository,
 			if repo := s.parseLine(trimmed, filename, true); repo != nil {
 				repos = append(repos, *repo)
 			}
+		} else if info := parseTaskMeta(trimmed); info != nil {
+			meta[info.ID] = info
 		} else if all {
 			commented := strings.TrimPrefix(trimmed, "#")
 			commented = strings.TrimSpace(commented)
@@ -282,7 +287,7 @@ func (s *RepoService) uncommentRepo(repoLine string) (string, error) {
 		}
 
 		if modified {
-			errWrite := os.WriteFile(filename, []byte(strings.Join(lines, "\n")), 0644)
+			errWrite := writeFileAtomic(filename, []byte(strings.Join(lines, "\n")), 0644)
 			if errWrite != nil {
 				return "", errWrite
 			}
@@ -299,14 +304,15 @@ func (s *RepoService) appendRepo(repoLine string) error {
 		return fmt.Errorf(app.T_("Invalid repository line: %s"), repoLine)
 	}
 
-	file, err := os.OpenFile(s.confMain, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
+	content, err := os.ReadFile(s.confMain)
+	if err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf(app.T_("Failed to open %s: %v"), s.confMain, err)
 	}
-	defer func() { _ = file.Close() }()
+	if len(content) > 0 && !bytes.HasSuffix(content, []byte("\n")) {
+		content = append(content, '\n')
+	}
 
-	_, err = file.WriteString(repoLine + "\n")
-	if err != nil {
+	if err = writeFileAtomic(s.confMain, append(content, repoLine+"\n"...), 0644); err != nil {
 		return fmt.Errorf(app.T_("Failed to write to %s: %v"), s.confMain, err)
 	}
 
@@ -357,7 +363,7 @@ func (s *RepoService) removeFromFile(filename string, canonicalLine string) erro
 		}
 	}
 
-	return os.WriteFile(filename, []byte(strings.Join(newLines, "\n")), 0644)
+	return writeFileAtomic(filename, []byte(strings.Join(dropTaskMeta(newLines, lineTaskNumber(canonicalLine)), "\n")), 0644)
 }
 
 // commentInFile комментирует строку в файле
@@ -378,7 +384,7 @@ func (s *RepoService) commentInFile(filename string, canonicalLine string) error
 	}
 
 	if modified {
-		return os.WriteFile(filename, []byte(strings.Join(lines, "\n")), 0644)
+		return writeFileAtomic(filename, []byte(strings.Join(lines, "\n")), 0644)
 	}
 
 	return nil
@@ -398,7 +404,7 @@ func (s *RepoService) purgeAllRepos() error {
 		}
 	}
 
-	if err = os.WriteFile(s.confMain, []byte("\n"), 0644); err != nil {
+	if err = writeFileAtomic(s.confMain, []byte("\n"), 0644); err != nil {
 		return fmt.Errorf(app.T_("Failed to clear %s: %v"), s.confMain, err)
 	}
 
@@ -0,0 +1,323 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"apm/internal/common/app"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// taskMetaPrefix префикс строки-комментария с метаданными задачи в sources.list
+const taskMetaPrefix = "# apm-task "
+
+// Состояния задачи сборочницы, на которых наблюдение завершается
+const (
+	TaskStateDone    = "DONE"
+	TaskStateTested  = "TESTED"
+	TaskStateFailed  = "FAILED"
+	TaskStateEPERM   = "EPERM"
+	TaskStateDeleted = "DELETED"
+)
+
+// TaskInfo метаданные задачи сборочницы git.altlinux.org
+type TaskInfo struct {
+	ID       string        `json:"id"`
+	Repo     string        `json:"repo"`
+	Owner    string        `json:"owner"`
+	State    string        `json:"state"`
+	TestOnly bool          `json:"testOnly"`
+	Subtasks []TaskSubtask `json:"subtasks"`
+}
+
+// TaskSubtask подзадача задачи сборочницы
+type TaskSubtask struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Package string `json:"package"`
+	Owner   string `json:"owner,omitempty"`
+}
+
+// girarTaskInfo формат info.json задачи girar
+type girarTaskInfo struct {
+	TaskID   json.Number             `json:"taskid"`
+	Repo     string                  `json:"repo"`
+	Owner    string                  `json:"owner"`
+	State    string                  `json:"state"`
+	TestOnly bool                    `json:"test_only"`
+	Subtasks map[string]girarSubtask `json:"subtasks"`
+}
+
+type girarSubtask struct {
+	Type    string `json:"type"`
+	UserID  string `json:"userid"`
+	Dir     string `json:"dir"`
+	Package string `json:"package"`
+	SRPM    string `json:"srpm"`
+}
+
+// GetTaskInfo загружает метаданные задачи: владельца, состояние, режим test-only и подзадачи.
+// Сведения доступны на любой стадии задачи, в том числе до окончания сборки.
+func (s *RepoService) GetTaskInfo(ctx context.Context, taskNum string) (*TaskInfo, error) {
+	url := fmt.Sprintf("%s%s/%s/info.json", s.httpScheme(ctx), RepoTasksURL, taskNum)
+
+	resp, err := s.doRequest(ctx, http.MethodGet, url)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf(app.TL_(ctx, "Task %s not found"), taskNum)
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to get task information: HTTP %d"), resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseTaskInfo(taskNum, body)
+}
+
+// storeTaskInfo загружает метаданные добавленных задач и сохраняет их рядом с источниками.
+// Недоступность метаданных не мешает добавлению репозитория.
+func (s *RepoService) storeTaskInfo(ctx context.Context, added []Repository) {
+	infos := make(map[string]*TaskInfo)
+	for i := range added {
+		if !isTaskRepo(added[i]) {
+			continue
+		}
+		taskNum := taskNumberFromURL(added[i].URL)
+		if taskNum == "" {
+			continue
+		}
+
+		info, ok := infos[taskNum]
+		if !ok {
+			var err error
+			if info, err = s.GetTaskInfo(ctx, taskNum); err != nil {
+				app.Log.Debugf("failed to get info for task %s: %v", taskNum, err)
+			} else if err = s.saveTaskMeta(info); err != nil {
+				app.Log.Debugf("failed to save info for task %s: %v", taskNum, err)
+			}
+			infos[taskNum] = info
+		}
+		added[i].Task = info
+	}
+}
+
+// parseTaskInfo разбирает info.json задачи
+func parseTaskInfo(taskNum string, body []byte) (*TaskInfo, error) {
+	var raw girarTaskInfo
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf(app.T_("Failed to parse task information: %v"), err)
+	}
+
+	info := &TaskInfo{
+		ID:       taskNum,
+		Repo:     raw.Repo,
+		Owner:    raw.Owner,
+		State:    raw.State,
+		TestOnly: raw.TestOnly,
+	}
+	if raw.TaskID != "" {
+		info.ID = raw.TaskID.String()
+	}
+
+	for id, sub := range raw.Subtasks {
+		name := subtaskPackage(sub)
+		if name == "" {
+			continue
+		}
+		info.Subtasks = append(info.Subtasks, TaskSubtask{
+			ID:      id,
+			Type:    sub.Type,
+			Package: name,
+			Owner:   sub.UserID,
+		})
+	}
+
+	sort.Slice(info.Subtasks, func(i, j int) bool {
+		a, errA := strconv.Atoi(info.Subtasks[i].ID)
+		b, errB := strconv.Atoi(info.Subtasks[j].ID)
+		if errA != nil || errB != nil {
+			return info.Subtasks[i].ID < info.Subtasks[j].ID
+		}
+		return a < b
+	})
+
+	return info, nil
+}
+
+// subtaskPackage определяет имя пакета подзадачи по имени, git-каталогу или srpm
+func subtaskPackage(sub girarSubtask) string {
+	switch {
+	case sub.Package != "":
+		return sub.Package
+	case sub.Dir != "":
+		return strings.TrimSuffix(path.Base(sub.Dir), ".git")
+	case sub.SRPM != "":
+		return strings.TrimSuffix(sub.SRPM, ".src.rpm")
+	default:
+		return ""
+	}
+}
+
+// taskNumberFromURL извлекает номер задачи из URL task-репозитория
+func taskNumberFromURL(repoURL string) string {
+	parts := strings.Split(strings.Trim(stripScheme(repoURL), "/"), "/")
+	if len(parts) < 3 || parts[0] != "git.altlinux.org" {
+		return ""
+	}
+
+	if parts[1] == "repo" && isTaskNumber(parts[2]) {
+		return parts[2]
+	}
+
+	// Архивные задачи: git.altlinux.org/tasks/archive/done/_NNN/<task>/build/repo
+	for i := 1; i < len(parts); i++ {
+		if parts[i] == "build" && isTaskNumber(parts[i-1]) {
+			return parts[i-1]
+		}
+	}
+
+	return ""
+}
+
+// lineTaskNumber возвращает номер задачи для строки источника или пустую строку
+func lineTaskNumber(line string) string {
+	fields := strings.Fields(canonicalizeRepoLine(line))
+	idx := 1
+	if len(fields) > idx && strings.HasPrefix(fields[idx], "[") {
+		idx++
+	}
+	if len(fields) <= idx {
+		return ""
+	}
+	return taskNumberFromURL(fields[idx])
+}
+
+// isTaskRepo проверяет, что источник является task-репозиторием
+func isTaskRepo(repo Repository) bool {
+	for _, comp := range repo.Components {
+		if comp == "task" {
+			return true
+		}
+	}
+	return false
+}
+
+// formatTaskMeta формирует строку-комментарий с метаданными задачи
+func formatTaskMeta(info *TaskInfo) (string, error) {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return "", err
+	}
+	return taskMetaPrefix + string(data), nil
+}
+
+// parseTaskMeta разбирает строку-комментарий с метаданными задачи
+func parseTaskMeta(line string) *TaskInfo {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, taskMetaPrefix) {
+		return nil
+	}
+
+	var info TaskInfo
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(trimmed, taskMetaPrefix)), &info); err != nil || info.ID == "" {
+		return nil
+	}
+	return &info
+}
+
+// saveTaskMeta записывает метаданные задачи в sources.list, заменяя прежние для этой задачи
+func (s *RepoService) saveTaskMeta(info *TaskInfo) error {
+	meta, err := formatTaskMeta(info)
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(s.confMain)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf(app.T_("Failed to read %s: %v"), s.confMain, err)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(string(content), "\n"), "\n") {
+		if existing := parseTaskMeta(line); existing != nil && existing.ID == info.ID {
+			continue
+		}
+		if line != "" || len(lines) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	lines = append(lines, meta)
+
+	if err = writeFileAtomic(s.confMain, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		return fmt.Errorf(app.T_("Failed to write to %s: %v"), s.confMain, err)
+	}
+	return nil
+}
+
+// dropTaskMeta убирает метаданные задачи taskNum, если в строках не осталось её источников
+func dropTaskMeta(lines []string, taskNum string) []string {
+	if taskNum == "" {
+		return lines
+	}
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#"))
+		if strings.HasPrefix(trimmed, "rpm") && lineTaskNumber(trimmed) == taskNum {
+			return lines
+		}
+	}
+
+	result := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if info := parseTaskMeta(line); info != nil && info.ID == taskNum {
+			continue
+		}
+		result = append(result, line)
+	}
+	return result
+}
+
+// attachTaskMeta добавляет сохранённые метаданные к task-репозиториям
+func attachTaskMeta(repos []Repository, meta map[string]*TaskInfo) {
+	if len(meta) == 0 {
+		return
+	}
+	for i := range repos {
+		if !isTaskRepo(repos[i]) {
+			continue
+		}
+		if info, ok := meta[taskNumberFromURL(repos[i].URL)]; ok {
+			repos[i].Task = info
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseTaskInfo(t *testing.T) {
+	body := []byte(`{
+		"taskid": 389123,
+		"repo": "sisyphus",
+		"owner": "builder",
+		"state": "EPERM",
+		"test_only": true,
+		"subtasks": {
+			"200": {"type": "srpm", "userid": "builder", "srpm": "bar-2.0-alt1.src.rpm"},
+			"100": {"type": "repo", "userid": "builder", "dir": "/gears/f/foo.git", "tag_name": "1.0-alt1"},
+			"300": {"type": "delete", "userid": "other", "package": "baz"},
+			"400": {}
+		}
+	}`)
+
+	info, err := parseTaskInfo("389123", body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.ID != "389123" || info.Repo != "sisyphus" || info.Owner != "builder" || info.State != "EPERM" || !info.TestOnly {
+		t.Errorf("unexpected info: %+v", info)
+	}
+
+	expected := []TaskSubtask{
+		{ID: "100", Type: "repo", Package: "foo", Owner: "builder"},
+		{ID: "200", Type: "srpm", Package: "bar-2.0-alt1", Owner: "builder"},
+		{ID: "300", Type: "delete", Package: "baz", Owner: "other"},
+	}
+	if len(info.Subtasks) != len(expected) {
+		t.Fatalf("expected %d subtasks, got %+v", len(expected), info.Subtasks)
+	}
+	for i, sub := range expected {
+		if info.Subtasks[i] != sub {
+			t.Errorf("subtask %d: expected %+v, got %+v", i, sub, info.Subtasks[i])
+		}
+	}
+}
+
+func TestParseTaskInfo_Invalid(t *testing.T) {
+	if _, err := parseTaskInfo("1", []byte("<html>")); err == nil {
+		t.Error("expected error for invalid info.json")
+	}
+}
+
+func TestTaskNumberFromURL(t *testing.T) {
+	tests := map[string]string{
+		"http://git.altlinux.org/repo/389123":                               "389123",
+		"https://git.altlinux.org/repo/389123/":                             "389123",
+		"http://git.altlinux.org/tasks/archive/done/_380/389123/build/repo": "389123",
+		"http://ftp.altlinux.org/pub/distributions/ALTLinux/Sisyphus":       "",
+		"http://git.altlinux.org/people/user/packages":                      "",
+		"http://example.com/repo/389123":                                    "",
+	}
+
+	for url, expected := range tests {
+		if got := taskNumberFromURL(url); got != expected {
+			t.Errorf("taskNumberFromURL(%q) = %q, expected %q", url, got, expected)
+		}
+	}
+}
+
+func TestTaskMeta_AttachAndDrop(t *testing.T) {
+	s, _ := newTestService(t)
+	writeSourcesList(t, s, "rpm http://git.altlinux.org/repo/389123/ x86_64 task\nrpm http://git.altlinux.org/repo/389123/ x86_64-i586 task\n")
+
+	info := &TaskInfo{ID: "389123", Owner: "builder", State: "DONE", Subtasks: []TaskSubtask{{ID: "100", Package: "foo"}}}
+	if err := s.saveTaskMeta(info); err != nil {
+		t.Fatalf("saveTaskMeta: %v", err)
+	}
+	info.State = "TESTED"
+	if err := s.saveTaskMeta(info); err != nil {
+		t.Fatalf("saveTaskMeta: %v", err)
+	}
+
+	content, _ := os.ReadFile(s.confMain)
+	if strings.Count(string(content), taskMetaPrefix) != 1 {
+		t.Fatalf("expected a single metadata line, got:\n%s", content)
+	}
+
+	repos, err := s.GetRepositories(context.Background(), false)
+	if err != nil {
+		t.Fatalf("GetRepositories: %v", err)
+	}
+	if len(repos) != 2 {
+		t.Fatalf("expected 2 repositories, got %d", len(repos))
+	}
+	for _, repo := range repos {
+		if repo.Task == nil || repo.Task.State != "TESTED" || repo.Task.Owner != "builder" {
+			t.Errorf("expected task info on %s, got %+v", repo.Entry, repo.Task)
+		}
+	}
+
+	if err = s.removeOrCommentRepo("rpm http://git.altlinux.org/repo/389123/ x86_64 task"); err != nil {
+		t.Fatal(err)
+	}
+	content, _ = os.ReadFile(s.confMain)
+	if !strings.Contains(string(content), taskMetaPrefix) {
+		t.Error("metadata should be kept while the task still has sources")
+	}
+
+	if err = s.removeOrCommentRepo("rpm http://git.altlinux.org/repo/389123/ x86_64-i586 task"); err != nil {
+		t.Fatal(err)
+	}
+	content, _ = os.ReadFile(s.confMain)
+	if strings.Contains(string(content), taskMetaPrefix) {
+		t.Errorf("metadata should be removed with the last task source, got:\n%s", content)
+	}
+}
@@ -32,7 +32,7 @@ func (s *RepoService) buildTaskURLs(ctx context.Context, taskNum string) ([]stri
 		return nil, err
 	}
 	if !exists {
-		return nil, fmt.Errorf(app.T_("Task %s not found or still building"), taskNum)
+		return nil, fmt.Errorf(app.TL_(ctx, "Task %s not found or still building"), taskNum)
 	}
 
 	var repoURL string
@@ -61,12 +61,7 @@ func (s *RepoService) buildTaskURLs(ctx context.Context, taskNum string) ([]stri
 func (s *RepoService) checkTaskExists(ctx context.Context, taskNum string) (exists bool, baseURL string, err error) {
 	url := fmt.Sprintf("%s%s/%s/plan/add-bin", s.httpScheme(ctx), RepoTasksURL, taskNum)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
-	if err != nil {
-		return false, "", err
-	}
-
-	resp, err := s.httpClient.Do(req)
+	resp, err := s.doRequest(ctx, http.MethodHead, url)
 	if err != nil {
 		return false, "", err
 	}
@@ -89,12 +84,7 @@ func (s *RepoService) checkTaskExists(ctx context.Context, taskNum string) (exis
 func (s *RepoService) checkTaskHasArepo(ctx context.Context, taskNum string) (bool, error) {
 	url := fmt.Sprintf("%s%s/%s/plan/arepo-add-x86_64-i586", s.httpScheme(ctx), RepoTasksURL, taskNum)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return false, err
-	}
-
-	resp, err := s.httpClient.Do(req)
+	resp, err := s.doRequest(ctx, http.MethodGet, url)
 	if err != nil {
 		return false, err
 	}
@@ -132,24 +122,19 @@ func (s *RepoService) GetTaskPackages(ctx context.Context, taskNum string) ([]st
 		return nil, err
 	}
 	if !exists {
-		return nil, fmt.Errorf(app.T_("Task %s not found or still building"), taskNum)
+		return nil, fmt.Errorf(app.TL_(ctx, "Task %s not found or still building"), taskNum)
 	}
 
 	url := baseURL + "/plan/add-bin"
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := s.httpClient.Do(req)
+	resp, err := s.doRequest(ctx, http.MethodGet, url)
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf(app.T_("Failed to get task packages: HTTP %d"), resp.StatusCode)
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to get task packages: HTTP %d"), resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -0,0 +1,108 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"apm/internal/common/app"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/thediveo/osrelease"
+)
+
+// osReleasePath файл, из которого берётся ветка дистрибутива, если её не удалось определить по репозиториям
+var osReleasePath = "/etc/os-release"
+
+// repoVariablePattern переменная в определении репозитория вида $(NAME)
+var repoVariablePattern = regexp.MustCompile(`\$\(([A-Za-z_]+)\)`)
+
+// expandSourceArgs подставляет переменные $(ARCH), $(BRANCH) и $(DATE) в аргументы источника и дату
+func (s *RepoService) expandSourceArgs(ctx context.Context, args []string, date string) ([]string, string, error) {
+	expanded := make([]string, len(args))
+	for i, arg := range args {
+		value, err := s.expandVariables(ctx, arg)
+		if err != nil {
+			return nil, "", err
+		}
+		expanded[i] = value
+	}
+
+	expandedDate, err := s.expandVariables(ctx, date)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return expanded, expandedDate, nil
+}
+
+// expandVariables подставляет значения переменных в строку, неизвестная переменная считается ошибкой
+func (s *RepoService) expandVariables(ctx context.Context, value string) (string, error) {
+	if !strings.Contains(value, "$(") {
+		return value, nil
+	}
+
+	var expandErr error
+	result := repoVariablePattern.ReplaceAllStringFunc(value, func(match string) string {
+		name := strings.ToUpper(repoVariablePattern.FindStringSubmatch(match)[1])
+		switch name {
+		case "ARCH":
+			return s.arch
+		case "DATE":
+			return time.Now().Format("2006/01/02")
+		case "BRANCH":
+			branch, err := s.currentBranch(ctx)
+			if err != nil && expandErr == nil {
+				expandErr = err
+			}
+			return branch
+		default:
+			if expandErr == nil {
+				expandErr = fmt.Errorf(app.TL_(ctx, "Unknown repository variable: %s"), match)
+			}
+			return match
+		}
+	})
+
+	if expandErr != nil {
+		return "", expandErr
+	}
+	return result, nil
+}
+
+// currentBranch определяет текущую ветку по активным репозиториям, затем по ALT_BRANCH_ID из os-release
+func (s *RepoService) currentBranch(ctx context.Context) (string, error) {
+	s.ensureInitialized()
+
+	if repos, err := s.GetRepositories(ctx, false); err == nil {
+		for _, repo := range repos {
+			if _, ok := s.branches[repo.Branch]; ok && !strings.HasPrefix(repo.Branch, "autoimports.") {
+				return repo.Branch, nil
+			}
+		}
+	}
+
+	branch := strings.ToLower(strings.TrimSpace(osrelease.NewFromName(osReleasePath)["ALT_BRANCH_ID"]))
+	if _, ok := s.branches[branch]; ok {
+		return branch, nil
+	}
+
+	return "", errors.New(app.TL_(ctx, "Failed to determine the current branch for $(BRANCH)"))
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpandVariables(t *testing.T) {
+	s, tmpDir := newTestService(t)
+	ctx := context.Background()
+
+	t.Run("arch and date", func(t *testing.T) {
+		got, err := s.expandVariables(ctx, "rpm http://example.com/$(ARCH)/$(DATE) $(ARCH) classic")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "rpm http://example.com/x86_64/" + time.Now().Format("2006/01/02") + " x86_64 classic"
+		if got != want {
+			t.Errorf("expected %q, got %q", want, got)
+		}
+	})
+
+	t.Run("branch from active repos", func(t *testing.T) {
+		writeSourcesList(t, s, "rpm [p11] http://ftp.altlinux.org/pub/distributions/ALTLinux/p11/branch x86_64 classic\n")
+		got, err := s.expandVariables(ctx, "$(BRANCH)")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != "p11" {
+			t.Errorf("expected p11, got %q", got)
+		}
+	})
+
+	t.Run("branch from os-release", func(t *testing.T) {
+		writeSourcesList(t, s, "")
+		osRelease := filepath.Join(tmpDir, "os-release")
+		if err := os.WriteFile(osRelease, []byte("ALT_BRANCH_ID=sisyphus\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		old := osReleasePath
+		osReleasePath = osRelease
+		defer func() { osReleasePath = old }()
+
+		got, err := s.expandVariables(ctx, "$(BRANCH)")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != "sisyphus" {
+			t.Errorf("expected sisyphus, got %q", got)
+		}
+	})
+
+	t.Run("unknown variable", func(t *testing.T) {
+		_, err := s.expandVariables(ctx, "rpm http://example.com $(FOO) classic")
+		if err == nil || !strings.Contains(err.Error(), "$(FOO)") {
+			t.Errorf("expected unknown variable error, got %v", err)
+		}
+	})
+}
+
+func TestParseSourceArgs_Variables(t *testing.T) {
+	s, _ := newTestService(t)
+	ctx := context.Background()
+	writeSourcesList(t, s, "")
+
+	urls, err := s.parseSourceArgs(ctx, []string{"rpm", "http://example.com/repo", "$(ARCH)", "classic"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(urls) != 1 || urls[0] != "rpm http://example.com/repo x86_64 classic" {
+		t.Errorf("unexpected urls: %v", urls)
+	}
+}
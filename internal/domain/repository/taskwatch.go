@@ -0,0 +1,170 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package repository
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/journal"
+	"apm/internal/domain/repository/service"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultTaskWatchInterval интервал опроса задачи по умолчанию
+	defaultTaskWatchInterval = time.Minute
+	// minTaskWatchInterval минимальный интервал опроса, чтобы не нагружать сборочницу
+	minTaskWatchInterval = 10 * time.Second
+)
+
+// TaskWatchChange смена состояния задачи, обнаруженная при очередном опросе
+type TaskWatchChange struct {
+	TaskNum string `json:"taskNum"`
+	State   string `json:"state"`
+	Message string `json:"message"`
+	Date    string `json:"date"`
+}
+
+// TaskInfo возвращает состояние задачи, её подзадачи и пакеты, собранные в задаче
+func (a *Actions) TaskInfo(ctx context.Context, taskNum string) (*TaskInfoResponse, error) {
+	taskNum = strings.TrimSpace(taskNum)
+	if taskNum == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Task number must be specified")))
+	}
+
+	info, err := a.repoService.GetTaskInfo(ctx, taskNum)
+	if err != nil {
+		return nil, newRepoError(err)
+	}
+
+	packages, err := a.repoService.GetTaskPackages(ctx, taskNum)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, newRepoError(ctx.Err())
+		}
+		app.Log.Debugf("failed to get packages of task %s: %v", taskNum, err)
+	}
+	if packages == nil {
+		packages = []string{}
+	}
+
+	return &TaskInfoResponse{
+		Message:  fmt.Sprintf(app.TL_(ctx, "Task %s: %s"), taskNum, info.State),
+		Task:     info,
+		Packages: packages,
+		Count:    len(packages),
+	}, nil
+}
+
+// TaskWatch опрашивает задачу до состояния DONE или TESTED и передаёт в fn каждую смену состояния.
+// После завершения задачи при add подключает её репозиторий, при install также устанавливает её пакеты.
+// Задача в состоянии FAILED, EPERM или DELETED завершает наблюдение с ошибкой.
+func (a *Actions) TaskWatch(ctx context.Context, taskNum string, interval time.Duration, add, install bool, fn func(TaskWatchChange)) (*TaskWatchResponse, error) {
+	taskNum = strings.TrimSpace(taskNum)
+	if taskNum == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Task number must be specified")))
+	}
+	if interval <= 0 {
+		interval = defaultTaskWatchInterval
+	}
+	if interval < minTaskWatchInterval {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "The task watch interval must be at least 10 seconds")))
+	}
+	if add || install {
+		if err := a.checkOverlay(ctx); err != nil {
+			return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+		}
+	}
+
+	info, err := a.waitTask(ctx, taskNum, interval, fn)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &TaskWatchResponse{
+		Message: fmt.Sprintf(app.TL_(ctx, "Task %s: %s"), taskNum, info.State),
+		Task:    info,
+	}
+	if !add && !install {
+		return resp, nil
+	}
+
+	a.backupSources()
+	resp.Added, err = a.repoService.AddRepository(ctx, []string{taskNum}, "")
+	if err != nil {
+		a.recordOperation(ctx, journal.ActionAdd, []string{taskNum}, err)
+		return nil, apmerr.New(apmerr.ErrorTypeRepository, fmt.Errorf("%s: %v", app.TL_(ctx, "Failed to add task repository"), err))
+	}
+	a.recordOperation(ctx, journal.ActionAdd, repoTargets(resp.Added), nil)
+	if !install {
+		return resp, nil
+	}
+
+	packages, err := a.repoService.GetTaskPackages(ctx, taskNum)
+	if err != nil {
+		return nil, newRepoError(err)
+	}
+	if len(packages) == 0 {
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, errors.New(app.TL_(ctx, "No packages to install from task")))
+	}
+
+	if resp.Installed, err = a.installTaskPackages(ctx, packages); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// waitTask опрашивает задачу, пока она не завершится. Ошибки сети не прерывают наблюдение.
+func (a *Actions) waitTask(ctx context.Context, taskNum string, interval time.Duration, fn func(TaskWatchChange)) (*service.TaskInfo, error) {
+	var state string
+	for {
+		info, err := a.repoService.GetTaskInfo(ctx, taskNum)
+		switch {
+		case ctx.Err() != nil:
+			return nil, newRepoError(ctx.Err())
+		case err != nil:
+			app.Log.Errorf("task watch: %v", err)
+		case info.State != state:
+			state = info.State
+			fn(TaskWatchChange{
+				TaskNum: taskNum,
+				State:   state,
+				Message: fmt.Sprintf(app.TL_(ctx, "Task %s: %s"), taskNum, state),
+				Date:    time.Now().Format(time.RFC3339),
+			})
+		}
+
+		if err == nil {
+			switch info.State {
+			case service.TaskStateDone, service.TaskStateTested:
+				return info, nil
+			case service.TaskStateFailed, service.TaskStateEPERM, service.TaskStateDeleted:
+				return nil, apmerr.New(apmerr.ErrorTypeRepository, fmt.Errorf(app.TL_(ctx, "Task %s finished with state %s"), taskNum, info.State))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, newRepoError(ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,267 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package search
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	_package "apm/internal/common/apt/package"
+	"apm/internal/common/reply"
+	"apm/internal/common/swcat"
+	"apm/internal/domain/distrobox"
+	"apm/internal/domain/system"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// Actions объединяет методы поиска пакетов сразу по всем источникам
+type Actions struct {
+	appConfig        *app.Config
+	serviceSystem    systemService
+	serviceAppStream appStreamService
+	serviceDistrobox distroboxService
+}
+
+// NewActions создаёт новый экземпляр Actions. Контейнеры distrobox принадлежат пользователю,
+// поэтому от root поиск по ним не выполняется.
+func NewActions(appConfig *app.Config, reporter *reply.Reporter) *Actions {
+	a := &Actions{
+		appConfig:        appConfig,
+		serviceSystem:    system.NewActions(appConfig, reporter),
+		serviceAppStream: swcat.NewAppStreamDBService(appConfig.DatabaseManager, reporter),
+	}
+	if appConfig.ConfigManager.GetConfig().ExistDistrobox && syscall.Geteuid() != 0 {
+		a.serviceDistrobox = distrobox.NewActions(appConfig, reporter)
+	}
+
+	return a
+}
+
+// sourceOrder порядок источников при равной релевантности
+var sourceOrder = []string{SourceSystem, SourceStplr, SourceDistrobox}
+
+// Search параллельно ищет пакеты в системе (включая STPLR) и во всех контейнерах distrobox,
+// объединяет результаты и сортирует их по релевантности. Ошибка одного источника не прерывает
+// поиск и попадает в предупреждения.
+func (a *Actions) Search(ctx context.Context, query string, installed bool) (*SearchResponse, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.TL_(ctx, "You must specify the search query, for example `%s query`"), "apm search"))
+	}
+
+	var wg sync.WaitGroup
+	var systemResults, distroResults []Result
+	var systemErr, distroErr error
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		systemResults, systemErr = a.searchSystem(ctx, query, installed)
+	}()
+	if a.serviceDistrobox != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			distroResults, distroErr = a.searchDistrobox(ctx, query, installed)
+		}()
+	}
+	wg.Wait()
+
+	var warnings []string
+	if systemErr != nil {
+		warnings = append(warnings, fmt.Sprintf(app.TL_(ctx, "System packages: %v"), systemErr))
+	}
+	if distroErr != nil {
+		warnings = append(warnings, fmt.Sprintf(app.TL_(ctx, "Distrobox containers: %v"), distroErr))
+	}
+
+	results := append(systemResults, distroResults...)
+	if len(results) == 0 {
+		if systemErr != nil && (a.serviceDistrobox == nil || distroErr != nil) {
+			return nil, systemErr
+		}
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, errors.New(app.TL_(ctx, "Nothing found")))
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if results[i].Source != results[j].Source {
+			return slices.Index(sourceOrder, results[i].Source) < slices.Index(sourceOrder, results[j].Source)
+		}
+		if results[i].Name != results[j].Name {
+			return results[i].Name < results[j].Name
+		}
+		return results[i].Container < results[j].Container
+	})
+
+	return &SearchResponse{
+		Message:  fmt.Sprintf(app.TLN_(ctx, "%d record found", "%d records found", len(results)), len(results)),
+		Results:  results,
+		Warnings: warnings,
+	}, nil
+}
+
+// searchSystem ищет системные пакеты и пакеты STPLR, для приложений подгружает метаданные AppStream
+func (a *Actions) searchSystem(ctx context.Context, query string, installed bool) ([]Result, error) {
+	resp, err := a.serviceSystem.Search(ctx, query, installed, false)
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// В текстовом формате модуль system не обогащает пакеты AppStream, а для ранжирования они нужны
+	var names []string
+	for _, pkg := range resp.Packages {
+		if pkg.HasAppStream && len(pkg.AppStream) == 0 {
+			names = append(names, pkg.Name)
+		}
+	}
+	components := make(map[string][]swcat.Component)
+	if len(names) > 0 {
+		components, err = a.serviceAppStream.GetByPkgNames(ctx, names)
+		if err != nil {
+			app.Log.Debugf("search: failed to load AppStream components: %v", err)
+			components = make(map[string][]swcat.Component)
+		}
+	}
+
+	results := make([]Result, 0, len(resp.Packages))
+	for _, pkg := range resp.Packages {
+		comps := pkg.AppStream
+		if len(comps) == 0 {
+			comps = components[pkg.Name]
+		}
+		results = append(results, Result{
+			Name:      pkg.Name,
+			Source:    _package.PackageType(pkg.TypePackage).String(),
+			Version:   pkg.Version,
+			Summary:   pkg.Summary,
+			AppName:   appName(comps),
+			Installed: pkg.Installed,
+			Score:     relevance(query, pkg.Name, pkg.Summary, comps),
+		})
+	}
+
+	return results, nil
+}
+
+// searchDistrobox ищет пакеты по локальной базе всех контейнеров distrobox
+func (a *Actions) searchDistrobox(ctx context.Context, query string, installed bool) ([]Result, error) {
+	resp, err := a.serviceDistrobox.Search(ctx, "", query)
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(resp.Packages))
+	for _, pkg := range resp.Packages {
+		if installed && !pkg.Installed {
+			continue
+		}
+		results = append(results, Result{
+			Name:      pkg.Name,
+			Source:    SourceDistrobox,
+			Container: pkg.Container,
+			Version:   pkg.Version,
+			Summary:   pkg.Description,
+			Installed: pkg.Installed,
+			Score:     relevance(query, pkg.Name, pkg.Description, nil),
+		})
+	}
+
+	return results, nil
+}
+
+// relevance оценивает соответствие пакета запросу. Совпадение имени пакета весит больше всего,
+// затем совпадение с названием приложения и ключевыми словами AppStream, затем с описанием.
+func relevance(query, name, summary string, components []swcat.Component) int {
+	q := strings.ToLower(query)
+	n := strings.ToLower(name)
+
+	score := 0
+	switch {
+	case n == q:
+		score = 100
+	case strings.HasPrefix(n, q):
+		score = 60
+	case strings.Contains(n, q):
+		score = 40
+	}
+
+	if strings.Contains(strings.ToLower(summary), q) {
+		score += 10
+	}
+
+	if len(components) == 0 {
+		return score
+	}
+
+	// Приложения с метаданными AppStream поднимаются над библиотеками и служебными пакетами
+	score += 5
+	appScore := 0
+	for _, c := range components {
+		for _, text := range c.Name {
+			value := strings.ToLower(text.Value)
+			switch {
+			case value == q:
+				appScore = max(appScore, 50)
+			case strings.Contains(value, q):
+				appScore = max(appScore, 30)
+			}
+		}
+		for _, keyword := range c.Keywords {
+			if strings.ToLower(keyword.Value) == q {
+				appScore = max(appScore, 20)
+			}
+		}
+	}
+
+	return score + appScore
+}
+
+// appName возвращает непереведённое название приложения из первого компонента AppStream
+func appName(components []swcat.Component) string {
+	for _, c := range components {
+		for _, text := range c.Name {
+			if text.Lang == "" {
+				return text.Value
+			}
+		}
+		if len(c.Name) > 0 {
+			return c.Name[0].Value
+		}
+	}
+	return ""
+}
+
+// isNotFound проверяет, что источник ничего не нашёл
+func isNotFound(err error) bool {
+	var apmErr apmerr.APMError
+	return errors.As(err, &apmErr) && apmErr.Type == apmerr.ErrorTypeNotFound
+}
@@ -0,0 +1,118 @@
+package search
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	_package "apm/internal/common/apt/package"
+	"apm/internal/common/sandbox"
+	"apm/internal/common/swcat"
+	"apm/internal/domain/distrobox"
+	"apm/internal/domain/system"
+	"context"
+	"errors"
+	"testing"
+)
+
+type mockSystem struct {
+	packages []_package.Package
+	err      error
+}
+
+func (m *mockSystem) Search(_ context.Context, _ string, _ bool, _ bool) (*system.SearchResponse, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &system.SearchResponse{Packages: m.packages}, nil
+}
+
+type mockAppStream struct {
+	components map[string][]swcat.Component
+}
+
+func (m *mockAppStream) GetByPkgNames(_ context.Context, _ []string) (map[string][]swcat.Component, error) {
+	return m.components, nil
+}
+
+type mockDistrobox struct {
+	packages []sandbox.PackageInfo
+	err      error
+}
+
+func (m *mockDistrobox) Search(_ context.Context, _ string, _ string) (*distrobox.SearchResponse, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &distrobox.SearchResponse{Packages: m.packages}, nil
+}
+
+func TestSearchMergesAndRanks(t *testing.T) {
+	actions := &Actions{
+		appConfig: &app.Config{},
+		serviceSystem: &mockSystem{packages: []_package.Package{
+			{Name: "libfirefox-helper", Summary: "Helper library"},
+			{Name: "mozilla-browser", Summary: "Web browser", HasAppStream: true},
+			{Name: "firefox-esr", Summary: "Firefox ESR", TypePackage: int(_package.PackageTypeStplr)},
+		}},
+		serviceAppStream: &mockAppStream{components: map[string][]swcat.Component{
+			"mozilla-browser": {{Name: swcat.LocalizedMap{{Value: "Firefox"}}}},
+		}},
+		serviceDistrobox: &mockDistrobox{packages: []sandbox.PackageInfo{
+			{Name: "firefox", Container: "arch", Installed: true},
+		}},
+	}
+
+	resp, err := actions.Search(context.Background(), "firefox", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Results) != 4 {
+		t.Fatalf("expected 4 results, got %+v", resp.Results)
+	}
+
+	first := resp.Results[0]
+	if first.Name != "firefox" || first.Source != SourceDistrobox || first.Container != "arch" {
+		t.Errorf("expected exact name match from distrobox first, got %+v", first)
+	}
+	if resp.Results[1].Name != "firefox-esr" || resp.Results[1].Source != SourceStplr {
+		t.Errorf("expected STPLR prefix match second, got %+v", resp.Results[1])
+	}
+	if resp.Results[2].Name != "mozilla-browser" || resp.Results[2].AppName != "Firefox" {
+		t.Errorf("expected AppStream application third, got %+v", resp.Results[2])
+	}
+	if resp.Results[3].Source != SourceSystem {
+		t.Errorf("expected system library last, got %+v", resp.Results[3])
+	}
+}
+
+func TestSearchSourceErrors(t *testing.T) {
+	actions := &Actions{
+		appConfig:        &app.Config{},
+		serviceSystem:    &mockSystem{err: apmerr.New(apmerr.ErrorTypeNotFound, errors.New("nothing"))},
+		serviceAppStream: &mockAppStream{},
+		serviceDistrobox: &mockDistrobox{packages: []sandbox.PackageInfo{
+			{Name: "htop", Container: "alt"},
+			{Name: "htop", Container: "arch", Installed: true},
+		}},
+	}
+
+	resp, err := actions.Search(context.Background(), "htop", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].Container != "arch" || len(resp.Warnings) != 0 {
+		t.Errorf("expected only installed htop from arch without warnings, got %+v", resp)
+	}
+
+	actions.serviceSystem = &mockSystem{err: apmerr.New(apmerr.ErrorTypePermission, errors.New("no database"))}
+	actions.serviceDistrobox = &mockDistrobox{err: apmerr.New(apmerr.ErrorTypeDatabase, errors.New("no database"))}
+	_, err = actions.Search(context.Background(), "htop", false)
+	var apmErr apmerr.APMError
+	if !errors.As(err, &apmErr) || apmErr.Type != apmerr.ErrorTypePermission {
+		t.Errorf("expected system error when all sources fail, got %v", err)
+	}
+
+	_, err = actions.Search(context.Background(), " ", false)
+	if !errors.As(err, &apmErr) || apmErr.Type != apmerr.ErrorTypeValidation {
+		t.Errorf("expected validation error for empty query, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package search
+
+import (
+	"apm/internal/common/app"
+	apmcli "apm/internal/common/cli"
+	"apm/internal/common/reply"
+	"context"
+
+	"github.com/urfave/cli/v3"
+)
+
+// newErrorResponseFromError создаёт ответ с ошибкой, извлекая тип из apmerr.APMError.
+func newErrorResponseFromError(err error) reply.APIResponse {
+	app.Log.Error(err.Error())
+	return reply.ErrorResponseFromError(err)
+}
+
+// Command команда поиска пакетов по всем источникам
+func Command(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
+	withGlobalWrapper := apmcli.WithOptions(appConfig, reporter, apmcli.NoRootCheck, NewActions, newErrorResponseFromError)
+
+	return &cli.Command{
+		Name:      "search",
+		Usage:     app.T_("Search packages in the system, STPLR and distrobox containers"),
+		ArgsUsage: "query",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "installed",
+				Usage:   app.T_("Only installed"),
+				Aliases: []string{"i"},
+				Value:   false,
+			},
+		},
+		Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+			resp, err := actions.Search(ctx, cmd.Args().First(), cmd.Bool("installed"))
+			if err != nil {
+				return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+			}
+			return reporter.CliResponse(ctx, reply.OK(resp))
+		}),
+	}
+}
@@ -0,0 +1,39 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package search
+
+import (
+	"apm/internal/common/swcat"
+	"apm/internal/domain/distrobox"
+	"apm/internal/domain/system"
+	"context"
+)
+
+// systemService определяет методы модуля system для поиска пакетов. В выдаче есть и пакеты STPLR.
+type systemService interface {
+	Search(ctx context.Context, packageName string, installed bool, description bool) (*system.SearchResponse, error)
+}
+
+// appStreamService определяет методы чтения метаданных AppStream, загруженных модулем system.
+type appStreamService interface {
+	GetByPkgNames(ctx context.Context, names []string) (map[string][]swcat.Component, error)
+}
+
+// distroboxService определяет методы модуля distrobox для поиска пакетов в контейнерах.
+type distroboxService interface {
+	Search(ctx context.Context, container string, packageName string) (*distrobox.SearchResponse, error)
+}
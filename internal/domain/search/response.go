@@ -0,0 +1,43 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package search
+
+// Источники результатов поиска
+const (
+	SourceSystem    = "system"
+	SourceStplr     = "stplr"
+	SourceDistrobox = "distrobox"
+)
+
+// Result найденный пакет с указанием источника
+type Result struct {
+	Name      string `json:"name"`
+	Source    string `json:"source"`
+	Container string `json:"container,omitempty"`
+	Version   string `json:"version"`
+	Summary   string `json:"summary"`
+	AppName   string `json:"appName,omitempty"`
+	Installed bool   `json:"installed"`
+	Score     int    `json:"score"`
+}
+
+// SearchResponse структура ответа для Search метода
+type SearchResponse struct {
+	Message  string   `json:"message"`
+	Results  []Result `json:"results"`
+	Warnings []string `json:"warnings,omitempty"`
+}
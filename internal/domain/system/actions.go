@@ -27,16 +27,32 @@ import (
 	"apm/internal/common/build/lint"
 	"apm/internal/common/command"
 	"apm/internal/common/filter"
+	"apm/internal/common/helper"
+	"apm/internal/common/hooks"
+	"apm/internal/common/journal"
+	"apm/internal/common/oplock"
+	"apm/internal/common/oplog"
 	"apm/internal/common/reply"
+	"apm/internal/common/sandbox"
 	"apm/internal/common/swcat"
 	kservice "apm/internal/domain/kernel/service"
 	reposervice "apm/internal/domain/repository/service"
+	"apm/internal/domain/system/autoupgrade"
 	"apm/internal/domain/system/dialog"
+	"apm/internal/domain/system/group"
+	"apm/internal/domain/system/localrpm"
+	"apm/internal/domain/system/restart"
+	"apm/internal/domain/system/rpmdup"
+	"apm/internal/domain/system/rpmnew"
+	"apm/internal/domain/system/schedule"
+	"apm/internal/domain/system/stplr"
 	"apm/internal/domain/system/temporary"
+	"apm/internal/domain/system/units"
 	"context"
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"syscall"
 )
@@ -52,6 +68,30 @@ type Actions struct {
 	serviceHostConfig      hostConfigService
 	serviceTemporaryConfig temporaryConfigService
 	serviceAppStreamDB     appStreamService
+	serviceJournal         journalService
+	serviceLogReader       logReaderService
+	serviceSchedule        applyScheduleService
+	serviceAutoUpgrade     autoUpgradeService
+	serviceUnits           unitService
+	serviceRpmnew          rpmnewService
+	serviceRpmDup          rpmDupService
+	serviceLocalRpm        localRpmService
+	serviceRestart         restartService
+	serviceKernel          kernelInfoService
+	serviceRepos           repoListService
+	serviceContainers      containerListService
+	serviceHooks           hooksService
+	serviceStplr           stplrService
+	serviceGroups          groupService
+	operationLock          *oplock.Lock
+	stateNotifier          app.StateNotifier
+	conflictPolicy         apt.ConflictPolicy
+	forceEssential         bool
+	allowUnsigned          bool
+	downloadDir            string
+	offlineDir             string
+	packageGroups          map[string]string
+	indexesFresh           bool
 }
 
 // NewActions создаёт новый экземпляр Actions.
@@ -88,6 +128,23 @@ func NewActions(appConfig *app.Config, reporter *reply.Reporter) *Actions {
 		serviceHostConfig:      hostConfigSvc,
 		serviceTemporaryConfig: hostTemporarySvc,
 		serviceAppStreamDB:     appStreamDBSvc,
+		serviceJournal:         journal.NewService(appConfig.DatabaseManager),
+		serviceLogReader:       oplog.NewReader(),
+		serviceSchedule:        schedule.NewManager(runner, filepath.Join(app.StateDir(cfg), schedule.StateFileName)),
+		serviceAutoUpgrade:     autoupgrade.NewManager(runner, autoupgrade.DefaultUnitDir),
+		serviceUnits:           units.NewManager(runner, units.DefaultDirs(), httpUnitOptions(cfg)),
+		serviceRpmnew:          rpmnew.NewManager(runner, "/etc"),
+		serviceRpmDup:          rpmdup.NewManager(runner, aptBinding.NewActions()),
+		serviceLocalRpm:        localrpm.NewManager(runner, appConfig.ConfigManager.GetResourcesDir()),
+		serviceRestart:         restart.NewManager(runner, "/proc", cfg.RestartBlacklist),
+		serviceKernel:          kservice.NewKernelManager(hostPackageDBSvc, aptBinding.NewActions(), runner, reporter),
+		serviceRepos:           reposervice.NewRepoService(hostPackageDBSvc, runner, reporter),
+		serviceContainers:      sandbox.NewDistroAPIService(runner, reporter),
+		serviceHooks:           hooks.NewService(runner, cfg.PathHooksDir),
+		serviceStplr:           stplr.NewManager(runner, stplr.DefaultBuildDir),
+		serviceGroups:          group.NewService(group.DefaultDirs),
+		operationLock:          oplock.Shared(),
+		stateNotifier:          app.DBusStateNotifier(appConfig.DBusManager),
 	}
 }
 
@@ -118,9 +175,10 @@ func (a *Actions) CheckRemove(ctx context.Context, packages []string, purge bool
 	if aptError != nil {
 		return nil, apmerr.New(apmerr.ErrorTypeApt, aptError)
 	}
+	a.markProtected(ctx, packageParse)
 
 	return &CheckResponse{
-		Message: app.T_("Inspection information"),
+		Message: app.TL_(ctx, "Inspection information"),
 		Info:    *packageParse,
 	}, nil
 }
@@ -133,18 +191,33 @@ func (a *Actions) CheckUpgrade(ctx context.Context) (*CheckResponse, error) {
 	}
 
 	return &CheckResponse{
-		Message: app.T_("Inspection information"),
+		Message: app.TL_(ctx, "Inspection information"),
 		Info:    *packageParse,
 	}, nil
 }
 
 // CheckInstall проверяем пакеты перед установкой
 func (a *Actions) CheckInstall(ctx context.Context, packages []string) (*CheckResponse, error) {
+	var (
+		offlineNames map[string]bool
+		err          error
+	)
+	if a.offlineDir != "" {
+		if packages, offlineNames, err = a.offlinePackages(ctx, packages); err != nil {
+			return nil, err
+		}
+	}
+
 	if len(packages) == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.T_("You must specify at least one package")))
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify at least one package")))
 	}
 
-	err := a.validateDB(ctx, false)
+	err = a.validateDB(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	packages, _, err = a.prepareLocalPackages(ctx, packages, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -165,9 +238,13 @@ func (a *Actions) CheckInstall(ctx context.Context, packages []string) (*CheckRe
 	if errFind != nil {
 		return nil, apmerr.New(apmerr.ErrorTypeApt, errFind)
 	}
+	if err = checkOfflineChanges(ctx, packageParse, offlineNames); err != nil {
+		return nil, err
+	}
+	a.markProtected(ctx, packageParse)
 
 	return &CheckResponse{
-		Message: app.T_("Inspection information"),
+		Message: app.TL_(ctx, "Inspection information"),
 		Info:    *packageParse,
 	}, nil
 }
@@ -179,13 +256,23 @@ func (a *Actions) Remove(ctx context.Context, packages []string, purge bool, dep
 		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
 	}
 
+	ctx, release, err := a.operationLock.Acquire(ctx, journal.ActionRemove)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	err = a.validateDB(ctx, false)
 	if err != nil {
 		return nil, err
 	}
 
+	if err = a.checkInterrupted(ctx); err != nil {
+		return nil, err
+	}
+
 	if len(packages) == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.T_("At least one package must be specified")))
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "At least one package must be specified")))
 	}
 
 	_, packageNames, packagesInfo, packageParse, errFind := a.serviceAptActions.FindPackage(ctx,
@@ -195,43 +282,67 @@ func (a *Actions) Remove(ctx context.Context, packages []string, purge bool, dep
 	}
 
 	if packageParse.RemovedCount == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeNotFound, errors.New(app.T_("No candidates for removal found")))
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, errors.New(app.TL_(ctx, "No candidates for removal found")))
+	}
+
+	protected := a.markProtected(ctx, packageParse)
+	if err = a.guardProtected(ctx, protected); err != nil {
+		return nil, err
 	}
+	a.markGroups(packagesInfo)
 
 	if !confirm {
 		reply.StopSpinner(a.appConfig)
-		dialogStatus, err := dialog.NewDialog(a.appConfig, packagesInfo, *packageParse, dialog.ActionRemove)
+		dialogStatus, err := dialog.NewDialog(ctx, a.appConfig, packagesInfo, *packageParse, dialog.ActionRemove)
 		if err != nil {
 			return nil, err
 		}
 
 		if !dialogStatus {
-			return nil, apmerr.New(apmerr.ErrorTypeCanceled, errors.New(app.T_("Cancel dialog")))
+			return nil, apmerr.New(apmerr.ErrorTypeCanceled, errors.New(app.TL_(ctx, "Cancel dialog")))
 		}
 
 		reply.CreateSpinner(a.appConfig)
 	}
 
+	if err = a.confirmProtected(ctx, protected); err != nil {
+		return nil, err
+	}
+
+	hookTx := hookTransaction(ctx, hooks.EventRemove, packageNames, packageParse)
+	if err = a.beforeHooks(ctx, hookTx); err != nil {
+		return nil, err
+	}
+
+	journalID := a.beginJournal(ctx, journal.ActionRemove, packageParse)
+	defer a.finishJournal(ctx, journalID)
+
 	err = a.serviceAptActions.Remove(ctx, packageNames, purge, depends)
+	errHooks := a.afterHooks(ctx, hookTx, err)
 	if err != nil {
 		return nil, apmerr.New(apmerr.ErrorTypeApt, err)
 	}
+	a.recordJournal(ctx, journalID, journal.ActionRemove, packageParse)
 
 	removePackageNames := strings.Join(packageParse.RemovedPackages, ", ")
 	err = a.updateAllPackagesDB(ctx)
+	a.notifyPackagesChanged(journal.ActionRemove, packageParse)
 	if err != nil {
 		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
 	}
 
-	messageAnswer := fmt.Sprintf(app.TN_("%s removed successfully", "%s removed successfully", packageParse.RemovedCount), removePackageNames)
+	messageAnswer := fmt.Sprintf(app.TLN_(ctx, "%s removed successfully", "%s removed successfully", packageParse.RemovedCount), removePackageNames)
 
 	if a.appConfig.ConfigManager.GetConfig().IsAtomic {
-		messageAnswer += app.T_(". The system image has not been changed. To apply the changes, run: apm s image apply")
+		messageAnswer += app.TL_(ctx, ". The system image has not been changed. To apply the changes, run: apm s image apply")
 		errSave := a.saveChange(ctx, []string{}, packageNames)
 		if errSave != nil {
 			return nil, apmerr.New(apmerr.ErrorTypeImage, errSave)
 		}
 	}
+	if errHooks != nil {
+		return nil, errHooks
+	}
 
 	return &InstallRemoveResponse{
 		Message: messageAnswer,
@@ -239,6 +350,34 @@ func (a *Actions) Remove(ctx context.Context, packages []string, purge bool, dep
 	}, nil
 }
 
+// CheckAutoRemove проверяем пакеты, которые больше не нужны системе
+func (a *Actions) CheckAutoRemove(ctx context.Context) (*CheckResponse, error) {
+	packageParse, aptError := a.serviceAptActions.CheckAutoRemove(ctx)
+	if aptError != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeApt, aptError)
+	}
+	a.markProtected(ctx, packageParse)
+
+	return &CheckResponse{
+		Message: app.TL_(ctx, "Inspection information"),
+		Info:    *packageParse,
+	}, nil
+}
+
+// AutoRemove удаляет пакеты, установленные как зависимости и более никем не используемые.
+func (a *Actions) AutoRemove(ctx context.Context, confirm bool) (*InstallRemoveResponse, error) {
+	check, err := a.CheckAutoRemove(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if check.Info.RemovedCount == 0 {
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.TL_(ctx, "No unused packages to remove")))
+	}
+
+	return a.Remove(ctx, check.Info.RemovedPackages, false, false, confirm)
+}
+
 // Install осуществляет установку системного пакета.
 func (a *Actions) Install(ctx context.Context, packages []string, confirm bool, downloadOnly bool) (*InstallRemoveResponse, error) {
 	err := a.checkOverlay(ctx)
@@ -246,13 +385,50 @@ func (a *Actions) Install(ctx context.Context, packages []string, confirm bool,
 		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
 	}
 
+	ctx, release, err := a.operationLock.Acquire(ctx, journal.ActionInstall)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	err = a.validateDB(ctx, false)
 	if err != nil {
 		return nil, err
 	}
 
+	if err = a.checkInterrupted(ctx); err != nil {
+		return nil, err
+	}
+
+	var offlineNames map[string]bool
+	if a.offlineDir != "" {
+		if packages, offlineNames, err = a.offlinePackages(ctx, packages); err != nil {
+			return nil, err
+		}
+	}
+
 	if len(packages) == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.T_("You must specify at least one package")))
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify at least one package")))
+	}
+
+	if downloadOnly && a.downloadDir != "" {
+		if err = a.useDownloadDir(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Собранные пакеты STPLR подтверждаются так же, как локальные файлы, но не проверяются rpm -K:
+	// они не подписаны и собраны в каталоге, доступном только текущему пользователю
+	packages, stplrFiles, cleanupStplr, err := a.buildStplrPackages(ctx, packages)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanupStplr()
+
+	var localFiles []string
+	packages, localFiles, err = a.prepareLocalPackages(ctx, packages, stplrFiles)
+	if err != nil {
+		return nil, err
 	}
 
 	packagesInstall, packagesRemove, errPrepare := a.serviceAptActions.PrepareInstallPackages(ctx, packages)
@@ -270,7 +446,7 @@ func (a *Actions) Install(ctx context.Context, packages []string, confirm bool,
 			}
 		}
 	}
-	if !allLocalRpm {
+	if !allLocalRpm && !a.indexesFresh {
 		err = a.serviceAptActions.AptUpdate(ctx)
 		if err != nil {
 			return nil, apmerr.New(apmerr.ErrorTypeApt, err)
@@ -290,7 +466,20 @@ func (a *Actions) Install(ctx context.Context, packages []string, confirm bool,
 	}
 
 	if packageParse.NewInstalledCount == 0 && packageParse.UpgradedCount == 0 && packageParse.RemovedCount == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.T_("The operation will not make any changes")))
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.TL_(ctx, "The operation will not make any changes")))
+	}
+	if err = checkOfflineChanges(ctx, packageParse, offlineNames); err != nil {
+		return nil, err
+	}
+	a.markLocalFiles(ctx, localFiles, packagesInfo)
+	a.markGroups(packagesInfo)
+
+	var protected []string
+	if !downloadOnly {
+		protected = a.markProtected(ctx, packageParse)
+		if err = a.guardProtected(ctx, protected); err != nil {
+			return nil, err
+		}
 	}
 
 	if len(packagesInfo) > 0 && !confirm {
@@ -305,19 +494,37 @@ func (a *Actions) Install(ctx context.Context, packages []string, confirm bool,
 			action = dialog.ActionInstall
 		}
 
-		dialogStatus, errDialog := dialog.NewDialog(a.appConfig, packagesInfo, *packageParse, action)
+		dialogStatus, errDialog := dialog.NewDialog(ctx, a.appConfig, packagesInfo, *packageParse, action)
 		if errDialog != nil {
 			return nil, errDialog
 		}
 
 		if !dialogStatus {
-			return nil, apmerr.New(apmerr.ErrorTypeCanceled, errors.New(app.T_("Cancel dialog")))
+			return nil, apmerr.New(apmerr.ErrorTypeCanceled, errors.New(app.TL_(ctx, "Cancel dialog")))
 		}
 
 		reply.CreateSpinner(a.appConfig)
 	}
 
-	errInstall := a.serviceAptActions.CombineInstallRemovePackages(ctx, packagesInstall, packagesRemove, false, false, downloadOnly)
+	if err = a.confirmProtected(ctx, protected); err != nil {
+		return nil, err
+	}
+
+	var journalID uint
+	hookTx := hookTransaction(ctx, hooks.EventInstall, packages, packageParse)
+	if !downloadOnly {
+		if err = a.beforeHooks(ctx, hookTx); err != nil {
+			return nil, err
+		}
+		journalID = a.beginJournal(ctx, journal.ActionInstall, packageParse)
+		defer a.finishJournal(ctx, journalID)
+	}
+
+	packagesInstall, packagesRemove, errInstall := a.installWithConflictResolution(ctx, packagesInstall, packagesRemove, downloadOnly, confirm)
+	var errHooks error
+	if !downloadOnly {
+		errHooks = a.afterHooks(ctx, hookTx, errInstall)
+	}
 	if errInstall != nil {
 		var matchedErr *apt.MatchedError
 		if errors.As(errInstall, &matchedErr) && matchedErr.NeedUpdate() {
@@ -326,7 +533,12 @@ func (a *Actions) Install(ctx context.Context, packages []string, confirm bool,
 				return nil, apmerr.New(apmerr.ErrorTypeApt, err)
 			}
 
-			return nil, apmerr.New(apmerr.ErrorTypeRepository, errors.New(app.T_("A repository connection error occurred. The package list has been updated, please try running the command again")))
+			return nil, apmerr.New(apmerr.ErrorTypeRepository, errors.New(app.TL_(ctx, "A repository connection error occurred. The package list has been updated, please try running the command again")))
+		}
+
+		var apmErr apmerr.APMError
+		if errors.As(errInstall, &apmErr) {
+			return nil, errInstall
 		}
 
 		return nil, apmerr.New(apmerr.ErrorTypeApt, errInstall)
@@ -336,30 +548,46 @@ func (a *Actions) Install(ctx context.Context, packages []string, confirm bool,
 
 	if downloadOnly {
 		messageAnswer = fmt.Sprintf(
-			app.TN_("%d package successfully downloaded", "%d packages successfully downloaded", packageParse.NewInstalledCount+packageParse.UpgradedCount),
+			app.TLN_(ctx, "%d package successfully downloaded", "%d packages successfully downloaded", packageParse.NewInstalledCount+packageParse.UpgradedCount),
 			packageParse.NewInstalledCount+packageParse.UpgradedCount,
 		)
+
+		if a.downloadDir != "" {
+			manifest, errManifest := a.writeDownloadManifest(ctx, packages)
+			if errManifest != nil {
+				return nil, errManifest
+			}
+			messageAnswer += fmt.Sprintf(app.TLN_(ctx, ". The set of %d package is saved to %s, install it with: apm s install --offline %s",
+				". The set of %d packages is saved to %s, install it with: apm s install --offline %s", len(manifest.Packages)),
+				len(manifest.Packages), a.downloadDir, a.downloadDir)
+		}
 	} else {
+		a.recordJournal(ctx, journalID, journal.ActionInstall, packageParse)
+
 		err = a.updateAllPackagesDB(ctx)
+		a.notifyPackagesChanged(journal.ActionInstall, packageParse)
 		if err != nil {
 			return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
 		}
 
 		messageAnswer = fmt.Sprintf(
 			"%s %s %s",
-			fmt.Sprintf(app.TN_("%d package successfully installed", "%d packages successfully installed", packageParse.NewInstalledCount), packageParse.NewInstalledCount),
-			app.T_("and"),
-			fmt.Sprintf(app.TN_("%d updated", "%d updated", packageParse.UpgradedCount), packageParse.UpgradedCount),
+			fmt.Sprintf(app.TLN_(ctx, "%d package successfully installed", "%d packages successfully installed", packageParse.NewInstalledCount), packageParse.NewInstalledCount),
+			app.TL_(ctx, "and"),
+			fmt.Sprintf(app.TLN_(ctx, "%d updated", "%d updated", packageParse.UpgradedCount), packageParse.UpgradedCount),
 		)
 
 		if a.appConfig.ConfigManager.GetConfig().IsAtomic {
-			messageAnswer += app.T_(". The system image has not been changed. To apply the changes, run: apm s image apply")
+			messageAnswer += app.TL_(ctx, ". The system image has not been changed. To apply the changes, run: apm s image apply")
 			errSave := a.saveChange(ctx, packagesInstall, packagesRemove)
 			if errSave != nil {
 				return nil, apmerr.New(apmerr.ErrorTypeImage, errSave)
 			}
 		}
 	}
+	if errHooks != nil {
+		return nil, errHooks
+	}
 
 	return &InstallRemoveResponse{
 		Message: messageAnswer,
@@ -370,7 +598,7 @@ func (a *Actions) Install(ctx context.Context, packages []string, confirm bool,
 // CheckReinstall проверяем пакеты перед переустановкой
 func (a *Actions) CheckReinstall(ctx context.Context, packages []string) (*CheckResponse, error) {
 	if len(packages) == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.T_("You must specify at least one package")))
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify at least one package")))
 	}
 
 	packagesInstall, packagesRemove, errPrepare := a.serviceAptActions.PrepareInstallPackages(ctx, packages)
@@ -391,7 +619,7 @@ func (a *Actions) CheckReinstall(ctx context.Context, packages []string) (*Check
 	}
 
 	return &CheckResponse{
-		Message: app.T_("Inspection information"),
+		Message: app.TL_(ctx, "Inspection information"),
 		Info:    *packageParse,
 	}, nil
 }
@@ -403,13 +631,23 @@ func (a *Actions) Reinstall(ctx context.Context, packages []string, confirm bool
 		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
 	}
 
+	ctx, release, err := a.operationLock.Acquire(ctx, journal.ActionReinstall)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	err = a.validateDB(ctx, false)
 	if err != nil {
 		return nil, err
 	}
 
+	if err = a.checkInterrupted(ctx); err != nil {
+		return nil, err
+	}
+
 	if len(packages) == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.T_("You must specify at least one package")))
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify at least one package")))
 	}
 
 	packagesInstall, _, errPrepare := a.serviceAptActions.PrepareInstallPackages(ctx, packages)
@@ -430,24 +668,27 @@ func (a *Actions) Reinstall(ctx context.Context, packages []string, confirm bool
 	}
 
 	if packageParse.NewInstalledCount == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.T_("The operation will not make any changes")))
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.TL_(ctx, "The operation will not make any changes")))
 	}
 
 	if !confirm {
 		reply.StopSpinner(a.appConfig)
 
-		dialogStatus, errDialog := dialog.NewDialog(a.appConfig, packagesInfo, *packageParse, dialog.ActionInstall)
+		dialogStatus, errDialog := dialog.NewDialog(ctx, a.appConfig, packagesInfo, *packageParse, dialog.ActionInstall)
 		if errDialog != nil {
 			return nil, errDialog
 		}
 
 		if !dialogStatus {
-			return nil, apmerr.New(apmerr.ErrorTypeCanceled, errors.New(app.T_("Cancel dialog")))
+			return nil, apmerr.New(apmerr.ErrorTypeCanceled, errors.New(app.TL_(ctx, "Cancel dialog")))
 		}
 
 		reply.CreateSpinner(a.appConfig)
 	}
 
+	journalID := a.beginJournal(ctx, journal.ActionReinstall, packageParse)
+	defer a.finishJournal(ctx, journalID)
+
 	errReinstall := a.serviceAptActions.ReinstallPackages(ctx, packagesInstall)
 	if errReinstall != nil {
 		var matchedErr *apt.MatchedError
@@ -457,19 +698,21 @@ func (a *Actions) Reinstall(ctx context.Context, packages []string, confirm bool
 				return nil, apmerr.New(apmerr.ErrorTypeApt, err)
 			}
 
-			return nil, apmerr.New(apmerr.ErrorTypeRepository, errors.New(app.T_("A repository connection error occurred. The package list has been updated, please try running the command again")))
+			return nil, apmerr.New(apmerr.ErrorTypeRepository, errors.New(app.TL_(ctx, "A repository connection error occurred. The package list has been updated, please try running the command again")))
 		}
 
 		return nil, apmerr.New(apmerr.ErrorTypeApt, errReinstall)
 	}
+	a.recordJournal(ctx, journalID, journal.ActionReinstall, packageParse)
 
 	err = a.updateAllPackagesDB(ctx)
+	a.notifyPackagesChanged(journal.ActionReinstall, packageParse)
 	if err != nil {
 		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
 	}
 
 	messageAnswer := fmt.Sprintf(
-		app.TN_("%d package successfully reinstalled", "%d packages successfully reinstalled", packageParse.NewInstalledCount),
+		app.TLN_(ctx, "%d package successfully reinstalled", "%d packages successfully reinstalled", packageParse.NewInstalledCount),
 		packageParse.NewInstalledCount,
 	)
 
@@ -486,6 +729,16 @@ func (a *Actions) Update(ctx context.Context, noLock bool, onlyDB bool) (*Update
 		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
 	}
 
+	// Без блокировки APT (сборка образа) блокировка операций apm также не используется
+	if !noLock {
+		var release func()
+		ctx, release, err = a.operationLock.Acquire(ctx, journal.ActionUpdate)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+
 	err = a.validateDB(ctx, noLock)
 	if err != nil {
 		return nil, err
@@ -500,7 +753,7 @@ func (a *Actions) Update(ctx context.Context, noLock bool, onlyDB bool) (*Update
 			app.Log.Debugf("UpdateAppStreamLinks: %v", err)
 		}
 		return &UpdateResponse{
-			Message: app.T_("Installed package status updated"),
+			Message: app.TL_(ctx, "Installed package status updated"),
 			Count:   len(packages),
 		}, nil
 	}
@@ -509,19 +762,24 @@ func (a *Actions) Update(ctx context.Context, noLock bool, onlyDB bool) (*Update
 	if err != nil {
 		return nil, apmerr.New(apmerr.ErrorTypeApt, err)
 	}
+	a.syncStplr(ctx)
 
 	if err = a.serviceAptDatabase.UpdateAppStreamLinks(ctx); err != nil {
 		app.Log.Debugf("UpdateAppStreamLinks: %v", err)
 	}
 
 	return &UpdateResponse{
-		Message: app.T_("Package list updated successfully"),
+		Message: app.TL_(ctx, "Package list updated successfully"),
 		Count:   len(packages),
 	}, nil
 }
 
-// ImageBuild Update Сборка образа
-func (a *Actions) ImageBuild(ctx context.Context, configPath, workdir string) (*ImageBuild, error) {
+// ImageBuild Update Сборка образа. С tag собирает отдельный вариант образа на хосте, не применяя его
+func (a *Actions) ImageBuild(ctx context.Context, configPath, workdir, tag string) (*ImageBuild, error) {
+	if tag != "" {
+		return a.imageBuildTagged(ctx, configPath, workdir, tag)
+	}
+
 	a.appConfig.ConfigManager.EnableVerbose()
 	reply.StopSpinner(a.appConfig)
 
@@ -559,7 +817,7 @@ func (a *Actions) ImageBuild(ctx context.Context, configPath, workdir string) (*
 	hostPackageDBSvc := _package.NewPackageDBService(a.appConfig.DatabaseManager, a.reporter)
 	aptActions := aptBinding.NewActions()
 	kernelManager := kservice.NewKernelManager(hostPackageDBSvc, aptActions, runner, a.reporter)
-	repoService := reposervice.NewRepoService(hostPackageDBSvc, runner)
+	repoService := reposervice.NewRepoService(hostPackageDBSvc, runner, a.reporter)
 	buildConfigSvc := build.NewConfigService(a.appConfig, a.reporter, a.serviceAptActions, hostPackageDBSvc, kernelManager, repoService, a.serviceHostConfig, runner)
 
 	err = buildConfigSvc.Build(ctx)
@@ -568,22 +826,82 @@ func (a *Actions) ImageBuild(ctx context.Context, configPath, workdir string) (*
 	}
 
 	return &ImageBuild{
-		Message: app.T_("DONE"),
+		Message: app.TL_(ctx, "DONE"),
+	}, nil
+}
+
+// imageBuildTagged собирает вариант образа из файла конфигурации и сохраняет его под тегом
+func (a *Actions) imageBuildTagged(ctx context.Context, configPath, workdir, tag string) (*ImageBuild, error) {
+	if !a.appConfig.ConfigManager.GetConfig().IsAtomic {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, errors.New(app.TL_(ctx, "This option is only available for an atomic system")))
+	}
+
+	if syscall.Geteuid() != 0 {
+		return nil, apmerr.New(apmerr.ErrorTypePermission, errors.New(app.TL_(ctx, "Elevated rights are required to perform this action. Please use sudo or su")))
+	}
+
+	if err := build.ValidateImageTag(tag); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, err)
+	}
+
+	if configPath != "" {
+		if _, err := os.Stat(configPath); err != nil {
+			return nil, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.TL_(ctx, "Configuration file %s not found"), configPath))
+		}
+	}
+
+	if err := a.serviceHostConfig.ApplyPathOverrides(configPath, workdir); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+
+	if err := a.serviceHostConfig.LoadConfig(); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+
+	cfg := a.serviceHostConfig.GetConfig()
+	if err := cfg.CheckImage(); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+
+	imageID, err := a.serviceHostImage.BuildTaggedImage(ctx, *cfg, tag, false, true)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+
+	return &ImageBuild{
+		Message: fmt.Sprintf(app.TL_(ctx, "Image variant %s built. Apply it with 'apm system image apply --tag %s'"), build.TaggedImageName(tag), tag),
+		Tag:     tag,
+		ImageID: imageID,
 	}, nil
 }
 
 // Upgrade общее обновление системы
 func (a *Actions) Upgrade(ctx context.Context, downloadOnly bool) (*UpgradeResponse, error) {
+	return a.upgrade(ctx, downloadOnly, false)
+}
+
+// upgrade выполняет общее обновление системы. С confirm диалог подтверждения не показывается
+func (a *Actions) upgrade(ctx context.Context, downloadOnly bool, confirm bool) (*UpgradeResponse, error) {
 	err := a.checkOverlay(ctx)
 	if err != nil {
 		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
 	}
 
+	ctx, release, err := a.operationLock.Acquire(ctx, journal.ActionUpgrade)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	err = a.validateDB(ctx, false)
 	if err != nil {
 		return nil, err
 	}
 
+	if err = a.checkInterrupted(ctx); err != nil {
+		return nil, err
+	}
+
 	_, err = a.serviceAptActions.Update(ctx)
 	if err != nil {
 		return nil, apmerr.New(apmerr.ErrorTypeApt, err)
@@ -595,28 +913,46 @@ func (a *Actions) Upgrade(ctx context.Context, downloadOnly bool) (*UpgradeRespo
 	}
 
 	if packageParse.NewInstalledCount == 0 && packageParse.UpgradedCount == 0 && packageParse.RemovedCount == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.T_("The operation will not make any changes")))
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.TL_(ctx, "The operation will not make any changes")))
 	}
 
-	reply.StopSpinner(a.appConfig)
+	if !confirm {
+		reply.StopSpinner(a.appConfig)
 
-	action := dialog.ActionUpgrade
-	if downloadOnly {
-		action = dialog.ActionDownload
-	}
+		action := dialog.ActionUpgrade
+		if downloadOnly {
+			action = dialog.ActionDownload
+		}
 
-	dialogStatus, err := dialog.NewDialog(a.appConfig, []_package.Package{}, *packageParse, action)
-	if err != nil {
-		return nil, err
-	}
+		dialogStatus, errDialog := dialog.NewDialog(ctx, a.appConfig, []_package.Package{}, *packageParse, action)
+		if errDialog != nil {
+			return nil, errDialog
+		}
 
-	if !dialogStatus {
-		return nil, apmerr.New(apmerr.ErrorTypeCanceled, errors.New(app.T_("Cancel dialog")))
+		if !dialogStatus {
+			return nil, apmerr.New(apmerr.ErrorTypeCanceled, errors.New(app.TL_(ctx, "Cancel dialog")))
+		}
+
+		reply.CreateSpinner(a.appConfig)
 	}
 
-	reply.CreateSpinner(a.appConfig)
+	rpmnewBefore := a.serviceRpmnew.Snapshot()
+
+	var journalID uint
+	hookTx := hookTransaction(ctx, hooks.EventUpgrade, nil, packageParse)
+	if !downloadOnly {
+		if err = a.beforeHooks(ctx, hookTx); err != nil {
+			return nil, err
+		}
+		journalID = a.beginJournal(ctx, journal.ActionUpgrade, packageParse)
+		defer a.finishJournal(ctx, journalID)
+	}
 
 	errUpgrade := a.serviceAptActions.Upgrade(ctx, downloadOnly)
+	var errHooks error
+	if !downloadOnly {
+		errHooks = a.afterHooks(ctx, hookTx, errUpgrade)
+	}
 	if errUpgrade != nil {
 		return nil, apmerr.New(apmerr.ErrorTypeApt, errUpgrade)
 	}
@@ -624,31 +960,44 @@ func (a *Actions) Upgrade(ctx context.Context, downloadOnly bool) (*UpgradeRespo
 	if downloadOnly {
 		total := packageParse.NewInstalledCount + packageParse.UpgradedCount
 		messageAnswer := fmt.Sprintf(
-			app.TN_("%d package successfully downloaded", "%d packages successfully downloaded", total),
+			app.TLN_(ctx, "%d package successfully downloaded", "%d packages successfully downloaded", total),
 			total,
 		)
 
 		return &UpgradeResponse{
-			Message: app.T_("Download complete"),
+			Message: app.TL_(ctx, "Download complete"),
 			Result:  &messageAnswer,
 		}, nil
 	}
+	a.recordJournal(ctx, journalID, journal.ActionUpgrade, packageParse)
 
 	err = a.updateAllPackagesDB(ctx)
+	a.notifyPackagesChanged(journal.ActionUpgrade, packageParse)
 	if err != nil {
 		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
 	}
 
+	configFiles := a.resolveConfigMerges(ctx, rpmnewBefore)
+	needRestart := a.findServicesToRestart(ctx)
+
 	messageAnswer := fmt.Sprintf(
 		"%s %s %s",
-		fmt.Sprintf(app.TN_("%d package successfully installed", "%d packages successfully installed", packageParse.NewInstalledCount), packageParse.NewInstalledCount),
-		app.T_("and"),
-		fmt.Sprintf(app.TN_("%d updated", "%d updated", packageParse.UpgradedCount), packageParse.UpgradedCount),
+		fmt.Sprintf(app.TLN_(ctx, "%d package successfully installed", "%d packages successfully installed", packageParse.NewInstalledCount), packageParse.NewInstalledCount),
+		app.TL_(ctx, "and"),
+		fmt.Sprintf(app.TLN_(ctx, "%d updated", "%d updated", packageParse.UpgradedCount), packageParse.UpgradedCount),
 	)
+	if len(needRestart) > 0 {
+		messageAnswer += ". " + restartHint(ctx, needRestart)
+	}
+	if errHooks != nil {
+		return nil, errHooks
+	}
 
 	return &UpgradeResponse{
-		Message: app.T_("The system has been upgrade successfully"),
-		Result:  &messageAnswer,
+		Message:     app.TL_(ctx, "The system has been upgrade successfully"),
+		Result:      &messageAnswer,
+		ConfigFiles: configFiles,
+		NeedRestart: needRestart,
 	}, nil
 }
 
@@ -656,7 +1005,7 @@ func (a *Actions) Upgrade(ctx context.Context, downloadOnly bool) (*UpgradeRespo
 func (a *Actions) Info(ctx context.Context, packageName string) (*InfoResponse, error) {
 	packageName = strings.TrimSpace(packageName)
 	if packageName == "" {
-		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.T_("Package name must be specified, for example info package")))
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Package name must be specified, for example info package")))
 	}
 
 	err := a.validateDB(ctx, false)
@@ -676,7 +1025,7 @@ func (a *Actions) Info(ctx context.Context, packageName string) (*InfoResponse,
 		}
 
 		if len(alternativePackages) == 0 {
-			errorFindPackage := fmt.Sprintf(app.T_("Failed to retrieve information about the package %s"), packageName)
+			errorFindPackage := fmt.Sprintf(app.TL_(ctx, "Failed to retrieve information about the package %s"), packageName)
 			return nil, apmerr.New(apmerr.ErrorTypeNotFound, errors.New(errorFindPackage))
 		} else if len(alternativePackages) == 1 {
 			packageInfo = alternativePackages[0]
@@ -686,7 +1035,7 @@ func (a *Actions) Info(ctx context.Context, packageName string) (*InfoResponse,
 				altNames = append(altNames, altPkg.Name)
 			}
 
-			message := err.Error() + app.T_(". Maybe you were looking for: ")
+			message := err.Error() + app.TL_(ctx, ". Maybe you were looking for: ")
 
 			return nil, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(message+"%s", strings.Join(altNames, " ")))
 		}
@@ -697,7 +1046,7 @@ func (a *Actions) Info(ctx context.Context, packageName string) (*InfoResponse,
 	packageInfo = pkgs[0]
 
 	return &InfoResponse{
-		Message:     app.T_("Package found"),
+		Message:     app.TL_(ctx, "Package found"),
 		PackageInfo: packageInfo,
 	}, nil
 }
@@ -705,7 +1054,7 @@ func (a *Actions) Info(ctx context.Context, packageName string) (*InfoResponse,
 // MultiInfo возвращает информацию о нескольких пакетах одним запросом.
 func (a *Actions) MultiInfo(ctx context.Context, packageNames []string) (*MultiInfoResponse, error) {
 	if len(packageNames) == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.T_("Package list must not be empty")))
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Package list must not be empty")))
 	}
 
 	err := a.validateDB(ctx, false)
@@ -722,7 +1071,7 @@ func (a *Actions) MultiInfo(ctx context.Context, packageNames []string) (*MultiI
 	}
 
 	if len(names) == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.T_("Package list must not be empty")))
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Package list must not be empty")))
 	}
 
 	packages, err := a.serviceAptDatabase.GetPackagesByNames(ctx, names)
@@ -757,7 +1106,7 @@ func (a *Actions) MultiInfo(ctx context.Context, packageNames []string) (*MultiI
 	a.enrichWithAppStream(ctx, packages)
 
 	return &MultiInfoResponse{
-		Message:  fmt.Sprintf(app.T_("Found %d out of %d packages"), len(packages), len(names)),
+		Message:  fmt.Sprintf(app.TLN_(ctx, "Found %d out of %d package", "Found %d out of %d packages", len(names)), len(packages), len(names)),
 		Packages: packages,
 		NotFound: notFound,
 	}, nil
@@ -798,12 +1147,12 @@ func (a *Actions) List(ctx context.Context, params ListParams) (*ListResponse, e
 	}
 
 	if len(packages) == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeNotFound, errors.New(app.T_("Nothing found")))
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, errors.New(app.TL_(ctx, "Nothing found")))
 	}
 
 	a.enrichWithAppStream(ctx, packages)
 
-	msg := fmt.Sprintf(app.TN_("%d record found", "%d records found", len(packages)), len(packages))
+	msg := fmt.Sprintf(app.TLN_(ctx, "%d record found", "%d records found", len(packages)), len(packages))
 
 	return &ListResponse{
 		Message:    msg,
@@ -833,13 +1182,14 @@ func (a *Actions) Sections(ctx context.Context) (*SectionsResponse, error) {
 	}
 
 	return &SectionsResponse{
-		Message:  fmt.Sprintf(app.TN_("%d section found", "%d sections found", len(sections)), len(sections)),
+		Message:  fmt.Sprintf(app.TLN_(ctx, "%d section found", "%d sections found", len(sections)), len(sections)),
 		Sections: sections,
 	}, nil
 }
 
-// Search осуществляет поиск системного пакета по названию
-func (a *Actions) Search(ctx context.Context, packageName string, installed bool) (*SearchResponse, error) {
+// Search осуществляет поиск системного пакета по названию. С description ищет также по описанию
+// и ключевым словам AppStream и упорядочивает результаты по релевантности.
+func (a *Actions) Search(ctx context.Context, packageName string, installed bool, description bool) (*SearchResponse, error) {
 	err := a.validateDB(ctx, false)
 	if err != nil {
 		return nil, err
@@ -847,21 +1197,26 @@ func (a *Actions) Search(ctx context.Context, packageName string, installed bool
 
 	packageName = strings.TrimSpace(packageName)
 	if packageName == "" {
-		return nil, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.T_("You must specify the package name, for example `%s package`"), "search"))
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.TL_(ctx, "You must specify the package name, for example `%s package`"), "search"))
 	}
 
-	packages, err := a.serviceAptDatabase.SearchPackagesByNameLike(ctx, "%"+packageName+"%", installed)
+	var packages []_package.Package
+	if description {
+		packages, err = a.serviceAptDatabase.SearchPackagesFullText(ctx, packageName, installed)
+	} else {
+		packages, err = a.serviceAptDatabase.SearchPackagesByNameLike(ctx, "%"+packageName+"%", installed)
+	}
 	if err != nil {
 		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
 	}
 
 	if len(packages) == 0 {
-		return nil, apmerr.New(apmerr.ErrorTypeNotFound, errors.New(app.T_("Nothing found")))
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, errors.New(app.TL_(ctx, "Nothing found")))
 	}
 
 	a.enrichWithAppStream(ctx, packages)
 
-	msg := fmt.Sprintf(app.TN_("%d record found", "%d records found", len(packages)), len(packages))
+	msg := fmt.Sprintf(app.TLN_(ctx, "%d record found", "%d records found", len(packages)), len(packages))
 
 	return &SearchResponse{
 		Message:  msg,
@@ -876,23 +1231,52 @@ func (a *Actions) ImageStatus(ctx context.Context) (*ImageStatusResponse, error)
 		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
 	}
 
+	scheduled, err := a.serviceSchedule.Load()
+	if err != nil {
+		app.Log.Debugf("failed to read scheduled apply: %v", err)
+	}
+
 	return &ImageStatusResponse{
-		Message:     app.T_("Image status"),
+		Message:     app.TL_(ctx, "Image status"),
 		BootedImage: imageStatus,
+		Scheduled:   scheduled,
+		Pending:     pendingDeployment(ctx, imageStatus.Image),
 	}, nil
 }
 
+// pendingDeployment описывает развёртывание, которое будет загружено после перезагрузки
+func pendingDeployment(ctx context.Context, hostImage build.HostImage) string {
+	switch {
+	case hostImage.Status.RollbackQueued && hostImage.Status.Rollback != nil:
+		return fmt.Sprintf(app.TL_(ctx, "Rollback to %s is queued and will be applied after reboot"), hostImage.Status.Rollback.Image.Image.Image)
+	case hostImage.Status.Staged != nil:
+		return fmt.Sprintf(app.TL_(ctx, "Image %s is staged and will be applied after reboot"), hostImage.Status.Staged.Image.Image.Image)
+	}
+	return ""
+}
+
 // ImageUpdate обновляет образ.
 func (a *Actions) ImageUpdate(ctx context.Context, hostCache bool) (*ImageUpdateResponse, error) {
-	if err := a.serviceHostConfig.LoadConfig(); err != nil {
+	ctx, release, err := a.operationLock.Acquire(ctx, journal.ActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if err = a.serviceHostConfig.LoadConfig(); err != nil {
 		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
 	}
 
-	if err := a.serviceHostConfig.GetConfig().CheckImage(); err != nil {
+	if err = a.serviceHostConfig.GetConfig().CheckImage(); err != nil {
 		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
 	}
 
-	err := a.serviceHostImage.CheckAndUpdateBaseImage(ctx, true, hostCache, *a.serviceHostConfig.GetConfig())
+	err = a.serviceHostImage.CheckAndUpdateBaseImage(ctx, true, hostCache, *a.serviceHostConfig.GetConfig())
+	a.recordOperation(ctx, journal.Entry{
+		Module:  journal.ModuleImage,
+		Action:  journal.ActionUpdate,
+		Targets: []string{a.serviceHostConfig.GetConfig().Image},
+	}, err)
 	if err != nil {
 		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
 	}
@@ -903,18 +1287,28 @@ func (a *Actions) ImageUpdate(ctx context.Context, hostCache bool) (*ImageUpdate
 	}
 
 	return &ImageUpdateResponse{
-		Message:     app.T_("Command executed successfully"),
+		Message:     app.TL_(ctx, "Command executed successfully"),
 		BootedImage: imageStatus,
 	}, nil
 }
 
-// ImageApply применить изменения к хосту
-func (a *Actions) ImageApply(ctx context.Context, pullImage bool, hostCache bool, configPath, workdir string) (*ImageApplyResponse, error) {
-	err := a.checkOverlay(ctx)
+// ImageApply применить изменения к хосту. С tag переключает систему на ранее собранный вариант образа
+func (a *Actions) ImageApply(ctx context.Context, pullImage bool, hostCache bool, configPath, workdir, tag string) (*ImageApplyResponse, error) {
+	ctx, release, err := a.operationLock.Acquire(ctx, journal.ActionApply)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	err = a.checkOverlay(ctx)
 	if err != nil {
 		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
 	}
 
+	if tag != "" {
+		return a.imageApplyTagged(ctx, tag)
+	}
+
 	if err = a.serviceHostConfig.ApplyPathOverrides(configPath, workdir); err != nil {
 		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
 	}
@@ -935,6 +1329,7 @@ func (a *Actions) ImageApply(ctx context.Context, pullImage bool, hostCache bool
 		reply.StopSpinner(a.appConfig)
 		// Показываем диалог выбора пакетов
 		result, errDialog := dialog.NewPackageSelectionDialog(
+			ctx,
 			a.appConfig,
 			a.serviceTemporaryConfig.GetConfig().Packages.Install,
 			a.serviceTemporaryConfig.GetConfig().Packages.Remove,
@@ -944,7 +1339,7 @@ func (a *Actions) ImageApply(ctx context.Context, pullImage bool, hostCache bool
 		}
 
 		if result.Canceled {
-			return nil, apmerr.New(apmerr.ErrorTypeCanceled, errors.New(app.T_("Cancel dialog")))
+			return nil, apmerr.New(apmerr.ErrorTypeCanceled, errors.New(app.TL_(ctx, "Cancel dialog")))
 		}
 
 		reply.CreateSpinner(a.appConfig)
@@ -962,24 +1357,38 @@ func (a *Actions) ImageApply(ctx context.Context, pullImage bool, hostCache bool
 		}
 	}
 
-	if len(a.serviceHostConfig.GetConfig().Modules) > 0 {
+	buildImage := len(a.serviceHostConfig.GetConfig().Modules) > 0
+	if buildImage {
 		err = a.serviceHostConfig.GenerateDockerfile(hostCache)
 		if err != nil {
 			return nil, apmerr.New(apmerr.ErrorTypeImage, err)
 		}
+	}
+
+	hookTx := hooks.NewTransaction(ctx, hooks.EventImageApply, []string{a.serviceHostConfig.GetConfig().Image})
+	if err = a.beforeHooks(ctx, hookTx); err != nil {
+		return nil, err
+	}
 
+	if buildImage {
 		err = a.serviceHostImage.BuildAndSwitch(ctx, pullImage, true, a.serviceHostConfig)
-		if err != nil {
-			return nil, apmerr.New(apmerr.ErrorTypeImage, err)
-		}
 	} else {
 		err = a.serviceHostImage.SwitchImage(ctx, a.serviceHostConfig.GetConfig().Image, false)
-		if err != nil {
-			return nil, apmerr.New(apmerr.ErrorTypeImage, err)
-		}
+	}
+	errHooks := a.afterHooks(ctx, hookTx, err)
+	a.recordOperation(ctx, journal.Entry{
+		Module:  journal.ModuleImage,
+		Action:  journal.ActionApply,
+		Targets: []string{a.serviceHostConfig.GetConfig().Image},
+	}, err)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
 	}
 
 	_ = a.serviceTemporaryConfig.DeleteFile()
+	if errHooks != nil {
+		return nil, errHooks
+	}
 
 	imageStatus, err := a.getImageStatus(ctx)
 	if err != nil {
@@ -987,7 +1396,7 @@ func (a *Actions) ImageApply(ctx context.Context, pullImage bool, hostCache bool
 	}
 
 	return &ImageApplyResponse{
-		Message:     app.T_("Changes applied successfully. A reboot is required"),
+		Message:     app.TL_(ctx, "Changes applied successfully. A reboot is required"),
 		BootedImage: imageStatus,
 	}, nil
 }
@@ -1004,7 +1413,7 @@ func (a *Actions) ImageHistory(ctx context.Context, imageName string, limit int,
 		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
 	}
 
-	msg := fmt.Sprintf(app.TN_("%d record found", "%d records found", len(history)), len(history))
+	msg := fmt.Sprintf(app.TLN_(ctx, "%d record found", "%d records found", len(history)), len(history))
 
 	return &ImageHistoryResponse{
 		Message:    msg,
@@ -1013,6 +1422,38 @@ func (a *Actions) ImageHistory(ctx context.Context, imageName string, limit int,
 	}, nil
 }
 
+// ImageLogs возвращает журнал сборки образа указанного поколения, 0 - последней сборки
+func (a *Actions) ImageLogs(ctx context.Context, generation int) (*ImageLogsResponse, error) {
+	logs, err := a.serviceHostImage.BuildLogs()
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+	if len(logs) == 0 {
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, errors.New(app.TL_(ctx, "No build logs found")))
+	}
+
+	if generation == 0 {
+		generation = len(logs)
+	}
+	if generation < 1 || generation > len(logs) {
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound,
+			fmt.Errorf(app.TL_(ctx, "Build log for generation %d not found, available generations: 1-%d"), generation, len(logs)))
+	}
+
+	log := logs[generation-1]
+	content, err := a.serviceHostImage.ReadBuildLog(log.Path)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+
+	return &ImageLogsResponse{
+		Message:     fmt.Sprintf(app.TL_(ctx, "Build log of generation %d"), generation),
+		Log:         log,
+		Content:     content,
+		Generations: len(logs),
+	}, nil
+}
+
 // ImageLint линтер файлов и пакетной базы
 func (a *Actions) ImageLint(ctx context.Context, rootfs string, fix bool) (*ImageLintResponse, error) {
 	svc := lint.New(rootfs, a.reporter)
@@ -1071,11 +1512,50 @@ func (a *Actions) ImageSaveConfig(_ context.Context, config build.Config) (*Imag
 	}, nil
 }
 
+// imageApplyTagged переключает систему на вариант образа, собранный через image build --tag
+func (a *Actions) imageApplyTagged(ctx context.Context, tag string) (*ImageApplyResponse, error) {
+	imageID, err := a.serviceHostImage.TaggedImageID(ctx, tag)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, err)
+	}
+
+	hookTx := hooks.NewTransaction(ctx, hooks.EventImageApply, []string{tag})
+	if err = a.beforeHooks(ctx, hookTx); err != nil {
+		return nil, err
+	}
+
+	err = a.serviceHostImage.SwitchImage(ctx, imageID, true)
+	errHooks := a.afterHooks(ctx, hookTx, err)
+	a.recordOperation(ctx, journal.Entry{Module: journal.ModuleImage, Action: journal.ActionApply, Targets: []string{tag}}, err)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+	if errHooks != nil {
+		return nil, errHooks
+	}
+
+	imageStatus, err := a.getImageStatus(ctx)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+
+	return &ImageApplyResponse{
+		Message:     app.TL_(ctx, "Changes applied successfully. A reboot is required"),
+		BootedImage: imageStatus,
+	}, nil
+}
+
 // ImageFixNss исправляет /etc/passwd и /etc/group на живой атомарной системе
-func (a *Actions) ImageFixNss(_ context.Context) (*ImageFixNssResponse, error) {
+func (a *Actions) ImageFixNss(ctx context.Context) (*ImageFixNssResponse, error) {
 	if !a.appConfig.ConfigManager.GetConfig().IsAtomic {
-		return nil, apmerr.New(apmerr.ErrorTypeImage, errors.New(app.T_("This option is only available for an atomic system")))
+		return nil, apmerr.New(apmerr.ErrorTypeImage, errors.New(app.TL_(ctx, "This option is only available for an atomic system")))
+	}
+
+	ctx, release, err := a.operationLock.Acquire(ctx, journal.ActionSet)
+	if err != nil {
+		return nil, err
 	}
+	defer release()
 
 	svc := altfiles.NewDefault()
 	result, err := svc.ApplyFix()
@@ -1084,7 +1564,7 @@ func (a *Actions) ImageFixNss(_ context.Context) (*ImageFixNssResponse, error) {
 	}
 
 	return &ImageFixNssResponse{
-		Message:        app.T_("nss-altfiles configuration applied successfully"),
+		Message:        app.TL_(ctx, "nss-altfiles configuration applied successfully"),
 		EtcPasswdCount: result.EtcPasswdCount,
 		LibPasswdCount: result.LibPasswdCount,
 		EtcGroupCount:  result.EtcGroupCount,
@@ -1093,10 +1573,16 @@ func (a *Actions) ImageFixNss(_ context.Context) (*ImageFixNssResponse, error) {
 }
 
 // ImageSyncGroups синхронизирует группы пользователей из YAML-конфигов
-func (a *Actions) ImageSyncGroups(_ context.Context, configDirs []string) (*ImageSyncGroupsResponse, error) {
+func (a *Actions) ImageSyncGroups(ctx context.Context, configDirs []string) (*ImageSyncGroupsResponse, error) {
 	if !a.appConfig.ConfigManager.GetConfig().IsAtomic {
-		return nil, apmerr.New(apmerr.ErrorTypeImage, errors.New(app.T_("This option is only available for an atomic system")))
+		return nil, apmerr.New(apmerr.ErrorTypeImage, errors.New(app.TL_(ctx, "This option is only available for an atomic system")))
+	}
+
+	ctx, release, err := a.operationLock.Acquire(ctx, journal.ActionSet)
+	if err != nil {
+		return nil, err
 	}
+	defer release()
 
 	svc := altfiles.NewDefault()
 
@@ -1107,7 +1593,7 @@ func (a *Actions) ImageSyncGroups(_ context.Context, configDirs []string) (*Imag
 
 	if len(configs) == 0 {
 		return &ImageSyncGroupsResponse{
-			Message: app.T_("No configs found"),
+			Message: app.TL_(ctx, "No configs found"),
 		}, nil
 	}
 
@@ -1117,7 +1603,7 @@ func (a *Actions) ImageSyncGroups(_ context.Context, configDirs []string) (*Imag
 	}
 
 	return &ImageSyncGroupsResponse{
-		Message: app.T_("Groups synced successfully"),
+		Message: app.TL_(ctx, "Groups synced successfully"),
 		Added:   result.Added,
 		Fixed:   result.Fixed,
 		Skipped: result.Skipped,
@@ -1137,15 +1623,21 @@ func (a *Actions) checkOverlay(_ context.Context) error {
 }
 
 // saveChange применяет изменения к образу системы
-func (a *Actions) saveChange(_ context.Context, packagesInstall []string, packagesRemove []string) error {
+func (a *Actions) saveChange(ctx context.Context, packagesInstall []string, packagesRemove []string) error {
 	if !a.appConfig.ConfigManager.GetConfig().IsAtomic {
-		return apmerr.New(apmerr.ErrorTypeImage, errors.New(app.T_("This option is only available for an atomic system")))
+		return apmerr.New(apmerr.ErrorTypeImage, errors.New(app.TL_(ctx, "This option is only available for an atomic system")))
 	}
 
 	if err := a.serviceTemporaryConfig.LoadConfig(); err != nil {
 		return err
 	}
 
+	// Локальные файлы сохраняются в ресурсах образа, иначе сборка их не найдёт
+	packagesInstall, err := a.storeLocalPackages(ctx, packagesInstall)
+	if err != nil {
+		return err
+	}
+
 	processPackages := func(packages []string, addFunc func(string) error) error {
 		for _, pkg := range packages {
 			if pkg = strings.TrimSpace(pkg); pkg != "" {
@@ -1172,7 +1664,7 @@ func (a *Actions) saveChange(_ context.Context, packagesInstall []string, packag
 func (a *Actions) validateDB(ctx context.Context, noLock bool) error {
 	if err := a.serviceAptDatabase.PackageDatabaseExist(ctx); err != nil {
 		if syscall.Geteuid() != 0 {
-			return apmerr.New(apmerr.ErrorTypePermission, errors.New(app.T_("package database is empty. Run 'apm system update' with elevated rights to create it")))
+			return apmerr.New(apmerr.ErrorTypePermission, errors.New(app.TL_(ctx, "package database is empty. Run 'apm system update' with elevated rights to create it")))
 		}
 
 		_, err = a.serviceAptActions.Update(ctx, noLock)
@@ -1230,7 +1722,7 @@ func (a *Actions) enrichWithAppStream(ctx context.Context, packages []_package.P
 	}
 }
 
-func (a *Actions) getImageStatus(_ context.Context) (ImageStatus, error) {
+func (a *Actions) getImageStatus(ctx context.Context) (ImageStatus, error) {
 	hostImage, err := a.serviceHostImage.GetHostImage()
 	if err != nil {
 		return ImageStatus{}, err
@@ -1243,14 +1735,14 @@ func (a *Actions) getImageStatus(_ context.Context) (ImageStatus, error) {
 
 	if hostImage.Status.Booted.Image.Image.Transport == "containers-storage" {
 		return ImageStatus{
-			Status: app.T_("Modified image. Configuration file: ") + a.appConfig.ConfigManager.GetConfig().PathImageFile,
+			Status: app.TL_(ctx, "Modified image. Configuration file: ") + a.appConfig.ConfigManager.GetConfig().PathImageFile,
 			Image:  hostImage,
 			Config: *a.serviceHostConfig.GetConfig(),
 		}, nil
 	}
 
 	return ImageStatus{
-		Status: app.T_("Cloud image without changes"),
+		Status: app.TL_(ctx, "Cloud image without changes"),
 		Image:  hostImage,
 		Config: *a.serviceHostConfig.GetConfig(),
 	}, nil
@@ -1265,6 +1757,29 @@ type ShortPackageResponse struct {
 	Maintainer string `json:"maintainer"`
 }
 
+// UpgradablePackageResponse краткая информация о пакете, для которого доступна новая версия
+type UpgradablePackageResponse struct {
+	Name             string `json:"name"`
+	VersionInstalled string `json:"versionInstalled"`
+	Version          string `json:"version"`
+	Size             string `json:"size"`
+}
+
+// FormatUpgradableOutput возвращает установленную и новую версии пакетов с релизом и размером загрузки,
+// чтобы были видны и обновления, меняющие только релиз.
+func (a *Actions) FormatUpgradableOutput(packages []_package.Package) []UpgradablePackageResponse {
+	result := make([]UpgradablePackageResponse, 0, len(packages))
+	for _, pkg := range packages {
+		result = append(result, UpgradablePackageResponse{
+			Name:             pkg.Name,
+			VersionInstalled: pkg.VersionInstalledRaw,
+			Version:          pkg.VersionRaw,
+			Size:             helper.FormatBytes(uint64(pkg.Size)),
+		})
+	}
+	return result
+}
+
 // FormatPackageOutput принимает данные (один пакет или срез пакетов) и флаг full.
 // Если full == true, то возвращается полный вывод, иначе – сокращённый.
 func (a *Actions) FormatPackageOutput(data interface{}, full bool) interface{} {
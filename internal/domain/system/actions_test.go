@@ -2,17 +2,47 @@ package system
 
 import (
 	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	aptCommon "apm/internal/common/apt"
 	_package "apm/internal/common/apt/package"
+	aptBinding "apm/internal/common/binding/apt"
 	aptLib "apm/internal/common/binding/apt/lib"
 	"apm/internal/common/build"
 	"apm/internal/common/filter"
+	"apm/internal/common/journal"
+	"apm/internal/common/network"
+	"apm/internal/common/oplock"
+	"apm/internal/common/oplog"
+	"apm/internal/common/reply"
+	"apm/internal/common/sandbox"
 	"apm/internal/common/swcat"
 	"apm/internal/common/testutil"
+	"apm/internal/common/version"
+	kservice "apm/internal/domain/kernel/service"
+	reposervice "apm/internal/domain/repository/service"
+	"apm/internal/domain/system/autoupgrade"
+	"apm/internal/domain/system/group"
+	"apm/internal/domain/system/localrpm"
+	"apm/internal/domain/system/restart"
+	"apm/internal/domain/system/rpmdup"
+	"apm/internal/domain/system/rpmnew"
+	"apm/internal/domain/system/schedule"
+	"apm/internal/domain/system/stplr"
 	"apm/internal/domain/system/temporary"
+	"apm/internal/domain/system/units"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"syscall"
 	"testing"
+	"time"
 )
 
 type mockAptActions struct {
@@ -21,12 +51,29 @@ type mockAptActions struct {
 	checkRemoveErr  error
 	checkUpgradeRes *aptLib.PackageChanges
 	checkUpgradeErr error
+	autoRemoveRes   *aptLib.PackageChanges
+	prepareArgs     []string
 	prepareInstall  []string
 	prepareRemove   []string
 	prepareErr      error
 	findChanges     *aptLib.PackageChanges
+	findByPackage   map[string]*aptLib.PackageChanges
 	findErr         error
 	updateErr       error
+	updatePackages  []_package.Package
+	combineCalled   bool
+	combineConfig   map[string]string
+	aptUpdated      int
+	infoErr         error
+	installedFiles  []aptBinding.RpmFileInfo
+	isInstalled     bool
+	fixBrokenRes    *aptLib.PackageChanges
+	fixBrokenCalled bool
+	installed       map[string]string
+	holdSynced      int
+	owners          []aptBinding.InstalledPackageInfo
+	info            *aptLib.PackageInfo
+	archivesDir     string
 }
 
 func (m *mockAptActions) SetAptConfigOverrides(o map[string]string) { m.overrides = o }
@@ -37,29 +84,74 @@ func (m *mockAptActions) CheckRemove(_ context.Context, _ []string, _ bool, _ bo
 func (m *mockAptActions) CheckUpgrade(_ context.Context) (*aptLib.PackageChanges, error) {
 	return m.checkUpgradeRes, m.checkUpgradeErr
 }
-func (m *mockAptActions) PrepareInstallPackages(_ context.Context, _ []string) ([]string, []string, error) {
+func (m *mockAptActions) CheckAutoRemove(_ context.Context) (*aptLib.PackageChanges, error) {
+	if m.autoRemoveRes == nil {
+		return &aptLib.PackageChanges{}, nil
+	}
+	return m.autoRemoveRes, nil
+}
+func (m *mockAptActions) PrepareInstallPackages(_ context.Context, packages []string) ([]string, []string, error) {
+	m.prepareArgs = packages
 	return m.prepareInstall, m.prepareRemove, m.prepareErr
 }
-func (m *mockAptActions) FindPackage(_ context.Context, _ []string, _ []string, _ bool, _ bool, _ bool) ([]string, []string, []_package.Package, *aptLib.PackageChanges, error) {
+func (m *mockAptActions) FindPackage(_ context.Context, installed []string, _ []string, _ bool, _ bool, _ bool) ([]string, []string, []_package.Package, *aptLib.PackageChanges, error) {
+	if changes, ok := m.findByPackage[strings.Join(installed, " ")]; ok {
+		return installed, nil, nil, changes, nil
+	}
 	return nil, nil, nil, m.findChanges, m.findErr
 }
 func (m *mockAptActions) Remove(_ context.Context, _ []string, _ bool, _ bool) error { return nil }
 func (m *mockAptActions) CombineInstallRemovePackages(_ context.Context, _ []string, _ []string, _ bool, _ bool, _ bool) error {
+	m.combineCalled = true
+	m.combineConfig = m.overrides
 	return nil
 }
 func (m *mockAptActions) Update(_ context.Context, _ ...bool) ([]_package.Package, error) {
-	return nil, m.updateErr
+	return m.updatePackages, m.updateErr
 }
 func (m *mockAptActions) UpdateDBOnly(_ context.Context, _ ...bool) ([]_package.Package, error) {
 	return nil, nil
 }
-func (m *mockAptActions) AptUpdate(_ context.Context, _ ...bool) error { return nil }
+func (m *mockAptActions) AptUpdate(_ context.Context, _ ...bool) error {
+	m.aptUpdated++
+	return nil
+}
 func (m *mockAptActions) GetInstalledPackages(_ context.Context, _ ...bool) (map[string]string, error) {
-	return nil, nil
+	return m.installed, nil
+}
+func (m *mockAptActions) GetInstalledEVRs(_ context.Context, _ ...bool) (map[string]string, error) {
+	return m.installed, nil
 }
 func (m *mockAptActions) Upgrade(_ context.Context, _ bool) error               { return nil }
 func (m *mockAptActions) ReinstallPackages(_ context.Context, _ []string) error { return nil }
 func (m *mockAptActions) Install(_ context.Context, _ []string, _ bool) error   { return nil }
+func (m *mockAptActions) GetInstalledFiles(_ context.Context, _ string) ([]aptBinding.RpmFileInfo, bool, error) {
+	return m.installedFiles, m.isInstalled, nil
+}
+func (m *mockAptActions) CheckFixBroken(_ context.Context) (*aptLib.PackageChanges, error) {
+	return m.fixBrokenRes, nil
+}
+func (m *mockAptActions) SyncHoldPreferences(_ context.Context) error {
+	m.holdSynced++
+	return nil
+}
+func (m *mockAptActions) GetFileOwners(_ context.Context, _ string) ([]aptBinding.InstalledPackageInfo, error) {
+	return m.owners, nil
+}
+func (m *mockAptActions) GetInfo(_ context.Context, _ string) (*aptLib.PackageInfo, error) {
+	if m.infoErr != nil {
+		return nil, m.infoErr
+	}
+	if m.info == nil {
+		return &aptLib.PackageInfo{}, nil
+	}
+	return m.info, nil
+}
+func (m *mockAptActions) ArchivesDir() string { return m.archivesDir }
+func (m *mockAptActions) FixBroken(_ context.Context) error {
+	m.fixBrokenCalled = true
+	return nil
+}
 
 type mockAptDB struct {
 	dbExistErr       error
@@ -73,8 +165,15 @@ type mockAptDB struct {
 	countErr         error
 	searchResult     []_package.Package
 	searchErr        error
+	fullTextResult   []_package.Package
 	sectionsResult   []string
 	sectionsErr      error
+	universe         []_package.Package
+	held             []string
+	providing        []_package.Package
+	orphanedHeld     int64
+	heldInstalled    map[string]string
+	stplrSaved       []_package.Package
 }
 
 func (m *mockAptDB) PackageDatabaseExist(_ context.Context) error { return m.dbExistErr }
@@ -84,6 +183,33 @@ func (m *mockAptDB) GetPackageByName(_ context.Context, _ string) (_package.Pack
 func (m *mockAptDB) GetPackagesByNames(_ context.Context, _ []string) ([]_package.Package, error) {
 	return m.getByNamesResult, m.getByNamesErr
 }
+func (m *mockAptDB) GetReverseDependencies(_ context.Context, capabilities []string, installed bool) ([]_package.Package, error) {
+	var result []_package.Package
+	for _, pkg := range m.universe {
+		if installed && !pkg.Installed {
+			continue
+		}
+		for _, dep := range pkg.Depends {
+			if slices.Contains(capabilities, dep) {
+				result = append(result, pkg)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+func (m *mockAptDB) GetProviders(_ context.Context, capabilities []string) ([]_package.Package, error) {
+	var result []_package.Package
+	for _, pkg := range m.universe {
+		for _, provide := range pkg.Provides {
+			if slices.Contains(capabilities, provide) {
+				result = append(result, pkg)
+				break
+			}
+		}
+	}
+	return result, nil
+}
 func (m *mockAptDB) QueryHostImagePackages(_ context.Context, _ []filter.Filter, _ string, _ string, _ int, _ int) ([]_package.Package, error) {
 	return m.queryResult, m.queryErr
 }
@@ -93,16 +219,43 @@ func (m *mockAptDB) CountHostImagePackages(_ context.Context, _ []filter.Filter)
 func (m *mockAptDB) SearchPackagesByNameLike(_ context.Context, _ string, _ bool) ([]_package.Package, error) {
 	return m.searchResult, m.searchErr
 }
+func (m *mockAptDB) SearchPackagesFullText(_ context.Context, _ string, _ bool) ([]_package.Package, error) {
+	return m.fullTextResult, m.searchErr
+}
 func (m *mockAptDB) SearchPackagesMultiLimit(_ context.Context, _ string, _ int, _ bool) ([]_package.Package, error) {
 	return m.searchResult, m.searchErr
 }
 func (m *mockAptDB) SyncPackageInstallationInfo(_ context.Context, _ map[string]string) error {
 	return nil
 }
+func (m *mockAptDB) SaveStplrPackages(_ context.Context, packages []_package.Package) error {
+	m.stplrSaved = packages
+	return nil
+}
 func (m *mockAptDB) UpdateAppStreamLinks(_ context.Context) error { return nil }
 func (m *mockAptDB) GetSections(_ context.Context) ([]string, error) {
 	return m.sectionsResult, m.sectionsErr
 }
+func (m *mockAptDB) HoldPackages(_ context.Context, names []string) error {
+	m.held = append(m.held, names...)
+	slices.Sort(m.held)
+	return nil
+}
+func (m *mockAptDB) UnholdPackages(_ context.Context, names []string) error {
+	m.held = slices.DeleteFunc(m.held, func(name string) bool { return slices.Contains(names, name) })
+	return nil
+}
+func (m *mockAptDB) GetHeldPackages(_ context.Context) ([]string, error) {
+	return m.held, nil
+}
+func (m *mockAptDB) FindPackagesProviding(_ context.Context, _ string) ([]_package.Package, error) {
+	return m.providing, nil
+}
+
+func (m *mockAptDB) DeleteOrphanedHeld(_ context.Context, installed map[string]string) (int64, error) {
+	m.heldInstalled = installed
+	return m.orphanedHeld, nil
+}
 
 type mockHostDB struct {
 	historyResult []build.ImageHistory
@@ -114,15 +267,34 @@ type mockHostDB struct {
 func (m *mockHostDB) GetImageHistoriesFiltered(_ context.Context, _ string, _ int, _ int) ([]build.ImageHistory, error) {
 	return m.historyResult, m.historyErr
 }
+func (m *mockHostDB) GetImageHistoryByID(_ context.Context, id uint) (build.ImageHistory, error) {
+	for _, h := range m.historyResult {
+		if h.ID == id {
+			return h, nil
+		}
+	}
+	return build.ImageHistory{}, errors.New("not found")
+}
 func (m *mockHostDB) CountImageHistoriesFiltered(_ context.Context, _ string) (int, error) {
 	return m.countResult, m.countErr
 }
 
-type mockHostImage struct{}
+type mockHostImage struct {
+	buildLogs   []build.BuildLog
+	buildLogErr error
+	readErr     error
+	hostImage   build.HostImage
+	rolledBack  bool
+}
 
 func (m *mockHostImage) EnableOverlay() error { return nil }
 func (m *mockHostImage) GetHostImage() (build.HostImage, error) {
-	return build.HostImage{}, nil
+	return m.hostImage, nil
+}
+func (m *mockHostImage) RollbackImage(_ context.Context) error {
+	m.rolledBack = true
+	m.hostImage.Status.RollbackQueued = true
+	return nil
 }
 func (m *mockHostImage) CheckAndUpdateBaseImage(_ context.Context, _ bool, _ bool, _ build.Config) error {
 	return nil
@@ -131,6 +303,17 @@ func (m *mockHostImage) SwitchImage(_ context.Context, _ string, _ bool) error {
 func (m *mockHostImage) BuildAndSwitch(_ context.Context, _ bool, _ bool, _ build.SwitchableConfig) error {
 	return nil
 }
+func (m *mockHostImage) BuildTaggedImage(_ context.Context, _ build.Config, _ string, _ bool, _ bool) (string, error) {
+	return "", nil
+}
+func (m *mockHostImage) TaggedImageID(_ context.Context, _ string) (string, error) { return "", nil }
+func (m *mockHostImage) BuildLogs() ([]build.BuildLog, error)                      { return m.buildLogs, m.buildLogErr }
+func (m *mockHostImage) ReadBuildLog(path string) (string, error) {
+	if m.readErr != nil {
+		return "", m.readErr
+	}
+	return "log " + path, nil
+}
 
 type mockHostConfig struct {
 	config  *build.Config
@@ -170,6 +353,352 @@ func (m *mockAppStream) GetByPkgNames(_ context.Context, _ []string) (map[string
 	return m.result, m.err
 }
 
+type mockJournal struct {
+	entries     []journal.Entry
+	interrupted []journal.Entry
+	last        *journal.Entry
+	begun       int
+	completed   int
+	failed      int
+	err         error
+}
+
+func (m *mockJournal) Record(_ context.Context, entry journal.Entry) error {
+	entry.Status = journal.StatusDone
+	m.entries = append([]journal.Entry{entry}, m.entries...)
+	return m.err
+}
+
+func (m *mockJournal) RecordResult(ctx context.Context, entry journal.Entry, opErr error) error {
+	if opErr == nil {
+		return m.Record(ctx, entry)
+	}
+	entry.Status = journal.StatusFailed
+	entry.Error = opErr.Error()
+	m.entries = append([]journal.Entry{entry}, m.entries...)
+	return m.err
+}
+
+func (m *mockJournal) Since(_ context.Context, _ time.Time) ([]journal.Entry, error) {
+	return m.entries, m.err
+}
+
+func (m *mockJournal) Begin(_ context.Context, _ journal.Entry) (uint, error) {
+	m.begun++
+	return uint(m.begun), m.err
+}
+
+func (m *mockJournal) Complete(_ context.Context, _ uint, _ journal.Entry) error {
+	m.completed++
+	return m.err
+}
+
+func (m *mockJournal) Fail(_ context.Context, _ uint) error {
+	m.failed++
+	return m.err
+}
+
+func (m *mockJournal) Interrupted(_ context.Context) ([]journal.Entry, error) {
+	return m.interrupted, m.err
+}
+
+func (m *mockJournal) MarkRepaired(_ context.Context) error {
+	m.interrupted = nil
+	return m.err
+}
+
+func (m *mockJournal) Last(_ context.Context, _ string) (*journal.Entry, error) {
+	return m.last, m.err
+}
+
+func (m *mockJournal) History(_ context.Context, filter journal.HistoryFilter) ([]journal.Entry, int, error) {
+	var result []journal.Entry
+	for _, e := range m.entries {
+		if filter.Module == "" || e.Module == filter.Module {
+			result = append(result, e)
+		}
+	}
+	return result, len(result), m.err
+}
+
+func (m *mockJournal) Get(_ context.Context, id uint) (*journal.Entry, error) {
+	for _, e := range m.entries {
+		if e.ID == id {
+			return &e, m.err
+		}
+	}
+	return nil, m.err
+}
+
+func (m *mockJournal) FindByTransaction(_ context.Context, transaction string) (*journal.Entry, error) {
+	for _, e := range m.entries {
+		if e.Transaction == transaction {
+			return &e, m.err
+		}
+	}
+	return nil, m.err
+}
+
+type mockRestart struct {
+	services  []restart.Service
+	failUnit  string
+	restarted []string
+	selfUnit  string
+	selfArgv  []string
+}
+
+func (m *mockRestart) Find(_ context.Context) ([]restart.Service, error) {
+	return m.services, nil
+}
+
+func (m *mockRestart) Restart(_ context.Context, unit string) error {
+	if unit == m.failUnit {
+		return errors.New("job failed")
+	}
+	m.restarted = append(m.restarted, unit)
+	return nil
+}
+
+func (m *mockRestart) RestartSelf(_ context.Context, unit string, argv []string) []string {
+	m.selfUnit = unit
+	m.selfArgv = argv
+	return []string{unit}
+}
+
+type mockKernelInfo struct {
+	current *kservice.Info
+	latest  *kservice.Info
+	err     error
+}
+
+func (m *mockKernelInfo) GetCurrentKernel(_ context.Context) (*kservice.Info, error) {
+	return m.current, m.err
+}
+
+func (m *mockKernelInfo) FindLatestKernel(_ context.Context, _ string) (*kservice.Info, error) {
+	if m.latest == nil {
+		return nil, errors.New("no kernels found")
+	}
+	return m.latest, nil
+}
+
+type mockRepoList struct {
+	repos       []reposervice.Repository
+	err         error
+	sandboxArgs []string
+	sandbox     *reposervice.Sandbox
+	sandboxErr  error
+}
+
+func (m *mockRepoList) GetRepositories(_ context.Context, _ bool) ([]reposervice.Repository, error) {
+	return m.repos, m.err
+}
+
+func (m *mockRepoList) CreateSandbox(_ context.Context, args []string) (*reposervice.Sandbox, error) {
+	m.sandboxArgs = args
+	return m.sandbox, m.sandboxErr
+}
+
+type mockContainerList struct {
+	containers []sandbox.ContainerInfo
+	err        error
+}
+
+func (m *mockContainerList) GetContainerList(_ context.Context, _ bool) ([]sandbox.ContainerInfo, error) {
+	return m.containers, m.err
+}
+
+type mockUnits struct {
+	enabled map[string]bool
+	active  map[string]bool
+}
+
+func (m *mockUnits) Install(_ context.Context, name string) (units.Status, error) {
+	return units.Status{Name: name, Installed: true}, nil
+}
+
+func (m *mockUnits) Enable(_ context.Context, name string) (units.Status, error) {
+	if m.enabled == nil {
+		m.enabled = map[string]bool{}
+	}
+	m.enabled[name] = true
+	return units.Status{Name: name, Installed: true, Enabled: true}, nil
+}
+
+func (m *mockUnits) Disable(_ context.Context, name string) (bool, error) {
+	disabled := m.enabled[name]
+	delete(m.enabled, name)
+	return disabled, nil
+}
+
+func (m *mockUnits) Status(_ context.Context, name string) (units.Status, error) {
+	return units.Status{Name: name, Unit: name, Installed: m.enabled[name], Enabled: m.enabled[name], Active: m.active[name]}, nil
+}
+
+type mockStplr struct {
+	catalogue []stplr.Package
+	buildDir  string
+	built     []string
+}
+
+func (m *mockStplr) Refresh(_ context.Context) error { return nil }
+func (m *mockStplr) List(_ context.Context) ([]stplr.Package, error) {
+	return m.catalogue, nil
+}
+func (m *mockStplr) Build(_ context.Context, name string) (string, []string, error) {
+	m.built = append(m.built, name)
+	dir, err := os.MkdirTemp(m.buildDir, "stplr-")
+	if err != nil {
+		return "", nil, err
+	}
+	file := filepath.Join(dir, name+"-1.0-1.x86_64.rpm")
+	return dir, []string{file}, os.WriteFile(file, []byte("rpm"), 0o644)
+}
+
+type mockRpmDup struct {
+	duplicates []rpmdup.Duplicate
+	finished   []string
+}
+
+func (m *mockRpmDup) Find(_ context.Context) ([]rpmdup.Duplicate, error) {
+	return m.duplicates, nil
+}
+
+func (m *mockRpmDup) Finish(_ context.Context, d rpmdup.Duplicate) error {
+	m.finished = append(m.finished, d.Name)
+	return nil
+}
+
+type mockLocalRpm struct {
+	verifyErr error
+	verified  []string
+	stored    []string
+	storePath string
+}
+
+func (m *mockLocalRpm) Verify(_ context.Context, files []string) error {
+	m.verified = append(m.verified, files...)
+	return m.verifyErr
+}
+
+func (m *mockLocalRpm) Names(_ context.Context, files []string) (map[string]string, error) {
+	names := make(map[string]string, len(files))
+	for _, f := range files {
+		names[strings.TrimSuffix(filepath.Base(f), ".rpm")] = f
+	}
+	return names, nil
+}
+
+func (m *mockLocalRpm) Store(_ context.Context, file string) (string, error) {
+	m.stored = append(m.stored, file)
+	return filepath.Join("rpms", filepath.Base(file)), nil
+}
+
+func (m *mockLocalRpm) WriteManifest(_ context.Context, _ string, requested []string) (localrpm.Manifest, error) {
+	return localrpm.Manifest{Requested: requested}, nil
+}
+
+func (m *mockLocalRpm) StorePath() string { return m.storePath }
+
+type mockLogReader struct {
+	entries []oplog.Entry
+	filter  oplog.Filter
+}
+
+func (m *mockLogReader) Read(_ context.Context, filter oplog.Filter) ([]oplog.Entry, error) {
+	m.filter = filter
+	return m.entries, nil
+}
+
+func (m *mockLogReader) Follow(_ context.Context, filter oplog.Filter, fn func(oplog.Entry)) error {
+	m.filter = filter
+	for _, e := range m.entries {
+		fn(e)
+	}
+	return nil
+}
+
+type mockSchedule struct {
+	current  *schedule.Schedule
+	idle     bool
+	created  *schedule.Schedule
+	finished bool
+	notified []string
+}
+
+func (m *mockSchedule) Create(_ context.Context, s schedule.Schedule) error {
+	m.created = &s
+	return nil
+}
+
+func (m *mockSchedule) Load() (*schedule.Schedule, error) {
+	return m.current, nil
+}
+
+func (m *mockSchedule) Cancel(_ context.Context) (*schedule.Schedule, error) {
+	s := m.current
+	m.current = nil
+	return s, nil
+}
+
+func (m *mockSchedule) Finish(_ context.Context) error {
+	m.finished = true
+	return nil
+}
+
+func (m *mockSchedule) IsIdle(_ context.Context) bool {
+	return m.idle
+}
+
+func (m *mockSchedule) Notify(_ context.Context, message string) {
+	m.notified = append(m.notified, message)
+}
+
+type mockAutoUpgrade struct {
+	status   autoupgrade.Status
+	enabled  string
+	notified int
+}
+
+func (m *mockAutoUpgrade) Enable(_ context.Context, onCalendar string) error {
+	m.enabled = onCalendar
+	m.status = autoupgrade.Status{Enabled: true, OnCalendar: onCalendar}
+	return nil
+}
+
+func (m *mockAutoUpgrade) Disable(_ context.Context) (bool, error) {
+	disabled := m.status.Enabled
+	m.status = autoupgrade.Status{}
+	return disabled, nil
+}
+
+func (m *mockAutoUpgrade) Status(_ context.Context) (autoupgrade.Status, error) {
+	return m.status, nil
+}
+
+func (m *mockAutoUpgrade) NotifyUsers(_ context.Context, _ string, _ string) int {
+	m.notified++
+	return 1
+}
+
+type mockRpmnew struct {
+	files   []rpmnew.File
+	applied map[string]string
+}
+
+func (m *mockRpmnew) Snapshot() map[string]struct{} { return map[string]struct{}{} }
+func (m *mockRpmnew) Collect(_ map[string]struct{}) []rpmnew.File {
+	return m.files
+}
+func (m *mockRpmnew) Diff(_ context.Context, _ rpmnew.File) (string, error) { return "", nil }
+func (m *mockRpmnew) Apply(_ context.Context, file rpmnew.File, action string) error {
+	if m.applied == nil {
+		m.applied = make(map[string]string)
+	}
+	m.applied[file.Path] = action
+	return nil
+}
+
 func newTestActions(aptAct *mockAptActions, aptDB *mockAptDB, hostDB *mockHostDB) *Actions {
 	if aptAct == nil {
 		aptAct = &mockAptActions{}
@@ -189,6 +718,16 @@ func newTestActions(aptAct *mockAptActions, aptDB *mockAptDB, hostDB *mockHostDB
 		serviceHostConfig:      &mockHostConfig{},
 		serviceTemporaryConfig: &mockTempConfig{},
 		serviceAppStreamDB:     &mockAppStream{},
+		serviceSchedule:        &mockSchedule{},
+		serviceAutoUpgrade:     &mockAutoUpgrade{},
+		serviceRpmnew:          &mockRpmnew{},
+		serviceRpmDup:          &mockRpmDup{},
+		serviceLocalRpm:        &mockLocalRpm{},
+		serviceRestart:         &mockRestart{},
+		serviceKernel:          &mockKernelInfo{},
+		serviceRepos:           &mockRepoList{},
+		serviceContainers:      &mockContainerList{},
+		serviceUnits:           &mockUnits{},
 	}
 }
 
@@ -363,6 +902,7 @@ func TestSearch(t *testing.T) {
 	tests := []struct {
 		name        string
 		query       string
+		description bool
 		db          *mockAptDB
 		wantErr     bool
 		wantErrType string
@@ -374,6 +914,13 @@ func TestSearch(t *testing.T) {
 			db:        &mockAptDB{searchResult: pkgs},
 			wantCount: 2,
 		},
+		{
+			name:        "description search uses the full-text index",
+			query:       "text editor",
+			description: true,
+			db:          &mockAptDB{searchResult: pkgs, fullTextResult: pkgs[:1]},
+			wantCount:   1,
+		},
 		{
 			name:        "nothing found returns not found",
 			query:       "zzzzz",
@@ -400,7 +947,7 @@ func TestSearch(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			actions := newTestActions(nil, tt.db, nil)
-			resp, err := actions.Search(context.Background(), tt.query, false)
+			resp, err := actions.Search(context.Background(), tt.query, false, tt.description)
 
 			if tt.wantErr {
 				testutil.AssertAPMError(t, err, tt.wantErrType)
@@ -546,359 +1093,2766 @@ func TestCheckInstall(t *testing.T) {
 	})
 }
 
-func TestCheckRemove(t *testing.T) {
-	t.Run("success shows removal candidates with dependencies", func(t *testing.T) {
-		changes := &aptLib.PackageChanges{
-			RemovedCount:    2,
-			RemovedPackages: []string{"vim", "vim-common"},
+func TestCheckInstallLocalPackages(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"foo.rpm", "bar.rpm"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("rpm"), 0644); err != nil {
+			t.Fatal(err)
 		}
-		apt := &mockAptActions{checkRemoveRes: changes}
+	}
+	changes := &aptLib.PackageChanges{NewInstalledCount: 2, NewInstalledPackages: []string{"bar", "foo"}}
+
+	t.Run("directory is expanded and verified", func(t *testing.T) {
+		apt := &mockAptActions{findChanges: changes}
 		actions := newTestActions(apt, &mockAptDB{}, nil)
+		local := &mockLocalRpm{}
+		actions.serviceLocalRpm = local
 
-		resp, err := actions.CheckRemove(context.Background(), []string{"vim"}, false, false)
-		if err != nil {
+		if _, err := actions.CheckInstall(context.Background(), []string{"vim", dir + "/"}); err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
-		if resp.Info.RemovedCount != 2 {
-			t.Errorf("expected 2 removed, got %d", resp.Info.RemovedCount)
+		files := []string{filepath.Join(dir, "bar.rpm"), filepath.Join(dir, "foo.rpm")}
+		if want := append([]string{"vim"}, files...); !slices.Equal(apt.prepareArgs, want) {
+			t.Errorf("prepared %v, want %v", apt.prepareArgs, want)
+		}
+		if !slices.Equal(local.verified, files) {
+			t.Errorf("verified %v, want %v", local.verified, files)
 		}
 	})
 
-	t.Run("apt error propagates", func(t *testing.T) {
-		apt := &mockAptActions{checkRemoveErr: errors.New("cannot remove essential")}
-		actions := newTestActions(apt, &mockAptDB{}, nil)
+	t.Run("invalid signature returns validation error", func(t *testing.T) {
+		actions := newTestActions(&mockAptActions{findChanges: changes}, &mockAptDB{}, nil)
+		actions.serviceLocalRpm = &mockLocalRpm{verifyErr: errors.New("not signed")}
 
-		_, err := actions.CheckRemove(context.Background(), []string{"glibc"}, false, false)
-		testutil.AssertAPMError(t, err, apmerr.ErrorTypeApt)
+		_, err := actions.CheckInstall(context.Background(), []string{filepath.Join(dir, "*.rpm")})
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
 	})
-}
 
-func TestCheckUpgrade(t *testing.T) {
-	t.Run("success shows available upgrades", func(t *testing.T) {
-		changes := &aptLib.PackageChanges{UpgradedCount: 15}
-		apt := &mockAptActions{checkUpgradeRes: changes}
-		actions := newTestActions(apt, &mockAptDB{}, nil)
+	t.Run("allow unsigned skips verification", func(t *testing.T) {
+		actions := newTestActions(&mockAptActions{findChanges: changes}, &mockAptDB{}, nil)
+		local := &mockLocalRpm{verifyErr: errors.New("not signed")}
+		actions.serviceLocalRpm = local
+		actions.SetAllowUnsigned(true)
 
-		resp, err := actions.CheckUpgrade(context.Background())
-		if err != nil {
+		if _, err := actions.CheckInstall(context.Background(), []string{dir}); err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
-		if resp.Info.UpgradedCount != 15 {
-			t.Errorf("expected 15 upgrades, got %d", resp.Info.UpgradedCount)
+		if len(local.verified) != 0 {
+			t.Errorf("verification must be skipped, got %v", local.verified)
 		}
 	})
 
-	t.Run("apt error propagates", func(t *testing.T) {
-		apt := &mockAptActions{checkUpgradeErr: errors.New("repo unreachable")}
-		actions := newTestActions(apt, &mockAptDB{}, nil)
-
-		_, err := actions.CheckUpgrade(context.Background())
-		testutil.AssertAPMError(t, err, apmerr.ErrorTypeApt)
-	})
-}
-
-func TestCheckReinstall(t *testing.T) {
-	t.Run("empty packages returns validation error", func(t *testing.T) {
+	t.Run("empty directory returns validation error", func(t *testing.T) {
 		actions := newTestActions(nil, &mockAptDB{}, nil)
-		_, err := actions.CheckReinstall(context.Background(), []string{})
+		_, err := actions.CheckInstall(context.Background(), []string{t.TempDir()})
 		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
 	})
+}
 
-	t.Run("success returns reinstall changes", func(t *testing.T) {
-		changes := &aptLib.PackageChanges{NewInstalledCount: 1}
-		apt := &mockAptActions{
-			prepareInstall: []string{"bash"},
-			findChanges:    changes,
-		}
+func TestLocalPackagesMarkedAndStored(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "foo.rpm")
+	if err := os.WriteFile(file, []byte("rpm"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	actions := newTestActions(nil, &mockAptDB{}, nil)
+	local := &mockLocalRpm{}
+	actions.serviceLocalRpm = local
+
+	info := []_package.Package{{Name: "foo"}, {Name: "vim"}}
+	actions.markLocalFiles(context.Background(), []string{file}, info)
+	if info[0].LocalFile != file || info[1].LocalFile != "" {
+		t.Errorf("unexpected local files: %q, %q", info[0].LocalFile, info[1].LocalFile)
+	}
+
+	stored, err := actions.storeLocalPackages(context.Background(), []string{"vim", file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"vim", filepath.Join("rpms", "foo.rpm")}; !slices.Equal(stored, want) {
+		t.Errorf("stored %v, want %v", stored, want)
+	}
+}
+
+func TestCheckInstallOffline(t *testing.T) {
+	dir := t.TempDir()
+	data := []byte("rpm")
+	if err := os.WriteFile(filepath.Join(dir, "foo-1.0-alt1.x86_64.rpm"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(data)
+	manifest := localrpm.Manifest{Packages: []localrpm.ManifestPackage{{
+		Name: "foo", Version: "1.0-alt1", Arch: "x86_64", File: "foo-1.0-alt1.x86_64.rpm", SHA256: hex.EncodeToString(sum[:]),
+	}}}
+	raw, _ := json.Marshal(manifest)
+	if err := os.WriteFile(filepath.Join(dir, localrpm.ManifestFile), raw, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("whole set is installed", func(t *testing.T) {
+		apt := &mockAptActions{findChanges: &aptLib.PackageChanges{NewInstalledCount: 1, NewInstalledPackages: []string{"foo"}}}
 		actions := newTestActions(apt, &mockAptDB{}, nil)
+		actions.SetOfflineDir(dir)
 
-		resp, err := actions.CheckReinstall(context.Background(), []string{"bash"})
-		if err != nil {
+		if _, err := actions.CheckInstall(context.Background(), nil); err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
-		if resp.Info.NewInstalledCount != 1 {
-			t.Errorf("expected 1 reinstall, got %d", resp.Info.NewInstalledCount)
+		if want := []string{filepath.Join(dir, "foo-1.0-alt1.x86_64.rpm")}; !slices.Equal(apt.prepareArgs, want) {
+			t.Errorf("prepared %v, want %v", apt.prepareArgs, want)
 		}
 	})
-}
 
-func TestImageHistory(t *testing.T) {
-	history := []build.ImageHistory{
-		{ImageName: "alt:p11"},
-		{ImageName: "alt:p11"},
-	}
+	t.Run("missing dependency returns repository error", func(t *testing.T) {
+		apt := &mockAptActions{findChanges: &aptLib.PackageChanges{NewInstalledCount: 2, NewInstalledPackages: []string{"foo", "libbar"}}}
+		actions := newTestActions(apt, &mockAptDB{}, nil)
+		actions.SetOfflineDir(dir)
 
-	t.Run("returns history with total count", func(t *testing.T) {
-		hostDB := &mockHostDB{historyResult: history, countResult: 10}
-		actions := newTestActions(nil, &mockAptDB{}, hostDB)
-
-		resp, err := actions.ImageHistory(context.Background(), "", 10, 0)
-		if err != nil {
-			t.Fatalf("unexpected error: %v", err)
-		}
-		if len(resp.History) != 2 {
-			t.Errorf("expected 2 entries, got %d", len(resp.History))
-		}
-		if resp.TotalCount != 10 {
-			t.Errorf("expected totalCount=10, got %d", resp.TotalCount)
+		_, err := actions.CheckInstall(context.Background(), nil)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeRepository)
+		if !strings.Contains(err.Error(), "libbar") {
+			t.Errorf("error must name the missing package: %v", err)
 		}
 	})
 
-	t.Run("history query error propagates", func(t *testing.T) {
-		hostDB := &mockHostDB{historyErr: errors.New("db error")}
-		actions := newTestActions(nil, &mockAptDB{}, hostDB)
+	t.Run("packages with offline set are rejected", func(t *testing.T) {
+		actions := newTestActions(nil, &mockAptDB{}, nil)
+		actions.SetOfflineDir(dir)
 
-		_, err := actions.ImageHistory(context.Background(), "", 10, 0)
-		testutil.AssertAPMError(t, err, apmerr.ErrorTypeDatabase)
+		_, err := actions.CheckInstall(context.Background(), []string{"vim"})
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
 	})
+}
 
-	t.Run("count query error propagates", func(t *testing.T) {
-		hostDB := &mockHostDB{historyResult: history, countErr: errors.New("count error")}
-		actions := newTestActions(nil, &mockAptDB{}, hostDB)
+func TestUseDownloadDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "set")
+	apt := &mockAptActions{overrides: map[string]string{"Debug::pkgProblemResolver": "true"}}
+	actions := newTestActions(apt, &mockAptDB{}, nil)
+	actions.SetDownloadDir(dir)
 
-		_, err := actions.ImageHistory(context.Background(), "", 10, 0)
-		testutil.AssertAPMError(t, err, apmerr.ErrorTypeDatabase)
-	})
+	if err := actions.useDownloadDir(); err != nil {
+		t.Fatal(err)
+	}
+	if apt.overrides[archivesConfigKey] != dir+"/" || apt.overrides["Debug::pkgProblemResolver"] != "true" {
+		t.Errorf("unexpected overrides: %v", apt.overrides)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "partial")); err != nil {
+		t.Errorf("partial directory is missing: %v", err)
+	}
 }
 
-func TestImageGetConfig(t *testing.T) {
-	t.Run("returns loaded config", func(t *testing.T) {
-		cfg := &build.Config{Image: "alt:p11"}
-		actions := newTestActions(nil, &mockAptDB{}, nil)
-		actions.serviceHostConfig = &mockHostConfig{config: cfg}
+func TestCheckRemove(t *testing.T) {
+	t.Run("success shows removal candidates with dependencies", func(t *testing.T) {
+		changes := &aptLib.PackageChanges{
+			RemovedCount:    2,
+			RemovedPackages: []string{"vim", "vim-common"},
+		}
+		apt := &mockAptActions{checkRemoveRes: changes}
+		actions := newTestActions(apt, &mockAptDB{}, nil)
 
-		resp, err := actions.ImageGetConfig(context.Background())
+		resp, err := actions.CheckRemove(context.Background(), []string{"vim"}, false, false)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
-		if resp.Config.Image != "alt:p11" {
-			t.Errorf("expected image alt:p11, got %s", resp.Config.Image)
+		if resp.Info.RemovedCount != 2 {
+			t.Errorf("expected 2 removed, got %d", resp.Info.RemovedCount)
 		}
 	})
 
-	t.Run("load error propagates as image error", func(t *testing.T) {
-		actions := newTestActions(nil, &mockAptDB{}, nil)
-		actions.serviceHostConfig = &mockHostConfig{loadErr: errors.New("file not found")}
+	t.Run("apt error propagates", func(t *testing.T) {
+		apt := &mockAptActions{checkRemoveErr: errors.New("cannot remove essential")}
+		actions := newTestActions(apt, &mockAptDB{}, nil)
 
-		_, err := actions.ImageGetConfig(context.Background())
-		testutil.AssertAPMError(t, err, apmerr.ErrorTypeImage)
+		_, err := actions.CheckRemove(context.Background(), []string{"glibc"}, false, false)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeApt)
 	})
 }
 
-func TestImageSaveConfig(t *testing.T) {
-	t.Run("replaces config and saves", func(t *testing.T) {
-		hcfg := &mockHostConfig{config: &build.Config{Image: "old"}}
-		actions := newTestActions(nil, &mockAptDB{}, nil)
-		actions.serviceHostConfig = hcfg
+func TestRemoveProtected(t *testing.T) {
+	newActions := func() *Actions {
+		changes := &aptLib.PackageChanges{
+			RemovedCount:    2,
+			RemovedPackages: []string{"systemd", "vim"},
+		}
+		actions := newTestActions(&mockAptActions{findChanges: changes, checkRemoveRes: changes}, &mockAptDB{}, nil)
+		actions.appConfig = testutil.JsonAppConfig()
+		actions.appConfig.ConfigManager.GetConfig().ProtectedPackages = []string{"systemd", "glibc*"}
+		return actions
+	}
 
-		newCfg := build.Config{Image: "alt:p12"}
-		resp, err := actions.ImageSaveConfig(context.Background(), newCfg)
+	t.Run("simulation marks protected packages", func(t *testing.T) {
+		resp, err := newActions().CheckRemove(context.Background(), []string{"systemd"}, false, false)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
-		if resp.Config.Image != "alt:p12" {
-			t.Errorf("expected saved image alt:p12, got %s", resp.Config.Image)
+		if len(resp.Info.EssentialPackages) != 1 || resp.Info.EssentialPackages[0].Name != "systemd" {
+			t.Errorf("expected systemd marked as essential, got %+v", resp.Info.EssentialPackages)
 		}
-		if hcfg.config.Image != "alt:p12" {
-			t.Error("SetConfig should update the stored config")
+	})
+
+	t.Run("removal without force is refused", func(t *testing.T) {
+		_, err := newActions().Remove(context.Background(), []string{"systemd"}, false, false, true)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+	})
+
+	t.Run("force requires typed confirmation", func(t *testing.T) {
+		actions := newActions()
+		actions.SetForceEssential(true)
+
+		_, err := actions.Remove(context.Background(), []string{"systemd"}, false, false, true)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeCanceled)
+	})
+
+	t.Run("glob patterns match", func(t *testing.T) {
+		got := newActions().findProtected([]string{"glibc-core", "vim", "systemd"})
+		if len(got) != 2 || got[0] != "glibc-core" || got[1] != "systemd" {
+			t.Errorf("unexpected protected packages: %v", got)
 		}
 	})
+}
 
-	t.Run("save error propagates as image error", func(t *testing.T) {
-		actions := newTestActions(nil, &mockAptDB{}, nil)
-		actions.serviceHostConfig = &mockHostConfig{
-			config:  &build.Config{},
-			saveErr: errors.New("disk full"),
+func TestAutoRemove(t *testing.T) {
+	t.Run("nothing to remove", func(t *testing.T) {
+		actions := newTestActions(nil, nil, nil)
+		_, err := actions.AutoRemove(context.Background(), true)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNoOperation)
+	})
+
+	t.Run("orphans are removed", func(t *testing.T) {
+		changes := &aptLib.PackageChanges{RemovedCount: 2, RemovedPackages: []string{"libfoo", "libbar"}}
+		actions := newTestActions(&mockAptActions{autoRemoveRes: changes, findChanges: changes}, nil, nil)
+		actions.reporter = reply.NewReporter(actions.appConfig)
+
+		resp, err := actions.AutoRemove(context.Background(), true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Info.RemovedCount != 2 {
+			t.Errorf("expected 2 removed packages, got %d", resp.Info.RemovedCount)
 		}
+	})
 
-		_, err := actions.ImageSaveConfig(context.Background(), build.Config{Image: "new"})
-		testutil.AssertAPMError(t, err, apmerr.ErrorTypeImage)
+	t.Run("protected orphans are refused", func(t *testing.T) {
+		changes := &aptLib.PackageChanges{RemovedCount: 1, RemovedPackages: []string{"systemd"}}
+		actions := newTestActions(&mockAptActions{autoRemoveRes: changes, findChanges: changes}, nil, nil)
+		actions.appConfig = testutil.JsonAppConfig()
+		actions.appConfig.ConfigManager.GetConfig().ProtectedPackages = []string{"systemd"}
+
+		_, err := actions.AutoRemove(context.Background(), true)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
 	})
 }
 
-func TestSetGetAptConfigOverrides(t *testing.T) {
-	t.Run("set and get overrides roundtrip", func(t *testing.T) {
+func TestHold(t *testing.T) {
+	packages := []_package.Package{
+		{Name: "vim", Installed: true, VersionInstalled: "9.0-alt1"},
+		{Name: "emacs"},
+	}
+	newActions := func() (*Actions, *mockAptActions, *mockAptDB) {
 		apt := &mockAptActions{}
-		actions := newTestActions(apt, &mockAptDB{}, nil)
+		db := &mockAptDB{getByNamesResult: packages}
+		return newTestActions(apt, db, nil), apt, db
+	}
 
-		overrides := map[string]string{"Acquire::http::Proxy": "http://proxy:8080"}
-		resp, err := actions.SetAptConfigOverrides(overrides)
+	t.Run("holds installed package", func(t *testing.T) {
+		actions, apt, db := newActions()
+		resp, err := actions.Hold(context.Background(), []string{"vim"})
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
-		if resp.Options["Acquire::http::Proxy"] != "http://proxy:8080" {
-			t.Error("SetAptConfigOverrides should return the passed options")
+		if !slices.Equal(db.held, []string{"vim"}) || !slices.Equal(resp.Held, []string{"vim"}) {
+			t.Errorf("expected vim to be held, got db=%v resp=%v", db.held, resp.Held)
+		}
+		if apt.holdSynced != 1 {
+			t.Errorf("expected APT preferences to be synced once, got %d", apt.holdSynced)
 		}
 
-		resp, err = actions.GetAptConfigOverrides()
+		_, err = actions.Hold(context.Background(), []string{"vim"})
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNoOperation)
+	})
+
+	t.Run("not installed package is refused", func(t *testing.T) {
+		actions, _, _ := newActions()
+		_, err := actions.Hold(context.Background(), []string{"emacs"})
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+	})
+
+	t.Run("unknown package", func(t *testing.T) {
+		actions, _, _ := newActions()
+		_, err := actions.Hold(context.Background(), []string{"nano"})
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNotFound)
+	})
+
+	t.Run("empty list", func(t *testing.T) {
+		actions, _, _ := newActions()
+		_, err := actions.Hold(context.Background(), []string{" "})
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+	})
+
+	t.Run("unhold", func(t *testing.T) {
+		actions, apt, db := newActions()
+		db.held = []string{"bash", "vim"}
+
+		resp, err := actions.Unhold(context.Background(), []string{"vim", "emacs"})
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
-		if resp.Options["Acquire::http::Proxy"] != "http://proxy:8080" {
-			t.Error("GetAptConfigOverrides should return previously set options")
+		if !slices.Equal(resp.Packages, []string{"vim"}) || !slices.Equal(db.held, []string{"bash"}) {
+			t.Errorf("expected only vim to be released, got packages=%v held=%v", resp.Packages, db.held)
 		}
+		if apt.holdSynced != 1 {
+			t.Errorf("expected APT preferences to be synced once, got %d", apt.holdSynced)
+		}
+
+		_, err = actions.Unhold(context.Background(), []string{"emacs"})
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNoOperation)
 	})
 
-	t.Run("get returns empty map when nil", func(t *testing.T) {
-		apt := &mockAptActions{overrides: nil}
-		actions := newTestActions(apt, &mockAptDB{}, nil)
+	t.Run("simulation keeps holds unchanged", func(t *testing.T) {
+		actions, apt, db := newActions()
+		db.held = []string{"bash"}
 
-		resp, err := actions.GetAptConfigOverrides()
+		resp, err := actions.CheckHold(context.Background(), []string{"vim"})
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
-		if resp.Options == nil {
-			t.Error("should return empty map, not nil")
+		if !slices.Equal(resp.Held, []string{"bash", "vim"}) {
+			t.Errorf("expected vim in the resulting holds, got %v", resp.Held)
 		}
-	})
-}
-
-func TestValidateDB_DBEmpty(t *testing.T) {
-	apt := &mockAptActions{updateErr: errors.New("update failed")}
-	db := &mockAptDB{dbExistErr: errors.New("empty database")}
-	actions := newTestActions(apt, db, nil)
 
-	_, err := actions.Search(context.Background(), "vim", false)
-	if syscall.Geteuid() == 0 {
-		testutil.AssertAPMError(t, err, apmerr.ErrorTypeDatabase)
-	} else {
-		testutil.AssertAPMError(t, err, apmerr.ErrorTypePermission)
-	}
+		resp, err = actions.CheckUnhold(context.Background(), []string{"bash"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resp.Held) != 0 {
+			t.Errorf("expected no holds left, got %v", resp.Held)
+		}
+		if !slices.Equal(db.held, []string{"bash"}) || apt.holdSynced != 0 {
+			t.Errorf("simulation must not change holds, got db=%v synced=%d", db.held, apt.holdSynced)
+		}
+	})
 }
 
-func TestFormatPackageOutput(t *testing.T) {
-	pkg := _package.Package{
-		Name:       "vim",
-		Summary:    "Text editor",
-		Version:    "9.0",
-		Installed:  true,
-		Maintainer: "packager@alt",
-		Size:       12345,
-		Section:    "editors",
-	}
-	actions := newTestActions(nil, &mockAptDB{}, nil)
+func TestProvides(t *testing.T) {
+	t.Run("installed owner and repository candidates", func(t *testing.T) {
+		apt := &mockAptActions{owners: []aptBinding.InstalledPackageInfo{{Name: "vim-console", Version: "9.0-alt1"}}}
+		db := &mockAptDB{providing: []_package.Package{
+			{Name: "vim-console", Version: "9.1-alt1", Installed: true},
+			{Name: "vim-X11", Version: "9.1-alt1"},
+		}}
+		actions := newTestActions(apt, db, nil)
 
-	t.Run("single full preserves all fields", func(t *testing.T) {
-		result := actions.FormatPackageOutput(pkg, true)
-		full, ok := result.(_package.Package)
-		if !ok {
-			t.Fatalf("expected Package, got %T", result)
+		resp, err := actions.Provides(context.Background(), " /usr/bin/vim ")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
 		}
-		if full.Section != "editors" || full.Size != 12345 {
-			t.Error("full output should preserve all fields")
+		if resp.Target != "/usr/bin/vim" || resp.Count != 2 {
+			t.Fatalf("expected 2 packages for /usr/bin/vim, got %+v", resp)
 		}
-	})
-
-	t.Run("single short strips extra fields", func(t *testing.T) {
-		result := actions.FormatPackageOutput(pkg, false)
-		short, ok := result.(ShortPackageResponse)
-		if !ok {
-			t.Fatalf("expected ShortPackageResponse, got %T", result)
+		if p := resp.Packages[0]; p.Name != "vim-console" || p.Source != FilesSourceRpm || !p.Installed {
+			t.Errorf("expected installed owner from rpm first, got %+v", p)
 		}
-		if short.Name != "vim" || short.Version != "9.0" || !short.Installed {
-			t.Errorf("wrong short values: %+v", short)
+		if p := resp.Packages[1]; p.Name != "vim-X11" || p.Source != FilesSourceRepository || p.Installed {
+			t.Errorf("expected repository candidate, got %+v", p)
 		}
 	})
 
-	t.Run("slice short converts all", func(t *testing.T) {
-		pkgs := []_package.Package{pkg, {Name: "nano", Version: "7.0"}}
-		result := actions.FormatPackageOutput(pkgs, false)
-		short, ok := result.([]ShortPackageResponse)
-		if !ok {
-			t.Fatalf("expected []ShortPackageResponse, got %T", result)
-		}
-		if len(short) != 2 || short[1].Name != "nano" {
-			t.Errorf("unexpected: %+v", short)
-		}
+	t.Run("nothing found", func(t *testing.T) {
+		_, err := newTestActions(nil, nil, nil).Provides(context.Background(), "libnothing.so")
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNotFound)
 	})
 
-	t.Run("unknown type returns nil", func(t *testing.T) {
-		if actions.FormatPackageOutput("string", false) != nil {
-			t.Error("expected nil for unknown type")
-		}
+	t.Run("empty target", func(t *testing.T) {
+		_, err := newTestActions(nil, nil, nil).Provides(context.Background(), "")
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
 	})
 }
 
-func TestEnrichWithAppStream(t *testing.T) {
-	comp := swcat.Component{
-		Type:    "desktop-application",
-		ID:      "org.vim.Vim",
-		PkgName: "vim",
+func TestChangelog(t *testing.T) {
+	info := &aptLib.PackageInfo{
+		Version: "9.1-alt1",
+		Changelog: "* Tue Oct 01 2024 Ivan <ivan@altlinux.org> 9.1-alt1\n- Updated to 9.1.\n\n" +
+			"* Fri Jan 12 2024 Ivan <ivan@altlinux.org> 9.0-alt1\n- Initial build.",
+	}
+	newActions := func(pkg _package.Package) *Actions {
+		return newTestActions(&mockAptActions{info: info}, &mockAptDB{getByNameResult: pkg}, nil)
 	}
 
-	t.Run("enriches packages when format is json and HasAppStream is true", func(t *testing.T) {
-		appStream := &mockAppStream{
-			result: map[string][]swcat.Component{"vim": {comp}},
-		}
-		actions := newTestActions(nil, &mockAptDB{
-			getByNameResult: _package.Package{Name: "vim", HasAppStream: true},
-		}, nil)
-		actions.appConfig = testutil.JsonAppConfig()
-		actions.serviceAppStreamDB = appStream
-
-		resp, err := actions.Info(context.Background(), "vim")
+	t.Run("installed package shows newer entries", func(t *testing.T) {
+		resp, err := newActions(_package.Package{Name: "vim", Installed: true, VersionInstalled: "9.0"}).
+			Changelog(context.Background(), "vim", "")
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
-		if len(resp.PackageInfo.AppStream) != 1 {
-			t.Errorf("expected 1 appstream component, got %d", len(resp.PackageInfo.AppStream))
-		}
-		if resp.PackageInfo.AppStream[0].ID != "org.vim.Vim" {
-			t.Errorf("expected component ID org.vim.Vim, got %s", resp.PackageInfo.AppStream[0].ID)
+		if resp.Since != "9.0" || len(resp.Entries) != 1 || resp.Entries[0].Version != "9.1-alt1" {
+			t.Errorf("expected only the 9.1 entry, got since=%q entries=%+v", resp.Since, resp.Entries)
 		}
 	})
 
-	t.Run("skips enrichment when format is text", func(t *testing.T) {
-		appStream := &mockAppStream{
-			result: map[string][]swcat.Component{"vim": {comp}},
-		}
-		actions := newTestActions(nil, &mockAptDB{
-			getByNameResult: _package.Package{Name: "vim", HasAppStream: true},
-		}, nil)
-		actions.serviceAppStreamDB = appStream
-
-		resp, err := actions.Info(context.Background(), "vim")
+	t.Run("not installed package shows full changelog", func(t *testing.T) {
+		resp, err := newActions(_package.Package{Name: "vim"}).Changelog(context.Background(), "vim", "")
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
-		if len(resp.PackageInfo.AppStream) != 0 {
-			t.Errorf("expected no appstream in text format, got %d", len(resp.PackageInfo.AppStream))
+		if len(resp.Entries) != 2 {
+			t.Errorf("expected 2 entries, got %d", len(resp.Entries))
 		}
 	})
 
-	t.Run("skips packages without HasAppStream flag", func(t *testing.T) {
-		appStream := &mockAppStream{
-			result: map[string][]swcat.Component{"vim": {comp}},
-		}
-		actions := newTestActions(nil, &mockAptDB{
-			getByNameResult: _package.Package{Name: "vim", HasAppStream: false},
-		}, nil)
-		actions.appConfig = testutil.JsonAppConfig()
-		actions.serviceAppStreamDB = appStream
-
-		resp, err := actions.Info(context.Background(), "vim")
+	t.Run("since overrides installed version", func(t *testing.T) {
+		resp, err := newActions(_package.Package{Name: "vim", Installed: true, VersionInstalled: "9.1"}).
+			Changelog(context.Background(), "vim", "9.0-alt1")
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
-		if len(resp.PackageInfo.AppStream) != 0 {
-			t.Errorf("expected no appstream when HasAppStream=false, got %d", len(resp.PackageInfo.AppStream))
+		if len(resp.Entries) != 1 {
+			t.Errorf("expected 1 entry after 9.0-alt1, got %d", len(resp.Entries))
 		}
 	})
 
-	t.Run("appstream error does not fail the request", func(t *testing.T) {
-		appStream := &mockAppStream{err: errors.New("db error")}
-		actions := newTestActions(nil, &mockAptDB{
-			getByNameResult: _package.Package{Name: "vim", HasAppStream: true},
-		}, nil)
-		actions.appConfig = testutil.JsonAppConfig()
-		actions.serviceAppStreamDB = appStream
+	t.Run("empty name", func(t *testing.T) {
+		_, err := newActions(_package.Package{}).Changelog(context.Background(), " ", "")
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+	})
+}
+
+func TestCheckUpgrade(t *testing.T) {
+	t.Run("success shows available upgrades", func(t *testing.T) {
+		changes := &aptLib.PackageChanges{UpgradedCount: 15}
+		apt := &mockAptActions{checkUpgradeRes: changes}
+		actions := newTestActions(apt, &mockAptDB{}, nil)
+
+		resp, err := actions.CheckUpgrade(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Info.UpgradedCount != 15 {
+			t.Errorf("expected 15 upgrades, got %d", resp.Info.UpgradedCount)
+		}
+	})
+
+	t.Run("apt error propagates", func(t *testing.T) {
+		apt := &mockAptActions{checkUpgradeErr: errors.New("repo unreachable")}
+		actions := newTestActions(apt, &mockAptDB{}, nil)
+
+		_, err := actions.CheckUpgrade(context.Background())
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeApt)
+	})
+}
+
+func TestCheckReinstall(t *testing.T) {
+	t.Run("empty packages returns validation error", func(t *testing.T) {
+		actions := newTestActions(nil, &mockAptDB{}, nil)
+		_, err := actions.CheckReinstall(context.Background(), []string{})
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+	})
+
+	t.Run("success returns reinstall changes", func(t *testing.T) {
+		changes := &aptLib.PackageChanges{NewInstalledCount: 1}
+		apt := &mockAptActions{
+			prepareInstall: []string{"bash"},
+			findChanges:    changes,
+		}
+		actions := newTestActions(apt, &mockAptDB{}, nil)
+
+		resp, err := actions.CheckReinstall(context.Background(), []string{"bash"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Info.NewInstalledCount != 1 {
+			t.Errorf("expected 1 reinstall, got %d", resp.Info.NewInstalledCount)
+		}
+	})
+}
+
+func TestImageHistory(t *testing.T) {
+	history := []build.ImageHistory{
+		{ImageName: "alt:p11"},
+		{ImageName: "alt:p11"},
+	}
+
+	t.Run("returns history with total count", func(t *testing.T) {
+		hostDB := &mockHostDB{historyResult: history, countResult: 10}
+		actions := newTestActions(nil, &mockAptDB{}, hostDB)
+
+		resp, err := actions.ImageHistory(context.Background(), "", 10, 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resp.History) != 2 {
+			t.Errorf("expected 2 entries, got %d", len(resp.History))
+		}
+		if resp.TotalCount != 10 {
+			t.Errorf("expected totalCount=10, got %d", resp.TotalCount)
+		}
+	})
+
+	t.Run("history query error propagates", func(t *testing.T) {
+		hostDB := &mockHostDB{historyErr: errors.New("db error")}
+		actions := newTestActions(nil, &mockAptDB{}, hostDB)
+
+		_, err := actions.ImageHistory(context.Background(), "", 10, 0)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeDatabase)
+	})
+
+	t.Run("count query error propagates", func(t *testing.T) {
+		hostDB := &mockHostDB{historyResult: history, countErr: errors.New("count error")}
+		actions := newTestActions(nil, &mockAptDB{}, hostDB)
+
+		_, err := actions.ImageHistory(context.Background(), "", 10, 0)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeDatabase)
+	})
+}
+
+func TestImageDiff(t *testing.T) {
+	hostDB := &mockHostDB{historyResult: []build.ImageHistory{
+		{ID: 1, ImageName: "alt", Packages: map[string]string{"bash": "5.1-alt1", "nano": "7.2-alt1"}},
+		{ID: 2, ImageName: "alt", Packages: map[string]string{"bash": "5.2-alt1", "vim": "9.1-alt1"}},
+		{ID: 3, ImageName: "alt"},
+	}}
+	actions := newTestActions(nil, nil, hostDB)
+
+	t.Run("package difference", func(t *testing.T) {
+		resp, err := actions.ImageDiff(context.Background(), 1, 2)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resp.Added) != 1 || resp.Added[0] != (ImagePackageChange{Name: "vim", To: "9.1-alt1"}) {
+			t.Errorf("unexpected added: %+v", resp.Added)
+		}
+		if len(resp.Removed) != 1 || resp.Removed[0] != (ImagePackageChange{Name: "nano", From: "7.2-alt1"}) {
+			t.Errorf("unexpected removed: %+v", resp.Removed)
+		}
+		if len(resp.Upgraded) != 1 || resp.Upgraded[0] != (ImagePackageChange{Name: "bash", From: "5.1-alt1", To: "5.2-alt1"}) {
+			t.Errorf("unexpected upgraded: %+v", resp.Upgraded)
+		}
+	})
+
+	t.Run("record without snapshot", func(t *testing.T) {
+		_, err := actions.ImageDiff(context.Background(), 1, 3)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNotFound)
+	})
+
+	t.Run("same record", func(t *testing.T) {
+		_, err := actions.ImageDiff(context.Background(), 2, 2)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+	})
+}
+
+func TestImageRollback(t *testing.T) {
+	newActions := func(hostImage *mockHostImage, hostDB *mockHostDB) (*Actions, *mockHostConfig) {
+		hostConfig := &mockHostConfig{config: &build.Config{Image: "current"}}
+		actions := newTestActions(nil, nil, hostDB)
+		actions.appConfig.ConfigManager.GetConfig().IsAtomic = true
+		actions.serviceHostImage = hostImage
+		actions.serviceHostConfig = hostConfig
+		return actions, hostConfig
+	}
+
+	t.Run("restores previous config", func(t *testing.T) {
+		hostImage := &mockHostImage{}
+		hostImage.hostImage.Status.Rollback = &build.ImageStatus{Image: build.Image{Image: build.ImageInfo{Image: "previous"}}}
+		hostDB := &mockHostDB{historyResult: []build.ImageHistory{{ID: 1, Config: &build.Config{Image: "previous"}}}}
+		actions, hostConfig := newActions(hostImage, hostDB)
+
+		resp, err := actions.ImageRollback(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !hostImage.rolledBack {
+			t.Error("expected bootc rollback")
+		}
+		if hostConfig.config.Image != "previous" {
+			t.Errorf("expected previous config restored, got %q", hostConfig.config.Image)
+		}
+		if resp.Pending == "" {
+			t.Error("expected pending rollback in status")
+		}
+	})
+
+	t.Run("no previous deployment", func(t *testing.T) {
+		actions, _ := newActions(&mockHostImage{}, nil)
+
+		_, err := actions.ImageRollback(context.Background())
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNotFound)
+	})
+
+	t.Run("rollback already queued", func(t *testing.T) {
+		hostImage := &mockHostImage{}
+		hostImage.hostImage.Status.Rollback = &build.ImageStatus{}
+		hostImage.hostImage.Status.RollbackQueued = true
+		actions, _ := newActions(hostImage, nil)
+
+		_, err := actions.ImageRollback(context.Background())
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNoOperation)
+	})
+}
+
+func TestImageLogs(t *testing.T) {
+	logs := []build.BuildLog{
+		{Generation: 1, Path: "/var/lib/apm/logs/build-20250101T100000.log.gz"},
+		{Generation: 2, Path: "/var/lib/apm/logs/build-20250102T100000.log.gz"},
+	}
+
+	t.Run("returns latest generation by default", func(t *testing.T) {
+		actions := newTestActions(nil, &mockAptDB{}, nil)
+		actions.serviceHostImage = &mockHostImage{buildLogs: logs}
+
+		resp, err := actions.ImageLogs(context.Background(), 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Log.Generation != 2 || resp.Generations != 2 {
+			t.Errorf("expected generation 2 of 2, got %d of %d", resp.Log.Generation, resp.Generations)
+		}
+		if resp.Content != "log "+logs[1].Path {
+			t.Errorf("unexpected content %q", resp.Content)
+		}
+	})
+
+	t.Run("returns requested generation", func(t *testing.T) {
+		actions := newTestActions(nil, &mockAptDB{}, nil)
+		actions.serviceHostImage = &mockHostImage{buildLogs: logs}
+
+		resp, err := actions.ImageLogs(context.Background(), 1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Log.Path != logs[0].Path {
+			t.Errorf("expected %s, got %s", logs[0].Path, resp.Log.Path)
+		}
+	})
+
+	t.Run("unknown generation is not found", func(t *testing.T) {
+		actions := newTestActions(nil, &mockAptDB{}, nil)
+		actions.serviceHostImage = &mockHostImage{buildLogs: logs}
+
+		_, err := actions.ImageLogs(context.Background(), 3)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNotFound)
+	})
+
+	t.Run("no logs is not found", func(t *testing.T) {
+		actions := newTestActions(nil, &mockAptDB{}, nil)
+		actions.serviceHostImage = &mockHostImage{}
+
+		_, err := actions.ImageLogs(context.Background(), 0)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNotFound)
+	})
+
+	t.Run("read error propagates", func(t *testing.T) {
+		actions := newTestActions(nil, &mockAptDB{}, nil)
+		actions.serviceHostImage = &mockHostImage{buildLogs: logs, readErr: errors.New("gzip: invalid header")}
+
+		_, err := actions.ImageLogs(context.Background(), 0)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeImage)
+	})
+}
+
+func TestImageGetConfig(t *testing.T) {
+	t.Run("returns loaded config", func(t *testing.T) {
+		cfg := &build.Config{Image: "alt:p11"}
+		actions := newTestActions(nil, &mockAptDB{}, nil)
+		actions.serviceHostConfig = &mockHostConfig{config: cfg}
+
+		resp, err := actions.ImageGetConfig(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Config.Image != "alt:p11" {
+			t.Errorf("expected image alt:p11, got %s", resp.Config.Image)
+		}
+	})
+
+	t.Run("load error propagates as image error", func(t *testing.T) {
+		actions := newTestActions(nil, &mockAptDB{}, nil)
+		actions.serviceHostConfig = &mockHostConfig{loadErr: errors.New("file not found")}
+
+		_, err := actions.ImageGetConfig(context.Background())
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeImage)
+	})
+}
+
+func TestImageSaveConfig(t *testing.T) {
+	t.Run("replaces config and saves", func(t *testing.T) {
+		hcfg := &mockHostConfig{config: &build.Config{Image: "old"}}
+		actions := newTestActions(nil, &mockAptDB{}, nil)
+		actions.serviceHostConfig = hcfg
+
+		newCfg := build.Config{Image: "alt:p12"}
+		resp, err := actions.ImageSaveConfig(context.Background(), newCfg)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Config.Image != "alt:p12" {
+			t.Errorf("expected saved image alt:p12, got %s", resp.Config.Image)
+		}
+		if hcfg.config.Image != "alt:p12" {
+			t.Error("SetConfig should update the stored config")
+		}
+	})
+
+	t.Run("save error propagates as image error", func(t *testing.T) {
+		actions := newTestActions(nil, &mockAptDB{}, nil)
+		actions.serviceHostConfig = &mockHostConfig{
+			config:  &build.Config{},
+			saveErr: errors.New("disk full"),
+		}
+
+		_, err := actions.ImageSaveConfig(context.Background(), build.Config{Image: "new"})
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeImage)
+	})
+}
+
+func TestSetGetAptConfigOverrides(t *testing.T) {
+	t.Run("set and get overrides roundtrip", func(t *testing.T) {
+		apt := &mockAptActions{}
+		actions := newTestActions(apt, &mockAptDB{}, nil)
+
+		overrides := map[string]string{"Acquire::http::Proxy": "http://proxy:8080"}
+		resp, err := actions.SetAptConfigOverrides(overrides)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Options["Acquire::http::Proxy"] != "http://proxy:8080" {
+			t.Error("SetAptConfigOverrides should return the passed options")
+		}
+
+		resp, err = actions.GetAptConfigOverrides()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Options["Acquire::http::Proxy"] != "http://proxy:8080" {
+			t.Error("GetAptConfigOverrides should return previously set options")
+		}
+	})
+
+	t.Run("get returns empty map when nil", func(t *testing.T) {
+		apt := &mockAptActions{overrides: nil}
+		actions := newTestActions(apt, &mockAptDB{}, nil)
+
+		resp, err := actions.GetAptConfigOverrides()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Options == nil {
+			t.Error("should return empty map, not nil")
+		}
+	})
+}
+
+func TestValidateDB_DBEmpty(t *testing.T) {
+	apt := &mockAptActions{updateErr: errors.New("update failed")}
+	db := &mockAptDB{dbExistErr: errors.New("empty database")}
+	actions := newTestActions(apt, db, nil)
+
+	_, err := actions.Search(context.Background(), "vim", false, false)
+	if syscall.Geteuid() == 0 {
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeDatabase)
+	} else {
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypePermission)
+	}
+}
+
+func TestFormatPackageOutput(t *testing.T) {
+	pkg := _package.Package{
+		Name:       "vim",
+		Summary:    "Text editor",
+		Version:    "9.0",
+		Installed:  true,
+		Maintainer: "packager@alt",
+		Size:       12345,
+		Section:    "editors",
+	}
+	actions := newTestActions(nil, &mockAptDB{}, nil)
+
+	t.Run("single full preserves all fields", func(t *testing.T) {
+		result := actions.FormatPackageOutput(pkg, true)
+		full, ok := result.(_package.Package)
+		if !ok {
+			t.Fatalf("expected Package, got %T", result)
+		}
+		if full.Section != "editors" || full.Size != 12345 {
+			t.Error("full output should preserve all fields")
+		}
+	})
+
+	t.Run("single short strips extra fields", func(t *testing.T) {
+		result := actions.FormatPackageOutput(pkg, false)
+		short, ok := result.(ShortPackageResponse)
+		if !ok {
+			t.Fatalf("expected ShortPackageResponse, got %T", result)
+		}
+		if short.Name != "vim" || short.Version != "9.0" || !short.Installed {
+			t.Errorf("wrong short values: %+v", short)
+		}
+	})
+
+	t.Run("slice short converts all", func(t *testing.T) {
+		pkgs := []_package.Package{pkg, {Name: "nano", Version: "7.0"}}
+		result := actions.FormatPackageOutput(pkgs, false)
+		short, ok := result.([]ShortPackageResponse)
+		if !ok {
+			t.Fatalf("expected []ShortPackageResponse, got %T", result)
+		}
+		if len(short) != 2 || short[1].Name != "nano" {
+			t.Errorf("unexpected: %+v", short)
+		}
+	})
+
+	t.Run("unknown type returns nil", func(t *testing.T) {
+		if actions.FormatPackageOutput("string", false) != nil {
+			t.Error("expected nil for unknown type")
+		}
+	})
+}
+
+func TestEnrichWithAppStream(t *testing.T) {
+	comp := swcat.Component{
+		Type:    "desktop-application",
+		ID:      "org.vim.Vim",
+		PkgName: "vim",
+	}
+
+	t.Run("enriches packages when format is json and HasAppStream is true", func(t *testing.T) {
+		appStream := &mockAppStream{
+			result: map[string][]swcat.Component{"vim": {comp}},
+		}
+		actions := newTestActions(nil, &mockAptDB{
+			getByNameResult: _package.Package{Name: "vim", HasAppStream: true},
+		}, nil)
+		actions.appConfig = testutil.JsonAppConfig()
+		actions.serviceAppStreamDB = appStream
+
+		resp, err := actions.Info(context.Background(), "vim")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resp.PackageInfo.AppStream) != 1 {
+			t.Errorf("expected 1 appstream component, got %d", len(resp.PackageInfo.AppStream))
+		}
+		if resp.PackageInfo.AppStream[0].ID != "org.vim.Vim" {
+			t.Errorf("expected component ID org.vim.Vim, got %s", resp.PackageInfo.AppStream[0].ID)
+		}
+	})
+
+	t.Run("skips enrichment when format is text", func(t *testing.T) {
+		appStream := &mockAppStream{
+			result: map[string][]swcat.Component{"vim": {comp}},
+		}
+		actions := newTestActions(nil, &mockAptDB{
+			getByNameResult: _package.Package{Name: "vim", HasAppStream: true},
+		}, nil)
+		actions.serviceAppStreamDB = appStream
+
+		resp, err := actions.Info(context.Background(), "vim")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resp.PackageInfo.AppStream) != 0 {
+			t.Errorf("expected no appstream in text format, got %d", len(resp.PackageInfo.AppStream))
+		}
+	})
+
+	t.Run("skips packages without HasAppStream flag", func(t *testing.T) {
+		appStream := &mockAppStream{
+			result: map[string][]swcat.Component{"vim": {comp}},
+		}
+		actions := newTestActions(nil, &mockAptDB{
+			getByNameResult: _package.Package{Name: "vim", HasAppStream: false},
+		}, nil)
+		actions.appConfig = testutil.JsonAppConfig()
+		actions.serviceAppStreamDB = appStream
+
+		resp, err := actions.Info(context.Background(), "vim")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resp.PackageInfo.AppStream) != 0 {
+			t.Errorf("expected no appstream when HasAppStream=false, got %d", len(resp.PackageInfo.AppStream))
+		}
+	})
+
+	t.Run("appstream error does not fail the request", func(t *testing.T) {
+		appStream := &mockAppStream{err: errors.New("db error")}
+		actions := newTestActions(nil, &mockAptDB{
+			getByNameResult: _package.Package{Name: "vim", HasAppStream: true},
+		}, nil)
+		actions.appConfig = testutil.JsonAppConfig()
+		actions.serviceAppStreamDB = appStream
 
 		resp, err := actions.Info(context.Background(), "vim")
 		if err != nil {
-			t.Fatalf("expected no error even when appstream fails, got: %v", err)
+			t.Fatalf("expected no error even when appstream fails, got: %v", err)
+		}
+		if resp.PackageInfo.Name != "vim" {
+			t.Errorf("expected package vim, got %s", resp.PackageInfo.Name)
+		}
+	})
+}
+
+func TestRecent(t *testing.T) {
+	t.Run("invalid days", func(t *testing.T) {
+		actions := newTestActions(nil, nil, nil)
+		actions.serviceJournal = &mockJournal{}
+
+		_, err := actions.Recent(context.Background(), 0)
+		var apmErr apmerr.APMError
+		if !errors.As(err, &apmErr) || apmErr.Type != apmerr.ErrorTypeValidation {
+			t.Fatalf("expected validation error, got %v", err)
+		}
+	})
+
+	t.Run("latest action wins", func(t *testing.T) {
+		jr := &mockJournal{}
+		actions := newTestActions(nil, nil, nil)
+		actions.serviceJournal = jr
+
+		actions.recordJournal(context.Background(), 0, journal.ActionInstall, &aptLib.PackageChanges{NewInstalledPackages: []string{"vim", "mc"}})
+		actions.recordJournal(context.Background(), 0, journal.ActionRemove, &aptLib.PackageChanges{RemovedPackages: []string{"vim"}})
+
+		resp, err := actions.Recent(context.Background(), 7)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resp.Installed) != 1 || resp.Installed[0].Name != "mc" {
+			t.Errorf("expected only mc installed, got %+v", resp.Installed)
+		}
+		if len(resp.Removed) != 1 || resp.Removed[0].Undo != "apm s install vim" {
+			t.Errorf("unexpected removed list: %+v", resp.Removed)
+		}
+		if len(resp.Transactions) != 2 {
+			t.Fatalf("expected 2 transactions, got %d", len(resp.Transactions))
+		}
+		if resp.Transactions[0].Redo != "apm s remove vim" || resp.Transactions[0].Undo != "apm s install vim" {
+			t.Errorf("unexpected shortcuts for remove: %+v", resp.Transactions[0])
+		}
+		if resp.Transactions[1].Undo != "apm s remove vim mc" {
+			t.Errorf("unexpected undo for install: %q", resp.Transactions[1].Undo)
+		}
+	})
+}
+
+func TestFiles(t *testing.T) {
+	t.Run("installed package uses rpm manifest", func(t *testing.T) {
+		apt := &mockAptActions{
+			isInstalled: true,
+			installedFiles: []aptBinding.RpmFileInfo{
+				{Path: "/usr/bin/vim", Mode: "-rwxr-xr-x"},
+				{Path: "/etc/vimrc", Flags: "cn"},
+				{Path: "/usr/share/doc/vim/README", Flags: "d"},
+				{Path: "/usr/share/vim/syntax.vim"},
+			},
+		}
+		actions := newTestActions(apt, nil, nil)
+
+		resp, err := actions.Files(context.Background(), "vim", "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Source != FilesSourceRpm || !resp.Installed || resp.Count != 4 {
+			t.Fatalf("unexpected response: %+v", resp)
+		}
+		want := []string{FileTypeBin, FileTypeConfig, FileTypeDoc, FileTypeOther}
+		for i, f := range resp.Files {
+			if f.Type != want[i] {
+				t.Errorf("%s: expected type %s, got %s", f.Path, want[i], f.Type)
+			}
+		}
+
+		resp, err = actions.Files(context.Background(), "vim", "config")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Count != 1 || resp.Files[0].Path != "/etc/vimrc" {
+			t.Errorf("expected only /etc/vimrc, got %+v", resp.Files)
+		}
+	})
+
+	t.Run("not installed package uses repository index", func(t *testing.T) {
+		db := &mockAptDB{getByNameResult: _package.Package{Name: "mc", Files: []string{"/usr/bin/mc", "/usr/share/man/man1/mc.1.xz"}}}
+		actions := newTestActions(nil, db, nil)
+
+		resp, err := actions.Files(context.Background(), "mc", "bin")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Source != FilesSourceRepository || resp.Installed || resp.Count != 1 {
+			t.Errorf("unexpected response: %+v", resp)
+		}
+	})
+
+	t.Run("no file list in repository index", func(t *testing.T) {
+		db := &mockAptDB{getByNameResult: _package.Package{Name: "mc"}}
+		actions := newTestActions(nil, db, nil)
+
+		_, err := actions.Files(context.Background(), "mc", "")
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNotFound)
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		actions := newTestActions(nil, nil, nil)
+
+		_, err := actions.Files(context.Background(), "vim", "lib")
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+	})
+}
+
+func TestLog(t *testing.T) {
+	t.Run("negative lines", func(t *testing.T) {
+		actions := newTestActions(nil, nil, nil)
+		actions.serviceLogReader = &mockLogReader{}
+
+		_, err := actions.Log(context.Background(), "", "", -1)
+		var apmErr apmerr.APMError
+		if !errors.As(err, &apmErr) || apmErr.Type != apmerr.ErrorTypeValidation {
+			t.Fatalf("expected validation error, got %v", err)
+		}
+	})
+
+	t.Run("passes filter to reader", func(t *testing.T) {
+		reader := &mockLogReader{entries: []oplog.Entry{{Message: "[OK] Installing packages", Module: "system"}}}
+		actions := newTestActions(nil, nil, nil)
+		actions.serviceLogReader = reader
+
+		resp, err := actions.Log(context.Background(), " install ", "tx-1", 10)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Count != 1 {
+			t.Errorf("expected 1 entry, got %d", resp.Count)
+		}
+		want := oplog.Filter{Grep: "install", Transaction: "tx-1", Lines: 10}
+		if reader.filter != want {
+			t.Errorf("unexpected filter: %+v", reader.filter)
+		}
+	})
+
+	t.Run("follow streams entries", func(t *testing.T) {
+		reader := &mockLogReader{entries: []oplog.Entry{{Message: "a"}, {Message: "b"}}}
+		actions := newTestActions(nil, nil, nil)
+		actions.serviceLogReader = reader
+
+		var got []string
+		err := actions.FollowLog(context.Background(), "", "", 0, func(e oplog.Entry) {
+			got = append(got, e.Message)
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got) != 2 {
+			t.Errorf("expected 2 entries, got %v", got)
+		}
+	})
+}
+
+func TestImageApplySchedule(t *testing.T) {
+	t.Run("invalid time", func(t *testing.T) {
+		actions := newTestActions(nil, nil, nil)
+
+		_, err := actions.ImageApplySchedule(context.Background(), "25:00", false, false, "", "")
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+	})
+
+	t.Run("cancel without schedule", func(t *testing.T) {
+		actions := newTestActions(nil, nil, nil)
+
+		_, err := actions.ImageApplyCancelScheduled(context.Background())
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNoOperation)
+	})
+
+	t.Run("simulation shows the planned image and schedule", func(t *testing.T) {
+		sched := &mockSchedule{}
+		actions := newTestActions(nil, nil, nil)
+		actions.serviceSchedule = sched
+		actions.serviceHostConfig = &mockHostConfig{config: &build.Config{Image: "registry.altlinux.org/alt/base:sisyphus"}}
+		tmp := &temporary.Config{}
+		tmp.Packages.Install = []string{"vim"}
+		actions.serviceTemporaryConfig = &mockTempConfig{config: tmp}
+
+		resp, err := actions.CheckImageApply(context.Background(), "shutdown", true, "", "", "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Build || !resp.Pull || !slices.Equal(resp.Install, []string{"vim"}) {
+			t.Errorf("unexpected plan: %+v", resp)
+		}
+		if resp.Schedule == nil || resp.Schedule.Mode != schedule.ModeShutdown || sched.created != nil {
+			t.Errorf("expected a planned schedule without creating it, got %+v", resp.Schedule)
+		}
+
+		_, err = actions.CheckImageApplyCancelScheduled(context.Background())
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNoOperation)
+	})
+
+	t.Run("idle run postponed while system is busy", func(t *testing.T) {
+		sched := &mockSchedule{current: &schedule.Schedule{Mode: schedule.ModeIdle}}
+		actions := newTestActions(nil, nil, nil)
+		actions.serviceSchedule = sched
+
+		_, err := actions.ImageApplyRunScheduled(context.Background())
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNoOperation)
+		if sched.finished {
+			t.Error("schedule must stay active until the system is idle")
+		}
+	})
+}
+
+func TestAutoUpgrade(t *testing.T) {
+	info := &aptLib.PackageInfo{
+		Version: "1.1-alt1",
+		Changelog: "* Tue Oct 01 2024 Ivan <ivan@altlinux.org> 1.1-alt1\n- Fixed CVE-2024-1234.\n\n" +
+			"* Fri Jan 12 2024 Ivan <ivan@altlinux.org> 1.0-alt1\n- Initial build.",
+	}
+	newActions := func() *Actions {
+		apt := &mockAptActions{
+			info:            info,
+			checkUpgradeRes: &aptLib.PackageChanges{UpgradedCount: 1, UpgradedPackages: []string{"openssl"}},
+		}
+		db := &mockAptDB{getByNameResult: _package.Package{Name: "openssl", Installed: true, VersionInstalled: "1.0"}}
+		return newTestActions(apt, db, nil)
+	}
+
+	t.Run("unknown policy", func(t *testing.T) {
+		_, err := newActions().AutoUpgrade(context.Background(), "weekly")
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+	})
+
+	t.Run("none policy only reports security updates", func(t *testing.T) {
+		resp, err := newActions().AutoUpgrade(context.Background(), app.AutoUpgradeNone)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resp.Security) != 1 || resp.Security[0] != "openssl" {
+			t.Errorf("expected openssl as a security update, got %v", resp.Security)
+		}
+		if len(resp.Applied) != 0 || resp.RebootRequired {
+			t.Errorf("none policy must not apply updates, got %+v", resp)
+		}
+	})
+
+	t.Run("atomic system needs the all policy", func(t *testing.T) {
+		actions := newActions()
+		actions.appConfig.ConfigManager.GetConfig().IsAtomic = true
+
+		resp, err := actions.AutoUpgrade(context.Background(), app.AutoUpgradeSecurity)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resp.Applied) != 0 {
+			t.Errorf("expected nothing applied, got %v", resp.Applied)
+		}
+	})
+
+	t.Run("enable and disable timer", func(t *testing.T) {
+		timer := &mockAutoUpgrade{}
+		actions := newTestActions(nil, nil, nil)
+		actions.serviceAutoUpgrade = timer
+		actions.appConfig.ConfigManager.GetConfig().AutoUpgrade.OnCalendar = "daily"
+
+		resp, err := actions.AutoUpgradeEnable(context.Background(), "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if timer.enabled != "daily" || !resp.Timer.Enabled {
+			t.Errorf("expected timer enabled with the default schedule, got %q %+v", timer.enabled, resp.Timer)
+		}
+
+		if _, err = actions.AutoUpgradeDisable(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, err = actions.AutoUpgradeDisable(context.Background())
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNoOperation)
+	})
+}
+
+func TestNeedsReboot(t *testing.T) {
+	for name, want := range map[string]bool{
+		"kernel-image-6.12": true,
+		"glibc-core":        true,
+		"systemd":           true,
+		"vim-console":       false,
+	} {
+		if got := needsReboot(name); got != want {
+			t.Errorf("needsReboot(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestResolveConfigMerges(t *testing.T) {
+	files := &mockRpmnew{files: []rpmnew.File{{Path: "/etc/ssh/sshd_config", New: "/etc/ssh/sshd_config.rpmnew"}}}
+	actions := newTestActions(nil, nil, nil)
+	actions.serviceRpmnew = files
+
+	decisions := actions.resolveConfigMerges(context.Background(), nil)
+	if len(decisions) != 1 || decisions[0].Action != rpmnew.ActionSkip {
+		t.Fatalf("non-interactive run must postpone decisions, got %+v", decisions)
+	}
+	if files.applied["/etc/ssh/sshd_config"] != rpmnew.ActionSkip {
+		t.Errorf("unexpected applied actions: %v", files.applied)
+	}
+}
+
+func TestReverseDepends(t *testing.T) {
+	universe := []_package.Package{
+		{Name: "libfoo", Provides: []string{"libfoo.so.1()(64bit)"}},
+		{Name: "foo-tools", Depends: []string{"libfoo.so.1()(64bit)"}, Installed: true},
+		{Name: "foo-gui", Depends: []string{"foo-tools"}},
+		{Name: "foo-plugin", Depends: []string{"foo-gui", "libfoo"}, Installed: true},
+		{Name: "unrelated", Depends: []string{"bash"}, Installed: true},
+	}
+
+	newActions := func() *Actions {
+		return newTestActions(nil, &mockAptDB{universe: universe, getByNameResult: universe[0]}, nil)
+	}
+
+	t.Run("direct dependents through provides", func(t *testing.T) {
+		resp, err := newActions().ReverseDepends(context.Background(), "libfoo", 1, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Count != 2 || resp.Packages[0].Name != "foo-plugin" || resp.Packages[1].Name != "foo-tools" {
+			t.Fatalf("unexpected packages: %+v", resp.Packages)
+		}
+		if resp.Packages[1].Via != "libfoo.so.1()(64bit)" {
+			t.Errorf("unexpected via: %q", resp.Packages[1].Via)
+		}
+	})
+
+	t.Run("depth follows the chain without duplicates", func(t *testing.T) {
+		resp, err := newActions().ReverseDepends(context.Background(), "libfoo", 3, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Count != 3 {
+			t.Fatalf("expected 3 packages, got %+v", resp.Packages)
+		}
+		if last := resp.Packages[2]; last.Name != "foo-gui" || last.Depth != 2 {
+			t.Errorf("unexpected second level: %+v", last)
+		}
+	})
+
+	t.Run("installed only", func(t *testing.T) {
+		resp, err := newActions().ReverseDepends(context.Background(), "libfoo", 3, true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Count != 2 {
+			t.Errorf("expected 2 installed packages, got %+v", resp.Packages)
+		}
+	})
+
+	t.Run("invalid depth", func(t *testing.T) {
+		_, err := newActions().ReverseDepends(context.Background(), "libfoo", 0, false)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+	})
+}
+
+func TestReverseDependsTree(t *testing.T) {
+	universe := []_package.Package{
+		{Name: "libfoo", Provides: []string{"libfoo.so.1()(64bit)"}},
+		{Name: "foo-tools", Depends: []string{"libfoo.so.1()(64bit)"}},
+		{Name: "foo-gui", Depends: []string{"foo-tools"}},
+	}
+	actions := newTestActions(nil, &mockAptDB{universe: universe, getByNameResult: universe[0]}, nil)
+
+	resp, err := actions.ReverseDepends(context.Background(), "libfoo", 2, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tools := resp.Tree.Children
+	if resp.Tree.Name != "libfoo" || len(tools) != 1 || tools[0].Name != "foo-tools" || tools[0].Via != "libfoo.so.1()(64bit)" {
+		t.Fatalf("unexpected first level: %+v", resp.Tree)
+	}
+	if gui := tools[0].Children; len(gui) != 1 || gui[0].Name != "foo-gui" || gui[0].Via != "" {
+		t.Errorf("unexpected second level: %+v", gui)
+	}
+}
+
+func TestDepends(t *testing.T) {
+	universe := []_package.Package{
+		{Name: "app", Version: "1.0", Depends: []string{"libfoo.so.1()(64bit)", "common", "libgone"}},
+		{Name: "libfoo", Version: "2.0", Provides: []string{"libfoo.so.1()(64bit)"}, Depends: []string{"common"}},
+		{Name: "libfoo-compat", Provides: []string{"libfoo.so.1()(64bit)"}},
+		{Name: "common", Version: "3.0", Installed: true, Depends: []string{"app"}},
+	}
+	newActions := func() *Actions {
+		return newTestActions(nil, &mockAptDB{universe: universe, getByNameResult: universe[0], getByNamesResult: universe}, nil)
+	}
+
+	t.Run("tree with capabilities and missing dependencies", func(t *testing.T) {
+		resp, err := newActions().Depends(context.Background(), "app", 2, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Count != 2 {
+			t.Fatalf("expected 2 packages, got %+v", resp)
+		}
+
+		children := resp.Tree.Children
+		if len(children) != 3 || children[0].Name != "common" || children[1].Name != "libfoo" || children[2].Name != "libgone" {
+			t.Fatalf("unexpected children: %+v", children)
+		}
+		if children[1].Via != "libfoo.so.1()(64bit)" || !children[2].Missing {
+			t.Errorf("unexpected via or missing marks: %+v", children)
+		}
+		if nested := children[1].Children; len(nested) != 1 || !nested[0].Repeated {
+			t.Errorf("expected common to be repeated under libfoo, got %+v", nested)
+		}
+		if cycle := children[0].Children; len(cycle) != 1 || cycle[0].Name != "app" || !cycle[0].Repeated {
+			t.Errorf("expected cycle back to app to be marked repeated, got %+v", cycle)
+		}
+	})
+
+	t.Run("installed only", func(t *testing.T) {
+		resp, err := newActions().Depends(context.Background(), "app", 1, true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Count != 1 || resp.Tree.Children[0].Name != "common" {
+			t.Errorf("expected only the installed dependency, got %+v", resp.Tree.Children)
+		}
+	})
+
+	t.Run("invalid depth", func(t *testing.T) {
+		_, err := newActions().Depends(context.Background(), "app", rdependsMaxDepth+1, false)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+	})
+
+	t.Run("text and DOT output", func(t *testing.T) {
+		resp, err := newActions().Depends(context.Background(), "app", 1, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		text := renderDependencyTree(context.Background(), resp.Tree)
+		if !strings.HasPrefix(text, "app 1.0\n├── common 3.0\n") || !strings.Contains(text, "╰── libgone") {
+			t.Errorf("unexpected text tree:\n%s", text)
+		}
+
+		dot := dependencyDOT(resp.Tree, false)
+		if !strings.Contains(dot, `"app" -> "libfoo" [label="libfoo.so.1()(64bit)"];`) ||
+			!strings.Contains(dot, `"libgone" [style=dashed];`) {
+			t.Errorf("unexpected DOT graph:\n%s", dot)
+		}
+		if reverse := dependencyDOT(resp.Tree, true); !strings.Contains(reverse, `"common" -> "app";`) {
+			t.Errorf("expected reverse edges to point to the dependency, got:\n%s", reverse)
+		}
+	})
+}
+
+func TestOrphans(t *testing.T) {
+	universe := []_package.Package{
+		{Name: "bash", Version: "5.2", Filename: "bash-5.2-alt1.x86_64.rpm"},
+		{Name: "vim", Version: "9.0", Filename: "vim-9.0-alt1.x86_64.rpm"},
+		{Name: "old-tool", Version: "1.0"},
+		{Name: "new-tool", Version: "2.0", Filename: "new-tool-2.0-alt1.x86_64.rpm", Provides: []string{"old-tool"}},
+	}
+	installed := map[string]string{
+		"bash":       "5.2",
+		"vim":        "9.1",
+		"old-tool":   "1.0",
+		"custom":     "0.1",
+		"gpg-pubkey": "1-1",
+	}
+
+	newActions := func() *Actions {
+		return newTestActions(&mockAptActions{installed: installed},
+			&mockAptDB{universe: universe, getByNamesResult: universe[:3]}, nil)
+	}
+
+	t.Run("report", func(t *testing.T) {
+		resp, err := newActions().Orphans(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Count != 3 {
+			t.Fatalf("expected 3 orphans, got %+v", resp.Packages)
+		}
+
+		byName := make(map[string]OrphanPackage)
+		for _, pkg := range resp.Packages {
+			byName[pkg.Name] = pkg
+		}
+		if pkg := byName["vim"]; pkg.Reason != OrphanNewerThanRepo || pkg.RepoVersion != "9.0" {
+			t.Errorf("unexpected vim entry: %+v", pkg)
+		}
+		if pkg := byName["old-tool"]; pkg.Reason != OrphanNotAvailable || !slices.Equal(pkg.Replacements, []string{"new-tool"}) {
+			t.Errorf("unexpected old-tool entry: %+v", pkg)
+		}
+		if pkg := byName["custom"]; pkg.Reason != OrphanNotAvailable || len(pkg.Replacements) != 0 {
+			t.Errorf("unexpected custom entry: %+v", pkg)
+		}
+		if !slices.Equal(orphanReplacements(resp), []string{"new-tool"}) {
+			t.Errorf("unexpected replacements: %v", orphanReplacements(resp))
+		}
+	})
+
+	t.Run("keep excludes packages", func(t *testing.T) {
+		resp, err := newActions().Orphans(context.Background(), []string{"custom", "vim"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !slices.Equal(orphanNames(resp), []string{"old-tool"}) {
+			t.Errorf("unexpected orphans: %v", orphanNames(resp))
+		}
+	})
+}
+
+func TestExplicitPackages(t *testing.T) {
+	known := []_package.Package{
+		{Name: "vim", Filename: "vim.rpm", Depends: []string{"vim-common", "libc"}},
+		{Name: "vim-common", Filename: "vim-common.rpm", Depends: []string{"libc"}},
+		{Name: "glibc", Filename: "glibc.rpm", Provides: []string{"libc"}, Depends: []string{"libc"}},
+		{Name: "mc", Filename: "mc.rpm", Depends: []string{"libc", "libc"}},
+		{Name: "custom", Depends: nil},
+	}
+	installed := map[string]string{"vim": "9.1", "vim-common": "9.1", "glibc": "2.40", "mc": "4.8", "custom": "0.1"}
+	actions := newTestActions(&mockAptActions{installed: installed}, &mockAptDB{getByNamesResult: known}, nil)
+
+	resp, err := actions.ExplicitPackages(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(resp.Packages, []string{"mc", "vim"}) {
+		t.Errorf("expected only leaf packages from repositories, got %v", resp.Packages)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	jr := &mockJournal{entries: []journal.Entry{
+		{ID: 3, Module: journal.ModuleRepository, Action: journal.ActionSet, Targets: []string{"p11"}, Status: journal.StatusFailed},
+		{ID: 2, Module: journal.ModuleSystem, Action: journal.ActionInstall, Installed: []string{"vim"}, Status: journal.StatusDone},
+		{ID: 1, Module: journal.ModuleKernel, Action: journal.ActionInstall, Targets: []string{"6.12.1-un-def-alt1"}, Status: journal.StatusDone},
+	}}
+	actions := newTestActions(nil, nil, nil)
+	actions.serviceJournal = jr
+
+	t.Run("list by module", func(t *testing.T) {
+		resp, err := actions.HistoryList(context.Background(), journal.ModuleRepository, 20, 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.TotalCount != 1 || resp.Entries[0].ID != 3 {
+			t.Errorf("unexpected entries: %+v", resp.Entries)
+		}
+	})
+
+	t.Run("unknown module", func(t *testing.T) {
+		_, err := actions.HistoryList(context.Background(), "distrobox", 20, 0)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+	})
+
+	t.Run("show with shortcuts", func(t *testing.T) {
+		resp, err := actions.HistoryShow(context.Background(), 2)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Entry.Redo != "apm s install vim" || resp.Entry.Undo != "apm s remove vim" {
+			t.Errorf("unexpected shortcuts: %+v", resp.Entry)
+		}
+	})
+
+	t.Run("failed operation has no shortcuts", func(t *testing.T) {
+		resp, err := actions.HistoryShow(context.Background(), 3)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Entry.Redo != "" || resp.Entry.Undo != "" {
+			t.Errorf("expected no shortcuts, got %+v", resp.Entry)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := actions.HistoryShow(context.Background(), 42)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNotFound)
+	})
+}
+
+func TestPlanRollback(t *testing.T) {
+	entry := journal.Entry{
+		Installed: []string{"vim", "gone"},
+		Upgraded:  []string{"bash", "curl", "zsh"},
+		Removed:   []string{"nano", "mc", "back"},
+		Previous:  []string{"bash=5.1", "zsh=5.9", "nano=7.2", "back=1.0"},
+	}
+	installed := map[string]string{"vim": "9.1", "bash": "5.2", "curl": "8.5", "zsh": "5.9", "back": "1.0"}
+
+	plan := planRollback(entry, installed)
+
+	if !slices.Equal(plan.Remove, []string{"vim"}) {
+		t.Errorf("unexpected remove list: %v", plan.Remove)
+	}
+	if !slices.Equal(plan.Restore, []string{"nano=7.2", "mc"}) {
+		t.Errorf("unexpected restore list: %v", plan.Restore)
+	}
+	if !slices.Equal(plan.Downgrade, []string{"bash=5.1"}) {
+		t.Errorf("unexpected downgrade list: %v", plan.Downgrade)
+	}
+	if !slices.Equal(plan.Skipped, []string{"curl"}) {
+		t.Errorf("unexpected skipped list: %v", plan.Skipped)
+	}
+	if !slices.Equal(plan.args(), []string{"nano=7.2", "mc", "bash=5.1", "vim-"}) {
+		t.Errorf("unexpected install arguments: %v", plan.args())
+	}
+}
+
+func TestPlanRollbackReleaseOnly(t *testing.T) {
+	entry := journal.Entry{Upgraded: []string{"htop", "mc"}, Previous: []string{"htop=3.0-alt1", "mc=4.8.30"}}
+	installed := map[string]string{"htop": "3.0-alt2", "mc": "4.8.30-alt3"}
+
+	plan := planRollback(entry, installed)
+
+	if !slices.Equal(plan.Downgrade, []string{"htop=3.0-alt1"}) {
+		t.Errorf("expected release-only upgrade to be rolled back, got %v", plan.Downgrade)
+	}
+}
+
+func TestRollback(t *testing.T) {
+	jr := &mockJournal{entries: []journal.Entry{
+		{ID: 3, Module: journal.ModuleSystem, Action: journal.ActionInstall, Installed: []string{"vim"}, Status: journal.StatusFailed},
+		{ID: 2, Module: journal.ModuleSystem, Action: journal.ActionRemove, Removed: []string{"nano"}, Status: journal.StatusDone, Transaction: "tx-2"},
+		{ID: 1, Module: journal.ModuleRepository, Action: journal.ActionSet, Targets: []string{"p11"}, Status: journal.StatusDone},
+	}}
+	actions := newTestActions(&mockAptActions{installed: map[string]string{"nano": "7.2"}}, nil, nil)
+	actions.serviceJournal = jr
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := actions.Rollback(context.Background(), "tx-42", true, false)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNotFound)
+	})
+
+	t.Run("failed operation", func(t *testing.T) {
+		_, err := actions.Rollback(context.Background(), "3", true, false)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+	})
+
+	t.Run("other module", func(t *testing.T) {
+		_, err := actions.Rollback(context.Background(), "1", true, false)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+	})
+
+	t.Run("nothing to roll back", func(t *testing.T) {
+		_, err := actions.Rollback(context.Background(), "tx-2", true, false)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNoOperation)
+	})
+
+	t.Run("release-only upgrade", func(t *testing.T) {
+		jr.entries = append(jr.entries, journal.Entry{ID: 4, Module: journal.ModuleSystem, Action: journal.ActionUpgrade,
+			Upgraded: []string{"htop"}, Previous: []string{"htop=3.0-alt1"}, Status: journal.StatusDone})
+		apt := actions.serviceAptActions.(*mockAptActions)
+		apt.installed["htop"] = "3.0-alt2"
+		apt.findChanges = &aptLib.PackageChanges{}
+
+		resp, err := actions.Rollback(context.Background(), "4", true, true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resp.Skipped) != 0 {
+			t.Errorf("expected htop to be downgraded, skipped %v", resp.Skipped)
+		}
+	})
+}
+
+func TestCheckInterrupted(t *testing.T) {
+	jr := &mockJournal{interrupted: []journal.Entry{{Action: journal.ActionUpgrade, Date: time.Now()}}}
+	actions := newTestActions(nil, nil, nil)
+	actions.serviceJournal = jr
+
+	_, err := actions.Remove(context.Background(), []string{"vim"}, false, false, true)
+	var apmErr apmerr.APMError
+	if !errors.As(err, &apmErr) || apmErr.Type != apmerr.ErrorTypeApt {
+		t.Fatalf("expected interrupted transaction error, got %v", err)
+	}
+
+	jr.interrupted = nil
+	if err = actions.checkInterrupted(context.Background()); err != nil {
+		t.Errorf("expected no error without interrupted transactions, got %v", err)
+	}
+}
+
+func TestJournalMarks(t *testing.T) {
+	jr := &mockJournal{}
+	actions := newTestActions(nil, nil, nil)
+	actions.serviceJournal = jr
+
+	changes := &aptLib.PackageChanges{NewInstalledPackages: []string{"vim"}}
+	id := actions.beginJournal(context.Background(), journal.ActionInstall, changes)
+	actions.recordJournal(context.Background(), id, journal.ActionInstall, changes)
+	actions.finishJournal(context.Background(), id)
+	if jr.begun != 1 || jr.completed != 1 || jr.failed != 1 || len(jr.entries) != 0 {
+		t.Errorf("expected transaction mark to be completed in place, got begun=%d completed=%d failed=%d entries=%d",
+			jr.begun, jr.completed, jr.failed, len(jr.entries))
+	}
+
+	actions.finishJournal(context.Background(), actions.beginJournal(context.Background(), journal.ActionInstall, nil))
+	if jr.begun != 1 || jr.failed != 1 {
+		t.Errorf("transaction without changes must not be marked, got begun=%d failed=%d", jr.begun, jr.failed)
+	}
+}
+
+func TestRepair(t *testing.T) {
+	t.Run("nothing to repair", func(t *testing.T) {
+		apt := &mockAptActions{fixBrokenRes: &aptLib.PackageChanges{}}
+		actions := newTestActions(apt, nil, nil)
+		actions.reporter = reply.NewReporter(actions.appConfig)
+		actions.serviceJournal = &mockJournal{}
+
+		resp, err := actions.Repair(context.Background(), true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if apt.fixBrokenCalled || resp.Changes != nil || len(resp.Duplicates) != 0 {
+			t.Errorf("expected no repair actions, got %+v", resp)
+		}
+	})
+
+	t.Run("finishes updates and fixes dependencies", func(t *testing.T) {
+		apt := &mockAptActions{fixBrokenRes: &aptLib.PackageChanges{RemovedCount: 1, RemovedPackages: []string{"orphan"}}}
+		dup := &mockRpmDup{duplicates: []rpmdup.Duplicate{{Name: "glibc-core", Keep: "glibc-core-2.39-alt1.x86_64", Stale: []string{"glibc-core-2.38-alt1.x86_64"}}}}
+		jr := &mockJournal{interrupted: []journal.Entry{{Action: journal.ActionUpgrade}}}
+		actions := newTestActions(apt, nil, nil)
+		actions.reporter = reply.NewReporter(actions.appConfig)
+		actions.serviceJournal = jr
+		actions.serviceRpmDup = dup
+
+		resp, err := actions.Repair(context.Background(), true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !slices.Equal(dup.finished, []string{"glibc-core"}) {
+			t.Errorf("expected stale glibc-core to be finished, got %v", dup.finished)
+		}
+		if !apt.fixBrokenCalled || resp.Changes == nil {
+			t.Error("expected broken dependencies to be fixed")
+		}
+		if len(resp.Interrupted) != 1 || len(jr.interrupted) != 0 {
+			t.Errorf("expected interrupted transaction to be reported and marked repaired, got %+v", resp.Interrupted)
+		}
+	})
+
+	t.Run("simulation lists stale versions without removing them", func(t *testing.T) {
+		apt := &mockAptActions{fixBrokenRes: &aptLib.PackageChanges{RemovedCount: 1, RemovedPackages: []string{"orphan"}}}
+		dup := &mockRpmDup{duplicates: []rpmdup.Duplicate{{Name: "glibc-core", Keep: "glibc-core-2.39-alt1.x86_64", Stale: []string{"glibc-core-2.38-alt1.x86_64"}}}}
+		actions := newTestActions(apt, nil, nil)
+		actions.serviceRpmDup = dup
+
+		resp, err := actions.CheckRepair(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(dup.finished) != 0 || apt.fixBrokenCalled {
+			t.Error("simulation must not change the system")
+		}
+		if resp.Info.RemovedCount != 2 || !slices.Equal(resp.Info.RemovedPackages, []string{"orphan", "glibc-core-2.38-alt1.x86_64"}) {
+			t.Errorf("expected stale version in planned removals, got %+v", resp.Info)
+		}
+		if !slices.Equal(apt.fixBrokenRes.RemovedPackages, []string{"orphan"}) {
+			t.Errorf("fix-broken changes must not be modified, got %v", apt.fixBrokenRes.RemovedPackages)
+		}
+	})
+}
+
+func TestGetSystemOverview(t *testing.T) {
+	t.Run("collects all sections", func(t *testing.T) {
+		upgraded := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+		apt := &mockAptActions{checkUpgradeRes: &aptLib.PackageChanges{UpgradedCount: 5, NewInstalledCount: 1, DownloadSize: 1024}}
+		actions := newTestActions(apt, nil, nil)
+		actions.serviceJournal = &mockJournal{last: &journal.Entry{Action: journal.ActionUpgrade, Date: upgraded}}
+		actions.serviceKernel = &mockKernelInfo{current: &kservice.Info{Flavour: "6.12", Version: "6.12.10"}}
+		actions.serviceRepos = &mockRepoList{repos: []reposervice.Repository{
+			{URL: "http://ftp.altlinux.org/pub/distributions/ALTLinux/Sisyphus", Components: []string{"classic"}, Active: true, Branch: "sisyphus"},
+			{URL: "http://ftp.altlinux.org/pub/distributions/ALTLinux/Sisyphus", Components: []string{"classic"}, Active: true, Branch: "sisyphus"},
+			{URL: "http://git.altlinux.org/repo/350000/", Components: []string{"task"}, Active: true},
+			{URL: "http://ftp.altlinux.org/pub/distributions/ALTLinux/p11", Components: []string{"classic"}, Branch: "p11"},
+		}}
+
+		resp, err := actions.GetSystemOverview(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resp.Errors) != 0 {
+			t.Fatalf("unexpected section errors: %v", resp.Errors)
+		}
+		if resp.Upgrades == nil || resp.Upgrades.Upgraded != 5 || resp.Upgrades.NewInstalled != 1 || resp.Upgrades.DownloadSize != 1024 {
+			t.Errorf("unexpected upgrades: %+v", resp.Upgrades)
+		}
+		if resp.Kernel == nil || resp.Kernel.Flavour != "6.12" {
+			t.Errorf("unexpected kernel: %+v", resp.Kernel)
+		}
+		want := OverviewRepositories{Total: 4, Active: 3, Tasks: 1, Branch: "sisyphus"}
+		if resp.Repositories != want {
+			t.Errorf("expected repositories %+v, got %+v", want, resp.Repositories)
+		}
+		if resp.LastUpgrade == nil || !resp.LastUpgrade.Equal(upgraded) {
+			t.Errorf("expected last upgrade %v, got %v", upgraded, resp.LastUpgrade)
+		}
+		if resp.Image != nil || resp.HasDistrobox {
+			t.Errorf("expected no image and distrobox sections on non-atomic system without distrobox, got %+v", resp)
+		}
+	})
+
+	t.Run("failed sections are reported without failing the call", func(t *testing.T) {
+		apt := &mockAptActions{checkUpgradeErr: errors.New("lock is held")}
+		actions := newTestActions(apt, nil, nil)
+		actions.serviceJournal = &mockJournal{}
+		actions.serviceKernel = &mockKernelInfo{err: errors.New("uname failed")}
+		actions.appConfig.ConfigManager.GetConfig().ExistDistrobox = true
+		actions.serviceContainers = &mockContainerList{containers: []sandbox.ContainerInfo{{ContainerName: "alt"}, {ContainerName: "arch"}}}
+
+		resp, err := actions.GetSystemOverview(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resp.Errors) != 2 || resp.Upgrades != nil || resp.Kernel != nil {
+			t.Errorf("expected upgrades and kernel sections to fail, got %+v", resp)
+		}
+		if resp.DistroboxContainers != 2 || resp.LastUpgrade != nil {
+			t.Errorf("unexpected distrobox count or last upgrade: %+v", resp)
+		}
+	})
+}
+
+func TestRestartServices(t *testing.T) {
+	services := []restart.Service{
+		{Unit: "dbus.service", PIDs: []int{1}, Blacklisted: true},
+		{Unit: "nginx.service", PIDs: []int{200}},
+		{Unit: "sshd.service", PIDs: []int{100}},
+	}
+
+	t.Run("lists services without auto", func(t *testing.T) {
+		rs := &mockRestart{services: services}
+		actions := newTestActions(nil, nil, nil)
+		actions.serviceRestart = rs
+
+		resp, err := actions.RestartServices(context.Background(), false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resp.Services) != 3 || len(rs.restarted) != 0 {
+			t.Errorf("expected services to be listed only, got %+v, restarted %v", resp, rs.restarted)
+		}
+	})
+
+	t.Run("auto restarts services except blacklisted", func(t *testing.T) {
+		rs := &mockRestart{services: services, failUnit: "nginx.service"}
+		actions := newTestActions(nil, nil, nil)
+		actions.reporter = reply.NewReporter(actions.appConfig)
+		actions.serviceRestart = rs
+
+		resp, err := actions.RestartServices(context.Background(), true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !slices.Equal(resp.Restarted, []string{"sshd.service"}) {
+			t.Errorf("expected sshd.service to be restarted, got %v", resp.Restarted)
+		}
+		if !slices.Equal(resp.Skipped, []string{"dbus.service"}) {
+			t.Errorf("expected dbus.service to be skipped, got %v", resp.Skipped)
+		}
+		if !slices.Equal(resp.Failed, []string{"nginx.service"}) {
+			t.Errorf("expected nginx.service to fail, got %v", resp.Failed)
+		}
+	})
+
+	t.Run("nothing to restart", func(t *testing.T) {
+		actions := newTestActions(nil, nil, nil)
+
+		resp, err := actions.RestartServices(context.Background(), true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resp.Services) != 0 || len(resp.Restarted) != 0 {
+			t.Errorf("expected empty response, got %+v", resp)
+		}
+	})
+}
+
+func TestDBStatus(t *testing.T) {
+	t.Run("unsupported database manager returns error", func(t *testing.T) {
+		actions := newTestActions(nil, nil, nil)
+
+		_, err := actions.DBStatus(context.Background())
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeDatabase)
+	})
+
+	t.Run("reports pending migrations for missing databases", func(t *testing.T) {
+		dir := t.TempDir()
+		actions := newTestActions(nil, nil, nil)
+		actions.appConfig.DatabaseManager = app.NewDatabaseManager(filepath.Join(dir, "system.db"), filepath.Join(dir, "user.db"))
+
+		resp, err := actions.DBStatus(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resp.Databases) != 2 {
+			t.Fatalf("expected 2 databases, got %d", len(resp.Databases))
+		}
+		for _, db := range resp.Databases {
+			if db.Exists || db.Pending == 0 {
+				t.Errorf("expected missing database with pending migrations, got %+v", db)
+			}
+		}
+	})
+}
+
+type mockRelocator struct {
+	*testutil.MockConfigManager
+	relocated []app.DataRelocation
+	called    bool
+}
+
+func (m *mockRelocator) RelocateData() ([]app.DataRelocation, error) {
+	m.called = true
+	return m.relocated, nil
+}
+
+func TestDBRelocate(t *testing.T) {
+	newRelocateActions := func(relocated ...app.DataRelocation) (*Actions, *mockRelocator) {
+		actions := newTestActions(nil, nil, nil)
+		relocator := &mockRelocator{
+			MockConfigManager: actions.appConfig.ConfigManager.(*testutil.MockConfigManager),
+			relocated:         relocated,
+		}
+		actions.appConfig.ConfigManager = relocator
+		return actions, relocator
+	}
+
+	t.Run("moves data when services are stopped", func(t *testing.T) {
+		actions, relocator := newRelocateActions(app.DataRelocation{From: "/var/lib/apm/apm.db", To: "/srv/apm/apm.db"})
+
+		resp, err := actions.DBRelocate(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !relocator.called || len(resp.Relocated) != 1 {
+			t.Errorf("unexpected response: %+v", resp)
+		}
+	})
+
+	t.Run("refuses while the system service is running", func(t *testing.T) {
+		actions, relocator := newRelocateActions(app.DataRelocation{From: "/var/lib/apm/apm.db", To: "/srv/apm/apm.db"})
+		actions.serviceUnits = &mockUnits{active: map[string]bool{units.DBusSystem: true}}
+
+		_, err := actions.DBRelocate(context.Background())
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeBusy)
+		if relocator.called {
+			t.Error("expected data to stay in place")
+		}
+	})
+
+	t.Run("nothing to move", func(t *testing.T) {
+		actions, _ := newRelocateActions()
+
+		_, err := actions.DBRelocate(context.Background())
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNoOperation)
+	})
+}
+
+func TestDiffWatchSnapshots(t *testing.T) {
+	initial := watchSnapshot{upgrades: []string{"vim"}, repositories: []string{"rpm p11 x86_64 classic"}}
+
+	changes := diffWatchSnapshots(nil, initial)
+	if len(changes) != 1 || changes[0].Kind != WatchChangeUpgrades {
+		t.Fatalf("expected only pending upgrades on first check, got %+v", changes)
+	}
+
+	if changes = diffWatchSnapshots(&initial, initial); len(changes) != 0 {
+		t.Errorf("expected no changes for the same state, got %+v", changes)
+	}
+
+	next := watchSnapshot{
+		upgrades:     []string{"firefox", "vim"},
+		kernel:       "6.12.20-un-def-alt1",
+		repositories: []string{"rpm sisyphus x86_64 classic"},
+	}
+	changes = diffWatchSnapshots(&initial, next)
+	if len(changes) != 3 {
+		t.Fatalf("expected 3 changes, got %+v", changes)
+	}
+	if !slices.Equal(changes[0].Added, []string{"firefox"}) || changes[0].Upgrades != 2 {
+		t.Errorf("unexpected upgrades change: %+v", changes[0])
+	}
+	if changes[1].Kind != WatchChangeKernel || changes[1].Kernel != "6.12.20-un-def-alt1" {
+		t.Errorf("unexpected kernel change: %+v", changes[1])
+	}
+	repos := changes[2]
+	if repos.Kind != WatchChangeRepositories ||
+		!slices.Equal(repos.Added, []string{"rpm sisyphus x86_64 classic"}) ||
+		!slices.Equal(repos.Removed, []string{"rpm p11 x86_64 classic"}) {
+		t.Errorf("unexpected repositories change: %+v", repos)
+	}
+}
+
+func TestWatchSnapshot(t *testing.T) {
+	actions := newTestActions(&mockAptActions{
+		checkUpgradeRes: &aptLib.PackageChanges{UpgradedPackages: []string{"vim", "firefox"}},
+	}, nil, nil)
+	actions.serviceKernel = &mockKernelInfo{
+		current: &kservice.Info{Flavour: "6.12", FullVersion: "6.12.10-6.12-alt1"},
+		latest:  &kservice.Info{Flavour: "6.12", FullVersion: "6.12.20-6.12-alt1"},
+	}
+	actions.serviceRepos = &mockRepoList{repos: []reposervice.Repository{{Entry: "rpm p11 x86_64 classic"}}}
+
+	snapshot, err := actions.watchSnapshot(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(snapshot.upgrades, []string{"firefox", "vim"}) {
+		t.Errorf("expected sorted upgrades, got %v", snapshot.upgrades)
+	}
+	if snapshot.kernel != "6.12.20-6.12-alt1" {
+		t.Errorf("expected new kernel, got %q", snapshot.kernel)
+	}
+	if len(snapshot.repositories) != 1 {
+		t.Errorf("expected 1 repository, got %v", snapshot.repositories)
+	}
+
+	actions.serviceKernel = &mockKernelInfo{
+		current: &kservice.Info{Flavour: "6.12"},
+		latest:  &kservice.Info{Flavour: "6.12", IsInstalled: true},
+	}
+	if snapshot, err = actions.watchSnapshot(context.Background()); err != nil || snapshot.kernel != "" {
+		t.Errorf("expected no kernel update for installed kernel, got %q (%v)", snapshot.kernel, err)
+	}
+}
+
+func TestWatchInterval(t *testing.T) {
+	actions := newTestActions(nil, nil, nil)
+	err := actions.Watch(context.Background(), time.Second, func(WatchChange) {})
+	testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+}
+
+func TestClean(t *testing.T) {
+	write := func(t *testing.T, path string, size int) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("cache by default", func(t *testing.T) {
+		dir := t.TempDir()
+		write(t, filepath.Join(dir, "vim-9.0-alt1.x86_64.rpm"), 100)
+		write(t, filepath.Join(dir, "partial", "bash-5.2-alt1.x86_64.rpm"), 50)
+		write(t, filepath.Join(dir, "lock"), 0)
+
+		apt := &mockAptActions{archivesDir: dir}
+		actions := newTestActions(apt, &mockAptDB{orphanedHeld: 2}, nil)
+		resp, err := actions.Clean(context.Background(), false, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if resp.Files != 2 || resp.SizeBefore != 150 || resp.SizeAfter != 0 || resp.Freed != 150 {
+			t.Errorf("unexpected sizes: %+v", resp)
+		}
+		if len(resp.Targets) != 1 || resp.Targets[0].Name != CleanTargetCache {
+			t.Errorf("expected only the APT cache to be cleaned, got %+v", resp.Targets)
+		}
+		if _, err = os.Stat(filepath.Join(dir, "lock")); err != nil {
+			t.Errorf("expected lock file to be kept: %v", err)
+		}
+		if _, err = os.Stat(filepath.Join(dir, "partial")); err != nil {
+			t.Errorf("expected partial directory to be kept: %v", err)
+		}
+		if resp.OrphanedRows != 2 || apt.holdSynced != 1 {
+			t.Errorf("expected orphaned rows removed and preferences synced, got rows=%d synced=%d", resp.OrphanedRows, apt.holdSynced)
+		}
+	})
+
+	t.Run("simulation keeps files", func(t *testing.T) {
+		dir := t.TempDir()
+		write(t, filepath.Join(dir, "vim-9.0-alt1.x86_64.rpm"), 100)
+
+		apt := &mockAptActions{archivesDir: dir}
+		actions := newTestActions(apt, &mockAptDB{orphanedHeld: 2}, nil)
+		resp, err := actions.CheckClean(context.Background(), true, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Files != 1 || resp.Freed != 100 || resp.SizeAfter != 0 {
+			t.Errorf("unexpected sizes: %+v", resp)
+		}
+		if _, err = os.Stat(filepath.Join(dir, "vim-9.0-alt1.x86_64.rpm")); err != nil {
+			t.Errorf("expected package to be kept: %v", err)
+		}
+		if resp.OrphanedRows != 0 || apt.holdSynced != 0 {
+			t.Errorf("simulation must not touch holds, got rows=%d synced=%d", resp.OrphanedRows, apt.holdSynced)
+		}
+	})
+
+	t.Run("holds of installed packages are kept", func(t *testing.T) {
+		apt := &mockAptActions{archivesDir: t.TempDir(), installed: map[string]string{"local": "1.0-alt1"}}
+		aptDB := &mockAptDB{held: []string{"gone", "local", "vim"}, getByNamesResult: []_package.Package{{Name: "vim"}}}
+		actions := newTestActions(apt, aptDB, nil)
+
+		resp, err := actions.CheckClean(context.Background(), true, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.OrphanedRows != 1 {
+			t.Errorf("expected only the hold of the missing package to be counted, got %d", resp.OrphanedRows)
+		}
+
+		if _, err = actions.Clean(context.Background(), true, false); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := aptDB.heldInstalled["local"]; !ok {
+			t.Errorf("expected installed packages to be passed to the cleanup, got %v", aptDB.heldInstalled)
+		}
+	})
+
+	t.Run("holds are kept while the package database is empty", func(t *testing.T) {
+		apt := &mockAptActions{archivesDir: t.TempDir()}
+		aptDB := &mockAptDB{held: []string{"vim"}, orphanedHeld: 1, dbExistErr: errors.New("empty")}
+		actions := newTestActions(apt, aptDB, nil)
+
+		resp, err := actions.Clean(context.Background(), true, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.OrphanedRows != 0 || aptDB.heldInstalled != nil || apt.holdSynced != 0 {
+			t.Errorf("expected holds to be kept, got rows=%d synced=%d", resp.OrphanedRows, apt.holdSynced)
+		}
+	})
+
+	t.Run("downloads keep packages used by the image", func(t *testing.T) {
+		dir := t.TempDir()
+		write(t, filepath.Join(dir, "used.rpm"), 10)
+		write(t, filepath.Join(dir, "stale.rpm"), 30)
+
+		actions := newTestActions(nil, nil, nil)
+		actions.appConfig.ConfigManager.GetConfig().IsAtomic = true
+		actions.serviceLocalRpm = &mockLocalRpm{storePath: dir}
+		actions.serviceHostConfig = &mockHostConfig{config: &build.Config{Image: "current"}}
+		tmp := &temporary.Config{}
+		tmp.Packages.Install = []string{filepath.Join(localrpm.StoreDir, "used.rpm")}
+		actions.serviceTemporaryConfig = &mockTempConfig{config: tmp}
+
+		resp, err := actions.Clean(context.Background(), false, true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
 		}
-		if resp.PackageInfo.Name != "vim" {
-			t.Errorf("expected package vim, got %s", resp.PackageInfo.Name)
+		if resp.Files != 1 || resp.Freed != 30 || resp.SizeAfter != 10 {
+			t.Errorf("unexpected sizes: %+v", resp)
+		}
+		if _, err = os.Stat(filepath.Join(dir, "used.rpm")); err != nil {
+			t.Errorf("expected used package to be kept: %v", err)
+		}
+		if _, err = os.Stat(filepath.Join(dir, "stale.rpm")); !os.IsNotExist(err) {
+			t.Errorf("expected stale package to be removed, got %v", err)
+		}
+	})
+
+	t.Run("downloads are skipped on a regular system", func(t *testing.T) {
+		dir := t.TempDir()
+		write(t, filepath.Join(dir, "stale.rpm"), 30)
+
+		actions := newTestActions(&mockAptActions{archivesDir: t.TempDir()}, nil, nil)
+		actions.serviceLocalRpm = &mockLocalRpm{storePath: dir}
+
+		resp, err := actions.Clean(context.Background(), true, true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resp.Targets) != 2 || resp.Files != 0 {
+			t.Errorf("expected nothing to be removed, got %+v", resp)
+		}
+	})
+}
+
+func TestSyncStplr(t *testing.T) {
+	aptDB := &mockAptDB{}
+	actions := newTestActions(&mockAptActions{installed: map[string]string{"foo": "1.0-1"}}, aptDB, nil)
+	actions.reporter = reply.NewReporter(actions.appConfig)
+	actions.serviceStplr = &mockStplr{catalogue: []stplr.Package{
+		{Repository: "repo", Name: "foo", Version: "1.0-1"},
+		{Repository: "repo", Name: "bar", Version: "2.0-1"},
+	}}
+
+	actions.syncStplr(context.Background())
+	if aptDB.stplrSaved != nil {
+		t.Fatalf("catalogue must not be synced without stplr, got %+v", aptDB.stplrSaved)
+	}
+
+	actions.appConfig.ConfigManager.GetConfig().ExistStplr = true
+	actions.syncStplr(context.Background())
+	if len(aptDB.stplrSaved) != 2 {
+		t.Fatalf("expected 2 STPLR packages, got %+v", aptDB.stplrSaved)
+	}
+	foo, bar := aptDB.stplrSaved[0], aptDB.stplrSaved[1]
+	if foo.TypePackage != int(_package.PackageTypeStplr) || !foo.Installed || foo.VersionInstalled != "1.0-1" {
+		t.Errorf("unexpected installed STPLR package: %+v", foo)
+	}
+	if bar.Installed || bar.Version != "2.0-1" {
+		t.Errorf("unexpected STPLR package: %+v", bar)
+	}
+}
+
+func TestBuildStplrPackages(t *testing.T) {
+	aptDB := &mockAptDB{getByNamesResult: []_package.Package{
+		{Name: "foo", TypePackage: int(_package.PackageTypeStplr)},
+		{Name: "vim", TypePackage: int(_package.PackageTypeSystem)},
+		{Name: "both", TypePackage: int(_package.PackageTypeStplr)},
+		{Name: "both", TypePackage: int(_package.PackageTypeSystem)},
+	}}
+	builder := &mockStplr{buildDir: t.TempDir()}
+	actions := newTestActions(nil, aptDB, nil)
+	actions.serviceStplr = builder
+	actions.appConfig.ConfigManager.GetConfig().ExistStplr = true
+
+	packages, built, cleanup, err := actions.buildStplrPackages(context.Background(), []string{"vim", "foo", "both"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(builder.built, []string{"foo"}) {
+		t.Errorf("only the STPLR-only package must be built, got %v", builder.built)
+	}
+	if len(packages) != 3 || packages[0] != "vim" || !strings.HasSuffix(packages[1], ".rpm") || packages[2] != "both" {
+		t.Fatalf("unexpected packages %v", packages)
+	}
+	if !slices.Equal(built, packages[1:2]) {
+		t.Errorf("expected the built file to be returned, got %v", built)
+	}
+
+	cleanup()
+	if _, err = os.Stat(packages[1]); !os.IsNotExist(err) {
+		t.Errorf("build directory must be removed, stat error: %v", err)
+	}
+}
+
+func TestPrepareLocalPackagesSkipsStplrBuilds(t *testing.T) {
+	dir := t.TempDir()
+	built, local := filepath.Join(dir, "foo-1.0-1.x86_64.rpm"), filepath.Join(dir, "bar.rpm")
+	for _, file := range []string{built, local} {
+		if err := os.WriteFile(file, []byte("rpm"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("only foreign files are verified", func(t *testing.T) {
+		actions := newTestActions(nil, nil, nil)
+		rpms := &mockLocalRpm{}
+		actions.serviceLocalRpm = rpms
+
+		if _, _, err := actions.prepareLocalPackages(context.Background(), []string{built, local}, []string{built}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !slices.Equal(rpms.verified, []string{local}) {
+			t.Errorf("expected only %s verified, got %v", local, rpms.verified)
+		}
+	})
+
+	t.Run("unsigned build is installed", func(t *testing.T) {
+		actions := newTestActions(nil, nil, nil)
+		rpms := &mockLocalRpm{verifyErr: errors.New("not signed")}
+		actions.serviceLocalRpm = rpms
+
+		_, files, err := actions.prepareLocalPackages(context.Background(), []string{built}, []string{built})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(rpms.verified) != 0 || !slices.Equal(files, []string{built}) {
+			t.Errorf("expected no verification, verified %v, files %v", rpms.verified, files)
+		}
+	})
+}
+
+func newTestGroups(t *testing.T) *group.Service {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"virtualization.yaml": "name: Virtualization\npackages: [qemu-kvm, libvirt]\noptional: [virt-viewer]\n",
+		"devel-c.yaml":        "name: Development C/C++\npackages: [gcc, make]\n",
+		"tools.yaml":          "name: Tools\npackages: [make, htop]\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return group.NewService([]string{dir})
+}
+
+func TestGroupList(t *testing.T) {
+	aptDB := &mockAptDB{getByNamesResult: []_package.Package{
+		{Name: "gcc", Installed: true},
+		{Name: "make", Installed: true},
+		{Name: "qemu-kvm", Installed: true},
+		{Name: "libvirt"},
+	}}
+	actions := newTestActions(nil, aptDB, nil)
+	actions.serviceGroups = newTestGroups(t)
+
+	resp, err := actions.GroupList(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Groups) != 3 {
+		t.Fatalf("expected 3 groups, got %+v", resp.Groups)
+	}
+	devel, virt := resp.Groups[0], resp.Groups[2]
+	if !devel.Installed || devel.InstalledCount != 2 {
+		t.Errorf("devel-c must be installed: %+v", devel)
+	}
+	if virt.Installed || virt.InstalledCount != 1 {
+		t.Errorf("virtualization must be partially installed: %+v", virt)
+	}
+}
+
+func TestGroupInfo(t *testing.T) {
+	aptDB := &mockAptDB{getByNamesResult: []_package.Package{
+		{Name: "qemu-kvm", Installed: true},
+		{Name: "libvirt"},
+	}}
+	actions := newTestActions(nil, aptDB, nil)
+	actions.serviceGroups = newTestGroups(t)
+
+	resp, err := actions.GroupInfo(context.Background(), "virtualization")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []GroupPackage{
+		{Name: "qemu-kvm", Available: true, Installed: true},
+		{Name: "libvirt", Available: true},
+		{Name: "virt-viewer", Optional: true},
+	}
+	if !slices.Equal(resp.Packages, want) {
+		t.Errorf("got %+v, want %+v", resp.Packages, want)
+	}
+
+	_, err = actions.GroupInfo(context.Background(), "games")
+	testutil.AssertAPMError(t, err, apmerr.ErrorTypeNotFound)
+}
+
+func TestResolveGroups(t *testing.T) {
+	actions := newTestActions(nil, nil, nil)
+	actions.serviceGroups = newTestGroups(t)
+
+	_, packages, owners, err := actions.resolveGroups(context.Background(), []string{"Development C/C++", "tools", "virtualization"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(packages, []string{"gcc", "make", "htop", "qemu-kvm", "libvirt"}) {
+		t.Errorf("unexpected packages %v", packages)
+	}
+	if owners["make"] != "Development C/C++" || owners["htop"] != "Tools" {
+		t.Errorf("package must belong to the first group that lists it: %v", owners)
+	}
+
+	_, packages, _, _ = actions.resolveGroups(context.Background(), []string{"virtualization"}, true)
+	if !slices.Contains(packages, "virt-viewer") {
+		t.Errorf("optional packages must be included, got %v", packages)
+	}
+
+	_, _, _, err = actions.resolveGroups(context.Background(), nil, false)
+	testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+
+	actions.packageGroups = owners
+	info := []_package.Package{{Name: "gcc"}, {Name: "libgcc"}}
+	actions.markGroups(info)
+	if info[0].Group != "Development C/C++" || info[1].Group != "" {
+		t.Errorf("unexpected group marks: %+v", info)
+	}
+}
+
+func TestGroupRemoveNothingInstalled(t *testing.T) {
+	actions := newTestActions(nil, &mockAptDB{getByNamesResult: []_package.Package{{Name: "htop"}}}, nil)
+	actions.serviceGroups = newTestGroups(t)
+
+	_, err := actions.GroupRemove(context.Background(), []string{"tools"}, true)
+	testutil.AssertAPMError(t, err, apmerr.ErrorTypeNoOperation)
+}
+
+func TestParseBatch(t *testing.T) {
+	actions := newTestActions(nil, nil, nil)
+
+	plan, err := actions.parseBatch(context.Background(), []BatchOperation{
+		{Type: BatchUpdate},
+		{Type: BatchInstall, Packages: []string{"gimp", "vim"}},
+		{Type: BatchRemove, Packages: []string{"zip"}},
+		{Type: BatchInstall, Packages: []string{"vim", "mc"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plan.update || plan.imageApply {
+		t.Errorf("unexpected plan: %+v", plan)
+	}
+	if !slices.Equal(plan.packages, []string{"gimp", "vim", "zip-", "mc"}) {
+		t.Errorf("unexpected packages %v", plan.packages)
+	}
+
+	invalid := map[string][]BatchOperation{
+		"empty":         nil,
+		"unknown":       {{Type: "reboot"}},
+		"no packages":   {{Type: BatchInstall}},
+		"order":         {{Type: BatchInstall, Packages: []string{"vim"}}, {Type: BatchUpdate}},
+		"conflict":      {{Type: BatchInstall, Packages: []string{"vim"}}, {Type: BatchRemove, Packages: []string{"vim"}}},
+		"image on host": {{Type: BatchImageApply}},
+	}
+	for name, ops := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := actions.parseBatch(context.Background(), ops)
+			testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+		})
+	}
+
+	actions.appConfig.ConfigManager.GetConfig().IsAtomic = true
+	plan, err = actions.parseBatch(context.Background(), []BatchOperation{{Type: BatchRemove, Packages: []string{"zip"}}, {Type: BatchImageApply}})
+	if err != nil || !plan.imageApply {
+		t.Errorf("image-apply must be accepted on an atomic system: %+v, %v", plan, err)
+	}
+}
+
+func TestBatchSimulate(t *testing.T) {
+	apt := &mockAptActions{
+		prepareInstall: []string{"gimp"},
+		prepareRemove:  []string{"zip"},
+		findChanges:    &aptLib.PackageChanges{NewInstalledCount: 1, RemovedCount: 1},
+	}
+	actions := newTestActions(apt, &mockAptDB{}, nil)
+
+	resp, err := actions.Batch(context.Background(), []BatchOperation{
+		{Type: BatchUpdate},
+		{Type: BatchInstall, Packages: []string{"gimp"}},
+		{Type: BatchRemove, Packages: []string{"zip"}},
+	}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(apt.prepareArgs, []string{"gimp", "zip-"}) {
+		t.Errorf("install and remove must be checked as one change, got %v", apt.prepareArgs)
+	}
+	if !resp.Simulated || resp.Update != nil || resp.Check == nil || resp.Check.Info.RemovedCount != 1 {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
+// configPathManager конфигурация тестов с реальным файлом для apm config edit
+type configPathManager struct {
+	testutil.MockConfigManager
+	path string
+}
+
+func (m *configPathManager) GetConfigPath() string { return m.path }
+
+func TestConfigSetGet(t *testing.T) {
+	actions := newTestActions(nil, nil, nil)
+	defer network.Configure(app.NetworkSettings{})
+
+	resp, err := actions.ConfigSet(context.Background(), "network.LIMIT", " 2M ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Values["network.limit"] != "2M" {
+		t.Errorf("unexpected values %v", resp.Values)
+	}
+	if network.Current().Limit != "2M" {
+		t.Errorf("network settings not applied: %+v", network.Current())
+	}
+
+	resp, err = actions.ConfigSet(context.Background(), "kernel.autoSwitch", "false")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Values["kernel.autoSwitch"] != "false" {
+		t.Errorf("unexpected values %v", resp.Values)
+	}
+
+	resp, err = actions.ConfigList(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Values) != len(configKeys) {
+		t.Errorf("expected all keys, got %v", resp.Values)
+	}
+
+	for key, value := range map[string]string{
+		"network.httpProxy":    "ftp://proxy",
+		"formatType":           "json",
+		"watchInterval":        "0",
+		"modules.distrobox":    "maybe",
+		"httpListen":           "8080",
+		"httpSocket.mode":      "999",
+		"atomicMode":           "yes",
+		"network.limit":        "fast",
+		"nonInteractiveAnswer": "always",
+	} {
+		_, err = actions.ConfigSet(context.Background(), key, value)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+	}
+
+	_, err = actions.ConfigGet(context.Background(), "network.unknown")
+	testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+}
+
+func TestConfigUnset(t *testing.T) {
+	actions := newTestActions(nil, nil, nil)
+	actions.appConfig.ConfigManager.GetConfig().WatchInterval = 5
+
+	resp, err := actions.ConfigUnset(context.Background(), "watchInterval")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Values["watchInterval"] != "60" {
+		t.Errorf("expected default value, got %v", resp.Values)
+	}
+}
+
+func TestConfigEdit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(path, []byte("formatType: tree\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	actions := newTestActions(nil, nil, nil)
+	actions.appConfig = &app.Config{ConfigManager: &configPathManager{
+		MockConfigManager: testutil.MockConfigManager{Config: &app.Configuration{}},
+		path:              path,
+	}}
+
+	original := runEditor
+	defer func() { runEditor = original }()
+
+	runEditor = func(file string) error {
+		return os.WriteFile(file, []byte("formatType: bogus\n"), 0644)
+	}
+	_, err := actions.ConfigEdit(context.Background())
+	testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+	if data, _ := os.ReadFile(path); string(data) != "formatType: tree\n" {
+		t.Errorf("invalid configuration must not be saved, got %q", data)
+	}
+
+	runEditor = func(string) error { return nil }
+	_, err = actions.ConfigEdit(context.Background())
+	testutil.AssertAPMError(t, err, apmerr.ErrorTypeNoOperation)
+
+	runEditor = func(file string) error {
+		return os.WriteFile(file, []byte("formatType: plain\nnetwork:\n  limit: 1M\n"), 0644)
+	}
+	defer network.Configure(app.NetworkSettings{})
+	resp, err := actions.ConfigEdit(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Values["formatType"] != "plain" || resp.Values["network.limit"] != "1M" {
+		t.Errorf("unexpected values %v", resp.Values)
+	}
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), "limit: 1M") {
+		t.Errorf("configuration not saved: %q", data)
+	}
+}
+
+func TestServiceEnableDisable(t *testing.T) {
+	actions := newTestActions(nil, nil, nil)
+
+	resp, err := actions.ServiceEnable(context.Background(), units.HTTPServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Service.Enabled {
+		t.Errorf("expected enabled service, got %+v", resp.Service)
+	}
+
+	if _, err = actions.ServiceDisable(context.Background(), units.HTTPServer); err != nil {
+		t.Fatal(err)
+	}
+	_, err = actions.ServiceDisable(context.Background(), units.HTTPServer)
+	testutil.AssertAPMError(t, err, apmerr.ErrorTypeNoOperation)
+}
+
+func TestServiceStatusAll(t *testing.T) {
+	actions := newTestActions(nil, nil, nil)
+	if _, err := actions.ServiceEnable(context.Background(), units.AutoUpgrade); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := actions.ServiceStatus(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, s := range resp.Services {
+		names = append(names, s.Name)
+		if s.Name == units.AutoUpgrade && !s.Enabled {
+			t.Errorf("expected enabled auto-upgrade timer, got %+v", s)
+		}
+	}
+	expected := []string{units.DBusSystem, units.DBusSession, units.HTTPServer, units.AutoUpgrade}
+	if !slices.Equal(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+}
+
+func TestStateSignals(t *testing.T) {
+	var signals []string
+	actions := newTestActions(nil, nil, nil)
+	actions.stateNotifier = func(signal string, args ...interface{}) {
+		signals = append(signals, signal)
+	}
+
+	actions.notifyPackagesChanged(journal.ActionInstall, &aptLib.PackageChanges{NewInstalledPackages: []string{"vim"}})
+	actions.notifyPackagesChanged(journal.ActionInstall, nil)
+	actions.recordOperation(context.Background(), journal.Entry{Module: journal.ModuleImage, Action: journal.ActionApply}, nil)
+	actions.recordOperation(context.Background(), journal.Entry{Module: journal.ModuleImage, Action: journal.ActionUpdate}, errors.New("pull failed"))
+
+	expected := []string{app.PackagesChangedSignal, app.ImagePendingSignal}
+	if !slices.Equal(signals, expected) {
+		t.Errorf("expected %v, got %v", expected, signals)
+	}
+}
+
+func TestFormatUpgradableOutput(t *testing.T) {
+	actions := newTestActions(nil, nil, nil)
+
+	result := actions.FormatUpgradableOutput([]_package.Package{{Name: "vim", VersionInstalled: "9.0", VersionInstalledRaw: "2:9.0-alt1",
+		Version: "9.0", VersionRaw: "2:9.0-alt2", Size: 1572864}})
+	if len(result) != 1 || result[0].VersionInstalled != "2:9.0-alt1" || result[0].Version != "2:9.0-alt2" || result[0].Size != "1.5 MB" {
+		t.Errorf("unexpected upgradable output: %+v", result)
+	}
+}
+
+func TestResolveSelfUpdateSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		channel string
+		task    string
+		testing string
+		want    string
+		wantErr bool
+	}{
+		{name: "task overrides channel", channel: SelfUpdateChannelStable, task: " 350000 ", want: "350000"},
+		{name: "stable uses configured repositories", channel: SelfUpdateChannelStable, testing: "sisyphus", want: ""},
+		{name: "testing uses configured source", channel: SelfUpdateChannelTesting, testing: "sisyphus", want: "sisyphus"},
+		{name: "testing without source", channel: SelfUpdateChannelTesting, wantErr: true},
+		{name: "unknown channel", channel: "nightly", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveSelfUpdateSource(context.Background(), tt.channel, tt.task, tt.testing)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got source %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("source = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelfUpdate(t *testing.T) {
+	newSelfUpdateActions := func(t *testing.T) (*Actions, *mockAptActions, *mockRepoList, *mockRestart) {
+		apt := &mockAptActions{
+			info:        &aptLib.PackageInfo{Name: selfPackageName, Version: "0.5.0"},
+			installed:   map[string]string{selfPackageName: "0.4.0"},
+			findChanges: &aptLib.PackageChanges{UpgradedCount: 1, UpgradedPackages: []string{selfPackageName}},
+		}
+		repos := &mockRepoList{sandbox: &reposervice.Sandbox{
+			Dir:       t.TempDir(),
+			Overrides: map[string]string{"Dir::Etc::sourcelist": "/tmp/sandbox/sources.list"},
+		}}
+		rs := &mockRestart{}
+		actions := newTestActions(apt, nil, nil)
+		actions.reporter = reply.NewReporter(actions.appConfig)
+		actions.appConfig.ConfigManager.(*testutil.MockConfigManager).Version = &version.Version{Value: "0.4.0"}
+		actions.serviceRepos = repos
+		actions.serviceRestart = rs
+		return actions, apt, repos, rs
+	}
+
+	t.Run("installs the update and restarts apm services", func(t *testing.T) {
+		actions, apt, repos, rs := newSelfUpdateActions(t)
+
+		resp, err := actions.SelfUpdate(context.Background(), SelfUpdateChannelStable, "", false, true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !resp.Updated || resp.CurrentVersion != "0.4.0" || resp.AvailableVersion != "0.5.0" {
+			t.Errorf("unexpected response: %+v", resp)
+		}
+		if !apt.combineCalled {
+			t.Error("expected apm to be installed")
+		}
+		if rs.selfUnit != selfSystemdService {
+			t.Errorf("restarted unit = %q, want %q", rs.selfUnit, selfSystemdService)
 		}
+		if len(rs.selfArgv) != 2 || rs.selfArgv[1] != units.DBusSession {
+			t.Errorf("session command = %v", rs.selfArgv)
+		}
+		if repos.sandboxArgs != nil || apt.combineConfig != nil {
+			t.Errorf("stable channel must use the system sources: sandbox=%v config=%v", repos.sandboxArgs, apt.combineConfig)
+		}
+	})
+
+	t.Run("installs from the task in a sandbox", func(t *testing.T) {
+		actions, apt, repos, _ := newSelfUpdateActions(t)
+
+		if _, err := actions.SelfUpdate(context.Background(), "", "350000", false, true); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !slices.Equal(repos.sandboxArgs, []string{"350000"}) {
+			t.Errorf("sandbox args = %v", repos.sandboxArgs)
+		}
+		if apt.combineConfig["Dir::Etc::sourcelist"] != "/tmp/sandbox/sources.list" {
+			t.Errorf("install must run with the sandbox config, got %v", apt.combineConfig)
+		}
+		if apt.overrides != nil {
+			t.Errorf("APT config must be restored, got %v", apt.overrides)
+		}
+		if _, err := os.Stat(repos.sandbox.Dir); !os.IsNotExist(err) {
+			t.Errorf("sandbox directory must be removed, stat error: %v", err)
+		}
+	})
+
+	t.Run("check keeps the APT config", func(t *testing.T) {
+		actions, apt, repos, _ := newSelfUpdateActions(t)
+		apt.overrides = map[string]string{"Acquire::Retries": "3"}
+
+		resp, err := actions.CheckSelfUpdate(context.Background(), "", "350000")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Updated || apt.combineCalled {
+			t.Errorf("check changed the system: %+v", resp)
+		}
+		if len(repos.sandboxArgs) != 1 || !maps.Equal(apt.overrides, map[string]string{"Acquire::Retries": "3"}) {
+			t.Errorf("sandbox=%v overrides=%v", repos.sandboxArgs, apt.overrides)
+		}
+	})
+
+	t.Run("sandbox error", func(t *testing.T) {
+		actions, apt, repos, _ := newSelfUpdateActions(t)
+		repos.sandboxErr = errors.New("bad source")
+
+		_, err := actions.SelfUpdate(context.Background(), "", "350000", false, true)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeRepository)
+		if apt.aptUpdated != 0 {
+			t.Errorf("aptUpdated = %d, want 0", apt.aptUpdated)
+		}
+	})
+
+	t.Run("simulation does not install", func(t *testing.T) {
+		actions, apt, _, rs := newSelfUpdateActions(t)
+
+		resp, err := actions.CheckSelfUpdate(context.Background(), SelfUpdateChannelStable, "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Updated || apt.combineCalled || rs.selfUnit != "" {
+			t.Errorf("simulation changed the system: %+v", resp)
+		}
+		if resp.Message != app.T_("Simulation results") {
+			t.Errorf("message = %q", resp.Message)
+		}
+	})
+
+	t.Run("already up to date", func(t *testing.T) {
+		actions, apt, _, _ := newSelfUpdateActions(t)
+		apt.findChanges = &aptLib.PackageChanges{}
+
+		_, err := actions.SelfUpdate(context.Background(), SelfUpdateChannelStable, "", false, true)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNoOperation)
+		if apt.combineCalled {
+			t.Error("expected no installation")
+		}
+	})
+
+	t.Run("package missing from repositories", func(t *testing.T) {
+		actions, apt, _, _ := newSelfUpdateActions(t)
+		apt.infoErr = errors.New("package not found")
+
+		_, err := actions.SelfUpdate(context.Background(), SelfUpdateChannelStable, "", false, true)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeNotFound)
+	})
+
+	t.Run("atomic system is updated with the image", func(t *testing.T) {
+		actions, _, _, _ := newSelfUpdateActions(t)
+		actions.appConfig.ConfigManager.GetConfig().IsAtomic = true
+
+		_, err := actions.SelfUpdate(context.Background(), SelfUpdateChannelStable, "", false, true)
+		testutil.AssertAPMError(t, err, apmerr.ErrorTypeImage)
 	})
 }
+
+func TestSkipConflictingPackages(t *testing.T) {
+	apt := &mockAptActions{findByPackage: map[string]*aptLib.PackageChanges{
+		"foo":  {NewInstalledPackages: []string{"foo", "libfoo"}},
+		"bar":  {NewInstalledPackages: []string{"bar"}, UpgradedPackages: []string{"libbar"}},
+		"baz":  {NewInstalledPackages: []string{"baz"}},
+		"qux":  {NewInstalledPackages: []string{"qux"}},
+		"quux": {NewInstalledPackages: []string{"quux"}},
+	}}
+	actions := newTestActions(apt, nil, nil)
+
+	conflicts := []aptCommon.FileConflict{
+		{Path: "/usr/lib64/libfoo.so.1", Package: "libfoo-1.0-alt1.x86_64", ConflictsWith: "libfoo-compat-1.0-alt1.x86_64", Installed: true},
+		{Path: "/usr/lib64/libbar.so.2", Package: "libbar-2.0-alt1.x86_64", ConflictsWith: "libbar1-1.0-alt1.x86_64", Installed: true},
+		{Path: "/usr/bin/qux", Package: "qux-1.0-alt1.x86_64", ConflictsWith: "quux-1.0-alt1.x86_64"},
+	}
+
+	got := actions.skipConflictingPackages(context.Background(), []string{"foo", "bar", "baz", "qux", "quux"}, conflicts)
+	want := []string{"baz", "quux"}
+	if !slices.Equal(got, want) {
+		t.Errorf("install = %v, want %v", got, want)
+	}
+}
+
+func TestMutatingActionsTakeOperationLock(t *testing.T) {
+	actions := newTestActions(nil, nil, nil)
+	actions.reporter = reply.NewReporter(actions.appConfig)
+	lockPath := filepath.Join(t.TempDir(), "apm.lock")
+	actions.operationLock = oplock.New(lockPath)
+
+	// Блокировку держит другой процесс apm
+	_, release, err := oplock.New(lockPath).Acquire(context.Background(), journal.ActionInstall)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	calls := map[string]func() error{
+		"hold":         func() error { _, err := actions.Hold(context.Background(), []string{"vim"}); return err },
+		"unhold":       func() error { _, err := actions.Unhold(context.Background(), []string{"vim"}); return err },
+		"image update": func() error { _, err := actions.ImageUpdate(context.Background(), false); return err },
+		"image apply":  func() error { _, err := actions.ImageApply(context.Background(), false, false, "", "", ""); return err },
+	}
+	// Системную базу обслуживает только root, база пользователя обходится без общей блокировки
+	if os.Geteuid() == 0 {
+		calls["db migrate"] = func() error { _, err := actions.DBMigrate(context.Background()); return err }
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			testutil.AssertAPMError(t, call(), apmerr.ErrorTypeBusy)
+		})
+	}
+}
+
+func TestUserDatabaseMaintenanceSkipsOperationLock(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("the system database is maintained as root")
+	}
+
+	actions := newTestActions(nil, nil, nil)
+	// Файл блокировки недоступен, как /run/apm.lock для обычного пользователя
+	actions.operationLock = oplock.New(filepath.Join(t.TempDir(), "missing", "apm.lock"))
+
+	ctx, release, err := actions.lockDatabases(context.Background(), journal.ActionVacuum)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	release()
+	if ctx == nil {
+		t.Error("expected the caller context to be returned")
+	}
+}
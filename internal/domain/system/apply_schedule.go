@@ -0,0 +1,112 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/domain/system/schedule"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+// ImageApplySchedule откладывает применение образа до выключения, простоя системы или указанного времени
+func (a *Actions) ImageApplySchedule(ctx context.Context, at string, pullImage bool, hostCache bool, configPath, workdir string) (*ImageApplyScheduleResponse, error) {
+	mode, clock, err := schedule.ParseAt(at)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, err)
+	}
+
+	if err = a.serviceHostConfig.ApplyPathOverrides(configPath, workdir); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+
+	if err = a.serviceHostConfig.LoadConfig(); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+
+	if err = a.serviceHostConfig.GetConfig().CheckImage(); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+
+	s := schedule.Schedule{
+		Mode:      mode,
+		At:        clock,
+		Pull:      pullImage,
+		HostCache: hostCache,
+	}
+	if configPath != "" {
+		if s.Config, err = filepath.Abs(configPath); err != nil {
+			return nil, apmerr.New(apmerr.ErrorTypeValidation, err)
+		}
+	}
+	if workdir != "" {
+		if s.Workdir, err = filepath.Abs(workdir); err != nil {
+			return nil, apmerr.New(apmerr.ErrorTypeValidation, err)
+		}
+	}
+
+	if err = a.serviceSchedule.Create(ctx, s); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+
+	return &ImageApplyScheduleResponse{
+		Message:  fmt.Sprintf(app.TL_(ctx, "Image apply scheduled %s"), s.Describe()),
+		Schedule: &s,
+	}, nil
+}
+
+// ImageApplyCancelScheduled отменяет отложенное применение образа
+func (a *Actions) ImageApplyCancelScheduled(ctx context.Context) (*ImageApplyScheduleResponse, error) {
+	canceled, err := a.serviceSchedule.Cancel(ctx)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+	if canceled == nil {
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.TL_(ctx, "No image apply is scheduled")))
+	}
+
+	return &ImageApplyScheduleResponse{
+		Message:  app.TL_(ctx, "Scheduled image apply canceled"),
+		Schedule: canceled,
+	}, nil
+}
+
+// ImageApplyRunScheduled выполняет отложенное применение, вызывается юнитом systemd
+func (a *Actions) ImageApplyRunScheduled(ctx context.Context) (*ImageApplyResponse, error) {
+	s, err := a.serviceSchedule.Load()
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+	if s == nil {
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.TL_(ctx, "No image apply is scheduled")))
+	}
+
+	if s.Mode == schedule.ModeIdle && !a.serviceSchedule.IsIdle(ctx) {
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.TL_(ctx, "The system is in use, scheduled image apply postponed")))
+	}
+
+	a.serviceSchedule.Notify(ctx, app.TL_(ctx, "APM: starting scheduled system image apply. Please do not power off the computer"))
+
+	if err = a.serviceSchedule.Finish(ctx); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+
+	return a.ImageApply(ctx, s.Pull, s.HostCache, s.Config, s.Workdir, "")
+}
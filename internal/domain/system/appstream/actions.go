@@ -55,11 +55,11 @@ func (a *Actions) Update(ctx context.Context) (*UpdateResponse, error) {
 
 	pkgMap, err := a.swCatService.Load(ctx)
 	if err != nil {
-		return nil, apmerr.New(apmerr.ErrorTypeDatabase, fmt.Errorf(app.T_("Failed to load application data: %w"), err))
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, fmt.Errorf(app.TL_(ctx, "Failed to load application data: %w"), err))
 	}
 
 	if err = a.dbService.SaveComponentsToDB(ctx, pkgMap); err != nil {
-		return nil, apmerr.New(apmerr.ErrorTypeDatabase, fmt.Errorf(app.T_("Failed to save application data to database: %w"), err))
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, fmt.Errorf(app.TL_(ctx, "Failed to save application data to database: %w"), err))
 	}
 
 	if err = a.pkgDBService.UpdateAppStreamLinks(ctx); err != nil {
@@ -67,7 +67,7 @@ func (a *Actions) Update(ctx context.Context) (*UpdateResponse, error) {
 	}
 
 	return &UpdateResponse{
-		Message: app.T_("Application data updated successfully"),
+		Message: app.TL_(ctx, "Application data updated successfully"),
 		Count:   len(pkgMap),
 	}, nil
 }
@@ -76,7 +76,7 @@ func (a *Actions) Update(ctx context.Context) (*UpdateResponse, error) {
 func (a *Actions) validateDB(ctx context.Context) error {
 	if err := a.dbService.DatabaseExist(ctx); err != nil {
 		if syscall.Geteuid() != 0 {
-			return apmerr.New(apmerr.ErrorTypePermission, errors.New(app.T_("applications database is empty. Run 'apm system application update' with elevated rights to create it")))
+			return apmerr.New(apmerr.ErrorTypePermission, errors.New(app.TL_(ctx, "applications database is empty. Run 'apm system application update' with elevated rights to create it")))
 		}
 		if _, updateErr := a.Update(ctx); updateErr != nil {
 			return updateErr
@@ -93,11 +93,11 @@ func (a *Actions) Info(ctx context.Context, pkgname string) (*InfoResponse, erro
 
 	components, err := a.dbService.GetByPkgName(ctx, pkgname)
 	if err != nil {
-		return nil, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.T_("Application data not found for package: %s"), pkgname))
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.TL_(ctx, "Application data not found for package: %s"), pkgname))
 	}
 
 	return &InfoResponse{
-		Message:    fmt.Sprintf(app.T_("Application info for %s"), pkgname),
+		Message:    fmt.Sprintf(app.TL_(ctx, "Application info for %s"), pkgname),
 		PkgName:    pkgname,
 		Components: components,
 	}, nil
@@ -119,7 +119,7 @@ func (a *Actions) List(ctx context.Context, params ListParams) (*ListResponse, e
 		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
 	}
 
-	msg := fmt.Sprintf(app.TN_("%d record found", "%d records found", len(components)), len(components))
+	msg := fmt.Sprintf(app.TLN_(ctx, "%d record found", "%d records found", len(components)), len(components))
 
 	return &ListResponse{
 		Message:    msg,
@@ -140,7 +140,7 @@ func (a *Actions) Categories(ctx context.Context) (*CategoriesResponse, error) {
 	}
 
 	return &CategoriesResponse{
-		Message:    fmt.Sprintf(app.TN_("%d category found", "%d categories found", len(categories)), len(categories)),
+		Message:    fmt.Sprintf(app.TLN_(ctx, "%d category found", "%d categories found", len(categories)), len(categories)),
 		Categories: categories,
 	}, nil
 }
@@ -0,0 +1,201 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package appstream
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/filter"
+	"apm/internal/common/swcat"
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// appTypes типы компонентов AppStream, которые показываются в каталоге приложений
+var appTypes = []string{"desktop-application", "desktop", "console-application", "web-application"}
+
+// AppList возвращает каталог приложений: каждый компонент AppStream отдельной записью
+// с пакетом, которому он принадлежит. Фильтры по категории и ключевому слову не зависят от регистра.
+func (a *Actions) AppList(ctx context.Context, params AppListParams) (*AppListResponse, error) {
+	if err := a.validateDB(ctx); err != nil {
+		return nil, err
+	}
+
+	var filters []filter.Filter
+	if params.Category != "" {
+		filters = append(filters, filter.Filter{Field: "components.categories", Op: filter.OpContains, Value: params.Category})
+	}
+	if params.Keyword != "" {
+		filters = append(filters, filter.Filter{Field: "components.keywords", Op: filter.OpContains, Value: params.Keyword})
+	}
+
+	rows, err := a.dbService.QueryComponents(ctx, filters, "pkgname", "ASC", 0, 0)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+
+	apps := collectApps(rows, params.Category, params.Keyword, app.GetSystemLocale().String())
+	a.fillPackageState(ctx, apps)
+
+	total := len(apps)
+	apps = paginate(apps, params.Limit, params.Offset)
+
+	return &AppListResponse{
+		Message:    fmt.Sprintf(app.TLN_(ctx, "%d application found", "%d applications found", len(apps)), len(apps)),
+		Apps:       apps,
+		TotalCount: total,
+	}, nil
+}
+
+// AppInfo возвращает приложение по идентификатору компонента AppStream вместе с полными метаданными.
+func (a *Actions) AppInfo(ctx context.Context, id string) (*AppInfoResponse, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.TL_(ctx, "You must specify the application ID, for example `%s org.gnome.Calculator`"), "apps info"))
+	}
+
+	if err := a.validateDB(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := a.dbService.QueryComponents(ctx, []filter.Filter{{Field: "components.id", Op: filter.OpEq, Value: id}}, "", "", 0, 0)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+
+	lang := app.GetSystemLocale().String()
+	for _, row := range rows {
+		for _, component := range row.Components {
+			if component.ID != id {
+				continue
+			}
+			apps := []App{newApp(row.PkgName, component, lang)}
+			a.fillPackageState(ctx, apps)
+			return &AppInfoResponse{
+				Message:   fmt.Sprintf(app.TL_(ctx, "Application %s from package %s"), apps[0].Name, row.PkgName),
+				App:       apps[0],
+				Component: component,
+			}, nil
+		}
+	}
+
+	return nil, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.TL_(ctx, "Application %s not found"), id))
+}
+
+// fillPackageState дополняет приложения версией и признаком установки пакета
+func (a *Actions) fillPackageState(ctx context.Context, apps []App) {
+	names := make([]string, 0, len(apps))
+	for _, item := range apps {
+		if !slices.Contains(names, item.Package) {
+			names = append(names, item.Package)
+		}
+	}
+
+	packages, err := a.pkgDBService.GetPackagesByNames(ctx, names)
+	if err != nil {
+		app.Log.Debugf("failed to get packages for applications: %v", err)
+		return
+	}
+
+	for _, pkg := range packages {
+		for i := range apps {
+			if apps[i].Package == pkg.Name {
+				apps[i].Version = pkg.Version
+				apps[i].Installed = pkg.Installed
+			}
+		}
+	}
+}
+
+// collectApps разворачивает записи пакетов в список приложений, отбрасывая компоненты,
+// которые не являются приложениями или не подходят под фильтры, и сортирует их по названию
+func collectApps(rows []swcat.DBAppStream, category, keyword, lang string) []App {
+	var apps []App
+	for _, row := range rows {
+		for _, component := range row.Components {
+			if !slices.Contains(appTypes, component.Type) {
+				continue
+			}
+			if category != "" && !slices.ContainsFunc(component.Categories, func(c string) bool { return strings.EqualFold(c, category) }) {
+				continue
+			}
+			if keyword != "" && !slices.ContainsFunc(component.Keywords, func(k swcat.Keyword) bool { return strings.EqualFold(k.Value, keyword) }) {
+				continue
+			}
+			apps = append(apps, newApp(row.PkgName, component, lang))
+		}
+	}
+
+	sort.SliceStable(apps, func(i, j int) bool {
+		left, right := strings.ToLower(apps[i].Name), strings.ToLower(apps[j].Name)
+		if left != right {
+			return left < right
+		}
+		return apps[i].ID < apps[j].ID
+	})
+
+	return apps
+}
+
+// newApp собирает запись каталога из компонента AppStream
+func newApp(pkgName string, component swcat.Component, lang string) App {
+	item := App{
+		ID:          component.ID,
+		Type:        component.Type,
+		Name:        localized(component.Name, lang),
+		Summary:     localized(component.Summary, lang),
+		Package:     pkgName,
+		Categories:  component.Categories,
+		Icons:       component.Icons,
+		Screenshots: component.Screenshots,
+	}
+	for _, keyword := range component.Keywords {
+		item.Keywords = append(item.Keywords, keyword.Value)
+	}
+	return item
+}
+
+// localized возвращает перевод для языка lang, иначе непереведённое значение
+func localized(values swcat.LocalizedMap, lang string) string {
+	fallback := ""
+	for _, text := range values {
+		if text.Lang == lang || strings.HasPrefix(text.Lang, lang+"_") {
+			return text.Value
+		}
+		if text.Lang == "" || fallback == "" {
+			fallback = text.Value
+		}
+	}
+	return fallback
+}
+
+// paginate возвращает страницу списка приложений. Нулевой limit означает все записи.
+func paginate(apps []App, limit, offset int) []App {
+	if offset > 0 {
+		if offset >= len(apps) {
+			return []App{}
+		}
+		apps = apps[offset:]
+	}
+	if limit > 0 && limit < len(apps) {
+		apps = apps[:limit]
+	}
+	return apps
+}
@@ -0,0 +1,52 @@
+package appstream
+
+import (
+	"apm/internal/common/swcat"
+	"testing"
+)
+
+func TestCollectApps(t *testing.T) {
+	rows := []swcat.DBAppStream{
+		{PkgName: "gnome-calculator", Components: []swcat.Component{
+			{Type: "desktop-application", ID: "org.gnome.Calculator", Categories: []string{"Utility"},
+				Name: swcat.LocalizedMap{{Value: "Calculator"}, {Lang: "ru", Value: "Калькулятор"}}},
+		}},
+		{PkgName: "supertux", Components: []swcat.Component{
+			{Type: "desktop-application", ID: "org.supertuxproject.SuperTux", Categories: []string{"Game"},
+				Keywords: swcat.KeywordList{{Value: "platformer"}}, Name: swcat.LocalizedMap{{Value: "SuperTux"}}},
+			{Type: "addon", ID: "org.supertuxproject.SuperTux.addon", Categories: []string{"Game"}},
+		}},
+	}
+
+	apps := collectApps(rows, "", "", "ru")
+	if len(apps) != 2 || apps[0].Name != "SuperTux" || apps[1].Name != "Калькулятор" {
+		t.Fatalf("expected two applications sorted by localized name, got %+v", apps)
+	}
+	if apps[0].Package != "supertux" || len(apps[0].Keywords) != 1 {
+		t.Errorf("unexpected application: %+v", apps[0])
+	}
+
+	if apps = collectApps(rows, "game", "", "en"); len(apps) != 1 || apps[0].ID != "org.supertuxproject.SuperTux" {
+		t.Errorf("expected only the game without addon, got %+v", apps)
+	}
+	if apps = collectApps(rows, "", "Platformer", "en"); len(apps) != 1 {
+		t.Errorf("expected keyword match, got %+v", apps)
+	}
+	if apps = collectApps(rows, "", "", "en"); apps[0].Name != "Calculator" {
+		t.Errorf("expected unlocalized name fallback, got %+v", apps)
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	apps := []App{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+
+	if page := paginate(apps, 2, 1); len(page) != 2 || page[0].ID != "b" {
+		t.Errorf("unexpected page: %+v", page)
+	}
+	if page := paginate(apps, 0, 0); len(page) != 3 {
+		t.Errorf("expected all records without limit, got %+v", page)
+	}
+	if page := paginate(apps, 10, 5); len(page) != 0 {
+		t.Errorf("expected empty page beyond the end, got %+v", page)
+	}
+}
@@ -56,7 +56,7 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) []*cli.Command
 			ArgsUsage: "<package_name>",
 			Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
 				if cmd.Args().Len() == 0 {
-					return reporter.CliResponse(ctx, newErrorResponseFromError(errors.New(app.T_("Package name is required"))))
+					return reporter.CliResponse(ctx, newErrorResponseFromError(errors.New(app.TL_(ctx, "Package name is required"))))
 				}
 				resp, err := actions.Info(ctx, cmd.Args().First())
 				if err != nil {
@@ -128,3 +128,59 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) []*cli.Command
 		},
 	}
 }
+
+// AppsCommandList возвращает CLI-подкоманды каталога приложений.
+func AppsCommandList(appConfig *app.Config, reporter *reply.Reporter) []*cli.Command {
+	withGlobalWrapper := apmcli.WithOptions(appConfig, reporter, apmcli.NoRootCheck, NewActions, newErrorResponseFromError)
+
+	return []*cli.Command{
+		{
+			Name:  "list",
+			Usage: app.T_("List applications from the catalogue"),
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "category",
+					Usage: app.T_("Only applications of the category, for example Game"),
+				},
+				&cli.StringFlag{
+					Name:  "keyword",
+					Usage: app.T_("Only applications with the keyword"),
+				},
+				&cli.IntFlag{
+					Name:  "limit",
+					Usage: app.T_("Maximum number of records to return"),
+					Value: 10,
+				},
+				&cli.IntFlag{
+					Name:  "offset",
+					Usage: app.T_("Starting position (offset) for the result set"),
+					Value: 0,
+				},
+			},
+			Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+				resp, err := actions.AppList(ctx, AppListParams{
+					Category: cmd.String("category"),
+					Keyword:  cmd.String("keyword"),
+					Limit:    cmd.Int("limit"),
+					Offset:   cmd.Int("offset"),
+				})
+				if err != nil {
+					return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+				}
+				return reporter.CliResponse(ctx, reply.OK(resp))
+			}),
+		},
+		{
+			Name:      "info",
+			Usage:     app.T_("Show application with icons, screenshots and the owning package"),
+			ArgsUsage: "<application_id>",
+			Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+				resp, err := actions.AppInfo(ctx, cmd.Args().First())
+				if err != nil {
+					return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+				}
+				return reporter.CliResponse(ctx, reply.OK(resp))
+			}),
+		},
+	}
+}
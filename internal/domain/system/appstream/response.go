@@ -63,3 +63,40 @@ type CategoriesResponse struct {
 
 // FilterFieldsAppStreamResponse структура ответа для GetFilterFields.
 type FilterFieldsAppStreamResponse []filter.FieldInfo
+
+// App приложение каталога AppStream вместе с пакетом, которому оно принадлежит.
+type App struct {
+	ID          string             `json:"id"`
+	Type        string             `json:"type"`
+	Name        string             `json:"name"`
+	Summary     string             `json:"summary"`
+	Package     string             `json:"package"`
+	Version     string             `json:"version,omitempty"`
+	Installed   bool               `json:"installed"`
+	Categories  []string           `json:"categories,omitempty"`
+	Keywords    []string           `json:"keywords,omitempty"`
+	Icons       []swcat.Icon       `json:"icons,omitempty"`
+	Screenshots []swcat.Screenshot `json:"screenshots,omitempty"`
+}
+
+// AppListParams параметры запроса каталога приложений.
+type AppListParams struct {
+	Category string `json:"category"`
+	Keyword  string `json:"keyword"`
+	Limit    int    `json:"limit"`
+	Offset   int    `json:"offset"`
+}
+
+// AppListResponse структура ответа для метода AppList.
+type AppListResponse struct {
+	Message    string `json:"message"`
+	Apps       []App  `json:"apps"`
+	TotalCount int    `json:"totalCount"`
+}
+
+// AppInfoResponse структура ответа для метода AppInfo.
+type AppInfoResponse struct {
+	Message   string          `json:"message"`
+	App       App             `json:"app"`
+	Component swcat.Component `json:"component"`
+}
@@ -0,0 +1,50 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/reply"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Attach подключается к шине сервиса apm и показывает прогресс транзакции до её завершения.
+// Подключение выполняется без регистрации имени, поэтому работает параллельно с запущенным сервисом.
+func (a *Actions) Attach(ctx context.Context, transaction string, session bool) (*reply.TaskResultEvent, error) {
+	transaction = strings.TrimSpace(transaction)
+	if transaction == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Specify the transaction to follow with the --transaction flag")))
+	}
+
+	connect := dbus.ConnectSystemBus
+	if session {
+		connect = dbus.ConnectSessionBus
+	}
+	conn, err := connect()
+	if err != nil {
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to connect to D-Bus: %v"), err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	return reply.FollowDaemonEvents(ctx, a.appConfig, conn, transaction)
+}
@@ -0,0 +1,241 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	_package "apm/internal/common/apt/package"
+	"apm/internal/common/journal"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// securityMarkers признаки исправления уязвимости в записи changelog
+var securityMarkers = []string{"cve-", "security", "vulnerab"}
+
+// rebootPackages префиксы пакетов, после обновления которых требуется перезагрузка
+var rebootPackages = []string{"kernel-image-", "glibc", "systemd"}
+
+// AutoUpgrade обновляет списки пакетов и применяет обновления согласно политике, вызывается таймером systemd.
+// Пустая политика берётся из конфигурации
+func (a *Actions) AutoUpgrade(ctx context.Context, policy string) (*AutoUpgradeResponse, error) {
+	cfg := a.appConfig.ConfigManager.GetConfig()
+	if policy == "" {
+		policy = cfg.AutoUpgrade.Policy
+	}
+	if !slices.Contains([]string{app.AutoUpgradeSecurity, app.AutoUpgradeAll, app.AutoUpgradeNone}, policy) {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.TL_(ctx, "Unknown auto-upgrade policy %q, expected security, all or none"), policy))
+	}
+
+	ctx, release, err := a.operationLock.Acquire(ctx, journal.ActionUpgrade)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	resp := &AutoUpgradeResponse{Policy: policy}
+
+	if cfg.IsAtomic {
+		return a.autoUpgradeImage(ctx, resp)
+	}
+
+	if _, err = a.Update(ctx, false, false); err != nil {
+		return nil, err
+	}
+
+	packageParse, err := a.serviceAptActions.CheckUpgrade(ctx)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeApt, err)
+	}
+	resp.Available = packageParse.UpgradedPackages
+	resp.Security = a.securityUpgrades(ctx, packageParse.UpgradedPackages)
+
+	switch {
+	case len(resp.Available) == 0 && packageParse.NewInstalledCount == 0:
+		resp.Message = app.TL_(ctx, "The system is up to date")
+	case policy == app.AutoUpgradeNone:
+		resp.Message = fmt.Sprintf(app.TLN_(ctx, "%d update available, policy does not allow applying it", "%d updates available, policy does not allow applying them", len(resp.Available)), len(resp.Available))
+	case policy == app.AutoUpgradeSecurity && len(resp.Security) == 0:
+		resp.Message = fmt.Sprintf(app.TLN_(ctx, "%d update available, none of them are security fixes", "%d updates available, none of them are security fixes", len(resp.Available)), len(resp.Available))
+	case policy == app.AutoUpgradeSecurity:
+		if _, err = a.Install(ctx, resp.Security, true, false); err != nil {
+			return nil, err
+		}
+		resp.Applied = resp.Security
+	default:
+		if _, err = a.upgrade(ctx, false, true); err != nil {
+			return nil, err
+		}
+		resp.Applied = resp.Available
+	}
+
+	if len(resp.Applied) > 0 {
+		resp.Message = fmt.Sprintf(app.TLN_(ctx, "%d package upgraded automatically", "%d packages upgraded automatically", len(resp.Applied)), len(resp.Applied))
+		resp.RebootRequired = slices.ContainsFunc(resp.Applied, needsReboot)
+	}
+
+	a.finishAutoUpgrade(ctx, resp)
+	return resp, nil
+}
+
+// autoUpgradeImage обновляет образ атомарной системы. Отдельные пакеты в образе не обновляются,
+// поэтому образ обновляется только с политикой all
+func (a *Actions) autoUpgradeImage(ctx context.Context, resp *AutoUpgradeResponse) (*AutoUpgradeResponse, error) {
+	if resp.Policy != app.AutoUpgradeAll {
+		resp.Message = app.TL_(ctx, "Atomic system image is updated only with the all policy, nothing was applied")
+		a.finishAutoUpgrade(ctx, resp)
+		return resp, nil
+	}
+
+	if _, err := a.ImageUpdate(ctx, false); err != nil {
+		return nil, err
+	}
+
+	hostImage, err := a.serviceHostImage.GetHostImage()
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+	resp.RebootRequired = hostImage.Status.Staged != nil
+	if resp.RebootRequired {
+		resp.Message = app.TL_(ctx, "A new system image has been staged")
+	} else {
+		resp.Message = app.TL_(ctx, "The system image is up to date")
+	}
+
+	a.finishAutoUpgrade(ctx, resp)
+	return resp, nil
+}
+
+// finishAutoUpgrade пишет итог в лог и уведомляет пользователей, если требуется перезагрузка
+func (a *Actions) finishAutoUpgrade(ctx context.Context, resp *AutoUpgradeResponse) {
+	app.Log.Info(fmt.Sprintf("auto-upgrade (%s): %s", resp.Policy, resp.Message))
+
+	if !resp.RebootRequired {
+		return
+	}
+	resp.Message += ". " + app.TL_(ctx, "Reboot is required to finish the upgrade")
+
+	if a.appConfig.ConfigManager.GetConfig().AutoUpgrade.Notify {
+		resp.Notified = a.serviceAutoUpgrade.NotifyUsers(ctx,
+			app.TL_(ctx, "System upgraded"),
+			app.TL_(ctx, "Updates have been installed. Restart the computer to finish the upgrade"))
+	}
+}
+
+// securityUpgrades возвращает пакеты, в changelog которых после установленной версии упоминаются исправления уязвимостей
+func (a *Actions) securityUpgrades(ctx context.Context, names []string) []string {
+	var result []string
+	for _, name := range names {
+		installed, err := a.serviceAptDatabase.GetPackageByName(ctx, name)
+		if err != nil {
+			continue
+		}
+		candidate, err := a.serviceAptActions.GetInfo(ctx, name)
+		if err != nil {
+			app.Log.Debugf("auto-upgrade: changelog of %s: %v", name, err)
+			continue
+		}
+
+		entries, found := _package.ChangelogSince(_package.ParseChangelog(candidate.Changelog), installed.VersionInstalled)
+		if !found {
+			continue
+		}
+		if slices.ContainsFunc(entries, isSecurityEntry) {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// isSecurityEntry сообщает, упоминается ли в записи changelog исправление уязвимости
+func isSecurityEntry(entry _package.ChangelogEntry) bool {
+	for _, change := range entry.Changes {
+		lower := strings.ToLower(change)
+		for _, marker := range securityMarkers {
+			if strings.Contains(lower, marker) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// needsReboot сообщает, требует ли обновление пакета перезагрузки
+func needsReboot(name string) bool {
+	for _, prefix := range rebootPackages {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// AutoUpgradeEnable включает таймер автоматического обновления. Пустое расписание берётся из конфигурации
+func (a *Actions) AutoUpgradeEnable(ctx context.Context, onCalendar string) (*AutoUpgradeStatusResponse, error) {
+	if onCalendar == "" {
+		onCalendar = a.appConfig.ConfigManager.GetConfig().AutoUpgrade.OnCalendar
+	}
+
+	if err := a.serviceAutoUpgrade.Enable(ctx, onCalendar); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, err)
+	}
+
+	status, err := a.AutoUpgradeStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status.Message = fmt.Sprintf(app.TL_(ctx, "Automatic upgrades enabled (%s)"), onCalendar)
+	return status, nil
+}
+
+// AutoUpgradeDisable выключает таймер автоматического обновления
+func (a *Actions) AutoUpgradeDisable(ctx context.Context) (*AutoUpgradeStatusResponse, error) {
+	disabled, err := a.serviceAutoUpgrade.Disable(ctx)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypePermission, err)
+	}
+	if !disabled {
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.TL_(ctx, "Automatic upgrades are not enabled")))
+	}
+
+	return &AutoUpgradeStatusResponse{
+		Message: app.TL_(ctx, "Automatic upgrades disabled"),
+		Policy:  a.appConfig.ConfigManager.GetConfig().AutoUpgrade.Policy,
+	}, nil
+}
+
+// AutoUpgradeStatus возвращает состояние таймера автоматического обновления
+func (a *Actions) AutoUpgradeStatus(ctx context.Context) (*AutoUpgradeStatusResponse, error) {
+	status, err := a.serviceAutoUpgrade.Status(ctx)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypePermission, err)
+	}
+
+	resp := &AutoUpgradeStatusResponse{
+		Message: app.TL_(ctx, "Automatic upgrades are disabled"),
+		Policy:  a.appConfig.ConfigManager.GetConfig().AutoUpgrade.Policy,
+		Timer:   status,
+	}
+	if status.Enabled {
+		resp.Message = app.TL_(ctx, "Automatic upgrades are enabled")
+	}
+	return resp, nil
+}
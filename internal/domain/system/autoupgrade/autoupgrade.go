@@ -0,0 +1,231 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package autoupgrade
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/command"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	// UnitName имя юнитов systemd автоматического обновления
+	UnitName = "apm-auto-upgrade"
+	// DefaultUnitDir каталог, в который записываются юниты
+	DefaultUnitDir = "/etc/systemd/system"
+)
+
+// Status состояние таймера автоматического обновления
+type Status struct {
+	Enabled    bool   `json:"enabled"`
+	OnCalendar string `json:"onCalendar,omitempty"`
+	NextRun    string `json:"nextRun,omitempty"`
+	LastRun    string `json:"lastRun,omitempty"`
+}
+
+// Manager управляет таймером systemd автоматического обновления и уведомлениями пользователей
+type Manager struct {
+	runner     command.Runner
+	unitDir    string
+	executable string
+}
+
+// NewManager создаёт менеджер автоматического обновления.
+func NewManager(runner command.Runner, unitDir string) *Manager {
+	executable, err := os.Executable()
+	if err != nil {
+		executable = "apm"
+	}
+	return &Manager{
+		runner:     runner,
+		unitDir:    unitDir,
+		executable: executable,
+	}
+}
+
+// Enable записывает юниты сервиса и таймера и включает таймер с расписанием onCalendar.
+func (m *Manager) Enable(ctx context.Context, onCalendar string) error {
+	onCalendar = strings.TrimSpace(onCalendar)
+	if _, stderr, err := m.runner.Run(ctx, []string{"systemd-analyze", "calendar", onCalendar}, command.WithQuiet()); err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Invalid timer schedule %q: %s"), onCalendar, strings.TrimSpace(stderr))
+	}
+
+	if err := os.WriteFile(m.unitPath(".service"), []byte(m.serviceUnit()), 0644); err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Failed to write %s: %v"), m.unitPath(".service"), err)
+	}
+	if err := os.WriteFile(m.unitPath(".timer"), []byte(timerUnit(onCalendar)), 0644); err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Failed to write %s: %v"), m.unitPath(".timer"), err)
+	}
+
+	if _, stderr, err := m.runner.Run(ctx, []string{"systemctl", "daemon-reload"}, command.WithQuiet()); err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Failed to reload systemd: %s"), strings.TrimSpace(stderr))
+	}
+	if _, stderr, err := m.runner.Run(ctx, []string{"systemctl", "enable", "--now", UnitName + ".timer"}, command.WithQuiet()); err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Failed to enable %s: %s"), UnitName+".timer", strings.TrimSpace(stderr))
+	}
+	return nil
+}
+
+// Disable выключает таймер и удаляет юниты. Возвращает false, если таймер не был настроен.
+func (m *Manager) Disable(ctx context.Context) (bool, error) {
+	if _, err := os.Stat(m.unitPath(".timer")); errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+
+	_, _, _ = m.runner.Run(ctx, []string{"systemctl", "disable", "--now", UnitName + ".timer"}, command.WithQuiet())
+	for _, suffix := range []string{".timer", ".service"} {
+		if err := os.Remove(m.unitPath(suffix)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return false, err
+		}
+	}
+	_, _, _ = m.runner.Run(ctx, []string{"systemctl", "daemon-reload"}, command.WithQuiet())
+
+	return true, nil
+}
+
+// Status возвращает состояние таймера. Если юниты не записаны, таймер считается выключенным.
+func (m *Manager) Status(ctx context.Context) (Status, error) {
+	var status Status
+
+	data, err := os.ReadFile(m.unitPath(".timer"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return status, nil
+		}
+		return status, err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(line), "OnCalendar="); ok {
+			status.OnCalendar = value
+		}
+	}
+
+	stdout, _, _ := m.runner.Run(ctx, []string{"systemctl", "is-enabled", UnitName + ".timer"}, command.WithQuiet())
+	status.Enabled = strings.TrimSpace(stdout) == "enabled"
+
+	stdout, _, err = m.runner.Run(ctx, []string{"systemctl", "show", UnitName + ".timer",
+		"-p", "NextElapseUSecRealtime", "-p", "LastTriggerUSec"}, command.WithQuiet(), command.WithEnv("LC_ALL=C"))
+	if err == nil {
+		for _, line := range strings.Split(stdout, "\n") {
+			key, value, _ := strings.Cut(strings.TrimSpace(line), "=")
+			if value == "" || value == "n/a" {
+				continue
+			}
+			switch key {
+			case "NextElapseUSecRealtime":
+				status.NextRun = value
+			case "LastTriggerUSec":
+				status.LastRun = value
+			}
+		}
+	}
+
+	return status, nil
+}
+
+// NotifyUsers отправляет уведомление в сеансы пользователей с графическим окружением через их Session Bus.
+// Возвращает число пользователей, получивших уведомление.
+func (m *Manager) NotifyUsers(ctx context.Context, summary string, body string) int {
+	stdout, _, err := m.runner.Run(ctx, []string{"loginctl", "list-sessions", "--no-legend"}, command.WithQuiet())
+	if err != nil {
+		return 0
+	}
+
+	var users []string
+	for _, line := range strings.Split(stdout, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		info, _, errShow := m.runner.Run(ctx, []string{"loginctl", "show-session", fields[0], "-p", "Name", "-p", "Type"}, command.WithQuiet())
+		if errShow != nil {
+			continue
+		}
+		name, graphical := parseSession(info)
+		if graphical && name != "" && !slices.Contains(users, name) {
+			users = append(users, name)
+		}
+	}
+
+	notified := 0
+	for _, user := range users {
+		args := []string{"busctl", "--user", "--machine=" + user + "@.host", "call",
+			"org.freedesktop.Notifications", "/org/freedesktop/Notifications", "org.freedesktop.Notifications",
+			"Notify", "susssasa{sv}i", "APM", "0", "system-software-update", summary, body, "0", "0", "-1"}
+		if _, _, errNotify := m.runner.Run(ctx, args, command.WithQuiet()); errNotify != nil {
+			app.Log.Debugf("auto-upgrade: notify %s: %v", user, errNotify)
+			continue
+		}
+		notified++
+	}
+	return notified
+}
+
+// parseSession разбирает вывод "loginctl show-session -p Name -p Type"
+func parseSession(output string) (name string, graphical bool) {
+	for _, line := range strings.Split(output, "\n") {
+		key, value, _ := strings.Cut(strings.TrimSpace(line), "=")
+		switch key {
+		case "Name":
+			name = value
+		case "Type":
+			graphical = value == "x11" || value == "wayland"
+		}
+	}
+	return name, graphical
+}
+
+// unitPath возвращает путь к юниту с указанным суффиксом
+func (m *Manager) unitPath(suffix string) string {
+	return filepath.Join(m.unitDir, UnitName+suffix)
+}
+
+// serviceUnit формирует юнит сервиса, запускающего обновление
+func (m *Manager) serviceUnit() string {
+	return fmt.Sprintf(`[Unit]
+Description=APM unattended upgrade
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+Type=oneshot
+ExecStart=%s system auto-upgrade run
+Nice=10
+IOSchedulingClass=idle
+`, m.executable)
+}
+
+// timerUnit формирует юнит таймера с расписанием onCalendar
+func timerUnit(onCalendar string) string {
+	return fmt.Sprintf(`[Unit]
+Description=APM unattended upgrade timer
+
+[Timer]
+OnCalendar=%s
+RandomizedDelaySec=1h
+Persistent=true
+
+[Install]
+WantedBy=timers.target
+`, onCalendar)
+}
@@ -0,0 +1,114 @@
+package autoupgrade
+
+import (
+	"apm/internal/common/command"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type mockRunner struct {
+	calls   [][]string
+	runFunc func(args []string) (string, string, error)
+}
+
+func (m *mockRunner) Run(_ context.Context, args []string, _ ...command.Option) (string, string, error) {
+	m.calls = append(m.calls, args)
+	if m.runFunc != nil {
+		return m.runFunc(args)
+	}
+	return "", "", nil
+}
+
+func newTestManager(t *testing.T, runner *mockRunner) *Manager {
+	t.Helper()
+	m := NewManager(runner, t.TempDir())
+	m.executable = "/usr/bin/apm"
+	return m
+}
+
+func TestEnableStatusDisable(t *testing.T) {
+	runner := &mockRunner{runFunc: func(args []string) (string, string, error) {
+		switch strings.Join(args[:2], " ") {
+		case "systemctl is-enabled":
+			return "enabled\n", "", nil
+		case "systemctl show":
+			return "NextElapseUSecRealtime=Thu 2026-10-16 00:00:00 UTC\nLastTriggerUSec=n/a\n", "", nil
+		}
+		return "", "", nil
+	}}
+	m := newTestManager(t, runner)
+	ctx := context.Background()
+
+	if err := m.Enable(ctx, "weekly"); err != nil {
+		t.Fatalf("enable: %v", err)
+	}
+
+	service, err := os.ReadFile(filepath.Join(m.unitDir, UnitName+".service"))
+	if err != nil || !strings.Contains(string(service), "ExecStart=/usr/bin/apm system auto-upgrade run") {
+		t.Errorf("unexpected service unit: %s (%v)", service, err)
+	}
+
+	status, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if !status.Enabled || status.OnCalendar != "weekly" || status.NextRun == "" || status.LastRun != "" {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	if disabled, err := m.Disable(ctx); err != nil || !disabled {
+		t.Fatalf("disable: %v, %v", disabled, err)
+	}
+	if _, err = os.Stat(filepath.Join(m.unitDir, UnitName+".timer")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("timer unit must be removed, got %v", err)
+	}
+	if disabled, _ := m.Disable(ctx); disabled {
+		t.Error("second disable must report nothing to do")
+	}
+}
+
+func TestEnableInvalidCalendar(t *testing.T) {
+	runner := &mockRunner{runFunc: func(args []string) (string, string, error) {
+		if args[0] == "systemd-analyze" {
+			return "", "Failed to parse calendar specification", errors.New("exit status 1")
+		}
+		return "", "", nil
+	}}
+	m := newTestManager(t, runner)
+
+	if err := m.Enable(context.Background(), "sometimes"); err == nil {
+		t.Fatal("expected error for invalid schedule")
+	}
+	if _, err := os.Stat(filepath.Join(m.unitDir, UnitName+".timer")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("timer unit must not be written, got %v", err)
+	}
+}
+
+func TestNotifyUsers(t *testing.T) {
+	runner := &mockRunner{runFunc: func(args []string) (string, string, error) {
+		switch {
+		case args[0] == "loginctl" && args[1] == "list-sessions":
+			return "2 1000 alice seat0 tty2\n3 1000 alice - pts/0\n4 0 root - tty1\n", "", nil
+		case args[0] == "loginctl" && args[2] == "2":
+			return "Name=alice\nType=wayland\n", "", nil
+		case args[0] == "loginctl" && args[2] == "3":
+			return "Name=alice\nType=x11\n", "", nil
+		case args[0] == "loginctl":
+			return "Name=root\nType=tty\n", "", nil
+		}
+		return "", "", nil
+	}}
+	m := newTestManager(t, runner)
+
+	if n := m.NotifyUsers(context.Background(), "summary", "body"); n != 1 {
+		t.Errorf("expected one notified user, got %d", n)
+	}
+	last := runner.calls[len(runner.calls)-1]
+	if last[0] != "busctl" || last[2] != "--machine=alice@.host" {
+		t.Errorf("unexpected notify call: %v", last)
+	}
+}
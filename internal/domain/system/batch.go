@@ -0,0 +1,163 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Типы операций пакетного запроса
+const (
+	BatchUpdate     = "update"
+	BatchInstall    = "install"
+	BatchRemove     = "remove"
+	BatchImageApply = "image-apply"
+)
+
+// batchOrder порядок выполнения типов операций пакетного запроса
+var batchOrder = []string{BatchUpdate, BatchInstall, BatchRemove, BatchImageApply}
+
+// BatchOperation операция пакетного запроса
+type BatchOperation struct {
+	Type     string   `json:"type"`
+	Packages []string `json:"packages,omitempty"`
+}
+
+// batchPlan разобранный пакетный запрос
+type batchPlan struct {
+	update     bool
+	packages   []string
+	imageApply bool
+}
+
+// parseBatch проверяет операции и объединяет установку и удаление в один список в формате package+ package-.
+// Операции должны идти в порядке update, install/remove, image-apply, так как изменения пакетов
+// выполняются одной транзакцией.
+func (a *Actions) parseBatch(ctx context.Context, ops []BatchOperation) (*batchPlan, error) {
+	if len(ops) == 0 {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "At least one operation must be specified")))
+	}
+
+	plan := &batchPlan{}
+	stage := 0
+	actions := make(map[string]string)
+	for _, op := range ops {
+		idx := slices.Index(batchOrder, op.Type)
+		if idx == -1 {
+			return nil, apmerr.New(apmerr.ErrorTypeValidation,
+				fmt.Errorf(app.TL_(ctx, "Unknown batch operation %s, available: %s"), op.Type, strings.Join(batchOrder, ", ")))
+		}
+		// Установка и удаление образуют одну стадию и могут чередоваться
+		if idx == slices.Index(batchOrder, BatchRemove) {
+			idx = slices.Index(batchOrder, BatchInstall)
+		}
+		if idx < stage {
+			return nil, apmerr.New(apmerr.ErrorTypeValidation,
+				errors.New(app.TL_(ctx, "Batch operations must be ordered: update, then install and remove, then image-apply")))
+		}
+		stage = idx
+
+		switch op.Type {
+		case BatchUpdate:
+			plan.update = true
+		case BatchImageApply:
+			if !a.appConfig.ConfigManager.GetConfig().IsAtomic {
+				return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "This option is only available for an atomic system")))
+			}
+			plan.imageApply = true
+		case BatchInstall, BatchRemove:
+			if len(op.Packages) == 0 {
+				return nil, apmerr.New(apmerr.ErrorTypeValidation,
+					fmt.Errorf(app.TL_(ctx, "Batch operation %s requires a package list"), op.Type))
+			}
+			for _, pkg := range op.Packages {
+				pkg = strings.TrimSpace(pkg)
+				if pkg == "" {
+					continue
+				}
+				if prev, ok := actions[pkg]; ok && prev != op.Type {
+					return nil, apmerr.New(apmerr.ErrorTypeValidation,
+						fmt.Errorf(app.TL_(ctx, "Package %s is both installed and removed in the batch"), pkg))
+				} else if ok {
+					continue
+				}
+				actions[pkg] = op.Type
+				if op.Type == BatchRemove {
+					pkg += "-"
+				}
+				plan.packages = append(plan.packages, pkg)
+			}
+		}
+	}
+	return plan, nil
+}
+
+// Batch выполняет список операций как одну транзакцию: обновление списков пакетов, затем
+// установку и удаление пакетов одним изменением APT, затем применение образа. С simulate
+// изменения пакетов только проверяются, а обновление и применение образа не выполняются.
+func (a *Actions) Batch(ctx context.Context, ops []BatchOperation, simulate bool) (*BatchResponse, error) {
+	plan, err := a.parseBatch(ctx, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &BatchResponse{Simulated: simulate, Operations: ops}
+	if simulate {
+		if len(plan.packages) > 0 {
+			if resp.Check, err = a.CheckInstall(ctx, plan.packages); err != nil {
+				return nil, err
+			}
+		}
+		resp.Message = app.TL_(ctx, "Inspection information")
+		return resp, nil
+	}
+
+	ctx, release, err := a.operationLock.Acquire(ctx, "batch")
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if plan.update {
+		if resp.Update, err = a.Update(ctx, false, false); err != nil {
+			return nil, err
+		}
+		a.indexesFresh = true
+		defer func() { a.indexesFresh = false }()
+	}
+
+	if len(plan.packages) > 0 {
+		if resp.Packages, err = a.Install(ctx, plan.packages, true, false); err != nil {
+			return nil, err
+		}
+	}
+
+	if plan.imageApply {
+		if resp.Image, err = a.ImageApply(ctx, false, true, "", "", ""); err != nil {
+			return nil, err
+		}
+	}
+
+	resp.Message = fmt.Sprintf(app.TLN_(ctx, "%d operation completed successfully", "%d operations completed successfully", len(ops)), len(ops))
+	return resp, nil
+}
@@ -0,0 +1,84 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	_package "apm/internal/common/apt/package"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Changelog возвращает записи changelog версии-кандидата, появившиеся после версии since. Если since
+// не указана, для установленного пакета берётся установленная версия, иначе возвращается весь changelog.
+func (a *Actions) Changelog(ctx context.Context, packageName string, since string) (*ChangelogResponse, error) {
+	packageName = strings.TrimSpace(packageName)
+	if packageName == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Package name must be specified, for example changelog package")))
+	}
+
+	if err := a.validateDB(ctx, false); err != nil {
+		return nil, err
+	}
+
+	packageInfo, err := a.serviceAptDatabase.GetPackageByName(ctx, packageName)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.TL_(ctx, "Failed to retrieve information about the package %s"), packageName))
+	}
+
+	candidate, err := a.serviceAptActions.GetInfo(ctx, packageName)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeApt, err)
+	}
+
+	resp := &ChangelogResponse{
+		Package:          packageName,
+		InstalledVersion: packageInfo.VersionInstalled,
+		CandidateVersion: candidate.Version,
+		Since:            strings.TrimSpace(since),
+	}
+	if resp.Since == "" && packageInfo.Installed {
+		resp.Since = packageInfo.VersionInstalled
+	}
+
+	entries := _package.ParseChangelog(candidate.Changelog)
+	if len(entries) == 0 {
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.TL_(ctx, "Repository index has no changelog for package %s"), packageName))
+	}
+
+	resp.Entries = entries
+	if resp.Since != "" {
+		var found bool
+		resp.Entries, found = _package.ChangelogSince(entries, resp.Since)
+		if !found {
+			resp.Message = fmt.Sprintf(app.TL_(ctx, "Version %s is not mentioned in the changelog, showing all entries"), resp.Since)
+		}
+	}
+
+	if resp.Message == "" {
+		if len(resp.Entries) == 0 {
+			resp.Message = fmt.Sprintf(app.TL_(ctx, "No changes after version %s"), resp.Since)
+		} else {
+			resp.Message = fmt.Sprintf(app.TLN_(ctx, "%d changelog entry", "%d changelog entries", len(resp.Entries)), len(resp.Entries))
+		}
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,253 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	_package "apm/internal/common/apt/package"
+	"apm/internal/common/helper"
+	"apm/internal/domain/system/localrpm"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+const (
+	// CleanTargetCache скачанные APT пакеты
+	CleanTargetCache = "cache"
+	// CleanTargetDownloads локальные пакеты, сохранённые apm в ресурсах образа
+	CleanTargetDownloads = "downloads"
+)
+
+// Clean удаляет скачанные APT пакеты (cache) и сохранённые apm локальные пакеты, на которые
+// больше не ссылается конфигурация образа (downloads). Вместе с кэшем APT из базы удаляются
+// записи об удержании исчезнувших пакетов. Без указания целей очищается кэш APT.
+func (a *Actions) Clean(ctx context.Context, cache bool, downloads bool) (*CleanResponse, error) {
+	ctx, release, err := a.operationLock.Acquire(ctx, "clean")
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return a.clean(ctx, cache, downloads, false)
+}
+
+// CheckClean показывает, какие файлы и записи удалит Clean и сколько места освободится, ничего не удаляя.
+func (a *Actions) CheckClean(ctx context.Context, cache bool, downloads bool) (*CleanResponse, error) {
+	return a.clean(ctx, cache, downloads, true)
+}
+
+// clean очищает выбранные цели, при simulate только подсчитывает результат.
+func (a *Actions) clean(ctx context.Context, cache bool, downloads bool, simulate bool) (*CleanResponse, error) {
+	if !cache && !downloads {
+		cache = true
+	}
+
+	resp := &CleanResponse{Targets: []CleanTarget{}}
+
+	if cache {
+		target, err := a.cleanArchives(ctx, simulate)
+		if err != nil {
+			return nil, err
+		}
+		resp.add(target)
+
+		if resp.OrphanedRows, err = a.cleanOrphanedHeld(ctx, simulate); err != nil {
+			return nil, err
+		}
+	}
+
+	if downloads {
+		target, err := a.cleanStoredPackages(ctx, simulate)
+		if err != nil {
+			return nil, err
+		}
+		resp.add(target)
+	}
+
+	if simulate {
+		resp.Message = fmt.Sprintf(app.TL_(ctx, "Would free %s: remove %d files and %d orphaned database records"),
+			helper.AutoSize(int(resp.Freed)), resp.Files, resp.OrphanedRows)
+		return resp, nil
+	}
+
+	resp.Message = fmt.Sprintf(app.TL_(ctx, "Freed %s: removed %d files and %d orphaned database records"),
+		helper.AutoSize(int(resp.Freed)), resp.Files, resp.OrphanedRows)
+	return resp, nil
+}
+
+// cleanOrphanedHeld удаляет записи об удержании пакетов, которые не установлены и недоступны в репозиториях,
+// и возвращает их число. Пока база пакетов не заполнена, записи не трогаются. При simulate записи только подсчитываются.
+func (a *Actions) cleanOrphanedHeld(ctx context.Context, simulate bool) (int64, error) {
+	if a.serviceAptDatabase.PackageDatabaseExist(ctx) != nil {
+		return 0, nil
+	}
+	installed, err := a.serviceAptActions.GetInstalledPackages(ctx)
+	if err != nil {
+		return 0, apmerr.New(apmerr.ErrorTypeApt, err)
+	}
+
+	if simulate {
+		held, err := a.serviceAptDatabase.GetHeldPackages(ctx)
+		if err != nil {
+			return 0, apmerr.New(apmerr.ErrorTypeDatabase, err)
+		}
+		if len(held) == 0 {
+			return 0, nil
+		}
+		found, err := a.serviceAptDatabase.GetPackagesByNames(ctx, held)
+		if err != nil {
+			return 0, apmerr.New(apmerr.ErrorTypeDatabase, err)
+		}
+		var orphaned int64
+		for _, name := range held {
+			if _, ok := installed[name]; ok {
+				continue
+			}
+			if !slices.ContainsFunc(found, func(p _package.Package) bool { return p.Name == name }) {
+				orphaned++
+			}
+		}
+		return orphaned, nil
+	}
+
+	deleted, err := a.serviceAptDatabase.DeleteOrphanedHeld(ctx, installed)
+	if err != nil {
+		return 0, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+	if deleted > 0 {
+		if err = a.serviceAptActions.SyncHoldPreferences(ctx); err != nil {
+			return 0, apmerr.New(apmerr.ErrorTypeApt, fmt.Errorf(app.TL_(ctx, "Failed to update APT preferences: %w"), err))
+		}
+	}
+	return deleted, nil
+}
+
+// add учитывает очищенный каталог в итоговых размерах.
+func (r *CleanResponse) add(target CleanTarget) {
+	r.Targets = append(r.Targets, target)
+	r.SizeBefore += target.SizeBefore
+	r.SizeAfter += target.SizeAfter
+	r.Freed += target.SizeBefore - target.SizeAfter
+	r.Files += target.Files
+}
+
+// cleanArchives удаляет скачанные пакеты и недокачанные файлы из каталога архивов APT.
+// Файл блокировки и каталог partial сохраняются.
+func (a *Actions) cleanArchives(ctx context.Context, simulate bool) (CleanTarget, error) {
+	dir := filepath.Clean(a.serviceAptActions.ArchivesDir())
+
+	var files []string
+	archives, err := filepath.Glob(filepath.Join(dir, "*.rpm"))
+	if err != nil {
+		return CleanTarget{}, apmerr.New(apmerr.ErrorTypeApt, err)
+	}
+	files = append(files, archives...)
+
+	entries, err := os.ReadDir(filepath.Join(dir, "partial"))
+	if err != nil && !os.IsNotExist(err) {
+		return CleanTarget{}, apmerr.New(apmerr.ErrorTypeApt, err)
+	}
+	for _, e := range entries {
+		files = append(files, filepath.Join(dir, "partial", e.Name()))
+	}
+
+	return cleanFiles(ctx, CleanTargetCache, dir, files, simulate)
+}
+
+// cleanStoredPackages удаляет из ресурсов образа локальные пакеты, которые не указаны
+// ни в конфигурации образа, ни во временной конфигурации.
+func (a *Actions) cleanStoredPackages(ctx context.Context, simulate bool) (CleanTarget, error) {
+	dir := a.serviceLocalRpm.StorePath()
+	if !a.appConfig.ConfigManager.GetConfig().IsAtomic {
+		return CleanTarget{Name: CleanTargetDownloads, Path: dir}, nil
+	}
+
+	if err := a.serviceHostConfig.LoadConfig(); err != nil {
+		return CleanTarget{}, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+	if err := a.serviceTemporaryConfig.LoadConfig(); err != nil {
+		return CleanTarget{}, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+
+	stored, err := filepath.Glob(filepath.Join(dir, "*.rpm"))
+	if err != nil {
+		return CleanTarget{}, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+
+	var files []string
+	for _, file := range stored {
+		if !a.storedPackageUsed(filepath.Join(localrpm.StoreDir, filepath.Base(file))) {
+			files = append(files, file)
+		}
+	}
+
+	return cleanFiles(ctx, CleanTargetDownloads, dir, files, simulate)
+}
+
+// storedPackageUsed проверяет, ссылается ли конфигурация образа на сохранённый пакет.
+func (a *Actions) storedPackageUsed(rel string) bool {
+	if config := a.serviceHostConfig.GetConfig(); config != nil && config.IsInstalled(rel) {
+		return true
+	}
+	if config := a.serviceTemporaryConfig.GetConfig(); config != nil && slices.Contains(config.Packages.Install, rel) {
+		return true
+	}
+	return false
+}
+
+// cleanFiles удаляет файлы каталога dir и возвращает размеры каталога до и после удаления.
+// При simulate файлы не удаляются, а размер после очистки вычисляется.
+func cleanFiles(ctx context.Context, name string, dir string, files []string, simulate bool) (CleanTarget, error) {
+	target := CleanTarget{Name: name, Path: dir, SizeBefore: dirSize(dir)}
+	if simulate {
+		target.SizeAfter = target.SizeBefore
+		for _, file := range files {
+			target.SizeAfter -= dirSize(file)
+			target.Files++
+		}
+		return target, nil
+	}
+
+	for _, file := range files {
+		if err := os.RemoveAll(file); err != nil {
+			return CleanTarget{}, apmerr.New(apmerr.ErrorTypePermission, fmt.Errorf(app.TL_(ctx, "Failed to remove %s: %w"), file, err))
+		}
+		target.Files++
+	}
+	target.SizeAfter = dirSize(dir)
+	return target, nil
+}
+
+// dirSize возвращает суммарный размер обычных файлов каталога. Отсутствующий каталог считается пустым.
+func dirSize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return nil
+		}
+		if info, errInfo := d.Info(); errInfo == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
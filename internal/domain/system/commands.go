@@ -17,16 +17,24 @@
 package system
 
 import (
+	"apm/internal/common/apmerr"
 	"apm/internal/common/app"
+	"apm/internal/common/apt"
 	_package "apm/internal/common/apt/package"
 	"apm/internal/common/build/altfiles"
 	apmcli "apm/internal/common/cli"
+	"apm/internal/common/filter"
 	"apm/internal/common/helper"
+	"apm/internal/common/oplog"
 	"apm/internal/common/reply"
 	"apm/internal/domain/system/appstream"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/urfave/cli/v3"
 )
@@ -37,40 +45,35 @@ func newErrorResponseFromError(err error) reply.APIResponse {
 	return reply.ErrorResponseFromError(err)
 }
 
+// findPkgWithInstalled дополняет имена пакетов из базы. Без введённого префикса предлагаются
+// только установленные пакеты, полный список доступных слишком велик.
 func findPkgWithInstalled(appConfig *app.Config, reporter *reply.Reporter, installed bool) func(ctx context.Context, cmd *cli.Command) {
 	return func(ctx context.Context, cmd *cli.Command) {
-		args := cmd.Args().Slice()
-
-		// Текущий токен — последний позиционный аргумент (если есть)
-		var currentToken string
-		if len(args) > 0 {
-			currentToken = args[len(args)-1]
-		}
-		currentToken = strings.TrimSpace(currentToken)
-		if currentToken == "" {
-			// Пользователь ещё ничего не ввёл — не предлагаем варианты
+		if apmcli.CompleteFlags(ctx, cmd) || apmcli.CompletingFlagValue(cmd) {
 			return
 		}
-
-		exclude := make(map[string]struct{}, len(args))
-		for i := 0; i < len(args)-1; i++ {
-			exclude[strings.TrimRight(strings.TrimSpace(args[i]), "+-")] = struct{}{}
+		currentToken, _ := apmcli.CompletionToken()
+		if currentToken == "" && !installed {
+			return
 		}
 
-		like := currentToken + "%"
 		svc := NewActions(appConfig, reporter).serviceAptDatabase
 		if svc == nil {
 			return
 		}
 
-		pkgs, _ := svc.SearchPackagesMultiLimit(ctx, like, 200, installed)
+		pkgs, _ := svc.SearchPackagesMultiLimit(ctx, currentToken+"%", apmcli.CompletionLimit, installed)
 
+		names := make([]string, 0, len(pkgs))
 		for _, p := range pkgs {
-			if _, seen := exclude[p.Name]; seen {
-				continue
-			}
-			fmt.Println(p.Name)
+			names = append(names, p.Name)
+		}
+		// Уже перечисленные в командной строке пакеты не предлагаются повторно
+		args := cmd.Args().Slice()
+		if len(args) > 0 && strings.TrimSpace(args[len(args)-1]) == currentToken {
+			args = args[:len(args)-1]
 		}
+		apmcli.PrintCompletions(currentToken, names, args...)
 	}
 }
 
@@ -107,13 +110,40 @@ var aptOptionFlag = func() cli.Flag {
 	}
 }
 
+var dotFlag = func() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "dot",
+		Usage: app.T_("Print the graph in Graphviz DOT format"),
+	}
+}
+
+// dependencyTreeResponse выводит дерево зависимостей: в формате DOT с флагом --dot, псевдографикой
+// в текстовом формате, иначе обычным ответом.
+func dependencyTreeResponse(ctx context.Context, appConfig *app.Config, reporter *reply.Reporter, cmd *cli.Command,
+	resp interface{}, message string, tree DependencyNode, reverse bool) error {
+	if cmd.Bool("dot") {
+		reply.StopSpinner(appConfig)
+		fmt.Print(dependencyDOT(tree, reverse))
+		return nil
+	}
+	if appConfig.ConfigManager.GetConfig().Format != app.FormatText {
+		return reporter.CliResponse(ctx, reply.OK(resp))
+	}
+
+	reply.StopSpinner(appConfig)
+	fmt.Println(message)
+	fmt.Print(renderDependencyTree(ctx, tree))
+	return nil
+}
+
 func upgradeCommand(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 	withRootCheckWrapper := apmcli.WithOptions(appConfig, reporter, apmcli.RequireRoot, NewActions, newErrorResponseFromError)
 
 	if appConfig.ConfigManager.GetConfig().IsAtomic {
 		return &cli.Command{
-			Name:  "upgrade",
-			Usage: app.T_("Upgrade system image"),
+			Name:     "upgrade",
+			Usage:    app.T_("Upgrade system image"),
+			Metadata: apmcli.Requires(apmcli.CapHost),
 			Flags: []cli.Flag{
 				&cli.BoolFlag{
 					Name:  "no-cache",
@@ -167,6 +197,468 @@ func upgradeCommand(appConfig *app.Config, reporter *reply.Reporter) *cli.Comman
 	}
 }
 
+// SelfUpdateCommand возвращает команду самообновления apm.
+func SelfUpdateCommand(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
+	withRootCheckWrapper := apmcli.WithOptions(appConfig, reporter, apmcli.RequireRoot, NewActions, newErrorResponseFromError)
+
+	return &cli.Command{
+		Name:     "self-update",
+		Usage:    app.T_("Update apm to the latest version"),
+		Metadata: apmcli.Requires(apmcli.CapSystemd),
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "channel",
+				Usage: app.T_("Update channel: stable or testing"),
+				Value: appConfig.ConfigManager.GetConfig().SelfUpdateChannel,
+			},
+			&cli.StringFlag{
+				Name:  "task",
+				Usage: app.T_("Take the new version from the specified task"),
+			},
+			&cli.BoolFlag{
+				Name:  "check",
+				Usage: app.T_("Only check whether a new version is available"),
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Usage:   app.T_("Update without confirmation"),
+				Aliases: []string{"y"},
+			},
+			&cli.BoolFlag{
+				Name:    "simulate",
+				Usage:   app.T_("Show the package changes of the update without applying them"),
+				Aliases: []string{"s"},
+			},
+		},
+		Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+			if cmd.Bool("simulate") {
+				resp, err := actions.CheckSelfUpdate(ctx, cmd.String("channel"), cmd.String("task"))
+				if err != nil {
+					return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+				}
+				return reporter.CliResponse(ctx, reply.OK(resp))
+			}
+			resp, err := actions.SelfUpdate(ctx, cmd.String("channel"), cmd.String("task"), cmd.Bool("check"), cmd.Bool("yes"))
+			if err != nil {
+				return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+			}
+			return reporter.CliResponse(ctx, reply.OK(resp))
+		}),
+	}
+}
+
+// LogCommand возвращает команду просмотра лога apm.
+func LogCommand(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
+	withGlobalWrapper := apmcli.WithOptions(appConfig, reporter, apmcli.NoRootCheck, NewActions, newErrorResponseFromError)
+
+	return &cli.Command{
+		Name:  "log",
+		Usage: app.T_("Show apm operation log, including the system service. Use the global --transaction flag to filter by transaction"),
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "follow",
+				Usage: app.T_("Wait for new entries and print them as they appear"),
+			},
+			&cli.StringFlag{
+				Name:  "grep",
+				Usage: app.T_("Show only entries containing the text"),
+			},
+			&cli.IntFlag{
+				Name:    "lines",
+				Usage:   app.T_("Number of last entries to show, 0 for all"),
+				Aliases: []string{"n"},
+				Value:   100,
+			},
+		},
+		Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+			grep, transaction, lines := cmd.String("grep"), cmd.String("transaction"), cmd.Int("lines")
+			isText := appConfig.ConfigManager.GetConfig().Format == app.FormatText
+			renderer := oplog.NewRenderer(appConfig.ConfigManager.GetColors())
+
+			if cmd.Bool("follow") {
+				reply.StopSpinner(appConfig)
+				err := actions.FollowLog(ctx, grep, transaction, lines, func(e oplog.Entry) {
+					if isText {
+						fmt.Println(renderer.Render(e))
+						return
+					}
+					if data, errMarshal := json.Marshal(e); errMarshal == nil {
+						fmt.Println(string(data))
+					}
+				})
+				if err != nil {
+					return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+				}
+				return nil
+			}
+
+			resp, err := actions.Log(ctx, grep, transaction, lines)
+			if err != nil {
+				return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+			}
+			if !isText {
+				return reporter.CliResponse(ctx, reply.OK(resp))
+			}
+
+			reply.StopSpinner(appConfig)
+			for _, e := range resp.Entries {
+				fmt.Println(renderer.Render(e))
+			}
+			return nil
+		}),
+	}
+}
+
+// HistoryCommand возвращает команды просмотра истории операций всех модулей.
+func HistoryCommand(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
+	withGlobalWrapper := apmcli.WithOptions(appConfig, reporter, apmcli.NoRootCheck, NewActions, newErrorResponseFromError)
+
+	return &cli.Command{
+		Name:  "history",
+		Usage: app.T_("History of install, remove, upgrade, kernel, repository and image operations"),
+		Commands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: app.T_("List operations from newest to oldest"),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "module",
+						Usage: app.T_("Filter by module: system, kernel, repository, image"),
+					},
+					&cli.IntFlag{
+						Name:  "limit",
+						Usage: app.T_("Maximum number of records to return"),
+						Value: 20,
+					},
+					&cli.IntFlag{
+						Name:  "offset",
+						Usage: app.T_("Starting position (offset) for the result set"),
+						Value: 0,
+					},
+				},
+				Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+					resp, err := actions.HistoryList(ctx, cmd.String("module"), cmd.Int("limit"), cmd.Int("offset"))
+					if err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+
+					return reporter.CliResponse(ctx, reply.OK(resp))
+				}),
+			},
+			{
+				Name:      "show",
+				Usage:     app.T_("Show an operation by its ID"),
+				ArgsUsage: "<id>",
+				Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+					id, err := strconv.Atoi(cmd.Args().First())
+					if err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(apmerr.New(apmerr.ErrorTypeValidation,
+							fmt.Errorf(app.TL_(ctx, "Invalid operation ID: %s"), cmd.Args().First()))))
+					}
+
+					resp, err := actions.HistoryShow(ctx, id)
+					if err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+
+					return reporter.CliResponse(ctx, reply.OK(resp))
+				}),
+			},
+		},
+	}
+}
+
+// DBCommand возвращает команды обслуживания баз данных apm.
+func DBCommand(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
+	withGlobalWrapper := apmcli.WithOptions(appConfig, reporter, apmcli.NoRootCheck, NewActions, newErrorResponseFromError)
+
+	return &cli.Command{
+		Name:  "db",
+		Usage: app.T_("Database maintenance"),
+		Commands: []*cli.Command{
+			{
+				Name:  "status",
+				Usage: app.T_("Show schema versions and health of the databases"),
+				Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+					resp, err := actions.DBStatus(ctx)
+					if err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+
+					return reporter.CliResponse(ctx, reply.OK(resp))
+				}),
+			},
+			{
+				Name:  "migrate",
+				Usage: app.T_("Apply pending schema migrations. The system database requires elevated rights"),
+				Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+					resp, err := actions.DBMigrate(ctx)
+					if err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+
+					return reporter.CliResponse(ctx, reply.OK(resp))
+				}),
+			},
+			{
+				Name:  "vacuum",
+				Usage: app.T_("Compact the databases and recreate corrupted ones. The system database requires elevated rights"),
+				Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+					resp, err := actions.DBVacuum(ctx)
+					if err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+
+					return reporter.CliResponse(ctx, reply.OK(resp))
+				}),
+			},
+			{
+				Name:  "relocate",
+				Usage: app.T_("Move the databases and build logs to the configured location: the system data as root, the user database otherwise. Stop apm services first"),
+				Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+					resp, err := actions.DBRelocate(ctx)
+					if err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+
+					return reporter.CliResponse(ctx, reply.OK(resp))
+				}),
+			},
+		},
+	}
+}
+
+// HooksCommand возвращает команды просмотра и проверки хуков транзакций.
+func HooksCommand(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
+	withGlobalWrapper := apmcli.WithOptions(appConfig, reporter, apmcli.NoRootCheck, NewActions, newErrorResponseFromError)
+
+	return &cli.Command{
+		Name:  "hooks",
+		Usage: app.T_("Hooks run before and after install, remove, upgrade, image apply and kernel install"),
+		Commands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: app.T_("List hooks in execution order"),
+				Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+					resp, err := actions.HooksList(ctx)
+					if err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+
+					return reporter.CliResponse(ctx, reply.OK(resp))
+				}),
+			},
+			{
+				Name:      "test",
+				Usage:     app.T_("Run a hook with a sample transaction"),
+				ArgsUsage: "<name> [packages...]",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "event",
+						Usage: app.T_("Transaction event: install, remove, upgrade, image-apply, kernel-install"),
+					},
+					&cli.StringFlag{
+						Name:  "phase",
+						Usage: app.T_("Hook phase: pre or post"),
+						Value: "pre",
+					},
+				},
+				Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+					if cmd.Args().Len() == 0 {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(apmerr.New(apmerr.ErrorTypeValidation,
+							errors.New(app.TL_(ctx, "Hook name must be specified")))))
+					}
+
+					resp, err := actions.HooksTest(ctx, cmd.Args().First(), cmd.String("phase"), cmd.String("event"), cmd.Args().Tail())
+					if err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+
+					return reporter.CliResponse(ctx, reply.OK(resp))
+				}),
+			},
+		},
+	}
+}
+
+// ConfigCommand возвращает команды просмотра и изменения параметров конфигурации apm.
+func ConfigCommand(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
+	withGlobalWrapper := apmcli.WithOptions(appConfig, reporter, apmcli.NoRootCheck, NewActions, newErrorResponseFromError)
+	withRootCheckWrapper := apmcli.WithOptions(appConfig, reporter, apmcli.RequireRoot, NewActions, newErrorResponseFromError)
+
+	return &cli.Command{
+		Name:  "config",
+		Usage: app.T_("View and change apm settings"),
+		Commands: []*cli.Command{
+			{
+				Name:      "get",
+				Usage:     app.T_("Show the value of a parameter or all parameters"),
+				ArgsUsage: "[key]",
+				Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+					resp, err := actions.ConfigGet(ctx, cmd.Args().First())
+					if err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+
+					return reporter.CliResponse(ctx, reply.OK(resp))
+				}),
+			},
+			{
+				Name:  "list",
+				Usage: app.T_("Show all parameters"),
+				Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+					resp, err := actions.ConfigList(ctx)
+					if err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+
+					return reporter.CliResponse(ctx, reply.OK(resp))
+				}),
+			},
+			{
+				Name:      "set",
+				Usage:     app.T_("Set a parameter, for example: apm config set network.limit 2M"),
+				ArgsUsage: "<key> <value>",
+				Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+					if cmd.Args().Len() != 2 {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(apmerr.New(apmerr.ErrorTypeValidation,
+							errors.New(app.TL_(ctx, "Parameter name and value must be specified")))))
+					}
+
+					resp, err := actions.ConfigSet(ctx, cmd.Args().Get(0), cmd.Args().Get(1))
+					if err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+
+					return reporter.CliResponse(ctx, reply.OK(resp))
+				}),
+			},
+			{
+				Name:      "unset",
+				Usage:     app.T_("Reset a parameter to its default value"),
+				ArgsUsage: "<key>",
+				Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+					if cmd.Args().Len() != 1 {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(apmerr.New(apmerr.ErrorTypeValidation,
+							errors.New(app.TL_(ctx, "Parameter name must be specified")))))
+					}
+
+					resp, err := actions.ConfigUnset(ctx, cmd.Args().First())
+					if err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+
+					return reporter.CliResponse(ctx, reply.OK(resp))
+				}),
+			},
+			{
+				Name:  "edit",
+				Usage: app.T_("Edit the configuration file in $EDITOR and save it after validation"),
+				Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+					resp, err := actions.ConfigEdit(ctx)
+					if err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+
+					return reporter.CliResponse(ctx, reply.OK(resp))
+				}),
+			},
+		},
+	}
+}
+
+// ServiceCommand возвращает команды управления юнитами systemd служб apm.
+func ServiceCommand(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
+	withGlobalWrapper := apmcli.WithOptions(appConfig, reporter, apmcli.NoRootCheck, NewActions, newErrorResponseFromError)
+	withRootCheckWrapper := apmcli.WithOptions(appConfig, reporter, apmcli.RequireRoot, NewActions, newErrorResponseFromError)
+
+	// serviceAction проверяет, что передано имя службы, и выполняет над ней действие
+	serviceAction := func(run func(actions *Actions, ctx context.Context, name string) (*ServiceResponse, error)) cli.ActionFunc {
+		return withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+			if cmd.Args().Len() != 1 {
+				return reporter.CliResponse(ctx, newErrorResponseFromError(apmerr.New(apmerr.ErrorTypeValidation,
+					errors.New(app.TL_(ctx, "Service name must be specified: dbus-system, dbus-session, http-server or auto-upgrade")))))
+			}
+
+			resp, err := run(actions, ctx, cmd.Args().First())
+			if err != nil {
+				return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+			}
+
+			return reporter.CliResponse(ctx, reply.OK(resp))
+		})
+	}
+
+	return &cli.Command{
+		Name:     "service",
+		Usage:    app.T_("systemd units for the apm D-Bus services, HTTP API and automatic upgrade timer"),
+		Category: app.T_("Services"),
+		Metadata: apmcli.Requires(apmcli.CapSystemd),
+		Commands: []*cli.Command{
+			{
+				Name:      "install",
+				Usage:     app.T_("Write the systemd units of a service without enabling it"),
+				ArgsUsage: "<dbus-system|dbus-session|http-server|auto-upgrade>",
+				Action:    serviceAction((*Actions).ServiceInstall),
+			},
+			{
+				Name:      "enable",
+				Usage:     app.T_("Enable a service. D-Bus services start on the first call, the HTTP API on the first connection"),
+				ArgsUsage: "<dbus-system|dbus-session|http-server|auto-upgrade>",
+				Action:    serviceAction((*Actions).ServiceEnable),
+			},
+			{
+				Name:      "disable",
+				Usage:     app.T_("Disable a service and remove its generated units"),
+				ArgsUsage: "<dbus-system|dbus-session|http-server|auto-upgrade>",
+				Action:    serviceAction((*Actions).ServiceDisable),
+			},
+			{
+				Name:      "status",
+				Usage:     app.T_("Show the state of a service or of all apm services"),
+				ArgsUsage: "[name]",
+				Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+					resp, err := actions.ServiceStatus(ctx, cmd.Args().First())
+					if err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+
+					return reporter.CliResponse(ctx, reply.OK(resp))
+				}),
+			},
+		},
+	}
+}
+
+// AttachCommand возвращает команду для отслеживания транзакции, выполняемой сервисом apm.
+func AttachCommand(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
+	withGlobalWrapper := apmcli.WithOptions(appConfig, reporter, apmcli.NoRootCheck, NewActions, newErrorResponseFromError)
+
+	return &cli.Command{
+		Name:     "attach",
+		Usage:    app.T_("Show progress of a transaction running in the apm service. Use the global --transaction flag to select it"),
+		Metadata: apmcli.Requires(apmcli.CapDBus),
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "session",
+				Usage: app.T_("Follow the session service instead of the system one"),
+			},
+		},
+		Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+			result, err := actions.Attach(ctx, cmd.String("transaction"), cmd.Bool("session"))
+			if errors.Is(err, context.Canceled) {
+				reply.StopSpinner(appConfig)
+				return nil
+			}
+			if err != nil {
+				return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+			}
+
+			return reporter.CliResponse(ctx, reply.APIResponse{Data: result.Data, Error: result.Error})
+		}),
+	}
+}
+
 func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 	withGlobalWrapper := apmcli.WithOptions(appConfig, reporter, apmcli.NoRootCheck, NewActions, newErrorResponseFromError)
 	withRootCheckWrapper := apmcli.WithOptions(appConfig, reporter, apmcli.RequireRoot, NewActions, newErrorResponseFromError)
@@ -186,9 +678,13 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 					Aliases: []string{"w"},
 					Usage:   app.T_("Working directory for the build"),
 				},
+				&cli.StringFlag{
+					Name:  "tag",
+					Usage: app.T_("Build an image variant on the host and store it under this tag without applying it"),
+				},
 			},
 			Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
-				resp, err := actions.ImageBuild(ctx, cmd.String("config"), cmd.String("workdir"))
+				resp, err := actions.ImageBuild(ctx, cmd.String("config"), cmd.String("workdir"), cmd.String("tag"))
 				if err != nil {
 					return reporter.CliResponse(ctx, newErrorResponseFromError(err))
 				}
@@ -226,8 +722,9 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 			imageCmds,
 			[]*cli.Command{
 				{
-					Name:  "apply",
-					Usage: app.T_("Rebuild and deploy a new system image"),
+					Name:     "apply",
+					Usage:    app.T_("Rebuild and deploy a new system image"),
+					Metadata: apmcli.Requires(apmcli.CapHost),
 					Flags: []cli.Flag{
 						&cli.BoolFlag{
 							Name:  "pull",
@@ -249,9 +746,52 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 							Aliases: []string{"w"},
 							Usage:   app.T_("Working directory for the build"),
 						},
+						&cli.StringFlag{
+							Name:  "tag",
+							Usage: app.T_("Switch to an image variant built with 'image build --tag'"),
+						},
+						&cli.StringFlag{
+							Name:  "at",
+							Usage: app.T_("Postpone the apply: shutdown, idle or a time in HH:MM format"),
+						},
+						&cli.BoolFlag{
+							Name:  "cancel-scheduled",
+							Usage: app.T_("Cancel a postponed image apply"),
+							Value: false,
+						},
+						&cli.BoolFlag{
+							Name:   "run-scheduled",
+							Hidden: true,
+							Value:  false,
+						},
+						&cli.BoolFlag{
+							Name:    "simulate",
+							Usage:   app.T_("Show the image that would be built or deployed without applying it"),
+							Aliases: []string{"s"},
+							Value:   false,
+						},
 					},
 					Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
-						resp, err := actions.ImageApply(ctx, cmd.Bool("pull"), !cmd.Bool("no-cache"), cmd.String("config"), cmd.String("workdir"))
+						var resp any
+						var err error
+						switch {
+						case cmd.Bool("simulate") && cmd.Bool("cancel-scheduled"):
+							resp, err = actions.CheckImageApplyCancelScheduled(ctx)
+						case cmd.Bool("simulate"):
+							resp, err = actions.CheckImageApply(ctx, cmd.String("at"), cmd.Bool("pull"), cmd.String("config"), cmd.String("workdir"), cmd.String("tag"))
+						case cmd.Bool("cancel-scheduled"):
+							resp, err = actions.ImageApplyCancelScheduled(ctx)
+						case cmd.Bool("run-scheduled"):
+							resp, err = actions.ImageApplyRunScheduled(ctx)
+						case cmd.String("at") != "":
+							if cmd.String("tag") != "" {
+								err = apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "The --at flag cannot be combined with --tag")))
+								break
+							}
+							resp, err = actions.ImageApplySchedule(ctx, cmd.String("at"), cmd.Bool("pull"), !cmd.Bool("no-cache"), cmd.String("config"), cmd.String("workdir"))
+						default:
+							resp, err = actions.ImageApply(ctx, cmd.Bool("pull"), !cmd.Bool("no-cache"), cmd.String("config"), cmd.String("workdir"), cmd.String("tag"))
+						}
 						if err != nil {
 							return reporter.CliResponse(ctx, newErrorResponseFromError(err))
 						}
@@ -260,8 +800,9 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 					}),
 				},
 				{
-					Name:  "status",
-					Usage: app.T_("Image status"),
+					Name:     "status",
+					Usage:    app.T_("Image status"),
+					Metadata: apmcli.Requires(apmcli.CapHost),
 					Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
 						resp, err := actions.ImageStatus(ctx)
 						if err != nil {
@@ -272,8 +813,9 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 					}),
 				},
 				{
-					Name:  "update",
-					Usage: app.T_("Upgrade system image"),
+					Name:     "update",
+					Usage:    app.T_("Upgrade system image"),
+					Metadata: apmcli.Requires(apmcli.CapHost),
 					Flags: []cli.Flag{
 						&cli.BoolFlag{
 							Name:  "no-cache",
@@ -291,9 +833,23 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 					}),
 				},
 				{
-					Name:  "history",
-					Usage: app.T_("Image changes history"),
-					Flags: []cli.Flag{
+					Name:     "rollback",
+					Usage:    app.T_("Boot the previous deployment and restore its image configuration"),
+					Metadata: apmcli.Requires(apmcli.CapHost),
+					Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+						resp, err := actions.ImageRollback(ctx)
+						if err != nil {
+							return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+						}
+
+						return reporter.CliResponse(ctx, reply.OK(resp))
+					}),
+				},
+				{
+					Name:     "history",
+					Usage:    app.T_("Image changes history"),
+					Metadata: apmcli.Requires(apmcli.CapHost),
+					Flags: []cli.Flag{
 						&cli.StringFlag{
 							Name:  "image",
 							Usage: app.T_("Filter by image name"),
@@ -318,6 +874,59 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 						return reporter.CliResponse(ctx, reply.OK(resp))
 					}),
 				},
+				{
+					Name:      "diff",
+					Usage:     app.T_("Show package changes between two image history records"),
+					Metadata:  apmcli.Requires(apmcli.CapHost),
+					ArgsUsage: "<id1> <id2>",
+					Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+						ids := make([]uint, 2)
+						for i := range ids {
+							value, err := strconv.ParseUint(cmd.Args().Get(i), 10, 32)
+							if err != nil {
+								return reporter.CliResponse(ctx, newErrorResponseFromError(apmerr.New(apmerr.ErrorTypeValidation,
+									fmt.Errorf(app.TL_(ctx, "Invalid image history record ID: %s"), cmd.Args().Get(i)))))
+							}
+							ids[i] = uint(value)
+						}
+
+						resp, err := actions.ImageDiff(ctx, ids[0], ids[1])
+						if err != nil {
+							return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+						}
+
+						return reporter.CliResponse(ctx, reply.OK(resp))
+					}),
+				},
+				{
+					Name:      "logs",
+					Usage:     app.T_("Show the build log of an image generation"),
+					Metadata:  apmcli.Requires(apmcli.CapHost),
+					ArgsUsage: "[generation]",
+					Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+						generation := 0
+						if cmd.Args().Len() > 0 {
+							value, err := strconv.Atoi(cmd.Args().First())
+							if err != nil || value < 1 {
+								return reporter.CliResponse(ctx, newErrorResponseFromError(apmerr.New(apmerr.ErrorTypeValidation,
+									fmt.Errorf(app.TL_(ctx, "Invalid generation number: %s"), cmd.Args().First()))))
+							}
+							generation = value
+						}
+
+						resp, err := actions.ImageLogs(ctx, generation)
+						if err != nil {
+							return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+						}
+						if appConfig.ConfigManager.GetConfig().Format != app.FormatText {
+							return reporter.CliResponse(ctx, reply.OK(resp))
+						}
+
+						reply.StopSpinner(appConfig)
+						fmt.Print(resp.Content)
+						return nil
+					}),
+				},
 				{
 					Name:   "fix-nss",
 					Hidden: true,
@@ -414,10 +1023,44 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 					Aliases: []string{"d"},
 					Value:   false,
 				},
+				&cli.StringFlag{
+					Name:  "on-conflict",
+					Usage: app.T_("File conflict resolution policy: replace, skip or remove"),
+				},
+				&cli.BoolFlag{
+					Name:  "force-essential",
+					Usage: app.T_("Allow removing protected packages after typing a confirmation phrase"),
+				},
+				&cli.BoolFlag{
+					Name:  "allow-unsigned",
+					Usage: app.T_("Install local RPM files without signature verification"),
+				},
+				&cli.StringFlag{
+					Name:  "download-dir",
+					Usage: app.T_("Save downloaded packages and their manifest to the directory. Implies --download-only"),
+				},
+				&cli.StringFlag{
+					Name:  "offline",
+					Usage: app.T_("Install the whole set previously downloaded with --download-dir, without repository access"),
+				},
 				aptOptionFlag(),
 			},
 			Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
 				applyAptOptions(cmd, actions)
+				actions.SetForceEssential(cmd.Bool("force-essential"))
+				actions.SetAllowUnsigned(cmd.Bool("allow-unsigned"))
+				downloadOnly := cmd.Bool("download-only") || cmd.String("download-dir") != ""
+				if cmd.String("offline") != "" && downloadOnly {
+					return reporter.CliResponse(ctx, newErrorResponseFromError(apmerr.New(apmerr.ErrorTypeValidation,
+						errors.New(app.TL_(ctx, "--offline cannot be combined with --download-only or --download-dir")))))
+				}
+				actions.SetDownloadDir(cmd.String("download-dir"))
+				actions.SetOfflineDir(cmd.String("offline"))
+				policy, err := apt.ParseConflictPolicy(cmd.String("on-conflict"))
+				if err != nil {
+					return reporter.CliResponse(ctx, newErrorResponseFromError(apmerr.New(apmerr.ErrorTypeValidation, err)))
+				}
+				actions.SetConflictPolicy(policy)
 				if cmd.Bool("simulate") {
 					resp, err := actions.CheckInstall(ctx, cmd.Args().Slice())
 					if err != nil {
@@ -425,7 +1068,7 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 					}
 					return reporter.CliResponse(ctx, reply.OK(resp))
 				}
-				resp, err := actions.Install(ctx, cmd.Args().Slice(), cmd.Bool("yes"), cmd.Bool("download-only"))
+				resp, err := actions.Install(ctx, cmd.Args().Slice(), cmd.Bool("yes"), downloadOnly)
 				if err != nil {
 					return reporter.CliResponse(ctx, newErrorResponseFromError(err))
 				}
@@ -457,10 +1100,15 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 					Aliases: []string{"s"},
 					Value:   false,
 				},
+				&cli.BoolFlag{
+					Name:  "force-essential",
+					Usage: app.T_("Allow removing protected packages after typing a confirmation phrase"),
+				},
 				aptOptionFlag(),
 			},
 			Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
 				applyAptOptions(cmd, actions)
+				actions.SetForceEssential(cmd.Bool("force-essential"))
 				if cmd.Bool("simulate") {
 					resp, err := actions.CheckRemove(ctx, cmd.Args().Slice(), false, cmd.Bool("depends"))
 					if err != nil {
@@ -477,6 +1125,101 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 			}),
 			ShellComplete: findPkgWithInstalled(appConfig, reporter, true),
 		},
+		{
+			Name:  "autoremove",
+			Usage: app.T_("Remove packages that were installed as dependencies and are no longer needed"),
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:    "yes",
+					Usage:   app.T_("Remove without confirmation"),
+					Aliases: []string{"y"},
+					Value:   false,
+				},
+				&cli.BoolFlag{
+					Name:    "simulate",
+					Usage:   app.T_("Simulate removal"),
+					Aliases: []string{"s"},
+					Value:   false,
+				},
+				&cli.BoolFlag{
+					Name:  "force-essential",
+					Usage: app.T_("Allow removing protected packages after typing a confirmation phrase"),
+				},
+				aptOptionFlag(),
+			},
+			Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+				applyAptOptions(cmd, actions)
+				actions.SetForceEssential(cmd.Bool("force-essential"))
+				if cmd.Bool("simulate") {
+					resp, err := actions.CheckAutoRemove(ctx)
+					if err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+					return reporter.CliResponse(ctx, reply.OK(resp))
+				}
+				resp, err := actions.AutoRemove(ctx, cmd.Bool("yes"))
+				if err != nil {
+					return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+				}
+				return reporter.CliResponse(ctx, reply.OK(resp))
+			}),
+		},
+		{
+			Name:      "hold",
+			Usage:     app.T_("Hold installed packages back from system upgrades"),
+			ArgsUsage: "packages",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:    "simulate",
+					Usage:   app.T_("Show the changes without applying them"),
+					Aliases: []string{"s"},
+					Value:   false,
+				},
+			},
+			Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+				if cmd.Bool("simulate") {
+					resp, err := actions.CheckHold(ctx, cmd.Args().Slice())
+					if err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+					return reporter.CliResponse(ctx, reply.OK(resp))
+				}
+				resp, err := actions.Hold(ctx, cmd.Args().Slice())
+				if err != nil {
+					return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+				}
+				return reporter.CliResponse(ctx, reply.OK(resp))
+			}),
+			ShellComplete: findPkgWithInstalled(appConfig, reporter, true),
+		},
+		{
+			Name:      "unhold",
+			Usage:     app.T_("Return held packages to system upgrades"),
+			ArgsUsage: "packages",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:    "simulate",
+					Usage:   app.T_("Show the changes without applying them"),
+					Aliases: []string{"s"},
+					Value:   false,
+				},
+			},
+			Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+				if cmd.Bool("simulate") {
+					resp, err := actions.CheckUnhold(ctx, cmd.Args().Slice())
+					if err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+					return reporter.CliResponse(ctx, reply.OK(resp))
+				}
+				resp, err := actions.Unhold(ctx, cmd.Args().Slice())
+				if err != nil {
+					return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+				}
+				return reporter.CliResponse(ctx, reply.OK(resp))
+			}),
+			ShellComplete: findPkgWithInstalled(appConfig, reporter, true),
+		},
 		{
 			Name:  "update",
 			Usage: app.T_("Updating package database"),
@@ -502,7 +1245,291 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 				return reporter.CliResponse(ctx, reply.OK(resp))
 			}),
 		},
+		{
+			Name:  "watch",
+			Usage: app.T_("Periodically refresh the package database and report new upgrades, kernels and repository changes"),
+			Flags: []cli.Flag{
+				&cli.IntFlag{
+					Name:  "interval",
+					Usage: app.T_("Interval between checks in minutes, defaults to watchInterval from the configuration"),
+				},
+			},
+			Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+				isText := appConfig.ConfigManager.GetConfig().Format == app.FormatText
+				interval := time.Duration(cmd.Int("interval")) * time.Minute
+
+				err := actions.Watch(ctx, interval, func(change WatchChange) {
+					reply.StopSpinner(appConfig)
+					if !isText {
+						if data, errMarshal := json.Marshal(change); errMarshal == nil {
+							fmt.Println(string(data))
+						}
+						return
+					}
+					fmt.Printf("[%s] %s\n", change.Date, change.Message)
+					for _, item := range change.Added {
+						fmt.Println("  + " + item)
+					}
+					for _, item := range change.Removed {
+						fmt.Println("  - " + item)
+					}
+				})
+				if err != nil {
+					return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+				}
+				return nil
+			}),
+		},
 		upgradeCommand(appConfig, reporter),
+		{
+			Name:  "repair",
+			Usage: app.T_("Repair the package state after an interrupted transaction"),
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:    "yes",
+					Usage:   app.T_("Apply repairs without confirmation"),
+					Aliases: []string{"y"},
+					Value:   false,
+				},
+				&cli.BoolFlag{
+					Name:    "simulate",
+					Usage:   app.T_("Show stale package versions and dependency fixes without applying them"),
+					Aliases: []string{"s"},
+					Value:   false,
+				},
+			},
+			Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+				if cmd.Bool("simulate") {
+					resp, err := actions.CheckRepair(ctx)
+					if err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+					return reporter.CliResponse(ctx, reply.OK(resp))
+				}
+				resp, err := actions.Repair(ctx, cmd.Bool("yes"))
+				if err != nil {
+					return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+				}
+				return reporter.CliResponse(ctx, reply.OK(resp))
+			}),
+		},
+		{
+			Name:  "clean",
+			Usage: app.T_("Remove downloaded packages and report the freed space"),
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "cache",
+					Usage: app.T_("Remove packages downloaded by APT and orphaned database records (default)"),
+				},
+				&cli.BoolFlag{
+					Name:  "downloads",
+					Usage: app.T_("Remove local packages saved in the image resources that the image configuration no longer uses"),
+				},
+				&cli.BoolFlag{
+					Name:  "all",
+					Usage: app.T_("Clean everything"),
+				},
+				&cli.BoolFlag{
+					Name:    "simulate",
+					Usage:   app.T_("Show what would be removed without actually removing"),
+					Aliases: []string{"s"},
+				},
+			},
+			Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+				all := cmd.Bool("all")
+				if cmd.Bool("simulate") {
+					resp, err := actions.CheckClean(ctx, all || cmd.Bool("cache"), all || cmd.Bool("downloads"))
+					if err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+					return reporter.CliResponse(ctx, reply.OK(resp))
+				}
+				resp, err := actions.Clean(ctx, all || cmd.Bool("cache"), all || cmd.Bool("downloads"))
+				if err != nil {
+					return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+				}
+				return reporter.CliResponse(ctx, reply.OK(resp))
+			}),
+		},
+		{
+			Name:     "restart-services",
+			Usage:    app.T_("List services that use libraries replaced by an upgrade and restart them"),
+			Metadata: apmcli.Requires(apmcli.CapSystemd),
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "auto",
+					Usage: app.T_("Restart the found services, except those listed in restartBlacklist"),
+					Value: false,
+				},
+			},
+			Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+				resp, err := actions.RestartServices(ctx, cmd.Bool("auto"))
+				if err != nil {
+					return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+				}
+				return reporter.CliResponse(ctx, reply.OK(resp))
+			}),
+		},
+		{
+			Name:  "auto-upgrade",
+			Usage: app.T_("Scheduled unattended upgrades"),
+			Commands: []*cli.Command{
+				{
+					Name:  "run",
+					Usage: app.T_("Update package lists and apply upgrades according to the policy"),
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "policy",
+							Usage: app.T_("Upgrade policy: security, all or none. Defaults to autoUpgrade.policy from the configuration"),
+						},
+					},
+					Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+						resp, err := actions.AutoUpgrade(ctx, cmd.String("policy"))
+						if err != nil {
+							return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+						}
+						return reporter.CliResponse(ctx, reply.OK(resp))
+					}),
+				},
+				{
+					Name:     "enable",
+					Usage:    app.T_("Create and start the systemd timer for automatic upgrades"),
+					Metadata: apmcli.Requires(apmcli.CapSystemd),
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "on-calendar",
+							Usage: app.T_("Timer schedule in systemd OnCalendar format. Defaults to autoUpgrade.onCalendar from the configuration"),
+						},
+					},
+					Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+						resp, err := actions.AutoUpgradeEnable(ctx, cmd.String("on-calendar"))
+						if err != nil {
+							return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+						}
+						return reporter.CliResponse(ctx, reply.OK(resp))
+					}),
+				},
+				{
+					Name:     "disable",
+					Usage:    app.T_("Stop and remove the automatic upgrade timer"),
+					Metadata: apmcli.Requires(apmcli.CapSystemd),
+					Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+						resp, err := actions.AutoUpgradeDisable(ctx)
+						if err != nil {
+							return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+						}
+						return reporter.CliResponse(ctx, reply.OK(resp))
+					}),
+				},
+				{
+					Name:     "status",
+					Usage:    app.T_("Show the automatic upgrade timer state"),
+					Metadata: apmcli.Requires(apmcli.CapSystemd),
+					Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+						resp, err := actions.AutoUpgradeStatus(ctx)
+						if err != nil {
+							return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+						}
+						return reporter.CliResponse(ctx, reply.OK(resp))
+					}),
+				},
+			},
+		},
+		{
+			Name:  "group",
+			Usage: app.T_("Package groups for typical tasks"),
+			Commands: []*cli.Command{
+				{
+					Name:  "list",
+					Usage: app.T_("List package groups"),
+					Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+						resp, err := actions.GroupList(ctx)
+						if err != nil {
+							return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+						}
+						return reporter.CliResponse(ctx, reply.OK(resp))
+					}),
+				},
+				{
+					Name:      "info",
+					Usage:     app.T_("Package group information"),
+					ArgsUsage: "group",
+					Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+						resp, err := actions.GroupInfo(ctx, cmd.Args().First())
+						if err != nil {
+							return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+						}
+						return reporter.CliResponse(ctx, reply.OK(resp))
+					}),
+				},
+				{
+					Name:      "install",
+					Usage:     app.T_("Install packages of the groups"),
+					ArgsUsage: "groups",
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:    "yes",
+							Usage:   app.T_("Install without confirmation"),
+							Aliases: []string{"y"},
+						},
+						&cli.BoolFlag{
+							Name:    "simulate",
+							Usage:   app.T_("Simulate installation"),
+							Aliases: []string{"s"},
+						},
+						&cli.BoolFlag{
+							Name:  "with-optional",
+							Usage: app.T_("Also install optional packages of the groups"),
+						},
+					},
+					Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+						if cmd.Bool("simulate") {
+							resp, err := actions.GroupCheckInstall(ctx, cmd.Args().Slice(), cmd.Bool("with-optional"))
+							if err != nil {
+								return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+							}
+							return reporter.CliResponse(ctx, reply.OK(resp))
+						}
+						resp, err := actions.GroupInstall(ctx, cmd.Args().Slice(), cmd.Bool("with-optional"), cmd.Bool("yes"))
+						if err != nil {
+							return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+						}
+						return reporter.CliResponse(ctx, reply.OK(resp))
+					}),
+				},
+				{
+					Name:      "remove",
+					Usage:     app.T_("Remove installed packages of the groups, including optional ones"),
+					ArgsUsage: "groups",
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:    "yes",
+							Usage:   app.T_("Remove without confirmation"),
+							Aliases: []string{"y"},
+						},
+						&cli.BoolFlag{
+							Name:    "simulate",
+							Usage:   app.T_("Simulate removal"),
+							Aliases: []string{"s"},
+						},
+					},
+					Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+						if cmd.Bool("simulate") {
+							resp, err := actions.GroupCheckRemove(ctx, cmd.Args().Slice())
+							if err != nil {
+								return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+							}
+							return reporter.CliResponse(ctx, reply.OK(resp))
+						}
+						resp, err := actions.GroupRemove(ctx, cmd.Args().Slice(), cmd.Bool("yes"))
+						if err != nil {
+							return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+						}
+						return reporter.CliResponse(ctx, reply.OK(resp))
+					}),
+				},
+			},
+		},
 		{
 			Name:      "info",
 			Usage:     app.T_("Package information"),
@@ -543,13 +1570,24 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 					Usage: app.T_("Full information output"),
 					Value: false,
 				},
+				&cli.BoolFlag{
+					Name:    "description",
+					Usage:   app.T_("Also search in descriptions and AppStream keywords, sorting results by relevance"),
+					Aliases: []string{"d"},
+				},
+				apmcli.ColumnsFlag(),
 			},
 			Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
-				resp, err := actions.Search(ctx, cmd.Args().First(), cmd.Bool("installed"))
+				columns, err := apmcli.ApplyColumns(appConfig, cmd.StringSlice("columns"), _package.Package{})
 				if err != nil {
 					return reporter.CliResponse(ctx, newErrorResponseFromError(err))
 				}
-				full := cmd.Bool("full")
+
+				resp, err := actions.Search(ctx, strings.Join(cmd.Args().Slice(), " "), cmd.Bool("installed"), cmd.Bool("description"))
+				if err != nil {
+					return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+				}
+				full := cmd.Bool("full") || columns
 				return reporter.CliResponse(ctx, reply.OK(map[string]interface{}{
 					"message":  reply.MessageWithHint(resp.Message, full),
 					"packages": actions.FormatPackageOutput(resp.Packages, full),
@@ -567,6 +1605,246 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 				return reporter.CliResponse(ctx, reply.OK(resp))
 			}),
 		},
+		{
+			Name:      "files",
+			Usage:     app.T_("List files of a package"),
+			ArgsUsage: "package",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "type",
+					Usage: app.T_("Show only files of the given type: bin, config or doc"),
+				},
+			},
+			Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+				resp, err := actions.Files(ctx, cmd.Args().First(), cmd.String("type"))
+				if err != nil {
+					return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+				}
+				return reporter.CliResponse(ctx, reply.OK(resp))
+			}),
+		},
+		{
+			Name:      "provides",
+			Aliases:   []string{"whatprovides"},
+			Usage:     app.T_("Find packages that own a file or provide a library"),
+			ArgsUsage: "path|library",
+			Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+				resp, err := actions.Provides(ctx, cmd.Args().First())
+				if err != nil {
+					return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+				}
+				return reporter.CliResponse(ctx, reply.OK(resp))
+			}),
+		},
+		{
+			Name:      "changelog",
+			Usage:     app.T_("Show changelog entries of the candidate version that are newer than the installed one"),
+			ArgsUsage: "package",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "since",
+					Usage: app.T_("Show entries newer than the given version instead of the installed one"),
+				},
+			},
+			Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+				resp, err := actions.Changelog(ctx, cmd.Args().First(), cmd.String("since"))
+				if err != nil {
+					return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+				}
+				return reporter.CliResponse(ctx, reply.OK(resp))
+			}),
+			ShellComplete: findPkgWithInstalled(appConfig, reporter, false),
+		},
+		{
+			Name:      "depends",
+			Usage:     app.T_("Show the dependency tree of a package"),
+			ArgsUsage: "package",
+			Flags: []cli.Flag{
+				&cli.IntFlag{
+					Name:  "depth",
+					Usage: app.T_("How many levels of dependencies to follow"),
+					Value: 1,
+				},
+				&cli.BoolFlag{
+					Name:  "installed",
+					Usage: app.T_("Resolve dependencies only to installed packages"),
+					Value: false,
+				},
+				dotFlag(),
+			},
+			Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+				resp, err := actions.Depends(ctx, cmd.Args().First(), cmd.Int("depth"), cmd.Bool("installed"))
+				if err != nil {
+					return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+				}
+				return dependencyTreeResponse(ctx, appConfig, reporter, cmd, resp, resp.Message, resp.Tree, false)
+			}),
+			ShellComplete: findPkgInfoOnlyFirstArg(appConfig, reporter),
+		},
+		{
+			Name:      "rdepends",
+			Usage:     app.T_("Show packages that depend on a package or capability"),
+			ArgsUsage: "package|capability",
+			Flags: []cli.Flag{
+				&cli.IntFlag{
+					Name:  "depth",
+					Usage: app.T_("How many levels of reverse dependencies to follow"),
+					Value: 1,
+				},
+				&cli.BoolFlag{
+					Name:  "installed",
+					Usage: app.T_("Only installed packages"),
+					Value: false,
+				},
+				dotFlag(),
+			},
+			Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+				resp, err := actions.ReverseDepends(ctx, cmd.Args().First(), cmd.Int("depth"), cmd.Bool("installed"))
+				if err != nil {
+					return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+				}
+				return dependencyTreeResponse(ctx, appConfig, reporter, cmd, resp, resp.Message, resp.Tree, true)
+			}),
+			ShellComplete: findPkgInfoOnlyFirstArg(appConfig, reporter),
+		},
+		{
+			Name:  "orphans",
+			Usage: app.T_("Show installed packages that are no longer provided by the configured repositories"),
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "by-repo",
+					Usage: app.T_("Find packages whose source repository is no longer configured"),
+				},
+				&cli.StringSliceFlag{
+					Name:  "keep",
+					Usage: app.T_("Keep the package: exclude it from the report and from removal"),
+				},
+				&cli.BoolFlag{
+					Name:  "remove",
+					Usage: app.T_("Remove the found packages"),
+				},
+				&cli.BoolFlag{
+					Name:  "replace",
+					Usage: app.T_("Install replacements for the found packages from the active repositories"),
+				},
+				&cli.BoolFlag{
+					Name:    "yes",
+					Usage:   app.T_("Apply without confirmation"),
+					Aliases: []string{"y"},
+				},
+				&cli.BoolFlag{
+					Name:    "simulate",
+					Usage:   app.T_("Show changes of --remove or --replace without applying them"),
+					Aliases: []string{"s"},
+				},
+			},
+			Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+				if !cmd.Bool("by-repo") {
+					return reporter.CliResponse(ctx, newErrorResponseFromError(apmerr.New(apmerr.ErrorTypeValidation,
+						errors.New(app.TL_(ctx, "Specify which orphaned packages to find, for example --by-repo")))))
+				}
+				if cmd.Bool("remove") && cmd.Bool("replace") {
+					return reporter.CliResponse(ctx, newErrorResponseFromError(apmerr.New(apmerr.ErrorTypeValidation,
+						errors.New(app.TL_(ctx, "Flags --remove and --replace cannot be used together")))))
+				}
+
+				apply := cmd.Bool("remove") || cmd.Bool("replace")
+				simulate := apply && cmd.Bool("simulate")
+				if apply && !simulate {
+					if err := apmcli.CheckRoot(apmcli.RequireRoot); err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(apmerr.New(apmerr.ErrorTypePermission, err)))
+					}
+				}
+
+				resp, err := actions.Orphans(ctx, cmd.StringSlice("keep"))
+				if err != nil || !apply || resp.Count == 0 {
+					if err != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+					}
+					return reporter.CliResponse(ctx, reply.OK(resp))
+				}
+
+				if cmd.Bool("remove") {
+					if simulate {
+						check, errCheck := actions.CheckRemove(ctx, orphanNames(resp), false, false)
+						if errCheck != nil {
+							return reporter.CliResponse(ctx, newErrorResponseFromError(errCheck))
+						}
+						return reporter.CliResponse(ctx, reply.OK(check))
+					}
+					removed, errRemove := actions.Remove(ctx, orphanNames(resp), false, false, cmd.Bool("yes"))
+					if errRemove != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(errRemove))
+					}
+					return reporter.CliResponse(ctx, reply.OK(removed))
+				}
+
+				replacements := orphanReplacements(resp)
+				if len(replacements) == 0 {
+					return reporter.CliResponse(ctx, newErrorResponseFromError(apmerr.New(apmerr.ErrorTypeNoOperation,
+						errors.New(app.TL_(ctx, "No replacements found in the active repositories")))))
+				}
+				if simulate {
+					check, errCheck := actions.CheckInstall(ctx, replacements)
+					if errCheck != nil {
+						return reporter.CliResponse(ctx, newErrorResponseFromError(errCheck))
+					}
+					return reporter.CliResponse(ctx, reply.OK(check))
+				}
+				installed, errInstall := actions.Install(ctx, replacements, cmd.Bool("yes"), false)
+				if errInstall != nil {
+					return reporter.CliResponse(ctx, newErrorResponseFromError(errInstall))
+				}
+				return reporter.CliResponse(ctx, reply.OK(installed))
+			}),
+		},
+		{
+			Name:  "recent",
+			Usage: app.T_("Show recently installed and removed packages with commands to repeat or undo them"),
+			Flags: []cli.Flag{
+				&cli.IntFlag{
+					Name:  "days",
+					Usage: app.T_("Number of days to show"),
+					Value: 7,
+				},
+			},
+			Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+				resp, err := actions.Recent(ctx, cmd.Int("days"))
+				if err != nil {
+					return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+				}
+				return reporter.CliResponse(ctx, reply.OK(resp))
+			}),
+		},
+		{
+			Name:      "rollback",
+			Usage:     app.T_("Roll back a package transaction from the journal: remove installed packages, restore removed ones and downgrade upgraded ones"),
+			ArgsUsage: "<id|transaction>",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:    "yes",
+					Usage:   app.T_("Roll back without confirmation"),
+					Aliases: []string{"y"},
+				},
+				&cli.BoolFlag{
+					Name:    "simulate",
+					Usage:   app.T_("Simulate the rollback"),
+					Aliases: []string{"s"},
+				},
+				&cli.BoolFlag{
+					Name:  "force-essential",
+					Usage: app.T_("Allow removing protected packages after typing a confirmation phrase"),
+				},
+			},
+			Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+				actions.SetForceEssential(cmd.Bool("force-essential"))
+				resp, err := actions.Rollback(ctx, cmd.Args().First(), cmd.Bool("yes"), cmd.Bool("simulate"))
+				if err != nil {
+					return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+				}
+				return reporter.CliResponse(ctx, reply.OK(resp))
+			}),
+		},
 		{
 			Name:  "list",
 			Usage: app.T_("Building a query to get a list of packages"),
@@ -608,12 +1886,25 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 					Usage: app.T_("Full information output"),
 					Value: false,
 				},
+				&cli.BoolFlag{
+					Name:  "upgradable",
+					Usage: app.T_("Only installed packages with a newer candidate version, same as --filter upgradable=true"),
+				},
+				apmcli.ColumnsFlag(),
 			},
 			Action: withGlobalWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
 				filters, err := _package.SystemFilterConfig.Parse(cmd.StringSlice("filter"))
 				if err != nil {
 					return reporter.CliResponse(ctx, newErrorResponseFromError(err))
 				}
+				if cmd.Bool("upgradable") {
+					filters = append(filters, filter.Filter{Field: "upgradable", Op: filter.OpEq, Value: "true"})
+				}
+
+				columns, err := apmcli.ApplyColumns(appConfig, cmd.StringSlice("columns"), _package.Package{})
+				if err != nil {
+					return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+				}
 
 				params := ListParams{
 					Sort:        cmd.String("sort"),
@@ -628,10 +1919,14 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 				if err != nil {
 					return reporter.CliResponse(ctx, newErrorResponseFromError(err))
 				}
-				full := cmd.Bool("full")
+				full := cmd.Bool("full") || columns
+				var packages interface{} = actions.FormatPackageOutput(resp.Packages, full)
+				if cmd.Bool("upgradable") && !full {
+					packages = actions.FormatUpgradableOutput(resp.Packages)
+				}
 				return reporter.CliResponse(ctx, reply.OK(map[string]interface{}{
 					"message":    reply.MessageWithHint(resp.Message, full),
-					"packages":   actions.FormatPackageOutput(resp.Packages, full),
+					"packages":   packages,
 					"totalCount": resp.TotalCount,
 				}))
 			}),
@@ -642,6 +1937,12 @@ func CommandList(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
 			Category: app.T_("Applications"),
 			Commands: appstream.CommandList(appConfig, reporter),
 		},
+		{
+			Name:     "apps",
+			Usage:    app.T_("Application catalogue for software centers"),
+			Category: app.T_("Applications"),
+			Commands: appstream.AppsCommandList(appConfig, reporter),
+		},
 		{
 			Name:     "image",
 			Usage:    app.T_("Module for working with the image"),
@@ -0,0 +1,348 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/network"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+
+	goyaml "github.com/goccy/go-yaml"
+)
+
+// configKey параметр конфигурации, изменяемый через apm config
+type configKey struct {
+	name string
+	get  func(cfg *app.Configuration) string
+	set  func(cfg *app.Configuration, value string) error
+}
+
+// stringKey строковый параметр с необязательной проверкой значения.
+func stringKey(name string, field func(cfg *app.Configuration) *string, validate func(string) error) configKey {
+	return configKey{
+		name: name,
+		get:  func(cfg *app.Configuration) string { return *field(cfg) },
+		set: func(cfg *app.Configuration, value string) error {
+			if validate != nil {
+				if err := validate(value); err != nil {
+					return err
+				}
+			}
+			*field(cfg) = value
+			return nil
+		},
+	}
+}
+
+// enumKey строковый параметр с фиксированным набором значений.
+func enumKey(name string, field func(cfg *app.Configuration) *string, allowed ...string) configKey {
+	return stringKey(name, field, func(value string) error {
+		if !slices.Contains(allowed, value) {
+			return fmt.Errorf(app.T_("Invalid value %q for %s, expected one of: %s"), value, name, strings.Join(allowed, ", "))
+		}
+		return nil
+	})
+}
+
+// boolKey логический параметр.
+func boolKey(name string, field func(cfg *app.Configuration) *bool) configKey {
+	return configKey{
+		name: name,
+		get:  func(cfg *app.Configuration) string { return strconv.FormatBool(*field(cfg)) },
+		set: func(cfg *app.Configuration, value string) error {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf(app.T_("Invalid value %q for %s, expected true or false"), value, name)
+			}
+			*field(cfg) = parsed
+			return nil
+		},
+	}
+}
+
+// intKey положительный целочисленный параметр.
+func intKey(name string, field func(cfg *app.Configuration) *int) configKey {
+	return configKey{
+		name: name,
+		get:  func(cfg *app.Configuration) string { return strconv.Itoa(*field(cfg)) },
+		set: func(cfg *app.Configuration, value string) error {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 {
+				return fmt.Errorf(app.T_("Invalid value %q for %s, expected a positive number"), value, name)
+			}
+			*field(cfg) = parsed
+			return nil
+		},
+	}
+}
+
+// validateListen проверяет адрес вида host:port.
+func validateListen(value string) error {
+	if value == "" {
+		return nil
+	}
+	if _, port, err := net.SplitHostPort(value); err != nil || port == "" {
+		return fmt.Errorf(app.T_("Invalid listen address %q, expected host:port"), value)
+	}
+	return nil
+}
+
+// validateSocketMode проверяет права доступа к сокету в восьмеричной записи.
+func validateSocketMode(value string) error {
+	if value == "" {
+		return nil
+	}
+	if mode, err := strconv.ParseUint(value, 8, 32); err != nil || mode > 0o777 {
+		return fmt.Errorf(app.T_("Invalid unix socket mode %q: expected an octal value such as 0660"), value)
+	}
+	return nil
+}
+
+// validateLimit проверяет ограничение скорости загрузки.
+func validateLimit(value string) error {
+	_, err := network.ParseLimit(value)
+	return err
+}
+
+// configKeys параметры, доступные для apm config
+var configKeys = []configKey{
+	enumKey("formatType", func(cfg *app.Configuration) *string { return &cfg.FormatType }, app.FormatTypeTree, app.FormatTypePlain),
+	boolKey("accessible", func(cfg *app.Configuration) *bool { return &cfg.Accessible }),
+	intKey("accessibleInterval", func(cfg *app.Configuration) *int { return &cfg.AccessibleInterval }),
+	enumKey("atomicMode", func(cfg *app.Configuration) *string { return &cfg.AtomicMode }, app.AtomicModeAuto, app.AtomicModeOn, app.AtomicModeOff),
+	enumKey("selfUpdateChannel", func(cfg *app.Configuration) *string { return &cfg.SelfUpdateChannel }, "stable", "testing"),
+	enumKey("nonInteractiveAnswer", func(cfg *app.Configuration) *string { return &cfg.NonInteractiveAnswer }, "yes", "no"),
+	boolKey("polkitFallback", func(cfg *app.Configuration) *bool { return &cfg.PolkitFallback }),
+	boolKey("verifyDownloads", func(cfg *app.Configuration) *bool { return &cfg.VerifyDownloads }),
+	intKey("watchInterval", func(cfg *app.Configuration) *int { return &cfg.WatchInterval }),
+	stringKey("kernel.preferredFlavour", func(cfg *app.Configuration) *string { return &cfg.Kernel.PreferredFlavour }, nil),
+	boolKey("kernel.autoSwitch", func(cfg *app.Configuration) *bool { return &cfg.Kernel.AutoSwitch }),
+	stringKey("httpListen", func(cfg *app.Configuration) *string { return &cfg.HTTPListen }, validateListen),
+	stringKey("httpSocket.path", func(cfg *app.Configuration) *string { return &cfg.HTTPSocket.Path }, nil),
+	stringKey("httpSocket.mode", func(cfg *app.Configuration) *string { return &cfg.HTTPSocket.Mode }, validateSocketMode),
+	stringKey("httpSocket.group", func(cfg *app.Configuration) *string { return &cfg.HTTPSocket.Group }, nil),
+	enumKey("httpSocket.permission", func(cfg *app.Configuration) *string { return &cfg.HTTPSocket.Permission }, "", "read", "manage"),
+	boolKey("httpSocket.socketOnly", func(cfg *app.Configuration) *bool { return &cfg.HTTPSocket.SocketOnly }),
+	boolKey("modules.distrobox", func(cfg *app.Configuration) *bool { return &cfg.Modules.Distrobox }),
+	boolKey("modules.stplr", func(cfg *app.Configuration) *bool { return &cfg.Modules.Stplr }),
+	stringKey("network.limit", func(cfg *app.Configuration) *string { return &cfg.Network.Limit }, validateLimit),
+	stringKey("network.httpProxy", func(cfg *app.Configuration) *string { return &cfg.Network.HTTPProxy }, network.ValidateHTTPProxy),
+	stringKey("network.httpsProxy", func(cfg *app.Configuration) *string { return &cfg.Network.HTTPSProxy }, network.ValidateProxy),
+	stringKey("network.socksProxy", func(cfg *app.Configuration) *string { return &cfg.Network.SocksProxy }, network.ValidateProxy),
+}
+
+// findConfigKey ищет параметр по имени без учёта регистра.
+func findConfigKey(name string) (configKey, error) {
+	for _, key := range configKeys {
+		if strings.EqualFold(key.name, name) {
+			return key, nil
+		}
+	}
+
+	names := make([]string, 0, len(configKeys))
+	for _, key := range configKeys {
+		names = append(names, key.name)
+	}
+	return configKey{}, apmerr.New(apmerr.ErrorTypeValidation,
+		fmt.Errorf(app.T_("Unknown configuration key %s, available: %s"), name, strings.Join(names, ", ")))
+}
+
+// configValues возвращает значения параметров; пустое имя — все параметры.
+func configValues(cfg *app.Configuration, name string) (map[string]string, error) {
+	values := make(map[string]string)
+	if name == "" {
+		for _, key := range configKeys {
+			values[key.name] = key.get(cfg)
+		}
+		return values, nil
+	}
+
+	key, err := findConfigKey(name)
+	if err != nil {
+		return nil, err
+	}
+	values[key.name] = key.get(cfg)
+	return values, nil
+}
+
+// validateConfiguration проверяет значения всех параметров, например после ручного редактирования файла.
+func validateConfiguration(cfg *app.Configuration) error {
+	var errs []error
+	for _, key := range configKeys {
+		if err := key.set(cfg, key.get(cfg)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ConfigGet возвращает значение параметра конфигурации или всех параметров.
+func (a *Actions) ConfigGet(_ context.Context, name string) (*ConfigResponse, error) {
+	values, err := configValues(a.appConfig.ConfigManager.GetConfig(), name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConfigResponse{
+		Message: app.T_("Configuration"),
+		Path:    a.appConfig.ConfigManager.GetConfigPath(),
+		Values:  values,
+	}, nil
+}
+
+// ConfigList возвращает значения всех параметров конфигурации.
+func (a *Actions) ConfigList(ctx context.Context) (*ConfigResponse, error) {
+	return a.ConfigGet(ctx, "")
+}
+
+// ConfigSet сохраняет значение параметра в файл конфигурации и сразу применяет сетевые настройки.
+func (a *Actions) ConfigSet(_ context.Context, name, value string) (*ConfigResponse, error) {
+	key, err := findConfigKey(name)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *a.appConfig.ConfigManager.GetConfig()
+	if err = key.set(&updated, strings.TrimSpace(value)); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, err)
+	}
+
+	return a.saveConfigKey(&updated, key, fmt.Sprintf(app.T_("Parameter %s set"), key.name))
+}
+
+// ConfigUnset возвращает параметру значение по умолчанию.
+func (a *Actions) ConfigUnset(_ context.Context, name string) (*ConfigResponse, error) {
+	key, err := findConfigKey(name)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *a.appConfig.ConfigManager.GetConfig()
+	if err = key.set(&updated, key.get(app.DefaultConfiguration())); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, err)
+	}
+
+	return a.saveConfigKey(&updated, key, fmt.Sprintf(app.T_("Parameter %s reset"), key.name))
+}
+
+// saveConfigKey сохраняет конфигурацию с изменённым параметром.
+func (a *Actions) saveConfigKey(updated *app.Configuration, key configKey, message string) (*ConfigResponse, error) {
+	if err := a.appConfig.ConfigManager.SaveConfig(updated); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypePermission, err)
+	}
+	network.Configure(updated.Network)
+
+	return &ConfigResponse{
+		Message: message,
+		Path:    a.appConfig.ConfigManager.GetConfigPath(),
+		Values:  map[string]string{key.name: key.get(updated)},
+	}, nil
+}
+
+// ConfigEdit открывает файл конфигурации в редакторе ($VISUAL, $EDITOR или vi) и сохраняет его,
+// только если все параметры прошли проверку.
+func (a *Actions) ConfigEdit(_ context.Context) (*ConfigResponse, error) {
+	path := a.appConfig.ConfigManager.GetConfigPath()
+	original, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, apmerr.New(apmerr.ErrorTypePermission, err)
+	}
+
+	tmp, err := os.CreateTemp("", "apm-config-*.yml")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err = tmp.Write(original); err != nil {
+		_ = tmp.Close()
+		return nil, err
+	}
+	if err = tmp.Close(); err != nil {
+		return nil, err
+	}
+
+	if err = runEditor(tmp.Name()); err != nil {
+		return nil, err
+	}
+
+	edited, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(edited, original) {
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.T_("Configuration has not changed")))
+	}
+
+	cfg := app.DefaultConfiguration()
+	if err = goyaml.Unmarshal(edited, cfg); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.T_("Configuration is not valid YAML, changes are discarded: %v"), err))
+	}
+	if err = validateConfiguration(cfg); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.T_("Configuration is not valid, changes are discarded: %v"), err))
+	}
+
+	if err = app.EnsurePath(path); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypePermission, err)
+	}
+	if err = os.WriteFile(path, edited, 0644); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypePermission, err)
+	}
+	network.Configure(cfg.Network)
+
+	values, _ := configValues(cfg, "")
+	return &ConfigResponse{
+		Message: app.T_("Configuration saved"),
+		Path:    path,
+		Values:  values,
+	}, nil
+}
+
+// runEditor запускает редактор в текущем терминале.
+var runEditor = func(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	args := strings.Fields(editor)
+	program, err := exec.LookPath(args[0])
+	if err != nil {
+		return apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.T_("Editor %s not found, set EDITOR"), args[0]))
+	}
+
+	cmd := exec.Command(program, append(args[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err = cmd.Run(); err != nil {
+		return fmt.Errorf(app.T_("Editor %s failed: %v"), args[0], err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/reply"
+	"apm/internal/domain/system/dialog"
+	"apm/internal/domain/system/rpmnew"
+	"context"
+	"fmt"
+)
+
+// resolveConfigMerges предлагает разобрать .rpmnew файлы, появившиеся после транзакции.
+// Каждое решение пишется в лог apm, отложенные файлы остаются на месте.
+func (a *Actions) resolveConfigMerges(ctx context.Context, before map[string]struct{}) []rpmnew.Decision {
+	files := a.serviceRpmnew.Collect(before)
+	if len(files) == 0 {
+		return nil
+	}
+
+	reply.StopSpinner(a.appConfig)
+	defer reply.CreateSpinner(a.appConfig)
+
+	decisions := make([]rpmnew.Decision, 0, len(files))
+	for i, file := range files {
+		diff, err := a.serviceRpmnew.Diff(ctx, file)
+		if err != nil {
+			app.Log.Warning(err.Error())
+		}
+
+		action, err := dialog.SelectRpmnewAction(ctx, a.appConfig, file, diff, fmt.Sprintf("%d/%d", i+1, len(files)))
+		if err != nil {
+			app.Log.Warning(err.Error())
+			action = rpmnew.ActionSkip
+		}
+
+		if err = a.serviceRpmnew.Apply(ctx, file, action); err != nil {
+			app.Log.Warning(err.Error())
+			action = rpmnew.ActionSkip
+		}
+
+		app.Log.Info(fmt.Sprintf("config merge %s: %s", file.Path, action))
+		decisions = append(decisions, rpmnew.Decision{Path: file.Path, Action: action})
+	}
+
+	return decisions
+}
@@ -0,0 +1,162 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/apt"
+	"apm/internal/common/reply"
+	"apm/internal/domain/system/dialog"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// rpmReplaceFilesOption опция APT-RPM, передающая rpm флаг --replacefiles
+const rpmReplaceFilesOption = "RPM::Install-Options::"
+
+// SetConflictPolicy задаёт политику автоматического разрешения файловых конфликтов при установке.
+func (a *Actions) SetConflictPolicy(policy apt.ConflictPolicy) {
+	a.conflictPolicy = policy
+}
+
+// FileConflictError ошибка установки, вызванная файловыми конфликтами пакетов.
+type FileConflictError struct {
+	Err       error
+	Conflicts []apt.FileConflict
+	// locale язык клиента, на котором формируется сообщение
+	locale string
+}
+
+func (e *FileConflictError) Error() string {
+	ctx := app.WithLocale(context.Background(), e.locale)
+	lines := make([]string, 0, len(e.Conflicts)+2)
+	lines = append(lines, app.TL_(ctx, "Packages conflict on files:"))
+	for _, c := range e.Conflicts {
+		lines = append(lines, fmt.Sprintf("  %s: %s ↔ %s", c.Path, c.Package, c.ConflictsWith))
+	}
+	lines = append(lines, app.TL_(ctx, "Choose a conflict policy (replace, skip or remove) to resolve the conflict automatically"))
+	return strings.Join(lines, "\n")
+}
+
+func (e *FileConflictError) Unwrap() error {
+	return e.Err
+}
+
+// installWithConflictResolution выполняет транзакцию и при файловых конфликтах
+// повторяет её один раз согласно выбранной политике. Возвращает итоговые списки пакетов.
+func (a *Actions) installWithConflictResolution(ctx context.Context, install, remove []string, downloadOnly, confirm bool) ([]string, []string, error) {
+	errInstall := a.serviceAptActions.CombineInstallRemovePackages(ctx, install, remove, false, false, downloadOnly)
+	if errInstall == nil || downloadOnly {
+		return install, remove, errInstall
+	}
+
+	var matchedErr *apt.MatchedError
+	if !errors.As(errInstall, &matchedErr) {
+		return install, remove, errInstall
+	}
+
+	conflicts := apt.ParseFileConflicts(matchedErr.Details)
+	if len(conflicts) == 0 {
+		return install, remove, errInstall
+	}
+
+	policy := a.conflictPolicy
+	if policy == apt.ConflictPolicyNone && !confirm {
+		reply.StopSpinner(a.appConfig)
+		selected, errDialog := dialog.SelectConflictPolicy(ctx, a.appConfig, conflicts)
+		if errDialog != nil {
+			return install, remove, apmerr.New(apmerr.ErrorTypeCanceled, errDialog)
+		}
+		policy = selected
+		reply.CreateSpinner(a.appConfig)
+	}
+
+	switch policy {
+	case apt.ConflictPolicyReplace:
+		return install, remove, a.retryWithReplaceFiles(ctx, install, remove)
+	case apt.ConflictPolicySkip:
+		install = a.skipConflictingPackages(ctx, install, conflicts)
+		if len(install) == 0 && len(remove) == 0 {
+			return install, remove, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.TL_(ctx, "All requested packages conflict on files, nothing left to install")))
+		}
+	case apt.ConflictPolicyRemove:
+		remove = appendConflictOwners(remove, conflicts)
+	default:
+		return install, remove, &FileConflictError{Err: errInstall, Conflicts: conflicts, locale: app.LocaleFromContext(ctx)}
+	}
+
+	app.Log.Debugf("Retrying transaction with conflict policy %q", policy)
+	return install, remove, a.serviceAptActions.CombineInstallRemovePackages(ctx, install, remove, false, false, false)
+}
+
+// retryWithReplaceFiles повторяет транзакцию с разрешением перезаписи файлов, сохраняя исходные переопределения APT.
+func (a *Actions) retryWithReplaceFiles(ctx context.Context, install, remove []string) error {
+	original := a.serviceAptActions.GetAptConfigOverrides()
+	overrides := make(map[string]string, len(original)+1)
+	maps.Copy(overrides, original)
+	overrides[rpmReplaceFilesOption] = "--replacefiles"
+
+	a.serviceAptActions.SetAptConfigOverrides(overrides)
+	defer a.serviceAptActions.SetAptConfigOverrides(original)
+
+	app.Log.Debugf("Retrying transaction with conflict policy %q", apt.ConflictPolicyReplace)
+	return a.serviceAptActions.CombineInstallRemovePackages(ctx, install, remove, false, false, false)
+}
+
+// skipConflictingPackages исключает из списка установки пакеты, участвующие в конфликтах, а также запрошенные
+// пакеты, которые подтягивают конфликтующие зависимости.
+func (a *Actions) skipConflictingPackages(ctx context.Context, install []string, conflicts []apt.FileConflict) []string {
+	conflicting := make(map[string]bool)
+	for _, c := range conflicts {
+		conflicting[apt.PackageNameFromNEVRA(c.Package)] = true
+	}
+	isConflicting := func(name string) bool {
+		return conflicting[name]
+	}
+
+	return slices.DeleteFunc(slices.Clone(install), func(pkg string) bool {
+		if conflicting[pkg] {
+			return true
+		}
+		_, _, _, changes, err := a.serviceAptActions.FindPackage(ctx, []string{pkg}, nil, false, false, false)
+		if err != nil || changes == nil {
+			app.Log.Debugf("failed to resolve dependencies of %s: %v", pkg, err)
+			return false
+		}
+		return slices.ContainsFunc(changes.NewInstalledPackages, isConflicting) ||
+			slices.ContainsFunc(changes.UpgradedPackages, isConflicting)
+	})
+}
+
+// appendConflictOwners добавляет в список удаления установленные пакеты, владеющие конфликтующими файлами.
+func appendConflictOwners(remove []string, conflicts []apt.FileConflict) []string {
+	for _, c := range conflicts {
+		if !c.Installed {
+			continue
+		}
+		name := apt.PackageNameFromNEVRA(c.ConflictsWith)
+		if !slices.Contains(remove, name) {
+			remove = append(remove, name)
+		}
+	}
+	return remove
+}
@@ -0,0 +1,172 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/journal"
+	"apm/internal/domain/system/units"
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+)
+
+// dbMaintenance возвращает обслуживание баз данных из менеджера приложения
+func (a *Actions) dbMaintenance(ctx context.Context) (app.DatabaseMaintenance, error) {
+	maintenance, ok := a.appConfig.DatabaseManager.(app.DatabaseMaintenance)
+	if !ok {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, errors.New(app.TL_(ctx, "Database maintenance is not supported")))
+	}
+	return maintenance, nil
+}
+
+// DBStatus возвращает версии схемы и состояние баз данных.
+func (a *Actions) DBStatus(ctx context.Context) (*DBStatusResponse, error) {
+	maintenance, err := a.dbMaintenance(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := maintenance.DatabaseStatus()
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+
+	pending := 0
+	for _, s := range statuses {
+		pending += s.Pending
+	}
+	message := app.TL_(ctx, "Database schemas are up to date")
+	if pending > 0 {
+		message = fmt.Sprintf(app.TLN_(ctx, "%d migration pending", "%d migrations pending", pending), pending)
+	}
+
+	return &DBStatusResponse{
+		Message:   message,
+		Databases: statuses,
+	}, nil
+}
+
+// lockDatabases берёт общую блокировку операций apm, когда обслуживается системная база. Файл блокировки
+// лежит в /run и недоступен обычному пользователю, а его собственную базу другие процессы apm не трогают.
+func (a *Actions) lockDatabases(ctx context.Context, action string) (context.Context, func(), error) {
+	if syscall.Geteuid() != 0 {
+		return ctx, func() {}, nil
+	}
+	return a.operationLock.Acquire(ctx, action)
+}
+
+// DBMigrate применяет недостающие миграции. Системная база обрабатывается только от root.
+func (a *Actions) DBMigrate(ctx context.Context) (*DBStatusResponse, error) {
+	ctx, release, err := a.lockDatabases(ctx, journal.ActionMigrate)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	maintenance, err := a.dbMaintenance(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := maintenance.MigrateDatabases()
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+
+	return &DBStatusResponse{
+		Message:   app.TL_(ctx, "Database migrations applied"),
+		Databases: statuses,
+	}, nil
+}
+
+// DBVacuum сжимает базы данных и пересоздаёт повреждённые.
+func (a *Actions) DBVacuum(ctx context.Context) (*DBVacuumResponse, error) {
+	ctx, release, err := a.lockDatabases(ctx, journal.ActionVacuum)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	maintenance, err := a.dbMaintenance(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := maintenance.VacuumDatabases()
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+
+	message := app.TL_(ctx, "Databases compacted")
+	for _, r := range results {
+		if r.Recovered {
+			message = app.TL_(ctx, "Corrupted databases were recreated, run 'apm system update' to restore package data")
+			break
+		}
+	}
+
+	return &DBVacuumResponse{
+		Message:   message,
+		Databases: results,
+	}, nil
+}
+
+// DBRelocate переносит данные в расположение из конфигурации: от root - системную базу и журналы сборки,
+// от обычного пользователя - его базу. Перенос выполняется только при остановленных службах apm,
+// которые держат базу открытой.
+func (a *Actions) DBRelocate(ctx context.Context) (*DBRelocateResponse, error) {
+	relocator, ok := a.appConfig.ConfigManager.(app.DataRelocator)
+	if !ok {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, errors.New(app.TL_(ctx, "Data relocation is not supported")))
+	}
+
+	services := []string{units.DBusSystem, units.HTTPServer}
+	if syscall.Geteuid() != 0 {
+		services = []string{units.DBusSession}
+	}
+	for _, name := range services {
+		status, err := a.serviceUnits.Status(ctx, name)
+		if err != nil {
+			return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+		}
+		if status.Active {
+			return nil, apmerr.New(apmerr.ErrorTypeBusy, fmt.Errorf(app.TL_(ctx, "Service %s is running, stop it before relocating data: systemctl stop %s"), status.Unit, status.Unit))
+		}
+	}
+
+	ctx, release, err := a.lockDatabases(ctx, journal.ActionRelocate)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	relocated, err := relocator.RelocateData()
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+	if len(relocated) == 0 {
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.TL_(ctx, "Data is already in the configured location")))
+	}
+
+	return &DBRelocateResponse{
+		Message:   app.TL_(ctx, "Data moved to the configured location"),
+		Relocated: relocated,
+	}, nil
+}
@@ -64,17 +64,24 @@ func NewDBusWrapper(a *Actions, c *dbus.Conn, ctx context.Context) *DBusWrapper
 	}
 }
 
-// checkManagePermission проверяет права org.altlinux.APM.manage
-func (w *DBusWrapper) checkManagePermission(sender dbus.Sender) *dbus.Error {
-	if err := helper.PolkitCheck(w.conn, sender, "org.altlinux.APM.manage"); err != nil {
-		return dbus.MakeFailedError(err)
+// WithLocale возвращает копию обёртки, формирующую сообщения на языке клиента lang
+func (w *DBusWrapper) WithLocale(lang string) any {
+	localized := *w
+	localized.ctx = app.WithLocale(w.ctx, lang)
+	return &localized
+}
+
+// checkPermission проверяет права на действие Polkit action
+func (w *DBusWrapper) checkPermission(sender dbus.Sender, action string) *dbus.Error {
+	if err := helper.PolkitCheck(w.conn, sender, action); err != nil {
+		return apmerr.DBusError(apmerr.New(apmerr.ErrorTypePermission, err))
 	}
 	return nil
 }
 
 // Install устанавливает пакеты.
 func (w *DBusWrapper) Install(sender dbus.Sender, packages []string, downloadOnly bool, transaction string, background bool) (string, *dbus.Error) {
-	if err := w.checkManagePermission(sender); err != nil {
+	if err := w.checkPermission(sender, helper.PolkitActionInstall); err != nil {
 		return "", err
 	}
 
@@ -83,14 +90,14 @@ func (w *DBusWrapper) Install(sender dbus.Sender, packages []string, downloadOnl
 	}
 
 	if background {
-		ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventSystemInstall)
 		go func() {
 			resp, err := w.actions.Install(ctx, packages, true, downloadOnly)
 			w.actions.reporter.SendTaskResult(ctx, reply.EventSystemInstall, resp, err)
 		}()
 
 		bgResp := BackgroundTaskResponse{
-			Message:     app.T_("Task started in background"),
+			Message:     app.TL_(w.ctx, "Task started in background"),
 			Transaction: transaction,
 		}
 		data, jerr := json.Marshal(reply.OK(bgResp))
@@ -115,7 +122,7 @@ func (w *DBusWrapper) Install(sender dbus.Sender, packages []string, downloadOnl
 
 // Remove удаляет пакеты.
 func (w *DBusWrapper) Remove(sender dbus.Sender, packages []string, purge bool, depends bool, transaction string, background bool) (string, *dbus.Error) {
-	if err := w.checkManagePermission(sender); err != nil {
+	if err := w.checkPermission(sender, helper.PolkitActionRemove); err != nil {
 		return "", err
 	}
 
@@ -124,14 +131,14 @@ func (w *DBusWrapper) Remove(sender dbus.Sender, packages []string, purge bool,
 	}
 
 	if background {
-		ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventSystemRemove)
 		go func() {
 			resp, err := w.actions.Remove(ctx, packages, purge, depends, true)
 			w.actions.reporter.SendTaskResult(ctx, reply.EventSystemRemove, resp, err)
 		}()
 
 		bgResp := BackgroundTaskResponse{
-			Message:     app.T_("Task started in background"),
+			Message:     app.TL_(w.ctx, "Task started in background"),
 			Transaction: transaction,
 		}
 		data, jerr := json.Marshal(reply.OK(bgResp))
@@ -154,6 +161,88 @@ func (w *DBusWrapper) Remove(sender dbus.Sender, packages []string, purge bool,
 	return string(data), nil
 }
 
+// AutoRemove удаляет неиспользуемые пакеты.
+func (w *DBusWrapper) AutoRemove(sender dbus.Sender, transaction string, background bool) (string, *dbus.Error) {
+	if err := w.checkPermission(sender, helper.PolkitActionRemove); err != nil {
+		return "", err
+	}
+
+	if transaction == "" {
+		transaction = helper.GenerateTransactionID()
+	}
+
+	if background {
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventSystemAutoRemove)
+		go func() {
+			resp, err := w.actions.AutoRemove(ctx, true)
+			w.actions.reporter.SendTaskResult(ctx, reply.EventSystemAutoRemove, resp, err)
+		}()
+
+		bgResp := BackgroundTaskResponse{
+			Message:     app.TL_(w.ctx, "Task started in background"),
+			Transaction: transaction,
+		}
+		data, jerr := json.Marshal(reply.OK(bgResp))
+		if jerr != nil {
+			return "", dbus.MakeFailedError(jerr)
+		}
+		return string(data), nil
+	}
+
+	// Синхронное выполнение
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.AutoRemove(ctx, true)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// Reinstall переустанавливает пакеты.
+func (w *DBusWrapper) Reinstall(sender dbus.Sender, packages []string, transaction string, background bool) (string, *dbus.Error) {
+	if err := w.checkPermission(sender, helper.PolkitActionInstall); err != nil {
+		return "", err
+	}
+
+	if transaction == "" {
+		transaction = helper.GenerateTransactionID()
+	}
+
+	if background {
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventSystemReinstall)
+		go func() {
+			resp, err := w.actions.Reinstall(ctx, packages, true)
+			w.actions.reporter.SendTaskResult(ctx, reply.EventSystemReinstall, resp, err)
+		}()
+
+		bgResp := BackgroundTaskResponse{
+			Message:     app.TL_(w.ctx, "Task started in background"),
+			Transaction: transaction,
+		}
+		data, jerr := json.Marshal(reply.OK(bgResp))
+		if jerr != nil {
+			return "", dbus.MakeFailedError(jerr)
+		}
+		return string(data), nil
+	}
+
+	// Синхронное выполнение
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.Reinstall(ctx, packages, true)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
 // GetFilterFields возвращает список полей фильтрации для метода list, помогающий динамически строить фильтры в интерфейсе.
 func (w *DBusWrapper) GetFilterFields(transaction string) (string, *dbus.Error) {
 	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
@@ -172,7 +261,7 @@ func (w *DBusWrapper) GetFilterFields(transaction string) (string, *dbus.Error)
 
 // Update обновляет систему.
 func (w *DBusWrapper) Update(sender dbus.Sender, transaction string, background bool) (string, *dbus.Error) {
-	if err := w.checkManagePermission(sender); err != nil {
+	if err := w.checkPermission(sender, helper.PolkitActionUpgrade); err != nil {
 		return "", err
 	}
 
@@ -181,14 +270,14 @@ func (w *DBusWrapper) Update(sender dbus.Sender, transaction string, background
 	}
 
 	if background {
-		ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventSystemUpdate)
 		go func() {
 			resp, err := w.actions.Update(ctx, false, false)
 			w.actions.reporter.SendTaskResult(ctx, reply.EventSystemUpdate, resp, err)
 		}()
 
 		bgResp := BackgroundTaskResponse{
-			Message:     app.T_("Task started in background"),
+			Message:     app.TL_(w.ctx, "Task started in background"),
 			Transaction: transaction,
 		}
 		data, jerr := json.Marshal(reply.OK(bgResp))
@@ -280,7 +369,7 @@ func (w *DBusWrapper) MultiInfo(packages []string, transaction string) (string,
 
 // CheckUpgrade проверяет возможность обновления.
 func (w *DBusWrapper) CheckUpgrade(sender dbus.Sender, transaction string, background bool) (string, *dbus.Error) {
-	if err := w.checkManagePermission(sender); err != nil {
+	if err := w.checkPermission(sender, helper.PolkitActionUpgrade); err != nil {
 		return "", err
 	}
 
@@ -289,14 +378,14 @@ func (w *DBusWrapper) CheckUpgrade(sender dbus.Sender, transaction string, backg
 	}
 
 	if background {
-		ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventSystemCheckUpgrade)
 		go func() {
 			resp, err := w.actions.CheckUpgrade(ctx)
 			w.actions.reporter.SendTaskResult(ctx, reply.EventSystemCheckUpgrade, resp, err)
 		}()
 
 		bgResp := BackgroundTaskResponse{
-			Message:     app.T_("Task started in background"),
+			Message:     app.TL_(w.ctx, "Task started in background"),
 			Transaction: transaction,
 		}
 		data, jerr := json.Marshal(reply.OK(bgResp))
@@ -321,7 +410,7 @@ func (w *DBusWrapper) CheckUpgrade(sender dbus.Sender, transaction string, backg
 
 // Upgrade обновляет систему (для не-атомарных систем).
 func (w *DBusWrapper) Upgrade(sender dbus.Sender, downloadOnly bool, transaction string, background bool) (string, *dbus.Error) {
-	if err := w.checkManagePermission(sender); err != nil {
+	if err := w.checkPermission(sender, helper.PolkitActionUpgrade); err != nil {
 		return "", err
 	}
 
@@ -330,14 +419,14 @@ func (w *DBusWrapper) Upgrade(sender dbus.Sender, downloadOnly bool, transaction
 	}
 
 	if background {
-		ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventSystemUpgrade)
 		go func() {
 			resp, err := w.actions.Upgrade(ctx, downloadOnly)
 			w.actions.reporter.SendTaskResult(ctx, reply.EventSystemUpgrade, resp, err)
 		}()
 
 		bgResp := BackgroundTaskResponse{
-			Message:     app.T_("Task started in background"),
+			Message:     app.TL_(w.ctx, "Task started in background"),
 			Transaction: transaction,
 		}
 		data, jerr := json.Marshal(reply.OK(bgResp))
@@ -362,7 +451,7 @@ func (w *DBusWrapper) Upgrade(sender dbus.Sender, downloadOnly bool, transaction
 
 // CheckInstall проверяет возможность установки пакетов.
 func (w *DBusWrapper) CheckInstall(sender dbus.Sender, packages []string, transaction string, background bool) (string, *dbus.Error) {
-	if err := w.checkManagePermission(sender); err != nil {
+	if err := w.checkPermission(sender, helper.PolkitActionInstall); err != nil {
 		return "", err
 	}
 
@@ -371,14 +460,14 @@ func (w *DBusWrapper) CheckInstall(sender dbus.Sender, packages []string, transa
 	}
 
 	if background {
-		ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventSystemCheckInstall)
 		go func() {
 			resp, err := w.actions.CheckInstall(ctx, packages)
 			w.actions.reporter.SendTaskResult(ctx, reply.EventSystemCheckInstall, resp, err)
 		}()
 
 		bgResp := BackgroundTaskResponse{
-			Message:     app.T_("Task started in background"),
+			Message:     app.TL_(w.ctx, "Task started in background"),
 			Transaction: transaction,
 		}
 		data, jerr := json.Marshal(reply.OK(bgResp))
@@ -401,9 +490,50 @@ func (w *DBusWrapper) CheckInstall(sender dbus.Sender, packages []string, transa
 	return string(data), nil
 }
 
+// CheckReinstall проверяет возможность переустановки пакетов.
+func (w *DBusWrapper) CheckReinstall(sender dbus.Sender, packages []string, transaction string, background bool) (string, *dbus.Error) {
+	if err := w.checkPermission(sender, helper.PolkitActionInstall); err != nil {
+		return "", err
+	}
+
+	if transaction == "" {
+		transaction = helper.GenerateTransactionID()
+	}
+
+	if background {
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventSystemCheckReinstall)
+		go func() {
+			resp, err := w.actions.CheckReinstall(ctx, packages)
+			w.actions.reporter.SendTaskResult(ctx, reply.EventSystemCheckReinstall, resp, err)
+		}()
+
+		bgResp := BackgroundTaskResponse{
+			Message:     app.TL_(w.ctx, "Task started in background"),
+			Transaction: transaction,
+		}
+		data, jerr := json.Marshal(reply.OK(bgResp))
+		if jerr != nil {
+			return "", dbus.MakeFailedError(jerr)
+		}
+		return string(data), nil
+	}
+
+	// Синхронное выполнение
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.CheckReinstall(ctx, packages)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
 // CheckRemove проверяет возможность удаления пакетов.
 func (w *DBusWrapper) CheckRemove(sender dbus.Sender, packages []string, depends bool, transaction string, background bool) (string, *dbus.Error) {
-	if err := w.checkManagePermission(sender); err != nil {
+	if err := w.checkPermission(sender, helper.PolkitActionRemove); err != nil {
 		return "", err
 	}
 
@@ -412,14 +542,14 @@ func (w *DBusWrapper) CheckRemove(sender dbus.Sender, packages []string, depends
 	}
 
 	if background {
-		ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventSystemCheckRemove)
 		go func() {
 			resp, err := w.actions.CheckRemove(ctx, packages, false, depends)
 			w.actions.reporter.SendTaskResult(ctx, reply.EventSystemCheckRemove, resp, err)
 		}()
 
 		bgResp := BackgroundTaskResponse{
-			Message:     app.T_("Task started in background"),
+			Message:     app.TL_(w.ctx, "Task started in background"),
 			Transaction: transaction,
 		}
 		data, jerr := json.Marshal(reply.OK(bgResp))
@@ -442,10 +572,51 @@ func (w *DBusWrapper) CheckRemove(sender dbus.Sender, packages []string, depends
 	return string(data), nil
 }
 
-// Search выполняет простой поиск пакетов.
-func (w *DBusWrapper) Search(packageName string, transaction string, installed bool) (string, *dbus.Error) {
+// CheckAutoRemove проверяет неиспользуемые пакеты перед удалением.
+func (w *DBusWrapper) CheckAutoRemove(sender dbus.Sender, transaction string, background bool) (string, *dbus.Error) {
+	if err := w.checkPermission(sender, helper.PolkitActionRemove); err != nil {
+		return "", err
+	}
+
+	if transaction == "" {
+		transaction = helper.GenerateTransactionID()
+	}
+
+	if background {
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventSystemCheckAutoRemove)
+		go func() {
+			resp, err := w.actions.CheckAutoRemove(ctx)
+			w.actions.reporter.SendTaskResult(ctx, reply.EventSystemCheckAutoRemove, resp, err)
+		}()
+
+		bgResp := BackgroundTaskResponse{
+			Message:     app.TL_(w.ctx, "Task started in background"),
+			Transaction: transaction,
+		}
+		data, jerr := json.Marshal(reply.OK(bgResp))
+		if jerr != nil {
+			return "", dbus.MakeFailedError(jerr)
+		}
+		return string(data), nil
+	}
+
+	// Синхронное выполнение
 	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
-	resp, err := w.actions.Search(ctx, packageName, installed)
+	resp, err := w.actions.CheckAutoRemove(ctx)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// Search выполняет простой поиск пакетов. С description ищет также по описанию с ранжированием.
+func (w *DBusWrapper) Search(packageName string, transaction string, installed bool, description bool) (string, *dbus.Error) {
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.Search(ctx, packageName, installed, description)
 	if err != nil {
 		return "", apmerr.DBusError(err)
 	}
@@ -458,7 +629,7 @@ func (w *DBusWrapper) Search(packageName string, transaction string, installed b
 
 // ImageApply декларативно применяет настройки image.yml к образу хост-системы.
 func (w *DBusWrapper) ImageApply(sender dbus.Sender, transaction string, background bool, pullImage bool, noCache bool, configPath string, workdir string) (string, *dbus.Error) {
-	if err := w.checkManagePermission(sender); err != nil {
+	if err := w.checkPermission(sender, helper.PolkitActionManage); err != nil {
 		return "", err
 	}
 
@@ -469,14 +640,14 @@ func (w *DBusWrapper) ImageApply(sender dbus.Sender, transaction string, backgro
 	hostCache := !noCache
 
 	if background {
-		ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventSystemImageApply)
 		go func() {
-			resp, err := w.actions.ImageApply(ctx, pullImage, hostCache, configPath, workdir)
+			resp, err := w.actions.ImageApply(ctx, pullImage, hostCache, configPath, workdir, "")
 			w.actions.reporter.SendTaskResult(ctx, reply.EventSystemImageApply, resp, err)
 		}()
 
 		bgResp := BackgroundTaskResponse{
-			Message:     app.T_("Task started in background"),
+			Message:     app.TL_(w.ctx, "Task started in background"),
 			Transaction: transaction,
 		}
 		data, jerr := json.Marshal(reply.OK(bgResp))
@@ -488,7 +659,24 @@ func (w *DBusWrapper) ImageApply(sender dbus.Sender, transaction string, backgro
 
 	// Синхронное выполнение
 	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
-	resp, err := w.actions.ImageApply(ctx, pullImage, hostCache, configPath, workdir)
+	resp, err := w.actions.ImageApply(ctx, pullImage, hostCache, configPath, workdir, "")
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// ImageRollback возвращает загрузку к предыдущему развёртыванию образа.
+func (w *DBusWrapper) ImageRollback(sender dbus.Sender, transaction string) (string, *dbus.Error) {
+	if err := w.checkPermission(sender, helper.PolkitActionManage); err != nil {
+		return "", err
+	}
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.ImageRollback(ctx)
 	if err != nil {
 		return "", apmerr.DBusError(err)
 	}
@@ -501,7 +689,7 @@ func (w *DBusWrapper) ImageApply(sender dbus.Sender, transaction string, backgro
 
 // ImageHistory возвращает историю обновлений.
 func (w *DBusWrapper) ImageHistory(sender dbus.Sender, transaction string, imageName string, limit int, offset int) (string, *dbus.Error) {
-	if err := w.checkManagePermission(sender); err != nil {
+	if err := w.checkPermission(sender, helper.PolkitActionManage); err != nil {
 		return "", err
 	}
 	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
@@ -516,9 +704,43 @@ func (w *DBusWrapper) ImageHistory(sender dbus.Sender, transaction string, image
 	return string(data), nil
 }
 
+// ImageDiff возвращает разницу пакетов между двумя записями истории образа.
+func (w *DBusWrapper) ImageDiff(sender dbus.Sender, transaction string, fromID uint32, toID uint32) (string, *dbus.Error) {
+	if err := w.checkPermission(sender, helper.PolkitActionManage); err != nil {
+		return "", err
+	}
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.ImageDiff(ctx, uint(fromID), uint(toID))
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// ImageLogs возвращает журнал сборки образа.
+func (w *DBusWrapper) ImageLogs(sender dbus.Sender, transaction string, generation int) (string, *dbus.Error) {
+	if err := w.checkPermission(sender, helper.PolkitActionManage); err != nil {
+		return "", err
+	}
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.ImageLogs(ctx, generation)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
 // ImageUpdate обновляет образ системы.
 func (w *DBusWrapper) ImageUpdate(sender dbus.Sender, transaction string, background bool, noCache bool) (string, *dbus.Error) {
-	if err := w.checkManagePermission(sender); err != nil {
+	if err := w.checkPermission(sender, helper.PolkitActionUpgrade); err != nil {
 		return "", err
 	}
 
@@ -529,14 +751,14 @@ func (w *DBusWrapper) ImageUpdate(sender dbus.Sender, transaction string, backgr
 	hostCache := !noCache
 
 	if background {
-		ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventSystemImageUpdate)
 		go func() {
 			resp, err := w.actions.ImageUpdate(ctx, hostCache)
 			w.actions.reporter.SendTaskResult(ctx, reply.EventSystemImageUpdate, resp, err)
 		}()
 
 		bgResp := BackgroundTaskResponse{
-			Message:     app.T_("Task started in background"),
+			Message:     app.TL_(w.ctx, "Task started in background"),
 			Transaction: transaction,
 		}
 		data, jerr := json.Marshal(reply.OK(bgResp))
@@ -561,7 +783,7 @@ func (w *DBusWrapper) ImageUpdate(sender dbus.Sender, transaction string, backgr
 
 // ImageStatus проверяет статус образа.
 func (w *DBusWrapper) ImageStatus(sender dbus.Sender, transaction string) (string, *dbus.Error) {
-	if err := w.checkManagePermission(sender); err != nil {
+	if err := w.checkPermission(sender, helper.PolkitActionManage); err != nil {
 		return "", err
 	}
 	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
@@ -576,6 +798,23 @@ func (w *DBusWrapper) ImageStatus(sender dbus.Sender, transaction string) (strin
 	return string(data), nil
 }
 
+// GetSystemOverview возвращает сводное состояние системы одним вызовом.
+func (w *DBusWrapper) GetSystemOverview(sender dbus.Sender, transaction string) (string, *dbus.Error) {
+	if err := w.checkPermission(sender, helper.PolkitActionManage); err != nil {
+		return "", err
+	}
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.GetSystemOverview(ctx)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
 // ImageGetConfig возвращает текущую конфигурацию image.yml.
 func (w *DBusWrapper) ImageGetConfig() (string, *dbus.Error) {
 	resp, err := w.actions.ImageGetConfig(w.ctx)
@@ -591,7 +830,7 @@ func (w *DBusWrapper) ImageGetConfig() (string, *dbus.Error) {
 
 // ApplicationUpdate загружает и сохраняет данные приложений.
 func (w *DBusWrapper) ApplicationUpdate(sender dbus.Sender, transaction string, background bool) (string, *dbus.Error) {
-	if err := w.checkManagePermission(sender); err != nil {
+	if err := w.checkPermission(sender, helper.PolkitActionUpgrade); err != nil {
 		return "", err
 	}
 
@@ -600,14 +839,14 @@ func (w *DBusWrapper) ApplicationUpdate(sender dbus.Sender, transaction string,
 	}
 
 	if background {
-		ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventApplicationUpdate)
 		go func() {
 			resp, err := w.appstreamActions.Update(ctx)
 			w.actions.reporter.SendTaskResult(ctx, reply.EventApplicationUpdate, resp, err)
 		}()
 
 		bgResp := BackgroundTaskResponse{
-			Message:     app.T_("Task started in background"),
+			Message:     app.TL_(w.ctx, "Task started in background"),
 			Transaction: transaction,
 		}
 		data, jerr := json.Marshal(reply.OK(bgResp))
@@ -709,6 +948,218 @@ func (w *DBusWrapper) Sections(transaction string) (string, *dbus.Error) {
 	return string(data), nil
 }
 
+// Files возвращает список файлов пакета, fileType — bin, config, doc или пустая строка.
+func (w *DBusWrapper) Files(packageName string, fileType string, transaction string) (string, *dbus.Error) {
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.Files(ctx, packageName, fileType)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// Provides ищет пакеты, которым принадлежит файл или которые предоставляют библиотеку.
+func (w *DBusWrapper) Provides(target string, transaction string) (string, *dbus.Error) {
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.Provides(ctx, target)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// Changelog возвращает записи changelog версии-кандидата новее установленной версии или since.
+func (w *DBusWrapper) Changelog(packageName string, since string, transaction string) (string, *dbus.Error) {
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.Changelog(ctx, packageName, since)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// Depends возвращает дерево зависимостей пакета с обходом до depth уровней.
+func (w *DBusWrapper) Depends(packageName string, depth int, installed bool, transaction string) (string, *dbus.Error) {
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.Depends(ctx, packageName, depth, installed)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// ReverseDepends возвращает пакеты, зависящие от пакета или capability, с обходом до depth уровней.
+func (w *DBusWrapper) ReverseDepends(target string, depth int, installed bool, transaction string) (string, *dbus.Error) {
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.ReverseDepends(ctx, target, depth, installed)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// Recent возвращает недавно установленные и удалённые пакеты за последние days дней.
+func (w *DBusWrapper) Recent(days int, transaction string) (string, *dbus.Error) {
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.Recent(ctx, days)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// Orphans возвращает установленные пакеты, которых больше нет в подключённых репозиториях.
+func (w *DBusWrapper) Orphans(keep []string, transaction string) (string, *dbus.Error) {
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.Orphans(ctx, keep)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// HistoryList возвращает историю операций всех модулей. Пустой module означает все модули.
+func (w *DBusWrapper) HistoryList(module string, limit int, offset int, transaction string) (string, *dbus.Error) {
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.HistoryList(ctx, module, limit, offset)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// HistoryShow возвращает операцию из истории по идентификатору.
+func (w *DBusWrapper) HistoryShow(id int, transaction string) (string, *dbus.Error) {
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.HistoryShow(ctx, id)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// Rollback откатывает транзакцию из журнала по номеру операции или идентификатору транзакции.
+func (w *DBusWrapper) Rollback(sender dbus.Sender, ref string, simulate bool, transaction string, background bool) (string, *dbus.Error) {
+	if err := w.checkPermission(sender, helper.PolkitActionManage); err != nil {
+		return "", err
+	}
+
+	if transaction == "" {
+		transaction = helper.GenerateTransactionID()
+	}
+
+	if background {
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventSystemRollback)
+		go func() {
+			resp, err := w.actions.Rollback(ctx, ref, true, simulate)
+			w.actions.reporter.SendTaskResult(ctx, reply.EventSystemRollback, resp, err)
+		}()
+
+		bgResp := BackgroundTaskResponse{
+			Message:     app.TL_(w.ctx, "Task started in background"),
+			Transaction: transaction,
+		}
+		data, jerr := json.Marshal(reply.OK(bgResp))
+		if jerr != nil {
+			return "", dbus.MakeFailedError(jerr)
+		}
+		return string(data), nil
+	}
+
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.Rollback(ctx, ref, true, simulate)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// Batch выполняет список операций одной транзакцией. Операции передаются JSON-массивом
+// объектов {"type": "install", "packages": ["vim"]}.
+func (w *DBusWrapper) Batch(sender dbus.Sender, operationsJSON string, simulate bool, transaction string, background bool) (string, *dbus.Error) {
+	if err := w.checkPermission(sender, helper.PolkitActionManage); err != nil {
+		return "", err
+	}
+
+	var ops []BatchOperation
+	if err := json.Unmarshal([]byte(operationsJSON), &ops); err != nil {
+		return "", apmerr.DBusError(apmerr.New(apmerr.ErrorTypeValidation, err))
+	}
+
+	if transaction == "" {
+		transaction = helper.GenerateTransactionID()
+	}
+
+	if background {
+		ctx := w.actions.reporter.StartTask(context.WithValue(w.ctx, helper.TransactionKey, transaction), reply.EventSystemBatch)
+		go func() {
+			resp, err := w.actions.Batch(ctx, ops, simulate)
+			w.actions.reporter.SendTaskResult(ctx, reply.EventSystemBatch, resp, err)
+		}()
+
+		bgResp := BackgroundTaskResponse{
+			Message:     app.TL_(w.ctx, "Task started in background"),
+			Transaction: transaction,
+		}
+		data, jerr := json.Marshal(reply.OK(bgResp))
+		if jerr != nil {
+			return "", dbus.MakeFailedError(jerr)
+		}
+		return string(data), nil
+	}
+
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.Batch(ctx, ops, simulate)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
 // ApplicationCategories возвращает список уникальных категорий приложений.
 func (w *DBusWrapper) ApplicationCategories(transaction string) (string, *dbus.Error) {
 	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
@@ -723,9 +1174,60 @@ func (w *DBusWrapper) ApplicationCategories(transaction string) (string, *dbus.E
 	return string(data), nil
 }
 
+// Clean удаляет скачанные пакеты.
+func (w *DBusWrapper) Clean(sender dbus.Sender, cache bool, downloads bool, transaction string) (string, *dbus.Error) {
+	if err := w.checkPermission(sender, helper.PolkitActionRemove); err != nil {
+		return "", err
+	}
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.Clean(ctx, cache, downloads)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// Hold удерживает пакеты от обновления.
+func (w *DBusWrapper) Hold(sender dbus.Sender, packages []string, transaction string) (string, *dbus.Error) {
+	if err := w.checkPermission(sender, helper.PolkitActionUpgrade); err != nil {
+		return "", err
+	}
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.Hold(ctx, packages)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// Unhold снимает удержание с пакетов.
+func (w *DBusWrapper) Unhold(sender dbus.Sender, packages []string, transaction string) (string, *dbus.Error) {
+	if err := w.checkPermission(sender, helper.PolkitActionUpgrade); err != nil {
+		return "", err
+	}
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.Unhold(ctx, packages)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
 // SetAptConfigOverrides устанавливает переопределения конфигурации APT, сохраняющиеся между запросами.
 func (w *DBusWrapper) SetAptConfigOverrides(sender dbus.Sender, options map[string]string) (string, *dbus.Error) {
-	if err := w.checkManagePermission(sender); err != nil {
+	if err := w.checkPermission(sender, helper.PolkitActionManage); err != nil {
 		return "", err
 	}
 	resp, err := w.actions.SetAptConfigOverrides(options)
@@ -754,13 +1256,13 @@ func (w *DBusWrapper) GetAptConfigOverrides() (string, *dbus.Error) {
 
 // ImageSaveConfig проверяет и сохраняет новую конфигурацию image.yml.
 func (w *DBusWrapper) ImageSaveConfig(sender dbus.Sender, config string) (string, *dbus.Error) {
-	if err := w.checkManagePermission(sender); err != nil {
+	if err := w.checkPermission(sender, helper.PolkitActionManage); err != nil {
 		return "", err
 	}
 
 	configObject := build.Config{}
 	if err := json.Unmarshal([]byte(config), &configObject); err != nil {
-		return "", dbus.MakeFailedError(fmt.Errorf(app.T_("Failed to parse JSON: %w"), err))
+		return "", dbus.MakeFailedError(fmt.Errorf(app.TL_(w.ctx, "Failed to parse JSON: %w"), err))
 	}
 	resp, err := w.actions.ImageSaveConfig(w.ctx, configObject)
 	if err != nil {
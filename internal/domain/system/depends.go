@@ -0,0 +1,294 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	_package "apm/internal/common/apt/package"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Depends возвращает дерево зависимостей пакета глубиной до depth уровней. Каждая зависимость
+// разрешается в пакет с таким именем или в пакет, предоставляющий её через Provides.
+// Пакет раскрывается только в первом вхождении, повторные вхождения отмечаются как repeated.
+func (a *Actions) Depends(ctx context.Context, target string, depth int, installed bool) (*DependsResponse, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Package must be specified, for example depends package")))
+	}
+	if depth < 1 || depth > rdependsMaxDepth {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.TL_(ctx, "Depth must be between 1 and %d"), rdependsMaxDepth))
+	}
+
+	if err := a.validateDB(ctx, false); err != nil {
+		return nil, err
+	}
+
+	root, err := a.serviceAptDatabase.GetPackageByName(ctx, target)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.TL_(ctx, "Package %s not found"), target))
+	}
+
+	graph := newDependencyGraph(root)
+	frontier := []_package.Package{root}
+	for level := 1; level <= depth && len(frontier) > 0; level++ {
+		var capabilities []string
+		for _, pkg := range frontier {
+			capabilities = append(capabilities, pkg.Depends...)
+		}
+
+		providers, err := a.resolveCapabilities(ctx, capabilities, installed)
+		if err != nil {
+			return nil, err
+		}
+
+		var next []_package.Package
+		for _, pkg := range frontier {
+			for _, capability := range pkg.Depends {
+				provider, ok := providers[capability]
+				if !ok {
+					graph.addMissing(pkg.Name, capability)
+					continue
+				}
+				if provider.Name == pkg.Name {
+					continue
+				}
+				if graph.addEdge(pkg.Name, provider, capability) {
+					next = append(next, provider)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	count := len(graph.nodes) - 1
+	return &DependsResponse{
+		Message: fmt.Sprintf(app.TLN_(ctx, "%s depends on %d package", "%s depends on %d packages", count), target, count),
+		Target:  target,
+		Tree:    graph.tree(root.Name),
+		Count:   count,
+	}, nil
+}
+
+// resolveCapabilities сопоставляет зависимостям пакеты, которые их удовлетворяют. Предпочтение отдаётся
+// пакету с именем зависимости, затем установленному пакету, затем первому по алфавиту.
+func (a *Actions) resolveCapabilities(ctx context.Context, capabilities []string, installed bool) (map[string]_package.Package, error) {
+	if len(capabilities) == 0 {
+		return map[string]_package.Package{}, nil
+	}
+
+	byName, err := a.serviceAptDatabase.GetPackagesByNames(ctx, capabilities)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+	providers, err := a.serviceAptDatabase.GetProviders(ctx, capabilities)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+	sort.Slice(providers, func(i, j int) bool {
+		if providers[i].Installed != providers[j].Installed {
+			return providers[i].Installed
+		}
+		return providers[i].Name < providers[j].Name
+	})
+
+	wanted := make(map[string]bool, len(capabilities))
+	for _, c := range capabilities {
+		wanted[c] = true
+	}
+
+	result := make(map[string]_package.Package, len(capabilities))
+	for _, pkg := range byName {
+		if wanted[pkg.Name] && (!installed || pkg.Installed) {
+			result[pkg.Name] = pkg
+		}
+	}
+	for _, pkg := range providers {
+		if installed && !pkg.Installed {
+			continue
+		}
+		for _, provide := range pkg.Provides {
+			if _, ok := result[provide]; wanted[provide] && !ok {
+				result[provide] = pkg
+			}
+		}
+	}
+	return result, nil
+}
+
+// dependencyEdge ребро графа зависимостей
+type dependencyEdge struct {
+	to      string
+	via     string
+	missing bool
+}
+
+// dependencyGraph граф зависимостей, собранный обходом в ширину. Для каждого пакета запоминается
+// родитель, через которого он был найден впервые: под ним пакет раскрывается в дереве.
+type dependencyGraph struct {
+	nodes  map[string]DependencyNode
+	parent map[string]string
+	edges  map[string][]dependencyEdge
+}
+
+// newDependencyGraph создаёт граф с корневым пакетом.
+func newDependencyGraph(root _package.Package) *dependencyGraph {
+	return &dependencyGraph{
+		nodes:  map[string]DependencyNode{root.Name: dependencyNode(root)},
+		parent: map[string]string{},
+		edges:  map[string][]dependencyEdge{},
+	}
+}
+
+// dependencyNode создаёт узел дерева для пакета.
+func dependencyNode(pkg _package.Package) DependencyNode {
+	return DependencyNode{Name: pkg.Name, Version: pkg.Version, Installed: pkg.Installed}
+}
+
+// addEdge добавляет ребро from -> pkg и возвращает true, если пакет встретился впервые.
+func (g *dependencyGraph) addEdge(from string, pkg _package.Package, via string) bool {
+	for _, e := range g.edges[from] {
+		if e.to == pkg.Name {
+			return false
+		}
+	}
+
+	if via == pkg.Name || via == from {
+		via = ""
+	}
+	g.edges[from] = append(g.edges[from], dependencyEdge{to: pkg.Name, via: via})
+
+	if _, ok := g.nodes[pkg.Name]; ok {
+		return false
+	}
+	g.nodes[pkg.Name] = dependencyNode(pkg)
+	g.parent[pkg.Name] = from
+	return true
+}
+
+// addMissing добавляет зависимость, которую не удовлетворяет ни один пакет из базы.
+func (g *dependencyGraph) addMissing(from string, capability string) {
+	g.edges[from] = append(g.edges[from], dependencyEdge{to: capability, missing: true})
+}
+
+// tree строит дерево от пакета name.
+func (g *dependencyGraph) tree(name string) DependencyNode {
+	node := g.nodes[name]
+	for _, e := range g.edges[name] {
+		if e.missing {
+			node.Children = append(node.Children, DependencyNode{Name: e.to, Missing: true})
+			continue
+		}
+
+		var child DependencyNode
+		if g.parent[e.to] == name {
+			child = g.tree(e.to)
+		} else {
+			child = g.nodes[e.to]
+			child.Repeated = true
+		}
+		child.Via = e.via
+		node.Children = append(node.Children, child)
+	}
+	if node.Children != nil {
+		sort.SliceStable(node.Children, func(i, j int) bool {
+			return node.Children[i].Name < node.Children[j].Name
+		})
+	}
+	return node
+}
+
+// renderDependencyTree выводит дерево зависимостей с псевдографикой.
+func renderDependencyTree(ctx context.Context, node DependencyNode) string {
+	var sb strings.Builder
+	sb.WriteString(dependencyLabel(ctx, node))
+	sb.WriteByte('\n')
+	writeDependencyChildren(ctx, &sb, node.Children, "")
+	return sb.String()
+}
+
+// writeDependencyChildren выводит дочерние узлы дерева с отступом indent.
+func writeDependencyChildren(ctx context.Context, sb *strings.Builder, children []DependencyNode, indent string) {
+	for i, child := range children {
+		branch, cont := "├── ", "│   "
+		if i == len(children)-1 {
+			branch, cont = "╰── ", "    "
+		}
+		sb.WriteString(indent + branch + dependencyLabel(ctx, child) + "\n")
+		writeDependencyChildren(ctx, sb, child.Children, indent+cont)
+	}
+}
+
+// dependencyLabel формирует подпись узла: имя, версию, capability и отметки.
+func dependencyLabel(ctx context.Context, node DependencyNode) string {
+	label := node.Name
+	if node.Version != "" {
+		label += " " + node.Version
+	}
+	if node.Via != "" {
+		label += " (" + node.Via + ")"
+	}
+	switch {
+	case node.Missing:
+		label += " - " + app.TL_(ctx, "not found")
+	case node.Repeated:
+		label += " - " + app.TL_(ctx, "shown above")
+	}
+	return label
+}
+
+// dependencyDOT выводит граф зависимостей в формате Graphviz DOT. Рёбра всегда направлены от зависящего
+// пакета к зависимости, поэтому для обратных зависимостей (reverse) они идут от потомка к родителю.
+func dependencyDOT(root DependencyNode, reverse bool) string {
+	var sb strings.Builder
+	sb.WriteString("digraph " + strconv.Quote(root.Name) + " {\n")
+	sb.WriteString("\t" + strconv.Quote(root.Name) + " [style=bold];\n")
+
+	seen := make(map[string]bool)
+	var walk func(node DependencyNode)
+	walk = func(node DependencyNode) {
+		for _, child := range node.Children {
+			from, to := node.Name, child.Name
+			if reverse {
+				from, to = to, from
+			}
+
+			edge := strconv.Quote(from) + " -> " + strconv.Quote(to)
+			if !seen[edge] {
+				seen[edge] = true
+				if child.Missing {
+					sb.WriteString("\t" + strconv.Quote(child.Name) + " [style=dashed];\n")
+				}
+				if child.Via != "" {
+					edge += " [label=" + strconv.Quote(child.Via) + "]"
+				}
+				sb.WriteString("\t" + edge + ";\n")
+			}
+			walk(child)
+		}
+	}
+	walk(root)
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
@@ -22,6 +22,7 @@ import (
 	aptLib "apm/internal/common/binding/apt/lib"
 	"apm/internal/common/helper"
 	"apm/internal/common/reply"
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -57,22 +58,29 @@ type model struct {
 }
 
 // NewDialog запускает диалог отображения информации о пакете с выбором действия.
-func NewDialog(appConfig *app.Config, packageInfo []_package.Package, packageChange aptLib.PackageChanges, action Action) (bool, error) {
+// Ответ, заданный флагами --yes/--assume-no/--non-interactive, возвращается без показа диалога.
+func NewDialog(ctx context.Context, appConfig *app.Config, packageInfo []_package.Package, packageChange aptLib.PackageChanges, action Action) (bool, error) {
+	switch helper.AnswerFromContext(ctx) {
+	case helper.AnswerYes:
+		return true, nil
+	case helper.AnswerNo:
+		return false, nil
+	}
 	if !reply.IsInteractive(appConfig) {
 		return true, nil
 	}
 
 	switch action {
 	case ActionMultiInstall:
-		choices = []string{app.T_("Edit"), app.T_("Abort")}
+		choices = []string{app.TL_(ctx, "Edit"), app.TL_(ctx, "Abort")}
 	case ActionInstall:
-		choices = []string{app.T_("Install"), app.T_("Abort")}
+		choices = []string{app.TL_(ctx, "Install"), app.TL_(ctx, "Abort")}
 	case ActionRemove:
-		choices = []string{app.T_("Remove"), app.T_("Abort")}
+		choices = []string{app.TL_(ctx, "Remove"), app.TL_(ctx, "Abort")}
 	case ActionUpgrade:
-		choices = []string{app.T_("Upgrade"), app.T_("Abort")}
+		choices = []string{app.TL_(ctx, "Upgrade"), app.TL_(ctx, "Abort")}
 	case ActionDownload:
-		choices = []string{app.T_("Download"), app.T_("Abort")}
+		choices = []string{app.TL_(ctx, "Download"), app.TL_(ctx, "Abort")}
 	}
 
 	m := model{
@@ -89,19 +97,19 @@ func NewDialog(appConfig *app.Config, packageInfo []_package.Package, packageCha
 		tea.WithoutSignalHandler())
 	finalModel, err := p.Run()
 	if err != nil {
-		app.Log.Errorf(app.T_("Error starting TEA: %v"), err)
+		app.Log.Errorf(app.TL_(ctx, "Error starting TEA: %v"), err)
 		return false, err
 	}
 
 	if m, ok := finalModel.(model); ok {
 		if m.canceled || m.choice == "" {
-			return false, errors.New(app.T_("Operation cancelled"))
+			return false, errors.New(app.TL_(ctx, "Operation cancelled"))
 		}
 
-		return m.choice == app.T_("Install") || m.choice == app.T_("Remove") || m.choice == app.T_("Edit") || m.choice == app.T_("Upgrade") || m.choice == app.T_("Download"), nil
+		return m.choice == app.TL_(ctx, "Install") || m.choice == app.TL_(ctx, "Remove") || m.choice == app.TL_(ctx, "Edit") || m.choice == app.TL_(ctx, "Upgrade") || m.choice == app.TL_(ctx, "Download"), nil
 	}
 
-	return false, errors.New(app.T_("Operation cancelled"))
+	return false, errors.New(app.TL_(ctx, "Operation cancelled"))
 }
 
 func (m model) Init() tea.Cmd {
@@ -387,7 +395,14 @@ func (m model) buildContent() string {
 				installedText = " " + m.getActionStyle().Render(app.T_("[Installed]"))
 			}
 
-			line := fmt.Sprintf("• %s%s - %s", pkg.Name, installedText, statusText)
+			name := pkg.Name
+			if pkg.LocalFile != "" {
+				name += " (" + pkg.LocalFile + ")"
+			}
+			if pkg.Group != "" {
+				name += " [" + pkg.Group + "]"
+			}
+			line := fmt.Sprintf("• %s%s - %s", name, installedText, statusText)
 			sb.WriteString("\n" + valueStyle.Render(line))
 		}
 	} else {
@@ -403,6 +418,12 @@ func (m model) buildContent() string {
 			}
 
 			sb.WriteString("\n" + formatLine(app.T_("Name"), pkg.Name, keyWidth, keyStyle, valueStyle))
+			if pkg.LocalFile != "" {
+				sb.WriteString("\n" + formatLine(app.T_("Local file"), pkg.LocalFile, keyWidth, keyStyle, valueStyle))
+			}
+			if pkg.Group != "" {
+				sb.WriteString("\n" + formatLine(app.T_("Group"), pkg.Group, keyWidth, keyStyle, valueStyle))
+			}
 			sb.WriteString("\n" + formatLine(app.T_("Action"), m.statusPackage(pkg), keyWidth, keyStyle, valueStyle))
 			sb.WriteString("\n" + formatLine(app.T_("Category"), pkg.Section, keyWidth, keyStyle, valueStyle))
 			sb.WriteString("\n" + formatLine(app.T_("Maintainer"), pkg.Maintainer, keyWidth, keyStyle, valueStyle))
@@ -0,0 +1,191 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package dialog
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/apt"
+	"apm/internal/common/helper"
+	"apm/internal/common/reply"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type conflictOption struct {
+	policy apt.ConflictPolicy
+	label  string
+}
+
+type conflictModel struct {
+	conflicts []apt.FileConflict
+	options   []conflictOption
+	cursor    int
+	selected  apt.ConflictPolicy
+	canceled  bool
+	quitting  bool
+	colors    app.Colors
+}
+
+func newConflictModel(conflicts []apt.FileConflict, colors app.Colors) conflictModel {
+	options := []conflictOption{
+		{apt.ConflictPolicyReplace, app.T_("Replace conflicting files")},
+		{apt.ConflictPolicySkip, app.T_("Skip conflicting packages")},
+	}
+
+	for _, c := range conflicts {
+		if c.Installed {
+			options = append(options, conflictOption{apt.ConflictPolicyRemove, app.T_("Remove installed packages owning the files")})
+			break
+		}
+	}
+
+	return conflictModel{
+		conflicts: conflicts,
+		options:   options,
+		colors:    colors,
+	}
+}
+
+func (m conflictModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m conflictModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.Type {
+	case tea.KeyCtrlC, tea.KeyEsc:
+		m.canceled = true
+		m.quitting = true
+		return m, tea.Quit
+	case tea.KeyEnter:
+		if m.cursor == len(m.options) {
+			m.canceled = true
+		} else {
+			m.selected = m.options[m.cursor].policy
+		}
+		m.quitting = true
+		return m, tea.Quit
+	case tea.KeyUp:
+		m.cursor--
+		if m.cursor < 0 {
+			m.cursor = len(m.options)
+		}
+	case tea.KeyDown:
+		m.cursor++
+		if m.cursor > len(m.options) {
+			m.cursor = 0
+		}
+	case tea.KeyRunes:
+		switch keyMsg.String() {
+		case "j":
+			m.cursor++
+			if m.cursor > len(m.options) {
+				m.cursor = 0
+			}
+		case "k":
+			m.cursor--
+			if m.cursor < 0 {
+				m.cursor = len(m.options)
+			}
+		case "q":
+			m.canceled = true
+			m.quitting = true
+			return m, tea.Quit
+		}
+	default:
+	}
+
+	return m, nil
+}
+
+func (m conflictModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).
+		Foreground(lipgloss.Color(m.colors.Accent))
+	activeStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.DialogAction))
+	dangerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.DialogDanger))
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.DialogHint)).Faint(true)
+
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render(app.T_("File conflicts detected:")))
+	sb.WriteString("\n")
+
+	for _, c := range m.conflicts {
+		sb.WriteString(fmt.Sprintf("  %s\n", c.Path))
+		sb.WriteString(hintStyle.Render(fmt.Sprintf("    %s ↔ %s", c.Package, c.ConflictsWith)) + "\n")
+	}
+
+	sb.WriteString("\n")
+	for i, o := range m.options {
+		if i == m.cursor {
+			sb.WriteString(activeStyle.Render("  › "+o.label) + "\n")
+		} else {
+			sb.WriteString("    " + o.label + "\n")
+		}
+	}
+
+	cancelLabel := app.T_("Abort")
+	if m.cursor == len(m.options) {
+		sb.WriteString(dangerStyle.Render("  › "+cancelLabel) + "\n")
+	} else {
+		sb.WriteString(hintStyle.Render("    "+cancelLabel) + "\n")
+	}
+
+	sb.WriteString(hintStyle.Render(app.T_("Navigation: ↑/↓ or j/k - select, Enter - confirm, Esc/q - cancel")))
+
+	return sb.String()
+}
+
+// SelectConflictPolicy показывает список файловых конфликтов и предлагает способ их разрешения.
+// В неинтерактивном режиме и с заранее заданным ответом возвращает apt.ConflictPolicyNone.
+func SelectConflictPolicy(ctx context.Context, appConfig *app.Config, conflicts []apt.FileConflict) (apt.ConflictPolicy, error) {
+	if !reply.IsInteractive(appConfig) || helper.AnswerFromContext(ctx) != "" || len(conflicts) == 0 {
+		return apt.ConflictPolicyNone, nil
+	}
+
+	m := newConflictModel(conflicts, appConfig.ConfigManager.GetColors())
+	p := tea.NewProgram(m,
+		tea.WithOutput(os.Stdout),
+		tea.WithoutSignalHandler())
+
+	finalModel, err := p.Run()
+	if err != nil {
+		return apt.ConflictPolicyNone, fmt.Errorf(app.TL_(ctx, "Error starting selector: %v"), err)
+	}
+
+	if result, ok := finalModel.(conflictModel); ok && !result.canceled && result.selected != apt.ConflictPolicyNone {
+		return result.selected, nil
+	}
+
+	return apt.ConflictPolicyNone, errors.New(app.TL_(ctx, "Operation cancelled"))
+}
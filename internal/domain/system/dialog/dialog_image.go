@@ -18,7 +18,9 @@ package dialog
 
 import (
 	"apm/internal/common/app"
+	"apm/internal/common/helper"
 	"apm/internal/common/reply"
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -54,8 +56,13 @@ type packageItem struct {
 }
 
 // NewPackageSelectionDialog запускает диалог выбора пакетов для установки/удаления
-func NewPackageSelectionDialog(appConfig *app.Config, installPkgs, removePkgs []string) (*PackageSelectionResult, error) {
-	if !reply.IsInteractive(appConfig) {
+// С заранее заданным отказом выбор отменяется, с согласием принимаются все пакеты.
+func NewPackageSelectionDialog(ctx context.Context, appConfig *app.Config, installPkgs, removePkgs []string) (*PackageSelectionResult, error) {
+	answer := helper.AnswerFromContext(ctx)
+	if answer == helper.AnswerNo {
+		return &PackageSelectionResult{Canceled: true}, nil
+	}
+	if answer == helper.AnswerYes || !reply.IsInteractive(appConfig) {
 		return &PackageSelectionResult{
 			InstallPackages: installPkgs,
 			RemovePackages:  removePkgs,
@@ -78,7 +85,7 @@ func NewPackageSelectionDialog(appConfig *app.Config, installPkgs, removePkgs []
 		removePackages:  removeItems,
 		currentPanel:    0,
 		cursor:          0,
-		choices:         []string{app.T_("Apply"), app.T_("Abort")},
+		choices:         []string{app.TL_(ctx, "Apply"), app.TL_(ctx, "Abort")},
 		width:           80,
 		height:          24,
 		appConfig:       appConfig,
@@ -91,13 +98,13 @@ func NewPackageSelectionDialog(appConfig *app.Config, installPkgs, removePkgs []
 
 	finalModel, err := p.Run()
 	if err != nil {
-		app.Log.Errorf(app.T_("Error starting TEA: %v"), err)
+		app.Log.Errorf(app.TL_(ctx, "Error starting TEA: %v"), err)
 		return nil, err
 	}
 
 	if m, ok := finalModel.(selectionModel); ok {
-		if m.canceled || m.choice == app.T_("Abort") {
-			return &PackageSelectionResult{Canceled: true}, errors.New(app.T_("Operation cancelled"))
+		if m.canceled || m.choice == app.TL_(ctx, "Abort") {
+			return &PackageSelectionResult{Canceled: true}, errors.New(app.TL_(ctx, "Operation cancelled"))
 		}
 
 		// Собираем выбранные пакеты
@@ -120,7 +127,7 @@ func NewPackageSelectionDialog(appConfig *app.Config, installPkgs, removePkgs []
 		}, nil
 	}
 
-	return &PackageSelectionResult{Canceled: true}, errors.New(app.T_("Operation cancelled"))
+	return &PackageSelectionResult{Canceled: true}, errors.New(app.TL_(ctx, "Operation cancelled"))
 }
 
 func (m selectionModel) Init() tea.Cmd {
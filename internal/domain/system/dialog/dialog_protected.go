@@ -0,0 +1,131 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package dialog
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/helper"
+	"apm/internal/common/reply"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type phraseModel struct {
+	packages []string
+	phrase   string
+	input    []rune
+	done     bool
+	canceled bool
+	colors   app.Colors
+}
+
+func (m phraseModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m phraseModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.Type {
+	case tea.KeyCtrlC, tea.KeyEsc:
+		m.canceled = true
+		m.done = true
+		return m, tea.Quit
+	case tea.KeyEnter:
+		m.done = true
+		return m, tea.Quit
+	case tea.KeyBackspace:
+		if len(m.input) > 0 {
+			m.input = m.input[:len(m.input)-1]
+		}
+	case tea.KeySpace:
+		m.input = append(m.input, ' ')
+	case tea.KeyRunes:
+		m.input = append(m.input, keyMsg.Runes...)
+	default:
+	}
+
+	return m, nil
+}
+
+func (m phraseModel) View() string {
+	if m.done {
+		return ""
+	}
+
+	dangerStyle := lipgloss.NewStyle().Bold(true).
+		Foreground(lipgloss.Color(m.colors.DialogDanger))
+	accentStyle := lipgloss.NewStyle().Bold(true).
+		Foreground(lipgloss.Color(m.colors.Accent))
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.DialogHint)).Faint(true)
+
+	var sb strings.Builder
+	sb.WriteString(dangerStyle.Render(app.T_("WARNING: Protected packages will be removed:")))
+	sb.WriteString("\n")
+	for _, pkg := range m.packages {
+		sb.WriteString(fmt.Sprintf("  %s\n", pkg))
+	}
+	sb.WriteString("\n")
+	sb.WriteString(app.T_("The system may become unbootable. To continue, type the phrase:"))
+	sb.WriteString("\n  " + accentStyle.Render(m.phrase) + "\n\n")
+	sb.WriteString("> " + string(m.input) + "█\n")
+	sb.WriteString(hintStyle.Render(app.T_("Enter - confirm, Esc - cancel")))
+
+	return sb.String()
+}
+
+// ConfirmPhrase просит ввести фразу подтверждения перед удалением защищённых пакетов.
+// В неинтерактивном режиме и с заранее заданным ответом подтверждение невозможно и возвращается ошибка.
+func ConfirmPhrase(ctx context.Context, appConfig *app.Config, packages []string, phrase string) error {
+	if !reply.IsInteractive(appConfig) || helper.AnswerFromContext(ctx) != "" {
+		return errors.New(app.TL_(ctx, "Removing protected packages requires typing a confirmation phrase in an interactive terminal"))
+	}
+
+	m := phraseModel{
+		packages: packages,
+		phrase:   phrase,
+		colors:   appConfig.ConfigManager.GetColors(),
+	}
+	p := tea.NewProgram(m,
+		tea.WithOutput(os.Stdout),
+		tea.WithoutSignalHandler())
+
+	finalModel, err := p.Run()
+	if err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Error starting selector: %v"), err)
+	}
+
+	result, ok := finalModel.(phraseModel)
+	if !ok || result.canceled {
+		return errors.New(app.TL_(ctx, "Operation cancelled"))
+	}
+	if strings.TrimSpace(string(result.input)) != phrase {
+		return errors.New(app.TL_(ctx, "The confirmation phrase does not match"))
+	}
+
+	return nil
+}
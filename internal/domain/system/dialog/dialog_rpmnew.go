@@ -0,0 +1,176 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package dialog
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/helper"
+	"apm/internal/common/reply"
+	"apm/internal/domain/system/rpmnew"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// rpmnewDiffLines сколько строк diff показывать в диалоге
+const rpmnewDiffLines = 20
+
+type rpmnewOption struct {
+	action string
+	label  string
+}
+
+type rpmnewModel struct {
+	file     rpmnew.File
+	diff     []string
+	position string
+	options  []rpmnewOption
+	cursor   int
+	selected string
+	quitting bool
+	colors   app.Colors
+}
+
+func newRpmnewModel(file rpmnew.File, diff string, position string, colors app.Colors) rpmnewModel {
+	return rpmnewModel{
+		file:     file,
+		diff:     strings.Split(strings.TrimRight(diff, "\n"), "\n"),
+		position: position,
+		options: []rpmnewOption{
+			{rpmnew.ActionKeep, app.T_("Keep the current file")},
+			{rpmnew.ActionReplace, app.T_("Replace with the new version (save current as .rpmsave)")},
+			{rpmnew.ActionMerge, app.T_("Merge changes (MERGEPROG, vimdiff by default)")},
+			{rpmnew.ActionSkip, app.T_("Decide later")},
+		},
+		selected: rpmnew.ActionSkip,
+		colors:   colors,
+	}
+}
+
+func (m rpmnewModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m rpmnewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.Type {
+	case tea.KeyCtrlC, tea.KeyEsc:
+		m.quitting = true
+		return m, tea.Quit
+	case tea.KeyEnter:
+		m.selected = m.options[m.cursor].action
+		m.quitting = true
+		return m, tea.Quit
+	case tea.KeyUp:
+		m.cursor = (m.cursor - 1 + len(m.options)) % len(m.options)
+	case tea.KeyDown:
+		m.cursor = (m.cursor + 1) % len(m.options)
+	case tea.KeyRunes:
+		switch keyMsg.String() {
+		case "j":
+			m.cursor = (m.cursor + 1) % len(m.options)
+		case "k":
+			m.cursor = (m.cursor - 1 + len(m.options)) % len(m.options)
+		case "q":
+			m.quitting = true
+			return m, tea.Quit
+		}
+	default:
+	}
+
+	return m, nil
+}
+
+func (m rpmnewModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).
+		Foreground(lipgloss.Color(m.colors.Accent))
+	activeStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.DialogAction))
+	dangerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.DialogDanger))
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.colors.DialogHint)).Faint(true)
+
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render(fmt.Sprintf(app.T_("New configuration file version %s: %s"), m.position, m.file.Path)))
+	sb.WriteString("\n\n")
+
+	for i, line := range m.diff {
+		if i == rpmnewDiffLines {
+			rest := len(m.diff) - rpmnewDiffLines
+			sb.WriteString(hintStyle.Render(fmt.Sprintf(app.TN_("  … %d more line", "  … %d more lines", rest), rest)) + "\n")
+			break
+		}
+		switch {
+		case strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++"):
+			sb.WriteString("  " + activeStyle.Render(line) + "\n")
+		case strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---"):
+			sb.WriteString("  " + dangerStyle.Render(line) + "\n")
+		default:
+			sb.WriteString("  " + hintStyle.Render(line) + "\n")
+		}
+	}
+
+	sb.WriteString("\n")
+	for i, o := range m.options {
+		if i == m.cursor {
+			sb.WriteString(activeStyle.Render("  › "+o.label) + "\n")
+		} else {
+			sb.WriteString("    " + o.label + "\n")
+		}
+	}
+
+	sb.WriteString(hintStyle.Render(app.T_("Navigation: ↑/↓ or j/k - select, Enter - confirm, Esc/q - decide later")))
+
+	return sb.String()
+}
+
+// SelectRpmnewAction показывает diff конфигурационного файла и его .rpmnew версии и предлагает
+// способ слияния. В неинтерактивном режиме и с заранее заданным ответом решение откладывается.
+func SelectRpmnewAction(ctx context.Context, appConfig *app.Config, file rpmnew.File, diff string, position string) (string, error) {
+	if !reply.IsInteractive(appConfig) || helper.AnswerFromContext(ctx) != "" {
+		return rpmnew.ActionSkip, nil
+	}
+
+	m := newRpmnewModel(file, diff, position, appConfig.ConfigManager.GetColors())
+	p := tea.NewProgram(m,
+		tea.WithOutput(os.Stdout),
+		tea.WithoutSignalHandler())
+
+	finalModel, err := p.Run()
+	if err != nil {
+		return rpmnew.ActionSkip, fmt.Errorf(app.TL_(ctx, "Error starting selector: %v"), err)
+	}
+
+	if result, ok := finalModel.(rpmnewModel); ok {
+		return result.selected, nil
+	}
+
+	return rpmnew.ActionSkip, nil
+}
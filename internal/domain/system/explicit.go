@@ -0,0 +1,94 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// ExplicitPackages возвращает установленные пакеты, от которых не зависит ни один другой установленный
+// пакет. Остальные пакеты ставятся как их зависимости, поэтому этого набора достаточно, чтобы повторить
+// систему. Пакеты, которых нет в подключённых репозиториях, не учитываются.
+func (a *Actions) ExplicitPackages(ctx context.Context) (*ExplicitPackagesResponse, error) {
+	if err := a.validateDB(ctx, false); err != nil {
+		return nil, err
+	}
+
+	installed, err := a.serviceAptActions.GetInstalledPackages(ctx)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeApt, err)
+	}
+
+	names := make([]string, 0, len(installed))
+	for name := range installed {
+		if !slices.Contains(orphanIgnored, name) {
+			names = append(names, name)
+		}
+	}
+
+	known, err := a.serviceAptDatabase.GetPackagesByNames(ctx, names)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+
+	// Для каждой capability считаем, сколько установленных пакетов от неё зависит
+	required := make(map[string]int)
+	for _, pkg := range known {
+		seen := make(map[string]bool, len(pkg.Depends))
+		for _, dep := range pkg.Depends {
+			if !seen[dep] {
+				seen[dep] = true
+				required[dep]++
+			}
+		}
+	}
+
+	packages := make([]string, 0)
+	for _, pkg := range known {
+		// Пакет без файла в репозитории не удастся поставить на другой системе
+		if pkg.Filename == "" {
+			continue
+		}
+
+		leaf := true
+		for _, capability := range append([]string{pkg.Name}, pkg.Provides...) {
+			count := required[capability]
+			if slices.Contains(pkg.Depends, capability) {
+				count--
+			}
+			if count > 0 {
+				leaf = false
+				break
+			}
+		}
+		if leaf {
+			packages = append(packages, pkg.Name)
+		}
+	}
+	sort.Strings(packages)
+
+	return &ExplicitPackagesResponse{
+		Message:  fmt.Sprintf(app.TLN_(ctx, "%d explicitly installed package", "%d explicitly installed packages", len(packages)), len(packages)),
+		Packages: packages,
+		Count:    len(packages),
+	}, nil
+}
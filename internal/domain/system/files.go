@@ -0,0 +1,152 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	aptBinding "apm/internal/common/binding/apt"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// FileTypeBin исполняемый файл
+	FileTypeBin = "bin"
+	// FileTypeConfig файл конфигурации
+	FileTypeConfig = "config"
+	// FileTypeDoc документация, man-страницы и лицензии
+	FileTypeDoc = "doc"
+	// FileTypeOther прочие файлы
+	FileTypeOther = "other"
+
+	// FilesSourceRpm манифест получен из базы RPM установленного пакета
+	FilesSourceRpm = "rpm"
+	// FilesSourceRepository манифест получен из индекса репозитория
+	FilesSourceRepository = "repository"
+)
+
+var (
+	binDirs = []string{"/bin/", "/sbin/", "/usr/bin/", "/usr/sbin/", "/usr/libexec/", "/usr/local/bin/", "/usr/local/sbin/"}
+	docDirs = []string{"/usr/share/doc/", "/usr/share/man/", "/usr/share/info/", "/usr/share/licenses/"}
+)
+
+// classifyFile определяет тип файла по флагам RPM (если известны) и расположению.
+func classifyFile(path, flags string) string {
+	switch {
+	case strings.Contains(flags, "c"), strings.HasPrefix(path, "/etc/"):
+		return FileTypeConfig
+	case strings.Contains(flags, "d"), strings.Contains(flags, "l"):
+		return FileTypeDoc
+	}
+
+	for _, dir := range binDirs {
+		if strings.HasPrefix(path, dir) {
+			return FileTypeBin
+		}
+	}
+	for _, dir := range docDirs {
+		if strings.HasPrefix(path, dir) {
+			return FileTypeDoc
+		}
+	}
+
+	return FileTypeOther
+}
+
+// filterFiles оставляет только файлы указанного типа. Пустой тип означает все файлы.
+func filterFiles(files []PackageFile, fileType string) []PackageFile {
+	if fileType == "" {
+		return files
+	}
+
+	result := make([]PackageFile, 0, len(files))
+	for _, f := range files {
+		if f.Type == fileType {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+// Files возвращает список файлов пакета. Для установленного пакета список берётся из базы RPM,
+// для неустановленного — из индекса репозитория, если он содержит список файлов.
+func (a *Actions) Files(ctx context.Context, packageName string, fileType string) (*FilesResponse, error) {
+	packageName = strings.TrimSpace(packageName)
+	if packageName == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Package name must be specified, for example files package")))
+	}
+
+	fileType = strings.ToLower(strings.TrimSpace(fileType))
+	switch fileType {
+	case "", FileTypeBin, FileTypeConfig, FileTypeDoc:
+	default:
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.TL_(ctx, "Unknown file type: %s. Allowed values: bin, config, doc"), fileType))
+	}
+
+	resp := &FilesResponse{Package: packageName}
+
+	rpmFiles, installed, err := a.serviceAptActions.GetInstalledFiles(ctx, packageName)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeApt, err)
+	}
+
+	if installed {
+		resp.Installed = true
+		resp.Source = FilesSourceRpm
+		resp.Files = convertRpmFiles(rpmFiles)
+	} else {
+		if err = a.validateDB(ctx, false); err != nil {
+			return nil, err
+		}
+
+		packageInfo, errFind := a.serviceAptDatabase.GetPackageByName(ctx, packageName)
+		if errFind != nil {
+			return nil, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.TL_(ctx, "Failed to retrieve information about the package %s"), packageName))
+		}
+		if len(packageInfo.Files) == 0 {
+			return nil, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.TL_(ctx, "Package %s is not installed and the repository index has no file list for it"), packageName))
+		}
+
+		resp.Source = FilesSourceRepository
+		resp.Files = make([]PackageFile, 0, len(packageInfo.Files))
+		for _, path := range packageInfo.Files {
+			resp.Files = append(resp.Files, PackageFile{Path: path, Type: classifyFile(path, "")})
+		}
+	}
+
+	resp.Files = filterFiles(resp.Files, fileType)
+	resp.Count = len(resp.Files)
+	resp.Message = fmt.Sprintf(app.TLN_(ctx, "%d file found", "%d files found", resp.Count), resp.Count)
+
+	return resp, nil
+}
+
+// convertRpmFiles преобразует манифест RPM в список файлов пакета.
+func convertRpmFiles(rpmFiles []aptBinding.RpmFileInfo) []PackageFile {
+	files := make([]PackageFile, 0, len(rpmFiles))
+	for _, f := range rpmFiles {
+		files = append(files, PackageFile{
+			Path: f.Path,
+			Type: classifyFile(f.Path, f.Flags),
+			Mode: f.Mode,
+		})
+	}
+	return files
+}
@@ -0,0 +1,238 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	_package "apm/internal/common/apt/package"
+	"apm/internal/domain/system/group"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// GroupPackage состояние пакета группы в системе
+type GroupPackage struct {
+	Name      string `json:"name"`
+	Optional  bool   `json:"optional"`
+	Available bool   `json:"available"`
+	Installed bool   `json:"installed"`
+}
+
+// GroupSummary группа пакетов с числом установленных обязательных пакетов
+type GroupSummary struct {
+	group.Group
+	InstalledCount int  `json:"installedCount"`
+	Installed      bool `json:"installed"`
+}
+
+// markGroups отмечает в сведениях о пакетах группы, из-за которых они попали в транзакцию.
+func (a *Actions) markGroups(packagesInfo []_package.Package) {
+	for i := range packagesInfo {
+		if name, ok := a.packageGroups[packagesInfo[i].Name]; ok {
+			packagesInfo[i].Group = name
+		}
+	}
+}
+
+// resolveGroups находит группы по именам и возвращает их пакеты без повторов вместе с
+// соответствием пакета первой группе, в которую он входит.
+func (a *Actions) resolveGroups(ctx context.Context, names []string, withOptional bool) ([]group.Group, []string, map[string]string, error) {
+	if len(names) == 0 {
+		return nil, nil, nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "At least one group must be specified")))
+	}
+
+	var (
+		groups   []group.Group
+		packages []string
+		owners   = make(map[string]string)
+	)
+	for _, name := range names {
+		g, err := a.serviceGroups.Get(name)
+		if err != nil {
+			return nil, nil, nil, apmerr.New(apmerr.ErrorTypeNotFound, err)
+		}
+		groups = append(groups, g)
+		for _, pkg := range g.PackageNames(withOptional) {
+			if _, ok := owners[pkg]; ok {
+				continue
+			}
+			owners[pkg] = g.Name
+			packages = append(packages, pkg)
+		}
+	}
+	return groups, packages, owners, nil
+}
+
+// groupPackages возвращает состояние пакетов групп по базе пакетов.
+func (a *Actions) groupPackages(ctx context.Context, groups []group.Group) (map[string]GroupPackage, error) {
+	var names []string
+	for _, g := range groups {
+		names = append(names, g.PackageNames(true)...)
+	}
+
+	found, err := a.serviceAptDatabase.GetPackagesByNames(ctx, names)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+
+	state := make(map[string]GroupPackage, len(names))
+	for _, pkg := range found {
+		st := state[pkg.Name]
+		st.Name = pkg.Name
+		st.Available = true
+		st.Installed = st.Installed || pkg.Installed
+		state[pkg.Name] = st
+	}
+	return state, nil
+}
+
+// GroupList возвращает группы пакетов с числом установленных обязательных пакетов.
+func (a *Actions) GroupList(ctx context.Context) (*GroupListResponse, error) {
+	groups, err := a.serviceGroups.List()
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, err)
+	}
+
+	if err = a.validateDB(ctx, false); err != nil {
+		return nil, err
+	}
+
+	state, err := a.groupPackages(ctx, groups)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]GroupSummary, 0, len(groups))
+	for _, g := range groups {
+		s := GroupSummary{Group: g}
+		for _, pkg := range g.Packages {
+			if state[pkg].Installed {
+				s.InstalledCount++
+			}
+		}
+		s.Installed = s.InstalledCount == len(g.Packages)
+		summaries = append(summaries, s)
+	}
+
+	return &GroupListResponse{
+		Message: fmt.Sprintf(app.TLN_(ctx, "%d package group found", "%d package groups found", len(summaries)), len(summaries)),
+		Groups:  summaries,
+	}, nil
+}
+
+// GroupInfo возвращает описание группы и состояние её пакетов.
+func (a *Actions) GroupInfo(ctx context.Context, name string) (*GroupInfoResponse, error) {
+	g, err := a.serviceGroups.Get(name)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, err)
+	}
+
+	if err = a.validateDB(ctx, false); err != nil {
+		return nil, err
+	}
+
+	state, err := a.groupPackages(ctx, []group.Group{g})
+	if err != nil {
+		return nil, err
+	}
+
+	packages := make([]GroupPackage, 0, len(g.Packages)+len(g.Optional))
+	for _, pkg := range g.PackageNames(true) {
+		st := state[pkg]
+		st.Name = pkg
+		st.Optional = !slices.Contains(g.Packages, pkg)
+		packages = append(packages, st)
+	}
+
+	return &GroupInfoResponse{
+		Message:  fmt.Sprintf(app.TL_(ctx, "Package group %s"), g.Name),
+		Group:    g,
+		Packages: packages,
+	}, nil
+}
+
+// GroupCheckInstall проверяет установку пакетов групп без внесения изменений.
+func (a *Actions) GroupCheckInstall(ctx context.Context, names []string, withOptional bool) (*CheckResponse, error) {
+	_, packages, _, err := a.resolveGroups(ctx, names, withOptional)
+	if err != nil {
+		return nil, err
+	}
+	return a.CheckInstall(ctx, packages)
+}
+
+// GroupInstall устанавливает пакеты групп. Необязательные пакеты устанавливаются только с withOptional.
+func (a *Actions) GroupInstall(ctx context.Context, names []string, withOptional bool, confirm bool) (*InstallRemoveResponse, error) {
+	_, packages, owners, err := a.resolveGroups(ctx, names, withOptional)
+	if err != nil {
+		return nil, err
+	}
+
+	a.packageGroups = owners
+	defer func() { a.packageGroups = nil }()
+
+	return a.Install(ctx, packages, confirm, false)
+}
+
+// installedGroupPackages возвращает установленные пакеты групп, включая необязательные.
+func (a *Actions) installedGroupPackages(ctx context.Context, names []string) ([]string, map[string]string, error) {
+	groups, packages, owners, err := a.resolveGroups(ctx, names, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = a.validateDB(ctx, false); err != nil {
+		return nil, nil, err
+	}
+
+	state, err := a.groupPackages(ctx, groups)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	installed := slices.DeleteFunc(packages, func(pkg string) bool {
+		return !state[pkg].Installed
+	})
+	if len(installed) == 0 {
+		return nil, nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.TL_(ctx, "No packages of the specified groups are installed")))
+	}
+	return installed, owners, nil
+}
+
+// GroupCheckRemove проверяет удаление установленных пакетов групп без внесения изменений.
+func (a *Actions) GroupCheckRemove(ctx context.Context, names []string) (*CheckResponse, error) {
+	packages, _, err := a.installedGroupPackages(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	return a.CheckRemove(ctx, packages, false, false)
+}
+
+// GroupRemove удаляет установленные пакеты групп, включая необязательные.
+func (a *Actions) GroupRemove(ctx context.Context, names []string, confirm bool) (*InstallRemoveResponse, error) {
+	packages, owners, err := a.installedGroupPackages(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+
+	a.packageGroups = owners
+	defer func() { a.packageGroups = nil }()
+
+	return a.Remove(ctx, packages, false, false, confirm)
+}
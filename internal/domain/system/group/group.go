@@ -0,0 +1,141 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package group
+
+import (
+	"apm/internal/common/app"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// DefaultDirs каталоги описаний групп пакетов. Группа из /etc переопределяет одноимённую группу дистрибутива.
+var DefaultDirs = []string{
+	"/usr/share/apm/groups.d",
+	"/etc/apm/groups.d",
+}
+
+// Group описывает группу пакетов для типовой задачи. Идентификатор группы совпадает с именем файла без расширения.
+type Group struct {
+	ID          string   `yaml:"-" json:"id"`
+	Name        string   `yaml:"name" json:"name"`
+	Description string   `yaml:"description" json:"description"`
+	Packages    []string `yaml:"packages" json:"packages"`
+	Optional    []string `yaml:"optional" json:"optional"`
+	File        string   `yaml:"-" json:"file"`
+}
+
+// PackageNames возвращает обязательные пакеты группы и, с withOptional, необязательные.
+func (g Group) PackageNames(withOptional bool) []string {
+	if !withOptional {
+		return slices.Clone(g.Packages)
+	}
+	return slices.Concat(g.Packages, g.Optional)
+}
+
+// Service читает описания групп пакетов из YAML файлов.
+type Service struct {
+	dirs []string
+}
+
+// NewService создаёт сервис групп для указанных каталогов.
+func NewService(dirs []string) *Service {
+	return &Service{dirs: dirs}
+}
+
+// List возвращает группы, отсортированные по идентификатору. Каталоги обходятся по порядку,
+// поэтому группа из более позднего каталога заменяет одноимённую.
+func (s *Service) List() ([]Group, error) {
+	byID := make(map[string]Group)
+	for _, dir := range s.dirs {
+		entries, err := os.ReadDir(dir)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf(app.T_("Failed to read groups directory %s: %v"), dir, err)
+		}
+
+		for _, entry := range entries {
+			ext := filepath.Ext(entry.Name())
+			if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+				continue
+			}
+			g, err := readGroup(filepath.Join(dir, entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+			byID[g.ID] = g
+		}
+	}
+
+	groups := make([]Group, 0, len(byID))
+	for _, g := range byID {
+		groups = append(groups, g)
+	}
+	slices.SortFunc(groups, func(a, b Group) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return groups, nil
+}
+
+// Get возвращает группу по идентификатору или названию без учёта регистра.
+func (s *Service) Get(name string) (Group, error) {
+	groups, err := s.List()
+	if err != nil {
+		return Group{}, err
+	}
+	idx := slices.IndexFunc(groups, func(g Group) bool {
+		return strings.EqualFold(g.ID, name) || strings.EqualFold(g.Name, name)
+	})
+	if idx == -1 {
+		return Group{}, fmt.Errorf(app.T_("Package group %s not found"), name)
+	}
+	return groups[idx], nil
+}
+
+// readGroup читает и проверяет файл описания группы.
+func readGroup(path string) (Group, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Group{}, fmt.Errorf(app.T_("Failed to read package group %s: %v"), path, err)
+	}
+
+	var g Group
+	if err = yaml.Unmarshal(data, &g); err != nil {
+		return Group{}, fmt.Errorf(app.T_("Failed to parse package group %s: %v"), path, err)
+	}
+	g.ID = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	g.File = path
+	if g.Name == "" {
+		g.Name = g.ID
+	}
+	if len(g.Packages) == 0 {
+		return Group{}, fmt.Errorf(app.T_("Package group %s has no packages"), path)
+	}
+	for _, pkg := range slices.Concat(g.Packages, g.Optional) {
+		if strings.TrimSpace(pkg) == "" || strings.ContainsAny(pkg, " \t/") {
+			return Group{}, fmt.Errorf(app.T_("Invalid package name %q in group %s"), pkg, path)
+		}
+	}
+	return g, nil
+}
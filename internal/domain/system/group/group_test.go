@@ -0,0 +1,81 @@
+package group
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeGroup(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServiceList(t *testing.T) {
+	root := t.TempDir()
+	vendorDir, etcDir := filepath.Join(root, "usr"), filepath.Join(root, "etc")
+	writeGroup(t, vendorDir, "virtualization.yaml", "name: Virtualization\npackages: [qemu-kvm, libvirt]\noptional: [virt-viewer]\n")
+	writeGroup(t, vendorDir, "devel-c.yml", "name: Development C/C++\npackages: [gcc]\n")
+	writeGroup(t, etcDir, "devel-c.yaml", "name: Development C/C++\npackages: [gcc, gcc-c++, make]\n")
+	writeGroup(t, etcDir, "notes.txt", "not a group")
+
+	groups, err := NewService([]string{vendorDir, etcDir, filepath.Join(root, "missing")}).List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 2 || groups[0].ID != "devel-c" || groups[1].ID != "virtualization" {
+		t.Fatalf("unexpected groups: %+v", groups)
+	}
+	if !slices.Equal(groups[0].Packages, []string{"gcc", "gcc-c++", "make"}) {
+		t.Errorf("group from /etc must override the vendor one, got %v", groups[0].Packages)
+	}
+	if got := groups[1].PackageNames(true); !slices.Equal(got, []string{"qemu-kvm", "libvirt", "virt-viewer"}) {
+		t.Errorf("PackageNames(true) = %v", got)
+	}
+	if got := groups[1].PackageNames(false); !slices.Equal(got, []string{"qemu-kvm", "libvirt"}) {
+		t.Errorf("PackageNames(false) = %v", got)
+	}
+}
+
+func TestServiceGet(t *testing.T) {
+	dir := t.TempDir()
+	writeGroup(t, dir, "devel-c.yaml", "name: Development C/C++\npackages: [gcc]\n")
+	writeGroup(t, dir, "tools.yaml", "packages: [htop]\n")
+	svc := NewService([]string{dir})
+
+	for _, name := range []string{"devel-c", "development c/c++", "TOOLS"} {
+		if _, err := svc.Get(name); err != nil {
+			t.Errorf("Get(%q): %v", name, err)
+		}
+	}
+	if g, _ := svc.Get("tools"); g.Name != "tools" {
+		t.Errorf("group without name must use its ID, got %q", g.Name)
+	}
+	if _, err := svc.Get("games"); err == nil {
+		t.Error("expected error for unknown group")
+	}
+}
+
+func TestServiceListInvalid(t *testing.T) {
+	cases := map[string]string{
+		"empty":   "name: Empty\n",
+		"name":    "packages: [\"bad name\"]\n",
+		"syntax":  "packages: [gcc\n",
+		"slashes": "packages: [gcc]\noptional: [../etc]\n",
+	}
+	for name, content := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeGroup(t, dir, "bad.yaml", content)
+			if _, err := NewService([]string{dir}).List(); err == nil {
+				t.Fatal("expected validation error")
+			}
+		})
+	}
+}
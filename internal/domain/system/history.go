@@ -0,0 +1,82 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/journal"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// historyModules модули, операции которых попадают в историю
+var historyModules = []string{journal.ModuleSystem, journal.ModuleKernel, journal.ModuleRepository, journal.ModuleImage}
+
+// HistoryList возвращает историю операций всех модулей от новых к старым.
+// Пустой module означает все модули.
+func (a *Actions) HistoryList(ctx context.Context, module string, limit int, offset int) (*HistoryListResponse, error) {
+	if module != "" && !slices.Contains(historyModules, module) {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.TL_(ctx, "Unknown module %s, available: %s"),
+			module, strings.Join(historyModules, ", ")))
+	}
+	if limit < 0 || offset < 0 {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Limit and offset must not be negative")))
+	}
+
+	entries, total, err := a.serviceJournal.History(ctx, journal.HistoryFilter{Module: module, Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+	if entries == nil {
+		entries = []journal.Entry{}
+	}
+
+	return &HistoryListResponse{
+		Message:    fmt.Sprintf(app.TLN_(ctx, "%d operation found", "%d operations found", total), total),
+		Entries:    entries,
+		TotalCount: total,
+	}, nil
+}
+
+// HistoryShow возвращает операцию из истории по идентификатору вместе с командами для повтора и отмены.
+func (a *Actions) HistoryShow(ctx context.Context, id int) (*HistoryShowResponse, error) {
+	if id <= 0 {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Operation ID must be a positive number")))
+	}
+
+	entry, err := a.serviceJournal.Get(ctx, uint(id))
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+	if entry == nil {
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.TL_(ctx, "Operation %d not found in history"), id))
+	}
+
+	transaction := RecentTransaction{Entry: *entry}
+	if entry.Module == journal.ModuleSystem && entry.Status == journal.StatusDone {
+		transaction.Redo, transaction.Undo = transactionShortcuts(*entry)
+	}
+
+	return &HistoryShowResponse{
+		Message: fmt.Sprintf(app.TL_(ctx, "Operation %d"), id),
+		Entry:   transaction,
+	}, nil
+}
@@ -0,0 +1,215 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	_package "apm/internal/common/apt/package"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Hold удерживает установленные пакеты от обновления: они исключаются из симуляции и выполнения
+// обновления системы, а их версии закрепляются в настройках APT.
+func (a *Actions) Hold(ctx context.Context, packages []string) (*HoldResponse, error) {
+	ctx, release, err := a.operationLock.Acquire(ctx, "hold")
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	targets, err := a.holdTargets(ctx, packages)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = a.serviceAptDatabase.HoldPackages(ctx, targets); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+
+	return a.holdResponse(ctx, targets,
+		fmt.Sprintf(app.TLN_(ctx, "%s is held back from upgrades", "%s are held back from upgrades", len(targets)), strings.Join(targets, ", ")))
+}
+
+// CheckHold показывает, какие пакеты будут удержаны, не меняя базу и настройки APT.
+func (a *Actions) CheckHold(ctx context.Context, packages []string) (*HoldResponse, error) {
+	targets, err := a.holdTargets(ctx, packages)
+	if err != nil {
+		return nil, err
+	}
+
+	held, err := a.serviceAptDatabase.GetHeldPackages(ctx)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+	held = append(slices.Clone(held), targets...)
+	slices.Sort(held)
+
+	return &HoldResponse{
+		Message:  app.TL_(ctx, "Simulation results"),
+		Packages: targets,
+		Held:     held,
+	}, nil
+}
+
+// Unhold снимает удержание с пакетов и возвращает их в обновление системы.
+func (a *Actions) Unhold(ctx context.Context, packages []string) (*HoldResponse, error) {
+	ctx, release, err := a.operationLock.Acquire(ctx, "unhold")
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	targets, _, err := a.unholdTargets(ctx, packages)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = a.serviceAptDatabase.UnholdPackages(ctx, targets); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+
+	return a.holdResponse(ctx, targets,
+		fmt.Sprintf(app.TLN_(ctx, "%s is no longer held", "%s are no longer held", len(targets)), strings.Join(targets, ", ")))
+}
+
+// CheckUnhold показывает, с каких пакетов будет снято удержание, не меняя базу и настройки APT.
+func (a *Actions) CheckUnhold(ctx context.Context, packages []string) (*HoldResponse, error) {
+	targets, held, err := a.unholdTargets(ctx, packages)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := []string{}
+	for _, name := range held {
+		if !slices.Contains(targets, name) {
+			remaining = append(remaining, name)
+		}
+	}
+
+	return &HoldResponse{
+		Message:  app.TL_(ctx, "Simulation results"),
+		Packages: targets,
+		Held:     remaining,
+	}, nil
+}
+
+// holdTargets проверяет, что пакеты установлены, и возвращает те из них, которые ещё не удержаны.
+func (a *Actions) holdTargets(ctx context.Context, packages []string) ([]string, error) {
+	names, err := holdNames(ctx, packages)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = a.validateDB(ctx, false); err != nil {
+		return nil, err
+	}
+
+	found, err := a.serviceAptDatabase.GetPackagesByNames(ctx, names)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+
+	for _, name := range names {
+		idx := slices.IndexFunc(found, func(p _package.Package) bool { return p.Name == name })
+		if idx < 0 {
+			return nil, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.TL_(ctx, "Package %s not found"), name))
+		}
+		if !found[idx].Installed {
+			return nil, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.TL_(ctx, "Package %s is not installed"), name))
+		}
+	}
+
+	held, err := a.serviceAptDatabase.GetHeldPackages(ctx)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+
+	var targets []string
+	for _, name := range names {
+		if !slices.Contains(held, name) {
+			targets = append(targets, name)
+		}
+	}
+	if len(targets) == 0 {
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.TL_(ctx, "Packages are already held")))
+	}
+
+	return targets, nil
+}
+
+// unholdTargets возвращает удержанные пакеты из списка и все удерживаемые сейчас пакеты.
+func (a *Actions) unholdTargets(ctx context.Context, packages []string) ([]string, []string, error) {
+	names, err := holdNames(ctx, packages)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	held, err := a.serviceAptDatabase.GetHeldPackages(ctx)
+	if err != nil {
+		return nil, nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+
+	var targets []string
+	for _, name := range names {
+		if slices.Contains(held, name) {
+			targets = append(targets, name)
+		}
+	}
+	if len(targets) == 0 {
+		return nil, nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.TL_(ctx, "Packages are not held")))
+	}
+
+	return targets, held, nil
+}
+
+// holdResponse обновляет закрепления APT и собирает ответ со списком удерживаемых пакетов.
+func (a *Actions) holdResponse(ctx context.Context, changed []string, message string) (*HoldResponse, error) {
+	if err := a.serviceAptActions.SyncHoldPreferences(ctx); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeApt, fmt.Errorf(app.TL_(ctx, "Failed to update APT preferences: %w"), err))
+	}
+
+	held, err := a.serviceAptDatabase.GetHeldPackages(ctx)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+
+	return &HoldResponse{
+		Message:  message,
+		Packages: changed,
+		Held:     held,
+	}, nil
+}
+
+// holdNames очищает список имён пакетов и проверяет, что он не пуст.
+func holdNames(ctx context.Context, packages []string) ([]string, error) {
+	var names []string
+	for _, name := range packages {
+		name = strings.TrimSpace(name)
+		if name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "At least one package must be specified")))
+	}
+	return names, nil
+}
@@ -0,0 +1,122 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	aptLib "apm/internal/common/binding/apt/lib"
+	"apm/internal/common/hooks"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// hookTransaction формирует описание транзакции для хуков по планируемым изменениям пакетов.
+func hookTransaction(ctx context.Context, event string, targets []string, changes *aptLib.PackageChanges) hooks.Transaction {
+	tx := hooks.NewTransaction(ctx, event, targets)
+	if changes != nil {
+		tx.Installed = changes.NewInstalledPackages
+		tx.Upgraded = changes.UpgradedPackages
+		tx.Removed = changes.RemovedPackages
+	}
+	return tx
+}
+
+// beforeHooks запускает хуки перед транзакцией. Ошибка означает отмену транзакции хуком.
+func (a *Actions) beforeHooks(ctx context.Context, tx hooks.Transaction) error {
+	if a.serviceHooks == nil {
+		return nil
+	}
+	return a.serviceHooks.Before(ctx, tx)
+}
+
+// afterHooks запускает хуки после транзакции с её результатом opErr.
+func (a *Actions) afterHooks(ctx context.Context, tx hooks.Transaction, opErr error) error {
+	if a.serviceHooks == nil {
+		return nil
+	}
+	return a.serviceHooks.After(ctx, tx, opErr)
+}
+
+// HooksList возвращает хуки транзакций в порядке выполнения.
+func (a *Actions) HooksList(ctx context.Context) (*HooksListResponse, error) {
+	list, err := a.serviceHooks.List(ctx)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeHook, err)
+	}
+
+	return &HooksListResponse{
+		Message: fmt.Sprintf(app.TLN_(ctx, "%d hook found", "%d hooks found", len(list)), len(list)),
+		Dir:     a.appConfig.ConfigManager.GetConfig().PathHooksDir,
+		Hooks:   list,
+	}, nil
+}
+
+// HooksTest запускает хук с пробной транзакцией события event в фазе phase. Пустое событие означает
+// первое событие хука. Пакеты передаются хуку как цели и изменения транзакции.
+func (a *Actions) HooksTest(ctx context.Context, name, phase, event string, packages []string) (*HooksTestResponse, error) {
+	hook, err := a.serviceHooks.Get(ctx, name)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, err)
+	}
+
+	if phase == "" {
+		phase = hooks.PhasePre
+	}
+	if !slices.Contains(hooks.Phases, phase) {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation,
+			fmt.Errorf(app.TL_(ctx, "Unknown hook phase %s, available: %s"), phase, strings.Join(hooks.Phases, ", ")))
+	}
+	if event == "" {
+		event = hook.Events[0]
+	}
+	if !slices.Contains(hooks.Events, event) {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation,
+			fmt.Errorf(app.TL_(ctx, "Unknown hook event %s, available: %s"), event, strings.Join(hooks.Events, ", ")))
+	}
+
+	tx := hooks.NewTransaction(ctx, event, packages)
+	tx.Phase = phase
+	switch event {
+	case hooks.EventInstall, hooks.EventKernelInstall:
+		tx.Installed = packages
+	case hooks.EventRemove:
+		tx.Removed = packages
+	case hooks.EventUpgrade:
+		tx.Upgraded = packages
+	}
+	if phase == hooks.PhasePost {
+		tx.Status = hooks.StatusDone
+	}
+
+	result := a.serviceHooks.Exec(ctx, hook, tx)
+	if !result.Success {
+		return nil, apmerr.New(apmerr.ErrorTypeHook, fmt.Errorf(app.TL_(ctx, "Hook %s failed: %s"), hook.Name, result.Error))
+	}
+
+	msg := fmt.Sprintf(app.TL_(ctx, "Hook %s completed successfully"), hook.Name)
+	if !hook.Matches(phase, event) {
+		msg += fmt.Sprintf(app.TL_(ctx, ". The hook is not subscribed to %s-%s and will not run in real transactions"), phase, event)
+	}
+
+	return &HooksTestResponse{
+		Message: msg,
+		Result:  result,
+	}, nil
+}
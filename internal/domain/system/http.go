@@ -132,6 +132,36 @@ func (w *HTTPWrapper) CheckInstall(rw http.ResponseWriter, r *http.Request) {
 	w.WriteJSON(rw, reply.OK(resp))
 }
 
+// CheckReinstall проверяет возможность переустановки пакетов.
+func (w *HTTPWrapper) CheckReinstall(rw http.ResponseWriter, r *http.Request) {
+	body, err := w.ParseBodyParams(r)
+	if err != nil {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
+		return
+	}
+
+	var packages []string
+
+	if err = reply.UnmarshalField(body, "packages", &packages); err != nil {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
+		return
+	}
+
+	if w.RunBackground(rw, r, reply.EventSystemCheckReinstall, func(ctx context.Context) (interface{}, error) {
+		return w.actions.CheckReinstall(ctx, packages)
+	}) {
+		return
+	}
+
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.CheckReinstall(ctx, packages)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
 // CheckUpgrade проверяет возможность обновления системы.
 func (w *HTTPWrapper) CheckUpgrade(rw http.ResponseWriter, r *http.Request) {
 	if w.RunBackground(rw, r, reply.EventSystemCheckUpgrade, func(ctx context.Context) (interface{}, error) {
@@ -149,6 +179,40 @@ func (w *HTTPWrapper) CheckUpgrade(rw http.ResponseWriter, r *http.Request) {
 	w.WriteJSON(rw, reply.OK(resp))
 }
 
+// CheckAutoRemove проверяет неиспользуемые пакеты перед удалением.
+func (w *HTTPWrapper) CheckAutoRemove(rw http.ResponseWriter, r *http.Request) {
+	if w.RunBackground(rw, r, reply.EventSystemCheckAutoRemove, func(ctx context.Context) (interface{}, error) {
+		return w.actions.CheckAutoRemove(ctx)
+	}) {
+		return
+	}
+
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.CheckAutoRemove(ctx)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// AutoRemove удаляет неиспользуемые пакеты.
+func (w *HTTPWrapper) AutoRemove(rw http.ResponseWriter, r *http.Request) {
+	if w.RunBackground(rw, r, reply.EventSystemAutoRemove, func(ctx context.Context) (interface{}, error) {
+		return w.actions.AutoRemove(ctx, true)
+	}) {
+		return
+	}
+
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.AutoRemove(ctx, true)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
 // Remove удаляет пакеты.
 func (w *HTTPWrapper) Remove(rw http.ResponseWriter, r *http.Request) {
 	body, err := w.ParseBodyParams(r)
@@ -221,6 +285,36 @@ func (w *HTTPWrapper) Install(rw http.ResponseWriter, r *http.Request) {
 	w.WriteJSON(rw, reply.OK(resp))
 }
 
+// Reinstall переустанавливает пакеты.
+func (w *HTTPWrapper) Reinstall(rw http.ResponseWriter, r *http.Request) {
+	body, err := w.ParseBodyParams(r)
+	if err != nil {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
+		return
+	}
+
+	var packages []string
+
+	if err = reply.UnmarshalField(body, "packages", &packages); err != nil {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
+		return
+	}
+
+	if w.RunBackground(rw, r, reply.EventSystemReinstall, func(ctx context.Context) (interface{}, error) {
+		return w.actions.Reinstall(ctx, packages, true)
+	}) {
+		return
+	}
+
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.Reinstall(ctx, packages, true)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
 // Info возвращает информацию о пакете.
 func (w *HTTPWrapper) Info(rw http.ResponseWriter, r *http.Request) {
 	name := r.PathValue("name")
@@ -338,9 +432,10 @@ func (w *HTTPWrapper) Search(rw http.ResponseWriter, r *http.Request) {
 	q := query.Get("q")
 	installed := query.Get("installed") == "true"
 	full := query.Get("full") == "true"
+	description := query.Get("description") == "true"
 
 	ctx := w.CtxWithTransaction(r)
-	resp, err := w.actions.Search(ctx, q, installed)
+	resp, err := w.actions.Search(ctx, q, installed, description)
 	if err != nil {
 		reply.WriteHTTPError(rw, err)
 		return
@@ -427,15 +522,27 @@ func (w *HTTPWrapper) ImageApply(rw http.ResponseWriter, r *http.Request) {
 	hostCache := r.URL.Query().Get("no_cache") != "true"
 	configPath := r.URL.Query().Get("config")
 	workdir := r.URL.Query().Get("workdir")
+	tag := r.URL.Query().Get("tag")
 
 	if w.RunBackground(rw, r, reply.EventSystemImageApply, func(ctx context.Context) (interface{}, error) {
-		return w.actions.ImageApply(ctx, pullImage, hostCache, configPath, workdir)
+		return w.actions.ImageApply(ctx, pullImage, hostCache, configPath, workdir, tag)
 	}) {
 		return
 	}
 
 	ctx := w.CtxWithTransaction(r)
-	resp, err := w.actions.ImageApply(ctx, pullImage, hostCache, configPath, workdir)
+	resp, err := w.actions.ImageApply(ctx, pullImage, hostCache, configPath, workdir, tag)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// ImageRollback возвращает загрузку к предыдущему развёртыванию образа.
+func (w *HTTPWrapper) ImageRollback(rw http.ResponseWriter, r *http.Request) {
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.ImageRollback(ctx)
 	if err != nil {
 		reply.WriteHTTPError(rw, err)
 		return
@@ -471,6 +578,43 @@ func (w *HTTPWrapper) ImageHistory(rw http.ResponseWriter, r *http.Request) {
 	w.WriteJSON(rw, reply.OK(resp))
 }
 
+// ImageDiff возвращает разницу пакетов между двумя записями истории образа.
+func (w *HTTPWrapper) ImageDiff(rw http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	fromID, errFrom := strconv.ParseUint(query.Get("from"), 10, 32)
+	toID, errTo := strconv.ParseUint(query.Get("to"), 10, 32)
+	if errFrom != nil || errTo != nil {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(r.Context(), "Query parameters from and to must be image history record IDs"))))
+		return
+	}
+
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.ImageDiff(ctx, uint(fromID), uint(toID))
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// ImageLogs возвращает журнал сборки образа.
+func (w *HTTPWrapper) ImageLogs(rw http.ResponseWriter, r *http.Request) {
+	generation := 0
+	if g := r.URL.Query().Get("generation"); g != "" {
+		if v, err := strconv.Atoi(g); err == nil {
+			generation = v
+		}
+	}
+
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.ImageLogs(ctx, generation)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
 // ImageGetConfig возвращает конфигурацию образа.
 func (w *HTTPWrapper) ImageGetConfig(rw http.ResponseWriter, r *http.Request) {
 	ctx := w.CtxWithTransaction(r)
@@ -628,6 +772,263 @@ func (w *HTTPWrapper) Sections(rw http.ResponseWriter, r *http.Request) {
 	w.WriteJSON(rw, reply.OK(resp))
 }
 
+// Files возвращает список файлов пакета.
+func (w *HTTPWrapper) Files(rw http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.Files(ctx, name, r.URL.Query().Get("type"))
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// Provides ищет пакеты, которым принадлежит файл или которые предоставляют библиотеку.
+func (w *HTTPWrapper) Provides(rw http.ResponseWriter, r *http.Request) {
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.Provides(ctx, r.URL.Query().Get("target"))
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// Changelog возвращает записи changelog версии-кандидата новее установленной версии.
+func (w *HTTPWrapper) Changelog(rw http.ResponseWriter, r *http.Request) {
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.Changelog(ctx, r.PathValue("name"), r.URL.Query().Get("since"))
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// Depends возвращает дерево зависимостей пакета.
+func (w *HTTPWrapper) Depends(rw http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	query := r.URL.Query()
+
+	depth := 1
+	if v := query.Get("depth"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			depth = n
+		}
+	}
+
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.Depends(ctx, name, depth, query.Get("installed") == "true")
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// ReverseDepends возвращает пакеты, зависящие от пакета или capability.
+func (w *HTTPWrapper) ReverseDepends(rw http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	query := r.URL.Query()
+
+	depth := 1
+	if v := query.Get("depth"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			depth = n
+		}
+	}
+
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.ReverseDepends(ctx, name, depth, query.Get("installed") == "true")
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// Recent возвращает недавно установленные и удалённые пакеты.
+func (w *HTTPWrapper) Recent(rw http.ResponseWriter, r *http.Request) {
+	ctx := w.CtxWithTransaction(r)
+	days := 7
+	if v := r.URL.Query().Get("days"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			days = n
+		}
+	}
+
+	resp, err := w.actions.Recent(ctx, days)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// Orphans возвращает установленные пакеты, которых больше нет в подключённых репозиториях.
+func (w *HTTPWrapper) Orphans(rw http.ResponseWriter, r *http.Request) {
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.Orphans(ctx, r.URL.Query()["keep"])
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// Clean удаляет скачанные пакеты.
+func (w *HTTPWrapper) Clean(rw http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	all := query.Get("all") == "true"
+
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.Clean(ctx, all || query.Get("cache") == "true", all || query.Get("downloads") == "true")
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// Hold удерживает пакеты от обновления.
+func (w *HTTPWrapper) Hold(rw http.ResponseWriter, r *http.Request) {
+	w.changeHold(rw, r, w.actions.Hold)
+}
+
+// Unhold снимает удержание с пакетов.
+func (w *HTTPWrapper) Unhold(rw http.ResponseWriter, r *http.Request) {
+	w.changeHold(rw, r, w.actions.Unhold)
+}
+
+// changeHold разбирает список пакетов из тела запроса и применяет к нему fn.
+func (w *HTTPWrapper) changeHold(rw http.ResponseWriter, r *http.Request, fn func(context.Context, []string) (*HoldResponse, error)) {
+	body, err := w.ParseBodyParams(r)
+	if err != nil {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
+		return
+	}
+
+	var packages []string
+	if err = reply.UnmarshalField(body, "packages", &packages); err != nil {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
+		return
+	}
+
+	ctx := w.CtxWithTransaction(r)
+	resp, err := fn(ctx, packages)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// HistoryList возвращает историю операций всех модулей.
+func (w *HTTPWrapper) HistoryList(rw http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit := 20
+	if l := query.Get("limit"); l != "" {
+		if v, err := strconv.Atoi(l); err == nil {
+			limit = v
+		}
+	}
+	offset := 0
+	if o := query.Get("offset"); o != "" {
+		if v, err := strconv.Atoi(o); err == nil {
+			offset = v
+		}
+	}
+
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.HistoryList(ctx, query.Get("module"), limit, offset)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// HistoryShow возвращает операцию из истории по идентификатору.
+func (w *HTTPWrapper) HistoryShow(rw http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation,
+			fmt.Errorf(app.TL_(r.Context(), "Invalid operation ID: %s"), r.PathValue("id"))))
+		return
+	}
+
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.HistoryShow(ctx, id)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// Rollback откатывает транзакцию из журнала по номеру операции или идентификатору транзакции.
+func (w *HTTPWrapper) Rollback(rw http.ResponseWriter, r *http.Request) {
+	ref := r.PathValue("id")
+	simulate := r.URL.Query().Get("simulate") == "true"
+
+	if w.RunBackground(rw, r, reply.EventSystemRollback, func(ctx context.Context) (interface{}, error) {
+		return w.actions.Rollback(ctx, ref, true, simulate)
+	}) {
+		return
+	}
+
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.Rollback(ctx, ref, true, simulate)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// Batch выполняет список операций одной транзакцией.
+func (w *HTTPWrapper) Batch(rw http.ResponseWriter, r *http.Request) {
+	body, err := w.ParseBodyParams(r)
+	if err != nil {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
+		return
+	}
+
+	var ops []BatchOperation
+	if err = reply.UnmarshalField(body, "operations", &ops); err != nil {
+		reply.WriteHTTPError(rw, apmerr.New(apmerr.ErrorTypeValidation, err))
+		return
+	}
+	simulate := r.URL.Query().Get("simulate") == "true"
+
+	if w.RunBackground(rw, r, reply.EventSystemBatch, func(ctx context.Context) (interface{}, error) {
+		return w.actions.Batch(ctx, ops, simulate)
+	}) {
+		return
+	}
+
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.Batch(ctx, ops, simulate)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// GetSystemOverview возвращает сводное состояние системы одним вызовом.
+func (w *HTTPWrapper) GetSystemOverview(rw http.ResponseWriter, r *http.Request) {
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.actions.GetSystemOverview(ctx)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
 // ApplicationCategories возвращает список уникальных категорий приложений.
 func (w *HTTPWrapper) ApplicationCategories(rw http.ResponseWriter, r *http.Request) {
 	ctx := w.CtxWithTransaction(r)
@@ -639,6 +1040,45 @@ func (w *HTTPWrapper) ApplicationCategories(rw http.ResponseWriter, r *http.Requ
 	w.WriteJSON(rw, reply.OK(resp))
 }
 
+// AppList возвращает каталог приложений с фильтрами по категории и ключевому слову.
+func (w *HTTPWrapper) AppList(rw http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	params := appstream.AppListParams{
+		Category: query.Get("category"),
+		Keyword:  query.Get("keyword"),
+		Limit:    50,
+	}
+	if v := query.Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			params.Limit = n
+		}
+	}
+	if v := query.Get("offset"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			params.Offset = n
+		}
+	}
+
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.appstreamActions.AppList(ctx, params)
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// AppInfo возвращает приложение каталога по идентификатору.
+func (w *HTTPWrapper) AppInfo(rw http.ResponseWriter, r *http.Request) {
+	ctx := w.CtxWithTransaction(r)
+	resp, err := w.appstreamActions.AppInfo(ctx, r.PathValue("id"))
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
 // GetEndpoints возвращает описания endpoints с handler
 func (w *HTTPWrapper) GetEndpoints(isAtomic bool) []http_server.Endpoint {
 	endpoints := []http_server.Endpoint{
@@ -659,6 +1099,18 @@ func (w *HTTPWrapper) GetEndpoints(isAtomic bool) []http_server.Endpoint {
 				{Name: "background", Type: "boolean", Required: false, Description: "Выполнить в фоне (результат придёт через WebSocket)"},
 			},
 		},
+		{
+			Handler:      w.CheckAutoRemove,
+			HTTPMethod:   "GET",
+			HTTPPath:     "/api/v1/packages/check-autoremove",
+			ResponseType: reflect.TypeOf(CheckResponse{}),
+			Permission:   http_server.PermRead,
+			Summary:      "Проверить неиспользуемые пакеты перед удалением",
+			Tags:         []string{"packages"},
+			QueryParams: []http_server.QueryParam{
+				{Name: "background", Type: "boolean", Required: false, Description: "Выполнить в фоне (результат придёт через WebSocket)"},
+			},
+		},
 		{
 			Handler:      w.CheckInstall,
 			HTTPMethod:   "POST",
@@ -674,6 +1126,21 @@ func (w *HTTPWrapper) GetEndpoints(isAtomic bool) []http_server.Endpoint {
 				{Name: "background", Type: "boolean", Required: false, Description: "Выполнить в фоне (результат придёт через WebSocket)"},
 			},
 		},
+		{
+			Handler:      w.CheckReinstall,
+			HTTPMethod:   "POST",
+			HTTPPath:     "/api/v1/packages/check-reinstall",
+			ResponseType: reflect.TypeOf(CheckResponse{}),
+			Permission:   http_server.PermRead,
+			Summary:      "Проверить пакеты перед переустановкой",
+			Tags:         []string{"packages"},
+			ParamMappings: []http_server.ParamMapping{
+				{Name: "packages", Source: "body", Type: "[]string", ArgIndex: 1},
+			},
+			QueryParams: []http_server.QueryParam{
+				{Name: "background", Type: "boolean", Required: false, Description: "Выполнить в фоне (результат придёт через WebSocket)"},
+			},
+		},
 		{
 			Handler:      w.CheckUpgrade,
 			HTTPMethod:   "GET",
@@ -705,6 +1172,18 @@ func (w *HTTPWrapper) GetEndpoints(isAtomic bool) []http_server.Endpoint {
 				{Name: "background", Type: "boolean", Required: false, Description: "Выполнить в фоне (результат придёт через WebSocket)"},
 			},
 		},
+		{
+			Handler:      w.AutoRemove,
+			HTTPMethod:   "POST",
+			HTTPPath:     "/api/v1/packages/autoremove",
+			ResponseType: reflect.TypeOf(InstallRemoveResponse{}),
+			Permission:   http_server.PermManage,
+			Summary:      "Удалить неиспользуемые пакеты",
+			Tags:         []string{"packages"},
+			QueryParams: []http_server.QueryParam{
+				{Name: "background", Type: "boolean", Required: false, Description: "Выполнить в фоне (результат придёт через WebSocket)"},
+			},
+		},
 		{
 			Handler:      w.Install,
 			HTTPMethod:   "POST",
@@ -721,6 +1200,21 @@ func (w *HTTPWrapper) GetEndpoints(isAtomic bool) []http_server.Endpoint {
 				{Name: "download_only", Type: "boolean", Required: false, Description: "Только скачать пакеты без установки"},
 			},
 		},
+		{
+			Handler:      w.Reinstall,
+			HTTPMethod:   "POST",
+			HTTPPath:     "/api/v1/packages/reinstall",
+			ResponseType: reflect.TypeOf(InstallRemoveResponse{}),
+			Permission:   http_server.PermManage,
+			Summary:      "Переустановить пакеты",
+			Tags:         []string{"packages"},
+			ParamMappings: []http_server.ParamMapping{
+				{Name: "packages", Source: "body", Type: "[]string", ArgIndex: 1},
+			},
+			QueryParams: []http_server.QueryParam{
+				{Name: "background", Type: "boolean", Required: false, Description: "Выполнить в фоне (результат придёт через WebSocket)"},
+			},
+		},
 
 		// Packages - информация
 		{
@@ -736,6 +1230,72 @@ func (w *HTTPWrapper) GetEndpoints(isAtomic bool) []http_server.Endpoint {
 				{Name: "full", Type: "boolean", Required: false, Description: "Полный формат вывода"},
 			},
 		},
+		{
+			Handler:      w.Files,
+			HTTPMethod:   "GET",
+			HTTPPath:     "/api/v1/packages/{name}/files",
+			ResponseType: reflect.TypeOf(FilesResponse{}),
+			Permission:   http_server.PermRead,
+			Summary:      "Получить список файлов пакета",
+			Tags:         []string{"packages"},
+			PathParams:   []string{"name"},
+			QueryParams: []http_server.QueryParam{
+				{Name: "type", Type: "string", Required: false, Description: "Тип файлов: bin, config или doc"},
+			},
+		},
+		{
+			Handler:      w.Provides,
+			HTTPMethod:   "GET",
+			HTTPPath:     "/api/v1/packages/provides",
+			ResponseType: reflect.TypeOf(ProvidesResponse{}),
+			Permission:   http_server.PermRead,
+			Summary:      "Найти пакеты, которым принадлежит файл или которые предоставляют библиотеку",
+			Tags:         []string{"packages"},
+			QueryParams: []http_server.QueryParam{
+				{Name: "target", Type: "string", Required: true, Description: "Абсолютный путь к файлу или имя библиотеки"},
+			},
+		},
+		{
+			Handler:      w.Changelog,
+			HTTPMethod:   "GET",
+			HTTPPath:     "/api/v1/packages/{name}/changelog",
+			ResponseType: reflect.TypeOf(ChangelogResponse{}),
+			Permission:   http_server.PermRead,
+			Summary:      "Получить изменения версии-кандидата относительно установленной версии",
+			Tags:         []string{"packages"},
+			PathParams:   []string{"name"},
+			QueryParams: []http_server.QueryParam{
+				{Name: "since", Type: "string", Required: false, Description: "Показать записи новее указанной версии вместо установленной"},
+			},
+		},
+		{
+			Handler:      w.Depends,
+			HTTPMethod:   "GET",
+			HTTPPath:     "/api/v1/packages/{name}/depends",
+			ResponseType: reflect.TypeOf(DependsResponse{}),
+			Permission:   http_server.PermRead,
+			Summary:      "Получить дерево зависимостей пакета",
+			Tags:         []string{"packages"},
+			PathParams:   []string{"name"},
+			QueryParams: []http_server.QueryParam{
+				{Name: "depth", Type: "integer", Required: false, Description: "Глубина обхода зависимостей (по умолчанию 1)"},
+				{Name: "installed", Type: "boolean", Required: false, Description: "Разрешать зависимости только в установленные пакеты"},
+			},
+		},
+		{
+			Handler:      w.ReverseDepends,
+			HTTPMethod:   "GET",
+			HTTPPath:     "/api/v1/packages/{name}/rdepends",
+			ResponseType: reflect.TypeOf(ReverseDependsResponse{}),
+			Permission:   http_server.PermRead,
+			Summary:      "Получить пакеты, зависящие от пакета или capability",
+			Tags:         []string{"packages"},
+			PathParams:   []string{"name"},
+			QueryParams: []http_server.QueryParam{
+				{Name: "depth", Type: "integer", Required: false, Description: "Глубина обхода обратных зависимостей (по умолчанию 1)"},
+				{Name: "installed", Type: "boolean", Required: false, Description: "Только установленные пакеты"},
+			},
+		},
 		{
 			Handler:      w.MultiInfo,
 			HTTPMethod:   "POST",
@@ -794,6 +1354,118 @@ func (w *HTTPWrapper) GetEndpoints(isAtomic bool) []http_server.Endpoint {
 			Summary:      "Получить список секций пакетов",
 			Tags:         []string{"packages"},
 		},
+		{
+			Handler:      w.Recent,
+			HTTPMethod:   "GET",
+			HTTPPath:     "/api/v1/packages/recent",
+			ResponseType: reflect.TypeOf(RecentResponse{}),
+			Permission:   http_server.PermRead,
+			Summary:      "Получить недавно установленные и удалённые пакеты",
+			Tags:         []string{"packages"},
+			QueryParams: []http_server.QueryParam{
+				{Name: "days", Type: "integer", Required: false, Description: "Количество дней (по умолчанию 7)"},
+			},
+		},
+		{
+			Handler:      w.HistoryList,
+			HTTPMethod:   "GET",
+			HTTPPath:     "/api/v1/history",
+			ResponseType: reflect.TypeOf(HistoryListResponse{}),
+			Permission:   http_server.PermRead,
+			Summary:      "Получить историю операций всех модулей",
+			Tags:         []string{"system"},
+			QueryParams: []http_server.QueryParam{
+				{Name: "module", Type: "string", Required: false, Description: "Модуль: system, kernel, repository, image"},
+				{Name: "limit", Type: "integer", Required: false, Description: "Лимит записей (по умолчанию 20)"},
+				{Name: "offset", Type: "integer", Required: false, Description: "Смещение"},
+			},
+		},
+		{
+			Handler:      w.HistoryShow,
+			HTTPMethod:   "GET",
+			HTTPPath:     "/api/v1/history/{id}",
+			ResponseType: reflect.TypeOf(HistoryShowResponse{}),
+			Permission:   http_server.PermRead,
+			Summary:      "Получить операцию из истории",
+			Tags:         []string{"system"},
+		},
+		{
+			Handler:      w.Rollback,
+			HTTPMethod:   "POST",
+			HTTPPath:     "/api/v1/history/{id}/rollback",
+			ResponseType: reflect.TypeOf(RollbackResponse{}),
+			Permission:   http_server.PermManage,
+			Summary:      "Откатить транзакцию по номеру операции или идентификатору транзакции",
+			Tags:         []string{"system"},
+			QueryParams: []http_server.QueryParam{
+				{Name: "simulate", Type: "boolean", Required: false, Description: "Только проверить изменения без выполнения"},
+				{Name: "background", Type: "boolean", Required: false, Description: "Выполнить в фоне (результат придёт через WebSocket)"},
+			},
+		},
+		{
+			Handler:      w.Batch,
+			HTTPMethod:   "POST",
+			HTTPPath:     "/api/v1/batch",
+			RequestType:  reflect.TypeOf(BatchBody{}),
+			ResponseType: reflect.TypeOf(BatchResponse{}),
+			Permission:   http_server.PermManage,
+			Summary:      "Выполнить список операций (update, install, remove, image-apply) одной транзакцией",
+			Tags:         []string{"system"},
+			QueryParams: []http_server.QueryParam{
+				{Name: "simulate", Type: "boolean", Required: false, Description: "Только проверить изменения пакетов без выполнения"},
+				{Name: "background", Type: "boolean", Required: false, Description: "Выполнить в фоне (результат придёт через WebSocket)"},
+			},
+		},
+		{
+			Handler:      w.Clean,
+			HTTPMethod:   "POST",
+			HTTPPath:     "/api/v1/packages/clean",
+			ResponseType: reflect.TypeOf(CleanResponse{}),
+			Permission:   http_server.PermManage,
+			Summary:      "Удалить скачанные пакеты и показать освобождённое место",
+			Tags:         []string{"packages"},
+			QueryParams: []http_server.QueryParam{
+				{Name: "cache", Type: "boolean", Required: false, Description: "Удалить пакеты, скачанные APT, и осиротевшие записи базы (по умолчанию)"},
+				{Name: "downloads", Type: "boolean", Required: false, Description: "Удалить неиспользуемые локальные пакеты из ресурсов образа"},
+				{Name: "all", Type: "boolean", Required: false, Description: "Очистить всё"},
+			},
+		},
+		{
+			Handler:      w.Hold,
+			HTTPMethod:   "POST",
+			HTTPPath:     "/api/v1/packages/hold",
+			ResponseType: reflect.TypeOf(HoldResponse{}),
+			Permission:   http_server.PermManage,
+			Summary:      "Удержать пакеты от обновления",
+			Tags:         []string{"packages"},
+			ParamMappings: []http_server.ParamMapping{
+				{Name: "packages", Source: "body", Type: "[]string", ArgIndex: 1},
+			},
+		},
+		{
+			Handler:      w.Unhold,
+			HTTPMethod:   "POST",
+			HTTPPath:     "/api/v1/packages/unhold",
+			ResponseType: reflect.TypeOf(HoldResponse{}),
+			Permission:   http_server.PermManage,
+			Summary:      "Снять удержание с пакетов",
+			Tags:         []string{"packages"},
+			ParamMappings: []http_server.ParamMapping{
+				{Name: "packages", Source: "body", Type: "[]string", ArgIndex: 1},
+			},
+		},
+		{
+			Handler:      w.Orphans,
+			HTTPMethod:   "GET",
+			HTTPPath:     "/api/v1/packages/orphans",
+			ResponseType: reflect.TypeOf(OrphansResponse{}),
+			Permission:   http_server.PermRead,
+			Summary:      "Получить пакеты, отсутствующие в подключённых репозиториях",
+			Tags:         []string{"packages"},
+			QueryParams: []http_server.QueryParam{
+				{Name: "keep", Type: "string", Required: false, Description: "Пакет, исключаемый из отчёта (можно указать несколько раз)"},
+			},
+		},
 		{
 			Handler:      w.Search,
 			HTTPMethod:   "GET",
@@ -805,6 +1477,7 @@ func (w *HTTPWrapper) GetEndpoints(isAtomic bool) []http_server.Endpoint {
 			QueryParams: []http_server.QueryParam{
 				{Name: "q", Type: "string", Required: true, Description: "Поисковый запрос"},
 				{Name: "installed", Type: "boolean", Required: false, Description: "Искать только установленные"},
+				{Name: "description", Type: "boolean", Required: false, Description: "Искать также по описанию и ключевым словам AppStream с сортировкой по релевантности"},
 				{Name: "full", Type: "boolean", Required: false, Description: "Полный формат вывода"},
 			},
 		},
@@ -831,6 +1504,15 @@ func (w *HTTPWrapper) GetEndpoints(isAtomic bool) []http_server.Endpoint {
 		},
 
 		// System
+		{
+			Handler:      w.GetSystemOverview,
+			HTTPMethod:   "GET",
+			HTTPPath:     "/api/v1/system/overview",
+			ResponseType: reflect.TypeOf(SystemOverviewResponse{}),
+			Permission:   http_server.PermRead,
+			Summary:      "Получить сводное состояние системы",
+			Tags:         []string{"system"},
+		},
 		{
 			Handler:      w.Update,
 			HTTPMethod:   "POST",
@@ -922,6 +1604,32 @@ func (w *HTTPWrapper) GetEndpoints(isAtomic bool) []http_server.Endpoint {
 			Summary:      "Получить список категорий приложений",
 			Tags:         []string{"applications"},
 		},
+		http_server.Endpoint{
+			Handler:      w.AppList,
+			HTTPMethod:   "GET",
+			HTTPPath:     "/api/v1/apps",
+			ResponseType: reflect.TypeOf(appstream.AppListResponse{}),
+			Permission:   http_server.PermRead,
+			Summary:      "Получить каталог приложений",
+			Description:  "Каждое приложение отдельной записью с иконками, скриншотами, категориями и пакетом, которому оно принадлежит",
+			Tags:         []string{"applications"},
+			QueryParams: []http_server.QueryParam{
+				{Name: "category", Type: "string", Required: false, Description: "Категория, например Game"},
+				{Name: "keyword", Type: "string", Required: false, Description: "Ключевое слово"},
+				{Name: "limit", Type: "integer", Required: false, Description: "Лимит записей (по умолчанию 50)"},
+				{Name: "offset", Type: "integer", Required: false, Description: "Смещение"},
+			},
+		},
+		http_server.Endpoint{
+			Handler:      w.AppInfo,
+			HTTPMethod:   "GET",
+			HTTPPath:     "/api/v1/apps/{id}",
+			ResponseType: reflect.TypeOf(appstream.AppInfoResponse{}),
+			Permission:   http_server.PermRead,
+			Summary:      "Получить приложение каталога по идентификатору",
+			Tags:         []string{"applications"},
+			PathParams:   []string{"id"},
+		},
 	)
 
 	// Image (только для atomic)
@@ -963,8 +1671,18 @@ func (w *HTTPWrapper) GetEndpoints(isAtomic bool) []http_server.Endpoint {
 					{Name: "no_cache", Type: "boolean", Required: false, Description: "Отключить кэш APT-пакетов при сборке образа"},
 					{Name: "config", Type: "string", Required: false, Description: "Путь к файлу конфигурации образа"},
 					{Name: "workdir", Type: "string", Required: false, Description: "Рабочая директория сборки"},
+					{Name: "tag", Type: "string", Required: false, Description: "Тег варианта образа, собранного через image build --tag"},
 				},
 			},
+			http_server.Endpoint{
+				Handler:      w.ImageRollback,
+				HTTPMethod:   "POST",
+				HTTPPath:     "/api/v1/image/rollback",
+				ResponseType: reflect.TypeOf(ImageStatusResponse{}),
+				Permission:   http_server.PermManage,
+				Summary:      "Вернуться к предыдущему развёртыванию образа",
+				Tags:         []string{"image"},
+			},
 			http_server.Endpoint{
 				Handler:      w.ImageHistory,
 				HTTPMethod:   "GET",
@@ -979,6 +1697,31 @@ func (w *HTTPWrapper) GetEndpoints(isAtomic bool) []http_server.Endpoint {
 					{Name: "offset", Type: "integer", Required: false, Description: "Смещение"},
 				},
 			},
+			http_server.Endpoint{
+				Handler:      w.ImageDiff,
+				HTTPMethod:   "GET",
+				HTTPPath:     "/api/v1/image/diff",
+				ResponseType: reflect.TypeOf(ImageDiffResponse{}),
+				Permission:   http_server.PermRead,
+				Summary:      "Получить разницу пакетов между двумя сборками образа",
+				Tags:         []string{"image"},
+				QueryParams: []http_server.QueryParam{
+					{Name: "from", Type: "integer", Required: true, Description: "Идентификатор исходной записи истории"},
+					{Name: "to", Type: "integer", Required: true, Description: "Идентификатор конечной записи истории"},
+				},
+			},
+			http_server.Endpoint{
+				Handler:      w.ImageLogs,
+				HTTPMethod:   "GET",
+				HTTPPath:     "/api/v1/image/logs",
+				ResponseType: reflect.TypeOf(ImageLogsResponse{}),
+				Permission:   http_server.PermRead,
+				Summary:      "Получить журнал сборки образа",
+				Tags:         []string{"image"},
+				QueryParams: []http_server.QueryParam{
+					{Name: "generation", Type: "integer", Required: false, Description: "Номер поколения сборки, по умолчанию последняя"},
+				},
+			},
 			http_server.Endpoint{
 				Handler:      w.ImageGetConfig,
 				HTTPMethod:   "GET",
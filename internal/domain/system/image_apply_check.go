@@ -0,0 +1,92 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/domain/system/schedule"
+	"context"
+	"errors"
+)
+
+// CheckImageApply показывает, какой образ будет собран или включён командой image apply, не собирая
+// и не переключая его. Непустой at добавляет в ответ расписание отложенного применения.
+func (a *Actions) CheckImageApply(ctx context.Context, at string, pullImage bool, configPath, workdir, tag string) (*ImageApplySimulateResponse, error) {
+	var planned *schedule.Schedule
+	if at != "" {
+		if tag != "" {
+			return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "The --at flag cannot be combined with --tag")))
+		}
+		mode, clock, err := schedule.ParseAt(at)
+		if err != nil {
+			return nil, apmerr.New(apmerr.ErrorTypeValidation, err)
+		}
+		planned = &schedule.Schedule{Mode: mode, At: clock, Pull: pullImage}
+	}
+
+	if tag != "" {
+		if _, err := a.serviceHostImage.TaggedImageID(ctx, tag); err != nil {
+			return nil, apmerr.New(apmerr.ErrorTypeNotFound, err)
+		}
+		return &ImageApplySimulateResponse{
+			Message: app.TL_(ctx, "Simulation results"),
+			Image:   tag,
+		}, nil
+	}
+
+	if err := a.serviceHostConfig.ApplyPathOverrides(configPath, workdir); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+	if err := a.serviceHostConfig.LoadConfig(); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+	config := a.serviceHostConfig.GetConfig()
+	if err := config.CheckImage(); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+	if err := a.serviceTemporaryConfig.LoadConfig(); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+	pending := a.serviceTemporaryConfig.GetConfig().Packages
+
+	return &ImageApplySimulateResponse{
+		Message:  app.TL_(ctx, "Simulation results"),
+		Image:    config.Image,
+		Build:    len(config.Modules) > 0,
+		Pull:     pullImage,
+		Install:  pending.Install,
+		Remove:   pending.Remove,
+		Schedule: planned,
+	}, nil
+}
+
+// CheckImageApplyCancelScheduled показывает отложенное применение, которое будет отменено.
+func (a *Actions) CheckImageApplyCancelScheduled(ctx context.Context) (*ImageApplyScheduleResponse, error) {
+	scheduled, err := a.serviceSchedule.Load()
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+	if scheduled == nil {
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.TL_(ctx, "No image apply is scheduled")))
+	}
+
+	return &ImageApplyScheduleResponse{
+		Message:  app.TL_(ctx, "Simulation results"),
+		Schedule: scheduled,
+	}, nil
+}
@@ -0,0 +1,95 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/build"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ImageDiff сравнивает снимки пакетов двух записей истории образа
+func (a *Actions) ImageDiff(ctx context.Context, fromID uint, toID uint) (*ImageDiffResponse, error) {
+	if fromID == 0 || toID == 0 {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Two image history record IDs must be specified, for example image diff 3 5")))
+	}
+	if fromID == toID {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Specify two different image history records")))
+	}
+
+	from, err := a.imageHistorySnapshot(ctx, fromID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := a.imageHistorySnapshot(ctx, toID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ImageDiffResponse{From: from, To: to}
+	resp.Added, resp.Removed, resp.Upgraded = diffPackages(from.Packages, to.Packages)
+
+	if len(resp.Added)+len(resp.Removed)+len(resp.Upgraded) == 0 {
+		resp.Message = fmt.Sprintf(app.TL_(ctx, "Images %d and %d contain the same packages"), fromID, toID)
+	} else {
+		resp.Message = fmt.Sprintf(app.TL_(ctx, "Added: %d, removed: %d, changed: %d"), len(resp.Added), len(resp.Removed), len(resp.Upgraded))
+	}
+
+	return resp, nil
+}
+
+// imageHistorySnapshot возвращает запись истории, у которой сохранён снимок пакетов
+func (a *Actions) imageHistorySnapshot(ctx context.Context, id uint) (build.ImageHistory, error) {
+	history, err := a.serviceHostDatabase.GetImageHistoryByID(ctx, id)
+	if err != nil {
+		return build.ImageHistory{}, apmerr.New(apmerr.ErrorTypeNotFound, err)
+	}
+	if history.Packages == nil {
+		return build.ImageHistory{}, apmerr.New(apmerr.ErrorTypeNotFound,
+			fmt.Errorf(app.TL_(ctx, "Image history record %d has no package snapshot, it was built by an older version of apm"), id))
+	}
+	return history, nil
+}
+
+// diffPackages возвращает добавленные, удалённые пакеты и пакеты с изменённой версией, отсортированные по имени
+func diffPackages(from map[string]string, to map[string]string) (added, removed, changed []ImagePackageChange) {
+	for name, version := range to {
+		previous, ok := from[name]
+		switch {
+		case !ok:
+			added = append(added, ImagePackageChange{Name: name, To: version})
+		case previous != version:
+			changed = append(changed, ImagePackageChange{Name: name, From: previous, To: version})
+		}
+	}
+	for name, version := range from {
+		if _, ok := to[name]; !ok {
+			removed = append(removed, ImagePackageChange{Name: name, From: version})
+		}
+	}
+
+	byName := func(a, b ImagePackageChange) int { return strings.Compare(a.Name, b.Name) }
+	slices.SortFunc(added, byName)
+	slices.SortFunc(removed, byName)
+	slices.SortFunc(changed, byName)
+	return added, removed, changed
+}
@@ -0,0 +1,105 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/build"
+	"apm/internal/common/journal"
+	"context"
+	"errors"
+)
+
+// ImageRollback возвращает загрузку к предыдущему развёртыванию и восстанавливает соответствующую ему
+// конфигурацию образа из истории
+func (a *Actions) ImageRollback(ctx context.Context) (*ImageStatusResponse, error) {
+	if !a.appConfig.ConfigManager.GetConfig().IsAtomic {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, errors.New(app.TL_(ctx, "This option is only available for an atomic system")))
+	}
+
+	hostImage, err := a.serviceHostImage.GetHostImage()
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+	if hostImage.Status.Rollback == nil {
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, errors.New(app.TL_(ctx, "There is no previous deployment to roll back to")))
+	}
+	if hostImage.Status.RollbackQueued {
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.TL_(ctx, "Rollback is already queued, reboot to apply it")))
+	}
+
+	ctx, release, err := a.operationLock.Acquire(ctx, journal.ActionRestore)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	previous, err := a.previousImageHistory(ctx, hostImage.Status.Staged != nil)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+
+	err = a.serviceHostImage.RollbackImage(ctx)
+	a.recordOperation(ctx, journal.Entry{
+		Module:  journal.ModuleImage,
+		Action:  journal.ActionRestore,
+		Targets: []string{hostImage.Status.Rollback.Image.Image.Image},
+	}, err)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+
+	message := app.TL_(ctx, "Rolled back to the previous deployment. A reboot is required")
+	if previous != nil && previous.Config != nil {
+		a.serviceHostConfig.SetConfig(previous.Config)
+		if err = a.serviceHostConfig.SaveConfig(); err != nil {
+			return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+		}
+	} else {
+		message += ". " + app.TL_(ctx, "Image history has no previous configuration, the configuration file was left unchanged")
+	}
+
+	imageStatus, err := a.getImageStatus(ctx)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+
+	return &ImageStatusResponse{
+		Message:     message,
+		BootedImage: imageStatus,
+		Pending:     pendingDeployment(ctx, imageStatus.Image),
+	}, nil
+}
+
+// previousImageHistory возвращает запись истории предыдущего развёртывания. Последняя запись
+// соответствует загруженному образу, а при подготовленном обновлении - подготовленному
+func (a *Actions) previousImageHistory(ctx context.Context, staged bool) (*build.ImageHistory, error) {
+	skip := 1
+	if staged {
+		skip = 2
+	}
+
+	history, err := a.serviceHostDatabase.GetImageHistoriesFiltered(ctx, "", 1, skip)
+	if err != nil {
+		return nil, err
+	}
+	if len(history) == 0 {
+		return nil, nil
+	}
+	return &history[0], nil
+}
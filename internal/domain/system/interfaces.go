@@ -18,12 +18,29 @@ package system
 
 import (
 	_package "apm/internal/common/apt/package"
+	aptBinding "apm/internal/common/binding/apt"
 	aptLib "apm/internal/common/binding/apt/lib"
 	"apm/internal/common/build"
 	"apm/internal/common/filter"
+	"apm/internal/common/hooks"
+	"apm/internal/common/journal"
+	"apm/internal/common/oplog"
+	"apm/internal/common/sandbox"
 	"apm/internal/common/swcat"
+	kservice "apm/internal/domain/kernel/service"
+	reposervice "apm/internal/domain/repository/service"
+	"apm/internal/domain/system/autoupgrade"
+	"apm/internal/domain/system/group"
+	"apm/internal/domain/system/localrpm"
+	"apm/internal/domain/system/restart"
+	"apm/internal/domain/system/rpmdup"
+	"apm/internal/domain/system/rpmnew"
+	"apm/internal/domain/system/schedule"
+	"apm/internal/domain/system/stplr"
 	"apm/internal/domain/system/temporary"
+	"apm/internal/domain/system/units"
 	"context"
+	"time"
 )
 
 // aptActionsService определяет методы для APT операций с пакетами.
@@ -32,6 +49,7 @@ type aptActionsService interface {
 	GetAptConfigOverrides() map[string]string
 	CheckRemove(ctx context.Context, packages []string, purge bool, depends bool) (*aptLib.PackageChanges, error)
 	CheckUpgrade(ctx context.Context) (*aptLib.PackageChanges, error)
+	CheckAutoRemove(ctx context.Context) (*aptLib.PackageChanges, error)
 	PrepareInstallPackages(ctx context.Context, packages []string) ([]string, []string, error)
 	FindPackage(ctx context.Context, installed []string, removed []string, purge bool, depends bool, reinstall bool) ([]string, []string, []_package.Package, *aptLib.PackageChanges, error)
 	Remove(ctx context.Context, packages []string, purge bool, depends bool) error
@@ -40,9 +58,17 @@ type aptActionsService interface {
 	UpdateDBOnly(ctx context.Context, noLock ...bool) ([]_package.Package, error)
 	AptUpdate(ctx context.Context, noLock ...bool) error
 	GetInstalledPackages(ctx context.Context, noLock ...bool) (map[string]string, error)
+	GetInstalledEVRs(ctx context.Context, noLock ...bool) (map[string]string, error)
 	Upgrade(ctx context.Context, downloadOnly bool) error
+	CheckFixBroken(ctx context.Context) (*aptLib.PackageChanges, error)
+	FixBroken(ctx context.Context) error
 	ReinstallPackages(ctx context.Context, packages []string) error
 	Install(ctx context.Context, packages []string, downloadOnly bool) error
+	GetInstalledFiles(ctx context.Context, packageName string) ([]aptBinding.RpmFileInfo, bool, error)
+	SyncHoldPreferences(ctx context.Context) error
+	GetFileOwners(ctx context.Context, target string) ([]aptBinding.InstalledPackageInfo, error)
+	GetInfo(ctx context.Context, packageName string) (*aptLib.PackageInfo, error)
+	ArchivesDir() string
 }
 
 // aptDatabaseService определяет методы для запросов к базе данных пакетов.
@@ -53,15 +79,25 @@ type aptDatabaseService interface {
 	QueryHostImagePackages(ctx context.Context, filters []filter.Filter, sortField, sortOrder string, limit, offset int) ([]_package.Package, error)
 	CountHostImagePackages(ctx context.Context, filters []filter.Filter) (int64, error)
 	SearchPackagesByNameLike(ctx context.Context, likePattern string, installed bool) ([]_package.Package, error)
+	SearchPackagesFullText(ctx context.Context, text string, installed bool) ([]_package.Package, error)
+	GetReverseDependencies(ctx context.Context, capabilities []string, installed bool) ([]_package.Package, error)
+	GetProviders(ctx context.Context, capabilities []string) ([]_package.Package, error)
 	SearchPackagesMultiLimit(ctx context.Context, likePattern string, limit int, installed bool) ([]_package.Package, error)
 	SyncPackageInstallationInfo(ctx context.Context, installedPackages map[string]string) error
+	SaveStplrPackages(ctx context.Context, packages []_package.Package) error
 	UpdateAppStreamLinks(ctx context.Context) error
 	GetSections(ctx context.Context) ([]string, error)
+	HoldPackages(ctx context.Context, names []string) error
+	UnholdPackages(ctx context.Context, names []string) error
+	GetHeldPackages(ctx context.Context) ([]string, error)
+	FindPackagesProviding(ctx context.Context, target string) ([]_package.Package, error)
+	DeleteOrphanedHeld(ctx context.Context, installed map[string]string) (int64, error)
 }
 
 // hostDatabaseService определяет методы для работы с базой данных образов.
 type hostDatabaseService interface {
 	GetImageHistoriesFiltered(ctx context.Context, imageNameFilter string, limit, offset int) ([]build.ImageHistory, error)
+	GetImageHistoryByID(ctx context.Context, id uint) (build.ImageHistory, error)
 	CountImageHistoriesFiltered(ctx context.Context, imageNameFilter string) (int, error)
 }
 
@@ -71,7 +107,12 @@ type hostImageService interface {
 	GetHostImage() (build.HostImage, error)
 	CheckAndUpdateBaseImage(ctx context.Context, pullImage bool, hostCache bool, config build.Config) error
 	SwitchImage(ctx context.Context, podmanImageID string, isLocal bool) error
+	RollbackImage(ctx context.Context) error
 	BuildAndSwitch(ctx context.Context, pullImage bool, checkSame bool, hostConfigService build.SwitchableConfig) error
+	BuildTaggedImage(ctx context.Context, config build.Config, tag string, pullImage bool, hostCache bool) (string, error)
+	TaggedImageID(ctx context.Context, tag string) (string, error)
+	BuildLogs() ([]build.BuildLog, error)
+	ReadBuildLog(path string) (string, error)
 }
 
 // hostConfigService определяет методы для работы с конфигурацией хоста.
@@ -103,3 +144,120 @@ type temporaryConfigService interface {
 type appStreamService interface {
 	GetByPkgNames(ctx context.Context, names []string) (map[string][]swcat.Component, error)
 }
+
+// journalService определяет методы для работы с журналом транзакций.
+type journalService interface {
+	Record(ctx context.Context, entry journal.Entry) error
+	RecordResult(ctx context.Context, entry journal.Entry, opErr error) error
+	Since(ctx context.Context, since time.Time) ([]journal.Entry, error)
+	Begin(ctx context.Context, entry journal.Entry) (uint, error)
+	Complete(ctx context.Context, id uint, entry journal.Entry) error
+	Fail(ctx context.Context, id uint) error
+	Interrupted(ctx context.Context) ([]journal.Entry, error)
+	MarkRepaired(ctx context.Context) error
+	Last(ctx context.Context, action string) (*journal.Entry, error)
+	History(ctx context.Context, filter journal.HistoryFilter) ([]journal.Entry, int, error)
+	Get(ctx context.Context, id uint) (*journal.Entry, error)
+	FindByTransaction(ctx context.Context, transaction string) (*journal.Entry, error)
+}
+
+// logReaderService определяет методы для чтения лога apm.
+type logReaderService interface {
+	Read(ctx context.Context, filter oplog.Filter) ([]oplog.Entry, error)
+	Follow(ctx context.Context, filter oplog.Filter, fn func(oplog.Entry)) error
+}
+
+// applyScheduleService определяет методы для отложенного применения образа.
+type applyScheduleService interface {
+	Create(ctx context.Context, s schedule.Schedule) error
+	Load() (*schedule.Schedule, error)
+	Cancel(ctx context.Context) (*schedule.Schedule, error)
+	Finish(ctx context.Context) error
+	IsIdle(ctx context.Context) bool
+	Notify(ctx context.Context, message string)
+}
+
+// autoUpgradeService определяет методы для таймера автоматического обновления.
+type autoUpgradeService interface {
+	Enable(ctx context.Context, onCalendar string) error
+	Disable(ctx context.Context) (bool, error)
+	Status(ctx context.Context) (autoupgrade.Status, error)
+	NotifyUsers(ctx context.Context, summary string, body string) int
+}
+
+// unitService определяет методы для юнитов systemd служб apm.
+type unitService interface {
+	Install(ctx context.Context, name string) (units.Status, error)
+	Enable(ctx context.Context, name string) (units.Status, error)
+	Disable(ctx context.Context, name string) (bool, error)
+	Status(ctx context.Context, name string) (units.Status, error)
+}
+
+// rpmnewService определяет методы для разбора .rpmnew файлов после обновления.
+type rpmnewService interface {
+	Snapshot() map[string]struct{}
+	Collect(before map[string]struct{}) []rpmnew.File
+	Diff(ctx context.Context, file rpmnew.File) (string, error)
+	Apply(ctx context.Context, file rpmnew.File, action string) error
+}
+
+// localRpmService определяет методы для работы с локальными RPM-файлами.
+type localRpmService interface {
+	Verify(ctx context.Context, files []string) error
+	Names(ctx context.Context, files []string) (map[string]string, error)
+	Store(ctx context.Context, file string) (string, error)
+	WriteManifest(ctx context.Context, dir string, requested []string) (localrpm.Manifest, error)
+	StorePath() string
+}
+
+// rpmDupService определяет методы для завершения прерванных обновлений rpm.
+type rpmDupService interface {
+	Find(ctx context.Context) ([]rpmdup.Duplicate, error)
+	Finish(ctx context.Context, d rpmdup.Duplicate) error
+}
+
+// restartService определяет методы для перезапуска служб с устаревшими библиотеками.
+type restartService interface {
+	Find(ctx context.Context) ([]restart.Service, error)
+	Restart(ctx context.Context, unit string) error
+	RestartSelf(ctx context.Context, unit string, argv []string) []string
+}
+
+// kernelInfoService определяет методы для получения сведений о текущем ядре.
+type kernelInfoService interface {
+	GetCurrentKernel(ctx context.Context) (*kservice.Info, error)
+	FindLatestKernel(ctx context.Context, flavour string) (*kservice.Info, error)
+}
+
+// repoListService определяет методы для получения списка репозиториев и создания временной конфигурации APT.
+type repoListService interface {
+	GetRepositories(ctx context.Context, all bool) ([]reposervice.Repository, error)
+	CreateSandbox(ctx context.Context, args []string) (*reposervice.Sandbox, error)
+}
+
+// containerListService определяет методы для получения списка контейнеров distrobox.
+type containerListService interface {
+	GetContainerList(ctx context.Context, getFullInfo bool) ([]sandbox.ContainerInfo, error)
+}
+
+// hooksService определяет методы для выполнения хуков транзакций.
+type hooksService interface {
+	List(ctx context.Context) ([]hooks.Hook, error)
+	Get(ctx context.Context, name string) (hooks.Hook, error)
+	Before(ctx context.Context, tx hooks.Transaction) error
+	After(ctx context.Context, tx hooks.Transaction, opErr error) error
+	Exec(ctx context.Context, hook hooks.Hook, tx hooks.Transaction) hooks.Result
+}
+
+// stplrService определяет методы синхронизации каталога STPLR и сборки его пакетов.
+type stplrService interface {
+	Refresh(ctx context.Context) error
+	List(ctx context.Context) ([]stplr.Package, error)
+	Build(ctx context.Context, name string) (string, []string, error)
+}
+
+// groupService определяет методы чтения групп пакетов.
+type groupService interface {
+	List() ([]group.Group, error)
+	Get(name string) (group.Group, error)
+}
@@ -0,0 +1,103 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/apt"
+	_package "apm/internal/common/apt/package"
+	"apm/internal/domain/system/localrpm"
+	"context"
+	"path/filepath"
+	"slices"
+)
+
+// SetAllowUnsigned разрешает установку локальных RPM-файлов без проверки подписи.
+func (a *Actions) SetAllowUnsigned(allow bool) {
+	a.allowUnsigned = allow
+}
+
+// prepareLocalPackages раскрывает каталоги и шаблоны путей в список RPM-файлов и проверяет
+// подписи найденных файлов, кроме доверенных trusted (собранных самим apm).
+// Возвращает раскрытый список пакетов и локальные файлы.
+func (a *Actions) prepareLocalPackages(ctx context.Context, packages []string, trusted []string) ([]string, []string, error) {
+	expanded, files, err := localrpm.Expand(ctx, packages)
+	if err != nil {
+		return nil, nil, apmerr.New(apmerr.ErrorTypeValidation, err)
+	}
+	if a.allowUnsigned {
+		return expanded, files, nil
+	}
+
+	unverified := files
+	if len(trusted) > 0 {
+		skip := make(map[string]bool, len(trusted))
+		for _, file := range trusted {
+			if abs, errAbs := filepath.Abs(file); errAbs == nil {
+				skip[abs] = true
+			}
+		}
+		unverified = slices.DeleteFunc(slices.Clone(files), func(file string) bool { return skip[file] })
+	}
+	if len(unverified) == 0 {
+		return expanded, files, nil
+	}
+
+	if err = a.serviceLocalRpm.Verify(ctx, unverified); err != nil {
+		return nil, nil, apmerr.New(apmerr.ErrorTypeValidation, err)
+	}
+	return expanded, files, nil
+}
+
+// markLocalFiles отмечает в сведениях о пакетах файлы, из которых они будут установлены,
+// чтобы диалог подтверждения показал источник пакета.
+func (a *Actions) markLocalFiles(ctx context.Context, files []string, packagesInfo []_package.Package) {
+	if len(files) == 0 {
+		return
+	}
+
+	names, err := a.serviceLocalRpm.Names(ctx, files)
+	if err != nil {
+		app.Log.Error(err.Error())
+		return
+	}
+	for i := range packagesInfo {
+		if file, ok := names[packagesInfo[i].Name]; ok {
+			packagesInfo[i].LocalFile = file
+		}
+	}
+}
+
+// storeLocalPackages заменяет локальные RPM-файлы их копиями в ресурсах образа:
+// исходный файл может быть удалён до следующей пересборки системы.
+func (a *Actions) storeLocalPackages(ctx context.Context, packages []string) ([]string, error) {
+	result := make([]string, 0, len(packages))
+	for _, pkg := range packages {
+		if !apt.IsRegularFileAndIsPackage(pkg) {
+			result = append(result, pkg)
+			continue
+		}
+
+		stored, err := a.serviceLocalRpm.Store(ctx, pkg)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, stored)
+	}
+	return result, nil
+}
@@ -0,0 +1,216 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package localrpm
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/apt"
+	"apm/internal/common/command"
+	"apm/internal/common/osutils"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// StoreDir каталог внутри ресурсов образа, куда копируются локальные пакеты атомарной системы
+const StoreDir = "rpms"
+
+// Manager проверяет подписи локальных RPM-файлов и сохраняет их копии в ресурсах образа,
+// чтобы пакеты оставались доступны при пересборке атомарной системы.
+type Manager struct {
+	runner       command.Runner
+	resourcesDir string
+}
+
+// NewManager создаёт менеджер локальных пакетов.
+func NewManager(runner command.Runner, resourcesDir string) *Manager {
+	return &Manager{runner: runner, resourcesDir: resourcesDir}
+}
+
+// Expand раскрывает каталоги и шаблоны путей в списке пакетов в абсолютные пути RPM-файлов.
+// Аргументы, не похожие на путь, остаются без изменений: шаблон lib* по-прежнему ищется в базе пакетов.
+// Вторым значением возвращаются все найденные локальные файлы.
+func Expand(ctx context.Context, packages []string) (expanded []string, files []string, err error) {
+	seen := make(map[string]bool)
+	add := func(path string) error {
+		abs, errAbs := filepath.Abs(path)
+		if errAbs != nil {
+			return errAbs
+		}
+		if !seen[abs] {
+			seen[abs] = true
+			expanded = append(expanded, abs)
+			files = append(files, abs)
+		}
+		return nil
+	}
+
+	for _, pkg := range packages {
+		pkg = strings.TrimSpace(pkg)
+		if !isPath(pkg) {
+			expanded = append(expanded, pkg)
+			continue
+		}
+
+		var matched []string
+		switch {
+		case isDir(pkg):
+			if matched, err = filepath.Glob(filepath.Join(pkg, "*.rpm")); err != nil {
+				return nil, nil, err
+			}
+			if matched = onlyPackages(matched); len(matched) == 0 {
+				return nil, nil, fmt.Errorf(app.TL_(ctx, "Directory %s contains no RPM packages"), pkg)
+			}
+		case strings.ContainsAny(pkg, "*?["):
+			if matched, err = filepath.Glob(pkg); err != nil {
+				return nil, nil, err
+			}
+			if matched = onlyPackages(matched); len(matched) == 0 {
+				return nil, nil, fmt.Errorf(app.TL_(ctx, "No RPM files match %s"), pkg)
+			}
+		case apt.IsRegularFileAndIsPackage(pkg):
+			matched = []string{pkg}
+		default:
+			expanded = append(expanded, pkg)
+			continue
+		}
+
+		for _, path := range matched {
+			if err = add(path); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	return expanded, files, nil
+}
+
+// Verify проверяет целостность и подписи файлов через rpm -K. Возвращает ошибку
+// со списком файлов без подписи или с неверной подписью.
+func (m *Manager) Verify(ctx context.Context, files []string) error {
+	if len(files) == 0 {
+		return nil
+	}
+
+	args := append([]string{"rpm", "-K", "--"}, files...)
+	stdout, stderr, err := m.runner.Run(ctx, args, command.WithQuiet(), command.WithEnv("LC_ALL=C"))
+	if err != nil && strings.TrimSpace(stdout) == "" {
+		return fmt.Errorf(app.TL_(ctx, "Failed to verify RPM signatures: %s"), strings.TrimSpace(stderr))
+	}
+
+	if bad := parseCheckSig(stdout, files); len(bad) > 0 {
+		return fmt.Errorf(app.TL_(ctx, "RPM files are not signed or the signature is invalid: %s"), strings.Join(bad, ", "))
+	}
+	return nil
+}
+
+// Names возвращает имена пакетов в файлах, ключ - имя пакета.
+func (m *Manager) Names(ctx context.Context, files []string) (map[string]string, error) {
+	result := make(map[string]string, len(files))
+	if len(files) == 0 {
+		return result, nil
+	}
+
+	args := append([]string{"rpm", "-qp", "--queryformat", "%{NAME}\n", "--"}, files...)
+	stdout, stderr, err := m.runner.Run(ctx, args, command.WithQuiet())
+	if err != nil {
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to read RPM files: %s"), strings.TrimSpace(stderr))
+	}
+
+	names := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(names) != len(files) {
+		return nil, errors.New(app.TL_(ctx, "Failed to read RPM files: unexpected rpm output"))
+	}
+	for i, name := range names {
+		result[strings.TrimSpace(name)] = files[i]
+	}
+	return result, nil
+}
+
+// Store копирует файл в ресурсы образа и возвращает путь относительно каталога ресурсов,
+// под которым файл доступен при сборке.
+func (m *Manager) Store(ctx context.Context, file string) (string, error) {
+	dir := m.StorePath()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	rel := filepath.Join(StoreDir, filepath.Base(file))
+	if err := osutils.Copy(file, filepath.Join(m.resourcesDir, rel), true); err != nil {
+		return "", fmt.Errorf(app.TL_(ctx, "Failed to save %s to the image resources: %v"), file, err)
+	}
+	return rel, nil
+}
+
+// StorePath возвращает абсолютный путь к каталогу сохранённых локальных пакетов.
+func (m *Manager) StorePath() string {
+	return filepath.Join(m.resourcesDir, StoreDir)
+}
+
+// parseCheckSig разбирает вывод rpm -K и возвращает файлы, не прошедшие проверку.
+// Файл без подписи считается непроверенным, даже если контрольные суммы совпали.
+func parseCheckSig(output string, files []string) []string {
+	verified := make(map[string]bool, len(files))
+	for _, line := range strings.Split(output, "\n") {
+		path, result, ok := strings.Cut(line, ": ")
+		if !ok {
+			continue
+		}
+		result = strings.ToLower(result)
+		if strings.Contains(result, "not ok") {
+			continue
+		}
+		if strings.Contains(result, "signatures") || strings.Contains(result, "gpg") || strings.Contains(result, "pgp") {
+			verified[strings.TrimSpace(path)] = true
+		}
+	}
+
+	var bad []string
+	for _, file := range files {
+		if !verified[file] {
+			bad = append(bad, file)
+		}
+	}
+	sort.Strings(bad)
+	return bad
+}
+
+// isPath сообщает, указан ли аргумент как путь, а не как имя пакета
+func isPath(arg string) bool {
+	return arg == "." || arg == ".." || strings.Contains(arg, "/") || strings.HasSuffix(strings.ToLower(arg), ".rpm")
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// onlyPackages оставляет среди путей только обычные файлы .rpm
+func onlyPackages(paths []string) []string {
+	var result []string
+	for _, path := range paths {
+		if apt.IsRegularFileAndIsPackage(path) {
+			result = append(result, path)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
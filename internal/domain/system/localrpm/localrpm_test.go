@@ -0,0 +1,141 @@
+package localrpm
+
+import (
+	"apm/internal/common/command"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type mockRunner struct {
+	stdout string
+	err    error
+	calls  [][]string
+}
+
+func (m *mockRunner) Run(_ context.Context, args []string, _ ...command.Option) (string, string, error) {
+	m.calls = append(m.calls, args)
+	return m.stdout, "", m.err
+}
+
+func writeFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("rpm"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "b.rpm", "a.rpm", "notes.txt")
+	if err := os.Mkdir(filepath.Join(dir, "sub.rpm"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	a, b := filepath.Join(dir, "a.rpm"), filepath.Join(dir, "b.rpm")
+
+	t.Run("directory", func(t *testing.T) {
+		expanded, files, err := Expand(context.Background(), []string{"vim", dir + "/", "lib*"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"vim", a, b, "lib*"}; !reflect.DeepEqual(expanded, want) {
+			t.Errorf("expanded = %v, want %v", expanded, want)
+		}
+		if want := []string{a, b}; !reflect.DeepEqual(files, want) {
+			t.Errorf("files = %v, want %v", files, want)
+		}
+	})
+
+	t.Run("glob and duplicates", func(t *testing.T) {
+		expanded, files, err := Expand(context.Background(), []string{filepath.Join(dir, "*.rpm"), a})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{a, b}; !reflect.DeepEqual(expanded, want) || !reflect.DeepEqual(files, want) {
+			t.Errorf("expanded = %v, files = %v", expanded, files)
+		}
+	})
+
+	t.Run("relative file", func(t *testing.T) {
+		t.Chdir(dir)
+		_, files, err := Expand(context.Background(), []string{"./a.rpm"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{a}; !reflect.DeepEqual(files, want) {
+			t.Errorf("files = %v, want %v", files, want)
+		}
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		if _, _, err := Expand(context.Background(), []string{filepath.Join(dir, "*.src.rpm")}); err == nil {
+			t.Error("expected error for unmatched pattern")
+		}
+		empty := t.TempDir()
+		if _, _, err := Expand(context.Background(), []string{empty}); err == nil {
+			t.Error("expected error for directory without packages")
+		}
+	})
+}
+
+func TestVerify(t *testing.T) {
+	files := []string{"/tmp/a.rpm", "/tmp/b.rpm", "/tmp/c.rpm"}
+	runner := &mockRunner{stdout: "" +
+		"/tmp/a.rpm: digests signatures OK\n" +
+		"/tmp/b.rpm: digests OK\n" +
+		"/tmp/c.rpm: digests SIGNATURES NOT OK\n"}
+
+	err := NewManager(runner, "").Verify(context.Background(), files)
+	if err == nil {
+		t.Fatal("expected verification error")
+	}
+	if msg := err.Error(); strings.Contains(msg, "/tmp/a.rpm") || !strings.Contains(msg, "/tmp/b.rpm") || !strings.Contains(msg, "/tmp/c.rpm") {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	runner = &mockRunner{stdout: "/tmp/a.rpm: sha1 md5 gpg OK\n"}
+	if err = NewManager(runner, "").Verify(context.Background(), files[:1]); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if want := []string{"rpm", "-K", "--", "/tmp/a.rpm"}; !reflect.DeepEqual(runner.calls[0], want) {
+		t.Errorf("unexpected call: %v", runner.calls[0])
+	}
+
+	if err = NewManager(&mockRunner{err: errors.New("rpm failed")}, "").Verify(context.Background(), files); err == nil {
+		t.Error("expected error when rpm fails without output")
+	}
+}
+
+func TestNames(t *testing.T) {
+	runner := &mockRunner{stdout: "foo\nbar\n"}
+	names, err := NewManager(runner, "").Names(context.Background(), []string{"/tmp/foo.rpm", "/tmp/bar.rpm"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"foo": "/tmp/foo.rpm", "bar": "/tmp/bar.rpm"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("names = %v, want %v", names, want)
+	}
+}
+
+func TestStore(t *testing.T) {
+	src := t.TempDir()
+	writeFiles(t, src, "foo.rpm")
+	resources := t.TempDir()
+
+	rel, err := NewManager(&mockRunner{}, resources).Store(context.Background(), filepath.Join(src, "foo.rpm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rel != filepath.Join(StoreDir, "foo.rpm") {
+		t.Errorf("unexpected path %q", rel)
+	}
+	if _, err = os.Stat(filepath.Join(resources, rel)); err != nil {
+		t.Errorf("stored file is missing: %v", err)
+	}
+}
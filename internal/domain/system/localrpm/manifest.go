@@ -0,0 +1,170 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package localrpm
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/command"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ManifestFile имя файла манифеста в каталоге скачанных пакетов
+const ManifestFile = "manifest.json"
+
+// Manifest набор пакетов, скачанных для установки на машине без доступа к репозиториям.
+type Manifest struct {
+	Created   time.Time         `json:"created"`
+	Requested []string          `json:"requested"`
+	Packages  []ManifestPackage `json:"packages"`
+}
+
+// ManifestPackage точная версия пакета из набора и контрольная сумма его файла.
+type ManifestPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Arch    string `json:"arch"`
+	File    string `json:"file"`
+	SHA256  string `json:"sha256"`
+}
+
+// WriteManifest описывает все RPM-файлы каталога и сохраняет манифест. Запрошенные пакеты
+// добавляются к уже записанным, чтобы каталог можно было пополнять несколькими загрузками.
+func (m *Manager) WriteManifest(ctx context.Context, dir string, requested []string) (Manifest, error) {
+	manifest := Manifest{Created: time.Now().UTC()}
+	if previous, err := readManifestFile(ctx, dir); err == nil {
+		manifest.Requested = previous.Requested
+	}
+	for _, pkg := range requested {
+		if !slices.Contains(manifest.Requested, pkg) {
+			manifest.Requested = append(manifest.Requested, pkg)
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.rpm"))
+	if err != nil {
+		return Manifest{}, err
+	}
+	sort.Strings(files)
+
+	if len(files) > 0 {
+		args := append([]string{"rpm", "-qp", "--queryformat", "%{NAME}\t%{VERSION}-%{RELEASE}\t%{ARCH}\n", "--"}, files...)
+		stdout, stderr, errRun := m.runner.Run(ctx, args, command.WithQuiet(), command.WithEnv("LC_ALL=C"))
+		if errRun != nil {
+			return Manifest{}, fmt.Errorf(app.TL_(ctx, "Failed to read RPM files: %s"), strings.TrimSpace(stderr))
+		}
+
+		lines := strings.Split(strings.TrimSpace(stdout), "\n")
+		if len(lines) != len(files) {
+			return Manifest{}, errors.New(app.TL_(ctx, "Failed to read RPM files: unexpected rpm output"))
+		}
+		for i, line := range lines {
+			fields := strings.Split(line, "\t")
+			if len(fields) != 3 {
+				return Manifest{}, errors.New(app.TL_(ctx, "Failed to read RPM files: unexpected rpm output"))
+			}
+			sum, errSum := fileSHA256(files[i])
+			if errSum != nil {
+				return Manifest{}, errSum
+			}
+			manifest.Packages = append(manifest.Packages, ManifestPackage{
+				Name:    fields[0],
+				Version: fields[1],
+				Arch:    fields[2],
+				File:    filepath.Base(files[i]),
+				SHA256:  sum,
+			})
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return Manifest{}, err
+	}
+	if err = os.WriteFile(filepath.Join(dir, ManifestFile), append(data, '\n'), 0644); err != nil {
+		return Manifest{}, err
+	}
+	return manifest, nil
+}
+
+// ReadManifest читает манифест каталога и сверяет контрольные суммы файлов.
+// Возвращает манифест и абсолютные пути файлов набора.
+func ReadManifest(ctx context.Context, dir string) (Manifest, []string, error) {
+	manifest, err := readManifestFile(ctx, dir)
+	if err != nil {
+		return Manifest{}, nil, err
+	}
+	if len(manifest.Packages) == 0 {
+		return Manifest{}, nil, fmt.Errorf(app.TL_(ctx, "Manifest %s contains no packages"), filepath.Join(dir, ManifestFile))
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return Manifest{}, nil, err
+	}
+
+	files := make([]string, 0, len(manifest.Packages))
+	for _, pkg := range manifest.Packages {
+		path := filepath.Join(abs, filepath.Base(pkg.File))
+		sum, errSum := fileSHA256(path)
+		if errSum != nil {
+			return Manifest{}, nil, fmt.Errorf(app.TL_(ctx, "Package file %s from the manifest is missing: %v"), pkg.File, errSum)
+		}
+		if sum != pkg.SHA256 {
+			return Manifest{}, nil, fmt.Errorf(app.TL_(ctx, "Checksum of %s does not match the manifest"), pkg.File)
+		}
+		files = append(files, path)
+	}
+	return manifest, files, nil
+}
+
+func readManifestFile(ctx context.Context, dir string) (Manifest, error) {
+	var manifest Manifest
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return manifest, fmt.Errorf(app.TL_(ctx, "Failed to read the manifest of downloaded packages: %v"), err)
+	}
+	if err = json.Unmarshal(data, &manifest); err != nil {
+		return manifest, fmt.Errorf(app.TL_(ctx, "Failed to read the manifest of downloaded packages: %v"), err)
+	}
+	return manifest, nil
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
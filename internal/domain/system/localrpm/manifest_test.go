@@ -0,0 +1,51 @@
+package localrpm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWriteAndReadManifest(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "bar-2.0-alt1.noarch.rpm", "foo-1.0-alt1.x86_64.rpm")
+	runner := &mockRunner{stdout: "bar\t2.0-alt1\tnoarch\nfoo\t1.0-alt1\tx86_64\n"}
+	m := NewManager(runner, "")
+
+	if _, err := m.WriteManifest(context.Background(), dir, []string{"foo"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.WriteManifest(context.Background(), dir, []string{"bar", "foo"}); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, files, err := ReadManifest(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"foo", "bar"}; !reflect.DeepEqual(manifest.Requested, want) {
+		t.Errorf("requested = %v, want %v", manifest.Requested, want)
+	}
+	if len(manifest.Packages) != 2 || manifest.Packages[1].Name != "foo" || manifest.Packages[1].Version != "1.0-alt1" {
+		t.Errorf("unexpected packages: %+v", manifest.Packages)
+	}
+	want := []string{filepath.Join(dir, "bar-2.0-alt1.noarch.rpm"), filepath.Join(dir, "foo-1.0-alt1.x86_64.rpm")}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("files = %v, want %v", files, want)
+	}
+
+	if err = os.WriteFile(filepath.Join(dir, "foo-1.0-alt1.x86_64.rpm"), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = ReadManifest(context.Background(), dir); err == nil {
+		t.Error("expected checksum mismatch error")
+	}
+}
+
+func TestReadManifestMissing(t *testing.T) {
+	if _, _, err := ReadManifest(context.Background(), t.TempDir()); err == nil {
+		t.Error("expected error for directory without manifest")
+	}
+}
@@ -0,0 +1,69 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/oplog"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// newLogFilter проверяет параметры и формирует фильтр лога.
+func newLogFilter(ctx context.Context, grep string, transaction string, lines int) (oplog.Filter, error) {
+	if lines < 0 {
+		return oplog.Filter{}, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "The number of lines must not be negative")))
+	}
+
+	return oplog.Filter{
+		Grep:        strings.TrimSpace(grep),
+		Transaction: strings.TrimSpace(transaction),
+		Lines:       lines,
+	}, nil
+}
+
+// Log возвращает последние записи лога apm, включая записи системного сервиса.
+func (a *Actions) Log(ctx context.Context, grep string, transaction string, lines int) (*LogResponse, error) {
+	filter, err := newLogFilter(ctx, grep, transaction, lines)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := a.serviceLogReader.Read(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LogResponse{
+		Message: fmt.Sprintf(app.TLN_(ctx, "%d log entry found", "%d log entries found", len(entries)), len(entries)),
+		Count:   len(entries),
+		Entries: entries,
+	}, nil
+}
+
+// FollowLog передаёт в fn последние записи лога и новые по мере появления, пока не отменён ctx.
+func (a *Actions) FollowLog(ctx context.Context, grep string, transaction string, lines int, fn func(oplog.Entry)) error {
+	filter, err := newLogFilter(ctx, grep, transaction, lines)
+	if err != nil {
+		return err
+	}
+
+	return a.serviceLogReader.Follow(ctx, filter, fn)
+}
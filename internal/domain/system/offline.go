@@ -0,0 +1,119 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	aptLib "apm/internal/common/binding/apt/lib"
+	"apm/internal/common/helper"
+	"apm/internal/domain/system/localrpm"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// archivesConfigKey ключ конфигурации APT с каталогом скачанных пакетов
+const archivesConfigKey = "Dir::Cache::Archives"
+
+// SetDownloadDir задаёт каталог, в который установка в режиме «только скачать» сохраняет
+// пакеты вместе с манифестом точных версий.
+func (a *Actions) SetDownloadDir(dir string) {
+	a.downloadDir = dir
+}
+
+// SetOfflineDir включает установку только из набора пакетов, ранее скачанного в каталог dir.
+func (a *Actions) SetOfflineDir(dir string) {
+	a.offlineDir = dir
+}
+
+// useDownloadDir направляет загрузку APT в каталог набора.
+func (a *Actions) useDownloadDir() error {
+	dir, err := filepath.Abs(a.downloadDir)
+	if err != nil {
+		return apmerr.New(apmerr.ErrorTypeValidation, err)
+	}
+	if err = os.MkdirAll(filepath.Join(dir, "partial"), 0755); err != nil {
+		return apmerr.New(apmerr.ErrorTypeValidation, err)
+	}
+
+	overrides := maps.Clone(a.serviceAptActions.GetAptConfigOverrides())
+	if overrides == nil {
+		overrides = make(map[string]string, 1)
+	}
+	overrides[archivesConfigKey] = dir + "/"
+	a.serviceAptActions.SetAptConfigOverrides(overrides)
+	return nil
+}
+
+// writeDownloadManifest обновляет манифест каталога после загрузки пакетов.
+func (a *Actions) writeDownloadManifest(ctx context.Context, requested []string) (localrpm.Manifest, error) {
+	manifest, err := a.serviceLocalRpm.WriteManifest(ctx, a.downloadDir, requested)
+	if err != nil {
+		return manifest, apmerr.New(apmerr.ErrorTypeApt, err)
+	}
+	return manifest, nil
+}
+
+// offlinePackages возвращает файлы набора из каталога SetOfflineDir и имена входящих в него пакетов.
+// Набор устанавливается целиком, поэтому перечислять пакеты вместе с ним нельзя.
+func (a *Actions) offlinePackages(ctx context.Context, packages []string) ([]string, map[string]bool, error) {
+	if len(packages) > 0 {
+		return nil, nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Packages cannot be listed together with --offline: the whole downloaded set is installed")))
+	}
+
+	manifest, files, err := localrpm.ReadManifest(ctx, a.offlineDir)
+	if err != nil {
+		return nil, nil, apmerr.New(apmerr.ErrorTypeValidation, err)
+	}
+
+	names := make(map[string]bool, len(manifest.Packages))
+	for _, pkg := range manifest.Packages {
+		names[pkg.Name] = true
+	}
+	return files, names, nil
+}
+
+// checkOfflineChanges проверяет, что все устанавливаемые и обновляемые пакеты входят в скачанный набор:
+// без сети APT не сможет получить недостающие зависимости.
+func checkOfflineChanges(ctx context.Context, changes *aptLib.PackageChanges, names map[string]bool) error {
+	if names == nil {
+		return nil
+	}
+
+	var missing []string
+	for _, list := range [][]string{changes.NewInstalledPackages, changes.UpgradedPackages, changes.ExtraInstalled} {
+		for _, pkg := range list {
+			name := helper.CleanPackageName(strings.TrimSpace(pkg))
+			if name != "" && !names[name] {
+				missing = append(missing, name)
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return apmerr.New(apmerr.ErrorTypeRepository, fmt.Errorf(
+		app.TL_(ctx, "The downloaded set is incomplete, missing packages: %s. Download them again with --download-only on a machine with repository access"),
+		strings.Join(missing, ", "),
+	))
+}
@@ -0,0 +1,144 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	_package "apm/internal/common/apt/package"
+	"apm/internal/common/helper"
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+const (
+	// OrphanNotAvailable пакета нет ни в одном подключённом репозитории
+	OrphanNotAvailable = "notAvailable"
+	// OrphanNewerThanRepo установленная версия новее версии из подключённых репозиториев
+	OrphanNewerThanRepo = "newerThanRepo"
+)
+
+// orphanIgnored пакеты, которые не поставляются репозиториями
+var orphanIgnored = []string{"gpg-pubkey"}
+
+// Orphans возвращает установленные пакеты, источник которых больше не подключён: пакета нет
+// в активных репозиториях или там лежит только более старая версия. Такие пакеты не обновляются.
+// Пакеты из keep исключаются из отчёта.
+func (a *Actions) Orphans(ctx context.Context, keep []string) (*OrphansResponse, error) {
+	if err := a.validateDB(ctx, false); err != nil {
+		return nil, err
+	}
+
+	installed, err := a.serviceAptActions.GetInstalledPackages(ctx)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeApt, err)
+	}
+
+	names := make([]string, 0, len(installed))
+	for name := range installed {
+		if !slices.Contains(orphanIgnored, name) && !slices.Contains(keep, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	known, err := a.serviceAptDatabase.GetPackagesByNames(ctx, names)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+	available := make(map[string]_package.Package, len(known))
+	for _, pkg := range known {
+		// Пакет без файла в репозитории известен apt только из базы rpm
+		if pkg.Filename != "" {
+			available[pkg.Name] = pkg
+		}
+	}
+
+	orphans := make([]OrphanPackage, 0)
+	var missing []string
+	for _, name := range names {
+		orphan := OrphanPackage{Name: name, VersionInstalled: installed[name]}
+		repoPkg, ok := available[name]
+		switch {
+		case !ok:
+			orphan.Reason = OrphanNotAvailable
+			missing = append(missing, name)
+		case helper.CompareVersions(installed[name], repoPkg.Version) > 0:
+			orphan.Reason = OrphanNewerThanRepo
+			orphan.RepoVersion = repoPkg.Version
+		default:
+			continue
+		}
+		orphans = append(orphans, orphan)
+	}
+
+	if err = a.findReplacements(ctx, orphans, missing); err != nil {
+		return nil, err
+	}
+
+	return &OrphansResponse{
+		Message: fmt.Sprintf(app.TLN_(ctx, "%d package is not provided by the configured repositories",
+			"%d packages are not provided by the configured repositories", len(orphans)), len(orphans)),
+		Packages: orphans,
+		Count:    len(orphans),
+	}, nil
+}
+
+// findReplacements заполняет замены для пакетов, отсутствующих в репозиториях: пакеты из
+// активных репозиториев, предоставляющие их имя
+func (a *Actions) findReplacements(ctx context.Context, orphans []OrphanPackage, missing []string) error {
+	providers, err := a.serviceAptDatabase.GetProviders(ctx, missing)
+	if err != nil {
+		return apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+
+	for i := range orphans {
+		if orphans[i].Reason != OrphanNotAvailable {
+			continue
+		}
+		for _, pkg := range providers {
+			if pkg.Filename != "" && pkg.Name != orphans[i].Name && slices.Contains(pkg.Provides, orphans[i].Name) {
+				orphans[i].Replacements = append(orphans[i].Replacements, pkg.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// orphanNames возвращает имена пакетов из отчёта
+func orphanNames(r *OrphansResponse) []string {
+	names := make([]string, 0, len(r.Packages))
+	for _, pkg := range r.Packages {
+		names = append(names, pkg.Name)
+	}
+	return names
+}
+
+// orphanReplacements возвращает уникальные замены для пакетов из отчёта
+func orphanReplacements(r *OrphansResponse) []string {
+	var names []string
+	for _, pkg := range r.Packages {
+		for _, name := range pkg.Replacements {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,110 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/journal"
+	reposervice "apm/internal/domain/repository/service"
+	"context"
+	"fmt"
+	"slices"
+)
+
+// GetSystemOverview возвращает сводное состояние системы одним вызовом: тип системы, статус образа,
+// ожидающие обновления, текущее ядро, репозитории, контейнеры distrobox и время последнего обновления.
+// Ошибка отдельного раздела не прерывает сбор остальных, она попадает в список errors.
+func (a *Actions) GetSystemOverview(ctx context.Context) (*SystemOverviewResponse, error) {
+	cfg := a.appConfig.ConfigManager.GetConfig()
+	resp := &SystemOverviewResponse{
+		Message:      app.TL_(ctx, "System overview"),
+		IsAtomic:     cfg.IsAtomic,
+		HasDistrobox: cfg.ExistDistrobox,
+	}
+
+	fail := func(section string, err error) {
+		app.Log.Debugf("system overview: %s: %v", section, err)
+		resp.Errors = append(resp.Errors, fmt.Sprintf("%s: %v", section, err))
+	}
+
+	if cfg.IsAtomic {
+		if imageStatus, err := a.getImageStatus(ctx); err != nil {
+			fail("image", err)
+		} else {
+			resp.Image = &imageStatus
+		}
+	}
+
+	if changes, err := a.serviceAptActions.CheckUpgrade(ctx); err != nil {
+		fail("upgrades", err)
+	} else if changes != nil {
+		resp.Upgrades = &OverviewUpgrades{
+			Upgraded:     changes.UpgradedCount,
+			NewInstalled: changes.NewInstalledCount,
+			Removed:      changes.RemovedCount,
+			KeptBack:     changes.KeptBackCount,
+			DownloadSize: changes.DownloadSize,
+		}
+	}
+
+	if kernel, err := a.serviceKernel.GetCurrentKernel(ctx); err != nil {
+		fail("kernel", err)
+	} else {
+		resp.Kernel = kernel
+	}
+
+	if repos, err := a.serviceRepos.GetRepositories(ctx, true); err != nil {
+		fail("repositories", err)
+	} else {
+		resp.Repositories = summarizeRepositories(repos)
+	}
+
+	if cfg.ExistDistrobox {
+		if containers, err := a.serviceContainers.GetContainerList(ctx, false); err != nil {
+			fail("distrobox", err)
+		} else {
+			resp.DistroboxContainers = len(containers)
+		}
+	}
+
+	if last, err := a.serviceJournal.Last(ctx, journal.ActionUpgrade); err != nil {
+		fail("lastUpgrade", err)
+	} else if last != nil {
+		resp.LastUpgrade = &last.Date
+	}
+
+	return resp, nil
+}
+
+// summarizeRepositories подсчитывает источники и определяет текущую ветку
+func summarizeRepositories(repos []reposervice.Repository) OverviewRepositories {
+	summary := OverviewRepositories{Total: len(repos)}
+	for _, repo := range repos {
+		if !repo.Active {
+			continue
+		}
+		summary.Active++
+		if slices.Contains(repo.Components, "task") {
+			summary.Tasks++
+			continue
+		}
+		if summary.Branch == "" && repo.Branch != "" {
+			summary.Branch = repo.Branch
+		}
+	}
+	return summary
+}
@@ -0,0 +1,98 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	aptLib "apm/internal/common/binding/apt/lib"
+	"apm/internal/common/reply"
+	"apm/internal/domain/system/dialog"
+	"context"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// SetForceEssential разрешает удаление защищённых пакетов после ввода фразы подтверждения.
+func (a *Actions) SetForceEssential(force bool) {
+	a.forceEssential = force
+}
+
+// findProtected возвращает удаляемые пакеты, попадающие под шаблоны protectedPackages
+func (a *Actions) findProtected(removed []string) []string {
+	patterns := a.appConfig.ConfigManager.GetConfig().ProtectedPackages
+
+	var protected []string
+	for _, pkg := range removed {
+		for _, pattern := range patterns {
+			if matched, err := path.Match(pattern, pkg); err == nil && matched {
+				protected = append(protected, pkg)
+				break
+			}
+		}
+	}
+	return protected
+}
+
+// markProtected отмечает защищённые пакеты среди критически важных, чтобы диалог и ответ
+// показали предупреждение. Возвращает найденные защищённые пакеты.
+func (a *Actions) markProtected(ctx context.Context, changes *aptLib.PackageChanges) []string {
+	protected := a.findProtected(changes.RemovedPackages)
+	for _, pkg := range protected {
+		known := false
+		for _, ep := range changes.EssentialPackages {
+			if ep.Name == pkg {
+				known = true
+				break
+			}
+		}
+		if !known {
+			changes.EssentialPackages = append(changes.EssentialPackages, aptLib.EssentialPackage{
+				Name:   pkg,
+				Reason: app.TL_(ctx, "protected package"),
+			})
+		}
+	}
+	return protected
+}
+
+// guardProtected запрещает удаление защищённых пакетов без флага --force-essential
+func (a *Actions) guardProtected(ctx context.Context, protected []string) error {
+	if len(protected) == 0 || a.forceEssential {
+		return nil
+	}
+
+	return apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(
+		app.TL_(ctx, "Protected packages will be removed: %s. Use --force-essential if you are sure"),
+		strings.Join(protected, ", ")))
+}
+
+// confirmProtected запрашивает ввод фразы подтверждения перед удалением защищённых пакетов
+func (a *Actions) confirmProtected(ctx context.Context, protected []string) error {
+	if len(protected) == 0 {
+		return nil
+	}
+
+	reply.StopSpinner(a.appConfig)
+	if err := dialog.ConfirmPhrase(ctx, a.appConfig, protected, app.TL_(ctx, "Yes, remove protected packages")); err != nil {
+		return apmerr.New(apmerr.ErrorTypeCanceled, err)
+	}
+	reply.CreateSpinner(a.appConfig)
+
+	return nil
+}
@@ -0,0 +1,80 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Provides ищет пакеты, которым принадлежит файл по абсолютному пути или которые предоставляют
+// библиотеку либо возможность. Установленные владельцы определяются через базу RPM, остальные
+// пакеты — по спискам файлов и provides из индекса репозиториев, сохранённого в базе пакетов.
+func (a *Actions) Provides(ctx context.Context, target string) (*ProvidesResponse, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "File path or library name must be specified, for example provides /usr/bin/vim")))
+	}
+
+	owners, err := a.serviceAptActions.GetFileOwners(ctx, target)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeApt, err)
+	}
+
+	resp := &ProvidesResponse{Target: target, Packages: []ProvidingPackage{}}
+	for _, owner := range owners {
+		resp.Packages = append(resp.Packages, ProvidingPackage{
+			Name:      owner.Name,
+			Version:   owner.Version,
+			Installed: true,
+			Source:    FilesSourceRpm,
+		})
+	}
+
+	if err = a.validateDB(ctx, false); err != nil {
+		return nil, err
+	}
+
+	available, err := a.serviceAptDatabase.FindPackagesProviding(ctx, target)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+	for _, pkg := range available {
+		if slices.ContainsFunc(resp.Packages, func(p ProvidingPackage) bool { return p.Name == pkg.Name }) {
+			continue
+		}
+		resp.Packages = append(resp.Packages, ProvidingPackage{
+			Name:      pkg.Name,
+			Version:   pkg.Version,
+			Installed: pkg.Installed,
+			Source:    FilesSourceRepository,
+		})
+	}
+
+	if len(resp.Packages) == 0 {
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.TL_(ctx, "No package provides %s"), target))
+	}
+
+	resp.Count = len(resp.Packages)
+	resp.Message = fmt.Sprintf(app.TLN_(ctx, "%d package provides %s", "%d packages provide %s", resp.Count), resp.Count, target)
+	return resp, nil
+}
@@ -0,0 +1,123 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	_package "apm/internal/common/apt/package"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// rdependsMaxDepth максимальная глубина обхода обратных зависимостей
+const rdependsMaxDepth = 10
+
+// ReverseDepends возвращает пакеты, зависящие от пакета или capability. Для пакета учитываются
+// также все его Provides. На каждом следующем уровне ищутся пакеты, зависящие от найденных ранее.
+func (a *Actions) ReverseDepends(ctx context.Context, target string, depth int, installed bool) (*ReverseDependsResponse, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "Package or capability must be specified, for example rdepends package")))
+	}
+	if depth < 1 || depth > rdependsMaxDepth {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(app.TL_(ctx, "Depth must be between 1 and %d"), rdependsMaxDepth))
+	}
+
+	if err := a.validateDB(ctx, false); err != nil {
+		return nil, err
+	}
+
+	visited := make(map[string]bool)
+	frontier := []string{target}
+	root := _package.Package{Name: target}
+	if pkg, err := a.serviceAptDatabase.GetPackageByName(ctx, target); err == nil {
+		visited[pkg.Name] = true
+		frontier = append(frontier, pkg.Provides...)
+		root = pkg
+	}
+
+	// owners сопоставляет capability текущего уровня пакету, через который она найдена
+	graph := newDependencyGraph(root)
+	owners := make(map[string]string, len(frontier))
+	for _, c := range frontier {
+		owners[c] = root.Name
+	}
+
+	packages := make([]ReverseDependency, 0)
+	for level := 1; level <= depth && len(frontier) > 0; level++ {
+		capabilities := make(map[string]bool, len(frontier))
+		for _, c := range frontier {
+			capabilities[c] = true
+		}
+
+		found, err := a.serviceAptDatabase.GetReverseDependencies(ctx, frontier, installed)
+		if err != nil {
+			return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+		}
+		sort.Slice(found, func(i, j int) bool {
+			return found[i].Name < found[j].Name
+		})
+
+		var next []string
+		nextOwners := make(map[string]string)
+		for _, pkg := range found {
+			via := ""
+			for _, dep := range pkg.Depends {
+				if capabilities[dep] {
+					via = dep
+					break
+				}
+			}
+			if owner := owners[via]; owner != pkg.Name {
+				graph.addEdge(owner, pkg, via)
+			}
+
+			if visited[pkg.Name] {
+				continue
+			}
+			visited[pkg.Name] = true
+
+			packages = append(packages, ReverseDependency{
+				Name:      pkg.Name,
+				Version:   pkg.Version,
+				Installed: pkg.Installed,
+				Depth:     level,
+				Via:       via,
+			})
+			next = append(next, pkg.Name)
+			next = append(next, pkg.Provides...)
+			nextOwners[pkg.Name] = pkg.Name
+			for _, provide := range pkg.Provides {
+				nextOwners[provide] = pkg.Name
+			}
+		}
+		frontier = next
+		owners = nextOwners
+	}
+
+	return &ReverseDependsResponse{
+		Message:  fmt.Sprintf(app.TLN_(ctx, "%d package depends on %s", "%d packages depend on %s", len(packages)), len(packages), target),
+		Target:   target,
+		Packages: packages,
+		Tree:     graph.tree(root.Name),
+		Count:    len(packages),
+	}, nil
+}
@@ -0,0 +1,174 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	aptLib "apm/internal/common/binding/apt/lib"
+	"apm/internal/common/journal"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// recordJournal записывает выполненную транзакцию в журнал: переводит отметку id, поставленную
+// beginJournal, в выполненные или, если отметки нет, создаёт новую запись. Ошибка записи не прерывает операцию.
+func (a *Actions) recordJournal(ctx context.Context, id uint, action string, changes *aptLib.PackageChanges) {
+	if a.serviceJournal == nil || changes == nil {
+		return
+	}
+
+	var err error
+	if id != 0 {
+		err = a.serviceJournal.Complete(ctx, id, journalEntry(action, changes))
+	} else {
+		err = a.serviceJournal.Record(ctx, journalEntry(action, changes))
+	}
+	if err != nil {
+		app.Log.Warning(fmt.Sprintf("failed to record transaction journal: %v", err))
+	}
+}
+
+// recordOperation записывает в журнал результат операции над образом. Ошибка записи не прерывает операцию.
+// Об успешной операции отправляется сигнал ImagePending: новое развёртывание загрузится после перезагрузки.
+func (a *Actions) recordOperation(ctx context.Context, entry journal.Entry, opErr error) {
+	if opErr == nil && entry.Module == journal.ModuleImage {
+		a.stateNotifier.Emit(app.ImagePendingSignal, entry.Action, entry.Targets)
+	}
+	if a.serviceJournal == nil {
+		return
+	}
+
+	if err := a.serviceJournal.RecordResult(context.WithoutCancel(ctx), entry, opErr); err != nil {
+		app.Log.Warning(fmt.Sprintf("failed to record transaction journal: %v", err))
+	}
+}
+
+// notifyPackagesChanged сообщает по DBus об изменении пакетов после обновления базы пакетов
+func (a *Actions) notifyPackagesChanged(action string, changes *aptLib.PackageChanges) {
+	if changes == nil {
+		return
+	}
+	a.stateNotifier.Emit(app.PackagesChangedSignal, journal.ModuleSystem, action,
+		changes.NewInstalledPackages, changes.UpgradedPackages, changes.RemovedPackages)
+}
+
+// journalEntry формирует запись журнала по изменениям транзакции
+func journalEntry(action string, changes *aptLib.PackageChanges) journal.Entry {
+	entry := journal.Entry{
+		Module:    journal.ModuleSystem,
+		Action:    action,
+		Installed: changes.NewInstalledPackages,
+		Upgraded:  changes.UpgradedPackages,
+		Removed:   changes.RemovedPackages,
+	}
+	if action == journal.ActionReinstall {
+		entry.Installed = nil
+		entry.Upgraded = changes.NewInstalledPackages
+	}
+	return entry
+}
+
+// Recent возвращает сводку транзакций за последние days дней с командами для повтора и отмены.
+func (a *Actions) Recent(ctx context.Context, days int) (*RecentResponse, error) {
+	if days <= 0 {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "The number of days must be greater than zero")))
+	}
+
+	entries, err := a.serviceJournal.Since(ctx, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+
+	resp := summarizeRecent(entries)
+	resp.Days = days
+	resp.Message = fmt.Sprintf(app.TLN_(ctx, "%d transaction found", "%d transactions found", len(entries)), len(entries))
+
+	return resp, nil
+}
+
+// summarizeRecent сводит записи журнала (от новых к старым) в списки недавно установленных и удалённых пакетов.
+// Для каждого пакета учитывается только последнее действие.
+func summarizeRecent(entries []journal.Entry) *RecentResponse {
+	resp := &RecentResponse{
+		Installed:    []RecentPackage{},
+		Removed:      []RecentPackage{},
+		Transactions: make([]RecentTransaction, 0, len(entries)),
+	}
+
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		for _, name := range e.Installed {
+			if !seen[name] {
+				seen[name] = true
+				resp.Installed = append(resp.Installed, RecentPackage{Name: name, Date: e.Date, Undo: "apm s remove " + name})
+			}
+		}
+		for _, name := range e.Removed {
+			if !seen[name] {
+				seen[name] = true
+				resp.Removed = append(resp.Removed, RecentPackage{Name: name, Date: e.Date, Undo: "apm s install " + name})
+			}
+		}
+
+		redo, undo := transactionShortcuts(e)
+		resp.Transactions = append(resp.Transactions, RecentTransaction{Entry: e, Redo: redo, Undo: undo})
+	}
+
+	return resp
+}
+
+// transactionShortcuts возвращает команды для повтора и отмены транзакции.
+// Обновления и переустановку отменить нельзя, поэтому для них команда отмены не формируется.
+func transactionShortcuts(e journal.Entry) (redo, undo string) {
+	switch e.Action {
+	case journal.ActionUpgrade:
+		return "apm s upgrade", ""
+	case journal.ActionReinstall:
+		return shortcut("reinstall", e.Upgraded, nil), ""
+	case journal.ActionRemove:
+		redo = shortcut("remove", e.Removed, nil)
+	default:
+		redo = shortcut("install", e.Installed, e.Removed)
+	}
+
+	if len(e.Removed) == 0 {
+		undo = shortcut("remove", e.Installed, nil)
+	} else {
+		undo = shortcut("install", e.Removed, e.Installed)
+	}
+
+	return redo, undo
+}
+
+// shortcut формирует команду apm s <cmd>, где удаляемые пакеты помечаются суффиксом «-».
+func shortcut(cmd string, packages []string, minus []string) string {
+	if len(packages) == 0 && len(minus) == 0 {
+		return ""
+	}
+
+	args := make([]string, 0, len(packages)+len(minus))
+	args = append(args, packages...)
+	for _, name := range minus {
+		args = append(args, name+"-")
+	}
+
+	return fmt.Sprintf("apm s %s %s", cmd, strings.Join(args, " "))
+}
@@ -0,0 +1,218 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	_package "apm/internal/common/apt/package"
+	aptLib "apm/internal/common/binding/apt/lib"
+	"apm/internal/common/journal"
+	"apm/internal/common/reply"
+	"apm/internal/domain/system/dialog"
+	"apm/internal/domain/system/rpmdup"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// beginJournal отмечает в журнале начало транзакции. Отметка остаётся, если процесс будет прерван.
+// Вместе с отметкой сохраняются прежние версии пакетов, чтобы транзакцию можно было откатить.
+func (a *Actions) beginJournal(ctx context.Context, action string, changes *aptLib.PackageChanges) uint {
+	if a.serviceJournal == nil || changes == nil {
+		return 0
+	}
+
+	entry := journalEntry(action, changes)
+	entry.Previous = a.previousVersions(ctx, entry)
+	id, err := a.serviceJournal.Begin(ctx, entry)
+	if err != nil {
+		app.Log.Warning(fmt.Sprintf("failed to mark transaction start in journal: %v", err))
+		return 0
+	}
+	return id
+}
+
+// finishJournal закрывает отметку начатой транзакции. Если транзакция не была записана как выполненная,
+// она остаётся в журнале со статусом failed.
+func (a *Actions) finishJournal(ctx context.Context, id uint) {
+	if a.serviceJournal == nil || id == 0 {
+		return
+	}
+
+	if err := a.serviceJournal.Fail(context.WithoutCancel(ctx), id); err != nil {
+		app.Log.Warning(fmt.Sprintf("failed to clear transaction mark in journal: %v", err))
+	}
+}
+
+// checkInterrupted не даёт начать новую транзакцию, пока не восстановлена прерванная.
+func (a *Actions) checkInterrupted(ctx context.Context) error {
+	if a.serviceJournal == nil {
+		return nil
+	}
+
+	entries, err := a.serviceJournal.Interrupted(ctx)
+	if err != nil {
+		app.Log.Debugf("failed to check interrupted transactions: %v", err)
+		return nil
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	last := entries[0]
+	return apmerr.New(apmerr.ErrorTypeApt, fmt.Errorf(
+		app.TL_(ctx, "The %s transaction started at %s was interrupted and the package state may be inconsistent. Run 'apm system repair' first"),
+		last.Action, last.Date.Format("2006-01-02 15:04:05")))
+}
+
+// Repair восстанавливает систему после прерванной транзакции: завершает незаконченные обновления rpm,
+// исправляет нарушенные зависимости и синхронизирует базу пакетов apm.
+func (a *Actions) Repair(ctx context.Context, confirm bool) (*RepairResponse, error) {
+	if err := a.checkOverlay(ctx); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, err)
+	}
+
+	ctx, release, err := a.operationLock.Acquire(ctx, "repair")
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	resp := &RepairResponse{
+		Interrupted: []journal.Entry{},
+		Duplicates:  []rpmdup.Duplicate{},
+	}
+
+	if a.serviceJournal != nil {
+		interrupted, err := a.serviceJournal.Interrupted(ctx)
+		if err != nil {
+			return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+		}
+		resp.Interrupted = interrupted
+	}
+
+	duplicates, err := a.serviceRpmDup.Find(ctx)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeApt, err)
+	}
+
+	changes, err := a.serviceAptActions.CheckFixBroken(ctx)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeApt, err)
+	}
+	fixBroken := changes != nil && changes.NewInstalledCount+changes.UpgradedCount+changes.RemovedCount > 0
+
+	// Устаревшие версии удаляются через rpm -e, поэтому до подтверждения ничего не меняется
+	if !confirm && (fixBroken || len(duplicates) > 0) {
+		reply.StopSpinner(a.appConfig)
+		dialogStatus, errDialog := dialog.NewDialog(ctx, a.appConfig, []_package.Package{}, repairPlan(changes, duplicates), dialog.ActionMultiInstall)
+		if errDialog != nil {
+			return nil, errDialog
+		}
+		if !dialogStatus {
+			return nil, apmerr.New(apmerr.ErrorTypeCanceled, errors.New(app.TL_(ctx, "Cancel dialog")))
+		}
+		reply.CreateSpinner(a.appConfig)
+	}
+
+	for _, d := range duplicates {
+		a.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventSystemRepair),
+			reply.WithEventView(fmt.Sprintf(app.TL_(ctx, "Finishing interrupted update of %s"), d.Name)))
+		err = a.serviceRpmDup.Finish(ctx, d)
+		a.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventSystemRepair))
+		if err != nil {
+			return nil, apmerr.New(apmerr.ErrorTypeApt, err)
+		}
+		resp.Duplicates = append(resp.Duplicates, d)
+	}
+
+	if fixBroken {
+		if err = a.serviceAptActions.FixBroken(ctx); err != nil {
+			return nil, apmerr.New(apmerr.ErrorTypeApt, err)
+		}
+		resp.Changes = changes
+	}
+
+	if err = a.updateAllPackagesDB(ctx); err != nil {
+		return nil, err
+	}
+
+	if a.serviceJournal != nil {
+		if err = a.serviceJournal.MarkRepaired(ctx); err != nil {
+			return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+		}
+	}
+
+	resp.Message = repairMessage(ctx, resp)
+	return resp, nil
+}
+
+// CheckRepair показывает, какие устаревшие версии пакетов удалит и какие исправления зависимостей
+// выполнит Repair, не применяя их
+func (a *Actions) CheckRepair(ctx context.Context) (*CheckResponse, error) {
+	duplicates, err := a.serviceRpmDup.Find(ctx)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeApt, err)
+	}
+
+	changes, err := a.serviceAptActions.CheckFixBroken(ctx)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeApt, err)
+	}
+
+	return &CheckResponse{
+		Message: app.TL_(ctx, "Inspection information"),
+		Info:    repairPlan(changes, duplicates),
+	}, nil
+}
+
+// repairPlan дополняет исправления зависимостей устаревшими версиями пакетов, которые будут удалены
+func repairPlan(changes *aptLib.PackageChanges, duplicates []rpmdup.Duplicate) aptLib.PackageChanges {
+	var plan aptLib.PackageChanges
+	if changes != nil {
+		plan = *changes
+	}
+
+	plan.RemovedPackages = slices.Clone(plan.RemovedPackages)
+	for _, d := range duplicates {
+		plan.RemovedPackages = append(plan.RemovedPackages, d.Stale...)
+		plan.RemovedCount += len(d.Stale)
+	}
+	return plan
+}
+
+// repairMessage формирует итоговое сообщение восстановления
+func repairMessage(ctx context.Context, resp *RepairResponse) string {
+	if len(resp.Duplicates) == 0 && resp.Changes == nil {
+		if len(resp.Interrupted) == 0 {
+			return app.TL_(ctx, "No problems found, the package state is consistent")
+		}
+		return app.TL_(ctx, "Interrupted transactions were checked, the package state is consistent")
+	}
+
+	var parts []string
+	if len(resp.Duplicates) > 0 {
+		parts = append(parts, fmt.Sprintf(app.TLN_(ctx, "%d interrupted package update finished", "%d interrupted package updates finished", len(resp.Duplicates)), len(resp.Duplicates)))
+	}
+	if resp.Changes != nil {
+		parts = append(parts, app.TL_(ctx, "broken dependencies fixed"))
+	}
+	return strings.Join(parts, ", ")
+}
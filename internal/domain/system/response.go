@@ -17,10 +17,23 @@
 package system
 
 import (
+	"apm/internal/common/app"
 	_package "apm/internal/common/apt/package"
 	aptlib "apm/internal/common/binding/apt/lib"
 	"apm/internal/common/build"
 	"apm/internal/common/filter"
+	"apm/internal/common/hooks"
+	"apm/internal/common/journal"
+	"apm/internal/common/oplog"
+	kservice "apm/internal/domain/kernel/service"
+	"apm/internal/domain/system/autoupgrade"
+	"apm/internal/domain/system/group"
+	"apm/internal/domain/system/restart"
+	"apm/internal/domain/system/rpmdup"
+	"apm/internal/domain/system/rpmnew"
+	"apm/internal/domain/system/schedule"
+	"apm/internal/domain/system/units"
+	"time"
 )
 
 // CheckResponse структура ответа для Check* методов
@@ -43,8 +56,18 @@ type UpdateResponse struct {
 
 // UpgradeResponse структура ответа для Upgrade метода
 type UpgradeResponse struct {
-	Message string  `json:"message"`
-	Result  *string `json:"result"`
+	Message     string            `json:"message"`
+	Result      *string           `json:"result"`
+	ConfigFiles []rpmnew.Decision `json:"configFiles,omitempty"`
+	NeedRestart []restart.Service `json:"needRestart,omitempty"`
+}
+
+// RepairResponse структура ответа для Repair метода
+type RepairResponse struct {
+	Message     string                 `json:"message"`
+	Interrupted []journal.Entry        `json:"interrupted"`
+	Duplicates  []rpmdup.Duplicate     `json:"duplicates"`
+	Changes     *aptlib.PackageChanges `json:"changes,omitempty"`
 }
 
 // InfoResponse структура ответа для Info метода
@@ -82,12 +105,16 @@ type SearchResponse struct {
 // ImageBuild структура ответа для ImageBuild
 type ImageBuild struct {
 	Message string `json:"message"`
+	Tag     string `json:"tag,omitempty"`
+	ImageID string `json:"imageId,omitempty"`
 }
 
 // ImageStatusResponse структура ответа для ImageStatus метода
 type ImageStatusResponse struct {
-	Message     string      `json:"message"`
-	BootedImage ImageStatus `json:"bootedImage"`
+	Message     string             `json:"message"`
+	BootedImage ImageStatus        `json:"bootedImage"`
+	Scheduled   *schedule.Schedule `json:"scheduled,omitempty"`
+	Pending     string             `json:"pending,omitempty"`
 }
 
 // ImageUpdateResponse структура ответа для ImageUpdate метода
@@ -102,6 +129,115 @@ type ImageApplyResponse struct {
 	BootedImage ImageStatus `json:"bootedImage"`
 }
 
+// ReverseDependency пакет, зависящий от запрошенного пакета или capability
+type ReverseDependency struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Installed bool   `json:"installed"`
+	Depth     int    `json:"depth"`
+	Via       string `json:"via"`
+}
+
+// ReverseDependsResponse структура ответа для ReverseDepends метода
+type ReverseDependsResponse struct {
+	Message  string              `json:"message"`
+	Target   string              `json:"target"`
+	Packages []ReverseDependency `json:"packages"`
+	Tree     DependencyNode      `json:"tree"`
+	Count    int                 `json:"count"`
+}
+
+// DependencyNode узел дерева зависимостей. Via - capability, через которую связаны пакеты, если она
+// отличается от имени пакета. Missing - зависимость не удовлетворяет ни один пакет, Repeated - пакет уже
+// раскрыт в другом месте дерева.
+type DependencyNode struct {
+	Name      string           `json:"name"`
+	Version   string           `json:"version,omitempty"`
+	Installed bool             `json:"installed"`
+	Via       string           `json:"via,omitempty"`
+	Missing   bool             `json:"missing,omitempty"`
+	Repeated  bool             `json:"repeated,omitempty"`
+	Children  []DependencyNode `json:"children,omitempty"`
+}
+
+// DependsResponse структура ответа для Depends метода
+type DependsResponse struct {
+	Message string         `json:"message"`
+	Target  string         `json:"target"`
+	Tree    DependencyNode `json:"tree"`
+	Count   int            `json:"count"`
+}
+
+// OrphanPackage установленный пакет, который не обновляется из подключённых репозиториев
+type OrphanPackage struct {
+	Name             string   `json:"name"`
+	VersionInstalled string   `json:"versionInstalled"`
+	RepoVersion      string   `json:"repoVersion,omitempty"`
+	Reason           string   `json:"reason"`
+	Replacements     []string `json:"replacements,omitempty"`
+}
+
+// OrphansResponse структура ответа для Orphans метода
+type OrphansResponse struct {
+	Message  string          `json:"message"`
+	Packages []OrphanPackage `json:"packages"`
+	Count    int             `json:"count"`
+}
+
+// ExplicitPackagesResponse структура ответа для ExplicitPackages метода
+type ExplicitPackagesResponse struct {
+	Message  string   `json:"message"`
+	Packages []string `json:"packages"`
+	Count    int      `json:"count"`
+}
+
+// ImageApplySimulateResponse структура ответа для CheckImageApply метода
+type ImageApplySimulateResponse struct {
+	Message  string             `json:"message"`
+	Image    string             `json:"image"`
+	Build    bool               `json:"build"`
+	Pull     bool               `json:"pull"`
+	Install  []string           `json:"install,omitempty"`
+	Remove   []string           `json:"remove,omitempty"`
+	Schedule *schedule.Schedule `json:"schedule,omitempty"`
+}
+
+// ImageApplyScheduleResponse структура ответа для отложенного применения образа
+type ImageApplyScheduleResponse struct {
+	Message  string             `json:"message"`
+	Schedule *schedule.Schedule `json:"schedule"`
+}
+
+// AutoUpgradeResponse структура ответа для AutoUpgrade метода
+type AutoUpgradeResponse struct {
+	Message        string   `json:"message"`
+	Policy         string   `json:"policy"`
+	Available      []string `json:"available"`
+	Security       []string `json:"security"`
+	Applied        []string `json:"applied"`
+	RebootRequired bool     `json:"rebootRequired"`
+	Notified       int      `json:"notified"`
+}
+
+// AutoUpgradeStatusResponse структура ответа для состояния автоматического обновления
+type AutoUpgradeStatusResponse struct {
+	Message string             `json:"message"`
+	Policy  string             `json:"policy"`
+	Timer   autoupgrade.Status `json:"timer"`
+}
+
+// ServiceResponse структура ответа для методов ServiceInstall, ServiceEnable и ServiceDisable
+type ServiceResponse struct {
+	Message string       `json:"message"`
+	Service units.Status `json:"service"`
+}
+
+// ServiceStatusResponse структура ответа для метода ServiceStatus
+type ServiceStatusResponse struct {
+	Message  string         `json:"message"`
+	Services []units.Status `json:"services"`
+}
+
 // ImageHistoryResponse структура ответа для ImageHistory метода
 type ImageHistoryResponse struct {
 	Message    string               `json:"message"`
@@ -109,6 +245,31 @@ type ImageHistoryResponse struct {
 	TotalCount int                  `json:"totalCount"`
 }
 
+// ImagePackageChange изменение пакета между двумя сборками образа
+type ImagePackageChange struct {
+	Name string `json:"name"`
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// ImageDiffResponse структура ответа для ImageDiff метода
+type ImageDiffResponse struct {
+	Message  string               `json:"message"`
+	From     build.ImageHistory   `json:"from"`
+	To       build.ImageHistory   `json:"to"`
+	Added    []ImagePackageChange `json:"added"`
+	Removed  []ImagePackageChange `json:"removed"`
+	Upgraded []ImagePackageChange `json:"upgraded"`
+}
+
+// ImageLogsResponse структура ответа для ImageLogs метода
+type ImageLogsResponse struct {
+	Message     string         `json:"message"`
+	Log         build.BuildLog `json:"log"`
+	Content     string         `json:"content"`
+	Generations int            `json:"generations"`
+}
+
 type ImageLintResponse struct {
 	Message  string             `json:"message"`
 	Tmpfiles *ImageLintTmpfiles `json:"tmpfiles,omitempty"`
@@ -165,8 +326,249 @@ type AptConfigResponse struct {
 	Options map[string]string `json:"options"`
 }
 
+// ConfigResponse структура ответа для методов ConfigGet, ConfigSet и ConfigUnset
+type ConfigResponse struct {
+	Message string            `json:"message"`
+	Path    string            `json:"path"`
+	Values  map[string]string `json:"values"`
+}
+
+// SelfUpdateResponse структура ответа для SelfUpdate метода
+type SelfUpdateResponse struct {
+	Message           string                 `json:"message"`
+	Channel           string                 `json:"channel"`
+	Source            string                 `json:"source,omitempty"`
+	CurrentVersion    string                 `json:"currentVersion"`
+	AvailableVersion  string                 `json:"availableVersion"`
+	Changelog         string                 `json:"changelog,omitempty"`
+	Info              *aptlib.PackageChanges `json:"info,omitempty"`
+	Updated           bool                   `json:"updated"`
+	RestartedServices []string               `json:"restartedServices,omitempty"`
+}
+
+// RecentPackage недавно установленный или удалённый пакет
+type RecentPackage struct {
+	Name string    `json:"name"`
+	Date time.Time `json:"date"`
+	Undo string    `json:"undo"`
+}
+
+// RecentTransaction транзакция из журнала с командами для повтора и отмены
+type RecentTransaction struct {
+	journal.Entry
+	Redo string `json:"redo,omitempty"`
+	Undo string `json:"undo,omitempty"`
+}
+
+// RecentResponse структура ответа для Recent метода
+type RecentResponse struct {
+	Message      string              `json:"message"`
+	Days         int                 `json:"days"`
+	Installed    []RecentPackage     `json:"installed"`
+	Removed      []RecentPackage     `json:"removed"`
+	Transactions []RecentTransaction `json:"transactions"`
+}
+
+// PackageFile файл из манифеста пакета
+type PackageFile struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+	Mode string `json:"mode,omitempty"`
+}
+
+// FilesResponse структура ответа для Files метода
+type FilesResponse struct {
+	Message   string        `json:"message"`
+	Package   string        `json:"package"`
+	Installed bool          `json:"installed"`
+	Source    string        `json:"source"`
+	Count     int           `json:"count"`
+	Files     []PackageFile `json:"files"`
+}
+
+// ProvidingPackage пакет, содержащий файл или предоставляющий возможность
+type ProvidingPackage struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Installed bool   `json:"installed"`
+	Source    string `json:"source"`
+}
+
+// ProvidesResponse структура ответа для Provides метода
+type ProvidesResponse struct {
+	Message  string             `json:"message"`
+	Target   string             `json:"target"`
+	Count    int                `json:"count"`
+	Packages []ProvidingPackage `json:"packages"`
+}
+
+// ChangelogResponse структура ответа для Changelog метода
+type ChangelogResponse struct {
+	Message          string                    `json:"message"`
+	Package          string                    `json:"package"`
+	InstalledVersion string                    `json:"installedVersion"`
+	CandidateVersion string                    `json:"candidateVersion"`
+	Since            string                    `json:"since"`
+	Entries          []_package.ChangelogEntry `json:"entries"`
+}
+
+// LogResponse структура ответа для Log метода
+type LogResponse struct {
+	Message string        `json:"message"`
+	Count   int           `json:"count"`
+	Entries []oplog.Entry `json:"entries"`
+}
+
+// HistoryListResponse структура ответа для HistoryList метода
+type HistoryListResponse struct {
+	Message    string          `json:"message"`
+	Entries    []journal.Entry `json:"entries"`
+	TotalCount int             `json:"totalCount"`
+}
+
+// HistoryShowResponse структура ответа для HistoryShow метода
+type HistoryShowResponse struct {
+	Message string            `json:"message"`
+	Entry   RecentTransaction `json:"entry"`
+}
+
+// HoldResponse структура ответа для Hold и Unhold методов
+type HoldResponse struct {
+	Message  string   `json:"message"`
+	Packages []string `json:"packages"`
+	Held     []string `json:"held"`
+}
+
+// RollbackResponse структура ответа для Rollback метода
+type RollbackResponse struct {
+	Message string                `json:"message"`
+	ID      uint                  `json:"id"`
+	Info    aptlib.PackageChanges `json:"info"`
+	Skipped []string              `json:"skipped"`
+}
+
+// DBStatusResponse структура ответа для DBStatus и DBMigrate методов
+type DBStatusResponse struct {
+	Message   string               `json:"message"`
+	Databases []app.DatabaseStatus `json:"databases"`
+}
+
+// DBVacuumResponse структура ответа для DBVacuum метода
+type DBVacuumResponse struct {
+	Message   string             `json:"message"`
+	Databases []app.VacuumResult `json:"databases"`
+}
+
+// DBRelocateResponse структура ответа для DBRelocate метода
+type DBRelocateResponse struct {
+	Message   string               `json:"message"`
+	Relocated []app.DataRelocation `json:"relocated"`
+}
+
+// HooksListResponse структура ответа для HooksList метода
+type HooksListResponse struct {
+	Message string       `json:"message"`
+	Dir     string       `json:"dir"`
+	Hooks   []hooks.Hook `json:"hooks"`
+}
+
+// HooksTestResponse структура ответа для HooksTest метода
+type HooksTestResponse struct {
+	Message string       `json:"message"`
+	Result  hooks.Result `json:"result"`
+}
+
+// GroupListResponse структура ответа для GroupList метода
+type GroupListResponse struct {
+	Message string         `json:"message"`
+	Groups  []GroupSummary `json:"groups"`
+}
+
+// GroupInfoResponse структура ответа для GroupInfo метода
+type GroupInfoResponse struct {
+	Message  string         `json:"message"`
+	Group    group.Group    `json:"group"`
+	Packages []GroupPackage `json:"packages"`
+}
+
+// BatchBody тело запроса для Batch — операции в порядке выполнения.
+type BatchBody struct {
+	Operations []BatchOperation `json:"operations"`
+}
+
+// BatchResponse структура ответа для Batch метода
+type BatchResponse struct {
+	Message    string                 `json:"message"`
+	Simulated  bool                   `json:"simulated"`
+	Operations []BatchOperation       `json:"operations"`
+	Update     *UpdateResponse        `json:"update,omitempty"`
+	Check      *CheckResponse         `json:"check,omitempty"`
+	Packages   *InstallRemoveResponse `json:"packages,omitempty"`
+	Image      *ImageApplyResponse    `json:"image,omitempty"`
+}
+
 // BackgroundTaskResponse структура ответа при запуске фоновой задачи
 type BackgroundTaskResponse struct {
 	Message     string `json:"message"`
 	Transaction string `json:"transaction"`
 }
+
+// SystemOverviewResponse сводное состояние системы для стартового экрана GUI
+type SystemOverviewResponse struct {
+	Message             string               `json:"message"`
+	IsAtomic            bool                 `json:"isAtomic"`
+	Image               *ImageStatus         `json:"image,omitempty"`
+	Upgrades            *OverviewUpgrades    `json:"upgrades,omitempty"`
+	Kernel              *kservice.Info       `json:"kernel,omitempty"`
+	Repositories        OverviewRepositories `json:"repositories"`
+	HasDistrobox        bool                 `json:"hasDistrobox"`
+	DistroboxContainers int                  `json:"distroboxContainers"`
+	LastUpgrade         *time.Time           `json:"lastUpgrade,omitempty"`
+	Errors              []string             `json:"errors,omitempty"`
+}
+
+// OverviewUpgrades количество ожидающих изменений при обновлении системы
+type OverviewUpgrades struct {
+	Upgraded     int    `json:"upgraded"`
+	NewInstalled int    `json:"newInstalled"`
+	Removed      int    `json:"removed"`
+	KeptBack     int    `json:"keptBack"`
+	DownloadSize uint64 `json:"downloadSize"`
+}
+
+// OverviewRepositories сводка по источникам пакетов
+type OverviewRepositories struct {
+	Total  int    `json:"total"`
+	Active int    `json:"active"`
+	Tasks  int    `json:"tasks"`
+	Branch string `json:"branch"`
+}
+
+// RestartServicesResponse структура ответа для RestartServices метода
+type RestartServicesResponse struct {
+	Message   string            `json:"message"`
+	Services  []restart.Service `json:"services"`
+	Restarted []string          `json:"restarted"`
+	Skipped   []string          `json:"skipped"`
+	Failed    []string          `json:"failed"`
+}
+
+// CleanTarget размеры очищенного каталога
+type CleanTarget struct {
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	Files      int    `json:"files"`
+	SizeBefore int64  `json:"sizeBefore"`
+	SizeAfter  int64  `json:"sizeAfter"`
+}
+
+// CleanResponse структура ответа для Clean метода
+type CleanResponse struct {
+	Message      string        `json:"message"`
+	Targets      []CleanTarget `json:"targets"`
+	Files        int           `json:"files"`
+	OrphanedRows int64         `json:"orphanedRows"`
+	SizeBefore   int64         `json:"sizeBefore"`
+	SizeAfter    int64         `json:"sizeAfter"`
+	Freed        int64         `json:"freed"`
+}
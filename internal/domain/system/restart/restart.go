@@ -0,0 +1,259 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package restart
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/command"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// deletedSuffix отметка в /proc/<pid>/maps для файлов, удалённых после отображения в память
+const deletedSuffix = " (deleted)"
+
+// libraryPrefixes каталоги, замена файлов в которых означает обновление пакета
+var libraryPrefixes = []string{"/usr/", "/lib/", "/lib64/", "/bin/", "/sbin/", "/opt/"}
+
+// Service служба, процессы которой используют удалённые при обновлении файлы
+type Service struct {
+	Unit        string   `json:"unit"`
+	PIDs        []int    `json:"pids"`
+	Files       []string `json:"files"`
+	Blacklisted bool     `json:"blacklisted"`
+}
+
+// Manager находит и перезапускает службы, использующие устаревшие библиотеки
+type Manager struct {
+	runner    command.Runner
+	procRoot  string
+	blacklist []string
+}
+
+// NewManager создаёт менеджер перезапуска служб. blacklist содержит шаблоны имён юнитов,
+// которые не перезапускаются автоматически.
+func NewManager(runner command.Runner, procRoot string, blacklist []string) *Manager {
+	return &Manager{
+		runner:    runner,
+		procRoot:  procRoot,
+		blacklist: blacklist,
+	}
+}
+
+// Find возвращает системные службы, процессы которых держат отображёнными удалённые файлы
+func (m *Manager) Find(_ context.Context) ([]Service, error) {
+	entries, err := os.ReadDir(m.procRoot)
+	if err != nil {
+		return nil, fmt.Errorf(app.T_("Failed to read %s: %v"), m.procRoot, err)
+	}
+
+	services := make(map[string]*Service)
+	for _, entry := range entries {
+		pid, errAtoi := strconv.Atoi(entry.Name())
+		if errAtoi != nil || !entry.IsDir() {
+			continue
+		}
+
+		// Процесс мог завершиться между чтением каталога и чтением файлов
+		maps, errRead := os.ReadFile(filepath.Join(m.procRoot, entry.Name(), "maps"))
+		if errRead != nil {
+			continue
+		}
+		files := parseDeletedFiles(string(maps))
+		if len(files) == 0 {
+			continue
+		}
+
+		cgroup, errRead := os.ReadFile(filepath.Join(m.procRoot, entry.Name(), "cgroup"))
+		if errRead != nil {
+			continue
+		}
+		unit := parseServiceUnit(string(cgroup))
+		if unit == "" {
+			continue
+		}
+
+		svc, ok := services[unit]
+		if !ok {
+			svc = &Service{Unit: unit, Blacklisted: m.IsBlacklisted(unit)}
+			services[unit] = svc
+		}
+		svc.PIDs = append(svc.PIDs, pid)
+		svc.Files = mergeFiles(svc.Files, files)
+	}
+
+	result := make([]Service, 0, len(services))
+	for _, svc := range services {
+		sort.Ints(svc.PIDs)
+		result = append(result, *svc)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Unit < result[j].Unit
+	})
+	return result, nil
+}
+
+// Restart перезапускает службу
+func (m *Manager) Restart(ctx context.Context, unit string) error {
+	if _, stderr, err := m.runner.Run(ctx, []string{"systemctl", "restart", unit}, command.WithQuiet()); err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Failed to restart %s: %s"), unit, strings.TrimSpace(stderr))
+	}
+	return nil
+}
+
+// RestartSelf перезапускает системную службу unit, если она запущена, и завершает процессы, запущенные
+// с аргументами argv. Сессионные службы активируются по D-Bus и запустятся из нового бинарника при следующем
+// обращении. Возвращает перезапущенные службы.
+func (m *Manager) RestartSelf(ctx context.Context, unit string, argv []string) []string {
+	var restarted []string
+	if _, _, err := m.runner.Run(ctx, []string{"systemctl", "is-active", "--quiet", unit}, command.WithQuiet()); err == nil {
+		if _, stderr, errRestart := m.runner.Run(ctx, []string{"systemctl", "try-restart", unit}, command.WithQuiet()); errRestart != nil {
+			app.Log.Warning(fmt.Sprintf("failed to restart %s: %v %s", unit, errRestart, stderr))
+		} else {
+			restarted = append(restarted, unit)
+		}
+	}
+
+	pids := m.findProcesses(argv)
+	if len(pids) == 0 {
+		return restarted
+	}
+
+	args := []string{"kill", "-TERM"}
+	for _, pid := range pids {
+		args = append(args, strconv.Itoa(pid))
+	}
+	if _, stderr, err := m.runner.Run(ctx, args, command.WithQuiet()); err != nil {
+		app.Log.Warning(fmt.Sprintf("failed to stop %s: %v %s", strings.Join(argv, " "), err, stderr))
+		return restarted
+	}
+	return append(restarted, strings.Join(argv[1:], " "))
+}
+
+// findProcesses возвращает процессы, запущенные ровно с аргументами argv
+func (m *Manager) findProcesses(argv []string) []int {
+	if len(argv) == 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(m.procRoot)
+	if err != nil {
+		return nil
+	}
+
+	var pids []int
+	for _, entry := range entries {
+		pid, errAtoi := strconv.Atoi(entry.Name())
+		if errAtoi != nil || !entry.IsDir() {
+			continue
+		}
+
+		cmdline, errRead := os.ReadFile(filepath.Join(m.procRoot, entry.Name(), "cmdline"))
+		if errRead != nil {
+			continue
+		}
+		if slices.Equal(strings.Split(strings.TrimRight(string(cmdline), "\x00"), "\x00"), argv) {
+			pids = append(pids, pid)
+		}
+	}
+	sort.Ints(pids)
+	return pids
+}
+
+// IsBlacklisted проверяет, запрещён ли автоматический перезапуск службы
+func (m *Manager) IsBlacklisted(unit string) bool {
+	for _, pattern := range m.blacklist {
+		if matched, err := path.Match(pattern, unit); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDeletedFiles возвращает удалённые системные файлы из содержимого /proc/<pid>/maps
+func parseDeletedFiles(maps string) []string {
+	var files []string
+	for _, line := range strings.Split(maps, "\n") {
+		if !strings.HasSuffix(line, deletedSuffix) {
+			continue
+		}
+
+		// адрес, права, смещение, устройство, inode, путь
+		fields := strings.SplitN(line, " ", 6)
+		if len(fields) < 6 {
+			continue
+		}
+		file := strings.TrimSuffix(strings.TrimSpace(fields[5]), deletedSuffix)
+		if isLibraryPath(file) {
+			files = mergeFiles(files, []string{file})
+		}
+	}
+	return files
+}
+
+// isLibraryPath проверяет, что файл относится к установленным пакетам, а не к временным данным
+func isLibraryPath(file string) bool {
+	for _, prefix := range libraryPrefixes {
+		if strings.HasPrefix(file, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseServiceUnit определяет службу systemd по содержимому /proc/<pid>/cgroup. Поддерживаются
+// cgroup v2 и иерархия name=systemd из cgroup v1. Для процессов вне служб возвращается пустая строка.
+func parseServiceUnit(cgroup string) string {
+	for _, line := range strings.Split(cgroup, "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if !(parts[0] == "0" && parts[1] == "") && parts[1] != "name=systemd" {
+			continue
+		}
+
+		// Первая служба в пути: процессы пользовательского менеджера относятся к user@UID.service
+		for _, component := range strings.Split(parts[2], "/") {
+			if strings.HasSuffix(component, ".service") {
+				return component
+			}
+		}
+	}
+	return ""
+}
+
+// mergeFiles добавляет файлы без повторов и сохраняет сортировку
+func mergeFiles(files []string, add []string) []string {
+	for _, f := range add {
+		idx := sort.SearchStrings(files, f)
+		if idx < len(files) && files[idx] == f {
+			continue
+		}
+		files = append(files, "")
+		copy(files[idx+1:], files[idx:])
+		files[idx] = f
+	}
+	return files
+}
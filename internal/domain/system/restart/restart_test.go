@@ -0,0 +1,129 @@
+package restart
+
+import (
+	"apm/internal/common/command"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type mockRunner struct {
+	calls [][]string
+}
+
+func (m *mockRunner) Run(_ context.Context, args []string, _ ...command.Option) (string, string, error) {
+	m.calls = append(m.calls, args)
+	return "", "", nil
+}
+
+func writeProc(t *testing.T, root, pid, maps, cgroup string) {
+	t.Helper()
+	dir := filepath.Join(root, pid)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "maps"), []byte(maps), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "cgroup"), []byte(cgroup), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	deletedSSL := "7f1c2a400000-7f1c2a428000 r-xp 00000000 fd:01 1313046                    /usr/lib64/libssl.so.3 (deleted)\n"
+	deletedLibc := "7f1c2a500000-7f1c2a528000 r-xp 00000000 fd:01 1313047                    /lib64/libc.so.6 (deleted)\n"
+	memfd := "7f1c2a600000-7f1c2a628000 rw-s 00000000 00:01 2048                       /memfd:wayland-shm (deleted)\n"
+	current := "7f1c2a700000-7f1c2a728000 r-xp 00000000 fd:01 1313048                    /usr/lib64/libz.so.1\n"
+
+	writeProc(t, root, "100", deletedSSL+current, "0::/system.slice/sshd.service\n")
+	writeProc(t, root, "101", deletedSSL+deletedLibc, "0::/system.slice/sshd.service\n")
+	writeProc(t, root, "200", memfd+current, "0::/system.slice/compositor.service\n")
+	writeProc(t, root, "300", deletedLibc, "0::/user.slice/user-1000.slice/session-2.scope\n")
+	writeProc(t, root, "400", deletedLibc, "0::/user.slice/user-1000.slice/user@1000.service/app.slice/pipewire.service\n")
+	writeProc(t, root, "500", deletedSSL, "12:name=systemd:/system.slice/nginx.service\n1:cpu:/\n")
+	if err := os.MkdirAll(filepath.Join(root, "self"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewManager(&mockRunner{}, root, []string{"user@*.service"})
+	services, err := m.Find(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Service{
+		{Unit: "nginx.service", PIDs: []int{500}, Files: []string{"/usr/lib64/libssl.so.3"}},
+		{Unit: "sshd.service", PIDs: []int{100, 101}, Files: []string{"/lib64/libc.so.6", "/usr/lib64/libssl.so.3"}},
+		{Unit: "user@1000.service", PIDs: []int{400}, Files: []string{"/lib64/libc.so.6"}, Blacklisted: true},
+	}
+	if !reflect.DeepEqual(services, want) {
+		t.Errorf("Find() = %+v, want %+v", services, want)
+	}
+}
+
+func TestIsBlacklisted(t *testing.T) {
+	m := NewManager(&mockRunner{}, "/proc", []string{"dbus.service", "getty@*.service"})
+
+	tests := map[string]bool{
+		"dbus.service":        true,
+		"getty@tty1.service":  true,
+		"sshd.service":        false,
+		"dbus-broker.service": false,
+	}
+	for unit, want := range tests {
+		if got := m.IsBlacklisted(unit); got != want {
+			t.Errorf("IsBlacklisted(%q) = %v, want %v", unit, got, want)
+		}
+	}
+}
+
+func TestRestart(t *testing.T) {
+	runner := &mockRunner{}
+	m := NewManager(runner, "/proc", nil)
+
+	if err := m.Restart(context.Background(), "sshd.service"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := [][]string{{"systemctl", "restart", "sshd.service"}}
+	if !reflect.DeepEqual(runner.calls, want) {
+		t.Errorf("expected calls %v, got %v", want, runner.calls)
+	}
+}
+
+func TestRestartSelf(t *testing.T) {
+	root := t.TempDir()
+	for pid, cmdline := range map[string]string{
+		"100": "/usr/bin/apm\x00dbus-session\x00",
+		"101": "/usr/bin/apm\x00dbus-session\x00",
+		"200": "/usr/bin/apm\x00dbus-system\x00",
+		"300": "/usr/bin/vim\x00dbus-session\x00",
+	} {
+		dir := filepath.Join(root, pid)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "cmdline"), []byte(cmdline), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runner := &mockRunner{}
+	m := NewManager(runner, root, nil)
+	restarted := m.RestartSelf(context.Background(), "apm.service", []string{"/usr/bin/apm", "dbus-session"})
+
+	if !reflect.DeepEqual(restarted, []string{"apm.service", "dbus-session"}) {
+		t.Errorf("unexpected restarted services: %v", restarted)
+	}
+	want := [][]string{
+		{"systemctl", "is-active", "--quiet", "apm.service"},
+		{"systemctl", "try-restart", "apm.service"},
+		{"kill", "-TERM", "100", "101"},
+	}
+	if !reflect.DeepEqual(runner.calls, want) {
+		t.Errorf("unexpected commands: %v", runner.calls)
+	}
+}
@@ -0,0 +1,102 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/helper"
+	"apm/internal/common/reply"
+	"apm/internal/domain/system/restart"
+	"context"
+	"fmt"
+)
+
+// findServicesToRestart ищет службы, использующие библиотеки, заменённые обновлением.
+// Ошибка поиска не прерывает обновление. Внутри сборки образа службы не запущены, поиск пропускается.
+func (a *Actions) findServicesToRestart(ctx context.Context) []restart.Service {
+	if helper.IsBuildEnvironment() {
+		return nil
+	}
+	services, err := a.serviceRestart.Find(ctx)
+	if err != nil {
+		app.Log.Debugf("failed to find services to restart: %v", err)
+		return nil
+	}
+	return services
+}
+
+// restartHint формирует подсказку о службах, требующих перезапуска
+func restartHint(ctx context.Context, services []restart.Service) string {
+	return fmt.Sprintf(app.TLN_(ctx,
+		"%d service uses outdated libraries, restart it with: apm system restart-services --auto",
+		"%d services use outdated libraries, restart them with: apm system restart-services --auto",
+		len(services)), len(services))
+}
+
+// RestartServices находит службы, использующие устаревшие после обновления библиотеки.
+// При auto службы перезапускаются, кроме перечисленных в restartBlacklist.
+func (a *Actions) RestartServices(ctx context.Context, auto bool) (*RestartServicesResponse, error) {
+	services, err := a.serviceRestart.Find(ctx)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypePermission, err)
+	}
+
+	resp := &RestartServicesResponse{
+		Services:  services,
+		Restarted: []string{},
+		Skipped:   []string{},
+		Failed:    []string{},
+	}
+
+	if len(services) == 0 {
+		resp.Message = app.TL_(ctx, "No services need to be restarted")
+		return resp, nil
+	}
+
+	if !auto {
+		resp.Message = restartHint(ctx, services)
+		return resp, nil
+	}
+
+	for _, svc := range services {
+		if svc.Blacklisted {
+			resp.Skipped = append(resp.Skipped, svc.Unit)
+			continue
+		}
+
+		a.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventSystemRestartServices),
+			reply.WithEventView(fmt.Sprintf(app.TL_(ctx, "Restarting %s"), svc.Unit)))
+		err = a.serviceRestart.Restart(ctx, svc.Unit)
+		a.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventSystemRestartServices))
+		if err != nil {
+			app.Log.Warning(err.Error())
+			resp.Failed = append(resp.Failed, svc.Unit)
+			continue
+		}
+		resp.Restarted = append(resp.Restarted, svc.Unit)
+	}
+
+	resp.Message = fmt.Sprintf(app.TLN_(ctx, "%d service restarted", "%d services restarted", len(resp.Restarted)), len(resp.Restarted))
+	if len(resp.Failed) > 0 {
+		resp.Message += ", " + fmt.Sprintf(app.TLN_(ctx, "%d failed", "%d failed", len(resp.Failed)), len(resp.Failed))
+	}
+	if len(resp.Skipped) > 0 {
+		resp.Message += ", " + fmt.Sprintf(app.TLN_(ctx, "%d skipped by restartBlacklist", "%d skipped by restartBlacklist", len(resp.Skipped)), len(resp.Skipped))
+	}
+	return resp, nil
+}
@@ -0,0 +1,221 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/helper"
+	"apm/internal/common/journal"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// rollbackPlan обратные операции для отката транзакции
+type rollbackPlan struct {
+	// Remove пакеты, установленные транзакцией
+	Remove []string
+	// Restore удалённые транзакцией пакеты: имя=версия-релиз, если прежняя версия известна
+	Restore []string
+	// Downgrade обновлённые транзакцией пакеты с прежней версией: имя=версия-релиз
+	Downgrade []string
+	// Skipped пакеты, которые не удастся вернуть к прежней версии
+	Skipped []string
+}
+
+// args формирует список пакетов для Install, где удаляемые пакеты помечены суффиксом «-».
+func (p rollbackPlan) args() []string {
+	args := make([]string, 0, len(p.Restore)+len(p.Downgrade)+len(p.Remove))
+	args = append(args, p.Restore...)
+	args = append(args, p.Downgrade...)
+	for _, name := range p.Remove {
+		args = append(args, name+"-")
+	}
+	return args
+}
+
+// Rollback откатывает транзакцию из журнала: удаляет установленные ею пакеты, возвращает удалённые
+// и понижает обновлённые до прежних версий, если эти версии ещё доступны в репозиториях.
+// ref — номер операции в истории или идентификатор транзакции. При simulate изменения только проверяются.
+func (a *Actions) Rollback(ctx context.Context, ref string, confirm bool, simulate bool) (*RollbackResponse, error) {
+	entry, err := a.findRollbackEntry(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Module != journal.ModuleSystem || entry.Status != journal.StatusDone {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, fmt.Errorf(
+			app.TL_(ctx, "Operation %d cannot be rolled back: only completed package transactions are supported"), entry.ID))
+	}
+
+	installed, err := a.serviceAptActions.GetInstalledEVRs(ctx)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeApt, err)
+	}
+
+	plan := planRollback(*entry, installed)
+	a.checkRollbackVersions(ctx, &plan)
+
+	args := plan.args()
+	if len(args) == 0 {
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, fmt.Errorf(
+			app.TL_(ctx, "Operation %d has nothing to roll back"), entry.ID))
+	}
+
+	resp := &RollbackResponse{ID: entry.ID, Skipped: plan.Skipped}
+	if simulate {
+		check, errCheck := a.CheckInstall(ctx, args)
+		if errCheck != nil {
+			return nil, errCheck
+		}
+		resp.Message = check.Message
+		resp.Info = check.Info
+		return resp, nil
+	}
+
+	result, err := a.Install(ctx, args, confirm, false)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Message = fmt.Sprintf(app.TL_(ctx, "Operation %d rolled back"), entry.ID)
+	resp.Info = result.Info
+	return resp, nil
+}
+
+// findRollbackEntry находит запись журнала по номеру операции или идентификатору транзакции
+func (a *Actions) findRollbackEntry(ctx context.Context, ref string) (*journal.Entry, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "You must specify an operation ID or a transaction")))
+	}
+
+	var entry *journal.Entry
+	var err error
+	if id, errParse := strconv.ParseUint(ref, 10, 64); errParse == nil {
+		entry, err = a.serviceJournal.Get(ctx, uint(id))
+	} else {
+		entry, err = a.serviceJournal.FindByTransaction(ctx, ref)
+	}
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+	if entry == nil {
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.TL_(ctx, "Operation %s not found in history"), ref))
+	}
+
+	return entry, nil
+}
+
+// planRollback вычисляет обратные операции по записи журнала и текущим установленным версиям
+// с эпохой и релизом. Пакеты, уже приведённые к прежнему состоянию, пропускаются. В старых записях
+// журнала версия хранится без релиза, тогда релиз при сравнении не учитывается.
+func planRollback(entry journal.Entry, installed map[string]string) rollbackPlan {
+	previous := make(map[string]string, len(entry.Previous))
+	for _, item := range entry.Previous {
+		if name, version, ok := strings.Cut(item, "="); ok && version != "" {
+			previous[name] = version
+		}
+	}
+
+	plan := rollbackPlan{Skipped: []string{}}
+	for _, name := range entry.Installed {
+		if _, ok := installed[name]; ok {
+			plan.Remove = append(plan.Remove, name)
+		}
+	}
+
+	for _, name := range entry.Removed {
+		if _, ok := installed[name]; ok {
+			continue
+		}
+		if version, ok := previous[name]; ok {
+			plan.Restore = append(plan.Restore, name+"="+version)
+		} else {
+			plan.Restore = append(plan.Restore, name)
+		}
+	}
+
+	for _, name := range entry.Upgraded {
+		current, ok := installed[name]
+		if !ok {
+			continue
+		}
+		version, known := previous[name]
+		if !known {
+			plan.Skipped = append(plan.Skipped, name)
+			continue
+		}
+		if helper.CompareVersions(current, version) != 0 {
+			plan.Downgrade = append(plan.Downgrade, name+"="+version)
+		}
+	}
+
+	return plan
+}
+
+// checkRollbackVersions проверяет, что прежние версии пакетов ещё доступны в репозиториях.
+// Недоступные пакеты для понижения пропускаются, а удалённые пакеты возвращаются в текущей версии.
+func (a *Actions) checkRollbackVersions(ctx context.Context, plan *rollbackPlan) {
+	var downgrade []string
+	for _, req := range plan.Downgrade {
+		if a.versionAvailable(ctx, req) {
+			downgrade = append(downgrade, req)
+		} else {
+			name, _, _ := strings.Cut(req, "=")
+			plan.Skipped = append(plan.Skipped, name)
+		}
+	}
+	plan.Downgrade = downgrade
+
+	for i, req := range plan.Restore {
+		if name, _, versioned := strings.Cut(req, "="); versioned && !a.versionAvailable(ctx, req) {
+			plan.Restore[i] = name
+		}
+	}
+}
+
+// versionAvailable проверяет симуляцией, что пакет указанной версии можно установить
+func (a *Actions) versionAvailable(ctx context.Context, req string) bool {
+	_, _, _, _, err := a.serviceAptActions.FindPackage(ctx, []string{req}, nil, false, false, false)
+	return err == nil
+}
+
+// previousVersions возвращает установленные версии обновляемых и удаляемых пакетов в формате
+// имя=[эпоха:]версия-релиз, чтобы откат мог вернуть и обновление, изменившее только релиз
+func (a *Actions) previousVersions(ctx context.Context, entry journal.Entry) []string {
+	names := append(append([]string{}, entry.Upgraded...), entry.Removed...)
+	if len(names) == 0 {
+		return nil
+	}
+
+	installed, err := a.serviceAptActions.GetInstalledEVRs(ctx)
+	if err != nil {
+		app.Log.Warning(fmt.Sprintf("failed to get installed versions for journal: %v", err))
+		return nil
+	}
+
+	var previous []string
+	for _, name := range names {
+		if version, ok := installed[name]; ok {
+			previous = append(previous, name+"="+version)
+		}
+	}
+	return previous
+}
@@ -0,0 +1,171 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package rpmdup
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/command"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// queryFormat формат вывода rpm -qa для поиска дубликатов
+const queryFormat = "%{NAME}\t%{ARCH}\t%{INSTALLTIME}\t%{NAME}-%{VERSION}-%{RELEASE}.%{ARCH}\t%{DBINSTANCE}\n"
+
+// multiInstallPrefixes пакеты, которые штатно установлены в нескольких версиях
+var multiInstallPrefixes = []string{"kernel-", "gpg-pubkey"}
+
+// Duplicate пакет, установленный в двух и более версиях
+type Duplicate struct {
+	Name  string   `json:"name"`
+	Keep  string   `json:"keep"`
+	Stale []string `json:"stale"`
+
+	// staleInstances номера заголовков в базе rpm для копий из Stale, в том же порядке
+	staleInstances []uint
+}
+
+// HeaderRemover удаляет записи из базы rpm по номерам экземпляров заголовков
+type HeaderRemover interface {
+	RpmRemoveHeaders(ctx context.Context, instances []uint) error
+}
+
+// Manager ищет и завершает прерванные обновления пакетов: если процесс остановили посреди транзакции,
+// в базе rpm остаются обе версии пакета, а скриптлеты удаления старой не выполнены.
+type Manager struct {
+	runner  command.Runner
+	headers HeaderRemover
+}
+
+// NewManager создаёт менеджер дубликатов rpm.
+func NewManager(runner command.Runner, headers HeaderRemover) *Manager {
+	return &Manager{runner: runner, headers: headers}
+}
+
+// Find возвращает пакеты, установленные в нескольких версиях. Оставляется самая поздно установленная.
+func (m *Manager) Find(ctx context.Context) ([]Duplicate, error) {
+	stdout, stderr, err := m.runner.Run(ctx, []string{"rpm", "-qa", "--queryformat", queryFormat}, command.WithQuiet())
+	if err != nil {
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to query installed packages: %s"), strings.TrimSpace(stderr))
+	}
+	return parseDuplicates(stdout), nil
+}
+
+// Finish удаляет устаревшие версии пакета с выполнением их скриптлетов удаления.
+// Копии с тем же NEVRA, что и у оставляемой или уже удаляемой версии, rpm -e не различает,
+// поэтому их записи удаляются из базы по номеру заголовка, а файлы остаются оставшейся копии.
+func (m *Manager) Finish(ctx context.Context, d Duplicate) error {
+	labels, instances := splitStale(d)
+
+	if len(instances) > 0 {
+		if err := m.headers.RpmRemoveHeaders(ctx, instances); err != nil {
+			return fmt.Errorf(app.TL_(ctx, "Failed to remove stale versions of %s: %s"), d.Name, err.Error())
+		}
+	}
+
+	if len(labels) == 0 {
+		return nil
+	}
+
+	args := append([]string{"rpm", "-e", "--nodeps"}, labels...)
+	if _, stderr, err := m.runner.Run(ctx, args, command.WithQuiet()); err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Failed to remove stale versions of %s: %s"), d.Name, strings.TrimSpace(stderr))
+	}
+	return nil
+}
+
+// splitStale делит устаревшие копии на удаляемые через rpm -e по NEVRA и на повторы NEVRA,
+// которые удаляются по номеру заголовка
+func splitStale(d Duplicate) ([]string, []uint) {
+	seen := map[string]bool{d.Keep: true}
+	var labels []string
+	var instances []uint
+
+	for i, label := range d.Stale {
+		if seen[label] && i < len(d.staleInstances) {
+			instances = append(instances, d.staleInstances[i])
+			continue
+		}
+		seen[label] = true
+		labels = append(labels, label)
+	}
+	return labels, instances
+}
+
+type installedVersion struct {
+	nevra       string
+	installTime int64
+	instance    uint
+}
+
+// parseDuplicates разбирает вывод rpm -qa и группирует версии по имени и архитектуре
+func parseDuplicates(output string) []Duplicate {
+	groups := make(map[string][]installedVersion)
+	names := make(map[string]string)
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "\t")
+		if len(fields) != 5 || isMultiInstall(fields[0]) {
+			continue
+		}
+
+		installTime, _ := strconv.ParseInt(fields[2], 10, 64)
+		instance, err := strconv.ParseUint(fields[4], 10, 32)
+		if err != nil {
+			continue
+		}
+		key := fields[0] + "." + fields[1]
+		groups[key] = append(groups[key], installedVersion{nevra: fields[3], installTime: installTime, instance: uint(instance)})
+		names[key] = fields[0]
+	}
+
+	var result []Duplicate
+	for key, versions := range groups {
+		if len(versions) < 2 {
+			continue
+		}
+
+		sort.SliceStable(versions, func(i, j int) bool {
+			return versions[i].installTime > versions[j].installTime
+		})
+
+		d := Duplicate{Name: names[key], Keep: versions[0].nevra}
+		for _, v := range versions[1:] {
+			d.Stale = append(d.Stale, v.nevra)
+			d.staleInstances = append(d.staleInstances, v.instance)
+		}
+		result = append(result, d)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Keep < result[j].Keep
+	})
+	return result
+}
+
+// isMultiInstall проверяет, допускает ли пакет несколько установленных версий
+func isMultiInstall(name string) bool {
+	for _, prefix := range multiInstallPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
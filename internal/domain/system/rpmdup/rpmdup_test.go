@@ -0,0 +1,139 @@
+package rpmdup
+
+import (
+	"apm/internal/common/command"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type mockRunner struct {
+	stdout string
+	err    error
+	calls  [][]string
+}
+
+func (m *mockRunner) Run(_ context.Context, args []string, _ ...command.Option) (string, string, error) {
+	m.calls = append(m.calls, args)
+	return m.stdout, "", m.err
+}
+
+type mockHeaders struct {
+	removed [][]uint
+}
+
+func (m *mockHeaders) RpmRemoveHeaders(_ context.Context, instances []uint) error {
+	m.removed = append(m.removed, instances)
+	return nil
+}
+
+func TestFind(t *testing.T) {
+	runner := &mockRunner{stdout: "" +
+		"glibc-core\tx86_64\t1700000000\tglibc-core-2.38-alt1.x86_64\t101\n" +
+		"glibc-core\tx86_64\t1710000000\tglibc-core-2.39-alt1.x86_64\t102\n" +
+		"i586-glibc-core\ti586\t1700000000\ti586-glibc-core-2.38-alt1.i586\t103\n" +
+		"bash\tx86_64\t1600000000\tbash-5.2-alt1.x86_64\t104\n" +
+		"kernel-image-std-def\tx86_64\t1600000000\tkernel-image-std-def-6.1.1-alt1.x86_64\t105\n" +
+		"kernel-image-std-def\tx86_64\t1700000000\tkernel-image-std-def-6.1.2-alt1.x86_64\t106\n" +
+		"gpg-pubkey\t(none)\t1600000000\tgpg-pubkey-1-1.(none)\t107\n" +
+		"gpg-pubkey\t(none)\t1600000001\tgpg-pubkey-2-1.(none)\t108\n" +
+		"vim-console\tx86_64\t1600000000\tvim-console-9.1-alt1.x86_64\t109\n" +
+		"vim-console\tx86_64\t1600000005\tvim-console-9.1-alt1.x86_64\t110\n" +
+		"broken line\n"}
+
+	dups, err := NewManager(runner, &mockHeaders{}).Find(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Duplicate{{
+		Name:           "glibc-core",
+		Keep:           "glibc-core-2.39-alt1.x86_64",
+		Stale:          []string{"glibc-core-2.38-alt1.x86_64"},
+		staleInstances: []uint{101},
+	}, {
+		Name:           "vim-console",
+		Keep:           "vim-console-9.1-alt1.x86_64",
+		Stale:          []string{"vim-console-9.1-alt1.x86_64"},
+		staleInstances: []uint{109},
+	}}
+	if !reflect.DeepEqual(dups, expected) {
+		t.Errorf("expected %+v, got %+v", expected, dups)
+	}
+}
+
+func TestFind_Error(t *testing.T) {
+	if _, err := NewManager(&mockRunner{err: errors.New("rpm failed")}, &mockHeaders{}).Find(context.Background()); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestFinish(t *testing.T) {
+	runner := &mockRunner{}
+	headers := &mockHeaders{}
+	m := NewManager(runner, headers)
+
+	if err := m.Finish(context.Background(), Duplicate{Name: "bash"}); err != nil || len(runner.calls) != 0 || len(headers.removed) != 0 {
+		t.Fatalf("nothing to finish must not call rpm, calls: %v", runner.calls)
+	}
+
+	err := m.Finish(context.Background(), Duplicate{
+		Name:           "glibc-core",
+		Keep:           "glibc-core-2.39-alt1.x86_64",
+		Stale:          []string{"glibc-core-2.38-alt1.x86_64"},
+		staleInstances: []uint{101},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"rpm", "-e", "--nodeps", "glibc-core-2.38-alt1.x86_64"}
+	if len(runner.calls) != 1 || !reflect.DeepEqual(runner.calls[0], expected) {
+		t.Errorf("unexpected calls: %v", runner.calls)
+	}
+	if len(headers.removed) != 0 {
+		t.Errorf("distinct versions must be removed by rpm -e, removed headers: %v", headers.removed)
+	}
+}
+
+func TestFinishSameNEVRA(t *testing.T) {
+	runner := &mockRunner{}
+	headers := &mockHeaders{}
+	m := NewManager(runner, headers)
+
+	// Копия с тем же NEVRA, что и оставляемая, удаляется только по номеру заголовка
+	err := m.Finish(context.Background(), Duplicate{
+		Name:           "vim-console",
+		Keep:           "vim-console-9.1-alt1.x86_64",
+		Stale:          []string{"vim-console-9.1-alt1.x86_64"},
+		staleInstances: []uint{109},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runner.calls) != 0 {
+		t.Errorf("rpm -e must not be called for the kept NEVRA, calls: %v", runner.calls)
+	}
+	if !reflect.DeepEqual(headers.removed, [][]uint{{109}}) {
+		t.Errorf("unexpected removed headers: %v", headers.removed)
+	}
+
+	// Из двух одинаковых устаревших копий одна удаляется по заголовку, вторая через rpm -e
+	runner, headers = &mockRunner{}, &mockHeaders{}
+	err = NewManager(runner, headers).Finish(context.Background(), Duplicate{
+		Name:           "glibc-core",
+		Keep:           "glibc-core-2.39-alt1.x86_64",
+		Stale:          []string{"glibc-core-2.38-alt1.x86_64", "glibc-core-2.38-alt1.x86_64"},
+		staleInstances: []uint{101, 103},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(headers.removed, [][]uint{{103}}) {
+		t.Errorf("unexpected removed headers: %v", headers.removed)
+	}
+	expected := []string{"rpm", "-e", "--nodeps", "glibc-core-2.38-alt1.x86_64"}
+	if len(runner.calls) != 1 || !reflect.DeepEqual(runner.calls[0], expected) {
+		t.Errorf("unexpected calls: %v", runner.calls)
+	}
+}
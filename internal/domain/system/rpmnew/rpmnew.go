@@ -0,0 +1,179 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package rpmnew
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/command"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// ActionKeep оставить текущий файл, удалить .rpmnew
+	ActionKeep = "keep"
+	// ActionReplace заменить файл новой версией, старую сохранить как .rpmsave
+	ActionReplace = "replace"
+	// ActionMerge объединить изменения во внешней программе
+	ActionMerge = "merge"
+	// ActionSkip отложить решение, .rpmnew остаётся на месте
+	ActionSkip = "skip"
+
+	// Suffix расширение новых версий конфигурационных файлов
+	Suffix = ".rpmnew"
+	// SaveSuffix расширение сохранённой копии при замене
+	SaveSuffix = ".rpmsave"
+
+	// defaultMergeProgram программа слияния по умолчанию, переопределяется через MERGEPROG
+	defaultMergeProgram = "vimdiff"
+)
+
+// File описывает конфигурационный файл, для которого пакет положил .rpmnew
+type File struct {
+	Path string `json:"path"`
+	New  string `json:"new"`
+}
+
+// Decision описывает принятое решение по файлу
+type Decision struct {
+	Path   string `json:"path"`
+	Action string `json:"action"`
+}
+
+// Manager ищет и разрешает .rpmnew файлы
+type Manager struct {
+	runner command.Runner
+	root   string
+}
+
+// NewManager создаёт менеджер .rpmnew файлов в каталоге root.
+func NewManager(runner command.Runner, root string) *Manager {
+	return &Manager{
+		runner: runner,
+		root:   root,
+	}
+}
+
+// Snapshot возвращает множество .rpmnew файлов, существующих до транзакции
+func (m *Manager) Snapshot() map[string]struct{} {
+	result := make(map[string]struct{})
+	_ = filepath.WalkDir(m.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() && strings.HasSuffix(path, Suffix) {
+			result[path] = struct{}{}
+		}
+		return nil
+	})
+	return result
+}
+
+// Collect возвращает .rpmnew файлы, появившиеся после снимка before
+func (m *Manager) Collect(before map[string]struct{}) []File {
+	var files []File
+	for path := range m.Snapshot() {
+		if _, ok := before[path]; ok {
+			continue
+		}
+		files = append(files, File{
+			Path: strings.TrimSuffix(path, Suffix),
+			New:  path,
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+	return files
+}
+
+// Diff возвращает unified diff между текущим файлом и его новой версией
+func (m *Manager) Diff(ctx context.Context, file File) (string, error) {
+	stdout, stderr, err := m.runner.Run(ctx, []string{"diff", "-u", file.Path, file.New}, command.WithQuiet())
+	// diff завершается с кодом 1, если файлы различаются
+	if err != nil && stdout == "" {
+		return "", fmt.Errorf(app.TL_(ctx, "Failed to compare %s: %s"), file.Path, strings.TrimSpace(stderr))
+	}
+	return stdout, nil
+}
+
+// Apply применяет решение к файлу
+func (m *Manager) Apply(_ context.Context, file File, action string) error {
+	switch action {
+	case ActionSkip:
+		return nil
+	case ActionKeep:
+		return removeNew(file)
+	case ActionReplace:
+		if err := os.Rename(file.Path, file.Path+SaveSuffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf(app.T_("Failed to save %s: %v"), file.Path, err)
+		}
+		if err := os.Rename(file.New, file.Path); err != nil {
+			return fmt.Errorf(app.T_("Failed to replace %s: %v"), file.Path, err)
+		}
+		return nil
+	case ActionMerge:
+		if err := runMergeProgram(file); err != nil {
+			return err
+		}
+		return removeNew(file)
+	default:
+		return fmt.Errorf(app.T_("Unknown action for %s: %s"), file.Path, action)
+	}
+}
+
+// runMergeProgram запускает программу слияния с выводом в текущий терминал
+func runMergeProgram(file File) error {
+	program := os.Getenv("MERGEPROG")
+	if program == "" {
+		program = defaultMergeProgram
+	}
+
+	args := strings.Fields(program)
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return fmt.Errorf(app.T_("Merge program %s not found, set MERGEPROG"), args[0])
+	}
+
+	cmd := exec.Command(path, append(args[1:], file.Path, file.New)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err = cmd.Run(); err != nil {
+		return fmt.Errorf(app.T_("Merge of %s failed: %v"), file.Path, err)
+	}
+	return nil
+}
+
+// removeNew удаляет .rpmnew файл
+func removeNew(file File) error {
+	if err := os.Remove(file.New); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf(app.T_("Failed to remove %s: %v"), file.New, err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package rpmnew
+
+import (
+	"apm/internal/common/command"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type mockRunner struct {
+	stdout string
+	err    error
+}
+
+func (m *mockRunner) Run(_ context.Context, _ []string, _ ...command.Option) (string, string, error) {
+	return m.stdout, "", m.err
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestCollect(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "old.conf.rpmnew"), "old")
+	m := NewManager(&mockRunner{}, root)
+
+	before := m.Snapshot()
+	writeFile(t, filepath.Join(root, "ssh", "sshd_config.rpmnew"), "new")
+	writeFile(t, filepath.Join(root, "a.conf.rpmnew"), "new")
+	writeFile(t, filepath.Join(root, "plain.conf"), "plain")
+
+	files := m.Collect(before)
+	if len(files) != 2 {
+		t.Fatalf("expected 2 new files, got %+v", files)
+	}
+	if files[0].Path != filepath.Join(root, "a.conf") || files[1].New != filepath.Join(root, "ssh", "sshd_config.rpmnew") {
+		t.Errorf("unexpected files: %+v", files)
+	}
+}
+
+func TestApply(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("keep removes new version", func(t *testing.T) {
+		root := t.TempDir()
+		file := File{Path: filepath.Join(root, "a.conf"), New: filepath.Join(root, "a.conf.rpmnew")}
+		writeFile(t, file.Path, "current")
+		writeFile(t, file.New, "new")
+
+		if err := NewManager(&mockRunner{}, root).Apply(ctx, file, ActionKeep); err != nil {
+			t.Fatal(err)
+		}
+		if readFile(t, file.Path) != "current" {
+			t.Error("current file must be kept")
+		}
+		if _, err := os.Stat(file.New); !os.IsNotExist(err) {
+			t.Error(".rpmnew must be removed")
+		}
+	})
+
+	t.Run("replace saves current as rpmsave", func(t *testing.T) {
+		root := t.TempDir()
+		file := File{Path: filepath.Join(root, "a.conf"), New: filepath.Join(root, "a.conf.rpmnew")}
+		writeFile(t, file.Path, "current")
+		writeFile(t, file.New, "new")
+
+		if err := NewManager(&mockRunner{}, root).Apply(ctx, file, ActionReplace); err != nil {
+			t.Fatal(err)
+		}
+		if readFile(t, file.Path) != "new" || readFile(t, file.Path+SaveSuffix) != "current" {
+			t.Error("file must be replaced and the old version saved")
+		}
+	})
+
+	t.Run("skip leaves files untouched", func(t *testing.T) {
+		root := t.TempDir()
+		file := File{Path: filepath.Join(root, "a.conf"), New: filepath.Join(root, "a.conf.rpmnew")}
+		writeFile(t, file.New, "new")
+
+		if err := NewManager(&mockRunner{}, root).Apply(ctx, file, ActionSkip); err != nil {
+			t.Fatal(err)
+		}
+		if readFile(t, file.New) != "new" {
+			t.Error(".rpmnew must stay in place")
+		}
+	})
+
+	t.Run("unknown action", func(t *testing.T) {
+		if err := NewManager(&mockRunner{}, t.TempDir()).Apply(ctx, File{}, "drop"); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestDiff(t *testing.T) {
+	file := File{Path: "/etc/a.conf", New: "/etc/a.conf.rpmnew"}
+
+	diff, err := NewManager(&mockRunner{stdout: "-old\n+new\n", err: errors.New("exit status 1")}, "/etc").Diff(context.Background(), file)
+	if err != nil || diff != "-old\n+new\n" {
+		t.Errorf("differences must not be an error, got %q, %v", diff, err)
+	}
+
+	if _, err = NewManager(&mockRunner{err: errors.New("exit status 2")}, "/etc").Diff(context.Background(), file); err == nil {
+		t.Error("expected error when diff fails")
+	}
+}
@@ -0,0 +1,307 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package schedule
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/command"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// ModeShutdown применение при выключении или перезагрузке
+	ModeShutdown = "shutdown"
+	// ModeIdle применение, когда все сеансы пользователей простаивают
+	ModeIdle = "idle"
+	// ModeTime применение в указанное время HH:MM
+	ModeTime = "time"
+
+	// UnitName имя transient-юнита systemd для отложенного применения
+	UnitName = "apm-scheduled-apply"
+	// StateFileName имя файла расписания в каталоге состояния apm
+	StateFileName = "scheduled-apply.json"
+
+	// idleCheckCalendar как часто проверять простой системы в режиме idle
+	idleCheckCalendar = "*:0/5"
+)
+
+// Schedule описывает отложенное применение образа
+type Schedule struct {
+	Mode      string `json:"mode"`
+	At        string `json:"at,omitempty"`
+	Pull      bool   `json:"pull"`
+	HostCache bool   `json:"hostCache"`
+	Config    string `json:"config,omitempty"`
+	Workdir   string `json:"workdir,omitempty"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// Manager управляет отложенным применением образа через transient-юниты systemd
+type Manager struct {
+	runner     command.Runner
+	stateFile  string
+	executable string
+}
+
+// NewManager создаёт менеджер отложенного применения.
+func NewManager(runner command.Runner, stateFile string) *Manager {
+	executable, err := os.Executable()
+	if err != nil {
+		executable = "apm"
+	}
+	return &Manager{
+		runner:     runner,
+		stateFile:  stateFile,
+		executable: executable,
+	}
+}
+
+// syncStateMutex защищает операции с файлом расписания.
+var syncStateMutex sync.Mutex
+
+// ParseAt разбирает значение --at: shutdown, idle или время HH:MM
+func ParseAt(at string) (mode string, clock string, err error) {
+	at = strings.ToLower(strings.TrimSpace(at))
+	switch at {
+	case ModeShutdown, ModeIdle:
+		return at, "", nil
+	}
+
+	parsed, err := time.Parse("15:04", at)
+	if err != nil {
+		return "", "", fmt.Errorf(app.T_("Invalid schedule %q. Allowed values: shutdown, idle or time in HH:MM format"), at)
+	}
+	return ModeTime, parsed.Format("15:04"), nil
+}
+
+// Create сохраняет расписание и регистрирует юнит systemd, который запустит применение.
+func (m *Manager) Create(ctx context.Context, s Schedule) error {
+	existing, err := m.Load()
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf(app.TL_(ctx, "Image apply is already scheduled (%s). Cancel it with --cancel-scheduled"), existing.Describe())
+	}
+
+	s.CreatedAt = time.Now().Format(time.RFC3339)
+	if err = m.save(&s); err != nil {
+		return err
+	}
+
+	if stdout, stderr, errRun := m.runner.Run(ctx, m.unitArgs(s), command.WithQuiet()); errRun != nil {
+		_ = m.remove()
+		return fmt.Errorf(app.TL_(ctx, "Failed to register scheduled apply: %s"), strings.TrimSpace(stdout+stderr))
+	}
+
+	return nil
+}
+
+// Load возвращает текущее расписание или nil, если применение не запланировано.
+func (m *Manager) Load() (*Schedule, error) {
+	syncStateMutex.Lock()
+	defer syncStateMutex.Unlock()
+
+	file, err := os.OpenFile(m.stateFile, os.O_RDONLY|syscall.O_NOFOLLOW, 0)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	if err = checkStateFile(file); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var s Schedule
+	if err = json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Cancel отменяет запланированное применение. Файл расписания удаляется до остановки юнита,
+// поэтому ExecStop в режиме shutdown ничего не применит.
+func (m *Manager) Cancel(ctx context.Context) (*Schedule, error) {
+	existing, err := m.Load()
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, nil
+	}
+
+	if err = m.remove(); err != nil {
+		return nil, err
+	}
+	m.stopUnits(ctx)
+
+	return existing, nil
+}
+
+// Finish снимает расписание перед запуском применения, чтобы таймер не сработал повторно.
+func (m *Manager) Finish(ctx context.Context) error {
+	if err := m.remove(); err != nil {
+		return err
+	}
+	_, _, _ = m.runner.Run(ctx, []string{"systemctl", "stop", UnitName + ".timer"}, command.WithQuiet())
+	return nil
+}
+
+// IsIdle проверяет, что все сеансы logind простаивают.
+func (m *Manager) IsIdle(ctx context.Context) bool {
+	stdout, _, err := m.runner.Run(ctx, []string{"loginctl", "list-sessions", "--no-legend"}, command.WithQuiet())
+	if err != nil {
+		return false
+	}
+
+	for _, line := range strings.Split(stdout, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		hint, _, errHint := m.runner.Run(ctx, []string{"loginctl", "show-session", fields[0], "-p", "IdleHint", "--value"}, command.WithQuiet())
+		if errHint != nil || strings.TrimSpace(hint) != "yes" {
+			return false
+		}
+	}
+	return true
+}
+
+// Notify предупреждает пользователей о начале применения.
+func (m *Manager) Notify(ctx context.Context, message string) {
+	_, _, _ = m.runner.Run(ctx, []string{"wall", message}, command.WithQuiet())
+}
+
+// Describe возвращает человекочитаемое описание момента применения
+func (s *Schedule) Describe() string {
+	switch s.Mode {
+	case ModeShutdown:
+		return app.T_("at shutdown")
+	case ModeIdle:
+		return app.T_("when the system is idle")
+	default:
+		return fmt.Sprintf(app.T_("at %s"), s.At)
+	}
+}
+
+// unitArgs формирует команду systemd-run для расписания
+func (m *Manager) unitArgs(s Schedule) []string {
+	runScheduled := []string{m.executable, "system", "image", "apply", "--run-scheduled"}
+	args := []string{"systemd-run", "--unit", UnitName, "--description", "APM scheduled image apply"}
+
+	if s.Mode == ModeShutdown {
+		// Сервис «висит» запущенным, применение выполняет ExecStop при остановке системы
+		args = append(args,
+			"--property=Type=oneshot",
+			"--property=RemainAfterExit=yes",
+			"--property=TimeoutStopSec=infinity",
+			"--property=After=network-online.target",
+			"--property=ExecStop="+strings.Join(runScheduled, " "),
+			"/bin/true",
+		)
+		return args
+	}
+
+	calendar := idleCheckCalendar
+	if s.Mode == ModeTime {
+		calendar = "*-*-* " + s.At + ":00"
+	}
+	args = append(args, "--on-calendar", calendar, "--timer-property=AccuracySec=1min",
+		"systemd-inhibit", "--what=shutdown:sleep", "--who=apm", "--why=Applying system image")
+	return append(args, runScheduled...)
+}
+
+// stopUnits останавливает таймер и сервис расписания
+func (m *Manager) stopUnits(ctx context.Context) {
+	_, _, _ = m.runner.Run(ctx, []string{"systemctl", "stop", UnitName + ".timer", UnitName + ".service"}, command.WithQuiet())
+	_, _, _ = m.runner.Run(ctx, []string{"systemctl", "reset-failed", UnitName + ".service"}, command.WithQuiet())
+}
+
+// save записывает расписание в файл, доступный только владельцу. Символические ссылки
+// не разыменовываются, чтобы root не записал расписание в чужой файл.
+func (m *Manager) save(s *Schedule) error {
+	syncStateMutex.Lock()
+	defer syncStateMutex.Unlock()
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = app.EnsureDir(filepath.Dir(m.stateFile)); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(m.stateFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC|syscall.O_NOFOLLOW, 0600)
+	if err != nil {
+		return err
+	}
+	if err = checkStateFile(file); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err = file.Chmod(0600); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if _, err = file.Write(data); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// checkStateFile проверяет, что файл расписания принадлежит текущему пользователю
+// и недоступен для записи другим
+func checkStateFile(file *os.File) error {
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !info.Mode().IsRegular() || (ok && int(stat.Uid) != os.Geteuid()) || info.Mode().Perm()&0022 != 0 {
+		return fmt.Errorf(app.T_("Schedule file %s has unsafe owner or permissions"), file.Name())
+	}
+	return nil
+}
+
+// remove удаляет файл расписания
+func (m *Manager) remove() error {
+	syncStateMutex.Lock()
+	defer syncStateMutex.Unlock()
+
+	if err := os.Remove(m.stateFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package schedule
+
+import (
+	"apm/internal/common/command"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type mockRunner struct {
+	calls   [][]string
+	runFunc func(args []string) (string, string, error)
+}
+
+func (m *mockRunner) Run(_ context.Context, args []string, _ ...command.Option) (string, string, error) {
+	m.calls = append(m.calls, args)
+	if m.runFunc != nil {
+		return m.runFunc(args)
+	}
+	return "", "", nil
+}
+
+func newTestManager(t *testing.T, runner *mockRunner) *Manager {
+	t.Helper()
+	m := NewManager(runner, filepath.Join(t.TempDir(), "schedule.json"))
+	m.executable = "/usr/bin/apm"
+	return m
+}
+
+func TestParseAt(t *testing.T) {
+	tests := []struct {
+		in       string
+		wantMode string
+		wantAt   string
+		wantErr  bool
+	}{
+		{in: "shutdown", wantMode: ModeShutdown},
+		{in: " IDLE ", wantMode: ModeIdle},
+		{in: "03:30", wantMode: ModeTime, wantAt: "03:30"},
+		{in: "3:05", wantMode: ModeTime, wantAt: "03:05"},
+		{in: "25:00", wantErr: true},
+		{in: "tomorrow", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			mode, at, err := ParseAt(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error for %q", tt.in)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if mode != tt.wantMode || at != tt.wantAt {
+				t.Errorf("got (%q, %q), want (%q, %q)", mode, at, tt.wantMode, tt.wantAt)
+			}
+		})
+	}
+}
+
+func TestCreateLoadCancel(t *testing.T) {
+	runner := &mockRunner{}
+	m := newTestManager(t, runner)
+	ctx := context.Background()
+
+	if s, err := m.Load(); err != nil || s != nil {
+		t.Fatalf("expected empty schedule, got %v, %v", s, err)
+	}
+
+	if err := m.Create(ctx, Schedule{Mode: ModeTime, At: "03:30", Pull: true}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := m.Create(ctx, Schedule{Mode: ModeIdle}); err == nil {
+		t.Fatal("expected error for a second schedule")
+	}
+
+	s, err := m.Load()
+	if err != nil || s == nil {
+		t.Fatalf("load: %v, %v", s, err)
+	}
+	if s.Mode != ModeTime || s.At != "03:30" || !s.Pull || s.CreatedAt == "" {
+		t.Errorf("unexpected schedule: %+v", s)
+	}
+
+	canceled, err := m.Cancel(ctx)
+	if err != nil || canceled == nil {
+		t.Fatalf("cancel: %v, %v", canceled, err)
+	}
+	if s, _ = m.Load(); s != nil {
+		t.Errorf("schedule must be removed after cancel, got %+v", s)
+	}
+}
+
+func TestCreateRollsBackOnFailure(t *testing.T) {
+	runner := &mockRunner{runFunc: func(args []string) (string, string, error) {
+		return "", "unit exists", errors.New("exit status 1")
+	}}
+	m := newTestManager(t, runner)
+
+	if err := m.Create(context.Background(), Schedule{Mode: ModeShutdown}); err == nil {
+		t.Fatal("expected error")
+	}
+	if s, _ := m.Load(); s != nil {
+		t.Errorf("state must be removed on failure, got %+v", s)
+	}
+}
+
+func TestStateFileIsPrivate(t *testing.T) {
+	runner := &mockRunner{}
+	m := newTestManager(t, runner)
+
+	if err := m.Create(context.Background(), Schedule{Mode: ModeIdle}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	info, err := os.Stat(m.stateFile)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("expected mode 0600, got %o", perm)
+	}
+}
+
+func TestStateFileRejectsSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "planted.json")
+	if err := os.WriteFile(target, []byte(`{"mode":"idle","config":"/tmp/evil.yml"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager(&mockRunner{}, filepath.Join(dir, "schedule.json"))
+	if err := os.Symlink(target, m.stateFile); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.Load(); err == nil {
+		t.Error("expected error for symlinked schedule file")
+	}
+	if err := m.Create(context.Background(), Schedule{Mode: ModeIdle}); err == nil {
+		t.Error("expected error when writing through a symlink")
+	}
+}
+
+func TestUnitArgs(t *testing.T) {
+	m := newTestManager(t, &mockRunner{})
+
+	shutdown := strings.Join(m.unitArgs(Schedule{Mode: ModeShutdown}), " ")
+	if !strings.Contains(shutdown, "--property=ExecStop=/usr/bin/apm system image apply --run-scheduled") {
+		t.Errorf("shutdown unit must apply on stop: %s", shutdown)
+	}
+	if strings.Contains(shutdown, "--on-calendar") {
+		t.Errorf("shutdown unit must not use a timer: %s", shutdown)
+	}
+
+	timed := strings.Join(m.unitArgs(Schedule{Mode: ModeTime, At: "03:30"}), " ")
+	if !strings.Contains(timed, "--on-calendar *-*-* 03:30:00") {
+		t.Errorf("unexpected calendar: %s", timed)
+	}
+
+	idle := strings.Join(m.unitArgs(Schedule{Mode: ModeIdle}), " ")
+	if !strings.Contains(idle, "--on-calendar "+idleCheckCalendar) {
+		t.Errorf("unexpected calendar: %s", idle)
+	}
+}
+
+func TestIsIdle(t *testing.T) {
+	runner := &mockRunner{runFunc: func(args []string) (string, string, error) {
+		if args[1] == "list-sessions" {
+			return "2 1000 user seat0 tty2\n5 1001 other - pts/0\n", "", nil
+		}
+		if args[2] == "5" {
+			return "no\n", "", nil
+		}
+		return "yes\n", "", nil
+	}}
+	m := newTestManager(t, runner)
+
+	if m.IsIdle(context.Background()) {
+		t.Error("expected busy system when one session is active")
+	}
+}
@@ -0,0 +1,211 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/journal"
+	"apm/internal/common/reply"
+	"apm/internal/domain/system/dialog"
+	"apm/internal/domain/system/units"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	// SelfUpdateChannelStable обновление из подключённых репозиториев
+	SelfUpdateChannelStable = "stable"
+	// SelfUpdateChannelTesting обновление из тестового источника (ветка или задача)
+	SelfUpdateChannelTesting = "testing"
+
+	selfPackageName    = "apm"
+	selfSystemdService = "apm.service"
+)
+
+// resolveSelfUpdateSource возвращает дополнительный источник пакетов для канала обновления.
+// Пустая строка означает использование уже подключённых репозиториев.
+func resolveSelfUpdateSource(ctx context.Context, channel, task, testingSource string) (string, error) {
+	if task = strings.TrimSpace(task); task != "" {
+		return task, nil
+	}
+
+	switch channel {
+	case SelfUpdateChannelStable:
+		return "", nil
+	case SelfUpdateChannelTesting:
+		if testingSource == "" {
+			return "", errors.New(app.TL_(ctx, "Testing channel source is not configured"))
+		}
+		return testingSource, nil
+	default:
+		return "", fmt.Errorf(app.TL_(ctx, "Unknown update channel: %s. Allowed values: stable, testing"), channel)
+	}
+}
+
+// SelfUpdate проверяет наличие новой версии apm и обновляет её.
+// Источник канала testing или задачи подключается только во временной конфигурации APT:
+// системные sources.list и база пакетов при проверке не меняются.
+func (a *Actions) SelfUpdate(ctx context.Context, channel, task string, checkOnly bool, confirm bool) (*SelfUpdateResponse, error) {
+	cfg := a.appConfig.ConfigManager.GetConfig()
+	if cfg.IsAtomic {
+		return nil, apmerr.New(apmerr.ErrorTypeImage, errors.New(app.TL_(ctx, "On an atomic system apm is updated together with the image. Run: apm s image update")))
+	}
+
+	channel = strings.ToLower(strings.TrimSpace(channel))
+	if channel == "" {
+		channel = cfg.SelfUpdateChannel
+	}
+
+	source, err := resolveSelfUpdateSource(ctx, channel, task, cfg.SelfUpdateTestingSource)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, err)
+	}
+
+	// Блокировка нужна и для проверки: песочница временно меняет конфигурацию APT сервиса
+	ctx, release, err := a.operationLock.Acquire(ctx, journal.ActionUpgrade)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	restoreConfig := func() {}
+	if source != "" {
+		restore, errSandbox := a.useSelfUpdateSandbox(ctx, source)
+		if errSandbox != nil {
+			return nil, errSandbox
+		}
+		restoreConfig = sync.OnceFunc(restore)
+		defer restoreConfig()
+	}
+
+	if err = a.serviceAptActions.AptUpdate(ctx); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeApt, err)
+	}
+
+	resp := &SelfUpdateResponse{
+		Channel:        channel,
+		Source:         source,
+		CurrentVersion: a.appConfig.ConfigManager.GetParsedVersion().Value,
+	}
+
+	candidate, err := a.serviceAptActions.GetInfo(ctx, selfPackageName)
+	if err != nil || candidate == nil || candidate.Version == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, errors.New(app.TL_(ctx, "Package apm was not found in the configured repositories")))
+	}
+	if installed, errInstalled := a.serviceAptActions.GetInstalledPackages(ctx); errInstalled == nil && installed[selfPackageName] != "" {
+		resp.CurrentVersion = installed[selfPackageName]
+	}
+	resp.AvailableVersion = candidate.Version
+	resp.Changelog = candidate.Changelog
+
+	install, remove, packagesInfo, packageParse, err := a.serviceAptActions.FindPackage(ctx, []string{selfPackageName}, nil, false, false, false)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeApt, err)
+	}
+
+	if packageParse.UpgradedCount == 0 && packageParse.NewInstalledCount == 0 {
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, fmt.Errorf(app.TL_(ctx, "apm is already up to date (%s)"), resp.CurrentVersion))
+	}
+
+	resp.Info = packageParse
+	if checkOnly {
+		resp.Message = fmt.Sprintf(app.TL_(ctx, "A new version of apm is available: %s"), resp.AvailableVersion)
+		return resp, nil
+	}
+
+	if !confirm {
+		reply.StopSpinner(a.appConfig)
+		dialogStatus, errDialog := dialog.NewDialog(ctx, a.appConfig, packagesInfo, *packageParse, dialog.ActionUpgrade)
+		if errDialog != nil {
+			return nil, errDialog
+		}
+		if !dialogStatus {
+			return nil, apmerr.New(apmerr.ErrorTypeCanceled, errors.New(app.TL_(ctx, "Cancel dialog")))
+		}
+		reply.CreateSpinner(a.appConfig)
+	}
+
+	// Работающий бинарник заменяется rpm через rename, поэтому текущий процесс продолжает
+	// использовать старый inode и корректно завершает операцию.
+	if err = a.serviceAptActions.CombineInstallRemovePackages(ctx, install, remove, false, false, false); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeApt, err)
+	}
+	resp.Updated = true
+
+	// База пакетов обновляется уже по системной конфигурации APT, без временного источника
+	restoreConfig()
+	if err = a.updateAllPackagesDB(ctx); err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+
+	resp.RestartedServices = a.serviceRestart.RestartSelf(ctx, selfSystemdService, selfSessionCommand())
+	resp.Message = fmt.Sprintf(app.TL_(ctx, "apm updated from %s to %s"), resp.CurrentVersion, resp.AvailableVersion)
+
+	return resp, nil
+}
+
+// CheckSelfUpdate показывает изменения пакетов, которые выполнит самообновление, не устанавливая их.
+func (a *Actions) CheckSelfUpdate(ctx context.Context, channel, task string) (*SelfUpdateResponse, error) {
+	resp, err := a.SelfUpdate(ctx, channel, task, true, true)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Message = app.TL_(ctx, "Simulation results")
+	return resp, nil
+}
+
+// useSelfUpdateSandbox подключает источник самообновления во временной конфигурации APT.
+// Возвращаемая функция восстанавливает прежнюю конфигурацию и удаляет песочницу.
+func (a *Actions) useSelfUpdateSandbox(ctx context.Context, source string) (func(), error) {
+	sandbox, err := a.serviceRepos.CreateSandbox(ctx, []string{source})
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeRepository, fmt.Errorf("%s: %w", app.TL_(ctx, "Failed to add update source"), err))
+	}
+
+	previous := a.serviceAptActions.GetAptConfigOverrides()
+	overrides := make(map[string]string, len(previous)+len(sandbox.Overrides))
+	for k, v := range previous {
+		overrides[k] = v
+	}
+	for k, v := range sandbox.Overrides {
+		overrides[k] = v
+	}
+	a.serviceAptActions.SetAptConfigOverrides(overrides)
+
+	return func() {
+		a.serviceAptActions.SetAptConfigOverrides(previous)
+		if errClean := sandbox.Cleanup(); errClean != nil {
+			app.Log.Warning(fmt.Sprintf("failed to remove sandbox %s: %v", sandbox.Dir, errClean))
+		}
+	}, nil
+}
+
+// selfSessionCommand возвращает командную строку сессионного D-Bus сервиса apm
+func selfSessionCommand() []string {
+	executable, err := os.Executable()
+	if err != nil {
+		app.Log.Debugf("failed to resolve apm executable: %v", err)
+		return nil
+	}
+	return []string{executable, units.DBusSession}
+}
@@ -0,0 +1,163 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/domain/system/units"
+	"context"
+	"fmt"
+)
+
+// httpUnitOptions возвращает адреса сокет-юнита HTTP API из конфигурации
+func httpUnitOptions(cfg *app.Configuration) units.HTTPOptions {
+	opts := units.HTTPOptions{
+		Listen:     cfg.HTTPListen,
+		SocketPath: cfg.HTTPSocket.Path,
+		SocketMode: cfg.HTTPSocket.Mode,
+		Group:      cfg.HTTPSocket.Group,
+	}
+	if opts.Listen == "" {
+		opts.Listen = units.DefaultHTTPListen
+	}
+	if cfg.HTTPSocket.SocketOnly && opts.SocketPath != "" {
+		opts.Listen = ""
+	}
+	return opts
+}
+
+// autoUpgradeUnit возвращает состояние таймера автоматического обновления в виде состояния службы
+func (a *Actions) autoUpgradeUnit(ctx context.Context) (units.Status, error) {
+	timer, err := a.serviceAutoUpgrade.Status(ctx)
+	if err != nil {
+		return units.Status{}, apmerr.New(apmerr.ErrorTypePermission, err)
+	}
+	return units.Status{
+		Name:       units.AutoUpgrade,
+		Unit:       "apm-auto-upgrade.timer",
+		Scope:      units.ScopeSystem,
+		Activation: units.ActivationTimer,
+		Installed:  timer.OnCalendar != "",
+		Enabled:    timer.Enabled,
+		Active:     timer.Enabled,
+	}, nil
+}
+
+// ServiceInstall записывает юниты systemd службы apm, не включая её. Таймер автоматического
+// обновления записывается и включается одновременно.
+func (a *Actions) ServiceInstall(ctx context.Context, name string) (*ServiceResponse, error) {
+	if name == units.AutoUpgrade {
+		return a.serviceEnableAutoUpgrade(ctx)
+	}
+
+	status, err := a.serviceUnits.Install(ctx, name)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, err)
+	}
+
+	return &ServiceResponse{
+		Message: fmt.Sprintf(app.TL_(ctx, "Units of service %s installed"), name),
+		Service: status,
+	}, nil
+}
+
+// ServiceEnable включает службу apm, при необходимости записывая её юниты.
+func (a *Actions) ServiceEnable(ctx context.Context, name string) (*ServiceResponse, error) {
+	if name == units.AutoUpgrade {
+		return a.serviceEnableAutoUpgrade(ctx)
+	}
+
+	status, err := a.serviceUnits.Enable(ctx, name)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, err)
+	}
+
+	return &ServiceResponse{
+		Message: fmt.Sprintf(app.TL_(ctx, "Service %s enabled"), name),
+		Service: status,
+	}, nil
+}
+
+// serviceEnableAutoUpgrade включает таймер автоматического обновления с расписанием из конфигурации
+func (a *Actions) serviceEnableAutoUpgrade(ctx context.Context) (*ServiceResponse, error) {
+	if _, err := a.AutoUpgradeEnable(ctx, ""); err != nil {
+		return nil, err
+	}
+
+	status, err := a.autoUpgradeUnit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &ServiceResponse{
+		Message: fmt.Sprintf(app.TL_(ctx, "Service %s enabled"), units.AutoUpgrade),
+		Service: status,
+	}, nil
+}
+
+// ServiceDisable выключает службу apm и удаляет созданные для неё юниты.
+func (a *Actions) ServiceDisable(ctx context.Context, name string) (*ServiceResponse, error) {
+	if name == units.AutoUpgrade {
+		if _, err := a.AutoUpgradeDisable(ctx); err != nil {
+			return nil, err
+		}
+		return &ServiceResponse{
+			Message: fmt.Sprintf(app.TL_(ctx, "Service %s disabled"), name),
+			Service: units.Status{Name: name, Scope: units.ScopeSystem, Activation: units.ActivationTimer},
+		}, nil
+	}
+
+	disabled, err := a.serviceUnits.Disable(ctx, name)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, err)
+	}
+	if !disabled {
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, fmt.Errorf(app.TL_(ctx, "Units of service %s are not installed"), name))
+	}
+
+	status, err := a.serviceUnits.Status(ctx, name)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, err)
+	}
+	return &ServiceResponse{
+		Message: fmt.Sprintf(app.TL_(ctx, "Service %s disabled"), name),
+		Service: status,
+	}, nil
+}
+
+// ServiceStatus возвращает состояние службы или всех служб apm, если имя не задано.
+func (a *Actions) ServiceStatus(ctx context.Context, name string) (*ServiceStatusResponse, error) {
+	names := []string{name}
+	if name == "" {
+		names = append(units.Names(), units.AutoUpgrade)
+	}
+
+	resp := &ServiceStatusResponse{Message: app.TL_(ctx, "apm services")}
+	for _, n := range names {
+		var status units.Status
+		var err error
+		if n == units.AutoUpgrade {
+			if status, err = a.autoUpgradeUnit(ctx); err != nil {
+				return nil, err
+			}
+		} else if status, err = a.serviceUnits.Status(ctx, n); err != nil {
+			return nil, apmerr.New(apmerr.ErrorTypeValidation, err)
+		}
+		resp.Services = append(resp.Services, status)
+	}
+	return resp, nil
+}
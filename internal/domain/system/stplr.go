@@ -0,0 +1,137 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/apt"
+	_package "apm/internal/common/apt/package"
+	"apm/internal/common/reply"
+	"context"
+	"fmt"
+	"os"
+)
+
+// stplrEnabled сообщает, установлен ли в системе STPLR.
+func (a *Actions) stplrEnabled() bool {
+	return a.serviceStplr != nil && a.appConfig.ConfigManager.GetConfig().ExistStplr
+}
+
+// syncStplr загружает каталог STPLR в базу пакетов с типом stplr.
+// Ошибка STPLR не прерывает обновление системных пакетов, она только пишется в лог.
+func (a *Actions) syncStplr(ctx context.Context) {
+	if !a.stplrEnabled() {
+		return
+	}
+
+	a.reporter.CreateEventNotification(ctx, reply.StateBefore, reply.WithEventName(reply.EventSystemUpdateSTPLR))
+	defer a.reporter.CreateEventNotification(ctx, reply.StateAfter, reply.WithEventName(reply.EventSystemUpdateSTPLR))
+
+	if err := a.serviceStplr.Refresh(ctx); err != nil {
+		app.Log.Warning(err.Error())
+	}
+
+	catalogue, err := a.serviceStplr.List(ctx)
+	if err != nil {
+		app.Log.Warning(err.Error())
+		return
+	}
+
+	installed, err := a.serviceAptActions.GetInstalledPackages(ctx)
+	if err != nil {
+		app.Log.Warning(fmt.Sprintf("failed to get installed packages for STPLR: %v", err))
+	}
+
+	packages := make([]_package.Package, 0, len(catalogue))
+	for _, p := range catalogue {
+		pkg := _package.Package{
+			Name:        p.Name,
+			Version:     p.Version,
+			VersionRaw:  p.Version,
+			TypePackage: int(_package.PackageTypeStplr),
+		}
+		if version, ok := installed[p.Name]; ok {
+			pkg.Installed = true
+			pkg.VersionInstalled = version
+		}
+		packages = append(packages, pkg)
+	}
+
+	if err = a.serviceAptDatabase.SaveStplrPackages(ctx, packages); err != nil {
+		app.Log.Warning(fmt.Sprintf("failed to save STPLR packages: %v", err))
+	}
+}
+
+// buildStplrPackages собирает запрошенные пакеты STPLR в RPM-файлы, которые затем ставятся как локальные,
+// и заменяет ими имена пакетов. Пакет собирается, только если в базе нет системного пакета с тем же именем.
+// Возвращает список пакетов, собранные файлы и функцию удаления каталогов сборки.
+func (a *Actions) buildStplrPackages(ctx context.Context, packages []string) ([]string, []string, func(), error) {
+	cleanup := func() {}
+	if !a.stplrEnabled() {
+		return packages, nil, cleanup, nil
+	}
+
+	var names []string
+	for _, pkg := range packages {
+		if !apt.IsRegularFileAndIsPackage(pkg) {
+			names = append(names, pkg)
+		}
+	}
+	if len(names) == 0 {
+		return packages, nil, cleanup, nil
+	}
+
+	records, err := a.serviceAptDatabase.GetPackagesByNames(ctx, names)
+	if err != nil {
+		return nil, nil, cleanup, apmerr.New(apmerr.ErrorTypeDatabase, err)
+	}
+	fromStplr := make(map[string]bool)
+	for _, record := range records {
+		isStplr := _package.PackageType(record.TypePackage) == _package.PackageTypeStplr
+		if seen, ok := fromStplr[record.Name]; ok {
+			isStplr = isStplr && seen
+		}
+		fromStplr[record.Name] = isStplr
+	}
+
+	var dirs []string
+	cleanup = func() {
+		for _, dir := range dirs {
+			_ = os.RemoveAll(dir)
+		}
+	}
+
+	result := make([]string, 0, len(packages))
+	var builtFiles []string
+	for _, pkg := range packages {
+		if !fromStplr[pkg] {
+			result = append(result, pkg)
+			continue
+		}
+
+		dir, built, errBuild := a.serviceStplr.Build(ctx, pkg)
+		if errBuild != nil {
+			cleanup()
+			return nil, nil, func() {}, apmerr.New(apmerr.ErrorTypeApt, errBuild)
+		}
+		dirs = append(dirs, dir)
+		result = append(result, built...)
+		builtFiles = append(builtFiles, built...)
+	}
+	return result, builtFiles, cleanup, nil
+}
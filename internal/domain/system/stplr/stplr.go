@@ -0,0 +1,138 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package stplr
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/command"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+// DefaultBuildDir каталог сборки пакетов STPLR, доступный только root
+const DefaultBuildDir = "/var/cache/apm/stplr"
+
+// Package пакет из каталога STPLR
+type Package struct {
+	Repository string
+	Name       string
+	Version    string
+}
+
+// Manager синхронизирует каталог STPLR и собирает из него RPM-пакеты.
+type Manager struct {
+	runner   command.Runner
+	buildDir string
+}
+
+// NewManager создаёт менеджер STPLR. Пакеты собираются во временных каталогах внутри buildDir.
+func NewManager(runner command.Runner, buildDir string) *Manager {
+	return &Manager{runner: runner, buildDir: buildDir}
+}
+
+// Refresh обновляет репозитории STPLR.
+func (m *Manager) Refresh(ctx context.Context) error {
+	if _, stderr, err := m.runner.Run(ctx, []string{"stplr", "refresh"}, command.WithQuiet()); err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Failed to refresh STPLR repositories: %s"), errorText(stderr, err))
+	}
+	return nil
+}
+
+// List возвращает пакеты каталога STPLR.
+func (m *Manager) List(ctx context.Context) ([]Package, error) {
+	stdout, stderr, err := m.runner.Run(ctx, []string{"stplr", "list"}, command.WithQuiet())
+	if err != nil {
+		return nil, fmt.Errorf(app.TL_(ctx, "Failed to get STPLR package list: %s"), errorText(stderr, err))
+	}
+	return parseList(stdout), nil
+}
+
+// Build собирает пакет STPLR и возвращает пути к полученным RPM-файлам. Каталог сборки
+// удаляет вызывающий, когда файлы больше не нужны.
+func (m *Manager) Build(ctx context.Context, name string) (string, []string, error) {
+	if err := m.ensureBuildDir(); err != nil {
+		return "", nil, err
+	}
+	dir, err := os.MkdirTemp(m.buildDir, "stplr-")
+	if err != nil {
+		return "", nil, err
+	}
+
+	_, stderr, err := m.runner.Run(ctx, []string{"stplr", "--interactive=false", "build", "--package", name}, command.WithDir(dir))
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return "", nil, fmt.Errorf(app.TL_(ctx, "Failed to build STPLR package %s: %s"), name, errorText(stderr, err))
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.rpm"))
+	if err == nil && len(files) == 0 {
+		err = fmt.Errorf(app.TL_(ctx, "Building STPLR package %s produced no RPM files"), name)
+	}
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return "", nil, err
+	}
+	sort.Strings(files)
+	return dir, files, nil
+}
+
+// ensureBuildDir создаёт каталог сборки с правами 0700 и проверяет, что он принадлежит
+// текущему пользователю: иначе собранные файлы мог бы подменить другой пользователь.
+func (m *Manager) ensureBuildDir() error {
+	if err := os.MkdirAll(m.buildDir, 0o700); err != nil {
+		return err
+	}
+	info, err := os.Lstat(m.buildDir)
+	if err != nil {
+		return err
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !info.IsDir() || (ok && int(stat.Uid) != os.Geteuid()) {
+		return fmt.Errorf(app.T_("STPLR build directory %s is not owned by the current user"), m.buildDir)
+	}
+	return os.Chmod(m.buildDir, 0o700)
+}
+
+// parseList разбирает вывод stplr list: строки вида «репозиторий/имя версия».
+func parseList(output string) []Package {
+	var packages []Package
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		repo, name, ok := strings.Cut(fields[0], "/")
+		if !ok || repo == "" || name == "" {
+			continue
+		}
+		packages = append(packages, Package{Repository: repo, Name: name, Version: fields[1]})
+	}
+	return packages
+}
+
+// errorText возвращает текст ошибки команды, предпочитая её stderr.
+func errorText(stderr string, err error) string {
+	if text := strings.TrimSpace(stderr); text != "" {
+		return text
+	}
+	return err.Error()
+}
@@ -0,0 +1,95 @@
+package stplr
+
+import (
+	"apm/internal/common/command"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type mockRunner struct {
+	stdout string
+	stderr string
+	err    error
+	calls  [][]string
+	onRun  func()
+}
+
+func (m *mockRunner) Run(_ context.Context, args []string, _ ...command.Option) (string, string, error) {
+	m.calls = append(m.calls, args)
+	if m.onRun != nil {
+		m.onRun()
+	}
+	return m.stdout, m.stderr, m.err
+}
+
+func TestParseList(t *testing.T) {
+	output := "stplr-repo/foo 1.2-1\n\nWARN something happened here\nother/bar-baz 0.3+git1-2\nbroken/ 1.0\n"
+	want := []Package{
+		{Repository: "stplr-repo", Name: "foo", Version: "1.2-1"},
+		{Repository: "other", Name: "bar-baz", Version: "0.3+git1-2"},
+	}
+	if got := parseList(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseList() = %+v, want %+v", got, want)
+	}
+}
+
+func TestListError(t *testing.T) {
+	runner := &mockRunner{stderr: "no repositories configured\n", err: errors.New("exit status 1")}
+	_, err := NewManager(runner, t.TempDir()).List(context.Background())
+	if err == nil || !reflect.DeepEqual(runner.calls, [][]string{{"stplr", "list"}}) {
+		t.Fatalf("unexpected result: %v, calls %v", err, runner.calls)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	root := t.TempDir()
+	runner := &mockRunner{}
+	runner.onRun = func() {
+		// stplr складывает собранные пакеты в рабочий каталог; имитируем это в единственном каталоге сборки
+		dirs, _ := filepath.Glob(filepath.Join(root, "stplr-*"))
+		for _, dir := range dirs {
+			_ = os.WriteFile(filepath.Join(dir, "foo-1.2-1.x86_64.rpm"), []byte("rpm"), 0o644)
+		}
+	}
+
+	dir, files, err := NewManager(runner, root).Build(context.Background(), "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{filepath.Join(dir, "foo-1.2-1.x86_64.rpm")}; !reflect.DeepEqual(files, want) {
+		t.Errorf("files = %v, want %v", files, want)
+	}
+	if want := [][]string{{"stplr", "--interactive=false", "build", "--package", "foo"}}; !reflect.DeepEqual(runner.calls, want) {
+		t.Errorf("calls = %v, want %v", runner.calls, want)
+	}
+}
+
+func TestBuildNoPackages(t *testing.T) {
+	root := t.TempDir()
+	if _, _, err := NewManager(&mockRunner{}, root).Build(context.Background(), "foo"); err == nil {
+		t.Fatal("expected error when nothing was built")
+	}
+	if dirs, _ := filepath.Glob(filepath.Join(root, "stplr-*")); len(dirs) != 0 {
+		t.Errorf("build directory was not removed: %v", dirs)
+	}
+}
+
+func TestBuildDirIsPrivate(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "stplr")
+	if err := os.MkdirAll(root, 0o777); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, _ = NewManager(&mockRunner{}, root).Build(context.Background(), "foo")
+	info, err := os.Stat(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o700 {
+		t.Errorf("build directory mode = %o, want 700", perm)
+	}
+}
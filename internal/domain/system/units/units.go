@@ -0,0 +1,506 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package units
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/command"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Службы apm, для которых генерируются юниты systemd
+const (
+	DBusSystem  = "dbus-system"
+	DBusSession = "dbus-session"
+	HTTPServer  = "http-server"
+	// AutoUpgrade таймер автоматического обновления, юниты которого создаёт apm system auto-upgrade
+	AutoUpgrade = "auto-upgrade"
+)
+
+// DefaultHTTPListen адрес HTTP API, если httpListen не задан в конфигурации
+const DefaultHTTPListen = "127.0.0.1:8080"
+
+// Способы запуска службы
+const (
+	// ActivationDBus служба запускается при первом обращении к имени на шине D-Bus
+	ActivationDBus = "dbus"
+	// ActivationSocket служба запускается при первом подключении к сокету
+	ActivationSocket = "socket"
+	// ActivationTimer служба запускается по расписанию
+	ActivationTimer = "timer"
+)
+
+// Области юнитов systemd
+const (
+	ScopeSystem = "system"
+	ScopeUser   = "user"
+)
+
+// BusName имя сервиса apm на шине D-Bus
+const BusName = "org.altlinux.APM"
+
+// Marker строка в сгенерированных файлах, по которой apm отличает их от установленных пакетом
+const Marker = "# Generated by apm service install"
+
+// Dirs каталоги, в которые записываются юниты и файлы активации D-Bus. PackageUnits и PackageSystemBus
+// каталоги файлов пакета apm: если служба уже поставлена пакетом, её файлы не переопределяются.
+// Политики D-Bus (BusPolicy) и Polkit (PolkitActions) ставит только пакет, apm проверяет их наличие.
+type Dirs struct {
+	SystemUnits      string
+	UserUnits        string
+	SystemBus        string
+	SessionBus       string
+	PackageUnits     string
+	PackageSystemBus string
+	BusPolicy        []string
+	PolkitActions    []string
+}
+
+// DefaultDirs возвращает каталоги администратора, имеющие приоритет над файлами пакета.
+func DefaultDirs() Dirs {
+	return Dirs{
+		SystemUnits:      "/etc/systemd/system",
+		UserUnits:        "/etc/systemd/user",
+		SystemBus:        "/usr/local/share/dbus-1/system-services",
+		SessionBus:       "/usr/local/share/dbus-1/services",
+		PackageUnits:     "/usr/lib/systemd/system",
+		PackageSystemBus: "/usr/share/dbus-1/system-services",
+		BusPolicy:        []string{"/etc/dbus-1/system.d", "/usr/share/dbus-1/system.d"},
+		PolkitActions:    []string{"/usr/share/polkit-1/actions"},
+	}
+}
+
+// HTTPOptions адреса, на которых systemd слушает HTTP API вместо apm http-server
+type HTTPOptions struct {
+	Listen     string
+	SocketPath string
+	SocketMode string
+	Group      string
+}
+
+// Status состояние службы
+type Status struct {
+	Name       string   `json:"name"`
+	Unit       string   `json:"unit"`
+	Scope      string   `json:"scope"`
+	Activation string   `json:"activation"`
+	Installed  bool     `json:"installed"`
+	Enabled    bool     `json:"enabled"`
+	Active     bool     `json:"active"`
+	Files      []string `json:"files,omitempty"`
+}
+
+// unitFile файл, записываемый при установке службы. Файл пакета (packaged) не записывается и не удаляется.
+type unitFile struct {
+	path     string
+	content  string
+	packaged bool
+}
+
+// packageFile файл, который служба ожидает от пакета apm в одном из каталогов
+type packageFile struct {
+	name string
+	dirs []string
+}
+
+// service описание службы: юнит, который включается, файлы для записи и файлы пакета, без которых служба не работает
+type service struct {
+	name       string
+	scope      string
+	unit       string
+	activation string
+	files      []unitFile
+	requires   []packageFile
+}
+
+// Manager создаёт и управляет юнитами systemd для служб apm
+type Manager struct {
+	runner     command.Runner
+	dirs       Dirs
+	executable string
+	http       HTTPOptions
+	euid       int
+}
+
+// NewManager создаёт менеджер юнитов.
+func NewManager(runner command.Runner, dirs Dirs, http HTTPOptions) *Manager {
+	executable, err := os.Executable()
+	if err != nil {
+		executable = "apm"
+	}
+	return &Manager{
+		runner:     runner,
+		dirs:       dirs,
+		executable: executable,
+		http:       http,
+		euid:       os.Geteuid(),
+	}
+}
+
+// Names возвращает имена служб, для которых можно создать юниты.
+func Names() []string {
+	return []string{DBusSystem, DBusSession, HTTPServer}
+}
+
+// Install записывает юниты службы и перечитывает конфигурацию systemd.
+func (m *Manager) Install(ctx context.Context, name string) (Status, error) {
+	svc, err := m.service(name)
+	if err != nil {
+		return Status{}, err
+	}
+
+	for _, file := range svc.requires {
+		if !packaged(file) {
+			return Status{}, fmt.Errorf(app.TL_(ctx, "File %s is not installed in %s, reinstall the apm package"), file.name, strings.Join(file.dirs, ", "))
+		}
+	}
+
+	for _, file := range svc.files {
+		if file.packaged {
+			continue
+		}
+		if _, errStat := os.Stat(file.path); errStat == nil && !generated(file.path) {
+			return Status{}, fmt.Errorf(app.TL_(ctx, "File %s was not created by apm service install, refusing to overwrite it"), file.path)
+		}
+	}
+
+	for _, file := range svc.files {
+		if file.packaged {
+			continue
+		}
+		if err = os.MkdirAll(filepath.Dir(file.path), 0755); err != nil {
+			return Status{}, fmt.Errorf(app.TL_(ctx, "Failed to write %s: %v"), file.path, err)
+		}
+		if err = os.WriteFile(file.path, []byte(file.content), 0644); err != nil {
+			return Status{}, fmt.Errorf(app.TL_(ctx, "Failed to write %s: %v"), file.path, err)
+		}
+	}
+
+	if err = m.daemonReload(ctx, svc.scope); err != nil {
+		return Status{}, err
+	}
+	return m.status(ctx, svc), nil
+}
+
+// Enable устанавливает юниты при необходимости и включает службу. Системные службы
+// сразу запускаются; пользовательские включаются для всех пользователей и стартуют при входе
+// или при первом обращении к шине.
+func (m *Manager) Enable(ctx context.Context, name string) (Status, error) {
+	svc, err := m.service(name)
+	if err != nil {
+		return Status{}, err
+	}
+	if !m.installed(svc) {
+		if _, err = m.Install(ctx, name); err != nil {
+			return Status{}, err
+		}
+	}
+
+	args := []string{"systemctl", "enable", "--now", svc.unit}
+	if svc.scope == ScopeUser {
+		args = []string{"systemctl", "--global", "enable", svc.unit}
+	}
+	if _, stderr, errRun := m.runner.Run(ctx, args, command.WithQuiet()); errRun != nil {
+		return Status{}, fmt.Errorf(app.TL_(ctx, "Failed to enable %s: %s"), svc.unit, strings.TrimSpace(stderr))
+	}
+	return m.status(ctx, svc), nil
+}
+
+// Disable выключает службу и удаляет сгенерированные файлы. Возвращает false, если юниты не были установлены.
+func (m *Manager) Disable(ctx context.Context, name string) (bool, error) {
+	svc, err := m.service(name)
+	if err != nil {
+		return false, err
+	}
+	if !m.installed(svc) {
+		return false, nil
+	}
+
+	args := []string{"systemctl", "disable", "--now", svc.unit}
+	if svc.scope == ScopeUser {
+		args = []string{"systemctl", "--global", "disable", svc.unit}
+	}
+	if _, stderr, errRun := m.runner.Run(ctx, args, command.WithQuiet()); errRun != nil {
+		return false, fmt.Errorf(app.TL_(ctx, "Failed to disable %s: %s"), svc.unit, strings.TrimSpace(stderr))
+	}
+
+	for _, file := range svc.files {
+		if file.packaged || !generated(file.path) {
+			continue
+		}
+		if err = os.Remove(file.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return false, err
+		}
+	}
+	return true, m.daemonReload(ctx, svc.scope)
+}
+
+// Status возвращает состояние службы.
+func (m *Manager) Status(ctx context.Context, name string) (Status, error) {
+	svc, err := m.service(name)
+	if err != nil {
+		return Status{}, err
+	}
+	return m.status(ctx, svc), nil
+}
+
+// status опрашивает systemd о состоянии юнита службы
+func (m *Manager) status(ctx context.Context, svc service) Status {
+	status := Status{
+		Name:       svc.name,
+		Unit:       svc.unit,
+		Scope:      svc.scope,
+		Activation: svc.activation,
+		Installed:  m.installed(svc),
+	}
+	if status.Installed {
+		for _, file := range svc.files {
+			status.Files = append(status.Files, file.path)
+		}
+	}
+
+	if svc.scope == ScopeUser {
+		stdout, _, _ := m.runner.Run(ctx, []string{"systemctl", "--global", "is-enabled", svc.unit}, command.WithQuiet())
+		status.Enabled = strings.TrimSpace(stdout) == "enabled"
+		status.Active = m.userActive(ctx, svc.unit)
+		return status
+	}
+
+	stdout, _, _ := m.runner.Run(ctx, []string{"systemctl", "is-enabled", svc.unit}, command.WithQuiet())
+	status.Enabled = strings.TrimSpace(stdout) == "enabled"
+	stdout, _, _ = m.runner.Run(ctx, []string{"systemctl", "is-active", svc.unit}, command.WithQuiet())
+	status.Active = strings.TrimSpace(stdout) == "active"
+	return status
+}
+
+// userActive сообщает, запущена ли пользовательская служба. От имени root опрашиваются менеджеры
+// всех пользователей с открытыми сеансами, иначе менеджер текущего пользователя.
+func (m *Manager) userActive(ctx context.Context, unit string) bool {
+	if m.euid != 0 {
+		_, _, err := m.runner.Run(ctx, []string{"systemctl", "--user", "is-active", "--quiet", unit}, command.WithQuiet())
+		return err == nil
+	}
+	for _, user := range SessionUsers(ctx, m.runner) {
+		args := []string{"systemctl", "--user", "--machine", user + "@", "is-active", "--quiet", unit}
+		if _, _, err := m.runner.Run(ctx, args, command.WithQuiet()); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// SessionUsers возвращает имена пользователей, у которых есть сеансы и запущен пользовательский менеджер systemd
+func SessionUsers(ctx context.Context, runner command.Runner) []string {
+	stdout, _, err := runner.Run(ctx, []string{"loginctl", "list-users", "--no-legend"}, command.WithQuiet())
+	if err != nil {
+		return nil
+	}
+	var users []string
+	for _, line := range strings.Split(stdout, "\n") {
+		// UID USER [LINGER STATE]
+		if fields := strings.Fields(line); len(fields) >= 2 {
+			users = append(users, fields[1])
+		}
+	}
+	return users
+}
+
+// installed сообщает, записаны ли все файлы службы
+func (m *Manager) installed(svc service) bool {
+	for _, file := range svc.files {
+		if _, err := os.Stat(file.path); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// generated сообщает, создан ли файл командой apm service install. Файлы, написанные
+// администратором вручную, не удаляются
+func generated(path string) bool {
+	data, err := os.ReadFile(path)
+	return err == nil && strings.HasPrefix(string(data), Marker)
+}
+
+// packaged сообщает, установлен ли файл пакета хотя бы в одном из каталогов
+func packaged(file packageFile) bool {
+	for _, dir := range file.dirs {
+		if _, err := os.Stat(filepath.Join(dir, file.name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// daemonReload перечитывает юниты systemd
+func (m *Manager) daemonReload(ctx context.Context, scope string) error {
+	if scope == ScopeUser {
+		// Пользовательские менеджеры перечитают юниты при следующем входе
+		return nil
+	}
+	if _, stderr, err := m.runner.Run(ctx, []string{"systemctl", "daemon-reload"}, command.WithQuiet()); err != nil {
+		return fmt.Errorf(app.TL_(ctx, "Failed to reload systemd: %s"), strings.TrimSpace(stderr))
+	}
+	return nil
+}
+
+// service возвращает описание службы по имени
+func (m *Manager) service(name string) (service, error) {
+	switch name {
+	case DBusSystem:
+		return service{
+			name:       DBusSystem,
+			scope:      ScopeSystem,
+			unit:       "apm.service",
+			activation: ActivationDBus,
+			files: []unitFile{
+				m.packagedOr(m.dirs.PackageUnits, m.dirs.SystemUnits, "apm.service", m.systemUnit()),
+				m.packagedOr(m.dirs.PackageSystemBus, m.dirs.SystemBus, BusName+".service", m.busActivation(DBusSystem, "apm.service", true)),
+			},
+			requires: []packageFile{
+				{name: BusName + ".conf", dirs: m.dirs.BusPolicy},
+				{name: BusName + ".policy", dirs: m.dirs.PolkitActions},
+			},
+		}, nil
+	case DBusSession:
+		return service{
+			name:       DBusSession,
+			scope:      ScopeUser,
+			unit:       "apm-session.service",
+			activation: ActivationDBus,
+			files: []unitFile{
+				{path: filepath.Join(m.dirs.UserUnits, "apm-session.service"), content: m.sessionUnit()},
+				{path: filepath.Join(m.dirs.SessionBus, BusName+".service"), content: m.busActivation(DBusSession, "apm-session.service", false)},
+			},
+		}, nil
+	case HTTPServer:
+		return service{
+			name:       HTTPServer,
+			scope:      ScopeSystem,
+			unit:       "apm-http.socket",
+			activation: ActivationSocket,
+			files: []unitFile{
+				{path: filepath.Join(m.dirs.SystemUnits, "apm-http.service"), content: m.httpUnit()},
+				{path: filepath.Join(m.dirs.SystemUnits, "apm-http.socket"), content: m.httpSocketUnit()},
+			},
+		}, nil
+	default:
+		return service{}, fmt.Errorf(app.T_("Unknown service %s, available: %s"), name, strings.Join(append(Names(), AutoUpgrade), ", "))
+	}
+}
+
+// packagedOr возвращает файл пакета из packageDir, если он установлен, иначе файл для записи в dir.
+// Так юнит администратора не скрывает юнит пакета и его последующие обновления.
+func (m *Manager) packagedOr(packageDir, dir, name, content string) unitFile {
+	if packageDir != "" {
+		path := filepath.Join(packageDir, name)
+		if _, err := os.Stat(path); err == nil {
+			return unitFile{path: path, packaged: true}
+		}
+	}
+	return unitFile{path: filepath.Join(dir, name), content: content}
+}
+
+// systemUnit формирует юнит системной службы D-Bus по образцу data/apm.service.in из пакета
+func (m *Manager) systemUnit() string {
+	return fmt.Sprintf(`%s
+[Unit]
+Description=APM Daemon
+Wants=network-online.target
+
+[Service]
+Type=dbus
+BusName=%s
+User=root
+ExecStart=%s %s
+
+[Install]
+WantedBy=multi-user.target
+`, Marker, BusName, m.executable, DBusSystem)
+}
+
+// sessionUnit формирует юнит сессионной службы D-Bus, запускаемой по имени на шине.
+// Сессионная служба не запускается у root: его запросы обслуживает системная служба.
+func (m *Manager) sessionUnit() string {
+	return fmt.Sprintf(`%s
+[Unit]
+Description=APM session D-Bus service
+ConditionUser=!root
+
+[Service]
+Type=dbus
+BusName=%s
+ExecStart=%s %s
+
+[Install]
+WantedBy=default.target
+`, Marker, BusName, m.executable, DBusSession)
+}
+
+// busActivation формирует файл активации D-Bus, передающий запуск службы systemd
+func (m *Manager) busActivation(command, unit string, system bool) string {
+	content := fmt.Sprintf(`%s
+[D-BUS Service]
+Name=%s
+Exec=%s %s
+`, Marker, BusName, m.executable, command)
+	if system {
+		content += "User=root\n"
+	}
+	return content + "SystemdService=" + unit + "\n"
+}
+
+// httpUnit формирует юнит HTTP API, получающего сокеты от apm-http.socket
+func (m *Manager) httpUnit() string {
+	return fmt.Sprintf(`%s
+[Unit]
+Description=APM HTTP API
+Requires=apm-http.socket
+After=apm-http.socket
+
+[Service]
+ExecStart=%s http-server
+`, Marker, m.executable)
+}
+
+// httpSocketUnit формирует сокет-юнит: systemd слушает адреса HTTP API и запускает службу при первом подключении
+func (m *Manager) httpSocketUnit() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n[Unit]\nDescription=APM HTTP API socket\n\n[Socket]\n", Marker)
+
+	listen := slices.DeleteFunc([]string{m.http.Listen, m.http.SocketPath}, func(s string) bool { return s == "" })
+	for _, addr := range listen {
+		fmt.Fprintf(&b, "ListenStream=%s\n", addr)
+	}
+	if m.http.SocketPath != "" {
+		if m.http.SocketMode != "" {
+			fmt.Fprintf(&b, "SocketMode=%s\n", m.http.SocketMode)
+		}
+		if m.http.Group != "" {
+			fmt.Fprintf(&b, "SocketGroup=%s\n", m.http.Group)
+		}
+	}
+
+	b.WriteString("\n[Install]\nWantedBy=sockets.target\n")
+	return b.String()
+}
@@ -0,0 +1,303 @@
+package units
+
+import (
+	"apm/internal/common/command"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type mockRunner struct {
+	calls   [][]string
+	runFunc func(args []string) (string, string, error)
+}
+
+func (m *mockRunner) Run(_ context.Context, args []string, _ ...command.Option) (string, string, error) {
+	m.calls = append(m.calls, args)
+	if m.runFunc != nil {
+		return m.runFunc(args)
+	}
+	return "", "", nil
+}
+
+func (m *mockRunner) called(cmd string) bool {
+	for _, call := range m.calls {
+		if strings.Join(call, " ") == cmd {
+			return true
+		}
+	}
+	return false
+}
+
+func newTestManager(t *testing.T, runner *mockRunner, http HTTPOptions) *Manager {
+	t.Helper()
+	dir := t.TempDir()
+	m := NewManager(runner, Dirs{
+		SystemUnits:      filepath.Join(dir, "system"),
+		UserUnits:        filepath.Join(dir, "user"),
+		SystemBus:        filepath.Join(dir, "system-services"),
+		SessionBus:       filepath.Join(dir, "services"),
+		PackageUnits:     filepath.Join(dir, "lib", "system"),
+		PackageSystemBus: filepath.Join(dir, "share", "system-services"),
+		BusPolicy:        []string{filepath.Join(dir, "etc", "system.d"), filepath.Join(dir, "share", "system.d")},
+		PolkitActions:    []string{filepath.Join(dir, "polkit-1", "actions")},
+	}, http)
+	m.executable = "/usr/bin/apm"
+	m.euid = 0
+
+	// Политики поставляет пакет apm
+	for _, path := range []string{
+		filepath.Join(m.dirs.BusPolicy[1], BusName+".conf"),
+		filepath.Join(m.dirs.PolkitActions[0], BusName+".policy"),
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("<packaged/>\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return m
+}
+
+func TestInstallDBusSystem(t *testing.T) {
+	runner := &mockRunner{}
+	m := newTestManager(t, runner, HTTPOptions{})
+
+	status, err := m.Install(context.Background(), DBusSystem)
+	if err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	if !status.Installed || status.Unit != "apm.service" || len(status.Files) != 2 {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	unit, _ := os.ReadFile(filepath.Join(m.dirs.SystemUnits, "apm.service"))
+	for _, line := range []string{"Type=dbus", "Wants=network-online.target", "User=root", "ExecStart=/usr/bin/apm dbus-system"} {
+		if !strings.Contains(string(unit), line) {
+			t.Errorf("unit misses %q:\n%s", line, unit)
+		}
+	}
+	activation, _ := os.ReadFile(filepath.Join(m.dirs.SystemBus, BusName+".service"))
+	if !strings.Contains(string(activation), "SystemdService=apm.service") || !strings.Contains(string(activation), "User=root") {
+		t.Errorf("unexpected activation file: %s", activation)
+	}
+	if !runner.called("systemctl daemon-reload") {
+		t.Error("expected daemon-reload")
+	}
+
+	if _, err = m.Disable(context.Background(), DBusSystem); err != nil {
+		t.Fatalf("disable: %v", err)
+	}
+	policy := filepath.Join(m.dirs.PolkitActions[0], BusName+".policy")
+	if data, _ := os.ReadFile(policy); string(data) != "<packaged/>\n" {
+		t.Errorf("packaged policy must be kept, got %q", data)
+	}
+}
+
+func TestInstallRequiresPackagedPolicies(t *testing.T) {
+	m := newTestManager(t, &mockRunner{}, HTTPOptions{})
+	if err := os.Remove(filepath.Join(m.dirs.PolkitActions[0], BusName+".policy")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.Install(context.Background(), DBusSystem); err == nil {
+		t.Fatal("expected error without the packaged polkit policy")
+	}
+	if _, err := os.Stat(filepath.Join(m.dirs.SystemUnits, "apm.service")); !os.IsNotExist(err) {
+		t.Error("units must not be written without the policies")
+	}
+	if _, err := os.Stat(filepath.Join(m.dirs.PolkitActions[0], BusName+".policy")); !os.IsNotExist(err) {
+		t.Error("apm must not write the polkit policy itself")
+	}
+}
+
+func TestInstallKeepsPackagedUnit(t *testing.T) {
+	runner := &mockRunner{}
+	m := newTestManager(t, runner, HTTPOptions{})
+	packaged := filepath.Join(m.dirs.PackageUnits, "apm.service")
+	if err := os.MkdirAll(m.dirs.PackageUnits, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(packaged, []byte("[Service]\nExecStart=/usr/bin/apm dbus-system\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := m.Install(context.Background(), DBusSystem)
+	if err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	if _, err = os.Stat(filepath.Join(m.dirs.SystemUnits, "apm.service")); !os.IsNotExist(err) {
+		t.Error("packaged unit must not be shadowed by a generated one")
+	}
+	if !status.Installed || status.Files[0] != packaged {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	if _, err = m.Disable(context.Background(), DBusSystem); err != nil {
+		t.Fatalf("disable: %v", err)
+	}
+	if _, err = os.Stat(packaged); err != nil {
+		t.Error("packaged unit must be kept on disable")
+	}
+}
+
+func TestInstallRefusesForeignFiles(t *testing.T) {
+	m := newTestManager(t, &mockRunner{}, HTTPOptions{Listen: "127.0.0.1:8080"})
+	manual := filepath.Join(m.dirs.SystemUnits, "apm-http.service")
+	if err := os.MkdirAll(m.dirs.SystemUnits, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(manual, []byte("[Service]\nExecStart=/opt/apm http-server\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.Install(context.Background(), HTTPServer); err == nil {
+		t.Fatal("expected install to refuse overwriting a manual unit")
+	}
+	if data, _ := os.ReadFile(manual); !strings.Contains(string(data), "/opt/apm") {
+		t.Errorf("manual unit was overwritten: %s", data)
+	}
+	if _, err := os.Stat(filepath.Join(m.dirs.SystemUnits, "apm-http.socket")); !os.IsNotExist(err) {
+		t.Error("no files must be written when install is refused")
+	}
+}
+
+func TestEnableSessionInstallsGlobally(t *testing.T) {
+	runner := &mockRunner{runFunc: func(args []string) (string, string, error) {
+		if strings.Join(args, " ") == "systemctl --global is-enabled apm-session.service" {
+			return "enabled\n", "", nil
+		}
+		return "", "", nil
+	}}
+	m := newTestManager(t, runner, HTTPOptions{})
+
+	status, err := m.Enable(context.Background(), DBusSession)
+	if err != nil {
+		t.Fatalf("enable: %v", err)
+	}
+	if !status.Installed || !status.Enabled || status.Scope != ScopeUser {
+		t.Errorf("unexpected status: %+v", status)
+	}
+	if !runner.called("systemctl --global enable apm-session.service") {
+		t.Errorf("expected global enable, calls: %v", runner.calls)
+	}
+	if runner.called("systemctl daemon-reload") {
+		t.Error("user units must not reload the system manager")
+	}
+	unit, _ := os.ReadFile(filepath.Join(m.dirs.UserUnits, "apm-session.service"))
+	if !strings.Contains(string(unit), "ConditionUser=!root") {
+		t.Errorf("session unit must not start for root:\n%s", unit)
+	}
+}
+
+func TestSessionStatusActive(t *testing.T) {
+	runner := &mockRunner{runFunc: func(args []string) (string, string, error) {
+		switch strings.Join(args, " ") {
+		case "loginctl list-users --no-legend":
+			return " 1000 alice no active\n 1001 bob no active\n", "", nil
+		case "systemctl --user --machine bob@ is-active --quiet apm-session.service":
+			return "", "", nil
+		case "systemctl --global is-enabled apm-session.service":
+			return "enabled\n", "", nil
+		}
+		return "", "", errors.New("exit status 3")
+	}}
+	m := newTestManager(t, runner, HTTPOptions{})
+
+	status, err := m.Status(context.Background(), DBusSession)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if !status.Active {
+		t.Errorf("expected session service to be reported active, calls: %v", runner.calls)
+	}
+
+	m.euid = 1000
+	if status, _ = m.Status(context.Background(), DBusSession); status.Active {
+		t.Error("expected inactive session service for the current user")
+	}
+}
+
+func TestDisableReportsSystemctlError(t *testing.T) {
+	runner := &mockRunner{runFunc: func(args []string) (string, string, error) {
+		if slices.Contains(args, "disable") {
+			return "", "Access denied\n", errors.New("exit status 1")
+		}
+		return "", "", nil
+	}}
+	m := newTestManager(t, runner, HTTPOptions{Listen: "127.0.0.1:8080"})
+	if _, err := m.Install(context.Background(), HTTPServer); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+
+	if _, err := m.Disable(context.Background(), HTTPServer); err == nil || !strings.Contains(err.Error(), "Access denied") {
+		t.Fatalf("expected disable error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(m.dirs.SystemUnits, "apm-http.socket")); err != nil {
+		t.Error("units must be kept when systemctl disable fails")
+	}
+}
+
+func TestHTTPSocketUnit(t *testing.T) {
+	m := newTestManager(t, &mockRunner{}, HTTPOptions{
+		Listen:     "127.0.0.1:8080",
+		SocketPath: "/run/apm/http.sock",
+		SocketMode: "0660",
+		Group:      "wheel",
+	})
+
+	if _, err := m.Install(context.Background(), HTTPServer); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+
+	socket, _ := os.ReadFile(filepath.Join(m.dirs.SystemUnits, "apm-http.socket"))
+	for _, line := range []string{"ListenStream=127.0.0.1:8080", "ListenStream=/run/apm/http.sock", "SocketMode=0660", "SocketGroup=wheel", "WantedBy=sockets.target"} {
+		if !strings.Contains(string(socket), line) {
+			t.Errorf("socket unit misses %q:\n%s", line, socket)
+		}
+	}
+}
+
+func TestDisableKeepsManualFiles(t *testing.T) {
+	runner := &mockRunner{}
+	m := newTestManager(t, runner, HTTPOptions{Listen: "127.0.0.1:8080"})
+	ctx := context.Background()
+
+	disabled, err := m.Disable(ctx, HTTPServer)
+	if err != nil || disabled {
+		t.Fatalf("disable without units = %v, %v", disabled, err)
+	}
+
+	if _, err = m.Install(ctx, HTTPServer); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	manual := filepath.Join(m.dirs.SystemUnits, "apm-http.service")
+	if err = os.WriteFile(manual, []byte("[Service]\nExecStart=/opt/apm http-server\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if disabled, err = m.Disable(ctx, HTTPServer); err != nil || !disabled {
+		t.Fatalf("disable = %v, %v", disabled, err)
+	}
+	if _, err = os.Stat(manual); err != nil {
+		t.Error("manually written unit must be kept")
+	}
+	if _, err = os.Stat(filepath.Join(m.dirs.SystemUnits, "apm-http.socket")); !os.IsNotExist(err) {
+		t.Error("generated socket unit must be removed")
+	}
+	if !runner.called("systemctl disable --now apm-http.socket") {
+		t.Errorf("expected disable, calls: %v", runner.calls)
+	}
+}
+
+func TestUnknownService(t *testing.T) {
+	m := newTestManager(t, &mockRunner{}, HTTPOptions{})
+	if _, err := m.Status(context.Background(), "cron"); err == nil {
+		t.Error("expected error for unknown service")
+	}
+}
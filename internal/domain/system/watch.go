@@ -0,0 +1,182 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package system
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/reply"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Виды изменений, о которых сообщает Watch
+const (
+	WatchChangeUpgrades     = "upgrades"
+	WatchChangeKernel       = "kernel"
+	WatchChangeRepositories = "repositories"
+)
+
+// WatchChange изменение состояния пакетов, обнаруженное при очередной проверке
+type WatchChange struct {
+	Kind     string   `json:"kind"`
+	Message  string   `json:"message"`
+	Added    []string `json:"added,omitempty"`
+	Removed  []string `json:"removed,omitempty"`
+	Kernel   string   `json:"kernel,omitempty"`
+	Date     string   `json:"date"`
+	Upgrades int      `json:"upgrades"`
+}
+
+// watchSnapshot состояние, которое сравнивается между проверками
+type watchSnapshot struct {
+	upgrades     []string
+	kernel       string
+	repositories []string
+}
+
+// Watch периодически обновляет базу пакетов и передаёт в fn изменения: новые обновления,
+// новое ядро текущего flavour и изменения списка репозиториев. Работает, пока не отменён ctx.
+// Первая проверка сообщает об уже ожидающих обновлениях и доступном ядре.
+func (a *Actions) Watch(ctx context.Context, interval time.Duration, fn func(WatchChange)) error {
+	if interval <= 0 {
+		interval = time.Duration(a.appConfig.ConfigManager.GetConfig().WatchInterval) * time.Minute
+	}
+	if interval < time.Minute {
+		return apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "The watch interval must be at least one minute")))
+	}
+
+	var prev *watchSnapshot
+	for {
+		snapshot, err := a.watchSnapshot(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			app.Log.Errorf("watch: %v", err)
+		} else {
+			for _, change := range diffWatchSnapshots(prev, snapshot) {
+				a.reporter.SendTaskResult(ctx, reply.EventSystemWatch, change, nil)
+				fn(change)
+			}
+			prev = &snapshot
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// watchSnapshot обновляет базу пакетов и собирает текущее состояние
+func (a *Actions) watchSnapshot(ctx context.Context) (watchSnapshot, error) {
+	if _, err := a.Update(ctx, false, false); err != nil {
+		return watchSnapshot{}, err
+	}
+
+	var snapshot watchSnapshot
+	changes, err := a.serviceAptActions.CheckUpgrade(ctx)
+	if err != nil {
+		return watchSnapshot{}, apmerr.New(apmerr.ErrorTypeApt, err)
+	}
+	if changes != nil {
+		snapshot.upgrades = slices.Sorted(slices.Values(changes.UpgradedPackages))
+	}
+
+	if current, errKernel := a.serviceKernel.GetCurrentKernel(ctx); errKernel != nil {
+		app.Log.Debugf("watch: current kernel: %v", errKernel)
+	} else if latest, errLatest := a.serviceKernel.FindLatestKernel(ctx, current.Flavour); errLatest != nil {
+		app.Log.Debugf("watch: latest kernel: %v", errLatest)
+	} else if !latest.IsInstalled {
+		snapshot.kernel = latest.FullVersion
+	}
+
+	repos, err := a.serviceRepos.GetRepositories(ctx, false)
+	if err != nil {
+		app.Log.Debugf("watch: repositories: %v", err)
+	}
+	for _, repo := range repos {
+		snapshot.repositories = append(snapshot.repositories, repo.Entry)
+	}
+	slices.Sort(snapshot.repositories)
+
+	return snapshot, nil
+}
+
+// diffWatchSnapshots сравнивает два состояния. При prev == nil сообщает только о наличии
+// обновлений и нового ядра, не перечисляя репозитории.
+func diffWatchSnapshots(prev *watchSnapshot, cur watchSnapshot) []WatchChange {
+	date := time.Now().Format(time.RFC3339)
+	var changes []WatchChange
+
+	var old watchSnapshot
+	if prev != nil {
+		old = *prev
+	}
+
+	if added := subtract(cur.upgrades, old.upgrades); len(added) > 0 {
+		changes = append(changes, WatchChange{
+			Kind:     WatchChangeUpgrades,
+			Message:  fmt.Sprintf(app.TN_("%d new upgrade available", "%d new upgrades available", len(added)), len(added)),
+			Added:    added,
+			Date:     date,
+			Upgrades: len(cur.upgrades),
+		})
+	}
+
+	if cur.kernel != "" && cur.kernel != old.kernel {
+		changes = append(changes, WatchChange{
+			Kind:     WatchChangeKernel,
+			Message:  fmt.Sprintf(app.T_("New kernel %s is available"), cur.kernel),
+			Kernel:   cur.kernel,
+			Date:     date,
+			Upgrades: len(cur.upgrades),
+		})
+	}
+
+	if prev != nil {
+		added, removed := subtract(cur.repositories, old.repositories), subtract(old.repositories, cur.repositories)
+		if len(added) > 0 || len(removed) > 0 {
+			changes = append(changes, WatchChange{
+				Kind:     WatchChangeRepositories,
+				Message:  app.T_("Repository list changed"),
+				Added:    added,
+				Removed:  removed,
+				Date:     date,
+				Upgrades: len(cur.upgrades),
+			})
+		}
+	}
+
+	return changes
+}
+
+// subtract возвращает элементы a, отсутствующие в b
+func subtract(a, b []string) []string {
+	var result []string
+	for _, item := range a {
+		if !slices.Contains(b, item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,98 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package tasks
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/reply"
+	"apm/internal/common/taskmanager"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Actions объединяет методы для управления фоновыми задачами.
+type Actions struct {
+	appConfig *app.Config
+	tasks     *taskmanager.Manager
+}
+
+// NewActions создаёт Actions поверх менеджера задач reporter
+func NewActions(appConfig *app.Config, reporter *reply.Reporter) *Actions {
+	return &Actions{
+		appConfig: appConfig,
+		tasks:     reporter.Tasks(),
+	}
+}
+
+// ListTasks возвращает выполняющиеся и завершённые фоновые задачи.
+func (a *Actions) ListTasks(_ context.Context) (*ListResponse, error) {
+	tasks := a.tasks.List()
+	running := 0
+	for _, t := range tasks {
+		if t.State == taskmanager.StateRunning {
+			running++
+		}
+	}
+
+	return &ListResponse{
+		Message: fmt.Sprintf(app.TN_("%d task found", "%d tasks found", len(tasks)), len(tasks)),
+		Tasks:   tasks,
+		Running: running,
+	}, nil
+}
+
+// TaskStatus возвращает состояние задачи и результат, если она завершена.
+func (a *Actions) TaskStatus(_ context.Context, id string) (*StatusResponse, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.T_("Task ID must be specified")))
+	}
+
+	t, err := a.tasks.Get(id)
+	if err != nil {
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.T_("Task %s not found"), id))
+	}
+
+	return &StatusResponse{
+		Message: fmt.Sprintf(app.T_("Task %s is %s"), id, t.State),
+		Task:    t,
+	}, nil
+}
+
+// CancelTask отменяет выполняющуюся задачу.
+func (a *Actions) CancelTask(_ context.Context, id string) (*StatusResponse, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil, apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.T_("Task ID must be specified")))
+	}
+
+	t, err := a.tasks.Cancel(id)
+	switch {
+	case errors.Is(err, taskmanager.ErrNotFound):
+		return nil, apmerr.New(apmerr.ErrorTypeNotFound, fmt.Errorf(app.T_("Task %s not found"), id))
+	case errors.Is(err, taskmanager.ErrNotRunning):
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, fmt.Errorf(app.T_("Task %s is already finished"), id))
+	}
+
+	return &StatusResponse{
+		Message: fmt.Sprintf(app.T_("Cancellation of task %s requested"), id),
+		Task:    t,
+	}, nil
+}
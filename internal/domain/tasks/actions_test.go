@@ -0,0 +1,59 @@
+package tasks
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/helper"
+	"apm/internal/common/reply"
+	"apm/internal/common/taskmanager"
+	"apm/internal/common/testutil"
+	"context"
+	"testing"
+)
+
+func TestTaskActions(t *testing.T) {
+	appConfig := &app.Config{
+		ConfigManager: &testutil.MockConfigManager{Config: &app.Configuration{Format: app.FormatHTTP}},
+		DBusManager:   app.NewDBusManager(),
+	}
+	reporter := reply.NewReporter(appConfig)
+	actions := NewActions(appConfig, reporter)
+	ctx := context.Background()
+
+	taskCtx := reporter.StartTask(context.WithValue(ctx, helper.TransactionKey, "tx-1"), reply.EventSystemUpgrade)
+
+	list, err := actions.ListTasks(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Tasks) != 1 || list.Running != 1 {
+		t.Errorf("expected one running task, got %+v", list)
+	}
+
+	resp, err := actions.CancelTask(ctx, "tx-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Task.ID != "tx-1" || taskCtx.Err() == nil {
+		t.Errorf("expected task tx-1 to be canceled, got %+v", resp.Task)
+	}
+
+	reporter.SendTaskResult(taskCtx, reply.EventSystemUpgrade, nil, taskCtx.Err())
+
+	status, err := actions.TaskStatus(ctx, "tx-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Task.State != taskmanager.StateCanceled {
+		t.Errorf("expected canceled state, got %s", status.Task.State)
+	}
+
+	_, err = actions.CancelTask(ctx, "tx-1")
+	testutil.AssertAPMError(t, err, apmerr.ErrorTypeNoOperation)
+
+	_, err = actions.TaskStatus(ctx, "missing")
+	testutil.AssertAPMError(t, err, apmerr.ErrorTypeNotFound)
+
+	_, err = actions.TaskStatus(ctx, " ")
+	testutil.AssertAPMError(t, err, apmerr.ErrorTypeValidation)
+}
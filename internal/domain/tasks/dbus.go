@@ -0,0 +1,118 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package tasks
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/helper"
+	"apm/internal/common/reply"
+	"apm/internal/common/service"
+	"context"
+	"encoding/json"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const DBusInterface = "org.altlinux.APM.tasks"
+
+// DBusFactory создаёт модуль задач для шины bus. На системной шине отмена задач требует прав org.altlinux.APM.manage.
+func DBusFactory(appConfig *app.Config, reporter *reply.Reporter, bus service.BusType) service.DBusModule {
+	return service.DBusModule{
+		Interface: DBusInterface,
+		Build: func(ctx context.Context, conn *dbus.Conn) (service.DBusExport, error) {
+			actions := NewActions(appConfig, reporter)
+			return service.DBusExport{Object: NewDBusWrapper(actions, conn, ctx, bus == service.BusSystem)}, nil
+		},
+	}
+}
+
+// DBusWrapper предоставляет обёртку для управления фоновыми задачами через DBus.
+type DBusWrapper struct {
+	conn              *dbus.Conn
+	actions           *Actions
+	ctx               context.Context
+	requirePermission bool
+}
+
+// NewDBusWrapper создаёт новую обёртку над actions
+func NewDBusWrapper(a *Actions, c *dbus.Conn, ctx context.Context, requirePermission bool) *DBusWrapper {
+	return &DBusWrapper{actions: a, conn: c, ctx: ctx, requirePermission: requirePermission}
+}
+
+// WithLocale возвращает копию обёртки, формирующую сообщения на языке клиента lang
+func (w *DBusWrapper) WithLocale(lang string) any {
+	localized := *w
+	localized.ctx = app.WithLocale(w.ctx, lang)
+	return &localized
+}
+
+// checkManagePermission проверяет права org.altlinux.APM.manage
+func (w *DBusWrapper) checkManagePermission(sender dbus.Sender) *dbus.Error {
+	if !w.requirePermission {
+		return nil
+	}
+	if err := helper.PolkitCheck(w.conn, sender, helper.PolkitActionManage); err != nil {
+		return apmerr.DBusError(apmerr.New(apmerr.ErrorTypePermission, err))
+	}
+	return nil
+}
+
+// ListTasks возвращает список фоновых задач.
+func (w *DBusWrapper) ListTasks(transaction string) (string, *dbus.Error) {
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.ListTasks(ctx)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// TaskStatus возвращает состояние и результат задачи.
+func (w *DBusWrapper) TaskStatus(id string, transaction string) (string, *dbus.Error) {
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.TaskStatus(ctx, id)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
+
+// CancelTask отменяет выполняющуюся задачу.
+func (w *DBusWrapper) CancelTask(sender dbus.Sender, id string, transaction string) (string, *dbus.Error) {
+	if err := w.checkManagePermission(sender); err != nil {
+		return "", err
+	}
+	ctx := context.WithValue(w.ctx, helper.TransactionKey, transaction)
+	resp, err := w.actions.CancelTask(ctx, id)
+	if err != nil {
+		return "", apmerr.DBusError(err)
+	}
+	data, jerr := json.Marshal(reply.OK(resp))
+	if jerr != nil {
+		return "", dbus.MakeFailedError(jerr)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,115 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package tasks
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/http_server"
+	"apm/internal/common/reply"
+	"apm/internal/common/service"
+	"context"
+	"net/http"
+	"reflect"
+)
+
+func HTTPFactory(appConfig *app.Config, reporter *reply.Reporter) service.HTTPModule {
+	return service.HTTPModule{
+		Endpoints: func(ctx context.Context) []http_server.Endpoint {
+			actions := NewActions(appConfig, reporter)
+			return NewHTTPWrapper(actions, appConfig, reporter, ctx).GetEndpoints()
+		},
+	}
+}
+
+// HTTPWrapper предоставляет обёртку для управления фоновыми задачами через HTTP.
+type HTTPWrapper struct {
+	http_server.BaseHTTPWrapper
+	actions *Actions
+}
+
+// NewHTTPWrapper создаёт новую обёртку над actions.
+func NewHTTPWrapper(a *Actions, appConfig *app.Config, reporter *reply.Reporter, ctx context.Context) *HTTPWrapper {
+	return &HTTPWrapper{
+		BaseHTTPWrapper: http_server.BaseHTTPWrapper{Ctx: ctx, AppConfig: appConfig, Reporter: reporter},
+		actions:         a,
+	}
+}
+
+// ListTasks возвращает список фоновых задач.
+func (w *HTTPWrapper) ListTasks(rw http.ResponseWriter, r *http.Request) {
+	resp, err := w.actions.ListTasks(w.CtxWithTransaction(r))
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// TaskStatus возвращает состояние и результат задачи.
+func (w *HTTPWrapper) TaskStatus(rw http.ResponseWriter, r *http.Request) {
+	resp, err := w.actions.TaskStatus(w.CtxWithTransaction(r), r.PathValue("id"))
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// CancelTask отменяет выполняющуюся задачу.
+func (w *HTTPWrapper) CancelTask(rw http.ResponseWriter, r *http.Request) {
+	resp, err := w.actions.CancelTask(w.CtxWithTransaction(r), r.PathValue("id"))
+	if err != nil {
+		reply.WriteHTTPError(rw, err)
+		return
+	}
+	w.WriteJSON(rw, reply.OK(resp))
+}
+
+// GetEndpoints возвращает описания endpoints с handler
+func (w *HTTPWrapper) GetEndpoints() []http_server.Endpoint {
+	return []http_server.Endpoint{
+		{
+			Handler:      w.ListTasks,
+			HTTPMethod:   "GET",
+			HTTPPath:     "/api/v1/tasks",
+			ResponseType: reflect.TypeOf(ListResponse{}),
+			Permission:   http_server.PermRead,
+			Summary:      "Получить список фоновых задач",
+			Tags:         []string{"tasks"},
+		},
+		{
+			Handler:      w.TaskStatus,
+			HTTPMethod:   "GET",
+			HTTPPath:     "/api/v1/tasks/{id}",
+			ResponseType: reflect.TypeOf(StatusResponse{}),
+			Permission:   http_server.PermRead,
+			Summary:      "Получить состояние и результат фоновой задачи",
+			Tags:         []string{"tasks"},
+			PathParams:   []string{"id"},
+		},
+		{
+			Handler:      w.CancelTask,
+			HTTPMethod:   "POST",
+			HTTPPath:     "/api/v1/tasks/{id}/cancel",
+			ResponseType: reflect.TypeOf(StatusResponse{}),
+			Permission:   http_server.PermManage,
+			Summary:      "Отменить выполняющуюся фоновую задачу",
+			Tags:         []string{"tasks"},
+			PathParams:   []string{"id"},
+		},
+	}
+}
@@ -0,0 +1,32 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package tasks
+
+import "apm/internal/common/taskmanager"
+
+// ListResponse структура ответа для ListTasks метода
+type ListResponse struct {
+	Message string             `json:"message"`
+	Tasks   []taskmanager.Task `json:"tasks"`
+	Running int                `json:"running"`
+}
+
+// StatusResponse структура ответа для TaskStatus и CancelTask методов
+type StatusResponse struct {
+	Message string           `json:"message"`
+	Task    taskmanager.Task `json:"task"`
+}
@@ -0,0 +1,109 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package tui
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	"apm/internal/common/reply"
+	"apm/internal/domain/distrobox"
+	"apm/internal/domain/kernel"
+	"apm/internal/domain/system"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Actions объединяет действия модулей, доступных в интерфейсе
+type Actions struct {
+	appConfig *app.Config
+	system    *system.Actions
+	kernel    *kernel.Actions
+	distrobox *distrobox.Actions
+}
+
+// NewActions создаёт Actions поверх существующих модулей system, kernel и distrobox.
+// Контейнеры distrobox принадлежат пользователю, а от root podman видит только контейнеры root,
+// поэтому под root вкладка контейнера не добавляется.
+func NewActions(appConfig *app.Config, reporter *reply.Reporter) *Actions {
+	a := &Actions{
+		appConfig: appConfig,
+		system:    system.NewActions(appConfig, reporter),
+		kernel:    kernel.NewActions(appConfig, reporter),
+	}
+	if appConfig.ConfigManager.GetConfig().ExistDistrobox && os.Geteuid() != 0 {
+		a.distrobox = distrobox.NewActions(appConfig, reporter)
+	}
+	return a
+}
+
+// Sources возвращает источники пакетов для вкладок. Вкладка контейнера добавляется,
+// если distrobox доступен, а контейнер указан явно или найден первым в списке.
+func (a *Actions) Sources(ctx context.Context, container string) ([]Source, error) {
+	sources := []Source{
+		&systemSource{actions: a.system},
+		&kernelSource{actions: a.kernel},
+	}
+	if a.distrobox == nil {
+		if container != "" {
+			return nil, apmerr.New(apmerr.ErrorTypeValidation,
+				fmt.Errorf(app.TL_(ctx, "Container %s is not available: distrobox containers cannot be managed as root"), container))
+		}
+		return sources, nil
+	}
+
+	if container == "" {
+		resp, err := a.distrobox.ContainerList(ctx, false)
+		if err != nil || len(resp.Containers) == 0 {
+			return sources, nil
+		}
+		container = resp.Containers[0].ContainerName
+	}
+	return append(sources, &distroboxSource{actions: a.distrobox, container: container}), nil
+}
+
+// Apply применяет отмеченные изменения по каждому источнику
+func (a *Actions) Apply(ctx context.Context, sources []Source, result Result) (*ApplyResponse, error) {
+	if !result.Apply {
+		return nil, apmerr.New(apmerr.ErrorTypeCanceled, errors.New(app.TL_(ctx, "Operation cancelled")))
+	}
+
+	resp := &ApplyResponse{}
+	for i, p := range result.Pending {
+		if len(p.Install) == 0 && len(p.Remove) == 0 {
+			continue
+		}
+		res, err := sources[i].Apply(ctx, p.Install, p.Remove)
+		if err != nil {
+			return nil, err
+		}
+		resp.Results = append(resp.Results, SourceResult{
+			Source:  sources[i].Title(),
+			Install: p.Install,
+			Remove:  p.Remove,
+			Result:  res,
+		})
+	}
+
+	if len(resp.Results) == 0 {
+		return nil, apmerr.New(apmerr.ErrorTypeNoOperation, errors.New(app.TL_(ctx, "No changes selected")))
+	}
+
+	resp.Message = fmt.Sprintf(app.TLN_(ctx, "Changes applied to %d module", "Changes applied to %d modules", len(resp.Results)), len(resp.Results))
+	return resp, nil
+}
@@ -0,0 +1,73 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package tui
+
+import (
+	"apm/internal/common/apmerr"
+	"apm/internal/common/app"
+	apmcli "apm/internal/common/cli"
+	"apm/internal/common/reply"
+	"context"
+	"errors"
+
+	"github.com/urfave/cli/v3"
+)
+
+func newErrorResponseFromError(err error) reply.APIResponse {
+	app.Log.Error(err.Error())
+	return reply.ErrorResponseFromError(err)
+}
+
+// Command возвращает команду полноэкранного интерфейса управления пакетами
+func Command(appConfig *app.Config, reporter *reply.Reporter) *cli.Command {
+	withRootCheckWrapper := apmcli.WithOptions(appConfig, reporter, apmcli.RequireRoot, NewActions, newErrorResponseFromError)
+
+	return &cli.Command{
+		Name:  "tui",
+		Usage: app.T_("Interactive package management interface"),
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "container",
+				Usage:   app.T_("Container for the distrobox tab"),
+				Aliases: []string{"c"},
+			},
+		},
+		Action: withRootCheckWrapper(func(ctx context.Context, cmd *cli.Command, actions *Actions) error {
+			reply.StopSpinner(appConfig)
+			if !reply.IsInteractive(appConfig) {
+				return reporter.CliResponse(ctx, newErrorResponseFromError(
+					apmerr.New(apmerr.ErrorTypeValidation, errors.New(app.TL_(ctx, "The interface requires an interactive terminal")))))
+			}
+
+			sources, err := actions.Sources(ctx, cmd.String("container"))
+			if err != nil {
+				return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+			}
+			result, err := Run(ctx, sources, appConfig.ConfigManager.GetColors())
+			if err != nil {
+				return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+			}
+
+			resp, err := actions.Apply(ctx, sources, result)
+			if err != nil {
+				return reporter.CliResponse(ctx, newErrorResponseFromError(err))
+			}
+
+			return reporter.CliResponse(ctx, reply.OK(resp))
+		}),
+	}
+}
@@ -0,0 +1,336 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package tui
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/common/helper"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Mark отметка пакета в ожидающих изменениях
+type Mark int
+
+const (
+	MarkNone Mark = iota
+	MarkInstall
+	MarkRemove
+)
+
+// Pending ожидающие изменения одного источника
+type Pending struct {
+	Install []string
+	Remove  []string
+}
+
+// Result итог работы интерфейса: изменения по каждому источнику в порядке вкладок
+type Result struct {
+	Apply   bool
+	Pending []Pending
+}
+
+type itemsMsg struct {
+	tab   int
+	query string
+	items []Item
+	err   error
+}
+
+type model struct {
+	ctx     context.Context
+	sources []Source
+	tab     int
+	input   textinput.Model
+	items   []Item
+	marks   []map[string]Mark
+	cursor  int
+	offset  int
+	height  int
+	loading bool
+	err     error
+	apply   bool
+	quit    bool
+	colors  app.Colors
+}
+
+func newModel(ctx context.Context, sources []Source, colors app.Colors) model {
+	input := textinput.New()
+	input.Placeholder = app.TL_(ctx, "Search packages")
+	input.Prompt = "/ "
+	input.Focus()
+
+	marks := make([]map[string]Mark, len(sources))
+	for i := range marks {
+		marks[i] = map[string]Mark{}
+	}
+
+	return model{
+		ctx:     ctx,
+		sources: sources,
+		input:   input,
+		marks:   marks,
+		height:  20,
+		loading: true,
+		colors:  colors,
+	}
+}
+
+// search запускает поиск в фоне. Промежуточные события подавляются, чтобы не портить экран.
+func (m model) search() tea.Cmd {
+	tab, query := m.tab, strings.TrimSpace(m.input.Value())
+	src := m.sources[tab]
+	ctx := context.WithValue(m.ctx, helper.QuietKey, true)
+	return func() tea.Msg {
+		items, err := src.Search(ctx, query)
+		return itemsMsg{tab: tab, query: query, items: items, err: err}
+	}
+}
+
+func (m model) Init() tea.Cmd {
+	return tea.Batch(textinput.Blink, m.search())
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = max(msg.Height-8, 3)
+		return m, nil
+	case itemsMsg:
+		if msg.tab != m.tab || msg.query != strings.TrimSpace(m.input.Value()) {
+			return m, nil
+		}
+		m.loading = false
+		m.items, m.err = msg.items, msg.err
+		m.cursor, m.offset = 0, 0
+		return m, nil
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			m.quit = true
+			return m, tea.Quit
+		case tea.KeyCtrlA:
+			if m.pendingCount() > 0 {
+				m.apply = true
+				m.quit = true
+				return m, tea.Quit
+			}
+			return m, nil
+		case tea.KeyTab, tea.KeyShiftTab:
+			if len(m.sources) < 2 {
+				return m, nil
+			}
+			step := 1
+			if msg.Type == tea.KeyShiftTab {
+				step = len(m.sources) - 1
+			}
+			m.tab = (m.tab + step) % len(m.sources)
+			m.loading, m.items, m.err = true, nil, nil
+			return m, m.search()
+		case tea.KeyEnter:
+			m.loading = true
+			return m, m.search()
+		case tea.KeyUp:
+			m.move(-1)
+			return m, nil
+		case tea.KeyDown:
+			m.move(1)
+			return m, nil
+		case tea.KeyPgUp:
+			m.move(-m.height)
+			return m, nil
+		case tea.KeyPgDown:
+			m.move(m.height)
+			return m, nil
+		case tea.KeySpace:
+			m.toggle()
+			return m, nil
+		default:
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m *model) move(delta int) {
+	if len(m.items) == 0 {
+		return
+	}
+	m.cursor = min(max(m.cursor+delta, 0), len(m.items)-1)
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	} else if m.cursor >= m.offset+m.height {
+		m.offset = m.cursor - m.height + 1
+	}
+}
+
+// toggle помечает пакет под курсором: установленный - к удалению, остальные - к установке.
+// Повторное нажатие снимает отметку.
+func (m *model) toggle() {
+	if len(m.items) == 0 {
+		return
+	}
+	item := m.items[m.cursor]
+	marks := m.marks[m.tab]
+	switch {
+	case marks[item.Name] != MarkNone:
+		delete(marks, item.Name)
+	case item.Installed:
+		marks[item.Name] = MarkRemove
+	default:
+		marks[item.Name] = MarkInstall
+	}
+}
+
+func (m model) pendingCount() int {
+	count := 0
+	for _, marks := range m.marks {
+		count += len(marks)
+	}
+	return count
+}
+
+func (m model) result() Result {
+	result := Result{Apply: m.apply, Pending: make([]Pending, len(m.sources))}
+	for i, marks := range m.marks {
+		for name, mark := range marks {
+			switch mark {
+			case MarkInstall:
+				result.Pending[i].Install = append(result.Pending[i].Install, name)
+			case MarkRemove:
+				result.Pending[i].Remove = append(result.Pending[i].Remove, name)
+			default:
+			}
+		}
+		slices.Sort(result.Pending[i].Install)
+		slices.Sort(result.Pending[i].Remove)
+	}
+	return result
+}
+
+func (m model) View() string {
+	if m.quit {
+		return ""
+	}
+
+	accentStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(m.colors.Accent))
+	activeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(m.colors.DialogAction))
+	dangerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(m.colors.DialogDanger))
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(m.colors.DialogHint)).Faint(true)
+
+	var sb strings.Builder
+	for i, src := range m.sources {
+		title := " " + src.Title() + " "
+		if i == m.tab {
+			sb.WriteString(accentStyle.Reverse(true).Render(title))
+		} else {
+			sb.WriteString(hintStyle.Render(title))
+		}
+		sb.WriteString(" ")
+	}
+	sb.WriteString("\n\n")
+	sb.WriteString(m.input.View())
+	sb.WriteString("\n\n")
+
+	switch {
+	case m.loading:
+		sb.WriteString(hintStyle.Render(app.T_("Loading...")) + "\n")
+	case m.err != nil:
+		sb.WriteString(dangerStyle.Render(m.err.Error()) + "\n")
+	case len(m.items) == 0:
+		sb.WriteString(hintStyle.Render(app.T_("Nothing found")) + "\n")
+	default:
+		end := min(m.offset+m.height, len(m.items))
+		for i := m.offset; i < end; i++ {
+			item := m.items[i]
+			box := "[ ]"
+			switch m.marks[m.tab][item.Name] {
+			case MarkInstall:
+				box = activeStyle.Render("[+]")
+			case MarkRemove:
+				box = dangerStyle.Render("[-]")
+			default:
+				if item.Installed {
+					box = "[i]"
+				}
+			}
+			pointer := "  "
+			name := item.Name
+			if i == m.cursor {
+				pointer = "› "
+				name = activeStyle.Render(name)
+			}
+			sb.WriteString(fmt.Sprintf("%s%s %s %s %s\n", pointer, box, name,
+				hintStyle.Render(item.Version), hintStyle.Render(item.Description)))
+		}
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(m.summary(accentStyle))
+	sb.WriteString(hintStyle.Render(app.T_("Navigation: ↑/↓ - select, Space - mark, Enter - search, Tab - next module, Ctrl+A - apply, Esc - quit")))
+
+	return sb.String()
+}
+
+// summary формирует строку ожидающих изменений по всем вкладкам
+func (m model) summary(style lipgloss.Style) string {
+	result := m.result()
+	var parts []string
+	for i, p := range result.Pending {
+		if len(p.Install) == 0 && len(p.Remove) == 0 {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf(app.T_("%s: %d to install, %d to remove"),
+			m.sources[i].Title(), len(p.Install), len(p.Remove)))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return style.Render(app.T_("Pending changes: ")+strings.Join(parts, "; ")) + "\n"
+}
+
+// Run открывает полноэкранный интерфейс и возвращает отмеченные изменения
+func Run(ctx context.Context, sources []Source, colors app.Colors) (Result, error) {
+	if len(sources) == 0 {
+		return Result{}, errors.New(app.TL_(ctx, "No package sources available"))
+	}
+
+	p := tea.NewProgram(newModel(ctx, sources, colors),
+		tea.WithAltScreen(),
+		tea.WithOutput(os.Stdout),
+		tea.WithoutSignalHandler())
+
+	finalModel, err := p.Run()
+	if err != nil {
+		return Result{}, fmt.Errorf(app.TL_(ctx, "Error starting TUI: %v"), err)
+	}
+
+	if result, ok := finalModel.(model); ok {
+		return result.result(), nil
+	}
+	return Result{}, nil
+}
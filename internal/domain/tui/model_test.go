@@ -0,0 +1,103 @@
+package tui
+
+import (
+	"apm/internal/common/app"
+	"context"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type fakeSource struct {
+	title string
+	items []Item
+}
+
+func (s *fakeSource) Title() string { return s.title }
+
+func (s *fakeSource) Search(_ context.Context, _ string) ([]Item, error) {
+	return s.items, nil
+}
+
+func (s *fakeSource) Apply(_ context.Context, install []string, remove []string) (any, error) {
+	return Pending{Install: install, Remove: remove}, nil
+}
+
+func press(m model, key tea.KeyType) model {
+	next, _ := m.Update(tea.KeyMsg{Type: key})
+	return next.(model)
+}
+
+func TestModelMarks(t *testing.T) {
+	src := &fakeSource{title: "system", items: []Item{
+		{Name: "curl", Installed: true},
+		{Name: "vim"},
+	}}
+	m := newModel(context.Background(), []Source{src, &fakeSource{title: "kernel"}}, app.Colors{})
+
+	next, _ := m.Update(itemsMsg{tab: 0, items: src.items})
+	m = next.(model)
+
+	m = press(m, tea.KeySpace)
+	m = press(m, tea.KeyDown)
+	m = press(m, tea.KeySpace)
+
+	result := m.result()
+	if len(result.Pending[0].Remove) != 1 || result.Pending[0].Remove[0] != "curl" {
+		t.Errorf("expected curl to be marked for removal, got %v", result.Pending[0].Remove)
+	}
+	if len(result.Pending[0].Install) != 1 || result.Pending[0].Install[0] != "vim" {
+		t.Errorf("expected vim to be marked for install, got %v", result.Pending[0].Install)
+	}
+
+	m = press(m, tea.KeySpace)
+	if m.pendingCount() != 1 {
+		t.Errorf("expected second press to unmark package, got %d pending", m.pendingCount())
+	}
+
+	m = press(m, tea.KeyTab)
+	if m.tab != 1 || !m.loading {
+		t.Errorf("expected tab switch to reload second source, got tab %d", m.tab)
+	}
+	next, _ = m.Update(itemsMsg{tab: 0, items: src.items})
+	if next.(model).items != nil {
+		t.Error("expected stale results from previous tab to be ignored")
+	}
+
+	m = press(m, tea.KeyCtrlA)
+	if !m.result().Apply {
+		t.Error("expected apply with pending changes")
+	}
+}
+
+func TestApply(t *testing.T) {
+	actions := &Actions{}
+	sources := []Source{&fakeSource{title: "system"}, &fakeSource{title: "kernel"}}
+
+	if _, err := actions.Apply(context.Background(), sources, Result{}); err == nil {
+		t.Error("expected error when apply was not requested")
+	}
+
+	resp, err := actions.Apply(context.Background(), sources, Result{
+		Apply:   true,
+		Pending: []Pending{{}, {Install: []string{"v4l2loopback"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].Source != "kernel" {
+		t.Errorf("expected only kernel changes applied, got %+v", resp.Results)
+	}
+}
+
+func TestSourcesWithoutDistrobox(t *testing.T) {
+	a := &Actions{}
+
+	sources, err := a.Sources(context.Background(), "")
+	if err != nil || len(sources) != 2 {
+		t.Fatalf("expected system and kernel tabs only, got %d (%v)", len(sources), err)
+	}
+	if _, err = a.Sources(context.Background(), "dev"); err == nil {
+		t.Error("expected error for a container when distrobox is not available")
+	}
+}
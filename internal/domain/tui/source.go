@@ -0,0 +1,178 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package tui
+
+import (
+	"apm/internal/common/app"
+	"apm/internal/domain/distrobox"
+	"apm/internal/domain/kernel"
+	"apm/internal/domain/system"
+	"context"
+	"strings"
+)
+
+// listLimit ограничивает число пакетов, показываемых без поискового запроса
+const listLimit = 200
+
+// Item строка списка пакетов в интерфейсе
+type Item struct {
+	Name        string
+	Version     string
+	Description string
+	Installed   bool
+}
+
+// Source источник пакетов для отдельной вкладки: система, модули ядра или контейнер distrobox.
+type Source interface {
+	Title() string
+	Search(ctx context.Context, query string) ([]Item, error)
+	Apply(ctx context.Context, install []string, remove []string) (any, error)
+}
+
+type systemSource struct {
+	actions *system.Actions
+}
+
+func (s *systemSource) Title() string {
+	return app.T_("System")
+}
+
+func (s *systemSource) Search(ctx context.Context, query string) ([]Item, error) {
+	var items []Item
+	if query == "" {
+		resp, err := s.actions.List(ctx, system.ListParams{Sort: "name", Order: "ASC", Limit: listLimit})
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range resp.Packages {
+			items = append(items, Item{Name: p.Name, Version: p.Version, Description: p.Summary, Installed: p.Installed})
+		}
+		return items, nil
+	}
+
+	resp, err := s.actions.Search(ctx, query, false, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range resp.Packages {
+		items = append(items, Item{Name: p.Name, Version: p.Version, Description: p.Summary, Installed: p.Installed})
+	}
+	return items, nil
+}
+
+// Apply выполняет установку и удаление одной транзакцией, удаляемые пакеты помечаются суффиксом "-".
+func (s *systemSource) Apply(ctx context.Context, install []string, remove []string) (any, error) {
+	packages := append([]string{}, install...)
+	for _, name := range remove {
+		packages = append(packages, name+"-")
+	}
+	return s.actions.Install(ctx, packages, true, false)
+}
+
+type kernelSource struct {
+	actions *kernel.Actions
+}
+
+func (s *kernelSource) Title() string {
+	return app.T_("Kernel modules")
+}
+
+func (s *kernelSource) Search(ctx context.Context, query string) ([]Item, error) {
+	resp, err := s.actions.ListKernelModules(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var items []Item
+	for _, m := range resp.Modules {
+		if query != "" && !strings.Contains(m.Name, query) {
+			continue
+		}
+		items = append(items, Item{Name: m.Name, Version: resp.Kernel.FullVersion, Description: m.PackageName, Installed: m.IsInstalled})
+	}
+	return items, nil
+}
+
+func (s *kernelSource) Apply(ctx context.Context, install []string, remove []string) (any, error) {
+	var results []any
+	if len(install) > 0 {
+		resp, err := s.actions.InstallKernelModules(ctx, "", install, false)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, resp)
+	}
+	if len(remove) > 0 {
+		resp, err := s.actions.RemoveKernelModules(ctx, "", remove, false)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, resp)
+	}
+	return results, nil
+}
+
+type distroboxSource struct {
+	actions   *distrobox.Actions
+	container string
+}
+
+func (s *distroboxSource) Title() string {
+	return "Distrobox: " + s.container
+}
+
+func (s *distroboxSource) Search(ctx context.Context, query string) ([]Item, error) {
+	var items []Item
+	if query == "" {
+		resp, err := s.actions.List(ctx, distrobox.ListParams{Container: s.container, Sort: "name", Order: "ASC", Limit: listLimit})
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range resp.Packages {
+			items = append(items, Item{Name: p.Name, Version: p.Version, Description: p.Description, Installed: p.Installed})
+		}
+		return items, nil
+	}
+
+	resp, err := s.actions.Search(ctx, s.container, query)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range resp.Packages {
+		items = append(items, Item{Name: p.Name, Version: p.Version, Description: p.Description, Installed: p.Installed})
+	}
+	return items, nil
+}
+
+func (s *distroboxSource) Apply(ctx context.Context, install []string, remove []string) (any, error) {
+	var results []any
+	for _, name := range install {
+		resp, err := s.actions.Install(ctx, s.container, name, distrobox.InstallOptions{})
+		if err != nil {
+			return results, err
+		}
+		results = append(results, resp)
+	}
+	for _, name := range remove {
+		resp, err := s.actions.Remove(ctx, s.container, name, false)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, resp)
+	}
+	return results, nil
+}
@@ -17,16 +17,23 @@
 package main
 
 import (
+	"apm/internal/common/apmerr"
 	"apm/internal/common/app"
 	"apm/internal/common/binding/apt"
 	aptLib "apm/internal/common/binding/apt/lib"
 	apmcli "apm/internal/common/cli"
+	"apm/internal/common/network"
 	"apm/internal/common/reply"
 	"apm/internal/common/service"
 	"apm/internal/domain/distrobox"
+	"apm/internal/domain/driver"
 	"apm/internal/domain/kernel"
+	"apm/internal/domain/manifest"
 	"apm/internal/domain/repository"
+	"apm/internal/domain/search"
 	"apm/internal/domain/system"
+	"apm/internal/domain/tasks"
+	"apm/internal/domain/tui"
 	"context"
 	"errors"
 	"fmt"
@@ -46,6 +53,8 @@ type appRuntime struct {
 	reporter *reply.Reporter
 	ctx      context.Context
 	once     sync.Once
+	// exitCode код завершения, если команда не вернула ошибку, но завершилась неудачно
+	exitCode int
 }
 
 func main() {
@@ -59,6 +68,12 @@ func run() int {
 		return 1
 	}
 
+	network.Configure(cfg.ConfigManager.GetConfig().Network)
+
+	if lang := apmcli.LanguageFromArgs(os.Args[1:]); lang != "" {
+		app.SetLanguage(lang)
+	}
+
 	ctx, cancel := apmcli.InstallSignalHandler(context.Background())
 	defer cancel()
 
@@ -85,18 +100,24 @@ func run() int {
 		OnNotFound: func(_ context.Context, cmd *cli.Command, name string) {
 			rt.config.ConfigManager.SetFormat(cmd.String("format"))
 			rt.cliError(fmt.Errorf(app.T_("Unknown command: %s. See 'apm help'"), name))
+			rt.exitCode = apmcli.ExitUsage
 		},
 		OnUsageError: func(err error) error {
 			rt.cliError(apmcli.TranslateUsageError(err))
+			return apmerr.New(apmerr.ErrorTypeValidation, err)
+		},
+		OnUnavailable: func(_ context.Context, cmd *cli.Command, err error) error {
+			rt.config.ConfigManager.SetFormat(cmd.String("format"))
+			rt.cliError(err)
 			return err
 		},
 	})
 
 	if err := rootCommand.Run(rt.ctx, os.Args); err != nil {
-		return 1
+		return apmcli.ExitCode(err)
 	}
 
-	return 0
+	return rt.exitCode
 }
 
 func (rt *appRuntime) buildCommands() []*cli.Command {
@@ -105,43 +126,59 @@ func (rt *appRuntime) buildCommands() []*cli.Command {
 	commands := []*cli.Command{
 		apmcli.NewDBusCommand("dbus-session", app.T_("Start session D-Bus service org.altlinux.APM"), rt.sessionDbus),
 		apmcli.NewDBusCommand("dbus-system", app.T_("Start system D-Bus service org.altlinux.APM"), rt.systemDbus),
-		apmcli.NewHTTPCommand("http-server", app.T_("Start system HTTP API server"), defaultSystemHTTPListen, rt.httpServer),
+		apmcli.NewHTTPCommand("http-server", app.T_("Start system HTTP API server"), systemHTTPListen(cfg), rt.httpServer),
 		apmcli.NewHTTPCommand("http-session", app.T_("Start session HTTP API"), defaultSessionHTTPListen, rt.httpSession),
 		system.CommandList(rt.config, rt.reporter),
 		repository.CommandList(rt.config, rt.reporter),
+		search.Command(rt.config, rt.reporter),
+		system.SelfUpdateCommand(rt.config, rt.reporter),
+		system.LogCommand(rt.config, rt.reporter),
+		system.HistoryCommand(rt.config, rt.reporter),
+		system.AttachCommand(rt.config, rt.reporter),
+		system.DBCommand(rt.config, rt.reporter),
+		system.HooksCommand(rt.config, rt.reporter),
+		system.ConfigCommand(rt.config, rt.reporter),
+		system.ServiceCommand(rt.config, rt.reporter),
+		manifest.ExportCommand(rt.config, rt.reporter),
+		manifest.ApplyCommand(rt.config, rt.reporter),
 	}
 	if cfg.ExistDistrobox {
 		commands = append(commands, distrobox.CommandList(rt.config, rt.reporter))
 	}
-	if !cfg.IsAtomic {
-		commands = append(commands, kernel.CommandList(rt.config, rt.reporter))
-	}
+	commands = append(commands, kernel.CommandList(rt.config, rt.reporter), driver.CommandList(rt.config, rt.reporter),
+		tui.Command(rt.config, rt.reporter))
 	return append(commands, apmcli.HelpCommand(), apmcli.VersionCommand(rt.printVersion))
 }
 
+// systemHTTPListen возвращает адрес системного HTTP API из конфигурации или адрес по умолчанию
+func systemHTTPListen(cfg *app.Configuration) string {
+	if cfg.HTTPListen != "" {
+		return cfg.HTTPListen
+	}
+	return defaultSystemHTTPListen
+}
+
 func (rt *appRuntime) sessionDbus(ctx context.Context, cmd *cli.Command) error {
 	return rt.reportError(service.RunDBus(ctx, cmd, rt.config, service.DBusRunConfig{
 		Bus:  service.BusSession,
 		Mode: apmcli.ForbidRoot,
 		Modules: []service.DBusModule{
 			distrobox.DBusFactory(rt.config, rt.reporter),
+			tasks.DBusFactory(rt.config, rt.reporter, service.BusSession),
 		},
 	}))
 }
 
 func (rt *appRuntime) systemDbus(ctx context.Context, cmd *cli.Command) error {
-	cfg := rt.config.ConfigManager.GetConfig()
-	modules := []service.DBusModule{
-		system.DBusFactory(rt.config, rt.reporter),
-		repository.DBusFactory(rt.config, rt.reporter),
-	}
-	if !cfg.IsAtomic {
-		modules = append(modules, kernel.DBusFactory(rt.config, rt.reporter))
-	}
 	return rt.reportError(service.RunDBus(ctx, cmd, rt.config, service.DBusRunConfig{
-		Bus:     service.BusSystem,
-		Mode:    apmcli.RequireRoot,
-		Modules: modules,
+		Bus:  service.BusSystem,
+		Mode: apmcli.RequireRoot,
+		Modules: []service.DBusModule{
+			system.DBusFactory(rt.config, rt.reporter),
+			repository.DBusFactory(rt.config, rt.reporter),
+			kernel.DBusFactory(rt.config, rt.reporter),
+			tasks.DBusFactory(rt.config, rt.reporter, service.BusSystem),
+		},
 	}))
 }
 
@@ -157,7 +194,9 @@ func (rt *appRuntime) httpServer(ctx context.Context, cmd *cli.Command) error {
 		Modules: []service.HTTPModule{
 			system.HTTPFactory(rt.config, rt.reporter, cfg.IsAtomic),
 			repository.HTTPFactory(rt.config, rt.reporter),
+			tasks.HTTPFactory(rt.config, rt.reporter),
 		},
+		Socket: cfg.HTTPSocket,
 	}))
 }
 
@@ -170,6 +209,7 @@ func (rt *appRuntime) httpSession(ctx context.Context, cmd *cli.Command) error {
 		APIInfo: service.APIInfo{HasDistrobox: true},
 		Modules: []service.HTTPModule{
 			distrobox.HTTPFactory(rt.config, rt.reporter),
+			tasks.HTTPFactory(rt.config, rt.reporter),
 		},
 	}))
 }
@@ -0,0 +1,238 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package client предоставляет типизированный Go-клиент к сервисам apm.
+//
+// Клиент сам выбирает транспорт: D-Bus, если сервис org.altlinux.APM зарегистрирован на шине,
+// иначе HTTP API. Модули system, kernel и repo обслуживаются системным сервисом,
+// distrobox — пользовательским, поэтому транспорт выбирается для каждого из них отдельно.
+// Ход выполнения операций можно получать через WithProgress.
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Transport способ подключения к сервису apm
+type Transport int
+
+const (
+	// TransportAuto выбирает D-Bus при наличии сервиса на шине, иначе HTTP
+	TransportAuto Transport = iota
+	// TransportDBus использует только D-Bus
+	TransportDBus
+	// TransportHTTP использует только HTTP API
+	TransportHTTP
+)
+
+const (
+	// DefaultSystemURL адрес системного HTTP-сервера apm (apm http-server)
+	DefaultSystemURL = "http://127.0.0.1:8080"
+	// DefaultSessionURL адрес пользовательского HTTP-сервера apm (apm http-session)
+	DefaultSessionURL = "http://127.0.0.1:8082"
+)
+
+// ErrUnsupported возвращается, если операция недоступна через выбранный транспорт
+var ErrUnsupported = errors.New("operation is not supported by the selected transport")
+
+// Options параметры подключения клиента
+type Options struct {
+	// Transport способ подключения, по умолчанию TransportAuto
+	Transport Transport
+	// SystemURL адрес системного HTTP-сервера, по умолчанию DefaultSystemURL
+	SystemURL string
+	// SessionURL адрес пользовательского HTTP-сервера, по умолчанию DefaultSessionURL
+	SessionURL string
+	// Token токен авторизации HTTP API в формате [права:]токен
+	Token string
+	// Timeout ограничение времени HTTP-запроса, 0 — без ограничения
+	Timeout time.Duration
+}
+
+// Client клиент сервисов apm
+type Client struct {
+	system  transport
+	session transport
+
+	System    *SystemService
+	Kernel    *KernelService
+	Repo      *RepoService
+	Distrobox *DistroboxService
+	// Tasks фоновые задачи системного сервиса, SessionTasks — пользовательского
+	Tasks        *TaskService
+	SessionTasks *TaskService
+}
+
+// New создаёт клиент и выбирает транспорт для системного и пользовательского сервисов.
+func New(ctx context.Context, opts Options) (*Client, error) {
+	if opts.SystemURL == "" {
+		opts.SystemURL = DefaultSystemURL
+	}
+	if opts.SessionURL == "" {
+		opts.SessionURL = DefaultSessionURL
+	}
+
+	system, err := selectTransport(ctx, opts, busSystem, opts.SystemURL)
+	if err != nil {
+		return nil, err
+	}
+	session, err := selectTransport(ctx, opts, busSession, opts.SessionURL)
+	if err != nil {
+		_ = system.close()
+		return nil, err
+	}
+
+	return newClient(system, session), nil
+}
+
+// newClient собирает клиент поверх готовых транспортов
+func newClient(system, session transport) *Client {
+	c := &Client{system: system, session: session}
+	c.System = &SystemService{c: c}
+	c.Kernel = &KernelService{c: c}
+	c.Repo = &RepoService{c: c}
+	c.Distrobox = &DistroboxService{c: c}
+	c.Tasks = &TaskService{c: c}
+	c.SessionTasks = &TaskService{c: c, session: true}
+	return c
+}
+
+// selectTransport подключается к D-Bus и при отсутствии сервиса на шине переходит на HTTP
+func selectTransport(ctx context.Context, opts Options, bus busType, baseURL string) (transport, error) {
+	switch opts.Transport {
+	case TransportHTTP:
+		return newHTTPTransport(baseURL, opts.Token, opts.Timeout), nil
+	case TransportDBus:
+		return newDBusTransport(ctx, bus)
+	default:
+		if t, err := newDBusTransport(ctx, bus); err == nil {
+			return t, nil
+		}
+		return newHTTPTransport(baseURL, opts.Token, opts.Timeout), nil
+	}
+}
+
+// Close закрывает подключения клиента.
+func (c *Client) Close() error {
+	return errors.Join(c.system.close(), c.session.close())
+}
+
+// SystemTransport возвращает транспорт, выбранный для модулей system, kernel и repo.
+func (c *Client) SystemTransport() Transport {
+	return c.system.kind()
+}
+
+// SessionTransport возвращает транспорт, выбранный для модуля distrobox.
+func (c *Client) SessionTransport() Transport {
+	return c.session.kind()
+}
+
+// Error ошибка, возвращённая сервисом apm
+type Error struct {
+	// Code тип ошибки, например NOT_FOUND или VALIDATION
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return e.Message
+	}
+	return e.Code + ": " + e.Message
+}
+
+// Event уведомление или прогресс выполнения операции
+type Event struct {
+	Name         string  `json:"name"`
+	Message      string  `json:"message"`
+	State        string  `json:"state"`
+	Type         string  `json:"type"`
+	Progress     float64 `json:"progress"`
+	ProgressDone string  `json:"progressDone"`
+	Transaction  string  `json:"transaction"`
+}
+
+// ProgressFunc получает события выполнения операции
+type ProgressFunc func(Event)
+
+type progressKey struct{}
+
+type transactionKey struct{}
+
+// WithProgress возвращает контекст, с которым вызовы клиента передают события операции в fn.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// WithTransaction задаёт идентификатор транзакции вызова. По умолчанию он генерируется клиентом.
+func WithTransaction(ctx context.Context, transaction string) context.Context {
+	return context.WithValue(ctx, transactionKey{}, transaction)
+}
+
+// transactionFrom возвращает идентификатор транзакции из контекста или создаёт новый
+func transactionFrom(ctx context.Context) string {
+	if tx, ok := ctx.Value(transactionKey{}).(string); ok && tx != "" {
+		return tx
+	}
+
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	return strconv.FormatInt(time.Now().UnixNano(), 10) + "-" + hex.EncodeToString(buf)
+}
+
+// progressFrom возвращает обработчик событий из контекста
+func progressFrom(ctx context.Context) ProgressFunc {
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return fn
+}
+
+// call описывает вызов метода модуля через D-Bus и HTTP
+type call struct {
+	module string
+	method string
+	// dbusArgs аргументы D-Bus метода, tx — идентификатор транзакции
+	dbusArgs func(tx string) []any
+	// httpMethod и httpPath пусты, если метод недоступен через HTTP
+	httpMethod string
+	httpPath   string
+	query      map[string]string
+	body       any
+	// session направляет вызов пользовательскому сервису
+	session bool
+}
+
+// invoke выполняет вызов через транспорт модуля и декодирует поле data ответа в out
+func (c *Client) invoke(ctx context.Context, cl call, out any) error {
+	t := c.system
+	if cl.module == moduleDistrobox || cl.session {
+		t = c.session
+	}
+
+	tx := transactionFrom(ctx)
+	if err := t.call(ctx, cl, tx, progressFrom(ctx), out); err != nil {
+		if errors.Is(err, ErrUnsupported) {
+			return fmt.Errorf("%s.%s: %w", cl.module, cl.method, err)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestErrorCodeFromDBusName(t *testing.T) {
+	cases := map[string]string{
+		"org.altlinux.APM.Error.NotFound":    ErrorTypeNotFound,
+		"org.altlinux.APM.Error.NoOperation": ErrorTypeNoOperation,
+		"org.altlinux.APM.Error.Apt":         ErrorTypeApt,
+		"org.freedesktop.DBus.Error.Failed":  "",
+	}
+	for name, expected := range cases {
+		if code := errorCodeFromDBusName(name); code != expected {
+			t.Errorf("%s: expected %q, got %q", name, expected, code)
+		}
+	}
+}
+
+// newTestServer запускает HTTP API, который отвечает handler и рассылает events подписчикам /api/v1/events
+func newTestServer(t *testing.T, handler http.HandlerFunc, events func(tx string) []string) *httptest.Server {
+	t.Helper()
+
+	var mu sync.Mutex
+	var subscribers []*websocket.Conn
+	upgrader := websocket.Upgrader{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/events", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		mu.Lock()
+		subscribers = append(subscribers, conn)
+		mu.Unlock()
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if events != nil {
+			mu.Lock()
+			for _, conn := range subscribers {
+				for _, event := range events(r.Header.Get("X-Transaction-ID")) {
+					_ = conn.WriteMessage(websocket.TextMessage, []byte(event))
+				}
+			}
+			mu.Unlock()
+		}
+		handler(w, r)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newHTTPClient(url string) *Client {
+	return newClient(newHTTPTransport(url, "manage:secret", 0), newHTTPTransport(url, "", 0))
+}
+
+func TestHTTPInstall(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/packages/install" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.URL.Query().Get("download_only") != "true" {
+			t.Errorf("expected download_only query parameter")
+		}
+		if r.Header.Get("Authorization") != "Bearer manage:secret" {
+			t.Errorf("expected token, got %q", r.Header.Get("Authorization"))
+		}
+		var body struct {
+			Packages []string `json:"packages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || !slices.Equal(body.Packages, []string{"vim"}) {
+			t.Errorf("unexpected body: %+v (%v)", body, err)
+		}
+		_, _ = w.Write([]byte(`{"data":{"message":"done","info":{"newInstalledPackages":["vim"],"newInstalledCount":1}},"error":null}`))
+	}, nil)
+
+	c := newHTTPClient(srv.URL)
+	resp, err := c.System.Install(context.Background(), []string{"vim"}, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Message != "done" || resp.Info.NewInstalledCount != 1 {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+	if c.SystemTransport() != TransportHTTP {
+		t.Errorf("expected HTTP transport")
+	}
+}
+
+func TestHTTPBatch(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/batch" || r.URL.Query().Get("simulate") != "true" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		var body struct {
+			Operations []BatchOperation `json:"operations"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.Operations) != 2 || body.Operations[1].Type != "remove" {
+			t.Errorf("unexpected body: %+v (%v)", body, err)
+		}
+		_, _ = w.Write([]byte(`{"data":{"message":"ok","simulated":true,"check":{"message":"check","info":{"removedCount":1}}},"error":null}`))
+	}, nil)
+
+	resp, err := newHTTPClient(srv.URL).System.Batch(context.Background(), []BatchOperation{
+		{Type: "update"},
+		{Type: "remove", Packages: []string{"zip"}},
+	}, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.Simulated || resp.Check == nil || resp.Check.Info.RemovedCount != 1 || resp.Packages != nil {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
+func TestHTTPError(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"data":null,"error":{"errorCode":"NOT_FOUND","message":"package not found"}}`))
+	}, nil)
+
+	_, err := newHTTPClient(srv.URL).System.Info(context.Background(), "missing")
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Code != ErrorTypeNotFound || apiErr.Message != "package not found" {
+		t.Fatalf("expected NOT_FOUND error, got %v", err)
+	}
+}
+
+func TestHTTPProgress(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/distrobox/packages/install" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"data":{"message":"installed","packageInfo":{"package":{"name":"htop"}}},"error":null}`))
+	}, func(tx string) []string {
+		return []string{
+			`{"name":"distrobox.Install","type":"PROGRESS","progress":50,"transaction":"` + tx + `"}` + "\n" +
+				`{"name":"distrobox.Install","type":"PROGRESS","progress":50,"transaction":"other"}`,
+			`{"name":"distrobox.Install","type":"NOTIFICATION","state":"AFTER","transaction":"` + tx + `"}`,
+		}
+	})
+
+	var events []Event
+	ctx := WithProgress(WithTransaction(context.Background(), "tx-1"), func(e Event) {
+		events = append(events, e)
+	})
+
+	resp, err := newHTTPClient(srv.URL).Distrobox.Install(ctx, "alt", "htop", DistroboxInstallOptions{Export: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.PackageInfo.Package.Name != "htop" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+	if len(events) != 2 || events[0].Progress != 50 || events[1].State != "AFTER" {
+		t.Errorf("expected 2 events of transaction tx-1, got %+v", events)
+	}
+}
+
+func TestKernelUnsupportedOverHTTP(t *testing.T) {
+	srv := newTestServer(t, func(http.ResponseWriter, *http.Request) {
+		t.Error("kernel methods must not be sent over HTTP")
+	}, nil)
+
+	_, err := newHTTPClient(srv.URL).Kernel.Current(context.Background())
+	if !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+}
+
+func TestHTTPSessionTaskCancel(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/tasks/tx-1/cancel" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("expected session transport without token, got %q", r.Header.Get("Authorization"))
+		}
+		_, _ = w.Write([]byte(`{"data":{"message":"canceled","task":{"id":"tx-1","name":"distrobox.Update","state":"running"}},"error":null}`))
+	}, nil)
+
+	resp, err := newHTTPClient(srv.URL).SessionTasks.Cancel(context.Background(), "tx-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Task.ID != "tx-1" || resp.Task.Name != "distrobox.Update" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
@@ -0,0 +1,158 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	dbusServiceName  = "org.altlinux.APM"
+	dbusObjectPath   = dbus.ObjectPath("/org/altlinux/APM")
+	dbusSignalMember = "Notification"
+	dbusErrorPrefix  = "org.altlinux.APM.Error."
+)
+
+// busType шина D-Bus сервиса
+type busType int
+
+const (
+	busSystem busType = iota
+	busSession
+)
+
+// dbusTransport вызывает методы сервиса apm через D-Bus
+type dbusTransport struct {
+	conn *dbus.Conn
+}
+
+// newDBusTransport подключается к шине и проверяет, что сервис apm на ней зарегистрирован
+func newDBusTransport(ctx context.Context, bus busType) (*dbusTransport, error) {
+	connect := dbus.ConnectSystemBus
+	if bus == busSession {
+		connect = dbus.ConnectSessionBus
+	}
+	conn, err := connect(dbus.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("connect to D-Bus: %w", err)
+	}
+
+	var hasOwner bool
+	err = conn.BusObject().CallWithContext(ctx, "org.freedesktop.DBus.NameHasOwner", 0, dbusServiceName).Store(&hasOwner)
+	if err == nil && !hasOwner {
+		err = fmt.Errorf("%s is not running", dbusServiceName)
+	}
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return &dbusTransport{conn: conn}, nil
+}
+
+func (t *dbusTransport) kind() Transport {
+	return TransportDBus
+}
+
+func (t *dbusTransport) close() error {
+	return t.conn.Close()
+}
+
+func (t *dbusTransport) call(ctx context.Context, cl call, tx string, progress ProgressFunc, out any) error {
+	if cl.dbusArgs == nil {
+		return ErrUnsupported
+	}
+
+	if progress != nil {
+		stop, err := t.follow(ctx, tx, progress)
+		if err != nil {
+			return err
+		}
+		defer stop()
+	}
+
+	var payload string
+	method := dbusServiceName + "." + cl.module + "." + cl.method
+	err := t.conn.Object(dbusServiceName, dbusObjectPath).CallWithContext(ctx, method, 0, cl.dbusArgs(tx)...).Store(&payload)
+	if err != nil {
+		return convertDBusError(err)
+	}
+
+	return decodeResponse([]byte(payload), out)
+}
+
+// follow подписывается на сигналы сервиса и передаёт в progress события транзакции tx
+func (t *dbusTransport) follow(ctx context.Context, tx string, progress ProgressFunc) (func(), error) {
+	matchOptions := []dbus.MatchOption{
+		dbus.WithMatchObjectPath(dbusObjectPath),
+		dbus.WithMatchInterface(dbusServiceName),
+		dbus.WithMatchMember(dbusSignalMember),
+	}
+	if err := t.conn.AddMatchSignalContext(ctx, matchOptions...); err != nil {
+		return nil, fmt.Errorf("subscribe to D-Bus signals: %w", err)
+	}
+
+	signals := make(chan *dbus.Signal, 64)
+	t.conn.Signal(signals)
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+		for {
+			select {
+			case <-done:
+				return
+			case sig, ok := <-signals:
+				if !ok {
+					return
+				}
+				if sig.Name != dbusServiceName+"."+dbusSignalMember || len(sig.Body) == 0 {
+					continue
+				}
+				if payload, isString := sig.Body[0].(string); isString {
+					dispatchEvent([]byte(payload), tx, progress)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-finished
+		t.conn.RemoveSignal(signals)
+		_ = t.conn.RemoveMatchSignal(matchOptions...)
+	}, nil
+}
+
+// convertDBusError переводит ошибку D-Bus сервиса apm в *Error
+func convertDBusError(err error) error {
+	var dbusErr dbus.Error
+	if !errors.As(err, &dbusErr) {
+		var dbusErrPtr *dbus.Error
+		if !errors.As(err, &dbusErrPtr) {
+			return err
+		}
+		dbusErr = *dbusErrPtr
+	}
+
+	return &Error{Code: errorCodeFromDBusName(dbusErr.Name), Message: dbusErr.Error()}
+}
@@ -0,0 +1,345 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// DistroboxService методы модуля distrobox: пакеты и контейнеры пользователя
+type DistroboxService struct {
+	c *Client
+}
+
+// DistroboxInstallOptions параметры установки пакета в контейнер
+type DistroboxInstallOptions struct {
+	// Export экспортировать приложения пакета на хост
+	Export bool
+	// ExportMain экспортировать только основной .desktop файл или бинарник. Только HTTP
+	ExportMain bool
+	// ExportLabel подпись к названию приложения в меню. Только HTTP
+	ExportLabel string
+	// StripPrefix убрать имя контейнера из идентификаторов .desktop файлов. Только HTTP
+	StripPrefix bool
+}
+
+// Update обновляет список пакетов контейнера.
+func (s *DistroboxService) Update(ctx context.Context, container string) (*ContainerUpdateResponse, error) {
+	var resp ContainerUpdateResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleDistrobox,
+		method:     "Update",
+		dbusArgs:   func(tx string) []any { return []any{container, tx, false} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/distrobox/update",
+		query:      map[string]string{"container": container},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Info возвращает информацию о пакете контейнера.
+func (s *DistroboxService) Info(ctx context.Context, container, packageName string) (*ContainerPackageResponse, error) {
+	var resp ContainerPackageResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleDistrobox,
+		method:     "Info",
+		dbusArgs:   func(tx string) []any { return []any{container, packageName, tx} },
+		httpMethod: http.MethodGet,
+		httpPath:   "/api/v1/distrobox/packages/" + url.PathEscape(packageName),
+		query:      map[string]string{"container": container},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Search ищет пакеты контейнера по названию. Пустой container — поиск во всех контейнерах.
+func (s *DistroboxService) Search(ctx context.Context, container, query string) (*ContainerPackagesResponse, error) {
+	var resp ContainerPackagesResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleDistrobox,
+		method:     "Search",
+		dbusArgs:   func(tx string) []any { return []any{container, query, tx} },
+		httpMethod: http.MethodGet,
+		httpPath:   "/api/v1/distrobox/packages/search",
+		query:      map[string]string{"container": container, "q": query},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// List возвращает постраничный список пакетов контейнера с фильтрацией.
+func (s *DistroboxService) List(ctx context.Context, container string, params ListParams) (*ContainerPackagesResponse, error) {
+	filters, err := marshalFilters(params.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	query := listQuery(params)
+	query["container"] = container
+
+	var resp ContainerPackagesResponse
+	err = s.c.invoke(ctx, call{
+		module: moduleDistrobox,
+		method: "List",
+		dbusArgs: func(tx string) []any {
+			return []any{container, params.Sort, params.Order, params.Limit, params.Offset, filters, params.ForceUpdate, tx}
+		},
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/distrobox/packages/list",
+		query:      query,
+		body:       map[string]any{"filters": params.Filters},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Install устанавливает пакет в контейнер.
+func (s *DistroboxService) Install(ctx context.Context, container, packageName string, opts DistroboxInstallOptions) (*ContainerPackageResponse, error) {
+	var resp ContainerPackageResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleDistrobox,
+		method:     "Install",
+		dbusArgs:   func(tx string) []any { return []any{container, packageName, opts.Export, tx} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/distrobox/packages/install",
+		body: map[string]any{
+			"container":   container,
+			"package":     packageName,
+			"export":      opts.Export,
+			"exportMain":  opts.ExportMain,
+			"exportLabel": opts.ExportLabel,
+			"stripPrefix": opts.StripPrefix,
+		},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Remove удаляет пакет из контейнера. С onlyExport удаляется только экспорт на хост.
+func (s *DistroboxService) Remove(ctx context.Context, container, packageName string, onlyExport bool) (*ContainerPackageResponse, error) {
+	var resp ContainerPackageResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleDistrobox,
+		method:     "Remove",
+		dbusArgs:   func(tx string) []any { return []any{container, packageName, onlyExport, tx} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/distrobox/packages/remove",
+		body:       map[string]any{"container": container, "package": packageName, "onlyExport": onlyExport},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Containers возвращает контейнеры пользователя.
+func (s *DistroboxService) Containers(ctx context.Context) (*ContainerListResponse, error) {
+	var resp ContainerListResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleDistrobox,
+		method:     "ContainerList",
+		dbusArgs:   func(tx string) []any { return []any{tx} },
+		httpMethod: http.MethodGet,
+		httpPath:   "/api/v1/distrobox/containers",
+	}, &resp)
+	return result(&resp, err)
+}
+
+// ContainerAdd создаёт контейнер из образа. additionalPackages и initHooks перечисляются через пробел.
+func (s *DistroboxService) ContainerAdd(ctx context.Context, image, name, additionalPackages, initHooks string) (*ContainerResponse, error) {
+	var resp ContainerResponse
+	err := s.c.invoke(ctx, call{
+		module: moduleDistrobox,
+		method: "ContainerAdd",
+		dbusArgs: func(tx string) []any {
+			return []any{image, name, additionalPackages, initHooks, tx, false}
+		},
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/distrobox/containers",
+		body: map[string]any{
+			"image":              image,
+			"name":               name,
+			"additionalPackages": additionalPackages,
+			"initHooks":          initHooks,
+		},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// ContainerAddTemplate создаёт контейнер по шаблону. Пустой name - имя шаблона.
+func (s *DistroboxService) ContainerAddTemplate(ctx context.Context, template, name string) (*ContainerResponse, error) {
+	var resp ContainerResponse
+	err := s.c.invoke(ctx, call{
+		module: moduleDistrobox,
+		method: "ContainerAddTemplate",
+		dbusArgs: func(tx string) []any {
+			return []any{template, name, tx, false}
+		},
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/distrobox/containers/template",
+		body:       map[string]any{"template": template, "name": name},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Templates возвращает шаблоны контейнеров.
+func (s *DistroboxService) Templates(ctx context.Context) (*ContainerTemplateListResponse, error) {
+	var resp ContainerTemplateListResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleDistrobox,
+		method:     "TemplateList",
+		dbusArgs:   func(tx string) []any { return []any{tx} },
+		httpMethod: http.MethodGet,
+		httpPath:   "/api/v1/distrobox/templates",
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Template возвращает шаблон контейнера по имени.
+func (s *DistroboxService) Template(ctx context.Context, name string) (*ContainerTemplateResponse, error) {
+	var resp ContainerTemplateResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleDistrobox,
+		method:     "TemplateShow",
+		dbusArgs:   func(tx string) []any { return []any{name, tx} },
+		httpMethod: http.MethodGet,
+		httpPath:   "/api/v1/distrobox/templates/" + url.PathEscape(name),
+	}, &resp)
+	return result(&resp, err)
+}
+
+// ContainerClone создаёт контейнер name копией контейнера source.
+func (s *DistroboxService) ContainerClone(ctx context.Context, source, name string) (*ContainerResponse, error) {
+	var resp ContainerResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleDistrobox,
+		method:     "ContainerClone",
+		dbusArgs:   func(tx string) []any { return []any{source, name, tx, false} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/distrobox/containers/" + url.PathEscape(source) + "/clone",
+		body:       map[string]any{"target": name},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Snapshots возвращает снимки контейнера, начиная с последнего.
+func (s *DistroboxService) Snapshots(ctx context.Context, container string) (*ContainerSnapshotListResponse, error) {
+	var resp ContainerSnapshotListResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleDistrobox,
+		method:     "ContainerSnapshots",
+		dbusArgs:   func(tx string) []any { return []any{container, tx} },
+		httpMethod: http.MethodGet,
+		httpPath:   "/api/v1/distrobox/containers/" + url.PathEscape(container) + "/snapshots",
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Snapshot сохраняет снимок контейнера. Пустой name - время создания снимка.
+func (s *DistroboxService) Snapshot(ctx context.Context, container, name string) (*ContainerSnapshotResponse, error) {
+	var resp ContainerSnapshotResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleDistrobox,
+		method:     "ContainerSnapshot",
+		dbusArgs:   func(tx string) []any { return []any{container, name, tx, false} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/distrobox/containers/" + url.PathEscape(container) + "/snapshots",
+		body:       map[string]any{"snapshot": name},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Restore пересоздаёт контейнер из снимка. Пустой name - последний снимок.
+func (s *DistroboxService) Restore(ctx context.Context, container, name string) (*ContainerSnapshotResponse, error) {
+	var resp ContainerSnapshotResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleDistrobox,
+		method:     "ContainerRestore",
+		dbusArgs:   func(tx string) []any { return []any{container, name, tx, false} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/distrobox/containers/" + url.PathEscape(container) + "/restore",
+		body:       map[string]any{"snapshot": name},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// RemoveSnapshot удаляет снимок контейнера вместе с образом.
+func (s *DistroboxService) RemoveSnapshot(ctx context.Context, container, name string) (*ContainerSnapshotResponse, error) {
+	var resp ContainerSnapshotResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleDistrobox,
+		method:     "SnapshotRemove",
+		dbusArgs:   func(tx string) []any { return []any{container, name, tx} },
+		httpMethod: http.MethodDelete,
+		httpPath:   "/api/v1/distrobox/containers/" + url.PathEscape(container) + "/snapshots/" + url.PathEscape(name),
+	}, &resp)
+	return result(&resp, err)
+}
+
+// ContainerRemove удаляет контейнер.
+func (s *DistroboxService) ContainerRemove(ctx context.Context, name string) (*ContainerResponse, error) {
+	return s.container(ctx, "ContainerRemove", http.MethodDelete, name, "")
+}
+
+// ContainerStatus возвращает состояние контейнера.
+func (s *DistroboxService) ContainerStatus(ctx context.Context, name string) (*ContainerResponse, error) {
+	return s.container(ctx, "ContainerStatus", http.MethodGet, name, "")
+}
+
+// ContainerStart запускает контейнер.
+func (s *DistroboxService) ContainerStart(ctx context.Context, name string) (*ContainerResponse, error) {
+	return s.container(ctx, "ContainerStart", http.MethodPost, name, "/start")
+}
+
+// ContainerStop останавливает контейнер.
+func (s *DistroboxService) ContainerStop(ctx context.Context, name string) (*ContainerResponse, error) {
+	return s.container(ctx, "ContainerStop", http.MethodPost, name, "/stop")
+}
+
+func (s *DistroboxService) container(ctx context.Context, method, httpMethod, name, action string) (*ContainerResponse, error) {
+	var resp ContainerResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleDistrobox,
+		method:     method,
+		dbusArgs:   func(tx string) []any { return []any{name, tx} },
+		httpMethod: httpMethod,
+		httpPath:   "/api/v1/distrobox/containers/" + url.PathEscape(name) + action,
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Storage возвращает расположение хранилища контейнеров.
+func (s *DistroboxService) Storage(ctx context.Context) (*StorageResponse, error) {
+	var resp StorageResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleDistrobox,
+		method:     "StorageInfo",
+		dbusArgs:   func(tx string) []any { return []any{tx} },
+		httpMethod: http.MethodGet,
+		httpPath:   "/api/v1/distrobox/storage",
+	}, &resp)
+	return result(&resp, err)
+}
+
+// SetStorage переносит хранилище контейнеров в каталог path.
+func (s *DistroboxService) SetStorage(ctx context.Context, path string) (*StorageResponse, error) {
+	var resp StorageResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleDistrobox,
+		method:     "StorageSet",
+		dbusArgs:   func(tx string) []any { return []any{path, tx, false} },
+		httpMethod: http.MethodPut,
+		httpPath:   "/api/v1/distrobox/storage",
+		body:       map[string]any{"path": path},
+	}, &resp)
+	return result(&resp, err)
+}
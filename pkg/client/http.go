@@ -0,0 +1,163 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// httpTransport вызывает методы сервиса apm через HTTP API
+type httpTransport struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+func newHTTPTransport(baseURL, token string, timeout time.Duration) *httpTransport {
+	return &httpTransport{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+func (t *httpTransport) kind() Transport {
+	return TransportHTTP
+}
+
+func (t *httpTransport) close() error {
+	t.client.CloseIdleConnections()
+	return nil
+}
+
+func (t *httpTransport) call(ctx context.Context, cl call, tx string, progress ProgressFunc, out any) error {
+	if cl.httpPath == "" {
+		return ErrUnsupported
+	}
+
+	if progress != nil {
+		stop, err := t.follow(ctx, tx, progress)
+		if err != nil {
+			return err
+		}
+		defer stop()
+	}
+
+	endpoint := t.baseURL + cl.httpPath
+	if len(cl.query) > 0 {
+		values := url.Values{}
+		for key, value := range cl.query {
+			values.Set(key, value)
+		}
+		endpoint += "?" + values.Encode()
+	}
+
+	var body io.Reader
+	if cl.body != nil {
+		data, err := json.Marshal(cl.body)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, cl.httpMethod, endpoint, body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("X-Transaction-ID", tx)
+	if t.token != "" {
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	payload, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if err = decodeResponse(payload, out); err != nil {
+		if _, isAPIError := err.(*Error); !isAPIError && resp.StatusCode >= http.StatusBadRequest {
+			return &Error{Message: fmt.Sprintf("%s: %s", resp.Status, strings.TrimSpace(string(payload)))}
+		}
+		return err
+	}
+
+	return nil
+}
+
+// follow подключается к потоку событий /api/v1/events и передаёт в progress события транзакции tx
+func (t *httpTransport) follow(ctx context.Context, tx string, progress ProgressFunc) (func(), error) {
+	eventsURL, err := url.Parse(t.baseURL + "/api/v1/events")
+	if err != nil {
+		return nil, err
+	}
+	switch eventsURL.Scheme {
+	case "https":
+		eventsURL.Scheme = "wss"
+	default:
+		eventsURL.Scheme = "ws"
+	}
+
+	header := http.Header{}
+	if t.token != "" {
+		header.Set("Authorization", "Bearer "+t.token)
+	}
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, eventsURL.String(), header)
+	if err != nil {
+		return nil, fmt.Errorf("connect to events: %w", err)
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		for {
+			_, message, errRead := conn.ReadMessage()
+			if errRead != nil {
+				return
+			}
+			// Сервер может объединять несколько событий в одно сообщение через перевод строки
+			for _, line := range bytes.Split(message, []byte{'\n'}) {
+				if len(bytes.TrimSpace(line)) > 0 {
+					dispatchEvent(line, tx, progress)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		_ = conn.Close()
+		<-finished
+	}, nil
+}
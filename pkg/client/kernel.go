@@ -0,0 +1,179 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+)
+
+// KernelService методы модуля kernel. HTTP API для ядер не предусмотрен, методы работают только через D-Bus.
+type KernelService struct {
+	c *Client
+}
+
+// KernelInstallOptions параметры установки и обновления ядра
+type KernelInstallOptions struct {
+	// Flavour тип ядра, пустое значение — текущий
+	Flavour string
+	// Modules модули, устанавливаемые вместе с ядром
+	Modules []string
+	// IncludeHeaders установить заголовочные файлы ядра
+	IncludeHeaders bool
+	// DryRun только показать изменения
+	DryRun bool
+}
+
+// List возвращает ядра заданного flavour, пустое значение — все.
+func (s *KernelService) List(ctx context.Context, flavour string, installedOnly bool) (*KernelListResponse, error) {
+	var resp KernelListResponse
+	err := s.c.invoke(ctx, call{
+		module:   moduleKernel,
+		method:   "ListKernels",
+		dbusArgs: func(tx string) []any { return []any{flavour, installedOnly, tx} },
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Current возвращает загруженное ядро.
+func (s *KernelService) Current(ctx context.Context) (*KernelResponse, error) {
+	var resp KernelResponse
+	err := s.c.invoke(ctx, call{
+		module:   moduleKernel,
+		method:   "GetCurrentKernel",
+		dbusArgs: func(tx string) []any { return []any{tx} },
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Install устанавливает ядро.
+func (s *KernelService) Install(ctx context.Context, opts KernelInstallOptions) (*KernelInstallResponse, error) {
+	return s.install(ctx, "InstallKernel", "CheckInstallKernel", opts)
+}
+
+// Update обновляет ядро до последней версии.
+func (s *KernelService) Update(ctx context.Context, opts KernelInstallOptions) (*KernelInstallResponse, error) {
+	return s.install(ctx, "UpdateKernel", "CheckUpdateKernel", opts)
+}
+
+func (s *KernelService) install(ctx context.Context, method, checkMethod string, opts KernelInstallOptions) (*KernelInstallResponse, error) {
+	if opts.DryRun {
+		method = checkMethod
+	}
+
+	var resp KernelInstallResponse
+	err := s.c.invoke(ctx, call{
+		module: moduleKernel,
+		method: method,
+		dbusArgs: func(tx string) []any {
+			return []any{opts.Flavour, nonNil(opts.Modules), opts.IncludeHeaders, tx, false}
+		},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Clean удаляет старые ядра. С noBackup не сохраняется резервное ядро.
+func (s *KernelService) Clean(ctx context.Context, noBackup bool, dryRun bool) (*KernelCleanResponse, error) {
+	method := "CleanOldKernels"
+	if dryRun {
+		method = "CheckCleanOldKernels"
+	}
+
+	var resp KernelCleanResponse
+	err := s.c.invoke(ctx, call{
+		module:   moduleKernel,
+		method:   method,
+		dbusArgs: func(tx string) []any { return []any{noBackup, tx, false} },
+	}, &resp)
+	return result(&resp, err)
+}
+
+// SetDefault делает установленное ядро загружаемым по умолчанию. version — полная версия из List
+// или имя ядра в /boot. С dryRun только возвращает изменения загрузчика.
+func (s *KernelService) SetDefault(ctx context.Context, version string, dryRun bool) (*KernelSetDefaultResponse, error) {
+	var resp KernelSetDefaultResponse
+	err := s.c.invoke(ctx, call{
+		module:   moduleKernel,
+		method:   "SetDefaultKernel",
+		dbusArgs: func(tx string) []any { return []any{version, dryRun, tx} },
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Cmdline возвращает параметры командной строки ядра. Пустая version — текущее ядро.
+func (s *KernelService) Cmdline(ctx context.Context, version string) (*KernelCmdlineResponse, error) {
+	var resp KernelCmdlineResponse
+	err := s.c.invoke(ctx, call{
+		module:   moduleKernel,
+		method:   "GetKernelCmdline",
+		dbusArgs: func(tx string) []any { return []any{version, tx} },
+	}, &resp)
+	return result(&resp, err)
+}
+
+// SetCmdline добавляет и удаляет параметры командной строки ядра. С dryRun только возвращает разницу.
+func (s *KernelService) SetCmdline(ctx context.Context, version string, add []string, remove []string, dryRun bool) (*KernelCmdlineResponse, error) {
+	var resp KernelCmdlineResponse
+	err := s.c.invoke(ctx, call{
+		module:   moduleKernel,
+		method:   "SetKernelCmdline",
+		dbusArgs: func(tx string) []any { return []any{version, nonNil(add), nonNil(remove), dryRun, tx} },
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Modules возвращает модули, доступные для ядра заданного flavour.
+func (s *KernelService) Modules(ctx context.Context, flavour string) (*KernelModulesResponse, error) {
+	var resp KernelModulesResponse
+	err := s.c.invoke(ctx, call{
+		module:   moduleKernel,
+		method:   "ListKernelModules",
+		dbusArgs: func(tx string) []any { return []any{flavour, tx} },
+	}, &resp)
+	return result(&resp, err)
+}
+
+// InstallModules устанавливает модули ядра.
+func (s *KernelService) InstallModules(ctx context.Context, flavour string, modules []string, dryRun bool) (*KernelModulesChangeResponse, error) {
+	return s.changeModules(ctx, "InstallKernelModules", "CheckInstallKernelModules", flavour, modules, dryRun)
+}
+
+// RemoveModules удаляет модули ядра.
+func (s *KernelService) RemoveModules(ctx context.Context, flavour string, modules []string, dryRun bool) (*KernelModulesChangeResponse, error) {
+	return s.changeModules(ctx, "RemoveKernelModules", "CheckRemoveKernelModules", flavour, modules, dryRun)
+}
+
+func (s *KernelService) changeModules(ctx context.Context, method, checkMethod, flavour string, modules []string, dryRun bool) (*KernelModulesChangeResponse, error) {
+	if dryRun {
+		method = checkMethod
+	}
+
+	var resp KernelModulesChangeResponse
+	err := s.c.invoke(ctx, call{
+		module:   moduleKernel,
+		method:   method,
+		dbusArgs: func(tx string) []any { return []any{flavour, nonNil(modules), tx, false} },
+	}, &resp)
+	return result(&resp, err)
+}
+
+// nonNil заменяет nil пустым срезом: D-Bus не может передать nil как массив строк
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,326 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// RepoService методы модуля repo: источники пакетов
+type RepoService struct {
+	c *Client
+}
+
+// List возвращает репозитории. С all включаются неактивные.
+func (s *RepoService) List(ctx context.Context, all bool) (*RepoListResponse, error) {
+	var resp RepoListResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleRepo,
+		method:     "List",
+		dbusArgs:   func(tx string) []any { return []any{all, tx} },
+		httpMethod: http.MethodGet,
+		httpPath:   "/api/v1/repo",
+		query:      map[string]string{"all": strconv.FormatBool(all)},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Add добавляет репозиторий: ветку, задачу, URL или строку sources.list. date задаёт архив на дату.
+func (s *RepoService) Add(ctx context.Context, source, date string) (*RepoChangeResponse, error) {
+	var resp RepoChangeResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleRepo,
+		method:     "Add",
+		dbusArgs:   func(tx string) []any { return []any{source, date, tx} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/repo",
+		body:       map[string]any{"source": source, "date": date},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// AddNamed подключает репозиторий из реестра вместе с его ключом.
+func (s *RepoService) AddNamed(ctx context.Context, name string) (*RepoChangeResponse, error) {
+	var resp RepoChangeResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleRepo,
+		method:     "AddNamed",
+		dbusArgs:   func(tx string) []any { return []any{name, tx} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/repo/named",
+		body:       map[string]any{"name": name},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Available возвращает реестр известных сторонних репозиториев.
+func (s *RepoService) Available(ctx context.Context) (*RepoAvailableResponse, error) {
+	var resp RepoAvailableResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleRepo,
+		method:     "Available",
+		dbusArgs:   func(tx string) []any { return []any{tx} },
+		httpMethod: http.MethodGet,
+		httpPath:   "/api/v1/repo/available",
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Remove удаляет репозиторий.
+func (s *RepoService) Remove(ctx context.Context, source, date string) (*RepoChangeResponse, error) {
+	var resp RepoChangeResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleRepo,
+		method:     "Remove",
+		dbusArgs:   func(tx string) []any { return []any{source, date, tx} },
+		httpMethod: http.MethodDelete,
+		httpPath:   "/api/v1/repo",
+		body:       map[string]any{"source": source, "date": date},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Set заменяет репозитории веткой branch.
+func (s *RepoService) Set(ctx context.Context, branch, date string) (*RepoChangeResponse, error) {
+	var resp RepoChangeResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleRepo,
+		method:     "Set",
+		dbusArgs:   func(tx string) []any { return []any{branch, date, tx} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/repo/set",
+		body:       map[string]any{"branch": branch, "date": date},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Clean удаляет репозитории задач и cdrom.
+func (s *RepoService) Clean(ctx context.Context) (*RepoChangeResponse, error) {
+	var resp RepoChangeResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleRepo,
+		method:     "Clean",
+		dbusArgs:   func(tx string) []any { return []any{tx} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/repo/clean",
+	}, &resp)
+	return result(&resp, err)
+}
+
+// CheckAdd показывает репозитории, которые добавит Add.
+func (s *RepoService) CheckAdd(ctx context.Context, source, date string) (*RepoSimulateResponse, error) {
+	var resp RepoSimulateResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleRepo,
+		method:     "CheckAdd",
+		dbusArgs:   func(tx string) []any { return []any{source, date, tx} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/repo/check",
+		body:       map[string]any{"source": source, "date": date},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// CheckRemove показывает репозитории, которые удалит Remove.
+func (s *RepoService) CheckRemove(ctx context.Context, source, date string) (*RepoSimulateResponse, error) {
+	var resp RepoSimulateResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleRepo,
+		method:     "CheckRemove",
+		dbusArgs:   func(tx string) []any { return []any{source, date, tx} },
+		httpMethod: http.MethodDelete,
+		httpPath:   "/api/v1/repo/check",
+		body:       map[string]any{"source": source, "date": date},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// CheckSet показывает изменения, которые выполнит Set.
+func (s *RepoService) CheckSet(ctx context.Context, branch, date string) (*RepoSimulateResponse, error) {
+	var resp RepoSimulateResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleRepo,
+		method:     "CheckSet",
+		dbusArgs:   func(tx string) []any { return []any{branch, date, tx} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/repo/set/check",
+		body:       map[string]any{"branch": branch, "date": date},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// CheckClean показывает репозитории, которые удалит Clean.
+func (s *RepoService) CheckClean(ctx context.Context) (*RepoSimulateResponse, error) {
+	var resp RepoSimulateResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleRepo,
+		method:     "CheckClean",
+		dbusArgs:   func(tx string) []any { return []any{tx} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/repo/clean/check",
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Branches возвращает доступные ветки.
+func (s *RepoService) Branches(ctx context.Context) (*BranchesResponse, error) {
+	var resp BranchesResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleRepo,
+		method:     "GetBranches",
+		dbusArgs:   func(string) []any { return nil },
+		httpMethod: http.MethodGet,
+		httpPath:   "/api/v1/repo/branches",
+	}, &resp)
+	return result(&resp, err)
+}
+
+// TaskPackages возвращает пакеты задачи сборочницы.
+func (s *RepoService) TaskPackages(ctx context.Context, taskNum string) (*TaskPackagesResponse, error) {
+	var resp TaskPackagesResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleRepo,
+		method:     "GetTaskPackages",
+		dbusArgs:   func(tx string) []any { return []any{taskNum, tx} },
+		httpMethod: http.MethodGet,
+		httpPath:   "/api/v1/repo/task/" + url.PathEscape(taskNum),
+	}, &resp)
+	return result(&resp, err)
+}
+
+// TaskInfo возвращает состояние, подзадачи и пакеты задачи сборочницы.
+func (s *RepoService) TaskInfo(ctx context.Context, taskNum string) (*TaskInfoResponse, error) {
+	var resp TaskInfoResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleRepo,
+		method:     "TaskInfo",
+		dbusArgs:   func(tx string) []any { return []any{taskNum, tx} },
+		httpMethod: http.MethodGet,
+		httpPath:   "/api/v1/repo/task/" + url.PathEscape(taskNum) + "/info",
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Migrate переводит источники apt-repo под управление apm.
+func (s *RepoService) Migrate(ctx context.Context, simulate bool) (*RepoMigrateResponse, error) {
+	var resp RepoMigrateResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleRepo,
+		method:     "Migrate",
+		dbusArgs:   func(tx string) []any { return []any{simulate, tx} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/repo/migrate",
+		body:       map[string]any{"simulate": simulate},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Backups возвращает снимки источников, сохраняемые перед их изменением.
+func (s *RepoService) Backups(ctx context.Context) (*RepoBackupListResponse, error) {
+	var resp RepoBackupListResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleRepo,
+		method:     "Backups",
+		dbusArgs:   func(tx string) []any { return []any{tx} },
+		httpMethod: http.MethodGet,
+		httpPath:   "/api/v1/repo/backups",
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Restore восстанавливает источники из снимка id. Пустой id - последний снимок.
+func (s *RepoService) Restore(ctx context.Context, id string) (*RepoRestoreResponse, error) {
+	var resp RepoRestoreResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleRepo,
+		method:     "Restore",
+		dbusArgs:   func(tx string) []any { return []any{id, tx} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/repo/restore",
+		body:       map[string]any{"id": id},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// KeyList возвращает GPG ключи, которыми APT проверяет подписи репозиториев.
+func (s *RepoService) KeyList(ctx context.Context) (*KeyListResponse, error) {
+	var resp KeyListResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleRepo,
+		method:     "KeyList",
+		dbusArgs:   func(tx string) []any { return []any{tx} },
+		httpMethod: http.MethodGet,
+		httpPath:   "/api/v1/repo/keys",
+	}, &resp)
+	return result(&resp, err)
+}
+
+// KeyAdd импортирует ключ из файла, по URL или с сервера ключей keyserver. name задаёт имя ключа в источниках.
+func (s *RepoService) KeyAdd(ctx context.Context, source, keyserver, name string) (*KeyChangeResponse, error) {
+	var resp KeyChangeResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleRepo,
+		method:     "KeyAdd",
+		dbusArgs:   func(tx string) []any { return []any{source, keyserver, name, tx} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/repo/keys",
+		body:       map[string]any{"source": source, "keyserver": keyserver, "name": name},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// KeyRemove удаляет ключ по отпечатку, идентификатору или имени.
+func (s *RepoService) KeyRemove(ctx context.Context, id string) (*KeyChangeResponse, error) {
+	var resp KeyChangeResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleRepo,
+		method:     "KeyRemove",
+		dbusArgs:   func(tx string) []any { return []any{id, tx} },
+		httpMethod: http.MethodDelete,
+		httpPath:   "/api/v1/repo/keys/" + url.PathEscape(id),
+	}, &resp)
+	return result(&resp, err)
+}
+
+// KeyVerify проверяет, что ключи активных репозиториев есть в связке.
+func (s *RepoService) KeyVerify(ctx context.Context) (*KeyVerifyResponse, error) {
+	var resp KeyVerifyResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleRepo,
+		method:     "KeyVerify",
+		dbusArgs:   func(tx string) []any { return []any{tx} },
+		httpMethod: http.MethodGet,
+		httpPath:   "/api/v1/repo/keys/verify",
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Health проверяет активные репозитории и возвращает отчёт о проблемах.
+func (s *RepoService) Health(ctx context.Context) (*RepoHealthResponse, error) {
+	var resp RepoHealthResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleRepo,
+		method:     "Health",
+		dbusArgs:   func(tx string) []any { return []any{tx} },
+		httpMethod: http.MethodGet,
+		httpPath:   "/api/v1/repo/check",
+	}, &resp)
+	return result(&resp, err)
+}
@@ -0,0 +1,415 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// SystemService методы модуля system: пакеты, обновление системы и образ
+type SystemService struct {
+	c *Client
+}
+
+// Install устанавливает пакеты.
+func (s *SystemService) Install(ctx context.Context, packages []string, downloadOnly bool) (*ChangesResponse, error) {
+	var resp ChangesResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleSystem,
+		method:     "Install",
+		dbusArgs:   func(tx string) []any { return []any{packages, downloadOnly, tx, false} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/packages/install",
+		query:      map[string]string{"download_only": strconv.FormatBool(downloadOnly)},
+		body:       map[string]any{"packages": packages},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Remove удаляет пакеты. С depends удаляются и ставшие ненужными зависимости.
+func (s *SystemService) Remove(ctx context.Context, packages []string, purge bool, depends bool) (*ChangesResponse, error) {
+	var resp ChangesResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleSystem,
+		method:     "Remove",
+		dbusArgs:   func(tx string) []any { return []any{packages, purge, depends, tx, false} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/packages/remove",
+		body:       map[string]any{"packages": packages, "purge": purge, "depends": depends},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// AutoRemove удаляет пакеты, которые больше не нужны системе.
+func (s *SystemService) AutoRemove(ctx context.Context) (*ChangesResponse, error) {
+	var resp ChangesResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleSystem,
+		method:     "AutoRemove",
+		dbusArgs:   func(tx string) []any { return []any{tx, false} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/packages/autoremove",
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Reinstall переустанавливает пакеты.
+func (s *SystemService) Reinstall(ctx context.Context, packages []string) (*ChangesResponse, error) {
+	var resp ChangesResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleSystem,
+		method:     "Reinstall",
+		dbusArgs:   func(tx string) []any { return []any{packages, tx, false} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/packages/reinstall",
+		body:       map[string]any{"packages": packages},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// CheckInstall показывает изменения, которые выполнит установка пакетов.
+func (s *SystemService) CheckInstall(ctx context.Context, packages []string) (*ChangesResponse, error) {
+	var resp ChangesResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleSystem,
+		method:     "CheckInstall",
+		dbusArgs:   func(tx string) []any { return []any{packages, tx, false} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/packages/check-install",
+		body:       map[string]any{"packages": packages},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// CheckRemove показывает изменения, которые выполнит удаление пакетов.
+func (s *SystemService) CheckRemove(ctx context.Context, packages []string, depends bool) (*ChangesResponse, error) {
+	var resp ChangesResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleSystem,
+		method:     "CheckRemove",
+		dbusArgs:   func(tx string) []any { return []any{packages, depends, tx, false} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/packages/check-remove",
+		body:       map[string]any{"packages": packages, "depends": depends},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// CheckAutoRemove показывает пакеты, которые удалит AutoRemove.
+func (s *SystemService) CheckAutoRemove(ctx context.Context) (*ChangesResponse, error) {
+	var resp ChangesResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleSystem,
+		method:     "CheckAutoRemove",
+		dbusArgs:   func(tx string) []any { return []any{tx, false} },
+		httpMethod: http.MethodGet,
+		httpPath:   "/api/v1/packages/check-autoremove",
+	}, &resp)
+	return result(&resp, err)
+}
+
+// CheckUpgrade показывает изменения, которые выполнит обновление системы.
+func (s *SystemService) CheckUpgrade(ctx context.Context) (*ChangesResponse, error) {
+	var resp ChangesResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleSystem,
+		method:     "CheckUpgrade",
+		dbusArgs:   func(tx string) []any { return []any{tx, false} },
+		httpMethod: http.MethodGet,
+		httpPath:   "/api/v1/system/check-upgrade",
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Update обновляет базу пакетов.
+func (s *SystemService) Update(ctx context.Context) (*UpdateResponse, error) {
+	var resp UpdateResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleSystem,
+		method:     "Update",
+		dbusArgs:   func(tx string) []any { return []any{tx, false} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/system/update",
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Upgrade обновляет систему. С downloadOnly пакеты только скачиваются.
+func (s *SystemService) Upgrade(ctx context.Context, downloadOnly bool) (*UpgradeResponse, error) {
+	var resp UpgradeResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleSystem,
+		method:     "Upgrade",
+		dbusArgs:   func(tx string) []any { return []any{downloadOnly, tx, false} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/system/upgrade",
+		query:      map[string]string{"download_only": strconv.FormatBool(downloadOnly)},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Info возвращает информацию о пакете.
+func (s *SystemService) Info(ctx context.Context, packageName string) (*InfoResponse, error) {
+	var resp InfoResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleSystem,
+		method:     "Info",
+		dbusArgs:   func(tx string) []any { return []any{packageName, tx} },
+		httpMethod: http.MethodGet,
+		httpPath:   "/api/v1/packages/" + url.PathEscape(packageName),
+		query:      map[string]string{"full": "true"},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// MultiInfo возвращает информацию о нескольких пакетах.
+func (s *SystemService) MultiInfo(ctx context.Context, packages []string) (*MultiInfoResponse, error) {
+	var resp MultiInfoResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleSystem,
+		method:     "MultiInfo",
+		dbusArgs:   func(tx string) []any { return []any{packages, tx} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/packages/info",
+		query:      map[string]string{"full": "true"},
+		body:       map[string]any{"packages": packages},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Changelog возвращает записи changelog версии-кандидата новее установленной версии или since.
+func (s *SystemService) Changelog(ctx context.Context, packageName string, since string) (*ChangelogResponse, error) {
+	var resp ChangelogResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleSystem,
+		method:     "Changelog",
+		dbusArgs:   func(tx string) []any { return []any{packageName, since, tx} },
+		httpMethod: http.MethodGet,
+		httpPath:   "/api/v1/packages/" + url.PathEscape(packageName) + "/changelog",
+		query:      map[string]string{"since": since},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Provides ищет пакеты, которым принадлежит файл или которые предоставляют библиотеку.
+func (s *SystemService) Provides(ctx context.Context, target string) (*ProvidesResponse, error) {
+	var resp ProvidesResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleSystem,
+		method:     "Provides",
+		dbusArgs:   func(tx string) []any { return []any{target, tx} },
+		httpMethod: http.MethodGet,
+		httpPath:   "/api/v1/packages/provides",
+		query:      map[string]string{"target": target},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Hold удерживает установленные пакеты от обновления системы.
+func (s *SystemService) Hold(ctx context.Context, packages []string) (*HoldResponse, error) {
+	var resp HoldResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleSystem,
+		method:     "Hold",
+		dbusArgs:   func(tx string) []any { return []any{packages, tx} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/packages/hold",
+		body:       map[string]any{"packages": packages},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Unhold снимает удержание с пакетов.
+func (s *SystemService) Unhold(ctx context.Context, packages []string) (*HoldResponse, error) {
+	var resp HoldResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleSystem,
+		method:     "Unhold",
+		dbusArgs:   func(tx string) []any { return []any{packages, tx} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/packages/unhold",
+		body:       map[string]any{"packages": packages},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Clean удаляет скачанные APT пакеты (cache) и неиспользуемые локальные пакеты из ресурсов образа (downloads).
+func (s *SystemService) Clean(ctx context.Context, cache, downloads bool) (*CleanResponse, error) {
+	var resp CleanResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleSystem,
+		method:     "Clean",
+		dbusArgs:   func(tx string) []any { return []any{cache, downloads, tx} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/packages/clean",
+		query:      map[string]string{"cache": strconv.FormatBool(cache), "downloads": strconv.FormatBool(downloads)},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Search ищет пакеты по названию. С description ищет также по описанию с ранжированием.
+func (s *SystemService) Search(ctx context.Context, query string, installed bool, description bool) (*PackagesResponse, error) {
+	var resp PackagesResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleSystem,
+		method:     "Search",
+		dbusArgs:   func(tx string) []any { return []any{query, tx, installed, description} },
+		httpMethod: http.MethodGet,
+		httpPath:   "/api/v1/packages/search",
+		query: map[string]string{
+			"q": query, "installed": strconv.FormatBool(installed), "description": strconv.FormatBool(description), "full": "true",
+		},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// List возвращает постраничный список пакетов с фильтрацией.
+func (s *SystemService) List(ctx context.Context, params ListParams) (*PackagesResponse, error) {
+	filters, err := marshalFilters(params.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp PackagesResponse
+	err = s.c.invoke(ctx, call{
+		module: moduleSystem,
+		method: "List",
+		dbusArgs: func(tx string) []any {
+			return []any{params.Sort, params.Order, params.Limit, params.Offset, filters, params.ForceUpdate, tx}
+		},
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/packages/list",
+		query:      listQuery(params),
+		body:       map[string]any{"filters": params.Filters},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// GetSystemOverview возвращает сводное состояние системы.
+func (s *SystemService) GetSystemOverview(ctx context.Context) (*SystemOverviewResponse, error) {
+	var resp SystemOverviewResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleSystem,
+		method:     "GetSystemOverview",
+		dbusArgs:   func(tx string) []any { return []any{tx} },
+		httpMethod: http.MethodGet,
+		httpPath:   "/api/v1/system/overview",
+	}, &resp)
+	return result(&resp, err)
+}
+
+// ImageStatus возвращает состояние образа atomic-системы.
+func (s *SystemService) ImageStatus(ctx context.Context) (*ImageStatusResponse, error) {
+	var resp ImageStatusResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleSystem,
+		method:     "ImageStatus",
+		dbusArgs:   func(tx string) []any { return []any{tx} },
+		httpMethod: http.MethodGet,
+		httpPath:   "/api/v1/image/status",
+	}, &resp)
+	return result(&resp, err)
+}
+
+// ImageUpdate обновляет образ atomic-системы. С noCache сборка не использует кэш пакетов хоста.
+func (s *SystemService) ImageUpdate(ctx context.Context, noCache bool) (*ImageStatusResponse, error) {
+	var resp ImageStatusResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleSystem,
+		method:     "ImageUpdate",
+		dbusArgs:   func(tx string) []any { return []any{tx, false, noCache} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/image/update",
+		query:      map[string]string{"no_cache": strconv.FormatBool(noCache)},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// ImageApply применяет изменения конфигурации к образу atomic-системы.
+func (s *SystemService) ImageApply(ctx context.Context, pullImage bool, noCache bool) (*ImageStatusResponse, error) {
+	var resp ImageStatusResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleSystem,
+		method:     "ImageApply",
+		dbusArgs:   func(tx string) []any { return []any{tx, false, pullImage, noCache, "", ""} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/image/apply",
+		query:      map[string]string{"pull": strconv.FormatBool(pullImage), "no_cache": strconv.FormatBool(noCache)},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Batch выполняет операции одной транзакцией: обновление, затем установку и удаление пакетов, затем
+// применение образа. С simulate изменения пакетов только проверяются.
+func (s *SystemService) Batch(ctx context.Context, ops []BatchOperation, simulate bool) (*BatchResponse, error) {
+	data, err := json.Marshal(ops)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp BatchResponse
+	err = s.c.invoke(ctx, call{
+		module:     moduleSystem,
+		method:     "Batch",
+		dbusArgs:   func(tx string) []any { return []any{string(data), simulate, tx, false} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/batch",
+		query:      map[string]string{"simulate": strconv.FormatBool(simulate)},
+		body:       map[string]any{"operations": ops},
+	}, &resp)
+	return result(&resp, err)
+}
+
+// result возвращает ответ или ошибку вызова
+func result[T any](resp *T, err error) (*T, error) {
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// marshalFilters кодирует фильтры для D-Bus методов List
+func marshalFilters(filters []Filter) (string, error) {
+	if len(filters) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(filters)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// listQuery формирует query-параметры HTTP методов List
+func listQuery(params ListParams) map[string]string {
+	query := map[string]string{
+		"limit":       strconv.Itoa(params.Limit),
+		"offset":      strconv.Itoa(params.Offset),
+		"forceUpdate": strconv.FormatBool(params.ForceUpdate),
+	}
+	if params.Sort != "" {
+		query["sort"] = params.Sort
+	}
+	if params.Order != "" {
+		query["order"] = params.Order
+	}
+	return query
+}
@@ -0,0 +1,71 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// TaskService методы модуля tasks: фоновые задачи, запущенные с background
+type TaskService struct {
+	c       *Client
+	session bool
+}
+
+// List возвращает выполняющиеся и последние завершённые задачи.
+func (s *TaskService) List(ctx context.Context) (*TaskListResponse, error) {
+	var resp TaskListResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleTasks,
+		method:     "ListTasks",
+		dbusArgs:   func(tx string) []any { return []any{tx} },
+		httpMethod: http.MethodGet,
+		httpPath:   "/api/v1/tasks",
+		session:    s.session,
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Status возвращает состояние задачи с идентификатором transaction и её результат.
+func (s *TaskService) Status(ctx context.Context, id string) (*TaskStatusResponse, error) {
+	var resp TaskStatusResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleTasks,
+		method:     "TaskStatus",
+		dbusArgs:   func(tx string) []any { return []any{id, tx} },
+		httpMethod: http.MethodGet,
+		httpPath:   "/api/v1/tasks/" + url.PathEscape(id),
+		session:    s.session,
+	}, &resp)
+	return result(&resp, err)
+}
+
+// Cancel отменяет выполняющуюся задачу.
+func (s *TaskService) Cancel(ctx context.Context, id string) (*TaskStatusResponse, error) {
+	var resp TaskStatusResponse
+	err := s.c.invoke(ctx, call{
+		module:     moduleTasks,
+		method:     "CancelTask",
+		dbusArgs:   func(tx string) []any { return []any{id, tx} },
+		httpMethod: http.MethodPost,
+		httpPath:   "/api/v1/tasks/" + url.PathEscape(id) + "/cancel",
+		session:    s.session,
+	}, &resp)
+	return result(&resp, err)
+}
@@ -0,0 +1,101 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+// Модули сервисов apm
+const (
+	moduleSystem    = "system"
+	moduleKernel    = "kernel"
+	moduleRepo      = "repo"
+	moduleDistrobox = "distrobox"
+	moduleTasks     = "tasks"
+)
+
+// Типы событий, приходящих через сигналы D-Bus и WebSocket
+const (
+	EventTypeNotification = "NOTIFICATION"
+	EventTypeProgress     = "PROGRESS"
+	EventTypeTaskResult   = "TASK_RESULT"
+)
+
+// transport выполняет вызовы методов сервиса
+type transport interface {
+	call(ctx context.Context, cl call, tx string, progress ProgressFunc, out any) error
+	kind() Transport
+	close() error
+}
+
+// apiResponse единый формат ответа сервисов apm
+type apiResponse struct {
+	Data  json.RawMessage `json:"data"`
+	Error *apiError       `json:"error"`
+}
+
+type apiError struct {
+	ErrorCode string `json:"errorCode"`
+	Message   string `json:"message"`
+}
+
+// decodeResponse разбирает ответ сервиса и декодирует data в out
+func decodeResponse(payload []byte, out any) error {
+	var resp apiResponse
+	if err := json.Unmarshal(payload, &resp); err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return &Error{Code: resp.Error.ErrorCode, Message: resp.Error.Message}
+	}
+	if out == nil || len(resp.Data) == 0 || string(resp.Data) == "null" {
+		return nil
+	}
+	return json.Unmarshal(resp.Data, out)
+}
+
+// dispatchEvent передаёт в progress событие своей транзакции. Результаты фоновых задач пропускаются.
+func dispatchEvent(payload []byte, tx string, progress ProgressFunc) {
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return
+	}
+	if event.Transaction != tx || event.Type == EventTypeTaskResult {
+		return
+	}
+	progress(event)
+}
+
+// errorCodeFromDBusName переводит имя ошибки D-Bus org.altlinux.APM.Error.NotFound в код NOT_FOUND
+func errorCodeFromDBusName(name string) string {
+	suffix, ok := strings.CutPrefix(name, dbusErrorPrefix)
+	if !ok {
+		return ""
+	}
+
+	var sb strings.Builder
+	for i, r := range suffix {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			sb.WriteByte('_')
+		}
+		sb.WriteRune(r)
+	}
+	return strings.ToUpper(sb.String())
+}
@@ -0,0 +1,757 @@
+// Atomic Package Manager
+// Copyright (C) 2025 Дмитрий Удалов dmitry@udalov.online
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"apm/internal/common/apmerr"
+	"encoding/json"
+	"time"
+)
+
+// Коды ошибок сервиса, см. Error.Code
+const (
+	ErrorTypeDatabase    = apmerr.ErrorTypeDatabase
+	ErrorTypeRepository  = apmerr.ErrorTypeRepository
+	ErrorTypeApt         = apmerr.ErrorTypeApt
+	ErrorTypeValidation  = apmerr.ErrorTypeValidation
+	ErrorTypePermission  = apmerr.ErrorTypePermission
+	ErrorTypeCanceled    = apmerr.ErrorTypeCanceled
+	ErrorTypeImage       = apmerr.ErrorTypeImage
+	ErrorTypeKernel      = apmerr.ErrorTypeKernel
+	ErrorTypeContainer   = apmerr.ErrorTypeContainer
+	ErrorTypeNoOperation = apmerr.ErrorTypeNoOperation
+	ErrorTypeNotFound    = apmerr.ErrorTypeNotFound
+	ErrorTypeBusy        = apmerr.ErrorTypeBusy
+)
+
+// Filter условие фильтрации списков пакетов
+type Filter struct {
+	Field string `json:"field"`
+	Op    string `json:"op"`
+	Value string `json:"value"`
+}
+
+// ListParams параметры постраничного списка пакетов
+type ListParams struct {
+	Sort        string
+	Order       string
+	Limit       int
+	Offset      int
+	Filters     []Filter
+	ForceUpdate bool
+}
+
+// EssentialPackage критически важный пакет, затрагиваемый операцией
+type EssentialPackage struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// PackageChanges изменения пакетов, которые выполнит или выполнила операция
+type PackageChanges struct {
+	ExtraInstalled       []string           `json:"extraInstalled"`
+	UpgradedPackages     []string           `json:"upgradedPackages"`
+	NewInstalledPackages []string           `json:"newInstalledPackages"`
+	RemovedPackages      []string           `json:"removedPackages"`
+	KeptBackPackages     []string           `json:"keptBackPackages"`
+	UpgradedCount        int                `json:"upgradedCount"`
+	NewInstalledCount    int                `json:"newInstalledCount"`
+	RemovedCount         int                `json:"removedCount"`
+	KeptBackCount        int                `json:"keptBackCount"`
+	NotUpgradedCount     int                `json:"notUpgradedCount"`
+	DownloadSize         uint64             `json:"downloadSize"`
+	InstallSize          int64              `json:"installSize"`
+	EssentialPackages    []EssentialPackage `json:"essentialPackages"`
+}
+
+// Package пакет системного репозитория
+type Package struct {
+	Name                string          `json:"name"`
+	Architecture        string          `json:"architecture"`
+	Section             string          `json:"section"`
+	InstalledSize       int             `json:"installedSize"`
+	Maintainer          string          `json:"maintainer"`
+	Version             string          `json:"version"`
+	VersionRaw          string          `json:"versionRaw"`
+	VersionInstalled    string          `json:"versionInstalled"`
+	VersionInstalledRaw string          `json:"versionInstalledRaw"`
+	Depends             []string        `json:"depends"`
+	Aliases             []string        `json:"aliases"`
+	Provides            []string        `json:"provides"`
+	Size                int             `json:"size"`
+	Filename            string          `json:"filename"`
+	Summary             string          `json:"summary"`
+	Description         string          `json:"description"`
+	AppStream           json.RawMessage `json:"appStream,omitempty"`
+	Changelog           string          `json:"lastChangelog"`
+	Installed           bool            `json:"installed"`
+	Held                bool            `json:"held"`
+	TypePackage         int             `json:"typePackage"`
+	Files               []string        `json:"files"`
+}
+
+// ChangesResponse ответ проверок и операций установки, удаления и переустановки пакетов
+type ChangesResponse struct {
+	Message string         `json:"message"`
+	Info    PackageChanges `json:"info"`
+}
+
+// UpdateResponse ответ обновления базы пакетов
+type UpdateResponse struct {
+	Message string `json:"message"`
+	Count   int    `json:"count"`
+}
+
+// BatchOperation операция пакетного запроса: update, install, remove или image-apply
+type BatchOperation struct {
+	Type     string   `json:"type"`
+	Packages []string `json:"packages,omitempty"`
+}
+
+// BatchResponse ответ пакетного запроса с результатами этапов
+type BatchResponse struct {
+	Message    string               `json:"message"`
+	Simulated  bool                 `json:"simulated"`
+	Operations []BatchOperation     `json:"operations"`
+	Update     *UpdateResponse      `json:"update,omitempty"`
+	Check      *ChangesResponse     `json:"check,omitempty"`
+	Packages   *ChangesResponse     `json:"packages,omitempty"`
+	Image      *ImageStatusResponse `json:"image,omitempty"`
+}
+
+// ConfigFileDecision решение по конфигурационному файлу .rpmnew после обновления
+type ConfigFileDecision struct {
+	Path   string `json:"path"`
+	Action string `json:"action"`
+}
+
+// RestartService служба, использующая обновлённые библиотеки
+type RestartService struct {
+	Unit        string   `json:"unit"`
+	PIDs        []int    `json:"pids"`
+	Files       []string `json:"files"`
+	Blacklisted bool     `json:"blacklisted"`
+}
+
+// UpgradeResponse ответ обновления системы
+type UpgradeResponse struct {
+	Message     string               `json:"message"`
+	Result      *string              `json:"result"`
+	ConfigFiles []ConfigFileDecision `json:"configFiles,omitempty"`
+	NeedRestart []RestartService     `json:"needRestart,omitempty"`
+}
+
+// InfoResponse ответ с информацией о пакете
+type InfoResponse struct {
+	Message     string  `json:"message"`
+	PackageInfo Package `json:"packageInfo"`
+}
+
+// MultiInfoResponse ответ с информацией о нескольких пакетах
+type MultiInfoResponse struct {
+	Message  string    `json:"message"`
+	Packages []Package `json:"packages"`
+	NotFound []string  `json:"notFound,omitempty"`
+}
+
+// PackagesResponse ответ поиска и списка пакетов
+type PackagesResponse struct {
+	Message    string    `json:"message"`
+	Packages   []Package `json:"packages"`
+	TotalCount int       `json:"totalCount,omitempty"`
+}
+
+// HoldResponse ответ удержания пакетов от обновления и снятия удержания
+type HoldResponse struct {
+	Message  string   `json:"message"`
+	Packages []string `json:"packages"`
+	Held     []string `json:"held"`
+}
+
+// CleanTarget размеры очищенного каталога
+type CleanTarget struct {
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	Files      int    `json:"files"`
+	SizeBefore int64  `json:"sizeBefore"`
+	SizeAfter  int64  `json:"sizeAfter"`
+}
+
+// CleanResponse ответ очистки скачанных пакетов
+type CleanResponse struct {
+	Message      string        `json:"message"`
+	Targets      []CleanTarget `json:"targets"`
+	Files        int           `json:"files"`
+	OrphanedRows int64         `json:"orphanedRows"`
+	SizeBefore   int64         `json:"sizeBefore"`
+	SizeAfter    int64         `json:"sizeAfter"`
+	Freed        int64         `json:"freed"`
+}
+
+// ProvidingPackage пакет, содержащий файл или предоставляющий библиотеку
+type ProvidingPackage struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Installed bool   `json:"installed"`
+	Source    string `json:"source"`
+}
+
+// ProvidesResponse ответ поиска владельцев файла
+type ProvidesResponse struct {
+	Message  string             `json:"message"`
+	Target   string             `json:"target"`
+	Count    int                `json:"count"`
+	Packages []ProvidingPackage `json:"packages"`
+}
+
+// ChangelogEntry запись changelog пакета
+type ChangelogEntry struct {
+	Date    string   `json:"date"`
+	Author  string   `json:"author"`
+	Version string   `json:"version"`
+	Changes []string `json:"changes"`
+}
+
+// ChangelogResponse ответ с изменениями версии-кандидата
+type ChangelogResponse struct {
+	Message          string           `json:"message"`
+	Package          string           `json:"package"`
+	InstalledVersion string           `json:"installedVersion"`
+	CandidateVersion string           `json:"candidateVersion"`
+	Since            string           `json:"since"`
+	Entries          []ChangelogEntry `json:"entries"`
+}
+
+// ImageStatus состояние образа atomic-системы. Image и Config передаются как есть.
+type ImageStatus struct {
+	Image  json.RawMessage `json:"image"`
+	Status string          `json:"status"`
+	Config json.RawMessage `json:"config"`
+}
+
+// ImageStatusResponse ответ с состоянием образа
+type ImageStatusResponse struct {
+	Message     string          `json:"message"`
+	BootedImage ImageStatus     `json:"bootedImage"`
+	Scheduled   json.RawMessage `json:"scheduled,omitempty"`
+}
+
+// OverviewUpgrades количество ожидающих изменений при обновлении системы
+type OverviewUpgrades struct {
+	Upgraded     int    `json:"upgraded"`
+	NewInstalled int    `json:"newInstalled"`
+	Removed      int    `json:"removed"`
+	KeptBack     int    `json:"keptBack"`
+	DownloadSize uint64 `json:"downloadSize"`
+}
+
+// OverviewRepositories сводка по источникам пакетов
+type OverviewRepositories struct {
+	Total  int    `json:"total"`
+	Active int    `json:"active"`
+	Tasks  int    `json:"tasks"`
+	Branch string `json:"branch"`
+}
+
+// SystemOverviewResponse сводное состояние системы
+type SystemOverviewResponse struct {
+	Message             string               `json:"message"`
+	IsAtomic            bool                 `json:"isAtomic"`
+	Image               *ImageStatus         `json:"image,omitempty"`
+	Upgrades            *OverviewUpgrades    `json:"upgrades,omitempty"`
+	Kernel              *Kernel              `json:"kernel,omitempty"`
+	Repositories        OverviewRepositories `json:"repositories"`
+	HasDistrobox        bool                 `json:"hasDistrobox"`
+	DistroboxContainers int                  `json:"distroboxContainers"`
+	LastUpgrade         *time.Time           `json:"lastUpgrade,omitempty"`
+	Errors              []string             `json:"errors,omitempty"`
+}
+
+// KernelModule модуль ядра
+type KernelModule struct {
+	Name        string `json:"name"`
+	PackageName string `json:"packageName"`
+	IsInstalled bool   `json:"isInstalled,omitempty"`
+}
+
+// Kernel ядро и его установленные модули
+type Kernel struct {
+	PackageName      string          `json:"packageName"`
+	Flavour          string          `json:"flavour"`
+	Version          string          `json:"version"`
+	VersionInstalled string          `json:"versionInstalled"`
+	Release          string          `json:"release"`
+	FullVersion      string          `json:"fullVersion"`
+	IsInstalled      bool            `json:"isInstalled"`
+	IsRunning        bool            `json:"isRunning"`
+	AgeInDays        int             `json:"ageInDays"`
+	BuildTime        json.RawMessage `json:"buildTime,omitempty"`
+	InstalledModules []KernelModule  `json:"installedModules,omitempty"`
+}
+
+// KernelPreview изменения при установке или обновлении ядра
+type KernelPreview struct {
+	Changes         *PackageChanges `json:"changes"`
+	SelectedModules []string        `json:"selectedModules"`
+	MissingModules  []string        `json:"missingModules"`
+}
+
+// KernelListResponse ответ со списком ядер
+type KernelListResponse struct {
+	Message string   `json:"message"`
+	Kernels []Kernel `json:"kernels"`
+}
+
+// KernelResponse ответ с информацией о ядре
+type KernelResponse struct {
+	Message string `json:"message"`
+	Kernel  Kernel `json:"kernel"`
+}
+
+// KernelModuleRebuild результат пересборки внешнего модуля (dkms, akmods) для нового ядра
+type KernelModuleRebuild struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Tool    string `json:"tool"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// KernelInstallResponse ответ установки или обновления ядра
+type KernelInstallResponse struct {
+	Message  string                `json:"message"`
+	Kernel   Kernel                `json:"kernel"`
+	Preview  *KernelPreview        `json:"preview,omitempty"`
+	NextBoot bool                  `json:"nextBoot,omitempty"`
+	Rebuilds []KernelModuleRebuild `json:"rebuilds,omitempty"`
+}
+
+// KeptKernel ядро, сохраняемое при очистке, и причины сохранения
+type KeptKernel struct {
+	Kernel  Kernel   `json:"kernel"`
+	Reasons []string `json:"reasons"`
+}
+
+// KernelCleanResponse ответ очистки старых ядер
+type KernelCleanResponse struct {
+	Message       string          `json:"message"`
+	RemoveKernels []Kernel        `json:"removeKernels"`
+	KeptKernels   []KeptKernel    `json:"keptKernels"`
+	Preview       *PackageChanges `json:"preview,omitempty"`
+}
+
+// KernelBootDefault изменения загрузчика при смене ядра по умолчанию
+type KernelBootDefault struct {
+	Bootloader string   `json:"bootloader"`
+	Entry      string   `json:"entry,omitempty"`
+	Commands   []string `json:"commands"`
+}
+
+// KernelSetDefaultResponse ответ смены ядра по умолчанию
+type KernelSetDefaultResponse struct {
+	Message string            `json:"message"`
+	Kernel  Kernel            `json:"kernel"`
+	Boot    KernelBootDefault `json:"boot"`
+}
+
+// KernelCmdline параметры командной строки ядра и место их хранения
+type KernelCmdline struct {
+	Source string   `json:"source"`
+	Scope  string   `json:"scope"`
+	Path   string   `json:"path,omitempty"`
+	Args   []string `json:"args"`
+}
+
+// KernelCmdlineResponse ответ с параметрами командной строки ядра
+type KernelCmdlineResponse struct {
+	Message string        `json:"message"`
+	Kernel  Kernel        `json:"kernel"`
+	Cmdline KernelCmdline `json:"cmdline"`
+	Added   []string      `json:"added,omitempty"`
+	Removed []string      `json:"removed,omitempty"`
+	Diff    string        `json:"diff,omitempty"`
+}
+
+// KernelModulesResponse ответ со списком модулей ядра
+type KernelModulesResponse struct {
+	Message string         `json:"message"`
+	Kernel  Kernel         `json:"kernel"`
+	Modules []KernelModule `json:"modules"`
+}
+
+// KernelModulesChangeResponse ответ установки или удаления модулей ядра
+type KernelModulesChangeResponse struct {
+	Message  string          `json:"message"`
+	Kernel   Kernel          `json:"kernel"`
+	Preview  *PackageChanges `json:"preview,omitempty"`
+	NextBoot bool            `json:"nextBoot,omitempty"`
+}
+
+// Repository источник пакетов
+type Repository struct {
+	URL        string          `json:"url"`
+	Arch       string          `json:"arch"`
+	Components []string        `json:"components"`
+	Active     bool            `json:"active"`
+	File       string          `json:"file"`
+	Entry      string          `json:"entry"`
+	Branch     string          `json:"branch"`
+	Task       json.RawMessage `json:"task,omitempty"`
+}
+
+// RepoListResponse ответ со списком репозиториев
+type RepoListResponse struct {
+	Message      string       `json:"message"`
+	Repositories []Repository `json:"repositories"`
+	Count        int          `json:"count"`
+}
+
+// RepoChangeResponse ответ добавления, удаления, смены ветки и очистки репозиториев
+type RepoChangeResponse struct {
+	Message string       `json:"message"`
+	Branch  string       `json:"branch,omitempty"`
+	Added   []Repository `json:"added,omitempty"`
+	Removed []Repository `json:"removed,omitempty"`
+	Keys    []Key        `json:"keys,omitempty"`
+}
+
+// NamedRepository известный сторонний репозиторий из реестра
+type NamedRepository struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Homepage    string   `json:"homepage,omitempty"`
+	Sources     []string `json:"sources"`
+	Key         string   `json:"key,omitempty"`
+	Keyserver   string   `json:"keyserver,omitempty"`
+	KeyName     string   `json:"keyName,omitempty"`
+	File        string   `json:"file,omitempty"`
+	Enabled     bool     `json:"enabled"`
+}
+
+// RepoAvailableResponse ответ со списком репозиториев реестра
+type RepoAvailableResponse struct {
+	Message      string            `json:"message"`
+	Repositories []NamedRepository `json:"repositories"`
+	Count        int               `json:"count"`
+}
+
+// RepoSimulateResponse ответ проверки изменений репозиториев
+type RepoSimulateResponse struct {
+	Message    string       `json:"message"`
+	WillAdd    []Repository `json:"willAdd,omitempty"`
+	WillRemove []Repository `json:"willRemove,omitempty"`
+}
+
+// BranchesResponse ответ со списком веток
+type BranchesResponse struct {
+	Message  string   `json:"message"`
+	Branches []string `json:"branches"`
+}
+
+// TaskPackagesResponse ответ со списком пакетов задачи
+type TaskPackagesResponse struct {
+	Message  string   `json:"message"`
+	TaskNum  string   `json:"taskNum"`
+	Packages []string `json:"packages"`
+	Count    int      `json:"count"`
+}
+
+// TaskSubtask подзадача задачи сборочницы
+type TaskSubtask struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Package string `json:"package"`
+	Owner   string `json:"owner,omitempty"`
+}
+
+// TaskInfo метаданные задачи сборочницы
+type TaskInfo struct {
+	ID       string        `json:"id"`
+	Repo     string        `json:"repo"`
+	Owner    string        `json:"owner"`
+	State    string        `json:"state"`
+	TestOnly bool          `json:"testOnly"`
+	Subtasks []TaskSubtask `json:"subtasks"`
+}
+
+// TaskInfoResponse ответ с состоянием и пакетами задачи
+type TaskInfoResponse struct {
+	Message  string    `json:"message"`
+	Task     *TaskInfo `json:"task"`
+	Packages []string  `json:"packages"`
+	Count    int       `json:"count"`
+}
+
+// MigrationChange изменение строки источника при переходе с apt-repo
+type MigrationChange struct {
+	File string `json:"file"`
+	Old  string `json:"old"`
+	New  string `json:"new,omitempty"`
+}
+
+// RepoMigrateResponse ответ перевода источников apt-repo под управление apm
+type RepoMigrateResponse struct {
+	Message        string            `json:"message"`
+	Changes        []MigrationChange `json:"changes"`
+	PriorityBranch string            `json:"priorityBranch,omitempty"`
+	PriorityMacro  bool              `json:"priorityMacro"`
+	LegacyMacro    bool              `json:"legacyMacro"`
+	TaskMeta       []string          `json:"taskMeta,omitempty"`
+	Simulate       bool              `json:"simulate"`
+}
+
+// Backup снимок файлов источников APT
+type Backup struct {
+	ID    string   `json:"id"`
+	Date  string   `json:"date"`
+	Files []string `json:"files"`
+}
+
+// RepoBackupListResponse ответ со списком снимков источников
+type RepoBackupListResponse struct {
+	Message string   `json:"message"`
+	Backups []Backup `json:"backups"`
+	Count   int      `json:"count"`
+}
+
+// RepoRestoreResponse ответ на восстановление источников из снимка
+type RepoRestoreResponse struct {
+	Message string       `json:"message"`
+	Backup  Backup       `json:"backup"`
+	Added   []Repository `json:"added,omitempty"`
+	Removed []Repository `json:"removed,omitempty"`
+}
+
+// Key GPG ключ из связки APT
+type Key struct {
+	KeyID       string   `json:"keyId"`
+	Fingerprint string   `json:"fingerprint"`
+	UserIDs     []string `json:"userIds"`
+	Created     string   `json:"created,omitempty"`
+	Expires     string   `json:"expires,omitempty"`
+	Vendors     []string `json:"vendors,omitempty"`
+}
+
+// KeyCheck результат проверки ключа источника: ok, unsigned, missingVendor или missingKey
+type KeyCheck struct {
+	Entry       string `json:"entry"`
+	Vendor      string `json:"vendor,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Status      string `json:"status"`
+}
+
+// KeyListResponse ответ со списком ключей
+type KeyListResponse struct {
+	Message string `json:"message"`
+	Keys    []Key  `json:"keys"`
+	Count   int    `json:"count"`
+}
+
+// KeyChangeResponse ответ на добавление или удаление ключей
+type KeyChangeResponse struct {
+	Message string `json:"message"`
+	Added   []Key  `json:"added,omitempty"`
+	Removed []Key  `json:"removed,omitempty"`
+}
+
+// KeyVerifyResponse ответ проверки ключей репозиториев
+type KeyVerifyResponse struct {
+	Message      string     `json:"message"`
+	Checks       []KeyCheck `json:"checks"`
+	MissingCount int        `json:"missingCount"`
+}
+
+// HealthIssue проблема, найденная при проверке репозиториев
+type HealthIssue struct {
+	Severity string `json:"severity"`
+	Check    string `json:"check"`
+	Entry    string `json:"entry,omitempty"`
+	File     string `json:"file,omitempty"`
+	Message  string `json:"message"`
+}
+
+// RepoHealthResponse ответ проверки репозиториев
+type RepoHealthResponse struct {
+	Message  string        `json:"message"`
+	Checked  int           `json:"checked"`
+	Issues   []HealthIssue `json:"issues"`
+	Errors   int           `json:"errors"`
+	Warnings int           `json:"warnings"`
+}
+
+// Container контейнер distrobox
+type Container struct {
+	ID            string          `json:"id,omitempty"`
+	OS            string          `json:"os"`
+	ContainerName string          `json:"name"`
+	Active        bool            `json:"active"`
+	Image         string          `json:"image,omitempty"`
+	State         string          `json:"state,omitempty"`
+	Running       bool            `json:"running"`
+	Resources     json.RawMessage `json:"resources,omitempty"`
+	DiskUsage     int64           `json:"diskUsage,omitempty"`
+}
+
+// ContainerPackage пакет в контейнере distrobox
+type ContainerPackage struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	Container   string `json:"container"`
+	Installed   bool   `json:"installed"`
+	Exporting   bool   `json:"exporting"`
+	Manager     string `json:"manager"`
+}
+
+// ContainerPackageInfo пакет контейнера с экспортируемыми файлами
+type ContainerPackageInfo struct {
+	Package      ContainerPackage `json:"package"`
+	DesktopPaths []string         `json:"desktopPaths"`
+	ConsolePaths []string         `json:"consolePaths"`
+	IsConsole    bool             `json:"isConsole"`
+}
+
+// ContainerListResponse ответ со списком контейнеров
+type ContainerListResponse struct {
+	Containers []Container `json:"containers"`
+}
+
+// ContainerResponse ответ операций с контейнером
+type ContainerResponse struct {
+	Message       string    `json:"message"`
+	ContainerInfo Container `json:"containerInfo"`
+	Template      string    `json:"template,omitempty"`
+	Exported      []string  `json:"exported,omitempty"`
+	Warnings      []string  `json:"warnings,omitempty"`
+	Source        string    `json:"source,omitempty"`
+}
+
+// ContainerSnapshot снимок контейнера в локальном образе
+type ContainerSnapshot struct {
+	Container string    `json:"container"`
+	Name      string    `json:"name"`
+	Image     string    `json:"image"`
+	OS        string    `json:"os"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ContainerSnapshotResponse ответ со снимком контейнера
+type ContainerSnapshotResponse struct {
+	Message       string            `json:"message"`
+	Snapshot      ContainerSnapshot `json:"snapshot"`
+	ContainerInfo *Container        `json:"containerInfo,omitempty"`
+}
+
+// ContainerSnapshotListResponse ответ со снимками контейнера
+type ContainerSnapshotListResponse struct {
+	Message   string              `json:"message"`
+	Snapshots []ContainerSnapshot `json:"snapshots"`
+}
+
+// ContainerTemplate шаблон контейнера distrobox
+type ContainerTemplate struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Image       string   `json:"image"`
+	Packages    []string `json:"packages"`
+	InitHooks   []string `json:"initHooks"`
+	Home        string   `json:"home"`
+	Export      []string `json:"export"`
+	File        string   `json:"file"`
+}
+
+// ContainerTemplateListResponse ответ со списком шаблонов контейнеров
+type ContainerTemplateListResponse struct {
+	Message   string              `json:"message"`
+	Templates []ContainerTemplate `json:"templates"`
+}
+
+// ContainerTemplateResponse ответ с шаблоном контейнера
+type ContainerTemplateResponse struct {
+	Message  string            `json:"message"`
+	Template ContainerTemplate `json:"template"`
+}
+
+// ContainerUpdateResponse ответ обновления списка пакетов контейнера
+type ContainerUpdateResponse struct {
+	Message   string    `json:"message"`
+	Container Container `json:"container"`
+	Count     int       `json:"count"`
+}
+
+// ContainerSync сведения о локальной базе пакетов контейнера
+type ContainerSync struct {
+	Container string    `json:"container"`
+	OS        string    `json:"os"`
+	Count     int       `json:"count"`
+	SyncedAt  time.Time `json:"syncedAt"`
+}
+
+// ContainerPackagesResponse ответ поиска и списка пакетов контейнера
+type ContainerPackagesResponse struct {
+	Message    string             `json:"message"`
+	Packages   []ContainerPackage `json:"packages"`
+	TotalCount int                `json:"totalCount,omitempty"`
+	Synced     *ContainerSync     `json:"synced,omitempty"`
+}
+
+// ContainerPackageResponse ответ с информацией о пакете контейнера, его установки и удаления
+type ContainerPackageResponse struct {
+	Message     string               `json:"message"`
+	PackageInfo ContainerPackageInfo `json:"packageInfo"`
+	Warnings    []string             `json:"warnings,omitempty"`
+}
+
+// Storage хранилище контейнеров distrobox
+type Storage struct {
+	Path   string `json:"path"`
+	Custom bool   `json:"custom"`
+	Total  uint64 `json:"total"`
+	Free   uint64 `json:"free"`
+}
+
+// StorageResponse ответ с хранилищем контейнеров
+type StorageResponse struct {
+	Message  string   `json:"message"`
+	Storage  Storage  `json:"storage"`
+	Migrated []string `json:"migrated,omitempty"`
+}
+
+// Task фоновая задача, идентификатор совпадает с transaction запуска
+type Task struct {
+	ID         string          `json:"id"`
+	Name       string          `json:"name"`
+	State      string          `json:"state"`
+	StartedAt  time.Time       `json:"startedAt"`
+	FinishedAt *time.Time      `json:"finishedAt,omitempty"`
+	Result     json.RawMessage `json:"result,omitempty"`
+	ErrorCode  string          `json:"errorCode,omitempty"`
+	Error      string          `json:"error,omitempty"`
+}
+
+// TaskListResponse ответ со списком фоновых задач
+type TaskListResponse struct {
+	Message string `json:"message"`
+	Tasks   []Task `json:"tasks"`
+	Running int    `json:"running"`
+}
+
+// TaskStatusResponse ответ с состоянием фоновой задачи
+type TaskStatusResponse struct {
+	Message string `json:"message"`
+	Task    Task   `json:"task"`
+}
@@ -174,7 +174,7 @@ case "${TEST_SUITE}" in
             echo 'Running binding tests...' && \
             go test ./tests/binding/... -v && \
             echo 'Running unit tests...' && \
-            go test ./internal/... -v
+            go test -tags sqlite_fts5 ./internal/... -v
         "
         ;;
     *)
@@ -88,7 +88,7 @@ func (s *DistroboxTestSuite) TestActionsCreation() {
 
 // TestContainerList тестирует получение списка контейнеров
 func (s *DistroboxTestSuite) TestContainerList() {
-	resp, err := s.actions.ContainerList(s.ctx)
+	resp, err := s.actions.ContainerList(s.ctx, false)
 
 	if err != nil {
 		s.T().Logf("ContainerList failed: %v", err)
@@ -112,7 +112,7 @@ func (s *DistroboxTestSuite) TestContainerList() {
 
 // TestPackageInstall тестирует установку пакета
 func (s *DistroboxTestSuite) TestPackageInstall() {
-	resp, err := s.actions.Install(s.ctx, s.containerName, "hello", false)
+	resp, err := s.actions.Install(s.ctx, s.containerName, "hello", distrobox.InstallOptions{})
 
 	if err != nil {
 		s.T().Logf("Install failed: %v", err)
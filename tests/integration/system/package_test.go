@@ -151,7 +151,7 @@ func (s *SystemTestSuite) TestInfoEmptyPackageName() {
 
 // TestSearch тестирует функцию Search
 func (s *SystemTestSuite) TestSearch() {
-	resp, err := s.actions.Search(s.ctx, testPackage, false)
+	resp, err := s.actions.Search(s.ctx, testPackage, false, false)
 	if err != nil {
 		s.T().Logf("Search error (may be expected): %v", err)
 		assert.True(s.T(),
@@ -77,7 +77,7 @@ func (s *ThreadSafeTestSuite) TestSimultaneousDifferentOperations() {
 			defer func() {
 				atomic.StoreInt64(&searchDone, time.Since(startTime).Nanoseconds())
 			}()
-			_, searchErr = s.actions.Search(s.ctx, threadSafeTestPackage, false)
+			_, searchErr = s.actions.Search(s.ctx, threadSafeTestPackage, false, false)
 		}()
 
 		// GetFilterFields операция
@@ -143,7 +143,7 @@ func (s *ThreadSafeTestSuite) TestConcurrentReadOperationsStress() {
 						}
 
 					case 1:
-						_, err := s.actions.Search(s.ctx, pkg, false)
+						_, err := s.actions.Search(s.ctx, pkg, false, false)
 						if err != nil {
 							atomic.AddInt64(&errorCount, 1)
 							t.Logf("Search error in goroutine %d: %v", goroutineID, err)
@@ -301,7 +301,7 @@ func (s *ThreadSafeTestSuite) TestDatabaseConcurrency() {
 						}
 
 					case 2:
-						_, err := s.actions.Search(s.ctx, "test", false)
+						_, err := s.actions.Search(s.ctx, "test", false, false)
 						if err != nil {
 							select {
 							case errors <- err: